@@ -30,18 +30,24 @@ const (
 	READ      Permission_Type = 0
 	WRITE     Permission_Type = 1
 	READWRITE Permission_Type = 2
+	WATCH     Permission_Type = 3
+	COUNT     Permission_Type = 4
 )
 
 var Permission_Type_name = map[int32]string{
 	0: "READ",
 	1: "WRITE",
 	2: "READWRITE",
+	3: "WATCH",
+	4: "COUNT",
 }
 
 var Permission_Type_value = map[string]int32{
 	"READ":      0,
 	"WRITE":     1,
 	"READWRITE": 2,
+	"WATCH":     3,
+	"COUNT":     4,
 }
 
 func (x Permission_Type) String() string {
@@ -181,11 +187,21 @@ var xxx_messageInfo_Permission proto.InternalMessageInfo
 
 // Role is a single entry in the bucket authRoles
 type Role struct {
-	Name                 []byte        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	KeyPermission        []*Permission `protobuf:"bytes,2,rep,name=keyPermission,proto3" json:"keyPermission,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Name          []byte        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	KeyPermission []*Permission `protobuf:"bytes,2,rep,name=keyPermission,proto3" json:"keyPermission,omitempty"`
+	// maxLeaseTTL is the maximum TTL of the leases granted by the users of the role, 0 if unlimited.
+	MaxLeaseTTL int64 `protobuf:"varint,3,opt,name=maxLeaseTTL,proto3" json:"maxLeaseTTL,omitempty"`
+	// maxRequestsPerSecond is the maximum rate of the unary requests of each user of the role
+	// served by a member, 0 if unlimited.
+	MaxRequestsPerSecond int64 `protobuf:"varint,4,opt,name=maxRequestsPerSecond,proto3" json:"maxRequestsPerSecond,omitempty"`
+	// maxWatchStreams is the maximum number of the concurrent watch streams of each user of the
+	// role served by a member, 0 if unlimited.
+	MaxWatchStreams int64 `protobuf:"varint,5,opt,name=maxWatchStreams,proto3" json:"maxWatchStreams,omitempty"`
+	// maxTxnOps is the maximum number of operations per txn of the users of the role, 0 if unlimited.
+	MaxTxnOps            int64    `protobuf:"varint,6,opt,name=maxTxnOps,proto3" json:"maxTxnOps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Role) Reset()         { *m = Role{} }
@@ -232,29 +248,34 @@ func init() {
 func init() { proto.RegisterFile("auth.proto", fileDescriptor_8bbd6f3875b0e874) }
 
 var fileDescriptor_8bbd6f3875b0e874 = []byte{
-	// 338 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xcf, 0x4e, 0xea, 0x40,
-	0x14, 0xc6, 0x3b, 0xb4, 0x70, 0xdb, 0xc3, 0x85, 0x90, 0x13, 0x72, 0x6f, 0x83, 0x49, 0x6d, 0xba,
-	0x6a, 0x5c, 0x54, 0x85, 0x8d, 0x5b, 0x8c, 0x2c, 0x5c, 0x49, 0x26, 0x18, 0x97, 0xa4, 0xa4, 0x13,
-	0x24, 0xc0, 0x4c, 0x33, 0x83, 0x31, 0x6c, 0x7c, 0x0e, 0x17, 0x3e, 0x10, 0x4b, 0x1e, 0x41, 0xf0,
-	0x45, 0x4c, 0x67, 0xf8, 0x13, 0xa2, 0xbb, 0xef, 0x7c, 0xe7, 0xfb, 0x66, 0x7e, 0x99, 0x01, 0x48,
-	0x5f, 0x16, 0xcf, 0x49, 0x2e, 0xc5, 0x42, 0x60, 0xa5, 0xd0, 0xf9, 0xa8, 0xd5, 0x1c, 0x8b, 0xb1,
-	0xd0, 0xd6, 0x65, 0xa1, 0xcc, 0x36, 0xba, 0x86, 0xfa, 0xa3, 0x62, 0xb2, 0x9b, 0x65, 0x0f, 0xf9,
-	0x62, 0x22, 0xb8, 0xc2, 0x73, 0xa8, 0x72, 0x31, 0xcc, 0x53, 0xa5, 0x5e, 0x85, 0xcc, 0x7c, 0x12,
-	0x92, 0xd8, 0xa5, 0xc0, 0x45, 0x7f, 0xe7, 0x44, 0x6f, 0xe0, 0x14, 0x15, 0x44, 0x70, 0x78, 0x3a,
-	0x67, 0x3a, 0xf1, 0x97, 0x6a, 0x8d, 0x2d, 0x70, 0x0f, 0xcd, 0x92, 0xf6, 0x0f, 0x33, 0x36, 0xa1,
-	0x2c, 0xc5, 0x8c, 0x29, 0xdf, 0x0e, 0xed, 0xd8, 0xa3, 0x66, 0xc0, 0x2b, 0xf8, 0x23, 0xcc, 0xcd,
-	0xbe, 0x13, 0x92, 0xb8, 0xda, 0xfe, 0x97, 0x18, 0xe0, 0xe4, 0x94, 0x8b, 0xee, 0x63, 0xd1, 0x07,
-	0x01, 0xe8, 0x33, 0x39, 0x9f, 0x28, 0x35, 0x11, 0x1c, 0x3b, 0xe0, 0xe6, 0x4c, 0xce, 0x07, 0xcb,
-	0xdc, 0xa0, 0xd4, 0xdb, 0xff, 0xf7, 0x27, 0x1c, 0x53, 0x49, 0xb1, 0xa6, 0x87, 0x20, 0x36, 0xc0,
-	0x9e, 0xb2, 0xe5, 0x0e, 0xb1, 0x90, 0x78, 0x06, 0x9e, 0x4c, 0xf9, 0x98, 0x0d, 0x19, 0xcf, 0x7c,
-	0xdb, 0xa0, 0x6b, 0xa3, 0xc7, 0xb3, 0xe8, 0x02, 0x1c, 0x5d, 0x73, 0xc1, 0xa1, 0xbd, 0xee, 0x5d,
-	0xc3, 0x42, 0x0f, 0xca, 0x4f, 0xf4, 0x7e, 0xd0, 0x6b, 0x10, 0xac, 0x81, 0x57, 0x98, 0x66, 0x2c,
-	0x45, 0x03, 0x70, 0xa8, 0x98, 0xb1, 0x5f, 0x9f, 0xe7, 0x06, 0x6a, 0x53, 0xb6, 0x3c, 0x62, 0xf9,
-	0xa5, 0xd0, 0x8e, 0xab, 0x6d, 0xfc, 0x09, 0x4c, 0x4f, 0x83, 0xb7, 0xfe, 0x6a, 0x13, 0x58, 0xeb,
-	0x4d, 0x60, 0xad, 0xb6, 0x01, 0x59, 0x6f, 0x03, 0xf2, 0xb9, 0x0d, 0xc8, 0xfb, 0x57, 0x60, 0x8d,
-	0x2a, 0xfa, 0x23, 0x3b, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x61, 0x66, 0xc6, 0x9d, 0xf4, 0x01,
-	0x00, 0x00,
+	// 432 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x41, 0x6e, 0xd3, 0x40,
+	0x14, 0x86, 0x33, 0xb1, 0x13, 0xe2, 0x17, 0x5a, 0xac, 0xa7, 0x08, 0xac, 0x82, 0x8c, 0xe5, 0x95,
+	0x57, 0x01, 0xd2, 0x0d, 0x4b, 0x42, 0x89, 0x04, 0x52, 0x45, 0xa2, 0xa9, 0xab, 0x2e, 0xab, 0x69,
+	0xfd, 0x94, 0x46, 0xad, 0x67, 0xcc, 0x8c, 0x2b, 0x9c, 0x0d, 0xe7, 0xe0, 0x1c, 0x9c, 0xa2, 0xcb,
+	0x1e, 0x81, 0x86, 0x1b, 0x70, 0x02, 0xe4, 0x71, 0x9b, 0x50, 0xe8, 0xee, 0x7f, 0xdf, 0xff, 0x3f,
+	0xfb, 0xf7, 0x93, 0x01, 0xc4, 0x65, 0x79, 0x36, 0x2c, 0xb4, 0x2a, 0x15, 0x76, 0x6b, 0x5d, 0x9c,
+	0xec, 0x0c, 0xe6, 0x6a, 0xae, 0x2c, 0x7a, 0x55, 0xab, 0xc6, 0x8d, 0xdf, 0xc0, 0xf6, 0xa1, 0x21,
+	0x3d, 0xce, 0xb2, 0x69, 0x51, 0x2e, 0x94, 0x34, 0xf8, 0x12, 0xfa, 0x52, 0x1d, 0x17, 0xc2, 0x98,
+	0xaf, 0x4a, 0x67, 0x01, 0x8b, 0x58, 0xd2, 0xe3, 0x20, 0xd5, 0xec, 0x96, 0xc4, 0xdf, 0xc0, 0xad,
+	0x57, 0x10, 0xc1, 0x95, 0x22, 0x27, 0x9b, 0x78, 0xcc, 0xad, 0xc6, 0x1d, 0xe8, 0xad, 0x37, 0xdb,
+	0x96, 0xaf, 0x67, 0x1c, 0x40, 0x47, 0xab, 0x0b, 0x32, 0x81, 0x13, 0x39, 0x89, 0xc7, 0x9b, 0x01,
+	0x5f, 0xc3, 0x23, 0xd5, 0xbc, 0x39, 0x70, 0x23, 0x96, 0xf4, 0x47, 0x4f, 0x87, 0x4d, 0xe1, 0xe1,
+	0xfd, 0x5e, 0xfc, 0x2e, 0x16, 0xff, 0x60, 0x00, 0x33, 0xd2, 0xf9, 0xc2, 0x98, 0x85, 0x92, 0xb8,
+	0x0b, 0xbd, 0x82, 0x74, 0x9e, 0x2e, 0x8b, 0xa6, 0xca, 0xf6, 0xe8, 0xd9, 0xdd, 0x13, 0x36, 0xa9,
+	0x61, 0x6d, 0xf3, 0x75, 0x10, 0x7d, 0x70, 0xce, 0x69, 0x79, 0x5b, 0xb1, 0x96, 0xf8, 0x1c, 0x3c,
+	0x2d, 0xe4, 0x9c, 0x8e, 0x49, 0x66, 0x81, 0xd3, 0x54, 0xb7, 0x60, 0x22, 0xb3, 0xf8, 0x1d, 0xb8,
+	0x76, 0xad, 0x07, 0x2e, 0x9f, 0x8c, 0x3f, 0xf8, 0x2d, 0xf4, 0xa0, 0x73, 0xc4, 0x3f, 0xa5, 0x13,
+	0x9f, 0xe1, 0x16, 0x78, 0x35, 0x6c, 0xc6, 0xb6, 0x75, 0xc6, 0xe9, 0xde, 0x47, 0xdf, 0xa9, 0xe5,
+	0xde, 0xf4, 0xf0, 0x73, 0xea, 0xbb, 0xf1, 0x6f, 0x06, 0x2e, 0x57, 0x17, 0xf4, 0xe0, 0xd5, 0xde,
+	0xc2, 0xd6, 0x39, 0x2d, 0x37, 0x6d, 0x83, 0x76, 0xe4, 0x24, 0xfd, 0x11, 0xfe, 0xff, 0x1d, 0xfc,
+	0x7e, 0x10, 0x23, 0xe8, 0xe7, 0xa2, 0xda, 0x27, 0x61, 0x28, 0x4d, 0xf7, 0x6d, 0x6f, 0x87, 0xff,
+	0x8d, 0x70, 0x04, 0x83, 0x5c, 0x54, 0x9c, 0xbe, 0x5c, 0x92, 0x29, 0xcd, 0x8c, 0xf4, 0x01, 0x9d,
+	0x2a, 0x99, 0xd9, 0x63, 0x3b, 0xfc, 0x41, 0x0f, 0x13, 0x78, 0x92, 0x8b, 0xea, 0x48, 0x94, 0xa7,
+	0x67, 0x07, 0xa5, 0x26, 0x91, 0x9b, 0xa0, 0x63, 0xe3, 0xff, 0x62, 0x7c, 0x01, 0x5e, 0x2e, 0xaa,
+	0xb4, 0x92, 0xd3, 0xc2, 0x04, 0x5d, 0x9b, 0xd9, 0x80, 0xf7, 0xc1, 0xd5, 0x4d, 0xd8, 0xba, 0xbe,
+	0x09, 0x5b, 0x57, 0xab, 0x90, 0x5d, 0xaf, 0x42, 0xf6, 0x73, 0x15, 0xb2, 0xef, 0xbf, 0xc2, 0xd6,
+	0x49, 0xd7, 0xfe, 0x7d, 0xbb, 0x7f, 0x06, 0x00, 0x18, 0x79, 0x11, 0x15, 0xa9, 0x02, 0x00, 0x00,
 }
 
 func (m *UserAddOptions) Marshal() (dAtA []byte, err error) {
@@ -426,6 +447,26 @@ func (m *Role) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.MaxTxnOps != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.MaxTxnOps))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.MaxWatchStreams != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.MaxWatchStreams))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.MaxRequestsPerSecond != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.MaxRequestsPerSecond))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MaxLeaseTTL != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.MaxLeaseTTL))
+		i--
+		dAtA[i] = 0x18
+	}
 	if len(m.KeyPermission) > 0 {
 		for iNdEx := len(m.KeyPermission) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -545,6 +586,18 @@ func (m *Role) Size() (n int) {
 			n += 1 + l + sovAuth(uint64(l))
 		}
 	}
+	if m.MaxLeaseTTL != 0 {
+		n += 1 + sovAuth(uint64(m.MaxLeaseTTL))
+	}
+	if m.MaxRequestsPerSecond != 0 {
+		n += 1 + sovAuth(uint64(m.MaxRequestsPerSecond))
+	}
+	if m.MaxWatchStreams != 0 {
+		n += 1 + sovAuth(uint64(m.MaxWatchStreams))
+	}
+	if m.MaxTxnOps != 0 {
+		n += 1 + sovAuth(uint64(m.MaxTxnOps))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1050,6 +1103,82 @@ func (m *Role) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxLeaseTTL", wireType)
+			}
+			m.MaxLeaseTTL = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxLeaseTTL |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxRequestsPerSecond", wireType)
+			}
+			m.MaxRequestsPerSecond = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxRequestsPerSecond |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxWatchStreams", wireType)
+			}
+			m.MaxWatchStreams = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxWatchStreams |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTxnOps", wireType)
+			}
+			m.MaxTxnOps = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTxnOps |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
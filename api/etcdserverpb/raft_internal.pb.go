@@ -31,7 +31,9 @@ type RequestHeader struct {
 	// username is a username that is associated with an auth token of gRPC connection
 	Username string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
 	// auth_revision is a revision number of auth.authStore. It is not related to mvcc
-	AuthRevision         uint64   `protobuf:"varint,3,opt,name=auth_revision,json=authRevision,proto3" json:"auth_revision,omitempty"`
+	AuthRevision uint64 `protobuf:"varint,3,opt,name=auth_revision,json=authRevision,proto3" json:"auth_revision,omitempty"`
+	// roles are the roles granted by the auth token of gRPC connection in addition to the roles of the user
+	Roles                []string `protobuf:"bytes,4,rep,name=roles,proto3" json:"roles,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -85,6 +87,12 @@ type InternalRaftRequest struct {
 	LeaseRevoke              *LeaseRevokeRequest                       `protobuf:"bytes,9,opt,name=lease_revoke,json=leaseRevoke,proto3" json:"lease_revoke,omitempty"`
 	Alarm                    *AlarmRequest                             `protobuf:"bytes,10,opt,name=alarm,proto3" json:"alarm,omitempty"`
 	LeaseCheckpoint          *LeaseCheckpointRequest                   `protobuf:"bytes,11,opt,name=lease_checkpoint,json=leaseCheckpoint,proto3" json:"lease_checkpoint,omitempty"`
+	PrefixQuota              *PrefixQuotaRequest                       `protobuf:"bytes,12,opt,name=prefix_quota,json=prefixQuota,proto3" json:"prefix_quota,omitempty"`
+	RevisionPin              *RevisionPinRequest                       `protobuf:"bytes,13,opt,name=revision_pin,json=revisionPin,proto3" json:"revision_pin,omitempty"`
+	LeaseGrantBatch          *LeaseGrantBatchRequest                   `protobuf:"bytes,14,opt,name=lease_grant_batch,json=leaseGrantBatch,proto3" json:"lease_grant_batch,omitempty"`
+	LeaseRevokeBatch         *LeaseRevokeBatchRequest                  `protobuf:"bytes,15,opt,name=lease_revoke_batch,json=leaseRevokeBatch,proto3" json:"lease_revoke_batch,omitempty"`
+	LeaseExpire              *LeaseRevokeRequest                       `protobuf:"bytes,16,opt,name=lease_expire,json=leaseExpire,proto3" json:"lease_expire,omitempty"`
+	ChangeFeedCheckpoint     *PutRequest                               `protobuf:"bytes,17,opt,name=change_feed_checkpoint,json=changeFeedCheckpoint,proto3" json:"change_feed_checkpoint,omitempty"`
 	AuthEnable               *AuthEnableRequest                        `protobuf:"bytes,1000,opt,name=auth_enable,json=authEnable,proto3" json:"auth_enable,omitempty"`
 	AuthDisable              *AuthDisableRequest                       `protobuf:"bytes,1011,opt,name=auth_disable,json=authDisable,proto3" json:"auth_disable,omitempty"`
 	AuthStatus               *AuthStatusRequest                        `protobuf:"bytes,1013,opt,name=auth_status,json=authStatus,proto3" json:"auth_status,omitempty"`
@@ -102,6 +110,11 @@ type InternalRaftRequest struct {
 	AuthRoleGet              *AuthRoleGetRequest                       `protobuf:"bytes,1202,opt,name=auth_role_get,json=authRoleGet,proto3" json:"auth_role_get,omitempty"`
 	AuthRoleGrantPermission  *AuthRoleGrantPermissionRequest           `protobuf:"bytes,1203,opt,name=auth_role_grant_permission,json=authRoleGrantPermission,proto3" json:"auth_role_grant_permission,omitempty"`
 	AuthRoleRevokePermission *AuthRoleRevokePermissionRequest          `protobuf:"bytes,1204,opt,name=auth_role_revoke_permission,json=authRoleRevokePermission,proto3" json:"auth_role_revoke_permission,omitempty"`
+	AuthRoleSetMaxLeaseTTL   *AuthRoleSetMaxLeaseTTLRequest            `protobuf:"bytes,1205,opt,name=auth_role_set_max_lease_ttl,json=authRoleSetMaxLeaseTtl,proto3" json:"auth_role_set_max_lease_ttl,omitempty"`
+	AuthRoleSetQuota         *AuthRoleSetQuotaRequest                  `protobuf:"bytes,1206,opt,name=auth_role_set_quota,json=authRoleSetQuota,proto3" json:"auth_role_set_quota,omitempty"`
+	AuthSetBcryptCost        *AuthSetBcryptCostRequest                 `protobuf:"bytes,1207,opt,name=auth_set_bcrypt_cost,json=authSetBcryptCost,proto3" json:"auth_set_bcrypt_cost,omitempty"`
+	AuthTokenList            *AuthTokenListRequest                     `protobuf:"bytes,1208,opt,name=auth_token_list,json=authTokenList,proto3" json:"auth_token_list,omitempty"`
+	AuthTokenRevoke          *AuthTokenRevokeRequest                   `protobuf:"bytes,1209,opt,name=auth_token_revoke,json=authTokenRevoke,proto3" json:"auth_token_revoke,omitempty"`
 	ClusterVersionSet        *membershippb.ClusterVersionSetRequest    `protobuf:"bytes,1300,opt,name=cluster_version_set,json=clusterVersionSet,proto3" json:"cluster_version_set,omitempty"`
 	ClusterMemberAttrSet     *membershippb.ClusterMemberAttrSetRequest `protobuf:"bytes,1301,opt,name=cluster_member_attr_set,json=clusterMemberAttrSet,proto3" json:"cluster_member_attr_set,omitempty"`
 	DowngradeInfoSet         *membershippb.DowngradeInfoSetRequest     `protobuf:"bytes,1302,opt,name=downgrade_info_set,json=downgradeInfoSet,proto3" json:"downgrade_info_set,omitempty"`
@@ -143,6 +156,34 @@ func (m *InternalRaftRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_InternalRaftRequest proto.InternalMessageInfo
 
+func (m *InternalRaftRequest) GetAuthRoleSetQuota() *AuthRoleSetQuotaRequest {
+	if m != nil {
+		return m.AuthRoleSetQuota
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetAuthSetBcryptCost() *AuthSetBcryptCostRequest {
+	if m != nil {
+		return m.AuthSetBcryptCost
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetAuthTokenList() *AuthTokenListRequest {
+	if m != nil {
+		return m.AuthTokenList
+	}
+	return nil
+}
+
+func (m *InternalRaftRequest) GetAuthTokenRevoke() *AuthTokenRevokeRequest {
+	if m != nil {
+		return m.AuthTokenRevoke
+	}
+	return nil
+}
+
 type EmptyResponse struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -189,7 +230,13 @@ type InternalAuthenticateRequest struct {
 	Name     string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
 	// simple_token is generated in API layer (etcdserver/v3_server.go)
-	SimpleToken          string   `protobuf:"bytes,3,opt,name=simple_token,json=simpleToken,proto3" json:"simple_token,omitempty"`
+	SimpleToken string `protobuf:"bytes,3,opt,name=simple_token,json=simpleToken,proto3" json:"simple_token,omitempty"`
+	// hashed_password is the password of the user hashed again with the current
+	// bcrypt cost, stored unless the auth store changed since auth_revision.
+	HashedPassword string `protobuf:"bytes,4,opt,name=hashed_password,json=hashedPassword,proto3" json:"hashed_password,omitempty"`
+	AuthRevision   uint64 `protobuf:"varint,5,opt,name=auth_revision,json=authRevision,proto3" json:"auth_revision,omitempty"`
+	// peer_address is the address of the authenticated client, recorded with its token.
+	PeerAddress          string   `protobuf:"bytes,6,opt,name=peer_address,json=peerAddress,proto3" json:"peer_address,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -228,6 +275,27 @@ func (m *InternalAuthenticateRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_InternalAuthenticateRequest proto.InternalMessageInfo
 
+func (m *InternalAuthenticateRequest) GetHashedPassword() string {
+	if m != nil {
+		return m.HashedPassword
+	}
+	return ""
+}
+
+func (m *InternalAuthenticateRequest) GetAuthRevision() uint64 {
+	if m != nil {
+		return m.AuthRevision
+	}
+	return 0
+}
+
+func (m *InternalAuthenticateRequest) GetPeerAddress() string {
+	if m != nil {
+		return m.PeerAddress
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*RequestHeader)(nil), "etcdserverpb.RequestHeader")
 	proto.RegisterType((*InternalRaftRequest)(nil), "etcdserverpb.InternalRaftRequest")
@@ -238,73 +306,95 @@ func init() {
 func init() { proto.RegisterFile("raft_internal.proto", fileDescriptor_b4c9a9be0cfca103) }
 
 var fileDescriptor_b4c9a9be0cfca103 = []byte{
-	// 1054 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x5d, 0x6f, 0x1b, 0x45,
-	0x14, 0xad, 0xd3, 0x34, 0x89, 0xc7, 0x49, 0x9a, 0x4e, 0x52, 0x3a, 0x38, 0x92, 0x71, 0x03, 0x2d,
-	0x01, 0x8a, 0x53, 0x1c, 0x78, 0xe1, 0x05, 0x5c, 0x3b, 0x4a, 0x83, 0x4a, 0x15, 0x6d, 0x0b, 0xaa,
-	0x84, 0xd0, 0x32, 0xde, 0xbd, 0xb1, 0xb7, 0x59, 0xef, 0x2e, 0x33, 0x63, 0x37, 0x7d, 0xe5, 0x91,
-	0x67, 0x40, 0xfc, 0x0c, 0x3e, 0xff, 0x43, 0x85, 0xf8, 0x28, 0xf0, 0x07, 0x20, 0xbc, 0xf0, 0x0e,
-	0xbc, 0xa3, 0xf9, 0xd8, 0x5d, 0xaf, 0x3d, 0xce, 0xdb, 0xfa, 0xde, 0x73, 0xcf, 0x39, 0x33, 0x73,
-	0xef, 0x78, 0xd0, 0x3a, 0xa3, 0x47, 0xc2, 0x0d, 0x22, 0x01, 0x2c, 0xa2, 0x61, 0x23, 0x61, 0xb1,
-	0x88, 0xf1, 0x32, 0x08, 0xcf, 0xe7, 0xc0, 0x46, 0xc0, 0x92, 0x6e, 0x75, 0xa3, 0x17, 0xf7, 0x62,
-	0x95, 0xd8, 0x91, 0x5f, 0x1a, 0x53, 0x5d, 0xcb, 0x31, 0x26, 0x52, 0x66, 0x89, 0x67, 0x3e, 0xeb,
-	0x32, 0xb9, 0x43, 0x93, 0x60, 0x67, 0x04, 0x8c, 0x07, 0x71, 0x94, 0x74, 0xd3, 0x2f, 0x83, 0xb8,
-	0x9e, 0x21, 0x06, 0x30, 0xe8, 0x02, 0xe3, 0xfd, 0x20, 0x49, 0xba, 0x63, 0x3f, 0x34, 0x6e, 0x8b,
-	0xa1, 0x15, 0x07, 0x3e, 0x1e, 0x02, 0x17, 0xb7, 0x81, 0xfa, 0xc0, 0xf0, 0x2a, 0x9a, 0x3b, 0xe8,
-	0x90, 0x52, 0xbd, 0xb4, 0x3d, 0xef, 0xcc, 0x1d, 0x74, 0x70, 0x15, 0x2d, 0x0d, 0xb9, 0x34, 0x3f,
-	0x00, 0x32, 0x57, 0x2f, 0x6d, 0x97, 0x9d, 0xec, 0x37, 0xbe, 0x81, 0x56, 0xe8, 0x50, 0xf4, 0x5d,
-	0x06, 0xa3, 0x40, 0x6a, 0x93, 0xf3, 0xb2, 0xec, 0xd6, 0xe2, 0xa7, 0xdf, 0x93, 0xf3, 0xbb, 0x8d,
-	0xd7, 0x9c, 0x65, 0x99, 0x75, 0x4c, 0xf2, 0xcd, 0xc5, 0x4f, 0x54, 0xf8, 0xe6, 0xd6, 0x0f, 0x18,
-	0xad, 0x1f, 0x98, 0x1d, 0x71, 0xe8, 0x91, 0x30, 0x06, 0xf0, 0x2e, 0x5a, 0xe8, 0x2b, 0x13, 0xc4,
-	0xaf, 0x97, 0xb6, 0x2b, 0xcd, 0xcd, 0xc6, 0xf8, 0x3e, 0x35, 0x0a, 0x3e, 0x1d, 0x03, 0x9d, 0xf2,
-	0x7b, 0x0d, 0xcd, 0x8d, 0x9a, 0xca, 0x69, 0xa5, 0x79, 0xd9, 0x4a, 0xe0, 0xcc, 0x8d, 0x9a, 0xf8,
-	0x26, 0xba, 0xc0, 0x68, 0xd4, 0x03, 0x65, 0xb9, 0xd2, 0xac, 0x4e, 0x20, 0x65, 0x2a, 0x85, 0x6b,
-	0x20, 0x7e, 0x19, 0x9d, 0x4f, 0x86, 0x82, 0xcc, 0x2b, 0x3c, 0x29, 0xe2, 0x0f, 0x87, 0xe9, 0x22,
-	0x1c, 0x09, 0xc2, 0x6d, 0xb4, 0xec, 0x43, 0x08, 0x02, 0x5c, 0x2d, 0x72, 0x41, 0x15, 0xd5, 0x8b,
-	0x45, 0x1d, 0x85, 0x28, 0x48, 0x55, 0xfc, 0x3c, 0x26, 0x05, 0xc5, 0x49, 0x44, 0x16, 0x6c, 0x82,
-	0xf7, 0x4f, 0xa2, 0x4c, 0x50, 0x9c, 0x44, 0xf8, 0x2d, 0x84, 0xbc, 0x78, 0x90, 0x50, 0x4f, 0xc8,
-	0x63, 0x58, 0x54, 0x25, 0xcf, 0x15, 0x4b, 0xda, 0x59, 0x3e, 0xad, 0x1c, 0x2b, 0xc1, 0x6f, 0xa3,
-	0x4a, 0x08, 0x94, 0x83, 0xdb, 0x63, 0x34, 0x12, 0x64, 0xc9, 0xc6, 0x70, 0x47, 0x02, 0xf6, 0x65,
-	0x3e, 0x63, 0x08, 0xb3, 0x90, 0x5c, 0xb3, 0x66, 0x60, 0x30, 0x8a, 0x8f, 0x81, 0x94, 0x6d, 0x6b,
-	0x56, 0x14, 0x8e, 0x02, 0x64, 0x6b, 0x0e, 0xf3, 0x98, 0x3c, 0x16, 0x1a, 0x52, 0x36, 0x20, 0xc8,
-	0x76, 0x2c, 0x2d, 0x99, 0xca, 0x8e, 0x45, 0x01, 0xf1, 0x03, 0xb4, 0xa6, 0x65, 0xbd, 0x3e, 0x78,
-	0xc7, 0x49, 0x1c, 0x44, 0x82, 0x54, 0x54, 0xf1, 0x0b, 0x16, 0xe9, 0x76, 0x06, 0x32, 0x34, 0x69,
-	0xb3, 0xbe, 0xee, 0x5c, 0x0c, 0x8b, 0x00, 0xdc, 0x42, 0x15, 0xd5, 0xdd, 0x10, 0xd1, 0x6e, 0x08,
-	0xe4, 0x6f, 0xeb, 0xae, 0xb6, 0x86, 0xa2, 0xbf, 0xa7, 0x00, 0xd9, 0x9e, 0xd0, 0x2c, 0x84, 0x3b,
-	0x48, 0x8d, 0x80, 0xeb, 0x07, 0x5c, 0x71, 0xfc, 0xb3, 0x68, 0xdb, 0x14, 0xc9, 0xd1, 0xd1, 0x88,
-	0x6c, 0x53, 0x68, 0x1e, 0xc3, 0xef, 0x18, 0x23, 0x5c, 0x50, 0x31, 0xe4, 0xe4, 0xbf, 0x99, 0x46,
-	0xee, 0x29, 0xc0, 0xc4, 0xca, 0xde, 0xd0, 0x8e, 0x74, 0x0e, 0xdf, 0xd5, 0x8e, 0x20, 0x12, 0x81,
-	0x47, 0x05, 0x90, 0x7f, 0x35, 0xd9, 0x4b, 0x45, 0xb2, 0x74, 0x3a, 0x5b, 0x63, 0xd0, 0xd4, 0x5a,
-	0xa1, 0x1e, 0xef, 0x99, 0x2b, 0x40, 0xde, 0x09, 0x2e, 0xf5, 0x7d, 0xf2, 0xe3, 0xd2, 0xac, 0x25,
-	0xbe, 0xc7, 0x81, 0xb5, 0x7c, 0xbf, 0xb0, 0x44, 0x13, 0xc3, 0x77, 0xd1, 0x5a, 0x4e, 0xa3, 0x87,
-	0x80, 0xfc, 0xa4, 0x99, 0x9e, 0xb7, 0x33, 0x99, 0xe9, 0x31, 0x64, 0xab, 0xb4, 0x10, 0x2e, 0xda,
-	0xea, 0x81, 0x20, 0x3f, 0x9f, 0x69, 0x6b, 0x1f, 0xc4, 0x94, 0xad, 0x7d, 0x10, 0xb8, 0x87, 0x9e,
-	0xcd, 0x69, 0xbc, 0xbe, 0x1c, 0x4b, 0x37, 0xa1, 0x9c, 0x3f, 0x8a, 0x99, 0x4f, 0x7e, 0xd1, 0x94,
-	0xaf, 0xd8, 0x29, 0xdb, 0x0a, 0x7d, 0x68, 0xc0, 0x29, 0xfb, 0x33, 0xd4, 0x9a, 0xc6, 0x0f, 0xd0,
-	0xc6, 0x98, 0x5f, 0x39, 0x4f, 0x2e, 0x8b, 0x43, 0x20, 0x4f, 0xb5, 0xc6, 0xf5, 0x19, 0xb6, 0xd5,
-	0x2c, 0xc6, 0x79, 0xdb, 0x5c, 0xa2, 0x93, 0x19, 0xfc, 0x01, 0xba, 0x9c, 0x33, 0xeb, 0xd1, 0xd4,
-	0xd4, 0xbf, 0x6a, 0xea, 0x17, 0xed, 0xd4, 0x66, 0x46, 0xc7, 0xb8, 0x31, 0x9d, 0x4a, 0xe1, 0xdb,
-	0x68, 0x35, 0x27, 0x0f, 0x03, 0x2e, 0xc8, 0x6f, 0x9a, 0xf5, 0xaa, 0x9d, 0xf5, 0x4e, 0xc0, 0x45,
-	0xa1, 0x8f, 0xd2, 0x60, 0xc6, 0x24, 0xad, 0x69, 0xa6, 0xdf, 0x67, 0x32, 0x49, 0xe9, 0x29, 0xa6,
-	0x34, 0x98, 0x1d, 0xbd, 0x62, 0x92, 0x1d, 0xf9, 0x55, 0x79, 0xd6, 0xd1, 0xcb, 0x9a, 0xc9, 0x8e,
-	0x34, 0xb1, 0xac, 0x23, 0x15, 0x8d, 0xe9, 0xc8, 0xaf, 0xcb, 0xb3, 0x3a, 0x52, 0x56, 0x59, 0x3a,
-	0x32, 0x0f, 0x17, 0x6d, 0xc9, 0x8e, 0xfc, 0xe6, 0x4c, 0x5b, 0x93, 0x1d, 0x69, 0x62, 0xf8, 0x21,
-	0xaa, 0x8e, 0xd1, 0xa8, 0x46, 0x49, 0x80, 0x0d, 0x02, 0xae, 0xfe, 0x7f, 0xbf, 0xd5, 0x9c, 0x37,
-	0x66, 0x70, 0x4a, 0xf8, 0x61, 0x86, 0x4e, 0xf9, 0xaf, 0x50, 0x7b, 0x1e, 0x0f, 0xd0, 0x66, 0xae,
-	0x65, 0x5a, 0x67, 0x4c, 0xec, 0x3b, 0x2d, 0xf6, 0xaa, 0x5d, 0x4c, 0x77, 0xc9, 0xb4, 0x1a, 0xa1,
-	0x33, 0x00, 0xf8, 0x23, 0xb4, 0xee, 0x85, 0x43, 0x2e, 0x80, 0xb9, 0xe6, 0x2d, 0xe3, 0x72, 0x10,
-	0xe4, 0x33, 0x64, 0x46, 0x60, 0xfc, 0x21, 0xd3, 0x68, 0x6b, 0xe4, 0xfb, 0x1a, 0x78, 0x0f, 0xc4,
-	0xd4, 0xad, 0x77, 0xc9, 0x9b, 0x84, 0xe0, 0x87, 0xe8, 0x4a, 0xaa, 0xa0, 0xc9, 0x5c, 0x2a, 0x04,
-	0x53, 0x2a, 0x9f, 0x23, 0x73, 0x0f, 0xda, 0x54, 0xde, 0x55, 0xb1, 0x96, 0x10, 0xcc, 0x26, 0xb4,
-	0xe1, 0x59, 0x50, 0xf8, 0x43, 0x84, 0xfd, 0xf8, 0x51, 0xd4, 0x63, 0xd4, 0x07, 0x37, 0x88, 0x8e,
-	0x62, 0x25, 0xf3, 0x85, 0x96, 0xb9, 0x56, 0x94, 0xe9, 0xa4, 0xc0, 0x83, 0xe8, 0x28, 0xb6, 0x49,
-	0xac, 0xf9, 0x13, 0x88, 0xfc, 0x31, 0x75, 0x11, 0xad, 0xec, 0x0d, 0x12, 0xf1, 0xd8, 0x01, 0x9e,
-	0xc4, 0x11, 0x87, 0xad, 0xc7, 0x68, 0xf3, 0x8c, 0xeb, 0x1b, 0x63, 0x34, 0xaf, 0xde, 0x72, 0x25,
-	0xf5, 0x96, 0x53, 0xdf, 0xf2, 0x8d, 0x97, 0xdd, 0x6a, 0xe6, 0x8d, 0x97, 0xfe, 0xc6, 0x57, 0xd1,
-	0x32, 0x0f, 0x06, 0x49, 0x08, 0xae, 0x88, 0x8f, 0x41, 0x3f, 0xf1, 0xca, 0x4e, 0x45, 0xc7, 0xee,
-	0xcb, 0x50, 0xe6, 0xe5, 0xd6, 0xc6, 0x93, 0x3f, 0x6b, 0xe7, 0x9e, 0x9c, 0xd6, 0x4a, 0x4f, 0x4f,
-	0x6b, 0xa5, 0x3f, 0x4e, 0x6b, 0xa5, 0x2f, 0xff, 0xaa, 0x9d, 0xeb, 0x2e, 0xa8, 0x97, 0xe6, 0xee,
-	0xff, 0x01, 0x00, 0x00, 0xff, 0xff, 0x30, 0x36, 0x53, 0xc6, 0x0b, 0x0b, 0x00, 0x00,
+	// 1396 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x97, 0x49, 0x73, 0x1c, 0x35,
+	0x14, 0xc7, 0x33, 0x5e, 0x33, 0x1a, 0xaf, 0xb2, 0x93, 0x08, 0xa7, 0x30, 0x8e, 0x21, 0xc1, 0x84,
+	0xe0, 0x04, 0x07, 0x72, 0xe0, 0x02, 0xde, 0x48, 0x4c, 0x39, 0x29, 0xd3, 0x36, 0x10, 0xd6, 0x46,
+	0xd3, 0xfd, 0x3c, 0xd3, 0x49, 0x4f, 0x77, 0x47, 0xd2, 0x38, 0x93, 0x2b, 0x55, 0x5c, 0xb8, 0x51,
+	0x05, 0x14, 0xdf, 0x82, 0x35, 0x2c, 0xdf, 0x20, 0x07, 0x96, 0x00, 0x5f, 0x00, 0xc2, 0x85, 0x3b,
+	0x70, 0xa7, 0xb4, 0xf4, 0x36, 0xa3, 0x71, 0x71, 0xeb, 0x7e, 0xef, 0xaf, 0xdf, 0x7b, 0x92, 0x9e,
+	0xfa, 0xb5, 0xd0, 0x0c, 0xa3, 0xfb, 0xc2, 0x0d, 0x22, 0x01, 0x2c, 0xa2, 0xe1, 0x72, 0xc2, 0x62,
+	0x11, 0xe3, 0x31, 0x10, 0x9e, 0xcf, 0x81, 0x1d, 0x00, 0x4b, 0xea, 0x73, 0xb3, 0x8d, 0xb8, 0x11,
+	0x2b, 0xc7, 0x79, 0xf9, 0xa4, 0x35, 0x73, 0x53, 0xb9, 0xc6, 0x58, 0xaa, 0x2c, 0xf1, 0xcc, 0xe3,
+	0x82, 0x74, 0x9e, 0xa7, 0x49, 0x70, 0xfe, 0x00, 0x18, 0x0f, 0xe2, 0x28, 0xa9, 0xa7, 0x4f, 0x46,
+	0x71, 0x26, 0x53, 0xb4, 0xa0, 0x55, 0x07, 0xc6, 0x9b, 0x41, 0x92, 0xd4, 0x0b, 0x2f, 0x5a, 0xb7,
+	0xf8, 0x61, 0x05, 0x8d, 0x3b, 0x70, 0xab, 0x0d, 0x5c, 0x5c, 0x01, 0xea, 0x03, 0xc3, 0x13, 0x68,
+	0x60, 0x6b, 0x83, 0x54, 0x16, 0x2a, 0x4b, 0x43, 0xce, 0xc0, 0xd6, 0x06, 0x9e, 0x43, 0x47, 0xdb,
+	0x5c, 0x66, 0xdf, 0x02, 0x32, 0xb0, 0x50, 0x59, 0xaa, 0x3a, 0xd9, 0x3b, 0x3e, 0x87, 0xc6, 0x69,
+	0x5b, 0x34, 0x5d, 0x06, 0x07, 0x81, 0x0c, 0x4e, 0x06, 0xe5, 0xb0, 0xb5, 0xd1, 0x0f, 0xee, 0x92,
+	0xc1, 0x8b, 0xcb, 0x4f, 0x3b, 0x63, 0xd2, 0xeb, 0x18, 0x27, 0x7e, 0x18, 0x0d, 0xb3, 0x38, 0x04,
+	0x4e, 0x86, 0x16, 0x06, 0x97, 0xaa, 0xa9, 0xea, 0x92, 0xa3, 0xad, 0xcf, 0x8d, 0xbe, 0xa7, 0xde,
+	0x2f, 0x2c, 0x7e, 0x36, 0x87, 0x66, 0xb6, 0xcc, 0x8a, 0x39, 0x74, 0x5f, 0x98, 0xfc, 0xf0, 0x45,
+	0x34, 0xd2, 0x54, 0x39, 0x12, 0x7f, 0xa1, 0xb2, 0x54, 0x5b, 0x39, 0xb9, 0x5c, 0x5c, 0xc7, 0xe5,
+	0xd2, 0x34, 0x9c, 0x91, 0xa6, 0x7d, 0x3a, 0xa7, 0xd1, 0xc0, 0xc1, 0x8a, 0x9a, 0x48, 0x6d, 0xe5,
+	0x98, 0x15, 0xe0, 0x0c, 0x1c, 0xac, 0xe0, 0x0b, 0x68, 0x98, 0xd1, 0xa8, 0x01, 0x6a, 0x46, 0xb5,
+	0x95, 0xb9, 0x2e, 0xa5, 0x74, 0xa5, 0x72, 0x2d, 0xc4, 0x67, 0xd1, 0x60, 0xd2, 0x16, 0x64, 0x48,
+	0xe9, 0x49, 0x59, 0xbf, 0xd3, 0x4e, 0x27, 0xe1, 0x48, 0x11, 0x5e, 0x47, 0x63, 0x3e, 0x84, 0x20,
+	0xc0, 0xd5, 0x41, 0x86, 0xd5, 0xa0, 0x85, 0xf2, 0xa0, 0x0d, 0xa5, 0x28, 0x85, 0xaa, 0xf9, 0xb9,
+	0x4d, 0x06, 0x14, 0x9d, 0x88, 0x8c, 0xd8, 0x02, 0xee, 0x75, 0xa2, 0x2c, 0xa0, 0xe8, 0x44, 0xf8,
+	0x79, 0x84, 0xbc, 0xb8, 0x95, 0x50, 0x4f, 0xc8, 0x5d, 0x1a, 0x55, 0x43, 0x1e, 0x29, 0x0f, 0x59,
+	0xcf, 0xfc, 0xe9, 0xc8, 0xc2, 0x10, 0xfc, 0x02, 0xaa, 0x85, 0x40, 0x39, 0xb8, 0x0d, 0x46, 0x23,
+	0x41, 0x8e, 0xda, 0x08, 0xdb, 0x52, 0x70, 0x59, 0xfa, 0x33, 0x42, 0x98, 0x99, 0xe4, 0x9c, 0x35,
+	0x81, 0xc1, 0x41, 0x7c, 0x13, 0x48, 0xd5, 0x36, 0x67, 0x85, 0x70, 0x94, 0x20, 0x9b, 0x73, 0x98,
+	0xdb, 0xe4, 0xb6, 0xd0, 0x90, 0xb2, 0x16, 0x41, 0xb6, 0x6d, 0x59, 0x95, 0xae, 0x6c, 0x5b, 0x94,
+	0x10, 0x5f, 0x47, 0x53, 0x3a, 0xac, 0xd7, 0x04, 0xef, 0x66, 0x12, 0x07, 0x91, 0x20, 0x35, 0x35,
+	0xf8, 0x31, 0x4b, 0xe8, 0xf5, 0x4c, 0x64, 0x30, 0x69, 0x95, 0x3e, 0xe3, 0x4c, 0x86, 0x65, 0x01,
+	0xde, 0x46, 0x63, 0x09, 0x83, 0xfd, 0xa0, 0xe3, 0xde, 0x6a, 0xc7, 0x82, 0x92, 0x31, 0xdb, 0x84,
+	0x76, 0x94, 0xe2, 0x65, 0x29, 0xe8, 0x22, 0x5e, 0x72, 0x6a, 0x49, 0xee, 0x94, 0xb4, 0xf4, 0x14,
+	0xb9, 0x49, 0x10, 0x91, 0x71, 0x1b, 0x2d, 0x3d, 0x4a, 0x3b, 0x41, 0xd4, 0x4b, 0x63, 0xb9, 0x13,
+	0xbf, 0x8e, 0xa6, 0x0b, 0xdb, 0xe5, 0xd6, 0xa9, 0xf0, 0x9a, 0x64, 0xa2, 0xef, 0xb4, 0xd5, 0x0e,
+	0xad, 0x49, 0x51, 0x0f, 0x76, 0x32, 0x2c, 0x0b, 0xf0, 0x5b, 0x08, 0x17, 0xf7, 0xd1, 0xb0, 0x27,
+	0x15, 0xfb, 0x74, 0xdf, 0xdd, 0xb4, 0xc3, 0xa7, 0xc2, 0x2e, 0x85, 0x5c, 0x06, 0x4d, 0x87, 0x4e,
+	0x12, 0x30, 0x20, 0x53, 0xff, 0xaf, 0x4a, 0x0a, 0xcb, 0xa0, 0x86, 0x6f, 0xaa, 0xd1, 0xf8, 0x35,
+	0x74, 0xdc, 0x6b, 0xca, 0xc3, 0xe2, 0xee, 0x03, 0xf8, 0xc5, 0x12, 0x98, 0x3e, 0xfc, 0x98, 0xe6,
+	0xbc, 0x59, 0x0d, 0x78, 0x11, 0xc0, 0x2f, 0xec, 0xfd, 0x2a, 0xaa, 0xa9, 0x0f, 0x1f, 0x44, 0xb4,
+	0x1e, 0x02, 0xf9, 0xcb, 0x7a, 0xa2, 0x56, 0xdb, 0xa2, 0xb9, 0xa9, 0x04, 0xd9, 0x79, 0xa0, 0x99,
+	0x09, 0x6f, 0x20, 0xf5, 0x75, 0x74, 0xfd, 0x80, 0x2b, 0xc6, 0xdf, 0xa3, 0xb6, 0xa9, 0x4a, 0xc6,
+	0x46, 0xc0, 0x8b, 0x90, 0x1a, 0xcd, 0x6d, 0xf8, 0x25, 0x93, 0x08, 0x17, 0x54, 0xb4, 0x39, 0xf9,
+	0xb7, 0x6f, 0x22, 0xbb, 0x4a, 0xd0, 0x35, 0xbd, 0x67, 0x75, 0x46, 0xda, 0x87, 0xaf, 0xe9, 0x8c,
+	0x20, 0x12, 0x81, 0x47, 0x05, 0x90, 0x7f, 0x34, 0xec, 0x89, 0x32, 0x2c, 0xfd, 0x32, 0xaf, 0x16,
+	0xa4, 0x69, 0x6a, 0xa5, 0xf1, 0x78, 0xd3, 0x74, 0x87, 0x36, 0x07, 0xe6, 0x52, 0xdf, 0x27, 0x3f,
+	0x1c, 0xed, 0x37, 0xc5, 0x57, 0x38, 0xb0, 0x55, 0xdf, 0x2f, 0x4d, 0xd1, 0xd8, 0xf0, 0x35, 0x34,
+	0x95, 0x63, 0xf4, 0x07, 0x90, 0xfc, 0xa8, 0x49, 0x8f, 0xda, 0x49, 0xe6, 0xcb, 0x69, 0x60, 0x13,
+	0xb4, 0x64, 0x2e, 0xa7, 0xd5, 0x00, 0x41, 0x7e, 0x3a, 0x34, 0xad, 0xcb, 0x20, 0x7a, 0xd2, 0xba,
+	0x0c, 0x02, 0x37, 0xd0, 0x43, 0x39, 0xc6, 0x54, 0x59, 0x42, 0x39, 0xbf, 0x1d, 0x33, 0x9f, 0xfc,
+	0xac, 0x91, 0x4f, 0xda, 0x91, 0xeb, 0x4a, 0xbd, 0x63, 0xc4, 0x29, 0xfd, 0x38, 0xb5, 0xba, 0xf1,
+	0x75, 0x34, 0x5b, 0xc8, 0x57, 0x9d, 0x67, 0xd9, 0x30, 0xc9, 0x7d, 0x1d, 0xe3, 0x4c, 0x9f, 0xb4,
+	0xa5, 0xd0, 0x89, 0xf3, 0xb2, 0x99, 0xa6, 0xdd, 0x1e, 0xfc, 0x26, 0x3a, 0x96, 0x93, 0xcd, 0x71,
+	0x56, 0xe8, 0x5f, 0x34, 0xfa, 0x71, 0x3b, 0xda, 0x9c, 0xbc, 0x02, 0x1b, 0xd3, 0x1e, 0x17, 0xbe,
+	0x82, 0x26, 0x72, 0x78, 0x18, 0x70, 0x41, 0x7e, 0xd5, 0xd4, 0x53, 0x76, 0xea, 0x76, 0xc0, 0x45,
+	0xa9, 0x8e, 0x52, 0x63, 0x46, 0x92, 0xa9, 0x69, 0xd2, 0x6f, 0x7d, 0x49, 0x32, 0x74, 0x0f, 0x29,
+	0x35, 0x66, 0x5b, 0xaf, 0x48, 0xb2, 0x22, 0x3f, 0xaf, 0xf6, 0xdb, 0x7a, 0x39, 0xa6, 0xbb, 0x22,
+	0x8d, 0x2d, 0xab, 0x48, 0x85, 0x31, 0x15, 0xf9, 0x45, 0xb5, 0x5f, 0x45, 0xca, 0x51, 0x96, 0x8a,
+	0xcc, 0xcd, 0xe5, 0xb4, 0x64, 0x45, 0x7e, 0x79, 0x68, 0x5a, 0xdd, 0x15, 0x69, 0x6c, 0xf8, 0x06,
+	0x9a, 0x2b, 0x60, 0x54, 0xa1, 0x24, 0xc0, 0x5a, 0x01, 0x57, 0xbf, 0x66, 0x5f, 0x69, 0xe6, 0xb9,
+	0x3e, 0x4c, 0x29, 0xdf, 0xc9, 0xd4, 0x29, 0xff, 0x04, 0xb5, 0xfb, 0x71, 0x0b, 0x9d, 0xcc, 0x63,
+	0x99, 0xd2, 0x29, 0x04, 0xfb, 0x5a, 0x07, 0x7b, 0xca, 0x1e, 0x4c, 0x57, 0x49, 0x6f, 0x34, 0x42,
+	0xfb, 0x08, 0x30, 0x2b, 0x86, 0xe3, 0x20, 0xdc, 0x16, 0xed, 0xb8, 0xba, 0x51, 0x08, 0x11, 0x92,
+	0xbb, 0xd5, 0x7e, 0xc7, 0x4d, 0xd2, 0x76, 0x41, 0x5c, 0xa5, 0x1d, 0xd5, 0x34, 0xf6, 0xf6, 0xb6,
+	0x7b, 0xbe, 0xf0, 0xc7, 0xa9, 0x45, 0x27, 0x42, 0xfc, 0x0e, 0x9a, 0x29, 0xc7, 0xd4, 0x6d, 0xfe,
+	0x9b, 0xaa, 0xad, 0xd5, 0x15, 0x62, 0xd9, 0x9b, 0xfd, 0x14, 0xed, 0x52, 0x60, 0x6a, 0xce, 0xb5,
+	0x44, 0xd7, 0x3d, 0x76, 0x27, 0x11, 0xae, 0x17, 0x73, 0x41, 0xbe, 0xad, 0xf6, 0x3b, 0xd7, 0xbb,
+	0x20, 0xd6, 0x94, 0x70, 0x3d, 0xe6, 0xbd, 0x9d, 0x6a, 0x9a, 0x76, 0x4b, 0xf0, 0x1e, 0x9a, 0x54,
+	0x21, 0x44, 0x7c, 0x13, 0x22, 0x7d, 0x74, 0xbe, 0xd3, 0xf4, 0xc5, 0x5e, 0xfa, 0x9e, 0x14, 0x6d,
+	0x07, 0x16, 0xf2, 0x38, 0x2d, 0xba, 0xf1, 0x1b, 0x68, 0xba, 0x40, 0x35, 0xbf, 0x73, 0xdf, 0x57,
+	0x6d, 0x7f, 0x17, 0x19, 0xb7, 0x4f, 0xb7, 0x9e, 0xa4, 0x65, 0x01, 0x7e, 0x17, 0xcd, 0x78, 0x61,
+	0x9b, 0x0b, 0x60, 0xae, 0xb9, 0xd0, 0xc8, 0xf5, 0x21, 0x1f, 0x21, 0xb3, 0x26, 0xc5, 0xdb, 0xcc,
+	0xf2, 0xba, 0x56, 0xbe, 0xaa, 0x85, 0xbb, 0x20, 0x7a, 0xda, 0xdb, 0xb4, 0xd7, 0x2d, 0xc1, 0x37,
+	0xd0, 0x89, 0x34, 0x82, 0x86, 0xb9, 0x54, 0x08, 0xa6, 0xa2, 0x7c, 0x8c, 0x4c, 0xc3, 0xb3, 0x45,
+	0xb9, 0xaa, 0x6c, 0xab, 0x42, 0x30, 0x5b, 0xa0, 0x59, 0xcf, 0xa2, 0xc2, 0x6f, 0x23, 0xec, 0xc7,
+	0xb7, 0xa3, 0x06, 0xa3, 0x3e, 0xb8, 0x41, 0xb4, 0x1f, 0xab, 0x30, 0x9f, 0x20, 0x53, 0x41, 0xa5,
+	0x30, 0x1b, 0xa9, 0x70, 0x2b, 0xda, 0x8f, 0x6d, 0x21, 0xa6, 0xfc, 0x2e, 0x45, 0x7e, 0x63, 0x9a,
+	0x44, 0xe3, 0x9b, 0xad, 0x44, 0xdc, 0x71, 0x80, 0x27, 0x71, 0xc4, 0x61, 0xf1, 0xfd, 0x01, 0x74,
+	0xf2, 0x90, 0x46, 0x8d, 0x31, 0x1a, 0x52, 0x17, 0xba, 0x8a, 0xba, 0xd0, 0xa9, 0x67, 0x79, 0xd1,
+	0xcb, 0xfa, 0x97, 0xb9, 0xe8, 0xa5, 0xef, 0xf8, 0x14, 0x1a, 0xe3, 0x41, 0x2b, 0x09, 0x41, 0x6f,
+	0xba, 0xba, 0x15, 0x55, 0x9d, 0x9a, 0xb6, 0xa9, 0xfd, 0xc3, 0x17, 0xd0, 0x64, 0x93, 0xf2, 0x26,
+	0xf8, 0x79, 0x17, 0x94, 0x77, 0xa1, 0xc2, 0x3d, 0x6f, 0x42, 0xfb, 0xb3, 0xc6, 0xd6, 0x73, 0x7b,
+	0x1c, 0x2e, 0xde, 0x1e, 0x2f, 0x75, 0xdd, 0x1e, 0xcf, 0xa2, 0xb1, 0x04, 0xf4, 0x8f, 0x04, 0x03,
+	0xce, 0xc9, 0x48, 0x19, 0x5e, 0x93, 0xce, 0x55, 0xed, 0xcb, 0x16, 0x66, 0x6d, 0xf6, 0xde, 0x1f,
+	0xf3, 0x47, 0xee, 0x3d, 0x98, 0xaf, 0xdc, 0x7f, 0x30, 0x5f, 0xf9, 0xfd, 0xc1, 0x7c, 0xe5, 0xd3,
+	0x3f, 0xe7, 0x8f, 0xd4, 0x47, 0xd4, 0xdd, 0xf7, 0xe2, 0x7f, 0x03, 0x00, 0xa0, 0xd0, 0xca, 0xa3,
+	0x9d, 0x0f, 0x00, 0x00,
 }
 
 func (m *RequestHeader) Marshal() (dAtA []byte, err error) {
@@ -331,6 +421,15 @@ func (m *RequestHeader) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Roles) > 0 {
+		for iNdEx := len(m.Roles) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Roles[iNdEx])
+			copy(dAtA[i:], m.Roles[iNdEx])
+			i = encodeVarintRaftInternal(dAtA, i, uint64(len(m.Roles[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.AuthRevision != 0 {
 		i = encodeVarintRaftInternal(dAtA, i, uint64(m.AuthRevision))
 		i--
@@ -417,6 +516,76 @@ func (m *InternalRaftRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0xa2
 	}
+	if m.AuthTokenRevoke != nil {
+		{
+			size, err := m.AuthTokenRevoke.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4b
+		i--
+		dAtA[i] = 0xca
+	}
+	if m.AuthTokenList != nil {
+		{
+			size, err := m.AuthTokenList.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4b
+		i--
+		dAtA[i] = 0xc2
+	}
+	if m.AuthSetBcryptCost != nil {
+		{
+			size, err := m.AuthSetBcryptCost.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4b
+		i--
+		dAtA[i] = 0xba
+	}
+	if m.AuthRoleSetQuota != nil {
+		{
+			size, err := m.AuthRoleSetQuota.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4b
+		i--
+		dAtA[i] = 0xb2
+	}
+	if m.AuthRoleSetMaxLeaseTTL != nil {
+		{
+			size, err := m.AuthRoleSetMaxLeaseTTL.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x4b
+		i--
+		dAtA[i] = 0xaa
+	}
 	if m.AuthRoleRevokePermission != nil {
 		{
 			size, err := m.AuthRoleRevokePermission.MarshalToSizedBuffer(dAtA[:i])
@@ -669,6 +838,82 @@ func (m *InternalRaftRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0xa2
 	}
+	if m.ChangeFeedCheckpoint != nil {
+		{
+			size, err := m.ChangeFeedCheckpoint.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x8a
+	}
+	if m.LeaseExpire != nil {
+		{
+			size, err := m.LeaseExpire.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if m.LeaseRevokeBatch != nil {
+		{
+			size, err := m.LeaseRevokeBatch.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x7a
+	}
+	if m.LeaseGrantBatch != nil {
+		{
+			size, err := m.LeaseGrantBatch.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x72
+	}
+	if m.RevisionPin != nil {
+		{
+			size, err := m.RevisionPin.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x6a
+	}
+	if m.PrefixQuota != nil {
+		{
+			size, err := m.PrefixQuota.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x62
+	}
 	if m.LeaseCheckpoint != nil {
 		{
 			size, err := m.LeaseCheckpoint.MarshalToSizedBuffer(dAtA[:i])
@@ -848,6 +1093,25 @@ func (m *InternalAuthenticateRequest) MarshalToSizedBuffer(dAtA []byte) (int, er
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.PeerAddress) > 0 {
+		i -= len(m.PeerAddress)
+		copy(dAtA[i:], m.PeerAddress)
+		i = encodeVarintRaftInternal(dAtA, i, uint64(len(m.PeerAddress)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.AuthRevision != 0 {
+		i = encodeVarintRaftInternal(dAtA, i, uint64(m.AuthRevision))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.HashedPassword) > 0 {
+		i -= len(m.HashedPassword)
+		copy(dAtA[i:], m.HashedPassword)
+		i = encodeVarintRaftInternal(dAtA, i, uint64(len(m.HashedPassword)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.SimpleToken) > 0 {
 		i -= len(m.SimpleToken)
 		copy(dAtA[i:], m.SimpleToken)
@@ -899,6 +1163,12 @@ func (m *RequestHeader) Size() (n int) {
 	if m.AuthRevision != 0 {
 		n += 1 + sovRaftInternal(uint64(m.AuthRevision))
 	}
+	if len(m.Roles) > 0 {
+		for _, s := range m.Roles {
+			l = len(s)
+			n += 1 + l + sovRaftInternal(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -954,6 +1224,30 @@ func (m *InternalRaftRequest) Size() (n int) {
 		l = m.LeaseCheckpoint.Size()
 		n += 1 + l + sovRaftInternal(uint64(l))
 	}
+	if m.PrefixQuota != nil {
+		l = m.PrefixQuota.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.RevisionPin != nil {
+		l = m.RevisionPin.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.LeaseGrantBatch != nil {
+		l = m.LeaseGrantBatch.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.LeaseRevokeBatch != nil {
+		l = m.LeaseRevokeBatch.Size()
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.LeaseExpire != nil {
+		l = m.LeaseExpire.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.ChangeFeedCheckpoint != nil {
+		l = m.ChangeFeedCheckpoint.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
 	if m.Header != nil {
 		l = m.Header.Size()
 		n += 2 + l + sovRaftInternal(uint64(l))
@@ -1026,6 +1320,26 @@ func (m *InternalRaftRequest) Size() (n int) {
 		l = m.AuthRoleRevokePermission.Size()
 		n += 2 + l + sovRaftInternal(uint64(l))
 	}
+	if m.AuthRoleSetMaxLeaseTTL != nil {
+		l = m.AuthRoleSetMaxLeaseTTL.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthRoleSetQuota != nil {
+		l = m.AuthRoleSetQuota.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthSetBcryptCost != nil {
+		l = m.AuthSetBcryptCost.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthTokenList != nil {
+		l = m.AuthTokenList.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthTokenRevoke != nil {
+		l = m.AuthTokenRevoke.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
 	if m.ClusterVersionSet != nil {
 		l = m.ClusterVersionSet.Size()
 		n += 2 + l + sovRaftInternal(uint64(l))
@@ -1074,6 +1388,17 @@ func (m *InternalAuthenticateRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRaftInternal(uint64(l))
 	}
+	l = len(m.HashedPassword)
+	if l > 0 {
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthRevision != 0 {
+		n += 1 + sovRaftInternal(uint64(m.AuthRevision))
+	}
+	l = len(m.PeerAddress)
+	if l > 0 {
+		n += 1 + l + sovRaftInternal(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1185,6 +1510,38 @@ func (m *RequestHeader) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Roles", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Roles = append(m.Roles, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaftInternal(dAtA[iNdEx:])
@@ -1615,9 +1972,9 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		case 100:
+		case 12:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field PrefixQuota", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1644,16 +2001,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Header == nil {
-				m.Header = &RequestHeader{}
+			if m.PrefixQuota == nil {
+				m.PrefixQuota = &PrefixQuotaRequest{}
 			}
-			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.PrefixQuota.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1000:
+		case 13:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AuthEnable", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field RevisionPin", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1680,16 +2037,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.AuthEnable == nil {
-				m.AuthEnable = &AuthEnableRequest{}
+			if m.RevisionPin == nil {
+				m.RevisionPin = &RevisionPinRequest{}
 			}
-			if err := m.AuthEnable.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.RevisionPin.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1011:
+		case 14:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AuthDisable", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseGrantBatch", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1716,16 +2073,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.AuthDisable == nil {
-				m.AuthDisable = &AuthDisableRequest{}
+			if m.LeaseGrantBatch == nil {
+				m.LeaseGrantBatch = &LeaseGrantBatchRequest{}
 			}
-			if err := m.AuthDisable.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.LeaseGrantBatch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1012:
+		case 15:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Authenticate", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseRevokeBatch", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1752,16 +2109,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Authenticate == nil {
-				m.Authenticate = &InternalAuthenticateRequest{}
+			if m.LeaseRevokeBatch == nil {
+				m.LeaseRevokeBatch = &LeaseRevokeBatchRequest{}
 			}
-			if err := m.Authenticate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.LeaseRevokeBatch.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1013:
+		case 16:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AuthStatus", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseExpire", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1788,16 +2145,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.AuthStatus == nil {
-				m.AuthStatus = &AuthStatusRequest{}
+			if m.LeaseExpire == nil {
+				m.LeaseExpire = &LeaseRevokeRequest{}
 			}
-			if err := m.AuthStatus.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.LeaseExpire.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1100:
+		case 17:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AuthUserAdd", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ChangeFeedCheckpoint", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1824,16 +2181,16 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.AuthUserAdd == nil {
-				m.AuthUserAdd = &AuthUserAddRequest{}
+			if m.ChangeFeedCheckpoint == nil {
+				m.ChangeFeedCheckpoint = &PutRequest{}
 			}
-			if err := m.AuthUserAdd.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.ChangeFeedCheckpoint.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 1101:
+		case 100:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field AuthUserDelete", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1860,10 +2217,226 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.AuthUserDelete == nil {
-				m.AuthUserDelete = &AuthUserDeleteRequest{}
+			if m.Header == nil {
+				m.Header = &RequestHeader{}
 			}
-			if err := m.AuthUserDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1000:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthEnable", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthEnable == nil {
+				m.AuthEnable = &AuthEnableRequest{}
+			}
+			if err := m.AuthEnable.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1011:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthDisable", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthDisable == nil {
+				m.AuthDisable = &AuthDisableRequest{}
+			}
+			if err := m.AuthDisable.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1012:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Authenticate", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Authenticate == nil {
+				m.Authenticate = &InternalAuthenticateRequest{}
+			}
+			if err := m.Authenticate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1013:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthStatus", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthStatus == nil {
+				m.AuthStatus = &AuthStatusRequest{}
+			}
+			if err := m.AuthStatus.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1100:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthUserAdd", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthUserAdd == nil {
+				m.AuthUserAdd = &AuthUserAddRequest{}
+			}
+			if err := m.AuthUserAdd.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1101:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthUserDelete", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthUserDelete == nil {
+				m.AuthUserDelete = &AuthUserDeleteRequest{}
+			}
+			if err := m.AuthUserDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -2263,6 +2836,186 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 1205:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthRoleSetMaxLeaseTTL", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthRoleSetMaxLeaseTTL == nil {
+				m.AuthRoleSetMaxLeaseTTL = &AuthRoleSetMaxLeaseTTLRequest{}
+			}
+			if err := m.AuthRoleSetMaxLeaseTTL.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1206:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthRoleSetQuota", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthRoleSetQuota == nil {
+				m.AuthRoleSetQuota = &AuthRoleSetQuotaRequest{}
+			}
+			if err := m.AuthRoleSetQuota.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1207:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthSetBcryptCost", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthSetBcryptCost == nil {
+				m.AuthSetBcryptCost = &AuthSetBcryptCostRequest{}
+			}
+			if err := m.AuthSetBcryptCost.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1208:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthTokenList", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthTokenList == nil {
+				m.AuthTokenList = &AuthTokenListRequest{}
+			}
+			if err := m.AuthTokenList.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1209:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthTokenRevoke", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthTokenRevoke == nil {
+				m.AuthTokenRevoke = &AuthTokenRevokeRequest{}
+			}
+			if err := m.AuthTokenRevoke.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 1300:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field ClusterVersionSet", wireType)
@@ -2569,6 +3322,89 @@ func (m *InternalAuthenticateRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.SimpleToken = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HashedPassword", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HashedPassword = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthRevision", wireType)
+			}
+			m.AuthRevision = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AuthRevision |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerAddress", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerAddress = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaftInternal(dAtA[iNdEx:])
@@ -40,6 +40,11 @@ func (as *InternalRaftStringer) String() string {
 			as.Request.Header.String(),
 			as.Request.LeaseRevoke.ID,
 		)
+	case as.Request.LeaseExpire != nil:
+		return fmt.Sprintf("header:<%s> lease_expire:<id:%016x>",
+			as.Request.Header.String(),
+			as.Request.LeaseExpire.ID,
+		)
 	case as.Request.Authenticate != nil:
 		return fmt.Sprintf("header:<%s> authenticate:<name:%s simple_token:%s>",
 			as.Request.Header.String(),
@@ -165,6 +170,7 @@ type loggablePutRequest struct {
 	PrevKv      bool   `protobuf:"varint,4,opt,name=prev_kv,proto3"`
 	IgnoreValue bool   `protobuf:"varint,5,opt,name=ignore_value,proto3"`
 	IgnoreLease bool   `protobuf:"varint,6,opt,name=ignore_lease,proto3"`
+	TtlSeconds  int64  `protobuf:"varint,7,opt,name=ttl_seconds,proto3"`
 }
 
 func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
@@ -175,6 +181,7 @@ func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
 		request.PrevKv,
 		request.IgnoreValue,
 		request.IgnoreLease,
+		request.TtlSeconds,
 	}
 }
 
@@ -116,10 +116,14 @@ type Event struct {
 	// its modification revision set to the revision of deletion.
 	Kv *KeyValue `protobuf:"bytes,2,opt,name=kv,proto3" json:"kv,omitempty"`
 	// prev_kv holds the key-value pair before the event happens.
-	PrevKv               *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	PrevKv *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	// coalesced is set if the event replaces the events of the key since
+	// the previous response of a watcher coalescing them. Its prev_kv is
+	// the key-value pair before the first replaced event.
+	Coalesced            bool     `protobuf:"varint,4,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -164,26 +168,27 @@ func init() {
 func init() { proto.RegisterFile("kv.proto", fileDescriptor_2216fe83c9c12408) }
 
 var fileDescriptor_2216fe83c9c12408 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x41, 0x4e, 0xc2, 0x40,
-	0x14, 0x86, 0x3b, 0x14, 0x0a, 0x3e, 0x08, 0x36, 0x13, 0x12, 0x27, 0x2e, 0x26, 0x95, 0x8d, 0x18,
-	0x13, 0x4c, 0xf0, 0x06, 0xc6, 0xae, 0x70, 0x61, 0x1a, 0x74, 0x4b, 0x4a, 0x79, 0x21, 0xa4, 0x94,
-	0x69, 0x4a, 0x9d, 0xa4, 0x37, 0x71, 0xef, 0xde, 0x73, 0xb0, 0xe4, 0x08, 0x52, 0x2f, 0x62, 0xfa,
-	0xc6, 0xe2, 0xc6, 0xcd, 0xe4, 0xfd, 0xff, 0xff, 0x65, 0xe6, 0x7f, 0x03, 0x9d, 0x58, 0x8f, 0xd3,
-	0x4c, 0xe5, 0x8a, 0x3b, 0x89, 0x8e, 0xa2, 0x74, 0x71, 0x39, 0x58, 0xa9, 0x95, 0x22, 0xeb, 0xae,
-	0x9a, 0x4c, 0x3a, 0xfc, 0x64, 0xd0, 0x99, 0x62, 0xf1, 0x1a, 0x6e, 0xde, 0x90, 0xbb, 0x60, 0xc7,
-	0x58, 0x08, 0xe6, 0xb1, 0x51, 0x2f, 0xa8, 0x46, 0x7e, 0x0d, 0xe7, 0x51, 0x86, 0x61, 0x8e, 0xf3,
-	0x0c, 0xf5, 0x7a, 0xb7, 0x56, 0x5b, 0xd1, 0xf0, 0xd8, 0xc8, 0x0e, 0xfa, 0xc6, 0x0e, 0x7e, 0x5d,
-	0x7e, 0x05, 0xbd, 0x44, 0x2d, 0xff, 0x28, 0x9b, 0xa8, 0x6e, 0xa2, 0x96, 0x27, 0x44, 0x40, 0x5b,
-	0x63, 0x46, 0x69, 0x93, 0xd2, 0x5a, 0xf2, 0x01, 0xb4, 0x74, 0x55, 0x40, 0xb4, 0xe8, 0x65, 0x23,
-	0x2a, 0x77, 0x83, 0xe1, 0x0e, 0x85, 0x43, 0xb4, 0x11, 0xc3, 0x0f, 0x06, 0x2d, 0x5f, 0xe3, 0x36,
-	0xe7, 0xb7, 0xd0, 0xcc, 0x8b, 0x14, 0xa9, 0x6e, 0x7f, 0x72, 0x31, 0x36, 0x7b, 0x8e, 0x29, 0x34,
-	0xe7, 0xac, 0x48, 0x31, 0x20, 0x88, 0x7b, 0xd0, 0x88, 0x35, 0x75, 0xef, 0x4e, 0xdc, 0x1a, 0xad,
-	0x17, 0x0f, 0x1a, 0xb1, 0xe6, 0x37, 0xd0, 0x4e, 0x33, 0xd4, 0xf3, 0x58, 0x53, 0xf9, 0xff, 0x30,
-	0xa7, 0x02, 0xa6, 0x7a, 0xe8, 0xc1, 0xd9, 0xe9, 0x7e, 0xde, 0x06, 0xfb, 0xf9, 0x65, 0xe6, 0x5a,
-	0x1c, 0xc0, 0x79, 0xf4, 0x9f, 0xfc, 0x99, 0xef, 0xb2, 0x07, 0xb1, 0x3f, 0x4a, 0xeb, 0x70, 0x94,
-	0xd6, 0xbe, 0x94, 0xec, 0x50, 0x4a, 0xf6, 0x55, 0x4a, 0xf6, 0xfe, 0x2d, 0xad, 0x85, 0x43, 0xff,
-	0x7e, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x45, 0x92, 0x5d, 0xa1, 0x01, 0x00, 0x00,
+	// 316 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0x6a, 0xf2, 0x40,
+	0x14, 0x85, 0x33, 0x46, 0xa3, 0x5e, 0xc5, 0x3f, 0x0c, 0xc2, 0x3f, 0x94, 0x32, 0xa4, 0x6e, 0x6a,
+	0x29, 0x58, 0xb0, 0x6f, 0x50, 0x9a, 0x95, 0x5d, 0x94, 0xc1, 0x76, 0x2b, 0x31, 0x5e, 0x44, 0xa2,
+	0x4e, 0x88, 0xe9, 0x40, 0xde, 0xa4, 0x4f, 0xd1, 0x77, 0xe8, 0xce, 0xa5, 0x8f, 0x50, 0xed, 0x8b,
+	0x94, 0xdc, 0xa9, 0xba, 0xea, 0x66, 0xb8, 0xf7, 0x9c, 0x0f, 0xee, 0x39, 0x0c, 0x34, 0x12, 0x33,
+	0x48, 0x33, 0x9d, 0x6b, 0xee, 0xad, 0x4c, 0x1c, 0xa7, 0xd3, 0x8b, 0xee, 0x5c, 0xcf, 0x35, 0x49,
+	0x77, 0xe5, 0x64, 0xdd, 0xde, 0x07, 0x83, 0xc6, 0x08, 0x8b, 0xd7, 0x68, 0xf9, 0x86, 0xdc, 0x07,
+	0x37, 0xc1, 0x42, 0xb0, 0x80, 0xf5, 0xdb, 0xaa, 0x1c, 0xf9, 0x35, 0xfc, 0x8b, 0x33, 0x8c, 0x72,
+	0x9c, 0x64, 0x68, 0x16, 0x9b, 0x85, 0x5e, 0x8b, 0x4a, 0xc0, 0xfa, 0xae, 0xea, 0x58, 0x59, 0xfd,
+	0xaa, 0xfc, 0x0a, 0xda, 0x2b, 0x3d, 0x3b, 0x53, 0x2e, 0x51, 0xad, 0x95, 0x9e, 0x9d, 0x10, 0x01,
+	0x75, 0x83, 0x19, 0xb9, 0x55, 0x72, 0x8f, 0x2b, 0xef, 0x42, 0xcd, 0x94, 0x01, 0x44, 0x8d, 0x2e,
+	0xdb, 0xa5, 0x54, 0x97, 0x18, 0x6d, 0x50, 0x78, 0x44, 0xdb, 0xa5, 0xf7, 0xc9, 0xa0, 0x16, 0x1a,
+	0x5c, 0xe7, 0xfc, 0x16, 0xaa, 0x79, 0x91, 0x22, 0xc5, 0xed, 0x0c, 0xff, 0x0f, 0x6c, 0xcf, 0x01,
+	0x99, 0xf6, 0x1d, 0x17, 0x29, 0x2a, 0x82, 0x78, 0x00, 0x95, 0xc4, 0x50, 0xf6, 0xd6, 0xd0, 0x3f,
+	0xa2, 0xc7, 0xe2, 0xaa, 0x92, 0x18, 0x7e, 0x03, 0xf5, 0x34, 0x43, 0x33, 0x49, 0x8c, 0x70, 0xff,
+	0xc0, 0xbc, 0x12, 0x18, 0x19, 0x7e, 0x09, 0xcd, 0x58, 0x47, 0x4b, 0xdc, 0xc4, 0x38, 0xa3, 0x2e,
+	0x0d, 0x75, 0x16, 0x7a, 0x01, 0x34, 0x4f, 0xd7, 0x79, 0x1d, 0xdc, 0xe7, 0x97, 0xb1, 0xef, 0x70,
+	0x00, 0xef, 0x31, 0x7c, 0x0a, 0xc7, 0xa1, 0xcf, 0x1e, 0xc4, 0x76, 0x2f, 0x9d, 0xdd, 0x5e, 0x3a,
+	0xdb, 0x83, 0x64, 0xbb, 0x83, 0x64, 0x5f, 0x07, 0xc9, 0xde, 0xbf, 0xa5, 0x33, 0xf5, 0xe8, 0x57,
+	0xee, 0x7f, 0x06, 0x00, 0xff, 0xf9, 0x4e, 0x90, 0xbf, 0x01, 0x00, 0x00,
 }
 
 func (m *KeyValue) Marshal() (dAtA []byte, err error) {
@@ -271,6 +276,16 @@ func (m *Event) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Coalesced {
+		i--
+		if m.Coalesced {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.PrevKv != nil {
 		{
 			size, err := m.PrevKv.MarshalToSizedBuffer(dAtA[:i])
@@ -363,6 +378,9 @@ func (m *Event) Size() (n int) {
 		l = m.PrevKv.Size()
 		n += 1 + l + sovKv(uint64(l))
 	}
+	if m.Coalesced {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -690,6 +708,26 @@ func (m *Event) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Coalesced", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Coalesced = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipKv(dAtA[iNdEx:])
@@ -25,19 +25,42 @@ var (
 	ErrGRPCKeyNotFound             = status.Error(codes.InvalidArgument, "etcdserver: key not found")
 	ErrGRPCValueProvided           = status.Error(codes.InvalidArgument, "etcdserver: value is provided")
 	ErrGRPCLeaseProvided           = status.Error(codes.InvalidArgument, "etcdserver: lease is provided")
+	ErrGRPCNegativeKeyTTL          = status.Error(codes.InvalidArgument, "etcdserver: key TTL is negative")
 	ErrGRPCTooManyOps              = status.Error(codes.InvalidArgument, "etcdserver: too many operations in txn request")
 	ErrGRPCDuplicateKey            = status.Error(codes.InvalidArgument, "etcdserver: duplicate key given in txn request")
 	ErrGRPCInvalidClientAPIVersion = status.Error(codes.InvalidArgument, "etcdserver: invalid client api version")
 	ErrGRPCInvalidSortOption       = status.Error(codes.InvalidArgument, "etcdserver: invalid sort option")
+	ErrGRPCEstimateRevision        = status.Error(codes.InvalidArgument, "etcdserver: estimates are only available at the current revision")
 	ErrGRPCCompacted               = status.Error(codes.OutOfRange, "etcdserver: mvcc: required revision has been compacted")
 	ErrGRPCFutureRev               = status.Error(codes.OutOfRange, "etcdserver: mvcc: required revision is a future revision")
 	ErrGRPCNoSpace                 = status.Error(codes.ResourceExhausted, "etcdserver: mvcc: database space exceeded")
 
-	ErrGRPCLeaseNotFound    = status.Error(codes.NotFound, "etcdserver: requested lease not found")
-	ErrGRPCLeaseExist       = status.Error(codes.FailedPrecondition, "etcdserver: lease already exists")
-	ErrGRPCLeaseTTLTooLarge = status.Error(codes.OutOfRange, "etcdserver: too large lease TTL")
+	ErrGRPCPrefixQuotaExceeded = status.Error(codes.ResourceExhausted, "etcdserver: prefix quota exceeded")
+	ErrGRPCInvalidPrefixQuota  = status.Error(codes.InvalidArgument, "etcdserver: invalid prefix quota")
 
-	ErrGRPCWatchCanceled = status.Error(codes.Canceled, "etcdserver: watch canceled")
+	ErrGRPCRevisionPinned     = status.Error(codes.FailedPrecondition, "etcdserver: revision is pinned")
+	ErrGRPCInvalidRevisionPin = status.Error(codes.InvalidArgument, "etcdserver: invalid revision pin")
+
+	ErrGRPCIndexNotFound = status.Error(codes.NotFound, "etcdserver: mvcc: secondary index not found")
+	ErrGRPCEmptyField    = status.Error(codes.InvalidArgument, "etcdserver: field is not provided")
+
+	ErrGRPCValueUploadNotFound = status.Error(codes.NotFound, "etcdserver: mvcc: value upload not found")
+	ErrGRPCValuePartInTxn      = status.Error(codes.InvalidArgument, "etcdserver: value parts cannot be put in txn requests")
+
+	ErrGRPCLeaseNotFound       = status.Error(codes.NotFound, "etcdserver: requested lease not found")
+	ErrGRPCLeaseExist          = status.Error(codes.FailedPrecondition, "etcdserver: lease already exists")
+	ErrGRPCLeaseTTLTooLarge    = status.Error(codes.OutOfRange, "etcdserver: too large lease TTL")
+	ErrGRPCLeaseWatchTooSlow   = status.Error(codes.Aborted, "etcdserver: lease expiration watcher is too slow, expirations were dropped")
+	ErrGRPCParentLeaseNotFound = status.Error(codes.NotFound, "etcdserver: parent lease not found")
+	ErrGRPCInvalidLeaseLabels  = status.Error(codes.InvalidArgument, "etcdserver: invalid lease labels")
+	ErrGRPCLeaseTooManyKeys    = status.Error(codes.ResourceExhausted, "etcdserver: too many keys attached to lease")
+
+	ErrGRPCWatchCanceled            = status.Error(codes.Canceled, "etcdserver: watch canceled")
+	ErrGRPCWatchStartRevisionTooOld = status.Error(codes.FailedPrecondition, "etcdserver: watch start revision is too far behind the current revision, re-list and watch from the revision of the response header")
+	ErrGRPCInvalidWatchValueFilter  = status.Error(codes.InvalidArgument, "etcdserver: invalid watch value filter")
+	ErrGRPCInvalidWatchResumeToken  = status.Error(codes.InvalidArgument, "etcdserver: invalid watch resume token")
+	ErrGRPCInvalidWatchProjection   = status.Error(codes.InvalidArgument, "etcdserver: invalid watch projection")
+	ErrGRPCWatchOverflow            = status.Error(codes.ResourceExhausted, "etcdserver: watcher could not keep up with the delivery rate limit")
 
 	ErrGRPCMemberExist            = status.Error(codes.FailedPrecondition, "etcdserver: member ID already exist")
 	ErrGRPCPeerURLExist           = status.Error(codes.FailedPrecondition, "etcdserver: Peer URLs already exists")
@@ -47,6 +70,8 @@ var (
 	ErrGRPCMemberNotLearner       = status.Error(codes.FailedPrecondition, "etcdserver: can only promote a learner member")
 	ErrGRPCLearnerNotReady        = status.Error(codes.FailedPrecondition, "etcdserver: can only promote a learner member which is in sync with leader")
 	ErrGRPCTooManyLearners        = status.Error(codes.FailedPrecondition, "etcdserver: too many learner members in cluster")
+	ErrGRPCMemberObserver         = status.Error(codes.FailedPrecondition, "etcdserver: cannot promote an observer member")
+	ErrGRPCMemberActive           = status.Error(codes.FailedPrecondition, "etcdserver: can only replace a stopped member")
 
 	ErrGRPCRequestTooLarge        = status.Error(codes.InvalidArgument, "etcdserver: request is too large")
 	ErrGRPCRequestTooManyRequests = status.Error(codes.ResourceExhausted, "etcdserver: too many requests")
@@ -68,19 +93,30 @@ var (
 	ErrGRPCInvalidAuthToken     = status.Error(codes.Unauthenticated, "etcdserver: invalid auth token")
 	ErrGRPCInvalidAuthMgmt      = status.Error(codes.InvalidArgument, "etcdserver: invalid auth management")
 	ErrGRPCAuthOldRevision      = status.Error(codes.InvalidArgument, "etcdserver: revision of auth store is old")
+	ErrGRPCRoleLeaseTTLTooLarge = status.Error(codes.PermissionDenied, "etcdserver: lease TTL exceeds the maximum lease TTL of the roles of the user")
+	ErrGRPCRoleRequestRate      = status.Error(codes.ResourceExhausted, "etcdserver: request rate exceeds the quota of the roles of the user")
+	ErrGRPCRoleWatchStreams     = status.Error(codes.ResourceExhausted, "etcdserver: watch streams exceed the quota of the roles of the user")
+	ErrGRPCRoleTxnOps           = status.Error(codes.ResourceExhausted, "etcdserver: txn operations exceed the quota of the roles of the user")
+	ErrGRPCPasswordPolicy       = status.Error(codes.InvalidArgument, "etcdserver: password does not satisfy the password policy")
+	ErrGRPCInvalidPermTemplate  = status.Error(codes.InvalidArgument, "etcdserver: invalid permission template")
+	ErrGRPCAuthorizerDenied     = status.Error(codes.PermissionDenied, "etcdserver: permission denied by the authorizer")
+	ErrGRPCAuthorizerFailed     = status.Error(codes.Unavailable, "etcdserver: authorizer unavailable")
 
 	ErrGRPCNoLeader                   = status.Error(codes.Unavailable, "etcdserver: no leader")
 	ErrGRPCNotLeader                  = status.Error(codes.FailedPrecondition, "etcdserver: not leader")
 	ErrGRPCLeaderChanged              = status.Error(codes.Unavailable, "etcdserver: leader changed")
 	ErrGRPCNotCapable                 = status.Error(codes.FailedPrecondition, "etcdserver: not capable")
 	ErrGRPCStopped                    = status.Error(codes.Unavailable, "etcdserver: server stopped")
+	ErrGRPCDraining                   = status.Error(codes.Unavailable, "etcdserver: server is draining")
 	ErrGRPCTimeout                    = status.Error(codes.Unavailable, "etcdserver: request timed out")
 	ErrGRPCTimeoutDueToLeaderFail     = status.Error(codes.Unavailable, "etcdserver: request timed out, possibly due to previous leader failure")
 	ErrGRPCTimeoutDueToConnectionLost = status.Error(codes.Unavailable, "etcdserver: request timed out, possibly due to connection lost")
 	ErrGRPCTimeoutWaitAppliedIndex    = status.Error(codes.Unavailable, "etcdserver: request timed out, waiting for the applied index took too long")
 	ErrGRPCUnhealthy                  = status.Error(codes.Unavailable, "etcdserver: unhealthy cluster")
 	ErrGRPCCorrupt                    = status.Error(codes.DataLoss, "etcdserver: corrupt cluster")
+	ErrGRPCBackendCorrupt             = status.Error(codes.DataLoss, "etcdserver: backend integrity check failed")
 	ErrGRPCNotSupportedForLearner     = status.Error(codes.FailedPrecondition, "etcdserver: rpc not supported for learner")
+	ErrGRPCNotSupportedForObserver    = status.Error(codes.FailedPrecondition, "etcdserver: rpc not supported for observer")
 	ErrGRPCBadLeaderTransferee        = status.Error(codes.FailedPrecondition, "etcdserver: bad leader transferee")
 
 	ErrGRPCWrongDowngradeVersionFormat   = status.Error(codes.InvalidArgument, "etcdserver: wrong downgrade target version format")
@@ -88,26 +124,51 @@ var (
 	ErrGRPCClusterVersionUnavailable     = status.Error(codes.FailedPrecondition, "etcdserver: cluster version not found during downgrade")
 	ErrGRPCDowngradeInProcess            = status.Error(codes.FailedPrecondition, "etcdserver: cluster has a downgrade job in progress")
 	ErrGRPCNoInflightDowngrade           = status.Error(codes.FailedPrecondition, "etcdserver: no inflight downgrade job")
+	ErrGRPCDowngradeEncodedKeyValues     = status.Error(codes.FailedPrecondition, "etcdserver: storage holds compressed or chunked records the downgrade target version cannot read")
 
 	ErrGRPCCanceled         = status.Error(codes.Canceled, "etcdserver: request canceled")
 	ErrGRPCDeadlineExceeded = status.Error(codes.DeadlineExceeded, "etcdserver: context deadline exceeded")
 
 	errStringToError = map[string]error{
-		ErrorDesc(ErrGRPCEmptyKey):      ErrGRPCEmptyKey,
-		ErrorDesc(ErrGRPCKeyNotFound):   ErrGRPCKeyNotFound,
-		ErrorDesc(ErrGRPCValueProvided): ErrGRPCValueProvided,
-		ErrorDesc(ErrGRPCLeaseProvided): ErrGRPCLeaseProvided,
+		ErrorDesc(ErrGRPCEmptyKey):       ErrGRPCEmptyKey,
+		ErrorDesc(ErrGRPCKeyNotFound):    ErrGRPCKeyNotFound,
+		ErrorDesc(ErrGRPCValueProvided):  ErrGRPCValueProvided,
+		ErrorDesc(ErrGRPCLeaseProvided):  ErrGRPCLeaseProvided,
+		ErrorDesc(ErrGRPCNegativeKeyTTL): ErrGRPCNegativeKeyTTL,
 
 		ErrorDesc(ErrGRPCTooManyOps):        ErrGRPCTooManyOps,
 		ErrorDesc(ErrGRPCDuplicateKey):      ErrGRPCDuplicateKey,
 		ErrorDesc(ErrGRPCInvalidSortOption): ErrGRPCInvalidSortOption,
+		ErrorDesc(ErrGRPCEstimateRevision):  ErrGRPCEstimateRevision,
 		ErrorDesc(ErrGRPCCompacted):         ErrGRPCCompacted,
 		ErrorDesc(ErrGRPCFutureRev):         ErrGRPCFutureRev,
 		ErrorDesc(ErrGRPCNoSpace):           ErrGRPCNoSpace,
 
-		ErrorDesc(ErrGRPCLeaseNotFound):    ErrGRPCLeaseNotFound,
-		ErrorDesc(ErrGRPCLeaseExist):       ErrGRPCLeaseExist,
-		ErrorDesc(ErrGRPCLeaseTTLTooLarge): ErrGRPCLeaseTTLTooLarge,
+		ErrorDesc(ErrGRPCPrefixQuotaExceeded): ErrGRPCPrefixQuotaExceeded,
+		ErrorDesc(ErrGRPCInvalidPrefixQuota):  ErrGRPCInvalidPrefixQuota,
+
+		ErrorDesc(ErrGRPCRevisionPinned):     ErrGRPCRevisionPinned,
+		ErrorDesc(ErrGRPCInvalidRevisionPin): ErrGRPCInvalidRevisionPin,
+
+		ErrorDesc(ErrGRPCIndexNotFound): ErrGRPCIndexNotFound,
+		ErrorDesc(ErrGRPCEmptyField):    ErrGRPCEmptyField,
+
+		ErrorDesc(ErrGRPCValueUploadNotFound): ErrGRPCValueUploadNotFound,
+		ErrorDesc(ErrGRPCValuePartInTxn):      ErrGRPCValuePartInTxn,
+
+		ErrorDesc(ErrGRPCLeaseNotFound):       ErrGRPCLeaseNotFound,
+		ErrorDesc(ErrGRPCLeaseExist):          ErrGRPCLeaseExist,
+		ErrorDesc(ErrGRPCLeaseTTLTooLarge):    ErrGRPCLeaseTTLTooLarge,
+		ErrorDesc(ErrGRPCLeaseWatchTooSlow):   ErrGRPCLeaseWatchTooSlow,
+		ErrorDesc(ErrGRPCParentLeaseNotFound): ErrGRPCParentLeaseNotFound,
+		ErrorDesc(ErrGRPCInvalidLeaseLabels):  ErrGRPCInvalidLeaseLabels,
+		ErrorDesc(ErrGRPCLeaseTooManyKeys):    ErrGRPCLeaseTooManyKeys,
+
+		ErrorDesc(ErrGRPCWatchStartRevisionTooOld): ErrGRPCWatchStartRevisionTooOld,
+		ErrorDesc(ErrGRPCInvalidWatchValueFilter):  ErrGRPCInvalidWatchValueFilter,
+		ErrorDesc(ErrGRPCInvalidWatchResumeToken):  ErrGRPCInvalidWatchResumeToken,
+		ErrorDesc(ErrGRPCInvalidWatchProjection):   ErrGRPCInvalidWatchProjection,
+		ErrorDesc(ErrGRPCWatchOverflow):            ErrGRPCWatchOverflow,
 
 		ErrorDesc(ErrGRPCMemberExist):            ErrGRPCMemberExist,
 		ErrorDesc(ErrGRPCPeerURLExist):           ErrGRPCPeerURLExist,
@@ -117,6 +178,8 @@ var (
 		ErrorDesc(ErrGRPCMemberNotLearner):       ErrGRPCMemberNotLearner,
 		ErrorDesc(ErrGRPCLearnerNotReady):        ErrGRPCLearnerNotReady,
 		ErrorDesc(ErrGRPCTooManyLearners):        ErrGRPCTooManyLearners,
+		ErrorDesc(ErrGRPCMemberObserver):         ErrGRPCMemberObserver,
+		ErrorDesc(ErrGRPCMemberActive):           ErrGRPCMemberActive,
 
 		ErrorDesc(ErrGRPCRequestTooLarge):        ErrGRPCRequestTooLarge,
 		ErrorDesc(ErrGRPCRequestTooManyRequests): ErrGRPCRequestTooManyRequests,
@@ -137,18 +200,29 @@ var (
 		ErrorDesc(ErrGRPCInvalidAuthToken):     ErrGRPCInvalidAuthToken,
 		ErrorDesc(ErrGRPCInvalidAuthMgmt):      ErrGRPCInvalidAuthMgmt,
 		ErrorDesc(ErrGRPCAuthOldRevision):      ErrGRPCAuthOldRevision,
+		ErrorDesc(ErrGRPCRoleLeaseTTLTooLarge): ErrGRPCRoleLeaseTTLTooLarge,
+		ErrorDesc(ErrGRPCRoleRequestRate):      ErrGRPCRoleRequestRate,
+		ErrorDesc(ErrGRPCRoleWatchStreams):     ErrGRPCRoleWatchStreams,
+		ErrorDesc(ErrGRPCRoleTxnOps):           ErrGRPCRoleTxnOps,
+		ErrorDesc(ErrGRPCPasswordPolicy):       ErrGRPCPasswordPolicy,
+		ErrorDesc(ErrGRPCInvalidPermTemplate):  ErrGRPCInvalidPermTemplate,
+		ErrorDesc(ErrGRPCAuthorizerDenied):     ErrGRPCAuthorizerDenied,
+		ErrorDesc(ErrGRPCAuthorizerFailed):     ErrGRPCAuthorizerFailed,
 
 		ErrorDesc(ErrGRPCNoLeader):                   ErrGRPCNoLeader,
 		ErrorDesc(ErrGRPCNotLeader):                  ErrGRPCNotLeader,
 		ErrorDesc(ErrGRPCLeaderChanged):              ErrGRPCLeaderChanged,
 		ErrorDesc(ErrGRPCNotCapable):                 ErrGRPCNotCapable,
 		ErrorDesc(ErrGRPCStopped):                    ErrGRPCStopped,
+		ErrorDesc(ErrGRPCDraining):                   ErrGRPCDraining,
 		ErrorDesc(ErrGRPCTimeout):                    ErrGRPCTimeout,
 		ErrorDesc(ErrGRPCTimeoutDueToLeaderFail):     ErrGRPCTimeoutDueToLeaderFail,
 		ErrorDesc(ErrGRPCTimeoutDueToConnectionLost): ErrGRPCTimeoutDueToConnectionLost,
 		ErrorDesc(ErrGRPCUnhealthy):                  ErrGRPCUnhealthy,
 		ErrorDesc(ErrGRPCCorrupt):                    ErrGRPCCorrupt,
+		ErrorDesc(ErrGRPCBackendCorrupt):             ErrGRPCBackendCorrupt,
 		ErrorDesc(ErrGRPCNotSupportedForLearner):     ErrGRPCNotSupportedForLearner,
+		ErrorDesc(ErrGRPCNotSupportedForObserver):    ErrGRPCNotSupportedForObserver,
 		ErrorDesc(ErrGRPCBadLeaderTransferee):        ErrGRPCBadLeaderTransferee,
 
 		ErrorDesc(ErrGRPCClusterVersionUnavailable):     ErrGRPCClusterVersionUnavailable,
@@ -156,6 +230,7 @@ var (
 		ErrorDesc(ErrGRPCInvalidDowngradeTargetVersion): ErrGRPCInvalidDowngradeTargetVersion,
 		ErrorDesc(ErrGRPCDowngradeInProcess):            ErrGRPCDowngradeInProcess,
 		ErrorDesc(ErrGRPCNoInflightDowngrade):           ErrGRPCNoInflightDowngrade,
+		ErrorDesc(ErrGRPCDowngradeEncodedKeyValues):     ErrGRPCDowngradeEncodedKeyValues,
 	}
 )
 
@@ -165,16 +240,40 @@ var (
 	ErrKeyNotFound       = Error(ErrGRPCKeyNotFound)
 	ErrValueProvided     = Error(ErrGRPCValueProvided)
 	ErrLeaseProvided     = Error(ErrGRPCLeaseProvided)
+	ErrNegativeKeyTTL    = Error(ErrGRPCNegativeKeyTTL)
 	ErrTooManyOps        = Error(ErrGRPCTooManyOps)
 	ErrDuplicateKey      = Error(ErrGRPCDuplicateKey)
 	ErrInvalidSortOption = Error(ErrGRPCInvalidSortOption)
+	ErrEstimateRevision  = Error(ErrGRPCEstimateRevision)
 	ErrCompacted         = Error(ErrGRPCCompacted)
 	ErrFutureRev         = Error(ErrGRPCFutureRev)
 	ErrNoSpace           = Error(ErrGRPCNoSpace)
 
-	ErrLeaseNotFound    = Error(ErrGRPCLeaseNotFound)
-	ErrLeaseExist       = Error(ErrGRPCLeaseExist)
-	ErrLeaseTTLTooLarge = Error(ErrGRPCLeaseTTLTooLarge)
+	ErrPrefixQuotaExceeded = Error(ErrGRPCPrefixQuotaExceeded)
+	ErrInvalidPrefixQuota  = Error(ErrGRPCInvalidPrefixQuota)
+
+	ErrRevisionPinned     = Error(ErrGRPCRevisionPinned)
+	ErrInvalidRevisionPin = Error(ErrGRPCInvalidRevisionPin)
+
+	ErrIndexNotFound = Error(ErrGRPCIndexNotFound)
+	ErrEmptyField    = Error(ErrGRPCEmptyField)
+
+	ErrValueUploadNotFound = Error(ErrGRPCValueUploadNotFound)
+	ErrValuePartInTxn      = Error(ErrGRPCValuePartInTxn)
+
+	ErrLeaseNotFound       = Error(ErrGRPCLeaseNotFound)
+	ErrLeaseExist          = Error(ErrGRPCLeaseExist)
+	ErrLeaseTTLTooLarge    = Error(ErrGRPCLeaseTTLTooLarge)
+	ErrLeaseWatchTooSlow   = Error(ErrGRPCLeaseWatchTooSlow)
+	ErrParentLeaseNotFound = Error(ErrGRPCParentLeaseNotFound)
+	ErrInvalidLeaseLabels  = Error(ErrGRPCInvalidLeaseLabels)
+	ErrLeaseTooManyKeys    = Error(ErrGRPCLeaseTooManyKeys)
+
+	ErrWatchStartRevisionTooOld = Error(ErrGRPCWatchStartRevisionTooOld)
+	ErrInvalidWatchValueFilter  = Error(ErrGRPCInvalidWatchValueFilter)
+	ErrInvalidWatchResumeToken  = Error(ErrGRPCInvalidWatchResumeToken)
+	ErrInvalidWatchProjection   = Error(ErrGRPCInvalidWatchProjection)
+	ErrWatchOverflow            = Error(ErrGRPCWatchOverflow)
 
 	ErrMemberExist            = Error(ErrGRPCMemberExist)
 	ErrPeerURLExist           = Error(ErrGRPCPeerURLExist)
@@ -184,6 +283,8 @@ var (
 	ErrMemberNotLearner       = Error(ErrGRPCMemberNotLearner)
 	ErrMemberLearnerNotReady  = Error(ErrGRPCLearnerNotReady)
 	ErrTooManyLearners        = Error(ErrGRPCTooManyLearners)
+	ErrMemberObserver         = Error(ErrGRPCMemberObserver)
+	ErrMemberActive           = Error(ErrGRPCMemberActive)
 
 	ErrRequestTooLarge = Error(ErrGRPCRequestTooLarge)
 	ErrTooManyRequests = Error(ErrGRPCRequestTooManyRequests)
@@ -204,18 +305,28 @@ var (
 	ErrInvalidAuthToken     = Error(ErrGRPCInvalidAuthToken)
 	ErrAuthOldRevision      = Error(ErrGRPCAuthOldRevision)
 	ErrInvalidAuthMgmt      = Error(ErrGRPCInvalidAuthMgmt)
+	ErrRoleLeaseTTLTooLarge = Error(ErrGRPCRoleLeaseTTLTooLarge)
+	ErrRoleRequestRate      = Error(ErrGRPCRoleRequestRate)
+	ErrRoleWatchStreams     = Error(ErrGRPCRoleWatchStreams)
+	ErrRoleTxnOps           = Error(ErrGRPCRoleTxnOps)
+	ErrPasswordPolicy       = Error(ErrGRPCPasswordPolicy)
+	ErrInvalidPermTemplate  = Error(ErrGRPCInvalidPermTemplate)
+	ErrAuthorizerDenied     = Error(ErrGRPCAuthorizerDenied)
+	ErrAuthorizerFailed     = Error(ErrGRPCAuthorizerFailed)
 
 	ErrNoLeader                   = Error(ErrGRPCNoLeader)
 	ErrNotLeader                  = Error(ErrGRPCNotLeader)
 	ErrLeaderChanged              = Error(ErrGRPCLeaderChanged)
 	ErrNotCapable                 = Error(ErrGRPCNotCapable)
 	ErrStopped                    = Error(ErrGRPCStopped)
+	ErrDraining                   = Error(ErrGRPCDraining)
 	ErrTimeout                    = Error(ErrGRPCTimeout)
 	ErrTimeoutDueToLeaderFail     = Error(ErrGRPCTimeoutDueToLeaderFail)
 	ErrTimeoutDueToConnectionLost = Error(ErrGRPCTimeoutDueToConnectionLost)
 	ErrTimeoutWaitAppliedIndex    = Error(ErrGRPCTimeoutWaitAppliedIndex)
 	ErrUnhealthy                  = Error(ErrGRPCUnhealthy)
 	ErrCorrupt                    = Error(ErrGRPCCorrupt)
+	ErrBackendCorrupt             = Error(ErrGRPCBackendCorrupt)
 	ErrBadLeaderTransferee        = Error(ErrGRPCBadLeaderTransferee)
 
 	ErrClusterVersionUnavailable     = Error(ErrGRPCClusterVersionUnavailable)
@@ -223,6 +334,7 @@ var (
 	ErrInvalidDowngradeTargetVersion = Error(ErrGRPCInvalidDowngradeTargetVersion)
 	ErrDowngradeInProcess            = Error(ErrGRPCDowngradeInProcess)
 	ErrNoInflightDowngrade           = Error(ErrGRPCNoInflightDowngrade)
+	ErrDowngradeEncodedKeyValues     = Error(ErrGRPCDowngradeEncodedKeyValues)
 )
 
 // EtcdError defines gRPC server errors.
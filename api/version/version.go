@@ -57,6 +57,12 @@ type Versions struct {
 	Server  string `json:"etcdserver"`
 	Cluster string `json:"etcdcluster"`
 	Storage string `json:"storage"`
+	// FeatureGates tells whether each feature gate known to the server is
+	// enabled. It is nil for the servers without feature gates.
+	FeatureGates map[string]bool `json:"featuregates,omitempty"`
+	// VersionRetention lists the version retentions of the server, as
+	// "<prefix>=<versions>" sorted. It is empty for the servers without any.
+	VersionRetention []string `json:"versionretention,omitempty"`
 	// TODO: raft state machine version
 }
 
@@ -50,13 +50,20 @@ func NewListenerWithOpts(addr, scheme string, opts ...ListenerOption) (net.Liste
 }
 
 func newListener(addr, scheme string, opts ...ListenerOption) (net.Listener, error) {
+	lnOpts := newListenOpts(opts...)
+	if lnOpts.inheritedListener != nil {
+		return wrapInheritedListener(scheme, lnOpts)
+	}
+
 	if scheme == "unix" || scheme == "unixs" {
 		// unix sockets via unix://laddr
-		return NewUnixListener(addr)
+		ln, err := NewUnixListener(addr)
+		if err != nil {
+			return nil, err
+		}
+		return lnOpts.wrap(ln), nil
 	}
 
-	lnOpts := newListenOpts(opts...)
-
 	switch {
 	case lnOpts.IsSocketOpts():
 		// new ListenConfig with socket options.
@@ -96,6 +103,7 @@ func newListener(addr, scheme string, opts ...ListenerOption) (net.Listener, err
 		lnOpts.Listener = ln
 	}
 
+	lnOpts.Listener = lnOpts.wrap(lnOpts.Listener)
 	//  only skip if not passing TLSInfo
 	if lnOpts.skipTLSInfoCheck && !lnOpts.IsTLS() {
 		return lnOpts.Listener, nil
@@ -103,6 +111,32 @@ func newListener(addr, scheme string, opts ...ListenerOption) (net.Listener, err
 	return wrapTLS(scheme, lnOpts.tlsInfo, lnOpts.Listener)
 }
 
+// wrapInheritedListener wraps an inherited listener like newListener wraps
+// the listeners it binds. The unix socket files are owned by whoever bound
+// them and are not removed on close.
+func wrapInheritedListener(scheme string, lnOpts *ListenerOptions) (net.Listener, error) {
+	ln := lnOpts.inheritedListener
+	if scheme == "unix" || scheme == "unixs" {
+		return lnOpts.wrap(ln), nil
+	}
+	ln, err := NewKeepAliveListener(ln, "tcp", nil)
+	if err != nil {
+		return nil, err
+	}
+	if lnOpts.IsTimeout() {
+		ln = &rwTimeoutListener{
+			Listener:     ln,
+			readTimeout:  lnOpts.readTimeout,
+			writeTimeout: lnOpts.writeTimeout,
+		}
+	}
+	ln = lnOpts.wrap(ln)
+	if lnOpts.skipTLSInfoCheck && !lnOpts.IsTLS() {
+		return ln, nil
+	}
+	return wrapTLS(scheme, lnOpts.tlsInfo, ln)
+}
+
 func newKeepAliveListener(cfg *net.ListenConfig, addr string) (ln net.Listener, err error) {
 	if cfg != nil {
 		ln, err = cfg.Listen(context.TODO(), "tcp", addr)
@@ -23,11 +23,13 @@ type ListenerOptions struct {
 	Listener     net.Listener
 	ListenConfig net.ListenConfig
 
-	socketOpts       *SocketOpts
-	tlsInfo          *TLSInfo
-	skipTLSInfoCheck bool
-	writeTimeout     time.Duration
-	readTimeout      time.Duration
+	socketOpts        *SocketOpts
+	inheritedListener net.Listener
+	wrapListener      func(net.Listener) net.Listener
+	tlsInfo           *TLSInfo
+	skipTLSInfoCheck  bool
+	writeTimeout      time.Duration
+	readTimeout       time.Duration
 }
 
 func newListenOpts(opts ...ListenerOption) *ListenerOptions {
@@ -42,6 +44,14 @@ func (lo *ListenerOptions) applyOpts(opts []ListenerOption) {
 	}
 }
 
+// wrap wraps the plain listener l with the listener wrapper, if any.
+func (lo *ListenerOptions) wrap(l net.Listener) net.Listener {
+	if lo.wrapListener == nil {
+		return l
+	}
+	return lo.wrapListener(l)
+}
+
 // IsTimeout returns true if the listener has a read/write timeout defined.
 func (lo *ListenerOptions) IsTimeout() bool { return lo.readTimeout != 0 || lo.writeTimeout != 0 }
 
@@ -88,3 +98,16 @@ func WithTLSInfo(t *TLSInfo) ListenerOption {
 func WithSkipTLSInfoCheck(skip bool) ListenerOption {
 	return func(lo *ListenerOptions) { lo.skipTLSInfoCheck = skip }
 }
+
+// WithInheritedListener serves the given listener, e.g. a socket passed by
+// systemd, rather than binding the address. The socket options do not apply
+// to the already bound listener.
+func WithInheritedListener(l net.Listener) ListenerOption {
+	return func(lo *ListenerOptions) { lo.inheritedListener = l }
+}
+
+// WithListenerWrapper wraps the plain listener, below TLS, e.g. to limit its
+// connections before their TLS handshakes.
+func WithListenerWrapper(w func(net.Listener) net.Listener) ListenerOption {
+	return func(lo *ListenerOptions) { lo.wrapListener = w }
+}
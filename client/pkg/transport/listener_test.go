@@ -526,6 +526,81 @@ func TestNewListenerUnixSocket(t *testing.T) {
 	l.Close()
 }
 
+// TestNewListenerInheritedListener tests that an inherited listener is served
+// rather than binding the address, and wrapped with TLS for https.
+func TestNewListenerInheritedListener(t *testing.T) {
+	tlsInfo, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the address is not bound as the inherited listener is served
+	ln, err := NewListenerWithOpts("invalid-address", "https", WithTLSInfo(tlsInfo), WithInheritedListener(inherited))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	assert.Equal(t, inherited.Addr().String(), ln.Addr().String())
+	if _, ok := ln.(*tlsListener); !ok {
+		t.Errorf("expected a TLS listener, got %T", ln)
+	}
+}
+
+type countingListener struct {
+	net.Listener
+	accepted int
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted++
+	}
+	return c, err
+}
+
+func TestNewListenerWithListenerWrapper(t *testing.T) {
+	tlsInfo, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	var wrapped *countingListener
+	ln, err := NewListenerWithOpts("127.0.0.1:0", "https",
+		WithTLSInfo(tlsInfo),
+		WithListenerWrapper(func(l net.Listener) net.Listener {
+			wrapped = &countingListener{Listener: l}
+			return wrapped
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	// the wrapper is below TLS
+	if _, ok := ln.(*tlsListener); !ok {
+		t.Fatalf("expected a TLS listener, got %T", ln)
+	}
+	if wrapped == nil {
+		t.Fatal("expected the listener to be wrapped")
+	}
+
+	go func() {
+		conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("unexpected Accept error: %v", err)
+	}
+	conn.Close()
+	assert.Equal(t, 1, wrapped.accepted)
+}
+
 // TestNewListenerTLSInfoSelfCert tests that a new certificate accepts connections.
 func TestNewListenerTLSInfoSelfCert(t *testing.T) {
 	tmpdir := t.TempDir()
@@ -40,6 +40,11 @@ type (
 	AuthRoleGrantPermissionResponse  pb.AuthRoleGrantPermissionResponse
 	AuthRoleGetResponse              pb.AuthRoleGetResponse
 	AuthRoleRevokePermissionResponse pb.AuthRoleRevokePermissionResponse
+	AuthRoleSetMaxLeaseTTLResponse   pb.AuthRoleSetMaxLeaseTTLResponse
+	AuthRoleSetQuotaResponse         pb.AuthRoleSetQuotaResponse
+	AuthSetBcryptCostResponse        pb.AuthSetBcryptCostResponse
+	AuthTokenListResponse            pb.AuthTokenListResponse
+	AuthTokenRevokeResponse          pb.AuthTokenRevokeResponse
 	AuthRoleDeleteResponse           pb.AuthRoleDeleteResponse
 	AuthUserListResponse             pb.AuthUserListResponse
 	AuthRoleListResponse             pb.AuthRoleListResponse
@@ -52,8 +57,21 @@ const (
 	PermRead      = authpb.READ
 	PermWrite     = authpb.WRITE
 	PermReadWrite = authpb.READWRITE
+	PermWatch     = authpb.WATCH
+	PermCount     = authpb.COUNT
 )
 
+// RoleQuota is the quota of the requests of the users of a role. A zero limit
+// means no limit.
+type RoleQuota struct {
+	// MaxRequestsPerSecond is the maximum rate of the unary requests of a user.
+	MaxRequestsPerSecond int64
+	// MaxWatchStreams is the maximum number of the concurrent watch streams of a user.
+	MaxWatchStreams int64
+	// MaxTxnOps is the maximum number of operations per txn of a user.
+	MaxTxnOps int64
+}
+
 type UserAddOptions authpb.UserAddOptions
 
 type Auth interface {
@@ -110,6 +128,27 @@ type Auth interface {
 
 	// RoleDelete deletes a role.
 	RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error)
+
+	// RoleSetMaxLeaseTTL sets the maximum TTL, in seconds, of the leases granted by the users
+	// of a role, unless another of their roles allows longer ones. 0 removes the limit.
+	RoleSetMaxLeaseTTL(ctx context.Context, role string, maxLeaseTTL int64) (*AuthRoleSetMaxLeaseTTLResponse, error)
+
+	// RoleSetQuota sets the quotas of the requests of each user of a role, on each member,
+	// unless another of their roles allows more. The zero limits of the quota remove them.
+	RoleSetQuota(ctx context.Context, role string, quota RoleQuota) (*AuthRoleSetQuotaResponse, error)
+
+	// AuthSetBcryptCost raises the bcrypt cost of the passwords of the cluster. The passwords
+	// hashed with a lower cost are rehashed when their users next authenticate.
+	AuthSetBcryptCost(ctx context.Context, cost int) (*AuthSetBcryptCostResponse, error)
+
+	// AuthTokenList lists the valid auth tokens issued by the member, of all the users if user is empty.
+	AuthTokenList(ctx context.Context, user string) (*AuthTokenListResponse, error)
+
+	// AuthTokenRevoke revokes the auth token of the ID on all the members.
+	AuthTokenRevoke(ctx context.Context, id string) (*AuthTokenRevokeResponse, error)
+
+	// AuthTokenRevokeUser revokes all the auth tokens of a user on all the members.
+	AuthTokenRevokeUser(ctx context.Context, user string) (*AuthTokenRevokeResponse, error)
 }
 
 type authClient struct {
@@ -228,6 +267,41 @@ func (auth *authClient) RoleDelete(ctx context.Context, role string) (*AuthRoleD
 	return (*AuthRoleDeleteResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) RoleSetMaxLeaseTTL(ctx context.Context, role string, maxLeaseTTL int64) (*AuthRoleSetMaxLeaseTTLResponse, error) {
+	resp, err := auth.remote.RoleSetMaxLeaseTTL(ctx, &pb.AuthRoleSetMaxLeaseTTLRequest{Role: role, MaxLeaseTTL: maxLeaseTTL}, auth.callOpts...)
+	return (*AuthRoleSetMaxLeaseTTLResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) RoleSetQuota(ctx context.Context, role string, quota RoleQuota) (*AuthRoleSetQuotaResponse, error) {
+	resp, err := auth.remote.RoleSetQuota(ctx, &pb.AuthRoleSetQuotaRequest{
+		Role:                 role,
+		MaxRequestsPerSecond: quota.MaxRequestsPerSecond,
+		MaxWatchStreams:      quota.MaxWatchStreams,
+		MaxTxnOps:            quota.MaxTxnOps,
+	}, auth.callOpts...)
+	return (*AuthRoleSetQuotaResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthSetBcryptCost(ctx context.Context, cost int) (*AuthSetBcryptCostResponse, error) {
+	resp, err := auth.remote.AuthSetBcryptCost(ctx, &pb.AuthSetBcryptCostRequest{Cost: int32(cost)}, auth.callOpts...)
+	return (*AuthSetBcryptCostResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthTokenList(ctx context.Context, user string) (*AuthTokenListResponse, error) {
+	resp, err := auth.remote.AuthTokenList(ctx, &pb.AuthTokenListRequest{User: user}, auth.callOpts...)
+	return (*AuthTokenListResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthTokenRevoke(ctx context.Context, id string) (*AuthTokenRevokeResponse, error) {
+	resp, err := auth.remote.AuthTokenRevoke(ctx, &pb.AuthTokenRevokeRequest{Id: id}, auth.callOpts...)
+	return (*AuthTokenRevokeResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthTokenRevokeUser(ctx context.Context, user string) (*AuthTokenRevokeResponse, error) {
+	resp, err := auth.remote.AuthTokenRevoke(ctx, &pb.AuthTokenRevokeRequest{User: user}, auth.callOpts...)
+	return (*AuthTokenRevokeResponse)(resp), toErr(ctx, err)
+}
+
 func StrToPermissionType(s string) (PermissionType, error) {
 	val, ok := authpb.Permission_Type_value[strings.ToUpper(s)]
 	if ok {
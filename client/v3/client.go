@@ -262,6 +262,14 @@ func (c *Client) Dial(ep string) (*grpc.ClientConn, error) {
 func (c *Client) getToken(ctx context.Context) error {
 	var err error // return last error in a case of fail
 
+	if c.cfg.TokenSource != nil {
+		token, err := c.cfg.TokenSource(ctx)
+		if err != nil {
+			return err
+		}
+		c.authTokenBundle.UpdateAuthToken(token)
+		return nil
+	}
 	if c.Username == "" || c.Password == "" {
 		return nil
 	}
@@ -390,6 +398,9 @@ func newClient(cfg *Config) (*Client, error) {
 		client.Password = cfg.Password
 		client.authTokenBundle = credentials.NewBundle(credentials.Config{})
 	}
+	if cfg.TokenSource != nil {
+		client.authTokenBundle = credentials.NewBundle(credentials.Config{})
+	}
 	if cfg.MaxCallSendMsgSize > 0 || cfg.MaxCallRecvMsgSize > 0 {
 		if cfg.MaxCallRecvMsgSize > 0 && cfg.MaxCallSendMsgSize > cfg.MaxCallRecvMsgSize {
 			return nil, fmt.Errorf("gRPC message recv limit (%d bytes) must be greater than send limit (%d bytes)", cfg.MaxCallRecvMsgSize, cfg.MaxCallSendMsgSize)
@@ -414,6 +414,54 @@ func (mm mockMaintenance) Downgrade(ctx context.Context, action DowngradeAction,
 	return nil, nil
 }
 
+func (mm mockMaintenance) PrefixQuotaList(ctx context.Context) (*PrefixQuotaResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) PrefixQuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*PrefixQuotaResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) PrefixQuotaDelete(ctx context.Context, prefix string) (*PrefixQuotaResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) HotKeys(ctx context.Context, endpoint string, limit int64) (*HotKeysResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) CorruptionCheck(ctx context.Context, endpoint string, run bool, scope CorruptionCheckScope) (*CorruptionCheckResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) PrefixStats(ctx context.Context, endpoint, prefix string, depth int64) (*PrefixStatsResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) RevisionPinList(ctx context.Context) (*RevisionPinResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) PinRevision(ctx context.Context, owner string, rev, ttl int64) (*RevisionPinResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) UnpinRevision(ctx context.Context, owner string) (*RevisionPinResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) SnapshotRange(ctx context.Context, session, key, end string, rev, limit int64) (*SnapshotRangeResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) WatchLag(ctx context.Context, endpoint string, limit int64) (*WatchLagResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) Drain(ctx context.Context, endpoint string, timeout time.Duration) (*DrainResponse, error) {
+	return nil, nil
+}
+
 type mockAuthServer struct {
 	*etcdserverpb.UnimplementedAuthServer
 }
@@ -438,6 +486,10 @@ func (mc *mockCluster) MemberAddAsLearner(ctx context.Context, peerAddrs []strin
 	return nil, nil
 }
 
+func (mc *mockCluster) MemberAddAsObserver(ctx context.Context, peerAddrs []string) (*MemberAddResponse, error) {
+	return nil, nil
+}
+
 func (mc *mockCluster) MemberRemove(ctx context.Context, id uint64) (*MemberRemoveResponse, error) {
 	return nil, nil
 }
@@ -449,3 +501,7 @@ func (mc *mockCluster) MemberUpdate(ctx context.Context, id uint64, peerAddrs []
 func (mc *mockCluster) MemberPromote(ctx context.Context, id uint64) (*MemberPromoteResponse, error) {
 	return nil, nil
 }
+
+func (mc *mockCluster) MemberReplace(ctx context.Context, id uint64, peerAddrs []string) (*MemberReplaceResponse, error) {
+	return nil, nil
+}
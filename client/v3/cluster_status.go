@@ -0,0 +1,136 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"sync"
+)
+
+// EndpointStatus is the status of an endpoint, or the error of the
+// request for it.
+type EndpointStatus struct {
+	Endpoint string
+	Status   *StatusResponse
+	Err      error
+}
+
+// ClusterStatus aggregates the statuses of the endpoints of a client.
+type ClusterStatus struct {
+	// Endpoints are the statuses of the endpoints, in the order of the
+	// client endpoints.
+	Endpoints []EndpointStatus
+	// Unreachable lists the endpoints whose status could not be queried.
+	Unreachable []string
+
+	// Leader is the member ID of the leader reported by the reachable
+	// endpoints, if they all agree on it. It is 0 otherwise.
+	Leader uint64
+	// LeaderAgreement is true if all the reachable endpoints report the
+	// same, non-zero, leader.
+	LeaderAgreement bool
+
+	// MinRevision and MaxRevision are the lowest and highest revisions
+	// reported by the reachable endpoints.
+	MinRevision int64
+	MaxRevision int64
+	// MinDbSize and MaxDbSize are the lowest and highest backend sizes, in
+	// bytes, reported by the reachable endpoints.
+	MinDbSize int64
+	MaxDbSize int64
+}
+
+// RevisionSpread returns how many revisions the most lagging reachable
+// endpoint is behind the most recent one.
+func (cs *ClusterStatus) RevisionSpread() int64 { return cs.MaxRevision - cs.MinRevision }
+
+// DbSizeSpread returns the difference, in bytes, between the largest and
+// the smallest backends of the reachable endpoints.
+func (cs *ClusterStatus) DbSizeSpread() int64 { return cs.MaxDbSize - cs.MinDbSize }
+
+// Healthy returns true if all the endpoints are reachable and agree on the
+// leader.
+func (cs *ClusterStatus) Healthy() bool {
+	return len(cs.Unreachable) == 0 && cs.LeaderAgreement
+}
+
+// ClusterStatus queries the status of every client endpoint in parallel and
+// aggregates them. The endpoints failing to respond are reported in the
+// result rather than failing the call; it only fails if the client has no
+// endpoints.
+func (c *Client) ClusterStatus(ctx context.Context) (*ClusterStatus, error) {
+	eps := c.Endpoints()
+	if len(eps) == 0 {
+		return nil, ErrNoAvailableEndpoints
+	}
+
+	statuses := make([]EndpointStatus, len(eps))
+	var wg sync.WaitGroup
+	wg.Add(len(eps))
+	for i, ep := range eps {
+		go func(i int, ep string) {
+			defer wg.Done()
+			resp, err := c.Maintenance.Status(ctx, ep)
+			statuses[i] = EndpointStatus{Endpoint: ep, Status: resp, Err: err}
+		}(i, ep)
+	}
+	wg.Wait()
+	return aggregateClusterStatus(statuses), nil
+}
+
+func aggregateClusterStatus(statuses []EndpointStatus) *ClusterStatus {
+	cs := &ClusterStatus{Endpoints: statuses}
+	reachable := 0
+	for _, s := range statuses {
+		if s.Err != nil || s.Status == nil {
+			cs.Unreachable = append(cs.Unreachable, s.Endpoint)
+			continue
+		}
+		rev, dbSize := int64(0), s.Status.DbSize
+		if s.Status.Header != nil {
+			rev = s.Status.Header.Revision
+		}
+		if reachable == 0 {
+			cs.Leader, cs.LeaderAgreement = s.Status.Leader, s.Status.Leader != 0
+			cs.MinRevision, cs.MaxRevision = rev, rev
+			cs.MinDbSize, cs.MaxDbSize = dbSize, dbSize
+		} else {
+			if s.Status.Leader != cs.Leader {
+				cs.LeaderAgreement = false
+			}
+			cs.MinRevision, cs.MaxRevision = min64(cs.MinRevision, rev), max64(cs.MaxRevision, rev)
+			cs.MinDbSize, cs.MaxDbSize = min64(cs.MinDbSize, dbSize), max64(cs.MaxDbSize, dbSize)
+		}
+		reachable++
+	}
+	if !cs.LeaderAgreement {
+		cs.Leader = 0
+	}
+	return cs
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
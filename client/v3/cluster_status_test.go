@@ -0,0 +1,113 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestAggregateClusterStatus(t *testing.T) {
+	status := func(leader uint64, rev, dbSize int64) *StatusResponse {
+		return &StatusResponse{Header: &pb.ResponseHeader{Revision: rev}, Leader: leader, DbSize: dbSize}
+	}
+	errUnreachable := errors.New("unreachable")
+
+	tests := []struct {
+		name     string
+		statuses []EndpointStatus
+		want     ClusterStatus
+		wHealthy bool
+	}{
+		{
+			name: "agreeing endpoints",
+			statuses: []EndpointStatus{
+				{Endpoint: "a", Status: status(1, 10, 100)},
+				{Endpoint: "b", Status: status(1, 12, 300)},
+				{Endpoint: "c", Status: status(1, 11, 200)},
+			},
+			want: ClusterStatus{
+				Leader: 1, LeaderAgreement: true,
+				MinRevision: 10, MaxRevision: 12,
+				MinDbSize: 100, MaxDbSize: 300,
+			},
+			wHealthy: true,
+		},
+		{
+			name: "leader disagreement",
+			statuses: []EndpointStatus{
+				{Endpoint: "a", Status: status(1, 10, 100)},
+				{Endpoint: "b", Status: status(2, 10, 100)},
+			},
+			want: ClusterStatus{
+				MinRevision: 10, MaxRevision: 10,
+				MinDbSize: 100, MaxDbSize: 100,
+			},
+		},
+		{
+			name: "no leader",
+			statuses: []EndpointStatus{
+				{Endpoint: "a", Status: status(0, 10, 100)},
+			},
+			want: ClusterStatus{
+				MinRevision: 10, MaxRevision: 10,
+				MinDbSize: 100, MaxDbSize: 100,
+			},
+		},
+		{
+			name: "unreachable endpoints",
+			statuses: []EndpointStatus{
+				{Endpoint: "a", Err: errUnreachable},
+				{Endpoint: "b", Status: status(3, 20, 100)},
+				{Endpoint: "c", Err: errUnreachable},
+			},
+			want: ClusterStatus{
+				Unreachable: []string{"a", "c"},
+				Leader:      3, LeaderAgreement: true,
+				MinRevision: 20, MaxRevision: 20,
+				MinDbSize: 100, MaxDbSize: 100,
+			},
+		},
+		{
+			name: "all unreachable",
+			statuses: []EndpointStatus{
+				{Endpoint: "a", Err: errUnreachable},
+			},
+			want: ClusterStatus{Unreachable: []string{"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := aggregateClusterStatus(tt.statuses)
+			tt.want.Endpoints = tt.statuses
+			assert.Equal(t, &tt.want, cs)
+			assert.Equal(t, tt.want.MaxRevision-tt.want.MinRevision, cs.RevisionSpread())
+			assert.Equal(t, tt.want.MaxDbSize-tt.want.MinDbSize, cs.DbSizeSpread())
+			assert.Equal(t, tt.wHealthy, cs.Healthy())
+		})
+	}
+}
+
+func TestClusterStatusNoEndpoints(t *testing.T) {
+	c := &Client{epMu: new(sync.RWMutex)}
+	_, err := c.ClusterStatus(context.TODO())
+	assert.Equal(t, ErrNoAvailableEndpoints, err)
+}
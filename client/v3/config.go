@@ -66,6 +66,11 @@ type Config struct {
 	// Password is a password for authentication.
 	Password string `json:"password"`
 
+	// TokenSource returns the token to authenticate with instead of Username and
+	// Password, such as the OIDC ID token of the user. It is called again to
+	// refresh the token once the server rejects it.
+	TokenSource func(ctx context.Context) (string, error) `json:"-"`
+
 	// RejectOldCluster when set will refuse to create a client against an outdated cluster.
 	RejectOldCluster bool `json:"reject-old-cluster"`
 
@@ -119,10 +124,13 @@ type SecureConfig struct {
 type AuthConfig struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Token is the token to authenticate with instead of Username and Password,
+	// such as the OIDC ID token of the user.
+	Token string `json:"token"`
 }
 
 func (cfg AuthConfig) Empty() bool {
-	return cfg.Username == "" && cfg.Password == ""
+	return cfg.Username == "" && cfg.Password == "" && cfg.Token == ""
 }
 
 // NewClientConfig creates a Config based on the provided ConfigSpec.
@@ -143,6 +151,9 @@ func NewClientConfig(confSpec *ConfigSpec, lg *zap.Logger) (*Config, error) {
 	if confSpec.Auth != nil {
 		cfg.Username = confSpec.Auth.Username
 		cfg.Password = confSpec.Auth.Password
+		if token := confSpec.Auth.Token; token != "" {
+			cfg.TokenSource = func(context.Context) (string, error) { return token, nil }
+		}
 	}
 
 	return cfg, nil
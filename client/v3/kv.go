@@ -48,6 +48,13 @@ type KV interface {
 	// When passed WithSort(), the keys will be sorted.
 	Get(ctx context.Context, key string, opts ...OpOption) (*GetResponse, error)
 
+	// IndexGet retrieves the keys under prefix whose JSON value has value at the
+	// dot separated field path, looked up in a secondary index registered on the
+	// server for the prefix and field. It fails with ErrIndexNotFound if there is
+	// no such index. The keys are sorted and read at the current revision; only
+	// WithLimit, WithSerializable and WithKeysOnly are taken into account.
+	IndexGet(ctx context.Context, prefix, field, value string, opts ...OpOption) (*GetResponse, error)
+
 	// Delete deletes a key, or optionally using WithRange(end), [key, end).
 	Delete(ctx context.Context, key string, opts ...OpOption) (*DeleteResponse, error)
 
@@ -121,6 +128,23 @@ func (kv *kv) Get(ctx context.Context, key string, opts ...OpOption) (*GetRespon
 	return r.get, toErr(ctx, err)
 }
 
+func (kv *kv) IndexGet(ctx context.Context, prefix, field, value string, opts ...OpOption) (*GetResponse, error) {
+	op := OpGet(prefix, opts...)
+	r := &pb.IndexRangeRequest{
+		Prefix:       []byte(prefix),
+		Field:        field,
+		Value:        value,
+		Limit:        op.limit,
+		Serializable: op.serializable,
+		KeysOnly:     op.keysOnly,
+	}
+	resp, err := kv.remote.IndexRange(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*GetResponse)(resp), nil
+}
+
 func (kv *kv) Delete(ctx context.Context, key string, opts ...OpOption) (*DeleteResponse, error) {
 	r, err := kv.Do(ctx, OpDelete(key, opts...))
 	return r.del, toErr(ctx, err)
@@ -157,7 +181,13 @@ func (kv *kv) Do(ctx context.Context, op Op) (OpResponse, error) {
 		}
 	case tPut:
 		var resp *pb.PutResponse
-		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease}
+		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease, TtlSeconds: op.ttl}
+		if op.valuePartSize > 0 && len(op.val) > op.valuePartSize {
+			r.ValueUpload, r.Value, err = kv.putValueParts(ctx, op.key, op.val, op.valuePartSize)
+			if err != nil {
+				break
+			}
+		}
 		resp, err = kv.remote.Put(ctx, r, kv.callOpts...)
 		if err == nil {
 			return OpResponse{put: (*PutResponse)(resp)}, nil
@@ -180,3 +210,17 @@ func (kv *kv) Do(ctx context.Context, op Op) (OpResponse, error) {
 	}
 	return OpResponse{}, toErr(ctx, err)
 }
+
+// putValueParts stages the parts of the value but the last one, returning
+// the upload and the last part the put of the key completes it with.
+func (kv *kv) putValueParts(ctx context.Context, key, val []byte, size int) (int64, []byte, error) {
+	var upload int64
+	for len(val) > size {
+		resp, err := kv.remote.Put(ctx, &pb.PutRequest{Key: key, Value: val[:size], ValuePart: true, ValueUpload: upload}, kv.callOpts...)
+		if err != nil {
+			return 0, nil, err
+		}
+		upload, val = resp.ValueUpload, val[size:]
+	}
+	return upload, val, nil
+}
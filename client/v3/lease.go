@@ -40,6 +40,22 @@ type LeaseGrantResponse struct {
 	Error string
 }
 
+// LeaseGrantBatchResponse wraps the protobuf message LeaseGrantBatchResponse.
+type LeaseGrantBatchResponse struct {
+	*pb.ResponseHeader
+	// Leases are the granted leases, in the order of the requested TTLs. The
+	// leases that failed to be granted have their Error set.
+	Leases []LeaseGrantResponse
+}
+
+// LeaseRevokeBatchResponse wraps the protobuf message LeaseRevokeBatchResponse.
+type LeaseRevokeBatchResponse struct {
+	*pb.ResponseHeader
+	// Errors are the errors revoking the leases, in the order of the requested
+	// IDs, empty for the revoked leases.
+	Errors []string
+}
+
 // LeaseKeepAliveResponse wraps the protobuf message LeaseKeepAliveResponse.
 type LeaseKeepAliveResponse struct {
 	*pb.ResponseHeader
@@ -60,12 +76,48 @@ type LeaseTimeToLiveResponse struct {
 
 	// Keys is the list of keys attached to this lease.
 	Keys [][]byte `json:"keys"`
+
+	// ParentID is the ID of the parent lease of this lease, NoLease if none.
+	ParentID LeaseID `json:"parent-id,omitempty"`
+
+	// Labels are the labels the lease was granted with.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Stats are the statistics of the lease, if requested with WithLeaseStats.
+	Stats *LeaseStats `json:"stats,omitempty"`
+}
+
+// LeaseStats represents the statistics of a lease, kept by the leader.
+type LeaseStats struct {
+	// Grantor is the name of the user who granted the lease, empty if it was
+	// granted without authentication.
+	Grantor string `json:"grantor,omitempty"`
+
+	// AttachedKeys is the number of keys attached to the lease.
+	AttachedKeys int64 `json:"attached-keys"`
+
+	// Renewals is the number of times the lease was renewed since StatsStartTime.
+	Renewals int64 `json:"renewals"`
+
+	// RenewRate is the average number of renewals per second since StatsStartTime.
+	RenewRate float64 `json:"renew-rate"`
+
+	// LastRenewTime is the time of the last renewal, zero if the lease was not
+	// renewed since StatsStartTime.
+	LastRenewTime time.Time `json:"last-renew-time"`
+
+	// StatsStartTime is the time the leader started keeping the statistics of
+	// the lease, as the lease was granted or the leader elected.
+	StatsStartTime time.Time `json:"stats-start-time"`
 }
 
 // LeaseStatus represents a lease status.
 type LeaseStatus struct {
 	ID LeaseID `json:"id"`
 	// TODO: TTL int64
+
+	// Labels are the labels of the lease, listed by Query only.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // LeaseLeasesResponse wraps the protobuf message LeaseLeasesResponse.
@@ -74,6 +126,41 @@ type LeaseLeasesResponse struct {
 	Leases []LeaseStatus `json:"leases"`
 }
 
+// LeaseExpiration represents a lease that expired or was revoked.
+type LeaseExpiration struct {
+	ID LeaseID `json:"id"`
+
+	// GrantedTTL is the initial granted time in seconds upon lease creation/renewal.
+	GrantedTTL int64 `json:"granted-ttl"`
+
+	// Keys is the list of keys deleted with the lease, if requested with WithAttachedKeys.
+	Keys [][]byte `json:"keys"`
+
+	// Expired is true if the lease expired, or was revoked along with a parent
+	// lease that expired, and false if it was revoked.
+	Expired bool `json:"expired"`
+
+	// ParentID is the ID of the parent lease the lease was granted with, NoLease if none.
+	ParentID LeaseID `json:"parent-id,omitempty"`
+
+	// Labels are the labels the lease was granted with.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// LeaseWatchExpirationsResponse wraps the protobuf message LeaseWatchExpirationsResponse.
+type LeaseWatchExpirationsResponse struct {
+	*pb.ResponseHeader
+	Expirations []LeaseExpiration `json:"expirations"`
+
+	closeErr error
+}
+
+// Err is the error the expiration stream ended with, set on the last
+// response sent before the channel closes.
+func (wr *LeaseWatchExpirationsResponse) Err() error {
+	return wr.closeErr
+}
+
 const (
 	// defaultTTL is the assumed lease TTL used for the first keepalive
 	// deadline before the actual TTL is known to the client.
@@ -106,18 +193,45 @@ func (e ErrKeepAliveHalted) Error() string {
 }
 
 type Lease interface {
-	// Grant creates a new lease.
-	Grant(ctx context.Context, ttl int64) (*LeaseGrantResponse, error)
+	// Grant creates a new lease. With WithParentLease, the lease is revoked
+	// along with the given parent lease. With WithLeaseLabels, the lease
+	// carries the given labels, which may be used to query the leases.
+	Grant(ctx context.Context, ttl int64, opts ...LeaseOption) (*LeaseGrantResponse, error)
 
 	// Revoke revokes the given lease.
 	Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse, error)
 
+	// GrantBatch creates a lease for every given TTL, all with the given
+	// options, in a single proposal. A lease failing to be granted does not
+	// fail the others.
+	GrantBatch(ctx context.Context, ttls []int64, opts ...LeaseOption) (*LeaseGrantBatchResponse, error)
+
+	// RevokeBatch revokes the given leases in a single proposal. A lease
+	// failing to be revoked does not fail the others.
+	RevokeBatch(ctx context.Context, ids []LeaseID) (*LeaseRevokeBatchResponse, error)
+
 	// TimeToLive retrieves the lease information of the given lease ID.
 	TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error)
 
 	// Leases retrieves all leases.
 	Leases(ctx context.Context) (*LeaseLeasesResponse, error)
 
+	// Query retrieves the leases carrying all the given labels, along with
+	// their labels.
+	Query(ctx context.Context, labels map[string]string) (*LeaseLeasesResponse, error)
+
+	// WatchExpirations streams the leases that expire or are revoked from the
+	// time it returns, in the order they do, telling the expired leases apart
+	// from the revoked ones. With WithAttachedKeys, each lease comes with the
+	// keys deleted with it, and with WithExpiredOnly, only the expired leases
+	// are streamed.
+	//
+	// The returned channel closes when ctx is canceled or the stream fails;
+	// in the latter case, the last response carries the error. Notably, the
+	// stream fails with ErrLeaseWatchTooSlow if the responses are not
+	// consumed fast enough, and the expirations since are lost.
+	WatchExpirations(ctx context.Context, opts ...LeaseOption) (<-chan LeaseWatchExpirationsResponse, error)
+
 	// KeepAlive attempts to keep the given lease alive forever. If the keepalive responses posted
 	// to the channel are not consumed promptly the channel may become full. When full, the lease
 	// client will continue sending keep alive requests to the etcd server, but will drop responses
@@ -211,8 +325,8 @@ func NewLeaseFromLeaseClient(remote pb.LeaseClient, c *Client, keepAliveTimeout
 	return l
 }
 
-func (l *lessor) Grant(ctx context.Context, ttl int64) (*LeaseGrantResponse, error) {
-	r := &pb.LeaseGrantRequest{TTL: ttl}
+func (l *lessor) Grant(ctx context.Context, ttl int64, opts ...LeaseOption) (*LeaseGrantResponse, error) {
+	r := toLeaseGrantRequest(ttl, opts...)
 	resp, err := l.remote.LeaseGrant(ctx, r, l.callOpts...)
 	if err == nil {
 		gresp := &LeaseGrantResponse{
@@ -235,6 +349,39 @@ func (l *lessor) Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse,
 	return nil, toErr(ctx, err)
 }
 
+func (l *lessor) GrantBatch(ctx context.Context, ttls []int64, opts ...LeaseOption) (*LeaseGrantBatchResponse, error) {
+	r := &pb.LeaseGrantBatchRequest{Requests: make([]*pb.LeaseGrantRequest, len(ttls))}
+	for i, ttl := range ttls {
+		r.Requests[i] = toLeaseGrantRequest(ttl, opts...)
+	}
+	resp, err := l.remote.LeaseGrantBatch(ctx, r, l.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	leases := make([]LeaseGrantResponse, len(resp.Responses))
+	for i, gr := range resp.Responses {
+		leases[i] = LeaseGrantResponse{
+			ResponseHeader: resp.GetHeader(),
+			ID:             LeaseID(gr.ID),
+			TTL:            gr.TTL,
+			Error:          gr.Error,
+		}
+	}
+	return &LeaseGrantBatchResponse{ResponseHeader: resp.GetHeader(), Leases: leases}, nil
+}
+
+func (l *lessor) RevokeBatch(ctx context.Context, ids []LeaseID) (*LeaseRevokeBatchResponse, error) {
+	r := &pb.LeaseRevokeBatchRequest{Requests: make([]*pb.LeaseRevokeRequest, len(ids))}
+	for i, id := range ids {
+		r.Requests[i] = &pb.LeaseRevokeRequest{ID: int64(id)}
+	}
+	resp, err := l.remote.LeaseRevokeBatch(ctx, r, l.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return &LeaseRevokeBatchResponse{ResponseHeader: resp.GetHeader(), Errors: resp.Errors}, nil
+}
+
 func (l *lessor) TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error) {
 	r := toLeaseTimeToLiveRequest(id, opts...)
 	resp, err := l.remote.LeaseTimeToLive(ctx, r, l.callOpts...)
@@ -247,10 +394,32 @@ func (l *lessor) TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption
 		TTL:            resp.TTL,
 		GrantedTTL:     resp.GrantedTTL,
 		Keys:           resp.Keys,
+		ParentID:       LeaseID(resp.ParentID),
+		Labels:         leaseLabelsFromPB(resp.Labels),
+		Stats:          leaseStatsFromPB(resp.Stats),
 	}
 	return gresp, nil
 }
 
+func leaseStatsFromPB(st *pb.LeaseStats) *LeaseStats {
+	if st == nil {
+		return nil
+	}
+	s := &LeaseStats{
+		Grantor:      st.Grantor,
+		AttachedKeys: st.AttachedKeys,
+		Renewals:     st.Renewals,
+		RenewRate:    st.RenewRate,
+	}
+	if st.LastRenewTime != 0 {
+		s.LastRenewTime = time.Unix(0, st.LastRenewTime)
+	}
+	if st.StatsStartTime != 0 {
+		s.StatsStartTime = time.Unix(0, st.StatsStartTime)
+	}
+	return s
+}
+
 func (l *lessor) Leases(ctx context.Context) (*LeaseLeasesResponse, error) {
 	resp, err := l.remote.LeaseLeases(ctx, &pb.LeaseLeasesRequest{}, l.callOpts...)
 	if err == nil {
@@ -263,6 +432,67 @@ func (l *lessor) Leases(ctx context.Context) (*LeaseLeasesResponse, error) {
 	return nil, toErr(ctx, err)
 }
 
+func (l *lessor) Query(ctx context.Context, labels map[string]string) (*LeaseLeasesResponse, error) {
+	resp, err := l.remote.LeaseQuery(ctx, &pb.LeaseQueryRequest{Labels: leaseLabelsToPB(labels)}, l.callOpts...)
+	if err == nil {
+		leases := make([]LeaseStatus, len(resp.Leases))
+		for i := range resp.Leases {
+			leases[i] = LeaseStatus{ID: LeaseID(resp.Leases[i].ID), Labels: leaseLabelsFromPB(resp.Leases[i].Labels)}
+		}
+		return &LeaseLeasesResponse{ResponseHeader: resp.GetHeader(), Leases: leases}, nil
+	}
+	return nil, toErr(ctx, err)
+}
+
+func (l *lessor) WatchExpirations(ctx context.Context, opts ...LeaseOption) (<-chan LeaseWatchExpirationsResponse, error) {
+	r := toLeaseWatchExpirationsRequest(opts...)
+	wc, err := l.remote.WatchExpirations(ctx, r, append(l.callOpts, withMax(defaultStreamMaxRetries))...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	// the first response, without expirations, is sent once the stream is set up
+	if _, err = wc.Recv(); err != nil {
+		return nil, toErr(ctx, err)
+	}
+
+	ch := make(chan LeaseWatchExpirationsResponse, LeaseResponseChSize)
+	go func() {
+		defer close(ch)
+		for {
+			resp, err := wc.Recv()
+			wr := LeaseWatchExpirationsResponse{}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				wr.closeErr = toErr(ctx, err)
+			} else {
+				wr.ResponseHeader = resp.GetHeader()
+				wr.Expirations = make([]LeaseExpiration, len(resp.Expirations))
+				for i, e := range resp.Expirations {
+					wr.Expirations[i] = LeaseExpiration{
+						ID:         LeaseID(e.ID),
+						GrantedTTL: e.GrantedTTL,
+						Keys:       e.Keys,
+						Expired:    e.Expired,
+						ParentID:   LeaseID(e.ParentID),
+						Labels:     leaseLabelsFromPB(e.Labels),
+					}
+				}
+			}
+			select {
+			case ch <- wr:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 func (l *lessor) KeepAlive(ctx context.Context, id LeaseID) (<-chan *LeaseKeepAliveResponse, error) {
 	ch := make(chan *LeaseKeepAliveResponse, LeaseResponseChSize)
 
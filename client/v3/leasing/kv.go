@@ -113,6 +113,10 @@ func (lkv *leasingKV) Do(ctx context.Context, op v3.Op) (v3.OpResponse, error) {
 	return v3.OpResponse{}, nil
 }
 
+func (lkv *leasingKV) IndexGet(ctx context.Context, prefix, field, value string, opts ...v3.OpOption) (*v3.GetResponse, error) {
+	return lkv.kv.IndexGet(ctx, prefix, field, value, opts...)
+}
+
 func (lkv *leasingKV) Compact(ctx context.Context, rev int64, opts ...v3.CompactOption) (*v3.CompactResponse, error) {
 	return lkv.kv.Compact(ctx, rev, opts...)
 }
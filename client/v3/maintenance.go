@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -27,15 +28,25 @@ import (
 )
 
 type (
-	DefragmentResponse pb.DefragmentResponse
-	AlarmResponse      pb.AlarmResponse
-	AlarmMember        pb.AlarmMember
-	StatusResponse     pb.StatusResponse
-	HashKVResponse     pb.HashKVResponse
-	MoveLeaderResponse pb.MoveLeaderResponse
-	DowngradeResponse  pb.DowngradeResponse
-
-	DowngradeAction pb.DowngradeRequest_DowngradeAction
+	DefragmentResponse  pb.DefragmentResponse
+	AlarmResponse       pb.AlarmResponse
+	AlarmMember         pb.AlarmMember
+	StatusResponse      pb.StatusResponse
+	HashKVResponse      pb.HashKVResponse
+	MoveLeaderResponse  pb.MoveLeaderResponse
+	DowngradeResponse   pb.DowngradeResponse
+	PrefixQuotaResponse pb.PrefixQuotaResponse
+	HotKeysResponse     pb.HotKeysResponse
+
+	CorruptionCheckResponse pb.CorruptionCheckResponse
+	PrefixStatsResponse     pb.PrefixStatsResponse
+	RevisionPinResponse     pb.RevisionPinResponse
+	SnapshotRangeResponse   pb.SnapshotRangeResponse
+	WatchLagResponse        pb.WatchLagResponse
+	DrainResponse           pb.DrainResponse
+
+	DowngradeAction      pb.DowngradeRequest_DowngradeAction
+	CorruptionCheckScope pb.CorruptionCheckRequest_Scope
 )
 
 const (
@@ -44,6 +55,11 @@ const (
 	DowngradeCancel   = DowngradeAction(pb.DowngradeRequest_CANCEL)
 )
 
+const (
+	CorruptionCheckFull    = CorruptionCheckScope(pb.CorruptionCheckRequest_FULL)
+	CorruptionCheckSampled = CorruptionCheckScope(pb.CorruptionCheckRequest_SAMPLED)
+)
+
 type Maintenance interface {
 	// AlarmList gets all active alarms.
 	AlarmList(ctx context.Context) (*AlarmResponse, error)
@@ -87,6 +103,80 @@ type Maintenance interface {
 	// on the cluster version.
 	// Supported since etcd 3.5.
 	Downgrade(ctx context.Context, action DowngradeAction, version string) (*DowngradeResponse, error)
+
+	// PrefixQuotaList gets the storage quotas of all the key prefixes, with
+	// their current usage.
+	// Supported since etcd 3.6.
+	PrefixQuotaList(ctx context.Context) (*PrefixQuotaResponse, error)
+
+	// PrefixQuotaSet sets the maximum total size, in bytes, of the keys and
+	// values under the prefix, and their maximum number. A limit of 0 means
+	// no limit. Writes that would exceed the quota fail with
+	// rpctypes.ErrPrefixQuotaExceeded.
+	// Supported since etcd 3.6.
+	PrefixQuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*PrefixQuotaResponse, error)
+
+	// PrefixQuotaDelete removes the storage quota of the prefix.
+	// Supported since etcd 3.6.
+	PrefixQuotaDelete(ctx context.Context, prefix string) (*PrefixQuotaResponse, error)
+
+	// HotKeys gets up to limit of the key prefixes with the most read, write and
+	// watch traffic on the member of the endpoint, all of the tracked ones if
+	// limit is 0. Hot key tracking must be enabled on the member.
+	// Supported since etcd 3.6.
+	HotKeys(ctx context.Context, endpoint string, limit int64) (*HotKeysResponse, error)
+
+	// CorruptionCheck gets the result of the last corruption check of the
+	// member of the endpoint. If run is set, the member first runs a check of
+	// the given scope, which fails unless the member is the leader.
+	// Supported since etcd 3.6.
+	CorruptionCheck(ctx context.Context, endpoint string, run bool, scope CorruptionCheckScope) (*CorruptionCheckResponse, error)
+
+	// PrefixStats gets the number of keys, their size and the number of
+	// watchers under each sub-prefix of the prefix depth '/' separated levels
+	// below it, on the member of the endpoint. Prefix statistics must be
+	// enabled on the member.
+	// Supported since etcd 3.6.
+	PrefixStats(ctx context.Context, endpoint, prefix string, depth int64) (*PrefixStatsResponse, error)
+
+	// RevisionPinList gets the revisions pinned against compaction, sorted
+	// by revision.
+	// Supported since etcd 3.6.
+	RevisionPinList(ctx context.Context) (*RevisionPinResponse, error)
+
+	// PinRevision pins the revision on behalf of the owner for ttl seconds,
+	// replacing the previous pin of the owner, the current revision if rev is
+	// 0. Compactions past the oldest pinned revision fail with
+	// rpctypes.ErrRevisionPinned. The pin must be renewed before its TTL
+	// elapses to be kept.
+	// Supported since etcd 3.6.
+	PinRevision(ctx context.Context, owner string, rev, ttl int64) (*RevisionPinResponse, error)
+
+	// UnpinRevision releases the pin of the owner.
+	// Supported since etcd 3.6.
+	UnpinRevision(ctx context.Context, owner string) (*RevisionPinResponse, error)
+
+	// SnapshotRange gets up to limit keys of the range [key, end) at the
+	// revision of the session, the current one if rev is 0 on the first call
+	// of the session. The revision is pinned against compaction until the
+	// last keys of the range are returned, or for a minute after the last
+	// call. The next keys are returned by calling again with the NextKey of
+	// the response while More is set.
+	// Supported since etcd 3.6.
+	SnapshotRange(ctx context.Context, session, key, end string, rev, limit int64) (*SnapshotRangeResponse, error)
+
+	// WatchLag gets up to limit of the watchers of the member of the endpoint
+	// lagging behind its current revision, slowest first, all of them if limit
+	// is 0, and the number of events held in memory for them.
+	// Supported since etcd 3.6.
+	WatchLag(ctx context.Context, endpoint string, limit int64) (*WatchLagResponse, error)
+
+	// Drain drains the member of the endpoint before stopping it: it rejects
+	// the new streams, transfers its leadership and waits for the requests in
+	// flight, up to timeout, or its own drain timeout if 0. Drain returns once
+	// the drain is started.
+	// Supported since etcd 3.6.
+	Drain(ctx context.Context, endpoint string, timeout time.Duration) (*DrainResponse, error)
 }
 
 // SnapshotResponse is aggregated response from the snapshot stream.
@@ -348,3 +438,145 @@ func (m *maintenance) Downgrade(ctx context.Context, action DowngradeAction, ver
 	resp, err := m.remote.Downgrade(ctx, &pb.DowngradeRequest{Action: actionType, Version: version}, m.callOpts...)
 	return (*DowngradeResponse)(resp), toErr(ctx, err)
 }
+
+func (m *maintenance) PrefixQuotaList(ctx context.Context) (*PrefixQuotaResponse, error) {
+	resp, err := m.remote.PrefixQuota(ctx, &pb.PrefixQuotaRequest{Action: pb.PrefixQuotaRequest_GET}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixQuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*PrefixQuotaResponse, error) {
+	req := &pb.PrefixQuotaRequest{
+		Action:   pb.PrefixQuotaRequest_SET,
+		Prefix:   []byte(prefix),
+		MaxBytes: maxBytes,
+		MaxKeys:  maxKeys,
+	}
+	resp, err := m.remote.PrefixQuota(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixQuotaDelete(ctx context.Context, prefix string) (*PrefixQuotaResponse, error) {
+	req := &pb.PrefixQuotaRequest{Action: pb.PrefixQuotaRequest_DELETE, Prefix: []byte(prefix)}
+	resp, err := m.remote.PrefixQuota(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaResponse)(resp), nil
+}
+
+func (m *maintenance) HotKeys(ctx context.Context, endpoint string, limit int64) (*HotKeysResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.HotKeys(ctx, &pb.HotKeysRequest{Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*HotKeysResponse)(resp), nil
+}
+
+func (m *maintenance) CorruptionCheck(ctx context.Context, endpoint string, run bool, scope CorruptionCheckScope) (*CorruptionCheckResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.CorruptionCheck(ctx, &pb.CorruptionCheckRequest{Run: run, Scope: pb.CorruptionCheckRequest_Scope(scope)}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*CorruptionCheckResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixStats(ctx context.Context, endpoint, prefix string, depth int64) (*PrefixStatsResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.PrefixStats(ctx, &pb.PrefixStatsRequest{Prefix: []byte(prefix), Depth: depth}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixStatsResponse)(resp), nil
+}
+
+func (m *maintenance) RevisionPinList(ctx context.Context) (*RevisionPinResponse, error) {
+	resp, err := m.remote.RevisionPin(ctx, &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_GET}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*RevisionPinResponse)(resp), nil
+}
+
+func (m *maintenance) PinRevision(ctx context.Context, owner string, rev, ttl int64) (*RevisionPinResponse, error) {
+	req := &pb.RevisionPinRequest{
+		Action:   pb.RevisionPinRequest_PIN,
+		Owner:    owner,
+		Revision: rev,
+		TTL:      ttl,
+	}
+	resp, err := m.remote.RevisionPin(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*RevisionPinResponse)(resp), nil
+}
+
+func (m *maintenance) UnpinRevision(ctx context.Context, owner string) (*RevisionPinResponse, error) {
+	req := &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_UNPIN, Owner: owner}
+	resp, err := m.remote.RevisionPin(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*RevisionPinResponse)(resp), nil
+}
+
+func (m *maintenance) SnapshotRange(ctx context.Context, session, key, end string, rev, limit int64) (*SnapshotRangeResponse, error) {
+	req := &pb.SnapshotRangeRequest{
+		Session:  session,
+		Revision: rev,
+		Key:      []byte(key),
+		RangeEnd: []byte(end),
+		Limit:    limit,
+	}
+	resp, err := m.remote.SnapshotRange(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*SnapshotRangeResponse)(resp), nil
+}
+
+func (m *maintenance) WatchLag(ctx context.Context, endpoint string, limit int64) (*WatchLagResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.WatchLag(ctx, &pb.WatchLagRequest{Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*WatchLagResponse)(resp), nil
+}
+
+func (m *maintenance) Drain(ctx context.Context, endpoint string, timeout time.Duration) (*DrainResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.Drain(ctx, &pb.DrainRequest{TimeoutSeconds: int64(timeout / time.Second)}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*DrainResponse)(resp), nil
+}
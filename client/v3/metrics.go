@@ -0,0 +1,55 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "client",
+		Name:      "retries_total",
+		Help:      "The total number of RPC attempts retried by the client retry interceptor, by method and the gRPC code that triggered the retry.",
+	},
+		[]string{"grpc_method", "grpc_code"},
+	)
+
+	retriesExhaustedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "client",
+		Name:      "retries_exhausted_total",
+		Help:      "The total number of RPCs that failed after using up all retry attempts, by method and the gRPC code of the last attempt.",
+	},
+		[]string{"grpc_method", "grpc_code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(retriesTotal)
+	prometheus.MustRegister(retriesExhaustedTotal)
+}
+
+// reportRetry records that an attempt of method failed with err and is being retried.
+func reportRetry(method string, err error) {
+	retriesTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+}
+
+// reportRetriesExhausted records that method failed with err after its last retry attempt.
+func reportRetriesExhausted(method string, err error) {
+	retriesExhaustedTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+}
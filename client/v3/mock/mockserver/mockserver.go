@@ -171,6 +171,10 @@ func (m *mockKVServer) Range(context.Context, *pb.RangeRequest) (*pb.RangeRespon
 	return &pb.RangeResponse{}, nil
 }
 
+func (m *mockKVServer) IndexRange(context.Context, *pb.IndexRangeRequest) (*pb.RangeResponse, error) {
+	return &pb.RangeResponse{}, nil
+}
+
 func (m *mockKVServer) Put(context.Context, *pb.PutRequest) (*pb.PutResponse, error) {
 	return &pb.PutResponse{}, nil
 }
@@ -212,3 +216,19 @@ func (s *mockLeaseServer) LeaseTimeToLive(context.Context, *pb.LeaseTimeToLiveRe
 func (s *mockLeaseServer) LeaseLeases(context.Context, *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error) {
 	return &pb.LeaseLeasesResponse{}, nil
 }
+
+func (s *mockLeaseServer) LeaseGrantBatch(context.Context, *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	return &pb.LeaseGrantBatchResponse{}, nil
+}
+
+func (s *mockLeaseServer) LeaseRevokeBatch(context.Context, *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	return &pb.LeaseRevokeBatchResponse{}, nil
+}
+
+func (s *mockLeaseServer) LeaseQuery(context.Context, *pb.LeaseQueryRequest) (*pb.LeaseQueryResponse, error) {
+	return &pb.LeaseQueryResponse{}, nil
+}
+
+func (s *mockLeaseServer) WatchExpirations(*pb.LeaseWatchExpirationsRequest, pb.Lease_WatchExpirationsServer) error {
+	return nil
+}
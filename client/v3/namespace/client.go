@@ -0,0 +1,35 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Wrap overrides the KV, Watcher and Lease interfaces of the client so that
+// all their requests are prefixed with the given string. It returns the
+// client for use with constructors like clientv3.NewWithDefaults:
+//
+//	cli, err := clientv3.NewWithDefaults(cfg)
+//	if err != nil {
+//		// handle error!
+//	}
+//	cli = namespace.Wrap(cli, "my-prefix/")
+func Wrap(c *clientv3.Client, prefix string) *clientv3.Client {
+	c.KV = NewKV(c.KV, prefix)
+	c.Watcher = NewWatcher(c.Watcher, prefix)
+	c.Lease = NewLease(c.Lease, prefix)
+	return c
+}
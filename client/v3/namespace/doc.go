@@ -39,4 +39,8 @@
 //	resp, _ = cli.Get(context.TODO(), "abc")
 //	fmt.Printf("%s\n", resp.Kvs[0].Value)
 //	// Output: 456
+//
+// Wrap overrides all three interfaces at once:
+//
+//	cli = namespace.Wrap(cli, "my-prefix/")
 package namespace
@@ -64,6 +64,15 @@ func (kv *kvPrefix) Get(ctx context.Context, key string, opts ...clientv3.OpOpti
 	return get, nil
 }
 
+func (kv *kvPrefix) IndexGet(ctx context.Context, prefix, field, value string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	get, err := kv.KV.IndexGet(ctx, kv.pfx+prefix, field, value, opts...)
+	if err != nil {
+		return nil, err
+	}
+	kv.unprefixGetResponse(get)
+	return get, nil
+}
+
 func (kv *kvPrefix) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
 	if len(key) == 0 && !(clientv3.IsOptsWithFromKey(opts) || clientv3.IsOptsWithPrefix(opts)) {
 		return nil, rpctypes.ErrEmptyKey
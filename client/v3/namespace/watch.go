@@ -45,6 +45,14 @@ func (w *watcherPrefix) Watch(ctx context.Context, key string, opts ...clientv3.
 	if pfxEnd != nil {
 		opts = append(opts, clientv3.WithRange(string(pfxEnd)))
 	}
+	if ranges := op.Ranges(); len(ranges) > 0 {
+		pfxRanges := make([]clientv3.KeyRange, len(ranges))
+		for i, r := range ranges {
+			rBegin, rEnd := prefixInterval(w.pfx, []byte(r.Key), []byte(r.End))
+			pfxRanges[i] = clientv3.KeyRange{Key: string(rBegin), End: string(rEnd)}
+		}
+		opts = append(opts, clientv3.WithRanges(pfxRanges...))
+	}
 
 	wch := w.Watcher.Watch(ctx, string(pfxBegin), opts...)
 
@@ -14,7 +14,12 @@
 
 package clientv3
 
-import pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+import (
+	"sort"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
 
 type opType int
 
@@ -38,8 +43,10 @@ type Op struct {
 	limit        int64
 	sort         *SortOption
 	serializable bool
+	bounded      bool
 	keysOnly     bool
 	countOnly    bool
+	estimate     bool
 	minModRev    int64
 	maxModRev    int64
 	minCreateRev int64
@@ -56,6 +63,15 @@ type Op struct {
 	// if true, split watch events when total exceeds
 	// "--max-request-bytes" flag value + 512-byte
 	fragment bool
+	// coalesceWindow is the window the watcher coalesces the events of
+	// each key within, disabled if zero
+	coalesceWindow time.Duration
+	// resumeToken resumes the watch right after the revision of the token
+	resumeToken []byte
+	// ranges are other key ranges to watch along with the key range
+	ranges []KeyRange
+	// projection strips the events down to the parts it keeps
+	projection pb.WatchCreateRequest_Projection
 
 	// for put
 	ignoreValue bool
@@ -68,10 +84,15 @@ type Op struct {
 	// filters for watchers
 	filterPut    bool
 	filterDelete bool
+	valueFilter  *pb.WatchValueFilter
 
 	// for put
 	val     []byte
 	leaseID LeaseID
+	ttl     int64
+	// valuePartSize splits the values larger than it into parts put in
+	// separate requests, disabled if zero
+	valuePartSize int
 
 	// txn
 	cmps    []Cmp
@@ -103,6 +124,9 @@ func (op *Op) WithKeyBytes(key []byte) { op.key = key }
 // RangeBytes returns the byte slice holding with the Op's range end, if any.
 func (op Op) RangeBytes() []byte { return op.end }
 
+// Ranges returns the other key ranges watched along with the key range.
+func (op Op) Ranges() []KeyRange { return op.ranges }
+
 // Rev returns the requested revision, if any.
 func (op Op) Rev() int64 { return op.rev }
 
@@ -118,12 +142,18 @@ func (op Op) IsDelete() bool { return op.t == tDeleteRange }
 // IsSerializable returns true if the serializable field is true.
 func (op Op) IsSerializable() bool { return op.serializable }
 
+// IsBoundedStaleness returns true if the bounded staleness field is true.
+func (op Op) IsBoundedStaleness() bool { return op.bounded }
+
 // IsKeysOnly returns whether keysOnly is set.
 func (op Op) IsKeysOnly() bool { return op.keysOnly }
 
 // IsCountOnly returns whether countOnly is set.
 func (op Op) IsCountOnly() bool { return op.countOnly }
 
+// IsEstimate returns whether estimate is set.
+func (op Op) IsEstimate() bool { return op.estimate }
+
 // MinModRev returns the operation's minimum modify revision.
 func (op Op) MinModRev() int64 { return op.minModRev }
 
@@ -155,8 +185,10 @@ func (op Op) toRangeRequest() *pb.RangeRequest {
 		Limit:             op.limit,
 		Revision:          op.rev,
 		Serializable:      op.serializable,
+		BoundedStaleness:  op.bounded,
 		KeysOnly:          op.keysOnly,
 		CountOnly:         op.countOnly,
+		Estimate:          op.estimate,
 		MinModRevision:    op.minModRev,
 		MaxModRevision:    op.maxModRev,
 		MinCreateRevision: op.minCreateRev,
@@ -190,7 +222,7 @@ func (op Op) toRequestOp() *pb.RequestOp {
 	case tRange:
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestRange{RequestRange: op.toRangeRequest()}}
 	case tPut:
-		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease}
+		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease, TtlSeconds: op.ttl}
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: r}}
 	case tDeleteRange:
 		r := &pb.DeleteRangeRequest{Key: op.key, RangeEnd: op.end, PrevKv: op.prevKV}
@@ -245,6 +277,8 @@ func OpDelete(key string, opts ...OpOption) Op {
 	switch {
 	case ret.leaseID != 0:
 		panic("unexpected lease in delete")
+	case ret.ttl != 0:
+		panic("unexpected ttl in delete")
 	case ret.limit != 0:
 		panic("unexpected limit in delete")
 	case ret.rev != 0:
@@ -253,14 +287,22 @@ func OpDelete(key string, opts ...OpOption) Op {
 		panic("unexpected sort in delete")
 	case ret.serializable:
 		panic("unexpected serializable in delete")
+	case ret.bounded:
+		panic("unexpected bounded staleness in delete")
 	case ret.countOnly:
 		panic("unexpected countOnly in delete")
+	case ret.estimate:
+		panic("unexpected estimate in delete")
 	case ret.minModRev != 0, ret.maxModRev != 0:
 		panic("unexpected mod revision filter in delete")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in delete")
-	case ret.filterDelete, ret.filterPut:
+	case ret.filterDelete, ret.filterPut, ret.valueFilter != nil:
 		panic("unexpected filter in delete")
+	case len(ret.ranges) > 0:
+		panic("unexpected ranges in delete")
+	case ret.projection != pb.WatchCreateRequest_FULL:
+		panic("unexpected projection in delete")
 	case ret.createdNotify:
 		panic("unexpected createdNotify in delete")
 	}
@@ -282,14 +324,22 @@ func OpPut(key, val string, opts ...OpOption) Op {
 		panic("unexpected sort in put")
 	case ret.serializable:
 		panic("unexpected serializable in put")
+	case ret.bounded:
+		panic("unexpected bounded staleness in put")
 	case ret.countOnly:
 		panic("unexpected countOnly in put")
+	case ret.estimate:
+		panic("unexpected estimate in put")
 	case ret.minModRev != 0, ret.maxModRev != 0:
 		panic("unexpected mod revision filter in put")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in put")
-	case ret.filterDelete, ret.filterPut:
+	case ret.filterDelete, ret.filterPut, ret.valueFilter != nil:
 		panic("unexpected filter in put")
+	case len(ret.ranges) > 0:
+		panic("unexpected ranges in put")
+	case ret.projection != pb.WatchCreateRequest_FULL:
+		panic("unexpected projection in put")
 	case ret.createdNotify:
 		panic("unexpected createdNotify in put")
 	}
@@ -307,14 +357,20 @@ func opWatch(key string, opts ...OpOption) Op {
 	switch {
 	case ret.leaseID != 0:
 		panic("unexpected lease in watch")
+	case ret.ttl != 0:
+		panic("unexpected ttl in watch")
 	case ret.limit != 0:
 		panic("unexpected limit in watch")
 	case ret.sort != nil:
 		panic("unexpected sort in watch")
 	case ret.serializable:
 		panic("unexpected serializable in watch")
+	case ret.bounded:
+		panic("unexpected bounded staleness in watch")
 	case ret.countOnly:
 		panic("unexpected countOnly in watch")
+	case ret.estimate:
+		panic("unexpected estimate in watch")
 	case ret.minModRev != 0, ret.maxModRev != 0:
 		panic("unexpected mod revision filter in watch")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
@@ -337,6 +393,24 @@ func WithLease(leaseID LeaseID) OpOption {
 	return func(op *Op) { op.leaseID = leaseID }
 }
 
+// WithTTL sets the time to live of a key in 'Put' request, in seconds. The key is
+// deleted once the TTL elapses without the key being put or deleted, without the
+// cost of a lease per key. This option can not be combined with WithLease or
+// WithIgnoreLease.
+func WithTTL(ttl int64) OpOption {
+	return func(op *Op) { op.ttl = ttl }
+}
+
+// WithValueParts puts the value in parts of at most size bytes, each sent in
+// its own request, the key being put along with the last part. It lets the
+// values larger than the request size limit of the server be put, up to its
+// --experimental-max-chunked-value-bytes, once all the members enable the
+// ValueChunking feature. The parts staged by a failed put are discarded by
+// the next compaction. This option is ignored in transactions.
+func WithValueParts(size int) OpOption {
+	return func(op *Op) { op.valuePartSize = size }
+}
+
 // WithLimit limits the number of results to return from 'Get' request.
 // If WithLimit is given a 0 limit, it is treated as no limit.
 func WithLimit(n int64) OpOption { return func(op *Op) { op.limit = n } }
@@ -431,6 +505,17 @@ func WithSerializable() OpOption {
 	return func(op *Op) { op.serializable = true }
 }
 
+// WithBoundedStaleness makes the 'Get' request served locally by the member
+// when its applied index lags the commit index it learned from the leader by
+// at most the lag configured on the server, and linearizable otherwise. It
+// sits between the default linearizable and the serializable consistency:
+// reads are spread over the followers of read heavy clusters, while the
+// staleness of their results stays bounded. It has no effect together with
+// WithSerializable.
+func WithBoundedStaleness() OpOption {
+	return func(op *Op) { op.bounded = true }
+}
+
 // WithKeysOnly makes the 'Get' request return only the keys and the corresponding
 // values will be omitted.
 func WithKeysOnly() OpOption {
@@ -442,6 +527,15 @@ func WithCountOnly() OpOption {
 	return func(op *Op) { op.countOnly = true }
 }
 
+// WithEstimate makes the 'Get' request return only the approximate count of
+// keys and their approximate total size, in the EstimatedSize field of the
+// response, at the current revision. Estimates are computed from the in-memory
+// index of the member without reading the values, so they are cheap to poll
+// on large key spaces; combine with WithSerializable to avoid the quorum read.
+func WithEstimate() OpOption {
+	return func(op *Op) { op.estimate = true }
+}
+
 // WithMinModRev filters out keys for Get with modification revisions less than the given revision.
 func WithMinModRev(rev int64) OpOption { return func(op *Op) { op.minModRev = rev } }
 
@@ -503,6 +597,39 @@ func WithFilterDelete() OpOption {
 	return func(op *Op) { op.filterDelete = true }
 }
 
+// WithFilterValuePrefix discards the PUT events whose value does not start
+// with the prefix from the watcher.
+// Supported since etcd 3.6.
+func WithFilterValuePrefix(prefix string) OpOption {
+	return func(op *Op) { op.watchValueFilter().Prefix = []byte(prefix) }
+}
+
+// WithFilterValueRegex discards the PUT events whose value does not contain
+// a match of the regular expression, in the RE2 syntax, from the watcher.
+// Supported since etcd 3.6.
+func WithFilterValueRegex(expr string) OpOption {
+	return func(op *Op) { op.watchValueFilter().Regex = expr }
+}
+
+// WithFilterValueJSONField discards the PUT events whose value is not a JSON
+// object with the value at the dot separated field path, for example
+// "spec.nodeName", from the watcher. Strings are compared as is, numbers and
+// booleans by their JSON encoding.
+// Supported since etcd 3.6.
+func WithFilterValueJSONField(field, value string) OpOption {
+	return func(op *Op) {
+		vf := op.watchValueFilter()
+		vf.JsonField, vf.JsonValue = field, value
+	}
+}
+
+func (op *Op) watchValueFilter() *pb.WatchValueFilter {
+	if op.valueFilter == nil {
+		op.valueFilter = &pb.WatchValueFilter{}
+	}
+	return op.valueFilter
+}
+
 // WithPrevKV gets the previous key-value pair before the event happens. If the previous KV is already compacted,
 // nothing will be returned.
 func WithPrevKV() OpOption {
@@ -522,6 +649,58 @@ func WithFragment() OpOption {
 	return func(op *Op) { op.fragment = true }
 }
 
+// WithCoalesceWindow makes the watcher receive only the latest event of each
+// key within windows of the given duration, capped at 10 seconds by the
+// server. An event replacing earlier events of its key is flagged as
+// coalesced and, with WithPrevKV, holds the key-value pair before the first
+// of them. It suits watchers only interested in the current state of the keys.
+// Supported since etcd 3.6.
+func WithCoalesceWindow(d time.Duration) OpOption {
+	return func(op *Op) { op.coalesceWindow = d }
+}
+
+// WithEventKeysOnly makes the watcher receive events holding only the key
+// and mod revision of their key-value pair, for watchers only interested in
+// which keys change. The events are sent without their previous key-value
+// pair, even with WithPrevKV.
+// Supported since etcd 3.6.
+func WithEventKeysOnly() OpOption {
+	return func(op *Op) { op.projection = pb.WatchCreateRequest_KEYS_ONLY }
+}
+
+// WithEventMetadataOnly makes the watcher receive events holding their
+// key-value pair without its value. The events are sent without their
+// previous key-value pair, even with WithPrevKV.
+// Supported since etcd 3.6.
+func WithEventMetadataOnly() OpOption {
+	return func(op *Op) { op.projection = pb.WatchCreateRequest_METADATA_ONLY }
+}
+
+// KeyRange is a range of keys from Key to End, exclusive. An empty End is the
+// single key Key, and "\x00" all the keys greater than or equal to Key. The
+// keys with a prefix range up to GetPrefixRangeEnd(prefix).
+type KeyRange struct {
+	Key string
+	End string
+}
+
+// WithRanges makes the watcher also watch the given key ranges, so that a
+// single watcher can watch several unrelated keys or prefixes. The ranges
+// must not overlap the key range of the watcher nor each other.
+// Supported since etcd 3.6.
+func WithRanges(ranges ...KeyRange) OpOption {
+	return func(op *Op) { op.ranges = ranges }
+}
+
+// WithResumeToken makes the watcher resume right after the revision of the
+// resume token of a progress notification received by a previous watcher
+// on the same key range. The watcher is canceled with its compact revision
+// set if the events following the token are compacted. It must not be
+// combined with WithRev. Supported since etcd 3.6.
+func WithResumeToken(token []byte) OpOption {
+	return func(op *Op) { op.resumeToken = token }
+}
+
 // WithIgnoreValue updates the key using its current value.
 // This option can not be combined with non-empty values.
 // Returns an error if the key does not exist.
@@ -544,8 +723,18 @@ func WithIgnoreLease() OpOption {
 type LeaseOp struct {
 	id LeaseID
 
-	// for TimeToLive
+	// for TimeToLive and WatchExpirations
 	attachedKeys bool
+
+	// for TimeToLive
+	stats bool
+
+	// for WatchExpirations
+	expiredOnly bool
+
+	// for Grant
+	parent LeaseID
+	labels map[string]string
 }
 
 // LeaseOption configures lease operations.
@@ -557,15 +746,77 @@ func (op *LeaseOp) applyOpts(opts []LeaseOption) {
 	}
 }
 
-// WithAttachedKeys makes TimeToLive list the keys attached to the given lease ID.
+// WithAttachedKeys makes TimeToLive list the keys attached to the given lease ID,
+// and WatchExpirations list the keys deleted with each lease.
 func WithAttachedKeys() LeaseOption {
 	return func(op *LeaseOp) { op.attachedKeys = true }
 }
 
+// WithLeaseStats makes TimeToLive return the statistics of the given lease
+// ID, e.g. to tell when it was last renewed.
+func WithLeaseStats() LeaseOption {
+	return func(op *LeaseOp) { op.stats = true }
+}
+
+// WithExpiredOnly makes WatchExpirations only stream the leases that expired,
+// leaving out the leases that were revoked.
+func WithExpiredOnly() LeaseOption {
+	return func(op *LeaseOp) { op.expiredOnly = true }
+}
+
+// WithParentLease makes Grant grant a child lease of the given lease, revoked
+// along with it.
+func WithParentLease(parent LeaseID) LeaseOption {
+	return func(op *LeaseOp) { op.parent = parent }
+}
+
+// WithLeaseLabels makes Grant grant a lease carrying the given labels, e.g.
+// naming the component owning it. There may be at most 16 labels, the key
+// and value of each at most 256 bytes long.
+func WithLeaseLabels(labels map[string]string) LeaseOption {
+	return func(op *LeaseOp) { op.labels = labels }
+}
+
+func toLeaseGrantRequest(ttl int64, opts ...LeaseOption) *pb.LeaseGrantRequest {
+	ret := &LeaseOp{}
+	ret.applyOpts(opts)
+	return &pb.LeaseGrantRequest{TTL: ttl, ParentID: int64(ret.parent), Labels: leaseLabelsToPB(ret.labels)}
+}
+
+// leaseLabelsToPB returns the labels sorted by key.
+func leaseLabelsToPB(labels map[string]string) []*pb.LeaseLabel {
+	if len(labels) == 0 {
+		return nil
+	}
+	pbl := make([]*pb.LeaseLabel, 0, len(labels))
+	for k, v := range labels {
+		pbl = append(pbl, &pb.LeaseLabel{Key: k, Value: v})
+	}
+	sort.Slice(pbl, func(i, j int) bool { return pbl[i].Key < pbl[j].Key })
+	return pbl
+}
+
+func leaseLabelsFromPB(pbl []*pb.LeaseLabel) map[string]string {
+	if len(pbl) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(pbl))
+	for _, l := range pbl {
+		labels[l.Key] = l.Value
+	}
+	return labels
+}
+
 func toLeaseTimeToLiveRequest(id LeaseID, opts ...LeaseOption) *pb.LeaseTimeToLiveRequest {
 	ret := &LeaseOp{id: id}
 	ret.applyOpts(opts)
-	return &pb.LeaseTimeToLiveRequest{ID: int64(id), Keys: ret.attachedKeys}
+	return &pb.LeaseTimeToLiveRequest{ID: int64(id), Keys: ret.attachedKeys, Stats: ret.stats}
+}
+
+func toLeaseWatchExpirationsRequest(opts ...LeaseOption) *pb.LeaseWatchExpirationsRequest {
+	ret := &LeaseOp{}
+	ret.applyOpts(opts)
+	return &pb.LeaseWatchExpirationsRequest{Keys: ret.attachedKeys, ExpiredOnly: ret.expiredOnly}
 }
 
 // IsOptsWithPrefix returns true if WithPrefix option is called in the given opts.
@@ -0,0 +1,141 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Recommended client settings, applied by NewWithDefaults to the fields
+// left unset.
+const (
+	DefaultDialTimeout          = 5 * time.Second
+	DefaultDialKeepAliveTime    = 30 * time.Second
+	DefaultDialKeepAliveTimeout = 10 * time.Second
+
+	// minDialKeepAliveTime is the minimum keepalive interval accepted by
+	// the gRPC client; shorter intervals are silently raised by gRPC.
+	minDialKeepAliveTime = 10 * time.Second
+)
+
+// Settings used by NewForKubernetes, matching the kube-apiserver etcd3
+// storage backend.
+const (
+	kubernetesDialTimeout          = 20 * time.Second
+	kubernetesDialKeepAliveTime    = 30 * time.Second
+	kubernetesDialKeepAliveTimeout = 10 * time.Second
+)
+
+// NewWithDefaults creates a new etcdv3 client like New, after filling the
+// unset dial timeout and keepalive settings of the configuration with the
+// recommended values and validating it. Requests are retried with the
+// client's default retry policy. Keys can be namespaced with the
+// "namespace" package.
+func NewWithDefaults(cfg Config) (*Client, error) {
+	applyDefaults(&cfg, DefaultDialTimeout, DefaultDialKeepAliveTime, DefaultDialKeepAliveTimeout)
+	if err := validateConfig(&cfg); err != nil {
+		return nil, err
+	}
+	return newClient(&cfg)
+}
+
+// NewForKubernetes creates a new etcdv3 client like NewWithDefaults, with
+// the settings used by the Kubernetes API server: a longer dial timeout
+// tolerating slow control plane startup, and keepalive probes sent also
+// without active streams, so that connections broken behind load
+// balancers are detected before the next request.
+func NewForKubernetes(cfg Config) (*Client, error) {
+	applyDefaults(&cfg, kubernetesDialTimeout, kubernetesDialKeepAliveTime, kubernetesDialKeepAliveTimeout)
+	cfg.PermitWithoutStream = true
+	if err := validateConfig(&cfg); err != nil {
+		return nil, err
+	}
+	return newClient(&cfg)
+}
+
+func applyDefaults(cfg *Config, dialTimeout, keepAliveTime, keepAliveTimeout time.Duration) {
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = dialTimeout
+	}
+	if cfg.DialKeepAliveTime == 0 {
+		cfg.DialKeepAliveTime = keepAliveTime
+	}
+	if cfg.DialKeepAliveTimeout == 0 {
+		cfg.DialKeepAliveTimeout = keepAliveTimeout
+	}
+}
+
+// validateConfig returns an error on settings that are accepted by New
+// but result in a client that cannot work as expected.
+func validateConfig(cfg *Config) error {
+	if len(cfg.Endpoints) == 0 {
+		return ErrNoAvailableEndpoints
+	}
+	seen := make(map[string]struct{}, len(cfg.Endpoints))
+	for _, ep := range cfg.Endpoints {
+		if err := validateEndpoint(ep); err != nil {
+			return err
+		}
+		if _, ok := seen[ep]; ok {
+			return fmt.Errorf("etcdclient: duplicate endpoint %q", ep)
+		}
+		seen[ep] = struct{}{}
+	}
+	if (cfg.Username == "") != (cfg.Password == "") {
+		return fmt.Errorf("etcdclient: username and password must be set together")
+	}
+	if cfg.TokenSource != nil && cfg.Username != "" {
+		return fmt.Errorf("etcdclient: token source and username must not be set together")
+	}
+	if cfg.DialTimeout < 0 || cfg.DialKeepAliveTime < 0 || cfg.DialKeepAliveTimeout < 0 || cfg.AutoSyncInterval < 0 {
+		return fmt.Errorf("etcdclient: timeouts and intervals must not be negative")
+	}
+	if cfg.DialKeepAliveTime < minDialKeepAliveTime {
+		return fmt.Errorf("etcdclient: keepalive time %v is less than the minimum %v accepted by gRPC", cfg.DialKeepAliveTime, minDialKeepAliveTime)
+	}
+	if cfg.MaxCallRecvMsgSize > 0 && cfg.MaxCallSendMsgSize > cfg.MaxCallRecvMsgSize {
+		return fmt.Errorf("etcdclient: gRPC message recv limit (%d bytes) must be greater than send limit (%d bytes)", cfg.MaxCallRecvMsgSize, cfg.MaxCallSendMsgSize)
+	}
+	return nil
+}
+
+func validateEndpoint(ep string) error {
+	if strings.HasPrefix(ep, "unix:") || strings.HasPrefix(ep, "unixs:") {
+		return nil
+	}
+	if !strings.Contains(ep, "://") {
+		// host:port endpoints
+		if _, err := url.Parse("http://" + ep); err != nil {
+			return fmt.Errorf("etcdclient: invalid endpoint %q: %v", ep, err)
+		}
+		return nil
+	}
+	u, err := url.Parse(ep)
+	if err != nil {
+		return fmt.Errorf("etcdclient: invalid endpoint %q: %v", ep, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return fmt.Errorf("etcdclient: invalid endpoint %q: unsupported scheme %q", ep, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("etcdclient: invalid endpoint %q: missing host", ep)
+	}
+	return nil
+}
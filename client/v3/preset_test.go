@@ -0,0 +1,106 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := Config{DialTimeout: time.Second}
+	applyDefaults(&cfg, DefaultDialTimeout, DefaultDialKeepAliveTime, DefaultDialKeepAliveTimeout)
+	assert.Equal(t, time.Second, cfg.DialTimeout, "set values must be kept")
+	assert.Equal(t, DefaultDialKeepAliveTime, cfg.DialKeepAliveTime)
+	assert.Equal(t, DefaultDialKeepAliveTimeout, cfg.DialKeepAliveTimeout)
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			Endpoints:         []string{"http://127.0.0.1:2379", "127.0.0.2:2379", "unix://localhost:2379"},
+			DialKeepAliveTime: DefaultDialKeepAliveTime,
+		}
+	}
+	tests := []struct {
+		name    string
+		update  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			update: func(cfg *Config) {},
+		},
+		{
+			name:    "no endpoints",
+			update:  func(cfg *Config) { cfg.Endpoints = nil },
+			wantErr: ErrNoAvailableEndpoints.Error(),
+		},
+		{
+			name:    "unsupported scheme",
+			update:  func(cfg *Config) { cfg.Endpoints = []string{"tcp://127.0.0.1:2379"} },
+			wantErr: "unsupported scheme",
+		},
+		{
+			name:    "missing host",
+			update:  func(cfg *Config) { cfg.Endpoints = []string{"https://"} },
+			wantErr: "missing host",
+		},
+		{
+			name:    "duplicate endpoint",
+			update:  func(cfg *Config) { cfg.Endpoints = append(cfg.Endpoints, cfg.Endpoints[0]) },
+			wantErr: "duplicate endpoint",
+		},
+		{
+			name:    "username without password",
+			update:  func(cfg *Config) { cfg.Username = "root" },
+			wantErr: "username and password",
+		},
+		{
+			name:    "negative timeout",
+			update:  func(cfg *Config) { cfg.DialTimeout = -time.Second },
+			wantErr: "must not be negative",
+		},
+		{
+			name:    "keepalive too short",
+			update:  func(cfg *Config) { cfg.DialKeepAliveTime = time.Second },
+			wantErr: "keepalive time",
+		},
+		{
+			name: "send limit above recv limit",
+			update: func(cfg *Config) {
+				cfg.MaxCallSendMsgSize = 2 * 1024 * 1024
+				cfg.MaxCallRecvMsgSize = 1024 * 1024
+			},
+			wantErr: "recv limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.update(&cfg)
+			err := validateConfig(&cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -105,6 +105,10 @@ func (rkv *retryKVClient) Range(ctx context.Context, in *pb.RangeRequest, opts .
 	return rkv.kc.Range(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rkv *retryKVClient) IndexRange(ctx context.Context, in *pb.IndexRangeRequest, opts ...grpc.CallOption) (resp *pb.RangeResponse, err error) {
+	return rkv.kc.IndexRange(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rkv *retryKVClient) Put(ctx context.Context, in *pb.PutRequest, opts ...grpc.CallOption) (resp *pb.PutResponse, err error) {
 	return rkv.kc.Put(ctx, in, opts...)
 }
@@ -140,6 +144,10 @@ func (rlc *retryLeaseClient) LeaseLeases(ctx context.Context, in *pb.LeaseLeases
 	return rlc.lc.LeaseLeases(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseQuery(ctx context.Context, in *pb.LeaseQueryRequest, opts ...grpc.CallOption) (resp *pb.LeaseQueryResponse, err error) {
+	return rlc.lc.LeaseQuery(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rlc *retryLeaseClient) LeaseGrant(ctx context.Context, in *pb.LeaseGrantRequest, opts ...grpc.CallOption) (resp *pb.LeaseGrantResponse, err error) {
 	return rlc.lc.LeaseGrant(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
@@ -148,10 +156,22 @@ func (rlc *retryLeaseClient) LeaseRevoke(ctx context.Context, in *pb.LeaseRevoke
 	return rlc.lc.LeaseRevoke(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseGrantBatch(ctx context.Context, in *pb.LeaseGrantBatchRequest, opts ...grpc.CallOption) (resp *pb.LeaseGrantBatchResponse, err error) {
+	return rlc.lc.LeaseGrantBatch(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rlc *retryLeaseClient) LeaseRevokeBatch(ctx context.Context, in *pb.LeaseRevokeBatchRequest, opts ...grpc.CallOption) (resp *pb.LeaseRevokeBatchResponse, err error) {
+	return rlc.lc.LeaseRevokeBatch(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rlc *retryLeaseClient) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (stream pb.Lease_LeaseKeepAliveClient, err error) {
 	return rlc.lc.LeaseKeepAlive(ctx, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) WatchExpirations(ctx context.Context, in *pb.LeaseWatchExpirationsRequest, opts ...grpc.CallOption) (stream pb.Lease_WatchExpirationsClient, err error) {
+	return rlc.lc.WatchExpirations(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 type retryClusterClient struct {
 	cc pb.ClusterClient
 }
@@ -183,6 +203,10 @@ func (rcc *retryClusterClient) MemberPromote(ctx context.Context, in *pb.MemberP
 	return rcc.cc.MemberPromote(ctx, in, opts...)
 }
 
+func (rcc *retryClusterClient) MemberReplace(ctx context.Context, in *pb.MemberReplaceRequest, opts ...grpc.CallOption) (resp *pb.MemberReplaceResponse, err error) {
+	return rcc.cc.MemberReplace(ctx, in, opts...)
+}
+
 type retryMaintenanceClient struct {
 	mc pb.MaintenanceClient
 }
@@ -226,6 +250,38 @@ func (rmc *retryMaintenanceClient) Downgrade(ctx context.Context, in *pb.Downgra
 	return rmc.mc.Downgrade(ctx, in, opts...)
 }
 
+func (rmc *retryMaintenanceClient) PrefixQuota(ctx context.Context, in *pb.PrefixQuotaRequest, opts ...grpc.CallOption) (resp *pb.PrefixQuotaResponse, err error) {
+	return rmc.mc.PrefixQuota(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) HotKeys(ctx context.Context, in *pb.HotKeysRequest, opts ...grpc.CallOption) (resp *pb.HotKeysResponse, err error) {
+	return rmc.mc.HotKeys(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) CorruptionCheck(ctx context.Context, in *pb.CorruptionCheckRequest, opts ...grpc.CallOption) (resp *pb.CorruptionCheckResponse, err error) {
+	return rmc.mc.CorruptionCheck(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) PrefixStats(ctx context.Context, in *pb.PrefixStatsRequest, opts ...grpc.CallOption) (resp *pb.PrefixStatsResponse, err error) {
+	return rmc.mc.PrefixStats(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) RevisionPin(ctx context.Context, in *pb.RevisionPinRequest, opts ...grpc.CallOption) (resp *pb.RevisionPinResponse, err error) {
+	return rmc.mc.RevisionPin(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) SnapshotRange(ctx context.Context, in *pb.SnapshotRangeRequest, opts ...grpc.CallOption) (resp *pb.SnapshotRangeResponse, err error) {
+	return rmc.mc.SnapshotRange(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) WatchLag(ctx context.Context, in *pb.WatchLagRequest, opts ...grpc.CallOption) (resp *pb.WatchLagResponse, err error) {
+	return rmc.mc.WatchLag(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) Drain(ctx context.Context, in *pb.DrainRequest, opts ...grpc.CallOption) (resp *pb.DrainResponse, err error) {
+	return rmc.mc.Drain(ctx, in, opts...)
+}
+
 type retryAuthClient struct {
 	ac pb.AuthClient
 }
@@ -301,6 +357,26 @@ func (rac *retryAuthClient) RoleRevokePermission(ctx context.Context, in *pb.Aut
 	return rac.ac.RoleRevokePermission(ctx, in, opts...)
 }
 
+func (rac *retryAuthClient) RoleSetMaxLeaseTTL(ctx context.Context, in *pb.AuthRoleSetMaxLeaseTTLRequest, opts ...grpc.CallOption) (resp *pb.AuthRoleSetMaxLeaseTTLResponse, err error) {
+	return rac.ac.RoleSetMaxLeaseTTL(ctx, in, opts...)
+}
+
+func (rac *retryAuthClient) RoleSetQuota(ctx context.Context, in *pb.AuthRoleSetQuotaRequest, opts ...grpc.CallOption) (resp *pb.AuthRoleSetQuotaResponse, err error) {
+	return rac.ac.RoleSetQuota(ctx, in, opts...)
+}
+
+func (rac *retryAuthClient) AuthSetBcryptCost(ctx context.Context, in *pb.AuthSetBcryptCostRequest, opts ...grpc.CallOption) (resp *pb.AuthSetBcryptCostResponse, err error) {
+	return rac.ac.AuthSetBcryptCost(ctx, in, opts...)
+}
+
+func (rac *retryAuthClient) AuthTokenList(ctx context.Context, in *pb.AuthTokenListRequest, opts ...grpc.CallOption) (resp *pb.AuthTokenListResponse, err error) {
+	return rac.ac.AuthTokenList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rac *retryAuthClient) AuthTokenRevoke(ctx context.Context, in *pb.AuthTokenRevokeRequest, opts ...grpc.CallOption) (resp *pb.AuthTokenRevokeResponse, err error) {
+	return rac.ac.AuthTokenRevoke(ctx, in, opts...)
+}
+
 func (rac *retryAuthClient) Authenticate(ctx context.Context, in *pb.AuthenticateRequest, opts ...grpc.CallOption) (resp *pb.AuthenticateResponse, err error) {
 	return rac.ac.Authenticate(ctx, in, opts...)
 }
@@ -52,6 +52,9 @@ func (c *Client) unaryClientInterceptor(optFuncs ...retryOption) grpc.UnaryClien
 			if err := waitRetryBackoff(ctx, attempt, callOpts); err != nil {
 				return err
 			}
+			if attempt > 0 {
+				reportRetry(method, lastErr)
+			}
 			c.GetLogger().Debug(
 				"retrying of unary invoker",
 				zap.String("target", cc.Target()),
@@ -93,6 +96,7 @@ func (c *Client) unaryClientInterceptor(optFuncs ...retryOption) grpc.UnaryClien
 				return lastErr
 			}
 		}
+		reportRetriesExhausted(method, lastErr)
 		return lastErr
 	}
 }
@@ -132,6 +136,7 @@ func (c *Client) streamClientInterceptor(optFuncs ...retryOption) grpc.StreamCli
 		}
 		retryingStreamer := &serverStreamingRetryingStream{
 			client:       c,
+			method:       method,
 			ClientStream: newStreamer,
 			callOpts:     callOpts,
 			ctx:          ctx,
@@ -149,7 +154,7 @@ func (c *Client) shouldRefreshToken(err error, callOpts *options) bool {
 	if rpctypes.Error(err) == rpctypes.ErrUserEmpty {
 		// refresh the token when username, password is present but the server returns ErrUserEmpty
 		// which is possible when the client token is cleared somehow
-		return c.authTokenBundle != nil // equal to c.Username != "" && c.Password != ""
+		return c.authTokenBundle != nil // equal to c.Username != "" && c.Password != "" or a token source
 	}
 
 	return callOpts.retryAuth &&
@@ -159,7 +164,7 @@ func (c *Client) shouldRefreshToken(err error, callOpts *options) bool {
 func (c *Client) refreshToken(ctx context.Context) error {
 	if c.authTokenBundle == nil {
 		// c.authTokenBundle will be initialized only when
-		// c.Username != "" && c.Password != "".
+		// c.Username != "" && c.Password != "", or with a token source.
 		//
 		// When users use the TLS CommonName based authentication, the
 		// authTokenBundle is always nil. But it's possible for the clients
@@ -179,6 +184,7 @@ func (c *Client) refreshToken(ctx context.Context) error {
 type serverStreamingRetryingStream struct {
 	grpc.ClientStream
 	client        *Client
+	method        string
 	bufferedSends []interface{} // single message that the client can sen
 	receivedGood  bool          // indicates whether any prior receives were successful
 	wasClosedSend bool          // indicates that CloseSend was closed
@@ -233,6 +239,7 @@ func (s *serverStreamingRetryingStream) RecvMsg(m interface{}) error {
 		if err := waitRetryBackoff(s.ctx, attempt, s.callOpts); err != nil {
 			return err
 		}
+		reportRetry(s.method, lastErr)
 		newStream, err := s.reestablishStreamAndResendBuffer(s.ctx)
 		if err != nil {
 			s.client.lg.Error("failed reestablishStreamAndResendBuffer", zap.Error(err))
@@ -246,6 +253,7 @@ func (s *serverStreamingRetryingStream) RecvMsg(m interface{}) error {
 			return lastErr
 		}
 	}
+	reportRetriesExhausted(s.method, lastErr)
 	return lastErr
 }
 
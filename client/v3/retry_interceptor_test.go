@@ -15,9 +15,18 @@
 package clientv3
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/v3/credentials"
@@ -138,3 +147,47 @@ func TestClientShouldRefreshToken(t *testing.T) {
 		})
 	}
 }
+
+func TestUnaryClientInterceptorRetryMetrics(t *testing.T) {
+	const method = "/etcdserverpb.KV/Range"
+	c := &Client{lg: zaptest.NewLogger(t), lgMu: new(sync.RWMutex)}
+	cc, err := grpc.Dial("passthrough:///localhost:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	unavailable := codes.Unavailable.String()
+	retried := testutil.ToFloat64(retriesTotal.WithLabelValues(method, unavailable))
+	exhausted := testutil.ToFloat64(retriesExhaustedTotal.WithLabelValues(method, unavailable))
+
+	interceptor := c.unaryClientInterceptor(withMax(3), withRetryPolicy(repeatable), withBackoff(func(uint) time.Duration { return 0 }))
+	failures := 2
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if failures > 0 {
+			failures--
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+	if err = interceptor(context.Background(), method, nil, nil, cc, invoker); err != nil {
+		t.Fatalf("expected the call to succeed after retries, got %v", err)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues(method, unavailable)) - retried; got != 2 {
+		t.Errorf("expected 2 retries, got %v", got)
+	}
+	if got := testutil.ToFloat64(retriesExhaustedTotal.WithLabelValues(method, unavailable)) - exhausted; got != 0 {
+		t.Errorf("expected no exhausted retries, got %v", got)
+	}
+
+	failures = 3
+	if err = interceptor(context.Background(), method, nil, nil, cc, invoker); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected %v, got %v", codes.Unavailable, err)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues(method, unavailable)) - retried; got != 4 {
+		t.Errorf("expected 4 retries, got %v", got)
+	}
+	if got := testutil.ToFloat64(retriesExhaustedTotal.WithLabelValues(method, unavailable)) - exhausted; got != 1 {
+		t.Errorf("expected 1 exhausted retry, got %v", got)
+	}
+}
@@ -100,6 +100,10 @@ type WatchResponse struct {
 	// CompactRevision is the minimum revision the watcher may receive.
 	CompactRevision int64
 
+	// LostRevisions is set along with CompactRevision to the number of
+	// revisions whose events were compacted before the watcher received them.
+	LostRevisions int64
+
 	// Canceled is used to indicate watch failure.
 	// If the watch failed and the stream was about to close, before the channel is closed,
 	// the channel sends a final response that has Canceled set to true with a non-nil Err().
@@ -108,6 +112,15 @@ type WatchResponse struct {
 	// Created is used to indicate the creation of the watcher.
 	Created bool
 
+	// ResumeToken is set on progress notifications. Given to
+	// WithResumeToken, it resumes a watch right after the revision of the
+	// notification.
+	ResumeToken []byte
+
+	// DroppedEvents is the number of events the server dropped before this
+	// response since the watcher could not keep up with its delivery rate limit.
+	DroppedEvents int64
+
 	closeErr error
 
 	// cancelReason is a reason of canceling watch
@@ -216,9 +229,19 @@ type watchRequest struct {
 	// if true, split watch events when total exceeds
 	// "--max-request-bytes" flag value + 512-byte
 	fragment bool
+	// coalesceWindow is the window the events of each key are coalesced within
+	coalesceWindow time.Duration
+	// resumeToken resumes the watcher right after the revision of the token
+	resumeToken []byte
+	// ranges are other key ranges to watch along with [key, end)
+	ranges []KeyRange
 
 	// filters is the list of events to filter out
 	filters []pb.WatchCreateRequest_FilterType
+	// valueFilter filters out the put events whose value does not match it
+	valueFilter *pb.WatchValueFilter
+	// projection strips the events down to the parts it keeps
+	projection pb.WatchCreateRequest_Projection
 	// get the previous key-value pair before the event happens
 	prevKV bool
 	// retc receives a chan WatchResponse once the watcher is established
@@ -244,6 +267,8 @@ type watcherStream struct {
 	closing bool
 	// id is the registered watch id on the grpc stream
 	id int64
+	// closeHeader is the header of the response that failed the watch creation
+	closeHeader pb.ResponseHeader
 
 	// buf holds all events received from etcd but not yet consumed by the client
 	buf []*WatchResponse
@@ -318,7 +343,12 @@ func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) Watch
 		rev:            ow.rev,
 		progressNotify: ow.progressNotify,
 		fragment:       ow.fragment,
+		coalesceWindow: ow.coalesceWindow,
+		resumeToken:    ow.resumeToken,
+		ranges:         ow.ranges,
 		filters:        filters,
+		valueFilter:    ow.valueFilter,
+		projection:     ow.projection,
 		prevKV:         ow.prevKV,
 		retc:           make(chan chan WatchResponse, 1),
 	}
@@ -464,6 +494,9 @@ func (w *watchGrpcStream) addSubstream(resp *pb.WatchResponse, ws *watcherStream
 	// check watch ID for backward compatibility (<= v3.3)
 	if resp.WatchId == InvalidWatchID || (resp.Canceled && resp.CancelReason != "") {
 		w.closeErr = v3rpc.Error(errors.New(resp.CancelReason))
+		if resp.Header != nil {
+			ws.closeHeader = *resp.Header
+		}
 		// failed; no channel
 		close(ws.recvc)
 		return
@@ -489,7 +522,7 @@ func (w *watchGrpcStream) closeSubstream(ws *watcherStream) {
 	}
 	// close subscriber's channel
 	if closeErr := w.closeErr; closeErr != nil && ws.initReq.ctx.Err() == nil {
-		go w.sendCloseSubstream(ws, &WatchResponse{Canceled: true, closeErr: w.closeErr})
+		go w.sendCloseSubstream(ws, &WatchResponse{Header: ws.closeHeader, Canceled: true, closeErr: w.closeErr})
 	} else if ws.outc != nil {
 		close(ws.outc)
 	}
@@ -632,6 +665,12 @@ func (w *watchGrpcStream) run() {
 
 			case pbresp.Canceled && pbresp.CompactRevision == 0:
 				delete(cancelSet, pbresp.WatchId)
+				if pbresp.CancelReason != "" && w.dispatchEvent(pbresp) {
+					// the server canceled the watch; the substream closes
+					// once it posts the response with its reason
+					cur = nil
+					break
+				}
 				if ws, ok := w.substreams[pbresp.WatchId]; ok {
 					// signal to stream goroutine to update closingc
 					close(ws.recvc)
@@ -752,8 +791,11 @@ func (w *watchGrpcStream) dispatchEvent(pbresp *pb.WatchResponse) bool {
 		Header:          *pbresp.Header,
 		Events:          events,
 		CompactRevision: pbresp.CompactRevision,
+		LostRevisions:   pbresp.LostRevisions,
 		Created:         pbresp.Created,
 		Canceled:        pbresp.Canceled,
+		ResumeToken:     pbresp.ResumeToken,
+		DroppedEvents:   pbresp.DroppedEvents,
 		cancelReason:    pbresp.CancelReason,
 	}
 
@@ -874,7 +916,9 @@ func (w *watchGrpcStream) serveSubstream(ws *watcherStream, resumec chan struct{
 					// If the revision is only bound on the first observed event,
 					// if wch is disconnected before the Put is issued, then reconnects
 					// after it is committed, it'll miss the Put.
-					if ws.initReq.rev == 0 {
+					// A resumed watch has no such revision until it receives
+					// events or a progress notification.
+					if ws.initReq.rev == 0 && ws.initReq.resumeToken == nil {
 						nextRev = wr.Header.Revision
 					}
 				}
@@ -887,6 +931,10 @@ func (w *watchGrpcStream) serveSubstream(ws *watcherStream, resumec chan struct{
 				nextRev = wr.Events[len(wr.Events)-1].Kv.ModRevision + 1
 			}
 
+			if nextRev != 0 {
+				// resume from the revision tracked from now on
+				ws.initReq.resumeToken = nil
+			}
 			ws.initReq.rev = nextRev
 
 			// created event is already sent above,
@@ -1048,13 +1096,20 @@ func (w *watchGrpcStream) openWatchClient() (ws pb.Watch_WatchClient, err error)
 // toPB converts an internal watch request structure to its protobuf WatchRequest structure.
 func (wr *watchRequest) toPB() *pb.WatchRequest {
 	req := &pb.WatchCreateRequest{
-		StartRevision:  wr.rev,
-		Key:            []byte(wr.key),
-		RangeEnd:       []byte(wr.end),
-		ProgressNotify: wr.progressNotify,
-		Filters:        wr.filters,
-		PrevKv:         wr.prevKV,
-		Fragment:       wr.fragment,
+		StartRevision:    wr.rev,
+		Key:              []byte(wr.key),
+		RangeEnd:         []byte(wr.end),
+		ProgressNotify:   wr.progressNotify,
+		Filters:          wr.filters,
+		PrevKv:           wr.prevKV,
+		Fragment:         wr.fragment,
+		ValueFilter:      wr.valueFilter,
+		CoalesceWindowMs: wr.coalesceWindow.Milliseconds(),
+		ResumeToken:      wr.resumeToken,
+		Projection:       wr.projection,
+	}
+	for _, r := range wr.ranges {
+		req.Ranges = append(req.Ranges, &pb.WatchKeyRange{Key: []byte(r.Key), RangeEnd: []byte(r.End)})
 	}
 	cr := &pb.WatchRequest_CreateRequest{CreateRequest: req}
 	return &pb.WatchRequest{RequestUnion: cr}
@@ -16,6 +16,7 @@ package command
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -33,6 +34,8 @@ func NewAuthCommand() *cobra.Command {
 	ac.AddCommand(newAuthEnableCommand())
 	ac.AddCommand(newAuthDisableCommand())
 	ac.AddCommand(newAuthStatusCommand())
+	ac.AddCommand(newAuthSetBcryptCostCommand())
+	ac.AddCommand(newAuthTokenCommand())
 
 	return ac
 }
@@ -122,3 +125,32 @@ func authDisableCommandFunc(cmd *cobra.Command, args []string) {
 
 	fmt.Println("Authentication Disabled")
 }
+
+func newAuthSetBcryptCostCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-bcrypt-cost <cost>",
+		Short: "Raises the bcrypt cost of the passwords",
+		Long:  "Raises the bcrypt cost of the passwords of the cluster. The passwords hashed with a lower cost are rehashed when their users next authenticate.",
+		Run:   authSetBcryptCostCommandFunc,
+	}
+}
+
+// authSetBcryptCostCommandFunc executes the "auth set-bcrypt-cost" command.
+func authSetBcryptCostCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth set-bcrypt-cost command requires the cost as its argument"))
+	}
+	cost, err := strconv.Atoi(args[0])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad bcrypt cost %q: %v", args[0], err))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Auth.AuthSetBcryptCost(ctx, cost)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthSetBcryptCost(cost, *resp)
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	v3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var tokenUser string
+
+// newAuthTokenCommand returns the cobra command for "auth token".
+func newAuthTokenCommand() *cobra.Command {
+	tc := &cobra.Command{
+		Use:   "token <subcommand>",
+		Short: "Auth token related commands",
+	}
+
+	tc.AddCommand(newAuthTokenListCommand())
+	tc.AddCommand(newAuthTokenRevokeCommand())
+
+	return tc
+}
+
+func newAuthTokenListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the valid auth tokens issued by the member",
+		Run:   authTokenListCommandFunc,
+	}
+	cmd.Flags().StringVar(&tokenUser, "user", "", "Lists only the tokens of the user")
+	return cmd
+}
+
+func newAuthTokenRevokeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [<token ID>]",
+		Short: "Revokes an auth token, or all the auth tokens of a user",
+		Run:   authTokenRevokeCommandFunc,
+	}
+	cmd.Flags().StringVar(&tokenUser, "user", "", "Revokes all the tokens of the user")
+	return cmd
+}
+
+// authTokenListCommandFunc executes the "auth token list" command.
+func authTokenListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth token list command does not accept any arguments"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Auth.AuthTokenList(ctx, tokenUser)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthTokenList(*resp)
+}
+
+// authTokenRevokeCommandFunc executes the "auth token revoke" command.
+func authTokenRevokeCommandFunc(cmd *cobra.Command, args []string) {
+	if (len(args) == 1) == (tokenUser != "") || len(args) > 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth token revoke command requires either a token ID as its argument or the --user flag"))
+	}
+
+	var (
+		resp *v3.AuthTokenRevokeResponse
+		err  error
+	)
+	ctx, cancel := commandCtx(cmd)
+	if tokenUser != "" {
+		resp, err = mustClientFromCmd(cmd).Auth.AuthTokenRevokeUser(ctx, tokenUser)
+	} else {
+		resp, err = mustClientFromCmd(cmd).Auth.AuthTokenRevoke(ctx, args[0])
+	}
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthTokenRevoke(*resp)
+}
@@ -15,6 +15,7 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -34,6 +35,20 @@ import (
 var epClusterEndpoints bool
 var epHashKVRev int64
 
+const (
+	// epHashKVAlignAttempts is the number of times the endpoints are hashed
+	// before giving up on aligning them on a common revision.
+	epHashKVAlignAttempts = 3
+	// epHashKVAlignRetryInterval is the time a lagging member is given to
+	// catch up between the attempts.
+	epHashKVAlignRetryInterval = time.Second
+)
+
+// errHashKVUnaligned is returned when the endpoints could not be hashed over
+// the same range of revisions, e.g. because one of them is catching up with
+// a compaction.
+var errHashKVUnaligned = errors.New("could not hash the endpoints over the same revisions, some members are lagging behind")
+
 // NewEndpointCommand returns the cobra command for "endpoint".
 func NewEndpointCommand() *cobra.Command {
 	ec := &cobra.Command{
@@ -74,9 +89,13 @@ func newEpHashKVCommand() *cobra.Command {
 	hc := &cobra.Command{
 		Use:   "hashkv",
 		Short: "Prints the KV history hash for each endpoint in --endpoints",
-		Run:   epHashKVCommandFunc,
+		Long: `When --rev is not set and there are several endpoints, the endpoints are hashed at the lowest
+current revision among them, so that the hashes are comparable, and a verdict comparing them is printed.
+The command fails if the hashes differ.
+`,
+		Run: epHashKVCommandFunc,
 	}
-	hc.PersistentFlags().Int64Var(&epHashKVRev, "rev", 0, "maximum revision to hash (default: all revisions)")
+	hc.PersistentFlags().Int64Var(&epHashKVRev, "rev", 0, "maximum revision to hash (default: the lowest current revision of the endpoints)")
 	return hc
 }
 
@@ -145,10 +164,14 @@ func epHealthCommandFunc(cmd *cobra.Command, args []string) {
 
 			if eh.Health {
 				resp, err := cli.AlarmList(ctx)
-				if err == nil && len(resp.Alarms) > 0 {
+				var alarms []*etcdserverpb.AlarmMember
+				if err == nil {
+					alarms = unhealthyAlarms(resp.Alarms)
+				}
+				if err == nil && len(alarms) > 0 {
 					eh.Health = false
 					eh.Error = "Active Alarm(s): "
-					for _, v := range resp.Alarms {
+					for _, v := range alarms {
 						switch v.Alarm {
 						case etcdserverpb.AlarmType_NOSPACE:
 							eh.Error = eh.Error + "NOSPACE "
@@ -185,6 +208,17 @@ func epHealthCommandFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+// unhealthyAlarms filters out the warning alarms, which do not make an endpoint unhealthy.
+func unhealthyAlarms(alarms []*etcdserverpb.AlarmMember) []*etcdserverpb.AlarmMember {
+	var unhealthy []*etcdserverpb.AlarmMember
+	for _, a := range alarms {
+		if a.Alarm != etcdserverpb.AlarmType_QUOTAGROWN && a.Alarm != etcdserverpb.AlarmType_WATCHBACKLOG && a.Alarm != etcdserverpb.AlarmType_LEASEKEYS {
+			unhealthy = append(unhealthy, a)
+		}
+	}
+	return unhealthy
+}
+
 type epStatus struct {
 	Ep   string                   `json:"Endpoint"`
 	Resp *clientv3.StatusResponse `json:"Status"`
@@ -222,16 +256,71 @@ type epHashKV struct {
 	Resp *clientv3.HashKVResponse `json:"HashKV"`
 }
 
+// epHashKVVerdict compares the hashes of endpoints taken at the same revision.
+type epHashKVVerdict struct {
+	Revision        int64
+	CompactRevision int64
+	// Endpoints is the number of endpoints, including the unreachable ones.
+	Endpoints int
+	// Mismatched lists the endpoints whose hash differs from the hash shared
+	// by most endpoints.
+	Mismatched []string
+	// Unreachable lists the endpoints that could not be hashed.
+	Unreachable []string
+}
+
 func epHashKVCommandFunc(cmd *cobra.Command, args []string) {
+	endpoints := endpointsFromCluster(cmd)
+	if epHashKVRev != 0 || len(endpoints) < 2 {
+		hashList, err := hashKVs(cmd, endpoints, epHashKVRev)
+		display.EndpointHashKV(hashList)
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		}
+		return
+	}
+
+	var hashList []epHashKV
+	var unreachable []string
+	var err error
+	for i := 0; i < epHashKVAlignAttempts; i++ {
+		if i > 0 {
+			time.Sleep(epHashKVAlignRetryInterval)
+		}
+		if hashList, unreachable, err = alignedHashKVs(cmd, endpoints); err != errHashKVUnaligned {
+			break
+		}
+	}
+
+	display.EndpointHashKV(hashList)
+	if err == errHashKVUnaligned {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	if len(hashList) > 0 {
+		v := hashKVVerdict(hashList, unreachable)
+		display.EndpointHashKVVerdict(v)
+		if len(v.Mismatched) > 0 && err == nil {
+			err = fmt.Errorf("the hashes of %d out of %d endpoints differ at revision %d", len(v.Mismatched), v.Endpoints, v.Revision)
+		}
+	}
+
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+}
+
+// hashKVs hashes the endpoints up to the given revision, skipping the
+// endpoints that fail.
+func hashKVs(cmd *cobra.Command, endpoints []string, rev int64) ([]epHashKV, error) {
 	cfg := clientConfigFromCmd(cmd)
 
 	var hashList []epHashKV
 	var err error
-	for _, ep := range endpointsFromCluster(cmd) {
+	for _, ep := range endpoints {
 		cfg.Endpoints = []string{ep}
 		c := mustClient(cfg)
 		ctx, cancel := commandCtx(cmd)
-		resp, serr := c.HashKV(ctx, ep, epHashKVRev)
+		resp, serr := c.HashKV(ctx, ep, rev)
 		cancel()
 		c.Close()
 		if serr != nil {
@@ -241,12 +330,89 @@ func epHashKVCommandFunc(cmd *cobra.Command, args []string) {
 		}
 		hashList = append(hashList, epHashKV{Ep: ep, Resp: resp})
 	}
+	return hashList, err
+}
 
-	display.EndpointHashKV(hashList)
+// alignedHashKVs hashes the endpoints at the lowest current revision among
+// them, so that every member hashes the same range of revisions. It returns
+// errHashKVUnaligned if a member has compacted past that revision, or the
+// members do not agree on the compact revision yet. The endpoints failing to
+// return their status or their hash are returned as unreachable.
+func alignedHashKVs(cmd *cobra.Command, endpoints []string) (hashList []epHashKV, unreachable []string, err error) {
+	cfg := clientConfigFromCmd(cmd)
 
-	if err != nil {
-		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	var rev int64
+	var live []string
+	for _, ep := range endpoints {
+		cfg.Endpoints = []string{ep}
+		c := mustClient(cfg)
+		ctx, cancel := commandCtx(cmd)
+		resp, serr := c.Status(ctx, ep)
+		cancel()
+		c.Close()
+		if serr != nil {
+			err = serr
+			fmt.Fprintf(os.Stderr, "Failed to get the status of endpoint %s (%v)\n", ep, serr)
+			unreachable = append(unreachable, ep)
+			continue
+		}
+		if rev == 0 || resp.Header.Revision < rev {
+			rev = resp.Header.Revision
+		}
+		live = append(live, ep)
+	}
+
+	for _, ep := range live {
+		cfg.Endpoints = []string{ep}
+		c := mustClient(cfg)
+		ctx, cancel := commandCtx(cmd)
+		resp, serr := c.HashKV(ctx, ep, rev)
+		cancel()
+		c.Close()
+		if serr == rpctypes.ErrCompacted {
+			return nil, nil, errHashKVUnaligned
+		}
+		if serr != nil {
+			err = serr
+			fmt.Fprintf(os.Stderr, "Failed to get the hash of endpoint %s (%v)\n", ep, serr)
+			unreachable = append(unreachable, ep)
+			continue
+		}
+		if len(hashList) > 0 && hashList[0].Resp.CompactRevision != resp.CompactRevision {
+			return nil, nil, errHashKVUnaligned
+		}
+		hashList = append(hashList, epHashKV{Ep: ep, Resp: resp})
+	}
+	return hashList, unreachable, err
+}
+
+// hashKVVerdict compares the hashes taken at the same revision. When they
+// differ, the hash shared by most endpoints is taken as the reference, the
+// hash of the first of the endpoints on a tie.
+func hashKVVerdict(hashList []epHashKV, unreachable []string) epHashKVVerdict {
+	counts := make(map[uint32]int)
+	for _, h := range hashList {
+		counts[h.Resp.Hash]++
+	}
+	common := hashList[0].Resp.Hash
+	for _, h := range hashList {
+		if counts[h.Resp.Hash] > counts[common] {
+			common = h.Resp.Hash
+		}
+	}
+
+	v := epHashKVVerdict{
+		Revision:        hashList[0].Resp.HashRevision,
+		CompactRevision: hashList[0].Resp.CompactRevision,
+		Endpoints:       len(hashList) + len(unreachable),
+		Unreachable:     unreachable,
+	}
+	for _, h := range hashList {
+		if h.Resp.Hash != common {
+			v.Mismatched = append(v.Mismatched, h.Ep)
+		}
 	}
+	return v
 }
 
 func endpointsFromCluster(cmd *cobra.Command) []string {
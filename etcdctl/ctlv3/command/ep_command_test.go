@@ -0,0 +1,88 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func TestHashKVVerdict(t *testing.T) {
+	hashKV := func(ep string, hash uint32) epHashKV {
+		return epHashKV{Ep: ep, Resp: &clientv3.HashKVResponse{
+			Header:          &pb.ResponseHeader{Revision: 13},
+			Hash:            hash,
+			HashRevision:    13,
+			CompactRevision: 5,
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		hashList    []epHashKV
+		unreachable []string
+
+		wantMismatched []string
+		wantString     string
+	}{
+		{
+			name:       "all equal",
+			hashList:   []epHashKV{hashKV("a", 1), hashKV("b", 1), hashKV("c", 1)},
+			wantString: "all 3 endpoints match at revision 13 (compact revision 5)",
+		},
+		{
+			name:           "majority",
+			hashList:       []epHashKV{hashKV("a", 2), hashKV("b", 1), hashKV("c", 1)},
+			wantMismatched: []string{"a"},
+			wantString:     "2 out of 3 endpoints match at revision 13 (compact revision 5), mismatched: a",
+		},
+		{
+			name:           "tie",
+			hashList:       []epHashKV{hashKV("a", 3), hashKV("b", 1), hashKV("c", 2), hashKV("d", 1), hashKV("e", 2)},
+			wantMismatched: []string{"a", "c", "e"},
+			wantString:     "2 out of 5 endpoints match at revision 13 (compact revision 5), mismatched: a, c, e",
+		},
+		{
+			name:        "unreachable",
+			hashList:    []epHashKV{hashKV("a", 1), hashKV("c", 1)},
+			unreachable: []string{"b"},
+			wantString:  "2 out of 3 endpoints match at revision 13 (compact revision 5), unreachable: b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := hashKVVerdict(tt.hashList, tt.unreachable)
+			if v.Revision != 13 || v.CompactRevision != 5 {
+				t.Errorf("revision = %d, compact revision = %d, want 13 and 5", v.Revision, v.CompactRevision)
+			}
+			if v.Endpoints != len(tt.hashList)+len(tt.unreachable) {
+				t.Errorf("endpoints = %d, want %d", v.Endpoints, len(tt.hashList)+len(tt.unreachable))
+			}
+			if !reflect.DeepEqual(v.Mismatched, tt.wantMismatched) {
+				t.Errorf("mismatched = %v, want %v", v.Mismatched, tt.wantMismatched)
+			}
+			if !reflect.DeepEqual(v.Unreachable, tt.unreachable) {
+				t.Errorf("unreachable = %v, want %v", v.Unreachable, tt.unreachable)
+			}
+			if s := endpointHashKVVerdictString(v); s != tt.wantString {
+				t.Errorf("verdict = %q, want %q", s, tt.wantString)
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ var (
 	getRev         int64
 	getKeysOnly    bool
 	getCountOnly   bool
+	getEstimate    bool
 	printValueOnly bool
 )
 
@@ -45,7 +46,7 @@ func NewGetCommand() *cobra.Command {
 		Run:   getCommandFunc,
 	}
 
-	cmd.Flags().StringVar(&getConsistency, "consistency", "l", "Linearizable(l) or Serializable(s)")
+	cmd.Flags().StringVar(&getConsistency, "consistency", "l", "Linearizable(l), Serializable(s) or Bounded staleness(b)")
 	cmd.Flags().StringVar(&getSortOrder, "order", "", "Order of results; ASCEND or DESCEND (ASCEND by default)")
 	cmd.Flags().StringVar(&getSortTarget, "sort-by", "", "Sort target; CREATE, KEY, MODIFY, VALUE, or VERSION")
 	cmd.Flags().Int64Var(&getLimit, "limit", 0, "Maximum number of results")
@@ -54,10 +55,11 @@ func NewGetCommand() *cobra.Command {
 	cmd.Flags().Int64Var(&getRev, "rev", 0, "Specify the kv revision")
 	cmd.Flags().BoolVar(&getKeysOnly, "keys-only", false, "Get only the keys")
 	cmd.Flags().BoolVar(&getCountOnly, "count-only", false, "Get only the count")
+	cmd.Flags().BoolVar(&getEstimate, "estimate", false, "Get only the approximate count and size of the keys, computed from the in-memory index")
 	cmd.Flags().BoolVar(&printValueOnly, "print-value-only", false, `Only write values when using the "simple" output format`)
 
 	cmd.RegisterFlagCompletionFunc("consistency", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-		return []string{"l", "s"}, cobra.ShellCompDirectiveDefault
+		return []string{"l", "s", "b"}, cobra.ShellCompDirectiveDefault
 	})
 	cmd.RegisterFlagCompletionFunc("order", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"ASCEND", "DESCEND"}, cobra.ShellCompDirectiveDefault
@@ -85,6 +87,14 @@ func getCommandFunc(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if getEstimate {
+		_, fields := display.(*fieldsPrinter)
+		_, json := display.(*jsonPrinter)
+		if !fields && !json {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--estimate is only for `--write-out=fields` or `--write-out=json`"))
+		}
+	}
+
 	if printValueOnly {
 		dp, simple := (display).(*simplePrinter)
 		if !simple {
@@ -108,8 +118,18 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--keys-only` and `--count-only` cannot be set at the same time, choose one"))
 	}
 
+	if getEstimate && (getKeysOnly || getCountOnly) {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--estimate` cannot be set with `--keys-only` or `--count-only`"))
+	}
+
+	if getEstimate && getRev != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--estimate` is only available at the current revision, `--rev` cannot be set"))
+	}
+
 	var opts []clientv3.OpOption
-	if IsSerializable(getConsistency) {
+	if getConsistency == "b" {
+		opts = append(opts, clientv3.WithBoundedStaleness())
+	} else if IsSerializable(getConsistency) {
 		opts = append(opts, clientv3.WithSerializable())
 	}
 
@@ -184,5 +204,9 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 		opts = append(opts, clientv3.WithCountOnly())
 	}
 
+	if getEstimate {
+		opts = append(opts, clientv3.WithEstimate())
+	}
+
 	return key, opts
 }
@@ -57,6 +57,7 @@ type GlobalFlags struct {
 
 	User     string
 	Password string
+	Token    string
 
 	Debug bool
 }
@@ -272,6 +273,17 @@ func authCfgFromCmd(cmd *cobra.Command) *clientv3.AuthConfig {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
 	}
 
+	tokenFlag, err := cmd.Flags().GetString("token")
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	if tokenFlag != "" {
+		if userFlag != "" {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("--token and --user must not be set together"))
+		}
+		return &clientv3.AuthConfig{Token: tokenFlag}
+	}
+
 	if userFlag == "" {
 		return nil
 	}
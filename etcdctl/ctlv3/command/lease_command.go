@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	v3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
@@ -42,13 +43,20 @@ func NewLeaseCommand() *cobra.Command {
 }
 
 // NewLeaseGrantCommand returns the cobra command for "lease grant".
+var (
+	leaseGrantParent string
+	leaseGrantLabels []string
+)
+
 func NewLeaseGrantCommand() *cobra.Command {
 	lc := &cobra.Command{
-		Use:   "grant <ttl>",
+		Use:   "grant <ttl> [options]",
 		Short: "Creates leases",
 
 		Run: leaseGrantCommandFunc,
 	}
+	lc.Flags().StringVar(&leaseGrantParent, "parent", "", "ID (in hex) of the parent lease, revoking the lease along with it")
+	lc.Flags().StringArrayVar(&leaseGrantLabels, "label", nil, "Label KEY=VALUE of the lease (can be repeated)")
 
 	return lc
 }
@@ -64,8 +72,16 @@ func leaseGrantCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad TTL (%v)", err))
 	}
 
+	var opts []v3.LeaseOption
+	if leaseGrantParent != "" {
+		opts = append(opts, v3.WithParentLease(leaseFromArgs(leaseGrantParent)))
+	}
+	if len(leaseGrantLabels) > 0 {
+		opts = append(opts, v3.WithLeaseLabels(leaseLabelsFromArgs(leaseGrantLabels)))
+	}
+
 	ctx, cancel := commandCtx(cmd)
-	resp, err := mustClientFromCmd(cmd).Grant(ctx, ttl)
+	resp, err := mustClientFromCmd(cmd).Grant(ctx, ttl, opts...)
 	cancel()
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to grant lease (%v)", err))
@@ -101,7 +117,10 @@ func leaseRevokeCommandFunc(cmd *cobra.Command, args []string) {
 	display.Revoke(id, *resp)
 }
 
-var timeToLiveKeys bool
+var (
+	timeToLiveKeys  bool
+	timeToLiveStats bool
+)
 
 // NewLeaseTimeToLiveCommand returns the cobra command for "lease timetolive".
 func NewLeaseTimeToLiveCommand() *cobra.Command {
@@ -112,6 +131,7 @@ func NewLeaseTimeToLiveCommand() *cobra.Command {
 		Run: leaseTimeToLiveCommandFunc,
 	}
 	lc.Flags().BoolVar(&timeToLiveKeys, "keys", false, "Get keys attached to this lease")
+	lc.Flags().BoolVar(&timeToLiveStats, "stats", false, "Get the renewal statistics of this lease and the user who granted it")
 
 	return lc
 }
@@ -125,6 +145,9 @@ func leaseTimeToLiveCommandFunc(cmd *cobra.Command, args []string) {
 	if timeToLiveKeys {
 		opts = append(opts, v3.WithAttachedKeys())
 	}
+	if timeToLiveStats {
+		opts = append(opts, v3.WithLeaseStats())
+	}
 	resp, rerr := mustClientFromCmd(cmd).TimeToLive(context.TODO(), leaseFromArgs(args[0]), opts...)
 	if rerr != nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadConnection, rerr)
@@ -132,19 +155,30 @@ func leaseTimeToLiveCommandFunc(cmd *cobra.Command, args []string) {
 	display.TimeToLive(*resp, timeToLiveKeys)
 }
 
+var leaseListLabels []string
+
 // NewLeaseListCommand returns the cobra command for "lease list".
 func NewLeaseListCommand() *cobra.Command {
 	lc := &cobra.Command{
-		Use:   "list",
+		Use:   "list [options]",
 		Short: "List all active leases",
 		Run:   leaseListCommandFunc,
 	}
+	lc.Flags().StringArrayVar(&leaseListLabels, "label", nil, "List only the leases with the label KEY=VALUE, along with their labels (can be repeated)")
 	return lc
 }
 
 // leaseListCommandFunc executes the "lease list" command.
 func leaseListCommandFunc(cmd *cobra.Command, args []string) {
-	resp, rerr := mustClientFromCmd(cmd).Leases(context.TODO())
+	var (
+		resp *v3.LeaseLeasesResponse
+		rerr error
+	)
+	if len(leaseListLabels) > 0 {
+		resp, rerr = mustClientFromCmd(cmd).Query(context.TODO(), leaseLabelsFromArgs(leaseListLabels))
+	} else {
+		resp, rerr = mustClientFromCmd(cmd).Leases(context.TODO())
+	}
 	if rerr != nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadConnection, rerr)
 	}
@@ -206,3 +240,15 @@ func leaseFromArgs(arg string) v3.LeaseID {
 	}
 	return v3.LeaseID(id)
 }
+
+func leaseLabelsFromArgs(args []string) map[string]string {
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad lease label %q, expected KEY=VALUE", arg))
+		}
+		labels[k] = v
+	}
+	return labels
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	v3 "go.etcd.io/etcd/client/v3"
@@ -43,11 +44,13 @@ type printer interface {
 	MemberRemove(id uint64, r v3.MemberRemoveResponse)
 	MemberUpdate(id uint64, r v3.MemberUpdateResponse)
 	MemberPromote(id uint64, r v3.MemberPromoteResponse)
+	MemberReplace(id uint64, r v3.MemberReplaceResponse)
 	MemberList(v3.MemberListResponse)
 
 	EndpointHealth([]epHealth)
 	EndpointStatus([]epStatus)
 	EndpointHashKV([]epHashKV)
+	EndpointHashKVVerdict(epHashKVVerdict)
 	MoveLeader(leader, target uint64, r v3.MoveLeaderResponse)
 
 	DowngradeValidate(r v3.DowngradeResponse)
@@ -62,6 +65,8 @@ type printer interface {
 	RoleList(v3.AuthRoleListResponse)
 	RoleGrantPermission(role string, r v3.AuthRoleGrantPermissionResponse)
 	RoleRevokePermission(role string, key string, end string, r v3.AuthRoleRevokePermissionResponse)
+	RoleSetMaxLeaseTTL(role string, ttl int64, r v3.AuthRoleSetMaxLeaseTTLResponse)
+	RoleSetQuota(role string, quota v3.RoleQuota, r v3.AuthRoleSetQuotaResponse)
 
 	UserAdd(user string, r v3.AuthUserAddResponse)
 	UserGet(user string, r v3.AuthUserGetResponse)
@@ -72,6 +77,9 @@ type printer interface {
 	UserDelete(user string, r v3.AuthUserDeleteResponse)
 
 	AuthStatus(r v3.AuthStatusResponse)
+	AuthSetBcryptCost(cost int, r v3.AuthSetBcryptCostResponse)
+	AuthTokenList(r v3.AuthTokenListResponse)
+	AuthTokenRevoke(r v3.AuthTokenRevokeResponse)
 }
 
 func NewPrinter(printerType string, isHex bool) printer {
@@ -117,6 +125,9 @@ func (p *printerRPC) MemberUpdate(id uint64, r v3.MemberUpdateResponse) {
 func (p *printerRPC) MemberPromote(id uint64, r v3.MemberPromoteResponse) {
 	p.p((*pb.MemberPromoteResponse)(&r))
 }
+func (p *printerRPC) MemberReplace(id uint64, r v3.MemberReplaceResponse) {
+	p.p((*pb.MemberReplaceResponse)(&r))
+}
 func (p *printerRPC) MemberList(r v3.MemberListResponse) { p.p((*pb.MemberListResponse)(&r)) }
 func (p *printerRPC) Alarm(r v3.AlarmResponse)           { p.p((*pb.AlarmResponse)(&r)) }
 func (p *printerRPC) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) {
@@ -137,6 +148,12 @@ func (p *printerRPC) RoleGrantPermission(_ string, r v3.AuthRoleGrantPermissionR
 func (p *printerRPC) RoleRevokePermission(_ string, _ string, _ string, r v3.AuthRoleRevokePermissionResponse) {
 	p.p((*pb.AuthRoleRevokePermissionResponse)(&r))
 }
+func (p *printerRPC) RoleSetMaxLeaseTTL(_ string, _ int64, r v3.AuthRoleSetMaxLeaseTTLResponse) {
+	p.p((*pb.AuthRoleSetMaxLeaseTTLResponse)(&r))
+}
+func (p *printerRPC) RoleSetQuota(_ string, _ v3.RoleQuota, r v3.AuthRoleSetQuotaResponse) {
+	p.p((*pb.AuthRoleSetQuotaResponse)(&r))
+}
 func (p *printerRPC) UserAdd(_ string, r v3.AuthUserAddResponse) { p.p((*pb.AuthUserAddResponse)(&r)) }
 func (p *printerRPC) UserGet(_ string, r v3.AuthUserGetResponse) { p.p((*pb.AuthUserGetResponse)(&r)) }
 func (p *printerRPC) UserList(r v3.AuthUserListResponse)         { p.p((*pb.AuthUserListResponse)(&r)) }
@@ -155,6 +172,15 @@ func (p *printerRPC) UserDelete(_ string, r v3.AuthUserDeleteResponse) {
 func (p *printerRPC) AuthStatus(r v3.AuthStatusResponse) {
 	p.p((*pb.AuthStatusResponse)(&r))
 }
+func (p *printerRPC) AuthSetBcryptCost(_ int, r v3.AuthSetBcryptCostResponse) {
+	p.p((*pb.AuthSetBcryptCostResponse)(&r))
+}
+func (p *printerRPC) AuthTokenList(r v3.AuthTokenListResponse) {
+	p.p((*pb.AuthTokenListResponse)(&r))
+}
+func (p *printerRPC) AuthTokenRevoke(r v3.AuthTokenRevokeResponse) {
+	p.p((*pb.AuthTokenRevokeResponse)(&r))
+}
 
 type printerUnsupported struct{ printerRPC }
 
@@ -165,9 +191,10 @@ func newPrinterUnsupported(n string) printer {
 	return &printerUnsupported{printerRPC{nil, f}}
 }
 
-func (p *printerUnsupported) EndpointHealth([]epHealth) { p.p(nil) }
-func (p *printerUnsupported) EndpointStatus([]epStatus) { p.p(nil) }
-func (p *printerUnsupported) EndpointHashKV([]epHashKV) { p.p(nil) }
+func (p *printerUnsupported) EndpointHealth([]epHealth)             { p.p(nil) }
+func (p *printerUnsupported) EndpointStatus([]epStatus)             { p.p(nil) }
+func (p *printerUnsupported) EndpointHashKV([]epHashKV)             { p.p(nil) }
+func (p *printerUnsupported) EndpointHashKVVerdict(epHashKVVerdict) { p.p(nil) }
 
 func (p *printerUnsupported) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) { p.p(nil) }
 func (p *printerUnsupported) DowngradeValidate(r v3.DowngradeResponse)                  { p.p(nil) }
@@ -175,7 +202,7 @@ func (p *printerUnsupported) DowngradeEnable(r v3.DowngradeResponse)
 func (p *printerUnsupported) DowngradeCancel(r v3.DowngradeResponse)                    { p.p(nil) }
 
 func makeMemberListTable(r v3.MemberListResponse) (hdr []string, rows [][]string) {
-	hdr = []string{"ID", "Status", "Name", "Peer Addrs", "Client Addrs", "Is Learner"}
+	hdr = []string{"ID", "Status", "Name", "Peer Addrs", "Client Addrs", "Is Learner", "Is Observer"}
 	for _, m := range r.Members {
 		status := "started"
 		if len(m.Name) == 0 {
@@ -192,6 +219,21 @@ func makeMemberListTable(r v3.MemberListResponse) (hdr []string, rows [][]string
 			strings.Join(m.PeerURLs, ","),
 			strings.Join(m.ClientURLs, ","),
 			isLearner,
+			fmt.Sprint(m.IsObserver),
+		})
+	}
+	return hdr, rows
+}
+
+func makeAuthTokenListTable(r v3.AuthTokenListResponse) (hdr []string, rows [][]string) {
+	hdr = []string{"ID", "User", "Issue Time", "TTL", "Peer Addr"}
+	for _, t := range r.Tokens {
+		rows = append(rows, []string{
+			t.Id,
+			t.User,
+			time.Unix(t.IssueTime, 0).UTC().Format(time.RFC3339),
+			fmt.Sprint(t.TTL),
+			t.PeerAddress,
 		})
 	}
 	return hdr, rows
@@ -243,3 +285,18 @@ func makeEndpointHashKVTable(hashList []epHashKV) (hdr []string, rows [][]string
 	}
 	return hdr, rows
 }
+
+func endpointHashKVVerdictString(v epHashKVVerdict) string {
+	if len(v.Mismatched) == 0 && len(v.Unreachable) == 0 {
+		return fmt.Sprintf("all %d endpoints match at revision %d (compact revision %d)", v.Endpoints, v.Revision, v.CompactRevision)
+	}
+	matched := v.Endpoints - len(v.Mismatched) - len(v.Unreachable)
+	str := fmt.Sprintf("%d out of %d endpoints match at revision %d (compact revision %d)", matched, v.Endpoints, v.Revision, v.CompactRevision)
+	if len(v.Mismatched) > 0 {
+		str += fmt.Sprintf(", mismatched: %s", strings.Join(v.Mismatched, ", "))
+	}
+	if len(v.Unreachable) > 0 {
+		str += fmt.Sprintf(", unreachable: %s", strings.Join(v.Unreachable, ", "))
+	}
+	return str
+}
@@ -16,6 +16,8 @@ package command
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	spb "go.etcd.io/etcd/api/v3/mvccpb"
@@ -73,6 +75,7 @@ func (p *fieldsPrinter) Get(r v3.GetResponse) {
 	}
 	fmt.Println(`"More" :`, r.More)
 	fmt.Println(`"Count" :`, r.Count)
+	fmt.Println(`"EstimatedSize" :`, r.EstimatedSize)
 }
 
 func (p *fieldsPrinter) Put(r v3.PutResponse) {
@@ -143,9 +146,33 @@ func (p *fieldsPrinter) TimeToLive(r v3.LeaseTimeToLiveResponse, keys bool) {
 	}
 	fmt.Println(`"TTL" :`, r.TTL)
 	fmt.Println(`"GrantedTTL" :`, r.GrantedTTL)
+	if r.ParentID != v3.NoLease {
+		if p.isHex {
+			fmt.Printf("\"ParentID\" : %016x\n", r.ParentID)
+		} else {
+			fmt.Println(`"ParentID" :`, r.ParentID)
+		}
+	}
+	p.leaseLabels(r.Labels)
 	for _, k := range r.Keys {
 		fmt.Printf("\"Key\" : %q\n", string(k))
 	}
+	if st := r.Stats; st != nil {
+		fmt.Printf("\"Grantor\" : %q\n", st.Grantor)
+		fmt.Println(`"AttachedKeys" :`, st.AttachedKeys)
+		fmt.Println(`"Renewals" :`, st.Renewals)
+		fmt.Println(`"RenewRate" :`, st.RenewRate)
+		fmt.Println(`"LastRenewTime" :`, unixNano(st.LastRenewTime))
+		fmt.Println(`"StatsStartTime" :`, unixNano(st.StatsStartTime))
+	}
+}
+
+// unixNano returns the time in Unix nanoseconds, 0 if it is zero.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
 }
 
 func (p *fieldsPrinter) Leases(r v3.LeaseLeasesResponse) {
@@ -156,6 +183,18 @@ func (p *fieldsPrinter) Leases(r v3.LeaseLeasesResponse) {
 		} else {
 			fmt.Println(`"ID" :`, item.ID)
 		}
+		p.leaseLabels(item.Labels)
+	}
+}
+
+func (p *fieldsPrinter) leaseLabels(labels map[string]string) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("\"Label\" : %q\n", k+"="+labels[k])
 	}
 }
 
@@ -175,6 +214,7 @@ func (p *fieldsPrinter) MemberList(r v3.MemberListResponse) {
 			fmt.Printf("\"ClientURL\" : %q\n", u)
 		}
 		fmt.Println(`"IsLearner" :`, m.IsLearner)
+		fmt.Println(`"IsObserver" :`, m.IsObserver)
 		fmt.Println()
 	}
 }
@@ -217,6 +257,18 @@ func (p *fieldsPrinter) EndpointHashKV(hs []epHashKV) {
 	}
 }
 
+func (p *fieldsPrinter) EndpointHashKVVerdict(v epHashKVVerdict) {
+	fmt.Println(`"Revision" :`, v.Revision)
+	fmt.Println(`"CompactRevision" :`, v.CompactRevision)
+	fmt.Println(`"Endpoints" :`, v.Endpoints)
+	for _, ep := range v.Mismatched {
+		fmt.Printf("\"Mismatched\" : %q\n", ep)
+	}
+	for _, ep := range v.Unreachable {
+		fmt.Printf("\"Unreachable\" : %q\n", ep)
+	}
+}
+
 func (p *fieldsPrinter) Alarm(r v3.AlarmResponse) {
 	p.hdr(r.Header)
 	for _, a := range r.Alarms {
@@ -238,6 +290,10 @@ func (p *fieldsPrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Printf("\"Key\" : %q\n", string(p.Key))
 		fmt.Printf("\"RangeEnd\" : %q\n", string(p.RangeEnd))
 	}
+	fmt.Println(`"MaxLeaseTTL" :`, r.MaxLeaseTTL)
+	fmt.Println(`"MaxRequestsPerSecond" :`, r.MaxRequestsPerSecond)
+	fmt.Println(`"MaxWatchStreams" :`, r.MaxWatchStreams)
+	fmt.Println(`"MaxTxnOps" :`, r.MaxTxnOps)
 }
 func (p *fieldsPrinter) RoleDelete(role string, r v3.AuthRoleDeleteResponse) { p.hdr(r.Header) }
 func (p *fieldsPrinter) RoleList(r v3.AuthRoleListResponse) {
@@ -254,6 +310,12 @@ func (p *fieldsPrinter) RoleGrantPermission(role string, r v3.AuthRoleGrantPermi
 func (p *fieldsPrinter) RoleRevokePermission(role string, key string, end string, r v3.AuthRoleRevokePermissionResponse) {
 	p.hdr(r.Header)
 }
+func (p *fieldsPrinter) RoleSetMaxLeaseTTL(role string, ttl int64, r v3.AuthRoleSetMaxLeaseTTLResponse) {
+	p.hdr(r.Header)
+}
+func (p *fieldsPrinter) RoleSetQuota(role string, quota v3.RoleQuota, r v3.AuthRoleSetQuotaResponse) {
+	p.hdr(r.Header)
+}
 func (p *fieldsPrinter) UserAdd(user string, r v3.AuthUserAddResponse)          { p.hdr(r.Header) }
 func (p *fieldsPrinter) UserChangePassword(r v3.AuthUserChangePasswordResponse) { p.hdr(r.Header) }
 func (p *fieldsPrinter) UserGrantRole(user string, role string, r v3.AuthUserGrantRoleResponse) {
@@ -263,3 +325,23 @@ func (p *fieldsPrinter) UserRevokeRole(user string, role string, r v3.AuthUserRe
 	p.hdr(r.Header)
 }
 func (p *fieldsPrinter) UserDelete(user string, r v3.AuthUserDeleteResponse) { p.hdr(r.Header) }
+func (p *fieldsPrinter) AuthSetBcryptCost(cost int, r v3.AuthSetBcryptCostResponse) {
+	p.hdr(r.Header)
+}
+
+func (p *fieldsPrinter) AuthTokenList(r v3.AuthTokenListResponse) {
+	p.hdr(r.Header)
+	for _, t := range r.Tokens {
+		fmt.Printf("\"ID\" : %q\n", t.Id)
+		fmt.Printf("\"User\" : %q\n", t.User)
+		fmt.Println(`"IssueTime" :`, t.IssueTime)
+		fmt.Println(`"TTL" :`, t.TTL)
+		fmt.Printf("\"PeerAddress\" : %q\n", t.PeerAddress)
+		fmt.Println()
+	}
+}
+
+func (p *fieldsPrinter) AuthTokenRevoke(r v3.AuthTokenRevokeResponse) {
+	p.hdr(r.Header)
+	fmt.Println(`"Revoked" :`, r.Revoked)
+}
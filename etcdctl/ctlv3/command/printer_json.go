@@ -40,6 +40,10 @@ func (p *jsonPrinter) EndpointHealth(r []epHealth) { printJSON(r) }
 func (p *jsonPrinter) EndpointStatus(r []epStatus) { printJSON(r) }
 func (p *jsonPrinter) EndpointHashKV(r []epHashKV) { printJSON(r) }
 
+// EndpointHashKVVerdict prints nothing, so that the output stays a single
+// JSON document; the verdict can be derived from the hashes.
+func (p *jsonPrinter) EndpointHashKVVerdict(v epHashKVVerdict) {}
+
 func (p *jsonPrinter) MemberList(r clientv3.MemberListResponse) {
 	if p.isHex {
 		printMemberListWithHexJSON(r)
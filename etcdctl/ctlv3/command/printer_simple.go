@@ -17,7 +17,9 @@ package command
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -102,6 +104,12 @@ func (s *simplePrinter) TimeToLive(resp v3.LeaseTimeToLiveResponse, keys bool) {
 	}
 
 	txt := fmt.Sprintf("lease %016x granted with TTL(%ds), remaining(%ds)", resp.ID, resp.GrantedTTL, resp.TTL)
+	if resp.ParentID != v3.NoLease {
+		txt += fmt.Sprintf(", parent(%016x)", resp.ParentID)
+	}
+	if len(resp.Labels) > 0 {
+		txt += fmt.Sprintf(", labels(%s)", leaseLabelsString(resp.Labels))
+	}
 	if keys {
 		ks := make([]string, len(resp.Keys))
 		for i := range resp.Keys {
@@ -109,16 +117,39 @@ func (s *simplePrinter) TimeToLive(resp v3.LeaseTimeToLiveResponse, keys bool) {
 		}
 		txt += fmt.Sprintf(", attached keys(%v)", ks)
 	}
+	if st := resp.Stats; st != nil {
+		txt += fmt.Sprintf(", attached keys count(%d), renewals(%d, %.3f/s)", st.AttachedKeys, st.Renewals, st.RenewRate)
+		if !st.LastRenewTime.IsZero() {
+			txt += fmt.Sprintf(", last renewed(%s)", st.LastRenewTime.Format(time.RFC3339))
+		}
+		if st.Grantor != "" {
+			txt += fmt.Sprintf(", grantor(%s)", st.Grantor)
+		}
+	}
 	fmt.Println(txt)
 }
 
 func (s *simplePrinter) Leases(resp v3.LeaseLeasesResponse) {
 	fmt.Printf("found %d leases\n", len(resp.Leases))
 	for _, item := range resp.Leases {
+		if len(item.Labels) > 0 {
+			fmt.Printf("%016x labels(%s)\n", item.ID, leaseLabelsString(item.Labels))
+			continue
+		}
 		fmt.Printf("%016x\n", item.ID)
 	}
 }
 
+// leaseLabelsString returns the labels as KEY=VALUE pairs, sorted by key.
+func leaseLabelsString(labels map[string]string) string {
+	kvs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
 func (s *simplePrinter) Alarm(resp v3.AlarmResponse) {
 	for _, e := range resp.Alarms {
 		fmt.Printf("%+v\n", e)
@@ -127,7 +158,9 @@ func (s *simplePrinter) Alarm(resp v3.AlarmResponse) {
 
 func (s *simplePrinter) MemberAdd(r v3.MemberAddResponse) {
 	asLearner := " "
-	if r.Member.IsLearner {
+	if r.Member.IsObserver {
+		asLearner = " as observer "
+	} else if r.Member.IsLearner {
 		asLearner = " as learner "
 	}
 	fmt.Printf("Member %16x added%sto cluster %16x\n", r.Member.ID, asLearner, r.Header.ClusterId)
@@ -145,6 +178,14 @@ func (s *simplePrinter) MemberPromote(id uint64, r v3.MemberPromoteResponse) {
 	fmt.Printf("Member %16x promoted in cluster %16x\n", id, r.Header.ClusterId)
 }
 
+func (s *simplePrinter) MemberReplace(id uint64, r v3.MemberReplaceResponse) {
+	asLearner := " as learner "
+	if r.Member.IsObserver {
+		asLearner = " as observer "
+	}
+	fmt.Printf("Member %16x replaced by member %16x%sin cluster %16x\n", id, r.Member.ID, asLearner, r.Header.ClusterId)
+}
+
 func (s *simplePrinter) MemberList(resp v3.MemberListResponse) {
 	_, rows := makeMemberListTable(resp)
 	for _, row := range rows {
@@ -176,6 +217,10 @@ func (s *simplePrinter) EndpointHashKV(hashList []epHashKV) {
 	}
 }
 
+func (s *simplePrinter) EndpointHashKVVerdict(v epHashKVVerdict) {
+	fmt.Println(endpointHashKVVerdictString(v))
+}
+
 func (s *simplePrinter) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) {
 	fmt.Printf("Leadership transferred from %s to %s\n", types.ID(leader), types.ID(target))
 }
@@ -204,6 +249,18 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		return
 	}
 
+	if r.MaxLeaseTTL != 0 {
+		fmt.Printf("Max lease TTL: %ds\n", r.MaxLeaseTTL)
+	}
+	if r.MaxRequestsPerSecond != 0 {
+		fmt.Printf("Max requests per second: %d\n", r.MaxRequestsPerSecond)
+	}
+	if r.MaxWatchStreams != 0 {
+		fmt.Printf("Max watch streams: %d\n", r.MaxWatchStreams)
+	}
+	if r.MaxTxnOps != 0 {
+		fmt.Printf("Max txn ops: %d\n", r.MaxTxnOps)
+	}
 	fmt.Println("KV Read:")
 
 	printRange := func(perm *v3.Permission) {
@@ -239,6 +296,27 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 			}
 		}
 	}
+	// the watch and count permissions, implied by the read permissions, are
+	// listed only when granted on their own
+	printOnly := func(title string, permType v3.PermissionType) {
+		printed := false
+		for _, perm := range r.Perm {
+			if v3.PermissionType(perm.PermType) != permType {
+				continue
+			}
+			if !printed {
+				fmt.Println(title)
+				printed = true
+			}
+			if len(perm.RangeEnd) == 0 {
+				fmt.Printf("\t%s\n", string(perm.Key))
+			} else {
+				printRange((*v3.Permission)(perm))
+			}
+		}
+	}
+	printOnly("KV Watch:", v3.PermissionType(v3.PermWatch))
+	printOnly("KV Count:", v3.PermissionType(v3.PermCount))
 }
 
 func (s *simplePrinter) RoleList(r v3.AuthRoleListResponse) {
@@ -255,6 +333,18 @@ func (s *simplePrinter) RoleGrantPermission(role string, r v3.AuthRoleGrantPermi
 	fmt.Printf("Role %s updated\n", role)
 }
 
+func (s *simplePrinter) RoleSetMaxLeaseTTL(role string, ttl int64, r v3.AuthRoleSetMaxLeaseTTLResponse) {
+	if ttl == 0 {
+		fmt.Printf("Max lease TTL of role %s is removed\n", role)
+		return
+	}
+	fmt.Printf("Max lease TTL of role %s is set to %ds\n", role, ttl)
+}
+
+func (s *simplePrinter) RoleSetQuota(role string, quota v3.RoleQuota, r v3.AuthRoleSetQuotaResponse) {
+	fmt.Printf("Quota of role %s is set\n", role)
+}
+
 func (s *simplePrinter) RoleRevokePermission(role string, key string, end string, r v3.AuthRoleRevokePermissionResponse) {
 	if len(end) == 0 {
 		fmt.Printf("Permission of key %s is revoked from role %s\n", key, role)
@@ -305,4 +395,22 @@ func (s *simplePrinter) UserList(r v3.AuthUserListResponse) {
 func (s *simplePrinter) AuthStatus(r v3.AuthStatusResponse) {
 	fmt.Println("Authentication Status:", r.Enabled)
 	fmt.Println("AuthRevision:", r.AuthRevision)
+	if r.BcryptCost != 0 {
+		fmt.Println("BcryptCost:", r.BcryptCost)
+	}
+}
+
+func (s *simplePrinter) AuthSetBcryptCost(cost int, r v3.AuthSetBcryptCostResponse) {
+	fmt.Printf("Bcrypt cost is set to %d\n", cost)
+}
+
+func (s *simplePrinter) AuthTokenList(r v3.AuthTokenListResponse) {
+	_, rows := makeAuthTokenListTable(r)
+	for _, row := range rows {
+		fmt.Println(strings.Join(row, ", "))
+	}
+}
+
+func (s *simplePrinter) AuthTokenRevoke(r v3.AuthTokenRevokeResponse) {
+	fmt.Printf("Revoked %d token(s)\n", r.Revoked)
 }
@@ -15,6 +15,7 @@
 package command
 
 import (
+	"fmt"
 	"os"
 
 	v3 "go.etcd.io/etcd/client/v3"
@@ -64,3 +65,16 @@ func (tp *tablePrinter) EndpointHashKV(r []epHashKV) {
 	table.SetAlignment(tablewriter.ALIGN_RIGHT)
 	table.Render()
 }
+func (tp *tablePrinter) EndpointHashKVVerdict(v epHashKVVerdict) {
+	fmt.Println(endpointHashKVVerdictString(v))
+}
+func (tp *tablePrinter) AuthTokenList(r v3.AuthTokenListResponse) {
+	hdr, rows := makeAuthTokenListTable(r)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(hdr)
+	for _, row := range rows {
+		table.Append(row)
+	}
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.Render()
+}
@@ -17,6 +17,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -27,6 +28,7 @@ import (
 var (
 	rolePermPrefix  bool
 	rolePermFromKey bool
+	roleQuota       clientv3.RoleQuota
 )
 
 // NewRoleCommand returns the cobra command for "role".
@@ -42,6 +44,8 @@ func NewRoleCommand() *cobra.Command {
 	ac.AddCommand(newRoleListCommand())
 	ac.AddCommand(newRoleGrantPermissionCommand())
 	ac.AddCommand(newRoleRevokePermissionCommand())
+	ac.AddCommand(newRoleSetMaxLeaseTTLCommand())
+	ac.AddCommand(newRoleSetQuotaCommand())
 
 	return ac
 }
@@ -104,6 +108,28 @@ func newRoleRevokePermissionCommand() *cobra.Command {
 	return cmd
 }
 
+func newRoleSetMaxLeaseTTLCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-max-lease-ttl <role name> <ttl>",
+		Short: "Sets the maximum TTL in seconds of the leases granted by the users of a role, 0 for no limit",
+		Run:   roleSetMaxLeaseTTLCommandFunc,
+	}
+}
+
+func newRoleSetQuotaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-quota [options] <role name>",
+		Short: "Sets the quotas of the requests of each user of a role, the omitted ones being removed",
+		Run:   roleSetQuotaCommandFunc,
+	}
+
+	cmd.Flags().Int64Var(&roleQuota.MaxRequestsPerSecond, "max-requests-per-second", 0, "maximum rate of the unary requests of a user served by a member, 0 for no limit")
+	cmd.Flags().Int64Var(&roleQuota.MaxWatchStreams, "max-watch-streams", 0, "maximum number of the concurrent watch streams of a user served by a member, 0 for no limit")
+	cmd.Flags().Int64Var(&roleQuota.MaxTxnOps, "max-txn-ops", 0, "maximum number of operations per txn of a user, 0 for no limit")
+
+	return cmd
+}
+
 // roleAddCommandFunc executes the "role add" command.
 func roleAddCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
@@ -195,6 +221,37 @@ func roleRevokePermissionCommandFunc(cmd *cobra.Command, args []string) {
 	display.RoleRevokePermission(args[0], args[1], rangeEnd, *resp)
 }
 
+// roleSetMaxLeaseTTLCommandFunc executes the "role set-max-lease-ttl" command.
+func roleSetMaxLeaseTTLCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role set-max-lease-ttl command requires role name and TTL as its arguments"))
+	}
+
+	ttl, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad TTL (%v)", err))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.RoleSetMaxLeaseTTL(context.TODO(), args[0], ttl)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RoleSetMaxLeaseTTL(args[0], ttl, *resp)
+}
+
+// roleSetQuotaCommandFunc executes the "role set-quota" command.
+func roleSetQuotaCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role set-quota command requires role name as its argument"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.RoleSetQuota(context.TODO(), args[0], roleQuota)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RoleSetQuota(args[0], roleQuota, *resp)
+}
+
 func permRange(args []string) (string, string) {
 	key := args[0]
 	var rangeEnd string
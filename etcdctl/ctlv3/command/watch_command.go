@@ -16,12 +16,16 @@ package command
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"text/template"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
@@ -42,6 +46,13 @@ var (
 	watchInteractive bool
 	watchPrevKey     bool
 	progressNotify   bool
+
+	watchExecBatchSize   int
+	watchExecConcurrency int
+	watchExecEnv         []string
+	watchExecStdinJSON   bool
+
+	watchExecEnvTemplates []watchExecEnvTemplate
 )
 
 // NewWatchCommand returns the cobra command for "watch".
@@ -57,6 +68,10 @@ func NewWatchCommand() *cobra.Command {
 	cmd.Flags().Int64Var(&watchRev, "rev", 0, "Revision to start watching")
 	cmd.Flags().BoolVar(&watchPrevKey, "prev-kv", false, "get the previous key-value pair before the event happens")
 	cmd.Flags().BoolVar(&progressNotify, "progress-notify", false, "get periodic watch progress notification from server")
+	cmd.Flags().IntVar(&watchExecBatchSize, "exec-batch-size", 1, "Maximum number of events of a watch response passed to a single exec-command invocation (0 for all events of the response)")
+	cmd.Flags().IntVar(&watchExecConcurrency, "exec-concurrency", 1, "Maximum number of exec-command invocations running concurrently")
+	cmd.Flags().StringArrayVar(&watchExecEnv, "exec-env", nil, "Environment variable NAME=TEMPLATE set for exec-command, TEMPLATE being a Go template evaluated against the batch of events (can be repeated)")
+	cmd.Flags().BoolVar(&watchExecStdinJSON, "exec-stdin-json", false, "Write the batch of events as JSON to the stdin of exec-command")
 
 	return cmd
 }
@@ -68,6 +83,17 @@ func watchCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("ETCDCTL_WATCH_KEY is empty but got ETCDCTL_WATCH_RANGE_END=%q", envRange))
 	}
 
+	if watchExecBatchSize < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--exec-batch-size must not be negative"))
+	}
+	if watchExecConcurrency < 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--exec-concurrency must be at least 1"))
+	}
+	var err error
+	if watchExecEnvTemplates, err = parseWatchExecEnv(watchExecEnv); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+
 	if watchInteractive {
 		watchInteractiveFunc(cmd, os.Args, envKey, envRange)
 		return
@@ -164,6 +190,10 @@ func getWatchChan(c *clientv3.Client, args []string) (clientv3.WatchChan, error)
 }
 
 func printWatchCh(c *clientv3.Client, ch clientv3.WatchChan, execArgs []string) {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, watchExecConcurrency)
+	)
 	for resp := range ch {
 		if resp.Canceled {
 			fmt.Fprintf(os.Stderr, "watch was canceled (%v)\n", resp.Err())
@@ -174,21 +204,150 @@ func printWatchCh(c *clientv3.Client, ch clientv3.WatchChan, execArgs []string)
 		display.Watch(resp)
 
 		if len(execArgs) > 0 {
-			for _, ev := range resp.Events {
-				cmd := exec.CommandContext(c.Ctx(), execArgs[0], execArgs[1:]...)
-				cmd.Env = os.Environ()
-				cmd.Env = append(cmd.Env, fmt.Sprintf("ETCD_WATCH_REVISION=%d", resp.Header.Revision))
-				cmd.Env = append(cmd.Env, fmt.Sprintf("ETCD_WATCH_EVENT_TYPE=%q", ev.Type))
-				cmd.Env = append(cmd.Env, fmt.Sprintf("ETCD_WATCH_KEY=%q", ev.Kv.Key))
-				cmd.Env = append(cmd.Env, fmt.Sprintf("ETCD_WATCH_VALUE=%q", ev.Kv.Value))
-				cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-				if err := cmd.Run(); err != nil {
-					fmt.Fprintf(os.Stderr, "command %q error (%v)\n", execArgs, err)
-					os.Exit(1)
-				}
+			for _, events := range batchWatchEvents(resp.Events, watchExecBatchSize) {
+				batch := newWatchExecBatch(resp.Header.Revision, events)
+				sem <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					if err := runWatchExec(c.Ctx(), execArgs, batch); err != nil {
+						fmt.Fprintf(os.Stderr, "command %q error (%v)\n", execArgs, err)
+						os.Exit(1)
+					}
+				}()
 			}
 		}
 	}
+	wg.Wait()
+}
+
+// watchExecEvent is an event passed to the exec-command of watch.
+type watchExecEvent struct {
+	Type           string `json:"type"`
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	PrevValue      string `json:"prev_value,omitempty"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+	Lease          int64  `json:"lease,omitempty"`
+}
+
+// watchExecBatch is the batch of events passed to a single invocation of
+// the exec-command of watch. It is the data of the --exec-env templates and
+// the JSON written to stdin with --exec-stdin-json.
+type watchExecBatch struct {
+	Revision int64            `json:"revision"`
+	Events   []watchExecEvent `json:"events"`
+}
+
+// Event returns the last event of the batch.
+func (b watchExecBatch) Event() watchExecEvent {
+	return b.Events[len(b.Events)-1]
+}
+
+func newWatchExecBatch(rev int64, events []*clientv3.Event) watchExecBatch {
+	b := watchExecBatch{Revision: rev, Events: make([]watchExecEvent, len(events))}
+	for i, ev := range events {
+		b.Events[i] = watchExecEvent{
+			Type:           ev.Type.String(),
+			Key:            string(ev.Kv.Key),
+			Value:          string(ev.Kv.Value),
+			CreateRevision: ev.Kv.CreateRevision,
+			ModRevision:    ev.Kv.ModRevision,
+			Version:        ev.Kv.Version,
+			Lease:          ev.Kv.Lease,
+		}
+		if ev.PrevKv != nil {
+			b.Events[i].PrevValue = string(ev.PrevKv.Value)
+		}
+	}
+	return b
+}
+
+// batchWatchEvents splits events in batches of at most size events, or a
+// single batch if size is 0.
+func batchWatchEvents(events []*clientv3.Event, size int) [][]*clientv3.Event {
+	if size == 0 || size > len(events) {
+		size = len(events)
+	}
+	var batches [][]*clientv3.Event
+	for len(events) > 0 {
+		n := size
+		if n > len(events) {
+			n = len(events)
+		}
+		batches = append(batches, events[:n])
+		events = events[n:]
+	}
+	return batches
+}
+
+type watchExecEnvTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+func parseWatchExecEnv(env []string) ([]watchExecEnvTemplate, error) {
+	tmpls := make([]watchExecEnvTemplate, 0, len(env))
+	for _, e := range env {
+		name, text, ok := strings.Cut(e, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --exec-env %q, expected NAME=TEMPLATE", e)
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exec-env template %q (%v)", e, err)
+		}
+		tmpls = append(tmpls, watchExecEnvTemplate{name: name, tmpl: tmpl})
+	}
+	return tmpls, nil
+}
+
+// watchExecEnvironment returns the environment variables describing the batch
+// to the exec-command, followed by the ones set with --exec-env.
+func watchExecEnvironment(b watchExecBatch, tmpls []watchExecEnvTemplate) ([]string, error) {
+	env := []string{
+		fmt.Sprintf("ETCD_WATCH_REVISION=%d", b.Revision),
+		fmt.Sprintf("ETCD_WATCH_EVENT_COUNT=%d", len(b.Events)),
+	}
+	if len(b.Events) == 1 {
+		ev := b.Events[0]
+		env = append(env,
+			fmt.Sprintf("ETCD_WATCH_EVENT_TYPE=%q", ev.Type),
+			fmt.Sprintf("ETCD_WATCH_KEY=%q", ev.Key),
+			fmt.Sprintf("ETCD_WATCH_VALUE=%q", ev.Value),
+		)
+	}
+	for _, t := range tmpls {
+		var buf bytes.Buffer
+		if err := t.tmpl.Execute(&buf, b); err != nil {
+			return nil, fmt.Errorf("failed to evaluate --exec-env %s (%v)", t.name, err)
+		}
+		env = append(env, t.name+"="+buf.String())
+	}
+	return env, nil
+}
+
+func runWatchExec(ctx context.Context, execArgs []string, b watchExecBatch) error {
+	env, err := watchExecEnvironment(b, watchExecEnvTemplates)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, execArgs[0], execArgs[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if watchExecStdinJSON {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	return cmd.Run()
 }
 
 // "commandArgs" is the command arguments after "spf13/cobra" parses
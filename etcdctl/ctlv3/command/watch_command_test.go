@@ -17,6 +17,9 @@ package command
 import (
 	"reflect"
 	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 func Test_parseWatchArgs(t *testing.T) {
@@ -556,3 +559,88 @@ func Test_parseWatchArgs(t *testing.T) {
 		}
 	}
 }
+
+func Test_batchWatchEvents(t *testing.T) {
+	events := make([]*clientv3.Event, 5)
+	for i := range events {
+		events[i] = &clientv3.Event{}
+	}
+	tt := []struct {
+		size   int
+		expect []int
+	}{
+		{size: 0, expect: []int{5}},
+		{size: 1, expect: []int{1, 1, 1, 1, 1}},
+		{size: 2, expect: []int{2, 2, 1}},
+		{size: 5, expect: []int{5}},
+		{size: 10, expect: []int{5}},
+	}
+	for i, tc := range tt {
+		var sizes []int
+		for _, b := range batchWatchEvents(events, tc.size) {
+			sizes = append(sizes, len(b))
+		}
+		if !reflect.DeepEqual(sizes, tc.expect) {
+			t.Errorf("#%d: batch sizes expected %v, got %v", i, tc.expect, sizes)
+		}
+	}
+}
+
+func Test_watchExecEnvironment(t *testing.T) {
+	events := []*clientv3.Event{
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte("bar"), ModRevision: 10}},
+		{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("baz"), ModRevision: 11}, PrevKv: &mvccpb.KeyValue{Value: []byte("qux")}},
+	}
+	tmpls, err := parseWatchExecEnv([]string{
+		"LAST_KEY={{.Event.Key}}",
+		"KEYS={{range $i, $e := .Events}}{{if $i}},{{end}}{{$e.Key}}{{end}}",
+		"PREV={{.Event.PrevValue}}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		events []*clientv3.Event
+		expect []string
+	}{
+		{
+			events: events[:1],
+			expect: []string{
+				"ETCD_WATCH_REVISION=11",
+				"ETCD_WATCH_EVENT_COUNT=1",
+				`ETCD_WATCH_EVENT_TYPE="PUT"`,
+				`ETCD_WATCH_KEY="foo"`,
+				`ETCD_WATCH_VALUE="bar"`,
+				"LAST_KEY=foo",
+				"KEYS=foo",
+				"PREV=",
+			},
+		},
+		{
+			events: events,
+			expect: []string{
+				"ETCD_WATCH_REVISION=11",
+				"ETCD_WATCH_EVENT_COUNT=2",
+				"LAST_KEY=baz",
+				"KEYS=foo,baz",
+				"PREV=qux",
+			},
+		},
+	}
+	for i, tc := range tt {
+		env, err := watchExecEnvironment(newWatchExecBatch(11, tc.events), tmpls)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(env, tc.expect) {
+			t.Errorf("#%d: environment expected %q, got %q", i, tc.expect, env)
+		}
+	}
+
+	for _, bad := range []string{"NOVALUE", "=value", "BAD={{.Event"} {
+		if _, err := parseWatchExecEnv([]string{bad}); err == nil {
+			t.Errorf("expected error parsing --exec-env %q", bad)
+		}
+	}
+}
@@ -42,11 +42,16 @@ func init() {
 
 	rootCmd.AddCommand(
 		etcdutl.NewBackupCommand(),
+		etcdutl.NewCheckCommand(),
+		etcdutl.NewCompactCommand(),
 		etcdutl.NewDefragCommand(),
+		etcdutl.NewHashKVCommand(),
 		etcdutl.NewSnapshotCommand(),
 		etcdutl.NewVersionCommand(),
 		etcdutl.NewCompletionCommand(),
 		etcdutl.NewMigrateCommand(),
+		etcdutl.NewRemoveKeysCommand(),
+		etcdutl.NewWALCommand(),
 	)
 }
 
@@ -69,6 +69,7 @@ func NewBackupCommand() *cobra.Command {
 	cmd.MarkFlagDirname("wal-dir")
 	cmd.MarkFlagDirname("backup-dir")
 	cmd.MarkFlagDirname("backup-wal-dir")
+	cmd.AddCommand(newBackupIncrementalCommand())
 	return cmd
 }
 
@@ -222,8 +223,8 @@ func translateWAL(lg *zap.Logger, srcWAL string, walsnap walpb.Snapshot) (etcdse
 		// Also moving entries and computing offsets would get complicated if
 		// TERM changes (so there are superflous entries from previous term).
 
-		if ents[i].Type == raftpb.EntryConfChange {
-			lg.Info("ignoring EntryConfChange raft entry")
+		if ents[i].Type == raftpb.EntryConfChange || ents[i].Type == raftpb.EntryConfChangeV2 {
+			lg.Info("ignoring EntryConfChange raft entry", zap.Stringer("type", ents[i].Type))
 			raftEntryToNoOp(&ents[i])
 			continue
 		}
@@ -269,20 +270,22 @@ func raftEntryToNoOp(entry *raftpb.Entry) {
 func saveDB(lg *zap.Logger, destDB, srcDB string, idx uint64, term uint64, desired *desiredCluster) {
 	// open src db to safely copy db state
 	var src *bolt.DB
-	ch := make(chan *bolt.DB, 1)
+	var closeSrc func() error
+	ch := make(chan struct{})
 	go func() {
-		db, err := bolt.Open(srcDB, 0444, &bolt.Options{ReadOnly: true})
+		var err error
+		src, closeSrc, err = backend.OpenBoltReadOnly(srcDB)
 		if err != nil {
 			lg.Fatal("bolt.Open FAILED", zap.Error(err))
 		}
-		ch <- db
+		close(ch)
 	}()
 	select {
-	case src = <-ch:
+	case <-ch:
 	case <-time.After(time.Second):
 		lg.Fatal("timed out waiting to acquire lock on", zap.String("srcDB", srcDB))
 	}
-	defer src.Close()
+	defer closeSrc()
 
 	tx, err := src.Begin(false)
 	if err != nil {
@@ -0,0 +1,269 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/etcdutl/v3/snapshot"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// NewCheckCommand returns the cobra command for "check".
+func NewCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check <subcommand>",
+		Short: "Checks the consistency of etcd files",
+	}
+	cmd.AddCommand(newCheckDataDirCommand())
+	return cmd
+}
+
+var checkDataDirWALDir string
+
+func newCheckDataDirCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datadir <data-dir>",
+		Short: "Checks that a data directory not in use by etcd is consistent and that the member would start cleanly",
+		Long: `Checks that a data directory not in use by etcd is consistent: the integrity of the db file, the health of
+the WAL segments, the snapshot files referenced by the WAL and the consistent index of the db file against
+the snapshot and the WAL. The data directory is only read, never modified.
+Exits with an error if the member would not start cleanly.
+`,
+		Run: checkDataDirCommandFunc,
+	}
+	cmd.Flags().StringVar(&checkDataDirWALDir, "wal-dir", "", "Path to the WAL directory (use <data-dir>/member/wal if none given)")
+	cmd.MarkFlagDirname("wal-dir")
+	return cmd
+}
+
+func checkDataDirCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("check datadir requires exactly one argument"))
+	}
+	dataDir := args[0]
+	if !fileutil.Exist(dataDir) {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("data directory %q does not exist", dataDir))
+	}
+	walDir := checkDataDirWALDir
+	if walDir == "" {
+		walDir = datadir.ToWalDir(dataDir)
+	}
+
+	r := CheckDataDir(GetLogger(), dataDir, walDir)
+	printDataDirReport(os.Stdout, r)
+	if !r.OK() {
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("data directory %q would not start cleanly", dataDir))
+	}
+}
+
+// DataDirCheckStatus is the outcome of a data directory check.
+type DataDirCheckStatus string
+
+const (
+	DataDirCheckOK      DataDirCheckStatus = "OK"
+	DataDirCheckWarning DataDirCheckStatus = "WARNING"
+	DataDirCheckFailed  DataDirCheckStatus = "FAILED"
+)
+
+// DataDirCheck is the result of a single data directory check.
+type DataDirCheck struct {
+	Name   string             `json:"name"`
+	Status DataDirCheckStatus `json:"status"`
+	Detail string             `json:"detail"`
+}
+
+// DataDirReport is the result of the checks run by CheckDataDir.
+type DataDirReport struct {
+	Checks []DataDirCheck `json:"checks"`
+}
+
+// OK returns true if no check failed, that is if the member would start
+// cleanly. Warnings report conditions that etcd handles on start.
+func (r *DataDirReport) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == DataDirCheckFailed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *DataDirReport) add(name string, status DataDirCheckStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, DataDirCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+func printDataDirReport(out io.Writer, r *DataDirReport) {
+	for _, c := range r.Checks {
+		fmt.Fprintf(out, "%-16s %-7s %s\n", c.Name, c.Status, c.Detail)
+	}
+	fmt.Fprintf(out, "would start cleanly: %v\n", r.OK())
+}
+
+// CheckDataDir checks the files of the given data directory, not in use by
+// etcd, without modifying them:
+//   - the db file pages, as done before defragmentation;
+//   - the WAL segments, detecting CRC mismatches and torn writes;
+//   - the snapshot files referenced by the WAL;
+//   - the consistent index of the db file, which must not be behind the
+//     snapshot the member restarts from, nor ahead of the WAL commit index.
+func CheckDataDir(lg *zap.Logger, dataDir, walDir string) *DataDirReport {
+	r := &DataDirReport{}
+	dbPath := datadir.ToBackendFileName(dataDir)
+
+	dbOK := checkDataDirDB(r, dbPath)
+	lastIndex, walOK := checkDataDirWAL(r, walDir)
+	if !walOK {
+		return r
+	}
+	walSnap, snapOK := checkDataDirSnapshots(lg, r, datadir.ToSnapDir(dataDir), walDir)
+	if !snapOK {
+		return r
+	}
+	state, err := wal.Verify(lg, walDir, walSnap)
+	if err != nil {
+		r.add("wal", DataDirCheckFailed, "failed to verify WAL from snapshot at index %d: %v", walSnap.Index, err)
+		return r
+	}
+	if dbOK {
+		checkDataDirConsistentIndex(r, dbPath, datadir.ToSnapDir(dataDir), walSnap, *state, lastIndex)
+	}
+	return r
+}
+
+func checkDataDirDB(r *DataDirReport, dbPath string) bool {
+	if !fileutil.Exist(dbPath) {
+		r.add("db", DataDirCheckFailed, "db file %q is missing", dbPath)
+		return false
+	}
+	if err := backend.CheckFile(dbPath); err != nil {
+		r.add("db", DataDirCheckFailed, "%v", err)
+		return false
+	}
+	r.add("db", DataDirCheckOK, "%s", dbPath)
+	return true
+}
+
+// checkDataDirWAL checks the health of every WAL segment and returns the
+// last entry index recorded in the WAL. A torn write at the tail of the
+// last segment is reported as a warning since etcd repairs it on start.
+func checkDataDirWAL(r *DataDirReport, walDir string) (uint64, bool) {
+	if !wal.Exist(walDir) {
+		r.add("wal", DataDirCheckFailed, "no WAL found in %q", walDir)
+		return 0, false
+	}
+	names, err := fileutil.ReadDir(walDir, fileutil.WithExt(".wal"))
+	if err != nil {
+		r.add("wal", DataDirCheckFailed, "%v", err)
+		return 0, false
+	}
+
+	var lastIndex uint64
+	ok := true
+	for i, name := range names {
+		sr, err := dumpWALSegment(io.Discard, filepath.Join(walDir, name), &walDumpOptions{healthOnly: true})
+		if err != nil {
+			r.add("wal", DataDirCheckFailed, "%v", err)
+			return 0, false
+		}
+		if sr.entries > 0 {
+			lastIndex = sr.lastIndex
+		}
+		switch {
+		case sr.healthy():
+		case i == len(names)-1 && sr.crcErrors == 0 && errors.Is(sr.err, io.ErrUnexpectedEOF):
+			r.add("wal", DataDirCheckWarning, "torn write at the tail of the last segment, repaired on start: %s", sr)
+		default:
+			r.add("wal", DataDirCheckFailed, "%s", sr)
+			ok = false
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	r.add("wal", DataDirCheckOK, "%d segments, last entry index %d", len(names), lastIndex)
+	return lastIndex, true
+}
+
+// checkDataDirSnapshots returns the newest snapshot referenced by the WAL
+// that has a readable snapshot file, which the member restarts from.
+func checkDataDirSnapshots(lg *zap.Logger, r *DataDirReport, snapDir, walDir string) (walpb.Snapshot, bool) {
+	walSnaps, err := wal.ValidSnapshotEntries(lg, walDir)
+	if err != nil {
+		r.add("snapshot", DataDirCheckFailed, "failed to read snapshot entries from WAL: %v", err)
+		return walpb.Snapshot{}, false
+	}
+	for i := len(walSnaps) - 1; i >= 0; i-- {
+		ws := walSnaps[i]
+		if ws.Index == 0 {
+			// the WAL starts from scratch
+			r.add("snapshot", DataDirCheckOK, "no snapshot, WAL replayed from index 0")
+			return ws, true
+		}
+		path := filepath.Join(snapDir, fmt.Sprintf("%016x-%016x.snap", ws.Term, ws.Index))
+		s, err := snap.Read(lg, path)
+		if err != nil {
+			r.add("snapshot", DataDirCheckWarning, "snapshot at index %d referenced by WAL is not readable: %v", ws.Index, err)
+			continue
+		}
+		if s.Metadata.Index != ws.Index || s.Metadata.Term != ws.Term {
+			r.add("snapshot", DataDirCheckWarning, "snapshot file %q does not match WAL snapshot entry (index %d, term %d)", path, ws.Index, ws.Term)
+			continue
+		}
+		r.add("snapshot", DataDirCheckOK, "restarting from snapshot at index %d, term %d", ws.Index, ws.Term)
+		return ws, true
+	}
+	r.add("snapshot", DataDirCheckFailed, "no readable snapshot file matches the %d snapshot entries of the WAL", len(walSnaps))
+	return walpb.Snapshot{}, false
+}
+
+func checkDataDirConsistentIndex(r *DataDirReport, dbPath, snapDir string, walSnap walpb.Snapshot, state raftpb.HardState, lastIndex uint64) {
+	ci, err := snapshot.ConsistentIndex(dbPath)
+	if err != nil {
+		r.add("consistent-index", DataDirCheckFailed, "%v", err)
+		return
+	}
+	switch {
+	case ci < walSnap.Index:
+		// etcd restores the db file from the snapshot db file received
+		// from the leader, if it was not yet renamed.
+		ss := snap.New(zap.NewNop(), snapDir)
+		if _, err = ss.DBFilePath(walSnap.Index); err != nil {
+			r.add("consistent-index", DataDirCheckFailed, "consistent index %d is behind snapshot index %d and no snapshot db file is available", ci, walSnap.Index)
+			return
+		}
+		r.add("consistent-index", DataDirCheckWarning, "consistent index %d is behind snapshot index %d, db file recovered from snapshot db file on start", ci, walSnap.Index)
+	case ci > state.Commit:
+		r.add("consistent-index", DataDirCheckFailed, "consistent index %d is ahead of WAL commit index %d, the WAL lost committed entries", ci, state.Commit)
+	case lastIndex != 0 && ci > lastIndex:
+		r.add("consistent-index", DataDirCheckFailed, "consistent index %d is ahead of last WAL entry index %d", ci, lastIndex)
+	default:
+		r.add("consistent-index", DataDirCheckOK, "consistent index %d, WAL commit index %d", ci, state.Commit)
+	}
+}
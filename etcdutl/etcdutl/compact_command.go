@@ -0,0 +1,81 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/etcdutl/v3/snapshot"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+var (
+	compactRevision int64
+	compactDefrag   bool
+)
+
+// NewCompactCommand returns the cobra command for "compact".
+func NewCompactCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact <db file> [options]",
+		Short: "Compacts the key space history of a db file not in use by etcd",
+		Long: `Compacts the key space history of a db file (for example <data-dir>/member/snap/db) not in use by etcd.
+All revisions of a key superseded by a newer revision at or before the compaction revision are removed.
+`,
+		Run: compactCommandFunc,
+	}
+	cmd.Flags().Int64Var(&compactRevision, "rev", 0, "Revision to compact at (0 means the current revision of the db file)")
+	cmd.Flags().BoolVar(&compactDefrag, "defrag", false, "Defragment the db file after compaction to release the freed space")
+	return cmd
+}
+
+func compactCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("compact requires exactly one argument"))
+	}
+	rev, err := CompactDB(GetLogger(), args[0], compactRevision, compactDefrag)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError,
+			fmt.Errorf("Failed to compact db file %q (%v)", args[0], err))
+	}
+	fmt.Printf("compacted revision %d\n", rev)
+}
+
+// CompactDB compacts the key space of the given db file at rev, or at its
+// current revision if rev is 0, and returns the compaction revision.
+func CompactDB(lg *zap.Logger, dbPath string, rev int64, defrag bool) (int64, error) {
+	if !fileutil.Exist(dbPath) {
+		return 0, fmt.Errorf("db file %q does not exist", dbPath)
+	}
+	be := backend.NewDefaultBackend(lg, dbPath)
+	defer be.Close()
+
+	rev, err := snapshot.Compact(lg, be, rev)
+	if err != nil {
+		return 0, err
+	}
+
+	if defrag {
+		if err = be.Defrag(); err != nil {
+			return 0, err
+		}
+	}
+	return rev, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// mustCreateDB creates the db file at dbPath with the given number of puts
+// to each of the keys.
+func mustCreateDB(t *testing.T, dbPath string, keys []string, revisions int) {
+	t.Helper()
+	lg := zaptest.NewLogger(t)
+	be := backend.NewDefaultBackend(lg, dbPath)
+	defer be.Close()
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer st.Close()
+	for i := 0; i < revisions; i++ {
+		for _, k := range keys {
+			st.Put([]byte(k), []byte(fmt.Sprint(i)), lease.NoLease)
+		}
+	}
+	be.ForceCommit()
+}
+
+func readDB(t *testing.T, dbPath string, rev int64) (*mvcc.RangeResult, error) {
+	t.Helper()
+	lg := zaptest.NewLogger(t)
+	be := backend.NewDefaultBackend(lg, dbPath)
+	defer be.Close()
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer st.Close()
+	return st.Range(context.TODO(), []byte("a"), []byte("z"), mvcc.RangeOptions{Rev: rev})
+}
+
+func TestCompactDB(t *testing.T) {
+	tcs := []struct {
+		name   string
+		rev    int64
+		defrag bool
+		expect int64
+	}{
+		{name: "current revision", expect: 7},
+		{name: "given revision", rev: 4, expect: 4},
+		{name: "defrag", rev: 4, defrag: true, expect: 4},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "db")
+			mustCreateDB(t, dbPath, []string{"a", "b"}, 3)
+
+			rev, err := CompactDB(zaptest.NewLogger(t), dbPath, tc.rev, tc.defrag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rev != tc.expect {
+				t.Errorf("compacted revision = %d, want %d", rev, tc.expect)
+			}
+
+			if _, err = readDB(t, dbPath, rev-1); !errors.Is(err, mvcc.ErrCompacted) {
+				t.Errorf("expected the revisions before %d to be compacted, got %v", rev, err)
+			}
+			r, err := readDB(t, dbPath, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.KVs) != 2 || r.Rev != 7 {
+				t.Errorf("expected the 2 keys at revision 7, got %d keys at revision %d", len(r.KVs), r.Rev)
+			}
+			for _, kv := range r.KVs {
+				if string(kv.Value) != "2" {
+					t.Errorf("key %q = %q, want the last put %q", kv.Key, kv.Value, "2")
+				}
+			}
+		})
+	}
+}
+
+func TestCompactDBFutureRevision(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db")
+	mustCreateDB(t, dbPath, []string{"a"}, 2)
+	if _, err := CompactDB(zaptest.NewLogger(t), dbPath, 10, false); !errors.Is(err, mvcc.ErrFutureRev) {
+		t.Fatalf("expected %v, got %v", mvcc.ErrFutureRev, err)
+	}
+}
+
+func TestCompactDBNotExist(t *testing.T) {
+	if _, err := CompactDB(zaptest.NewLogger(t), filepath.Join(t.TempDir(), "db"), 0, false); err == nil {
+		t.Fatal("expected an error for a missing db file")
+	}
+}
+
+func TestCompactCommandFlags(t *testing.T) {
+	cmd := NewCompactCommand()
+	if err := cmd.ParseFlags([]string{"--rev", "4", "--defrag"}); err != nil {
+		t.Fatal(err)
+	}
+	if compactRevision != 4 || !compactDefrag {
+		t.Errorf("got --rev %d --defrag %t, want --rev 4 --defrag true", compactRevision, compactDefrag)
+	}
+}
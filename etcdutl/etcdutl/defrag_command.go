@@ -24,10 +24,13 @@ import (
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 )
 
 var (
 	defragDataDir string
+	defragVerify  bool
+	defragForce   bool
 )
 
 // NewDefragCommand returns the cobra command for "Defrag".
@@ -38,20 +41,80 @@ func NewDefragCommand() *cobra.Command {
 		Run:   defragCommandFunc,
 	}
 	cmd.Flags().StringVar(&defragDataDir, "data-dir", "", "Required. Defragments a data directory not in use by etcd.")
+	cmd.Flags().BoolVar(&defragVerify, "verify", false, "Verify that key counts, consistent index and hash of the backend are unchanged by the defragmentation.")
+	cmd.Flags().BoolVar(&defragForce, "force", false, "Defragment even if the integrity check of the backend fails.")
 	cmd.MarkFlagRequired("data-dir")
 	cmd.MarkFlagDirname("data-dir")
 	return cmd
 }
 
 func defragCommandFunc(cmd *cobra.Command, args []string) {
-	err := DefragData(defragDataDir)
+	var err error
+	if defragVerify {
+		err = DefragDataWithVerify(defragDataDir, defragForce)
+	} else {
+		err = DefragData(defragDataDir, defragForce)
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError,
 			fmt.Errorf("Failed to defragment etcd data[%s] (%v)", defragDataDir, err))
 	}
 }
 
-func DefragData(dataDir string) error {
+// DefragData defragments the backend of the given data directory. Unless
+// force is true, it refuses to do so if the integrity check of the backend fails.
+func DefragData(dataDir string, force bool) error {
+	be := openDefragBackend(dataDir)
+	defer be.Close()
+	if err := checkBeforeDefrag(be, force); err != nil {
+		return err
+	}
+	return be.Defrag()
+}
+
+// DefragDataWithVerify defragments the backend of the given data directory and
+// cross-checks its content against the state read before the defragmentation.
+func DefragDataWithVerify(dataDir string, force bool) error {
+	be := openDefragBackend(dataDir)
+	defer be.Close()
+	if err := checkBeforeDefrag(be, force); err != nil {
+		return err
+	}
+
+	before, err := readBackendVerifyState(be)
+	if err != nil {
+		return fmt.Errorf("failed to read backend state before defragmentation: %v", err)
+	}
+	if err = be.Defrag(); err != nil {
+		return err
+	}
+	after, err := readBackendVerifyState(be)
+	if err != nil {
+		return fmt.Errorf("failed to read backend state after defragmentation: %v", err)
+	}
+	if before != after {
+		return fmt.Errorf("backend state changed by defragmentation: before %+v, after %+v", before, after)
+	}
+	fmt.Printf("Verified defragmentation: %+v\n", after)
+	return nil
+}
+
+// checkBeforeDefrag runs the integrity check of the backend, since
+// defragmenting a corrupted backend would only spread the corruption to the
+// rewritten database. With force, a failed check is only reported.
+func checkBeforeDefrag(be backend.Backend, force bool) error {
+	err := be.Check()
+	if err == nil {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("%v, use --force to defragment anyway", err)
+	}
+	fmt.Fprintf(os.Stderr, "ignoring failed integrity check due to --force: %v\n", err)
+	return nil
+}
+
+func openDefragBackend(dataDir string) backend.Backend {
 	var be backend.Backend
 	lg := GetLogger()
 	bch := make(chan struct{})
@@ -70,5 +133,40 @@ func DefragData(dataDir string) error {
 			"To defrag a running etcd instance, use `etcdctl defrag` instead.\n", dbDir)
 		<-bch
 	}
-	return be.Defrag()
+	return be
+}
+
+// backendVerifyState is the backend content compared before and after
+// an offline operation that must not change it.
+type backendVerifyState struct {
+	KeyCount        int
+	LeaseCount      int
+	ConsistentIndex uint64
+	Term            uint64
+	Hash            uint32
+}
+
+func readBackendVerifyState(be backend.Backend) (backendVerifyState, error) {
+	var st backendVerifyState
+	st.ConsistentIndex, st.Term = schema.ReadConsistentIndex(be.ReadTx())
+
+	tx := be.ReadTx()
+	tx.RLock()
+	err := tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
+		st.KeyCount++
+		return nil
+	})
+	if err == nil {
+		err = tx.UnsafeForEach(schema.Lease, func(k, v []byte) error {
+			st.LeaseCount++
+			return nil
+		})
+	}
+	tx.RUnlock()
+	if err != nil {
+		return st, err
+	}
+
+	st.Hash, err = be.Hash(func(bucketName, keyName []byte) bool { return false })
+	return st, err
 }
@@ -0,0 +1,105 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+func mustCreateDataDir(t *testing.T) string {
+	t.Helper()
+	dataDir := t.TempDir()
+	dbPath := datadir.ToBackendFileName(dataDir)
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	mustCreateDB(t, dbPath, []string{"a", "b", "c"}, 10)
+	return dataDir
+}
+
+func TestDefragData(t *testing.T) {
+	tcs := []struct {
+		name   string
+		defrag func(dataDir string, force bool) error
+	}{
+		{name: "defrag", defrag: DefragData},
+		{name: "defrag with verify", defrag: DefragDataWithVerify},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dataDir := mustCreateDataDir(t)
+			if _, err := CompactDB(zaptest.NewLogger(t), datadir.ToBackendFileName(dataDir), 0, false); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.defrag(dataDir, false); err != nil {
+				t.Fatal(err)
+			}
+			r, err := readDB(t, datadir.ToBackendFileName(dataDir), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.KVs) != 3 || r.Rev != 31 {
+				t.Errorf("expected the 3 keys at revision 31, got %d keys at revision %d", len(r.KVs), r.Rev)
+			}
+		})
+	}
+}
+
+func TestReadBackendVerifyState(t *testing.T) {
+	dataDir := mustCreateDataDir(t)
+	lg := zaptest.NewLogger(t)
+	be := backend.NewDefaultBackend(lg, datadir.ToBackendFileName(dataDir))
+	defer be.Close()
+
+	before, err := readBackendVerifyState(be)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.KeyCount != 30 {
+		t.Errorf("key count = %d, want the 30 revisions", before.KeyCount)
+	}
+	if err = be.Defrag(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := readBackendVerifyState(be)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != after {
+		t.Errorf("defragmentation changed the backend state from %+v to %+v", before, after)
+	}
+
+	// a change of the content is caught
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	st.Put([]byte("d"), []byte("v"), lease.NoLease)
+	st.Close()
+	be.ForceCommit()
+	changed, err := readBackendVerifyState(be)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.KeyCount != before.KeyCount+1 || changed.Hash == before.Hash {
+		t.Errorf("expected the put to change the backend state %+v, got %+v", before, changed)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+var hashKVRevision int64
+
+// HashKV is the hash of the key space of a db file, as returned by the HashKV RPC.
+type HashKV struct {
+	Hash            uint32 `json:"hash"`
+	HashRevision    int64  `json:"hashRevision"`
+	CompactRevision int64  `json:"compactRevision"`
+}
+
+// NewHashKVCommand returns the cobra command for "hashkv".
+func NewHashKVCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hashkv <db file> [options]",
+		Short: "Prints the KV history hash of a db file not in use by etcd",
+		Long: `Prints the KV history hash of a db file (for example <data-dir>/member/snap/db or a snapshot) not in use by etcd.
+The hash is computed as the HashKV RPC does, so that it can be compared with the hashes of the members of a live cluster.
+`,
+		Run: hashKVCommandFunc,
+	}
+	cmd.Flags().Int64Var(&hashKVRevision, "rev", 0, "Revision to compute the hash at (0 means the current revision of the db file)")
+	return cmd
+}
+
+func hashKVCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("hashkv requires exactly one argument"))
+	}
+	printer := initPrinterFromCmd(cmd)
+
+	h, err := ComputeHashKV(GetLogger(), args[0], hashKVRevision)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError,
+			fmt.Errorf("Failed to compute the hash of db file %q (%v)", args[0], err))
+	}
+	printer.DBHashKV(h)
+}
+
+// ComputeHashKV computes the hash of the key space of the given db file up to
+// rev, or up to its current revision if rev is 0.
+func ComputeHashKV(lg *zap.Logger, dbPath string, rev int64) (HashKV, error) {
+	if !fileutil.Exist(dbPath) {
+		return HashKV{}, fmt.Errorf("db file %q does not exist", dbPath)
+	}
+	be := backend.NewDefaultBackend(lg, dbPath)
+	defer be.Close()
+
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer st.Close()
+
+	h, _, err := st.HashStorage().HashByRev(rev)
+	if err != nil {
+		return HashKV{}, err
+	}
+	return HashKV{Hash: h.Hash, HashRevision: h.Revision, CompactRevision: h.CompactRevision}, nil
+}
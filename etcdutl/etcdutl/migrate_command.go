@@ -16,6 +16,8 @@ package etcdutl
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/coreos/go-semver/semver"
@@ -23,6 +25,7 @@ import (
 	"go.uber.org/zap"
 
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
@@ -56,6 +59,8 @@ type migrateOptions struct {
 	dataDir       string
 	targetVersion string
 	force         bool
+	dryRun        bool
+	backupBefore  string
 }
 
 func newMigrateOptions() *migrateOptions {
@@ -71,12 +76,15 @@ func (o *migrateOptions) AddFlags(cmd *cobra.Command) {
 	cmd.MarkFlagRequired("target-version")
 
 	cmd.Flags().BoolVar(&o.force, "force", o.force, "Ignore migration failure and forcefully override storage version. Not recommended.")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", o.dryRun, "Print every change the migration would make to the db without applying it.")
+	cmd.Flags().StringVar(&o.backupBefore, "backup-before", o.backupBefore, "Path to copy the db to before migrating it. Ignored with --dry-run.")
 }
 
 func (o *migrateOptions) Config() (*migrateConfig, error) {
 	c := &migrateConfig{
-		force: o.force,
-		lg:    GetLogger(),
+		force:  o.force,
+		dryRun: o.dryRun,
+		lg:     GetLogger(),
 	}
 	var err error
 	dotCount := strings.Count(o.targetVersion, ".")
@@ -92,6 +100,12 @@ func (o *migrateOptions) Config() (*migrateConfig, error) {
 	}
 
 	dbPath := datadir.ToBackendFileName(o.dataDir)
+	if o.backupBefore != "" && !o.dryRun {
+		if err = backupDB(dbPath, o.backupBefore); err != nil {
+			return nil, fmt.Errorf("failed to back up db to %q: %v", o.backupBefore, err)
+		}
+		c.lg.Info("backed up db before migration", zap.String("path", dbPath), zap.String("backup-path", o.backupBefore))
+	}
 	c.be = backend.NewDefaultBackend(GetLogger(), dbPath)
 
 	walPath := datadir.ToWalDir(o.dataDir)
@@ -114,6 +128,7 @@ type migrateConfig struct {
 	targetVersion *semver.Version
 	walVersion    schema.WALVersion
 	force         bool
+	dryRun        bool
 }
 
 func migrateCommandFunc(c *migrateConfig) error {
@@ -128,6 +143,9 @@ func migrateCommandFunc(c *migrateConfig) error {
 		c.lg.Info("storage version up-to-date", zap.String("storage-version", storageVersionToString(&current)))
 		return nil
 	}
+	if c.dryRun {
+		return migrateDryRun(c, current)
+	}
 	err = schema.Migrate(c.lg, tx, c.walVersion, *c.targetVersion)
 	if err != nil {
 		if !c.force {
@@ -140,6 +158,56 @@ func migrateCommandFunc(c *migrateConfig) error {
 	return nil
 }
 
+// migrateDryRun prints the changes the migration would make to the db.
+func migrateDryRun(c *migrateConfig, current semver.Version) error {
+	fmt.Printf("Migrating storage version %s to %s would make the following changes:\n",
+		storageVersionToString(&current), storageVersionToString(c.targetVersion))
+	changes, err := schema.PlanMigration(c.lg, c.be.ReadTx(), c.walVersion, *c.targetVersion)
+	if err != nil {
+		if !c.force {
+			return err
+		}
+		fmt.Printf("normal migrate would fail (%v), forcing it would ", err)
+		if c.targetVersion.LessThan(version.V3_6) {
+			fmt.Println("clear the storage version")
+		} else {
+			fmt.Printf("set the storage version to %s\n", storageVersionToString(c.targetVersion))
+		}
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	return nil
+}
+
+// backupDB copies the db file at dbPath to a new file at backupPath.
+func backupDB(dbPath, backupPath string) error {
+	if fileutil.Exist(backupPath) {
+		return fmt.Errorf("%q already exists", backupPath)
+	}
+	src, err := os.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = fileutil.Fsync(dst)
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(backupPath)
+	}
+	return err
+}
+
 func migrateForce(lg *zap.Logger, tx backend.BatchTx, target *semver.Version) {
 	tx.LockOutsideApply()
 	defer tx.Unlock()
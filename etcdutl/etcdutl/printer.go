@@ -32,6 +32,7 @@ var (
 
 type printer interface {
 	DBStatus(snapshot.Status)
+	DBHashKV(HashKV)
 }
 
 func NewPrinter(printerType string) printer {
@@ -65,6 +66,7 @@ func newPrinterUnsupported(n string) printer {
 }
 
 func (p *printerUnsupported) DBStatus(snapshot.Status) { p.p(nil) }
+func (p *printerUnsupported) DBHashKV(HashKV)          { p.p(nil) }
 
 func makeDBStatusTable(ds snapshot.Status) (hdr []string, rows [][]string) {
 	hdr = []string{"hash", "revision", "total keys", "total size", "version"}
@@ -78,6 +80,16 @@ func makeDBStatusTable(ds snapshot.Status) (hdr []string, rows [][]string) {
 	return hdr, rows
 }
 
+func makeDBHashKVTable(h HashKV) (hdr []string, rows [][]string) {
+	hdr = []string{"hash", "hash revision", "compact revision"}
+	rows = append(rows, []string{
+		fmt.Sprint(h.Hash),
+		fmt.Sprint(h.HashRevision),
+		fmt.Sprint(h.CompactRevision),
+	})
+	return hdr, rows
+}
+
 func initPrinterFromCmd(cmd *cobra.Command) (p printer) {
 	outputType, err := cmd.Flags().GetString("write-out")
 	if err != nil {
@@ -29,3 +29,9 @@ func (p *fieldsPrinter) DBStatus(r snapshot.Status) {
 	fmt.Println(`"Size" :`, r.TotalSize)
 	fmt.Println(`"Version" :`, r.Version)
 }
+
+func (p *fieldsPrinter) DBHashKV(h HashKV) {
+	fmt.Println(`"Hash" :`, h.Hash)
+	fmt.Println(`"HashRevision" :`, h.HashRevision)
+	fmt.Println(`"CompactRevision" :`, h.CompactRevision)
+}
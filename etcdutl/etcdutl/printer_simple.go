@@ -24,6 +24,13 @@ import (
 type simplePrinter struct {
 }
 
+func (s *simplePrinter) DBHashKV(h HashKV) {
+	_, rows := makeDBHashKVTable(h)
+	for _, row := range rows {
+		fmt.Println(strings.Join(row, ", "))
+	}
+}
+
 func (s *simplePrinter) DBStatus(ds snapshot.Status) {
 	_, rows := makeDBStatusTable(ds)
 	for _, row := range rows {
@@ -0,0 +1,105 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+var (
+	removeKeysDataDir string
+	removeKeysPrefix  string
+)
+
+// NewRemoveKeysCommand returns the cobra command for "remove-keys".
+func NewRemoveKeysCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-keys --prefix <prefix> --data-dir <data-dir>",
+		Short: "Removes a key range and its history from a data directory not in use by etcd",
+		Long: `Removes all keys with the given prefix, together with all their historical revisions, from a data
+directory not in use by etcd. The keys are first deleted at a new revision, so that the store revision
+keeps increasing, then every revision of the keys but the deletion itself is purged from the backend.
+
+This is meant for emergency recovery, when an application flooded the key space and the cluster
+cannot compact or defragment by itself. The backend content diverges from the other members, so
+the same command must be run on every member at the same raft index, or the other members must be
+restored from a snapshot of the modified one.
+`,
+		Run: removeKeysCommandFunc,
+	}
+	cmd.Flags().StringVar(&removeKeysDataDir, "data-dir", "", "Required. Path to the data directory not in use by etcd.")
+	cmd.Flags().StringVar(&removeKeysPrefix, "prefix", "", "Required. Prefix of the keys to remove.")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagRequired("prefix")
+	cmd.MarkFlagDirname("data-dir")
+	return cmd
+}
+
+func removeKeysCommandFunc(cmd *cobra.Command, args []string) {
+	if removeKeysPrefix == "" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--prefix must not be empty"))
+	}
+	deleted, purged, rev, err := RemoveKeys(GetLogger(), removeKeysDataDir, removeKeysPrefix)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError,
+			fmt.Errorf("Failed to remove keys from etcd data[%s] (%v)", removeKeysDataDir, err))
+	}
+	fmt.Printf("removed %d keys and %d revisions, current revision %d\n", deleted, purged, rev)
+}
+
+// RemoveKeys deletes the keys with the given prefix from the backend of the
+// given data directory and purges all their revisions but the deletion. It
+// returns the number of keys deleted, the number of revisions purged and the
+// store revision after the deletion.
+func RemoveKeys(lg *zap.Logger, dataDir, prefix string) (deleted int64, purged int, rev int64, err error) {
+	key, end := []byte(prefix), []byte(clientv3.GetPrefixRangeEnd(prefix))
+
+	be := openDefragBackend(dataDir)
+	defer be.Close()
+
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	txn := st.Write(traceutil.TODO())
+	deleted, rev = txn.DeleteRange(key, end)
+	txn.End()
+	st.Close()
+	be.ForceCommit()
+
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	purged, err = mvcc.UnsafePurgeKeyRange(tx, key, end)
+	tx.Unlock()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	be.ForceCommit()
+
+	lg.Info(
+		"removed keys",
+		zap.String("prefix", prefix),
+		zap.Int64("deleted-keys", deleted),
+		zap.Int("purged-revisions", purged),
+		zap.Int64("current-revision", rev),
+	)
+	return deleted, purged, rev, nil
+}
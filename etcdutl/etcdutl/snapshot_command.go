@@ -15,9 +15,15 @@
 package etcdutl
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/etcdutl/v3/snapshot"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
@@ -38,6 +44,21 @@ var (
 	restorePeerURLs     string
 	restoreName         string
 	skipHashCheck       bool
+
+	restoreFilterPrefixes        []string
+	restoreFilterExcludePrefixes []string
+	restoreAtRev                 int64
+	restoreForce                 bool
+	restoreIncrementals          []string
+
+	slimKeepLatest bool
+
+	exportOutput string
+	exportBase64 bool
+	importOutput string
+
+	snapshotKeyFile    string
+	snapshotKeyCommand string
 )
 
 // NewSnapshotCommand returns the cobra command for "snapshot".
@@ -48,18 +69,86 @@ func NewSnapshotCommand() *cobra.Command {
 	}
 	cmd.AddCommand(NewSnapshotRestoreCommand())
 	cmd.AddCommand(newSnapshotStatusCommand())
+	cmd.AddCommand(newSnapshotSlimCommand())
+	cmd.AddCommand(newSnapshotEncryptCommand())
+	cmd.AddCommand(newSnapshotDecryptCommand())
+	cmd.AddCommand(newSnapshotExportCommand())
+	cmd.AddCommand(newSnapshotImportCommand())
 	return cmd
 }
 
 func newSnapshotStatusCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "status <filename>",
 		Short: "Gets backend snapshot status of a given file",
 		Long: `When --write-out is set to simple, this command prints out comma-separated status lists for each endpoint.
 The items in the lists are hash, revision, total keys, total size.
+Encrypted snapshot files are decrypted to a temporary file first if a key is given.
 `,
 		Run: SnapshotStatusCommandFunc,
 	}
+	addSnapshotKeyFlags(cmd)
+	return cmd
+}
+
+func newSnapshotEncryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt <filename> <output filename> (--key-file <path> | --key-command <command>)",
+		Short: "Encrypts a snapshot file with AES-256-GCM",
+		Long: `Writes a copy of the given snapshot file encrypted with AES-256-GCM, which can be decrypted
+with "etcdutl snapshot decrypt". The key is 32 bytes, raw or base64 encoded, read from a file or
+from the standard output of a command, for example one fetching a data key from a KMS.
+`,
+		Run: snapshotEncryptCommandFunc,
+	}
+	addSnapshotKeyFlags(cmd)
+	return cmd
+}
+
+func newSnapshotDecryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decrypt <filename> <output filename> (--key-file <path> | --key-command <command>)",
+		Short: "Decrypts a snapshot file encrypted by \"etcdutl snapshot encrypt\"",
+		Run:   snapshotDecryptCommandFunc,
+	}
+	addSnapshotKeyFlags(cmd)
+	return cmd
+}
+
+func newSnapshotExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <filename> [options]",
+		Short: "Exports a snapshot file to JSON",
+		Long: `Writes the latest state of the key space (keys, values and their revisions), the leases and the
+authentication data of a snapshot file as human-readable JSON, which can be turned back into a
+snapshot file with "etcdutl snapshot import". The history of the key space is not exported.
+`,
+		Run: snapshotExportCommandFunc,
+	}
+	cmd.Flags().StringVar(&exportOutput, "output", "", "Path of the JSON file to write (standard output if none given)")
+	cmd.Flags().BoolVar(&exportBase64, "base64", false, "Encode keys and values in base64 (required for keys and values that are not valid UTF-8)")
+	cmd.Flags().BoolVar(&skipHashCheck, "skip-hash-check", false, "Ignore snapshot integrity hash value (required if copied from data directory)")
+	return cmd
+}
+
+func newSnapshotImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <json filename> --output <output filename>",
+		Short: "Imports a JSON file written by \"etcdutl snapshot export\" into a snapshot file",
+		Long: `Writes a snapshot file, which can be restored with "etcdutl snapshot restore", from the JSON written
+by "etcdutl snapshot export". Keys keep their revisions, and the key space is compacted at the exported
+revision since its history is not exported.
+`,
+		Run: snapshotImportCommandFunc,
+	}
+	cmd.Flags().StringVar(&importOutput, "output", "", "Path of the snapshot file to write")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func addSnapshotKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&snapshotKeyFile, "key-file", "", "Path to the file holding the 32 bytes encryption key, raw or base64 encoded")
+	cmd.Flags().StringVar(&snapshotKeyCommand, "key-command", "", "Command printing the 32 bytes encryption key, raw or base64 encoded, on its standard output (split on spaces, not run through a shell)")
 }
 
 func NewSnapshotRestoreCommand() *cobra.Command {
@@ -75,6 +164,11 @@ func NewSnapshotRestoreCommand() *cobra.Command {
 	cmd.Flags().StringVar(&restorePeerURLs, "initial-advertise-peer-urls", defaultInitialAdvertisePeerURLs, "List of this member's peer URLs to advertise to the rest of the cluster")
 	cmd.Flags().StringVar(&restoreName, "name", defaultName, "Human-readable name for this member")
 	cmd.Flags().BoolVar(&skipHashCheck, "skip-hash-check", false, "Ignore snapshot integrity hash value (required if copied from data directory)")
+	cmd.Flags().StringSliceVar(&restoreFilterPrefixes, "filter-prefix", nil, "Only restore the keys with one of the given prefixes (comma-separated or repeated)")
+	cmd.Flags().StringSliceVar(&restoreFilterExcludePrefixes, "filter-exclude-prefix", nil, "Do not restore the keys with one of the given prefixes (comma-separated or repeated)")
+	cmd.Flags().Int64Var(&restoreAtRev, "at-rev", 0, "Restore the key space as of the given revision, which must not be compacted in the snapshot (0 means the snapshot revision)")
+	cmd.Flags().BoolVar(&restoreForce, "force", false, "Restore even if the integrity check of the snapshot fails")
+	cmd.Flags().StringArrayVar(&restoreIncrementals, "incremental", nil, "Incremental backup file created by \"etcdutl backup incremental\" to apply on top of the snapshot (repeat in creation order)")
 
 	cmd.MarkFlagDirname("data-dir")
 	cmd.MarkFlagDirname("wal-dir")
@@ -82,6 +176,21 @@ func NewSnapshotRestoreCommand() *cobra.Command {
 	return cmd
 }
 
+func newSnapshotSlimCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slim <filename> <output filename> --keep-latest",
+		Short: "Writes a snapshot that only retains the latest revision of each key",
+		Long: `Writes a copy of the given snapshot file that only contains the latest revision of each key.
+Historical revisions and deleted keys are dropped, producing a minimal-size snapshot that can be
+restored with "etcdutl snapshot restore".
+`,
+		Run: snapshotSlimCommandFunc,
+	}
+	cmd.Flags().BoolVar(&slimKeepLatest, "keep-latest", false, "Keep only the latest revision of each key (required)")
+	cmd.Flags().BoolVar(&skipHashCheck, "skip-hash-check", false, "Ignore snapshot integrity hash value (required if copied from data directory)")
+	return cmd
+}
+
 func SnapshotStatusCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
 		err := fmt.Errorf("snapshot status requires exactly one argument")
@@ -91,16 +200,203 @@ func SnapshotStatusCommandFunc(cmd *cobra.Command, args []string) {
 
 	lg := GetLogger()
 	sp := snapshot.NewV3(lg)
-	ds, err := sp.Status(args[0])
+	dbPath := args[0]
+	var tmpDir string
+	if snapshotKeyFile != "" || snapshotKeyCommand != "" {
+		key := mustLoadSnapshotKey()
+		var err error
+		if tmpDir, err = os.MkdirTemp("", "etcdutl-snapshot-status"); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		}
+		dbPath = filepath.Join(tmpDir, "snapshot.db")
+		if err = sp.Decrypt(snapshot.EncryptConfig{SnapshotPath: args[0], OutputPath: dbPath, Key: key}); err != nil {
+			os.RemoveAll(tmpDir)
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		}
+	}
+	ds, err := sp.Status(dbPath)
+	if tmpDir != "" {
+		os.RemoveAll(tmpDir)
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
 	printer.DBStatus(ds)
 }
 
+func snapshotSlimCommandFunc(_ *cobra.Command, args []string) {
+	if len(args) != 2 {
+		err := fmt.Errorf("snapshot slim requires exactly two arguments")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	if !slimKeepLatest {
+		err := fmt.Errorf("snapshot slim requires --keep-latest")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+
+	lg := GetLogger()
+	sp := snapshot.NewV3(lg)
+	if err := sp.Slim(snapshot.SlimConfig{
+		SnapshotPath:  args[0],
+		OutputPath:    args[1],
+		SkipHashCheck: skipHashCheck,
+	}); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Slimmed snapshot %q written to %q\n", args[0], args[1])
+}
+
+func snapshotEncryptCommandFunc(_ *cobra.Command, args []string) {
+	if len(args) != 2 {
+		err := fmt.Errorf("snapshot encrypt requires exactly two arguments")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	sp := snapshot.NewV3(GetLogger())
+	if err := sp.Encrypt(snapshot.EncryptConfig{
+		SnapshotPath: args[0],
+		OutputPath:   args[1],
+		Key:          mustLoadSnapshotKey(),
+	}); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Encrypted snapshot %q written to %q\n", args[0], args[1])
+}
+
+func snapshotDecryptCommandFunc(_ *cobra.Command, args []string) {
+	if len(args) != 2 {
+		err := fmt.Errorf("snapshot decrypt requires exactly two arguments")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	sp := snapshot.NewV3(GetLogger())
+	if err := sp.Decrypt(snapshot.EncryptConfig{
+		SnapshotPath: args[0],
+		OutputPath:   args[1],
+		Key:          mustLoadSnapshotKey(),
+	}); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Decrypted snapshot %q written to %q\n", args[0], args[1])
+}
+
+func snapshotExportCommandFunc(_ *cobra.Command, args []string) {
+	if len(args) != 1 {
+		err := fmt.Errorf("snapshot export requires exactly one argument")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	cfg := snapshot.ExportConfig{
+		SnapshotPath:  args[0],
+		Output:        os.Stdout,
+		Encoding:      snapshot.ExportEncodingText,
+		SkipHashCheck: skipHashCheck,
+	}
+	if exportBase64 {
+		cfg.Encoding = snapshot.ExportEncodingBase64
+	}
+
+	var f *os.File
+	if exportOutput != "" {
+		var err error
+		if f, err = os.OpenFile(exportOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileutil.PrivateFileMode); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		}
+		cfg.Output = f
+	}
+	err := snapshot.NewV3(GetLogger()).Export(cfg)
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(exportOutput)
+		}
+	}
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	if f != nil {
+		fmt.Printf("Snapshot %q exported to %q\n", args[0], exportOutput)
+	}
+}
+
+func snapshotImportCommandFunc(_ *cobra.Command, args []string) {
+	if len(args) != 1 {
+		err := fmt.Errorf("snapshot import requires exactly one argument")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	sp := snapshot.NewV3(GetLogger())
+	if err := sp.Import(snapshot.ImportConfig{InputPath: args[0], OutputPath: importOutput}); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Snapshot %q imported to %q\n", args[0], importOutput)
+}
+
+// mustLoadSnapshotKey returns the encryption key given by either --key-file
+// or --key-command, exiting on error.
+func mustLoadSnapshotKey() []byte {
+	var (
+		raw []byte
+		err error
+	)
+	switch {
+	case snapshotKeyFile != "" && snapshotKeyCommand != "":
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--key-file and --key-command cannot be set together"))
+	case snapshotKeyFile != "":
+		raw, err = os.ReadFile(snapshotKeyFile)
+	case snapshotKeyCommand != "":
+		args := strings.Fields(snapshotKeyCommand)
+		if len(args) == 0 {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--key-command must not be empty"))
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stderr = os.Stderr
+		raw, err = cmd.Output()
+	default:
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("one of --key-file or --key-command is required"))
+	}
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to load encryption key: %v", err))
+	}
+	key, err := parseSnapshotKey(raw)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	return key
+}
+
+// parseSnapshotKey accepts a raw key or a base64 encoded one, surrounded
+// by optional whitespaces.
+func parseSnapshotKey(raw []byte) ([]byte, error) {
+	if len(raw) == snapshot.EncryptionKeySize {
+		return raw, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil || len(key) != snapshot.EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, raw or base64 encoded", snapshot.EncryptionKeySize)
+	}
+	return key, nil
+}
+
 func snapshotRestoreCommandFunc(_ *cobra.Command, args []string) {
-	SnapshotRestoreCommandFunc(restoreCluster, restoreClusterToken, restoreDataDir, restoreWalDir,
-		restorePeerURLs, restoreName, skipHashCheck, args)
+	snapshotRestore(restoreCluster, restoreClusterToken, restoreDataDir, restoreWalDir,
+		restorePeerURLs, restoreName, skipHashCheck, restoreOptions{
+			includePrefixes: restoreFilterPrefixes,
+			excludePrefixes: restoreFilterExcludePrefixes,
+			revision:        restoreAtRev,
+			force:           restoreForce,
+			incrementals:    restoreIncrementals,
+		}, args)
+}
+
+// restoreOptions holds the snapshot restore options not exposed by
+// SnapshotRestoreCommandFunc.
+type restoreOptions struct {
+	// includePrefixes, excludePrefixes and revision select the part of
+	// the key space restored from the snapshot.
+	includePrefixes []string
+	excludePrefixes []string
+	revision        int64
+	force           bool
+	incrementals    []string
 }
 
 func SnapshotRestoreCommandFunc(restoreCluster string,
@@ -111,10 +407,27 @@ func SnapshotRestoreCommandFunc(restoreCluster string,
 	restoreName string,
 	skipHashCheck bool,
 	args []string) {
+	snapshotRestore(restoreCluster, restoreClusterToken, restoreDataDir, restoreWalDir,
+		restorePeerURLs, restoreName, skipHashCheck, restoreOptions{}, args)
+}
+
+func snapshotRestore(restoreCluster string,
+	restoreClusterToken string,
+	restoreDataDir string,
+	restoreWalDir string,
+	restorePeerURLs string,
+	restoreName string,
+	skipHashCheck bool,
+	opts restoreOptions,
+	args []string) {
 	if len(args) != 1 {
 		err := fmt.Errorf("snapshot restore requires exactly one argument")
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
 	}
+	if opts.revision < 0 {
+		err := fmt.Errorf("--at-rev must not be negative")
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
 
 	dataDir := restoreDataDir
 	if dataDir == "" {
@@ -130,14 +443,19 @@ func SnapshotRestoreCommandFunc(restoreCluster string,
 	sp := snapshot.NewV3(lg)
 
 	if err := sp.Restore(snapshot.RestoreConfig{
-		SnapshotPath:        args[0],
-		Name:                restoreName,
-		OutputDataDir:       dataDir,
-		OutputWALDir:        walDir,
-		PeerURLs:            strings.Split(restorePeerURLs, ","),
-		InitialCluster:      restoreCluster,
-		InitialClusterToken: restoreClusterToken,
-		SkipHashCheck:       skipHashCheck,
+		SnapshotPath:           args[0],
+		Name:                   restoreName,
+		OutputDataDir:          dataDir,
+		OutputWALDir:           walDir,
+		PeerURLs:               strings.Split(restorePeerURLs, ","),
+		InitialCluster:         restoreCluster,
+		InitialClusterToken:    restoreClusterToken,
+		SkipHashCheck:          skipHashCheck,
+		IncludePrefixes:        opts.includePrefixes,
+		ExcludePrefixes:        opts.excludePrefixes,
+		Revision:               opts.revision,
+		Force:                  opts.force,
+		IncrementalBackupPaths: opts.incrementals,
 	}); err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
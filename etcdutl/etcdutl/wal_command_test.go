@@ -0,0 +1,220 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+func mustCreateWAL(t *testing.T, reqs ...*pb.InternalRaftRequest) string {
+	t.Helper()
+	walDir := filepath.Join(t.TempDir(), "wal")
+	w, err := wal.Create(zaptest.NewLogger(t), walDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ents []raftpb.Entry
+	for i, r := range reqs {
+		e := raftpb.Entry{Term: 1, Index: uint64(i + 1)}
+		if r != nil {
+			if e.Data, err = r.Marshal(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ents = append(ents, e)
+	}
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: uint64(len(ents))}, ents); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return walDir
+}
+
+func testWALRequests() []*pb.InternalRaftRequest {
+	return []*pb.InternalRaftRequest{
+		{Put: &pb.PutRequest{Key: []byte("foo"), Value: []byte("1")}},
+		{Put: &pb.PutRequest{Key: []byte("bar"), Value: []byte("2")}},
+		{Txn: &pb.TxnRequest{
+			Compare: []*pb.Compare{{Key: []byte("baz")}},
+			Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("foo/x")}}}},
+		}},
+		nil,
+	}
+}
+
+func TestDumpWAL(t *testing.T) {
+	walDir := mustCreateWAL(t, testWALRequests()...)
+
+	tcs := []struct {
+		name   string
+		opts   walDumpOptions
+		expect []string
+	}{
+		{
+			name: "all entries",
+			expect: []string{
+				`term=1 index=1 type=put key="foo"`,
+				`term=1 index=2 type=put key="bar"`,
+				`term=1 index=3 type=txn key="baz"`,
+				`term=1 index=4 type=empty`,
+			},
+		},
+		{
+			name: "index range",
+			opts: walDumpOptions{startIndex: 2, endIndex: 3},
+			expect: []string{
+				`term=1 index=2 type=put key="bar"`,
+				`term=1 index=3 type=txn key="baz"`,
+			},
+		},
+		{
+			name: "key prefix",
+			opts: walDumpOptions{keyPrefix: "foo"},
+			expect: []string{
+				`term=1 index=1 type=put key="foo"`,
+				`term=1 index=3 type=txn key="foo/x"`,
+			},
+		},
+		{
+			name: "health only",
+			opts: walDumpOptions{healthOnly: true},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			healthy, err := dumpWAL(&out, walDir, &tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !healthy {
+				t.Errorf("expected a healthy WAL, got:\n%s", out.String())
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tc.expect)+1 {
+				t.Fatalf("expected %d entries and a segment report, got:\n%s", len(tc.expect), out.String())
+			}
+			for i, want := range tc.expect {
+				if lines[i] != want {
+					t.Errorf("line %d = %q, want %q", i, lines[i], want)
+				}
+			}
+			report := lines[len(lines)-1]
+			if !strings.HasPrefix(report, "segment ") || !strings.Contains(report, "entries=4 index=[1, 4]") || !strings.HasSuffix(report, " OK") {
+				t.Errorf("unexpected segment report %q", report)
+			}
+		})
+	}
+}
+
+func TestDumpWALCorrupted(t *testing.T) {
+	walDir := mustCreateWAL(t, testWALRequests()...)
+
+	names, err := fileutil.ReadDir(walDir, fileutil.WithExt(".wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(walDir, names[0])
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(b, []byte("bar"))
+	if i < 0 {
+		t.Fatal("key not found in the WAL segment")
+	}
+	// the record keeps decoding, but fails the CRC validation
+	b[i] = 'c'
+	if err = os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	healthy, err := dumpWAL(&out, walDir, &walDumpOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healthy {
+		t.Fatalf("expected a corrupted WAL, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `type=put key="car"`) {
+		t.Errorf("expected the dump to continue past the corrupted record, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "crc-mismatches=") || !strings.Contains(out.String(), " CORRUPTED") {
+		t.Errorf("expected the segment to be reported corrupted, got:\n%s", out.String())
+	}
+}
+
+func TestDumpWALNoSegment(t *testing.T) {
+	if _, err := dumpWAL(&bytes.Buffer{}, t.TempDir(), &walDumpOptions{}); err == nil {
+		t.Fatal("expected an error for a directory without WAL segments")
+	}
+}
+
+func TestDescribeWALEntry(t *testing.T) {
+	tcs := []struct {
+		req      *pb.InternalRaftRequest
+		wantType string
+		wantKeys [][]byte
+	}{
+		{
+			req:      &pb.InternalRaftRequest{PrefixQuota: &pb.PrefixQuotaRequest{Prefix: []byte("foo/")}},
+			wantType: "prefix-quota",
+			wantKeys: [][]byte{[]byte("foo/")},
+		},
+		{
+			req:      &pb.InternalRaftRequest{RevisionPin: &pb.RevisionPinRequest{}},
+			wantType: "revision-pin",
+		},
+		{
+			req:      &pb.InternalRaftRequest{AuthRoleSetQuota: &pb.AuthRoleSetQuotaRequest{Role: "foo"}},
+			wantType: "auth-role-set-quota",
+		},
+		{
+			req:      &pb.InternalRaftRequest{AuthUserAdd: &pb.AuthUserAddRequest{Name: "foo"}},
+			wantType: "auth-user-add",
+		},
+		{
+			req:      &pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: 1}},
+			wantType: "unknown",
+		},
+	}
+	for _, tc := range tcs {
+		data, err := tc.req.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ, keys := describeWALEntry(raftpb.Entry{Type: raftpb.EntryNormal, Data: data})
+		if typ != tc.wantType {
+			t.Errorf("type = %q, want %q", typ, tc.wantType)
+		}
+		if len(keys) != len(tc.wantKeys) || (len(keys) > 0 && !bytes.Equal(keys[0], tc.wantKeys[0])) {
+			t.Errorf("%s: keys = %q, want %q", typ, keys, tc.wantKeys)
+		}
+	}
+}
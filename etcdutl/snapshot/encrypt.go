@@ -0,0 +1,273 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+// Encrypted snapshot file format:
+//
+//	header: magic (8 bytes) | version (1 byte) | chunk size (4 bytes) | nonce prefix (8 bytes)
+//	chunks: AES-256-GCM sealed chunks of at most chunk size plaintext bytes
+//
+// The nonce of chunk i is the nonce prefix followed by i as a big-endian
+// uint32. Every chunk is authenticated together with the header and a flag
+// telling whether it is the last chunk, so that reordered, truncated or
+// extended files fail to decrypt.
+const (
+	encryptedMagic          = "ETCDSENC"
+	encryptedVersion        = 1
+	encryptedNoncePrefixLen = 8
+	encryptedHeaderSize     = len(encryptedMagic) + 1 + 4 + encryptedNoncePrefixLen
+	encryptedChunkSize      = 1024 * 1024
+	encryptedMaxChunkSize   = 64 * 1024 * 1024
+)
+
+// EncryptionKeySize is the size in bytes of the AES-256 keys used to
+// encrypt snapshot files.
+const EncryptionKeySize = 32
+
+var (
+	// ErrEncrypted is returned when reading an encrypted snapshot file
+	// without decrypting it first.
+	ErrEncrypted = errors.New("snapshot file is encrypted, decrypt it first with \"etcdutl snapshot decrypt\"")
+	// ErrDecrypt is returned when an encrypted snapshot file cannot be
+	// authenticated, because of a wrong key or a corrupted file.
+	ErrDecrypt = errors.New("failed to decrypt snapshot file (wrong key or corrupted file)")
+)
+
+// EncryptConfig configures snapshot encrypt and decrypt operations.
+type EncryptConfig struct {
+	// SnapshotPath is the path of snapshot file to read from.
+	SnapshotPath string
+	// OutputPath is the path of the file to write.
+	// It returns an error if OutputPath already exists.
+	OutputPath string
+	// Key is the AES-256 key, of EncryptionKeySize bytes.
+	Key []byte
+}
+
+// IsEncrypted returns true if the file at the given path is an encrypted
+// snapshot file.
+func IsEncrypted(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	magic := make([]byte, len(encryptedMagic))
+	if _, err = io.ReadFull(f, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic) == encryptedMagic, nil
+}
+
+// Encrypt writes an encrypted copy of the snapshot file, including its
+// integrity hash, if any.
+func (s *v3Manager) Encrypt(cfg EncryptConfig) error {
+	if encrypted, err := IsEncrypted(cfg.SnapshotPath); err != nil {
+		return err
+	} else if encrypted {
+		return fmt.Errorf("snapshot file %q is already encrypted", cfg.SnapshotPath)
+	}
+	if err := transformSnapshot(cfg, encryptSnapshot); err != nil {
+		return err
+	}
+	s.lg.Info("encrypted snapshot", zap.String("path", cfg.SnapshotPath), zap.String("output-path", cfg.OutputPath))
+	return nil
+}
+
+// Decrypt writes a decrypted copy of an encrypted snapshot file.
+func (s *v3Manager) Decrypt(cfg EncryptConfig) error {
+	if err := transformSnapshot(cfg, decryptSnapshot); err != nil {
+		return err
+	}
+	s.lg.Info("decrypted snapshot", zap.String("path", cfg.SnapshotPath), zap.String("output-path", cfg.OutputPath))
+	return nil
+}
+
+// transformSnapshot writes the snapshot file transformed by f to the output
+// path through a temporary file, so that the output path only exists once
+// complete.
+func transformSnapshot(cfg EncryptConfig, f func(dst io.Writer, src *bufio.Reader, aead cipher.AEAD) error) error {
+	if cfg.SnapshotPath == cfg.OutputPath {
+		return fmt.Errorf("output path %q must be different from snapshot path", cfg.OutputPath)
+	}
+	if fileutil.Exist(cfg.OutputPath) {
+		return fmt.Errorf("output path %q already exists", cfg.OutputPath)
+	}
+	aead, err := newSnapshotAEAD(cfg.Key)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(cfg.SnapshotPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	partPath := cfg.OutputPath + ".part"
+	dst, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(dst)
+	err = f(w, bufio.NewReader(src), aead)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = fileutil.Fsync(dst)
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(partPath, cfg.OutputPath)
+	}
+	if err != nil {
+		os.Remove(partPath)
+	}
+	return err
+}
+
+func newSnapshotAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("invalid encryption key size %d, expected %d bytes", len(key), EncryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptSnapshot(dst io.Writer, src *bufio.Reader, aead cipher.AEAD) error {
+	header := make([]byte, encryptedHeaderSize)
+	copy(header, encryptedMagic)
+	header[len(encryptedMagic)] = encryptedVersion
+	binary.BigEndian.PutUint32(header[len(encryptedMagic)+1:], encryptedChunkSize)
+	if _, err := io.ReadFull(rand.Reader, header[encryptedHeaderSize-encryptedNoncePrefixLen:]); err != nil {
+		return err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+	return sealChunks(dst, src, aead, header, encryptedChunkSize)
+}
+
+func decryptSnapshot(dst io.Writer, src *bufio.Reader, aead cipher.AEAD) error {
+	header := make([]byte, encryptedHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil || !bytes.Equal(header[:len(encryptedMagic)], []byte(encryptedMagic)) {
+		return fmt.Errorf("not an encrypted snapshot file")
+	}
+	if v := header[len(encryptedMagic)]; v != encryptedVersion {
+		return fmt.Errorf("unsupported encrypted snapshot version %d", v)
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[len(encryptedMagic)+1:]))
+	if chunkSize <= 0 || chunkSize > encryptedMaxChunkSize {
+		return fmt.Errorf("invalid encrypted snapshot chunk size %d", chunkSize)
+	}
+	return openChunks(dst, src, aead, header, chunkSize+aead.Overhead())
+}
+
+// sealChunks encrypts src into chunks of chunkSize plaintext bytes.
+func sealChunks(dst io.Writer, src *bufio.Reader, aead cipher.AEAD, header []byte, chunkSize int) error {
+	buf := make([]byte, chunkSize, chunkSize+aead.Overhead())
+	for i := uint32(0); ; i++ {
+		n, last, err := readChunk(src, buf)
+		if err != nil {
+			return err
+		}
+		out := aead.Seal(buf[:0], chunkNonce(header, i), buf[:n], chunkAAD(header, last))
+		if _, err = dst.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		buf = buf[:chunkSize]
+	}
+}
+
+// openChunks decrypts src made of chunks of at most sealedChunkSize bytes.
+func openChunks(dst io.Writer, src *bufio.Reader, aead cipher.AEAD, header []byte, sealedChunkSize int) error {
+	buf := make([]byte, sealedChunkSize)
+	for i := uint32(0); ; i++ {
+		n, last, err := readChunk(src, buf)
+		if err != nil {
+			return err
+		}
+		out, err := aead.Open(buf[:0], chunkNonce(header, i), buf[:n], chunkAAD(header, last))
+		if err != nil {
+			return ErrDecrypt
+		}
+		if _, err = dst.Write(out); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// readChunk fills buf from src and reports whether it is the last chunk.
+func readChunk(src *bufio.Reader, buf []byte) (n int, last bool, err error) {
+	n, err = io.ReadFull(src, buf)
+	switch err {
+	case nil:
+		if _, err = src.Peek(1); err == io.EOF {
+			return n, true, nil
+		}
+		return n, false, err
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, true, nil
+	}
+	return n, false, err
+}
+
+func chunkNonce(header []byte, i uint32) []byte {
+	nonce := make([]byte, encryptedNoncePrefixLen+4)
+	copy(nonce, header[encryptedHeaderSize-encryptedNoncePrefixLen:])
+	binary.BigEndian.PutUint32(nonce[encryptedNoncePrefixLen:], i)
+	return nonce
+}
+
+func chunkAAD(header []byte, last bool) []byte {
+	aad := make([]byte, len(header)+1)
+	copy(aad, header)
+	if last {
+		aad[len(header)] = 1
+	}
+	return aad
+}
@@ -0,0 +1,374 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/lease/leasepb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// Encodings of the keys and values of exported snapshots.
+const (
+	ExportEncodingText   = "text"
+	ExportEncodingBase64 = "base64"
+)
+
+// ExportedSnapshot is the JSON representation of the latest state of the
+// key space, the leases and the authentication data of a snapshot file.
+// Keys, values and permission ranges are encoded as given by Encoding.
+type ExportedSnapshot struct {
+	Revision int64            `json:"revision"`
+	Encoding string           `json:"encoding"`
+	KVs      []ExportedKV     `json:"kvs"`
+	Leases   []ExportedLease  `json:"leases"`
+	Auth     ExportedAuthData `json:"auth"`
+}
+
+type ExportedKV struct {
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+	Lease          int64  `json:"lease,omitempty"`
+}
+
+type ExportedLease struct {
+	ID           int64 `json:"id"`
+	TTL          int64 `json:"ttl"`
+	RemainingTTL int64 `json:"remaining_ttl,omitempty"`
+}
+
+type ExportedAuthData struct {
+	Enabled  bool           `json:"enabled"`
+	Revision uint64         `json:"revision"`
+	Users    []ExportedUser `json:"users"`
+	Roles    []ExportedRole `json:"roles"`
+}
+
+type ExportedUser struct {
+	Name string `json:"name"`
+	// Password is the bcrypt hash of the password of the user.
+	Password   string   `json:"password,omitempty"`
+	Roles      []string `json:"roles"`
+	NoPassword bool     `json:"no_password,omitempty"`
+}
+
+type ExportedRole struct {
+	Name        string               `json:"name"`
+	Permissions []ExportedPermission `json:"permissions"`
+}
+
+type ExportedPermission struct {
+	Type     string `json:"type"`
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end,omitempty"`
+}
+
+// ExportConfig configures snapshot export.
+type ExportConfig struct {
+	// SnapshotPath is the path of snapshot file to export.
+	SnapshotPath string
+	// Output receives the JSON encoded ExportedSnapshot.
+	Output io.Writer
+	// Encoding is the encoding of keys and values, ExportEncodingText by
+	// default. Text encoding fails on keys or values that are not valid
+	// UTF-8.
+	Encoding string
+	// SkipHashCheck is "true" to ignore snapshot integrity hash value
+	// (required if copied from data directory).
+	SkipHashCheck bool
+}
+
+// ImportConfig configures snapshot import.
+type ImportConfig struct {
+	// InputPath is the path of the JSON file written by Export.
+	InputPath string
+	// OutputPath is the path of the snapshot file to write.
+	// It returns an error if OutputPath already exists.
+	OutputPath string
+}
+
+// Export writes the latest state of the key space, the leases and the
+// authentication data of the snapshot file as JSON. The history of the key
+// space is not exported.
+func (s *v3Manager) Export(cfg ExportConfig) error {
+	enc, err := newExportEncoding(cfg.Encoding)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "etcdutl-export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "db")
+	if err = copyAndVerifyDB(cfg.SnapshotPath, dbPath, cfg.SkipHashCheck); err != nil {
+		return err
+	}
+
+	be := backend.NewDefaultBackend(s.lg, dbPath)
+	defer be.Close()
+	es, err := exportBackend(s.lg, be, enc)
+	if err != nil {
+		return err
+	}
+
+	e := json.NewEncoder(cfg.Output)
+	e.SetIndent("", "  ")
+	if err = e.Encode(es); err != nil {
+		return err
+	}
+	s.lg.Info("exported snapshot", zap.String("path", cfg.SnapshotPath), zap.Int64("revision", es.Revision), zap.Int("keys", len(es.KVs)))
+	return nil
+}
+
+func exportBackend(lg *zap.Logger, be backend.Backend, enc exportEncoding) (*ExportedSnapshot, error) {
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer st.Close()
+	// an empty, non-nil end ranges over all keys from key
+	r, err := st.Range(context.TODO(), []byte{0}, []byte{}, mvcc.RangeOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	es := &ExportedSnapshot{Revision: r.Rev, Encoding: enc.name, KVs: make([]ExportedKV, 0, len(r.KVs))}
+	for _, kv := range r.KVs {
+		key, err := enc.encode(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := enc.encode(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("value of key %q: %w", kv.Key, err)
+		}
+		es.KVs = append(es.KVs, ExportedKV{
+			Key:            key,
+			Value:          value,
+			CreateRevision: kv.CreateRevision,
+			ModRevision:    kv.ModRevision,
+			Version:        kv.Version,
+			Lease:          kv.Lease,
+		})
+	}
+
+	tx := be.ReadTx()
+	tx.RLock()
+	leases := schema.MustUnsafeGetAllLeases(tx)
+	tx.RUnlock()
+	sort.Slice(leases, func(i, j int) bool { return leases[i].ID < leases[j].ID })
+	es.Leases = make([]ExportedLease, 0, len(leases))
+	for _, l := range leases {
+		es.Leases = append(es.Leases, ExportedLease{ID: l.ID, TTL: l.TTL, RemainingTTL: l.RemainingTTL})
+	}
+
+	abe := schema.NewAuthBackend(lg, be)
+	atx := abe.ReadTx()
+	atx.Lock()
+	es.Auth.Enabled = atx.UnsafeReadAuthEnabled()
+	es.Auth.Revision = atx.UnsafeReadAuthRevision()
+	atx.Unlock()
+	es.Auth.Users = make([]ExportedUser, 0)
+	for _, u := range abe.GetAllUsers() {
+		eu := ExportedUser{Name: string(u.Name), Password: string(u.Password), Roles: u.Roles}
+		if u.Options != nil {
+			eu.NoPassword = u.Options.NoPassword
+		}
+		es.Auth.Users = append(es.Auth.Users, eu)
+	}
+	es.Auth.Roles = make([]ExportedRole, 0)
+	for _, role := range abe.GetAllRoles() {
+		er := ExportedRole{Name: string(role.Name), Permissions: make([]ExportedPermission, 0, len(role.KeyPermission))}
+		for _, p := range role.KeyPermission {
+			key, err := enc.encode(p.Key)
+			if err != nil {
+				return nil, fmt.Errorf("permission of role %q: %w", role.Name, err)
+			}
+			end, err := enc.encode(p.RangeEnd)
+			if err != nil {
+				return nil, fmt.Errorf("permission of role %q: %w", role.Name, err)
+			}
+			er.Permissions = append(er.Permissions, ExportedPermission{Type: p.PermType.String(), Key: key, RangeEnd: end})
+		}
+		es.Auth.Roles = append(es.Auth.Roles, er)
+	}
+	return es, nil
+}
+
+// Import writes a snapshot file, with an integrity hash, holding the state
+// exported by Export. Keys keep their revisions and the key space is
+// compacted at the exported revision, since its history is not exported.
+func (s *v3Manager) Import(cfg ImportConfig) error {
+	if fileutil.Exist(cfg.OutputPath) {
+		return fmt.Errorf("output path %q already exists", cfg.OutputPath)
+	}
+	f, err := os.Open(cfg.InputPath)
+	if err != nil {
+		return err
+	}
+	var es ExportedSnapshot
+	err = json.NewDecoder(f).Decode(&es)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decode %q: %v", cfg.InputPath, err)
+	}
+	enc, err := newExportEncoding(es.Encoding)
+	if err != nil {
+		return err
+	}
+
+	partPath := cfg.OutputPath + ".part"
+	os.Remove(partPath)
+	be := backend.NewDefaultBackend(s.lg, partPath)
+	err = importBackend(s.lg, be, &es, enc)
+	be.Close()
+	if err == nil {
+		err = appendChecksum(partPath)
+	}
+	if err == nil {
+		err = os.Rename(partPath, cfg.OutputPath)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	s.lg.Info("imported snapshot", zap.String("path", cfg.InputPath), zap.String("output-path", cfg.OutputPath), zap.Int64("revision", es.Revision), zap.Int("keys", len(es.KVs)))
+	return nil
+}
+
+func importBackend(lg *zap.Logger, be backend.Backend, es *ExportedSnapshot, enc exportEncoding) error {
+	// creates the key and meta buckets
+	mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{}).Close()
+
+	abe := schema.NewAuthBackend(lg, be)
+	abe.CreateAuthBuckets()
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	defer tx.Unlock()
+
+	// keys modified in the same transaction share their mod revision and
+	// get increasing sub revisions.
+	subs := make(map[int64]int64)
+	for _, ekv := range es.KVs {
+		if ekv.ModRevision <= 0 || ekv.ModRevision > es.Revision {
+			return fmt.Errorf("key %q has mod revision %d out of the exported revision range [1, %d]", ekv.Key, ekv.ModRevision, es.Revision)
+		}
+		key, err := enc.decode(ekv.Key)
+		if err != nil {
+			return err
+		}
+		value, err := enc.decode(ekv.Value)
+		if err != nil {
+			return err
+		}
+		kv := &mvccpb.KeyValue{
+			Key:            key,
+			Value:          value,
+			CreateRevision: ekv.CreateRevision,
+			ModRevision:    ekv.ModRevision,
+			Version:        ekv.Version,
+			Lease:          ekv.Lease,
+		}
+		if err = mvcc.UnsafePutKeyValue(tx, kv, subs[ekv.ModRevision]); err != nil {
+			return err
+		}
+		subs[ekv.ModRevision]++
+	}
+	mvcc.UnsafeSetScheduledCompact(tx, es.Revision)
+	mvcc.UnsafeSetFinishedCompact(tx, es.Revision)
+
+	schema.UnsafeCreateLeaseBucket(tx)
+	for _, l := range es.Leases {
+		schema.MustUnsafePutLease(tx, &leasepb.Lease{ID: l.ID, TTL: l.TTL, RemainingTTL: l.RemainingTTL})
+	}
+
+	atx := abe.BatchTx()
+	for _, u := range es.Auth.Users {
+		user := &authpb.User{Name: []byte(u.Name), Password: []byte(u.Password), Roles: u.Roles}
+		if u.NoPassword {
+			user.Options = &authpb.UserAddOptions{NoPassword: true}
+		}
+		atx.UnsafePutUser(user)
+	}
+	for _, r := range es.Auth.Roles {
+		role := &authpb.Role{Name: []byte(r.Name)}
+		for _, p := range r.Permissions {
+			typ, ok := authpb.Permission_Type_value[p.Type]
+			if !ok {
+				return fmt.Errorf("role %q has a permission of unknown type %q", r.Name, p.Type)
+			}
+			key, err := enc.decode(p.Key)
+			if err != nil {
+				return err
+			}
+			end, err := enc.decode(p.RangeEnd)
+			if err != nil {
+				return err
+			}
+			role.KeyPermission = append(role.KeyPermission, &authpb.Permission{PermType: authpb.Permission_Type(typ), Key: key, RangeEnd: end})
+		}
+		atx.UnsafePutRole(role)
+	}
+	atx.UnsafeSaveAuthEnabled(es.Auth.Enabled)
+	atx.UnsafeSaveAuthRevision(es.Auth.Revision)
+	return nil
+}
+
+type exportEncoding struct {
+	name   string
+	encode func([]byte) (string, error)
+	decode func(string) ([]byte, error)
+}
+
+func newExportEncoding(name string) (exportEncoding, error) {
+	switch name {
+	case "", ExportEncodingText:
+		return exportEncoding{
+			name: ExportEncodingText,
+			encode: func(b []byte) (string, error) {
+				if !utf8.Valid(b) {
+					return "", fmt.Errorf("%q is not valid UTF-8, use the %s encoding", b, ExportEncodingBase64)
+				}
+				return string(b), nil
+			},
+			decode: func(s string) ([]byte, error) { return []byte(s), nil },
+		}, nil
+	case ExportEncodingBase64:
+		return exportEncoding{
+			name:   ExportEncodingBase64,
+			encode: func(b []byte) (string, error) { return base64.StdEncoding.EncodeToString(b), nil },
+			decode: base64.StdEncoding.DecodeString,
+		}, nil
+	}
+	return exportEncoding{}, fmt.Errorf("unknown export encoding %q", name)
+}
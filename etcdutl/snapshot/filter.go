@@ -0,0 +1,113 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// keyFilter selects the part of the key space kept by a restore.
+type keyFilter struct {
+	// includePrefixes, if not empty, restricts the restored keys to the
+	// ones with at least one of the given prefixes.
+	includePrefixes []string
+	// excludePrefixes drops the keys with any of the given prefixes.
+	excludePrefixes []string
+	// revision, if not 0, rolls the key space back to the given revision.
+	revision int64
+}
+
+func (f keyFilter) empty() bool {
+	return len(f.includePrefixes) == 0 && len(f.excludePrefixes) == 0 && f.revision == 0
+}
+
+// keep returns true if the given key should be restored.
+func (f keyFilter) keep(key []byte) bool {
+	k := string(key)
+	for _, p := range f.excludePrefixes {
+		if strings.HasPrefix(k, p) {
+			return false
+		}
+	}
+	if len(f.includePrefixes) == 0 {
+		return true
+	}
+	for _, p := range f.includePrefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyKeyFilter removes from the key space stored in the given backend the
+// revisions after the filter revision and all revisions of the filtered out
+// keys. Leases are left untouched, so a lease may end up without attached keys.
+func applyKeyFilter(lg *zap.Logger, be backend.Backend, f keyFilter) error {
+	if f.empty() {
+		return nil
+	}
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	currentRev := st.Rev()
+	st.Close()
+
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	defer func() {
+		tx.Unlock()
+		be.ForceCommit()
+	}()
+
+	if f.revision != 0 {
+		compactRev, _ := mvcc.UnsafeReadFinishedCompact(tx)
+		if f.revision < compactRev {
+			return fmt.Errorf("revision %d has been compacted, the snapshot only contains revisions since %d", f.revision, compactRev)
+		}
+		if f.revision > currentRev {
+			return fmt.Errorf("revision %d is greater than the snapshot revision %d", f.revision, currentRev)
+		}
+		removed := mvcc.UnsafeDeleteRevisionsAfter(tx, f.revision)
+		if scheduledRev, found := mvcc.UnsafeReadScheduledCompact(tx); found && scheduledRev > f.revision {
+			mvcc.UnsafeSetScheduledCompact(tx, compactRev)
+		}
+		lg.Info(
+			"rolled back key space",
+			zap.Int64("revision", f.revision),
+			zap.Int64("snapshot-revision", currentRev),
+			zap.Int("removed-revisions", removed),
+		)
+	}
+
+	if len(f.includePrefixes) != 0 || len(f.excludePrefixes) != 0 {
+		removed, err := mvcc.UnsafeDeleteKeysIf(tx, func(key []byte) bool { return !f.keep(key) })
+		if err != nil {
+			return err
+		}
+		lg.Info(
+			"filtered key space",
+			zap.Strings("include-prefixes", f.includePrefixes),
+			zap.Strings("exclude-prefixes", f.excludePrefixes),
+			zap.Int("removed-revisions", removed),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/txn"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.etcd.io/raft/v3/raftpb"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Incremental backup file format:
+//
+//	magic (8 bytes) | header length (4 bytes) | JSON encoded IncrementalBackupInfo
+//	entries: length (4 bytes) | protobuf encoded raftpb.Entry
+//	sha256 of all the preceding bytes (32 bytes)
+//
+// Lengths are big-endian.
+const incrementalMagic = "ETCDIBK1"
+
+// IncrementalBackupConfig configures the creation of an incremental backup.
+type IncrementalBackupConfig struct {
+	// WALDir is the WAL directory of the member to back up.
+	WALDir string
+	// SinceIndex is the raft index covered by the previous backup, usually
+	// the consistent index of a full snapshot, see ConsistentIndex, or the
+	// last index of a previous incremental backup.
+	SinceIndex uint64
+	// OutputPath is the path of the incremental backup file to write.
+	// It returns an error if OutputPath already exists.
+	OutputPath string
+}
+
+// IncrementalBackupInfo describes the content of an incremental backup.
+type IncrementalBackupInfo struct {
+	ClusterID  uint64 `json:"cluster_id"`
+	MemberID   uint64 `json:"member_id"`
+	SinceIndex uint64 `json:"since_index"`
+	LastIndex  uint64 `json:"last_index"`
+	LastTerm   uint64 `json:"last_term"`
+	Entries    int    `json:"entries"`
+}
+
+// ConsistentIndex returns the raft index of the last entry applied to the
+// backend of the given snapshot file, which is the index to give as
+// IncrementalBackupConfig.SinceIndex for the first incremental backup
+// following the snapshot.
+func ConsistentIndex(dbPath string) (uint64, error) {
+	db, closeDB, err := backend.OpenBoltReadOnly(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer closeDB()
+	var index uint64
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(schema.Meta.Name())
+		if b == nil {
+			return fmt.Errorf("snapshot file %q has no meta bucket", dbPath)
+		}
+		v := b.Get(schema.MetaConsistentIndexKeyName)
+		if len(v) != 8 {
+			return fmt.Errorf("snapshot file %q has no consistent index", dbPath)
+		}
+		index = binary.BigEndian.Uint64(v)
+		return nil
+	})
+	return index, err
+}
+
+// CreateIncrementalBackup writes the committed WAL entries with an index
+// greater than cfg.SinceIndex to an incremental backup file. The WAL can be
+// in use by a running etcd member.
+func CreateIncrementalBackup(lg *zap.Logger, cfg IncrementalBackupConfig) (IncrementalBackupInfo, error) {
+	info := IncrementalBackupInfo{SinceIndex: cfg.SinceIndex}
+	if fileutil.Exist(cfg.OutputPath) {
+		return info, fmt.Errorf("output path %q already exists", cfg.OutputPath)
+	}
+
+	ents, metadata, state, err := readWALSince(lg, cfg.WALDir, cfg.SinceIndex)
+	if err != nil {
+		return info, err
+	}
+	info.ClusterID, info.MemberID = metadata.ClusterID, metadata.NodeID
+	info.LastIndex, info.LastTerm = cfg.SinceIndex, state.Term
+	var kept []raftpb.Entry
+	for _, e := range ents {
+		if e.Index <= cfg.SinceIndex || e.Index > state.Commit {
+			continue
+		}
+		kept = append(kept, e)
+		info.LastIndex, info.LastTerm = e.Index, e.Term
+	}
+	info.Entries = len(kept)
+
+	if err = writeIncrementalBackup(cfg.OutputPath, info, kept); err != nil {
+		return info, err
+	}
+	lg.Info(
+		"created incremental backup",
+		zap.String("path", cfg.OutputPath),
+		zap.Uint64("since-index", info.SinceIndex),
+		zap.Uint64("last-index", info.LastIndex),
+		zap.Int("entries", info.Entries),
+	)
+	return info, nil
+}
+
+// readWALSince reads the WAL entries from the latest WAL snapshot at or
+// before the given index, so that no entry after index is missed.
+func readWALSince(lg *zap.Logger, walDir string, index uint64) ([]raftpb.Entry, pb.Metadata, raftpb.HardState, error) {
+	var metadata pb.Metadata
+	snaps, err := wal.ValidSnapshotEntries(lg, walDir)
+	if err != nil {
+		return nil, metadata, raftpb.HardState{}, err
+	}
+	var start *walpb.Snapshot
+	for i := range snaps {
+		if snaps[i].Index <= index && (start == nil || snaps[i].Index > start.Index) {
+			start = &snaps[i]
+		}
+	}
+	if start == nil {
+		return nil, metadata, raftpb.HardState{}, fmt.Errorf("WAL %q no longer contains the entries following index %d, take a new full snapshot", walDir, index)
+	}
+
+	w, err := wal.OpenForRead(lg, walDir, *start)
+	if err != nil {
+		return nil, metadata, raftpb.HardState{}, err
+	}
+	defer w.Close()
+	md, state, ents, err := w.ReadAll()
+	if err != nil {
+		return nil, metadata, raftpb.HardState{}, err
+	}
+	pbutil.MustUnmarshal(&metadata, md)
+	if state.Commit < index {
+		return nil, metadata, state, fmt.Errorf("index %d is beyond the WAL commit index %d", index, state.Commit)
+	}
+	return ents, metadata, state, nil
+}
+
+func writeIncrementalBackup(path string, info IncrementalBackupInfo, ents []raftpb.Entry) error {
+	header, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	partPath := path + ".part"
+	f, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	write := func(b []byte) {
+		if err == nil {
+			_, err = w.Write(b)
+		}
+	}
+	writeRecord := func(b []byte) {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+		write(l[:])
+		write(b)
+	}
+	write([]byte(incrementalMagic))
+	writeRecord(header)
+	for i := range ents {
+		writeRecord(pbutil.MustMarshal(&ents[i]))
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		_, err = f.Write(h.Sum(nil))
+	}
+	if err == nil {
+		err = fileutil.Fsync(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(partPath, path)
+	}
+	if err != nil {
+		os.Remove(partPath)
+	}
+	return err
+}
+
+// ReadIncrementalBackup reads and verifies the incremental backup file at
+// the given path.
+func ReadIncrementalBackup(path string) (IncrementalBackupInfo, []raftpb.Entry, error) {
+	var info IncrementalBackupInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, nil, err
+	}
+	if len(data) < len(incrementalMagic)+sha256.Size || string(data[:len(incrementalMagic)]) != incrementalMagic {
+		return info, nil, fmt.Errorf("%q is not an incremental backup file", path)
+	}
+	body, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if h := sha256.Sum256(body); !bytes.Equal(h[:], sum) {
+		return info, nil, fmt.Errorf("incremental backup file %q integrity check failed", path)
+	}
+
+	body = body[len(incrementalMagic):]
+	readRecord := func() ([]byte, error) {
+		if len(body) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		l := binary.BigEndian.Uint32(body)
+		if uint64(len(body)-4) < uint64(l) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		rec := body[4 : 4+l]
+		body = body[4+l:]
+		return rec, nil
+	}
+	header, err := readRecord()
+	if err != nil {
+		return info, nil, fmt.Errorf("incremental backup file %q: %v", path, err)
+	}
+	if err = json.Unmarshal(header, &info); err != nil {
+		return info, nil, fmt.Errorf("incremental backup file %q: %v", path, err)
+	}
+	ents := make([]raftpb.Entry, 0, info.Entries)
+	for len(body) > 0 {
+		rec, err := readRecord()
+		if err != nil {
+			return info, nil, fmt.Errorf("incremental backup file %q: %v", path, err)
+		}
+		var e raftpb.Entry
+		if err = e.Unmarshal(rec); err != nil {
+			return info, nil, fmt.Errorf("incremental backup file %q: %v", path, err)
+		}
+		ents = append(ents, e)
+	}
+	if len(ents) != info.Entries {
+		return info, nil, fmt.Errorf("incremental backup file %q: expected %d entries, got %d", path, info.Entries, len(ents))
+	}
+	return info, ents, nil
+}
+
+// errIncrementalGap is returned when the incremental backups do not cover
+// all the entries following the snapshot.
+var errIncrementalGap = errors.New("incremental backups do not form a continuous chain")
+
+// applyIncrementalBackups applies to the key space stored in the given
+// backend the entries of the incremental backup files, in order, following
+// its consistent index. Only key-value and lease requests are applied;
+// authentication, membership and other cluster-level changes are skipped.
+func applyIncrementalBackups(lg *zap.Logger, be backend.Backend, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	applied, _ := schema.ReadConsistentIndex(be.ReadTx())
+
+	lessor := lease.NewLessor(lg, be, nil, lease.LessorConfig{MinLeaseTTL: 1})
+	defer lessor.Stop()
+	st := mvcc.NewStore(lg, be, lessor, mvcc.StoreConfig{})
+	defer st.Close()
+	lessor.SetRangeDeleter(func() lease.TxnDelete { return st.Write(traceutil.TODO()) })
+
+	var clusterID uint64
+	for _, path := range paths {
+		info, ents, err := ReadIncrementalBackup(path)
+		if err != nil {
+			return err
+		}
+		if clusterID != 0 && info.ClusterID != clusterID {
+			return fmt.Errorf("incremental backup file %q belongs to cluster %x, expected %x", path, info.ClusterID, clusterID)
+		}
+		clusterID = info.ClusterID
+		if info.SinceIndex > applied {
+			return fmt.Errorf("%w: %q starts after index %d, while entries are only applied up to index %d", errIncrementalGap, path, info.SinceIndex, applied)
+		}
+
+		var n, skipped int
+		for _, e := range ents {
+			if e.Index <= applied {
+				continue
+			}
+			if !applyEntry(lg, st, lessor, e) {
+				skipped++
+			}
+			applied = e.Index
+			n++
+		}
+		lg.Info(
+			"applied incremental backup",
+			zap.String("path", path),
+			zap.Int("applied-entries", n),
+			zap.Int("skipped-entries", skipped),
+			zap.Uint64("applied-index", applied),
+		)
+	}
+	be.ForceCommit()
+	return nil
+}
+
+// applyEntry applies a single raft entry to the store and returns false if
+// the entry does not change the key space or the leases and is skipped.
+// Failed requests are not errors: they fail the same way when applied by
+// the server and leave the key space unchanged.
+func applyEntry(lg *zap.Logger, st mvcc.KV, lessor lease.Lessor, e raftpb.Entry) bool {
+	if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+		return false
+	}
+	var rr pb.InternalRaftRequest
+	if !pbutil.MaybeUnmarshal(&rr, e.Data) {
+		return false
+	}
+
+	var err error
+	switch {
+	case rr.Put != nil:
+		_, _, err = txn.Put(context.TODO(), lg, lessor, st, nil, rr.Put)
+	case rr.DeleteRange != nil:
+		_, err = txn.DeleteRange(st, nil, rr.DeleteRange)
+	case rr.Txn != nil:
+		_, _, err = txn.Txn(context.TODO(), lg, rr.Txn, false, st, lessor)
+	case rr.Compaction != nil:
+		var ch <-chan struct{}
+		if ch, err = st.Compact(traceutil.TODO(), rr.Compaction.Revision); err == nil {
+			<-ch
+		}
+	case rr.LeaseGrant != nil:
+		err = grantLease(lessor, rr.LeaseGrant, rr.Header)
+	case rr.LeaseRevoke != nil:
+		err = lessor.Revoke(lease.LeaseID(rr.LeaseRevoke.ID))
+	case rr.LeaseExpire != nil:
+		err = lessor.Expire(lease.LeaseID(rr.LeaseExpire.ID))
+	case rr.LeaseGrantBatch != nil:
+		for _, r := range rr.LeaseGrantBatch.Requests {
+			if gerr := grantLease(lessor, r, rr.Header); gerr != nil {
+				err = gerr
+			}
+		}
+	case rr.LeaseRevokeBatch != nil:
+		for _, r := range rr.LeaseRevokeBatch.Requests {
+			if rerr := lessor.Revoke(lease.LeaseID(r.ID)); rerr != nil {
+				err = rerr
+			}
+		}
+	default:
+		return false
+	}
+	if err != nil {
+		lg.Debug("incremental backup entry failed to apply", zap.Uint64("index", e.Index), zap.Error(err))
+	}
+	return true
+}
+
+// grantLease grants the lease, recording the user of the request header as
+// its grantor like the server does.
+func grantLease(lessor lease.Lessor, r *pb.LeaseGrantRequest, hdr *pb.RequestHeader) error {
+	l, err := lessor.GrantWithLabels(lease.LeaseID(r.ID), lease.LeaseID(r.ParentID), r.TTL, r.Labels)
+	if err != nil || hdr == nil || hdr.Username == "" {
+		return err
+	}
+	return lessor.SetGrantor(l.ID, hdr.Username)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// SlimConfig configures snapshot slim operation.
+type SlimConfig struct {
+	// SnapshotPath is the path of snapshot file to read from.
+	SnapshotPath string
+	// OutputPath is the path of the slimmed snapshot file to write.
+	// It returns an error if OutputPath already exists.
+	OutputPath string
+
+	// SkipHashCheck is "true" to ignore snapshot integrity hash value
+	// (required if copied from data directory).
+	SkipHashCheck bool
+}
+
+// Slim writes a copy of the snapshot that only retains the latest revision
+// of each key. It is equivalent to compacting the snapshot at its current
+// revision followed by a defragmentation. The output file has a sha256
+// integrity hash appended so that it can be restored like a regular snapshot.
+func (s *v3Manager) Slim(cfg SlimConfig) error {
+	if cfg.SnapshotPath == cfg.OutputPath {
+		return fmt.Errorf("output path %q must be different from snapshot path", cfg.OutputPath)
+	}
+	if fileutil.Exist(cfg.OutputPath) {
+		return fmt.Errorf("output path %q already exists", cfg.OutputPath)
+	}
+	if err := copyAndVerifyDB(cfg.SnapshotPath, cfg.OutputPath, cfg.SkipHashCheck); err != nil {
+		os.Remove(cfg.OutputPath)
+		return err
+	}
+
+	be := backend.NewDefaultBackend(s.lg, cfg.OutputPath)
+	rev, err := Compact(s.lg, be, 0)
+	if err == nil {
+		err = be.Defrag()
+	}
+	be.Close()
+	if err != nil {
+		os.Remove(cfg.OutputPath)
+		return err
+	}
+
+	if err = appendChecksum(cfg.OutputPath); err != nil {
+		os.Remove(cfg.OutputPath)
+		return err
+	}
+	s.lg.Info(
+		"slimmed snapshot",
+		zap.String("path", cfg.SnapshotPath),
+		zap.String("output-path", cfg.OutputPath),
+		zap.Int64("revision", rev),
+	)
+	return nil
+}
+
+// Compact compacts the key space stored in the given backend at rev, or at
+// its current revision if rev is 0, and returns the compaction revision. A key
+// space already compacted at its current revision is left as is.
+func Compact(lg *zap.Logger, be backend.Backend, rev int64) (int64, error) {
+	st := mvcc.NewStore(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer st.Close()
+
+	latest := rev == 0
+	if latest {
+		rev = st.Rev()
+	}
+	ch, err := st.Compact(traceutil.TODO(), rev)
+	if err != nil && !(latest && err == mvcc.ErrCompacted) {
+		return 0, err
+	}
+	<-ch
+	be.ForceCommit()
+	return rev, nil
+}
+
+// appendChecksum appends the sha256 hash of the file content to the file,
+// as done by the snapshot RPC.
+func appendChecksum(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return err
+	}
+	if _, err = f.Write(h.Sum(nil)); err != nil {
+		return err
+	}
+	return fileutil.Fsync(f)
+}
@@ -67,6 +67,25 @@ type Manager interface {
 	// file. It returns an error if specified data directory already
 	// exists, to prevent unintended data directory overwrites.
 	Restore(cfg RestoreConfig) error
+
+	// Slim writes a copy of the given snapshot file that only retains
+	// the latest revision of each key. Historical revisions and tombstones
+	// are dropped.
+	Slim(cfg SlimConfig) error
+
+	// Encrypt writes a copy of the given snapshot file encrypted with
+	// AES-256-GCM.
+	Encrypt(cfg EncryptConfig) error
+
+	// Decrypt writes a decrypted copy of the given encrypted snapshot file.
+	Decrypt(cfg EncryptConfig) error
+
+	// Export writes the latest state of the key space, the leases and the
+	// authentication data of the given snapshot file as JSON.
+	Export(cfg ExportConfig) error
+
+	// Import writes a snapshot file from the JSON written by Export.
+	Import(cfg ImportConfig) error
 }
 
 // NewV3 returns a new snapshot Manager for v3.x snapshot.
@@ -84,6 +103,9 @@ type v3Manager struct {
 	cl        *membership.RaftCluster
 
 	skipHashCheck bool
+	filter        keyFilter
+
+	incrementalBackupPaths []string
 }
 
 // hasChecksum returns "true" if the file size "n"
@@ -115,12 +137,19 @@ func (s *v3Manager) Status(dbPath string) (ds Status, err error) {
 	if _, err = os.Stat(dbPath); err != nil {
 		return ds, err
 	}
+	encrypted, err := IsEncrypted(dbPath)
+	if err != nil {
+		return ds, err
+	}
+	if encrypted {
+		return ds, ErrEncrypted
+	}
 
-	db, err := bolt.Open(dbPath, 0400, &bolt.Options{ReadOnly: true})
+	db, closeDB, err := backend.OpenBoltReadOnly(dbPath)
 	if err != nil {
 		return ds, err
 	}
-	defer db.Close()
+	defer closeDB()
 
 	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
 
@@ -203,6 +232,26 @@ type RestoreConfig struct {
 	// SkipHashCheck is "true" to ignore snapshot integrity hash value
 	// (required if copied from data directory).
 	SkipHashCheck bool
+
+	// IncludePrefixes, if not empty, only restores the keys with at least
+	// one of the given prefixes.
+	IncludePrefixes []string
+	// ExcludePrefixes drops all revisions of the keys with any of the given
+	// prefixes. It takes precedence over IncludePrefixes.
+	ExcludePrefixes []string
+	// Revision, if not 0, restores the key space as of the given revision.
+	// It must not be compacted in the snapshot.
+	Revision int64
+
+	// Force is "true" to restore the snapshot even if its backend
+	// integrity check fails.
+	Force bool
+
+	// IncrementalBackupPaths are the incremental backup files, created by
+	// CreateIncrementalBackup, to apply in order on top of the snapshot.
+	// Only their key-value and lease changes are applied. Prefix and
+	// revision filters apply to the resulting key space.
+	IncrementalBackupPaths []string
 }
 
 // Restore restores a new etcd data directory from given snapshot file.
@@ -253,6 +302,12 @@ func (s *v3Manager) Restore(cfg RestoreConfig) error {
 	s.walDir = walDir
 	s.snapDir = filepath.Join(dataDir, "member", "snap")
 	s.skipHashCheck = cfg.SkipHashCheck
+	s.filter = keyFilter{
+		includePrefixes: cfg.IncludePrefixes,
+		excludePrefixes: cfg.ExcludePrefixes,
+		revision:        cfg.Revision,
+	}
+	s.incrementalBackupPaths = cfg.IncrementalBackupPaths
 
 	s.lg.Info(
 		"restoring snapshot",
@@ -262,6 +317,12 @@ func (s *v3Manager) Restore(cfg RestoreConfig) error {
 		zap.String("snap-dir", s.snapDir),
 	)
 
+	if err = backend.CheckFile(s.srcDbPath); err != nil {
+		if !cfg.Force {
+			return err
+		}
+		s.lg.Warn("ignoring failed snapshot integrity check", zap.String("path", s.srcDbPath), zap.Error(err))
+	}
 	if err = s.saveDB(); err != nil {
 		return err
 	}
@@ -308,11 +369,23 @@ func (s *v3Manager) saveDB() error {
 		return err
 	}
 
-	return nil
+	if err = applyIncrementalBackups(s.lg, be, s.incrementalBackupPaths); err != nil {
+		return err
+	}
+	return applyKeyFilter(s.lg, be, s.filter)
 }
 
 func (s *v3Manager) copyAndVerifyDB() error {
-	srcf, ferr := os.Open(s.srcDbPath)
+	if err := fileutil.CreateDirAll(s.lg, s.snapDir); err != nil {
+		return err
+	}
+	return copyAndVerifyDB(s.srcDbPath, s.outDbPath(), s.skipHashCheck)
+}
+
+// copyAndVerifyDB copies the snapshot file at srcPath to dstPath, verifying
+// and truncating away its sha256 integrity hash, if any.
+func copyAndVerifyDB(srcPath, dstPath string, skipHashCheck bool) error {
+	srcf, ferr := os.Open(srcPath)
 	if ferr != nil {
 		return ferr
 	}
@@ -330,13 +403,7 @@ func (s *v3Manager) copyAndVerifyDB() error {
 		return err
 	}
 
-	if err := fileutil.CreateDirAll(s.lg, s.snapDir); err != nil {
-		return err
-	}
-
-	outDbPath := s.outDbPath()
-
-	db, dberr := os.OpenFile(outDbPath, os.O_RDWR|os.O_CREATE, 0600)
+	db, dberr := os.OpenFile(dstPath, os.O_RDWR|os.O_CREATE, 0600)
 	if dberr != nil {
 		return dberr
 	}
@@ -363,11 +430,11 @@ func (s *v3Manager) copyAndVerifyDB() error {
 		}
 	}
 
-	if !hasHash && !s.skipHashCheck {
+	if !hasHash && !skipHashCheck {
 		return fmt.Errorf("snapshot missing hash but --skip-hash-check=false")
 	}
 
-	if hasHash && !s.skipHashCheck {
+	if hasHash && !skipHashCheck {
 		// check for match
 		if _, err := db.Seek(0, io.SeekStart); err != nil {
 			return err
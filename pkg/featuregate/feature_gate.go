@@ -0,0 +1,242 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package featuregate implements the feature gates turning the features on and
+// off by their names, e.g. with --feature-gates=Foo=true,Bar=false, and
+// tracking their maturity.
+package featuregate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Feature string
+
+type prerelease string
+
+const (
+	// Alpha features are disabled by default, and may change or be removed
+	// without notice.
+	Alpha = prerelease("ALPHA")
+	// Beta features are well tested, usually enabled by default.
+	Beta = prerelease("BETA")
+	// GA features are always enabled, and their gates are to be removed.
+	GA = prerelease("")
+	// Deprecated features are to be removed.
+	Deprecated = prerelease("DEPRECATED")
+)
+
+// FeatureSpec is the default value and the maturity of a feature.
+type FeatureSpec struct {
+	// Default is the value of the feature unless it is set.
+	Default bool
+	// LockToDefault prevents the feature from being set to a value other than
+	// Default.
+	LockToDefault bool
+	PreRelease    prerelease
+}
+
+// FeatureGate tells whether the features are enabled.
+type FeatureGate interface {
+	// Enabled returns true if the feature is enabled. It panics for an unknown
+	// feature.
+	Enabled(key Feature) bool
+	// KnownFeatures returns the descriptions of the features which can be set,
+	// e.g. "Foo=true|false (ALPHA - default=false)".
+	KnownFeatures() []string
+	// Values returns whether each known feature is enabled, keyed by name.
+	Values() map[string]bool
+}
+
+// MutableFeatureGate is a FeatureGate whose features can be added and set,
+// e.g. from a flag.
+type MutableFeatureGate interface {
+	FeatureGate
+	flag.Value
+
+	// Add adds the features to the gate. A feature can be added twice with
+	// the same spec only.
+	Add(features map[Feature]FeatureSpec) error
+	// SetFromMap sets the features by name. Either all of them are set, or
+	// an error is returned.
+	SetFromMap(m map[string]bool) error
+	// IsSet returns true if the feature was set rather than left to its
+	// default value.
+	IsSet(key Feature) bool
+	// AddFlag adds the flag setting the features to fs.
+	AddFlag(fs *flag.FlagSet, flagName string)
+}
+
+type featureGate struct {
+	name string
+
+	mu    sync.RWMutex
+	known map[Feature]FeatureSpec
+	// set are the values of the features which were set.
+	set map[Feature]bool
+}
+
+// New returns a feature gate of the component name without features.
+func New(name string) MutableFeatureGate {
+	return &featureGate{
+		name:  name,
+		known: make(map[Feature]FeatureSpec),
+		set:   make(map[Feature]bool),
+	}
+}
+
+func (f *featureGate) Add(features map[Feature]FeatureSpec) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, spec := range features {
+		if existing, ok := f.known[name]; ok {
+			if existing == spec {
+				continue
+			}
+			return fmt.Errorf("feature gate %q with different spec already exists: %v", name, existing)
+		}
+		f.known[name] = spec
+	}
+	return nil
+}
+
+func (f *featureGate) Enabled(key Feature) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if v, ok := f.set[key]; ok {
+		return v
+	}
+	spec, ok := f.known[key]
+	if !ok {
+		panic(fmt.Errorf("feature %q is not registered in feature gate %q", key, f.name))
+	}
+	return spec.Default
+}
+
+func (f *featureGate) IsSet(key Feature) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.set[key]
+	return ok
+}
+
+// Set parses a comma-separated list of key=value pairs, implementing
+// flag.Value.
+func (f *featureGate) Set(value string) error {
+	m := make(map[string]bool)
+	for _, s := range strings.Split(value, ",") {
+		if len(s) == 0 {
+			continue
+		}
+		arr := strings.SplitN(s, "=", 2)
+		k := strings.TrimSpace(arr[0])
+		if len(arr) != 2 {
+			return fmt.Errorf("missing bool value for %s", k)
+		}
+		v := strings.TrimSpace(arr[1])
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s=%s, err: %v", k, v, err)
+		}
+		m[k] = b
+	}
+	return f.SetFromMap(m)
+}
+
+func (f *featureGate) SetFromMap(m map[string]bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	set := make(map[Feature]bool, len(f.set)+len(m))
+	for k, v := range f.set {
+		set[k] = v
+	}
+	for k, v := range m {
+		key := Feature(k)
+		spec, ok := f.known[key]
+		if !ok {
+			return fmt.Errorf("unrecognized feature gate: %s", k)
+		}
+		if spec.LockToDefault && spec.Default != v {
+			return fmt.Errorf("cannot set feature gate %v to %v, feature is locked to %v", k, v, spec.Default)
+		}
+		set[key] = v
+	}
+	f.set = set
+	return nil
+}
+
+// String returns the features which were set as a comma-separated list of
+// key=value pairs, implementing flag.Value.
+func (f *featureGate) String() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	pairs := make([]string, 0, len(f.set))
+	for k, v := range f.set {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *featureGate) KnownFeatures() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var known []string
+	for k, v := range f.known {
+		if v.PreRelease == GA || v.PreRelease == Deprecated {
+			continue
+		}
+		known = append(known, fmt.Sprintf("%s=true|false (%s - default=%t)", k, v.PreRelease, v.Default))
+	}
+	sort.Strings(known)
+	return known
+}
+
+func (f *featureGate) Values() map[string]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	values := make(map[string]bool, len(f.known))
+	for k, spec := range f.known {
+		values[string(k)] = spec.Default
+		if v, ok := f.set[k]; ok {
+			values[string(k)] = v
+		}
+	}
+	return values
+}
+
+func (f *featureGate) AddFlag(fs *flag.FlagSet, flagName string) {
+	fs.Var(f, flagName, "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(f.KnownFeatures(), "\n"))
+}
+
+// UnmarshalJSON sets the features from a string of comma-separated key=value
+// pairs, e.g. in the config file.
+func (f *featureGate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return f.Set(s)
+}
+
+func (f *featureGate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregate
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAlpha  Feature = "TestAlpha"
+	testBeta   Feature = "TestBeta"
+	testGA     Feature = "TestGA"
+	testLocked Feature = "TestLocked"
+)
+
+func newTestFeatureGate(t *testing.T) MutableFeatureGate {
+	f := New("test")
+	require.NoError(t, f.Add(map[Feature]FeatureSpec{
+		testAlpha:  {Default: false, PreRelease: Alpha},
+		testBeta:   {Default: true, PreRelease: Beta},
+		testGA:     {Default: true, PreRelease: GA},
+		testLocked: {Default: true, LockToDefault: true, PreRelease: GA},
+	}))
+	return f
+}
+
+func TestFeatureGateSet(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    map[Feature]bool
+		wantErr bool
+	}{
+		{
+			arg:  "",
+			want: map[Feature]bool{testAlpha: false, testBeta: true},
+		},
+		{
+			arg:  "TestAlpha=true,TestBeta=false",
+			want: map[Feature]bool{testAlpha: true, testBeta: false},
+		},
+		{
+			arg:  " TestAlpha = true , TestLocked=true",
+			want: map[Feature]bool{testAlpha: true, testBeta: true, testLocked: true},
+		},
+		{arg: "TestAlpha", wantErr: true},
+		{arg: "TestAlpha=maybe", wantErr: true},
+		{arg: "Unknown=true", wantErr: true},
+		{arg: "TestLocked=false", wantErr: true},
+		// nothing is set on an error
+		{arg: "TestAlpha=true,Unknown=true", want: map[Feature]bool{testAlpha: false}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			f := newTestFeatureGate(t)
+			err := f.Set(tt.arg)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			for k, v := range tt.want {
+				assert.Equal(t, v, f.Enabled(k), k)
+			}
+		})
+	}
+}
+
+func TestFeatureGateIsSet(t *testing.T) {
+	f := newTestFeatureGate(t)
+	require.NoError(t, f.Set("TestBeta=true"))
+	assert.True(t, f.IsSet(testBeta))
+	assert.False(t, f.IsSet(testAlpha))
+	assert.Equal(t, "TestBeta=true", f.String())
+}
+
+func TestFeatureGateEnabledUnknown(t *testing.T) {
+	f := newTestFeatureGate(t)
+	assert.Panics(t, func() { f.Enabled("Unknown") })
+}
+
+func TestFeatureGateAdd(t *testing.T) {
+	f := newTestFeatureGate(t)
+	require.NoError(t, f.Add(map[Feature]FeatureSpec{testAlpha: {Default: false, PreRelease: Alpha}}))
+	require.Error(t, f.Add(map[Feature]FeatureSpec{testAlpha: {Default: true, PreRelease: Beta}}))
+}
+
+func TestFeatureGateKnownFeatures(t *testing.T) {
+	f := newTestFeatureGate(t)
+	assert.Equal(t, []string{
+		"TestAlpha=true|false (ALPHA - default=false)",
+		"TestBeta=true|false (BETA - default=true)",
+	}, f.KnownFeatures())
+}
+
+func TestFeatureGateValues(t *testing.T) {
+	f := newTestFeatureGate(t)
+	require.NoError(t, f.Set("TestAlpha=true,TestBeta=false"))
+	assert.Equal(t, map[string]bool{
+		"TestAlpha":  true,
+		"TestBeta":   false,
+		"TestGA":     true,
+		"TestLocked": true,
+	}, f.Values())
+}
+
+func TestFeatureGateFlag(t *testing.T) {
+	f := newTestFeatureGate(t)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.AddFlag(fs, "feature-gates")
+	require.NoError(t, fs.Parse([]string{"--feature-gates=TestAlpha=true"}))
+	assert.True(t, f.Enabled(testAlpha))
+}
+
+func TestFeatureGateJSON(t *testing.T) {
+	var cfg struct {
+		FeatureGates FeatureGate `json:"feature-gates"`
+	}
+	cfg.FeatureGates = newTestFeatureGate(t)
+	require.NoError(t, json.Unmarshal([]byte(`{"feature-gates":"TestAlpha=true,TestBeta=false"}`), &cfg))
+	assert.True(t, cfg.FeatureGates.Enabled(testAlpha))
+	assert.False(t, cfg.FeatureGates.Enabled(testBeta))
+
+	b, err := json.Marshal(&cfg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"feature-gates":"TestAlpha=true,TestBeta=false"}`, string(b))
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2023, time.March, 15, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2023, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{"30 * * * *", time.Date(2023, time.March, 15, 11, 30, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2023, time.March, 16, 2, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 3 * * 1-5", time.Date(2023, time.March, 16, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1,20 * *", time.Date(2023, time.March, 20, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 0 1 * 5", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.spec, err)
+		}
+		if got := c.Next(now); !got.Equal(tt.want) {
+			t.Errorf("%q: next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	tests := []struct {
+		spec string
+		t    time.Time
+		want bool
+	}{
+		{"* 1-4 * * *", time.Date(2023, time.March, 15, 1, 0, 0, 0, time.UTC), true},
+		{"* 1-4 * * *", time.Date(2023, time.March, 15, 4, 59, 59, 0, time.UTC), true},
+		{"* 1-4 * * *", time.Date(2023, time.March, 15, 5, 0, 0, 0, time.UTC), false},
+		{"* 1-4 * * *", time.Date(2023, time.March, 15, 0, 59, 0, 0, time.UTC), false},
+		{"*/10 * * * 0,6", time.Date(2023, time.March, 18, 12, 20, 30, 0, time.UTC), true},
+		{"*/10 * * * 0,6", time.Date(2023, time.March, 18, 12, 21, 0, 0, time.UTC), false},
+		{"*/10 * * * 0,6", time.Date(2023, time.March, 15, 12, 20, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.spec, err)
+		}
+		if got := c.Match(tt.t); got != tt.want {
+			t.Errorf("%q: match(%v) = %v, want %v", tt.spec, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronError(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
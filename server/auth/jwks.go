@@ -0,0 +1,256 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// jwksMinFetchInterval rate limits fetching the key set again for the
+	// tokens signed with unknown key IDs.
+	jwksMinFetchInterval = 10 * time.Second
+	// jwksFetchTimeout is the timeout of fetching the key set.
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMaxSize is the maximum size of the key set document.
+	jwksMaxSize = 1 << 20
+)
+
+// jwks caches the public keys of a JSON Web Key Set (RFC 7517) published by
+// an identity provider, by key ID.
+type jwks struct {
+	lg              *zap.Logger
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	// fetches shares a fetch of the key set between the concurrent callers.
+	fetches singleflight.Group
+
+	mu sync.Mutex
+	// keys are the public keys of the key set, by key ID.
+	keys map[string]interface{}
+	// fetched is the time the key set was last fetched, successfully or not.
+	fetched time.Time
+}
+
+func newJWKS(lg *zap.Logger, url string, refreshInterval time.Duration) *jwks {
+	return &jwks{
+		lg:              lg,
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// key returns the public key with the given key ID. The key set is fetched
+// again once it is older than the refresh interval, or when it does not hold
+// the key, at most every jwksMinFetchInterval. The cached keys keep being used
+// while the key set cannot be fetched.
+func (j *jwks) key(kid string) (interface{}, error) {
+	j.mu.Lock()
+	k, ok := j.keys[kid]
+	since := time.Since(j.fetched)
+	j.mu.Unlock()
+
+	if ok && since < j.refreshInterval {
+		return k, nil
+	}
+	if ok || since >= jwksMinFetchInterval {
+		v, _, _ := j.fetches.Do("", j.refresh)
+		if nk, nok := v.(map[string]interface{})[kid]; nok {
+			k, ok = nk, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return k, nil
+}
+
+// refresh fetches the key set, unless it was fetched less than
+// jwksMinFetchInterval ago, and returns the cached keys. The lock is not held
+// while fetching, the callers needing the keys meanwhile joining the fetch.
+func (j *jwks) refresh() (interface{}, error) {
+	j.mu.Lock()
+	if time.Since(j.fetched) < jwksMinFetchInterval {
+		defer j.mu.Unlock()
+		return j.keys, nil
+	}
+	j.mu.Unlock()
+
+	keys, err := j.fetch()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.fetched = time.Now()
+	if err != nil {
+		j.lg.Warn("failed to fetch JWKS", zap.String("url", j.url), zap.Error(err))
+		return j.keys, nil
+	}
+	j.keys = keys
+	return keys, nil
+}
+
+// idpTokenRevisions binds the tokens minted by an identity provider, which
+// carry no auth revision, to the auth revision the member first verified
+// them at. The updates of the auth store then invalidate them like the tokens
+// assigned by etcd, the clients getting new tokens from the identity provider.
+type idpTokenRevisions struct {
+	mu     sync.Mutex
+	tokens map[[sha256.Size]byte]idpTokenRevision
+	// pruned is the time the expired tokens were last pruned.
+	pruned time.Time
+}
+
+type idpTokenRevision struct {
+	revision uint64
+	expires  time.Time
+}
+
+// revision returns the auth revision the token expiring at expires was first
+// verified at, binding it to rev if it is new.
+func (r *idpTokenRevisions) revision(token string, rev uint64, expires time.Time) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := sha256.Sum256([]byte(token))
+	if tr, ok := r.tokens[h]; ok {
+		return tr.revision
+	}
+	now := time.Now()
+	if r.tokens == nil {
+		r.tokens = make(map[[sha256.Size]byte]idpTokenRevision)
+	}
+	if now.Sub(r.pruned) >= jwksMinFetchInterval {
+		r.pruned = now
+		for th, tr := range r.tokens {
+			if now.After(tr.expires) {
+				delete(r.tokens, th)
+			}
+		}
+	}
+	r.tokens[h] = idpTokenRevision{revision: rev, expires: expires}
+	return rev
+}
+
+// jsonWebKey is a public RSA or EC JSON Web Key.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j *jwks) fetch() (map[string]interface{}, error) {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err = json.NewDecoder(http.MaxBytesReader(nil, resp.Body, jwksMaxSize)).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		k, err := jwk.publicKey()
+		if err != nil {
+			j.lg.Warn("ignored invalid JWK", zap.String("url", j.url), zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = k
+	}
+	return keys, nil
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
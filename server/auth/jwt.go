@@ -19,10 +19,14 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"errors"
+	"strconv"
+	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 )
 
 type tokenJWT struct {
@@ -31,6 +35,38 @@ type tokenJWT struct {
 	key        interface{}
 	ttl        time.Duration
 	verifyOnly bool
+
+	// jwks verifies the tokens carrying a key ID, if set.
+	jwks          *jwks
+	audience      string
+	issuer        string
+	usernameClaim string
+
+	// issuesMu protects issues, revoked and userRevoked
+	issuesMu sync.RWMutex
+	// issues are the issues of the unexpired tokens assigned by the member,
+	// by ID, with their expiry time
+	issues map[string]jwtIssue
+	// revoked are the expiry times of the revoked tokens, by ID
+	revoked map[string]time.Time
+	// userRevoked are the revocations of all the tokens of the users
+	userRevoked map[string]jwtUserRevocation
+
+	// idpRevisions are the auth revisions of the tokens minted by an
+	// identity provider.
+	idpRevisions idpTokenRevisions
+}
+
+type jwtIssue struct {
+	tokenIssue
+	expires time.Time
+}
+
+// jwtUserRevocation revokes the tokens of a user issued up to an index,
+// including the tokens not tracked by the member, until they expire.
+type jwtUserRevocation struct {
+	index   uint64
+	expires time.Time
 }
 
 func (t *tokenJWT) enable()                         {}
@@ -39,17 +75,26 @@ func (t *tokenJWT) invalidateUser(string)           {}
 func (t *tokenJWT) genTokenPrefix() (string, error) { return "", nil }
 
 func (t *tokenJWT) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
-	// rev isn't used in JWT, it is only used in simple token
+	// rev is only used for the tokens minted by an identity provider
 	var (
 		username string
 		revision uint64
+		idp      bool
 	)
 
 	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		if token.Method.Alg() != t.signMethod.Alg() {
+		if t.signMethod != nil && token.Method.Alg() != t.signMethod.Alg() {
 			return nil, errors.New("invalid signing method")
 		}
+		if kid, ok := token.Header["kid"].(string); ok && t.jwks != nil {
+			idp = true
+			// the signing method of the token must match the type of the key,
+			// which the verification checks
+			return t.jwks.key(kid)
+		}
 		switch k := t.key.(type) {
+		case nil:
+			return nil, errors.New("missing key ID")
 		case *rsa.PrivateKey:
 			return &k.PublicKey, nil
 		case *ecdsa.PrivateKey:
@@ -73,8 +118,37 @@ func (t *tokenJWT) info(ctx context.Context, token string, rev uint64) (*AuthInf
 		return nil, false
 	}
 
-	username = claims["username"].(string)
-	revision = uint64(claims["revision"].(float64))
+	// the tokens must expire, the verification only checks the expiry time
+	// if set
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		t.lg.Warn("missing expiry time of a JWT token")
+		return nil, false
+	}
+	if t.audience != "" && !claims.VerifyAudience(t.audience, true) {
+		t.lg.Warn("invalid audience of a JWT token", zap.Any("aud", claims["aud"]))
+		return nil, false
+	}
+	if t.issuer != "" && !claims.VerifyIssuer(t.issuer, true) {
+		t.lg.Warn("invalid issuer of a JWT token", zap.Any("iss", claims["iss"]))
+		return nil, false
+	}
+
+	username, ok = claims[t.usernameClaim].(string)
+	if !ok || username == "" {
+		t.lg.Warn("failed to obtain user name from a JWT token", zap.String("claim", t.usernameClaim))
+		return nil, false
+	}
+	if id, ok := claims["jti"].(string); ok && t.isRevoked(id, username) {
+		t.lg.Warn("revoked JWT token", zap.String("user-name", username), zap.String("token-id", id))
+		return nil, false
+	}
+	if r, ok := claims["revision"].(float64); ok && !idp {
+		revision = uint64(r)
+	} else {
+		// the tokens of an identity provider carry no auth revision
+		revision = t.idpRevisions.revision(token, rev, time.Unix(int64(exp), 0))
+	}
 
 	return &AuthInfo{Username: username, Revision: revision}, true
 }
@@ -86,12 +160,23 @@ func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64)
 
 	// Future work: let a jwt token include permission information would be useful for
 	// permission checking in proxy side.
-	tk := jwt.NewWithClaims(t.signMethod,
-		jwt.MapClaims{
-			"username": username,
-			"revision": revision,
-			"exp":      time.Now().Add(t.ttl).Unix(),
-		})
+	ti := newTokenIssue(ctx, username)
+	expires := ti.issued.Add(t.ttl)
+	claims := jwt.MapClaims{
+		t.usernameClaim: username,
+		"revision":      revision,
+		"exp":           expires.Unix(),
+	}
+	if ti.id != "" {
+		claims["jti"] = ti.id
+	}
+	if t.audience != "" {
+		claims["aud"] = t.audience
+	}
+	if t.issuer != "" {
+		claims["iss"] = t.issuer
+	}
+	tk := jwt.NewWithClaims(t.signMethod, claims)
 
 	token, err := tk.SignedString(t.key)
 	if err != nil {
@@ -104,6 +189,10 @@ func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64)
 		return "", err
 	}
 
+	if ti.id != "" {
+		t.track(ti, expires)
+	}
+
 	t.lg.Debug(
 		"created/assigned a new JWT token",
 		zap.String("user-name", username),
@@ -113,6 +202,144 @@ func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64)
 	return token, err
 }
 
+// track tracks the issue of a token, pruning the expired issues and
+// revocations.
+func (t *tokenJWT) track(ti tokenIssue, expires time.Time) {
+	t.issuesMu.Lock()
+	defer t.issuesMu.Unlock()
+	now := time.Now()
+	for id, is := range t.issues {
+		if now.After(is.expires) {
+			delete(t.issues, id)
+		}
+	}
+	for id, exp := range t.revoked {
+		if now.After(exp) {
+			delete(t.revoked, id)
+		}
+	}
+	for user, ur := range t.userRevoked {
+		if now.After(ur.expires) {
+			delete(t.userRevoked, user)
+		}
+	}
+	t.issues[ti.id] = jwtIssue{tokenIssue: ti, expires: expires}
+}
+
+func (t *tokenJWT) isRevoked(id, username string) bool {
+	t.issuesMu.RLock()
+	defer t.issuesMu.RUnlock()
+	if len(t.revoked) == 0 && len(t.userRevoked) == 0 {
+		return false
+	}
+	if _, ok := t.revoked[id]; ok {
+		return true
+	}
+	ur, ok := t.userRevoked[username]
+	if !ok {
+		return false
+	}
+	index, err := strconv.ParseUint(id, 10, 64)
+	return err == nil && index <= ur.index
+}
+
+func (t *tokenJWT) list(username string) []*pb.AuthToken {
+	t.issuesMu.RLock()
+	defer t.issuesMu.RUnlock()
+	now := time.Now()
+	var tokens []*pb.AuthToken
+	for id, is := range t.issues {
+		if now.After(is.expires) || (username != "" && is.username != username) {
+			continue
+		}
+		if _, ok := t.revoked[id]; ok {
+			continue
+		}
+		if ur, ok := t.userRevoked[is.username]; ok {
+			if index, _ := strconv.ParseUint(id, 10, 64); index <= ur.index {
+				continue
+			}
+		}
+		tokens = append(tokens, is.token(is.expires))
+	}
+	return tokens
+}
+
+// revoke revokes the tokens until they expire. The tokens issued before the
+// member started are not tracked, a token of the ID being revoked for the TTL
+// of the tokens, and all the tokens of the user up to the index of the
+// revocation.
+func (t *tokenJWT) revoke(ctx context.Context, id, username string) int {
+	t.issuesMu.Lock()
+	defer t.issuesMu.Unlock()
+	now := time.Now()
+	revoked := 0
+	if id != "" {
+		expires := now.Add(t.ttl)
+		if is, ok := t.issues[id]; ok && now.Before(is.expires) {
+			if _, ok := t.revoked[id]; !ok {
+				revoked++
+			}
+			expires = is.expires
+		}
+		t.revoked[id] = expires
+		return revoked
+	}
+
+	index, _ := ctx.Value(AuthenticateParamIndex{}).(uint64)
+	for id, is := range t.issues {
+		if is.username != username || now.After(is.expires) {
+			continue
+		}
+		if _, ok := t.revoked[id]; !ok {
+			revoked++
+		}
+		t.revoked[id] = is.expires
+	}
+	t.userRevoked[username] = jwtUserRevocation{index: index, expires: now.Add(t.ttl)}
+	return revoked
+}
+
+// revocations returns the unexpired revocations of the tokens.
+func (t *tokenJWT) revocations() []TokenRevocation {
+	t.issuesMu.RLock()
+	defer t.issuesMu.RUnlock()
+	now := time.Now()
+	var rs []TokenRevocation
+	for id, exp := range t.revoked {
+		if now.Before(exp) {
+			rs = append(rs, TokenRevocation{ID: id, Expires: exp})
+		}
+	}
+	for user, ur := range t.userRevoked {
+		if now.Before(ur.expires) {
+			rs = append(rs, TokenRevocation{User: user, Index: ur.index, Expires: ur.expires})
+		}
+	}
+	return rs
+}
+
+// recoverRevocations restores the revocations persisted by the member, keeping
+// the latest expiry time of the revocations it already has.
+func (t *tokenJWT) recoverRevocations(rs []TokenRevocation) {
+	t.issuesMu.Lock()
+	defer t.issuesMu.Unlock()
+	for _, rv := range rs {
+		if rv.ID != "" {
+			if exp, ok := t.revoked[rv.ID]; !ok || exp.Before(rv.Expires) {
+				t.revoked[rv.ID] = rv.Expires
+			}
+			continue
+		}
+		if ur, ok := t.userRevoked[rv.User]; !ok || ur.index < rv.Index || ur.expires.Before(rv.Expires) {
+			if ok && ur.index > rv.Index {
+				rv.Index = ur.index
+			}
+			t.userRevoked[rv.User] = jwtUserRevocation{index: rv.Index, expires: rv.Expires}
+		}
+	}
+}
+
 func newTokenProviderJWT(lg *zap.Logger, optMap map[string]string) (*tokenJWT, error) {
 	if lg == nil {
 		lg = zap.NewNop()
@@ -135,16 +362,28 @@ func newTokenProviderJWT(lg *zap.Logger, optMap map[string]string) (*tokenJWT, e
 		lg.Warn("unknown JWT options", zap.Strings("keys", keys))
 	}
 
-	key, err := opts.Key()
-	if err != nil {
-		return nil, err
+	var key interface{}
+	if opts.SignMethod != nil {
+		key, err = opts.Key()
+		if err != nil && (opts.JWKSURL == "" || !errors.Is(err, ErrMissingKey)) {
+			return nil, err
+		}
 	}
 
 	t := &tokenJWT{
-		lg:         lg,
-		ttl:        opts.TTL,
-		signMethod: opts.SignMethod,
-		key:        key,
+		lg:            lg,
+		ttl:           opts.TTL,
+		signMethod:    opts.SignMethod,
+		key:           key,
+		audience:      opts.Audience,
+		issuer:        opts.Issuer,
+		usernameClaim: opts.UsernameClaim,
+		issues:        make(map[string]jwtIssue),
+		revoked:       make(map[string]time.Time),
+		userRevoked:   make(map[string]jwtUserRevocation),
+	}
+	if opts.JWKSURL != "" {
+		t.jwks = newJWKS(lg, opts.JWKSURL, opts.JWKSRefreshInterval)
 	}
 
 	switch t.signMethod.(type) {
@@ -157,6 +396,10 @@ func newTokenProviderJWT(lg *zap.Logger, optMap map[string]string) (*tokenJWT, e
 			t.verifyOnly = true
 		}
 	}
+	if t.key == nil {
+		// the tokens are only verified with the keys of the JWKS
+		t.verifyOnly = true
+	}
 
 	return t, nil
 }
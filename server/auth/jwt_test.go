@@ -16,9 +16,18 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	jwtv4 "github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 )
 
@@ -184,6 +193,17 @@ func TestJWTBad(t *testing.T) {
 			"pub-key":     jwtECPubKey,
 			"priv-key":    jwtECPubKey,
 		},
+		"jwks invalid url": {
+			"jwks-url": "idp/keys",
+		},
+		"jwks invalid refresh interval": {
+			"jwks-url":              "https://idp/keys",
+			"jwks-refresh-interval": "hourly",
+		},
+		"jwks invalid method": {
+			"jwks-url":    "https://idp/keys",
+			"sign-method": "invalid",
+		},
 	}
 
 	lg := zap.NewNop()
@@ -202,3 +222,196 @@ func TestJWTBad(t *testing.T) {
 func testJWTOpts() string {
 	return fmt.Sprintf("%s,pub-key=%s,priv-key=%s,sign-method=RS256", tokenTypeJWT, jwtRSAPubKey, jwtRSAPrivKey)
 }
+
+func TestJWTJWKS(t *testing.T) {
+	pem, err := os.ReadFile(jwtRSAPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := jwtv4.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":%q,"e":%q},{"kty":"oct","kid":"k2"}]}`,
+			base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()))
+	}))
+	defer srv.Close()
+
+	lg := zap.NewNop()
+	verify, err := newTokenProviderJWT(lg, map[string]string{
+		"jwks-url":       srv.URL,
+		"aud":            "etcd",
+		"iss":            "https://idp",
+		"username-claim": "sub",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.TODO()
+	if _, aerr := verify.assign(ctx, "abc", 123); aerr != ErrVerifyOnly {
+		t.Fatalf("unexpected error when attempting to sign without key: %v", aerr)
+	}
+
+	sign := func(kid string, claims jwtv4.MapClaims) string {
+		tk := jwtv4.NewWithClaims(jwtv4.SigningMethodRS256, claims)
+		if kid != "" {
+			tk.Header["kid"] = kid
+		}
+		token, serr := tk.SignedString(priv)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		return token
+	}
+	claims := func(aud, iss string) jwtv4.MapClaims {
+		return jwtv4.MapClaims{"sub": "abc", "aud": aud, "iss": iss, "exp": time.Now().Add(time.Hour).Unix()}
+	}
+
+	ai, ok := verify.info(ctx, sign("k1", claims("etcd", "https://idp")), 7)
+	if !ok {
+		t.Fatal("failed to authenticate with token of identity provider")
+	}
+	if ai.Username != "abc" || ai.Revision != 7 {
+		t.Fatalf("unexpected auth info %+v", ai)
+	}
+	// the token stays bound to the auth revision it was first verified at,
+	// whatever revision it claims
+	ai, ok = verify.info(ctx, sign("k1", claims("etcd", "https://idp")), 8)
+	if !ok || ai.Revision != 7 {
+		t.Fatalf("expected the token to keep revision 7, got %+v", ai)
+	}
+	forged := claims("etcd", "https://idp")
+	forged["revision"] = 100
+	ai, ok = verify.info(ctx, sign("k1", forged), 8)
+	if !ok || ai.Revision != 8 {
+		t.Fatalf("expected the token claiming a revision to be bound to revision 8, got %+v", ai)
+	}
+	noExpiry := claims("etcd", "https://idp")
+	delete(noExpiry, "exp")
+	for name, token := range map[string]string{
+		"no expiry":      sign("k1", noExpiry),
+		"wrong audience": sign("k1", claims("other", "https://idp")),
+		"wrong issuer":   sign("k1", claims("etcd", "https://other")),
+		"unknown key":    sign("k3", claims("etcd", "https://idp")),
+		"unsupported":    sign("k2", claims("etcd", "https://idp")),
+		"no key ID":      sign("", claims("etcd", "https://idp")),
+	} {
+		if ai, ok := verify.info(ctx, token, 7); ok {
+			t.Errorf("%s: expected to fail to authenticate, got %+v", name, ai)
+		}
+	}
+	// the key set is fetched once, then again for the unknown key ID after
+	// the rate limit only
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected JWKS to be fetched once, got %d", n)
+	}
+}
+
+// TestJWKSConcurrentFetch tests that the concurrent callers share a fetch of
+// the key set, and that the fetch does not block the callers served from the
+// cache.
+func TestJWKSConcurrentFetch(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		fmt.Fprint(w, `{"keys":[{"kty":"EC","kid":"k1","crv":"P-256","x":"AQ","y":"Ag"}]}`)
+	}))
+	defer srv.Close()
+
+	j := newJWKS(zap.NewNop(), srv.URL, time.Hour)
+	j.keys = map[string]interface{}{"k0": "cached"}
+	// k0 is fresh, k1 unknown since longer than the rate limit
+	j.fetched = time.Now().Add(-2 * jwksMinFetchInterval)
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := j.key("k1")
+			errc <- err
+		}()
+	}
+	// wait for the fetch to start
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if k, err := j.key("k0"); err != nil || k != "cached" {
+		t.Fatalf("expected the cached key during the fetch, got %v, %v", k, err)
+	}
+	close(release)
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected JWKS to be fetched once, got %d", n)
+	}
+}
+
+func TestJWTListRevoke(t *testing.T) {
+	jwt, err := newTokenProviderJWT(zap.NewNop(), map[string]string{
+		"priv-key":    jwtRSAPrivKey,
+		"sign-method": "RS256",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assign := func(index uint64, username string) string {
+		ctx := context.WithValue(context.TODO(), AuthenticateParamIndex{}, index)
+		token, aerr := jwt.assign(ctx, username, 1)
+		if aerr != nil {
+			t.Fatal(aerr)
+		}
+		return token
+	}
+	valid := func(token string) bool {
+		_, ok := jwt.info(context.TODO(), token, 1)
+		return ok
+	}
+	token1 := assign(1, "user1")
+	token2 := assign(2, "user2")
+
+	tokens := jwt.list("user1")
+	if len(tokens) != 1 || tokens[0].Id != "1" || tokens[0].User != "user1" || tokens[0].TTL <= 0 {
+		t.Fatalf("unexpected tokens of user1 %v", tokens)
+	}
+
+	if n := jwt.revoke(context.TODO(), "1", ""); n != 1 {
+		t.Fatalf("expected 1 token revoked, got %d", n)
+	}
+	if valid(token1) {
+		t.Errorf("expected revoked token to be invalid")
+	}
+	if !valid(token2) {
+		t.Errorf("expected token of other user to remain valid")
+	}
+
+	// the revocation of the user covers the tokens issued up to its index,
+	// even those not tracked by the member
+	untracked := assign(3, "user2")
+	delete(jwt.issues, "3")
+	ctx := context.WithValue(context.TODO(), AuthenticateParamIndex{}, uint64(4))
+	if n := jwt.revoke(ctx, "", "user2"); n != 1 {
+		t.Fatalf("expected 1 tracked token revoked, got %d", n)
+	}
+	if valid(token2) || valid(untracked) {
+		t.Errorf("expected revoked tokens of user2 to be invalid")
+	}
+	if token5 := assign(5, "user2"); !valid(token5) {
+		t.Errorf("expected token issued after the revocation to be valid")
+	}
+	if tokens := jwt.list(""); len(tokens) != 1 || tokens[0].Id != "5" {
+		t.Fatalf("expected only the token issued after the revocation, got %v", tokens)
+	}
+}
@@ -16,14 +16,20 @@ package auth
 
 import (
 	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 )
 
 type tokenNop struct{}
 
-func (t *tokenNop) enable()                         {}
-func (t *tokenNop) disable()                        {}
-func (t *tokenNop) invalidateUser(string)           {}
-func (t *tokenNop) genTokenPrefix() (string, error) { return "", nil }
+func (t *tokenNop) enable()                                    {}
+func (t *tokenNop) disable()                                   {}
+func (t *tokenNop) invalidateUser(string)                      {}
+func (t *tokenNop) genTokenPrefix() (string, error)            { return "", nil }
+func (t *tokenNop) list(string) []*pb.AuthToken                { return nil }
+func (t *tokenNop) revoke(context.Context, string, string) int { return 0 }
+func (t *tokenNop) revocations() []TokenRevocation             { return nil }
+func (t *tokenNop) recoverRevocations([]TokenRevocation)       {}
 func (t *tokenNop) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
 	return nil, false
 }
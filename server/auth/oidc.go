@@ -0,0 +1,349 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+const (
+	optOIDCIssuer         = "issuer"
+	optOIDCClientID       = "client-id"
+	optOIDCUsernamePrefix = "username-prefix"
+	optOIDCGroupsClaim    = "groups-claim"
+	optOIDCGroupRoles     = "group-roles"
+
+	// DefaultOIDCUsernameClaim will be used when a 'username-claim' of an oidc token is not specified
+	DefaultOIDCUsernameClaim = "sub"
+	// DefaultOIDCGroupsClaim will be used when a 'groups-claim' is not specified
+	DefaultOIDCGroupsClaim = "groups"
+)
+
+var knownOIDCOptions = map[string]bool{
+	optOIDCIssuer:          true,
+	optOIDCClientID:        true,
+	optJWKSURL:             true,
+	optJWKSRefreshInterval: true,
+	optUsernameClaim:       true,
+	optOIDCUsernamePrefix:  true,
+	optOIDCGroupsClaim:     true,
+	optOIDCGroupRoles:      true,
+}
+
+// noOIDCUsernamePrefix disables prefixing the user names mapped from the
+// claims.
+const noOIDCUsernamePrefix = "-"
+
+type oidcOptions struct {
+	// Issuer is the URL of the identity provider, the required "iss" claim of
+	// the ID tokens, whose discovery document gives the JWKS URL.
+	Issuer string
+	// ClientID is the required "aud" claim of the ID tokens.
+	ClientID string
+	// JWKSURL skips the discovery of the JWKS URL of the issuer, if set.
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	// UsernameClaim is the claim of the ID tokens holding the name of the
+	// etcd user, prefixed with UsernamePrefix, which defaults to the issuer
+	// followed by '#' so that the users of the issuer cannot take the name of
+	// the other users.
+	UsernameClaim  string
+	UsernamePrefix string
+	// GroupsClaim is the claim of the ID tokens holding the groups of the user.
+	GroupsClaim string
+	// GroupRoles maps the groups of the users to the roles granted to them,
+	// in addition to the roles of the etcd user if it exists.
+	GroupRoles map[string][]string
+}
+
+// Parse will load options from the specified map
+func (opts *oidcOptions) Parse(optMap map[string]string) error {
+	opts.Issuer = optMap[optOIDCIssuer]
+	if opts.Issuer == "" {
+		return errors.New("missing issuer")
+	}
+	if _, err := url.ParseRequestURI(opts.Issuer); err != nil {
+		return err
+	}
+	opts.ClientID = optMap[optOIDCClientID]
+	if opts.ClientID == "" {
+		return errors.New("missing client-id")
+	}
+	if u := optMap[optJWKSURL]; u != "" {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return err
+		}
+		opts.JWKSURL = u
+	}
+
+	opts.JWKSRefreshInterval = DefaultJWKSRefreshInterval
+	if ri := optMap[optJWKSRefreshInterval]; ri != "" {
+		var err error
+		if opts.JWKSRefreshInterval, err = time.ParseDuration(ri); err != nil {
+			return err
+		}
+	}
+	opts.UsernameClaim = DefaultOIDCUsernameClaim
+	if claim := optMap[optUsernameClaim]; claim != "" {
+		opts.UsernameClaim = claim
+	}
+	switch prefix := optMap[optOIDCUsernamePrefix]; prefix {
+	case "":
+		opts.UsernamePrefix = opts.Issuer + "#"
+	case noOIDCUsernamePrefix:
+		opts.UsernamePrefix = ""
+	default:
+		opts.UsernamePrefix = prefix
+	}
+	opts.GroupsClaim = DefaultOIDCGroupsClaim
+	if claim := optMap[optOIDCGroupsClaim]; claim != "" {
+		opts.GroupsClaim = claim
+	}
+
+	// the option holds the ';' separated 'group:role' pairs, as ',' and '='
+	// separate the options
+	if gr := optMap[optOIDCGroupRoles]; gr != "" {
+		opts.GroupRoles = make(map[string][]string)
+		for _, pair := range strings.Split(gr, ";") {
+			group, role, ok := strings.Cut(pair, ":")
+			if !ok || group == "" || role == "" {
+				return fmt.Errorf("invalid group role mapping %q", pair)
+			}
+			opts.GroupRoles[group] = append(opts.GroupRoles[group], role)
+		}
+	}
+	return nil
+}
+
+// tokenOIDC authenticates the clients presenting the ID tokens of an OpenID
+// Connect identity provider as the etcd users mapped from their claims,
+// holding the roles mapped from their groups. The etcd users, which need no
+// password, must exist unless the groups of the clients grant them roles.
+type tokenOIDC struct {
+	lg   *zap.Logger
+	opts oidcOptions
+
+	// discoveries shares a discovery of the JWKS URL between the concurrent
+	// callers.
+	discoveries singleflight.Group
+	// revisions are the auth revisions of the ID tokens.
+	revisions idpTokenRevisions
+
+	mu   sync.Mutex
+	jwks *jwks
+	// discovered is the time the JWKS URL was last discovered, successfully or not.
+	discovered time.Time
+}
+
+func (t *tokenOIDC) enable()                         {}
+func (t *tokenOIDC) disable()                        {}
+func (t *tokenOIDC) invalidateUser(string)           {}
+func (t *tokenOIDC) genTokenPrefix() (string, error) { return "", nil }
+
+// the tokens minted by the identity provider are not tracked
+func (t *tokenOIDC) list(string) []*pb.AuthToken                { return nil }
+func (t *tokenOIDC) revoke(context.Context, string, string) int { return 0 }
+func (t *tokenOIDC) revocations() []TokenRevocation             { return nil }
+func (t *tokenOIDC) recoverRevocations([]TokenRevocation)       {}
+
+func (t *tokenOIDC) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		keys, err := t.keys()
+		if err != nil {
+			return nil, err
+		}
+		// the signing method of the token must match the type of the key,
+		// which the verification checks
+		return keys.key(kid)
+	})
+	if err != nil {
+		t.lg.Warn("failed to parse an OIDC ID token", zap.Error(err))
+		return nil, false
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !parsed.Valid || !ok {
+		t.lg.Warn("failed to obtain claims from an OIDC ID token")
+		return nil, false
+	}
+	if !claims.VerifyIssuer(t.opts.Issuer, true) {
+		t.lg.Warn("invalid issuer of an OIDC ID token", zap.Any("iss", claims["iss"]))
+		return nil, false
+	}
+	if !claims.VerifyAudience(t.opts.ClientID, true) {
+		t.lg.Warn("invalid audience of an OIDC ID token", zap.Any("aud", claims["aud"]))
+		return nil, false
+	}
+	// the ID tokens must expire, the verification only checks the expiry
+	// time if set
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		t.lg.Warn("missing expiry time of an OIDC ID token")
+		return nil, false
+	}
+
+	name, _ := claims[t.opts.UsernameClaim].(string)
+	if name == "" {
+		t.lg.Warn("failed to map an OIDC ID token to a user", zap.String("claim", t.opts.UsernameClaim))
+		return nil, false
+	}
+	username := t.opts.UsernamePrefix + name
+	if username == rootUser {
+		// the claims never map to the root user, the groups may grant the
+		// root role instead
+		t.lg.Warn("refused to map an OIDC ID token to the root user", zap.String("claim", t.opts.UsernameClaim))
+		return nil, false
+	}
+	// the ID tokens carry no auth revision
+	return &AuthInfo{
+		Username: username,
+		Revision: t.revisions.revision(token, rev, time.Unix(int64(exp), 0)),
+		Roles:    t.roles(claims),
+	}, true
+}
+
+// roles returns the roles mapped from the groups of the claims.
+func (t *tokenOIDC) roles(claims jwt.MapClaims) []string {
+	if len(t.opts.GroupRoles) == 0 {
+		return nil
+	}
+	var groups []string
+	switch gs := claims[t.opts.GroupsClaim].(type) {
+	case string:
+		groups = append(groups, gs)
+	case []interface{}:
+		for _, g := range gs {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+	var roles []string
+	for _, g := range groups {
+		roles = append(roles, t.opts.GroupRoles[g]...)
+	}
+	return roles
+}
+
+func (t *tokenOIDC) assign(ctx context.Context, username string, revision uint64) (string, error) {
+	// the ID tokens are minted by the identity provider only
+	return "", ErrVerifyOnly
+}
+
+// keys returns the JWKS of the issuer, discovering its URL first if needed,
+// at most every jwksMinFetchInterval. The lock is not held while
+// discovering, the concurrent callers joining the discovery.
+func (t *tokenOIDC) keys() (*jwks, error) {
+	t.mu.Lock()
+	keys, since := t.jwks, time.Since(t.discovered)
+	t.mu.Unlock()
+	if keys != nil {
+		return keys, nil
+	}
+	if since < jwksMinFetchInterval {
+		return nil, errors.New("JWKS URL of the issuer not discovered")
+	}
+
+	v, err, _ := t.discoveries.Do("", func() (interface{}, error) {
+		u, err := discoverJWKSURL(t.opts.Issuer)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.discovered = time.Now()
+		if err != nil {
+			t.lg.Warn("failed to discover JWKS URL of the issuer", zap.String("issuer", t.opts.Issuer), zap.Error(err))
+			return nil, err
+		}
+		if t.jwks == nil {
+			t.jwks = newJWKS(t.lg, u, t.opts.JWKSRefreshInterval)
+		}
+		return t.jwks, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*jwks), nil
+}
+
+// discoverJWKSURL gets the JWKS URL from the OpenID Connect discovery
+// document of the issuer.
+func discoverJWKSURL(issuer string) (string, error) {
+	client := &http.Client{Timeout: jwksFetchTimeout}
+	resp, err := client.Get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err = json.NewDecoder(http.MaxBytesReader(nil, resp.Body, jwksMaxSize)).Decode(&doc); err != nil {
+		return "", err
+	}
+	if doc.Issuer != issuer {
+		return "", fmt.Errorf("discovered issuer %q does not match", doc.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("missing jwks_uri")
+	}
+	return doc.JWKSURI, nil
+}
+
+func newTokenProviderOIDC(lg *zap.Logger, optMap map[string]string) (*tokenOIDC, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	var opts oidcOptions
+	if err := opts.Parse(optMap); err != nil {
+		lg.Error("problem loading OIDC options", zap.Error(err))
+		return nil, ErrInvalidAuthOpts
+	}
+
+	var keys = make([]string, 0, len(optMap))
+	for k := range optMap {
+		if !knownOIDCOptions[k] {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		lg.Warn("unknown OIDC options", zap.Strings("keys", keys))
+	}
+
+	t := &tokenOIDC{lg: lg, opts: opts}
+	if opts.JWKSURL != "" {
+		t.jwks = newJWKS(lg, opts.JWKSURL, opts.JWKSRefreshInterval)
+	}
+	return t, nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+)
+
+func TestOIDCInfo(t *testing.T) {
+	pem, err := os.ReadFile(jwtRSAPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := jwtv4.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer":%q,"jwks_uri":%q}`, srv.URL, srv.URL+"/keys")
+		case "/keys":
+			fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"k1","n":%q,"e":%q}]}`,
+				base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+				base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	lg := zap.NewNop()
+	oidc, err := newTokenProviderOIDC(lg, map[string]string{
+		"issuer":          srv.URL,
+		"client-id":       "etcd",
+		"username-prefix": "oidc:",
+		"group-roles":     "admins:root;devs:dev;devs:reader",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.TODO()
+	if _, aerr := oidc.assign(ctx, "abc", 123); aerr != ErrVerifyOnly {
+		t.Fatalf("unexpected error when attempting to assign OIDC token: %v", aerr)
+	}
+
+	sign := func(claims jwtv4.MapClaims) string {
+		tk := jwtv4.NewWithClaims(jwtv4.SigningMethodRS256, claims)
+		tk.Header["kid"] = "k1"
+		token, serr := tk.SignedString(priv)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		return token
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for name, tc := range map[string]struct {
+		claims   jwtv4.MapClaims
+		username string
+		roles    []string
+	}{
+		"subject": {
+			claims:   jwtv4.MapClaims{"sub": "alice", "aud": "etcd", "iss": srv.URL, "exp": exp},
+			username: "oidc:alice",
+		},
+		"unmapped groups": {
+			claims:   jwtv4.MapClaims{"sub": "alice", "groups": []string{"ops"}, "aud": "etcd", "iss": srv.URL, "exp": exp},
+			username: "oidc:alice",
+		},
+		"mapped groups": {
+			claims:   jwtv4.MapClaims{"sub": "alice", "groups": []string{"devs", "admins"}, "aud": "etcd", "iss": srv.URL, "exp": exp},
+			username: "oidc:alice",
+			roles:    []string{"dev", "reader", "root"},
+		},
+		"audiences": {
+			claims:   jwtv4.MapClaims{"sub": "alice", "groups": "devs", "aud": []string{"other", "etcd"}, "iss": srv.URL, "exp": exp},
+			username: "oidc:alice",
+			roles:    []string{"dev", "reader"},
+		},
+		"no expiry": {
+			claims: jwtv4.MapClaims{"sub": "alice", "aud": "etcd", "iss": srv.URL},
+		},
+		"wrong audience": {
+			claims: jwtv4.MapClaims{"sub": "alice", "aud": "other", "iss": srv.URL, "exp": exp},
+		},
+		"wrong issuer": {
+			claims: jwtv4.MapClaims{"sub": "alice", "aud": "etcd", "iss": "https://other", "exp": exp},
+		},
+		"expired": {
+			claims: jwtv4.MapClaims{"sub": "alice", "aud": "etcd", "iss": srv.URL, "exp": time.Now().Add(-time.Minute).Unix()},
+		},
+		"no subject": {
+			claims: jwtv4.MapClaims{"aud": "etcd", "iss": srv.URL, "exp": exp},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ai, ok := oidc.info(ctx, sign(tc.claims), 7)
+			if tc.username == "" {
+				if ok {
+					t.Fatalf("expected to fail to authenticate, got %+v", ai)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("failed to authenticate with OIDC ID token")
+			}
+			if ai.Username != tc.username || ai.Revision != 7 || !reflect.DeepEqual(ai.Roles, tc.roles) {
+				t.Fatalf("unexpected auth info %+v, expected user %q with roles %v", ai, tc.username, tc.roles)
+			}
+		})
+	}
+
+	// the token stays bound to the auth revision it was first verified at
+	token := sign(jwtv4.MapClaims{"sub": "bob", "aud": "etcd", "iss": srv.URL, "exp": exp})
+	for _, rev := range []uint64{8, 9} {
+		if ai, ok := oidc.info(ctx, token, rev); !ok || ai.Revision != 8 {
+			t.Fatalf("expected the token to keep revision 8, got %+v", ai)
+		}
+	}
+}
+
+func TestOIDCUsernamePrefix(t *testing.T) {
+	pem, err := os.ReadFile(jwtRSAPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := jwtv4.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"k1","n":%q,"e":%q}]}`,
+			base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()))
+	}))
+	defer srv.Close()
+	sign := func(sub string) string {
+		tk := jwtv4.NewWithClaims(jwtv4.SigningMethodRS256, jwtv4.MapClaims{
+			"sub": sub, "aud": "etcd", "iss": "https://idp", "exp": time.Now().Add(time.Hour).Unix(),
+		})
+		tk.Header["kid"] = "k1"
+		token, serr := tk.SignedString(priv)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		return token
+	}
+
+	for name, tc := range map[string]struct {
+		prefix   string
+		sub      string
+		username string
+	}{
+		"issuer by default": {sub: "root", username: "https://idp#root"},
+		"no prefix":         {prefix: "-", sub: "alice", username: "alice"},
+		"root refused":      {prefix: "-", sub: "root"},
+		"prefixed root":     {prefix: "ro", sub: "ot"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := map[string]string{"issuer": "https://idp", "client-id": "etcd", "jwks-url": srv.URL}
+			if tc.prefix != "" {
+				opts["username-prefix"] = tc.prefix
+			}
+			oidc, err := newTokenProviderOIDC(zap.NewNop(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ai, ok := oidc.info(context.TODO(), sign(tc.sub), 7)
+			if tc.username == "" {
+				if ok {
+					t.Fatalf("expected to fail to authenticate, got %+v", ai)
+				}
+				return
+			}
+			if !ok || ai.Username != tc.username {
+				t.Fatalf("unexpected auth info %+v, expected user %q", ai, tc.username)
+			}
+		})
+	}
+}
+
+func TestOIDCBad(t *testing.T) {
+	var badCases = map[string]map[string]string{
+		"no options": {},
+		"no client id": {
+			"issuer": "https://idp",
+		},
+		"invalid issuer": {
+			"issuer":    "idp",
+			"client-id": "etcd",
+		},
+		"invalid group roles": {
+			"issuer":      "https://idp",
+			"client-id":   "etcd",
+			"group-roles": "admins:root;devs",
+		},
+	}
+
+	lg := zap.NewNop()
+	for k, v := range badCases {
+		t.Run(k, func(t *testing.T) {
+			if _, err := newTokenProviderOIDC(lg, v); err == nil {
+				t.Errorf("expected error for options %v", v)
+			}
+		})
+	}
+}
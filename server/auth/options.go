@@ -18,6 +18,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"fmt"
+	"net/url"
 	"os"
 	"time"
 
@@ -29,6 +30,12 @@ const (
 	optPublicKey  = "pub-key"
 	optPrivateKey = "priv-key"
 	optTTL        = "ttl"
+
+	optJWKSURL             = "jwks-url"
+	optJWKSRefreshInterval = "jwks-refresh-interval"
+	optAudience            = "aud"
+	optIssuer              = "iss"
+	optUsernameClaim       = "username-claim"
 )
 
 var knownOptions = map[string]bool{
@@ -36,11 +43,21 @@ var knownOptions = map[string]bool{
 	optPublicKey:  true,
 	optPrivateKey: true,
 	optTTL:        true,
+
+	optJWKSURL:             true,
+	optJWKSRefreshInterval: true,
+	optAudience:            true,
+	optIssuer:              true,
+	optUsernameClaim:       true,
 }
 
 var (
 	// DefaultTTL will be used when a 'ttl' is not specified
 	DefaultTTL = 5 * time.Minute
+	// DefaultJWKSRefreshInterval will be used when a 'jwks-refresh-interval' is not specified
+	DefaultJWKSRefreshInterval = 10 * time.Minute
+	// DefaultUsernameClaim will be used when a 'username-claim' is not specified
+	DefaultUsernameClaim = "username"
 )
 
 type jwtOptions struct {
@@ -48,6 +65,16 @@ type jwtOptions struct {
 	PublicKey  []byte
 	PrivateKey []byte
 	TTL        time.Duration
+
+	// JWKSURL is the URL of the JSON Web Key Set of an identity provider,
+	// whose keys verify the tokens carrying their key ID.
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	// Audience and Issuer are the required "aud" and "iss" claims of the tokens, if any.
+	Audience string
+	Issuer   string
+	// UsernameClaim is the claim of the tokens holding the user name.
+	UsernameClaim string
 }
 
 // ParseWithDefaults will load options from the specified map or set defaults where appropriate
@@ -55,6 +82,12 @@ func (opts *jwtOptions) ParseWithDefaults(optMap map[string]string) error {
 	if opts.TTL == 0 && optMap[optTTL] == "" {
 		opts.TTL = DefaultTTL
 	}
+	if opts.JWKSRefreshInterval == 0 && optMap[optJWKSRefreshInterval] == "" {
+		opts.JWKSRefreshInterval = DefaultJWKSRefreshInterval
+	}
+	if opts.UsernameClaim == "" && optMap[optUsernameClaim] == "" {
+		opts.UsernameClaim = DefaultUsernameClaim
+	}
 
 	return opts.Parse(optMap)
 }
@@ -83,10 +116,33 @@ func (opts *jwtOptions) Parse(optMap map[string]string) error {
 		}
 	}
 
-	// signing method is a required field
+	if ri := optMap[optJWKSRefreshInterval]; ri != "" {
+		opts.JWKSRefreshInterval, err = time.ParseDuration(ri)
+		if err != nil {
+			return err
+		}
+	}
+	if u := optMap[optJWKSURL]; u != "" {
+		if _, err = url.ParseRequestURI(u); err != nil {
+			return err
+		}
+		opts.JWKSURL = u
+	}
+	if aud := optMap[optAudience]; aud != "" {
+		opts.Audience = aud
+	}
+	if iss := optMap[optIssuer]; iss != "" {
+		opts.Issuer = iss
+	}
+	if claim := optMap[optUsernameClaim]; claim != "" {
+		opts.UsernameClaim = claim
+	}
+
+	// signing method is a required field, unless the tokens are only verified
+	// with the keys of a JWKS, whose algorithms are then accepted
 	method := optMap[optSignMethod]
 	opts.SignMethod = jwt.GetSigningMethod(method)
-	if opts.SignMethod == nil {
+	if opts.SignMethod == nil && (method != "" || opts.JWKSURL == "" || len(opts.PublicKey) > 0 || len(opts.PrivateKey) > 0) {
 		return ErrInvalidAuthMethod
 	}
 
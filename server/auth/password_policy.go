@@ -0,0 +1,60 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"unicode"
+)
+
+// PasswordPolicy is the policy the passwords of the users must satisfy when
+// they are added or changed. The zero policy accepts any password. The
+// passwords hashed by the clients cannot be checked.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters of the passwords.
+	MinLength int
+	// MinCharClasses is the minimum number of the classes of characters of
+	// the passwords, out of lowercase letters, uppercase letters, digits and
+	// the other characters.
+	MinCharClasses int
+}
+
+// Check returns ErrPasswordPolicy if the password does not satisfy the policy.
+func (p PasswordPolicy) Check(password string) error {
+	var length int
+	var lower, upper, digit, other bool
+	for _, c := range password {
+		length++
+		switch {
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsDigit(c):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			classes++
+		}
+	}
+	if length < p.MinLength || classes < p.MinCharClasses {
+		return ErrPasswordPolicy
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  bool
+	}{
+		{name: "zero policy", password: ""},
+		{name: "long enough", policy: PasswordPolicy{MinLength: 4}, password: "abcd"},
+		{name: "too short", policy: PasswordPolicy{MinLength: 4}, password: "abc", wantErr: true},
+		{name: "length in characters", policy: PasswordPolicy{MinLength: 4}, password: "été!"},
+		{name: "enough classes", policy: PasswordPolicy{MinCharClasses: 3}, password: "aB1"},
+		{name: "symbols", policy: PasswordPolicy{MinCharClasses: 4}, password: "aB1-"},
+		{name: "too few classes", policy: PasswordPolicy{MinCharClasses: 3}, password: "abcDEF", wantErr: true},
+		{name: "both", policy: PasswordPolicy{MinLength: 8, MinCharClasses: 2}, password: "abcdefg1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.password)
+			if tt.wantErr && err != ErrPasswordPolicy {
+				t.Errorf("expected %v, got %v", ErrPasswordPolicy, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
@@ -15,51 +15,148 @@
 package auth
 
 import (
+	"bytes"
+	"strings"
+
 	"go.uber.org/zap"
 
 	"go.etcd.io/etcd/api/v3/authpb"
 	"go.etcd.io/etcd/pkg/v3/adt"
 )
 
+// permUserVariable is the variable of a key permission template that is
+// expanded to the name of the user whose permissions are checked. For example
+// a permission granted on the prefix "/users/{user}/" allows the user "alice"
+// to access keys with prefix "/users/alice/".
+var permUserVariable = []byte("{user}")
+
+// isValidPermTemplate reports whether the key range of perm cannot cover the
+// keys of another user once its template variables are expanded. A template of
+// a single key is always valid, but a template of a range must be a prefix
+// whose every variable is followed by "/": the prefix "/users/{user}" of the
+// user "al" would cover the keys of the user "alice", and so would the range
+// ["/a/{user}", "/b/{user}").
+func isValidPermTemplate(perm *authpb.Permission) bool {
+	if !bytes.Contains(perm.Key, permUserVariable) || len(perm.RangeEnd) == 0 {
+		return true
+	}
+	if !bytes.Equal(perm.RangeEnd, prefixRangeEnd(perm.Key)) {
+		return false
+	}
+	for key := perm.Key; ; {
+		i := bytes.Index(key, permUserVariable)
+		if i < 0 {
+			return true
+		}
+		key = key[i+len(permUserVariable):]
+		if len(key) == 0 || key[0] != '/' {
+			return false
+		}
+	}
+}
+
+// expandPermTemplate returns the key range of perm with the template
+// variables expanded for the given user. It returns false if the template
+// cannot be expanded for the user: a user name containing "/" would leave the
+// range of the user "foo" covering the keys of the user "foo/bar", so such a
+// user is granted nothing by the templates. Neither is anyone granted anything
+// by an invalid template, which may have been granted before the templates
+// were validated.
+func expandPermTemplate(perm *authpb.Permission, userName string) (key, rangeEnd []byte, ok bool) {
+	if !bytes.Contains(perm.Key, permUserVariable) {
+		return perm.Key, perm.RangeEnd, true
+	}
+	if strings.Contains(userName, "/") || !isValidPermTemplate(perm) {
+		return nil, nil, false
+	}
+	key = bytes.ReplaceAll(perm.Key, permUserVariable, []byte(userName))
+	if len(perm.RangeEnd) != 0 {
+		rangeEnd = prefixRangeEnd(key)
+	}
+	return key, rangeEnd, true
+}
+
+// prefixRangeEnd returns the end of the range covering all keys with the given prefix.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// next prefix does not exist (e.g., 0xffff);
+	// default to WithFromKey policy
+	return []byte{0}
+}
+
 func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
 	user := tx.UnsafeGetUser(userName)
 	if user == nil {
 		return nil
 	}
 
+	var roles []*authpb.Role
+	for _, roleName := range user.Roles {
+		if role := tx.UnsafeGetRole(roleName); role != nil {
+			roles = append(roles, role)
+		}
+	}
+	return mergePerms(userName, roles)
+}
+
+func mergePerms(userName string, roles []*authpb.Role) *unifiedRangePermissions {
 	readPerms := adt.NewIntervalTree()
 	writePerms := adt.NewIntervalTree()
+	watchPerms := adt.NewIntervalTree()
+	countPerms := adt.NewIntervalTree()
+	var quota Quota
+	hasRole := false
 
-	for _, roleName := range user.Roles {
-		role := tx.UnsafeGetRole(roleName)
-		if role == nil {
-			continue
-		}
+	for _, role := range roles {
+		quota.merge(role, !hasRole)
+		hasRole = true
 
 		for _, perm := range role.KeyPermission {
 			var ivl adt.Interval
 			var rangeEnd []byte
 
-			if len(perm.RangeEnd) != 1 || perm.RangeEnd[0] != 0 {
-				rangeEnd = perm.RangeEnd
+			key, permRangeEnd, ok := expandPermTemplate(perm, userName)
+			if !ok {
+				continue
+			}
+			if len(permRangeEnd) != 1 || permRangeEnd[0] != 0 {
+				rangeEnd = permRangeEnd
 			}
 
-			if len(perm.RangeEnd) != 0 {
-				ivl = adt.NewBytesAffineInterval(perm.Key, rangeEnd)
+			if len(permRangeEnd) != 0 {
+				ivl = adt.NewBytesAffineInterval(key, rangeEnd)
 			} else {
-				ivl = adt.NewBytesAffinePoint(perm.Key)
+				ivl = adt.NewBytesAffinePoint(key)
 			}
 
+			// reading the keys allows watching and counting them too
 			switch perm.PermType {
 			case authpb.READWRITE:
 				readPerms.Insert(ivl, struct{}{})
+				watchPerms.Insert(ivl, struct{}{})
+				countPerms.Insert(ivl, struct{}{})
 				writePerms.Insert(ivl, struct{}{})
 
 			case authpb.READ:
 				readPerms.Insert(ivl, struct{}{})
+				watchPerms.Insert(ivl, struct{}{})
+				countPerms.Insert(ivl, struct{}{})
 
 			case authpb.WRITE:
 				writePerms.Insert(ivl, struct{}{})
+
+			case authpb.WATCH:
+				watchPerms.Insert(ivl, struct{}{})
+
+			case authpb.COUNT:
+				countPerms.Insert(ivl, struct{}{})
 			}
 		}
 	}
@@ -67,6 +164,9 @@ func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
 	return &unifiedRangePermissions{
 		readPerms:  readPerms,
 		writePerms: writePerms,
+		watchPerms: watchPerms,
+		countPerms: countPerms,
+		quota:      quota,
 	}
 }
 
@@ -85,6 +185,10 @@ func checkKeyInterval(
 		return cachedPerms.readPerms.Contains(ivl)
 	case authpb.WRITE:
 		return cachedPerms.writePerms.Contains(ivl)
+	case authpb.WATCH:
+		return cachedPerms.watchPerms.Contains(ivl)
+	case authpb.COUNT:
+		return cachedPerms.countPerms.Contains(ivl)
 	default:
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
@@ -98,22 +202,23 @@ func checkKeyPoint(lg *zap.Logger, cachedPerms *unifiedRangePermissions, key []b
 		return cachedPerms.readPerms.Intersects(pt)
 	case authpb.WRITE:
 		return cachedPerms.writePerms.Intersects(pt)
+	case authpb.WATCH:
+		return cachedPerms.watchPerms.Intersects(pt)
+	case authpb.COUNT:
+		return cachedPerms.countPerms.Intersects(pt)
 	default:
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
 	return false
 }
 
-func (as *authStore) isRangeOpPermitted(userName string, key, rangeEnd []byte, permtyp authpb.Permission_Type) bool {
+func (as *authStore) isRangeOpPermitted(tx AuthReadTx, authInfo *AuthInfo, user *authpb.User, key, rangeEnd []byte, permtyp authpb.Permission_Type) bool {
 	// assumption: tx is Lock()ed
-	as.rangePermCacheMu.RLock()
-	defer as.rangePermCacheMu.RUnlock()
-
-	rangePerm, ok := as.rangePermCache[userName]
+	rangePerm, ok := as.authPerms(tx, authInfo, user)
 	if !ok {
 		as.lg.Error(
 			"user doesn't exist",
-			zap.String("user-name", userName),
+			zap.String("user-name", authInfo.Username),
 		)
 		return false
 	}
@@ -135,6 +240,8 @@ func (as *authStore) refreshRangePermCache(tx AuthReadTx) {
 	as.lg.Debug("Refreshing rangePermCache")
 
 	as.rangePermCache = make(map[string]*unifiedRangePermissions)
+	as.tokenRolesPermCache = make(map[string]*unifiedRangePermissions)
+	as.hasQuotas = false
 
 	users := tx.UnsafeGetAllUsers()
 	for _, user := range users {
@@ -148,10 +255,100 @@ func (as *authStore) refreshRangePermCache(tx AuthReadTx) {
 			continue
 		}
 		as.rangePermCache[userName] = perms
+		if perms.quota != (Quota{}) {
+			as.hasQuotas = true
+		}
+	}
+
+	// the permissions of the users named after SANs are merged from the
+	// roles granted by the rules on demand
+	as.sanRolesCache = make(map[string]*authpb.Role)
+	for _, r := range as.sanRoleRules {
+		role := tx.UnsafeGetRole(r.Role)
+		if role == nil {
+			continue
+		}
+		as.sanRolesCache[r.Role] = role
+		if role.MaxRequestsPerSecond != 0 || role.MaxWatchStreams != 0 || role.MaxTxnOps != 0 {
+			as.hasQuotas = true
+		}
+	}
+}
+
+// userPerms returns the cached permissions of the user, merging the ones of
+// a user named after a SAN from the roles granted by the rules when missing.
+func (as *authStore) userPerms(userName string) (*unifiedRangePermissions, bool) {
+	as.rangePermCacheMu.RLock()
+	perms, ok := as.rangePermCache[userName]
+	as.rangePermCacheMu.RUnlock()
+	if ok {
+		return perms, true
+	}
+	roleNames := as.sanRoles(userName)
+	if len(roleNames) == 0 {
+		return nil, false
+	}
+
+	as.rangePermCacheMu.Lock()
+	defer as.rangePermCacheMu.Unlock()
+	if perms, ok = as.rangePermCache[userName]; ok {
+		return perms, true
+	}
+	var roles []*authpb.Role
+	for _, roleName := range roleNames {
+		if role, ok := as.sanRolesCache[roleName]; ok {
+			roles = append(roles, role)
+		}
+	}
+	perms = mergePerms(userName, roles)
+	as.rangePermCache[userName] = perms
+	return perms, true
+}
+
+// maxTokenRolesPermCacheSize is the maximum number of the cached permissions
+// of the users holding the roles granted by their tokens.
+const maxTokenRolesPermCacheSize = 1024
+
+// authPerms returns the permissions of the user of the auth info, merged from
+// the roles granted by its token too. The tx must be locked and user must be
+// the user of the auth info, see unsafeGetAuthUser().
+func (as *authStore) authPerms(tx AuthReadTx, authInfo *AuthInfo, user *authpb.User) (*unifiedRangePermissions, bool) {
+	if len(authInfo.Roles) == 0 {
+		return as.userPerms(authInfo.Username)
+	}
+	key := strings.Join(append([]string{authInfo.Username}, user.Roles...), "\x00")
+	as.rangePermCacheMu.RLock()
+	perms, ok := as.tokenRolesPermCache[key]
+	as.rangePermCacheMu.RUnlock()
+	if ok {
+		return perms, true
+	}
+
+	var roles []*authpb.Role
+	for _, roleName := range user.Roles {
+		if role := tx.UnsafeGetRole(roleName); role != nil {
+			roles = append(roles, role)
+		}
+	}
+	perms = mergePerms(authInfo.Username, roles)
+
+	as.rangePermCacheMu.Lock()
+	defer as.rangePermCacheMu.Unlock()
+	if len(as.tokenRolesPermCache) >= maxTokenRolesPermCacheSize {
+		as.tokenRolesPermCache = make(map[string]*unifiedRangePermissions)
+	}
+	as.tokenRolesPermCache[key] = perms
+	if perms.quota != (Quota{}) {
+		as.hasQuotas = true
 	}
+	return perms, true
 }
 
 type unifiedRangePermissions struct {
 	readPerms  adt.IntervalTree
 	writePerms adt.IntervalTree
+	watchPerms adt.IntervalTree
+	countPerms adt.IntervalTree
+	// quota is the quota of the requests of the user, merged from its roles
+	quota Quota
 }
@@ -15,6 +15,7 @@
 package auth
 
 import (
+	"bytes"
 	"testing"
 
 	"go.uber.org/zap/zaptest"
@@ -100,3 +101,75 @@ func TestKeyPermission(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandPermTemplate(t *testing.T) {
+	tests := []struct {
+		perm         *authpb.Permission
+		wantKey      []byte
+		wantRangeEnd []byte
+	}{
+		{
+			&authpb.Permission{Key: []byte("/users/"), RangeEnd: []byte("/users0")},
+			[]byte("/users/"), []byte("/users0"),
+		},
+		{
+			&authpb.Permission{Key: []byte("/users/"), RangeEnd: []byte{0}},
+			[]byte("/users/"), []byte{0},
+		},
+		{
+			&authpb.Permission{Key: []byte("/users/{user}")},
+			[]byte("/users/alice"), nil,
+		},
+		{
+			&authpb.Permission{Key: []byte("/users/{user}/"), RangeEnd: []byte("/users/{user}0")},
+			[]byte("/users/alice/"), []byte("/users/alice0"),
+		},
+		{
+			&authpb.Permission{Key: []byte("/{user}/data/{user}/"), RangeEnd: []byte("/{user}/data/{user}0")},
+			[]byte("/alice/data/alice/"), []byte("/alice/data/alice0"),
+		},
+	}
+
+	for i, tt := range tests {
+		key, rangeEnd, ok := expandPermTemplate(tt.perm, "alice")
+		if !ok {
+			t.Errorf("#%d: template not expanded", i)
+		}
+		if !bytes.Equal(key, tt.wantKey) || !bytes.Equal(rangeEnd, tt.wantRangeEnd) {
+			t.Errorf("#%d: got [%q, %q), want [%q, %q)", i, key, rangeEnd, tt.wantKey, tt.wantRangeEnd)
+		}
+	}
+}
+
+func TestExpandPermTemplateInvalid(t *testing.T) {
+	tests := []*authpb.Permission{
+		// the prefix of the user "al" would cover the keys of the user "alice".
+		{Key: []byte("/users/{user}"), RangeEnd: []byte("/users/{user~")},
+		{Key: []byte("/users/{user}/{user}"), RangeEnd: []byte("/users/{user}/{user~")},
+		{Key: []byte("/users/{user}"), RangeEnd: []byte{0}},
+		{Key: []byte("/a/{user}/"), RangeEnd: []byte("/b/{user}/")},
+	}
+
+	for i, perm := range tests {
+		if isValidPermTemplate(perm) {
+			t.Errorf("#%d: [%q, %q) expected to be invalid", i, perm.Key, perm.RangeEnd)
+		}
+		if _, _, ok := expandPermTemplate(perm, "al"); ok {
+			t.Errorf("#%d: [%q, %q) expected not to expand", i, perm.Key, perm.RangeEnd)
+		}
+	}
+}
+
+func TestExpandPermTemplateUserWithSlash(t *testing.T) {
+	perm := &authpb.Permission{Key: []byte("/users/{user}/"), RangeEnd: []byte("/users/{user}0")}
+	if _, _, ok := expandPermTemplate(perm, "foo/bar"); ok {
+		t.Fatal("expected the template not to expand for a user name with '/'")
+	}
+
+	// the permissions without template still apply to the user.
+	perm = &authpb.Permission{Key: []byte("/shared/"), RangeEnd: []byte("/shared0")}
+	key, rangeEnd, ok := expandPermTemplate(perm, "foo/bar")
+	if !ok || !bytes.Equal(key, perm.Key) || !bytes.Equal(rangeEnd, perm.RangeEnd) {
+		t.Fatalf("got [%q, %q) %t, want [%q, %q) true", key, rangeEnd, ok, perm.Key, perm.RangeEnd)
+	}
+}
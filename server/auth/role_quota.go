@@ -0,0 +1,89 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"go.etcd.io/etcd/api/v3/authpb"
+)
+
+// Quota is the quota of the requests of a user, the most permissive of the
+// quotas of its roles. A zero limit means no limit.
+type Quota struct {
+	// MaxRequestsPerSecond is the maximum rate of the unary requests of the user.
+	MaxRequestsPerSecond int64
+	// MaxWatchStreams is the maximum number of the concurrent watch streams of the user.
+	MaxWatchStreams int64
+	// MaxTxnOps is the maximum number of operations per txn of the user.
+	MaxTxnOps int64
+}
+
+// merge merges the quota of a role of the user into q, taking it as is for
+// the first role of the user.
+func (q *Quota) merge(role *authpb.Role, first bool) {
+	if first {
+		q.MaxRequestsPerSecond = role.MaxRequestsPerSecond
+		q.MaxWatchStreams = role.MaxWatchStreams
+		q.MaxTxnOps = role.MaxTxnOps
+		return
+	}
+	q.MaxRequestsPerSecond = mergeQuotaLimit(q.MaxRequestsPerSecond, role.MaxRequestsPerSecond)
+	q.MaxWatchStreams = mergeQuotaLimit(q.MaxWatchStreams, role.MaxWatchStreams)
+	q.MaxTxnOps = mergeQuotaLimit(q.MaxTxnOps, role.MaxTxnOps)
+}
+
+// mergeQuotaLimit returns the most permissive of two limits, 0 being no limit.
+func mergeQuotaLimit(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// UserQuota returns the quota of the requests of the user. The root role has
+// no quota, nor have the requests without a user.
+func (as *authStore) UserQuota(authInfo *AuthInfo) Quota {
+	if !as.IsAuthEnabled() || authInfo == nil || authInfo.Username == "" {
+		return Quota{}
+	}
+
+	if len(authInfo.Roles) > 0 {
+		tx := as.be.ReadTx()
+		tx.Lock()
+		defer tx.Unlock()
+		if u := as.unsafeGetAuthUser(tx, authInfo); u != nil {
+			perms, _ := as.authPerms(tx, authInfo, u)
+			return perms.quota
+		}
+		return Quota{}
+	}
+	if perms, ok := as.userPerms(authInfo.Username); ok {
+		return perms.quota
+	}
+	return Quota{}
+}
+
+// HasQuotas returns true if a user has a quota, so that the requests need not
+// be authenticated for their quotas otherwise.
+func (as *authStore) HasQuotas() bool {
+	if !as.IsAuthEnabled() {
+		return false
+	}
+	as.rangePermCacheMu.RLock()
+	defer as.rangePermCacheMu.RUnlock()
+	return as.hasQuotas
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+)
+
+const (
+	// SANTypeURI matches the URI SANs of the client certificates, for
+	// example the SPIFFE IDs of the workloads.
+	SANTypeURI = "uri"
+	// SANTypeDNS matches the DNS name SANs of the client certificates.
+	SANTypeDNS = "dns"
+)
+
+// SANRoleRule grants a role to the clients presenting a certificate with a
+// subject alternative name matching the pattern, without a user per
+// certificate. The clients are authenticated as the user named after the
+// type and the value of their first matching SAN, like
+// "uri:spiffe://example.org/ns/prod/sa/api", which holds the roles of all
+// the rules matching the SAN and needs not exist. A user of the same name
+// takes precedence over the rules.
+type SANRoleRule struct {
+	// Type is the type of the SAN, SANTypeURI or SANTypeDNS.
+	Type string
+	// Pattern matches the SANs, a '*' matching any segment of a URI path or
+	// any label of a DNS name, like "spiffe://example.org/ns/*/sa/api" or
+	// "*.example.org".
+	Pattern string
+	// Role is the role granted to the matching clients.
+	Role string
+}
+
+// ParseSANRoleRule parses a rule given as "<type>:<pattern>=<role>", for
+// example "uri:spiffe://example.org/ns/prod/sa/*=prod-reader".
+func ParseSANRoleRule(s string) (SANRoleRule, error) {
+	typ, rest, ok := strings.Cut(s, ":")
+	i := strings.LastIndex(rest, "=")
+	if !ok || i <= 0 || i == len(rest)-1 {
+		return SANRoleRule{}, fmt.Errorf("invalid SAN role rule %q, expected '<type>:<pattern>=<role>'", s)
+	}
+	r := SANRoleRule{Type: typ, Pattern: rest[:i], Role: rest[i+1:]}
+	switch r.Type {
+	case SANTypeURI, SANTypeDNS:
+	default:
+		return SANRoleRule{}, fmt.Errorf("invalid SAN type %q of SAN role rule %q, expected %q or %q", r.Type, s, SANTypeURI, SANTypeDNS)
+	}
+	for _, seg := range strings.Split(r.Pattern, r.separator()) {
+		if _, err := path.Match(seg, ""); err != nil {
+			return SANRoleRule{}, fmt.Errorf("invalid pattern of SAN role rule %q: %v", s, err)
+		}
+	}
+	return r, nil
+}
+
+func (r SANRoleRule) separator() string {
+	if r.Type == SANTypeDNS {
+		return "."
+	}
+	return "/"
+}
+
+// match returns true if the pattern of the rule matches the SAN, segment by
+// segment.
+func (r SANRoleRule) match(san string) bool {
+	sep := r.separator()
+	pats, segs := strings.Split(r.Pattern, sep), strings.Split(san, sep)
+	if len(pats) != len(segs) {
+		return false
+	}
+	for i := range pats {
+		if ok, _ := path.Match(pats[i], segs[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SetSANRoleRules sets the rules granting roles to the clients by the SANs
+// of their certificates. The rules must be the same on all the members, as
+// they authorize the requests when applying them.
+func (as *authStore) SetSANRoleRules(rules []SANRoleRule) {
+	as.sanRoleRules = rules
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	as.refreshRangePermCache(tx)
+}
+
+// sanRoles returns the roles granted by the rules to the user named after a
+// SAN, nil for the other users.
+func (as *authStore) sanRoles(userName string) []string {
+	typ, san, ok := strings.Cut(userName, ":")
+	if !ok || len(as.sanRoleRules) == 0 {
+		return nil
+	}
+	var roles []string
+	for _, r := range as.sanRoleRules {
+		if r.Type == typ && r.match(san) {
+			roles = append(roles, r.Role)
+		}
+	}
+	return roles
+}
+
+// sanUser returns the user named after the first SAN of the certificate
+// matched by a rule, "" if none is.
+func (as *authStore) sanUser(cert *x509.Certificate) string {
+	if len(as.sanRoleRules) == 0 {
+		return ""
+	}
+	for _, u := range cert.URIs {
+		if name := SANTypeURI + ":" + u.String(); len(as.sanRoles(name)) > 0 {
+			return name
+		}
+	}
+	for _, dns := range cert.DNSNames {
+		if name := SANTypeDNS + ":" + dns; len(as.sanRoles(name)) > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// unsafeGetUser returns the user of the name, or the user named after a SAN
+// holding the roles granted by the rules, nil if neither exists.
+func (as *authStore) unsafeGetUser(tx AuthReadTx, userName string) *authpb.User {
+	if u := tx.UnsafeGetUser(userName); u != nil {
+		return u
+	}
+	roles := as.sanRoles(userName)
+	if len(roles) == 0 {
+		return nil
+	}
+	// the roles of the users are sorted, see hasRootRole()
+	sort.Strings(roles)
+	return &authpb.User{
+		Name:    []byte(userName),
+		Roles:   roles,
+		Options: &authpb.UserAddOptions{NoPassword: true},
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestParseSANRoleRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    SANRoleRule
+		wantErr bool
+	}{
+		{rule: "uri:spiffe://example.org/ns/*/sa/api=reader", want: SANRoleRule{Type: SANTypeURI, Pattern: "spiffe://example.org/ns/*/sa/api", Role: "reader"}},
+		{rule: "dns:*.example.org=writer", want: SANRoleRule{Type: SANTypeDNS, Pattern: "*.example.org", Role: "writer"}},
+		{rule: "ip:10.0.0.1=reader", wantErr: true},
+		{rule: "uri:spiffe://example.org", wantErr: true},
+		{rule: "uri:spiffe://example.org=", wantErr: true},
+		{rule: "dns:[.example.org=reader", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := ParseSANRoleRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if r != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, r)
+			}
+		})
+	}
+}
+
+func TestSANRoleRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule SANRoleRule
+		san  string
+		want bool
+	}{
+		{SANRoleRule{Type: SANTypeURI, Pattern: "spiffe://example.org/ns/*/sa/api"}, "spiffe://example.org/ns/prod/sa/api", true},
+		{SANRoleRule{Type: SANTypeURI, Pattern: "spiffe://example.org/ns/*/sa/api"}, "spiffe://example.org/ns/prod/sa/web", false},
+		{SANRoleRule{Type: SANTypeURI, Pattern: "spiffe://example.org/ns/*"}, "spiffe://example.org/ns/prod/sa/api", false},
+		{SANRoleRule{Type: SANTypeDNS, Pattern: "*.example.org"}, "api.example.org", true},
+		{SANRoleRule{Type: SANTypeDNS, Pattern: "*.example.org"}, "api.prod.example.org", false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.match(tt.san); got != tt.want {
+			t.Errorf("%q matching %q: expected %v, got %v", tt.rule.Pattern, tt.san, tt.want, got)
+		}
+	}
+}
+
+func TestSANRoleRulePermissions(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.READ, Key: []byte("foo"), RangeEnd: []byte("fop")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.SetSANRoleRules([]SANRoleRule{
+		{Type: SANTypeURI, Pattern: "spiffe://example.org/ns/*/sa/api", Role: "role-test"},
+		{Type: SANTypeDNS, Pattern: "admin.example.org", Role: "root"},
+	})
+
+	api := &AuthInfo{Username: "uri:spiffe://example.org/ns/prod/sa/api", Revision: as.Revision()}
+	if err = as.IsRangePermitted(api, []byte("foo"), []byte("foo1")); err != nil {
+		t.Errorf("expected range to be permitted, got %v", err)
+	}
+	if err = as.IsPutPermitted(api, []byte("foo")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsAdminPermitted(api); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	web := &AuthInfo{Username: "uri:spiffe://example.org/ns/prod/sa/web", Revision: as.Revision()}
+	if err = as.IsRangePermitted(web, []byte("foo"), nil); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	admin := &AuthInfo{Username: "dns:admin.example.org", Revision: as.Revision()}
+	if err = as.IsAdminPermitted(admin); err != nil {
+		t.Errorf("expected admin to be permitted, got %v", err)
+	}
+
+	// the permissions granted to the role later apply to the SAN users
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.WRITE, Key: []byte("foo")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.Revision = as.Revision()
+	if err = as.IsPutPermitted(api, []byte("foo")); err != nil {
+		t.Errorf("expected put to be permitted, got %v", err)
+	}
+}
+
+func TestAuthInfoFromTLSSANRoleRules(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+	as.SetSANRoleRules([]SANRoleRule{{Type: SANTypeURI, Pattern: "spiffe://example.org/*", Role: "role-test"}})
+
+	ctxWithCert := func(cn string) context.Context {
+		u, err := url.Parse("spiffe://example.org/api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}, URIs: []*url.URL{u}}
+		ctx := peer.NewContext(context.TODO(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		}})
+		return metadata.NewIncomingContext(ctx, metadata.New(nil))
+	}
+
+	if ai := as.AuthInfoFromTLS(ctxWithCert("")); ai == nil || ai.Username != "uri:spiffe://example.org/api" {
+		t.Errorf("expected the user of the SAN, got %+v", ai)
+	}
+	// the user of the common name takes precedence over the rules
+	if ai := as.AuthInfoFromTLS(ctxWithCert("foo")); ai == nil || ai.Username != "foo" {
+		t.Errorf("expected the user of the common name, got %+v", ai)
+	}
+}
@@ -29,6 +29,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 )
 
 const (
@@ -103,7 +105,8 @@ type tokenSimple struct {
 	indexWaiter       func(uint64) <-chan struct{}
 	simpleTokenKeeper *simpleTokenTTLKeeper
 	simpleTokensMu    sync.Mutex
-	simpleTokens      map[string]string // token -> username
+	simpleTokens      map[string]string     // token -> username
+	simpleTokenIssues map[string]tokenIssue // token -> issue of the token
 	simpleTokenTTL    time.Duration
 }
 
@@ -122,7 +125,7 @@ func (t *tokenSimple) genTokenPrefix() (string, error) {
 	return string(ret), nil
 }
 
-func (t *tokenSimple) assignSimpleTokenToUser(username, token string) {
+func (t *tokenSimple) assignSimpleTokenToUser(username, token string, ti tokenIssue) {
 	t.simpleTokensMu.Lock()
 	defer t.simpleTokensMu.Unlock()
 	if t.simpleTokenKeeper == nil {
@@ -139,6 +142,7 @@ func (t *tokenSimple) assignSimpleTokenToUser(username, token string) {
 	}
 
 	t.simpleTokens[token] = username
+	t.simpleTokenIssues[token] = ti
 	t.simpleTokenKeeper.addSimpleToken(token)
 }
 
@@ -150,6 +154,7 @@ func (t *tokenSimple) invalidateUser(username string) {
 	for token, name := range t.simpleTokens {
 		if name == username {
 			delete(t.simpleTokens, token)
+			delete(t.simpleTokenIssues, token)
 			t.simpleTokenKeeper.deleteSimpleToken(token)
 		}
 	}
@@ -174,6 +179,7 @@ func (t *tokenSimple) enable() {
 				zap.String("token", tk),
 			)
 			delete(t.simpleTokens, tk)
+			delete(t.simpleTokenIssues, tk)
 		}
 	}
 	t.simpleTokenKeeper = &simpleTokenTTLKeeper{
@@ -192,6 +198,7 @@ func (t *tokenSimple) disable() {
 	tk := t.simpleTokenKeeper
 	t.simpleTokenKeeper = nil
 	t.simpleTokens = make(map[string]string) // invalidate all tokens
+	t.simpleTokenIssues = make(map[string]tokenIssue)
 	t.simpleTokensMu.Unlock()
 	if tk != nil {
 		tk.stop()
@@ -220,11 +227,50 @@ func (t *tokenSimple) assign(ctx context.Context, username string, rev uint64) (
 	}
 	simpleTokenPrefix := ctx.Value(AuthenticateParamSimpleTokenPrefix{}).(string)
 	token := fmt.Sprintf("%s.%d", simpleTokenPrefix, index)
-	t.assignSimpleTokenToUser(username, token)
+	t.assignSimpleTokenToUser(username, token, newTokenIssue(ctx, username))
 
 	return token, nil
 }
 
+func (t *tokenSimple) list(username string) []*pb.AuthToken {
+	t.simpleTokensMu.Lock()
+	defer t.simpleTokensMu.Unlock()
+	if t.simpleTokenKeeper == nil {
+		return nil
+	}
+	var tokens []*pb.AuthToken
+	for token, ti := range t.simpleTokenIssues {
+		if ti.id == "" || (username != "" && ti.username != username) {
+			continue
+		}
+		tokens = append(tokens, ti.token(t.simpleTokenKeeper.tokens[token]))
+	}
+	return tokens
+}
+
+func (t *tokenSimple) revoke(_ context.Context, id, username string) int {
+	t.simpleTokensMu.Lock()
+	defer t.simpleTokensMu.Unlock()
+	if t.simpleTokenKeeper == nil {
+		return 0
+	}
+	revoked := 0
+	for token, ti := range t.simpleTokenIssues {
+		if (id != "" && ti.id != id) || (id == "" && ti.username != username) {
+			continue
+		}
+		delete(t.simpleTokens, token)
+		delete(t.simpleTokenIssues, token)
+		t.simpleTokenKeeper.deleteSimpleToken(token)
+		revoked++
+	}
+	return revoked
+}
+
+// the simple tokens do not survive a restart, nor do their revocations
+func (t *tokenSimple) revocations() []TokenRevocation       { return nil }
+func (t *tokenSimple) recoverRevocations([]TokenRevocation) {}
+
 func (t *tokenSimple) isValidSimpleToken(ctx context.Context, token string) bool {
 	splitted := strings.Split(token, ".")
 	if len(splitted) != 2 {
@@ -249,9 +295,10 @@ func newTokenProviderSimple(lg *zap.Logger, indexWaiter func(uint64) <-chan stru
 		lg = zap.NewNop()
 	}
 	return &tokenSimple{
-		lg:             lg,
-		simpleTokens:   make(map[string]string),
-		indexWaiter:    indexWaiter,
-		simpleTokenTTL: TokenTTL,
+		lg:                lg,
+		simpleTokens:      make(map[string]string),
+		simpleTokenIssues: make(map[string]tokenIssue),
+		indexWaiter:       indexWaiter,
+		simpleTokenTTL:    TokenTTL,
 	}
 }
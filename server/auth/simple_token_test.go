@@ -68,3 +68,51 @@ func TestSimpleTokenAssign(t *testing.T) {
 		t.Errorf("expected ok == false after user is invalidated")
 	}
 }
+
+// TestSimpleTokenListRevoke ensures that TokenProviderSimple lists the tokens
+// it assigned and revokes them by ID and by user.
+func TestSimpleTokenListRevoke(t *testing.T) {
+	tp := newTokenProviderSimple(zaptest.NewLogger(t), dummyIndexWaiter, simpleTokenTTLDefault)
+	tp.enable()
+	defer tp.disable()
+
+	assign := func(index uint64, username string) string {
+		ctx := context.WithValue(context.TODO(), AuthenticateParamIndex{}, index)
+		ctx = context.WithValue(ctx, AuthenticateParamSimpleTokenPrefix{}, "dummy")
+		ctx = context.WithValue(ctx, AuthenticateParamPeerAddress{}, "127.0.0.1:2379")
+		token, err := tp.assign(ctx, username, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	token1 := assign(1, "user1")
+	token2 := assign(2, "user1")
+	token3 := assign(3, "user2")
+
+	if tokens := tp.list(""); len(tokens) != 3 {
+		t.Fatalf("expected 3 tokens, got %v", tokens)
+	}
+	tokens := tp.list("user2")
+	if len(tokens) != 1 || tokens[0].Id != "3" || tokens[0].User != "user2" || tokens[0].PeerAddress != "127.0.0.1:2379" || tokens[0].TTL <= 0 {
+		t.Fatalf("unexpected tokens of user2 %v", tokens)
+	}
+
+	if n := tp.revoke(context.TODO(), "3", ""); n != 1 {
+		t.Fatalf("expected 1 token revoked, got %d", n)
+	}
+	if _, ok := tp.info(context.TODO(), token3, 0); ok {
+		t.Errorf("expected revoked token to be invalid")
+	}
+	if n := tp.revoke(context.TODO(), "", "user1"); n != 2 {
+		t.Fatalf("expected 2 tokens revoked, got %d", n)
+	}
+	for _, token := range []string{token1, token2} {
+		if _, ok := tp.info(context.TODO(), token, 0); ok {
+			t.Errorf("expected revoked token %q to be invalid", token)
+		}
+	}
+	if tokens := tp.list(""); len(tokens) != 0 {
+		t.Fatalf("expected no tokens, got %v", tokens)
+	}
+}
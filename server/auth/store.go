@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
@@ -65,19 +66,31 @@ var (
 	ErrMissingKey           = errors.New("auth: missing key data")
 	ErrKeyMismatch          = errors.New("auth: public and private keys don't match")
 	ErrVerifyOnly           = errors.New("auth: token signing attempted with verify-only key")
+	ErrRoleLeaseTTLTooLarge = errors.New("auth: lease TTL exceeds the maximum lease TTL of the roles of the user")
+	ErrPasswordPolicy       = errors.New("auth: password does not satisfy the password policy")
+	ErrReservedRoleExists   = errors.New("auth: a role was added with the name of a predefined role")
+	ErrInvalidPermTemplate  = errors.New("auth: invalid permission template")
 )
 
 const (
 	rootUser = "root"
 	rootRole = "root"
+	// maintenanceReadOnlyRole is the predefined role permitted to call the
+	// read-only maintenance RPCs, without any permission on the keys.
+	maintenanceReadOnlyRole = "maintenance-readonly"
 
 	tokenTypeSimple = "simple"
 	tokenTypeJWT    = "jwt"
+	tokenTypeOIDC   = "oidc"
 )
 
 type AuthInfo struct {
 	Username string
 	Revision uint64
+	// Roles are the roles granted by the auth token in addition to the roles
+	// of the user, like the roles mapped from the groups of an OIDC ID token.
+	// The user needs not exist if the token grants roles.
+	Roles []string
 }
 
 // AuthenticateParamIndex is used for a key of context in the parameters of Authenticate()
@@ -136,6 +149,21 @@ type AuthStore interface {
 	// RoleDelete gets the detailed information of a role
 	RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 
+	// RoleSetMaxLeaseTTL sets the maximum TTL of the leases granted by the users of a role
+	RoleSetMaxLeaseTTL(r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error)
+
+	// RoleSetQuota sets the quotas of the requests of the users of a role
+	RoleSetQuota(r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error)
+
+	// SetBcryptCost sets the minimum bcrypt cost of the passwords of the cluster
+	SetBcryptCost(r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error)
+
+	// TokenList lists the valid tokens issued by the member
+	TokenList(r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error)
+
+	// TokenRevoke revokes a token, or all the tokens of a user
+	TokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error)
+
 	// UserList gets a list of all users
 	UserList(r *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 
@@ -148,12 +176,33 @@ type AuthStore interface {
 	// IsRangePermitted checks range permission of the user
 	IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
+	// IsWatchPermitted checks watch permission of the user
+	IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
+	// IsCountPermitted checks the permission of the user to count the keys of
+	// a range, or to range over them without their values
+	IsCountPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
 	// IsDeleteRangePermitted checks delete-range permission of the user
 	IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
 	// IsAdminPermitted checks admin permission of the user
 	IsAdminPermitted(authInfo *AuthInfo) error
 
+	// IsMaintenanceReadPermitted checks the permission of the user to call
+	// the read-only maintenance RPCs
+	IsMaintenanceReadPermitted(authInfo *AuthInfo) error
+
+	// UserQuota returns the quota of the requests of the user
+	UserQuota(authInfo *AuthInfo) Quota
+
+	// HasQuotas returns true if a role has a quota of the requests of its users
+	HasQuotas() bool
+
+	// IsLeaseGrantPermitted checks that the TTL of a lease granted by the user
+	// does not exceed the maximum lease TTL of its roles
+	IsLeaseGrantPermitted(authInfo *AuthInfo, ttl int64) error
+
 	// GenTokenPrefix produces a random string in a case of simple token
 	// in a case of JWT, it produces an empty string
 	GenTokenPrefix() (string, error)
@@ -181,6 +230,12 @@ type AuthStore interface {
 
 	// BcryptCost gets strength of hashing bcrypted auth password
 	BcryptCost() int
+
+	// NeedsRehash checks that the password of user is hashed with a lower cost than BcryptCost()
+	NeedsRehash(user string) bool
+
+	// RehashPassword replaces the password hash of user, unless the auth revision changed since revision
+	RehashPassword(user, hashedPassword string, revision uint64)
 }
 
 type TokenProvider interface {
@@ -191,6 +246,16 @@ type TokenProvider interface {
 
 	invalidateUser(string)
 	genTokenPrefix() (string, error)
+
+	// list returns the valid tokens of the user, of all the users if empty
+	list(username string) []*pb.AuthToken
+	// revoke revokes the token of the ID, or all the tokens of the user if
+	// the ID is empty, returning the number of the revoked tokens
+	revoke(ctx context.Context, id, username string) int
+	// revocations returns the revocations to persist, which the provider
+	// recovers after a restart
+	revocations() []TokenRevocation
+	recoverRevocations(rs []TokenRevocation)
 }
 
 type AuthBackend interface {
@@ -209,19 +274,24 @@ type AuthBatchTx interface {
 	AuthReadTx
 	UnsafeSaveAuthEnabled(enabled bool)
 	UnsafeSaveAuthRevision(rev uint64)
+	UnsafeSaveAuthBcryptCost(cost int)
 	UnsafePutUser(*authpb.User)
 	UnsafeDeleteUser(string)
 	UnsafePutRole(*authpb.Role)
 	UnsafeDeleteRole(string)
+	UnsafePutTokenRevocation(TokenRevocation)
+	UnsafeDeleteTokenRevocation(TokenRevocation)
 }
 
 type AuthReadTx interface {
 	UnsafeReadAuthEnabled() bool
 	UnsafeReadAuthRevision() uint64
+	UnsafeReadAuthBcryptCost() int
 	UnsafeGetUser(string) *authpb.User
 	UnsafeGetRole(string) *authpb.Role
 	UnsafeGetAllUsers() []*authpb.User
 	UnsafeGetAllRoles() []*authpb.Role
+	UnsafeGetAllTokenRevocations() []TokenRevocation
 	Lock()
 	Unlock()
 }
@@ -243,9 +313,25 @@ type authStore struct {
 	// see also: https://github.com/etcd-io/etcd/pull/13920#discussion_r849114855
 	rangePermCache   map[string]*unifiedRangePermissions // username -> unifiedRangePermissions
 	rangePermCacheMu sync.RWMutex
+	// hasQuotas is true if a user has a quota, protected by rangePermCacheMu
+	hasQuotas bool
+
+	// sanRoleRules grant roles to the clients by the SANs of their
+	// certificates, set before serving the requests
+	sanRoleRules []SANRoleRule
+	// sanRolesCache holds the roles granted by sanRoleRules, protected by
+	// rangePermCacheMu
+	sanRolesCache map[string]*authpb.Role
+	// tokenRolesPermCache holds the permissions of the users holding the
+	// roles granted by their tokens, by user name and roles, protected by
+	// rangePermCacheMu
+	tokenRolesPermCache map[string]*unifiedRangePermissions
 
 	tokenProvider TokenProvider
 	bcryptCost    int // the algorithm cost / strength for hashing auth passwords
+	// clusterBcryptCost is the minimum bcrypt cost set on the cluster,
+	// accessed atomically
+	clusterBcryptCost int32
 }
 
 func (as *authStore) AuthEnable() error {
@@ -386,7 +472,9 @@ func (as *authStore) Recover(be AuthBackend) {
 
 	enabled := tx.UnsafeReadAuthEnabled()
 	as.setRevision(tx.UnsafeReadAuthRevision())
+	atomic.StoreInt32(&as.clusterBcryptCost, int32(tx.UnsafeReadAuthBcryptCost()))
 	as.refreshRangePermCache(tx)
+	as.tokenProvider.recoverRevocations(unexpiredTokenRevocations(tx))
 
 	tx.Unlock()
 
@@ -401,7 +489,7 @@ func (as *authStore) Recover(be AuthBackend) {
 func (as *authStore) selectPassword(password string, hashedPassword string) ([]byte, error) {
 	if password != "" && hashedPassword == "" {
 		// This path is for processing log entries created by etcd whose version is older than 3.5
-		return bcrypt.GenerateFromPassword([]byte(password), as.bcryptCost)
+		return bcrypt.GenerateFromPassword([]byte(password), as.BcryptCost())
 	}
 	return base64.StdEncoding.DecodeString(hashedPassword)
 }
@@ -531,7 +619,7 @@ func (as *authStore) UserGrantRole(r *pb.AuthUserGrantRoleRequest) (*pb.AuthUser
 		return nil, ErrUserNotFound
 	}
 
-	if r.Role != rootRole {
+	if r.Role != rootRole && r.Role != maintenanceReadOnlyRole {
 		role := tx.UnsafeGetRole(r.Role)
 		if role == nil {
 			return nil, ErrRoleNotFound
@@ -650,6 +738,10 @@ func (as *authStore) RoleGet(r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse,
 	} else {
 		resp.Perm = append(resp.Perm, role.KeyPermission...)
 	}
+	resp.MaxLeaseTTL = role.MaxLeaseTTL
+	resp.MaxRequestsPerSecond = role.MaxRequestsPerSecond
+	resp.MaxWatchStreams = role.MaxWatchStreams
+	resp.MaxTxnOps = role.MaxTxnOps
 	return &resp, nil
 }
 
@@ -674,7 +766,11 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 	}
 
 	updatedRole := &authpb.Role{
-		Name: role.Name,
+		Name:                 role.Name,
+		MaxLeaseTTL:          role.MaxLeaseTTL,
+		MaxRequestsPerSecond: role.MaxRequestsPerSecond,
+		MaxWatchStreams:      role.MaxWatchStreams,
+		MaxTxnOps:            role.MaxTxnOps,
 	}
 
 	for _, perm := range role.KeyPermission {
@@ -747,10 +843,82 @@ func (as *authStore) RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDelete
 	return &pb.AuthRoleDeleteResponse{}, nil
 }
 
+func (as *authStore) RoleSetMaxLeaseTTL(r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	if r.MaxLeaseTTL < 0 {
+		return nil, ErrInvalidAuthMgmt
+	}
+
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	role := tx.UnsafeGetRole(r.Role)
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	if r.Role == rootRole {
+		as.lg.Error("cannot limit the lease TTL of 'root' role", zap.String("role-name", r.Role))
+		return nil, ErrInvalidAuthMgmt
+	}
+
+	role.MaxLeaseTTL = r.MaxLeaseTTL
+	tx.UnsafePutRole(role)
+
+	as.commitRevision(tx)
+
+	as.lg.Info(
+		"set the maximum lease TTL of a role",
+		zap.String("role-name", r.Role),
+		zap.Int64("max-lease-ttl", r.MaxLeaseTTL),
+	)
+	return &pb.AuthRoleSetMaxLeaseTTLResponse{}, nil
+}
+
+func (as *authStore) RoleSetQuota(r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error) {
+	if r.MaxRequestsPerSecond < 0 || r.MaxWatchStreams < 0 || r.MaxTxnOps < 0 {
+		return nil, ErrInvalidAuthMgmt
+	}
+
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	role := tx.UnsafeGetRole(r.Role)
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	if r.Role == rootRole {
+		as.lg.Error("cannot set the quota of 'root' role", zap.String("role-name", r.Role))
+		return nil, ErrInvalidAuthMgmt
+	}
+
+	role.MaxRequestsPerSecond = r.MaxRequestsPerSecond
+	role.MaxWatchStreams = r.MaxWatchStreams
+	role.MaxTxnOps = r.MaxTxnOps
+	tx.UnsafePutRole(role)
+
+	as.commitRevision(tx)
+	as.refreshRangePermCache(tx)
+
+	as.lg.Info(
+		"set the quota of a role",
+		zap.String("role-name", r.Role),
+		zap.Int64("max-requests-per-second", r.MaxRequestsPerSecond),
+		zap.Int64("max-watch-streams", r.MaxWatchStreams),
+		zap.Int64("max-txn-ops", r.MaxTxnOps),
+	)
+	return &pb.AuthRoleSetQuotaResponse{}, nil
+}
+
 func (as *authStore) RoleAdd(r *pb.AuthRoleAddRequest) (*pb.AuthRoleAddResponse, error) {
 	if len(r.Name) == 0 {
 		return nil, ErrRoleEmpty
 	}
+	// the predefined role exists without being added; adding it would allow
+	// granting it permissions on the keys.
+	if r.Name == maintenanceReadOnlyRole {
+		return nil, ErrRoleAlreadyExist
+	}
 
 	tx := as.be.BatchTx()
 	tx.Lock()
@@ -795,6 +963,19 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	if r.Perm == nil {
 		return nil, ErrPermissionNotGiven
 	}
+	if r.Name == maintenanceReadOnlyRole {
+		as.lg.Error("cannot grant permissions to 'maintenance-readonly' role", zap.String("role-name", r.Name))
+		return nil, ErrInvalidAuthMgmt
+	}
+	if !isValidPermTemplate(r.Perm) {
+		as.lg.Error(
+			"cannot grant a permission template whose {user} variable is not followed by '/'",
+			zap.String("role-name", r.Name),
+			zap.ByteString("key", r.Perm.Key),
+			zap.ByteString("range-end", r.Perm.RangeEnd),
+		)
+		return nil, ErrInvalidPermTemplate
+	}
 
 	tx := as.be.BatchTx()
 	tx.Lock()
@@ -839,21 +1020,21 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	return &pb.AuthRoleGrantPermissionResponse{}, nil
 }
 
-func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeEnd []byte, permTyp authpb.Permission_Type) error {
+func (as *authStore) isOpPermitted(authInfo *AuthInfo, key, rangeEnd []byte, permTyp authpb.Permission_Type) error {
 	// TODO(mitake): this function would be costly so we need a caching mechanism
 	if !as.IsAuthEnabled() {
 		return nil
 	}
 
 	// only gets rev == 0 when passed AuthInfo{}; no user given
-	if revision == 0 {
+	if authInfo.Revision == 0 {
 		return ErrUserEmpty
 	}
 	rev := as.Revision()
-	if revision < rev {
+	if authInfo.Revision < rev {
 		as.lg.Warn("request auth revision is less than current node auth revision",
 			zap.Uint64("current node auth revision", rev),
-			zap.Uint64("request auth revision", revision),
+			zap.Uint64("request auth revision", authInfo.Revision),
 			zap.ByteString("request key", key),
 			zap.Error(ErrAuthOldRevision))
 		return ErrAuthOldRevision
@@ -863,9 +1044,9 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 	tx.Lock()
 	defer tx.Unlock()
 
-	user := tx.UnsafeGetUser(userName)
+	user := as.unsafeGetAuthUser(tx, authInfo)
 	if user == nil {
-		as.lg.Error("cannot find a user for permission check", zap.String("user-name", userName))
+		as.lg.Error("cannot find a user for permission check", zap.String("user-name", authInfo.Username))
 		return ErrPermissionDenied
 	}
 
@@ -874,7 +1055,7 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 		return nil
 	}
 
-	if as.isRangeOpPermitted(userName, key, rangeEnd, permTyp) {
+	if as.isRangeOpPermitted(tx, authInfo, user, key, rangeEnd, permTyp) {
 		return nil
 	}
 
@@ -882,15 +1063,23 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 }
 
 func (as *authStore) IsPutPermitted(authInfo *AuthInfo, key []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, nil, authpb.WRITE)
+	return as.isOpPermitted(authInfo, key, nil, authpb.WRITE)
 }
 
 func (as *authStore) IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.READ)
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.READ)
+}
+
+func (as *authStore) IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.WATCH)
+}
+
+func (as *authStore) IsCountPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.COUNT)
 }
 
 func (as *authStore) IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.WRITE)
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.WRITE)
 }
 
 func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
@@ -904,7 +1093,7 @@ func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
 	tx := as.be.ReadTx()
 	tx.Lock()
 	defer tx.Unlock()
-	u := tx.UnsafeGetUser(authInfo.Username)
+	u := as.unsafeGetAuthUser(tx, authInfo)
 
 	if u == nil {
 		return ErrUserNotFound
@@ -917,6 +1106,85 @@ func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
 	return nil
 }
 
+// IsMaintenanceReadPermitted checks that the user has the root role or the
+// predefined maintenance-readonly role, which permits calling the read-only
+// maintenance RPCs like Status and HashKV.
+func (as *authStore) IsMaintenanceReadPermitted(authInfo *AuthInfo) error {
+	if !as.IsAuthEnabled() {
+		return nil
+	}
+	if authInfo == nil || authInfo.Username == "" {
+		return ErrUserEmpty
+	}
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	u := as.unsafeGetAuthUser(tx, authInfo)
+
+	if u == nil {
+		return ErrUserNotFound
+	}
+
+	if !hasRootRole(u) && !hasRole(u, maintenanceReadOnlyRole) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// CheckReservedRoles returns an error if a role was added with the name of
+// the predefined maintenance-readonly role before the role was predefined, so
+// that its users do not silently gain the maintenance permissions. The role
+// must be deleted with the previous version of etcd before upgrading.
+func (as *authStore) CheckReservedRoles() error {
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	if tx.UnsafeGetRole(maintenanceReadOnlyRole) != nil {
+		return fmt.Errorf("%w: %q", ErrReservedRoleExists, maintenanceReadOnlyRole)
+	}
+	return nil
+}
+
+// IsLeaseGrantPermitted checks the TTL against the largest maximum lease TTL
+// of the roles of the user. A role without a maximum lease TTL, like the root
+// role, allows any TTL. The leases granted without a user are not limited.
+func (as *authStore) IsLeaseGrantPermitted(authInfo *AuthInfo, ttl int64) error {
+	if !as.IsAuthEnabled() || authInfo == nil || authInfo.Username == "" {
+		return nil
+	}
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	u := as.unsafeGetAuthUser(tx, authInfo)
+	if u == nil {
+		return ErrUserNotFound
+	}
+	if hasRootRole(u) {
+		return nil
+	}
+
+	var maxTTL int64
+	for _, roleName := range u.Roles {
+		role := tx.UnsafeGetRole(roleName)
+		if role == nil {
+			continue
+		}
+		if role.MaxLeaseTTL == 0 {
+			return nil
+		}
+		if role.MaxLeaseTTL > maxTTL {
+			maxTTL = role.MaxLeaseTTL
+		}
+	}
+	if maxTTL != 0 && ttl > maxTTL {
+		return ErrRoleLeaseTTLTooLarge
+	}
+	return nil
+}
+
 func (as *authStore) IsAuthEnabled() bool {
 	as.enabledMu.RLock()
 	defer as.enabledMu.RUnlock()
@@ -955,6 +1223,10 @@ func NewAuthStore(lg *zap.Logger, be AuthBackend, tp TokenProvider, bcryptCost i
 		tokenProvider:  tp,
 		bcryptCost:     bcryptCost,
 	}
+	as.clusterBcryptCost = int32(tx.UnsafeReadAuthBcryptCost())
+	if tp != nil {
+		tp.recoverRevocations(unexpiredTokenRevocations(tx))
+	}
 
 	if enabled {
 		as.tokenProvider.enable()
@@ -974,10 +1246,42 @@ func NewAuthStore(lg *zap.Logger, be AuthBackend, tp TokenProvider, bcryptCost i
 	return as
 }
 
+// unsafeGetAuthUser returns the user of the auth info holding the roles
+// granted by its token too, nil if the user does not exist and the token
+// grants no role.
+func (as *authStore) unsafeGetAuthUser(tx AuthReadTx, authInfo *AuthInfo) *authpb.User {
+	u := as.unsafeGetUser(tx, authInfo.Username)
+	if len(authInfo.Roles) == 0 {
+		return u
+	}
+	roles := append([]string(nil), authInfo.Roles...)
+	if u != nil {
+		roles = append(roles, u.Roles...)
+	}
+	// the roles of the users are sorted, see hasRootRole()
+	sort.Strings(roles)
+	j := 0
+	for i := range roles {
+		if i == 0 || roles[i] != roles[j-1] {
+			roles[j] = roles[i]
+			j++
+		}
+	}
+	return &authpb.User{
+		Name:    []byte(authInfo.Username),
+		Roles:   roles[:j],
+		Options: &authpb.UserAddOptions{NoPassword: true},
+	}
+}
+
 func hasRootRole(u *authpb.User) bool {
+	return hasRole(u, rootRole)
+}
+
+func hasRole(u *authpb.User, role string) bool {
 	// u.Roles is sorted in UserGrantRole(), so we can use binary search.
-	idx := sort.SearchStrings(u.Roles, rootRole)
-	return idx != len(u.Roles) && u.Roles[idx] == rootRole
+	idx := sort.SearchStrings(u.Roles, role)
+	return idx != len(u.Roles) && u.Roles[idx] == role
 }
 
 func (as *authStore) commitRevision(tx AuthBatchTx) {
@@ -1008,6 +1312,11 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 			Username: chains[0].Subject.CommonName,
 			Revision: as.Revision(),
 		}
+		// the SAN role rules authenticate the clients as the users named
+		// after their SANs, unless the common name is a user
+		if name := as.sanUser(chains[0]); name != "" && as.be.GetUser(ai.Username) == nil {
+			ai.Username = name
+		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			return nil
@@ -1123,6 +1432,9 @@ func NewTokenProvider(
 	case tokenTypeJWT:
 		return newTokenProviderJWT(lg, typeSpecificOpts)
 
+	case tokenTypeOIDC:
+		return newTokenProviderOIDC(lg, typeSpecificOpts)
+
 	case "":
 		return newTokenProviderNop()
 
@@ -1181,7 +1493,7 @@ func (as *authStore) WithRoot(ctx context.Context) context.Context {
 func (as *authStore) HasRole(user, role string) bool {
 	tx := as.be.BatchTx()
 	tx.Lock()
-	u := tx.UnsafeGetUser(user)
+	u := as.unsafeGetUser(tx, user)
 	tx.Unlock()
 
 	if u == nil {
@@ -1201,10 +1513,77 @@ func (as *authStore) HasRole(user, role string) bool {
 	return false
 }
 
+// BcryptCost returns the cost of hashing the passwords, the highest of the
+// cost of the member and the cost set on the cluster.
 func (as *authStore) BcryptCost() int {
+	if cost := int(atomic.LoadInt32(&as.clusterBcryptCost)); cost > as.bcryptCost {
+		return cost
+	}
 	return as.bcryptCost
 }
 
+func (as *authStore) SetBcryptCost(r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error) {
+	if int(r.Cost) < bcrypt.MinCost || int(r.Cost) > bcrypt.MaxCost {
+		as.lg.Error(
+			"invalid bcrypt cost",
+			zap.Int32("cost", r.Cost),
+			zap.Int("min-cost", bcrypt.MinCost),
+			zap.Int("max-cost", bcrypt.MaxCost),
+		)
+		return nil, ErrInvalidAuthMgmt
+	}
+
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	tx.UnsafeSaveAuthBcryptCost(int(r.Cost))
+	atomic.StoreInt32(&as.clusterBcryptCost, r.Cost)
+
+	as.lg.Info("set the bcrypt cost of the passwords", zap.Int32("cost", r.Cost))
+	return &pb.AuthSetBcryptCostResponse{}, nil
+}
+
+func (as *authStore) NeedsRehash(username string) bool {
+	tx := as.be.ReadTx()
+	tx.Lock()
+	user := tx.UnsafeGetUser(username)
+	tx.Unlock()
+	if user == nil || (user.Options != nil && user.Options.NoPassword) {
+		return false
+	}
+	cost, err := bcrypt.Cost(user.Password)
+	return err == nil && cost < as.BcryptCost()
+}
+
+// RehashPassword replaces the password hash of the user by the same password
+// hashed with a higher cost. The auth revision is kept, as the password is
+// unchanged, so the rehash is dropped if the password changed since it was
+// checked.
+func (as *authStore) RehashPassword(username, hashedPassword string, revision uint64) {
+	password, err := base64.StdEncoding.DecodeString(hashedPassword)
+	if err != nil {
+		as.lg.Warn("failed to decode the rehashed password", zap.String("user-name", username), zap.Error(err))
+		return
+	}
+
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	if tx.UnsafeReadAuthRevision() != revision {
+		return
+	}
+	user := tx.UnsafeGetUser(username)
+	if user == nil || (user.Options != nil && user.Options.NoPassword) {
+		return
+	}
+	user.Password = password
+	tx.UnsafePutUser(user)
+
+	as.lg.Info("rehashed the password of a user", zap.String("user-name", username))
+}
+
 func (as *authStore) setupMetricsReporter() {
 	reportCurrentAuthRevMu.Lock()
 	reportCurrentAuthRev = func() float64 {
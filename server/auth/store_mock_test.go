@@ -17,16 +17,21 @@ package auth
 import "go.etcd.io/etcd/api/v3/authpb"
 
 type backendMock struct {
-	users    map[string]*authpb.User
-	roles    map[string]*authpb.Role
-	enabled  bool
-	revision uint64
+	users      map[string]*authpb.User
+	roles      map[string]*authpb.Role
+	enabled    bool
+	revision   uint64
+	bcryptCost int
+
+	tokenRevocations map[TokenRevocation]struct{}
 }
 
 func newBackendMock() *backendMock {
 	return &backendMock{
 		users: make(map[string]*authpb.User),
 		roles: make(map[string]*authpb.Role),
+
+		tokenRevocations: make(map[TokenRevocation]struct{}),
 	}
 }
 
@@ -76,6 +81,10 @@ func (t txMock) UnsafeReadAuthRevision() uint64 {
 	return t.be.revision
 }
 
+func (t txMock) UnsafeReadAuthBcryptCost() int {
+	return t.be.bcryptCost
+}
+
 func (t txMock) UnsafeGetUser(s string) *authpb.User {
 	return t.be.users[s]
 }
@@ -100,6 +109,14 @@ func (t txMock) UnsafeGetAllRoles() []*authpb.Role {
 	return roles
 }
 
+func (t txMock) UnsafeGetAllTokenRevocations() []TokenRevocation {
+	var rs []TokenRevocation
+	for r := range t.be.tokenRevocations {
+		rs = append(rs, r)
+	}
+	return rs
+}
+
 func (t txMock) Lock() {
 }
 
@@ -114,6 +131,10 @@ func (t txMock) UnsafeSaveAuthRevision(rev uint64) {
 	t.be.revision = rev
 }
 
+func (t txMock) UnsafeSaveAuthBcryptCost(cost int) {
+	t.be.bcryptCost = cost
+}
+
 func (t txMock) UnsafePutUser(user *authpb.User) {
 	t.be.users[string(user.Name)] = user
 }
@@ -129,3 +150,11 @@ func (t txMock) UnsafePutRole(role *authpb.Role) {
 func (t txMock) UnsafeDeleteRole(s string) {
 	delete(t.be.roles, s)
 }
+
+func (t txMock) UnsafePutTokenRevocation(r TokenRevocation) {
+	t.be.tokenRevocations[r] = struct{}{}
+}
+
+func (t txMock) UnsafeDeleteTokenRevocation(r TokenRevocation) {
+	delete(t.be.tokenRevocations, r)
+}
@@ -17,6 +17,7 @@ package auth
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -231,6 +232,174 @@ func TestCheckPassword(t *testing.T) {
 	}
 }
 
+func TestSetBcryptCost(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if as.NeedsRehash("foo") {
+		t.Fatal("expected no rehash before raising the bcrypt cost")
+	}
+	if _, err := as.SetBcryptCost(&pb.AuthSetBcryptCostRequest{Cost: int32(bcrypt.MinCost - 1)}); err != ErrInvalidAuthMgmt {
+		t.Fatalf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+	if _, err := as.SetBcryptCost(&pb.AuthSetBcryptCostRequest{Cost: int32(bcrypt.MinCost + 1)}); err != nil {
+		t.Fatal(err)
+	}
+	if as.BcryptCost() != bcrypt.MinCost+1 {
+		t.Fatalf("expected bcrypt cost %d, got %d", bcrypt.MinCost+1, as.BcryptCost())
+	}
+	if !as.NeedsRehash("foo") {
+		t.Fatal("expected a rehash after raising the bcrypt cost")
+	}
+	if as.NeedsRehash("foo-test") {
+		t.Fatal("expected no rehash of a non-existing user")
+	}
+
+	// the cost of the cluster is kept by the backend
+	tp, err := NewTokenProvider(zaptest.NewLogger(t), tokenTypeSimple, dummyIndexWaiter, simpleTokenTTLDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as2 := NewAuthStore(zaptest.NewLogger(t), as.be, tp, bcrypt.MinCost)
+	defer as2.Close()
+	if as2.BcryptCost() != bcrypt.MinCost+1 {
+		t.Fatalf("expected bcrypt cost %d, got %d", bcrypt.MinCost+1, as2.BcryptCost())
+	}
+}
+
+func TestRehashPassword(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if _, err := as.SetBcryptCost(&pb.AuthSetBcryptCostRequest{Cost: int32(bcrypt.MinCost + 1)}); err != nil {
+		t.Fatal(err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("bar"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev := as.Revision()
+
+	// the password changed since it was checked
+	as.RehashPassword("foo", base64.StdEncoding.EncodeToString(hashedPassword), rev-1)
+	if !as.NeedsRehash("foo") {
+		t.Fatal("expected the rehash of a stale revision to be dropped")
+	}
+
+	as.RehashPassword("foo", base64.StdEncoding.EncodeToString(hashedPassword), rev)
+	if as.NeedsRehash("foo") {
+		t.Fatal("expected the password to be rehashed")
+	}
+	if as.Revision() != rev {
+		t.Fatalf("expected revision %d, got %d", rev, as.Revision())
+	}
+	if _, err = as.CheckPassword("foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTokenListRevoke(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	ctx := context.WithValue(context.WithValue(context.TODO(), AuthenticateParamIndex{}, uint64(1)), AuthenticateParamSimpleTokenPrefix{}, "dummy")
+	resp, err := as.Authenticate(ctx, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lresp, err := as.TokenList(&pb.AuthTokenListRequest{User: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Tokens) != 1 || lresp.Tokens[0].Id != "1" {
+		t.Fatalf("unexpected tokens %v", lresp.Tokens)
+	}
+
+	if _, err = as.TokenRevoke(context.TODO(), &pb.AuthTokenRevokeRequest{}); err != ErrInvalidAuthMgmt {
+		t.Fatalf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+	rresp, err := as.TokenRevoke(context.TODO(), &pb.AuthTokenRevokeRequest{User: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rresp.Revoked != 1 {
+		t.Fatalf("expected 1 token revoked, got %d", rresp.Revoked)
+	}
+	if _, err = as.AuthInfoFromCtx(metadata.NewIncomingContext(context.TODO(), metadata.New(map[string]string{rpctypes.TokenFieldNameGRPC: resp.Token}))); err != ErrInvalidAuthToken {
+		t.Fatalf("expected %v, got %v", ErrInvalidAuthToken, err)
+	}
+
+	as.AuthDisable()
+	if _, err = as.TokenList(&pb.AuthTokenListRequest{}); err != ErrAuthNotEnabled {
+		t.Fatalf("expected %v, got %v", ErrAuthNotEnabled, err)
+	}
+}
+
+// TestTokenRevokeRecoverJWT ensures that the JWT revocations are kept in the
+// backend and recovered by a new auth store, so that the revoked tokens stay
+// invalid after a restart.
+func TestTokenRevokeRecoverJWT(t *testing.T) {
+	tp, err := NewTokenProvider(zaptest.NewLogger(t), testJWTOpts(), dummyIndexWaiter, simpleTokenTTLDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	be := newBackendMock()
+	as := NewAuthStore(zaptest.NewLogger(t), be, tp, bcrypt.MinCost)
+	defer as.Close()
+	if err = enableAuthAndCreateRoot(as); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "foo", HashedPassword: encodePassword("bar"), Options: &authpb.UserAddOptions{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var tokens []string
+	for i := uint64(1); i <= 2; i++ {
+		resp, aerr := as.Authenticate(context.WithValue(context.TODO(), AuthenticateParamIndex{}, i), "foo", "bar")
+		if aerr != nil {
+			t.Fatal(aerr)
+		}
+		tokens = append(tokens, resp.Token)
+	}
+	if _, err = as.TokenRevoke(context.TODO(), &pb.AuthTokenRevokeRequest{Id: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tp2, err := NewTokenProvider(zaptest.NewLogger(t), testJWTOpts(), dummyIndexWaiter, simpleTokenTTLDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as2 := NewAuthStore(zaptest.NewLogger(t), be, tp2, bcrypt.MinCost)
+	defer as2.Close()
+	authInfo := func(token string) error {
+		_, aerr := as2.AuthInfoFromCtx(metadata.NewIncomingContext(context.TODO(), metadata.New(map[string]string{rpctypes.TokenFieldNameGRPC: token})))
+		return aerr
+	}
+	if err = authInfo(tokens[0]); err != ErrInvalidAuthToken {
+		t.Fatalf("expected %v for the revoked token, got %v", ErrInvalidAuthToken, err)
+	}
+	if err = authInfo(tokens[1]); err != nil {
+		t.Fatalf("expected the token not revoked to be valid, got %v", err)
+	}
+
+	// the revocations of the user are recovered too
+	if _, err = as2.TokenRevoke(context.WithValue(context.TODO(), AuthenticateParamIndex{}, uint64(3)), &pb.AuthTokenRevokeRequest{User: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	tp3, err := NewTokenProvider(zaptest.NewLogger(t), testJWTOpts(), dummyIndexWaiter, simpleTokenTTLDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a member restoring a snapshot recovers the revocations of the backend
+	as3 := NewAuthStore(zaptest.NewLogger(t), newBackendMock(), tp3, bcrypt.MinCost)
+	defer as3.Close()
+	as3.Recover(be)
+	if _, err = as3.AuthInfoFromCtx(metadata.NewIncomingContext(context.TODO(), metadata.New(map[string]string{rpctypes.TokenFieldNameGRPC: tokens[1]}))); err != ErrInvalidAuthToken {
+		t.Fatalf("expected %v for the token of the revoked user, got %v", ErrInvalidAuthToken, err)
+	}
+}
+
 func TestUserDelete(t *testing.T) {
 	as, tearDown := setupAuthStore(t)
 	defer tearDown(t)
@@ -423,10 +592,196 @@ func TestIsOpPermitted(t *testing.T) {
 
 	// check permission reflected to user
 
-	err = as.isOpPermitted("foo", as.Revision(), perm.Key, perm.RangeEnd, perm.PermType)
+	err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, perm.Key, perm.RangeEnd, perm.PermType)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsOpPermittedWithTokenRoles(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.READWRITE, Key: []byte("/{user}/"), RangeEnd: []byte("/{user}0")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the user needs not exist when the token grants roles
+	ai := &AuthInfo{Username: "oidc:bar", Revision: as.Revision(), Roles: []string{"role-test"}}
+	if err = as.IsPutPermitted(ai, []byte("/oidc:bar/a")); err != nil {
+		t.Errorf("expected access granted by the token roles, got %v", err)
+	}
+	if err = as.IsPutPermitted(ai, []byte("/foo/a")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsPutPermitted(&AuthInfo{Username: "oidc:bar", Revision: as.Revision()}, []byte("/oidc:bar/a")); err != ErrPermissionDenied {
+		t.Errorf("expected %v without the token roles, got %v", ErrPermissionDenied, err)
+	}
+	// the token roles add to the roles of an existing user
+	if err = as.IsPutPermitted(&AuthInfo{Username: "foo", Revision: as.Revision(), Roles: []string{"role-test"}}, []byte("/foo/a")); err != nil {
+		t.Errorf("expected access granted by the token roles, got %v", err)
+	}
+	if err = as.IsAdminPermitted(&AuthInfo{Username: "oidc:bar", Revision: as.Revision(), Roles: []string{"role-test", "root"}}); err != nil {
+		t.Errorf("expected the root role granted by the token to be admin, got %v", err)
+	}
+	if err = as.IsAdminPermitted(ai); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+}
+
+func TestIsOpPermittedWithPermTemplate(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: "self-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "self-service",
+		Perm: &authpb.Permission{
+			PermType: authpb.READWRITE,
+			Key:      []byte("/users/{user}/"),
+			RangeEnd: []byte("/users/{user}0"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "self-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("/users/foo/a"), nil, authpb.WRITE); err != nil {
+		t.Errorf("expected access to own prefix, got %v", err)
+	}
+	if err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("/users/foo/"), []byte("/users/foo0"), authpb.READ); err != nil {
+		t.Errorf("expected range access to own prefix, got %v", err)
+	}
+	if err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("/users/bar/a"), nil, authpb.READ); err != ErrPermissionDenied {
+		t.Errorf("expected %v for another user's prefix, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("/users/{user}/a"), nil, authpb.READ); err != ErrPermissionDenied {
+		t.Errorf("expected %v for the literal template key, got %v", ErrPermissionDenied, err)
+	}
+}
+
+func TestIsOpPermittedWithPermTemplateWithoutSeparator(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: "self-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, perm := range []*authpb.Permission{
+		{PermType: authpb.READ, Key: []byte("/users/{user}"), RangeEnd: []byte("/users/{user~")},
+		{PermType: authpb.READ, Key: []byte("/users/{user}"), RangeEnd: []byte{0}},
+		{PermType: authpb.READ, Key: []byte("/a/{user}"), RangeEnd: []byte("/b/{user}")},
+	} {
+		_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "self-service", Perm: perm})
+		if err != ErrInvalidPermTemplate {
+			t.Errorf("granting [%q, %q): expected %v, got %v", perm.Key, perm.RangeEnd, ErrInvalidPermTemplate, err)
+		}
+	}
+
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "al", Options: &authpb.UserAddOptions{NoPassword: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "al", Role: "self-service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a template stored without validation grants nothing.
+	tx := as.be.BatchTx()
+	tx.Lock()
+	role := tx.UnsafeGetRole("self-service")
+	role.KeyPermission = append(role.KeyPermission, &authpb.Permission{
+		PermType: authpb.READ,
+		Key:      []byte("/users/{user}"),
+		RangeEnd: []byte("/users/{user~"),
+	})
+	tx.UnsafePutRole(role)
+	as.commitRevision(tx)
+	as.refreshRangePermCache(tx)
+	tx.Unlock()
+
+	ai := &AuthInfo{Username: "al", Revision: as.Revision()}
+	if err = as.isOpPermitted(ai, []byte("/users/alice"), nil, authpb.READ); err != ErrPermissionDenied {
+		t.Errorf("expected %v for the keys of the user alice, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.isOpPermitted(ai, []byte("/users/al"), nil, authpb.READ); err != ErrPermissionDenied {
+		t.Errorf("expected %v for the invalid template, got %v", ErrPermissionDenied, err)
+	}
+
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "self-service",
+		Perm: &authpb.Permission{PermType: authpb.READ, Key: []byte("/users/{user}/"), RangeEnd: []byte("/users/{user}0")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai.Revision = as.Revision()
+	if err = as.isOpPermitted(ai, []byte("/users/al/a"), nil, authpb.READ); err != nil {
+		t.Errorf("expected access to own prefix, got %v", err)
+	}
+	if err = as.isOpPermitted(ai, []byte("/users/alice/a"), nil, authpb.READ); err != ErrPermissionDenied {
+		t.Errorf("expected %v for the keys of the user alice, got %v", ErrPermissionDenied, err)
+	}
+}
+
+func TestIsOpPermittedWatchAndCount(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: "role-test-1"})
 	if err != nil {
 		t.Fatal(err)
 	}
+	for _, perm := range []*authpb.Permission{
+		{PermType: authpb.WATCH, Key: []byte("w"), RangeEnd: []byte("x")},
+		{PermType: authpb.COUNT, Key: []byte("c"), RangeEnd: []byte("d")},
+		{PermType: authpb.READ, Key: []byte("r"), RangeEnd: []byte("s")},
+	} {
+		_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test-1", Perm: perm})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key      string
+		permType authpb.Permission_Type
+		want     error
+	}{
+		{"w1", authpb.WATCH, nil},
+		{"w1", authpb.COUNT, ErrPermissionDenied},
+		{"w1", authpb.READ, ErrPermissionDenied},
+		{"c1", authpb.COUNT, nil},
+		{"c1", authpb.WATCH, ErrPermissionDenied},
+		{"c1", authpb.READ, ErrPermissionDenied},
+		// reading the keys allows watching and counting them
+		{"r1", authpb.READ, nil},
+		{"r1", authpb.WATCH, nil},
+		{"r1", authpb.COUNT, nil},
+		{"r1", authpb.WRITE, ErrPermissionDenied},
+	}
+	for i, tt := range tests {
+		if err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte(tt.key), nil, tt.permType); err != tt.want {
+			t.Errorf("#%d: %s on %q: expected %v, got %v", i, tt.permType, tt.key, tt.want, err)
+		}
+	}
 }
 
 func TestGetUser(t *testing.T) {
@@ -605,6 +960,185 @@ func TestRoleRevokePermission(t *testing.T) {
 	}
 }
 
+func TestRoleSetMaxLeaseTTL(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if _, err := as.RoleSetMaxLeaseTTL(&pb.AuthRoleSetMaxLeaseTTLRequest{Role: "role-test", MaxLeaseTTL: 60}); err != nil {
+		t.Fatal(err)
+	}
+	// revoking a permission keeps the maximum lease TTL
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.WRITE, Key: []byte("foo")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", Key: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.MaxLeaseTTL != 60 {
+		t.Errorf("expected %v, got %v", 60, r.MaxLeaseTTL)
+	}
+
+	if _, err = as.RoleSetMaxLeaseTTL(&pb.AuthRoleSetMaxLeaseTTLRequest{Role: "role-test-1", MaxLeaseTTL: 60}); err != ErrRoleNotFound {
+		t.Errorf("expected %v, got %v", ErrRoleNotFound, err)
+	}
+	if _, err = as.RoleSetMaxLeaseTTL(&pb.AuthRoleSetMaxLeaseTTLRequest{Role: "role-test", MaxLeaseTTL: -1}); err != ErrInvalidAuthMgmt {
+		t.Errorf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+	if _, err = as.RoleSetMaxLeaseTTL(&pb.AuthRoleSetMaxLeaseTTLRequest{Role: "root", MaxLeaseTTL: 60}); err != ErrInvalidAuthMgmt {
+		t.Errorf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+}
+
+func TestIsLeaseGrantPermitted(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	for _, role := range []struct {
+		name   string
+		maxTTL int64
+	}{{"role-test", 60}, {"role-test-1", 600}, {"role-test-2", 0}} {
+		if role.name != "role-test" {
+			if _, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: role.name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := as.RoleSetMaxLeaseTTL(&pb.AuthRoleSetMaxLeaseTTLRequest{Role: role.name, MaxLeaseTTL: role.maxTTL}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	grant := func(role string) {
+		if _, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(ttl int64, werr error) {
+		t.Helper()
+		if err := as.IsLeaseGrantPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, ttl); err != werr {
+			t.Errorf("ttl %d: expected %v, got %v", ttl, werr, err)
+		}
+	}
+
+	// the user without roles is not limited
+	check(6000, nil)
+	grant("role-test")
+	check(60, nil)
+	check(61, ErrRoleLeaseTTLTooLarge)
+	// the largest maximum lease TTL of the roles applies
+	grant("role-test-1")
+	check(600, nil)
+	check(601, ErrRoleLeaseTTLTooLarge)
+	// a role without a maximum lease TTL lifts the limit
+	grant("role-test-2")
+	check(6000, nil)
+
+	if err := as.IsLeaseGrantPermitted(&AuthInfo{Username: "root", Revision: as.Revision()}, 6000); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+	if err := as.IsLeaseGrantPermitted(&AuthInfo{}, 6000); err != nil {
+		t.Errorf("expected %v, got %v", nil, err)
+	}
+}
+
+func TestRoleSetQuota(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if as.HasQuotas() {
+		t.Fatal("expected no quotas")
+	}
+	_, err := as.RoleSetQuota(&pb.AuthRoleSetQuotaRequest{Role: "role-test", MaxRequestsPerSecond: 10, MaxWatchStreams: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// revoking a permission keeps the quota
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.WRITE, Key: []byte("foo")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", Key: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.MaxRequestsPerSecond != 10 || r.MaxWatchStreams != 2 || r.MaxTxnOps != 0 {
+		t.Errorf("unexpected quota %+v", r)
+	}
+	// the role has no user yet
+	if as.HasQuotas() {
+		t.Fatal("expected no quotas")
+	}
+
+	if _, err = as.RoleSetQuota(&pb.AuthRoleSetQuotaRequest{Role: "role-test-1", MaxTxnOps: 1}); err != ErrRoleNotFound {
+		t.Errorf("expected %v, got %v", ErrRoleNotFound, err)
+	}
+	if _, err = as.RoleSetQuota(&pb.AuthRoleSetQuotaRequest{Role: "role-test", MaxTxnOps: -1}); err != ErrInvalidAuthMgmt {
+		t.Errorf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+	if _, err = as.RoleSetQuota(&pb.AuthRoleSetQuotaRequest{Role: "root", MaxTxnOps: 1}); err != ErrInvalidAuthMgmt {
+		t.Errorf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+}
+
+func TestUserQuota(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	for _, role := range []*pb.AuthRoleSetQuotaRequest{
+		{Role: "role-test", MaxRequestsPerSecond: 10, MaxWatchStreams: 2, MaxTxnOps: 8},
+		{Role: "role-test-1", MaxRequestsPerSecond: 100, MaxWatchStreams: 1},
+	} {
+		if role.Role != "role-test" {
+			if _, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: role.Role}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := as.RoleSetQuota(role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	grant := func(role string) {
+		if _, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(want Quota) {
+		t.Helper()
+		if q := as.UserQuota(&AuthInfo{Username: "foo", Revision: as.Revision()}); q != want {
+			t.Errorf("expected %+v, got %+v", want, q)
+		}
+	}
+
+	check(Quota{})
+	grant("role-test")
+	if !as.HasQuotas() {
+		t.Fatal("expected quotas")
+	}
+	check(Quota{MaxRequestsPerSecond: 10, MaxWatchStreams: 2, MaxTxnOps: 8})
+	// the most permissive quota of the roles applies, a zero limit lifting it
+	grant("role-test-1")
+	check(Quota{MaxRequestsPerSecond: 100, MaxWatchStreams: 2})
+
+	if q := as.UserQuota(&AuthInfo{Username: "root", Revision: as.Revision()}); q != (Quota{}) {
+		t.Errorf("expected no quota for root, got %+v", q)
+	}
+	if q := as.UserQuota(&AuthInfo{}); q != (Quota{}) {
+		t.Errorf("expected no quota without a user, got %+v", q)
+	}
+}
+
 func TestUserRevokePermission(t *testing.T) {
 	as, tearDown := setupAuthStore(t)
 	defer tearDown(t)
@@ -834,6 +1368,62 @@ func TestIsAdminPermitted(t *testing.T) {
 	}
 }
 
+func TestIsMaintenanceReadPermitted(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if err := as.IsMaintenanceReadPermitted(&AuthInfo{Username: "root", Revision: 1}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := as.IsMaintenanceReadPermitted(&AuthInfo{Username: "foo", Revision: 1}); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+
+	// the predefined role is granted without being added
+	if _, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: maintenanceReadOnlyRole}); err != nil {
+		t.Fatal(err)
+	}
+	if err := as.IsMaintenanceReadPermitted(&AuthInfo{Username: "foo", Revision: 1}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := as.IsAdminPermitted(&AuthInfo{Username: "foo", Revision: 1}); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err := as.IsRangePermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("foo"), nil); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+
+	// the predefined role is reserved and not granted permissions on the keys
+	if _, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: maintenanceReadOnlyRole}); err != ErrRoleAlreadyExist {
+		t.Errorf("expected %v, got %v", ErrRoleAlreadyExist, err)
+	}
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: maintenanceReadOnlyRole,
+		Perm: &authpb.Permission{PermType: authpb.READ, Key: []byte("foo")},
+	})
+	if err != ErrInvalidAuthMgmt {
+		t.Errorf("expected %v, got %v", ErrInvalidAuthMgmt, err)
+	}
+}
+
+func TestCheckReservedRoles(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	if err := as.CheckReservedRoles(); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+
+	// a role added with the name before it was predefined
+	tx := as.be.BatchTx()
+	tx.Lock()
+	tx.UnsafePutRole(&authpb.Role{Name: []byte(maintenanceReadOnlyRole)})
+	tx.Unlock()
+	if err := as.CheckReservedRoles(); !errors.Is(err, ErrReservedRoleExists) {
+		t.Errorf("expected %v, got %v", ErrReservedRoleExists, err)
+	}
+}
+
 func TestRecoverFromSnapshot(t *testing.T) {
 	as, teardown := setupAuthStore(t)
 	defer teardown(t)
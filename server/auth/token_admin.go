@@ -0,0 +1,135 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// TokenRevocation revokes the token of the ID, or, if the ID is empty, all the
+// tokens of the user issued up to the index, until the revoked tokens expire.
+type TokenRevocation struct {
+	ID      string
+	User    string
+	Index   uint64
+	Expires time.Time
+}
+
+// AuthenticateParamPeerAddress is used for a key of context in the parameters of Authenticate()
+type AuthenticateParamPeerAddress struct{}
+
+// tokenIssue is the issue of a token tracked by its token provider, for
+// listing and revoking it.
+type tokenIssue struct {
+	// id is the index of the authenticate request issuing the token, the
+	// same on all the members, "" for the tokens issued outside of the apply
+	// of an authenticate request, like the tokens of WithRoot(), which are
+	// not listed.
+	id          string
+	username    string
+	issued      time.Time
+	peerAddress string
+}
+
+// newTokenIssue returns the issue of a token to the user, with the index and
+// the peer address of the context.
+func newTokenIssue(ctx context.Context, username string) tokenIssue {
+	ti := tokenIssue{username: username, issued: time.Now()}
+	if index, ok := ctx.Value(AuthenticateParamIndex{}).(uint64); ok && index > 0 {
+		ti.id = strconv.FormatUint(index, 10)
+	}
+	ti.peerAddress, _ = ctx.Value(AuthenticateParamPeerAddress{}).(string)
+	return ti
+}
+
+// token returns the listed token of the issue, expiring at the given time.
+func (ti tokenIssue) token(expires time.Time) *pb.AuthToken {
+	return &pb.AuthToken{
+		Id:          ti.id,
+		User:        ti.username,
+		IssueTime:   ti.issued.Unix(),
+		TTL:         int64(time.Until(expires).Round(time.Second) / time.Second),
+		PeerAddress: ti.peerAddress,
+	}
+}
+
+// TokenList lists the valid tokens issued by the member. The tokens are
+// identified on all the members by the index of the authenticate request
+// issuing them. The tokens minted by an identity provider are not listed.
+func (as *authStore) TokenList(r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error) {
+	if !as.IsAuthEnabled() {
+		return nil, ErrAuthNotEnabled
+	}
+	tokens := as.tokenProvider.list(r.User)
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].IssueTime != tokens[j].IssueTime {
+			return tokens[i].IssueTime < tokens[j].IssueTime
+		}
+		return tokens[i].Id < tokens[j].Id
+	})
+	return &pb.AuthTokenListResponse{Tokens: tokens}, nil
+}
+
+// TokenRevoke revokes the token of the ID, or all the tokens of the user. The
+// revocations are kept in the backend until the tokens expire, so that the
+// revoked tokens stay invalid after a restart.
+func (as *authStore) TokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error) {
+	if !as.IsAuthEnabled() {
+		return nil, ErrAuthNotEnabled
+	}
+	if r.Id == "" && r.User == "" {
+		return nil, ErrInvalidAuthMgmt
+	}
+	revoked := as.tokenProvider.revoke(ctx, r.Id, r.User)
+
+	// the expired revocations are dropped from the backend
+	tx := as.be.BatchTx()
+	tx.Lock()
+	for _, rv := range tx.UnsafeGetAllTokenRevocations() {
+		tx.UnsafeDeleteTokenRevocation(rv)
+	}
+	for _, rv := range as.tokenProvider.revocations() {
+		tx.UnsafePutTokenRevocation(rv)
+	}
+	tx.Unlock()
+
+	as.lg.Info(
+		"revoked tokens",
+		zap.String("token-id", r.Id),
+		zap.String("user-name", r.User),
+		zap.Int("revoked", revoked),
+	)
+	return &pb.AuthTokenRevokeResponse{Revoked: int64(revoked)}, nil
+}
+
+// unexpiredTokenRevocations returns the revocations of the backend whose
+// revoked tokens have not expired.
+func unexpiredTokenRevocations(tx AuthReadTx) []TokenRevocation {
+	now := time.Now()
+	var rs []TokenRevocation
+	for _, rv := range tx.UnsafeGetAllTokenRevocations() {
+		if now.Before(rv.Expires) {
+			rs = append(rs, rv)
+		}
+	}
+	return rs
+}
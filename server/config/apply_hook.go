@@ -0,0 +1,36 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// ApplyHook is called by every member on applying the v3 requests of the
+// raft log, in the order of the log, for example to account for the writes
+// of the users or to reject the requests invalid to the application. The
+// requests rejected by the permissions of the users or by the quotas are
+// seen too.
+type ApplyHook interface {
+	// PreApply is called before applying the request, rejecting it with the
+	// error returned if any. The members must take the same decision, so it
+	// must only depend on the request and on the requests applied before.
+	PreApply(r *pb.InternalRaftRequest) error
+	// PostApply is called after applying the request with its response or
+	// error, which must not be modified.
+	PostApply(r *pb.InternalRaftRequest, resp proto.Message, err error)
+}
@@ -27,9 +27,13 @@ import (
 
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
 	"go.etcd.io/etcd/pkg/v3/netutil"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	bolt "go.etcd.io/bbolt"
 )
@@ -126,8 +130,52 @@ type ServerConfig struct {
 	QuotaBackendBytes       int64
 	MaxTxnOps               uint
 
+	// CompactionBatchTargetDuration paces the compaction batches, between
+	// CompactionMinBatchLimit and CompactionBatchLimit revisions, for the
+	// time each holds the backend to approach it. Zero disables the pacing.
+	CompactionBatchTargetDuration time.Duration
+	CompactionMinBatchLimit       int
+
+	// MaxQuotaBackendBytes is the hard cap up to which QuotaBackendBytes grows
+	// when the backend size in use approaches it. Disabled if not greater than
+	// the quota.
+	MaxQuotaBackendBytes int64
+
+	// AutoCompactionMaxRevisions is the maximum number of revisions kept
+	// by the hybrid auto compaction mode.
+	AutoCompactionMaxRevisions int64
+	// CompactionRevisionAlignment rounds the auto compaction revisions
+	// down to a multiple of it, if positive.
+	CompactionRevisionAlignment int64
+	// VersionRetention keeps the last versions of the keys with the given
+	// prefixes through the compactions.
+	VersionRetention []mvcc.VersionRetention
+
+	// SecondaryIndexes are the secondary indexes maintained on the JSON
+	// values of keys.
+	SecondaryIndexes []mvcc.SecondaryIndex
+
+	// HotKeyTracking enables the estimation of the read, write and watch
+	// traffic per key prefix, reported by the HotKeys RPC and metrics.
+	HotKeyTracking bool
+
+	// PrefixStats enables the statistics of the keys per prefix reported by
+	// the PrefixStats RPC.
+	PrefixStats bool
+
+	// ValueCompression compresses the stored key-value records whose value
+	// is at least ValueCompressionThreshold bytes, once the whole cluster
+	// supports reading them, if the ValueCompression feature is enabled.
+	ValueCompression          mvcc.ValueCompression
+	ValueCompressionThreshold int
+
 	// MaxRequestBytes is the maximum request size to send over raft.
 	MaxRequestBytes uint
+	// MaxChunkedValueBytes is the maximum size of the values put in parts,
+	// each part within MaxRequestBytes, their key-value records being split
+	// into chunks of MaxRequestBytes, if the ValueChunking feature is
+	// enabled. 0 disables it.
+	MaxChunkedValueBytes uint
 
 	// MaxConcurrentStreams specifies the maximum number of concurrent
 	// streams that each client can open at a time.
@@ -140,10 +188,16 @@ type ServerConfig struct {
 
 	// ClientCertAuthEnabled is true when cert has been signed by the client CA.
 	ClientCertAuthEnabled bool
+	// ClientCertSANRoleRules grant roles to the clients authenticated by
+	// the SANs of their certificates.
+	ClientCertSANRoleRules []auth.SANRoleRule
 
 	AuthToken  string
 	BcryptCost uint
 	TokenTTL   uint
+	// PasswordPolicy is the policy the passwords of the users must satisfy
+	// when they are added or changed.
+	PasswordPolicy auth.PasswordPolicy
 
 	// InitialCorruptCheck is true to check data corruption on boot
 	// before serving any peer/client traffic.
@@ -152,6 +206,15 @@ type ServerConfig struct {
 	CompactHashCheckEnabled bool
 	CompactHashCheckTime    time.Duration
 
+	// CorruptCheckSchedule is the cron schedule of the corruption check
+	// passes, used in place of CorruptCheckTime if set.
+	CorruptCheckSchedule string
+	// CorruptCheckScope is "full" to compare the hashes of the whole key
+	// spaces, or "sampled" to only compare the hashes of CorruptCheckSamples
+	// windows of revisions picked at random.
+	CorruptCheckScope   string
+	CorruptCheckSamples int
+
 	// PreVote is true to enable Raft Pre-Vote.
 	PreVote bool
 
@@ -161,6 +224,19 @@ type ServerConfig struct {
 	// Logger logs server-side operations.
 	Logger *zap.Logger
 
+	// AuditLogger records the requests of the clients, if set.
+	AuditLogger *zap.Logger
+	// AuditLogIncludePrefixes and AuditLogExcludePrefixes select the
+	// requests recorded by the audit logger by the keys they touch.
+	AuditLogIncludePrefixes []string
+	AuditLogExcludePrefixes []string
+
+	// Authorizer makes the final decision on the requests on keys of the
+	// users permitted by their roles, if set.
+	Authorizer *v3authz.Checker
+	// ApplyHooks are called, in order, on applying the requests.
+	ApplyHooks []ApplyHook
+
 	ForceNewCluster bool
 
 	// EnableLeaseCheckpoint enables leader to send regular checkpoints to other members to prevent reset of remaining TTL on leader change.
@@ -169,6 +245,11 @@ type ServerConfig struct {
 	LeaseCheckpointInterval time.Duration
 	// LeaseCheckpointPersist enables persisting remainingTTL to prevent indefinite auto-renewal of long lived leases. Always enabled in v3.6. Should be used to ensure smooth upgrade from v3.5 clusters with this feature enabled.
 	LeaseCheckpointPersist bool
+	// LeaseCheckpointOnRenew makes leader checkpoint the remaining TTL of a lease on every renewal, besides every LeaseCheckpointInterval.
+	LeaseCheckpointOnRenew bool
+	// MaxLeaseAttachedKeys is the maximum number of keys attached to a single lease,
+	// raising the LEASEKEYS alarm as a lease approaches it. Zero means unlimited.
+	MaxLeaseAttachedKeys int
 
 	EnableGRPCGateway bool
 
@@ -178,6 +259,31 @@ type ServerConfig struct {
 	ExperimentalTracerOptions []otelgrpc.Option
 
 	WatchProgressNotifyInterval time.Duration
+	// WatchMaxStartRevisionLag is the maximum number of revisions a watch start revision
+	// can be behind the current revision. Watch creations exceeding it are rejected.
+	// Zero means no limit.
+	WatchMaxStartRevisionLag int64
+	// WatchMaxStartRevisionAge is the maximum time a watch start revision can be behind
+	// the current revision. Watch creations exceeding it are rejected. Zero means no limit.
+	WatchMaxStartRevisionAge time.Duration
+	// WatchMaxEventsPerSecond is the maximum rate each watcher is sent events at.
+	// Zero means no limit.
+	WatchMaxEventsPerSecond int64
+	// WatchMaxQueuedEvents is the maximum number of events queued for each watcher
+	// waiting for the delivery rate limit before WatchOverflowPolicy applies.
+	WatchMaxQueuedEvents int64
+	// WatchOverflowPolicy is what happens to the watchers exceeding WatchMaxQueuedEvents:
+	// "drop-oldest", "cancel" or "block".
+	WatchOverflowPolicy string
+	// WatchBacklogAlarmEvents is the number of events held in memory for the slow
+	// watchers raising the WATCHBACKLOG alarm. Zero disables the alarm.
+	WatchBacklogAlarmEvents int64
+	// DrainTimeout is the deadline of a drain requested without one, after which
+	// the member stops even if client requests are still in flight.
+	DrainTimeout time.Duration
+	// BoundedStalenessMaxLag is the maximum number of entries the applied index of the
+	// member can lag its commit index for bounded staleness reads to be served locally.
+	BoundedStalenessMaxLag uint64
 
 	// UnsafeNoFsync disables all uses of fsync.
 	// Setting this is unsafe and will cause data loss.
@@ -201,11 +307,34 @@ type ServerConfig struct {
 	// consider running defrag during bootstrap. Needs to be set to non-zero value to take effect.
 	ExperimentalBootstrapDefragThresholdMegabytes uint `json:"experimental-bootstrap-defrag-threshold-megabytes"`
 
+	// ExperimentalIncrementalDefrag defragments the backend in small batches, serving
+	// requests between them, instead of blocking them for the whole defragmentation.
+	ExperimentalIncrementalDefrag bool `json:"experimental-incremental-defrag"`
+	// ExperimentalAutoDefragThreshold is the ratio of the bytes of the backend not
+	// in use to its size beyond which the member defragments it. 0 disables it.
+	ExperimentalAutoDefragThreshold float64 `json:"experimental-auto-defrag-threshold"`
+	// ExperimentalAutoDefragSchedule is the cron schedule of the minutes the member
+	// may defragment the backend in, at any time if empty.
+	ExperimentalAutoDefragSchedule string `json:"experimental-auto-defrag-schedule"`
+	// ExperimentalBackendEngine is the storage engine of the backend.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
+
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalLearnerAutoPromoteLag is the maximum number of raft entries a
+	// learner may lag behind the leader by to be promoted automatically.
+	ExperimentalLearnerAutoPromoteLag uint64 `json:"experimental-learner-auto-promote-lag"`
+	// ExperimentalLearnerAutoPromoteDuration is the time a learner has to stay
+	// within ExperimentalLearnerAutoPromoteLag for to be promoted automatically
+	// by the leader. 0 disables the automatic promotion.
+	ExperimentalLearnerAutoPromoteDuration time.Duration `json:"experimental-learner-auto-promote-duration"`
 
 	// V2Deprecation defines a phase of v2store deprecation process.
 	V2Deprecation V2DeprecationEnum `json:"v2-deprecation"`
+
+	// ServerFeatureGate tells the features enabled on the server, reported to
+	// the clients and the other members. The default features are enabled if nil.
+	ServerFeatureGate featuregate.FeatureGate
 }
 
 // VerifyBootstrap sanity-checks the initial config for bootstrap case
@@ -0,0 +1,179 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/tap"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+)
+
+const (
+	// ClientAuthModeToken authenticates the clients by their passwords and
+	// the tokens of the Auth service.
+	ClientAuthModeToken = "token"
+	// ClientAuthModeCert authenticates the clients by the common names of
+	// their verified certificates.
+	ClientAuthModeCert = "cert"
+)
+
+// ClientListenerGroup is a group of client listeners served with their own
+// TLS config and authentication modes, for example a listener requiring
+// client certificates for the control plane next to a plaintext localhost
+// listener for the sidecars.
+type ClientListenerGroup struct {
+	Name             string
+	ListenClientUrls []url.URL
+	// TLSInfo secures the https and unixs listeners of the group, instead of
+	// ClientTLSInfo.
+	TLSInfo transport.TLSInfo
+	// AuthModes are the authentication modes allowed on the listeners,
+	// ClientAuthModeToken and ClientAuthModeCert, all of them if empty.
+	AuthModes []string
+}
+
+// clientListenerGroupJSON is a ClientListenerGroup of the config file.
+type clientListenerGroupJSON struct {
+	Name             string         `json:"name"`
+	ListenClientUrls string         `json:"listen-client-urls"`
+	Security         securityConfig `json:"client-transport-security"`
+	AuthModes        []string       `json:"auth-modes"`
+}
+
+func (g clientListenerGroupJSON) clientListenerGroup() (ClientListenerGroup, error) {
+	urls, err := types.NewURLs(strings.Split(g.ListenClientUrls, ","))
+	if err != nil {
+		return ClientListenerGroup{}, fmt.Errorf("invalid listen-client-urls of client listener group %q: %v", g.Name, err)
+	}
+	if g.Security.AutoTLS {
+		return ClientListenerGroup{}, fmt.Errorf("auto-tls is not supported by client listener group %q", g.Name)
+	}
+	cg := ClientListenerGroup{Name: g.Name, ListenClientUrls: urls, AuthModes: g.AuthModes}
+	copySecurityDetails(&cg.TLSInfo, &g.Security)
+	return cg, nil
+}
+
+func (g *ClientListenerGroup) validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("client listener group without name")
+	}
+	if len(g.ListenClientUrls) == 0 {
+		return fmt.Errorf("client listener group %q without listen-client-urls", g.Name)
+	}
+	if err := checkBindURLs(g.ListenClientUrls); err != nil {
+		return fmt.Errorf("invalid listen-client-urls of client listener group %q: %v", g.Name, err)
+	}
+	for _, u := range g.ListenClientUrls {
+		if (u.Scheme == "https" || u.Scheme == "unixs") && g.TLSInfo.Empty() {
+			return fmt.Errorf("TLS key/cert must be provided for client url %s of client listener group %q with HTTPS scheme", u.String(), g.Name)
+		}
+	}
+	for _, m := range g.AuthModes {
+		if m != ClientAuthModeToken && m != ClientAuthModeCert {
+			return fmt.Errorf("unknown auth mode %q of client listener group %q, expected %q or %q", m, g.Name, ClientAuthModeToken, ClientAuthModeCert)
+		}
+	}
+	return nil
+}
+
+// authModes returns the authentication modes of the group.
+func (g *ClientListenerGroup) authModes() etcdserver.ClientAuthModes {
+	if len(g.AuthModes) == 0 {
+		return etcdserver.ClientAuthModes{Token: true, Cert: true}
+	}
+	var modes etcdserver.ClientAuthModes
+	for _, m := range g.AuthModes {
+		switch m {
+		case ClientAuthModeToken:
+			modes.Token = true
+		case ClientAuthModeCert:
+			modes.Cert = true
+		}
+	}
+	return modes
+}
+
+// validateClientListenerGroups checks that the groups have distinct names
+// and listen on addresses of their own.
+func (cfg *Config) validateClientListenerGroups() error {
+	names := make(map[string]struct{})
+	hosts := make(map[string]string)
+	for _, u := range cfg.LCUrls {
+		hosts[u.Host+u.Path] = "listen-client-urls"
+	}
+	for i := range cfg.ExperimentalClientListenerGroups {
+		g := &cfg.ExperimentalClientListenerGroups[i]
+		if err := g.validate(); err != nil {
+			return err
+		}
+		if _, ok := names[g.Name]; ok {
+			return fmt.Errorf("duplicate client listener group %q", g.Name)
+		}
+		names[g.Name] = struct{}{}
+		for _, u := range g.ListenClientUrls {
+			if other, ok := hosts[u.Host+u.Path]; ok {
+				return fmt.Errorf("client url %s of client listener group %q is already listened on by %s", u.String(), g.Name, other)
+			}
+			hosts[u.Host+u.Path] = fmt.Sprintf("client listener group %q", g.Name)
+		}
+	}
+	return nil
+}
+
+// clientCertAuthEnabled returns true if a client listener verifies the
+// client certificates for the authentication of the clients.
+func (cfg *Config) clientCertAuthEnabled() bool {
+	if cfg.ClientTLSInfo.ClientCertAuth {
+		return true
+	}
+	for i := range cfg.ExperimentalClientListenerGroups {
+		g := &cfg.ExperimentalClientListenerGroups[i]
+		if g.TLSInfo.ClientCertAuth && g.authModes().Cert {
+			return true
+		}
+	}
+	return false
+}
+
+// authModesServerOption returns the option restricting the authentication
+// of the gRPC requests served over HTTP/2 without TLS to the modes of the
+// listener, those served over TLS being restricted by authModesHandler.
+func (sctx *serveCtx) authModesServerOption() grpc.ServerOption {
+	modes := *sctx.authModes
+	return grpc.InTapHandle(func(ctx context.Context, _ *tap.Info) (context.Context, error) {
+		return etcdserver.WithClientAuthModes(ctx, modes), nil
+	})
+}
+
+// authModesHandler restricts the authentication of the requests of h to the
+// modes of the listener, if any.
+func (sctx *serveCtx) authModesHandler(h http.Handler) http.Handler {
+	if sctx.authModes == nil {
+		return h
+	}
+	modes := *sctx.authModes
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(etcdserver.WithClientAuthModes(r.Context(), modes)))
+	})
+}
@@ -22,7 +22,6 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -34,13 +33,23 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
 	"go.etcd.io/etcd/pkg/v3/flags"
 	"go.etcd.io/etcd/pkg/v3/netutil"
+	"go.etcd.io/etcd/pkg/v3/schedule"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
+	"go.etcd.io/etcd/server/v3/features"
+	"go.etcd.io/etcd/server/v3/storage"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -68,6 +77,14 @@ const (
 	DefaultGRPCKeepAliveTimeout        = 20 * time.Second
 	DefaultDowngradeCheckTime          = 5 * time.Second
 	DefaultWaitClusterReadyTimeout     = 5 * time.Second
+	DefaultBoundedStalenessMaxLag      = uint64(1000)
+	DefaultLearnerAutoPromoteLag       = uint64(1000)
+	DefaultWatchMaxQueuedEvents        = int64(1000)
+	DefaultValueCompressionThreshold   = 1024
+	DefaultCorruptCheckSamples         = 8
+	DefaultCompactionBatchTarget       = 50 * time.Millisecond
+	DefaultAuthzWebhookTimeout         = time.Second
+	DefaultAuthzCacheTTL               = 10 * time.Second
 
 	DefaultDiscoveryDialTimeout      = 2 * time.Second
 	DefaultDiscoveryRequestTimeOut   = 5 * time.Second
@@ -138,6 +155,14 @@ var (
 	// revision 5000 when the current revision is 6000.
 	// This runs every 5-minute if enough of logs have proceeded.
 	CompactorModeRevision = v3compactor.ModeRevision
+
+	// CompactorModeHybrid is hybrid time and revision compaction mode
+	// for "Config.AutoCompactionMode" field.
+	// If "AutoCompactionMode" is CompactorModeHybrid, "AutoCompactionRetention"
+	// is "1h" and "AutoCompactionMaxRevisions" is 10000, it keeps the last
+	// hour of logs, unless it holds more than 10000 revisions: it then
+	// compacts log on revision 5000 when the current revision is 15000.
+	CompactorModeHybrid = v3compactor.ModeHybrid
 )
 
 func init() {
@@ -157,7 +182,9 @@ type Config struct {
 	// We expect the follower has a millisecond level latency with the leader.
 	// The max throughput is around 10K. Keep a 5K entries is enough for helping
 	// follower to catch up.
-	SnapshotCatchUpEntries uint64 `json:"experimental-snapshot-catch-up-entries"`
+	SnapshotCatchUpEntries uint64 `json:"snapshot-catchup-entries"`
+	// ExperimentalSnapshotCatchUpEntries is deprecated, please use SnapshotCatchUpEntries instead.
+	ExperimentalSnapshotCatchUpEntries uint64 `json:"experimental-snapshot-catch-up-entries"`
 
 	MaxSnapFiles uint `json:"max-snapshots"`
 	MaxWalFiles  uint `json:"max-wals"`
@@ -207,6 +234,11 @@ type Config struct {
 	MaxTxnOps           uint   `json:"max-txn-ops"`
 	MaxRequestBytes     uint   `json:"max-request-bytes"`
 
+	// ExperimentalMaxQuotaBackendBytes is the hard cap up to which QuotaBackendBytes grows, with a
+	// QUOTAGROWN warning alarm instead of the NOSPACE alarm, when the backend size in use, which
+	// compaction and defragmentation cannot reclaim, approaches the quota. Zero disables it.
+	ExperimentalMaxQuotaBackendBytes int64 `json:"experimental-max-quota-backend-bytes"`
+
 	// MaxConcurrentStreams specifies the maximum number of concurrent
 	// streams that each client can open at a time.
 	MaxConcurrentStreams uint32 `json:"max-concurrent-streams"`
@@ -217,11 +249,19 @@ type Config struct {
 	ClientAutoTLS  bool
 	PeerTLSInfo    transport.TLSInfo
 	PeerAutoTLS    bool
+	// ExperimentalPeerSkipClientSANVerification is deprecated, please use
+	// PeerTLSInfo.SkipClientSANVerify instead.
+	ExperimentalPeerSkipClientSANVerification bool `json:"experimental-peer-skip-client-san-verification"`
 	// SelfSignedCertValidity specifies the validity period of the client and peer certificates
 	// that are automatically generated by etcd when you specify ClientAutoTLS and PeerAutoTLS,
 	// the unit is year, and the default is 1
 	SelfSignedCertValidity uint `json:"self-signed-cert-validity"`
 
+	// ExperimentalClientListenerGroups are client listeners served with their
+	// own TLS config and authentication modes, in addition to LCUrls served
+	// with ClientTLSInfo and all the authentication modes.
+	ExperimentalClientListenerGroups []ClientListenerGroup `json:"-"`
+
 	// CipherSuites is a list of supported TLS cipher suites between
 	// client/server and peers. If empty, Go auto-populates the list.
 	// Note that cipher suites are prioritized in the given order.
@@ -240,18 +280,24 @@ type Config struct {
 	Durl         string                      `json:"discovery"`
 	DiscoveryCfg v3discovery.DiscoveryConfig `json:"discovery-config"`
 
-	InitialCluster                      string        `json:"initial-cluster"`
-	InitialClusterToken                 string        `json:"initial-cluster-token"`
-	StrictReconfigCheck                 bool          `json:"strict-reconfig-check"`
-	ExperimentalWaitClusterReadyTimeout time.Duration `json:"wait-cluster-ready-timeout"`
+	InitialCluster      string `json:"initial-cluster"`
+	InitialClusterToken string `json:"initial-cluster-token"`
+	StrictReconfigCheck bool   `json:"strict-reconfig-check"`
+	// WaitClusterReadyTimeout is the maximum time to wait for the cluster to be ready.
+	WaitClusterReadyTimeout time.Duration `json:"wait-cluster-ready-timeout"`
+	// ExperimentalWaitClusterReadyTimeout is deprecated, please use WaitClusterReadyTimeout instead.
+	ExperimentalWaitClusterReadyTimeout time.Duration `json:"experimental-wait-cluster-ready-timeout"`
 
-	// AutoCompactionMode is either 'periodic' or 'revision'.
+	// AutoCompactionMode is either 'periodic', 'revision' or 'hybrid'.
 	AutoCompactionMode string `json:"auto-compaction-mode"`
 	// AutoCompactionRetention is either duration string with time unit
 	// (e.g. '5m' for 5-minute), or revision unit (e.g. '5000').
-	// If no time unit is provided and compaction mode is 'periodic',
-	// the unit defaults to hour. For example, '5' translates into 5-hour.
+	// If no time unit is provided and compaction mode is 'periodic' or
+	// 'hybrid', the unit defaults to hour. For example, '5' translates into 5-hour.
 	AutoCompactionRetention string `json:"auto-compaction-retention"`
+	// AutoCompactionMaxRevisions is the maximum number of revisions kept
+	// in 'hybrid' compaction mode, even if they are within the retention time.
+	AutoCompactionMaxRevisions int64 `json:"auto-compaction-max-revisions"`
 
 	// GRPCKeepAliveMinTime is the minimum interval that a client should
 	// wait before pinging server. When client pings "too fast", server
@@ -270,6 +316,27 @@ type Config struct {
 
 	// SocketOpts are socket options passed to listener config.
 	SocketOpts transport.SocketOpts `json:"socket-options"`
+	// ExperimentalSocketActivation serves the listening sockets passed by
+	// systemd (LISTEN_FDS) on the listen client and peer URLs they are bound
+	// to rather than binding them, so that etcd can be socket activated and
+	// restarted without closing its ports. The URLs without a passed socket
+	// are bound as usual, and a passed socket matching no URL is an error.
+	ExperimentalSocketActivation bool `json:"experimental-socket-activation"`
+	// ExperimentalClientMaxConnections is the maximum number of concurrent connections of
+	// each client listener, beyond which the new connections are rejected. Zero means no
+	// limit other than the file descriptor limit.
+	ExperimentalClientMaxConnections int `json:"experimental-client-max-connections"`
+	// ExperimentalClientAcceptRate is the maximum number of connections each client listener
+	// accepts per second, with a burst of one second of them, beyond which the new connections
+	// are rejected. It protects the member from the connection storms of a large client fleet
+	// reconnecting at once. Zero means no limit.
+	ExperimentalClientAcceptRate float64 `json:"experimental-client-accept-rate"`
+	// ExperimentalPeerMaxConnections is the maximum number of concurrent connections of each
+	// peer listener, beyond which the new connections are rejected. Zero means no limit.
+	ExperimentalPeerMaxConnections int `json:"experimental-peer-max-connections"`
+	// ExperimentalPeerAcceptRate is the maximum number of connections each peer listener
+	// accepts per second, beyond which the new connections are rejected. Zero means no limit.
+	ExperimentalPeerAcceptRate float64 `json:"experimental-peer-accept-rate"`
 
 	// PreVote is true to enable Raft Pre-Vote.
 	// If enabled, Raft runs an additional election phase
@@ -317,7 +384,20 @@ type Config struct {
 	//		pb.RegisterBarServer(s, &barServer{})
 	//	}
 	//	embed.StartEtcd(cfg)
+	// The services are served on the client listeners, like etcd's own
+	// services, and their handlers can get the etcd user authenticated for
+	// each request with AuthInfoFromContext.
 	ServiceRegister func(*grpc.Server) `json:"-"`
+	// ExperimentalUnaryInterceptors and ExperimentalStreamInterceptors are
+	// chained, in order, after the interceptors of etcd on the gRPC servers
+	// of the client listeners, for the services of etcd and of
+	// ServiceRegister alike. The etcd user of a request is returned by
+	// Etcd.Server.AuthInfoFromCtx.
+	ExperimentalUnaryInterceptors  []grpc.UnaryServerInterceptor  `json:"-"`
+	ExperimentalStreamInterceptors []grpc.StreamServerInterceptor `json:"-"`
+	// ExperimentalApplyHooks are called, in order, by the member on applying
+	// the requests of the raft log.
+	ExperimentalApplyHooks []config.ApplyHook `json:"-"`
 
 	AuthToken  string `json:"auth-token"`
 	BcryptCost uint   `json:"bcrypt-cost"`
@@ -325,10 +405,52 @@ type Config struct {
 	// AuthTokenTTL in seconds of the simple token
 	AuthTokenTTL uint `json:"auth-token-ttl"`
 
-	ExperimentalInitialCorruptCheck     bool          `json:"experimental-initial-corrupt-check"`
+	// ExperimentalClientCertSANRoleRules grant roles to the clients authenticated by their certificates
+	// with a subject alternative name matching a pattern, each given as "<uri|dns>:<pattern>=<role>", for
+	// example "uri:spiffe://example.org/ns/prod/sa/*=prod-reader". The clients are authenticated as the
+	// users named after their first matching SAN, like "uri:spiffe://example.org/ns/prod/sa/api", which
+	// need not exist, unless the common name of their certificate is a user. The rules must be the same
+	// on all the members.
+	ExperimentalClientCertSANRoleRules []string `json:"experimental-client-cert-san-role-rules"`
+
+	// ExperimentalPasswordMinLength is the minimum number of characters of the passwords of the users
+	// added or changed on this member. 0 means no minimum.
+	ExperimentalPasswordMinLength int `json:"experimental-password-min-length"`
+	// ExperimentalPasswordMinCharClasses is the minimum number of the classes of characters, out of
+	// lowercase letters, uppercase letters, digits and the other characters, of the passwords of the
+	// users added or changed on this member. 0 means no minimum.
+	ExperimentalPasswordMinCharClasses int `json:"experimental-password-min-char-classes"`
+
+	// ServerFeatureGate turns the alpha and beta features on and off, e.g. with
+	// "feature-gates: InitialCorruptCheck=true,LeaseCheckpoint=true" in the config file. The
+	// deprecated experimental flags turning the features on and off keep setting them, conflict
+	// with a different value of their feature gate, and are set from it on Validate. The
+	// experimental flags tuning the features require their feature gate.
+	ServerFeatureGate featuregate.FeatureGate `json:"feature-gates"`
+	// FlagsExplicitlySet records the flags set on the command line and the
+	// keys set in the config file, telling the flags set to their default
+	// apart from the flags left unset.
+	FlagsExplicitlySet map[string]bool `json:"-"`
+
+	ExperimentalInitialCorruptCheck bool `json:"experimental-initial-corrupt-check"`
+	// CorruptCheckTime is the duration of time between the cluster corruption check passes.
+	CorruptCheckTime time.Duration `json:"corrupt-check-time"`
+	// ExperimentalCorruptCheckTime is deprecated, please use CorruptCheckTime instead.
 	ExperimentalCorruptCheckTime        time.Duration `json:"experimental-corrupt-check-time"`
 	ExperimentalCompactHashCheckEnabled bool          `json:"experimental-compact-hash-check-enabled"`
-	ExperimentalCompactHashCheckTime    time.Duration `json:"experimental-compact-hash-check-time"`
+	// CompactHashCheckTime is the duration of time between the leader checks of the compaction
+	// hashes of the followers.
+	CompactHashCheckTime time.Duration `json:"compact-hash-check-time"`
+	// ExperimentalCompactHashCheckTime is deprecated, please use CompactHashCheckTime instead.
+	ExperimentalCompactHashCheckTime time.Duration `json:"experimental-compact-hash-check-time"`
+	// ExperimentalCorruptCheckSchedule is the cron schedule of the corruption
+	// check passes, overriding ExperimentalCorruptCheckTime if set.
+	ExperimentalCorruptCheckSchedule string `json:"experimental-corrupt-check-schedule"`
+	// ExperimentalCorruptCheckScope is "full" to compare the hashes of the
+	// whole key spaces in the corruption check passes, or "sampled" to only
+	// compare ExperimentalCorruptCheckSamples windows of revisions.
+	ExperimentalCorruptCheckScope   string `json:"experimental-corrupt-check-scope"`
+	ExperimentalCorruptCheckSamples int    `json:"experimental-corrupt-check-samples"`
 
 	// ExperimentalEnableLeaseCheckpoint enables leader to send regular checkpoints to other members to prevent reset of remaining TTL on leader change.
 	ExperimentalEnableLeaseCheckpoint bool `json:"experimental-enable-lease-checkpoint"`
@@ -337,23 +459,158 @@ type Config struct {
 	// Deprecated in v3.6.
 	// TODO: Delete in v3.7
 	ExperimentalEnableLeaseCheckpointPersist bool `json:"experimental-enable-lease-checkpoint-persist"`
-	ExperimentalCompactionBatchLimit         int  `json:"experimental-compaction-batch-limit"`
-	// ExperimentalCompactionSleepInterval is the sleep interval between every etcd compaction loop.
-	ExperimentalCompactionSleepInterval     time.Duration `json:"experimental-compaction-sleep-interval"`
+	// ExperimentalLeaseCheckpointInterval is the wait duration between lease checkpoints.
+	// Requires experimental-enable-lease-checkpoint to be enabled.
+	ExperimentalLeaseCheckpointInterval time.Duration `json:"experimental-lease-checkpoint-interval"`
+	// ExperimentalLeaseCheckpointOnRenew enables leader to checkpoint the remaining TTL of a lease on every renewal,
+	// at the cost of a raft entry per renewal. Requires experimental-enable-lease-checkpoint to be enabled.
+	ExperimentalLeaseCheckpointOnRenew bool `json:"experimental-lease-checkpoint-on-renew"`
+	// ExperimentalMaxLeaseAttachedKeys is the maximum number of keys attached to a single lease.
+	// Puts attaching more keys to a lease are rejected by the member serving them before they
+	// are proposed, so the apply does not depend on it. The leader raises the LEASEKEYS warning
+	// alarm once a lease reaches 90% of it, so that the revoke latency of mega-leases doesn't
+	// come as a surprise. Zero means unlimited.
+	ExperimentalMaxLeaseAttachedKeys int `json:"experimental-max-lease-attached-keys"`
+	// CompactionBatchLimit is the maximum number of revisions deleted in each compaction batch.
+	CompactionBatchLimit int `json:"compaction-batch-limit"`
+	// ExperimentalCompactionBatchLimit is deprecated, please use CompactionBatchLimit instead.
+	ExperimentalCompactionBatchLimit int `json:"experimental-compaction-batch-limit"`
+	// CompactionSleepInterval is the sleep interval between every etcd compaction loop.
+	CompactionSleepInterval time.Duration `json:"compaction-sleep-interval"`
+	// ExperimentalCompactionSleepInterval is deprecated, please use CompactionSleepInterval instead.
+	ExperimentalCompactionSleepInterval time.Duration `json:"experimental-compaction-sleep-interval"`
+	// WatchProgressNotifyInterval is the duration of the periodic watch progress notifications.
+	WatchProgressNotifyInterval time.Duration `json:"watch-progress-notify-interval"`
+	// ExperimentalWatchProgressNotifyInterval is deprecated, please use WatchProgressNotifyInterval instead.
 	ExperimentalWatchProgressNotifyInterval time.Duration `json:"experimental-watch-progress-notify-interval"`
-	// ExperimentalWarningApplyDuration is the time duration after which a warning is generated if applying request
+
+	// ExperimentalCompactionBatchTargetDuration resizes the compaction batches, down to
+	// ExperimentalCompactionMinBatchLimit revisions, for the time each holds the backend,
+	// its commit included, to approach it. Zero disables the pacing.
+	ExperimentalCompactionBatchTargetDuration time.Duration `json:"experimental-compaction-batch-target-duration"`
+	ExperimentalCompactionMinBatchLimit       int           `json:"experimental-compaction-min-batch-limit"`
+
+	// ExperimentalCompactionRevisionAlignment rounds the auto compaction revisions down to a multiple
+	// of it, so that all members and clusters compact at the same revision boundaries. Zero disables it.
+	ExperimentalCompactionRevisionAlignment int64 `json:"experimental-compaction-revision-alignment"`
+	// ExperimentalVersionRetention keeps the last versions of every key with a prefix through the
+	// compactions, whatever the compacted revision, each given as "<prefix>=<versions>", or as
+	// "<versions>" for the whole key space. The most specific prefix applies. The retained versions
+	// are read by the ranges within the prefixes below the compacted revision. It must be the same
+	// on every member: a member joining or restarting refuses to start if another member keeps
+	// other versions.
+	ExperimentalVersionRetention []string `json:"experimental-version-retention"`
+	// ExperimentalWatchMaxStartRevisionLag is the maximum number of revisions a watch start revision
+	// can be behind the current revision. Watch creations exceeding it are rejected so that clients
+	// re-list instead of replaying a deep history. Zero means no limit.
+	ExperimentalWatchMaxStartRevisionLag int64 `json:"experimental-watch-max-start-revision-lag"`
+	// ExperimentalWatchMaxStartRevisionAge is the maximum time a watch start revision can be
+	// behind the current revision, that is the age of the oldest event a watch creation can
+	// replay. Zero means no limit.
+	ExperimentalWatchMaxStartRevisionAge time.Duration `json:"experimental-watch-max-start-revision-age"`
+	// ExperimentalWatchMaxEventsPerSecond is the maximum rate each watcher is sent events at,
+	// so that one slow consumer cannot grow the memory of the member unboundedly. The events
+	// waiting for the rate limit are queued. Zero means no limit.
+	ExperimentalWatchMaxEventsPerSecond int64 `json:"experimental-watch-max-events-per-second"`
+	// ExperimentalWatchMaxQueuedEvents is the maximum number of events queued for each watcher
+	// waiting for the delivery rate limit before ExperimentalWatchOverflowPolicy applies.
+	ExperimentalWatchMaxQueuedEvents int64 `json:"experimental-watch-max-queued-events"`
+	// ExperimentalWatchOverflowPolicy is what happens to the watchers exceeding
+	// ExperimentalWatchMaxQueuedEvents: "drop-oldest" drops their oldest queued events, counted
+	// in their next response, "cancel" cancels them, and "block" blocks their watch stream
+	// until their queue drains.
+	ExperimentalWatchOverflowPolicy string `json:"experimental-watch-overflow-policy"`
+	// ExperimentalWatchBacklogAlarmEvents is the number of events held in memory for the
+	// watchers blocked on their full watch channel raising the WATCHBACKLOG warning alarm of
+	// the member, which is cleared once the backlog halves. Zero disables the alarm.
+	ExperimentalWatchBacklogAlarmEvents int64 `json:"experimental-watch-backlog-alarm-events"`
+	// ExperimentalDrainTimeout is the time the member drains the client requests for on
+	// SIGTERM or SIGINT before stopping: the new streams are rejected, the leadership is
+	// transferred and the requests in flight and the buffered watch events are waited for.
+	// It is also the deadline of the drains requested through the maintenance API without
+	// one. Zero stops the member without draining on the signals.
+	ExperimentalDrainTimeout time.Duration `json:"experimental-drain-timeout"`
+	// ExperimentalBoundedStalenessMaxLag is the maximum number of entries the applied index of
+	// the member can lag the commit index learned from the leader for bounded staleness reads to
+	// be served locally. Bounded staleness reads are linearizable when the member lags more.
+	ExperimentalBoundedStalenessMaxLag uint64 `json:"experimental-bounded-staleness-max-lag"`
+	// ExperimentalChangeFeeds publishes the committed events of key prefixes to sinks, for example
+	// message brokers, while the member is the leader. Events are delivered at least once, from
+	// checkpoints kept in the reserved keys under "\x00changefeed/" and requiring cluster version 3.6.
+	ExperimentalChangeFeeds []v3changefeed.Config `json:"-"`
+	// ExperimentalChangeFeedWebhookURL adds a change feed posting the committed events of
+	// ExperimentalChangeFeedPrefixes, or of the whole key space if none is given, to the URL.
+	ExperimentalChangeFeedWebhookURL string   `json:"experimental-change-feed-webhook-url"`
+	ExperimentalChangeFeedPrefixes   []string `json:"experimental-change-feed-prefixes"`
+	// ExperimentalEnableSnapshotHTTP serves consistent backend snapshots on the '/snapshot' path of the
+	// client URLs, for backup and analytics systems that cannot speak gRPC. When auth is enabled, only
+	// users with the root role can take snapshots.
+	ExperimentalEnableSnapshotHTTP bool `json:"experimental-enable-snapshot-http"`
+	// ExperimentalSecondaryIndexes are the secondary indexes maintained on the JSON values of keys,
+	// each given as "<prefix>=<field>" where field is a dot separated path, for example
+	// "/registry/pods/=spec.nodeName". The indexed keys are looked up with the IndexRange RPC.
+	ExperimentalSecondaryIndexes []string `json:"experimental-secondary-indexes"`
+	// ExperimentalHotKeyTracking enables the estimation of the read, write and watch traffic per key
+	// prefix, to identify the keys causing contention or excessive watch fan-out. The hottest prefixes
+	// are reported by the HotKeys RPC and the etcd_debugging_mvcc_hot_key_prefix_operations metric.
+	ExperimentalHotKeyTracking bool `json:"experimental-hot-key-tracking"`
+	// ExperimentalPrefixStats enables the statistics of the number of keys, their live and historical size and
+	// the number of watchers per key prefix, kept up to date as the writes are applied and reported by the
+	// PrefixStats RPC. They are rebuilt from the whole key space when the member starts.
+	ExperimentalPrefixStats bool `json:"experimental-prefix-stats"`
+	// ExperimentalValueCompression is the algorithm compressing the stored key-value records whose value
+	// is at least ExperimentalValueCompressionThreshold bytes, 'none', 'deflate', 'snappy' or 'zstd'. It
+	// requires the ValueCompression feature gate, to be enabled once all the members run a version
+	// supporting it. Records are only compressed once the cluster version is at least 3.6, and the
+	// storage cannot be downgraded to v3.5 while it holds compressed records.
+	ExperimentalValueCompression          string `json:"experimental-value-compression"`
+	ExperimentalValueCompressionThreshold int    `json:"experimental-value-compression-threshold"`
+	// ExperimentalMaxChunkedValueBytes is the maximum size of the values put in parts, each part
+	// being a put request within MaxRequestBytes. The key-value records larger than MaxRequestBytes
+	// are split into chunks stored in separate backend records. It requires the ValueChunking
+	// feature gate, and the values are only put in parts once all the members enable it. 0 disables
+	// it.
+	ExperimentalMaxChunkedValueBytes uint `json:"experimental-max-chunked-value-bytes"`
+	// WarningApplyDuration is the time duration after which a warning is generated if applying request
 	// takes more time than this value.
+	WarningApplyDuration time.Duration `json:"warning-apply-duration"`
+	// ExperimentalWarningApplyDuration is deprecated, please use WarningApplyDuration instead.
 	ExperimentalWarningApplyDuration time.Duration `json:"experimental-warning-apply-duration"`
-	// ExperimentalBootstrapDefragThresholdMegabytes is the minimum number of megabytes needed to be freed for etcd server to
+	// BootstrapDefragThresholdMegabytes is the minimum number of megabytes needed to be freed for etcd server to
 	// consider running defrag during bootstrap. Needs to be set to non-zero value to take effect.
+	BootstrapDefragThresholdMegabytes uint `json:"bootstrap-defrag-threshold-megabytes"`
+	// ExperimentalBootstrapDefragThresholdMegabytes is deprecated, please use BootstrapDefragThresholdMegabytes instead.
 	ExperimentalBootstrapDefragThresholdMegabytes uint `json:"experimental-bootstrap-defrag-threshold-megabytes"`
+
+	// ExperimentalIncrementalDefrag defragments the backend in small batches, serving
+	// requests between them, instead of blocking them for the whole defragmentation.
+	ExperimentalIncrementalDefrag bool `json:"experimental-incremental-defrag"`
+	// ExperimentalAutoDefragThreshold is the ratio of the bytes of the backend not in use
+	// to its size beyond which the member defragments it, during the off-peak minutes
+	// of ExperimentalAutoDefragSchedule if set. 0 disables it.
+	ExperimentalAutoDefragThreshold float64 `json:"experimental-auto-defrag-threshold"`
+	ExperimentalAutoDefragSchedule  string  `json:"experimental-auto-defrag-schedule"`
+	// ExperimentalBackendEngine is the storage engine of the backend, "bbolt" or "log". The
+	// log engine appends the changes to a log compacted by defragmentation, instead of
+	// rewriting the bbolt pages, and holds the keys in memory. Each engine converts the
+	// backend file of the other on start.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
 	// WarningUnaryRequestDuration is the time duration after which a warning is generated if applying
 	// unary request takes more time than this value.
 	WarningUnaryRequestDuration time.Duration `json:"warning-unary-request-duration"`
 	// ExperimentalWarningUnaryRequestDuration is deprecated, please use WarningUnaryRequestDuration instead.
 	ExperimentalWarningUnaryRequestDuration time.Duration `json:"experimental-warning-unary-request-duration"`
-	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
+	// MaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
+	MaxLearners int `json:"max-learners"`
+	// ExperimentalMaxLearners is deprecated, please use MaxLearners instead.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalLearnerAutoPromoteLag is the maximum number of raft entries a
+	// learner may lag behind the leader by to be promoted automatically.
+	ExperimentalLearnerAutoPromoteLag uint64 `json:"experimental-learner-auto-promote-lag"`
+	// ExperimentalLearnerAutoPromoteDuration is the time a learner has to stay
+	// within ExperimentalLearnerAutoPromoteLag for to be promoted automatically.
+	// 0 disables the automatic promotion. The observers are never promoted.
+	ExperimentalLearnerAutoPromoteDuration time.Duration `json:"experimental-learner-auto-promote-duration"`
 
 	// ForceNewCluster starts a new cluster even if previously started; unsafe.
 	ForceNewCluster bool `json:"force-new-cluster"`
@@ -363,21 +620,34 @@ type Config struct {
 	ListenMetricsUrls     []url.URL
 	ListenMetricsUrlsJSON string `json:"listen-metrics-urls"`
 
-	// ExperimentalEnableDistributedTracing indicates if experimental tracing using OpenTelemetry is enabled.
+	// EnableDistributedTracing indicates if tracing using OpenTelemetry is enabled.
+	EnableDistributedTracing bool `json:"enable-distributed-tracing"`
+	// DistributedTracingAddress is the address of the OpenTelemetry Collector.
+	// Can only be set if EnableDistributedTracing is true.
+	DistributedTracingAddress string `json:"distributed-tracing-address"`
+	// DistributedTracingServiceName is the name of the service.
+	// Can only be used if EnableDistributedTracing is true.
+	DistributedTracingServiceName string `json:"distributed-tracing-service-name"`
+	// DistributedTracingServiceInstanceID is the ID key of the service.
+	// This ID must be unique, as helps to distinguish instances of the same service
+	// that exist at the same time.
+	// Can only be used if EnableDistributedTracing is true.
+	DistributedTracingServiceInstanceID string `json:"distributed-tracing-instance-id"`
+	// DistributedTracingSamplingRatePerMillion is the number of samples to collect per million spans.
+	// Defaults to 0.
+	DistributedTracingSamplingRatePerMillion int `json:"distributed-tracing-sampling-rate"`
+
+	// ExperimentalEnableDistributedTracing is deprecated, please use EnableDistributedTracing instead.
 	ExperimentalEnableDistributedTracing bool `json:"experimental-enable-distributed-tracing"`
-	// ExperimentalDistributedTracingAddress is the address of the OpenTelemetry Collector.
-	// Can only be set if ExperimentalEnableDistributedTracing is true.
+	// ExperimentalDistributedTracingAddress is deprecated, please use DistributedTracingAddress instead.
 	ExperimentalDistributedTracingAddress string `json:"experimental-distributed-tracing-address"`
-	// ExperimentalDistributedTracingServiceName is the name of the service.
-	// Can only be used if ExperimentalEnableDistributedTracing is true.
+	// ExperimentalDistributedTracingServiceName is deprecated, please use DistributedTracingServiceName instead.
 	ExperimentalDistributedTracingServiceName string `json:"experimental-distributed-tracing-service-name"`
-	// ExperimentalDistributedTracingServiceInstanceID is the ID key of the service.
-	// This ID must be unique, as helps to distinguish instances of the same service
-	// that exist at the same time.
-	// Can only be used if ExperimentalEnableDistributedTracing is true.
+	// ExperimentalDistributedTracingServiceInstanceID is deprecated, please use DistributedTracingServiceInstanceID
+	// instead.
 	ExperimentalDistributedTracingServiceInstanceID string `json:"experimental-distributed-tracing-instance-id"`
-	// ExperimentalDistributedTracingSamplingRatePerMillion is the number of samples to collect per million spans.
-	// Defaults to 0.
+	// ExperimentalDistributedTracingSamplingRatePerMillion is deprecated, please use
+	// DistributedTracingSamplingRatePerMillion instead.
 	ExperimentalDistributedTracingSamplingRatePerMillion int `json:"experimental-distributed-tracing-sampling-rate"`
 
 	// Logger is logger options: currently only supports "zap".
@@ -401,11 +671,47 @@ type Config struct {
 	// ZapLoggerBuilder is used to build the zap logger.
 	ZapLoggerBuilder func(*Config) error
 
+	// ExperimentalAuditLogPath is the file the audit log records the requests of the clients to, as JSON
+	// entries holding their authenticated user, method, key ranges, result code and latency. The file is
+	// rotated as configured by ExperimentalAuditLogRotationConfigJSON. Empty disables the audit log.
+	ExperimentalAuditLogPath               string `json:"experimental-audit-log-path"`
+	ExperimentalAuditLogRotationConfigJSON string `json:"experimental-audit-log-rotation-config-json"`
+	// ExperimentalAuditLogIncludePrefixes limits the audit log to the requests touching the keys with
+	// the prefixes, and ExperimentalAuditLogExcludePrefixes leaves out the requests touching only keys
+	// with the prefixes. The requests on no keys are always recorded.
+	ExperimentalAuditLogIncludePrefixes []string `json:"experimental-audit-log-include-prefixes"`
+	ExperimentalAuditLogExcludePrefixes []string `json:"experimental-audit-log-exclude-prefixes"`
+
+	// ExperimentalAuthzWebhookURL is the URL of an external authorizer consulted on the requests on keys,
+	// the watches, the lease keep alives and the snapshots of the users permitted by their roles, posted as JSON entries holding the user, method and key
+	// ranges of the requests, for the final decision. Empty disables the external authorizer.
+	ExperimentalAuthzWebhookURL string `json:"experimental-authz-webhook-url"`
+	// ExperimentalAuthzWebhookTimeout bounds the time the external authorizer takes to decide on a request.
+	ExperimentalAuthzWebhookTimeout time.Duration `json:"experimental-authz-webhook-timeout"`
+	// ExperimentalAuthzWebhookTrustedCAFile verifies the certificate of an HTTPS webhook, instead of the
+	// system CAs, and ExperimentalAuthzWebhookCertFile and ExperimentalAuthzWebhookKeyFile authenticate
+	// the member to it.
+	ExperimentalAuthzWebhookTrustedCAFile string `json:"experimental-authz-webhook-trusted-ca-file"`
+	ExperimentalAuthzWebhookCertFile      string `json:"experimental-authz-webhook-cert-file"`
+	ExperimentalAuthzWebhookKeyFile       string `json:"experimental-authz-webhook-key-file"`
+	// ExperimentalAuthzCacheTTL is the time the decisions of the external authorizer are cached for. Zero
+	// disables the cache.
+	ExperimentalAuthzCacheTTL time.Duration `json:"experimental-authz-cache-ttl"`
+	// ExperimentalAuthzFailOpen allows the requests the external authorizer fails to decide on, which are
+	// denied otherwise.
+	ExperimentalAuthzFailOpen bool `json:"experimental-authz-fail-open"`
+	// ExperimentalAuthorizer is an external authorizer consulted instead of the webhook, for example a
+	// client of a gRPC authorization service.
+	ExperimentalAuthorizer v3authz.Authorizer `json:"-"`
+
 	// logger logs server-side operations. The default is nil,
 	// and "setupLogging" must be called before starting server.
 	// Do not set logger directly.
 	loggerMu *sync.RWMutex
 	logger   *zap.Logger
+	// loggerLevel is the level of the logger built by "setupLogging", nil
+	// for the loggers of ZapLoggerBuilder.
+	loggerLevel *zap.AtomicLevel
 	// EnableGRPCGateway enables grpc gateway.
 	// The gateway translates a RESTful HTTP API into gRPC.
 	EnableGRPCGateway bool `json:"enable-grpc-gateway"`
@@ -414,14 +720,19 @@ type Config struct {
 	// Setting this is unsafe and will cause data loss.
 	UnsafeNoFsync bool `json:"unsafe-no-fsync"`
 
+	// DowngradeCheckTime is the duration of time between two downgrade status checks.
+	DowngradeCheckTime time.Duration `json:"downgrade-check-time"`
+	// ExperimentalDowngradeCheckTime is deprecated, please use DowngradeCheckTime instead.
 	ExperimentalDowngradeCheckTime time.Duration `json:"experimental-downgrade-check-time"`
 
-	// ExperimentalMemoryMlock enables mlocking of etcd owned memory pages.
+	// MemoryMlock enables mlocking of etcd owned memory pages.
 	// The setting improves etcd tail latency in environments were:
 	//   - memory pressure might lead to swapping pages to disk
 	//   - disk latency might be unstable
 	// Currently all etcd memory gets mlocked, but in future the flag can
 	// be refined to mlock in-use area of bbolt only.
+	MemoryMlock bool `json:"memory-mlock"`
+	// ExperimentalMemoryMlock is deprecated, please use MemoryMlock instead.
 	ExperimentalMemoryMlock bool `json:"experimental-memory-mlock"`
 
 	// ExperimentalTxnModeWriteWithSharedBuffer enables write transaction to use a shared buffer in its readonly check operations.
@@ -449,6 +760,8 @@ type configJSON struct {
 
 	ClientSecurityJSON securityConfig `json:"client-transport-security"`
 	PeerSecurityJSON   securityConfig `json:"peer-transport-security"`
+
+	ClientListenerGroupsJSON []clientListenerGroupJSON `json:"experimental-client-listener-groups"`
 }
 
 type securityConfig struct {
@@ -459,6 +772,8 @@ type securityConfig struct {
 	CertAuth       bool   `json:"client-cert-auth"`
 	TrustedCAFile  string `json:"trusted-ca-file"`
 	AutoTLS        bool   `json:"auto-tls"`
+	// SkipClientSANVerify skips the verification of the SAN field of the client certificates.
+	SkipClientSANVerify bool `json:"skip-client-san-verification"`
 }
 
 // NewConfig creates a new Config populated with default values.
@@ -473,13 +788,16 @@ func NewConfig() *Config {
 
 		Name: DefaultName,
 
-		SnapshotCount:          etcdserver.DefaultSnapshotCount,
-		SnapshotCatchUpEntries: etcdserver.DefaultSnapshotCatchUpEntries,
+		SnapshotCount:                      etcdserver.DefaultSnapshotCount,
+		SnapshotCatchUpEntries:             etcdserver.DefaultSnapshotCatchUpEntries,
+		ExperimentalSnapshotCatchUpEntries: etcdserver.DefaultSnapshotCatchUpEntries,
 
 		MaxTxnOps:                        DefaultMaxTxnOps,
 		MaxRequestBytes:                  DefaultMaxRequestBytes,
 		MaxConcurrentStreams:             DefaultMaxConcurrentStreams,
+		WarningApplyDuration:             DefaultWarningApplyDuration,
 		ExperimentalWarningApplyDuration: DefaultWarningApplyDuration,
+		WarningUnaryRequestDuration:      DefaultWarningUnaryRequestDuration,
 
 		GRPCKeepAliveMinTime:  DefaultGRPCKeepAliveMinTime,
 		GRPCKeepAliveInterval: DefaultGRPCKeepAliveInterval,
@@ -501,6 +819,7 @@ func NewConfig() *Config {
 
 		ClusterState:                        ClusterStateFlagNew,
 		InitialClusterToken:                 "etcd-cluster",
+		WaitClusterReadyTimeout:             DefaultWaitClusterReadyTimeout,
 		ExperimentalWaitClusterReadyTimeout: DefaultWaitClusterReadyTimeout,
 
 		StrictReconfigCheck: DefaultStrictReconfigCheck,
@@ -524,16 +843,42 @@ func NewConfig() *Config {
 		LogRotationConfigJSON: DefaultLogRotationConfig,
 		EnableGRPCGateway:     true,
 
+		ExperimentalAuditLogRotationConfigJSON: DefaultLogRotationConfig,
+		ExperimentalAuthzWebhookTimeout:        DefaultAuthzWebhookTimeout,
+		ExperimentalAuthzCacheTTL:              DefaultAuthzCacheTTL,
+
+		DistributedTracingAddress:                 ExperimentalDistributedTracingAddress,
+		DistributedTracingServiceName:             ExperimentalDistributedTracingServiceName,
+		ExperimentalDistributedTracingAddress:     ExperimentalDistributedTracingAddress,
+		ExperimentalDistributedTracingServiceName: ExperimentalDistributedTracingServiceName,
+
+		DowngradeCheckTime:                       DefaultDowngradeCheckTime,
 		ExperimentalDowngradeCheckTime:           DefaultDowngradeCheckTime,
+		MemoryMlock:                              false,
 		ExperimentalMemoryMlock:                  false,
 		ExperimentalTxnModeWriteWithSharedBuffer: true,
+		MaxLearners:                              membership.DefaultMaxLearners,
 		ExperimentalMaxLearners:                  membership.DefaultMaxLearners,
+		ExperimentalBackendEngine:                backend.EngineBolt,
+		ExperimentalValueCompression:             mvcc.ValueCompressionNone.String(),
+		ExperimentalLearnerAutoPromoteLag:        DefaultLearnerAutoPromoteLag,
+		ExperimentalBoundedStalenessMaxLag:       DefaultBoundedStalenessMaxLag,
+		ExperimentalWatchMaxQueuedEvents:         DefaultWatchMaxQueuedEvents,
+		ExperimentalWatchOverflowPolicy:          v3rpc.WatchOverflowBlock,
+		ExperimentalValueCompressionThreshold:    DefaultValueCompressionThreshold,
 
 		ExperimentalCompactHashCheckEnabled: false,
+		CompactHashCheckTime:                time.Minute,
 		ExperimentalCompactHashCheckTime:    time.Minute,
+		ExperimentalCorruptCheckScope:       "full",
+		ExperimentalCorruptCheckSamples:     DefaultCorruptCheckSamples,
+
+		ExperimentalCompactionBatchTargetDuration: DefaultCompactionBatchTarget,
 
 		V2Deprecation: config.V2_DEPR_DEFAULT,
 
+		ServerFeatureGate: features.NewDefaultServerFeatureGate(),
+
 		DiscoveryCfg: v3discovery.DiscoveryConfig{
 			ConfigSpec: clientv3.ConfigSpec{
 				DialTimeout:      DefaultDiscoveryDialTimeout,
@@ -558,8 +903,46 @@ func ConfigFromFile(path string) (*Config, error) {
 	return &cfg.Config, nil
 }
 
+// runtimeConfigFromFile reads the config file at path without validating the
+// settings that cannot be changed at runtime.
+func runtimeConfigFromFile(path string) (*Config, error) {
+	b, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &configYAML{Config: *NewConfig()}
+	if err = yaml.Unmarshal(b, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.FlagsExplicitlySet, err = explicitlySetKeys(b); err != nil {
+		return nil, err
+	}
+	return &cfg.Config, nil
+}
+
+// explicitlySetKeys returns the keys set in the config file b.
+func explicitlySetKeys(b []byte) (map[string]bool, error) {
+	var m map[string]any
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(m))
+	for k := range m {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
 func (cfg *configYAML) configFromFile(path string) error {
-	b, err := os.ReadFile(path)
+	if err := cfg.loadFile(path); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// loadFile reads the config file at path without validating it.
+func (cfg *configYAML) loadFile(path string) error {
+	b, err := readConfigFile(path)
 	if err != nil {
 		return err
 	}
@@ -570,12 +953,14 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if err != nil {
 		return err
 	}
+	if cfg.FlagsExplicitlySet, err = explicitlySetKeys(b); err != nil {
+		return err
+	}
 
 	if cfg.LPUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.LPUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-peer-urls: %v", err)
 		}
 		cfg.LPUrls = u
 	}
@@ -583,8 +968,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.LCUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.LCUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-client-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-client-urls: %v", err)
 		}
 		cfg.LCUrls = u
 	}
@@ -592,8 +976,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.APUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.APUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up initial-advertise-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up initial-advertise-peer-urls: %v", err)
 		}
 		cfg.APUrls = u
 	}
@@ -601,8 +984,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.ACUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.ACUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up advertise-peer-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up advertise-client-urls: %v", err)
 		}
 		cfg.ACUrls = u
 	}
@@ -610,8 +992,7 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.ListenMetricsUrlsJSON != "" {
 		u, err := types.NewURLs(strings.Split(cfg.ListenMetricsUrlsJSON, ","))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "unexpected error setting up listen-metrics-urls: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("unexpected error setting up listen-metrics-urls: %v", err)
 		}
 		cfg.ListenMetricsUrls = u
 	}
@@ -634,14 +1015,6 @@ func (cfg *configYAML) configFromFile(path string) error {
 		cfg.ClusterState = ClusterStateFlagNew
 	}
 
-	copySecurityDetails := func(tls *transport.TLSInfo, ysc *securityConfig) {
-		tls.CertFile = ysc.CertFile
-		tls.KeyFile = ysc.KeyFile
-		tls.ClientCertFile = ysc.ClientCertFile
-		tls.ClientKeyFile = ysc.ClientKeyFile
-		tls.ClientCertAuth = ysc.CertAuth
-		tls.TrustedCAFile = ysc.TrustedCAFile
-	}
 	copySecurityDetails(&cfg.ClientTLSInfo, &cfg.ClientSecurityJSON)
 	copySecurityDetails(&cfg.PeerTLSInfo, &cfg.PeerSecurityJSON)
 	cfg.ClientAutoTLS = cfg.ClientSecurityJSON.AutoTLS
@@ -649,7 +1022,24 @@ func (cfg *configYAML) configFromFile(path string) error {
 	if cfg.SelfSignedCertValidity == 0 {
 		cfg.SelfSignedCertValidity = 1
 	}
-	return cfg.Validate()
+	for _, g := range cfg.ClientListenerGroupsJSON {
+		cg, err := g.clientListenerGroup()
+		if err != nil {
+			return err
+		}
+		cfg.ExperimentalClientListenerGroups = append(cfg.ExperimentalClientListenerGroups, cg)
+	}
+	return nil
+}
+
+func copySecurityDetails(tls *transport.TLSInfo, ysc *securityConfig) {
+	tls.CertFile = ysc.CertFile
+	tls.KeyFile = ysc.KeyFile
+	tls.ClientCertFile = ysc.ClientCertFile
+	tls.ClientKeyFile = ysc.ClientKeyFile
+	tls.ClientCertAuth = ysc.CertAuth
+	tls.TrustedCAFile = ysc.TrustedCAFile
+	tls.SkipClientSANVerify = ysc.SkipClientSANVerify
 }
 
 func updateCipherSuites(tls *transport.TLSInfo, ss []string) error {
@@ -682,12 +1072,21 @@ func (cfg *Config) Validate() error {
 	if err := cfg.setupLogging(); err != nil {
 		return err
 	}
+	if err := cfg.setupFeatureGates(); err != nil {
+		return err
+	}
+	if err := cfg.setupGraduatedFlags(); err != nil {
+		return err
+	}
 	if err := checkBindURLs(cfg.LPUrls); err != nil {
 		return err
 	}
 	if err := checkBindURLs(cfg.LCUrls); err != nil {
 		return err
 	}
+	if err := cfg.validateClientListenerGroups(); err != nil {
+		return err
+	}
 	if err := checkBindURLs(cfg.ListenMetricsUrls); err != nil {
 		return err
 	}
@@ -758,9 +1157,71 @@ func (cfg *Config) Validate() error {
 	switch cfg.AutoCompactionMode {
 	case "":
 	case CompactorModeRevision, CompactorModePeriodic:
+	case CompactorModeHybrid:
+		if cfg.AutoCompactionMaxRevisions <= 0 {
+			return fmt.Errorf("auto-compaction-mode %q requires a positive auto-compaction-max-revisions, got %d", cfg.AutoCompactionMode, cfg.AutoCompactionMaxRevisions)
+		}
 	default:
 		return fmt.Errorf("unknown auto-compaction-mode %q", cfg.AutoCompactionMode)
 	}
+	if cfg.ExperimentalWatchMaxEventsPerSecond < 0 {
+		return fmt.Errorf("experimental-watch-max-events-per-second must not be negative, got %d", cfg.ExperimentalWatchMaxEventsPerSecond)
+	}
+	if cfg.ExperimentalWatchMaxQueuedEvents < 0 {
+		return fmt.Errorf("experimental-watch-max-queued-events must not be negative, got %d", cfg.ExperimentalWatchMaxQueuedEvents)
+	}
+	if cfg.ExperimentalWatchBacklogAlarmEvents < 0 {
+		return fmt.Errorf("experimental-watch-backlog-alarm-events must not be negative, got %d", cfg.ExperimentalWatchBacklogAlarmEvents)
+	}
+	if cfg.ExperimentalDrainTimeout < 0 {
+		return fmt.Errorf("experimental-drain-timeout must not be negative, got %v", cfg.ExperimentalDrainTimeout)
+	}
+	if cfg.ExperimentalClientMaxConnections < 0 {
+		return fmt.Errorf("experimental-client-max-connections must not be negative, got %d", cfg.ExperimentalClientMaxConnections)
+	}
+	if cfg.ExperimentalClientAcceptRate < 0 {
+		return fmt.Errorf("experimental-client-accept-rate must not be negative, got %v", cfg.ExperimentalClientAcceptRate)
+	}
+	if cfg.ExperimentalPeerMaxConnections < 0 {
+		return fmt.Errorf("experimental-peer-max-connections must not be negative, got %d", cfg.ExperimentalPeerMaxConnections)
+	}
+	if cfg.ExperimentalPeerAcceptRate < 0 {
+		return fmt.Errorf("experimental-peer-accept-rate must not be negative, got %v", cfg.ExperimentalPeerAcceptRate)
+	}
+	if err := v3rpc.ValidateWatchOverflowPolicy(cfg.ExperimentalWatchOverflowPolicy); err != nil {
+		return err
+	}
+	if cfg.ExperimentalCompactionRevisionAlignment < 0 {
+		return fmt.Errorf("experimental-compaction-revision-alignment must not be negative, got %d", cfg.ExperimentalCompactionRevisionAlignment)
+	}
+	for _, s := range cfg.ExperimentalVersionRetention {
+		if _, err := mvcc.ParseVersionRetention(s); err != nil {
+			return err
+		}
+	}
+	if cfg.ExperimentalCompactionBatchTargetDuration < 0 {
+		return fmt.Errorf("experimental-compaction-batch-target-duration must not be negative, got %v", cfg.ExperimentalCompactionBatchTargetDuration)
+	}
+	if cfg.ExperimentalCompactionMinBatchLimit < 0 {
+		return fmt.Errorf("experimental-compaction-min-batch-limit must not be negative, got %d", cfg.ExperimentalCompactionMinBatchLimit)
+	}
+	if cfg.ExperimentalAutoDefragThreshold < 0 || cfg.ExperimentalAutoDefragThreshold >= 1 {
+		return fmt.Errorf("--experimental-auto-defrag-threshold must be in [0, 1) (set to %v)", cfg.ExperimentalAutoDefragThreshold)
+	}
+	if cfg.ExperimentalAutoDefragSchedule != "" {
+		if _, err := schedule.ParseCron(cfg.ExperimentalAutoDefragSchedule); err != nil {
+			return fmt.Errorf("invalid --experimental-auto-defrag-schedule: %v", err)
+		}
+	}
+	if cfg.ExperimentalMaxQuotaBackendBytes != 0 {
+		quota := cfg.QuotaBackendBytes
+		if quota == 0 {
+			quota = storage.DefaultQuotaBytes
+		}
+		if quota < 0 || cfg.ExperimentalMaxQuotaBackendBytes <= quota {
+			return fmt.Errorf("experimental-max-quota-backend-bytes must be greater than quota-backend-bytes %d, got %d", quota, cfg.ExperimentalMaxQuotaBackendBytes)
+		}
+	}
 
 	// Validate distributed tracing configuration but only if enabled.
 	if cfg.ExperimentalEnableDistributedTracing {
@@ -777,10 +1238,98 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("setting experimental-enable-lease-checkpoint-persist requires experimental-enable-lease-checkpoint")
 	}
 
+	if cfg.ExperimentalLeaseCheckpointInterval < 0 {
+		return fmt.Errorf("experimental-lease-checkpoint-interval %v must be non-negative", cfg.ExperimentalLeaseCheckpointInterval)
+	}
+
+	if cfg.ExperimentalLeaseCheckpointOnRenew && !cfg.ExperimentalEnableLeaseCheckpoint {
+		return fmt.Errorf("setting experimental-lease-checkpoint-on-renew requires experimental-enable-lease-checkpoint")
+	}
+	if cfg.ExperimentalMaxLeaseAttachedKeys < 0 {
+		return fmt.Errorf("experimental-max-lease-attached-keys must not be negative, got %d", cfg.ExperimentalMaxLeaseAttachedKeys)
+	}
+	if cfg.ExperimentalLearnerAutoPromoteDuration < 0 {
+		return fmt.Errorf("experimental-learner-auto-promote-duration must not be negative, got %v", cfg.ExperimentalLearnerAutoPromoteDuration)
+	}
+
+	for _, s := range cfg.ExperimentalSecondaryIndexes {
+		if _, err := mvcc.ParseSecondaryIndex(s); err != nil {
+			return err
+		}
+	}
+	for _, s := range cfg.ExperimentalClientCertSANRoleRules {
+		if _, err := auth.ParseSANRoleRule(s); err != nil {
+			return err
+		}
+	}
+	if cfg.ExperimentalPasswordMinLength < 0 {
+		return fmt.Errorf("experimental-password-min-length must not be negative, got %d", cfg.ExperimentalPasswordMinLength)
+	}
+	if cfg.ExperimentalPasswordMinCharClasses < 0 || cfg.ExperimentalPasswordMinCharClasses > 4 {
+		return fmt.Errorf("experimental-password-min-char-classes must be between 0 and 4, got %d", cfg.ExperimentalPasswordMinCharClasses)
+	}
+	if _, err := mvcc.ParseValueCompression(cfg.ExperimentalValueCompression); err != nil {
+		return err
+	}
+	if cfg.ExperimentalValueCompressionThreshold < 0 {
+		return fmt.Errorf("experimental-value-compression-threshold must not be negative, got %d", cfg.ExperimentalValueCompressionThreshold)
+	}
+
+	if err := backend.ValidateEngine(cfg.ExperimentalBackendEngine); err != nil {
+		return err
+	}
+
+	if cfg.ExperimentalAuditLogPath != "" {
+		if _, err := parseLogRotationConfig(cfg.ExperimentalAuditLogRotationConfigJSON); err != nil {
+			return err
+		}
+	} else if len(cfg.ExperimentalAuditLogIncludePrefixes) > 0 || len(cfg.ExperimentalAuditLogExcludePrefixes) > 0 {
+		return fmt.Errorf("--experimental-audit-log-include-prefixes and --experimental-audit-log-exclude-prefixes require --experimental-audit-log-path")
+	}
+
+	if cfg.ExperimentalAuthzWebhookURL != "" {
+		if cfg.ExperimentalAuthorizer != nil {
+			return fmt.Errorf("--experimental-authz-webhook-url cannot be set with an ExperimentalAuthorizer")
+		}
+		if _, err := url.Parse(cfg.ExperimentalAuthzWebhookURL); err != nil {
+			return fmt.Errorf("invalid --experimental-authz-webhook-url %q: %v", cfg.ExperimentalAuthzWebhookURL, err)
+		}
+	}
+	if (cfg.ExperimentalAuthzWebhookCertFile == "") != (cfg.ExperimentalAuthzWebhookKeyFile == "") {
+		return fmt.Errorf("--experimental-authz-webhook-cert-file and --experimental-authz-webhook-key-file must be set together")
+	}
+	if cfg.ExperimentalAuthzWebhookTimeout <= 0 {
+		return fmt.Errorf("--experimental-authz-webhook-timeout must be positive, got %v", cfg.ExperimentalAuthzWebhookTimeout)
+	}
+	if cfg.ExperimentalAuthzCacheTTL < 0 {
+		return fmt.Errorf("--experimental-authz-cache-ttl must not be negative, got %v", cfg.ExperimentalAuthzCacheTTL)
+	}
+
+	if len(cfg.ExperimentalChangeFeedPrefixes) > 0 && cfg.ExperimentalChangeFeedWebhookURL == "" {
+		return fmt.Errorf("--experimental-change-feed-prefixes requires --experimental-change-feed-webhook-url")
+	}
+	if cfg.ExperimentalChangeFeedWebhookURL != "" {
+		if _, err := url.Parse(cfg.ExperimentalChangeFeedWebhookURL); err != nil {
+			return fmt.Errorf("invalid --experimental-change-feed-webhook-url %q: %v", cfg.ExperimentalChangeFeedWebhookURL, err)
+		}
+	}
+
 	if cfg.ExperimentalCompactHashCheckTime <= 0 {
 		return fmt.Errorf("--experimental-compact-hash-check-time must be >0 (set to %v)", cfg.ExperimentalCompactHashCheckTime)
 	}
 
+	if cfg.ExperimentalCorruptCheckSchedule != "" {
+		if _, err := schedule.ParseCron(cfg.ExperimentalCorruptCheckSchedule); err != nil {
+			return fmt.Errorf("invalid --experimental-corrupt-check-schedule: %v", err)
+		}
+	}
+	if cfg.ExperimentalCorruptCheckScope != "full" && cfg.ExperimentalCorruptCheckScope != "sampled" {
+		return fmt.Errorf("--experimental-corrupt-check-scope must be \"full\" or \"sampled\" (set to %q)", cfg.ExperimentalCorruptCheckScope)
+	}
+	if cfg.ExperimentalCorruptCheckSamples <= 0 {
+		return fmt.Errorf("--experimental-corrupt-check-samples must be >0 (set to %v)", cfg.ExperimentalCorruptCheckSamples)
+	}
+
 	// If `--name` isn't configured, then multiple members may have the same "default" name.
 	// When adding a new member with the "default" name as well, etcd may regards its peerURL
 	// as one additional peerURL of the existing member which has the same "default" name,
@@ -0,0 +1,178 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+)
+
+// ConfigError is a problem of a configuration found by Check.
+type ConfigError struct {
+	// Fields are the names of the settings involved, if known.
+	Fields  []string `json:"fields,omitempty"`
+	Message string   `json:"message"`
+}
+
+func (e ConfigError) Error() string { return e.Message }
+
+// Check validates the configuration like Validate, and cross-checks the
+// settings otherwise only found to conflict when the server starts, e.g. the
+// URL schemes against the TLS settings. All the problems found are reported
+// rather than the first one. No listener is bound and the data directory is
+// not read, so the configuration can be checked on another host.
+func (cfg *Config) Check() []ConfigError {
+	var errs []ConfigError
+	if err := cfg.Validate(); err != nil {
+		var cerr ConfigError
+		if !errors.As(err, &cerr) {
+			cerr = ConfigError{Message: err.Error()}
+		}
+		errs = append(errs, cerr)
+	}
+	errs = append(errs, cfg.checkTLS()...)
+	errs = append(errs, cfg.checkCompaction()...)
+	errs = append(errs, cfg.checkInitialCluster()...)
+	return errs
+}
+
+// CheckConfigFile reads the config file at path and checks it like Check.
+func CheckConfigFile(path string) (*Config, []ConfigError) {
+	cfg := &configYAML{Config: *NewConfig()}
+	if err := cfg.loadFile(path); err != nil {
+		return nil, []ConfigError{{Message: fmt.Sprintf("invalid config file %q: %v", path, err)}}
+	}
+	return &cfg.Config, cfg.Check()
+}
+
+func (cfg *Config) checkTLS() []ConfigError {
+	var errs []ConfigError
+	check := func(urlsField string, urls []url.URL, tlsField string, tlsinfo *transport.TLSInfo, autoTLSField string, autoTLS bool) {
+		if (tlsinfo.CertFile == "") != (tlsinfo.KeyFile == "") {
+			errs = append(errs, ConfigError{
+				Fields:  []string{tlsField + "cert-file", tlsField + "key-file"},
+				Message: fmt.Sprintf("--%scert-file and --%skey-file must be set together", tlsField, tlsField),
+			})
+		}
+		if !tlsinfo.Empty() || autoTLS {
+			return
+		}
+		for _, u := range urls {
+			if isSecureScheme(u.Scheme) {
+				errs = append(errs, ConfigError{
+					Fields:  []string{urlsField, tlsField + "cert-file", tlsField + "key-file", autoTLSField},
+					Message: fmt.Sprintf("--%s %q has a TLS scheme, but neither --%scert-file and --%skey-file nor --%s are set", urlsField, u.String(), tlsField, tlsField, autoTLSField),
+				})
+			}
+		}
+	}
+	check("listen-client-urls", cfg.LCUrls, "", &cfg.ClientTLSInfo, "auto-tls", cfg.ClientAutoTLS)
+	check("listen-peer-urls", cfg.LPUrls, "peer-", &cfg.PeerTLSInfo, "peer-auto-tls", cfg.PeerAutoTLS)
+
+	// the advertised URLs must be served by a listener of the same security,
+	// or the clients and peers fail the TLS handshakes
+	lcurls := append([]url.URL{}, cfg.LCUrls...)
+	for _, g := range cfg.ExperimentalClientListenerGroups {
+		lcurls = append(lcurls, g.ListenClientUrls...)
+	}
+	for _, m := range []struct {
+		advertiseField, listenField string
+		advertise, listen           []url.URL
+	}{
+		{"advertise-client-urls", "listen-client-urls", cfg.ACUrls, lcurls},
+		{"initial-advertise-peer-urls", "listen-peer-urls", cfg.APUrls, cfg.LPUrls},
+	} {
+		if len(m.listen) == 0 {
+			continue
+		}
+		for _, u := range m.advertise {
+			if !hasURLOfSecurity(m.listen, isSecureScheme(u.Scheme)) {
+				errs = append(errs, ConfigError{
+					Fields:  []string{m.advertiseField, m.listenField},
+					Message: fmt.Sprintf("--%s %q is not served by any of --%s %q with the same scheme security", m.advertiseField, u.String(), m.listenField, types.URLs(m.listen).String()),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func isSecureScheme(scheme string) bool {
+	return scheme == "https" || scheme == "unixs"
+}
+
+func hasURLOfSecurity(urls []url.URL, secure bool) bool {
+	for _, u := range urls {
+		if isSecureScheme(u.Scheme) == secure {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *Config) checkCompaction() []ConfigError {
+	var errs []ConfigError
+	retention := cfg.AutoCompactionRetention
+	if retention == "" {
+		retention = "0"
+	}
+	ret, err := parseCompactionRetention(cfg.AutoCompactionMode, retention)
+	if err != nil {
+		errs = append(errs, ConfigError{
+			Fields:  []string{"auto-compaction-retention", "auto-compaction-mode"},
+			Message: fmt.Sprintf("--auto-compaction-retention %q is invalid for --auto-compaction-mode %q: %v", cfg.AutoCompactionRetention, cfg.AutoCompactionMode, err),
+		})
+	}
+	if err == nil && ret == 0 && cfg.ExperimentalCompactionRevisionAlignment > 0 {
+		errs = append(errs, ConfigError{
+			Fields:  []string{"experimental-compaction-revision-alignment", "auto-compaction-retention"},
+			Message: "--experimental-compaction-revision-alignment has no effect with the auto compaction disabled",
+		})
+	}
+	if cfg.ExperimentalAutoDefragSchedule != "" && cfg.ExperimentalAutoDefragThreshold == 0 {
+		errs = append(errs, ConfigError{
+			Fields:  []string{"experimental-auto-defrag-schedule", "experimental-auto-defrag-threshold"},
+			Message: "--experimental-auto-defrag-schedule has no effect with --experimental-auto-defrag-threshold disabled",
+		})
+	}
+	return errs
+}
+
+// checkInitialCluster checks that a member bootstrapping a new cluster from
+// a static --initial-cluster is part of it.
+func (cfg *Config) checkInitialCluster() []ConfigError {
+	if cfg.ClusterState != ClusterStateFlagNew || cfg.InitialCluster == "" ||
+		cfg.Durl != "" || cfg.DNSCluster != "" || len(cfg.DiscoveryCfg.Endpoints) > 0 {
+		return nil
+	}
+	urlsmap, err := types.NewURLsMap(cfg.InitialCluster)
+	if err != nil {
+		return []ConfigError{{
+			Fields:  []string{"initial-cluster"},
+			Message: fmt.Sprintf("--initial-cluster %q is invalid: %v", cfg.InitialCluster, err),
+		}}
+	}
+	if _, ok := urlsmap[cfg.Name]; !ok {
+		return []ConfigError{{
+			Fields:  []string{"initial-cluster", "name"},
+			Message: fmt.Sprintf("--initial-cluster %q has no member named --name %q", cfg.InitialCluster, cfg.Name),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/features"
+)
+
+// ServerFeatureGateFlagName is the flag setting ServerFeatureGate.
+const ServerFeatureGateFlagName = "feature-gates"
+
+// experimentalFeatureFlags returns the fields of the experimental flags
+// consolidated into the feature gates, keyed by flag name.
+func (cfg *Config) experimentalFeatureFlags() map[string]*bool {
+	return map[string]*bool{
+		"experimental-compact-hash-check-enabled":        &cfg.ExperimentalCompactHashCheckEnabled,
+		"experimental-enable-lease-checkpoint":           &cfg.ExperimentalEnableLeaseCheckpoint,
+		"experimental-enable-lease-checkpoint-persist":   &cfg.ExperimentalEnableLeaseCheckpointPersist,
+		"experimental-enable-snapshot-http":              &cfg.ExperimentalEnableSnapshotHTTP,
+		"experimental-hot-key-tracking":                  &cfg.ExperimentalHotKeyTracking,
+		"experimental-incremental-defrag":                &cfg.ExperimentalIncrementalDefrag,
+		"experimental-initial-corrupt-check":             &cfg.ExperimentalInitialCorruptCheck,
+		"experimental-lease-checkpoint-on-renew":         &cfg.ExperimentalLeaseCheckpointOnRenew,
+		"experimental-prefix-stats":                      &cfg.ExperimentalPrefixStats,
+		"experimental-socket-activation":                 &cfg.ExperimentalSocketActivation,
+		"experimental-txn-mode-write-with-shared-buffer": &cfg.ExperimentalTxnModeWriteWithSharedBuffer,
+	}
+}
+
+// experimentalFeatureParams returns the fields of the experimental flags
+// tuning the features, keyed by flag name.
+func (cfg *Config) experimentalFeatureParams() map[string]any {
+	return map[string]any{
+		"experimental-audit-log-exclude-prefixes":       &cfg.ExperimentalAuditLogExcludePrefixes,
+		"experimental-audit-log-include-prefixes":       &cfg.ExperimentalAuditLogIncludePrefixes,
+		"experimental-audit-log-path":                   &cfg.ExperimentalAuditLogPath,
+		"experimental-audit-log-rotation-config-json":   &cfg.ExperimentalAuditLogRotationConfigJSON,
+		"experimental-authz-cache-ttl":                  &cfg.ExperimentalAuthzCacheTTL,
+		"experimental-authz-fail-open":                  &cfg.ExperimentalAuthzFailOpen,
+		"experimental-authz-webhook-cert-file":          &cfg.ExperimentalAuthzWebhookCertFile,
+		"experimental-authz-webhook-key-file":           &cfg.ExperimentalAuthzWebhookKeyFile,
+		"experimental-authz-webhook-timeout":            &cfg.ExperimentalAuthzWebhookTimeout,
+		"experimental-authz-webhook-trusted-ca-file":    &cfg.ExperimentalAuthzWebhookTrustedCAFile,
+		"experimental-authz-webhook-url":                &cfg.ExperimentalAuthzWebhookURL,
+		"experimental-auto-defrag-schedule":             &cfg.ExperimentalAutoDefragSchedule,
+		"experimental-auto-defrag-threshold":            &cfg.ExperimentalAutoDefragThreshold,
+		"experimental-backend-engine":                   &cfg.ExperimentalBackendEngine,
+		"experimental-bounded-staleness-max-lag":        &cfg.ExperimentalBoundedStalenessMaxLag,
+		"experimental-change-feed-prefixes":             &cfg.ExperimentalChangeFeedPrefixes,
+		"experimental-change-feed-webhook-url":          &cfg.ExperimentalChangeFeedWebhookURL,
+		"experimental-client-accept-rate":               &cfg.ExperimentalClientAcceptRate,
+		"experimental-client-cert-san-role-rules":       &cfg.ExperimentalClientCertSANRoleRules,
+		"experimental-client-max-connections":           &cfg.ExperimentalClientMaxConnections,
+		"experimental-compaction-batch-target-duration": &cfg.ExperimentalCompactionBatchTargetDuration,
+		"experimental-compaction-min-batch-limit":       &cfg.ExperimentalCompactionMinBatchLimit,
+		"experimental-compaction-revision-alignment":    &cfg.ExperimentalCompactionRevisionAlignment,
+		"experimental-corrupt-check-samples":            &cfg.ExperimentalCorruptCheckSamples,
+		"experimental-corrupt-check-schedule":           &cfg.ExperimentalCorruptCheckSchedule,
+		"experimental-corrupt-check-scope":              &cfg.ExperimentalCorruptCheckScope,
+		"experimental-drain-timeout":                    &cfg.ExperimentalDrainTimeout,
+		"experimental-learner-auto-promote-duration":    &cfg.ExperimentalLearnerAutoPromoteDuration,
+		"experimental-learner-auto-promote-lag":         &cfg.ExperimentalLearnerAutoPromoteLag,
+		"experimental-lease-checkpoint-interval":        &cfg.ExperimentalLeaseCheckpointInterval,
+		"experimental-max-chunked-value-bytes":          &cfg.ExperimentalMaxChunkedValueBytes,
+		"experimental-max-lease-attached-keys":          &cfg.ExperimentalMaxLeaseAttachedKeys,
+		"experimental-max-quota-backend-bytes":          &cfg.ExperimentalMaxQuotaBackendBytes,
+		"experimental-password-min-char-classes":        &cfg.ExperimentalPasswordMinCharClasses,
+		"experimental-password-min-length":              &cfg.ExperimentalPasswordMinLength,
+		"experimental-peer-accept-rate":                 &cfg.ExperimentalPeerAcceptRate,
+		"experimental-peer-max-connections":             &cfg.ExperimentalPeerMaxConnections,
+		"experimental-secondary-indexes":                &cfg.ExperimentalSecondaryIndexes,
+		"experimental-value-compression":                &cfg.ExperimentalValueCompression,
+		"experimental-value-compression-threshold":      &cfg.ExperimentalValueCompressionThreshold,
+		"experimental-version-retention":                &cfg.ExperimentalVersionRetention,
+		"experimental-watch-backlog-alarm-events":       &cfg.ExperimentalWatchBacklogAlarmEvents,
+		"experimental-watch-max-events-per-second":      &cfg.ExperimentalWatchMaxEventsPerSecond,
+		"experimental-watch-max-queued-events":          &cfg.ExperimentalWatchMaxQueuedEvents,
+		"experimental-watch-max-start-revision-age":     &cfg.ExperimentalWatchMaxStartRevisionAge,
+		"experimental-watch-max-start-revision-lag":     &cfg.ExperimentalWatchMaxStartRevisionLag,
+		"experimental-watch-overflow-policy":            &cfg.ExperimentalWatchOverflowPolicy,
+	}
+}
+
+// setupFeatureGates reconciles the experimental flags with their feature gates.
+// An experimental flag turning a feature on or off sets the feature unless the
+// feature gate sets it, and conflicts with a different value of the feature
+// gate, whether it is set to its default or not. The flags are then set from the
+// feature gates, which the server reads. The experimental flags tuning the
+// features cannot be set unless their feature gates are enabled.
+func (cfg *Config) setupFeatureGates() error {
+	if cfg.ServerFeatureGate == nil {
+		cfg.ServerFeatureGate = features.NewDefaultServerFeatureGate()
+	}
+	fg := cfg.ServerFeatureGate
+	mfg, mutable := fg.(featuregate.MutableFeatureGate)
+
+	flags := cfg.experimentalFeatureFlags()
+	for _, name := range sortedKeys(flags) {
+		feature, ok := features.ExperimentalFlagToFeatureMap[name]
+		if !ok {
+			return fmt.Errorf("no feature gate for --%s", name)
+		}
+		v := flags[name]
+		if cfg.FlagsExplicitlySet[name] || *v != features.DefaultEtcdServerFeatureGates[feature].Default {
+			switch {
+			case mutable && !mfg.IsSet(feature):
+				if err := mfg.SetFromMap(map[string]bool{string(feature): *v}); err != nil {
+					return err
+				}
+				cfg.GetLogger().Warn(
+					fmt.Sprintf("--%s is deprecated, use --%s=%s=%t instead", name, ServerFeatureGateFlagName, feature, *v),
+				)
+			case fg.Enabled(feature) != *v:
+				return fmt.Errorf("cannot set --%s=%t and --%s=%s=%t", name, *v, ServerFeatureGateFlagName, feature, fg.Enabled(feature))
+			}
+		}
+		*v = fg.Enabled(feature)
+	}
+
+	params, defaults := cfg.experimentalFeatureParams(), NewConfig().experimentalFeatureParams()
+	for _, name := range sortedKeys(params) {
+		feature, ok := features.ExperimentalParamFlagToFeatureMap[name]
+		if !ok {
+			return fmt.Errorf("no feature gate for --%s", name)
+		}
+		if !fg.Enabled(feature) && !equalValues(params[name], defaults[name]) {
+			return fmt.Errorf("--%s requires --%s=%s=true", name, ServerFeatureGateFlagName, feature)
+		}
+	}
+	for _, f := range []struct {
+		field   string
+		set     bool
+		feature featuregate.Feature
+	}{
+		{"ExperimentalAuthorizer", cfg.ExperimentalAuthorizer != nil, features.Authz},
+		{"ExperimentalChangeFeeds", len(cfg.ExperimentalChangeFeeds) > 0, features.ChangeFeeds},
+		{"experimental-client-listener-groups", len(cfg.ExperimentalClientListenerGroups) > 0, features.ClientListenerGroups},
+	} {
+		if f.set && !fg.Enabled(f.feature) {
+			return fmt.Errorf("%s requires --%s=%s=true", f.field, ServerFeatureGateFlagName, f.feature)
+		}
+	}
+	return nil
+}
+
+// equalValues returns true if the fields a and b point to hold equal values,
+// the empty slices being equal.
+func equalValues(a, b any) bool {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	if va.Kind() == reflect.Slice && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(va.Interface(), vb.Interface())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
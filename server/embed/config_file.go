@@ -0,0 +1,173 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// configIncludeKey is the key of the config file listing the config files it
+// includes.
+const configIncludeKey = "include"
+
+// configEnvRegexp matches the "${NAME}" and "${NAME:-default}" environment
+// variable references of the config files, and their "$${" escapes.
+var configEnvRegexp = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// readConfigFile reads the config file at path, expanding the environment
+// variables and merging the included config files, and returns its content
+// as JSON.
+//
+// Outside of the comment lines, "${NAME}" is replaced verbatim by the value of
+// the environment variable NAME, which must be set, and "${NAME:-default}" by
+// default if NAME is unset or empty. "$${" stands for a literal "${".
+//
+// The "include" key lists the paths of the config files, relative to the
+// directory of the including file, merged in order under the including file:
+// the settings of a file override the ones of the files it includes, and of
+// the files included before it. The maps, such as client-transport-security,
+// are merged key by key, any other value, lists included, is replaced.
+func readConfigFile(path string) ([]byte, error) {
+	m, err := readConfigFileMap(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func readConfigFileMap(path string, including []string) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range including {
+		if p == abs {
+			return nil, fmt.Errorf("config file %q includes itself through %s", path, strings.Join(including, " -> "))
+		}
+	}
+	including = append(including, abs)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = expandConfigEnv(b); err != nil {
+		return nil, fmt.Errorf("config file %q: %v", path, err)
+	}
+	jb, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("config file %q: %v", path, err)
+	}
+	m := make(map[string]interface{})
+	if !bytes.Equal(bytes.TrimSpace(jb), []byte("null")) {
+		dec := json.NewDecoder(bytes.NewReader(jb))
+		// keep the integers beyond the float64 precision, e.g. the quotas
+		dec.UseNumber()
+		if err = dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("config file %q: %v", path, err)
+		}
+	}
+
+	includes, err := configIncludes(m[configIncludeKey])
+	if err != nil {
+		return nil, fmt.Errorf("config file %q: %v", path, err)
+	}
+	delete(m, configIncludeKey)
+	merged := make(map[string]interface{})
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		im, err := readConfigFileMap(inc, including)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigMaps(merged, im)
+	}
+	mergeConfigMaps(merged, m)
+	return merged, nil
+}
+
+// expandConfigEnv expands the environment variable references of the config
+// file, the comment lines being left as is.
+func expandConfigEnv(b []byte) ([]byte, error) {
+	var err error
+	lines := bytes.SplitAfter(b, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		lines[i] = configEnvRegexp.ReplaceAllFunc(line, func(ref []byte) []byte {
+			if string(ref) == "$${" {
+				return []byte("${")
+			}
+			sm := configEnvRegexp.FindSubmatch(ref)
+			name, hasDefault := string(sm[1]), len(sm[2]) > 0
+			if v := os.Getenv(name); v != "" {
+				return []byte(v)
+			}
+			if hasDefault {
+				return sm[3]
+			}
+			if _, ok := os.LookupEnv(name); !ok && err == nil {
+				err = fmt.Errorf("environment variable %q is not set", name)
+			}
+			return nil
+		})
+	}
+	return bytes.Join(lines, nil), err
+}
+
+func configIncludes(v interface{}) ([]string, error) {
+	switch inc := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{inc}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(inc))
+		for _, p := range inc {
+			s, ok := p.(string)
+			if !ok {
+				return nil, fmt.Errorf("%q must list paths, got %v", configIncludeKey, p)
+			}
+			paths = append(paths, s)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("%q must be a path or a list of paths, got %v", configIncludeKey, v)
+	}
+}
+
+// mergeConfigMaps merges src into dst, the values of src taking precedence.
+func mergeConfigMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		sm, srcIsMap := v.(map[string]interface{})
+		dm, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfigMaps(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// graduatedFlag is an experimental flag graduated to a stable flag, setting
+// fields of the same type.
+type graduatedFlag struct {
+	name         string
+	experimental any
+	stable       any
+}
+
+// graduatedFlags returns the fields of the experimental flags graduated to
+// stable flags, keyed by experimental flag name.
+func (cfg *Config) graduatedFlags() map[string]graduatedFlag {
+	return map[string]graduatedFlag{
+		"experimental-bootstrap-defrag-threshold-megabytes": {"bootstrap-defrag-threshold-megabytes", &cfg.ExperimentalBootstrapDefragThresholdMegabytes, &cfg.BootstrapDefragThresholdMegabytes},
+		"experimental-compact-hash-check-time":              {"compact-hash-check-time", &cfg.ExperimentalCompactHashCheckTime, &cfg.CompactHashCheckTime},
+		"experimental-compaction-batch-limit":               {"compaction-batch-limit", &cfg.ExperimentalCompactionBatchLimit, &cfg.CompactionBatchLimit},
+		"experimental-compaction-sleep-interval":            {"compaction-sleep-interval", &cfg.ExperimentalCompactionSleepInterval, &cfg.CompactionSleepInterval},
+		"experimental-corrupt-check-time":                   {"corrupt-check-time", &cfg.ExperimentalCorruptCheckTime, &cfg.CorruptCheckTime},
+		"experimental-distributed-tracing-address":          {"distributed-tracing-address", &cfg.ExperimentalDistributedTracingAddress, &cfg.DistributedTracingAddress},
+		"experimental-distributed-tracing-instance-id":      {"distributed-tracing-instance-id", &cfg.ExperimentalDistributedTracingServiceInstanceID, &cfg.DistributedTracingServiceInstanceID},
+		"experimental-distributed-tracing-sampling-rate":    {"distributed-tracing-sampling-rate", &cfg.ExperimentalDistributedTracingSamplingRatePerMillion, &cfg.DistributedTracingSamplingRatePerMillion},
+		"experimental-distributed-tracing-service-name":     {"distributed-tracing-service-name", &cfg.ExperimentalDistributedTracingServiceName, &cfg.DistributedTracingServiceName},
+		"experimental-downgrade-check-time":                 {"downgrade-check-time", &cfg.ExperimentalDowngradeCheckTime, &cfg.DowngradeCheckTime},
+		"experimental-enable-distributed-tracing":           {"enable-distributed-tracing", &cfg.ExperimentalEnableDistributedTracing, &cfg.EnableDistributedTracing},
+		"experimental-max-learners":                         {"max-learners", &cfg.ExperimentalMaxLearners, &cfg.MaxLearners},
+		"experimental-memory-mlock":                         {"memory-mlock", &cfg.ExperimentalMemoryMlock, &cfg.MemoryMlock},
+		"experimental-peer-skip-client-san-verification":    {"peer-skip-client-san-verification", &cfg.ExperimentalPeerSkipClientSANVerification, &cfg.PeerTLSInfo.SkipClientSANVerify},
+		"experimental-snapshot-catchup-entries":             {"snapshot-catchup-entries", &cfg.ExperimentalSnapshotCatchUpEntries, &cfg.SnapshotCatchUpEntries},
+		"experimental-wait-cluster-ready-timeout":           {"wait-cluster-ready-timeout", &cfg.ExperimentalWaitClusterReadyTimeout, &cfg.WaitClusterReadyTimeout},
+		"experimental-warning-apply-duration":               {"warning-apply-duration", &cfg.ExperimentalWarningApplyDuration, &cfg.WarningApplyDuration},
+		"experimental-warning-unary-request-duration":       {"warning-unary-request-duration", &cfg.ExperimentalWarningUnaryRequestDuration, &cfg.WarningUnaryRequestDuration},
+		"experimental-watch-progress-notify-interval":       {"watch-progress-notify-interval", &cfg.ExperimentalWatchProgressNotifyInterval, &cfg.WatchProgressNotifyInterval},
+	}
+}
+
+// GraduatedExperimentalFlags returns the stable flags the deprecated
+// experimental flags graduated to, keyed by experimental flag name.
+func GraduatedExperimentalFlags() map[string]string {
+	flags := make(map[string]string)
+	for name, f := range NewConfig().graduatedFlags() {
+		flags[name] = f.name
+	}
+	return flags
+}
+
+// setupGraduatedFlags sets the stable flags from the deprecated experimental
+// flags they graduated from. An experimental flag cannot be set with its
+// stable flag, even to the same value on the command line or in the config
+// file.
+func (cfg *Config) setupGraduatedFlags() error {
+	flags, defaults := cfg.graduatedFlags(), NewConfig().graduatedFlags()
+	for _, name := range sortedKeys(flags) {
+		f, def := flags[name], defaults[name]
+		if !cfg.FlagsExplicitlySet[name] && equalValues(f.experimental, def.experimental) {
+			continue
+		}
+		if cfg.FlagsExplicitlySet[f.name] {
+			return bothFlagsSetError(name, f.name)
+		}
+		if equalValues(f.stable, f.experimental) {
+			// already set from the experimental flag
+			continue
+		}
+		if !equalValues(f.stable, def.stable) {
+			return bothFlagsSetError(name, f.name)
+		}
+		reflect.ValueOf(f.stable).Elem().Set(reflect.ValueOf(f.experimental).Elem())
+		if lg := cfg.GetLogger(); lg != nil {
+			lg.Warn(fmt.Sprintf("--%s is deprecated, and will be decommissioned in v3.7. Use --%s instead.", name, f.name))
+		}
+	}
+	return nil
+}
+
+func bothFlagsSetError(experimental, stable string) ConfigError {
+	return ConfigError{
+		Fields:  []string{experimental, stable},
+		Message: fmt.Sprintf("both --%s and --%s flags are set. Use only --%s", experimental, stable, stable),
+	}
+}
@@ -118,6 +118,7 @@ func (cfg *Config) setupLogging() error {
 					return err
 				}
 				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(lg)
+				cfg.loggerLevel = &copied.Level
 			}
 		} else {
 			if len(cfg.LogOutputs) > 1 {
@@ -157,6 +158,7 @@ func (cfg *Config) setupLogging() error {
 			)
 			if cfg.ZapLoggerBuilder == nil {
 				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(zap.New(cr, zap.AddCaller(), zap.ErrorOutput(syncer)))
+				cfg.loggerLevel = &lvl
 			}
 		}
 
@@ -246,7 +248,6 @@ func (logRotationConfig) Sync() error { return nil }
 
 // setupLogRotation initializes log rotation for a single file path target.
 func setupLogRotation(logOutputs []string, logRotateConfigJSON string) error {
-	var logRotationConfig logRotationConfig
 	outputFilePaths := 0
 	for _, v := range logOutputs {
 		switch v {
@@ -265,21 +266,48 @@ func setupLogRotation(logOutputs []string, logRotateConfigJSON string) error {
 		return ErrLogRotationInvalidLogOutput
 	}
 
+	logRotationConfig, err := parseLogRotationConfig(logRotateConfigJSON)
+	if err != nil {
+		return err
+	}
+	zap.RegisterSink("rotate", func(u *url.URL) (zap.Sink, error) {
+		logRotationConfig.Filename = u.Path[1:]
+		return &logRotationConfig, nil
+	})
+	return nil
+}
+
+func parseLogRotationConfig(logRotateConfigJSON string) (logRotationConfig, error) {
+	var logRotationConfig logRotationConfig
 	if err := json.Unmarshal([]byte(logRotateConfigJSON), &logRotationConfig); err != nil {
 		var unmarshalTypeError *json.UnmarshalTypeError
 		var syntaxError *json.SyntaxError
 		switch {
 		case errors.As(err, &syntaxError):
-			return fmt.Errorf("improperly formatted log rotation config: %v", err)
+			return logRotationConfig, fmt.Errorf("improperly formatted log rotation config: %v", err)
 		case errors.As(err, &unmarshalTypeError):
-			return fmt.Errorf("invalid log rotation config: %v", err)
+			return logRotationConfig, fmt.Errorf("invalid log rotation config: %v", err)
 		default:
-			return fmt.Errorf("fail to unmarshal log rotation config: %v", err)
+			return logRotationConfig, fmt.Errorf("fail to unmarshal log rotation config: %v", err)
 		}
 	}
-	zap.RegisterSink("rotate", func(u *url.URL) (zap.Sink, error) {
-		logRotationConfig.Filename = u.Path[1:]
-		return &logRotationConfig, nil
-	})
-	return nil
+	return logRotationConfig, nil
+}
+
+// setupAuditLogger builds the JSON logger of the audit log, writing to the
+// rotated ExperimentalAuditLogPath file, and the function closing the file.
+func (cfg *Config) setupAuditLogger() (*zap.Logger, func(), error) {
+	rc, err := parseLogRotationConfig(cfg.ExperimentalAuditLogRotationConfigJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc.Logger == nil {
+		rc.Logger = &lumberjack.Logger{}
+	}
+	rc.Filename = cfg.ExperimentalAuditLogPath
+
+	encCfg := zap.NewProductionEncoderConfig()
+	encCfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), zapcore.AddSync(rc.Logger), zap.InfoLevel)
+	return zap.New(core), func() { rc.Logger.Close() }, nil
 }
@@ -21,14 +21,20 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.etcd.io/etcd/client/pkg/v3/srv"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+	"go.etcd.io/etcd/server/v3/features"
 
 	"sigs.k8s.io/yaml"
 )
@@ -199,6 +205,9 @@ func TestAutoCompactionModeParse(t *testing.T) {
 		{"periodic", "1", false, time.Hour},
 		{"periodic", "a", true, 0},
 		{"revision", "-1", true, 0},
+		// hybrid
+		{"hybrid", "1", false, time.Hour},
+		{"hybrid", "30m", false, 30 * time.Minute},
 		// err mode
 		{"errmode", "1", false, 0},
 		{"errmode", "1h", false, time.Hour},
@@ -337,6 +346,35 @@ func TestLeaseCheckpointValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Enabling checkpoint leases on renew should pass",
+			configFunc: func() Config {
+				cfg := *NewConfig()
+				cfg.ExperimentalEnableLeaseCheckpoint = true
+				cfg.ExperimentalLeaseCheckpointOnRenew = true
+				cfg.ExperimentalLeaseCheckpointInterval = time.Second
+				return cfg
+			},
+		},
+		{
+			name: "Enabling checkpoint leases on renew without checkpointing itself should fail",
+			configFunc: func() Config {
+				cfg := *NewConfig()
+				cfg.ExperimentalLeaseCheckpointOnRenew = true
+				return cfg
+			},
+			expectError: true,
+		},
+		{
+			name: "Negative checkpoint interval should fail",
+			configFunc: func() Config {
+				cfg := *NewConfig()
+				cfg.ExperimentalEnableLeaseCheckpoint = true
+				cfg.ExperimentalLeaseCheckpointInterval = -time.Second
+				return cfg
+			},
+			expectError: true,
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
@@ -431,6 +469,318 @@ func TestLogRotation(t *testing.T) {
 	}
 }
 
+func TestAuditLogValidate(t *testing.T) {
+	tests := []struct {
+		name              string
+		path              string
+		logRotationConfig string
+		includePrefixes   []string
+		wantErr           bool
+	}{
+		{
+			name:              "default log rotation config",
+			path:              "/tmp/audit.log",
+			logRotationConfig: DefaultLogRotationConfig,
+			includePrefixes:   []string{"/secrets/"},
+		},
+		{
+			name:              "invalid log rotation config",
+			path:              "/tmp/audit.log",
+			logRotationConfig: `{"maxsize": true}`,
+			wantErr:           true,
+		},
+		{
+			name:              "prefixes without path",
+			logRotationConfig: DefaultLogRotationConfig,
+			includePrefixes:   []string{"/secrets/"},
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("AuditLog=true"))
+			cfg.ExperimentalAuditLogPath = tt.path
+			cfg.ExperimentalAuditLogRotationConfigJSON = tt.logRotationConfig
+			cfg.ExperimentalAuditLogIncludePrefixes = tt.includePrefixes
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test %q, expected error %v, got %v", tt.name, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValueCompressionValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		featureGates string
+		compression  string
+		wantErr      bool
+	}{
+		{name: "no compression", compression: "none"},
+		{name: "compression with the feature gate", featureGates: "ValueCompression=true", compression: "zstd"},
+		{name: "compression without the feature gate", compression: "snappy", wantErr: true},
+		{name: "unknown compression", featureGates: "ValueCompression=true", compression: "zip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if tt.featureGates != "" {
+				require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set(tt.featureGates))
+			}
+			cfg.ExperimentalValueCompression = tt.compression
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test %q, expected error %v, got %v", tt.name, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMaxChunkedValueBytesValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		featureGates string
+		maxBytes     uint
+		wantErr      bool
+	}{
+		{name: "disabled"},
+		{name: "with the feature gate", featureGates: "ValueChunking=true", maxBytes: 10 * 1024 * 1024},
+		{name: "without the feature gate", maxBytes: 10 * 1024 * 1024, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if tt.featureGates != "" {
+				require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set(tt.featureGates))
+			}
+			cfg.ExperimentalMaxChunkedValueBytes = tt.maxBytes
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test %q, expected error %v, got %v", tt.name, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthzValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		authorizer v3authz.Authorizer
+		timeout    time.Duration
+		cacheTTL   time.Duration
+		certFile   string
+		keyFile    string
+		wantErr    bool
+	}{
+		{
+			name:    "webhook",
+			url:     "https://authz.example.org/v1/authorize",
+			timeout: DefaultAuthzWebhookTimeout,
+		},
+		{
+			name:     "client certificate",
+			url:      "https://authz.example.org/v1/authorize",
+			timeout:  DefaultAuthzWebhookTimeout,
+			certFile: "cert",
+			keyFile:  "key",
+		},
+		{
+			name:     "client certificate without key",
+			url:      "https://authz.example.org/v1/authorize",
+			timeout:  DefaultAuthzWebhookTimeout,
+			certFile: "cert",
+			wantErr:  true,
+		},
+		{
+			name:       "webhook and authorizer",
+			url:        "https://authz.example.org/v1/authorize",
+			authorizer: v3authz.NewWebhook("https://authz.example.org/v1/authorize", nil),
+			timeout:    DefaultAuthzWebhookTimeout,
+			wantErr:    true,
+		},
+		{
+			name:    "invalid url",
+			url:     "://authz",
+			timeout: DefaultAuthzWebhookTimeout,
+			wantErr: true,
+		},
+		{
+			name:    "zero timeout",
+			url:     "https://authz.example.org/v1/authorize",
+			wantErr: true,
+		},
+		{
+			name:     "negative cache TTL",
+			url:      "https://authz.example.org/v1/authorize",
+			timeout:  DefaultAuthzWebhookTimeout,
+			cacheTTL: -time.Second,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("Authz=true"))
+			cfg.ExperimentalAuthzWebhookURL = tt.url
+			cfg.ExperimentalAuthorizer = tt.authorizer
+			cfg.ExperimentalAuthzWebhookTimeout = tt.timeout
+			cfg.ExperimentalAuthzCacheTTL = tt.cacheTTL
+			cfg.ExperimentalAuthzWebhookCertFile = tt.certFile
+			cfg.ExperimentalAuthzWebhookKeyFile = tt.keyFile
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test %q, expected error %v, got %v", tt.name, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClientListenerGroupsValidate(t *testing.T) {
+	tlsInfo := transport.TLSInfo{CertFile: "cert", KeyFile: "key"}
+	tests := []struct {
+		name    string
+		groups  []ClientListenerGroup
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			groups: []ClientListenerGroup{
+				{Name: "mtls", ListenClientUrls: types.MustNewURLs([]string{"https://127.0.0.1:2479"}), TLSInfo: tlsInfo, AuthModes: []string{ClientAuthModeCert}},
+				{Name: "sidecars", ListenClientUrls: types.MustNewURLs([]string{"http://127.0.0.1:2579"})},
+			},
+		},
+		{
+			name:    "no name",
+			groups:  []ClientListenerGroup{{ListenClientUrls: types.MustNewURLs([]string{"http://127.0.0.1:2579"})}},
+			wantErr: true,
+		},
+		{
+			name:    "no urls",
+			groups:  []ClientListenerGroup{{Name: "sidecars"}},
+			wantErr: true,
+		},
+		{
+			name:    "https without TLS",
+			groups:  []ClientListenerGroup{{Name: "mtls", ListenClientUrls: types.MustNewURLs([]string{"https://127.0.0.1:2479"})}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown auth mode",
+			groups:  []ClientListenerGroup{{Name: "sidecars", ListenClientUrls: types.MustNewURLs([]string{"http://127.0.0.1:2579"}), AuthModes: []string{"password"}}},
+			wantErr: true,
+		},
+		{
+			name:    "url of listen-client-urls",
+			groups:  []ClientListenerGroup{{Name: "sidecars", ListenClientUrls: types.MustNewURLs([]string{"http://localhost:2379"})}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			groups: []ClientListenerGroup{
+				{Name: "sidecars", ListenClientUrls: types.MustNewURLs([]string{"http://127.0.0.1:2579"})},
+				{Name: "sidecars", ListenClientUrls: types.MustNewURLs([]string{"http://127.0.0.1:2679"})},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("ClientListenerGroups=true"))
+			cfg.ExperimentalClientListenerGroups = tt.groups
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test %q, expected error %v, got %v", tt.name, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigFileClientListenerGroups(t *testing.T) {
+	b := []byte(`
+feature-gates: ClientListenerGroups=true
+experimental-client-listener-groups:
+- name: mtls
+  listen-client-urls: https://127.0.0.1:2479
+  client-transport-security:
+    cert-file: cert
+    key-file: key
+    client-cert-auth: true
+  auth-modes: [cert]
+`)
+	tmpfile := mustCreateCfgFile(t, b)
+	defer os.Remove(tmpfile.Name())
+
+	cfg, err := ConfigFromFile(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ClientListenerGroup{{
+		Name:             "mtls",
+		ListenClientUrls: types.MustNewURLs([]string{"https://127.0.0.1:2479"}),
+		TLSInfo:          transport.TLSInfo{CertFile: "cert", KeyFile: "key", ClientCertAuth: true},
+		AuthModes:        []string{ClientAuthModeCert},
+	}}, cfg.ExperimentalClientListenerGroups)
+	assert.True(t, cfg.clientCertAuthEnabled())
+}
+
+func TestConfigFileIncludeAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	writeFile("common.yaml", `
+quota-backend-bytes: 9007199254740993
+snapshot-count: 1000
+client-transport-security:
+  cert-file: common-cert
+  key-file: common-key
+`)
+	writeFile("tls.yaml", `
+snapshot-count: 2000
+client-transport-security:
+  client-cert-auth: true
+`)
+	path := writeFile("member.yaml", `
+include: [common.yaml, tls.yaml]
+name: ${TEST_MEMBER_NAME}
+data-dir: ${TEST_UNSET_DATA_DIR:-/var/lib/etcd}
+client-transport-security:
+  key-file: $${literal}
+`)
+	t.Setenv("TEST_MEMBER_NAME", "m1")
+
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "m1", cfg.Name)
+	assert.Equal(t, "/var/lib/etcd", cfg.Dir)
+	assert.Equal(t, int64(9007199254740993), cfg.QuotaBackendBytes)
+	assert.Equal(t, uint64(2000), cfg.SnapshotCount)
+	assert.Equal(t, "common-cert", cfg.ClientTLSInfo.CertFile)
+	assert.Equal(t, "${literal}", cfg.ClientTLSInfo.KeyFile)
+	assert.True(t, cfg.ClientTLSInfo.ClientCertAuth)
+
+	t.Run("unset variable", func(t *testing.T) {
+		_, err := ConfigFromFile(writeFile("unset.yaml", "name: ${TEST_UNSET_MEMBER_NAME}\n"))
+		assert.ErrorContains(t, err, `environment variable "TEST_UNSET_MEMBER_NAME" is not set`)
+	})
+	t.Run("include cycle", func(t *testing.T) {
+		writeFile("a.yaml", "include: b.yaml\n")
+		writeFile("b.yaml", "include: a.yaml\n")
+		_, err := ConfigFromFile(filepath.Join(dir, "a.yaml"))
+		assert.ErrorContains(t, err, "includes itself")
+	})
+}
+
 func TestTLSVersionMinMax(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -507,3 +857,214 @@ func TestTLSVersionMinMax(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupFeatureGates(t *testing.T) {
+	tcs := []struct {
+		name         string
+		featureGates string
+		configFunc   func(cfg *Config)
+		expectError  bool
+		expected     map[featuregate.Feature]bool
+	}{
+		{
+			name:     "Default config keeps the default features",
+			expected: map[featuregate.Feature]bool{features.InitialCorruptCheck: false, features.TxnModeWriteWithSharedBuffer: true},
+		},
+		{
+			name:         "Feature gates set the experimental flags",
+			featureGates: "InitialCorruptCheck=true,TxnModeWriteWithSharedBuffer=false",
+			expected:     map[featuregate.Feature]bool{features.InitialCorruptCheck: true, features.TxnModeWriteWithSharedBuffer: false},
+		},
+		{
+			name: "Experimental flags set the feature gates",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalInitialCorruptCheck = true
+				cfg.ExperimentalTxnModeWriteWithSharedBuffer = false
+			},
+			expected: map[featuregate.Feature]bool{features.InitialCorruptCheck: true, features.TxnModeWriteWithSharedBuffer: false},
+		},
+		{
+			name:         "Experimental flag agreeing with the feature gate should pass",
+			featureGates: "InitialCorruptCheck=true",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalInitialCorruptCheck = true
+			},
+			expected: map[featuregate.Feature]bool{features.InitialCorruptCheck: true},
+		},
+		{
+			name:         "Experimental flag conflicting with the feature gate should fail",
+			featureGates: "InitialCorruptCheck=false",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalInitialCorruptCheck = true
+			},
+			expectError: true,
+		},
+		{
+			name:         "Experimental flag explicitly set to its default conflicting with the feature gate should fail",
+			featureGates: "InitialCorruptCheck=true",
+			configFunc: func(cfg *Config) {
+				cfg.FlagsExplicitlySet = map[string]bool{"experimental-initial-corrupt-check": true}
+			},
+			expectError: true,
+		},
+		{
+			name:         "Feature gate enabling lease checkpoint persist only should fail",
+			featureGates: "LeaseCheckpointPersist=true",
+			expectError:  true,
+		},
+		{
+			name:         "Experimental flag tuning an enabled feature should pass",
+			featureGates: "WatchRateLimit=true",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalWatchMaxEventsPerSecond = 100
+			},
+			expected: map[featuregate.Feature]bool{features.WatchRateLimit: true},
+		},
+		{
+			name: "Experimental flag tuning a disabled feature should fail",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalWatchMaxEventsPerSecond = 100
+			},
+			expectError: true,
+		},
+		{
+			name:         "Experimental flag tuning a feature disabled by default should fail",
+			featureGates: "CompactionPacing=false",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalCompactionMinBatchLimit = 10
+			},
+			expectError: true,
+		},
+		{
+			name: "Change feeds of a disabled feature should fail",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalChangeFeeds = []v3changefeed.Config{{Name: "a", Prefixes: []string{"/a/"}}}
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if tc.featureGates != "" {
+				require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set(tc.featureGates))
+			}
+			if tc.configFunc != nil {
+				tc.configFunc(cfg)
+			}
+			err := cfg.Validate()
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			flags := cfg.experimentalFeatureFlags()
+			for name, feature := range features.ExperimentalFlagToFeatureMap {
+				assert.Equal(t, cfg.ServerFeatureGate.Enabled(feature), *flags[name], name)
+			}
+			for feature, enabled := range tc.expected {
+				assert.Equal(t, enabled, cfg.ServerFeatureGate.Enabled(feature), feature)
+			}
+			// validating again keeps the features
+			require.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestConfigFileFeatureGates(t *testing.T) {
+	b := []byte(`
+feature-gates: InitialCorruptCheck=true,PrefixStats=true
+experimental-prefix-stats: true
+`)
+	tmpfile := mustCreateCfgFile(t, b)
+	defer os.Remove(tmpfile.Name())
+
+	cfg, err := ConfigFromFile(tmpfile.Name())
+	require.NoError(t, err)
+	assert.True(t, cfg.ServerFeatureGate.Enabled(features.InitialCorruptCheck))
+	assert.True(t, cfg.ServerFeatureGate.Enabled(features.PrefixStats))
+	assert.False(t, cfg.ServerFeatureGate.Enabled(features.HotKeyTracking))
+	assert.True(t, cfg.ExperimentalInitialCorruptCheck)
+}
+
+func TestSetupGraduatedFlags(t *testing.T) {
+	tcs := []struct {
+		name        string
+		configFunc  func(cfg *Config)
+		expectError bool
+		expected    time.Duration
+	}{
+		{
+			name:     "Default config keeps the default",
+			expected: 0,
+		},
+		{
+			name: "Stable flag is set",
+			configFunc: func(cfg *Config) {
+				cfg.CorruptCheckTime = time.Minute
+			},
+			expected: time.Minute,
+		},
+		{
+			name: "Experimental flag sets the stable flag",
+			configFunc: func(cfg *Config) {
+				cfg.ExperimentalCorruptCheckTime = time.Minute
+			},
+			expected: time.Minute,
+		},
+		{
+			name: "Experimental flag explicitly set to its default sets the stable flag",
+			configFunc: func(cfg *Config) {
+				cfg.FlagsExplicitlySet = map[string]bool{"experimental-corrupt-check-time": true}
+			},
+			expected: 0,
+		},
+		{
+			name: "Both flags set should fail",
+			configFunc: func(cfg *Config) {
+				cfg.CorruptCheckTime = time.Minute
+				cfg.ExperimentalCorruptCheckTime = time.Hour
+			},
+			expectError: true,
+		},
+		{
+			name: "Both flags explicitly set to the same value should fail",
+			configFunc: func(cfg *Config) {
+				cfg.CorruptCheckTime = time.Minute
+				cfg.ExperimentalCorruptCheckTime = time.Minute
+				cfg.FlagsExplicitlySet = map[string]bool{"corrupt-check-time": true, "experimental-corrupt-check-time": true}
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConfig()
+			if tc.configFunc != nil {
+				tc.configFunc(cfg)
+			}
+			err := cfg.Validate()
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg.CorruptCheckTime)
+			// validating again keeps the flags
+			require.NoError(t, cfg.Validate())
+			assert.Equal(t, tc.expected, cfg.CorruptCheckTime)
+		})
+	}
+}
+
+func TestConfigFileGraduatedFlags(t *testing.T) {
+	b := []byte(`
+experimental-compaction-batch-limit: 1000
+compaction-batch-limit: 100
+`)
+	tmpfile := mustCreateCfgFile(t, b)
+	defer os.Remove(tmpfile.Name())
+
+	_, err := ConfigFromFile(tmpfile.Name())
+	require.ErrorContains(t, err, "both --experimental-compaction-batch-limit and --compaction-batch-limit flags are set")
+}
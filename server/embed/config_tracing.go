@@ -49,7 +49,7 @@ type tracingExporter struct {
 func newTracingExporter(ctx context.Context, cfg *Config) (*tracingExporter, error) {
 	exporter, err := otlptracegrpc.New(ctx,
 		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(cfg.ExperimentalDistributedTracingAddress),
+		otlptracegrpc.WithEndpoint(cfg.DistributedTracingAddress),
 	)
 	if err != nil {
 		return nil, err
@@ -57,14 +57,14 @@ func newTracingExporter(ctx context.Context, cfg *Config) (*tracingExporter, err
 
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
-			semconv.ServiceNameKey.String(cfg.ExperimentalDistributedTracingServiceName),
+			semconv.ServiceNameKey.String(cfg.DistributedTracingServiceName),
 		),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if resWithIDKey := determineResourceWithIDKey(cfg.ExperimentalDistributedTracingServiceInstanceID); resWithIDKey != nil {
+	if resWithIDKey := determineResourceWithIDKey(cfg.DistributedTracingServiceInstanceID); resWithIDKey != nil {
 		// Merge resources into a new
 		// resource in case of duplicates.
 		res, err = resource.Merge(res, resWithIDKey)
@@ -77,7 +77,7 @@ func newTracingExporter(ctx context.Context, cfg *Config) (*tracingExporter, err
 		tracesdk.WithBatcher(exporter),
 		tracesdk.WithResource(res),
 		tracesdk.WithSampler(
-			tracesdk.ParentBased(determineSampler(cfg.ExperimentalDistributedTracingSamplingRatePerMillion)),
+			tracesdk.ParentBased(determineSampler(cfg.DistributedTracingSamplingRatePerMillion)),
 		),
 	)
 
@@ -95,10 +95,10 @@ func newTracingExporter(ctx context.Context, cfg *Config) (*tracingExporter, err
 
 	cfg.logger.Debug(
 		"distributed tracing enabled",
-		zap.String("address", cfg.ExperimentalDistributedTracingAddress),
-		zap.String("service-name", cfg.ExperimentalDistributedTracingServiceName),
-		zap.String("service-instance-id", cfg.ExperimentalDistributedTracingServiceInstanceID),
-		zap.Int("sampling-rate", cfg.ExperimentalDistributedTracingSamplingRatePerMillion),
+		zap.String("address", cfg.DistributedTracingAddress),
+		zap.String("service-name", cfg.DistributedTracingServiceName),
+		zap.String("service-instance-id", cfg.DistributedTracingServiceInstanceID),
+		zap.Int("sampling-rate", cfg.DistributedTracingSamplingRatePerMillion),
 	)
 
 	return &tracingExporter{
@@ -34,16 +34,22 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/debugutil"
 	runtimeutil "go.etcd.io/etcd/pkg/v3/runtime"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/etcdhttp"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
 	"go.etcd.io/etcd/server/v3/storage"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 	"go.etcd.io/etcd/server/v3/verify"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/soheilhy/cmux"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
@@ -71,9 +77,12 @@ type Etcd struct {
 	metricsListeners []net.Listener
 
 	tracingExporterShutdown func()
+	auditLogClose           func()
 
 	Server *etcdserver.EtcdServer
 
+	changeFeeds []*v3changefeed.ChangeFeed
+
 	cfg   Config
 	stopc chan struct{}
 	errc  chan error
@@ -118,11 +127,18 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 			zap.Bool("reuse-port", cfg.SocketOpts.ReusePort),
 		)
 	}
+	var inherited *inheritedListeners
+	if cfg.ExperimentalSocketActivation {
+		if inherited, err = newInheritedListeners(cfg.logger); err != nil {
+			return e, err
+		}
+	}
 	e.cfg.logger.Info(
 		"configuring peer listeners",
 		zap.Strings("listen-peer-urls", e.cfg.getLPURLs()),
 	)
-	if e.Peers, err = configurePeerListeners(cfg); err != nil {
+	if e.Peers, err = configurePeerListeners(cfg, inherited); err != nil {
+		inherited.close()
 		return e, err
 	}
 
@@ -130,13 +146,17 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		"configuring client listeners",
 		zap.Strings("listen-client-urls", e.cfg.getLCURLs()),
 	)
-	if e.sctxs, err = configureClientListeners(cfg); err != nil {
+	if e.sctxs, err = configureClientListeners(cfg, inherited); err != nil {
+		inherited.close()
 		return e, err
 	}
 
 	for _, sctx := range e.sctxs {
 		e.Clients = append(e.Clients, sctx.l)
 	}
+	if err = inherited.close(); err != nil {
+		return e, err
+	}
 
 	var (
 		urlsmap types.URLsMap
@@ -162,6 +182,39 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 
 	backendFreelistType := parseBackendFreelistType(cfg.BackendFreelistType)
 
+	var secondaryIndexes []mvcc.SecondaryIndex
+	for _, s := range cfg.ExperimentalSecondaryIndexes {
+		si, err := mvcc.ParseSecondaryIndex(s)
+		if err != nil {
+			return e, err
+		}
+		secondaryIndexes = append(secondaryIndexes, si)
+	}
+	var sanRoleRules []auth.SANRoleRule
+	for _, s := range cfg.ExperimentalClientCertSANRoleRules {
+		r, err := auth.ParseSANRoleRule(s)
+		if err != nil {
+			return e, err
+		}
+		sanRoleRules = append(sanRoleRules, r)
+	}
+	passwordPolicy := auth.PasswordPolicy{
+		MinLength:      cfg.ExperimentalPasswordMinLength,
+		MinCharClasses: cfg.ExperimentalPasswordMinCharClasses,
+	}
+	var versionRetention []mvcc.VersionRetention
+	for _, s := range cfg.ExperimentalVersionRetention {
+		vr, err := mvcc.ParseVersionRetention(s)
+		if err != nil {
+			return e, err
+		}
+		versionRetention = append(versionRetention, vr)
+	}
+	valueCompression, err := mvcc.ParseValueCompression(cfg.ExperimentalValueCompression)
+	if err != nil {
+		return e, err
+	}
+
 	srvcfg := config.ServerConfig{
 		Name:                                     cfg.Name,
 		ClientURLs:                               cfg.ACUrls,
@@ -181,11 +234,13 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		PeerTLSInfo:                              cfg.PeerTLSInfo,
 		TickMs:                                   cfg.TickMs,
 		ElectionTicks:                            cfg.ElectionTicks(),
-		WaitClusterReadyTimeout:                  cfg.ExperimentalWaitClusterReadyTimeout,
+		WaitClusterReadyTimeout:                  cfg.WaitClusterReadyTimeout,
 		InitialElectionTickAdvance:               cfg.InitialElectionTickAdvance,
 		AutoCompactionRetention:                  autoCompactionRetention,
 		AutoCompactionMode:                       cfg.AutoCompactionMode,
+		AutoCompactionMaxRevisions:               cfg.AutoCompactionMaxRevisions,
 		QuotaBackendBytes:                        cfg.QuotaBackendBytes,
+		MaxQuotaBackendBytes:                     cfg.ExperimentalMaxQuotaBackendBytes,
 		BackendBatchLimit:                        cfg.BackendBatchLimit,
 		BackendFreelistType:                      backendFreelistType,
 		BackendBatchInterval:                     cfg.BackendBatchInterval,
@@ -194,35 +249,69 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		MaxConcurrentStreams:                     cfg.MaxConcurrentStreams,
 		SocketOpts:                               cfg.SocketOpts,
 		StrictReconfigCheck:                      cfg.StrictReconfigCheck,
-		ClientCertAuthEnabled:                    cfg.ClientTLSInfo.ClientCertAuth,
+		ClientCertAuthEnabled:                    cfg.clientCertAuthEnabled(),
+		ClientCertSANRoleRules:                   sanRoleRules,
 		AuthToken:                                cfg.AuthToken,
 		BcryptCost:                               cfg.BcryptCost,
 		TokenTTL:                                 cfg.AuthTokenTTL,
+		PasswordPolicy:                           passwordPolicy,
 		CORS:                                     cfg.CORS,
 		HostWhitelist:                            cfg.HostWhitelist,
 		InitialCorruptCheck:                      cfg.ExperimentalInitialCorruptCheck,
-		CorruptCheckTime:                         cfg.ExperimentalCorruptCheckTime,
+		CorruptCheckTime:                         cfg.CorruptCheckTime,
 		CompactHashCheckEnabled:                  cfg.ExperimentalCompactHashCheckEnabled,
-		CompactHashCheckTime:                     cfg.ExperimentalCompactHashCheckTime,
+		CompactHashCheckTime:                     cfg.CompactHashCheckTime,
+		CorruptCheckSchedule:                     cfg.ExperimentalCorruptCheckSchedule,
+		CorruptCheckScope:                        cfg.ExperimentalCorruptCheckScope,
+		CorruptCheckSamples:                      cfg.ExperimentalCorruptCheckSamples,
 		PreVote:                                  cfg.PreVote,
 		Logger:                                   cfg.logger,
 		ForceNewCluster:                          cfg.ForceNewCluster,
 		EnableGRPCGateway:                        cfg.EnableGRPCGateway,
-		ExperimentalEnableDistributedTracing:     cfg.ExperimentalEnableDistributedTracing,
+		ExperimentalEnableDistributedTracing:     cfg.EnableDistributedTracing,
 		UnsafeNoFsync:                            cfg.UnsafeNoFsync,
 		EnableLeaseCheckpoint:                    cfg.ExperimentalEnableLeaseCheckpoint,
 		LeaseCheckpointPersist:                   cfg.ExperimentalEnableLeaseCheckpointPersist,
-		CompactionBatchLimit:                     cfg.ExperimentalCompactionBatchLimit,
-		CompactionSleepInterval:                  cfg.ExperimentalCompactionSleepInterval,
-		WatchProgressNotifyInterval:              cfg.ExperimentalWatchProgressNotifyInterval,
-		DowngradeCheckTime:                       cfg.ExperimentalDowngradeCheckTime,
-		WarningApplyDuration:                     cfg.ExperimentalWarningApplyDuration,
+		LeaseCheckpointInterval:                  cfg.ExperimentalLeaseCheckpointInterval,
+		LeaseCheckpointOnRenew:                   cfg.ExperimentalLeaseCheckpointOnRenew,
+		MaxLeaseAttachedKeys:                     cfg.ExperimentalMaxLeaseAttachedKeys,
+		CompactionBatchLimit:                     cfg.CompactionBatchLimit,
+		CompactionSleepInterval:                  cfg.CompactionSleepInterval,
+		CompactionBatchTargetDuration:            cfg.ExperimentalCompactionBatchTargetDuration,
+		CompactionMinBatchLimit:                  cfg.ExperimentalCompactionMinBatchLimit,
+		CompactionRevisionAlignment:              cfg.ExperimentalCompactionRevisionAlignment,
+		VersionRetention:                         versionRetention,
+		SecondaryIndexes:                         secondaryIndexes,
+		HotKeyTracking:                           cfg.ExperimentalHotKeyTracking,
+		PrefixStats:                              cfg.ExperimentalPrefixStats,
+		ValueCompression:                         valueCompression,
+		ValueCompressionThreshold:                cfg.ExperimentalValueCompressionThreshold,
+		MaxChunkedValueBytes:                     cfg.ExperimentalMaxChunkedValueBytes,
+		WatchProgressNotifyInterval:              cfg.WatchProgressNotifyInterval,
+		WatchMaxStartRevisionLag:                 cfg.ExperimentalWatchMaxStartRevisionLag,
+		WatchMaxStartRevisionAge:                 cfg.ExperimentalWatchMaxStartRevisionAge,
+		WatchMaxEventsPerSecond:                  cfg.ExperimentalWatchMaxEventsPerSecond,
+		WatchMaxQueuedEvents:                     cfg.ExperimentalWatchMaxQueuedEvents,
+		WatchOverflowPolicy:                      cfg.ExperimentalWatchOverflowPolicy,
+		WatchBacklogAlarmEvents:                  cfg.ExperimentalWatchBacklogAlarmEvents,
+		DrainTimeout:                             cfg.ExperimentalDrainTimeout,
+		BoundedStalenessMaxLag:                   cfg.ExperimentalBoundedStalenessMaxLag,
+		DowngradeCheckTime:                       cfg.DowngradeCheckTime,
+		WarningApplyDuration:                     cfg.WarningApplyDuration,
 		WarningUnaryRequestDuration:              cfg.WarningUnaryRequestDuration,
-		ExperimentalMemoryMlock:                  cfg.ExperimentalMemoryMlock,
+		ApplyHooks:                               cfg.ExperimentalApplyHooks,
+		ExperimentalMemoryMlock:                  cfg.MemoryMlock,
 		ExperimentalTxnModeWriteWithSharedBuffer: cfg.ExperimentalTxnModeWriteWithSharedBuffer,
-		ExperimentalBootstrapDefragThresholdMegabytes: cfg.ExperimentalBootstrapDefragThresholdMegabytes,
-		ExperimentalMaxLearners:                       cfg.ExperimentalMaxLearners,
+		ExperimentalBootstrapDefragThresholdMegabytes: cfg.BootstrapDefragThresholdMegabytes,
+		ExperimentalIncrementalDefrag:                 cfg.ExperimentalIncrementalDefrag,
+		ExperimentalAutoDefragThreshold:               cfg.ExperimentalAutoDefragThreshold,
+		ExperimentalAutoDefragSchedule:                cfg.ExperimentalAutoDefragSchedule,
+		ExperimentalBackendEngine:                     cfg.ExperimentalBackendEngine,
+		ExperimentalMaxLearners:                       cfg.MaxLearners,
+		ExperimentalLearnerAutoPromoteLag:             cfg.ExperimentalLearnerAutoPromoteLag,
+		ExperimentalLearnerAutoPromoteDuration:        cfg.ExperimentalLearnerAutoPromoteDuration,
 		V2Deprecation:                                 cfg.V2DeprecationEffective(),
+		ServerFeatureGate:                             cfg.ServerFeatureGate,
 	}
 
 	if srvcfg.ExperimentalEnableDistributedTracing {
@@ -241,6 +330,48 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		)
 	}
 
+	if cfg.ExperimentalAuditLogPath != "" {
+		if srvcfg.AuditLogger, e.auditLogClose, err = cfg.setupAuditLogger(); err != nil {
+			return e, err
+		}
+		srvcfg.AuditLogIncludePrefixes = cfg.ExperimentalAuditLogIncludePrefixes
+		srvcfg.AuditLogExcludePrefixes = cfg.ExperimentalAuditLogExcludePrefixes
+
+		e.cfg.logger.Info(
+			"audit log enabled",
+			zap.String("audit-log-path", cfg.ExperimentalAuditLogPath),
+		)
+	}
+
+	if authorizer := cfg.ExperimentalAuthorizer; authorizer != nil || cfg.ExperimentalAuthzWebhookURL != "" {
+		if authorizer == nil {
+			tr, err := transport.NewTransport(transport.TLSInfo{
+				TrustedCAFile: cfg.ExperimentalAuthzWebhookTrustedCAFile,
+				CertFile:      cfg.ExperimentalAuthzWebhookCertFile,
+				KeyFile:       cfg.ExperimentalAuthzWebhookKeyFile,
+			}, cfg.ExperimentalAuthzWebhookTimeout)
+			if err != nil {
+				return e, err
+			}
+			authorizer = v3authz.NewWebhook(cfg.ExperimentalAuthzWebhookURL, &http.Client{
+				Transport: tr,
+				Timeout:   cfg.ExperimentalAuthzWebhookTimeout,
+			})
+		}
+		srvcfg.Authorizer = v3authz.NewChecker(e.cfg.logger, authorizer, v3authz.Config{
+			Timeout:  cfg.ExperimentalAuthzWebhookTimeout,
+			CacheTTL: cfg.ExperimentalAuthzCacheTTL,
+			FailOpen: cfg.ExperimentalAuthzFailOpen,
+		})
+
+		e.cfg.logger.Info(
+			"external authorizer enabled",
+			zap.String("authz-webhook-url", cfg.ExperimentalAuthzWebhookURL),
+			zap.Duration("authz-cache-ttl", cfg.ExperimentalAuthzCacheTTL),
+			zap.Bool("authz-fail-open", cfg.ExperimentalAuthzFailOpen),
+		)
+	}
+
 	print(e.cfg.logger, *cfg, srvcfg, memberInitialized)
 
 	if e.Server, err = etcdserver.NewServer(srvcfg); err != nil {
@@ -265,6 +396,9 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 	}
 	e.Server.Start()
 
+	if err = e.startChangeFeeds(); err != nil {
+		return e, err
+	}
 	if err = e.servePeers(); err != nil {
 		return e, err
 	}
@@ -341,7 +475,12 @@ func print(lg *zap.Logger, ec Config, sc config.ServerConfig, memberInitialized
 		zap.String("initial-cluster-state", ec.ClusterState),
 		zap.String("initial-cluster-token", sc.InitialClusterToken),
 		zap.Int64("quota-backend-bytes", quota),
+		zap.Int64("max-quota-backend-bytes", sc.MaxQuotaBackendBytes),
+		zap.String("backend-engine", sc.ExperimentalBackendEngine),
+		zap.Stringer("value-compression", sc.ValueCompression),
+		zap.Int("value-compression-threshold", sc.ValueCompressionThreshold),
 		zap.Uint("max-request-bytes", sc.MaxRequestBytes),
+		zap.Uint("max-chunked-value-bytes", sc.MaxChunkedValueBytes),
 		zap.Uint32("max-concurrent-streams", sc.MaxConcurrentStreams),
 
 		zap.Bool("pre-vote", sc.PreVote),
@@ -349,9 +488,14 @@ func print(lg *zap.Logger, ec Config, sc config.ServerConfig, memberInitialized
 		zap.String("corrupt-check-time-interval", sc.CorruptCheckTime.String()),
 		zap.Bool("compact-check-time-enabled", sc.CompactHashCheckEnabled),
 		zap.Duration("compact-check-time-interval", sc.CompactHashCheckTime),
+		zap.String("corrupt-check-schedule", sc.CorruptCheckSchedule),
+		zap.String("corrupt-check-scope", sc.CorruptCheckScope),
+		zap.Int("corrupt-check-samples", sc.CorruptCheckSamples),
 		zap.String("auto-compaction-mode", sc.AutoCompactionMode),
 		zap.Duration("auto-compaction-retention", sc.AutoCompactionRetention),
 		zap.String("auto-compaction-interval", sc.AutoCompactionRetention.String()),
+		zap.Int64("auto-compaction-max-revisions", sc.AutoCompactionMaxRevisions),
+		zap.Int64("compaction-revision-alignment", sc.CompactionRevisionAlignment),
 		zap.String("discovery-url", sc.DiscoveryURL),
 		zap.String("discovery-proxy", sc.DiscoveryProxy),
 
@@ -378,6 +522,59 @@ func (e *Etcd) Config() Config {
 	return e.cfg
 }
 
+// Reconfigure applies the settings of cfg that can be changed at runtime, the
+// log level, the auto compaction retention, the backend quota, the corruption
+// check interval and the durations of the slow requests warnings, ignoring the
+// others.
+func (e *Etcd) Reconfigure(cfg *Config) error {
+	retention := cfg.AutoCompactionRetention
+	if retention == "" {
+		retention = "0"
+	}
+	autoCompactionRetention, err := parseCompactionRetention(e.cfg.AutoCompactionMode, retention)
+	if err != nil {
+		return err
+	}
+	var lvl zapcore.Level
+	if err = lvl.Set(cfg.LogLevel); err != nil {
+		return fmt.Errorf("invalid log level %q: %v", cfg.LogLevel, err)
+	}
+	if e.cfg.loggerLevel == nil && cfg.LogLevel != e.cfg.LogLevel {
+		return fmt.Errorf("log level of a custom logger cannot be changed")
+	}
+	if err = cfg.setupGraduatedFlags(); err != nil {
+		return err
+	}
+	warningUnaryRequestDuration := cfg.WarningUnaryRequestDuration
+	if warningUnaryRequestDuration == 0 {
+		warningUnaryRequestDuration = DefaultWarningUnaryRequestDuration
+	}
+
+	if err = e.Server.Reconfigure(etcdserver.RuntimeConfig{
+		AutoCompactionRetention:     autoCompactionRetention,
+		QuotaBackendBytes:           cfg.QuotaBackendBytes,
+		CorruptCheckTime:            cfg.CorruptCheckTime,
+		WarningApplyDuration:        cfg.WarningApplyDuration,
+		WarningUnaryRequestDuration: warningUnaryRequestDuration,
+	}); err != nil {
+		return err
+	}
+	if e.cfg.loggerLevel != nil {
+		e.cfg.loggerLevel.SetLevel(lvl)
+	}
+	return nil
+}
+
+// ReconfigureFromFile applies the settings of the config file at path that
+// can be changed at runtime, like Reconfigure, returning the config read.
+func (e *Etcd) ReconfigureFromFile(path string) (*Config, error) {
+	cfg, err := runtimeConfigFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, e.Reconfigure(cfg)
+}
+
 // Close gracefully shuts down all servers/listeners.
 // Client requests will be terminated with request timeout.
 // After timeout, enforce remaning requests be closed immediately.
@@ -403,6 +600,9 @@ func (e *Etcd) Close() {
 	e.closeOnce.Do(func() {
 		close(e.stopc)
 	})
+	if e.Server != nil {
+		e.Server.SetLifecycle(etcdserver.LifecycleDraining, "closing the client listeners")
+	}
 
 	// close client requests with request timeout
 	timeout := 2 * time.Second
@@ -436,6 +636,10 @@ func (e *Etcd) Close() {
 		e.tracingExporterShutdown()
 	}
 
+	for _, f := range e.changeFeeds {
+		f.Stop()
+	}
+
 	// close rafthttp transports
 	if e.Server != nil {
 		e.Server.Stop()
@@ -449,6 +653,11 @@ func (e *Etcd) Close() {
 			cancel()
 		}
 	}
+
+	// close the audit log once the client requests are done
+	if e.auditLogClose != nil {
+		e.auditLogClose()
+	}
 	if e.errc != nil {
 		close(e.errc)
 	}
@@ -492,7 +701,7 @@ func (e *Etcd) Err() <-chan error {
 	return e.errc
 }
 
-func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
+func configurePeerListeners(cfg *Config, inherited *inheritedListeners) (peers []*peerListener, err error) {
 	if err = updateCipherSuites(&cfg.PeerTLSInfo, cfg.CipherSuites); err != nil {
 		return nil, err
 	}
@@ -541,6 +750,10 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 			transport.WithTLSInfo(&cfg.PeerTLSInfo),
 			transport.WithSocketOpts(&cfg.SocketOpts),
 			transport.WithTimeout(rafthttp.ConnReadTimeout, rafthttp.ConnWriteTimeout),
+			transport.WithInheritedListener(inherited.take(u)),
+			transport.WithListenerWrapper(func(l net.Listener) net.Listener {
+				return newConnLimitListener(cfg.logger, l, "peer", cfg.ExperimentalPeerMaxConnections, cfg.ExperimentalPeerAcceptRate)
+			}),
 		)
 		if err != nil {
 			return nil, err
@@ -605,7 +818,7 @@ func (e *Etcd) servePeers() (err error) {
 	return nil
 }
 
-func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err error) {
+func configureClientListeners(cfg *Config, inherited *inheritedListeners) (sctxs map[string]*serveCtx, err error) {
 	if err = updateCipherSuites(&cfg.ClientTLSInfo, cfg.CipherSuites); err != nil {
 		return nil, err
 	}
@@ -617,18 +830,49 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 		cfg.logger.Info("pprof is enabled", zap.String("path", debugutil.HTTPPrefixPProf))
 	}
 
-	sctxs = make(map[string]*serveCtx)
+	// the listeners of the client listener groups are served with the TLS
+	// and the authentication modes of their groups
+	type clientURL struct {
+		u         url.URL
+		tlsinfo   *transport.TLSInfo
+		authModes *etcdserver.ClientAuthModes
+	}
+	var curls []clientURL
 	for _, u := range cfg.LCUrls {
+		curls = append(curls, clientURL{u: u, tlsinfo: &cfg.ClientTLSInfo})
+	}
+	for i := range cfg.ExperimentalClientListenerGroups {
+		g := &cfg.ExperimentalClientListenerGroups[i]
+		if err = updateCipherSuites(&g.TLSInfo, cfg.CipherSuites); err != nil {
+			return nil, err
+		}
+		updateMinMaxVersions(&g.TLSInfo, cfg.TlsMinVersion, cfg.TlsMaxVersion)
+		authModes := g.authModes()
+		cfg.logger.Info(
+			"configuring client listener group",
+			zap.String("name", g.Name),
+			zap.Strings("listen-client-urls", types.URLs(g.ListenClientUrls).StringSlice()),
+			zap.Bool("token-auth", authModes.Token),
+			zap.Bool("cert-auth", authModes.Cert),
+		)
+		for _, u := range g.ListenClientUrls {
+			curls = append(curls, clientURL{u: u, tlsinfo: &g.TLSInfo, authModes: &authModes})
+		}
+	}
+
+	sctxs = make(map[string]*serveCtx)
+	for _, cu := range curls {
+		u, tlsinfo := cu.u, cu.tlsinfo
 		sctx := newServeCtx(cfg.logger)
 		if u.Scheme == "http" || u.Scheme == "unix" {
-			if !cfg.ClientTLSInfo.Empty() {
+			if !tlsinfo.Empty() {
 				cfg.logger.Warn("scheme is HTTP while key and cert files are present; ignoring key and cert files", zap.String("client-url", u.String()))
 			}
-			if cfg.ClientTLSInfo.ClientCertAuth {
+			if tlsinfo.ClientCertAuth {
 				cfg.logger.Warn("scheme is HTTP while --client-cert-auth is enabled; ignoring client cert auth for this URL", zap.String("client-url", u.String()))
 			}
 		}
-		if (u.Scheme == "https" || u.Scheme == "unixs") && cfg.ClientTLSInfo.Empty() {
+		if (u.Scheme == "https" || u.Scheme == "unixs") && tlsinfo.Empty() {
 			return nil, fmt.Errorf("TLS key/cert (--cert-file, --key-file) must be provided for client url %s with HTTPS scheme", u.String())
 		}
 
@@ -651,6 +895,10 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 		if sctx.l, err = transport.NewListenerWithOpts(addr, u.Scheme,
 			transport.WithSocketOpts(&cfg.SocketOpts),
 			transport.WithSkipTLSInfoCheck(true),
+			transport.WithInheritedListener(inherited.take(u)),
+			transport.WithListenerWrapper(func(l net.Listener) net.Listener {
+				return newConnLimitListener(cfg.logger, l, "client", cfg.ExperimentalClientMaxConnections, cfg.ExperimentalClientAcceptRate)
+			}),
 		); err != nil {
 			return nil, err
 		}
@@ -684,6 +932,9 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 			sctx.userHandlers[k] = cfg.UserHandlers[k]
 		}
 		sctx.serviceRegister = cfg.ServiceRegister
+		if cu.authModes != nil {
+			sctx.tlsinfo, sctx.authModes = tlsinfo, cu.authModes
+		}
 		if cfg.EnablePprof || cfg.LogLevel == "debug" {
 			sctx.registerPprof()
 		}
@@ -710,6 +961,11 @@ func (e *Etcd) serveClients() (err error) {
 	etcdhttp.HandleVersion(mux, e.Server)
 	etcdhttp.HandleMetrics(mux)
 	etcdhttp.HandleHealth(e.cfg.logger, mux, e.Server)
+	if e.cfg.ExperimentalEnableSnapshotHTTP {
+		if err = etcdhttp.HandleSnapshot(e.cfg.logger, mux, e.Server, datadir.ToHTTPSnapshotDir(e.cfg.Dir)); err != nil {
+			return err
+		}
+	}
 
 	var gopts []grpc.ServerOption
 	if e.cfg.GRPCKeepAliveMinTime > time.Duration(0) {
@@ -726,12 +982,26 @@ func (e *Etcd) serveClients() (err error) {
 		}))
 	}
 
+	if len(e.cfg.ExperimentalUnaryInterceptors) > 0 {
+		gopts = append(gopts, grpc.ChainUnaryInterceptor(e.cfg.ExperimentalUnaryInterceptors...))
+	}
+	if len(e.cfg.ExperimentalStreamInterceptors) > 0 {
+		gopts = append(gopts, grpc.ChainStreamInterceptor(e.cfg.ExperimentalStreamInterceptors...))
+	}
+
 	// start client servers in each goroutine
 	for _, sctx := range e.sctxs {
 		go func(s *serveCtx) {
 			e.errHandler(s.serve(e.Server, &e.cfg.ClientTLSInfo, mux, e.errHandler, gopts...))
 		}(sctx)
 	}
+	go func() {
+		select {
+		case <-e.Server.ReadyNotify():
+			e.Server.SetLifecycle(etcdserver.LifecycleServing, "serving the client requests")
+		case <-e.Server.StopNotify():
+		}
+	}()
 	return nil
 }
 
@@ -770,6 +1040,37 @@ func (e *Etcd) serveMetrics() (err error) {
 	return nil
 }
 
+// startChangeFeeds starts the configured change feeds, publishing events
+// while the member is the leader.
+func (e *Etcd) startChangeFeeds() error {
+	cfgs := append([]v3changefeed.Config{}, e.cfg.ExperimentalChangeFeeds...)
+	if e.cfg.ExperimentalChangeFeedWebhookURL != "" {
+		prefixes := e.cfg.ExperimentalChangeFeedPrefixes
+		if len(prefixes) == 0 {
+			prefixes = []string{""}
+		}
+		cfgs = append(cfgs, v3changefeed.Config{
+			Name:     "webhook",
+			Prefixes: prefixes,
+			Sink:     v3changefeed.NewWebhookSink(e.cfg.ExperimentalChangeFeedWebhookURL, nil),
+		})
+	}
+	names := make(map[string]struct{}, len(cfgs))
+	for _, cfg := range cfgs {
+		if _, ok := names[cfg.Name]; ok {
+			return fmt.Errorf("duplicate change feed name %q", cfg.Name)
+		}
+		names[cfg.Name] = struct{}{}
+		f, err := v3changefeed.New(e.cfg.logger, e.Server, cfg)
+		if err != nil {
+			return err
+		}
+		f.Run()
+		e.changeFeeds = append(e.changeFeeds, f)
+	}
+	return nil
+}
+
 func (e *Etcd) errHandler(err error) {
 	if err != nil {
 		e.GetLogger().Error("setting up serving from embedded etcd failed.", zap.Error(err))
@@ -799,7 +1100,7 @@ func parseCompactionRetention(mode, retention string) (ret time.Duration, err er
 		switch mode {
 		case CompactorModeRevision:
 			ret = time.Duration(int64(h))
-		case CompactorModePeriodic:
+		case CompactorModePeriodic, CompactorModeHybrid:
 			ret = time.Duration(int64(h)) * time.Hour
 		}
 	} else {
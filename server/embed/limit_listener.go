@@ -0,0 +1,192 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"errors"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrTooManyConnections is the reason of the connections rejected as
+	// their listener is at its maximum number of concurrent connections.
+	ErrTooManyConnections = errors.New("listener: too many concurrent connections")
+	// ErrAcceptRateExceeded is the reason of the connections rejected as
+	// their listener exceeds its accept rate.
+	ErrAcceptRateExceeded = errors.New("listener: accept rate exceeded")
+)
+
+// rejectWarnInterval is the minimum interval between the warnings logged for
+// the connections rejected by a listener, so that a connection storm does not
+// flood the logs.
+const rejectWarnInterval = 10 * time.Second
+
+var (
+	listenerActiveConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "network",
+		Name:      "listener_active_connections",
+		Help:      "The current number of connections of the limited client and peer listeners.",
+	},
+		[]string{"listener", "address"},
+	)
+
+	listenerRejectedConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "network",
+		Name:      "listener_rejected_connections_total",
+		Help:      "The total number of connections rejected by the client and peer listeners over their limits.",
+	},
+		[]string{"listener", "address", "reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(listenerActiveConnections)
+	prometheus.MustRegister(listenerRejectedConnections)
+}
+
+// connLimitListener rejects the connections exceeding its maximum number of
+// concurrent connections or its accept rate, closing them once accepted
+// rather than leaving them in the backlog of the socket, so that the clients
+// fail fast and back off.
+type connLimitListener struct {
+	net.Listener
+	lg   *zap.Logger
+	kind string
+	addr string
+
+	// maxConns is the maximum number of concurrent connections, 0 for no limit.
+	maxConns int64
+	// limiter limits the accept rate, nil for no limit.
+	limiter *rate.Limiter
+	conns   int64
+
+	active prometheus.Gauge
+
+	mu       sync.Mutex
+	lastWarn time.Time
+	// rejected are the connections rejected since the last warning.
+	rejected int
+}
+
+// newConnLimitListener limits the connections of l, a "client" or a "peer"
+// listener, to maxConns concurrent ones accepted at acceptRate per second at
+// most. It returns l as is without limits.
+func newConnLimitListener(lg *zap.Logger, l net.Listener, kind string, maxConns int, acceptRate float64) net.Listener {
+	if maxConns <= 0 && acceptRate <= 0 {
+		return l
+	}
+	addr := l.Addr().String()
+	ll := &connLimitListener{
+		Listener: l,
+		lg:       lg,
+		kind:     kind,
+		addr:     addr,
+		maxConns: int64(maxConns),
+		active:   listenerActiveConnections.WithLabelValues(kind, addr),
+	}
+	if acceptRate > 0 {
+		ll.limiter = rate.NewLimiter(rate.Limit(acceptRate), int(math.Ceil(acceptRate)))
+	}
+	lg.Info(
+		"limiting listener connections",
+		zap.String("listener", kind),
+		zap.String("address", addr),
+		zap.Int("max-connections", maxConns),
+		zap.Float64("accept-rate", acceptRate),
+	)
+	return ll
+}
+
+func (l *connLimitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if err = l.admit(); err != nil {
+			l.reject(c, err)
+			continue
+		}
+		l.active.Inc()
+		return &connLimitConn{Conn: c, l: l}, nil
+	}
+}
+
+func (l *connLimitListener) admit() error {
+	n := atomic.AddInt64(&l.conns, 1)
+	if l.maxConns > 0 && n > l.maxConns {
+		atomic.AddInt64(&l.conns, -1)
+		return ErrTooManyConnections
+	}
+	if l.limiter != nil && !l.limiter.Allow() {
+		atomic.AddInt64(&l.conns, -1)
+		return ErrAcceptRateExceeded
+	}
+	return nil
+}
+
+func (l *connLimitListener) reject(c net.Conn, reason error) {
+	remote := c.RemoteAddr().String()
+	c.Close()
+	r := "max-connections"
+	if reason == ErrAcceptRateExceeded {
+		r = "accept-rate"
+	}
+	listenerRejectedConnections.WithLabelValues(l.kind, l.addr, r).Inc()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rejected++
+	if time.Since(l.lastWarn) < rejectWarnInterval {
+		return
+	}
+	l.lg.Warn(
+		"rejected connections over the listener limits",
+		zap.String("listener", l.kind),
+		zap.String("address", l.addr),
+		zap.String("remote-addr", remote),
+		zap.Int("rejected", l.rejected),
+		zap.Int64("max-connections", l.maxConns),
+		zap.Error(reason),
+	)
+	l.lastWarn, l.rejected = time.Now(), 0
+}
+
+func (l *connLimitListener) release() {
+	atomic.AddInt64(&l.conns, -1)
+	l.active.Dec()
+}
+
+type connLimitConn struct {
+	net.Conn
+	l           *connLimitListener
+	releaseOnce sync.Once
+}
+
+func (c *connLimitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.l.release)
+	return err
+}
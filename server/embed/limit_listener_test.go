@@ -0,0 +1,129 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// acceptConns accepts the connections of l until it is closed.
+func acceptConns(l net.Listener) <-chan net.Conn {
+	connc := make(chan net.Conn, 16)
+	go func() {
+		defer close(connc)
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			connc <- c
+		}
+	}()
+	return connc
+}
+
+// dialRejected dials addr and reports whether the connection was closed by
+// the listener, closing it, or returns it open.
+func dialRejected(t *testing.T, addr string) (net.Conn, bool) {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	c.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = c.Read(make([]byte, 1))
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		c.SetReadDeadline(time.Time{})
+		return c, false
+	}
+	c.Close()
+	if err != io.EOF {
+		// reset by the closed connection
+		require.Error(t, err)
+	}
+	return nil, true
+}
+
+func TestConnLimitListenerMaxConnections(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := newConnLimitListener(zaptest.NewLogger(t), raw, "client", 2, 0)
+	defer l.Close()
+	addr := raw.Addr().String()
+	rejected := listenerRejectedConnections.WithLabelValues("client", addr, "max-connections")
+	active := listenerActiveConnections.WithLabelValues("client", addr)
+	connc := acceptConns(l)
+
+	var served []net.Conn
+	for i := 0; i < 2; i++ {
+		c, ok := dialRejected(t, addr)
+		require.False(t, ok)
+		defer c.Close()
+		served = append(served, <-connc)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(active))
+
+	_, ok := dialRejected(t, addr)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), testutil.ToFloat64(rejected))
+
+	// closing a connection frees its slot, once
+	served[0].Close()
+	served[0].Close()
+	assert.Equal(t, float64(1), testutil.ToFloat64(active))
+	c, ok := dialRejected(t, addr)
+	require.False(t, ok)
+	defer c.Close()
+	served = append(served, <-connc)
+	assert.Equal(t, float64(2), testutil.ToFloat64(active))
+	for _, s := range served[1:] {
+		s.Close()
+	}
+}
+
+func TestConnLimitListenerAcceptRate(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := newConnLimitListener(zaptest.NewLogger(t), raw, "peer", 0, 1)
+	defer l.Close()
+	addr := raw.Addr().String()
+	rejected := listenerRejectedConnections.WithLabelValues("peer", addr, "accept-rate")
+	connc := acceptConns(l)
+
+	c, ok := dialRejected(t, addr)
+	require.False(t, ok)
+	defer c.Close()
+	defer (<-connc).Close()
+
+	// the burst of one connection is spent
+	_, ok = dialRejected(t, addr)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), testutil.ToFloat64(rejected))
+}
+
+func TestNewConnLimitListenerNoLimits(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer raw.Close()
+	assert.Equal(t, raw, newConnLimitListener(zaptest.NewLogger(t), raw, "client", 0, 0))
+}
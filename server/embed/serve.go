@@ -65,6 +65,11 @@ type serveCtx struct {
 	userHandlers    map[string]http.Handler
 	serviceRegister func(*grpc.Server)
 	serversC        chan *servers
+
+	// tlsinfo and authModes are those of the client listener group of the
+	// listener, if any.
+	tlsinfo   *transport.TLSInfo
+	authModes *etcdserver.ClientAuthModes
 }
 
 type servers struct {
@@ -109,6 +114,9 @@ func (sctx *serveCtx) serve(
 
 	sctx.lg.Info("ready to serve client requests")
 
+	if sctx.tlsinfo != nil {
+		tlsinfo = sctx.tlsinfo
+	}
 	m := cmux.New(sctx.l)
 	v3c := v3client.New(s)
 	servElection := v3election.NewElectionServer(v3c)
@@ -127,11 +135,16 @@ func (sctx *serveCtx) serve(
 	defer close(sctx.serversC)
 
 	if sctx.insecure {
-		gs = v3rpc.Server(s, nil, nil, gopts...)
+		us := newUserServices(s)
+		opts := append(us.serverOptions(), gopts...)
+		if sctx.authModes != nil {
+			opts = append(opts, sctx.authModesServerOption())
+		}
+		gs = v3rpc.Server(s, nil, nil, opts...)
 		v3electionpb.RegisterElectionServer(gs, servElection)
 		v3lockpb.RegisterLockServer(gs, servLock)
 		if sctx.serviceRegister != nil {
-			sctx.serviceRegister(gs)
+			us.register(gs, sctx.serviceRegister)
 		}
 		grpcl := m.Match(cmux.HTTP2())
 		go func() { errHandler(gs.Serve(grpcl)) }()
@@ -148,7 +161,7 @@ func (sctx *serveCtx) serve(
 		httpmux := sctx.createMux(gwmux, handler)
 
 		srvhttp := &http.Server{
-			Handler:  createAccessController(sctx.lg, s, httpmux),
+			Handler:  sctx.authModesHandler(createAccessController(sctx.lg, s, httpmux)),
 			ErrorLog: logger, // do not log user error
 		}
 		if err := configureHttpServer(srvhttp, s.Cfg); err != nil {
@@ -170,11 +183,12 @@ func (sctx *serveCtx) serve(
 		if tlsErr != nil {
 			return tlsErr
 		}
-		gs = v3rpc.Server(s, tlscfg, nil, gopts...)
+		us := newUserServices(s)
+		gs = v3rpc.Server(s, tlscfg, nil, append(us.serverOptions(), gopts...)...)
 		v3electionpb.RegisterElectionServer(gs, servElection)
 		v3lockpb.RegisterLockServer(gs, servLock)
 		if sctx.serviceRegister != nil {
-			sctx.serviceRegister(gs)
+			us.register(gs, sctx.serviceRegister)
 		}
 		handler = grpcHandlerFunc(gs, handler)
 
@@ -200,7 +214,7 @@ func (sctx *serveCtx) serve(
 		httpmux := sctx.createMux(gwmux, handler)
 
 		srv := &http.Server{
-			Handler:   createAccessController(sctx.lg, s, httpmux),
+			Handler:   sctx.authModesHandler(createAccessController(sctx.lg, s, httpmux)),
 			TLSConfig: tlscfg,
 			ErrorLog:  logger, // do not log user error
 		}
@@ -375,7 +389,7 @@ func (ac *accessController) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 			http.Error(rw, errCVE20185702(host), http.StatusMisdirectedRequest)
 			return
 		}
-	} else if ac.s.Cfg.ClientCertAuthEnabled && ac.s.Cfg.EnableGRPCGateway &&
+	} else if ac.s.Cfg.ClientCertAuthEnabled && ac.s.Cfg.EnableGRPCGateway && req.TLS != nil &&
 		ac.s.AuthStore().IsAuthEnabled() && strings.HasPrefix(req.URL.Path, "/v3/") {
 		for _, chains := range req.TLS.VerifiedChains {
 			if len(chains) < 1 {
@@ -0,0 +1,132 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/activation"
+	"go.uber.org/zap"
+)
+
+// activationListeners returns the listening sockets passed by systemd socket
+// activation, unsetting LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES so that
+// they are not inherited by the child processes. It is replaced by the tests.
+var activationListeners = func() ([]net.Listener, error) {
+	files := activation.Files(true)
+	ls := make([]net.Listener, 0, len(files))
+	for _, f := range files {
+		fd, name := f.Fd(), f.Name()
+		l, err := net.FileListener(f)
+		// the listener holds a duplicate of the file descriptor
+		f.Close()
+		if err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			return nil, fmt.Errorf("socket activation: file descriptor %d (%s) is not a listening socket: %v", fd, name, err)
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+// inheritedListeners are the listening sockets passed by systemd, taken by
+// the listen URLs they are bound to. A nil *inheritedListeners has none.
+type inheritedListeners struct {
+	lg *zap.Logger
+	ls []net.Listener
+}
+
+func newInheritedListeners(lg *zap.Logger) (*inheritedListeners, error) {
+	ls, err := activationListeners()
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(ls))
+	for _, l := range ls {
+		addrs = append(addrs, l.Addr().Network()+"://"+l.Addr().String())
+	}
+	lg.Info("inherited listening sockets from systemd", zap.Strings("addresses", addrs))
+	return &inheritedListeners{lg: lg, ls: ls}, nil
+}
+
+// take returns the inherited listener bound to the address of u, if any, for
+// it to be served rather than binding the address.
+func (il *inheritedListeners) take(u url.URL) net.Listener {
+	if il == nil {
+		return nil
+	}
+	for i, l := range il.ls {
+		if !listenerBoundTo(l, u) {
+			continue
+		}
+		il.ls = append(il.ls[:i:i], il.ls[i+1:]...)
+		il.lg.Info(
+			"serving inherited listening socket",
+			zap.String("url", u.String()),
+			zap.String("address", l.Addr().String()),
+		)
+		return l
+	}
+	return nil
+}
+
+// close closes the inherited listeners not taken by any listen URL, and
+// returns an error listing them as the sockets systemd was configured with
+// are expected to match the listen URLs.
+func (il *inheritedListeners) close() error {
+	if il == nil || len(il.ls) == 0 {
+		return nil
+	}
+	addrs := make([]string, 0, len(il.ls))
+	for _, l := range il.ls {
+		addrs = append(addrs, l.Addr().Network()+"://"+l.Addr().String())
+		l.Close()
+	}
+	il.ls = nil
+	return fmt.Errorf("socket activation: no --listen-client-urls or --listen-peer-urls bound to the inherited sockets %s", strings.Join(addrs, ","))
+}
+
+func listenerBoundTo(l net.Listener, u url.URL) bool {
+	switch addr := l.Addr().(type) {
+	case *net.UnixAddr:
+		return (u.Scheme == "unix" || u.Scheme == "unixs") && addr.Name == u.Host+u.Path
+	case *net.TCPAddr:
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return false
+		}
+		host, port, err := net.SplitHostPort(u.Host)
+		if err != nil || port != fmt.Sprint(addr.Port) {
+			return false
+		}
+		if host == "" {
+			return addr.IP.IsUnspecified()
+		}
+		ua, err := net.ResolveTCPAddr("tcp", u.Host)
+		if err != nil {
+			return false
+		}
+		// 0.0.0.0 is bound as [::] on the dual-stack hosts
+		if ua.IP.IsUnspecified() && addr.IP.IsUnspecified() {
+			return true
+		}
+		return ua.IP.Equal(addr.IP)
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerBoundTo(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{fmt.Sprintf("http://127.0.0.1:%d", port), true},
+		{fmt.Sprintf("https://127.0.0.1:%d", port), true},
+		{fmt.Sprintf("http://localhost:%d", port), true},
+		{fmt.Sprintf("http://127.0.0.1:%d", port+1), false},
+		{fmt.Sprintf("http://127.0.0.2:%d", port), false},
+		{fmt.Sprintf("http://0.0.0.0:%d", port), false},
+		{fmt.Sprintf("unix://127.0.0.1:%d", port), false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, listenerBoundTo(l, *u), tt.url)
+	}
+}
+
+// TestStartEtcdSocketActivation ensures that StartEtcd serves the inherited
+// listeners on the URLs they are bound to, and rejects an inherited listener
+// matching no URL.
+func TestStartEtcdSocketActivation(t *testing.T) {
+	urls := newEmbedURLs(3)
+	// the inherited listeners are closed with the server
+	listen := func(urls []url.URL) func() ([]net.Listener, error) {
+		var ls []net.Listener
+		for _, u := range urls {
+			l, err := net.Listen("unix", u.Host+u.Path)
+			require.NoError(t, err)
+			ls = append(ls, l)
+		}
+		return func() ([]net.Listener, error) { return ls, nil }
+	}
+	defer func(f func() ([]net.Listener, error)) { activationListeners = f }(activationListeners)
+
+	newConfig := func() *Config {
+		cfg := NewConfig()
+		curls, purls := []url.URL{urls[0]}, []url.URL{urls[1]}
+		cfg.LCUrls, cfg.ACUrls = curls, curls
+		cfg.LPUrls, cfg.APUrls = purls, purls
+		cfg.InitialCluster = "default=" + purls[0].String()
+		cfg.Dir = t.TempDir()
+		cfg.ExperimentalSocketActivation = true
+		return cfg
+	}
+
+	activationListeners = listen(urls)
+	if _, err := StartEtcd(newConfig()); err == nil {
+		t.Fatal("expected an error for the inherited listener matching no URL")
+	}
+
+	activationListeners = listen(urls[:2])
+	e, err := StartEtcd(newConfig())
+	require.NoError(t, err)
+	defer e.Close()
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the server to be ready")
+	}
+	require.Len(t, e.Clients, 1)
+	assert.Equal(t, urls[0].Host+urls[0].Path, e.Clients[0].Addr().String())
+	require.Len(t, e.Peers, 1)
+	assert.Equal(t, urls[1].Host+urls[1].Path, e.Peers[0].Addr().String())
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+)
+
+// ErrNotUserService is returned by AuthInfoFromContext when called outside
+// of a gRPC service registered through Config.ServiceRegister.
+var ErrNotUserService = errors.New("embed: context does not belong to a user gRPC service request")
+
+type userServiceCtxKey struct{}
+
+// AuthInfoFromContext returns the etcd user authenticated for a request
+// served by a gRPC service registered through Config.ServiceRegister.
+// Requests are authenticated the same way as etcd's own gRPC services, by
+// the token obtained from the Auth service or, if client certificate
+// authentication is enabled, by the common name of the client certificate.
+// It returns nil if etcd authentication is disabled or the request does
+// not carry any credentials, and an error if the credentials are invalid.
+func AuthInfoFromContext(ctx context.Context) (*auth.AuthInfo, error) {
+	s, ok := ctx.Value(userServiceCtxKey{}).(*etcdserver.EtcdServer)
+	if !ok {
+		return nil, ErrNotUserService
+	}
+	return s.AuthInfoFromCtx(ctx)
+}
+
+// userServices tracks the gRPC services registered by the user on a gRPC
+// server, so that their requests get access to the etcd server through
+// their context.
+type userServices struct {
+	s *etcdserver.EtcdServer
+	// names is only written before the gRPC server starts serving.
+	names map[string]struct{}
+}
+
+func newUserServices(s *etcdserver.EtcdServer) *userServices {
+	return &userServices{s: s, names: make(map[string]struct{})}
+}
+
+// register calls register on the gRPC server, recording the names of the
+// services it adds.
+func (us *userServices) register(gs *grpc.Server, register func(*grpc.Server)) {
+	before := gs.GetServiceInfo()
+	register(gs)
+	for name := range gs.GetServiceInfo() {
+		if _, ok := before[name]; !ok {
+			us.names[name] = struct{}{}
+		}
+	}
+}
+
+// serverOptions returns the interceptors to install on the gRPC server.
+func (us *userServices) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if us.isUserMethod(info.FullMethod) {
+				ctx = context.WithValue(ctx, userServiceCtxKey{}, us.s)
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if us.isUserMethod(info.FullMethod) {
+				wrapped := grpc_middleware.WrapServerStream(ss)
+				wrapped.WrappedContext = context.WithValue(ss.Context(), userServiceCtxKey{}, us.s)
+				ss = wrapped
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// isUserMethod returns true if the full method name, "/service/method",
+// belongs to a user service.
+func (us *userServices) isUserMethod(fullMethod string) bool {
+	if len(us.names) == 0 {
+		return false
+	}
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[:i]
+	}
+	_, ok := us.names[name]
+	return ok
+}
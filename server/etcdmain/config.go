@@ -17,20 +17,18 @@
 package etcdmain
 
 import (
-	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
-	"time"
 
 	"go.etcd.io/etcd/api/v3/version"
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
 	"go.etcd.io/etcd/client/pkg/v3/tlsutil"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
 	"go.etcd.io/etcd/pkg/v3/flags"
 	cconfig "go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/embed"
-	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 
 	"go.uber.org/zap"
@@ -62,11 +60,12 @@ var (
 
 // config holds the config for a command line invocation of etcd
 type config struct {
-	ec           embed.Config
-	cf           configFlags
-	configFile   string
-	printVersion bool
-	ignored      []string
+	ec             embed.Config
+	cf             configFlags
+	configFile     string
+	printVersion   bool
+	validateConfig bool
+	ignored        []string
 }
 
 // configFlags has the set of flags used for command line parsing a Config
@@ -103,7 +102,7 @@ func newConfig() *config {
 		fmt.Fprintln(os.Stderr, usageline)
 	}
 
-	fs.StringVar(&cfg.configFile, "config-file", "", "Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.")
+	fs.StringVar(&cfg.configFile, "config-file", "", "Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored. The settings that can be changed at runtime are applied again on SIGHUP.")
 
 	// member
 	fs.StringVar(&cfg.ec.Dir, "data-dir", cfg.ec.Dir, "Path to the data directory.")
@@ -215,7 +214,8 @@ func newConfig() *config {
 	fs.StringVar(&cfg.ec.PeerTLSInfo.AllowedCN, "peer-cert-allowed-cn", "", "Allowed CN for inter peer authentication.")
 	fs.StringVar(&cfg.ec.PeerTLSInfo.AllowedHostname, "peer-cert-allowed-hostname", "", "Allowed TLS hostname for inter peer authentication.")
 	fs.Var(flags.NewStringsValue(""), "cipher-suites", "Comma-separated list of supported TLS cipher suites between client/server and peers (empty will be auto-populated by Go).")
-	fs.BoolVar(&cfg.ec.PeerTLSInfo.SkipClientSANVerify, "experimental-peer-skip-client-san-verification", false, "Skip verification of SAN field in client certificate for peer connections.")
+	fs.BoolVar(&cfg.ec.PeerTLSInfo.SkipClientSANVerify, "peer-skip-client-san-verification", cfg.ec.PeerTLSInfo.SkipClientSANVerify, "Skip verification of SAN field in client certificate for peer connections.")
+	fs.BoolVar(&cfg.ec.ExperimentalPeerSkipClientSANVerification, "experimental-peer-skip-client-san-verification", cfg.ec.ExperimentalPeerSkipClientSANVerification, "Skip verification of SAN field in client certificate for peer connections. It's deprecated, and will be decommissioned in v3.7. Use --peer-skip-client-san-verification instead.")
 	fs.StringVar(&cfg.ec.TlsMinVersion, "tls-min-version", string(tlsutil.TLSVersion12), "Minimum TLS version supported by etcd. Possible values: TLS1.2, TLS1.3.")
 	fs.StringVar(&cfg.ec.TlsMaxVersion, "tls-max-version", string(tlsutil.TLSVersionDefault), "Maximum TLS version supported by etcd. Possible values: TLS1.2, TLS1.3 (empty defers to Go).")
 
@@ -236,9 +236,11 @@ func newConfig() *config {
 
 	// version
 	fs.BoolVar(&cfg.printVersion, "version", false, "Print the version and exit.")
+	fs.BoolVar(&cfg.validateConfig, "validate-config", false, "Check the configuration of the flags or of --config-file, print the problems found as JSON and exit, with status 1 if it is invalid.")
 
 	fs.StringVar(&cfg.ec.AutoCompactionRetention, "auto-compaction-retention", "0", "Auto compaction retention for mvcc key value store. 0 means disable auto compaction.")
-	fs.StringVar(&cfg.ec.AutoCompactionMode, "auto-compaction-mode", "periodic", "interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.")
+	fs.StringVar(&cfg.ec.AutoCompactionMode, "auto-compaction-mode", "periodic", "interpret 'auto-compaction-retention' one of: periodic|revision|hybrid. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention. 'hybrid' for duration based retention keeping at most 'auto-compaction-max-revisions' revisions.")
+	fs.Int64Var(&cfg.ec.AutoCompactionMaxRevisions, "auto-compaction-max-revisions", 0, "Maximum number of revisions kept by the 'hybrid' auto compaction mode, even if they are within 'auto-compaction-retention'.")
 
 	// pprof profiler via HTTP
 	fs.BoolVar(&cfg.ec.EnablePprof, "enable-pprof", false, "Enable runtime profiling data via HTTP server. Address is at client URL + \"/debug/pprof/\"")
@@ -246,43 +248,116 @@ func newConfig() *config {
 	// additional metrics
 	fs.StringVar(&cfg.ec.Metrics, "metrics", cfg.ec.Metrics, "Set level of detail for exported metrics, specify 'extensive' to include server side grpc histogram metrics")
 
-	// experimental distributed tracing
-	fs.BoolVar(&cfg.ec.ExperimentalEnableDistributedTracing, "experimental-enable-distributed-tracing", false, "Enable experimental distributed  tracing using OpenTelemetry Tracing.")
-	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingAddress, "experimental-distributed-tracing-address", embed.ExperimentalDistributedTracingAddress, "Address for distributed tracing used for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag).")
-	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingServiceName, "experimental-distributed-tracing-service-name", embed.ExperimentalDistributedTracingServiceName, "Configures service name for distributed tracing to be used to define service name for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). 'etcd' is the default service name. Use the same service name for all instances of etcd.")
-	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingServiceInstanceID, "experimental-distributed-tracing-instance-id", "", "Configures service instance ID for distributed tracing to be used to define service instance ID key for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). There is no default value set. This ID must be unique per etcd instance.")
-	fs.IntVar(&cfg.ec.ExperimentalDistributedTracingSamplingRatePerMillion, "experimental-distributed-tracing-sampling-rate", 0, "Number of samples to collect per million spans for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag).")
+	// distributed tracing
+	fs.BoolVar(&cfg.ec.EnableDistributedTracing, "enable-distributed-tracing", cfg.ec.EnableDistributedTracing, "Enable distributed tracing using OpenTelemetry Tracing.")
+	fs.BoolVar(&cfg.ec.ExperimentalEnableDistributedTracing, "experimental-enable-distributed-tracing", cfg.ec.ExperimentalEnableDistributedTracing, "Enable experimental distributed  tracing using OpenTelemetry Tracing. It's deprecated, and will be decommissioned in v3.7. Use --enable-distributed-tracing instead.")
+	fs.StringVar(&cfg.ec.DistributedTracingAddress, "distributed-tracing-address", cfg.ec.DistributedTracingAddress, "Address for distributed tracing used for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag).")
+	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingAddress, "experimental-distributed-tracing-address", cfg.ec.ExperimentalDistributedTracingAddress, "Address for distributed tracing used for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-address instead.")
+	fs.StringVar(&cfg.ec.DistributedTracingServiceName, "distributed-tracing-service-name", cfg.ec.DistributedTracingServiceName, "Configures service name for distributed tracing to be used to define service name for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag). 'etcd' is the default service name. Use the same service name for all instances of etcd.")
+	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingServiceName, "experimental-distributed-tracing-service-name", cfg.ec.ExperimentalDistributedTracingServiceName, "Configures service name for distributed tracing to be used to define service name for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). 'etcd' is the default service name. Use the same service name for all instances of etcd. It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-service-name instead.")
+	fs.StringVar(&cfg.ec.DistributedTracingServiceInstanceID, "distributed-tracing-instance-id", cfg.ec.DistributedTracingServiceInstanceID, "Configures service instance ID for distributed tracing to be used to define service instance ID key for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag). There is no default value set. This ID must be unique per etcd instance.")
+	fs.StringVar(&cfg.ec.ExperimentalDistributedTracingServiceInstanceID, "experimental-distributed-tracing-instance-id", cfg.ec.ExperimentalDistributedTracingServiceInstanceID, "Configures service instance ID for distributed tracing to be used to define service instance ID key for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). There is no default value set. This ID must be unique per etcd instance. It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-instance-id instead.")
+	fs.IntVar(&cfg.ec.DistributedTracingSamplingRatePerMillion, "distributed-tracing-sampling-rate", cfg.ec.DistributedTracingSamplingRatePerMillion, "Number of samples to collect per million spans for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag).")
+	fs.IntVar(&cfg.ec.ExperimentalDistributedTracingSamplingRatePerMillion, "experimental-distributed-tracing-sampling-rate", cfg.ec.ExperimentalDistributedTracingSamplingRatePerMillion, "Number of samples to collect per million spans for OpenTelemetry Tracing (if enabled with experimental-enable-distributed-tracing flag). It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-sampling-rate instead.")
 
 	// auth
 	fs.StringVar(&cfg.ec.AuthToken, "auth-token", cfg.ec.AuthToken, "Specify auth token specific options.")
 	fs.UintVar(&cfg.ec.BcryptCost, "bcrypt-cost", cfg.ec.BcryptCost, "Specify bcrypt algorithm cost factor for auth password hashing.")
 	fs.UintVar(&cfg.ec.AuthTokenTTL, "auth-token-ttl", cfg.ec.AuthTokenTTL, "The lifetime in seconds of the auth token.")
+	fs.Var(flags.NewStringsValue(""), "experimental-client-cert-san-role-rules", "Comma-separated list of '<uri|dns>:<pattern>=<role>' rules granting the role to the clients whose certificate has a matching SAN.")
+	fs.IntVar(&cfg.ec.ExperimentalPasswordMinLength, "experimental-password-min-length", 0, "Minimum number of characters of the passwords of the users added or changed on this member. 0 means no minimum.")
+	fs.IntVar(&cfg.ec.ExperimentalPasswordMinCharClasses, "experimental-password-min-char-classes", 0, "Minimum number of the classes of characters (lowercase, uppercase, digits, others) of the passwords of the users added or changed on this member. 0 means no minimum.")
 
 	// gateway
 	fs.BoolVar(&cfg.ec.EnableGRPCGateway, "enable-grpc-gateway", cfg.ec.EnableGRPCGateway, "Enable GRPC gateway.")
 
+	// feature gates
+	cfg.ec.ServerFeatureGate.(featuregate.MutableFeatureGate).AddFlag(fs, embed.ServerFeatureGateFlagName)
+
 	// experimental
 	fs.BoolVar(&cfg.ec.ExperimentalInitialCorruptCheck, "experimental-initial-corrupt-check", cfg.ec.ExperimentalInitialCorruptCheck, "Enable to check data corruption before serving any client/peer traffic.")
-	fs.DurationVar(&cfg.ec.ExperimentalCorruptCheckTime, "experimental-corrupt-check-time", cfg.ec.ExperimentalCorruptCheckTime, "Duration of time between cluster corruption check passes.")
+	fs.DurationVar(&cfg.ec.CorruptCheckTime, "corrupt-check-time", cfg.ec.CorruptCheckTime, "Duration of time between cluster corruption check passes.")
+	fs.DurationVar(&cfg.ec.ExperimentalCorruptCheckTime, "experimental-corrupt-check-time", cfg.ec.ExperimentalCorruptCheckTime, "Duration of time between cluster corruption check passes. It's deprecated, and will be decommissioned in v3.7. Use --corrupt-check-time instead.")
 	fs.BoolVar(&cfg.ec.ExperimentalCompactHashCheckEnabled, "experimental-compact-hash-check-enabled", cfg.ec.ExperimentalCompactHashCheckEnabled, "Enable leader to periodically check followers compaction hashes.")
-	fs.DurationVar(&cfg.ec.ExperimentalCompactHashCheckTime, "experimental-compact-hash-check-time", cfg.ec.ExperimentalCompactHashCheckTime, "Duration of time between leader checks followers compaction hashes.")
+	fs.DurationVar(&cfg.ec.CompactHashCheckTime, "compact-hash-check-time", cfg.ec.CompactHashCheckTime, "Duration of time between leader checks followers compaction hashes.")
+	fs.DurationVar(&cfg.ec.ExperimentalCompactHashCheckTime, "experimental-compact-hash-check-time", cfg.ec.ExperimentalCompactHashCheckTime, "Duration of time between leader checks followers compaction hashes. It's deprecated, and will be decommissioned in v3.7. Use --compact-hash-check-time instead.")
+	fs.StringVar(&cfg.ec.ExperimentalCorruptCheckSchedule, "experimental-corrupt-check-schedule", cfg.ec.ExperimentalCorruptCheckSchedule, "Cron schedule of the cluster corruption check passes, overriding --experimental-corrupt-check-time.")
+	fs.StringVar(&cfg.ec.ExperimentalCorruptCheckScope, "experimental-corrupt-check-scope", cfg.ec.ExperimentalCorruptCheckScope, "Scope of the cluster corruption check passes, 'full' or 'sampled'.")
+	fs.IntVar(&cfg.ec.ExperimentalCorruptCheckSamples, "experimental-corrupt-check-samples", cfg.ec.ExperimentalCorruptCheckSamples, "Number of windows of revisions compared by the sampled cluster corruption check passes.")
 
 	fs.BoolVar(&cfg.ec.ExperimentalEnableLeaseCheckpoint, "experimental-enable-lease-checkpoint", false, "Enable leader to send regular checkpoints to other members to prevent reset of remaining TTL on leader change.")
 	// TODO: delete in v3.7
 	fs.BoolVar(&cfg.ec.ExperimentalEnableLeaseCheckpointPersist, "experimental-enable-lease-checkpoint-persist", false, "Enable persisting remainingTTL to prevent indefinite auto-renewal of long lived leases. Always enabled in v3.6. Should be used to ensure smooth upgrade from v3.5 clusters with this feature enabled. Requires experimental-enable-lease-checkpoint to be enabled.")
-	fs.IntVar(&cfg.ec.ExperimentalCompactionBatchLimit, "experimental-compaction-batch-limit", cfg.ec.ExperimentalCompactionBatchLimit, "Sets the maximum revisions deleted in each compaction batch.")
-	fs.DurationVar(&cfg.ec.ExperimentalCompactionSleepInterval, "experimental-compaction-sleep-interval", cfg.ec.ExperimentalCompactionSleepInterval, "Sets the sleep interval between each compaction batch.")
-	fs.DurationVar(&cfg.ec.ExperimentalWatchProgressNotifyInterval, "experimental-watch-progress-notify-interval", cfg.ec.ExperimentalWatchProgressNotifyInterval, "Duration of periodic watch progress notifications.")
-	fs.DurationVar(&cfg.ec.ExperimentalDowngradeCheckTime, "experimental-downgrade-check-time", cfg.ec.ExperimentalDowngradeCheckTime, "Duration of time between two downgrade status check.")
-	fs.DurationVar(&cfg.ec.ExperimentalWarningApplyDuration, "experimental-warning-apply-duration", cfg.ec.ExperimentalWarningApplyDuration, "Time duration after which a warning is generated if request takes more time.")
+	fs.DurationVar(&cfg.ec.ExperimentalLeaseCheckpointInterval, "experimental-lease-checkpoint-interval", cfg.ec.ExperimentalLeaseCheckpointInterval, "Duration between the lease checkpoints, 0 meaning the default of 5m. Requires experimental-enable-lease-checkpoint to be enabled.")
+	fs.BoolVar(&cfg.ec.ExperimentalLeaseCheckpointOnRenew, "experimental-lease-checkpoint-on-renew", false, "Enable leader to checkpoint the remaining TTL of a lease on every renewal, at the cost of a raft entry per renewal. Requires experimental-enable-lease-checkpoint to be enabled.")
+	fs.IntVar(&cfg.ec.ExperimentalMaxLeaseAttachedKeys, "experimental-max-lease-attached-keys", 0, "Maximum number of keys attached to a single lease, raising a LEASEKEYS alarm as a lease approaches it. Zero means unlimited.")
+	fs.IntVar(&cfg.ec.CompactionBatchLimit, "compaction-batch-limit", cfg.ec.CompactionBatchLimit, "Sets the maximum revisions deleted in each compaction batch.")
+	fs.IntVar(&cfg.ec.ExperimentalCompactionBatchLimit, "experimental-compaction-batch-limit", cfg.ec.ExperimentalCompactionBatchLimit, "Sets the maximum revisions deleted in each compaction batch. It's deprecated, and will be decommissioned in v3.7. Use --compaction-batch-limit instead.")
+	fs.DurationVar(&cfg.ec.CompactionSleepInterval, "compaction-sleep-interval", cfg.ec.CompactionSleepInterval, "Sets the sleep interval between each compaction batch.")
+	fs.DurationVar(&cfg.ec.ExperimentalCompactionSleepInterval, "experimental-compaction-sleep-interval", cfg.ec.ExperimentalCompactionSleepInterval, "Sets the sleep interval between each compaction batch. It's deprecated, and will be decommissioned in v3.7. Use --compaction-sleep-interval instead.")
+	fs.DurationVar(&cfg.ec.ExperimentalCompactionBatchTargetDuration, "experimental-compaction-batch-target-duration", cfg.ec.ExperimentalCompactionBatchTargetDuration, "Resizes the compaction batches for the time each holds the backend to approach this duration, and spaces them out by at least as long as they took. 0 means disabled.")
+	fs.IntVar(&cfg.ec.ExperimentalCompactionMinBatchLimit, "experimental-compaction-min-batch-limit", cfg.ec.ExperimentalCompactionMinBatchLimit, "Sets the minimum revisions deleted in each paced compaction batch.")
+	fs.Int64Var(&cfg.ec.ExperimentalCompactionRevisionAlignment, "experimental-compaction-revision-alignment", 0, "Rounds the auto compaction revisions down to a multiple of this value, so that all members compact at the same revision boundaries. 0 means disabled.")
+	fs.Int64Var(&cfg.ec.ExperimentalMaxQuotaBackendBytes, "experimental-max-quota-backend-bytes", 0, "Grows the backend quota, raising a QUOTAGROWN alarm, when the backend size in use approaches it, up to this hard cap. 0 means disabled.")
+	fs.DurationVar(&cfg.ec.WatchProgressNotifyInterval, "watch-progress-notify-interval", cfg.ec.WatchProgressNotifyInterval, "Duration of periodic watch progress notifications.")
+	fs.DurationVar(&cfg.ec.ExperimentalWatchProgressNotifyInterval, "experimental-watch-progress-notify-interval", cfg.ec.ExperimentalWatchProgressNotifyInterval, "Duration of periodic watch progress notifications. It's deprecated, and will be decommissioned in v3.7. Use --watch-progress-notify-interval instead.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchMaxStartRevisionLag, "experimental-watch-max-start-revision-lag", cfg.ec.ExperimentalWatchMaxStartRevisionLag, "Maximum number of revisions a watch start revision can be behind the current revision. Zero means no limit.")
+	fs.DurationVar(&cfg.ec.ExperimentalWatchMaxStartRevisionAge, "experimental-watch-max-start-revision-age", cfg.ec.ExperimentalWatchMaxStartRevisionAge, "Maximum time a watch start revision can be behind the current revision. Zero means no limit.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchMaxEventsPerSecond, "experimental-watch-max-events-per-second", cfg.ec.ExperimentalWatchMaxEventsPerSecond, "Maximum rate each watcher is sent events at. Zero means no limit.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchMaxQueuedEvents, "experimental-watch-max-queued-events", cfg.ec.ExperimentalWatchMaxQueuedEvents, "Maximum number of events queued for each watcher waiting for the delivery rate limit before the overflow policy applies.")
+	fs.StringVar(&cfg.ec.ExperimentalWatchOverflowPolicy, "experimental-watch-overflow-policy", cfg.ec.ExperimentalWatchOverflowPolicy, "Policy for the watchers exceeding the maximum number of queued events: 'drop-oldest', 'cancel' or 'block'.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchBacklogAlarmEvents, "experimental-watch-backlog-alarm-events", cfg.ec.ExperimentalWatchBacklogAlarmEvents, "Number of events held in memory for the slow watchers raising a WATCHBACKLOG alarm. Zero means disabled.")
+	fs.DurationVar(&cfg.ec.ExperimentalDrainTimeout, "experimental-drain-timeout", cfg.ec.ExperimentalDrainTimeout, "Time to drain the client requests for on SIGTERM or SIGINT before stopping. Zero means stopping without draining.")
+	fs.Uint64Var(&cfg.ec.ExperimentalBoundedStalenessMaxLag, "experimental-bounded-staleness-max-lag", cfg.ec.ExperimentalBoundedStalenessMaxLag, "Maximum number of entries the applied index can lag the commit index learned from the leader for bounded staleness reads to be served locally.")
+	fs.StringVar(&cfg.ec.ExperimentalChangeFeedWebhookURL, "experimental-change-feed-webhook-url", "", "URL to post the committed events of --experimental-change-feed-prefixes to, while the member is the leader.")
+	fs.Var(flags.NewStringsValue(""), "experimental-change-feed-prefixes", "Comma-separated list of key prefixes whose events are posted to --experimental-change-feed-webhook-url (empty means the whole key space).")
+	fs.Var(flags.NewStringsValue(""), "experimental-version-retention", "Comma-separated list of '<prefix>=<versions>' retentions keeping the last versions of every key with the prefix through the compactions, or '<versions>' for the whole key space.")
+	fs.Var(flags.NewStringsValue(""), "experimental-secondary-indexes", "Comma-separated list of '<prefix>=<field>' secondary indexes maintained on the JSON values of the keys with the prefix, looked up with the IndexRange RPC.")
+	fs.BoolVar(&cfg.ec.ExperimentalHotKeyTracking, "experimental-hot-key-tracking", false, "Estimate the read, write and watch traffic per key prefix, reported by the HotKeys RPC and metrics.")
+	fs.BoolVar(&cfg.ec.ExperimentalPrefixStats, "experimental-prefix-stats", false, "Keep the number of keys, their size and the number of watchers per key prefix, reported by the PrefixStats RPC.")
+	fs.StringVar(&cfg.ec.ExperimentalValueCompression, "experimental-value-compression", cfg.ec.ExperimentalValueCompression, "Compression of the stored values of at least experimental-value-compression-threshold bytes ('none', 'deflate', 'snappy' or 'zstd'), once the cluster version is at least 3.6. Requires --feature-gates=ValueCompression=true.")
+	fs.IntVar(&cfg.ec.ExperimentalValueCompressionThreshold, "experimental-value-compression-threshold", cfg.ec.ExperimentalValueCompressionThreshold, "Minimum size in bytes of the values compressed by experimental-value-compression.")
+	fs.UintVar(&cfg.ec.ExperimentalMaxChunkedValueBytes, "experimental-max-chunked-value-bytes", 0, "Maximum size of the values put in parts of at most max-request-bytes, stored split into chunks, once all the members enable the ValueChunking feature gate. Requires --feature-gates=ValueChunking=true. 0 disables it.")
+	fs.StringVar(&cfg.ec.ExperimentalAuditLogPath, "experimental-audit-log-path", "", "Path of the file the audit log records the requests of the clients to. Empty disables the audit log.")
+	fs.StringVar(&cfg.ec.ExperimentalAuditLogRotationConfigJSON, "experimental-audit-log-rotation-config-json", embed.DefaultLogRotationConfig, "Configures the rotation of the audit log file with a JSON logger config, like --log-rotation-config-json.")
+	fs.Var(flags.NewStringsValue(""), "experimental-audit-log-include-prefixes", "Comma-separated list of key prefixes limiting the audit log to the requests touching their keys (empty means the whole key space).")
+	fs.Var(flags.NewStringsValue(""), "experimental-audit-log-exclude-prefixes", "Comma-separated list of key prefixes leaving the requests touching only their keys out of the audit log.")
+	fs.StringVar(&cfg.ec.ExperimentalAuthzWebhookURL, "experimental-authz-webhook-url", "", "URL of an external authorizer consulted on the requests on keys, the watches, the lease keep alives and the snapshots of the users permitted by their roles. Empty disables the external authorizer.")
+	fs.DurationVar(&cfg.ec.ExperimentalAuthzWebhookTimeout, "experimental-authz-webhook-timeout", cfg.ec.ExperimentalAuthzWebhookTimeout, "Time the external authorizer takes at most to decide on a request.")
+	fs.StringVar(&cfg.ec.ExperimentalAuthzWebhookTrustedCAFile, "experimental-authz-webhook-trusted-ca-file", "", "Path to the CA file verifying the certificate of an HTTPS authorizer webhook, instead of the system CAs.")
+	fs.StringVar(&cfg.ec.ExperimentalAuthzWebhookCertFile, "experimental-authz-webhook-cert-file", "", "Path to the client certificate authenticating the member to the authorizer webhook.")
+	fs.StringVar(&cfg.ec.ExperimentalAuthzWebhookKeyFile, "experimental-authz-webhook-key-file", "", "Path to the client key authenticating the member to the authorizer webhook.")
+	fs.DurationVar(&cfg.ec.ExperimentalAuthzCacheTTL, "experimental-authz-cache-ttl", cfg.ec.ExperimentalAuthzCacheTTL, "Time the decisions of the external authorizer are cached for. 0 disables the cache.")
+	fs.BoolVar(&cfg.ec.ExperimentalAuthzFailOpen, "experimental-authz-fail-open", false, "Allow the requests the external authorizer fails to decide on, which are denied otherwise.")
+	fs.BoolVar(&cfg.ec.ExperimentalEnableSnapshotHTTP, "experimental-enable-snapshot-http", false, "Serve consistent backend snapshots on the '/snapshot' path of the client URLs.")
+	fs.DurationVar(&cfg.ec.DowngradeCheckTime, "downgrade-check-time", cfg.ec.DowngradeCheckTime, "Duration of time between two downgrade status check.")
+	fs.DurationVar(&cfg.ec.ExperimentalDowngradeCheckTime, "experimental-downgrade-check-time", cfg.ec.ExperimentalDowngradeCheckTime, "Duration of time between two downgrade status check. It's deprecated, and will be decommissioned in v3.7. Use --downgrade-check-time instead.")
+	fs.DurationVar(&cfg.ec.WarningApplyDuration, "warning-apply-duration", cfg.ec.WarningApplyDuration, "Time duration after which a warning is generated if request takes more time.")
+	fs.DurationVar(&cfg.ec.ExperimentalWarningApplyDuration, "experimental-warning-apply-duration", cfg.ec.ExperimentalWarningApplyDuration, "Time duration after which a warning is generated if request takes more time. It's deprecated, and will be decommissioned in v3.7. Use --warning-apply-duration instead.")
 	fs.DurationVar(&cfg.ec.WarningUnaryRequestDuration, "warning-unary-request-duration", cfg.ec.WarningUnaryRequestDuration, "Time duration after which a warning is generated if a unary request takes more time.")
 	fs.DurationVar(&cfg.ec.ExperimentalWarningUnaryRequestDuration, "experimental-warning-unary-request-duration", cfg.ec.ExperimentalWarningUnaryRequestDuration, "Time duration after which a warning is generated if a unary request takes more time. It's deprecated, and will be decommissioned in v3.7. Use --warning-unary-request-duration instead.")
-	fs.BoolVar(&cfg.ec.ExperimentalMemoryMlock, "experimental-memory-mlock", cfg.ec.ExperimentalMemoryMlock, "Enable to enforce etcd pages (in particular bbolt) to stay in RAM.")
+	fs.BoolVar(&cfg.ec.MemoryMlock, "memory-mlock", cfg.ec.MemoryMlock, "Enable to enforce etcd pages (in particular bbolt) to stay in RAM.")
+	fs.BoolVar(&cfg.ec.ExperimentalMemoryMlock, "experimental-memory-mlock", cfg.ec.ExperimentalMemoryMlock, "Enable to enforce etcd pages (in particular bbolt) to stay in RAM. It's deprecated, and will be decommissioned in v3.7. Use --memory-mlock instead.")
 	fs.BoolVar(&cfg.ec.ExperimentalTxnModeWriteWithSharedBuffer, "experimental-txn-mode-write-with-shared-buffer", true, "Enable the write transaction to use a shared buffer in its readonly check operations.")
-	fs.UintVar(&cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "experimental-bootstrap-defrag-threshold-megabytes", 0, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.")
-	fs.IntVar(&cfg.ec.ExperimentalMaxLearners, "experimental-max-learners", membership.DefaultMaxLearners, "Sets the maximum number of learners that can be available in the cluster membership.")
-	fs.DurationVar(&cfg.ec.ExperimentalWaitClusterReadyTimeout, "experimental-wait-cluster-ready-timeout", cfg.ec.ExperimentalWaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready.")
-	fs.Uint64Var(&cfg.ec.SnapshotCatchUpEntries, "experimental-snapshot-catchup-entries", cfg.ec.SnapshotCatchUpEntries, "Number of entries for a slow follower to catch up after compacting the the raft storage entries.")
+	fs.UintVar(&cfg.ec.BootstrapDefragThresholdMegabytes, "bootstrap-defrag-threshold-megabytes", cfg.ec.BootstrapDefragThresholdMegabytes, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.")
+	fs.UintVar(&cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "experimental-bootstrap-defrag-threshold-megabytes", cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect. It's deprecated, and will be decommissioned in v3.7. Use --bootstrap-defrag-threshold-megabytes instead.")
+	fs.BoolVar(&cfg.ec.ExperimentalIncrementalDefrag, "experimental-incremental-defrag", false, "Defragment the backend in small batches, serving requests between them, with a short final cutover.")
+	fs.Float64Var(&cfg.ec.ExperimentalAutoDefragThreshold, "experimental-auto-defrag-threshold", 0, "Defragment the backend once the ratio of its bytes not in use to its size exceeds this threshold. 0 means disabled.")
+	fs.StringVar(&cfg.ec.ExperimentalAutoDefragSchedule, "experimental-auto-defrag-schedule", "", "Cron schedule of the off-peak minutes the backend may be defragmented automatically in, any time if empty.")
+	fs.StringVar(&cfg.ec.ExperimentalBackendEngine, "experimental-backend-engine", cfg.ec.ExperimentalBackendEngine, "Storage engine of the backend ('bbolt' or 'log').")
+	fs.IntVar(&cfg.ec.MaxLearners, "max-learners", cfg.ec.MaxLearners, "Sets the maximum number of learners that can be available in the cluster membership.")
+	fs.IntVar(&cfg.ec.ExperimentalMaxLearners, "experimental-max-learners", cfg.ec.ExperimentalMaxLearners, "Sets the maximum number of learners that can be available in the cluster membership. It's deprecated, and will be decommissioned in v3.7. Use --max-learners instead.")
+	fs.Uint64Var(&cfg.ec.ExperimentalLearnerAutoPromoteLag, "experimental-learner-auto-promote-lag", cfg.ec.ExperimentalLearnerAutoPromoteLag, "Maximum number of raft entries a learner may lag behind the leader by to be promoted automatically.")
+	fs.DurationVar(&cfg.ec.ExperimentalLearnerAutoPromoteDuration, "experimental-learner-auto-promote-duration", 0, "Time a learner has to stay within --experimental-learner-auto-promote-lag for to be promoted automatically. 0 disables the automatic promotion.")
+	fs.DurationVar(&cfg.ec.WaitClusterReadyTimeout, "wait-cluster-ready-timeout", cfg.ec.WaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready.")
+	fs.DurationVar(&cfg.ec.ExperimentalWaitClusterReadyTimeout, "experimental-wait-cluster-ready-timeout", cfg.ec.ExperimentalWaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready. It's deprecated, and will be decommissioned in v3.7. Use --wait-cluster-ready-timeout instead.")
+	fs.Uint64Var(&cfg.ec.SnapshotCatchUpEntries, "snapshot-catchup-entries", cfg.ec.SnapshotCatchUpEntries, "Number of entries for a slow follower to catch up after compacting the the raft storage entries.")
+	fs.Uint64Var(&cfg.ec.ExperimentalSnapshotCatchUpEntries, "experimental-snapshot-catchup-entries", cfg.ec.ExperimentalSnapshotCatchUpEntries, "Number of entries for a slow follower to catch up after compacting the the raft storage entries. It's deprecated, and will be decommissioned in v3.7. Use --snapshot-catchup-entries instead.")
+	fs.BoolVar(&cfg.ec.ExperimentalSocketActivation, "experimental-socket-activation", cfg.ec.ExperimentalSocketActivation, "Serve the listening sockets passed by systemd (LISTEN_FDS) on the listen client and peer URLs they are bound to, rather than binding them.")
+	fs.IntVar(&cfg.ec.ExperimentalClientMaxConnections, "experimental-client-max-connections", 0, "Maximum number of concurrent connections of each client listener, beyond which the new connections are rejected. 0 means no limit.")
+	fs.Float64Var(&cfg.ec.ExperimentalClientAcceptRate, "experimental-client-accept-rate", 0, "Maximum number of connections accepted per second by each client listener, beyond which the new connections are rejected. 0 means no limit.")
+	fs.IntVar(&cfg.ec.ExperimentalPeerMaxConnections, "experimental-peer-max-connections", 0, "Maximum number of concurrent connections of each peer listener, beyond which the new connections are rejected. 0 means no limit.")
+	fs.Float64Var(&cfg.ec.ExperimentalPeerAcceptRate, "experimental-peer-accept-rate", 0, "Maximum number of connections accepted per second by each peer listener, beyond which the new connections are rejected. 0 means no limit.")
 
 	// unsafe
 	fs.BoolVar(&cfg.ec.UnsafeNoFsync, "unsafe-no-fsync", false, "Disables fsync, unsafe, will cause data loss.")
@@ -326,6 +401,13 @@ func (cfg *config) parse(arguments []string) error {
 		cfg.configFile = os.Getenv(flags.FlagToEnv("ETCD", "config-file"))
 	}
 
+	if cfg.validateConfig {
+		if cfg.checkConfig(os.Stdout) {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+
 	if cfg.configFile != "" {
 		err = cfg.configFromFile(cfg.configFile)
 		if lg := cfg.ec.GetLogger(); lg != nil {
@@ -342,16 +424,20 @@ func (cfg *config) parse(arguments []string) error {
 		cfg.ec.V2Deprecation = cconfig.V2_DEPR_DEFAULT
 	}
 
-	cfg.ec.WarningUnaryRequestDuration, perr = cfg.parseWarningUnaryRequestDuration()
-	if perr != nil {
-		return perr
-	}
-
 	// now logger is set up
 	return err
 }
 
 func (cfg *config) configFromCmdLine() error {
+	if err := cfg.loadCmdLine(); err != nil {
+		return err
+	}
+	return cfg.validate()
+}
+
+// loadCmdLine sets the config from the flags and the environment variables
+// without validating it.
+func (cfg *config) loadCmdLine() error {
 	// user-specified logger is not setup yet, use this logger during flag parsing
 	lg, err := logutil.CreateDefaultZapLogger(zap.InfoLevel)
 	if err != nil {
@@ -374,6 +460,11 @@ func (cfg *config) configFromCmdLine() error {
 		return err
 	}
 
+	cfg.ec.FlagsExplicitlySet = make(map[string]bool)
+	cfg.cf.flagSet.Visit(func(f *flag.Flag) {
+		cfg.ec.FlagsExplicitlySet[f.Name] = true
+	})
+
 	if rafthttp.ConnReadTimeout < rafthttp.DefaultConnReadTimeout {
 		rafthttp.ConnReadTimeout = rafthttp.DefaultConnReadTimeout
 		lg.Info(fmt.Sprintf("raft-read-timeout increased to minimum value: %v", rafthttp.DefaultConnReadTimeout))
@@ -395,6 +486,12 @@ func (cfg *config) configFromCmdLine() error {
 	cfg.ec.HostWhitelist = flags.UniqueStringsMapFromFlag(cfg.cf.flagSet, "host-whitelist")
 
 	cfg.ec.CipherSuites = flags.StringsFromFlag(cfg.cf.flagSet, "cipher-suites")
+	cfg.ec.ExperimentalChangeFeedPrefixes = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-change-feed-prefixes")
+	cfg.ec.ExperimentalSecondaryIndexes = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-secondary-indexes")
+	cfg.ec.ExperimentalClientCertSANRoleRules = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-client-cert-san-role-rules")
+	cfg.ec.ExperimentalVersionRetention = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-version-retention")
+	cfg.ec.ExperimentalAuditLogIncludePrefixes = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-audit-log-include-prefixes")
+	cfg.ec.ExperimentalAuditLogExcludePrefixes = flags.StringsFromFlag(cfg.cf.flagSet, "experimental-audit-log-exclude-prefixes")
 
 	cfg.ec.MaxConcurrentStreams = flags.Uint32FromFlag(cfg.cf.flagSet, "max-concurrent-streams")
 
@@ -414,8 +511,7 @@ func (cfg *config) configFromCmdLine() error {
 	if (cfg.ec.Durl != "" || cfg.ec.DNSCluster != "" || cfg.ec.DNSClusterServiceName != "" || len(cfg.ec.DiscoveryCfg.Endpoints) > 0) && !flags.IsSet(cfg.cf.flagSet, "initial-cluster") {
 		cfg.ec.InitialCluster = ""
 	}
-
-	return cfg.validate()
+	return nil
 }
 
 func (cfg *config) configFromFile(path string) error {
@@ -434,24 +530,3 @@ func (cfg *config) validate() error {
 	}
 	return cfg.ec.Validate()
 }
-
-func (cfg *config) parseWarningUnaryRequestDuration() (time.Duration, error) {
-	if cfg.ec.ExperimentalWarningUnaryRequestDuration != 0 && cfg.ec.WarningUnaryRequestDuration != 0 {
-		return 0, errors.New(
-			"both --experimental-warning-unary-request-duration and --warning-unary-request-duration flags are set. " +
-				"Use only --warning-unary-request-duration")
-	}
-
-	if cfg.ec.WarningUnaryRequestDuration != 0 {
-		return cfg.ec.WarningUnaryRequestDuration, nil
-	}
-
-	if cfg.ec.ExperimentalWarningUnaryRequestDuration != 0 {
-		cfg.ec.GetLogger().Warn(
-			"--experimental-warning-unary-request-duration is deprecated, and will be decommissioned in v3.7. " +
-				"Use --warning-unary-request-duration instead.")
-		return cfg.ec.ExperimentalWarningUnaryRequestDuration, nil
-	}
-
-	return embed.DefaultWarningUnaryRequestDuration, nil
-}
@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// configCheck is the result of --validate-config.
+type configCheck struct {
+	ConfigFile string              `json:"config-file,omitempty"`
+	Valid      bool                `json:"valid"`
+	Errors     []embed.ConfigError `json:"errors,omitempty"`
+}
+
+// checkConfig checks the configuration of the config file, if any, or else
+// of the flags and the environment variables, writes the result as JSON to
+// w and returns whether the configuration is valid.
+func (cfg *config) checkConfig(w io.Writer) bool {
+	res := configCheck{ConfigFile: cfg.configFile}
+	if cfg.configFile != "" {
+		var ec *embed.Config
+		if ec, res.Errors = embed.CheckConfigFile(cfg.configFile); ec != nil {
+			cfg.ec = *ec
+		}
+	} else if err := cfg.loadCmdLine(); err != nil {
+		res.Errors = append(res.Errors, embed.ConfigError{Message: err.Error()})
+	} else {
+		if cfg.cf.fallback.String() == fallbackFlagProxy {
+			res.Errors = append(res.Errors, embed.ConfigError{
+				Fields:  []string{"discovery-fallback"},
+				Message: fmt.Sprintf("v2 proxy is deprecated, and --discovery-fallback can't be configured as %q", fallbackFlagProxy),
+			})
+		}
+		res.Errors = append(res.Errors, cfg.ec.Check()...)
+	}
+	res.Valid = len(res.Errors) == 0
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(res)
+	return res.Valid
+}
@@ -15,6 +15,9 @@
 package etcdmain
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"net/url"
 	"os"
@@ -24,7 +27,9 @@ import (
 
 	"sigs.k8s.io/yaml"
 
+	"go.etcd.io/etcd/pkg/v3/featuregate"
 	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/features"
 )
 
 func TestConfigParsingMemberFlags(t *testing.T) {
@@ -50,6 +55,87 @@ func TestConfigParsingMemberFlags(t *testing.T) {
 	validateMemberFlags(t, cfg)
 }
 
+func TestConfigParsingFeatureGates(t *testing.T) {
+	cfg := newConfig()
+	err := cfg.parse([]string{
+		"--feature-gates=InitialCorruptCheck=true,TxnModeWriteWithSharedBuffer=false",
+		"--experimental-prefix-stats=true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[featuregate.Feature]bool{
+		features.InitialCorruptCheck:          true,
+		features.TxnModeWriteWithSharedBuffer: false,
+		features.PrefixStats:                  true,
+		features.HotKeyTracking:               false,
+	} {
+		if got := cfg.ec.ServerFeatureGate.Enabled(f); got != want {
+			t.Errorf("feature %s = %v, want %v", f, got, want)
+		}
+	}
+	if !cfg.ec.ExperimentalInitialCorruptCheck || cfg.ec.ExperimentalTxnModeWriteWithSharedBuffer {
+		t.Errorf("experimental flags are not set from the feature gates")
+	}
+
+	cfg = newConfig()
+	err = cfg.parse([]string{
+		"--feature-gates=InitialCorruptCheck=false",
+		"--experimental-initial-corrupt-check=true",
+	})
+	if err == nil {
+		t.Fatal("expected an error for the experimental flag conflicting with its feature gate")
+	}
+}
+
+func TestExperimentalFlagsConsolidated(t *testing.T) {
+	graduated := embed.GraduatedExperimentalFlags()
+	cfg := newConfig()
+	cfg.cf.flagSet.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "experimental-") {
+			return
+		}
+		n := 0
+		if _, ok := features.ExperimentalFlagToFeatureMap[f.Name]; ok {
+			n++
+		}
+		if _, ok := features.ExperimentalParamFlagToFeatureMap[f.Name]; ok {
+			n++
+		}
+		if stable, ok := graduated[f.Name]; ok {
+			n++
+			if cfg.cf.flagSet.Lookup(stable) == nil {
+				t.Errorf("--%s graduated to the unknown flag --%s", f.Name, stable)
+			}
+		}
+		if n != 1 {
+			t.Errorf("--%s is neither a feature gate, a parameter of a feature gate nor a graduated flag", f.Name)
+		}
+	})
+}
+
+func TestConfigParsingGraduatedFlags(t *testing.T) {
+	cfg := newConfig()
+	if err := cfg.parse([]string{"--experimental-compaction-batch-limit=10", "--experimental-max-learners=1"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ec.CompactionBatchLimit != 10 || cfg.ec.MaxLearners != 1 {
+		t.Errorf("stable flags are not set from the experimental flags")
+	}
+
+	cfg = newConfig()
+	err := cfg.parse([]string{"--compaction-batch-limit=10", "--experimental-compaction-batch-limit=10"})
+	if err == nil {
+		t.Fatal("expected an error for both the experimental and the stable flag set")
+	}
+
+	cfg = newConfig()
+	err = cfg.parse([]string{"--experimental-watch-max-events-per-second=10"})
+	if err == nil {
+		t.Fatal("expected an error for the experimental flag tuning a disabled feature")
+	}
+}
+
 func TestConfigFileMemberFields(t *testing.T) {
 	yc := struct {
 		Dir                    string `json:"data-dir"`
@@ -375,6 +461,79 @@ func TestConfigFileElectionTimeout(t *testing.T) {
 	}
 }
 
+func TestConfigCheck(t *testing.T) {
+	b := []byte(`
+name: m1
+heartbeat-interval: 800
+election-timeout: 1000
+listen-client-urls: https://127.0.0.1:2379
+advertise-client-urls: http://127.0.0.1:2379
+initial-cluster: m2=http://127.0.0.1:2380
+auto-compaction-retention: abc
+`)
+	tmpfile := mustCreateCfgFile(t, b)
+	defer os.Remove(tmpfile.Name())
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantValid  bool
+		wantFields [][]string
+	}{
+		{
+			name:      "valid flags",
+			args:      []string{"--name=m1", "--initial-cluster=m1=http://localhost:2380"},
+			wantValid: true,
+		},
+		{
+			name: "invalid flags",
+			args: []string{
+				"--name=m1", "--initial-cluster=m2=http://localhost:2380",
+				"--warning-unary-request-duration=1s", "--experimental-warning-unary-request-duration=1s",
+			},
+			wantFields: [][]string{
+				{"experimental-warning-unary-request-duration", "warning-unary-request-duration"},
+				{"initial-cluster", "name"},
+			},
+		},
+		{
+			name: "invalid config file",
+			args: []string{fmt.Sprintf("--config-file=%s", tmpfile.Name())},
+			wantFields: [][]string{
+				nil,
+				{"listen-client-urls", "cert-file", "key-file", "auto-tls"},
+				{"advertise-client-urls", "listen-client-urls"},
+				{"auto-compaction-retention", "auto-compaction-mode"},
+				{"initial-cluster", "name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			if err := cfg.cf.flagSet.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if valid := cfg.checkConfig(&out); valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v: %s", valid, tt.wantValid, out.String())
+			}
+			var res configCheck
+			if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Valid != tt.wantValid || len(res.Errors) != len(tt.wantFields) {
+				t.Fatalf("unexpected result %s", out.String())
+			}
+			for i, e := range res.Errors {
+				if e.Message == "" || !reflect.DeepEqual(e.Fields, tt.wantFields[i]) {
+					t.Errorf("#%d: unexpected error %+v, want fields %v", i, e, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
 func mustCreateCfgFile(t *testing.T, b []byte) *os.File {
 	tmpfile, err := os.CreateTemp("", "servercfg")
 	if err != nil {
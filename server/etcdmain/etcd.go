@@ -15,10 +15,13 @@
 package etcdmain
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
@@ -111,7 +114,7 @@ func startEtcdOrProxyV2(args []string) {
 		)
 		switch which {
 		case dirMember:
-			stopped, errc, err = startEtcd(&cfg.ec)
+			stopped, errc, err = startEtcd(&cfg.ec, cfg.configFile)
 		case dirProxy:
 			lg.Panic("v2 http proxy has already been deprecated in 3.6", zap.String("dir-type", string(which)))
 		default:
@@ -126,7 +129,7 @@ func startEtcdOrProxyV2(args []string) {
 			zap.String("data-dir", cfg.ec.Dir),
 			zap.String("dir-type", string(which)),
 		)
-		stopped, errc, err = startEtcd(&cfg.ec)
+		stopped, errc, err = startEtcd(&cfg.ec, cfg.configFile)
 	}
 
 	if err != nil {
@@ -202,21 +205,51 @@ func startEtcdOrProxyV2(args []string) {
 }
 
 // startEtcd runs StartEtcd in addition to hooks needed for standalone etcd.
-func startEtcd(cfg *embed.Config) (<-chan struct{}, <-chan error, error) {
+func startEtcd(cfg *embed.Config, configFile string) (<-chan struct{}, <-chan error, error) {
 	e, err := embed.StartEtcd(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
-	osutil.RegisterInterruptHandler(e.Close)
+	osutil.RegisterInterruptHandler(func() {
+		if d := cfg.ExperimentalDrainTimeout; d > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), d)
+			if err := e.Server.Drain(ctx); err != nil {
+				e.GetLogger().Warn("failed to drain the client requests", zap.Error(err))
+			}
+			cancel()
+		}
+		e.Close()
+	})
+	if configFile != "" {
+		reconfigureOnSIGHUP(e, configFile)
+	}
 	select {
 	case <-e.Server.ReadyNotify(): // wait for e.Server to join the cluster
 	case <-e.Server.StopNotify(): // publish aborted from 'ErrStopped'
-	case <-time.After(cfg.ExperimentalWaitClusterReadyTimeout):
+	case <-time.After(cfg.WaitClusterReadyTimeout):
 		e.GetLogger().Warn("startEtcd: timed out waiting for the ready notification")
 	}
 	return e.Server.StopNotify(), e.Err(), nil
 }
 
+// reconfigureOnSIGHUP reads the config file again on every SIGHUP and applies
+// the settings that can be changed at runtime.
+func reconfigureOnSIGHUP(e *embed.Etcd, configFile string) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	go func() {
+		for range sigc {
+			lg := e.GetLogger()
+			cfg, err := e.ReconfigureFromFile(configFile)
+			if err != nil {
+				lg.Warn("failed to reconfigure server", zap.String("path", configFile), zap.Error(err))
+				continue
+			}
+			lg.Info("reconfigured server from config file", zap.String("path", configFile), zap.String("log-level", cfg.LogLevel))
+		}
+	}()
+}
+
 // identifyDataDirOrDie returns the type of the data dir.
 // Dies if the datadir is invalid.
 func identifyDataDirOrDie(lg *zap.Logger, dir string) dirType {
@@ -27,6 +27,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
@@ -42,6 +43,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3election/v3electionpb"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3lock/v3lockpb"
 	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy/cache"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
@@ -53,6 +55,7 @@ import (
 	"go.uber.org/zap/zapgrpc"
 	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/grpclog"
 	"google.golang.org/grpc/keepalive"
 )
@@ -76,6 +79,13 @@ var (
 	grpcProxyKey                   string
 	grpcProxyInsecureSkipTLSVerify bool
 
+	// auth for connecting to etcd
+
+	grpcProxyUser     string
+	grpcProxyPassword string
+
+	grpcProxyAuthTokenRefreshInterval time.Duration
+
 	// tls for clients connecting to proxy
 
 	grpcProxyListenCA           string
@@ -90,13 +100,26 @@ var (
 	grpcProxyResolverPrefix     string
 	grpcProxyResolverTTL        int
 
-	grpcProxyNamespace string
-	grpcProxyLeasing   string
+	grpcProxyNamespace        string
+	grpcProxyClientNamespaces []string
+	grpcProxyLeasing          string
+	grpcProxyShards           []string
+
+	grpcProxyCacheMaxEntries    int
+	grpcProxyCacheMaxStaleness  time.Duration
+	grpcProxyCacheWatchPrefixes []string
 
 	grpcProxyEnablePprof    bool
 	grpcProxyEnableOrdering bool
 	grpcProxyEnableLogging  bool
 
+	grpcProxyMaxConcurrentRequests int
+	grpcProxyRequestQueueTimeout   time.Duration
+	grpcProxyShedLatencyThreshold  time.Duration
+	grpcProxyLowPriorityMethods    []string
+
+	grpcProxyConfigFilePath string
+
 	grpcProxyDebug bool
 
 	// GRPC keep alive related options.
@@ -156,6 +179,10 @@ func newGRPCProxyStartCommand() *cobra.Command {
 	cmd.Flags().StringVar(&grpcProxyCA, "cacert", "", "verify certificates of TLS-enabled secure etcd servers using this CA bundle")
 	cmd.Flags().BoolVar(&grpcProxyInsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip authentication of etcd server TLS certificates (CAUTION: this option should be enabled only for testing purposes)")
 
+	// client auth for connecting to server
+	cmd.Flags().StringVar(&grpcProxyUser, "user", "", "username[:password] for authenticating the proxy with etcd servers, instead of forwarding the auth tokens of the clients")
+	cmd.Flags().StringVar(&grpcProxyPassword, "password", "", "password for authenticating the proxy with etcd servers, the username of --user being taken as a whole")
+
 	// client TLS for connecting to proxy
 	cmd.Flags().StringVar(&grpcProxyListenCert, "cert-file", "", "identify secure connections to the proxy using this TLS certificate file")
 	cmd.Flags().StringVar(&grpcProxyListenKey, "key-file", "", "identify secure connections to the proxy using this TLS key file")
@@ -169,6 +196,18 @@ func newGRPCProxyStartCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&grpcProxyEnableOrdering, "experimental-serializable-ordering", false, "Ensure serializable reads have monotonically increasing store revisions across endpoints.")
 	cmd.Flags().StringVar(&grpcProxyLeasing, "experimental-leasing-prefix", "", "leasing metadata prefix for disconnected linearized reads.")
 	cmd.Flags().BoolVar(&grpcProxyEnableLogging, "experimental-enable-grpc-logging", false, "logging all grpc requests and responses")
+	cmd.Flags().IntVar(&grpcProxyCacheMaxEntries, "experimental-cache-max-entries", cache.DefaultMaxEntries, "Maximum number of the cached serializable range responses.")
+	cmd.Flags().DurationVar(&grpcProxyCacheMaxStaleness, "experimental-cache-max-staleness", 0, "Maximum age of the cached serializable range responses (0 to serve them until invalidated by the writes through the proxy).")
+	cmd.Flags().StringArrayVar(&grpcProxyCacheWatchPrefixes, "experimental-cache-watch-prefix", nil, "Prefix of the keys watched to invalidate the cached range responses on the writes not going through the proxy (can be repeated).")
+	cmd.Flags().StringArrayVar(&grpcProxyClientNamespaces, "experimental-client-namespace", nil, "user:name=prefix or cn:common-name=prefix of the namespace of the keys of the clients authenticated as the user, which requires --experimental-auth-token-refresh-interval, or with a TLS certificate of the common name (can be repeated).")
+	cmd.Flags().StringArrayVar(&grpcProxyShards, "experimental-shard", nil, "prefix=endpoint1,endpoint2 of an etcd cluster serving the keys under the prefix instead of the --endpoints cluster (can be repeated).")
+	cmd.Flags().IntVar(&grpcProxyMaxConcurrentRequests, "experimental-max-concurrent-requests", 0, "Maximum number of the low and normal priority unary requests served concurrently (0 for no limit).")
+	cmd.Flags().DurationVar(&grpcProxyRequestQueueTimeout, "experimental-request-queue-timeout", 0, "Maximum time a unary request waits to be served at the concurrent requests limit (0 to wait until the request is canceled).")
+	cmd.Flags().DurationVar(&grpcProxyShedLatencyThreshold, "experimental-shed-latency-threshold", 0, "Backend latency above which the low priority unary requests are rejected (0 to disable).")
+	cmd.Flags().DurationVar(&grpcProxyAuthTokenRefreshInterval, "experimental-auth-token-refresh-interval", 0, "Age of the auth tokens shared between the clients authenticating as the same user, from which they are refreshed; should be lower than the auth token TTL of the cluster (0 to forward the authentications).")
+	cmd.Flags().StringSliceVar(&grpcProxyLowPriorityMethods, "experimental-low-priority-methods", nil, "Comma-separated list of the full gRPC method names of low priority, like /etcdserverpb.KV/Range, overridden by the "+grpcproxy.PriorityMetadataKey+" request metadata.")
+
+	cmd.Flags().StringVar(&grpcProxyConfigFilePath, "experimental-config-file", "", "Path to a YAML file of endpoints, experimental-client-namespace, experimental-max-concurrent-requests, experimental-request-queue-timeout, experimental-shed-latency-threshold and experimental-low-priority-methods overriding the flags, reloaded on SIGHUP or on a POST to "+grpcproxy.PathProxyReload+". The TLS certificates, keys and CRLs are read again on new connections without reloading.")
 
 	cmd.Flags().BoolVar(&grpcProxyDebug, "debug", false, "Enable debug-level logging for grpc-proxy.")
 
@@ -178,6 +217,7 @@ func newGRPCProxyStartCommand() *cobra.Command {
 }
 
 func startGRPCProxy(cmd *cobra.Command, args []string) {
+	mustLoadGRPCProxyConfigFile()
 	checkArgs()
 	lvl := zap.InfoLevel
 	if grpcProxyDebug {
@@ -223,7 +263,30 @@ func startGRPCProxy(cmd *cobra.Command, args []string) {
 		lg.Info("stop listening gRPC proxy client requests", zap.String("address", grpcProxyListenAddr))
 	}()
 
-	client := mustNewClient(lg)
+	var tokenCache *grpcproxy.AuthTokenCache
+	if grpcProxyAuthTokenRefreshInterval > 0 {
+		tokenCache = grpcproxy.NewAuthTokenCache(lg, grpcProxyAuthTokenRefreshInterval)
+	}
+	client := mustNewClient(lg, tokenCache)
+	shards := mustNewShards(lg)
+
+	var admission *grpcproxy.Admission
+	if grpcProxyConfigFilePath != "" || grpcProxyMaxConcurrentRequests > 0 || grpcProxyShedLatencyThreshold > 0 {
+		admission = grpcproxy.NewAdmission(currentGRPCProxySettings().admission)
+	}
+	var namespaces *grpcproxy.ClientNamespaces
+	if grpcProxyConfigFilePath != "" || len(grpcProxyClientNamespaces) > 0 {
+		// validated by checkArgs
+		cfg, _ := parseGRPCProxyClientNamespaces(grpcProxyClientNamespaces)
+		cfg.TokenCache = tokenCache
+		namespaces = grpcproxy.NewClientNamespaces(cfg)
+	}
+	var reload func() error
+	if grpcProxyConfigFilePath != "" {
+		r := &grpcProxyReloader{lg: lg, client: client, tokenCache: tokenCache, namespaces: namespaces, admission: admission}
+		r.reloadOnSIGHUP()
+		reload = r.reload
+	}
 
 	// The proxy client is used for self-healthchecking.
 	// TODO: The mechanism should be refactored to use internal connection.
@@ -233,7 +296,8 @@ func startGRPCProxy(cmd *cobra.Command, args []string) {
 	}
 	httpClient := mustNewHTTPClient(lg)
 
-	srvhttp, httpl := mustHTTPListener(lg, m, tlsInfo, client, proxyClient)
+	cacheStatus := grpcproxy.NewCacheStatusHandler()
+	srvhttp, httpl := mustHTTPListener(lg, m, tlsInfo, client, proxyClient, cacheStatus, reload)
 
 	if err := http2.ConfigureServer(srvhttp, &http2.Server{
 		MaxConcurrentStreams: maxConcurrentStreams,
@@ -242,7 +306,9 @@ func startGRPCProxy(cmd *cobra.Command, args []string) {
 	}
 
 	errc := make(chan error, 3)
-	go func() { errc <- newGRPCProxyServer(lg, client).Serve(grpcl) }()
+	go func() {
+		errc <- newGRPCProxyServer(lg, client, shards, cacheStatus, tokenCache, admission, namespaces).Serve(grpcl)
+	}()
 	go func() { errc <- srvhttp.Serve(httpl) }()
 	go func() { errc <- m.Serve() }()
 	if len(grpcProxyMetricsListenAddr) > 0 {
@@ -253,6 +319,10 @@ func startGRPCProxy(cmd *cobra.Command, args []string) {
 			grpcproxy.HandleHealth(lg, mux, client)
 			grpcproxy.HandleProxyMetrics(mux)
 			grpcproxy.HandleProxyHealth(lg, mux, proxyClient)
+			grpcproxy.HandleProxyCache(mux, cacheStatus)
+			if reload != nil {
+				grpcproxy.HandleProxyReload(mux, reload)
+			}
 			lg.Info("gRPC proxy server metrics URL serving")
 			herr := http.Serve(mhttpl, mux)
 			if herr != nil {
@@ -285,27 +355,81 @@ func checkArgs() {
 		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid advertise-client-url %q", grpcProxyAdvertiseClientURL))
 		os.Exit(1)
 	}
+	if grpcProxyUser == "" && grpcProxyPassword != "" {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("password given without user"))
+		os.Exit(1)
+	}
+	if grpcProxyUser != "" {
+		if _, _, err := backendUsernamePassword(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if grpcProxyAuthTokenRefreshInterval < 0 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid experimental-auth-token-refresh-interval %v", grpcProxyAuthTokenRefreshInterval))
+		os.Exit(1)
+	}
+	if grpcProxyUser != "" && grpcProxyAuthTokenRefreshInterval > 0 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("experimental-auth-token-refresh-interval cannot be used with user"))
+		os.Exit(1)
+	}
+	if err := currentGRPCProxySettings().validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if shards, err := parseGRPCProxyShards(grpcProxyShards); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	} else if len(shards) > 0 && (grpcProxyLeasing != "" || grpcProxyEnableOrdering) {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("experimental-shard cannot be used with experimental-leasing-prefix or experimental-serializable-ordering"))
+		os.Exit(1)
+	}
+	if grpcProxyCacheMaxEntries <= 0 || grpcProxyCacheMaxStaleness < 0 {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("invalid cache, the maximum entries must be positive and the maximum staleness not negative"))
+		os.Exit(1)
+	}
+	for _, prefix := range grpcProxyCacheWatchPrefixes {
+		if prefix == "" {
+			fmt.Fprintln(os.Stderr, fmt.Errorf("invalid empty experimental-cache-watch-prefix"))
+			os.Exit(1)
+		}
+	}
 	if grpcProxyListenAutoTLS && selfSignedCertValidity == 0 {
 		fmt.Fprintln(os.Stderr, fmt.Errorf("selfSignedCertValidity is invalid,it should be greater than 0"))
 		os.Exit(1)
 	}
 }
 
-func mustNewClient(lg *zap.Logger) *clientv3.Client {
+// mustNewClient returns the client of the --endpoints cluster, forwarding the
+// auth tokens of the clients through the token cache if not nil.
+func mustNewClient(lg *zap.Logger, tc *grpcproxy.AuthTokenCache) *clientv3.Client {
 	srvs := discoverEndpoints(lg, grpcProxyDNSCluster, grpcProxyCA, grpcProxyInsecureDiscovery, grpcProxyDNSClusterServiceName)
 	eps := srvs.Endpoints
 	if len(eps) == 0 {
 		eps = grpcProxyEndpoints
 	}
+	return mustNewClientWithEndpoints(lg, eps, tc)
+}
+
+func mustNewClientWithEndpoints(lg *zap.Logger, eps []string, tc *grpcproxy.AuthTokenCache) *clientv3.Client {
 	cfg, err := newClientCfg(lg, eps)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	cfg.DialOptions = append(cfg.DialOptions,
-		grpc.WithUnaryInterceptor(grpcproxy.AuthUnaryClientInterceptor))
-	cfg.DialOptions = append(cfg.DialOptions,
-		grpc.WithStreamInterceptor(grpcproxy.AuthStreamClientInterceptor))
+	if grpcProxyUser == "" && tc != nil {
+		cfg.DialOptions = append(cfg.DialOptions,
+			grpc.WithUnaryInterceptor(tc.UnaryClientInterceptor))
+		cfg.DialOptions = append(cfg.DialOptions,
+			grpc.WithStreamInterceptor(tc.StreamClientInterceptor))
+	} else if grpcProxyUser == "" {
+		// forward the auth tokens of the clients, the proxy authenticating
+		// with its own user otherwise.
+		cfg.DialOptions = append(cfg.DialOptions,
+			grpc.WithUnaryInterceptor(grpcproxy.AuthUnaryClientInterceptor))
+		cfg.DialOptions = append(cfg.DialOptions,
+			grpc.WithStreamInterceptor(grpcproxy.AuthStreamClientInterceptor))
+	}
 	cfg.Logger = lg.Named("client")
 	client, err := clientv3.New(*cfg)
 	if err != nil {
@@ -315,6 +439,71 @@ func mustNewClient(lg *zap.Logger) *clientv3.Client {
 	return client
 }
 
+// grpcProxyShard is an etcd cluster serving the keys under a prefix.
+type grpcProxyShard struct {
+	prefix    string
+	endpoints []string
+}
+
+// parseGRPCProxyShards parses the --experimental-shard values, each
+// prefix=endpoint1,endpoint2.
+func parseGRPCProxyShards(values []string) ([]grpcProxyShard, error) {
+	var shards []grpcProxyShard
+	prefixes := make(map[string]struct{})
+	for _, v := range values {
+		i := strings.LastIndex(v, "=")
+		if i <= 0 || i == len(v)-1 {
+			return nil, fmt.Errorf("invalid shard %q, expected prefix=endpoint1,endpoint2", v)
+		}
+		prefix := v[:i]
+		if _, ok := prefixes[prefix]; ok {
+			return nil, fmt.Errorf("duplicate shard prefix %q", prefix)
+		}
+		prefixes[prefix] = struct{}{}
+		shards = append(shards, grpcProxyShard{prefix: prefix, endpoints: strings.Split(v[i+1:], ",")})
+	}
+	return shards, nil
+}
+
+// parseGRPCProxyClientNamespaces parses the --experimental-client-namespace
+// values, each user:name=prefix or cn:common-name=prefix.
+func parseGRPCProxyClientNamespaces(values []string) (grpcproxy.ClientNamespaceConfig, error) {
+	users, commonNames := make(map[string]string), make(map[string]string)
+	for _, v := range values {
+		identity, prefix, ok := strings.Cut(v, "=")
+		kind, name, _ := strings.Cut(identity, ":")
+		var namespaces map[string]string
+		switch kind {
+		case "user":
+			namespaces = users
+		case "cn":
+			namespaces = commonNames
+		}
+		if !ok || prefix == "" || namespaces == nil || name == "" {
+			return grpcproxy.ClientNamespaceConfig{}, fmt.Errorf("invalid client namespace %q, expected user:name=prefix or cn:common-name=prefix", v)
+		}
+		if _, ok := namespaces[name]; ok {
+			return grpcproxy.ClientNamespaceConfig{}, fmt.Errorf("duplicate client namespace of %q", identity)
+		}
+		namespaces[name] = prefix
+	}
+	return grpcproxy.ClientNamespaceConfig{Users: users, CommonNames: commonNames}, nil
+}
+
+func mustNewShards(lg *zap.Logger) []grpcproxy.Shard {
+	specs, err := parseGRPCProxyShards(grpcProxyShards)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var shards []grpcproxy.Shard
+	for _, spec := range specs {
+		shards = append(shards, grpcproxy.Shard{Prefix: spec.prefix, Client: mustNewClientWithEndpoints(lg, spec.endpoints, nil)})
+		lg.Info("gRPC proxy shard", zap.String("prefix", spec.prefix), zap.Strings("endpoints", spec.endpoints))
+	}
+	return shards
+}
+
 func mustNewProxyClient(lg *zap.Logger, tls *transport.TLSInfo) *clientv3.Client {
 	eps := []string{grpcProxyAdvertiseClientURL}
 	cfg, err := newProxyClientCfg(lg.Named("client"), eps, tls)
@@ -378,9 +567,31 @@ func newClientCfg(lg *zap.Logger, eps []string) (*clientv3.Config, error) {
 		cfg.TLS = clientTLS
 		lg.Info("gRPC proxy client TLS", zap.String("tls-info", fmt.Sprintf("%+v", tls)))
 	}
+
+	if grpcProxyUser != "" {
+		username, password, err := backendUsernamePassword()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Username, cfg.Password = username, password
+		lg.Info("gRPC proxy client authenticating", zap.String("user-name", username))
+	}
 	return &cfg, nil
 }
 
+// backendUsernamePassword returns the username and the password the proxy
+// authenticates with to etcd servers, from --user and --password.
+func backendUsernamePassword() (string, string, error) {
+	if grpcProxyPassword != "" {
+		return grpcProxyUser, grpcProxyPassword, nil
+	}
+	username, password, ok := strings.Cut(grpcProxyUser, ":")
+	if !ok || username == "" {
+		return "", "", fmt.Errorf("invalid user %q, expected username:password or --password", grpcProxyUser)
+	}
+	return username, password, nil
+}
+
 func newTLS(ca, cert, key string, requireEmptyCN bool) *transport.TLSInfo {
 	if ca == "" && cert == "" && key == "" {
 		return nil
@@ -410,7 +621,43 @@ func mustListenCMux(lg *zap.Logger, tlsinfo *transport.TLSInfo) cmux.CMux {
 	return cmux.New(l)
 }
 
-func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
+// listenerTLSCredentials passes the connections through, exposing to the
+// gRPC server the TLS state of the connections of the TLS listener.
+type listenerTLSCredentials struct{}
+
+func (listenerTLSCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("client handshake is not supported")
+}
+
+func (listenerTLSCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	c := conn
+	if mc, ok := c.(*cmux.MuxConn); ok {
+		c = mc.Conn
+	}
+	tc, ok := c.(*tls.Conn)
+	if !ok {
+		return conn, nil, nil
+	}
+	return conn, credentials.TLSInfo{
+		State:          tc.ConnectionState(),
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+	}, nil
+}
+
+func (listenerTLSCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{}
+}
+
+func (c listenerTLSCredentials) Clone() credentials.TransportCredentials { return c }
+
+func (listenerTLSCredentials) OverrideServerName(string) error { return nil }
+
+func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client, shards []grpcproxy.Shard, cacheStatus *grpcproxy.CacheStatusHandler, tokenCache *grpcproxy.AuthTokenCache, admission *grpcproxy.Admission, namespaces *grpcproxy.ClientNamespaces) *grpc.Server {
+	if len(shards) > 0 {
+		client.KV = grpcproxy.NewShardedKV(client.KV, shards)
+		client.Watcher = grpcproxy.NewShardedWatcher(client.Watcher, shards)
+	}
+
 	if grpcProxyEnableOrdering {
 		vf := ordering.NewOrderViolationSwitchEndpointClosure(client)
 		client.KV = ordering.NewKV(client.KV, vf)
@@ -435,8 +682,13 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 		client.KV, _, _ = leasing.NewKV(client, grpcProxyLeasing)
 	}
 
-	kvp, _ := grpcproxy.NewKvProxy(client)
+	kvp, _ := grpcproxy.NewKvProxyWithCache(client, grpcproxy.KvCacheConfig{
+		MaxEntries:    grpcProxyCacheMaxEntries,
+		MaxStaleness:  grpcProxyCacheMaxStaleness,
+		WatchPrefixes: grpcProxyCacheWatchPrefixes,
+	})
 	watchp, _ := grpcproxy.NewWatchProxy(client.Ctx(), lg, client)
+	cacheStatus.Register(kvp, watchp)
 	if grpcProxyResolverPrefix != "" {
 		grpcproxy.Register(lg, client, grpcProxyResolverPrefix, grpcProxyAdvertiseClientURL, grpcProxyResolverTTL)
 	}
@@ -444,7 +696,17 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 	leasep, _ := grpcproxy.NewLeaseProxy(client.Ctx(), client)
 
 	mainp := grpcproxy.NewMaintenanceProxy(client)
+	if len(shards) > 0 {
+		if grpcProxyAdvertiseClientURL == "" {
+			// the members of the clusters, not the proxy itself
+			clusterp = grpcproxy.NewShardedClusterProxy(clusterp, shards)
+		}
+		mainp = grpcproxy.NewShardedMaintenanceProxy(mainp, shards)
+	}
 	authp := grpcproxy.NewAuthProxy(client)
+	if tokenCache != nil {
+		authp = grpcproxy.NewAuthProxyWithTokenCache(client, tokenCache)
+	}
 	electionp := grpcproxy.NewElectionProxy(client)
 	lockp := grpcproxy.NewLockProxy(client)
 
@@ -456,6 +718,13 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 	grpcChainUnaryList := []grpc.UnaryServerInterceptor{
 		grpc_prometheus.UnaryServerInterceptor,
 	}
+	if admission != nil {
+		grpcChainUnaryList = append(grpcChainUnaryList, admission.UnaryServerInterceptor)
+	}
+	if namespaces != nil {
+		grpcChainStreamList = append(grpcChainStreamList, namespaces.StreamServerInterceptor)
+		grpcChainUnaryList = append(grpcChainUnaryList, namespaces.UnaryServerInterceptor)
+	}
 	if grpcProxyEnableLogging {
 		grpcChainStreamList = append(grpcChainStreamList,
 			grpc_ctxtags.StreamServerInterceptor(),
@@ -475,6 +744,8 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 			grpcChainUnaryList...,
 		)),
 		grpc.MaxConcurrentStreams(math.MaxUint32),
+		// the client certificates identify the namespaces of the clients
+		grpc.Creds(listenerTLSCredentials{}),
 	}
 	if grpcKeepAliveMinTime > time.Duration(0) {
 		gopts = append(gopts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
@@ -504,7 +775,7 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 	return server
 }
 
-func mustHTTPListener(lg *zap.Logger, m cmux.CMux, tlsinfo *transport.TLSInfo, c *clientv3.Client, proxy *clientv3.Client) (*http.Server, net.Listener) {
+func mustHTTPListener(lg *zap.Logger, m cmux.CMux, tlsinfo *transport.TLSInfo, c *clientv3.Client, proxy *clientv3.Client, cacheStatus *grpcproxy.CacheStatusHandler, reload func() error) (*http.Server, net.Listener) {
 	httpClient := mustNewHTTPClient(lg)
 	httpmux := http.NewServeMux()
 	httpmux.HandleFunc("/", http.NotFound)
@@ -512,6 +783,10 @@ func mustHTTPListener(lg *zap.Logger, m cmux.CMux, tlsinfo *transport.TLSInfo, c
 	grpcproxy.HandleHealth(lg, httpmux, c)
 	grpcproxy.HandleProxyMetrics(httpmux)
 	grpcproxy.HandleProxyHealth(lg, httpmux, proxy)
+	grpcproxy.HandleProxyCache(httpmux, cacheStatus)
+	if reload != nil {
+		grpcproxy.HandleProxyReload(httpmux, reload)
+	}
 	if grpcProxyEnablePprof {
 		for p, h := range debugutil.PProfHandlers() {
 			httpmux.Handle(p, h)
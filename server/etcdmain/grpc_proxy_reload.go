@@ -0,0 +1,194 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+)
+
+// grpcProxyConfigFile is the content of the --experimental-config-file, whose
+// settings override the flags of the same names and are reloaded without
+// restarting the proxy.
+type grpcProxyConfigFile struct {
+	Endpoints             []string `json:"endpoints"`
+	ClientNamespaces      []string `json:"experimental-client-namespace"`
+	MaxConcurrentRequests *int     `json:"experimental-max-concurrent-requests"`
+	RequestQueueTimeout   string   `json:"experimental-request-queue-timeout"`
+	ShedLatencyThreshold  string   `json:"experimental-shed-latency-threshold"`
+	LowPriorityMethods    []string `json:"experimental-low-priority-methods"`
+}
+
+// grpcProxySettings are the settings of the proxy that can be reloaded.
+type grpcProxySettings struct {
+	endpoints        []string
+	clientNamespaces []string
+	admission        grpcproxy.AdmissionConfig
+}
+
+// grpcProxyFlagSettings are the settings given by the flags, the settings
+// removed from the config file falling back to them on reload.
+var grpcProxyFlagSettings grpcProxySettings
+
+func currentGRPCProxySettings() grpcProxySettings {
+	return grpcProxySettings{
+		endpoints:        grpcProxyEndpoints,
+		clientNamespaces: grpcProxyClientNamespaces,
+		admission: grpcproxy.AdmissionConfig{
+			MaxConcurrentRequests: grpcProxyMaxConcurrentRequests,
+			QueueTimeout:          grpcProxyRequestQueueTimeout,
+			ShedLatencyThreshold:  grpcProxyShedLatencyThreshold,
+			LowPriorityMethods:    grpcProxyLowPriorityMethods,
+		},
+	}
+}
+
+func (s grpcProxySettings) validate() error {
+	if cfg, err := parseGRPCProxyClientNamespaces(s.clientNamespaces); err != nil {
+		return err
+	} else if len(cfg.Users) > 0 && grpcProxyAuthTokenRefreshInterval == 0 {
+		return fmt.Errorf("experimental-client-namespace of users requires experimental-auth-token-refresh-interval")
+	}
+	if s.admission.MaxConcurrentRequests < 0 || s.admission.QueueTimeout < 0 || s.admission.ShedLatencyThreshold < 0 {
+		return fmt.Errorf("invalid admission control, the limits must not be negative")
+	}
+	return nil
+}
+
+// loadGRPCProxySettings returns the settings of the config file at path over
+// the given settings.
+func loadGRPCProxySettings(path string, s grpcProxySettings) (grpcProxySettings, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	var cfg grpcProxyConfigFile
+	if err = yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return s, fmt.Errorf("invalid config file %q: %v", path, err)
+	}
+
+	if cfg.Endpoints != nil {
+		if grpcProxyDNSCluster != "" {
+			return s, fmt.Errorf("endpoints of the config file cannot be used with discovery-srv")
+		}
+		s.endpoints = cfg.Endpoints
+	}
+	if cfg.ClientNamespaces != nil {
+		s.clientNamespaces = cfg.ClientNamespaces
+	}
+	if cfg.MaxConcurrentRequests != nil {
+		s.admission.MaxConcurrentRequests = *cfg.MaxConcurrentRequests
+	}
+	if cfg.RequestQueueTimeout != "" {
+		if s.admission.QueueTimeout, err = time.ParseDuration(cfg.RequestQueueTimeout); err != nil {
+			return s, fmt.Errorf("invalid experimental-request-queue-timeout: %v", err)
+		}
+	}
+	if cfg.ShedLatencyThreshold != "" {
+		if s.admission.ShedLatencyThreshold, err = time.ParseDuration(cfg.ShedLatencyThreshold); err != nil {
+			return s, fmt.Errorf("invalid experimental-shed-latency-threshold: %v", err)
+		}
+	}
+	if cfg.LowPriorityMethods != nil {
+		s.admission.LowPriorityMethods = cfg.LowPriorityMethods
+	}
+	if len(s.endpoints) == 0 && grpcProxyDNSCluster == "" {
+		return s, fmt.Errorf("no endpoints")
+	}
+	return s, s.validate()
+}
+
+// mustLoadGRPCProxyConfigFile overrides the flags by the settings of the
+// config file, if any.
+func mustLoadGRPCProxyConfigFile() {
+	grpcProxyFlagSettings = currentGRPCProxySettings()
+	if grpcProxyConfigFilePath == "" {
+		return
+	}
+	s, err := loadGRPCProxySettings(grpcProxyConfigFilePath, grpcProxyFlagSettings)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	grpcProxyEndpoints = s.endpoints
+	grpcProxyClientNamespaces = s.clientNamespaces
+	grpcProxyMaxConcurrentRequests = s.admission.MaxConcurrentRequests
+	grpcProxyRequestQueueTimeout = s.admission.QueueTimeout
+	grpcProxyShedLatencyThreshold = s.admission.ShedLatencyThreshold
+	grpcProxyLowPriorityMethods = s.admission.LowPriorityMethods
+}
+
+// grpcProxyReloader applies the settings of the config file to the running
+// proxy, the established client streams being kept.
+type grpcProxyReloader struct {
+	lg         *zap.Logger
+	client     *clientv3.Client
+	tokenCache *grpcproxy.AuthTokenCache
+	namespaces *grpcproxy.ClientNamespaces
+	admission  *grpcproxy.Admission
+
+	mu sync.Mutex
+}
+
+func (r *grpcProxyReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, err := loadGRPCProxySettings(grpcProxyConfigFilePath, grpcProxyFlagSettings)
+	if err != nil {
+		r.lg.Warn("failed to reload gRPC proxy config file", zap.String("path", grpcProxyConfigFilePath), zap.Error(err))
+		return err
+	}
+
+	if grpcProxyDNSCluster == "" {
+		r.client.SetEndpoints(s.endpoints...)
+	}
+	// validated by loadGRPCProxySettings
+	cfg, _ := parseGRPCProxyClientNamespaces(s.clientNamespaces)
+	cfg.TokenCache = r.tokenCache
+	r.namespaces.Update(cfg)
+	r.admission.Update(s.admission)
+	r.lg.Info(
+		"reloaded gRPC proxy config file",
+		zap.String("path", grpcProxyConfigFilePath),
+		zap.Strings("endpoints", r.client.Endpoints()),
+		zap.Strings("client-namespaces", s.clientNamespaces),
+		zap.Int("max-concurrent-requests", s.admission.MaxConcurrentRequests),
+		zap.Duration("request-queue-timeout", s.admission.QueueTimeout),
+		zap.Duration("shed-latency-threshold", s.admission.ShedLatencyThreshold),
+		zap.Strings("low-priority-methods", s.admission.LowPriorityMethods),
+	)
+	return nil
+}
+
+// reloadOnSIGHUP reloads the config file on every SIGHUP.
+func (r *grpcProxyReloader) reloadOnSIGHUP() {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	go func() {
+		for range sigc {
+			r.reload()
+		}
+	}()
+}
@@ -39,6 +39,12 @@ var (
 
   etcd --config-file
     Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.
+    The file may reference environment variables as ${NAME} or ${NAME:-default}, and merge other files listed by its include key under its settings.
+    On SIGHUP, the log-level, auto-compaction-retention, quota-backend-bytes, corrupt-check-time,
+    warning-apply-duration and warning-unary-request-duration of the file are applied without restarting.
+
+  etcd --validate-config [--config-file etcd.yaml | flags]
+    Check the configuration, printing the problems found as JSON, and exit with status 1 if it is invalid.
 
   etcd gateway
     Run the stateless pass-through etcd TCP connection forwarding proxy.
@@ -94,6 +100,30 @@ Member:
     Enable to set socket option SO_REUSEPORT on listeners allowing rebinding of a port already in use.
   --socket-reuse-address 'false'
 	Enable to set socket option SO_REUSEADDR on listeners allowing binding to an address in TIME_WAIT state.
+  --snapshot-catchup-entries '5000'
+    Number of entries for a slow follower to catch up after compacting the the raft storage entries.
+  --wait-cluster-ready-timeout '5s'
+    Maximum duration to wait for the cluster to be ready.
+  --corrupt-check-time '0s'
+    Duration of time between cluster corruption check passes.
+  --compact-hash-check-time '1m'
+    Duration of time between leader checks followers compaction hashes.
+  --compaction-batch-limit 1000
+    Sets the maximum revisions deleted in each compaction batch.
+  --compaction-sleep-interval '0s'
+    Sets the sleep interval between each compaction batch, 0 meaning the default of 10ms.
+  --watch-progress-notify-interval '10m'
+    Duration of periodic watch progress notifications.
+  --warning-apply-duration '100ms'
+    Time duration after which a warning is generated if request takes more time.
+  --bootstrap-defrag-threshold-megabytes
+    Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.
+  --max-learners '1'
+    Sets the maximum number of learners that can be available in the cluster membership.
+  --downgrade-check-time '5s'
+    Duration of time between two downgrade status check.
+  --memory-mlock 'false'
+    Enable to enforce etcd pages (in particular bbolt) to stay in RAM.
 
 Clustering:
   --initial-advertise-peer-urls 'http://localhost:2380'
@@ -151,7 +181,9 @@ Clustering:
   --auto-compaction-retention '0'
     Auto compaction retention length. 0 means disable auto compaction.
   --auto-compaction-mode 'periodic'
-    Interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.
+    Interpret 'auto-compaction-retention' one of: periodic|revision|hybrid. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention. 'hybrid' for duration based retention keeping at most 'auto-compaction-max-revisions' revisions.
+  --auto-compaction-max-revisions '0'
+    Maximum number of revisions kept by the 'hybrid' auto compaction mode, even if they are within 'auto-compaction-retention'.
   --v2-deprecation '` + string(cconfig.V2_DEPR_DEFAULT) + `'
     Phase of v2store deprecation. Allows to opt-in for higher compatibility mode.
     Supported values:
@@ -193,6 +225,8 @@ Security:
     The validity period of the client and peer certificates that are automatically generated by etcd when you specify ClientAutoTLS and PeerAutoTLS, the unit is year, and the default is 1.
   --peer-crl-file ''
     Path to the peer certificate revocation list file.
+  --peer-skip-client-san-verification 'false'
+    Skip verification of SAN field in client certificate for peer connections.
   --cipher-suites ''
     Comma-separated list of supported TLS cipher suites between client/server and peers (empty will be auto-populated by Go).
   --cors '*'
@@ -206,11 +240,19 @@ Security:
 
 Auth:
   --auth-token 'simple'
-    Specify a v3 authentication token type and its options ('simple' or 'jwt').
+    Specify a v3 authentication token type and its options ('simple', 'jwt' or 'oidc').
+    The 'jwt' tokens of an external identity provider are verified with the keys of its 'jwks-url', checking the optional 'aud' and 'iss' claims, e.g. 'jwt,jwks-url=https://idp/keys,aud=etcd,iss=https://idp,username-claim=sub'.
+    The 'oidc' ID tokens presented by the clients are verified against the 'issuer' and 'client-id', and authenticate as the etcd user of their 'username-claim' prefixed with 'username-prefix' (the issuer followed by '#' by default, '-' for none) but never as root, holding the roles mapped from their groups by 'group-roles', e.g. 'oidc,issuer=https://idp,client-id=etcd,username-prefix=oidc:,group-roles=admins:root;devs:dev'.
   --bcrypt-cost ` + fmt.Sprintf("%d", bcrypt.DefaultCost) + `
     Specify the cost / strength of the bcrypt algorithm for hashing auth passwords. Valid values are between ` + fmt.Sprintf("%d", bcrypt.MinCost) + ` and ` + fmt.Sprintf("%d", bcrypt.MaxCost) + `.
   --auth-token-ttl 300
     Time (in seconds) of the auth-token-ttl.
+  --experimental-client-cert-san-role-rules ''
+    Comma-separated list of '<uri|dns>:<pattern>=<role>' rules granting the role to the clients authenticated by a certificate with a matching SAN, a '*' matching a segment of a URI path or a label of a DNS name, e.g. 'uri:spiffe://example.org/ns/prod/sa/*=prod-reader'. The clients are authenticated as the users named after their first matching SAN, like 'uri:spiffe://example.org/ns/prod/sa/api', unless the common name of their certificate is a user. Must be the same on all members. Requires --feature-gates=ClientCertSANRoles=true.
+  --experimental-password-min-length 0
+    Minimum number of characters of the passwords of the users added or changed on this member. 0 means no minimum. Requires --feature-gates=PasswordPolicy=true.
+  --experimental-password-min-char-classes 0
+    Minimum number of the classes of characters (lowercase letters, uppercase letters, digits and others) of the passwords of the users added or changed on this member, up to 4. 0 means no minimum. Requires --feature-gates=PasswordPolicy=true.
 
 Profiling and Monitoring:
   --enable-pprof 'false'
@@ -236,45 +278,195 @@ Logging:
   --warning-unary-request-duration '300ms'
     Set time duration after which a warning is logged if a unary request takes more than this duration.
 
-Experimental distributed tracing:
+Distributed tracing:
+  --enable-distributed-tracing 'false'
+    Enable distributed tracing using OpenTelemetry Tracing.
+  --distributed-tracing-address 'localhost:4317'
+    Address for distributed tracing used for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag).
+  --distributed-tracing-service-name 'etcd'
+    Configures service name for distributed tracing to be used to define service name for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag). 'etcd' is the default service name. Use the same service name for all instances of etcd.
+  --distributed-tracing-instance-id ''
+    Configures service instance ID for distributed tracing to be used to define service instance ID key for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag). There is no default value set. This ID must be unique per etcd instance.
+  --distributed-tracing-sampling-rate '0'
+    Number of samples to collect per million spans for OpenTelemetry Tracing (if enabled with enable-distributed-tracing flag).
   --experimental-enable-distributed-tracing 'false'
-    Enable experimental distributed tracing.
+    It's deprecated, and will be decommissioned in v3.7. Use --enable-distributed-tracing instead.
   --experimental-distributed-tracing-address 'localhost:4317'
-    Distributed tracing collector address.
+    It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-address instead.
   --experimental-distributed-tracing-service-name 'etcd'
-    Distributed tracing service name, must be same across all etcd instances.
+    It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-service-name instead.
   --experimental-distributed-tracing-instance-id ''
-    Distributed tracing instance ID, must be unique per each etcd instance.
+    It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-instance-id instead.
   --experimental-distributed-tracing-sampling-rate '0'
-    Number of samples to collect per million spans for distributed tracing. Disabled by default.
+    It's deprecated, and will be decommissioned in v3.7. Use --distributed-tracing-sampling-rate instead.
+
+Feature gates:
+  --feature-gates ''
+    Comma-separated list of 'Feature=true|false' pairs turning the alpha and beta features on and off, e.g. 'InitialCorruptCheck=true,LeaseCheckpoint=true'. The --experimental-* flags turning the features on and off are deprecated, keep setting them and conflict with a different value of their feature gate. The --experimental-* flags tuning the features require their feature gate. Features are:
+      AuditLog=true|false (ALPHA - default=false)
+      Authz=true|false (ALPHA - default=false)
+      AutoDefrag=true|false (ALPHA - default=false)
+      BackendEngine=true|false (ALPHA - default=false)
+      BackendQuotaGrowth=true|false (ALPHA - default=false)
+      BoundedStalenessReads=true|false (BETA - default=true)
+      ChangeFeeds=true|false (ALPHA - default=false)
+      ClientCertSANRoles=true|false (ALPHA - default=false)
+      ClientListenerGroups=true|false (ALPHA - default=false)
+      CompactHashCheck=true|false (ALPHA - default=false)
+      CompactionPacing=true|false (BETA - default=true)
+      CompactionRevisionAlignment=true|false (ALPHA - default=false)
+      ConnectionLimits=true|false (ALPHA - default=false)
+      CorruptCheckSchedule=true|false (ALPHA - default=false)
+      GracefulDrain=true|false (ALPHA - default=false)
+      HotKeyTracking=true|false (ALPHA - default=false)
+      IncrementalDefrag=true|false (ALPHA - default=false)
+      InitialCorruptCheck=true|false (ALPHA - default=false)
+      LearnerAutoPromote=true|false (ALPHA - default=false)
+      LeaseCheckpoint=true|false (ALPHA - default=false)
+      LeaseCheckpointOnRenew=true|false (ALPHA - default=false)
+      LeaseCheckpointPersist=true|false (ALPHA - default=false)
+      LeaseKeyLimit=true|false (ALPHA - default=false)
+      PasswordPolicy=true|false (ALPHA - default=false)
+      PrefixStats=true|false (ALPHA - default=false)
+      SampledCorruptCheck=true|false (ALPHA - default=false)
+      SecondaryIndexes=true|false (ALPHA - default=false)
+      SnapshotHTTP=true|false (ALPHA - default=false)
+      SocketActivation=true|false (ALPHA - default=false)
+      TxnModeWriteWithSharedBuffer=true|false (BETA - default=true)
+      ValueChunking=true|false (ALPHA - default=false)
+      ValueCompression=true|false (ALPHA - default=false)
+      VersionRetention=true|false (ALPHA - default=false)
+      WatchBacklogAlarm=true|false (ALPHA - default=false)
+      WatchRateLimit=true|false (ALPHA - default=false)
+      WatchStartRevisionLimit=true|false (ALPHA - default=false)
 
 Experimental feature:
   --experimental-initial-corrupt-check 'false'
-    Enable to check data corruption before serving any client/peer traffic.
+    Enable to check data corruption before serving any client/peer traffic. It's deprecated, use --feature-gates=InitialCorruptCheck=true|false instead.
   --experimental-corrupt-check-time '0s'
-    Duration of time between cluster corruption check passes.
+    It's deprecated, and will be decommissioned in v3.7. Use --corrupt-check-time instead.
+  --experimental-corrupt-check-schedule ''
+    Cron schedule of the cluster corruption check passes, overriding --corrupt-check-time. Requires --feature-gates=CorruptCheckSchedule=true.
+  --experimental-corrupt-check-scope 'full'
+    Scope of the cluster corruption check passes, 'full' to compare the hashes of the whole key spaces or 'sampled' to compare the hashes of windows of revisions. Requires --feature-gates=SampledCorruptCheck=true.
+  --experimental-corrupt-check-samples 8
+    Number of windows of revisions compared by the sampled cluster corruption check passes. Requires --feature-gates=SampledCorruptCheck=true.
   --experimental-enable-lease-checkpoint 'false'
-    ExperimentalEnableLeaseCheckpoint enables primary lessor to persist lease remainingTTL to prevent indefinite auto-renewal of long lived leases.
+    ExperimentalEnableLeaseCheckpoint enables primary lessor to persist lease remainingTTL to prevent indefinite auto-renewal of long lived leases. It's deprecated, use --feature-gates=LeaseCheckpoint=true|false instead.
+  --experimental-lease-checkpoint-interval '0s'
+    Duration between the lease checkpoints, 0 meaning the default of 5m. Requires experimental-enable-lease-checkpoint to be enabled. Requires --feature-gates=LeaseCheckpoint=true.
+  --experimental-lease-checkpoint-on-renew 'false'
+    Enable leader to checkpoint the remaining TTL of a lease on every renewal, at the cost of a raft entry per renewal. Requires experimental-enable-lease-checkpoint to be enabled. It's deprecated, use --feature-gates=LeaseCheckpointOnRenew=true|false instead.
+  --experimental-max-lease-attached-keys '0'
+    Maximum number of keys attached to a single lease. Puts attaching more keys are rejected by the member serving them before they are proposed, and a LEASEKEYS warning alarm is raised once a lease reaches 90% of it. Zero means unlimited. Requires --feature-gates=LeaseKeyLimit=true.
   --experimental-compaction-batch-limit 1000
-    ExperimentalCompactionBatchLimit sets the maximum revisions deleted in each compaction batch.
+    It's deprecated, and will be decommissioned in v3.7. Use --compaction-batch-limit instead.
+  --experimental-compaction-batch-target-duration '50ms'
+    Resizes the compaction batches for the time each holds the backend to approach this duration, and spaces them out by at least as long as they took. 0 means disabled. Requires --feature-gates=CompactionPacing=true.
+  --experimental-compaction-min-batch-limit 100
+    ExperimentalCompactionMinBatchLimit sets the minimum revisions deleted in each paced compaction batch. Requires --feature-gates=CompactionPacing=true.
+  --experimental-compaction-revision-alignment '0'
+    Rounds the auto compaction revisions down to a multiple of this value, so that all members compact at the same revision boundaries. 0 means disabled. Requires --feature-gates=CompactionRevisionAlignment=true.
+  --experimental-max-quota-backend-bytes '0'
+    Grows the backend quota, raising a QUOTAGROWN alarm, when the backend size in use approaches it, up to this hard cap. 0 means disabled. Requires --feature-gates=BackendQuotaGrowth=true.
   --experimental-peer-skip-client-san-verification 'false'
-    Skip verification of SAN field in client certificate for peer connections.
+    It's deprecated, and will be decommissioned in v3.7. Use --peer-skip-client-san-verification instead.
   --experimental-watch-progress-notify-interval '10m'
-    Duration of periodical watch progress notification.
+    It's deprecated, and will be decommissioned in v3.7. Use --watch-progress-notify-interval instead.
+  --experimental-watch-max-start-revision-lag '0'
+    Maximum number of revisions a watch start revision can be behind the current revision, watch creations exceeding it are rejected. Zero means no limit. Requires --feature-gates=WatchStartRevisionLimit=true.
+  --experimental-watch-max-start-revision-age '0s'
+    Maximum time a watch start revision can be behind the current revision, watch creations replaying older events are rejected. Zero means no limit. Requires --feature-gates=WatchStartRevisionLimit=true.
+  --experimental-watch-max-events-per-second '0'
+    Maximum rate each watcher is sent events at, the events waiting for the rate limit being queued. Zero means no limit. Requires --feature-gates=WatchRateLimit=true.
+  --experimental-watch-max-queued-events '1000'
+    Maximum number of events queued for each watcher waiting for the delivery rate limit before the overflow policy applies. Requires --feature-gates=WatchRateLimit=true.
+  --experimental-watch-overflow-policy 'block'
+    Policy for the watchers exceeding the maximum number of queued events: 'drop-oldest' drops their oldest events, counted in their next response, 'cancel' cancels them, 'block' blocks their watch stream until their queue drains. Requires --feature-gates=WatchRateLimit=true.
+  --experimental-watch-backlog-alarm-events '0'
+    Number of events held in memory for the slow watchers raising a WATCHBACKLOG warning alarm, cleared once the backlog halves. Zero means disabled. Requires --feature-gates=WatchBacklogAlarm=true.
+  --experimental-drain-timeout '0s'
+    Time to drain the client requests for on SIGTERM or SIGINT before stopping: the new streams are rejected, the leadership is transferred, and the requests in flight and the buffered watch events are waited for. Also the deadline of the drains requested through the maintenance API without one. Zero means stopping without draining on the signals. Requires --feature-gates=GracefulDrain=true.
+  --experimental-bounded-staleness-max-lag '1000'
+    Maximum number of entries the applied index can lag the commit index learned from the leader for bounded staleness reads to be served locally. Bounded staleness reads are linearizable when the member lags more. Requires --feature-gates=BoundedStalenessReads=true.
+  --experimental-change-feed-webhook-url ''
+    URL to post the committed events of --experimental-change-feed-prefixes to as JSON, while the member is the leader. Events are delivered at least once, from checkpoints kept in the reserved keys under "\x00changefeed/". Requires --feature-gates=ChangeFeeds=true.
+  --experimental-change-feed-prefixes ''
+    Comma-separated list of key prefixes whose events are posted to --experimental-change-feed-webhook-url (empty means the whole key space). Requires --feature-gates=ChangeFeeds=true.
+  --experimental-version-retention ''
+    Comma-separated list of '<prefix>=<versions>' retentions keeping the last versions of every key with the prefix through the compactions, or '<versions>' for the whole key space. Must be the same on all the members, a member joining or restarting with another one refuses to start. Requires --feature-gates=VersionRetention=true.
+  --experimental-secondary-indexes ''
+    Comma-separated list of '<prefix>=<field>' secondary indexes maintained on the JSON values of the keys with the prefix, looked up with the IndexRange RPC. Requires --feature-gates=SecondaryIndexes=true.
+  --experimental-hot-key-tracking 'false'
+    Estimate the read, write and watch traffic per key prefix, reported by the HotKeys RPC and metrics. It's deprecated, use --feature-gates=HotKeyTracking=true|false instead.
+  --experimental-prefix-stats 'false'
+    Keep the number of keys, their size and the number of watchers per key prefix, reported by the PrefixStats RPC. It's deprecated, use --feature-gates=PrefixStats=true|false instead.
+  --experimental-value-compression 'none'
+    Compression of the stored values of at least experimental-value-compression-threshold bytes ('none', 'deflate', 'snappy' or 'zstd'), once the cluster version is at least 3.6. Requires --feature-gates=ValueCompression=true.
+  --experimental-value-compression-threshold 1024
+    Minimum size in bytes of the values compressed by experimental-value-compression. Requires --feature-gates=ValueCompression=true.
+  --experimental-max-chunked-value-bytes 0
+    Maximum size of the values put in parts of at most max-request-bytes, stored split into chunks, once all the members enable the ValueChunking feature gate. Requires --feature-gates=ValueChunking=true. 0 disables it.
+  --experimental-audit-log-path ''
+    Path of the file the audit log records the requests of the clients to, as JSON entries holding their authenticated user, method, key ranges, result code and latency. Empty disables the audit log. Requires --feature-gates=AuditLog=true.
+  --experimental-audit-log-rotation-config-json '{"maxsize": 100, "maxage": 0, "maxbackups": 0, "localtime": false, "compress": false}'
+    Configures the rotation of the audit log file with a JSON logger config, like --log-rotation-config-json. Requires --feature-gates=AuditLog=true.
+  --experimental-audit-log-include-prefixes ''
+    Comma-separated list of key prefixes limiting the audit log to the requests touching their keys (empty means the whole key space). The requests on no keys are always recorded. Requires --feature-gates=AuditLog=true.
+  --experimental-audit-log-exclude-prefixes ''
+    Comma-separated list of key prefixes leaving the requests touching only their keys out of the audit log. Requires --feature-gates=AuditLog=true.
+  --experimental-authz-webhook-url ''
+    URL of an external authorizer consulted on the requests on keys, the watches, the lease keep alives and the snapshots of the users permitted by their roles, before they are proposed, for the final decision. The requests are posted as JSON objects holding their user, method and base64 encoded key ranges, like '{"user": "alice", "method": "/etcdserverpb.KV/Put", "keys": [{"key": "Zm9v"}]}', to which the authorizer replies with '{"allowed": true}' or '{"allowed": false, "reason": "..."}'. Empty disables the external authorizer. Requires --feature-gates=Authz=true.
+  --experimental-authz-webhook-timeout '1s'
+    Time the external authorizer takes at most to decide on a request. Requires --feature-gates=Authz=true.
+  --experimental-authz-webhook-trusted-ca-file ''
+    Path to the CA file verifying the certificate of an HTTPS authorizer webhook, instead of the system CAs. Requires --feature-gates=Authz=true.
+  --experimental-authz-webhook-cert-file ''
+    Path to the client certificate authenticating the member to the authorizer webhook. Requires --feature-gates=Authz=true.
+  --experimental-authz-webhook-key-file ''
+    Path to the client key authenticating the member to the authorizer webhook. Requires --feature-gates=Authz=true.
+  --experimental-authz-cache-ttl '10s'
+    Time the decisions of the external authorizer are cached for, by user, method and key ranges. 0 disables the cache. Requires --feature-gates=Authz=true.
+  --experimental-authz-fail-open 'false'
+    Allow the requests the external authorizer fails to decide on, which are denied otherwise. Requires --feature-gates=Authz=true.
+  --experimental-enable-snapshot-http 'false'
+    Serve consistent backend snapshots on the '/snapshot' path of the client URLs, with range requests to resume downloads. When auth is enabled, requires the credentials of a user with the root role. It's deprecated, use --feature-gates=SnapshotHTTP=true|false instead.
   --experimental-warning-apply-duration '100ms'
-    Warning is generated if requests take more than this duration.
+    It's deprecated, and will be decommissioned in v3.7. Use --warning-apply-duration instead.
   --experimental-txn-mode-write-with-shared-buffer 'true'
-    Enable the write transaction to use a shared buffer in its readonly check operations.
+    Enable the write transaction to use a shared buffer in its readonly check operations. It's deprecated, use --feature-gates=TxnModeWriteWithSharedBuffer=true|false instead.
   --experimental-bootstrap-defrag-threshold-megabytes
-    Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.
+    It's deprecated, and will be decommissioned in v3.7. Use --bootstrap-defrag-threshold-megabytes instead.
+  --experimental-incremental-defrag 'false'
+    Defragment the backend in small batches, serving requests between them, with a short final cutover. It's deprecated, use --feature-gates=IncrementalDefrag=true|false instead.
+  --experimental-auto-defrag-threshold '0'
+    Defragment the backend once the ratio of its bytes not in use to its size exceeds this threshold, checked every minute. 0 means disabled. Requires --feature-gates=AutoDefrag=true.
+  --experimental-auto-defrag-schedule ''
+    Cron schedule of the off-peak minutes the backend may be defragmented automatically in, e.g. '* 1-4 * * *' for between 1AM and 5AM. Any time if empty. Requires --feature-gates=AutoDefrag=true.
+  --experimental-backend-engine 'bbolt'
+    Storage engine of the backend ('bbolt' or 'log'). The log engine appends the changes to a log compacted by defragmentation instead of rewriting bbolt pages, and holds the keys in memory. Each engine converts the backend of the other on start, so switching back is supported; snapshots are in the format of the engine, and the offline etcdutl commands support both. Requires --feature-gates=BackendEngine=true.
   --experimental-warning-unary-request-duration '300ms'
-    Set time duration after which a warning is generated if a unary request takes more than this duration. It's deprecated, and will be decommissioned in v3.7. Use --warning-unary-request-duration instead.
+    It's deprecated, and will be decommissioned in v3.7. Use --warning-unary-request-duration instead.
   --experimental-max-learners '1'
-    Set the max number of learner members allowed in the cluster membership.
+    It's deprecated, and will be decommissioned in v3.7. Use --max-learners instead.
+  --experimental-learner-auto-promote-lag '1000'
+    Maximum number of raft entries a learner may lag behind the leader by to be promoted automatically. Requires --feature-gates=LearnerAutoPromote=true.
+  --experimental-learner-auto-promote-duration '0s'
+    Time a learner has to stay within --experimental-learner-auto-promote-lag for to be promoted automatically by the leader, recorded in the server log and the audit log. The observers are never promoted. 0 disables the automatic promotion. Requires --feature-gates=LearnerAutoPromote=true.
   --experimental-wait-cluster-ready-timeout '5s'
-    Set the maximum time duration to wait for the cluster to be ready.
-  --experimental-snapshot-catch-up-entries '5000'
-    Number of entries for a slow follower to catch up after compacting the the raft storage entries.
+    It's deprecated, and will be decommissioned in v3.7. Use --wait-cluster-ready-timeout instead.
+  --experimental-snapshot-catchup-entries '5000'
+    It's deprecated, and will be decommissioned in v3.7. Use --snapshot-catchup-entries instead.
+  --experimental-socket-activation 'false'
+    Serve the listening sockets passed by systemd (LISTEN_FDS) on the listen client and peer URLs they are bound to, rather than binding them. The URLs without a passed socket are bound as usual. It's deprecated, use --feature-gates=SocketActivation=true|false instead.
+  --experimental-client-max-connections '0'
+    Maximum number of concurrent connections of each client listener, beyond which the new connections are rejected and counted by the etcd_network_listener_rejected_connections_total metric. 0 means no limit other than the file descriptor limit. Requires --feature-gates=ConnectionLimits=true.
+  --experimental-client-accept-rate '0'
+    Maximum number of connections accepted per second by each client listener, with a burst of one second of them, beyond which the new connections are rejected. Protects the member from a large client fleet reconnecting at once. 0 means no limit. Requires --feature-gates=ConnectionLimits=true.
+  --experimental-peer-max-connections '0'
+    Maximum number of concurrent connections of each peer listener, beyond which the new connections are rejected. 0 means no limit. Requires --feature-gates=ConnectionLimits=true.
+  --experimental-peer-accept-rate '0'
+    Maximum number of connections accepted per second by each peer listener, beyond which the new connections are rejected. 0 means no limit. Requires --feature-gates=ConnectionLimits=true.
 
 Unsafe feature:
   --force-new-cluster 'false'
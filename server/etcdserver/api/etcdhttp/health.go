@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -28,6 +29,7 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
+	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/raft/v3"
 )
 
@@ -37,6 +39,7 @@ const (
 )
 
 type ServerHealth interface {
+	Lifecycle() etcdserver.LifecycleTransition
 	Alarms() []*pb.AlarmMember
 	Leader() types.ID
 	Range(context.Context, *pb.RangeRequest) (*pb.RangeResponse, error)
@@ -47,6 +50,9 @@ type ServerHealth interface {
 // and its corresponding timeout.
 func HandleHealth(lg *zap.Logger, mux *http.ServeMux, srv ServerHealth) {
 	mux.Handle(PathHealth, NewHealthHandler(lg, func(excludedAlarms AlarmSet, serializable bool) Health {
+		if h := checkLifecycle(lg, srv, serializable); h.Health != "true" {
+			return h
+		}
 		if h := checkAlarms(lg, srv, excludedAlarms); h.Health != "true" {
 			return h
 		}
@@ -151,6 +157,11 @@ func checkAlarms(lg *zap.Logger, srv ServerHealth, excludedAlarms AlarmSet) Heal
 				lg.Debug("/health excluded alarm", zap.String("alarm", v.String()))
 				continue
 			}
+			if v.Alarm == etcdserverpb.AlarmType_QUOTAGROWN || v.Alarm == etcdserverpb.AlarmType_WATCHBACKLOG || v.Alarm == etcdserverpb.AlarmType_LEASEKEYS {
+				// a warning, the member still accepts writes
+				lg.Debug("/health ignored warning alarm", zap.String("alarm", v.String()))
+				continue
+			}
 
 			h.Health = "false"
 			switch v.Alarm {
@@ -169,6 +180,23 @@ func checkAlarms(lg *zap.Logger, srv ServerHealth, excludedAlarms AlarmSet) Heal
 	return h
 }
 
+// checkLifecycle tells apart the members not serving yet, e.g. replaying
+// their committed entries or not reaching their peers, and the stopping ones.
+// Only the stopping members fail the serializable checks of the liveness.
+func checkLifecycle(lg *zap.Logger, srv ServerHealth, serializable bool) Health {
+	h := Health{Health: "true"}
+	lc := srv.Lifecycle()
+	switch {
+	case lc.To == etcdserver.LifecyclePublished || lc.To == etcdserver.LifecycleServing:
+	case serializable && lc.To < etcdserver.LifecycleDraining:
+	default:
+		h.Health = "false"
+		h.Reason = fmt.Sprintf("LIFECYCLE %s: %s", strings.ToUpper(lc.To.String()), lc.Reason)
+		lg.Warn("serving /health false; server not serving", zap.Stringer("lifecycle-state", lc.To), zap.String("reason", lc.Reason))
+	}
+	return h
+}
+
 func checkLeader(lg *zap.Logger, srv ServerHealth, serializable bool) Health {
 	h := Health{Health: "true"}
 	if !serializable && (uint64(srv.Leader()) == raft.None) {
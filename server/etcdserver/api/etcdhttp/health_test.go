@@ -42,8 +42,13 @@ func (s *fakeStats) StoreStats() []byte  { return nil }
 
 type fakeHealthServer struct {
 	fakeServer
-	health   string
-	apiError error
+	health    string
+	apiError  error
+	lifecycle etcdserver.LifecycleState
+}
+
+func (s *fakeHealthServer) Lifecycle() etcdserver.LifecycleTransition {
+	return etcdserver.LifecycleTransition{To: s.lifecycle}
 }
 
 func (s *fakeHealthServer) Range(ctx context.Context, request *pb.RangeRequest) (*pb.RangeResponse, error) {
@@ -76,6 +81,7 @@ func TestHealthHandler(t *testing.T) {
 		alarms         []*pb.AlarmMember
 		healthCheckURL string
 		apiError       error
+		lifecycle      etcdserver.LifecycleState
 
 		expectStatusCode int
 		expectHealth     string
@@ -150,15 +156,42 @@ func TestHealthHandler(t *testing.T) {
 			expectStatusCode: http.StatusServiceUnavailable,
 			expectHealth:     "false",
 		},
+		{
+			name:             "Unhealthy if not published yet",
+			healthCheckURL:   "/health",
+			lifecycle:        etcdserver.LifecycleJoinedRaft,
+			expectStatusCode: http.StatusServiceUnavailable,
+			expectHealth:     "false",
+		},
+		{
+			name:             "Healthy if not published yet and serializable",
+			healthCheckURL:   "/health?serializable=true",
+			lifecycle:        etcdserver.LifecycleJoinedRaft,
+			expectStatusCode: http.StatusOK,
+			expectHealth:     "true",
+		},
+		{
+			name:             "Unhealthy if draining and serializable",
+			healthCheckURL:   "/health?serializable=true",
+			lifecycle:        etcdserver.LifecycleDraining,
+			expectStatusCode: http.StatusServiceUnavailable,
+			expectHealth:     "false",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			lifecycle := tt.lifecycle
+			if lifecycle == etcdserver.LifecycleStarting {
+				// serving unless set
+				lifecycle = etcdserver.LifecycleServing
+			}
 			mux := http.NewServeMux()
 			HandleHealth(zaptest.NewLogger(t), mux, &fakeHealthServer{
 				fakeServer: fakeServer{alarms: tt.alarms},
 				health:     tt.expectHealth,
 				apiError:   tt.apiError,
+				lifecycle:  lifecycle,
 			})
 			ts := httptest.NewServer(mux)
 			defer ts.Close()
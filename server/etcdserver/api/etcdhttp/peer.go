@@ -71,6 +71,7 @@ func newPeerHandler(
 	}
 	if hashKVHandler != nil {
 		mux.Handle(etcdserver.PeerHashKVPath, hashKVHandler)
+		mux.Handle(etcdserver.PeerHashRevisionsPath, hashKVHandler)
 	}
 	mux.HandleFunc(versionPath, versionHandler(s, serveVersion))
 	return mux
@@ -141,7 +142,7 @@ func (h *peerMemberPromoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		switch err {
 		case membership.ErrIDNotFound:
 			http.Error(w, err.Error(), http.StatusNotFound)
-		case membership.ErrMemberNotLearner:
+		case membership.ErrMemberNotLearner, membership.ErrMemberObserver:
 			http.Error(w, err.Error(), http.StatusPreconditionFailed)
 		case errors.ErrLearnerNotReady:
 			http.Error(w, err.Error(), http.StatusPreconditionFailed)
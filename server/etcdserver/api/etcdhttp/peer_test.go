@@ -74,8 +74,13 @@ func (s *fakeServer) UpdateMember(ctx context.Context, updateMemb membership.Mem
 func (s *fakeServer) PromoteMember(ctx context.Context, id uint64) ([]*membership.Member, error) {
 	return nil, fmt.Errorf("PromoteMember not implemented in fakeServer")
 }
+func (s *fakeServer) ReplaceMember(ctx context.Context, id uint64, memb membership.Member) ([]*membership.Member, error) {
+	return nil, fmt.Errorf("ReplaceMember not implemented in fakeServer")
+}
 func (s *fakeServer) ClusterVersion() *semver.Version      { return nil }
 func (s *fakeServer) StorageVersion() *semver.Version      { return nil }
+func (s *fakeServer) FeatureGates() map[string]bool        { return nil }
+func (s *fakeServer) VersionRetention() []string           { return nil }
 func (s *fakeServer) Cluster() api.Cluster                 { return s.cluster }
 func (s *fakeServer) Alarms() []*pb.AlarmMember            { return s.alarms }
 func (s *fakeServer) LeaderChangedNotify() <-chan struct{} { return nil }
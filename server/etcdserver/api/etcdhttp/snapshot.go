@@ -0,0 +1,244 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdhttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+const (
+	PathSnapshot = "/snapshot"
+
+	// SnapshotSHA256Header is the header giving the sha256 digest of the
+	// snapshot served, which is also its ETag.
+	SnapshotSHA256Header = "X-Etcd-Snapshot-Sha256"
+	// SnapshotStorageVersionHeader is the header giving the storage version
+	// of the snapshot served.
+	SnapshotStorageVersionHeader = "X-Etcd-Storage-Version"
+)
+
+// snapshotResumeWindow is how long the last snapshot taken is kept to serve
+// the range requests resuming its download.
+var snapshotResumeWindow = 10 * time.Minute
+
+// SnapshotServer is the part of the server serving snapshots over HTTP.
+type SnapshotServer interface {
+	Backend() backend.Backend
+	AuthStore() auth.AuthStore
+}
+
+// HandleSnapshot registers a handler on '/snapshot' serving a consistent
+// snapshot of the backend, as the Maintenance.Snapshot RPC does, to clients
+// that cannot speak gRPC. When auth is enabled, the client must authenticate
+// as a user with the root role, with basic auth or with a token in the
+// Authorization header.
+//
+// A request without a Range header takes a new snapshot. Range requests are
+// served from the last snapshot taken, kept for a while in dir, so that
+// downloads can be resumed with the Range and If-Range headers; if the
+// snapshot given by If-Range is gone, the new snapshot is served in full.
+func HandleSnapshot(lg *zap.Logger, mux *http.ServeMux, srv SnapshotServer, dir string) error {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	// snapshots left by a previous run cannot be resumed
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := fileutil.TouchDirAll(lg, dir); err != nil {
+		return err
+	}
+	mux.Handle(PathSnapshot, &snapshotHandler{lg: lg, srv: srv, dir: dir})
+	return nil
+}
+
+type snapshotHandler struct {
+	lg  *zap.Logger
+	srv SnapshotServer
+	dir string
+
+	mu   sync.Mutex
+	last *snapshotFile
+}
+
+type snapshotFile struct {
+	path           string
+	sha256         string
+	storageVersion string
+	created        time.Time
+}
+
+func (h *snapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if code, err := h.authorize(r); err != nil {
+		if code == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="etcd"`)
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	snap, f, err := h.snapshot(r.Header.Get("Range") != "")
+	if err != nil {
+		h.lg.Warn("failed to take snapshot", zap.String("remote-addr", r.RemoteAddr), zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="snapshot.db"`)
+	w.Header().Set("ETag", `"`+snap.sha256+`"`)
+	w.Header().Set(SnapshotSHA256Header, snap.sha256)
+	if snap.storageVersion != "" {
+		w.Header().Set(SnapshotStorageVersionHeader, snap.storageVersion)
+	}
+	h.lg.Info("sending database snapshot to HTTP client",
+		zap.String("remote-addr", r.RemoteAddr),
+		zap.String("range", r.Header.Get("Range")),
+		zap.String("sha256", snap.sha256),
+	)
+	http.ServeContent(w, r, "", snap.created, f)
+}
+
+// authorize returns the status code to reply with if the client is not
+// permitted to take snapshots.
+func (h *snapshotHandler) authorize(r *http.Request) (int, error) {
+	as := h.srv.AuthStore()
+	if !as.IsAuthEnabled() {
+		return http.StatusOK, nil
+	}
+	// the snapshots are only taken by the users of passwords and tokens
+	if !etcdserver.ClientAuthModesFromContext(r.Context()).Token {
+		return http.StatusForbidden, auth.ErrPermissionDenied
+	}
+	var authInfo *auth.AuthInfo
+	if username, password, ok := r.BasicAuth(); ok {
+		rev, err := as.CheckPassword(username, password)
+		if err != nil {
+			return http.StatusUnauthorized, err
+		}
+		authInfo = &auth.AuthInfo{Username: username, Revision: rev}
+	} else if token := r.Header.Get("Authorization"); token != "" {
+		// tokens are given as by the gRPC gateway, optionally with a Bearer scheme
+		token = strings.TrimPrefix(token, "Bearer ")
+		ctx := metadata.NewIncomingContext(r.Context(), metadata.Pairs(rpctypes.TokenFieldNameGRPC, token))
+		ai, err := as.AuthInfoFromCtx(ctx)
+		if err != nil {
+			return http.StatusUnauthorized, err
+		}
+		authInfo = ai
+	}
+	if authInfo == nil {
+		return http.StatusUnauthorized, auth.ErrUserEmpty
+	}
+	if err := as.IsAdminPermitted(authInfo); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+// snapshot returns the last snapshot taken if reuse is set and it is still
+// within the resume window, a new snapshot otherwise, and opens its file.
+func (h *snapshotHandler) snapshot(reuse bool) (*snapshotFile, *os.File, error) {
+	if reuse {
+		h.mu.Lock()
+		snap := h.last
+		h.mu.Unlock()
+		if snap != nil && time.Since(snap.created) < snapshotResumeWindow {
+			// the file may have been replaced since; it is then taken again
+			if f, err := os.Open(snap.path); err == nil {
+				return snap, f, nil
+			}
+		}
+	}
+
+	snap, err := h.takeSnapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(snap.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h.mu.Lock()
+	prev := h.last
+	h.last = snap
+	h.mu.Unlock()
+	// downloads in progress keep reading the files removed
+	if prev != nil {
+		os.Remove(prev.path)
+	}
+	time.AfterFunc(snapshotResumeWindow, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.last == snap {
+			h.last = nil
+			os.Remove(snap.path)
+		}
+	})
+	return snap, f, nil
+}
+
+func (h *snapshotHandler) takeSnapshot() (*snapshotFile, error) {
+	be := h.srv.Backend()
+	snap := &snapshotFile{created: time.Now().UTC().Truncate(time.Second)}
+	if ver := schema.ReadStorageVersion(be.ReadTx()); ver != nil {
+		snap.storageVersion = ver.String()
+	}
+
+	f, err := os.CreateTemp(h.dir, "snapshot-*.db")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s := be.Snapshot()
+	defer s.Close()
+	sha := sha256.New()
+	if _, err := s.WriteTo(io.MultiWriter(f, sha)); err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	snap.path = f.Name()
+	snap.sha256 = hex.EncodeToString(sha.Sum(nil))
+	return snap, nil
+}
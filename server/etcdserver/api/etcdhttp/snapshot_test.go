@@ -0,0 +1,200 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdhttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/metadata"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+type fakeSnapshotAuthStore struct {
+	auth.AuthStore
+	enabled bool
+}
+
+func (as *fakeSnapshotAuthStore) IsAuthEnabled() bool { return as.enabled }
+
+func (as *fakeSnapshotAuthStore) CheckPassword(username, password string) (uint64, error) {
+	if password != username+"-password" {
+		return 0, auth.ErrAuthFailed
+	}
+	return 1, nil
+}
+
+func (as *fakeSnapshotAuthStore) AuthInfoFromCtx(ctx context.Context) (*auth.AuthInfo, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ts := md[rpctypes.TokenFieldNameGRPC]
+	if len(ts) == 0 || ts[0] != "root-token" {
+		return nil, auth.ErrInvalidAuthToken
+	}
+	return &auth.AuthInfo{Username: "root", Revision: 1}, nil
+}
+
+func (as *fakeSnapshotAuthStore) IsAdminPermitted(authInfo *auth.AuthInfo) error {
+	if authInfo.Username != "root" {
+		return auth.ErrPermissionDenied
+	}
+	return nil
+}
+
+type fakeSnapshotServer struct {
+	be backend.Backend
+	as *fakeSnapshotAuthStore
+}
+
+func (s *fakeSnapshotServer) Backend() backend.Backend  { return s.be }
+func (s *fakeSnapshotServer) AuthStore() auth.AuthStore { return s.as }
+
+func newSnapshotTestServer(t *testing.T, authEnabled bool) (*fakeSnapshotServer, *httptest.Server) {
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	t.Cleanup(func() { betesting.Close(t, be) })
+	putTestKey(be, "foo")
+	srv := &fakeSnapshotServer{be: be, as: &fakeSnapshotAuthStore{enabled: authEnabled}}
+
+	mux := http.NewServeMux()
+	require.NoError(t, HandleSnapshot(zaptest.NewLogger(t), mux, srv, filepath.Join(t.TempDir(), "http-snapshot")))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+func putTestKey(be backend.Backend, key string) {
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafePut(schema.Test, []byte(key), []byte("bar"))
+	tx.Unlock()
+	be.ForceCommit()
+}
+
+func getSnapshot(t *testing.T, req *http.Request) (*http.Response, []byte) {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestSnapshotHandlerResume(t *testing.T) {
+	srv, ts := newSnapshotTestServer(t, false)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+PathSnapshot, nil)
+	require.NoError(t, err)
+	resp, full := getSnapshot(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sha := sha256.Sum256(full)
+	assert.Equal(t, hex.EncodeToString(sha[:]), resp.Header.Get(SnapshotSHA256Header))
+	etag := resp.Header.Get("ETag")
+
+	// the download resumes from the same snapshot, even though the backend changed
+	putTestKey(srv.be, "baz")
+	req.Header.Set("Range", "bytes=100-")
+	req.Header.Set("If-Range", etag)
+	resp, part := getSnapshot(t, req)
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, full[100:], part)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+
+	// a new download takes a new snapshot
+	req.Header.Del("Range")
+	req.Header.Del("If-Range")
+	resp, _ = getSnapshot(t, req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+
+	// and the old snapshot cannot be resumed anymore
+	req.Header.Set("Range", "bytes=100-")
+	req.Header.Set("If-Range", etag)
+	resp, _ = getSnapshot(t, req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodPost, ts.URL+PathSnapshot, nil)
+	require.NoError(t, err)
+	resp, _ = getSnapshot(t, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestSnapshotHandlerAuth(t *testing.T) {
+	_, ts := newSnapshotTestServer(t, true)
+
+	tests := []struct {
+		name       string
+		setAuth    func(r *http.Request)
+		wantStatus int
+	}{
+		{
+			name:       "no credentials",
+			setAuth:    func(r *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong password",
+			setAuth:    func(r *http.Request) { r.SetBasicAuth("root", "bad") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			setAuth:    func(r *http.Request) { r.Header.Set("Authorization", "bad-token") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not root",
+			setAuth:    func(r *http.Request) { r.SetBasicAuth("user", "user-password") },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "root password",
+			setAuth:    func(r *http.Request) { r.SetBasicAuth("root", "root-password") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "root token",
+			setAuth:    func(r *http.Request) { r.Header.Set("Authorization", "root-token") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "root bearer token",
+			setAuth:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer root-token") },
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+PathSnapshot, nil)
+			require.NoError(t, err)
+			tt.setAuth(req)
+			resp, _ := getSnapshot(t, req)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
@@ -31,7 +31,7 @@ func HandleVersion(mux *http.ServeMux, server etcdserver.Server) {
 	mux.HandleFunc(versionPath, versionHandler(server, serveVersion))
 }
 
-func versionHandler(server etcdserver.Server, fn func(http.ResponseWriter, *http.Request, string, string)) http.HandlerFunc {
+func versionHandler(server etcdserver.Server, fn func(http.ResponseWriter, *http.Request, string, string, map[string]bool, []string)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		clusterVersion := server.ClusterVersion()
 		storageVersion := server.StorageVersion()
@@ -42,11 +42,11 @@ func versionHandler(server etcdserver.Server, fn func(http.ResponseWriter, *http
 		if storageVersion != nil {
 			storageVersionStr = storageVersion.String()
 		}
-		fn(w, r, clusterVersionStr, storageVersionStr)
+		fn(w, r, clusterVersionStr, storageVersionStr, server.FeatureGates(), server.VersionRetention())
 	}
 }
 
-func serveVersion(w http.ResponseWriter, r *http.Request, clusterV, storageV string) {
+func serveVersion(w http.ResponseWriter, r *http.Request, clusterV, storageV string, featureGates map[string]bool, versionRetention []string) {
 	if !allowMethod(w, r, "GET") {
 		return
 	}
@@ -54,6 +54,9 @@ func serveVersion(w http.ResponseWriter, r *http.Request, clusterV, storageV str
 		Server:  version.Version,
 		Cluster: clusterV,
 		Storage: storageV,
+
+		FeatureGates:     featureGates,
+		VersionRetention: versionRetention,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -29,7 +29,7 @@ func TestServeVersion(t *testing.T) {
 		t.Fatalf("error creating request: %v", err)
 	}
 	rw := httptest.NewRecorder()
-	serveVersion(rw, req, "3.6.0", "3.5.2")
+	serveVersion(rw, req, "3.6.0", "3.5.2", map[string]bool{"InitialCorruptCheck": true}, []string{"/audit/=3"})
 	if rw.Code != http.StatusOK {
 		t.Errorf("code=%d, want %d", rw.Code, http.StatusOK)
 	}
@@ -37,6 +37,9 @@ func TestServeVersion(t *testing.T) {
 		Server:  version.Version,
 		Cluster: "3.6.0",
 		Storage: "3.5.2",
+
+		FeatureGates:     map[string]bool{"InitialCorruptCheck": true},
+		VersionRetention: []string{"/audit/=3"},
 	}
 	w, err := json.Marshal(&vs)
 	if err != nil {
@@ -60,7 +63,7 @@ func TestServeVersionFails(t *testing.T) {
 				t.Fatalf("error creating request: %v", err)
 			}
 			rw := httptest.NewRecorder()
-			serveVersion(rw, req, "3.6.0", "3.5.2")
+			serveVersion(rw, req, "3.6.0", "3.5.2", nil, nil)
 			if rw.Code != http.StatusMethodNotAllowed {
 				t.Errorf("method %s: code=%d, want %d", m, rw.Code, http.StatusMethodNotAllowed)
 			}
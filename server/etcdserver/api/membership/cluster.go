@@ -71,6 +71,18 @@ type ConfigChangeContext struct {
 	IsPromote bool `json:"isPromote"`
 }
 
+// ReplaceChangeContext represents a context for the confChangeV2 replacing a
+// member with a new learner member.
+type ReplaceChangeContext struct {
+	// ID is the ID of the request waiting for the confChangeV2 to be applied,
+	// which the confChangeV2 has no field for.
+	ID uint64 `json:"id"`
+	// ReplacedID is the ID of the member to remove.
+	ReplacedID types.ID `json:"replacedID"`
+	// Member is the replacement member to add as a learner.
+	Member Member `json:"member"`
+}
+
 type ShouldApplyV3 bool
 
 const (
@@ -292,6 +304,7 @@ func (c *RaftCluster) Recover(onSet func(*zap.Logger, *semver.Version)) {
 			zap.String("recovered-remote-peer-id", m.ID.String()),
 			zap.Strings("recovered-remote-peer-urls", m.PeerURLs),
 			zap.Bool("recovered-remote-peer-is-learner", m.IsLearner),
+			zap.Bool("recovered-remote-peer-is-observer", m.IsObserver),
 		)
 	}
 	if c.version != nil {
@@ -325,6 +338,9 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 			if !membersMap[id].IsLearner {
 				return ErrMemberNotLearner
 			}
+			if membersMap[id].IsObserver {
+				return ErrMemberObserver
+			}
 		} else { // adding a new member
 			if membersMap[id] != nil {
 				return ErrIDExists
@@ -344,7 +360,8 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 				}
 			}
 
-			if confChangeContext.Member.RaftAttributes.IsLearner && cc.Type == raftpb.ConfChangeAddLearnerNode { // the new member is a learner
+			// observers do not count toward the maximum number of learners
+			if confChangeContext.Member.RaftAttributes.IsLearner && !confChangeContext.Member.IsObserver && cc.Type == raftpb.ConfChangeAddLearnerNode { // the new member is a learner
 				scaleUpLearners := true
 				if err := ValidateMaxLearnerConfig(c.maxLearners, members, scaleUpLearners); err != nil {
 					return err
@@ -385,6 +402,52 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 	return nil
 }
 
+// ValidateReplaceMember ensures that the member of the given id can be replaced
+// by the given learner member, the same way ValidateConfigurationChange does for
+// removing the former and adding the latter, the replaced member no longer
+// holding its peerURLs and learner slot.
+func (c *RaftCluster) ValidateReplaceMember(id types.ID, m *Member) error {
+	membersMap, removedMap := membersFromStore(c.lg, c.v2store)
+	if removedMap[id] {
+		return ErrIDRemoved
+	}
+	if membersMap[id] == nil {
+		return ErrIDNotFound
+	}
+	if removedMap[m.ID] {
+		return ErrIDRemoved
+	}
+	if membersMap[m.ID] != nil {
+		return ErrIDExists
+	}
+
+	var members []*Member
+	urls := make(map[string]bool)
+	for _, mm := range membersMap {
+		if mm.ID == id {
+			continue
+		}
+		members = append(members, mm)
+		for _, u := range mm.PeerURLs {
+			urls[u] = true
+		}
+	}
+	for _, u := range m.PeerURLs {
+		if urls[u] {
+			return ErrPeerURLexists
+		}
+	}
+
+	// observers do not count toward the maximum number of learners
+	if !m.IsObserver {
+		scaleUpLearners := true
+		if err := ValidateMaxLearnerConfig(c.maxLearners, members, scaleUpLearners); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddMember adds a new Member into the cluster, and saves the given member's
 // raftAttributes into the store. The given member should have empty attributes.
 // A Member with a matching id must not exist.
@@ -408,6 +471,7 @@ func (c *RaftCluster) AddMember(m *Member, shouldApplyV3 ShouldApplyV3) {
 		zap.String("added-peer-id", m.ID.String()),
 		zap.Strings("added-peer-peer-urls", m.PeerURLs),
 		zap.Bool("added-peer-is-learner", m.IsLearner),
+		zap.Bool("added-peer-is-observer", m.IsObserver),
 	)
 }
 
@@ -745,6 +809,21 @@ func (c *RaftCluster) IsLocalMemberLearner() bool {
 	return localMember.IsLearner
 }
 
+// IsLocalMemberObserver returns if the local member is an observer
+func (c *RaftCluster) IsLocalMemberObserver() bool {
+	c.Lock()
+	defer c.Unlock()
+	localMember, ok := c.members[c.localID]
+	if !ok {
+		c.lg.Panic(
+			"failed to find local ID in cluster members",
+			zap.String("cluster-id", c.cid.String()),
+			zap.String("local-member-id", c.localID.String()),
+		)
+	}
+	return localMember.IsObserver
+}
+
 // DowngradeInfo returns the downgrade status of the cluster
 func (c *RaftCluster) DowngradeInfo() *serverversion.DowngradeInfo {
 	c.Lock()
@@ -836,11 +915,11 @@ func (c *RaftCluster) updateMembershipMetric(peer types.ID, known bool) {
 }
 
 // ValidateMaxLearnerConfig verifies the existing learner members in the cluster membership and an optional N+1 learner
-// scale up are not more than maxLearners.
+// scale up are not more than maxLearners. The observers are not counted.
 func ValidateMaxLearnerConfig(maxLearners int, members []*Member, scaleUpLearners bool) error {
 	numLearners := 0
 	for _, m := range members {
-		if m.IsLearner {
+		if m.IsLearner && !m.IsObserver {
 			numLearners++
 		}
 	}
@@ -461,6 +461,151 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 	}
 }
 
+func TestClusterValidateConfigurationChangeObserver(t *testing.T) {
+	cl := NewCluster(zaptest.NewLogger(t), WithMaxLearners(1))
+	cl.SetStore(v2store.New())
+	cl.AddMember(&Member{ID: 1, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:1"}}}, true)
+	cl.AddMember(&Member{ID: 2, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2"}, IsLearner: true}}, true)
+	cl.AddMember(&Member{ID: 3, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:3"}, IsLearner: true, IsObserver: true}}, true)
+
+	mustMarshal := func(ctx ConfigChangeContext) []byte {
+		b, err := json.Marshal(&ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		cc   raftpb.ConfChange
+		werr error
+	}{
+		{
+			name: "observer cannot be promoted",
+			cc: raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddNode,
+				NodeID:  3,
+				Context: mustMarshal(ConfigChangeContext{Member: Member{ID: 3}, IsPromote: true}),
+			},
+			werr: ErrMemberObserver,
+		},
+		{
+			name: "learner can be promoted",
+			cc: raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddNode,
+				NodeID:  2,
+				Context: mustMarshal(ConfigChangeContext{Member: Member{ID: 2}, IsPromote: true}),
+			},
+		},
+		{
+			name: "observer does not count toward the max learners",
+			cc: raftpb.ConfChange{
+				Type:   raftpb.ConfChangeAddLearnerNode,
+				NodeID: 4,
+				Context: mustMarshal(ConfigChangeContext{Member: Member{ID: 4, RaftAttributes: RaftAttributes{
+					PeerURLs: []string{"http://127.0.0.1:4"}, IsLearner: true, IsObserver: true,
+				}}}),
+			},
+		},
+		{
+			name: "learner is limited by the max learners",
+			cc: raftpb.ConfChange{
+				Type:   raftpb.ConfChangeAddLearnerNode,
+				NodeID: 5,
+				Context: mustMarshal(ConfigChangeContext{Member: Member{ID: 5, RaftAttributes: RaftAttributes{
+					PeerURLs: []string{"http://127.0.0.1:5"}, IsLearner: true,
+				}}}),
+			},
+			werr: ErrTooManyLearners,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cl.ValidateConfigurationChange(tt.cc); err != tt.werr {
+				t.Errorf("validateConfigurationChange error = %v, want %v", err, tt.werr)
+			}
+		})
+	}
+}
+
+func TestClusterValidateReplaceMember(t *testing.T) {
+	cl := NewCluster(zaptest.NewLogger(t), WithMaxLearners(1))
+	cl.SetStore(v2store.New())
+	cl.AddMember(&Member{ID: 1, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:1"}}}, true)
+	cl.AddMember(&Member{ID: 2, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2"}}}, true)
+	cl.AddMember(&Member{ID: 3, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:3"}, IsLearner: true}}, true)
+	cl.AddMember(&Member{ID: 4, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:4"}}}, true)
+	cl.RemoveMember(4, true)
+
+	learner := func(id types.ID, url string) *Member {
+		return &Member{ID: id, RaftAttributes: RaftAttributes{PeerURLs: []string{url}, IsLearner: true}}
+	}
+
+	tests := []struct {
+		name string
+		id   types.ID
+		m    *Member
+		werr error
+	}{
+		{
+			name: "replaced member not found",
+			id:   5,
+			m:    learner(6, "http://127.0.0.1:6"),
+			werr: ErrIDNotFound,
+		},
+		{
+			name: "replaced member removed",
+			id:   4,
+			m:    learner(6, "http://127.0.0.1:6"),
+			werr: ErrIDRemoved,
+		},
+		{
+			name: "replacement member exists",
+			id:   2,
+			m:    learner(1, "http://127.0.0.1:6"),
+			werr: ErrIDExists,
+		},
+		{
+			name: "replacement member removed",
+			id:   2,
+			m:    learner(4, "http://127.0.0.1:6"),
+			werr: ErrIDRemoved,
+		},
+		{
+			name: "replacement peerURL held by another member",
+			id:   2,
+			m:    learner(6, "http://127.0.0.1:1"),
+			werr: ErrPeerURLexists,
+		},
+		{
+			name: "replacement takes over the peerURL and learner slot of the replaced member",
+			id:   3,
+			m:    learner(6, "http://127.0.0.1:3"),
+		},
+		{
+			name: "replacement of a voting member is limited by the max learners",
+			id:   2,
+			m:    learner(6, "http://127.0.0.1:6"),
+			werr: ErrTooManyLearners,
+		},
+		{
+			name: "observer does not count toward the max learners",
+			id:   2,
+			m: &Member{ID: 6, RaftAttributes: RaftAttributes{
+				PeerURLs: []string{"http://127.0.0.1:2"}, IsLearner: true, IsObserver: true,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cl.ValidateReplaceMember(tt.id, tt.m); err != tt.werr {
+				t.Errorf("ValidateReplaceMember error = %v, want %v", err, tt.werr)
+			}
+		})
+	}
+}
+
 func TestClusterGenID(t *testing.T) {
 	cs := newTestCluster(t, []*Member{
 		newTestMember(1, nil, "", nil),
@@ -32,6 +32,10 @@ type RaftAttributes struct {
 	PeerURLs []string `json:"peerURLs"`
 	// IsLearner indicates if the member is raft learner.
 	IsLearner bool `json:"isLearner,omitempty"`
+	// IsObserver indicates if the member is a permanent non-voting observer.
+	// An observer is a raft learner which cannot be promoted, and does not
+	// count toward the maximum number of learners.
+	IsObserver bool `json:"isObserver,omitempty"`
 }
 
 // Attributes represents all the non-raft related attributes of an etcd member.
@@ -60,6 +64,14 @@ func NewMemberAsLearner(name string, peerURLs types.URLs, clusterName string, no
 	return newMember(name, peerURLs, memberId, true)
 }
 
+// NewMemberAsObserver creates an observer Member without an ID and generates one based on the
+// cluster name, peer URLs, and time. This is used for adding new observer member.
+func NewMemberAsObserver(name string, peerURLs types.URLs, clusterName string, now *time.Time) *Member {
+	m := NewMemberAsLearner(name, peerURLs, clusterName, now)
+	m.IsObserver = true
+	return m
+}
+
 func computeMemberId(peerURLs types.URLs, clusterName string, now *time.Time) types.ID {
 	peerURLstrs := peerURLs.StringSlice()
 	sort.Strings(peerURLstrs)
@@ -94,7 +106,8 @@ func (m *Member) Clone() *Member {
 	mm := &Member{
 		ID: m.ID,
 		RaftAttributes: RaftAttributes{
-			IsLearner: m.IsLearner,
+			IsLearner:  m.IsLearner,
+			IsObserver: m.IsObserver,
 		},
 		Attributes: Attributes{
 			Name: m.Name,
@@ -30,6 +30,11 @@ import (
 
 var ErrNoDBSnapshot = errors.New("snap: snapshot file doesn't exist")
 
+// DBChunkSize is the size of the chunks in which database snapshots are
+// streamed to the other members. The sender does not buffer more than a
+// chunk, so the memory used to send a snapshot does not depend on its size.
+const DBChunkSize = 1024 * 1024
+
 // SaveDBFrom saves snapshot of the database from the given reader. It
 // guarantees the save operation is atomic.
 func (s *Snapshotter) SaveDBFrom(r io.Reader, id uint64) (int64, error) {
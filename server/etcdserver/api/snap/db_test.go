@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snap
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// TestSaveDBFrom ensures that a database snapshot received from a stream is
+// saved entirely.
+func TestSaveDBFrom(t *testing.T) {
+	const size = 64 * DBChunkSize
+	ss := New(zaptest.NewLogger(t), t.TempDir())
+
+	n, err := ss.SaveDBFrom(io.LimitReader(zeroReader{}, size), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != size {
+		t.Fatalf("saved %d bytes, want %d", n, size)
+	}
+
+	fn, err := ss.DBFilePath(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != size {
+		t.Errorf("snapshot file size = %d, want %d", fi.Size(), size)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3authz consults an external authorizer for the final decision on
+// the requests of the clients permitted by the roles of their users.
+package v3authz
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTimeout = time.Second
+	// maxCacheEntries bounds the number of the cached decisions.
+	maxCacheEntries = 10000
+)
+
+var (
+	// ErrDenied is returned for the requests denied by the authorizer.
+	ErrDenied = errors.New("v3authz: request denied by the authorizer")
+	// ErrUnavailable is returned for the requests the authorizer failed to
+	// decide on, under the fail-closed policy.
+	ErrUnavailable = errors.New("v3authz: authorizer unavailable")
+)
+
+// KeyRange is a range of keys touched by a request, the single key if
+// RangeEnd is empty and all the keys from Key if RangeEnd is "\0". Keys are
+// base64 encoded in JSON.
+type KeyRange struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+// Request is a request of a client the authorizer decides on.
+type Request struct {
+	// User is the authenticated user of the request.
+	User string `json:"user"`
+	// Method is the full gRPC method of the request, like
+	// "/etcdserverpb.KV/Put".
+	Method string `json:"method"`
+	// Keys are the key ranges touched by the request.
+	Keys []KeyRange `json:"keys"`
+}
+
+// Decision is the decision of the authorizer on a request.
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Authorizer decides on the requests of the clients, for example by
+// consulting an HTTP webhook or a gRPC service.
+type Authorizer interface {
+	Authorize(ctx context.Context, r Request) (Decision, error)
+}
+
+// Config configures a Checker.
+type Config struct {
+	// Timeout bounds the time the authorizer takes to decide on a request,
+	// 1 second if zero.
+	Timeout time.Duration
+	// CacheTTL is the time the decisions of the authorizer are cached for,
+	// by user, method and key ranges. Zero disables the cache.
+	CacheTTL time.Duration
+	// FailOpen allows the requests the authorizer fails to decide on, which
+	// are denied otherwise.
+	FailOpen bool
+}
+
+// Checker checks the requests against the decisions of an authorizer,
+// caching them and applying the failure policy.
+type Checker struct {
+	lg  *zap.Logger
+	a   Authorizer
+	cfg Config
+
+	mu    sync.Mutex
+	cache map[string]cachedDecision
+}
+
+type cachedDecision struct {
+	Decision
+	expires time.Time
+}
+
+// NewChecker returns a Checker of the decisions of the authorizer.
+func NewChecker(lg *zap.Logger, a Authorizer, cfg Config) *Checker {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return &Checker{lg: lg, a: a, cfg: cfg, cache: make(map[string]cachedDecision)}
+}
+
+// Check returns nil if the authorizer allows the request, ErrDenied if it
+// denies it, and ErrUnavailable if it fails to decide on it under the
+// fail-closed policy.
+func (c *Checker) Check(ctx context.Context, r Request) error {
+	key := cacheKey(r)
+	if d, ok := c.cached(key); ok {
+		return decisionError(d)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	d, err := c.a.Authorize(ctx, r)
+	cancel()
+	if err != nil {
+		c.lg.Warn(
+			"failed to consult authorizer",
+			zap.String("user", r.User),
+			zap.String("method", r.Method),
+			zap.Bool("fail-open", c.cfg.FailOpen),
+			zap.Error(err),
+		)
+		if c.cfg.FailOpen {
+			return nil
+		}
+		return ErrUnavailable
+	}
+	if !d.Allowed {
+		c.lg.Debug(
+			"authorizer denied request",
+			zap.String("user", r.User),
+			zap.String("method", r.Method),
+			zap.String("reason", d.Reason),
+		)
+	}
+	c.store(key, d)
+	return decisionError(d)
+}
+
+func decisionError(d Decision) error {
+	if !d.Allowed {
+		return ErrDenied
+	}
+	return nil
+}
+
+func (c *Checker) cached(key string) (Decision, bool) {
+	if c.cfg.CacheTTL <= 0 {
+		return Decision{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.cache[key]
+	if !ok || time.Now().After(d.expires) {
+		return Decision{}, false
+	}
+	return d.Decision, true
+}
+
+func (c *Checker) store(key string, d Decision) {
+	if c.cfg.CacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.cache) >= maxCacheEntries {
+		for k, cd := range c.cache {
+			if now.After(cd.expires) {
+				delete(c.cache, k)
+			}
+		}
+		// drop the cache rather than tracking the least recently used
+		// decisions, the decisions being short-lived
+		if len(c.cache) >= maxCacheEntries {
+			c.cache = make(map[string]cachedDecision)
+		}
+	}
+	c.cache[key] = cachedDecision{Decision: d, expires: now.Add(c.cfg.CacheTTL)}
+}
+
+// cacheKey returns the key of the decisions on the request, the keys being
+// prefixed by their length to keep the key unambiguous.
+func cacheKey(r Request) string {
+	var sb strings.Builder
+	for _, s := range []string{r.User, r.Method} {
+		sb.WriteString(strconv.Itoa(len(s)))
+		sb.WriteByte(':')
+		sb.WriteString(s)
+	}
+	for _, kr := range r.Keys {
+		for _, b := range [][]byte{kr.Key, kr.RangeEnd} {
+			sb.WriteString(strconv.Itoa(len(b)))
+			sb.WriteByte(':')
+			sb.Write(b)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// newTestWebhook returns a webhook allowing the requests of the user "alice"
+// only, counting the requests it decides on.
+func newTestWebhook(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(Decision{Allowed: req.User == "alice", Reason: "only alice"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckerWebhook(t *testing.T) {
+	var calls atomic.Int32
+	srv := newTestWebhook(t, &calls)
+	c := NewChecker(zaptest.NewLogger(t), NewWebhook(srv.URL, nil), Config{CacheTTL: time.Minute})
+
+	ctx := context.TODO()
+	r := Request{User: "alice", Method: "/etcdserverpb.KV/Put", Keys: []KeyRange{{Key: []byte("foo")}}}
+	require.NoError(t, c.Check(ctx, r))
+	require.NoError(t, c.Check(ctx, r))
+	assert.Equal(t, int32(1), calls.Load(), "expected the decision to be cached")
+
+	r.Keys = []KeyRange{{Key: []byte("bar")}}
+	require.NoError(t, c.Check(ctx, r))
+	assert.Equal(t, int32(2), calls.Load(), "expected the decisions to be cached by key")
+
+	r.User = "bob"
+	require.ErrorIs(t, c.Check(ctx, r), ErrDenied)
+	require.ErrorIs(t, c.Check(ctx, r), ErrDenied)
+	assert.Equal(t, int32(3), calls.Load(), "expected the denial to be cached")
+}
+
+func TestCheckerNoCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := newTestWebhook(t, &calls)
+	c := NewChecker(zaptest.NewLogger(t), NewWebhook(srv.URL, nil), Config{})
+
+	r := Request{User: "alice", Method: "/etcdserverpb.KV/Range"}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Check(context.TODO(), r))
+	}
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCheckerFailurePolicy(t *testing.T) {
+	donec := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-donec
+			return
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	defer close(donec)
+
+	tests := []struct {
+		name     string
+		url      string
+		failOpen bool
+		wantErr  error
+	}{
+		{name: "fail-closed on error status", url: srv.URL, wantErr: ErrUnavailable},
+		{name: "fail-open on error status", url: srv.URL, failOpen: true},
+		{name: "fail-closed on timeout", url: srv.URL + "/slow", wantErr: ErrUnavailable},
+		{name: "fail-open on timeout", url: srv.URL + "/slow", failOpen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(zaptest.NewLogger(t), NewWebhook(tt.url, nil), Config{
+				Timeout:  100 * time.Millisecond,
+				CacheTTL: time.Minute,
+				FailOpen: tt.failOpen,
+			})
+			r := Request{User: "alice", Method: "/etcdserverpb.KV/Put"}
+			assert.ErrorIs(t, c.Check(context.TODO(), r), tt.wantErr)
+			// the failures are not cached
+			assert.Empty(t, c.cache)
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := Request{User: "a", Method: "b", Keys: []KeyRange{{Key: []byte("c")}}}
+	b := Request{User: "a", Method: "b", Keys: []KeyRange{{Key: []byte(""), RangeEnd: []byte("c")}}}
+	c := Request{User: "a:", Method: "b"}
+	assert.NotEqual(t, cacheKey(a), cacheKey(b))
+	assert.NotEqual(t, cacheKey(a), cacheKey(c))
+}
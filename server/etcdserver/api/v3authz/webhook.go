@@ -0,0 +1,70 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// webhookMaxResponseSize is the maximum size of the decisions of the webhook.
+const webhookMaxResponseSize = 64 * 1024
+
+type webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook returns an authorizer posting every request as a JSON encoded
+// Request to the given URL, which replies with a JSON encoded Decision and a
+// 2xx status code. If client is nil, http.DefaultClient is used, the time of
+// the decisions being bounded by the Checker.
+func NewWebhook(url string, client *http.Client) Authorizer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &webhook{url: url, client: client}
+}
+
+func (w *webhook) Authorize(ctx context.Context, r Request) (Decision, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return Decision{}, fmt.Errorf("webhook %q replied with status %q", w.url, resp.Status)
+	}
+	var d Decision
+	if err = json.NewDecoder(io.LimitReader(resp.Body, webhookMaxResponseSize)).Decode(&d); err != nil {
+		return Decision{}, fmt.Errorf("webhook %q replied with an invalid decision: %v", w.url, err)
+	}
+	return d, nil
+}
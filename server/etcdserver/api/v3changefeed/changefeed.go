@@ -0,0 +1,377 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3changefeed publishes the committed key space events of
+// configured prefixes to external sinks.
+package v3changefeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// CheckpointPrefix is the prefix of the reserved key space holding the change
+// feed checkpoints. Its events are never published by the change feeds.
+const CheckpointPrefix = "\x00changefeed/"
+
+var errNotLeader = errors.New("local member is not the leader")
+
+const (
+	minRetryInterval = 100 * time.Millisecond
+	maxRetryInterval = 30 * time.Second
+
+	// defaultCheckpointInterval is the interval at which the revisions of the
+	// published batches are checkpointed, each checkpoint being a revision of
+	// the key space.
+	defaultCheckpointInterval = time.Second
+)
+
+// Batch is a set of events of a change feed prefix, published at once.
+type Batch struct {
+	// Feed is the name of the change feed.
+	Feed string
+	// Prefix is the watched key prefix the events belong to.
+	Prefix string
+	// Events are the events of one or more consecutive revisions, in
+	// revision order. Events of a revision are never split across batches.
+	Events []mvccpb.Event
+}
+
+// Sink receives the events of a change feed. Batches are delivered at least
+// once: a batch is published again, after leader changes or restarts, until
+// Publish succeeds. Consumers should use the mod revision of the events to
+// drop duplicates.
+type Sink interface {
+	// Publish delivers the batch, returning only once the sink accepted it.
+	// A failed batch is retried with backoff.
+	Publish(ctx context.Context, b Batch) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Config configures a change feed.
+type Config struct {
+	// Name identifies the change feed and its checkpoints. It must be
+	// unique and stable across restarts.
+	Name string
+	// Prefixes are the key prefixes whose events are published. An empty
+	// prefix publishes the events of the whole key space.
+	Prefixes []string
+	// Sink receives the events.
+	Sink Sink
+}
+
+func (cfg Config) validate() error {
+	if cfg.Name == "" {
+		return errors.New("change feed name must not be empty")
+	}
+	if strings.Contains(cfg.Name, "\x00") {
+		return fmt.Errorf("change feed name %q must not contain NUL characters", cfg.Name)
+	}
+	if len(cfg.Prefixes) == 0 {
+		return fmt.Errorf("change feed %q has no prefix", cfg.Name)
+	}
+	if cfg.Sink == nil {
+		return fmt.Errorf("change feed %q has no sink", cfg.Name)
+	}
+	return nil
+}
+
+// Server is the etcd server a change feed tails.
+type Server interface {
+	KV() mvcc.WatchableKV
+	MemberId() types.ID
+	Leader() types.ID
+	LeaderChangedNotify() <-chan struct{}
+	// ChangeFeedCheckpoint proposes the put of a checkpoint key through raft.
+	ChangeFeedCheckpoint(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error)
+}
+
+// ChangeFeed tails the committed events of its prefixes while the local
+// member is the leader, and publishes them to its sink. The revision of the
+// last published batch of each prefix is checkpointed through raft in the
+// reserved key space, so that publishing resumes from it after leader
+// changes and restarts. A checkpoint not yet proposed when the leadership is
+// lost only causes batches to be published again.
+type ChangeFeed struct {
+	lg  *zap.Logger
+	s   Server
+	cfg Config
+
+	checkpointInterval time.Duration
+
+	stopc chan struct{}
+	donec chan struct{}
+	once  sync.Once
+}
+
+// New returns a change feed for the given server. Use Run to start it.
+func New(lg *zap.Logger, s Server, cfg Config) (*ChangeFeed, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &ChangeFeed{
+		lg:                 lg.With(zap.String("change-feed", cfg.Name)),
+		s:                  s,
+		cfg:                cfg,
+		checkpointInterval: defaultCheckpointInterval,
+		stopc:              make(chan struct{}),
+		donec:              make(chan struct{}),
+	}, nil
+}
+
+// Run starts the main loop of the change feed in background.
+// Use Stop to halt the loop and close the sink.
+func (f *ChangeFeed) Run() {
+	go func() {
+		defer close(f.donec)
+		for {
+			ctx, cancel, ok := f.waitLeadership()
+			if !ok {
+				return
+			}
+			f.lg.Info("started publishing change feed", zap.Strings("prefixes", f.cfg.Prefixes))
+			err := f.tail(ctx)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				f.lg.Warn("stopped publishing change feed", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Stop halts the main loop of the change feed and closes its sink.
+func (f *ChangeFeed) Stop() {
+	f.once.Do(func() { close(f.stopc) })
+	<-f.donec
+	if err := f.cfg.Sink.Close(); err != nil {
+		f.lg.Warn("failed to close change feed sink", zap.Error(err))
+	}
+}
+
+// waitLeadership waits for the local member to be the leader. It returns a
+// context canceled once the member loses the leadership or the change feed
+// is stopped.
+func (f *ChangeFeed) waitLeadership() (context.Context, context.CancelFunc, bool) {
+	for {
+		select {
+		case <-f.stopc:
+			return nil, nil, false
+		default:
+		}
+		changed := f.s.LeaderChangedNotify()
+		if f.s.Leader() == f.s.MemberId() {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-changed:
+				case <-f.stopc:
+				case <-ctx.Done():
+				}
+				cancel()
+			}()
+			return ctx, cancel, true
+		}
+		select {
+		case <-changed:
+		case <-f.stopc:
+			return nil, nil, false
+		}
+	}
+}
+
+// tail publishes the events of all prefixes, from their checkpoints, until
+// the context is canceled.
+func (f *ChangeFeed) tail(ctx context.Context) error {
+	ws := f.s.KV().NewWatchStream()
+	defer ws.Close()
+
+	prefixes := make(map[mvcc.WatchID]string, len(f.cfg.Prefixes))
+	watch := func(prefix string, startRev int64) error {
+		key, end := prefixRange(prefix)
+		id, err := ws.Watch(clientv3.AutoWatchID, key, end, startRev)
+		if err != nil {
+			return err
+		}
+		prefixes[id] = prefix
+		return nil
+	}
+	for _, prefix := range f.cfg.Prefixes {
+		if err := watch(prefix, f.startRevision(prefix)); err != nil {
+			return err
+		}
+	}
+
+	// the revisions published since the last checkpoints, by prefix
+	published := make(map[string]int64)
+	ticker := time.NewTicker(f.checkpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case resp, ok := <-ws.Chan():
+			if !ok {
+				return errors.New("watch stream closed")
+			}
+			prefix, ok := prefixes[resp.WatchID]
+			if !ok {
+				continue
+			}
+			if resp.CompactRevision != 0 {
+				f.lg.Warn(
+					"change feed checkpoint was compacted, events between the checkpoint and the compaction revision are lost",
+					zap.String("prefix", prefix),
+					zap.Int64("compact-revision", resp.CompactRevision),
+				)
+				delete(prefixes, resp.WatchID)
+				if err := watch(prefix, resp.CompactRevision); err != nil {
+					return err
+				}
+				continue
+			}
+			events := withoutCheckpoints(resp.Events)
+			if len(events) == 0 {
+				continue
+			}
+			// the context is canceled asynchronously to the leader changes
+			if f.s.Leader() != f.s.MemberId() {
+				return errNotLeader
+			}
+			if err := f.publish(ctx, Batch{Feed: f.cfg.Name, Prefix: prefix, Events: events}); err != nil {
+				return err
+			}
+			published[prefix] = events[len(events)-1].Kv.ModRevision
+		case <-ticker.C:
+			f.checkpoint(ctx, published)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// withoutCheckpoints returns the events not on checkpoint keys.
+func withoutCheckpoints(events []mvccpb.Event) []mvccpb.Event {
+	for i := range events {
+		if !bytes.HasPrefix(events[i].Kv.Key, []byte(CheckpointPrefix)) {
+			continue
+		}
+		kept := append([]mvccpb.Event{}, events[:i]...)
+		for _, ev := range events[i+1:] {
+			if !bytes.HasPrefix(ev.Kv.Key, []byte(CheckpointPrefix)) {
+				kept = append(kept, ev)
+			}
+		}
+		return kept
+	}
+	return events
+}
+
+// checkpoint proposes the checkpoints of the published revisions. The
+// revisions failing to be checkpointed are published again by the next
+// leaders, so the failures are only logged.
+func (f *ChangeFeed) checkpoint(ctx context.Context, published map[string]int64) {
+	for prefix, rev := range published {
+		r := &pb.PutRequest{Key: checkpointKey(f.cfg.Name, prefix), Value: []byte(strconv.FormatInt(rev, 10))}
+		if _, err := f.s.ChangeFeedCheckpoint(ctx, r); err != nil {
+			f.lg.Warn("failed to checkpoint change feed",
+				zap.String("prefix", prefix),
+				zap.Int64("revision", rev),
+				zap.Error(err),
+			)
+			return
+		}
+		delete(published, prefix)
+	}
+}
+
+// startRevision returns the revision to start watching the prefix from,
+// following its checkpoint, or following the current revision if the
+// prefix has no checkpoint yet.
+func (f *ChangeFeed) startRevision(prefix string) int64 {
+	rr, err := f.s.KV().Range(context.TODO(), checkpointKey(f.cfg.Name, prefix), nil, mvcc.RangeOptions{})
+	if err == nil && len(rr.KVs) == 1 {
+		if rev, err := strconv.ParseInt(string(rr.KVs[0].Value), 10, 64); err == nil {
+			return rev + 1
+		}
+		f.lg.Warn("ignored invalid change feed checkpoint", zap.String("prefix", prefix), zap.ByteString("checkpoint", rr.KVs[0].Value))
+	}
+	return f.s.KV().Rev() + 1
+}
+
+// publish delivers the batch, retrying with backoff until it succeeds or
+// the context is canceled.
+func (f *ChangeFeed) publish(ctx context.Context, b Batch) error {
+	interval := minRetryInterval
+	for {
+		err := f.cfg.Sink.Publish(ctx, b)
+		if err == nil {
+			return nil
+		}
+		f.lg.Warn("failed to publish change feed events, retrying",
+			zap.String("prefix", b.Prefix),
+			zap.Int64("revision", b.Events[0].Kv.ModRevision),
+			zap.Duration("retry-interval", interval),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// checkpointKey returns the key of the checkpoint of the prefix of the feed,
+// holding the last published revision in decimal.
+func checkpointKey(feed, prefix string) []byte {
+	return []byte(CheckpointPrefix + feed + "\x00" + prefix)
+}
+
+// prefixRange returns the key range covering all keys with the given
+// prefix. An empty, non-nil end watches all keys from key.
+func prefixRange(prefix string) (key, end []byte) {
+	if prefix == "" {
+		return []byte{0}, []byte{}
+	}
+	key = []byte(prefix)
+	end = make([]byte, len(key))
+	copy(end, key)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return key, end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, watch all keys from it
+	return key, []byte{}
+}
@@ -0,0 +1,230 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+type fakeServer struct {
+	kv mvcc.WatchableKV
+
+	mu      sync.Mutex
+	leader  types.ID
+	changed chan struct{}
+}
+
+func (s *fakeServer) KV() mvcc.WatchableKV { return s.kv }
+func (s *fakeServer) MemberId() types.ID   { return 1 }
+
+func (s *fakeServer) ChangeFeedCheckpoint(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	rev := s.kv.Put(r.Key, r.Value, lease.NoLease)
+	return &pb.PutResponse{Header: &pb.ResponseHeader{Revision: rev}}, nil
+}
+
+// checkpoint returns the checkpointed revision of the prefix of the feed.
+func (s *fakeServer) checkpoint(feed, prefix string) int64 {
+	rr, err := s.kv.Range(context.TODO(), checkpointKey(feed, prefix), nil, mvcc.RangeOptions{})
+	if err != nil || len(rr.KVs) == 0 {
+		return 0
+	}
+	rev, _ := strconv.ParseInt(string(rr.KVs[0].Value), 10, 64)
+	return rev
+}
+
+func (s *fakeServer) Leader() types.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+func (s *fakeServer) LeaderChangedNotify() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.changed
+}
+
+func (s *fakeServer) setLeader(id types.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = id
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+type recordingSink struct {
+	mu       sync.Mutex
+	failures int
+	events   []string
+}
+
+func (s *recordingSink) Publish(ctx context.Context, b Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	for _, ev := range b.Events {
+		s.events = append(s.events, ev.Type.String()+" "+string(ev.Kv.Key))
+	}
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) published() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func TestChangeFeedCheckpoint(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer kv.Close()
+	s := &fakeServer{kv: kv, leader: 1, changed: make(chan struct{})}
+
+	kv.Put([]byte("/a/0"), []byte("before"), lease.NoLease)
+
+	sink := &recordingSink{failures: 2}
+	f, err := New(lg, s, Config{Name: "test", Prefixes: []string{"/a/"}, Sink: sink})
+	require.NoError(t, err)
+	f.checkpointInterval = 10 * time.Millisecond
+	// checkpoint the current revision, for the feed not to start following a
+	// later one if the puts below happen before it starts
+	kv.Put(checkpointKey("test", "/a/"), []byte(strconv.FormatInt(kv.Rev(), 10)), lease.NoLease)
+	f.Run()
+
+	kv.Put([]byte("/a/1"), []byte("v"), lease.NoLease)
+	kv.Put([]byte("/b/1"), []byte("v"), lease.NoLease) // not published
+	_, rev := kv.DeleteRange([]byte("/a/1"), nil)
+	require.Eventually(t, func() bool { return len(sink.published()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"PUT /a/1", "DELETE /a/1"}, sink.published(), "expected events following the start, retried on failures")
+	require.Eventually(t, func() bool { return s.checkpoint("test", "/a/") == rev }, 5*time.Second, 10*time.Millisecond)
+
+	// no event is published while not the leader
+	s.setLeader(2)
+	rev = kv.Put([]byte("/a/2"), []byte("v"), lease.NoLease)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"PUT /a/1", "DELETE /a/1"}, sink.published())
+
+	// publishing resumes from the checkpoint when the leadership is back
+	s.setLeader(1)
+	require.Eventually(t, func() bool { return len(sink.published()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"PUT /a/1", "DELETE /a/1", "PUT /a/2"}, sink.published())
+	require.Eventually(t, func() bool { return s.checkpoint("test", "/a/") == rev }, 5*time.Second, 10*time.Millisecond)
+	f.Stop()
+
+	// and after restarts, or on the next leader, the checkpoints being
+	// replicated in the key space
+	kv.Put([]byte("/a/3"), []byte("v"), lease.NoLease)
+	sink = &recordingSink{}
+	f, err = New(lg, s, Config{Name: "test", Prefixes: []string{"/a/"}, Sink: sink})
+	require.NoError(t, err)
+	f.Run()
+	defer f.Stop()
+	require.Eventually(t, func() bool { return len(sink.published()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"PUT /a/3"}, sink.published())
+}
+
+func TestChangeFeedSkipsCheckpoints(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer kv.Close()
+	s := &fakeServer{kv: kv, leader: 1, changed: make(chan struct{})}
+
+	// the feed of the whole key space does not publish its own checkpoints
+	sink := &recordingSink{}
+	f, err := New(lg, s, Config{Name: "all", Prefixes: []string{""}, Sink: sink})
+	require.NoError(t, err)
+	f.checkpointInterval = 10 * time.Millisecond
+	kv.Put(checkpointKey("all", ""), []byte(strconv.FormatInt(kv.Rev(), 10)), lease.NoLease)
+	f.Run()
+	defer f.Stop()
+
+	rev := kv.Put([]byte("/a"), []byte("v"), lease.NoLease)
+	require.Eventually(t, func() bool { return s.checkpoint("all", "") == rev }, 5*time.Second, 10*time.Millisecond)
+	kv.Put([]byte("/b"), []byte("v"), lease.NoLease)
+	require.Eventually(t, func() bool { return len(sink.published()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"PUT /a", "PUT /b"}, sink.published())
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got WebhookBatch
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, nil)
+	defer sink.Close()
+	b := Batch{Feed: "test", Prefix: "/a/", Events: []mvccpb.Event{
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("/a/1"), Value: []byte("v"), CreateRevision: 2, ModRevision: 2, Version: 1}},
+		{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("/a/1"), ModRevision: 3}},
+	}}
+	require.NoError(t, sink.Publish(context.Background(), b))
+	assert.Equal(t, WebhookBatch{Feed: "test", Prefix: "/a/", Events: []WebhookEvent{
+		{Type: "PUT", Key: []byte("/a/1"), Value: []byte("v"), CreateRevision: 2, ModRevision: 2, Version: 1},
+		{Type: "DELETE", Key: []byte("/a/1"), ModRevision: 3},
+	}}, got)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, sink.Publish(context.Background(), b))
+}
+
+func TestPrefixRange(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		key, end []byte
+	}{
+		{"", []byte{0}, []byte{}},
+		{"/a/", []byte("/a/"), []byte("/a0")},
+		{"a\xff", []byte("a\xff"), []byte("b")},
+		{"\xff\xff", []byte("\xff\xff"), []byte{}},
+	}
+	for _, tt := range tests {
+		key, end := prefixRange(tt.prefix)
+		assert.Equal(t, tt.key, key, "prefix %q", tt.prefix)
+		assert.Equal(t, tt.end, end, "prefix %q", tt.prefix)
+	}
+}
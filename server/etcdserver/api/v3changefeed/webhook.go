@@ -0,0 +1,107 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3changefeed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookEvent is the JSON encoding of an event posted by the webhook sink.
+// Keys and values are base64 encoded.
+type WebhookEvent struct {
+	Type           string `json:"type"`
+	Key            []byte `json:"key"`
+	Value          []byte `json:"value,omitempty"`
+	Lease          int64  `json:"lease,omitempty"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+}
+
+// WebhookBatch is the JSON body of the requests of the webhook sink.
+type WebhookBatch struct {
+	Feed   string         `json:"feed"`
+	Prefix string         `json:"prefix"`
+	Events []WebhookEvent `json:"events"`
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a sink posting every batch as a JSON encoded
+// WebhookBatch to the given URL. A batch is accepted once the endpoint
+// replies with a 2xx status code. If client is nil, a client with a 10
+// seconds timeout is used.
+func NewWebhookSink(url string, client *http.Client) Sink {
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	return &webhookSink{url: url, client: client}
+}
+
+// NewWebhookBatch returns the JSON encodable form of the batch.
+func NewWebhookBatch(b Batch) WebhookBatch {
+	wb := WebhookBatch{Feed: b.Feed, Prefix: b.Prefix, Events: make([]WebhookEvent, 0, len(b.Events))}
+	for _, ev := range b.Events {
+		wb.Events = append(wb.Events, WebhookEvent{
+			Type:           ev.Type.String(),
+			Key:            ev.Kv.Key,
+			Value:          ev.Kv.Value,
+			Lease:          ev.Kv.Lease,
+			CreateRevision: ev.Kv.CreateRevision,
+			ModRevision:    ev.Kv.ModRevision,
+			Version:        ev.Kv.Version,
+		})
+	}
+	return wb
+}
+
+func (w *webhookSink) Publish(ctx context.Context, b Batch) error {
+	body, err := json.Marshal(NewWebhookBatch(b))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %q replied with status %q", w.url, resp.Status)
+	}
+	return nil
+}
+
+func (w *webhookSink) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+
+	"go.uber.org/zap"
+)
+
+// aligned rounds the compactions down to a multiple of the alignment.
+//
+// Compactions are proposed through raft by the leader only, so every member
+// already compacts at the same revisions. Aligning them additionally makes
+// the compaction revisions independent of which member was the leader and
+// when its compactor ticked, so the hashes of members and of clusters
+// running with the same alignment can be compared at well known revisions.
+type aligned struct {
+	lg        *zap.Logger
+	alignment int64
+	c         Compactable
+}
+
+func newAligned(lg *zap.Logger, alignment int64, c Compactable) *aligned {
+	return &aligned{lg: lg, alignment: alignment, c: c}
+}
+
+func (a *aligned) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	rev := r.Revision - r.Revision%a.alignment
+	if rev <= 0 {
+		// nothing to compact below the first boundary; report it as
+		// compacted so that the compactor moves on.
+		return nil, mvcc.ErrCompacted
+	}
+	if rev != r.Revision {
+		a.lg.Debug(
+			"aligned auto compaction revision",
+			zap.Int64("requested-revision", r.Revision),
+			zap.Int64("revision", rev),
+			zap.Int64("alignment", a.alignment),
+		)
+	}
+	return a.c.Compact(ctx, &pb.CompactionRequest{Revision: rev, Physical: r.Physical})
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAlignedCompact(t *testing.T) {
+	tests := []struct {
+		rev     int64
+		wrev    int64
+		wCalled bool
+	}{
+		{rev: 50, wCalled: false},
+		{rev: 100, wrev: 100, wCalled: true},
+		{rev: 199, wrev: 100, wCalled: true},
+		{rev: 1234, wrev: 1200, wCalled: true},
+	}
+	for _, tt := range tests {
+		compactable := &fakeCompactable{&testutil.RecorderBuffered{}}
+		a := newAligned(zaptest.NewLogger(t), 100, compactable)
+
+		_, err := a.Compact(context.TODO(), &pb.CompactionRequest{Revision: tt.rev})
+		if !tt.wCalled {
+			if err != mvcc.ErrCompacted {
+				t.Errorf("rev %d: err = %v, want %v", tt.rev, err, mvcc.ErrCompacted)
+			}
+			if n := len(compactable.Action()); n != 0 {
+				t.Errorf("rev %d: got %d compactions, want none", tt.rev, n)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		acts := compactable.Action()
+		if len(acts) != 1 {
+			t.Fatalf("rev %d: got %d compactions, want 1", tt.rev, len(acts))
+		}
+		if !reflect.DeepEqual(acts[0].Params[0], &pb.CompactionRequest{Revision: tt.wrev}) {
+			t.Errorf("rev %d: compact request = %v, want %v", tt.rev, acts[0].Params[0], &pb.CompactionRequest{Revision: tt.wrev})
+		}
+	}
+}
+
+func TestRevisionAligned(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	rg := &fakeRevGetter{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond), 0}
+	compactable := &fakeCompactable{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond)}
+	tb := newRevision(zaptest.NewLogger(t), fc, 10, rg, newAligned(zaptest.NewLogger(t), 100, compactable))
+
+	tb.Run()
+	defer tb.Stop()
+
+	rg.SetRev(1244)
+	// wait for the compactor to wait for the interval before advancing it
+	fc.BlockUntil(1)
+	fc.Advance(revInterval)
+	rg.Wait(1)
+	acts, err := compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the compactor wants to keep 10 revisions from 1245, compacting at 1235,
+	// which is aligned down to 1200.
+	wreq := &pb.CompactionRequest{Revision: 1200}
+	if !reflect.DeepEqual(acts[0].Params[0], wreq) {
+		t.Errorf("compact request = %v, want %v", acts[0].Params[0], wreq)
+	}
+}
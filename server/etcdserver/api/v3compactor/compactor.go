@@ -28,6 +28,7 @@ import (
 const (
 	ModePeriodic = "periodic"
 	ModeRevision = "revision"
+	ModeHybrid   = "hybrid"
 )
 
 // Compactor purges old log from the storage periodically.
@@ -52,21 +53,36 @@ type RevGetter interface {
 }
 
 // New returns a new Compactor based on given "mode".
+// "maxRevisions" is only used by the hybrid mode, along with "retention".
+// If "alignment" is positive, compactions are rounded down to a multiple of it.
 func New(
 	lg *zap.Logger,
 	mode string,
 	retention time.Duration,
+	maxRevisions int64,
+	alignment int64,
 	rg RevGetter,
 	c Compactable,
 ) (Compactor, error) {
 	if lg == nil {
 		lg = zap.NewNop()
 	}
+	if alignment < 0 {
+		return nil, fmt.Errorf("compaction revision alignment must not be negative, got %d", alignment)
+	}
+	if alignment > 0 {
+		c = newAligned(lg, alignment, c)
+	}
 	switch mode {
 	case ModePeriodic:
 		return newPeriodic(lg, clockwork.NewRealClock(), retention, rg, c), nil
 	case ModeRevision:
 		return newRevision(lg, clockwork.NewRealClock(), int64(retention), rg, c), nil
+	case ModeHybrid:
+		if maxRevisions <= 0 {
+			return nil, fmt.Errorf("compaction mode %s requires a positive revision limit, got %d", mode, maxRevisions)
+		}
+		return newHybrid(lg, clockwork.NewRealClock(), retention, maxRevisions, rg, c), nil
 	default:
 		return nil, fmt.Errorf("unsupported compaction mode %s", mode)
 	}
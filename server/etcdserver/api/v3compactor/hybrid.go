@@ -0,0 +1,167 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+// Hybrid compacts the log by purging revisions older than the configured
+// retention time, as Periodic does, while keeping at most the configured
+// number of revisions, as Revision does. When the writes burst so that the
+// retention time holds more revisions than that, the revision limit wins,
+// bounding the history kept.
+type Hybrid struct {
+	lg           *zap.Logger
+	clock        clockwork.Clock
+	period       time.Duration
+	maxRevisions int64
+
+	rg RevGetter
+	c  Compactable
+
+	revs   []int64
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu protects paused
+	mu     sync.RWMutex
+	paused bool
+}
+
+// newHybrid creates a new instance of Hybrid compactor that purges the log
+// older than h Duration, or more than maxRevisions behind the current revision.
+func newHybrid(lg *zap.Logger, clock clockwork.Clock, h time.Duration, maxRevisions int64, rg RevGetter, c Compactable) *Hybrid {
+	hc := &Hybrid{
+		lg:           lg,
+		clock:        clock,
+		period:       h,
+		maxRevisions: maxRevisions,
+		rg:           rg,
+		c:            c,
+	}
+	// revs won't be longer than the retentions.
+	hc.revs = make([]int64, 0, hc.getRetentions())
+	hc.ctx, hc.cancel = context.WithCancel(context.Background())
+	return hc
+}
+
+// Run runs hybrid compactor. The revisions are recorded every 1/10 of the
+// retention time, up to 6 minutes, as Periodic does; compaction is checked
+// at the same interval.
+func (hc *Hybrid) Run() {
+	retryInterval := hc.getRetryInterval()
+	retentions := hc.getRetentions()
+
+	go func() {
+		lastRevision := int64(0)
+		for {
+			rev := hc.rg.Rev()
+			hc.revs = append(hc.revs, rev)
+			if len(hc.revs) > retentions {
+				hc.revs = hc.revs[1:] // hc.revs[0] is always the rev at hc.period ago
+			}
+
+			select {
+			case <-hc.ctx.Done():
+				return
+			case <-hc.clock.After(retryInterval):
+				hc.mu.RLock()
+				p := hc.paused
+				hc.mu.RUnlock()
+				if p {
+					continue
+				}
+			}
+
+			// purge the revisions beyond the revision limit, and the ones
+			// older than the retention time once it has elapsed
+			compactRev := rev - hc.maxRevisions
+			if len(hc.revs) == retentions && hc.revs[0] > compactRev {
+				compactRev = hc.revs[0]
+			}
+			if compactRev <= 0 || compactRev <= lastRevision {
+				continue
+			}
+
+			hc.lg.Info(
+				"starting auto hybrid compaction",
+				zap.Int64("revision", compactRev),
+				zap.Duration("compact-period", hc.period),
+				zap.Int64("max-revisions", hc.maxRevisions),
+			)
+			startTime := hc.clock.Now()
+			_, err := hc.c.Compact(hc.ctx, &pb.CompactionRequest{Revision: compactRev})
+			if err == nil || err == mvcc.ErrCompacted {
+				hc.lg.Info(
+					"completed auto hybrid compaction",
+					zap.Int64("revision", compactRev),
+					zap.Duration("compact-period", hc.period),
+					zap.Int64("max-revisions", hc.maxRevisions),
+					zap.Duration("took", hc.clock.Now().Sub(startTime)),
+				)
+				lastRevision = compactRev
+			} else {
+				hc.lg.Warn(
+					"failed auto hybrid compaction",
+					zap.Int64("revision", compactRev),
+					zap.Duration("compact-period", hc.period),
+					zap.Int64("max-revisions", hc.maxRevisions),
+					zap.Duration("retry-interval", retryInterval),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+func (hc *Hybrid) getRetentions() int {
+	return int(hc.period/hc.getRetryInterval()) + 1
+}
+
+func (hc *Hybrid) getRetryInterval() time.Duration {
+	itv := hc.period
+	if itv > time.Hour {
+		itv = time.Hour
+	}
+	return itv / retryDivisor
+}
+
+// Stop stops hybrid compactor.
+func (hc *Hybrid) Stop() {
+	hc.cancel()
+}
+
+// Pause pauses hybrid compactor.
+func (hc *Hybrid) Pause() {
+	hc.mu.Lock()
+	hc.paused = true
+	hc.mu.Unlock()
+}
+
+// Resume resumes hybrid compactor.
+func (hc *Hybrid) Resume() {
+	hc.mu.Lock()
+	hc.paused = false
+	hc.mu.Unlock()
+}
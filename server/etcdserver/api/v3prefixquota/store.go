@@ -0,0 +1,155 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3prefixquota manages the storage quotas of key prefixes in etcd.
+package v3prefixquota
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+type PrefixQuotaBackend interface {
+	CreatePrefixQuotaBucket()
+	MustPutPrefixQuota(q *pb.PrefixQuota)
+	MustDeletePrefixQuota(prefix []byte)
+	GetAllPrefixQuotas() ([]*pb.PrefixQuota, error)
+	ForceCommit()
+}
+
+// PrefixQuotaStore persists the prefix quotas to the backend. It only keeps
+// their limits; the usage under a prefix is read from the key index when
+// needed.
+type PrefixQuotaStore struct {
+	lg     *zap.Logger
+	mu     sync.RWMutex
+	quotas map[string]*pb.PrefixQuota
+
+	be PrefixQuotaBackend
+}
+
+func NewPrefixQuotaStore(lg *zap.Logger, be PrefixQuotaBackend) (*PrefixQuotaStore, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	ret := &PrefixQuotaStore{lg: lg, quotas: make(map[string]*pb.PrefixQuota), be: be}
+	err := ret.restore()
+	return ret, err
+}
+
+// Set sets the limits of the quota of the prefix, replacing the previous ones.
+func (s *PrefixQuotaStore) Set(prefix []byte, maxBytes, maxKeys int64) *pb.PrefixQuota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := &pb.PrefixQuota{Prefix: append([]byte(nil), prefix...), MaxBytes: maxBytes, MaxKeys: maxKeys}
+	s.quotas[string(prefix)] = q
+	s.be.MustPutPrefixQuota(q)
+	return copyQuota(q)
+}
+
+// Delete removes the quota of the prefix. It returns nil if there is none.
+func (s *PrefixQuotaStore) Delete(prefix []byte) *pb.PrefixQuota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.quotas[string(prefix)]
+	if q == nil {
+		return nil
+	}
+	delete(s.quotas, string(prefix))
+	s.be.MustDeletePrefixQuota(prefix)
+	return copyQuota(q)
+}
+
+// Get returns the quota of the prefix, or all the quotas sorted by prefix if
+// the prefix is empty.
+func (s *PrefixQuotaStore) Get(prefix []byte) (ret []*pb.PrefixQuota) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(prefix) != 0 {
+		if q := s.quotas[string(prefix)]; q != nil {
+			ret = append(ret, copyQuota(q))
+		}
+		return ret
+	}
+	for _, q := range s.quotas {
+		ret = append(ret, copyQuota(q))
+	}
+	sortQuotas(ret)
+	return ret
+}
+
+// Matching returns the quotas whose prefix is a prefix of the key, sorted by
+// prefix.
+func (s *PrefixQuotaStore) Matching(key []byte) (ret []*pb.PrefixQuota) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, q := range s.quotas {
+		if bytes.HasPrefix(key, q.Prefix) {
+			ret = append(ret, copyQuota(q))
+		}
+	}
+	sortQuotas(ret)
+	return ret
+}
+
+// Len returns the number of quotas.
+func (s *PrefixQuotaStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.quotas)
+}
+
+func (s *PrefixQuotaStore) restore() error {
+	s.be.CreatePrefixQuotaBucket()
+	qs, err := s.be.GetAllPrefixQuotas()
+	if err != nil {
+		return err
+	}
+	for _, q := range qs {
+		s.quotas[string(q.Prefix)] = q
+	}
+	s.be.ForceCommit()
+	return err
+}
+
+// PrefixEnd returns the end of the range covering all the keys with the
+// prefix.
+func PrefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// next prefix does not exist (e.g., 0xffff);
+	// default to WithFromKey policy
+	return []byte{0}
+}
+
+func copyQuota(q *pb.PrefixQuota) *pb.PrefixQuota {
+	return &pb.PrefixQuota{Prefix: q.Prefix, MaxBytes: q.MaxBytes, MaxKeys: q.MaxKeys}
+}
+
+func sortQuotas(qs []*pb.PrefixQuota) {
+	sort.Slice(qs, func(i, j int) bool { return bytes.Compare(qs[i].Prefix, qs[j].Prefix) < 0 })
+}
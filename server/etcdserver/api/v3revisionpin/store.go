@@ -0,0 +1,184 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3revisionpin manages the revisions pinned against compaction in etcd.
+package v3revisionpin
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+// SnapshotRangeOwnerPrefix prefixes the session of the revision pins of the
+// SnapshotRange sessions. These pins are taken on behalf of any user allowed
+// to read the range, while the other pins are taken by administrators.
+const SnapshotRangeOwnerPrefix = "snapshot-range/"
+
+type RevisionPinBackend interface {
+	CreateRevisionPinBucket()
+	MustPutRevisionPin(p *pb.RevisionPin)
+	MustDeleteRevisionPin(owner string)
+	GetAllRevisionPins() ([]*pb.RevisionPin, error)
+	ForceCommit()
+}
+
+type pin struct {
+	*pb.RevisionPin
+	// expiry is the local time the pin expires at. As for leases, it is not
+	// replicated: a pin gets its full TTL again once restored.
+	expiry time.Time
+}
+
+// RevisionPinStore keeps the revisions pinned by their owners, at most one
+// per owner, and persists them to the backend.
+type RevisionPinStore struct {
+	lg   *zap.Logger
+	mu   sync.RWMutex
+	pins map[string]*pin
+
+	be RevisionPinBackend
+}
+
+func NewRevisionPinStore(lg *zap.Logger, be RevisionPinBackend) (*RevisionPinStore, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	ret := &RevisionPinStore{lg: lg, pins: make(map[string]*pin), be: be}
+	err := ret.restore()
+	return ret, err
+}
+
+// Pin pins the revision for the owner for ttl seconds, replacing the
+// previous pin of the owner and bumping its version.
+func (s *RevisionPinStore) Pin(owner string, rev, ttl int64) *pb.RevisionPin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var version int64 = 1
+	if prev := s.pins[owner]; prev != nil {
+		version = prev.Version + 1
+	}
+	p := &pb.RevisionPin{Owner: owner, Revision: rev, TTL: ttl, Version: version}
+	s.pins[owner] = &pin{RevisionPin: p, expiry: expiry(ttl)}
+	s.be.MustPutRevisionPin(p)
+	return copyPin(p)
+}
+
+// Unpin removes the pin of the owner if it is at the version, or whatever
+// its version if version is 0. It returns nil if no pin was removed.
+func (s *RevisionPinStore) Unpin(owner string, version int64) *pb.RevisionPin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.pins[owner]
+	if p == nil || (version != 0 && p.Version != version) {
+		return nil
+	}
+	delete(s.pins, owner)
+	s.be.MustDeleteRevisionPin(owner)
+	return copyPin(p.RevisionPin)
+}
+
+// Get returns the pin of the owner, or all the pins sorted by revision if
+// the owner is empty.
+func (s *RevisionPinStore) Get(owner string) (ret []*pb.RevisionPin) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if owner != "" {
+		if p := s.pins[owner]; p != nil {
+			ret = append(ret, copyPin(p.RevisionPin))
+		}
+		return ret
+	}
+	for _, p := range s.pins {
+		ret = append(ret, copyPin(p.RevisionPin))
+	}
+	sortPins(ret)
+	return ret
+}
+
+// Oldest returns the oldest pinned revision, or 0 if there is no pin.
+func (s *RevisionPinStore) Oldest() (rev int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.pins {
+		if rev == 0 || p.Revision < rev {
+			rev = p.Revision
+		}
+	}
+	return rev
+}
+
+// Expired returns up to limit of the pins whose TTL elapsed, sorted by
+// revision.
+func (s *RevisionPinStore) Expired(limit int) (ret []*pb.RevisionPin) {
+	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.pins {
+		if now.After(p.expiry) {
+			ret = append(ret, copyPin(p.RevisionPin))
+		}
+	}
+	sortPins(ret)
+	if len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret
+}
+
+// Recover replaces the pins with the ones persisted to the backend, after it
+// was restored from a snapshot.
+func (s *RevisionPinStore) Recover(be RevisionPinBackend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.be = be
+	s.pins = make(map[string]*pin)
+	return s.restore()
+}
+
+func (s *RevisionPinStore) restore() error {
+	s.be.CreateRevisionPinBucket()
+	ps, err := s.be.GetAllRevisionPins()
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		s.pins[p.Owner] = &pin{RevisionPin: p, expiry: expiry(p.TTL)}
+	}
+	s.be.ForceCommit()
+	return err
+}
+
+func expiry(ttl int64) time.Time {
+	return time.Now().Add(time.Duration(ttl) * time.Second)
+}
+
+func copyPin(p *pb.RevisionPin) *pb.RevisionPin {
+	return &pb.RevisionPin{Owner: p.Owner, Revision: p.Revision, TTL: p.TTL, Version: p.Version}
+}
+
+func sortPins(ps []*pb.RevisionPin) {
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].Revision != ps[j].Revision {
+			return ps[i].Revision < ps[j].Revision
+		}
+		return ps[i].Owner < ps[j].Owner
+	})
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+)
+
+// auditLog records the requests of the clients, with their user, method, key
+// ranges, result code and latency, to the audit logger of the server.
+type auditLog struct {
+	s      *etcdserver.EtcdServer
+	lg     *zap.Logger
+	filter auditFilter
+}
+
+func newAuditLog(s *etcdserver.EtcdServer) *auditLog {
+	return &auditLog{
+		s:      s,
+		lg:     s.Cfg.AuditLogger,
+		filter: newAuditFilter(s.Cfg.AuditLogIncludePrefixes, s.Cfg.AuditLogExcludePrefixes),
+	}
+}
+
+func newAuditUnaryInterceptor(al *auditLog) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+
+		ranges := auditRequestRanges(req)
+		if !al.filter.match(ranges) {
+			return resp, err
+		}
+		user := al.user(ctx)
+		if r, ok := req.(*pb.AuthenticateRequest); ok {
+			user = r.Name
+		}
+		al.lg.Info(
+			"request",
+			zap.String("user", user),
+			zap.String("remote", auditRemote(ctx)),
+			zap.String("method", info.FullMethod),
+			zap.Array("key-ranges", ranges),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("took", time.Since(startTime)),
+		)
+		return resp, err
+	}
+}
+
+func newAuditStreamInterceptor(al *auditLog) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
+		ctx := ss.Context()
+		as := &auditServerStream{
+			ServerStream: ss,
+			al:           al,
+			user:         al.user(ctx),
+			remote:       auditRemote(ctx),
+			method:       info.FullMethod,
+		}
+		err := handler(srv, as)
+
+		al.lg.Info(
+			"stream",
+			zap.String("user", as.user),
+			zap.String("remote", as.remote),
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("took", time.Since(startTime)),
+		)
+		return err
+	}
+}
+
+// auditServerStream records the watches created on a watch stream, as the
+// requests of the stream.
+type auditServerStream struct {
+	grpc.ServerStream
+	al     *auditLog
+	user   string
+	remote string
+	method string
+}
+
+func (ss *auditServerStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	wr, ok := m.(*pb.WatchRequest)
+	if !ok || wr.GetCreateRequest() == nil {
+		return nil
+	}
+	cr := wr.GetCreateRequest()
+	ranges := auditKeyRanges{{key: cr.Key, end: cr.RangeEnd}}
+	for _, r := range cr.Ranges {
+		ranges = append(ranges, auditKeyRange{key: r.Key, end: r.RangeEnd})
+	}
+	if ss.al.filter.match(ranges) {
+		ss.al.lg.Info(
+			"watch create",
+			zap.String("user", ss.user),
+			zap.String("remote", ss.remote),
+			zap.String("method", ss.method),
+			zap.Array("key-ranges", ranges),
+			zap.Int64("start-revision", cr.StartRevision),
+		)
+	}
+	return nil
+}
+
+// user returns the user authenticated by the credentials of the context, if
+// any. The credentials failing authentication are reported by the code of
+// the request.
+func (al *auditLog) user(ctx context.Context) string {
+	ai, err := al.s.AuthInfoFromCtx(ctx)
+	if err != nil || ai == nil {
+		return ""
+	}
+	return ai.Username
+}
+
+func auditRemote(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+type auditKeyRange struct {
+	key []byte
+	// end is the end of the range, the range being the single key if empty
+	// and all the keys from key if '\0'.
+	end []byte
+}
+
+func (r auditKeyRange) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("key", string(r.key))
+	if len(r.end) > 0 {
+		enc.AddString("range-end", string(r.end))
+	}
+	return nil
+}
+
+type auditKeyRanges []auditKeyRange
+
+func (rs auditKeyRanges) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, r := range rs {
+		if err := enc.AppendObject(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditRequestRanges returns the key ranges touched by the request, nil for
+// the requests on no keys.
+func auditRequestRanges(req interface{}) auditKeyRanges {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		return auditKeyRanges{{key: r.Key, end: r.RangeEnd}}
+	case *pb.IndexRangeRequest:
+		return auditKeyRanges{{key: r.Prefix, end: v3prefixquota.PrefixEnd(r.Prefix)}}
+	case *pb.PutRequest:
+		return auditKeyRanges{{key: r.Key}}
+	case *pb.DeleteRangeRequest:
+		return auditKeyRanges{{key: r.Key, end: r.RangeEnd}}
+	case *pb.TxnRequest:
+		return auditTxnRanges(nil, r)
+	case *pb.SnapshotRangeRequest:
+		return auditKeyRanges{{key: r.Key, end: r.RangeEnd}}
+	}
+	return nil
+}
+
+func auditTxnRanges(ranges auditKeyRanges, r *pb.TxnRequest) auditKeyRanges {
+	for _, c := range r.Compare {
+		ranges = append(ranges, auditKeyRange{key: c.Key, end: c.RangeEnd})
+	}
+	for _, branch := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range branch {
+			switch tv := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				ranges = append(ranges, auditKeyRange{key: tv.RequestRange.Key, end: tv.RequestRange.RangeEnd})
+			case *pb.RequestOp_RequestPut:
+				ranges = append(ranges, auditKeyRange{key: tv.RequestPut.Key})
+			case *pb.RequestOp_RequestDeleteRange:
+				ranges = append(ranges, auditKeyRange{key: tv.RequestDeleteRange.Key, end: tv.RequestDeleteRange.RangeEnd})
+			case *pb.RequestOp_RequestTxn:
+				ranges = auditTxnRanges(ranges, tv.RequestTxn)
+			}
+		}
+	}
+	return ranges
+}
+
+// auditFilter selects the requests recorded by their key ranges. A request is
+// recorded if one of its ranges overlaps an included prefix, or any key
+// without included prefixes, and does not lie within an excluded prefix. The
+// requests on no keys are always recorded.
+type auditFilter struct {
+	include [][]byte
+	exclude [][]byte
+}
+
+func newAuditFilter(include, exclude []string) auditFilter {
+	var f auditFilter
+	for _, p := range include {
+		f.include = append(f.include, []byte(p))
+	}
+	for _, p := range exclude {
+		f.exclude = append(f.exclude, []byte(p))
+	}
+	return f
+}
+
+func (f auditFilter) match(ranges auditKeyRanges) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if f.included(r) && !f.excluded(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f auditFilter) included(r auditKeyRange) bool {
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if bytes.HasPrefix(r.key, p) {
+			return true
+		}
+		// the range starts before the prefix and ends after its first key
+		if len(r.end) > 0 && bytes.Compare(r.key, p) < 0 &&
+			(bytes.Equal(r.end, []byte{0}) || bytes.Compare(r.end, p) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f auditFilter) excluded(r auditKeyRange) bool {
+	for _, p := range f.exclude {
+		if !bytes.HasPrefix(r.key, p) {
+			continue
+		}
+		if len(r.end) == 0 {
+			return true
+		}
+		if bytes.Equal(r.end, []byte{0}) {
+			continue
+		}
+		if pend := v3prefixquota.PrefixEnd(p); bytes.Equal(pend, []byte{0}) || bytes.Compare(r.end, pend) <= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestAuditRequestRanges(t *testing.T) {
+	txn := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("b")}}},
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("c"), RangeEnd: []byte("d")}}}},
+			}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestRange{RequestRange: &pb.RangeRequest{Key: []byte("e")}}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		req  interface{}
+		want auditKeyRanges
+	}{
+		{"range", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("b")}, auditKeyRanges{{key: []byte("a"), end: []byte("b")}}},
+		{"index range", &pb.IndexRangeRequest{Prefix: []byte("a/")}, auditKeyRanges{{key: []byte("a/"), end: []byte("a0")}}},
+		{"put", &pb.PutRequest{Key: []byte("a")}, auditKeyRanges{{key: []byte("a")}}},
+		{"delete range", &pb.DeleteRangeRequest{Key: []byte("a"), RangeEnd: []byte{0}}, auditKeyRanges{{key: []byte("a"), end: []byte{0}}}},
+		{"txn", txn, auditKeyRanges{{key: []byte("a")}, {key: []byte("b")}, {key: []byte("c"), end: []byte("d")}, {key: []byte("e")}}},
+		{"no keys", &pb.LeaseGrantRequest{TTL: 5}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, auditRequestRanges(tt.req))
+		})
+	}
+}
+
+func TestAuditFilter(t *testing.T) {
+	key := func(k string) auditKeyRanges { return auditKeyRanges{{key: []byte(k)}} }
+	rng := func(k, end string) auditKeyRanges { return auditKeyRanges{{key: []byte(k), end: []byte(end)}} }
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		ranges           auditKeyRanges
+		want             bool
+	}{
+		{"no filter", nil, nil, key("a"), true},
+		{"no keys", []string{"/secrets/"}, nil, nil, true},
+		{"included key", []string{"/secrets/"}, nil, key("/secrets/a"), true},
+		{"not included key", []string{"/secrets/"}, nil, key("/config/a"), false},
+		{"range overlapping included prefix", []string{"/secrets/"}, nil, rng("/", "/t"), true},
+		{"all keys overlapping included prefix", []string{"/secrets/"}, nil, rng("\x00", "\x00"), true},
+		{"range before included prefix", []string{"/secrets/"}, nil, rng("/a", "/b"), false},
+		{"excluded key", nil, []string{"/events/"}, key("/events/a"), false},
+		{"excluded range", nil, []string{"/events/"}, rng("/events/a", "/events0"), false},
+		{"range exceeding excluded prefix", nil, []string{"/events/"}, rng("/events/a", "/f"), true},
+		{"range from excluded key", nil, []string{"/events/"}, rng("/events/a", "\x00"), true},
+		{"included and excluded key", []string{"/"}, []string{"/events/"}, key("/events/a"), false},
+		{"one range recorded", nil, []string{"/events/"}, auditKeyRanges{{key: []byte("/events/a")}, {key: []byte("/pods/a")}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuditFilter(tt.include, tt.exclude)
+			assert.Equal(t, tt.want, f.match(tt.ranges))
+		})
+	}
+}
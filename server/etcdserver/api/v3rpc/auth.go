@@ -102,6 +102,46 @@ func (as *AuthServer) RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRe
 	return resp, nil
 }
 
+func (as *AuthServer) RoleSetMaxLeaseTTL(ctx context.Context, r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	resp, err := as.authenticator.RoleSetMaxLeaseTTL(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) RoleSetQuota(ctx context.Context, r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error) {
+	resp, err := as.authenticator.RoleSetQuota(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthSetBcryptCost(ctx context.Context, r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error) {
+	resp, err := as.authenticator.AuthSetBcryptCost(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthTokenList(ctx context.Context, r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error) {
+	resp, err := as.authenticator.AuthTokenList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthTokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error) {
+	resp, err := as.authenticator.AuthTokenRevoke(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
 func (as *AuthServer) RoleGrantPermission(ctx context.Context, r *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error) {
 	resp, err := as.authenticator.RoleGrantPermission(ctx, r)
 	if err != nil {
@@ -185,3 +225,14 @@ func (aa *AuthAdmin) isPermitted(ctx context.Context) error {
 
 	return aa.ag.AuthStore().IsAdminPermitted(authInfo)
 }
+
+// isReadPermitted verifies the user may call the read-only maintenance RPCs.
+// Users with "root" or "maintenance-readonly" role are permitted.
+func (aa *AuthAdmin) isReadPermitted(ctx context.Context) error {
+	authInfo, err := aa.ag.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	return aa.ag.AuthStore().IsMaintenanceReadPermitted(authInfo)
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/txn"
+)
+
+// newAuthzUnaryInterceptor consults the external authorizer on the requests
+// on keys of the authenticated users, once they are permitted by the roles of
+// their user. The authorizer is consulted by the member serving the request
+// before it is proposed, so that its decision does not depend on the member
+// applying the request.
+func newAuthzUnaryInterceptor(s *etcdserver.EtcdServer, c *v3authz.Checker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ranges := auditRequestRanges(req)
+		if ranges == nil || !s.AuthStore().IsAuthEnabled() {
+			return handler(ctx, req)
+		}
+		ai, err := s.AuthInfoFromCtx(ctx)
+		if err != nil || ai == nil {
+			// the requests failing authentication are rejected by their handlers
+			return handler(ctx, req)
+		}
+		if checkLocalPermission(s.AuthStore(), ai, req) != nil {
+			// the requests denied by the roles are rejected by their handlers
+			return handler(ctx, req)
+		}
+
+		if err := authorize(ctx, c, ai.Username, info.FullMethod, ranges); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newAuthzStreamInterceptor consults the external authorizer on the streams
+// of the authenticated users: on each watch created on a watch stream, on the
+// first keep alive of a lease keep alive stream, and on a snapshot, as a read
+// of all the keys. A watch stream multiplexes the watches of a client and the
+// interceptor cannot cancel one of them without racing the responses of the
+// watch server, so a denied watch fails its whole stream.
+func newAuthzStreamInterceptor(s *etcdserver.EtcdServer, c *v3authz.Checker) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		switch info.FullMethod {
+		case "/etcdserverpb.Watch/Watch", "/etcdserverpb.Lease/LeaseKeepAlive", "/etcdserverpb.Maintenance/Snapshot":
+			return handler(srv, &authzServerStream{ServerStream: ss, s: s, c: c, method: info.FullMethod})
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authzServerStream consults the external authorizer on the requests received
+// on a stream.
+type authzServerStream struct {
+	grpc.ServerStream
+	s      *etcdserver.EtcdServer
+	c      *v3authz.Checker
+	method string
+	// keptAlive is set once a keep alive of the stream is authorized.
+	keptAlive bool
+}
+
+func (ss *authzServerStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !ss.s.AuthStore().IsAuthEnabled() {
+		return nil
+	}
+	ctx := ss.Context()
+	ai, err := ss.s.AuthInfoFromCtx(ctx)
+	if err != nil || ai == nil {
+		// the requests failing authentication are rejected by their handlers
+		return nil
+	}
+
+	var ranges auditKeyRanges
+	switch r := m.(type) {
+	case *pb.WatchRequest:
+		cr := r.GetCreateRequest()
+		if cr == nil {
+			return nil
+		}
+		ranges = auditKeyRanges{{key: cr.Key, end: cr.RangeEnd}}
+		for _, kr := range cr.Ranges {
+			ranges = append(ranges, auditKeyRange{key: kr.Key, end: kr.RangeEnd})
+		}
+		for _, kr := range ranges {
+			if ss.s.AuthStore().IsWatchPermitted(ai, kr.key, kr.end) != nil {
+				// the watches denied by the roles are canceled by the watch server
+				return nil
+			}
+		}
+	case *pb.LeaseKeepAliveRequest:
+		// the keep alives are on no keys, the decision holds for the stream
+		if ss.keptAlive {
+			return nil
+		}
+	case *pb.SnapshotRequest:
+		if ss.s.AuthStore().IsAdminPermitted(ai) != nil {
+			// the snapshots denied by the roles are rejected by their handler
+			return nil
+		}
+		ranges = auditKeyRanges{{key: []byte{0}, end: []byte{0}}}
+	default:
+		return nil
+	}
+	if err := authorize(ctx, ss.c, ai.Username, ss.method, ranges); err != nil {
+		return err
+	}
+	if _, ok := m.(*pb.LeaseKeepAliveRequest); ok {
+		ss.keptAlive = true
+	}
+	return nil
+}
+
+// authorize returns the error of the decision of the external authorizer on
+// the request of user on ranges.
+func authorize(ctx context.Context, c *v3authz.Checker, user, method string, ranges auditKeyRanges) error {
+	r := v3authz.Request{User: user, Method: method}
+	for _, kr := range ranges {
+		r.Keys = append(r.Keys, v3authz.KeyRange{Key: kr.key, RangeEnd: kr.end})
+	}
+	switch c.Check(ctx, r) {
+	case v3authz.ErrDenied:
+		authorizerDecisions.WithLabelValues("denied").Inc()
+		return rpctypes.ErrGRPCAuthorizerDenied
+	case v3authz.ErrUnavailable:
+		authorizerDecisions.WithLabelValues("unavailable").Inc()
+		return rpctypes.ErrGRPCAuthorizerFailed
+	}
+	authorizerDecisions.WithLabelValues("allowed").Inc()
+	return nil
+}
+
+// checkLocalPermission checks the request on keys against the permissions of
+// the roles of the user, as its handler or applier does.
+func checkLocalPermission(as auth.AuthStore, ai *auth.AuthInfo, req interface{}) error {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		return txn.CheckRangeAuth(as, ai, r)
+	case *pb.IndexRangeRequest:
+		return as.IsRangePermitted(ai, r.Prefix, v3prefixquota.PrefixEnd(r.Prefix))
+	case *pb.PutRequest:
+		if r.PrevKv {
+			if err := as.IsRangePermitted(ai, r.Key, nil); err != nil {
+				return err
+			}
+		}
+		return as.IsPutPermitted(ai, r.Key)
+	case *pb.DeleteRangeRequest:
+		if r.PrevKv {
+			if err := as.IsRangePermitted(ai, r.Key, r.RangeEnd); err != nil {
+				return err
+			}
+		}
+		return as.IsDeleteRangePermitted(ai, r.Key, r.RangeEnd)
+	case *pb.TxnRequest:
+		return txn.CheckTxnAuth(as, ai, r)
+	case *pb.SnapshotRangeRequest:
+		return as.IsRangePermitted(ai, r.Key, r.RangeEnd)
+	}
+	return nil
+}
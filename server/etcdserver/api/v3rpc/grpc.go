@@ -42,25 +42,41 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 		bundle := credentials.NewBundle(credentials.Config{TLSConfig: tls})
 		opts = append(opts, grpc.Creds(bundle.TransportCredentials()))
 	}
+	rq := newRoleQuotas(s)
 	chainUnaryInterceptors := []grpc.UnaryServerInterceptor{
 		newLogUnaryInterceptor(s),
 		newUnaryInterceptor(s),
+		newRoleQuotaUnaryInterceptor(rq),
 		grpc_prometheus.UnaryServerInterceptor,
 	}
 	if interceptor != nil {
 		chainUnaryInterceptors = append(chainUnaryInterceptors, interceptor)
 	}
 
+	if s.Cfg.Authorizer != nil {
+		chainUnaryInterceptors = append(chainUnaryInterceptors, newAuthzUnaryInterceptor(s, s.Cfg.Authorizer))
+	}
+
 	chainStreamInterceptors := []grpc.StreamServerInterceptor{
 		newStreamInterceptor(s),
+		newRoleQuotaStreamInterceptor(rq),
 		grpc_prometheus.StreamServerInterceptor,
 	}
+	if s.Cfg.Authorizer != nil {
+		chainStreamInterceptors = append(chainStreamInterceptors, newAuthzStreamInterceptor(s, s.Cfg.Authorizer))
+	}
 
 	if s.Cfg.ExperimentalEnableDistributedTracing {
 		chainUnaryInterceptors = append(chainUnaryInterceptors, otelgrpc.UnaryServerInterceptor(s.Cfg.ExperimentalTracerOptions...))
 		chainStreamInterceptors = append(chainStreamInterceptors, otelgrpc.StreamServerInterceptor(s.Cfg.ExperimentalTracerOptions...))
 
 	}
+	if s.Cfg.AuditLogger != nil {
+		// the audit log records the requests rejected by the other interceptors too
+		al := newAuditLog(s)
+		chainUnaryInterceptors = append([]grpc.UnaryServerInterceptor{newAuditUnaryInterceptor(al)}, chainUnaryInterceptors...)
+		chainStreamInterceptors = append([]grpc.StreamServerInterceptor{newAuditStreamInterceptor(al)}, chainStreamInterceptors...)
+	}
 
 	opts = append(opts, grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(chainUnaryInterceptors...)))
 	opts = append(opts, grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(chainStreamInterceptors...)))
@@ -72,7 +88,8 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 	grpcServer := grpc.NewServer(append(opts, gopts...)...)
 
 	pb.RegisterKVServer(grpcServer, NewQuotaKVServer(s))
-	pb.RegisterWatchServer(grpcServer, NewWatchServer(s))
+	// the server codec sends the event encodings shared by the broker
+	pb.RegisterWatchServer(grpcServer, newWatchServer(s, newWatchBroker()))
 	pb.RegisterLeaseServer(grpcServer, NewQuotaLeaseServer(s))
 	pb.RegisterClusterServer(grpcServer, NewClusterServer(s))
 	pb.RegisterAuthServer(grpcServer, NewAuthServer(s))
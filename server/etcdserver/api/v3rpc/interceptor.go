@@ -50,10 +50,18 @@ func newUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
 			return nil, rpctypes.ErrGRPCNotCapable
 		}
 
-		if s.IsMemberExist(s.MemberId()) && s.IsLearner() && !isRPCSupportedForLearner(req) {
-			return nil, rpctypes.ErrGRPCNotSupportedForLearner
+		if s.IsMemberExist(s.MemberId()) && s.IsLearner() {
+			if s.IsObserver() {
+				if !isRPCSupportedForObserver(req) {
+					return nil, rpctypes.ErrGRPCNotSupportedForObserver
+				}
+			} else if !isRPCSupportedForLearner(req) {
+				return nil, rpctypes.ErrGRPCNotSupportedForLearner
+			}
 		}
 
+		defer s.BeginRequest()()
+
 		md, ok := metadata.FromIncomingContext(ctx)
 		if ok {
 			ver, vs := "unknown", md.Get(rpctypes.MetadataClientAPIVersionKey)
@@ -82,7 +90,7 @@ func newLogUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerIntercepto
 		resp, err := handler(ctx, req)
 		lg := s.Logger()
 		if lg != nil { // acquire stats if debug level is enabled or RequestInfo is expensive
-			defer logUnaryRequestStats(ctx, lg, s.Cfg.WarningUnaryRequestDuration, info, startTime, req, resp)
+			defer logUnaryRequestStats(ctx, lg, s.WarningUnaryRequestDuration(), info, startTime, req, resp)
 		}
 		return resp, err
 	}
@@ -219,8 +227,26 @@ func newStreamInterceptor(s *etcdserver.EtcdServer) grpc.StreamServerInterceptor
 			return rpctypes.ErrGRPCNotCapable
 		}
 
-		if s.IsMemberExist(s.MemberId()) && s.IsLearner() && info.FullMethod != snapshotMethod { // learner does not support stream RPC except Snapshot
-			return rpctypes.ErrGRPCNotSupportedForLearner
+		if s.IsMemberExist(s.MemberId()) && s.IsLearner() {
+			if s.IsObserver() {
+				if info.FullMethod != snapshotMethod && info.FullMethod != watchMethod { // observer does not support stream RPC except Snapshot and Watch
+					return rpctypes.ErrGRPCNotSupportedForObserver
+				}
+			} else if info.FullMethod != snapshotMethod { // learner does not support stream RPC except Snapshot
+				return rpctypes.ErrGRPCNotSupportedForLearner
+			}
+		}
+
+		select {
+		case <-s.DrainNotify():
+			return rpctypes.ErrGRPCDraining
+		default:
+		}
+		// the watch streams end once their buffered events are sent when
+		// draining, and the snapshots once sent; the other streams are
+		// closed with the server
+		if info.FullMethod == watchMethod || info.FullMethod == snapshotMethod {
+			defer s.BeginRequest()()
 		}
 
 		md, ok := metadata.FromIncomingContext(ss.Context())
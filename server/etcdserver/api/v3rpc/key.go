@@ -52,6 +52,20 @@ func (s *kvServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResp
 	return resp, nil
 }
 
+func (s *kvServer) IndexRange(ctx context.Context, r *pb.IndexRangeRequest) (*pb.RangeResponse, error) {
+	if err := checkIndexRangeRequest(r); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.kv.IndexRange(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 func (s *kvServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
 	if err := checkPutRequest(r); err != nil {
 		return nil, err
@@ -124,6 +138,20 @@ func checkRangeRequest(r *pb.RangeRequest) error {
 		return rpctypes.ErrGRPCInvalidSortOption
 	}
 
+	if r.Estimate && r.Revision != 0 {
+		return rpctypes.ErrGRPCEstimateRevision
+	}
+
+	return nil
+}
+
+func checkIndexRangeRequest(r *pb.IndexRangeRequest) error {
+	if len(r.Prefix) == 0 {
+		return rpctypes.ErrGRPCEmptyKey
+	}
+	if len(r.Field) == 0 {
+		return rpctypes.ErrGRPCEmptyField
+	}
 	return nil
 }
 
@@ -137,6 +165,15 @@ func checkPutRequest(r *pb.PutRequest) error {
 	if r.IgnoreLease && r.Lease != 0 {
 		return rpctypes.ErrGRPCLeaseProvided
 	}
+	if r.TtlSeconds < 0 {
+		return rpctypes.ErrGRPCNegativeKeyTTL
+	}
+	if r.TtlSeconds > 0 && (r.Lease != 0 || r.IgnoreLease) {
+		return rpctypes.ErrGRPCLeaseProvided
+	}
+	if (r.ValuePart || r.ValueUpload != 0) && r.IgnoreValue {
+		return rpctypes.ErrGRPCValueProvided
+	}
 	return nil
 }
 
@@ -268,6 +305,9 @@ func checkRequestOp(u *pb.RequestOp, maxTxnOps int) error {
 	case *pb.RequestOp_RequestRange:
 		return checkRangeRequest(uv.RequestRange)
 	case *pb.RequestOp_RequestPut:
+		if uv.RequestPut.ValuePart || uv.RequestPut.ValueUpload != 0 {
+			return rpctypes.ErrGRPCValuePartInTxn
+		}
 		return checkPutRequest(uv.RequestPut)
 	case *pb.RequestOp_RequestDeleteRange:
 		return checkDeleteRequest(uv.RequestDeleteRange)
@@ -30,10 +30,12 @@ type LeaseServer struct {
 	lg  *zap.Logger
 	hdr header
 	le  etcdserver.Lessor
+	// stopc is closed when the server is stopping.
+	stopc <-chan struct{}
 }
 
 func NewLeaseServer(s *etcdserver.EtcdServer) pb.LeaseServer {
-	srv := &LeaseServer{lg: s.Cfg.Logger, le: s, hdr: newHeader(s)}
+	srv := &LeaseServer{lg: s.Cfg.Logger, le: s, hdr: newHeader(s), stopc: s.StoppingNotify()}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
@@ -59,6 +61,24 @@ func (ls *LeaseServer) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeReques
 	return resp, nil
 }
 
+func (ls *LeaseServer) LeaseGrantBatch(ctx context.Context, cr *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	resp, err := ls.le.LeaseGrantBatch(ctx, cr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ls *LeaseServer) LeaseRevokeBatch(ctx context.Context, rr *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	resp, err := ls.le.LeaseRevokeBatch(ctx, rr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 func (ls *LeaseServer) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	resp, err := ls.le.LeaseTimeToLive(ctx, rr)
 	if err != nil && err != lease.ErrLeaseNotFound {
@@ -90,6 +110,15 @@ func (ls *LeaseServer) LeaseLeases(ctx context.Context, rr *pb.LeaseLeasesReques
 	return resp, nil
 }
 
+func (ls *LeaseServer) LeaseQuery(ctx context.Context, rr *pb.LeaseQueryRequest) (*pb.LeaseQueryResponse, error) {
+	resp, err := ls.le.LeaseQuery(ctx, rr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 func (ls *LeaseServer) LeaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) (err error) {
 	errc := make(chan error, 1)
 	go func() {
@@ -155,3 +184,83 @@ func (ls *LeaseServer) leaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) erro
 		}
 	}
 }
+
+func (ls *LeaseServer) WatchExpirations(r *pb.LeaseWatchExpirationsRequest, stream pb.Lease_WatchExpirationsServer) error {
+	revokedc, cancel := ls.le.LeaseWatchExpirations()
+	defer cancel()
+
+	// the first response tells the client that the leases revoked from
+	// now on will be streamed
+	resp := &pb.LeaseWatchExpirationsResponse{Header: &pb.ResponseHeader{}}
+	ls.hdr.fill(resp.Header)
+	if err := ls.sendExpirations(stream, resp); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case rl, ok := <-revokedc:
+			if !ok {
+				select {
+				case <-ls.stopc:
+					return rpctypes.ErrGRPCStopped
+				default:
+					return rpctypes.ErrGRPCLeaseWatchTooSlow
+				}
+			}
+			resp := &pb.LeaseWatchExpirationsResponse{Header: &pb.ResponseHeader{}}
+			if rl.Expired || !r.ExpiredOnly {
+				resp.Expirations = append(resp.Expirations, toLeaseExpiration(rl, r.Keys))
+			}
+			// batch the leases revoked meanwhile, e.g. expired together
+			for n := len(revokedc); n > 0; n-- {
+				rl, ok := <-revokedc
+				if !ok {
+					break
+				}
+				if rl.Expired || !r.ExpiredOnly {
+					resp.Expirations = append(resp.Expirations, toLeaseExpiration(rl, r.Keys))
+				}
+			}
+			if len(resp.Expirations) == 0 {
+				continue
+			}
+			ls.hdr.fill(resp.Header)
+			if err := ls.sendExpirations(stream, resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (ls *LeaseServer) sendExpirations(stream pb.Lease_WatchExpirationsServer, resp *pb.LeaseWatchExpirationsResponse) error {
+	err := stream.Send(resp)
+	if err != nil {
+		if isClientCtxErr(stream.Context().Err(), err) {
+			ls.lg.Debug("failed to send lease expirations to gRPC stream", zap.Error(err))
+		} else {
+			ls.lg.Warn("failed to send lease expirations to gRPC stream", zap.Error(err))
+			streamFailures.WithLabelValues("send", "lease-watch-expirations").Inc()
+		}
+	}
+	return err
+}
+
+func toLeaseExpiration(rl lease.RevokedLease, withKeys bool) *pb.LeaseExpiration {
+	le := &pb.LeaseExpiration{
+		ID:         int64(rl.ID),
+		GrantedTTL: rl.TTL,
+		Expired:    rl.Expired,
+		ParentID:   int64(rl.Parent),
+		Labels:     rl.Labels,
+	}
+	if withKeys {
+		le.Keys = make([][]byte, len(rl.Keys))
+		for i := range rl.Keys {
+			le.Keys[i] = []byte(rl.Keys[i])
+		}
+	}
+	return le
+}
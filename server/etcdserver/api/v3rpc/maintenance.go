@@ -18,6 +18,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -52,6 +53,15 @@ type Alarmer interface {
 	Alarm(ctx context.Context, ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 }
 
+type PrefixQuotaManager interface {
+	PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error)
+}
+
+type RevisionPinner interface {
+	RevisionPin(ctx context.Context, r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error)
+	SnapshotRange(ctx context.Context, r *pb.SnapshotRangeRequest) (*pb.SnapshotRangeResponse, error)
+}
+
 type Downgrader interface {
 	Downgrade(ctx context.Context, dr *pb.DowngradeRequest) (*pb.DowngradeResponse, error)
 }
@@ -60,8 +70,18 @@ type LeaderTransferrer interface {
 	MoveLeader(ctx context.Context, lead, target uint64) error
 }
 
+type CorruptionChecker interface {
+	CorruptionCheck(run bool, scope pb.CorruptionCheckRequest_Scope) (etcdserver.CorruptionCheckResult, error)
+}
+
 type ClusterStatusGetter interface {
 	IsLearner() bool
+	FeatureGates() map[string]bool
+}
+
+type Drainer interface {
+	Drain(ctx context.Context) error
+	Stop()
 }
 
 type maintenanceServer struct {
@@ -74,20 +94,37 @@ type maintenanceServer struct {
 	hdr    header
 	cs     ClusterStatusGetter
 	d      Downgrader
+	pq     PrefixQuotaManager
+	rp     RevisionPinner
+	kg     KVGetter
+	cc     CorruptionChecker
 	vs     serverversion.Server
+	dr     Drainer
+	// drainTimeout is the deadline of the drains requested without one
+	drainTimeout time.Duration
 }
 
 func NewMaintenanceServer(s *etcdserver.EtcdServer) pb.MaintenanceServer {
-	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, hasher: s.KV().HashStorage(), bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, vs: etcdserver.NewServerVersionAdapter(s)}
+	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, hasher: s.KV().HashStorage(), bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, pq: s, rp: s, kg: s, cc: s, vs: etcdserver.NewServerVersionAdapter(s), dr: s, drainTimeout: s.Cfg.DrainTimeout}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
+	if srv.drainTimeout == 0 {
+		srv.drainTimeout = s.Cfg.ReqTimeout()
+	}
 	return &authMaintenanceServer{srv, &AuthAdmin{s}}
 }
 
 func (ms *maintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRequest) (*pb.DefragmentResponse, error) {
 	ms.lg.Info("starting defragment")
-	err := ms.bg.Backend().Defrag()
+	be := ms.bg.Backend()
+	if err := be.Check(); err != nil {
+		// defragmentation rewrites the whole database from the current one,
+		// which would propagate, or worsen, any corruption.
+		ms.lg.Warn("refusing to defragment, backend integrity check failed", zap.Error(err))
+		return nil, rpctypes.ErrGRPCBackendCorrupt
+	}
+	err := be.Defrag()
 	if err != nil {
 		ms.lg.Warn("failed to defragment", zap.Error(err))
 		return nil, err
@@ -242,6 +279,12 @@ func (ms *maintenanceServer) Status(ctx context.Context, ar *pb.StatusRequest) (
 	if storageVersion := ms.vs.GetStorageVersion(); storageVersion != nil {
 		resp.StorageVersion = storageVersion.String()
 	}
+	for name, enabled := range ms.cs.FeatureGates() {
+		if enabled {
+			resp.FeatureGates = append(resp.FeatureGates, name)
+		}
+	}
+	sort.Strings(resp.FeatureGates)
 	if resp.Leader == raft.None {
 		resp.Errors = append(resp.Errors, errors.ErrNoLeader.Error())
 	}
@@ -272,6 +315,152 @@ func (ms *maintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeReque
 	return resp, nil
 }
 
+func (ms *maintenanceServer) PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error) {
+	resp, err := ms.pq.PrefixQuota(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if resp.Header == nil {
+		resp.Header = &pb.ResponseHeader{}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	hk := ms.kg.KV().HotKeys(int(r.Limit))
+	resp := &pb.HotKeysResponse{
+		Header:      &pb.ResponseHeader{},
+		Reads:       toPBHotKeys(hk.Reads),
+		Writes:      toPBHotKeys(hk.Writes),
+		WatchEvents: toPBHotKeys(hk.WatchEvents),
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) CorruptionCheck(ctx context.Context, r *pb.CorruptionCheckRequest) (*pb.CorruptionCheckResponse, error) {
+	res, err := ms.cc.CorruptionCheck(r.Run, r.Scope)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp := &pb.CorruptionCheckResponse{
+		Header:         &pb.ResponseHeader{},
+		Scope:          res.Scope,
+		Revision:       res.Revision,
+		MembersChecked: int64(res.MembersChecked),
+	}
+	if !res.Time.IsZero() {
+		resp.Time = res.Time.Unix()
+	}
+	for _, id := range res.Corrupted {
+		resp.CorruptAlarms = append(resp.CorruptAlarms, &pb.AlarmMember{MemberID: uint64(id), Alarm: pb.AlarmType_CORRUPT})
+	}
+	if res.Err != nil {
+		resp.Error = res.Err.Error()
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	stats := ms.kg.KV().PrefixStats(r.Prefix, int(r.Depth))
+	resp := &pb.PrefixStatsResponse{
+		Header: &pb.ResponseHeader{},
+		Stats:  make([]*pb.PrefixStat, len(stats)),
+	}
+	for i, st := range stats {
+		resp.Stats[i] = &pb.PrefixStat{
+			Prefix:          st.Prefix,
+			Keys:            st.Keys,
+			LiveBytes:       st.LiveBytes,
+			HistoricalBytes: st.HistoricalBytes,
+			Watchers:        st.Watchers,
+		}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) RevisionPin(ctx context.Context, r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error) {
+	resp, err := ms.rp.RevisionPin(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if resp.Header == nil {
+		resp.Header = &pb.ResponseHeader{}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) SnapshotRange(ctx context.Context, r *pb.SnapshotRangeRequest) (*pb.SnapshotRangeResponse, error) {
+	if r.Session == "" {
+		return nil, rpctypes.ErrGRPCEmptyField
+	}
+	resp, err := ms.rp.SnapshotRange(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if resp.Header == nil {
+		resp.Header = &pb.ResponseHeader{}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func toPBHotKeys(hks []mvcc.HotKey) []*pb.HotKey {
+	pbhks := make([]*pb.HotKey, len(hks))
+	for i, hk := range hks {
+		pbhks[i] = &pb.HotKey{Prefix: hk.Prefix, Count: hk.Count}
+	}
+	return pbhks
+}
+
+func (ms *maintenanceServer) WatchLag(ctx context.Context, r *pb.WatchLagRequest) (*pb.WatchLagResponse, error) {
+	wl := ms.kg.KV().WatchLag(int(r.Limit))
+	resp := &pb.WatchLagResponse{
+		Header:        &pb.ResponseHeader{},
+		Watchers:      make([]*pb.WatcherLag, len(wl.Watchers)),
+		SlowWatchers:  int64(wl.SlowWatchers),
+		PendingEvents: int64(wl.PendingEvents),
+	}
+	for i, l := range wl.Watchers {
+		resp.Watchers[i] = &pb.WatcherLag{
+			WatchId:        int64(l.ID),
+			Key:            l.Key,
+			RangeEnd:       l.End,
+			PendingEvents:  int64(l.PendingEvents),
+			OldestRevision: l.OldestRevision,
+			Lag:            l.Lag,
+		}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) Drain(ctx context.Context, r *pb.DrainRequest) (*pb.DrainResponse, error) {
+	timeout := ms.drainTimeout
+	if r.TimeoutSeconds > 0 {
+		timeout = time.Duration(r.TimeoutSeconds) * time.Second
+	}
+	ms.lg.Info("drain requested", zap.Duration("timeout", timeout))
+	go func() {
+		// ctx is canceled once the response is sent, before which the
+		// request is itself in flight
+		<-ctx.Done()
+		dctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := ms.dr.Drain(dctx); err != nil {
+			ms.lg.Warn("failed to drain the client requests", zap.Error(err))
+		}
+		cancel()
+		ms.dr.Stop()
+	}()
+	resp := &pb.DrainResponse{Header: &pb.ResponseHeader{}}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 type authMaintenanceServer struct {
 	*maintenanceServer
 	*AuthAdmin
@@ -294,7 +483,7 @@ func (ams *authMaintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Mainte
 }
 
 func (ams *authMaintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (*pb.HashResponse, error) {
-	if err := ams.isPermitted(ctx); err != nil {
+	if err := ams.isReadPermitted(ctx); err != nil {
 		return nil, err
 	}
 
@@ -302,14 +491,14 @@ func (ams *authMaintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (
 }
 
 func (ams *authMaintenanceServer) HashKV(ctx context.Context, r *pb.HashKVRequest) (*pb.HashKVResponse, error) {
-	if err := ams.isPermitted(ctx); err != nil {
+	if err := ams.isReadPermitted(ctx); err != nil {
 		return nil, err
 	}
 	return ams.maintenanceServer.HashKV(ctx, r)
 }
 
 func (ams *authMaintenanceServer) Status(ctx context.Context, ar *pb.StatusRequest) (*pb.StatusResponse, error) {
-	if err := ams.isPermitted(ctx); err != nil {
+	if err := ams.isReadPermitted(ctx); err != nil {
 		return nil, err
 	}
 
@@ -331,3 +520,59 @@ func (ams *authMaintenanceServer) Downgrade(ctx context.Context, r *pb.Downgrade
 
 	return ams.maintenanceServer.Downgrade(ctx, r)
 }
+
+func (ams *authMaintenanceServer) PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.PrefixQuota(ctx, r)
+}
+
+func (ams *authMaintenanceServer) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.HotKeys(ctx, r)
+}
+
+func (ams *authMaintenanceServer) CorruptionCheck(ctx context.Context, r *pb.CorruptionCheckRequest) (*pb.CorruptionCheckResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.CorruptionCheck(ctx, r)
+}
+
+func (ams *authMaintenanceServer) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.PrefixStats(ctx, r)
+}
+
+func (ams *authMaintenanceServer) RevisionPin(ctx context.Context, r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.RevisionPin(ctx, r)
+}
+
+func (ams *authMaintenanceServer) WatchLag(ctx context.Context, r *pb.WatchLagRequest) (*pb.WatchLagResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.WatchLag(ctx, r)
+}
+
+func (ams *authMaintenanceServer) Drain(ctx context.Context, r *pb.DrainRequest) (*pb.DrainResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.Drain(ctx, r)
+}
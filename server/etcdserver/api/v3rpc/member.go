@@ -46,7 +46,9 @@ func (cs *ClusterServer) MemberAdd(ctx context.Context, r *pb.MemberAddRequest)
 
 	now := time.Now()
 	var m *membership.Member
-	if r.IsLearner {
+	if r.IsObserver {
+		m = membership.NewMemberAsObserver("", urls, "", &now)
+	} else if r.IsLearner {
 		m = membership.NewMemberAsLearner("", urls, "", &now)
 	} else {
 		m = membership.NewMember("", urls, "", &now)
@@ -59,9 +61,10 @@ func (cs *ClusterServer) MemberAdd(ctx context.Context, r *pb.MemberAddRequest)
 	return &pb.MemberAddResponse{
 		Header: cs.header(),
 		Member: &pb.Member{
-			ID:        uint64(m.ID),
-			PeerURLs:  m.PeerURLs,
-			IsLearner: m.IsLearner,
+			ID:         uint64(m.ID),
+			PeerURLs:   m.PeerURLs,
+			IsLearner:  m.IsLearner,
+			IsObserver: m.IsObserver,
 		},
 		Members: membersToProtoMembers(membs),
 	}, nil
@@ -105,6 +108,28 @@ func (cs *ClusterServer) MemberPromote(ctx context.Context, r *pb.MemberPromoteR
 	return &pb.MemberPromoteResponse{Header: cs.header(), Members: membersToProtoMembers(membs)}, nil
 }
 
+func (cs *ClusterServer) MemberReplace(ctx context.Context, r *pb.MemberReplaceRequest) (*pb.MemberReplaceResponse, error) {
+	urls, err := types.NewURLs(r.PeerURLs)
+	if err != nil {
+		return nil, rpctypes.ErrGRPCMemberBadURLs
+	}
+
+	now := time.Now()
+	m := membership.NewMemberAsLearner("", urls, "", &now)
+	membs, merr := cs.server.ReplaceMember(ctx, r.ID, *m)
+	if merr != nil {
+		return nil, togRPCError(merr)
+	}
+
+	resp := &pb.MemberReplaceResponse{Header: cs.header(), Members: membersToProtoMembers(membs)}
+	for _, pm := range resp.Members {
+		if pm.ID == uint64(m.ID) {
+			resp.Member = pm
+		}
+	}
+	return resp, nil
+}
+
 func (cs *ClusterServer) header() *pb.ResponseHeader {
 	return &pb.ResponseHeader{ClusterId: uint64(cs.cluster.ID()), MemberId: uint64(cs.server.MemberId()), RaftTerm: cs.server.Term()}
 }
@@ -118,6 +143,7 @@ func membersToProtoMembers(membs []*membership.Member) []*pb.Member {
 			PeerURLs:   membs[i].PeerURLs,
 			ClientURLs: membs[i].ClientURLs,
 			IsLearner:  membs[i].IsLearner,
+			IsObserver: membs[i].IsObserver,
 		}
 	}
 	return protoMembs
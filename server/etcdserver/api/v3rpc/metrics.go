@@ -48,6 +48,52 @@ var (
 	},
 		[]string{"type", "client_api_version"},
 	)
+
+	coalescedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "watch_coalesced_events_total",
+		Help:      "The total number of watch events replaced by a later event of their key within the coalescing window of their watcher.",
+	})
+
+	sharedEventEncodings = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "watch_shared_event_encodings_total",
+		Help:      "The total number of watch events sent with an encoding shared with other watchers.",
+	})
+
+	droppedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "watch_dropped_events_total",
+		Help:      "The total number of watch events dropped from the queues of the watchers exceeding their delivery rate limit.",
+	})
+
+	overflowCanceledWatchers = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "watch_overflow_canceled_watchers_total",
+		Help:      "The total number of watchers canceled for queuing more events than allowed by their delivery rate limit.",
+	})
+
+	roleQuotaRejectedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "role_quota_rejected_requests_total",
+		Help:      "The total number of client requests rejected for exceeding the quotas of the roles of their user.",
+	},
+		[]string{"quota"},
+	)
+
+	authorizerDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "authorizer_decisions_total",
+		Help:      "The total number of client requests checked against the external authorizer, by decision.",
+	},
+		[]string{"decision"},
+	)
 )
 
 func init() {
@@ -55,4 +101,10 @@ func init() {
 	prometheus.MustRegister(receivedBytes)
 	prometheus.MustRegister(streamFailures)
 	prometheus.MustRegister(clientRequests)
+	prometheus.MustRegister(coalescedEvents)
+	prometheus.MustRegister(sharedEventEncodings)
+	prometheus.MustRegister(droppedEvents)
+	prometheus.MustRegister(overflowCanceledWatchers)
+	prometheus.MustRegister(roleQuotaRejectedRequests)
+	prometheus.MustRegister(authorizerDecisions)
 }
@@ -16,6 +16,7 @@ package v3rpc
 
 import (
 	"context"
+	"sync/atomic"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
@@ -33,12 +34,17 @@ type quotaAlarmer struct {
 	q  storage.Quota
 	a  Alarmer
 	id types.ID
+
+	// grownQuota is the last grown quota the warning alarm was raised for.
+	grownQuota int64
 }
 
 // check whether request satisfies the quota. If there is not enough space,
-// ignore request and raise the free space alarm.
+// ignore request and raise the free space alarm. If the quota grew, raise
+// the warning alarm.
 func (qa *quotaAlarmer) check(ctx context.Context, r interface{}) error {
 	if qa.q.Available(r) {
+		qa.checkGrown(ctx)
 		return nil
 	}
 	req := &pb.AlarmRequest{
@@ -50,10 +56,27 @@ func (qa *quotaAlarmer) check(ctx context.Context, r interface{}) error {
 	return rpctypes.ErrGRPCNoSpace
 }
 
+func (qa *quotaAlarmer) checkGrown(ctx context.Context) {
+	bq, ok := qa.q.(*storage.BackendQuota)
+	if !ok {
+		return
+	}
+	quota, grown := bq.Grown()
+	if !grown || atomic.SwapInt64(&qa.grownQuota, quota) >= quota {
+		return
+	}
+	req := &pb.AlarmRequest{
+		MemberID: uint64(qa.id),
+		Action:   pb.AlarmRequest_ACTIVATE,
+		Alarm:    pb.AlarmType_QUOTAGROWN,
+	}
+	qa.a.Alarm(ctx, req)
+}
+
 func NewQuotaKVServer(s *etcdserver.EtcdServer) pb.KVServer {
 	return &quotaKVServer{
 		NewKVServer(s),
-		quotaAlarmer{newBackendQuota(s, "kv"), s, s.MemberId()},
+		quotaAlarmer{q: newBackendQuota(s, "kv"), a: s, id: s.MemberId()},
 	}
 }
 
@@ -83,13 +106,20 @@ func (s *quotaLeaseServer) LeaseGrant(ctx context.Context, cr *pb.LeaseGrantRequ
 	return s.LeaseServer.LeaseGrant(ctx, cr)
 }
 
+func (s *quotaLeaseServer) LeaseGrantBatch(ctx context.Context, cr *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	if err := s.qa.check(ctx, cr); err != nil {
+		return nil, err
+	}
+	return s.LeaseServer.LeaseGrantBatch(ctx, cr)
+}
+
 func NewQuotaLeaseServer(s *etcdserver.EtcdServer) pb.LeaseServer {
 	return &quotaLeaseServer{
 		NewLeaseServer(s),
-		quotaAlarmer{newBackendQuota(s, "lease"), s, s.MemberId()},
+		quotaAlarmer{q: newBackendQuota(s, "lease"), a: s, id: s.MemberId()},
 	}
 }
 
 func newBackendQuota(s *etcdserver.EtcdServer, name string) storage.Quota {
-	return storage.NewBackendQuota(s.Logger(), s.Cfg.QuotaBackendBytes, s.Backend(), name)
+	return storage.NewRuntimeBackendQuota(s.Logger(), s.QuotaBackendBytes, s.Cfg.MaxQuotaBackendBytes, s.Backend(), name)
 }
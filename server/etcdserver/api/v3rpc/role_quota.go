@@ -0,0 +1,175 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+)
+
+const watchMethod = "/etcdserverpb.Watch/Watch"
+
+// roleQuotas enforces the quotas of the requests of the users set on their
+// roles. The quotas are enforced by each member on the requests it serves.
+type roleQuotas struct {
+	s *etcdserver.EtcdServer
+
+	mu    sync.Mutex
+	users map[string]*userQuotaState
+}
+
+type userQuotaState struct {
+	// limiter limits the rate of the unary requests of the user, nil
+	// without a quota.
+	limiter *rate.Limiter
+	// watchStreams is the number of the open watch streams of the user.
+	watchStreams int64
+}
+
+func newRoleQuotas(s *etcdserver.EtcdServer) *roleQuotas {
+	return &roleQuotas{s: s, users: make(map[string]*userQuotaState)}
+}
+
+func newRoleQuotaUnaryInterceptor(rq *roleQuotas) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rq.checkRequest(ctx, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func newRoleQuotaStreamInterceptor(rq *roleQuotas) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod != watchMethod {
+			return handler(srv, ss)
+		}
+		release, err := rq.acquireWatchStream(ss.Context())
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+// checkRequest checks the unary request against the request rate and txn
+// operations quotas of its user. The requests failing authentication are
+// left to be rejected by their handlers.
+func (rq *roleQuotas) checkRequest(ctx context.Context, req interface{}) error {
+	as := rq.s.AuthStore()
+	if !as.HasQuotas() {
+		return nil
+	}
+	ai, err := rq.s.AuthInfoFromCtx(ctx)
+	if err != nil || ai == nil {
+		return nil
+	}
+	q := as.UserQuota(ai)
+
+	if txn, ok := req.(*pb.TxnRequest); ok && q.MaxTxnOps > 0 && txnOps(txn) > q.MaxTxnOps {
+		roleQuotaRejectedRequests.WithLabelValues("txn_ops").Inc()
+		return rpctypes.ErrGRPCRoleTxnOps
+	}
+	if q.MaxRequestsPerSecond > 0 && !rq.allowRequest(ai.Username, q.MaxRequestsPerSecond) {
+		roleQuotaRejectedRequests.WithLabelValues("requests_per_second").Inc()
+		return rpctypes.ErrGRPCRoleRequestRate
+	}
+	return nil
+}
+
+// allowRequest takes a token of the rate limiter of the user, which holds up
+// to a second of requests.
+func (rq *roleQuotas) allowRequest(user string, rps int64) bool {
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+	st := rq.userState(user)
+	if st.limiter == nil {
+		st.limiter = rate.NewLimiter(rate.Limit(rps), int(rps))
+	} else if st.limiter.Burst() != int(rps) {
+		st.limiter.SetLimit(rate.Limit(rps))
+		st.limiter.SetBurst(int(rps))
+	}
+	return st.limiter.Allow()
+}
+
+// acquireWatchStream counts a watch stream of the user against its quota,
+// returning the function releasing it. The watch streams of the users are
+// counted whether they have a quota or not, for the quotas set afterwards.
+func (rq *roleQuotas) acquireWatchStream(ctx context.Context) (release func(), err error) {
+	as := rq.s.AuthStore()
+	if !as.IsAuthEnabled() {
+		return func() {}, nil
+	}
+	ai, err := rq.s.AuthInfoFromCtx(ctx)
+	if err != nil || ai == nil {
+		return func() {}, nil
+	}
+	q := as.UserQuota(ai)
+
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+	st := rq.userState(ai.Username)
+	if q.MaxWatchStreams > 0 && st.watchStreams >= q.MaxWatchStreams {
+		roleQuotaRejectedRequests.WithLabelValues("watch_streams").Inc()
+		return nil, rpctypes.ErrGRPCRoleWatchStreams
+	}
+	st.watchStreams++
+	return func() {
+		rq.mu.Lock()
+		defer rq.mu.Unlock()
+		st.watchStreams--
+		if st.watchStreams == 0 && st.limiter == nil {
+			delete(rq.users, ai.Username)
+		}
+	}, nil
+}
+
+// userState returns the state of the user, rq.mu being locked.
+func (rq *roleQuotas) userState(user string) *userQuotaState {
+	st, ok := rq.users[user]
+	if !ok {
+		st = &userQuotaState{}
+		rq.users[user] = st
+	}
+	return st
+}
+
+// txnOps returns the number of operations of the largest branch of a txn,
+// counting the operations of its nested txns.
+func txnOps(r *pb.TxnRequest) int64 {
+	ops := int64(len(r.Compare))
+	for _, branch := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		var n int64
+		for _, op := range branch {
+			if t := op.GetRequestTxn(); t != nil {
+				n += txnOps(t)
+			} else {
+				n++
+			}
+		}
+		if n > ops {
+			ops = n
+		}
+	}
+	return ops
+}
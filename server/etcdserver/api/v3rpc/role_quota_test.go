@@ -0,0 +1,47 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestTxnOps(t *testing.T) {
+	put := &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("a")}}}
+	cmp := &pb.Compare{Key: []byte("a")}
+	nested := &pb.RequestOp{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+		Success: []*pb.RequestOp{put, put, put},
+	}}}
+
+	tests := []struct {
+		name string
+		txn  *pb.TxnRequest
+		want int64
+	}{
+		{"empty", &pb.TxnRequest{}, 0},
+		{"compares", &pb.TxnRequest{Compare: []*pb.Compare{cmp, cmp}, Success: []*pb.RequestOp{put}}, 2},
+		{"largest branch", &pb.TxnRequest{Success: []*pb.RequestOp{put}, Failure: []*pb.RequestOp{put, put}}, 2},
+		{"nested txn", &pb.TxnRequest{Success: []*pb.RequestOp{put, nested}, Failure: []*pb.RequestOp{put, put}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, txnOps(tt.txn))
+		})
+	}
+}
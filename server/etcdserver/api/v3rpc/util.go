@@ -38,8 +38,10 @@ var toGRPCErrorMap = map[error]error{
 	membership.ErrPeerURLexists:       rpctypes.ErrGRPCPeerURLExist,
 	membership.ErrMemberNotLearner:    rpctypes.ErrGRPCMemberNotLearner,
 	membership.ErrTooManyLearners:     rpctypes.ErrGRPCTooManyLearners,
+	membership.ErrMemberObserver:      rpctypes.ErrGRPCMemberObserver,
 	errors.ErrNotEnoughStartedMembers: rpctypes.ErrMemberNotEnoughStarted,
 	errors.ErrLearnerNotReady:         rpctypes.ErrGRPCLearnerNotReady,
+	errors.ErrMemberActive:            rpctypes.ErrGRPCMemberActive,
 
 	mvcc.ErrCompacted:         rpctypes.ErrGRPCCompacted,
 	mvcc.ErrFutureRev:         rpctypes.ErrGRPCFutureRev,
@@ -47,6 +49,15 @@ var toGRPCErrorMap = map[error]error{
 	errors.ErrNoSpace:         rpctypes.ErrGRPCNoSpace,
 	errors.ErrTooManyRequests: rpctypes.ErrTooManyRequests,
 
+	errors.ErrPrefixQuotaExceeded: rpctypes.ErrGRPCPrefixQuotaExceeded,
+	errors.ErrInvalidPrefixQuota:  rpctypes.ErrGRPCInvalidPrefixQuota,
+
+	errors.ErrRevisionPinned:     rpctypes.ErrGRPCRevisionPinned,
+	errors.ErrInvalidRevisionPin: rpctypes.ErrGRPCInvalidRevisionPin,
+
+	mvcc.ErrIndexNotFound:       rpctypes.ErrGRPCIndexNotFound,
+	mvcc.ErrValueUploadNotFound: rpctypes.ErrGRPCValueUploadNotFound,
+
 	errors.ErrNoLeader:                   rpctypes.ErrGRPCNoLeader,
 	errors.ErrNotLeader:                  rpctypes.ErrGRPCNotLeader,
 	errors.ErrLeaderChanged:              rpctypes.ErrGRPCLeaderChanged,
@@ -59,16 +70,21 @@ var toGRPCErrorMap = map[error]error{
 	errors.ErrKeyNotFound:                rpctypes.ErrGRPCKeyNotFound,
 	errors.ErrCorrupt:                    rpctypes.ErrGRPCCorrupt,
 	errors.ErrBadLeaderTransferee:        rpctypes.ErrGRPCBadLeaderTransferee,
+	errors.ErrNotCapable:                 rpctypes.ErrGRPCNotCapable,
 
 	errors.ErrClusterVersionUnavailable:      rpctypes.ErrGRPCClusterVersionUnavailable,
 	errors.ErrWrongDowngradeVersionFormat:    rpctypes.ErrGRPCWrongDowngradeVersionFormat,
 	version.ErrInvalidDowngradeTargetVersion: rpctypes.ErrGRPCInvalidDowngradeTargetVersion,
 	version.ErrDowngradeInProcess:            rpctypes.ErrGRPCDowngradeInProcess,
 	version.ErrNoInflightDowngrade:           rpctypes.ErrGRPCNoInflightDowngrade,
+	version.ErrDowngradeEncodedKeyValues:     rpctypes.ErrGRPCDowngradeEncodedKeyValues,
 
-	lease.ErrLeaseNotFound:    rpctypes.ErrGRPCLeaseNotFound,
-	lease.ErrLeaseExists:      rpctypes.ErrGRPCLeaseExist,
-	lease.ErrLeaseTTLTooLarge: rpctypes.ErrGRPCLeaseTTLTooLarge,
+	lease.ErrLeaseNotFound:            rpctypes.ErrGRPCLeaseNotFound,
+	lease.ErrLeaseExists:              rpctypes.ErrGRPCLeaseExist,
+	lease.ErrLeaseTTLTooLarge:         rpctypes.ErrGRPCLeaseTTLTooLarge,
+	lease.ErrInvalidLeaseLabels:       rpctypes.ErrGRPCInvalidLeaseLabels,
+	lease.ErrParentLeaseNotFound:      rpctypes.ErrGRPCParentLeaseNotFound,
+	lease.ErrLeaseTooManyAttachedKeys: rpctypes.ErrGRPCLeaseTooManyKeys,
 
 	auth.ErrRootUserNotExist:     rpctypes.ErrGRPCRootUserNotExist,
 	auth.ErrRootRoleNotExist:     rpctypes.ErrGRPCRootRoleNotExist,
@@ -87,6 +103,9 @@ var toGRPCErrorMap = map[error]error{
 	auth.ErrInvalidAuthToken:     rpctypes.ErrGRPCInvalidAuthToken,
 	auth.ErrInvalidAuthMgmt:      rpctypes.ErrGRPCInvalidAuthMgmt,
 	auth.ErrAuthOldRevision:      rpctypes.ErrGRPCAuthOldRevision,
+	auth.ErrRoleLeaseTTLTooLarge: rpctypes.ErrGRPCRoleLeaseTTLTooLarge,
+	auth.ErrPasswordPolicy:       rpctypes.ErrGRPCPasswordPolicy,
+	auth.ErrInvalidPermTemplate:  rpctypes.ErrGRPCInvalidPermTemplate,
 
 	// In sync with status.FromContextError
 	context.Canceled:         rpctypes.ErrGRPCCanceled,
@@ -137,6 +156,16 @@ func isClientCtxErr(ctxErr error, err error) bool {
 	return false
 }
 
+// observer is allowed to serve serializable and linearizable reads and endpoint status
+func isRPCSupportedForObserver(req interface{}) bool {
+	switch req.(type) {
+	case *pb.StatusRequest, *pb.RangeRequest, *pb.IndexRangeRequest:
+		return true
+	default:
+		return false
+	}
+}
+
 // in v3.4, learner is allowed to serve serializable read and endpoint status
 func isRPCSupportedForLearner(req interface{}) bool {
 	switch r := req.(type) {
@@ -144,6 +173,8 @@ func isRPCSupportedForLearner(req interface{}) bool {
 		return true
 	case *pb.RangeRequest:
 		return r.Serializable
+	case *pb.IndexRangeRequest:
+		return r.Serializable
 	default:
 		return false
 	}
@@ -43,29 +43,65 @@ type watchServer struct {
 	memberID  int64
 
 	maxRequestBytes int
+	// maxStartRevisionLag is the maximum number of revisions a watch
+	// start revision can be behind the current revision; 0 means no limit.
+	maxStartRevisionLag int64
+	// history finds the revision current the maximum start revision age
+	// ago; nil means no limit.
+	history *revisionHistory
+	// maxEventsPerSecond is the maximum rate each watcher is sent events at;
+	// 0 means no limit. The events waiting for it are queued, up to
+	// maxQueuedEvents before overflowPolicy applies; 0 means no limit.
+	maxEventsPerSecond int64
+	maxQueuedEvents    int64
+	overflowPolicy     string
 
 	sg        apply.RaftStatusGetter
 	watchable mvcc.WatchableKV
 	ag        AuthGetter
+
+	// broker shares the event encodings across the streams, nil if the
+	// streams are not sent through the server codec
+	broker *watchBroker
+	// drainc is closed once the server drains, the streams ending once
+	// their buffered events are sent
+	drainc <-chan struct{}
 }
 
 // NewWatchServer returns a new watch server.
 func NewWatchServer(s *etcdserver.EtcdServer) pb.WatchServer {
+	return newWatchServer(s, nil)
+}
+
+func newWatchServer(s *etcdserver.EtcdServer, broker *watchBroker) pb.WatchServer {
 	srv := &watchServer{
 		lg: s.Cfg.Logger,
 
 		clusterID: int64(s.Cluster().ID()),
 		memberID:  int64(s.MemberId()),
 
-		maxRequestBytes: int(s.Cfg.MaxRequestBytes + grpcOverheadBytes),
+		maxRequestBytes:     int(s.Cfg.MaxRequestBytes + grpcOverheadBytes),
+		maxStartRevisionLag: s.Cfg.WatchMaxStartRevisionLag,
+		maxEventsPerSecond:  s.Cfg.WatchMaxEventsPerSecond,
+		maxQueuedEvents:     s.Cfg.WatchMaxQueuedEvents,
+		overflowPolicy:      s.Cfg.WatchOverflowPolicy,
 
 		sg:        s,
 		watchable: s.Watchable(),
 		ag:        s,
+
+		broker: broker,
+		drainc: s.DrainNotify(),
 	}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
+	if s.Cfg.WatchMaxStartRevisionAge > 0 {
+		srv.history = newRevisionHistory(s.Cfg.WatchMaxStartRevisionAge)
+		s.GoAttach(func() {
+			srv.history.run(func() int64 { return s.KV().Rev() }, s.StoppingNotify())
+		})
+	}
 	if s.Cfg.WatchProgressNotifyInterval > 0 {
 		if s.Cfg.WatchProgressNotifyInterval < minWatchProgressInterval {
 			srv.lg.Warn(
@@ -125,7 +161,12 @@ type serverWatchStream struct {
 	clusterID int64
 	memberID  int64
 
-	maxRequestBytes int
+	maxRequestBytes     int
+	maxStartRevisionLag int64
+	history             *revisionHistory
+	maxEventsPerSecond  int64
+	maxQueuedEvents     int64
+	overflowPolicy      string
 
 	sg        apply.RaftStatusGetter
 	watchable mvcc.WatchableKV
@@ -134,8 +175,9 @@ type serverWatchStream struct {
 	gRPCStream  pb.Watch_WatchServer
 	watchStream mvcc.WatchStream
 	ctrlStream  chan *pb.WatchResponse
+	broker      *watchBroker
 
-	// mu protects progress, prevKV, fragment
+	// mu protects progress, prevKV, fragment, coalesce, projection
 	mu sync.RWMutex
 	// tracks the watchID that stream might need to send progress to
 	// TODO: combine progress and prevKV into a single struct?
@@ -144,9 +186,17 @@ type serverWatchStream struct {
 	prevKV map[mvcc.WatchID]bool
 	// records fragmented watch IDs
 	fragment map[mvcc.WatchID]bool
+	// records the coalescing window of watch IDs coalescing their events
+	coalesce map[mvcc.WatchID]time.Duration
+	// records the projection of watch IDs not sent their events in full
+	projection map[mvcc.WatchID]pb.WatchCreateRequest_Projection
 
 	// closec indicates the stream is closed.
 	closec chan struct{}
+	// drainc is closed once the server drains, and drainedc once the stream
+	// sent its buffered events after it.
+	drainc   <-chan struct{}
+	drainedc chan struct{}
 
 	// wg waits for the send loop to complete
 	wg sync.WaitGroup
@@ -159,7 +209,12 @@ func (ws *watchServer) Watch(stream pb.Watch_WatchServer) (err error) {
 		clusterID: ws.clusterID,
 		memberID:  ws.memberID,
 
-		maxRequestBytes: ws.maxRequestBytes,
+		maxRequestBytes:     ws.maxRequestBytes,
+		maxStartRevisionLag: ws.maxStartRevisionLag,
+		history:             ws.history,
+		maxEventsPerSecond:  ws.maxEventsPerSecond,
+		maxQueuedEvents:     ws.maxQueuedEvents,
+		overflowPolicy:      ws.overflowPolicy,
 
 		sg:        ws.sg,
 		watchable: ws.watchable,
@@ -169,12 +224,17 @@ func (ws *watchServer) Watch(stream pb.Watch_WatchServer) (err error) {
 		watchStream: ws.watchable.NewWatchStream(),
 		// chan for sending control response like watcher created and canceled.
 		ctrlStream: make(chan *pb.WatchResponse, ctrlStreamBufLen),
+		broker:     ws.broker,
 
-		progress: make(map[mvcc.WatchID]bool),
-		prevKV:   make(map[mvcc.WatchID]bool),
-		fragment: make(map[mvcc.WatchID]bool),
+		progress:   make(map[mvcc.WatchID]bool),
+		prevKV:     make(map[mvcc.WatchID]bool),
+		fragment:   make(map[mvcc.WatchID]bool),
+		coalesce:   make(map[mvcc.WatchID]time.Duration),
+		projection: make(map[mvcc.WatchID]pb.WatchCreateRequest_Projection),
 
-		closec: make(chan struct{}),
+		closec:   make(chan struct{}),
+		drainc:   ws.drainc,
+		drainedc: make(chan struct{}),
 	}
 
 	sws.wg.Add(1)
@@ -220,6 +280,9 @@ func (ws *watchServer) Watch(stream pb.Watch_WatchServer) (err error) {
 		if err == context.Canceled {
 			err = rpctypes.ErrGRPCWatchCanceled
 		}
+	case <-sws.drainedc:
+		// the client resumes the watches on another member
+		err = rpctypes.ErrGRPCDraining
 	}
 
 	sws.close()
@@ -232,10 +295,57 @@ func (sws *serverWatchStream) isWatchPermitted(wcr *pb.WatchCreateRequest) error
 		return err
 	}
 	if authInfo == nil {
-		// if auth is enabled, IsRangePermitted() can cause an error
+		// if auth is enabled, IsWatchPermitted() can cause an error
 		authInfo = &auth.AuthInfo{}
 	}
-	return sws.ag.AuthStore().IsRangePermitted(authInfo, wcr.Key, wcr.RangeEnd)
+	if err := sws.ag.AuthStore().IsWatchPermitted(authInfo, wcr.Key, wcr.RangeEnd); err != nil {
+		return err
+	}
+	for _, r := range wcr.Ranges {
+		if err := sws.ag.AuthStore().IsWatchPermitted(authInfo, r.Key, r.RangeEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchKeyRange returns the key range of the watch stream for the key range
+// of a request.
+func watchKeyRange(key, end []byte) mvcc.KeyRange {
+	if len(key) == 0 {
+		// \x00 is the smallest key
+		key = []byte{0}
+	}
+	if len(end) == 0 {
+		// force nil since watchstream.Watch distinguishes
+		// between nil and []byte{} for single key / >=
+		end = nil
+	}
+	if len(end) == 1 && end[0] == 0 {
+		// support  >= key queries
+		end = []byte{}
+	}
+	return mvcc.KeyRange{Key: key, End: end}
+}
+
+// KeyRangesFromRequest returns the key ranges of the watch stream watched by
+// a given watch create request.
+func KeyRangesFromRequest(creq *pb.WatchCreateRequest) []mvcc.KeyRange {
+	ranges := make([]mvcc.KeyRange, 0, len(creq.Ranges)+1)
+	ranges = append(ranges, watchKeyRange(creq.Key, creq.RangeEnd))
+	for _, r := range creq.Ranges {
+		ranges = append(ranges, watchKeyRange(r.Key, r.RangeEnd))
+	}
+	return ranges
+}
+
+// isStartRevisionTooOld returns true if rev is further behind the current
+// revision, in revisions or in time, than allowed by the server policy.
+func (sws *serverWatchStream) isStartRevisionTooOld(rev, currentRev int64) bool {
+	if sws.maxStartRevisionLag > 0 && currentRev-rev > sws.maxStartRevisionLag {
+		return true
+	}
+	return sws.history != nil && sws.history.isTooOld(time.Now(), rev)
 }
 
 func (sws *serverWatchStream) recvLoop() error {
@@ -255,19 +365,8 @@ func (sws *serverWatchStream) recvLoop() error {
 			}
 
 			creq := uv.CreateRequest
-			if len(creq.Key) == 0 {
-				// \x00 is the smallest key
-				creq.Key = []byte{0}
-			}
-			if len(creq.RangeEnd) == 0 {
-				// force nil since watchstream.Watch distinguishes
-				// between nil and []byte{} for single key / >=
-				creq.RangeEnd = nil
-			}
-			if len(creq.RangeEnd) == 1 && creq.RangeEnd[0] == 0 {
-				// support  >= key queries
-				creq.RangeEnd = []byte{}
-			}
+			ranges := KeyRangesFromRequest(creq)
+			creq.Key, creq.RangeEnd = ranges[0].Key, ranges[0].End
 
 			err := sws.isWatchPermitted(creq)
 			if err != nil {
@@ -302,25 +401,48 @@ func (sws *serverWatchStream) recvLoop() error {
 				}
 			}
 
-			filters := FiltersFromRequest(creq)
+			filters, ferr := FiltersFromRequest(creq)
 
 			wsrev := sws.watchStream.Rev()
 			rev := creq.StartRevision
+			var terr error
+			if len(creq.ResumeToken) > 0 {
+				rev, terr = sws.resumeRevision(creq.ResumeToken, creq.StartRevision)
+			}
 			if rev == 0 {
 				rev = wsrev + 1
 			}
-			id, err := sws.watchStream.Watch(mvcc.WatchID(creq.WatchId), creq.Key, creq.RangeEnd, rev, filters...)
+			var id mvcc.WatchID
+			if ferr != nil {
+				sws.lg.Debug("invalid watch value filter", zap.Error(ferr))
+				err = rpctypes.ErrGRPCInvalidWatchValueFilter
+			} else if terr != nil {
+				sws.lg.Debug("invalid watch resume token", zap.Error(terr))
+				err = rpctypes.ErrGRPCInvalidWatchResumeToken
+			} else if !ValidWatchProjection(creq.Projection) {
+				err = rpctypes.ErrGRPCInvalidWatchProjection
+			} else if sws.isStartRevisionTooOld(rev, wsrev) {
+				err = rpctypes.ErrGRPCWatchStartRevisionTooOld
+			} else {
+				id, err = sws.watchStream.WatchRanges(mvcc.WatchID(creq.WatchId), ranges, rev, filters...)
+			}
 			if err == nil {
 				sws.mu.Lock()
 				if creq.ProgressNotify {
 					sws.progress[id] = true
 				}
-				if creq.PrevKv {
+				if creq.Projection != pb.WatchCreateRequest_FULL {
+					// projected events are sent without their previous key-value pair
+					sws.projection[id] = creq.Projection
+				} else if creq.PrevKv {
 					sws.prevKV[id] = true
 				}
 				if creq.Fragment {
 					sws.fragment[id] = true
 				}
+				if window := coalesceWindow(creq); window > 0 {
+					sws.coalesce[id] = window
+				}
 				sws.mu.Unlock()
 			} else {
 				id = clientv3.InvalidWatchID
@@ -333,7 +455,7 @@ func (sws *serverWatchStream) recvLoop() error {
 				Canceled: err != nil,
 			}
 			if err != nil {
-				wr.CancelReason = err.Error()
+				wr.CancelReason = rpctypes.ErrorDesc(err)
 			}
 			select {
 			case sws.ctrlStream <- wr:
@@ -355,14 +477,18 @@ func (sws *serverWatchStream) recvLoop() error {
 					delete(sws.progress, mvcc.WatchID(id))
 					delete(sws.prevKV, mvcc.WatchID(id))
 					delete(sws.fragment, mvcc.WatchID(id))
+					delete(sws.coalesce, mvcc.WatchID(id))
+					delete(sws.projection, mvcc.WatchID(id))
 					sws.mu.Unlock()
 				}
 			}
 		case *pb.WatchRequest_ProgressRequest:
 			if uv.ProgressRequest != nil {
+				rev := sws.watchStream.Rev()
 				sws.ctrlStream <- &pb.WatchResponse{
-					Header:  sws.newResponseHeader(sws.watchStream.Rev()),
-					WatchId: clientv3.InvalidWatchID, // response is not associated with any WatchId and will be broadcast to all watch channels
+					Header:      sws.newResponseHeader(rev),
+					WatchId:     clientv3.InvalidWatchID, // response is not associated with any WatchId and will be broadcast to all watch channels
+					ResumeToken: NewWatchResumeToken(uint64(sws.clusterID), rev),
 				}
 			}
 		default:
@@ -380,12 +506,22 @@ func (sws *serverWatchStream) sendLoop() {
 	ids := make(map[mvcc.WatchID]struct{})
 	// watch responses pending on a watch id creation message
 	pending := make(map[mvcc.WatchID][]*pb.WatchResponse)
+	// events buffered by the watch ids coalescing them
+	coalescers := make(map[mvcc.WatchID]*eventCoalescer)
+	// responses queued by the watch ids waiting for their delivery rate limit
+	limiters := make(map[mvcc.WatchID]*watchLimiter)
 
 	interval := GetProgressReportInterval()
 	progressTicker := time.NewTicker(interval)
+	coalesceTimer := time.NewTimer(0)
+	<-coalesceTimer.C
+	limitTimer := time.NewTimer(0)
+	<-limitTimer.C
 
 	defer func() {
 		progressTicker.Stop()
+		coalesceTimer.Stop()
+		limitTimer.Stop()
 		// drain the chan to clean up pending events
 		for ws := range sws.watchStream.Chan() {
 			mvcc.ReportEventReceived(len(ws.Events))
@@ -395,85 +531,374 @@ func (sws *serverWatchStream) sendLoop() {
 				mvcc.ReportEventReceived(len(ws.Events))
 			}
 		}
+		for _, c := range coalescers {
+			mvcc.ReportEventReceived(len(c.keys))
+		}
+		for _, l := range limiters {
+			mvcc.ReportEventReceived(l.queued)
+		}
 	}()
 
-	for {
-		select {
-		case wresp, ok := <-sws.watchStream.Chan():
-			if !ok {
-				return
+	send := func(wresp mvcc.WatchResponse) bool {
+		sws.mu.RLock()
+		needPrevKV := sws.prevKV[wresp.WatchID]
+		fragmented := sws.fragment[wresp.WatchID]
+		projection := sws.projection[wresp.WatchID]
+		sws.mu.RUnlock()
+
+		evs := wresp.Events
+		var shared []*sharedEvent
+		if sws.broker != nil {
+			var err error
+			shared, err = sws.broker.sharedEvents(evs, eventSpec{prevKV: needPrevKV, projection: projection}, sws.lookupPrevKV)
+			if err != nil {
+				sws.lg.Warn("failed to encode shared watch events", zap.Error(err))
+				shared = nil
 			}
+		}
 
-			// TODO: evs is []mvccpb.Event type
-			// either return []*mvccpb.Event from the mvcc package
-			// or define protocol buffer with []mvccpb.Event.
-			evs := wresp.Events
-			events := make([]*mvccpb.Event, len(evs))
-			sws.mu.RLock()
-			needPrevKV := sws.prevKV[wresp.WatchID]
-			sws.mu.RUnlock()
-			for i := range evs {
-				events[i] = &evs[i]
-				if needPrevKV && !IsCreateEvent(evs[i]) {
-					opt := mvcc.RangeOptions{Rev: evs[i].Kv.ModRevision - 1}
-					r, err := sws.watchable.Range(context.TODO(), evs[i].Kv.Key, nil, opt)
-					if err == nil && len(r.KVs) != 0 {
-						events[i].PrevKv = &(r.KVs[0])
-					}
-				}
+		// TODO: evs is []mvccpb.Event type
+		// either return []*mvccpb.Event from the mvcc package
+		// or define protocol buffer with []mvccpb.Event.
+		events := make([]*mvccpb.Event, len(evs))
+		for i := range evs {
+			if shared != nil {
+				events[i] = shared[i].ev
+				continue
+			}
+			if projection != pb.WatchCreateRequest_FULL {
+				events[i] = ProjectEvent(&evs[i], projection)
+				continue
+			}
+			events[i] = &evs[i]
+			// coalesced events already hold the previous key-value pair
+			// of the first event they replace
+			if needPrevKV && !evs[i].Coalesced {
+				events[i].PrevKv = sws.lookupPrevKV(evs[i])
 			}
+		}
 
-			canceled := wresp.CompactRevision != 0
-			wr := &pb.WatchResponse{
-				Header:          sws.newResponseHeader(wresp.Revision),
-				WatchId:         int64(wresp.WatchID),
-				Events:          events,
-				CompactRevision: wresp.CompactRevision,
-				Canceled:        canceled,
+		canceled := wresp.CompactRevision != 0
+		wr := &pb.WatchResponse{
+			Header:          sws.newResponseHeader(wresp.Revision),
+			WatchId:         int64(wresp.WatchID),
+			Events:          events,
+			CompactRevision: wresp.CompactRevision,
+			LostRevisions:   wresp.LostRevisions,
+			Canceled:        canceled,
+		}
+		if len(evs) == 0 && !canceled {
+			// progress notification
+			wr.ResumeToken = NewWatchResumeToken(uint64(sws.clusterID), wresp.Revision)
+		}
+		if l := limiters[wresp.WatchID]; l != nil {
+			wr.DroppedEvents, l.dropped = l.dropped, 0
+			if canceled {
+				delete(limiters, wresp.WatchID)
 			}
+		}
+
+		if _, okID := ids[wresp.WatchID]; !okID {
+			// buffer if id not yet announced
+			wrs := append(pending[wresp.WatchID], wr)
+			pending[wresp.WatchID] = wrs
+			return true
+		}
+
+		mvcc.ReportEventReceived(len(evs))
+
+		var serr error
+		switch {
+		case shared != nil:
+			serr = sws.sendShared(wr, shared, fragmented)
+		case !fragmented:
+			serr = sws.gRPCStream.Send(wr)
+		default:
+			serr = sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
+		}
+
+		if serr != nil {
+			if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
+				sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
+			} else {
+				sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
+				streamFailures.WithLabelValues("send", "watch").Inc()
+			}
+			return false
+		}
 
-			if _, okID := ids[wresp.WatchID]; !okID {
-				// buffer if id not yet announced
-				wrs := append(pending[wresp.WatchID], wr)
-				pending[wresp.WatchID] = wrs
+		sws.mu.Lock()
+		if len(evs) > 0 && sws.progress[wresp.WatchID] {
+			// elide next progress update if sent a key update
+			sws.progress[wresp.WatchID] = false
+		}
+		sws.mu.Unlock()
+		return true
+	}
+
+	// resetCoalesceTimer fires the timer at the end of the earliest
+	// coalescing window.
+	resetCoalesceTimer := func() {
+		if !coalesceTimer.Stop() {
+			select {
+			case <-coalesceTimer.C:
+			default:
+			}
+		}
+		var next time.Time
+		for _, c := range coalescers {
+			if next.IsZero() || c.deadline.Before(next) {
+				next = c.deadline
+			}
+		}
+		if !next.IsZero() {
+			coalesceTimer.Reset(time.Until(next))
+		}
+	}
+
+	// resetLimitTimer fires the timer when the earliest queued response
+	// of the watch ids is allowed by their delivery rate limit.
+	resetLimitTimer := func(now time.Time) {
+		if !limitTimer.Stop() {
+			select {
+			case <-limitTimer.C:
+			default:
+			}
+		}
+		next := time.Duration(-1)
+		for _, l := range limiters {
+			if len(l.queue) == 0 {
 				continue
 			}
+			if d := l.delay(now); next < 0 || d < next {
+				next = d
+			}
+		}
+		if next >= 0 {
+			limitTimer.Reset(next)
+		}
+	}
 
-			mvcc.ReportEventReceived(len(evs))
+	// drainLimiter sends the queued responses of a watch id allowed by its
+	// delivery rate limit at the given time.
+	drainLimiter := func(l *watchLimiter, now time.Time) bool {
+		for len(l.queue) > 0 && l.delay(now) == 0 {
+			if !send(l.pop()) {
+				return false
+			}
+		}
+		return true
+	}
 
-			sws.mu.RLock()
-			fragmented, ok := sws.fragment[wresp.WatchID]
-			sws.mu.RUnlock()
+	// cancelOverflow cancels a watch id queuing more events than allowed.
+	cancelOverflow := func(id mvcc.WatchID, l *watchLimiter) bool {
+		mvcc.ReportEventReceived(l.reset())
+		delete(limiters, id)
+		if err := sws.watchStream.Cancel(id); err != nil {
+			// already canceled by the client
+			return true
+		}
+		sws.mu.Lock()
+		delete(sws.progress, id)
+		delete(sws.prevKV, id)
+		delete(sws.fragment, id)
+		delete(sws.coalesce, id)
+		delete(sws.projection, id)
+		sws.mu.Unlock()
+		if co, ok := coalescers[id]; ok {
+			mvcc.ReportEventReceived(len(co.keys))
+			delete(coalescers, id)
+			resetCoalesceTimer()
+		}
+		overflowCanceledWatchers.Inc()
 
-			var serr error
-			if !fragmented && !ok {
-				serr = sws.gRPCStream.Send(wr)
+		wr := &pb.WatchResponse{
+			Header:       sws.newResponseHeader(sws.watchStream.Rev()),
+			WatchId:      int64(id),
+			Canceled:     true,
+			CancelReason: rpctypes.ErrorDesc(rpctypes.ErrGRPCWatchOverflow),
+		}
+		if _, okID := ids[id]; !okID {
+			// cancel after the id is announced
+			pending[id] = append(pending[id], wr)
+			return true
+		}
+		delete(ids, id)
+		if err := sws.gRPCStream.Send(wr); err != nil {
+			if isClientCtxErr(sws.gRPCStream.Context().Err(), err) {
+				sws.lg.Debug("failed to send watch overflow response to gRPC stream", zap.Error(err))
 			} else {
-				serr = sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
+				sws.lg.Warn("failed to send watch overflow response to gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("send", "watch").Inc()
 			}
+			return false
+		}
+		return true
+	}
 
-			if serr != nil {
-				if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
-					sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
-				} else {
-					sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
-					streamFailures.WithLabelValues("send", "watch").Inc()
+	// deliver sends the response within the delivery rate limit of its
+	// watch id, queuing it while it waits for the limit and applying the
+	// overflow policy once the watch id queues too many events.
+	deliver := func(wresp mvcc.WatchResponse) bool {
+		if sws.maxEventsPerSecond <= 0 {
+			return send(wresp)
+		}
+		now := time.Now()
+		l := limiters[wresp.WatchID]
+		if l == nil {
+			l = newWatchLimiter(sws.maxEventsPerSecond, now)
+			limiters[wresp.WatchID] = l
+		}
+		l.push(wresp)
+		if !drainLimiter(l, now) {
+			return false
+		}
+		if sws.maxQueuedEvents > 0 && int64(l.queued) > sws.maxQueuedEvents {
+			switch sws.overflowPolicy {
+			case WatchOverflowDropOldest:
+				dropped := l.dropOldest(int(sws.maxQueuedEvents))
+				mvcc.ReportEventReceived(dropped)
+				droppedEvents.Add(float64(dropped))
+			case WatchOverflowCancel:
+				if !cancelOverflow(wresp.WatchID, l) {
+					return false
+				}
+			default:
+				// block the stream, and so the watchable store sending to
+				// it, until the queue is back within the limit
+				for int64(l.queued) > sws.maxQueuedEvents {
+					if d := l.delay(time.Now()); d > 0 {
+						t := time.NewTimer(d)
+						select {
+						case <-t.C:
+						case <-sws.closec:
+							t.Stop()
+							return false
+						}
+					}
+					if !send(l.pop()) {
+						return false
+					}
 				}
+			}
+		}
+		resetLimitTimer(time.Now())
+		return true
+	}
+
+	// flushCoalescers sends the events buffered by the watch ids whose
+	// coalescing window ends by the given time.
+	flushCoalescers := func(by time.Time) bool {
+		for id, c := range coalescers {
+			if c.deadline.After(by) {
+				continue
+			}
+			delete(coalescers, id)
+			if !deliver(c.response(id)) {
+				return false
+			}
+		}
+		resetCoalesceTimer()
+		return true
+	}
+
+	drainc, draining := sws.drainc, false
+	for {
+		if draining && len(sws.watchStream.Chan()) == 0 && len(sws.ctrlStream) == 0 {
+			// send the events buffered for the coalescing windows and the
+			// delivery rate limits before ending the stream
+			if !flushCoalescers(time.Now().Add(maxCoalesceWindow)) {
 				return
 			}
+			for _, l := range limiters {
+				for len(l.queue) > 0 {
+					if !send(l.pop()) {
+						return
+					}
+				}
+			}
+			close(sws.drainedc)
+			return
+		}
 
-			sws.mu.Lock()
-			if len(evs) > 0 && sws.progress[wresp.WatchID] {
-				// elide next progress update if sent a key update
-				sws.progress[wresp.WatchID] = false
+		select {
+		case wresp, ok := <-sws.watchStream.Chan():
+			if !ok {
+				return
 			}
-			sws.mu.Unlock()
+
+			sws.mu.RLock()
+			window := sws.coalesce[wresp.WatchID]
+			sws.mu.RUnlock()
+			if window > 0 {
+				c := coalescers[wresp.WatchID]
+				if len(wresp.Events) > 0 && wresp.CompactRevision == 0 {
+					if c == nil {
+						c = newEventCoalescer(time.Now().Add(window))
+						coalescers[wresp.WatchID] = c
+						resetCoalesceTimer()
+					}
+					var prevKV func(mvccpb.Event) *mvccpb.KeyValue
+					sws.mu.RLock()
+					if sws.prevKV[wresp.WatchID] {
+						prevKV = sws.lookupPrevKV
+					}
+					sws.mu.RUnlock()
+					replaced := c.add(wresp, prevKV)
+					mvcc.ReportEventReceived(replaced)
+					coalescedEvents.Add(float64(replaced))
+					continue
+				}
+				if c != nil {
+					// send the buffered events first to keep the revisions
+					// of the responses in order
+					delete(coalescers, wresp.WatchID)
+					resetCoalesceTimer()
+					if !deliver(c.response(wresp.WatchID)) {
+						return
+					}
+				}
+			}
+			if !deliver(wresp) {
+				return
+			}
+
+		case <-coalesceTimer.C:
+			if !flushCoalescers(time.Now()) {
+				return
+			}
+
+		case <-limitTimer.C:
+			now := time.Now()
+			for _, l := range limiters {
+				if !drainLimiter(l, now) {
+					return
+				}
+			}
+			resetLimitTimer(now)
 
 		case c, ok := <-sws.ctrlStream:
 			if !ok {
 				return
 			}
 
+			// a progress notification of the stream must not get ahead of
+			// the events buffered by the watch ids coalescing them, all of
+			// which end their window within the longest one, nor of the
+			// responses queued for the delivery rate limit
+			if c.WatchId == clientv3.InvalidWatchID && !c.Created {
+				if !flushCoalescers(time.Now().Add(maxCoalesceWindow)) {
+					return
+				}
+				for _, l := range limiters {
+					for len(l.queue) > 0 {
+						if !send(l.pop()) {
+							return
+						}
+					}
+				}
+			}
+
 			if err := sws.gRPCStream.Send(c); err != nil {
 				if isClientCtxErr(sws.gRPCStream.Context().Err(), err) {
 					sws.lg.Debug("failed to send watch control response to gRPC stream", zap.Error(err))
@@ -491,6 +916,15 @@ func (sws *serverWatchStream) sendLoop() {
 
 			if c.Canceled && wid != clientv3.InvalidWatchID {
 				delete(ids, wid)
+				if co, ok := coalescers[wid]; ok {
+					mvcc.ReportEventReceived(len(co.keys))
+					delete(coalescers, wid)
+					resetCoalesceTimer()
+				}
+				if l, ok := limiters[wid]; ok {
+					mvcc.ReportEventReceived(l.reset())
+					delete(limiters, wid)
+				}
 				continue
 			}
 			if c.Created {
@@ -521,12 +955,42 @@ func (sws *serverWatchStream) sendLoop() {
 			}
 			sws.mu.Unlock()
 
+		case <-drainc:
+			drainc, draining = nil, true
+
 		case <-sws.closec:
 			return
 		}
 	}
 }
 
+// lookupPrevKV returns the key-value pair before the event, or nil if the
+// event created the key or the pair is compacted.
+func (sws *serverWatchStream) lookupPrevKV(ev mvccpb.Event) *mvccpb.KeyValue {
+	if IsCreateEvent(ev) {
+		return nil
+	}
+	opt := mvcc.RangeOptions{Rev: ev.Kv.ModRevision - 1}
+	r, err := sws.watchable.Range(context.TODO(), ev.Kv.Key, nil, opt)
+	if err != nil || len(r.KVs) == 0 {
+		return nil
+	}
+	return &r.KVs[0]
+}
+
+// sendShared sends the response with the shared encodings of its events,
+// unless the response has to be fragmented.
+func (sws *serverWatchStream) sendShared(wr *pb.WatchResponse, shared []*sharedEvent, fragmented bool) error {
+	ew, err := encodeWatchResponse(wr, shared)
+	if err != nil {
+		return err
+	}
+	if fragmented && len(ew.data) >= sws.maxRequestBytes {
+		return sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
+	}
+	return sws.gRPCStream.SendMsg(ew)
+}
+
 func IsCreateEvent(e mvccpb.Event) bool {
 	return e.Type == mvccpb.PUT && e.Kv.CreateRevision == e.Kv.ModRevision
 }
@@ -594,8 +1058,9 @@ func filterNoPut(e mvccpb.Event) bool {
 }
 
 // FiltersFromRequest returns "mvcc.FilterFunc" from a given watch create request.
-func FiltersFromRequest(creq *pb.WatchCreateRequest) []mvcc.FilterFunc {
-	filters := make([]mvcc.FilterFunc, 0, len(creq.Filters))
+// It fails if the value filter of the request is invalid.
+func FiltersFromRequest(creq *pb.WatchCreateRequest) ([]mvcc.FilterFunc, error) {
+	filters := make([]mvcc.FilterFunc, 0, len(creq.Filters)+1)
 	for _, ft := range creq.Filters {
 		switch ft {
 		case pb.WatchCreateRequest_NOPUT:
@@ -605,5 +1070,17 @@ func FiltersFromRequest(creq *pb.WatchCreateRequest) []mvcc.FilterFunc {
 		default:
 		}
 	}
-	return filters
+	if vf := creq.ValueFilter; vf != nil {
+		f, err := mvcc.NewValueFilterFunc(mvcc.ValueFilter{
+			Prefix:    vf.Prefix,
+			Regex:     vf.Regex,
+			JSONField: vf.JsonField,
+			JSONValue: vf.JsonValue,
+		})
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
 }
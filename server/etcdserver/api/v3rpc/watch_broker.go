@@ -0,0 +1,193 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+const (
+	// brokerRetainedRevisions is the number of the latest revisions whose
+	// event encodings are retained by a watch broker.
+	brokerRetainedRevisions = 64
+	// maxBrokerBytes bounds the size of the event encodings retained by a
+	// watch broker.
+	maxBrokerBytes = 64 * 1024 * 1024
+
+	// watchResponseEventsTag is the tag of the events field of a watch
+	// response, a length-delimited field numbered 11.
+	watchResponseEventsTag = 11<<3 | 2
+)
+
+// eventSpec is the part of a watch spec that shapes the events sent to the
+// watcher. Watchers with the same event spec share the encodings of the
+// events they receive.
+type eventSpec struct {
+	prevKV     bool
+	projection pb.WatchCreateRequest_Projection
+}
+
+type sharedEventKey struct {
+	key  string
+	spec eventSpec
+}
+
+// sharedEvent is an event ready to be sent to any watcher of its spec.
+type sharedEvent struct {
+	ev *mvccpb.Event
+	// data is the encoding of the event as an element of the events of a
+	// watch response, field tag included.
+	data []byte
+}
+
+func newSharedEvent(ev mvccpb.Event) (*sharedEvent, error) {
+	n := ev.Size()
+	data := make([]byte, 0, 1+binary.MaxVarintLen64+n)
+	data = append(data, watchResponseEventsTag)
+	data = binary.AppendUvarint(data, uint64(n))
+	data = data[:len(data)+n]
+	if _, err := ev.MarshalTo(data[len(data)-n:]); err != nil {
+		return nil, err
+	}
+	return &sharedEvent{ev: &ev, data: data}, nil
+}
+
+// watchBroker fans out the events sent by the watch streams of a server.
+// The events of the latest revisions are encoded, and their previous
+// key-value pairs looked up, once per event spec, however many watchers
+// receive them; the encodings are shared by all the streams sending them.
+type watchBroker struct {
+	mu sync.Mutex
+	// revs are the revisions of the retained encodings, in ascending order
+	revs   []int64
+	events map[int64]map[sharedEventKey]*sharedEvent
+	// size is the total size of the retained encodings
+	size int
+}
+
+func newWatchBroker() *watchBroker {
+	return &watchBroker{events: make(map[int64]map[sharedEventKey]*sharedEvent)}
+}
+
+// sharedEvents returns the shared encodings of the events for the given
+// spec, encoding the events not encoded yet. prevKV looks up the previous
+// key-value pair of an event if the spec requires it.
+func (b *watchBroker) sharedEvents(evs []mvccpb.Event, spec eventSpec, prevKV func(mvccpb.Event) *mvccpb.KeyValue) ([]*sharedEvent, error) {
+	shared := make([]*sharedEvent, len(evs))
+	for i, ev := range evs {
+		// coalesced events are specific to the watcher coalescing them and
+		// already hold their previous key-value pair
+		if ev.Coalesced {
+			se, err := newSharedEvent(*ProjectEvent(&ev, spec.projection))
+			if err != nil {
+				return nil, err
+			}
+			shared[i] = se
+			continue
+		}
+
+		rev, k := ev.Kv.ModRevision, sharedEventKey{key: string(ev.Kv.Key), spec: spec}
+		b.mu.Lock()
+		se, ok := b.events[rev][k]
+		b.mu.Unlock()
+		if ok {
+			sharedEventEncodings.Inc()
+			shared[i] = se
+			continue
+		}
+
+		if spec.prevKV {
+			ev.PrevKv = prevKV(ev)
+		}
+		se, err := newSharedEvent(*ProjectEvent(&ev, spec.projection))
+		if err != nil {
+			return nil, err
+		}
+		b.mu.Lock()
+		shared[i] = b.add(rev, k, se)
+		b.mu.Unlock()
+	}
+	return shared, nil
+}
+
+// add retains the encoding of the event, unless an encoding of the same
+// event was retained concurrently, and returns the retained encoding.
+func (b *watchBroker) add(rev int64, k sharedEventKey, se *sharedEvent) *sharedEvent {
+	evs, ok := b.events[rev]
+	if ok {
+		if cur, ok := evs[k]; ok {
+			return cur
+		}
+	} else {
+		if len(b.revs) >= brokerRetainedRevisions && rev < b.revs[0] {
+			// older than all the retained revisions, only sent to the
+			// watchers catching up
+			return se
+		}
+		evs = make(map[sharedEventKey]*sharedEvent)
+		b.events[rev] = evs
+		i := sort.Search(len(b.revs), func(i int) bool { return b.revs[i] > rev })
+		b.revs = append(b.revs, 0)
+		copy(b.revs[i+1:], b.revs[i:])
+		b.revs[i] = rev
+	}
+	evs[k] = se
+	b.size += len(se.data)
+	for len(b.revs) > brokerRetainedRevisions || b.size > maxBrokerBytes {
+		b.evictOldest()
+	}
+	return se
+}
+
+func (b *watchBroker) evictOldest() {
+	rev := b.revs[0]
+	b.revs = b.revs[1:]
+	for _, se := range b.events[rev] {
+		b.size -= len(se.data)
+	}
+	delete(b.events, rev)
+}
+
+// encodedWatchResponse is a watch response encoded with the shared
+// encodings of its events. The server codec sends it as is.
+type encodedWatchResponse struct {
+	data []byte
+}
+
+// encodeWatchResponse encodes the response, whose events are the given
+// shared events, appending the shared encodings of the events to the
+// encoding of the other fields.
+func encodeWatchResponse(wr *pb.WatchResponse, shared []*sharedEvent) (*encodedWatchResponse, error) {
+	hdr := *wr
+	hdr.Events = nil
+	n := hdr.Size()
+	size := n
+	for _, se := range shared {
+		size += len(se.data)
+	}
+	data := make([]byte, n, size)
+	if _, err := hdr.MarshalToSizedBuffer(data); err != nil {
+		return nil, err
+	}
+	for _, se := range shared {
+		data = append(data, se.data...)
+	}
+	return &encodedWatchResponse{data: data}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func brokerPut(key string, rev int64) mvccpb.Event {
+	return mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte("v"), CreateRevision: 1, ModRevision: rev}}
+}
+
+func TestWatchBrokerSharedEvents(t *testing.T) {
+	var lookups int
+	prevKV := func(ev mvccpb.Event) *mvccpb.KeyValue {
+		lookups++
+		return &mvccpb.KeyValue{Key: ev.Kv.Key, ModRevision: ev.Kv.ModRevision - 1}
+	}
+	b := newWatchBroker()
+	evs := []mvccpb.Event{brokerPut("a", 2), brokerPut("b", 2)}
+
+	s1, err := b.sharedEvents(evs, eventSpec{prevKV: true}, prevKV)
+	require.NoError(t, err)
+	s2, err := b.sharedEvents(evs, eventSpec{prevKV: true}, prevKV)
+	require.NoError(t, err)
+	s3, err := b.sharedEvents(evs[1:], eventSpec{}, prevKV)
+	require.NoError(t, err)
+
+	// the watchers of the same spec share the encodings, and the previous
+	// key-value pairs are looked up once
+	assert.Equal(t, 2, lookups)
+	assert.Same(t, s1[0], s2[0])
+	assert.Same(t, s1[1], s2[1])
+	assert.NotSame(t, s1[1], s3[0])
+	assert.Equal(t, int64(1), s1[1].ev.PrevKv.ModRevision)
+	assert.Nil(t, s3[0].ev.PrevKv)
+
+	// coalesced events are never shared
+	coalesced := brokerPut("a", 2)
+	coalesced.Coalesced = true
+	s4, err := b.sharedEvents([]mvccpb.Event{coalesced}, eventSpec{prevKV: true}, prevKV)
+	require.NoError(t, err)
+	assert.NotSame(t, s1[0], s4[0])
+	assert.True(t, s4[0].ev.Coalesced)
+	assert.Equal(t, 2, lookups)
+}
+
+func TestWatchBrokerEviction(t *testing.T) {
+	b := newWatchBroker()
+	for rev := int64(1); rev <= brokerRetainedRevisions+10; rev++ {
+		_, err := b.sharedEvents([]mvccpb.Event{brokerPut("a", rev)}, eventSpec{}, nil)
+		require.NoError(t, err)
+	}
+	assert.Len(t, b.revs, brokerRetainedRevisions)
+	assert.Len(t, b.events, brokerRetainedRevisions)
+	assert.Equal(t, int64(11), b.revs[0])
+
+	// revisions older than the retained ones are not retained
+	_, err := b.sharedEvents([]mvccpb.Event{brokerPut("a", 1)}, eventSpec{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), b.revs[0])
+
+	var size int
+	for _, evs := range b.events {
+		for _, se := range evs {
+			size += len(se.data)
+		}
+	}
+	assert.Equal(t, size, b.size)
+}
+
+func TestEncodeWatchResponse(t *testing.T) {
+	b := newWatchBroker()
+	shared, err := b.sharedEvents([]mvccpb.Event{brokerPut("a", 2), brokerPut("b", 3)}, eventSpec{}, nil)
+	require.NoError(t, err)
+
+	wr := &pb.WatchResponse{
+		Header:   &pb.ResponseHeader{ClusterId: 1, MemberId: 2, Revision: 3, RaftTerm: 4},
+		WatchId:  5,
+		Fragment: true,
+		Events:   []*mvccpb.Event{shared[0].ev, shared[1].ev},
+	}
+	ew, err := encodeWatchResponse(wr, shared)
+	require.NoError(t, err)
+
+	var got pb.WatchResponse
+	require.NoError(t, got.Unmarshal(ew.data))
+	assert.Equal(t, wr.Size(), len(ew.data))
+	assert.Equal(t, wr.String(), got.String())
+
+	data, err := (&codec{}).Marshal(ew)
+	require.NoError(t, err)
+	assert.Equal(t, ew.data, data)
+}
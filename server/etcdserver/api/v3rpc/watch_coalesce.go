@@ -0,0 +1,91 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// maxCoalesceWindow caps the window a watcher coalesces the events of its
+// keys within.
+const maxCoalesceWindow = 10 * time.Second
+
+// coalesceWindow returns the window the watcher created by the request
+// coalesces the events of its keys within, or 0 if it does not coalesce them.
+func coalesceWindow(creq *pb.WatchCreateRequest) time.Duration {
+	if creq.CoalesceWindowMs <= 0 {
+		return 0
+	}
+	if creq.CoalesceWindowMs > maxCoalesceWindow.Milliseconds() {
+		return maxCoalesceWindow
+	}
+	return time.Duration(creq.CoalesceWindowMs) * time.Millisecond
+}
+
+// eventCoalescer buffers the events of a watcher until the end of its
+// coalescing window, replacing the buffered event of a key by its latest one.
+type eventCoalescer struct {
+	deadline time.Time
+
+	// rev is the revision of the latest response buffered.
+	rev int64
+	// events are the buffered events in the order of their revisions.
+	// The replaced events are left empty.
+	events []mvccpb.Event
+	// keys maps the keys to the index of their latest event in events.
+	keys map[string]int
+}
+
+func newEventCoalescer(deadline time.Time) *eventCoalescer {
+	return &eventCoalescer{deadline: deadline, keys: make(map[string]int)}
+}
+
+// add buffers the events of the response and returns the number of buffered
+// events replaced by them. If prevKV is not nil, it looks up the previous
+// key-value pair of the first event replaced for each key.
+func (c *eventCoalescer) add(wresp mvcc.WatchResponse, prevKV func(mvccpb.Event) *mvccpb.KeyValue) (replaced int) {
+	c.rev = wresp.Revision
+	for _, ev := range wresp.Events {
+		key := string(ev.Kv.Key)
+		if i, ok := c.keys[key]; ok {
+			first := c.events[i]
+			if !first.Coalesced && prevKV != nil {
+				first.PrevKv = prevKV(first)
+			}
+			ev.PrevKv, ev.Coalesced = first.PrevKv, true
+			c.events[i] = mvccpb.Event{}
+			replaced++
+		}
+		c.keys[key] = len(c.events)
+		c.events = append(c.events, ev)
+	}
+	return replaced
+}
+
+// response returns the response of the watcher with the latest event of each
+// key buffered.
+func (c *eventCoalescer) response(id mvcc.WatchID) mvcc.WatchResponse {
+	evs := make([]mvccpb.Event, 0, len(c.keys))
+	for _, ev := range c.events {
+		if ev.Kv != nil {
+			evs = append(evs, ev)
+		}
+	}
+	return mvcc.WatchResponse{WatchID: id, Events: evs, Revision: c.rev}
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+func TestCoalesceWindow(t *testing.T) {
+	tests := []struct {
+		windowMs int64
+		want     time.Duration
+	}{
+		{windowMs: -1, want: 0},
+		{windowMs: 0, want: 0},
+		{windowMs: 250, want: 250 * time.Millisecond},
+		{windowMs: 60000, want: maxCoalesceWindow},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, coalesceWindow(&pb.WatchCreateRequest{CoalesceWindowMs: tt.windowMs}))
+	}
+}
+
+func TestEventCoalescer(t *testing.T) {
+	put := func(key string, createRev, modRev int64) mvccpb.Event {
+		return mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte(key), CreateRevision: createRev, ModRevision: modRev}}
+	}
+	var lookups []int64
+	prevKV := func(ev mvccpb.Event) *mvccpb.KeyValue {
+		lookups = append(lookups, ev.Kv.ModRevision)
+		return &mvccpb.KeyValue{Key: ev.Kv.Key, ModRevision: ev.Kv.ModRevision - 1}
+	}
+
+	c := newEventCoalescer(time.Now())
+	assert.Equal(t, 0, c.add(mvcc.WatchResponse{Events: []mvccpb.Event{put("a", 1, 2), put("b", 3, 3)}, Revision: 3}, prevKV))
+	assert.Equal(t, 1, c.add(mvcc.WatchResponse{Events: []mvccpb.Event{put("a", 1, 4)}, Revision: 4}, prevKV))
+	assert.Equal(t, 1, c.add(mvcc.WatchResponse{Events: []mvccpb.Event{put("a", 1, 5)}, Revision: 5}, prevKV))
+	// the previous key-value pair is only looked up for the first event
+	// replaced
+	assert.Equal(t, []int64{2}, lookups)
+
+	wresp := c.response(7)
+	assert.Equal(t, mvcc.WatchID(7), wresp.WatchID)
+	assert.Equal(t, int64(5), wresp.Revision)
+	if assert.Len(t, wresp.Events, 2) {
+		assert.Equal(t, "b", string(wresp.Events[0].Kv.Key))
+		assert.False(t, wresp.Events[0].Coalesced)
+		assert.Equal(t, "a", string(wresp.Events[1].Kv.Key))
+		assert.Equal(t, int64(5), wresp.Events[1].Kv.ModRevision)
+		assert.True(t, wresp.Events[1].Coalesced)
+		assert.Equal(t, int64(1), wresp.Events[1].PrevKv.ModRevision)
+	}
+}
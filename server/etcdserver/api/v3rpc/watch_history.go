@@ -0,0 +1,103 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// minRevisionHistoryInterval is the minimum interval at which the revision
+// history is sampled.
+const minRevisionHistoryInterval = 10 * time.Millisecond
+
+// revisionSample is the revision current at a time.
+type revisionSample struct {
+	t   time.Time
+	rev int64
+}
+
+// revisionHistory keeps the current revision sampled over the last age, to
+// find the revision that was current age ago. Like the periodic compactor,
+// it only knows the revisions of its member since it started.
+type revisionHistory struct {
+	age time.Duration
+
+	mu sync.Mutex
+	// samples are in time order. The first one is the newest older than age,
+	// once the history covers age.
+	samples []revisionSample
+}
+
+func newRevisionHistory(age time.Duration) *revisionHistory {
+	return &revisionHistory{age: age}
+}
+
+// interval returns the interval at which the history is sampled, so that
+// the revision found age ago is at most a tenth of age older.
+func (h *revisionHistory) interval() time.Duration {
+	if d := h.age / 10; d > minRevisionHistoryInterval {
+		return d
+	}
+	return minRevisionHistoryInterval
+}
+
+// add records rev as the current revision at now, and drops the samples no
+// longer needed.
+func (h *revisionHistory) add(now time.Time, rev int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples = append(h.samples, revisionSample{t: now, rev: rev})
+	// keep the newest sample older than age.
+	i := sort.Search(len(h.samples), func(i int) bool { return now.Sub(h.samples[i].t) < h.age })
+	if i > 1 {
+		h.samples = append(h.samples[:0], h.samples[i-1:]...)
+	}
+}
+
+// revisionAt returns the revision current at t, false if the history does
+// not go back to t.
+func (h *revisionHistory) revisionAt(t time.Time) (int64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := sort.Search(len(h.samples), func(i int) bool { return h.samples[i].t.After(t) })
+	if i == 0 {
+		return 0, false
+	}
+	return h.samples[i-1].rev, true
+}
+
+// isTooOld returns true if the events from rev on include events written more
+// than age before now, that is if rev was already current age ago.
+func (h *revisionHistory) isTooOld(now time.Time, rev int64) bool {
+	old, ok := h.revisionAt(now.Add(-h.age))
+	return ok && rev <= old
+}
+
+// run samples rev every interval until stopc is closed.
+func (h *revisionHistory) run(rev func() int64, stopc <-chan struct{}) {
+	ticker := time.NewTicker(h.interval())
+	defer ticker.Stop()
+	h.add(time.Now(), rev())
+	for {
+		select {
+		case now := <-ticker.C:
+			h.add(now, rev())
+		case <-stopc:
+			return
+		}
+	}
+}
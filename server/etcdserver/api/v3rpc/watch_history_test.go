@@ -0,0 +1,50 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	h := newRevisionHistory(10 * time.Second)
+	start := time.Unix(1000, 0)
+
+	// the history does not go back to age yet
+	h.add(start, 5)
+	if h.isTooOld(start.Add(5*time.Second), 1) {
+		t.Fatal("expected no revision too old before the history covers the age")
+	}
+
+	for i := 1; i <= 20; i++ {
+		h.add(start.Add(time.Duration(i)*time.Second), int64(5+i))
+	}
+	now := start.Add(20 * time.Second)
+	// revision 15 was current 10s ago, revision 16 was written since.
+	if rev, ok := h.revisionAt(now.Add(-10 * time.Second)); !ok || rev != 15 {
+		t.Fatalf("revisionAt = %d, %t, want 15, true", rev, ok)
+	}
+	if !h.isTooOld(now, 15) {
+		t.Error("expected revision 15 to be too old")
+	}
+	if h.isTooOld(now, 16) {
+		t.Error("expected revision 16 not to be too old")
+	}
+	// the samples older than the age are dropped, but the newest one.
+	if len(h.samples) != 11 || h.samples[0].rev != 15 {
+		t.Errorf("kept %d samples from revision %d, want 11 from revision 15", len(h.samples), h.samples[0].rev)
+	}
+}
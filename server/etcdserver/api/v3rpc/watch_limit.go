@@ -0,0 +1,118 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// The policies applied to the watchers queuing more events than allowed
+// while waiting for their delivery rate limit.
+const (
+	// WatchOverflowDropOldest drops the oldest queued responses of the
+	// watcher. The number of events dropped is reported in its next response.
+	WatchOverflowDropOldest = "drop-oldest"
+	// WatchOverflowCancel cancels the watcher.
+	WatchOverflowCancel = "cancel"
+	// WatchOverflowBlock blocks the watch stream of the watcher until its
+	// queue is back within the limit, pushing back on the watchable store.
+	WatchOverflowBlock = "block"
+)
+
+// ValidateWatchOverflowPolicy returns an error if the policy is unknown. The
+// empty policy blocks.
+func ValidateWatchOverflowPolicy(policy string) error {
+	switch policy {
+	case "", WatchOverflowDropOldest, WatchOverflowCancel, WatchOverflowBlock:
+		return nil
+	}
+	return fmt.Errorf("unknown watch overflow policy %q, expected %q, %q or %q", policy, WatchOverflowDropOldest, WatchOverflowCancel, WatchOverflowBlock)
+}
+
+// watchLimiter limits the rate at which a watcher is sent events with a
+// token bucket holding up to a second of events, and queues the responses
+// waiting for it.
+type watchLimiter struct {
+	rate float64
+	// tokens is the number of events the watcher can be sent right away.
+	// A response is sent as soon as tokens is not negative, whatever its
+	// number of events, leaving the bucket in debt.
+	tokens float64
+	last   time.Time
+
+	queue []mvcc.WatchResponse
+	// queued is the number of events in queue.
+	queued int
+	// dropped is the number of events dropped since the last response sent.
+	dropped int64
+}
+
+func newWatchLimiter(rate int64, now time.Time) *watchLimiter {
+	return &watchLimiter{rate: float64(rate), tokens: float64(rate), last: now}
+}
+
+// delay returns how long the next response has to wait for the rate limit.
+func (l *watchLimiter) delay(now time.Time) time.Duration {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.rate {
+			l.tokens = l.rate
+		}
+		l.last = now
+	}
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *watchLimiter) push(wresp mvcc.WatchResponse) {
+	l.queue = append(l.queue, wresp)
+	l.queued += len(wresp.Events)
+}
+
+// pop dequeues the oldest response and takes the tokens of its events.
+func (l *watchLimiter) pop() mvcc.WatchResponse {
+	wresp := l.queue[0]
+	l.queue[0] = mvcc.WatchResponse{}
+	l.queue = l.queue[1:]
+	l.queued -= len(wresp.Events)
+	l.tokens -= float64(len(wresp.Events))
+	return wresp
+}
+
+// dropOldest drops the oldest responses until at most max events are queued,
+// always keeping the newest response, and returns the number of events dropped.
+func (l *watchLimiter) dropOldest(max int) (dropped int) {
+	for l.queued > max && len(l.queue) > 1 {
+		n := len(l.queue[0].Events)
+		l.queue[0] = mvcc.WatchResponse{}
+		l.queue = l.queue[1:]
+		l.queued -= n
+		dropped += n
+	}
+	l.dropped += int64(dropped)
+	return dropped
+}
+
+// reset drops all the queued responses and returns the number of events dropped.
+func (l *watchLimiter) reset() int {
+	n := l.queued
+	l.queue, l.queued = nil, 0
+	return n
+}
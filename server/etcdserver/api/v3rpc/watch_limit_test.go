@@ -0,0 +1,85 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+func limitResponse(rev int64, n int) mvcc.WatchResponse {
+	evs := make([]mvccpb.Event, n)
+	for i := range evs {
+		evs[i] = brokerPut("a", rev)
+	}
+	return mvcc.WatchResponse{Revision: rev, Events: evs}
+}
+
+func TestValidateWatchOverflowPolicy(t *testing.T) {
+	for _, policy := range []string{"", WatchOverflowDropOldest, WatchOverflowCancel, WatchOverflowBlock} {
+		assert.NoError(t, ValidateWatchOverflowPolicy(policy))
+	}
+	assert.Error(t, ValidateWatchOverflowPolicy("drop-newest"))
+}
+
+func TestWatchLimiterDelay(t *testing.T) {
+	now := time.Now()
+	l := newWatchLimiter(10, now)
+
+	// a response is sent while the bucket is not in debt, whatever its size
+	l.push(limitResponse(2, 15))
+	assert.Zero(t, l.delay(now))
+	assert.Equal(t, int64(2), l.pop().Revision)
+	assert.Equal(t, 0, l.queued)
+
+	// the debt of 5 events is paid in half a second
+	assert.Equal(t, 500*time.Millisecond, l.delay(now))
+	assert.Equal(t, 300*time.Millisecond, l.delay(now.Add(200*time.Millisecond)))
+	assert.Zero(t, l.delay(now.Add(500*time.Millisecond)))
+
+	// the bucket holds at most a second of events
+	assert.Zero(t, l.delay(now.Add(time.Hour)))
+	assert.Equal(t, float64(10), l.tokens)
+}
+
+func TestWatchLimiterDropOldest(t *testing.T) {
+	l := newWatchLimiter(10, time.Now())
+	l.push(limitResponse(2, 3))
+	l.push(limitResponse(3, 3))
+	l.push(limitResponse(4, 0))
+	l.push(limitResponse(5, 5))
+	assert.Equal(t, 11, l.queued)
+
+	assert.Equal(t, 6, l.dropOldest(5))
+	assert.Equal(t, 5, l.queued)
+	assert.Equal(t, int64(6), l.dropped)
+	assert.Len(t, l.queue, 2)
+	assert.Equal(t, int64(4), l.queue[0].Revision)
+
+	// the newest response is kept, whatever its size
+	assert.Equal(t, 0, l.dropOldest(1))
+	assert.Len(t, l.queue, 1)
+	assert.Equal(t, int64(5), l.queue[0].Revision)
+	assert.Equal(t, int64(6), l.dropped)
+
+	assert.Equal(t, 5, l.reset())
+	assert.Empty(t, l.queue)
+	assert.Equal(t, 0, l.queued)
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// ValidWatchProjection returns true if the projection is known.
+func ValidWatchProjection(p pb.WatchCreateRequest_Projection) bool {
+	_, ok := pb.WatchCreateRequest_Projection_name[int32(p)]
+	return ok
+}
+
+// ProjectEvent returns a copy of the event stripped down to the parts of its
+// key-value pair kept by the projection, without its previous key-value
+// pair. The event itself is returned for the FULL projection.
+func ProjectEvent(ev *mvccpb.Event, p pb.WatchCreateRequest_Projection) *mvccpb.Event {
+	if p == pb.WatchCreateRequest_FULL || ev.Kv == nil {
+		return ev
+	}
+	kv := &mvccpb.KeyValue{Key: ev.Kv.Key, ModRevision: ev.Kv.ModRevision}
+	if p == pb.WatchCreateRequest_METADATA_ONLY {
+		kv.CreateRevision = ev.Kv.CreateRevision
+		kv.Version = ev.Kv.Version
+		kv.Lease = ev.Kv.Lease
+	}
+	return &mvccpb.Event{Type: ev.Type, Kv: kv, Coalesced: ev.Coalesced}
+}
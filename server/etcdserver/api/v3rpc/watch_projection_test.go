@@ -0,0 +1,64 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestProjectEvent(t *testing.T) {
+	ev := &mvccpb.Event{
+		Type:   mvccpb.PUT,
+		Kv:     &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("v"), CreateRevision: 2, ModRevision: 3, Version: 2, Lease: 5},
+		PrevKv: &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("u"), CreateRevision: 2, ModRevision: 2, Version: 1, Lease: 5},
+	}
+
+	tests := []struct {
+		projection pb.WatchCreateRequest_Projection
+		want       *mvccpb.Event
+	}{
+		{
+			pb.WatchCreateRequest_FULL,
+			ev,
+		},
+		{
+			pb.WatchCreateRequest_KEYS_ONLY,
+			&mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), ModRevision: 3}},
+		},
+		{
+			pb.WatchCreateRequest_METADATA_ONLY,
+			&mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), CreateRevision: 2, ModRevision: 3, Version: 2, Lease: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.projection.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, ProjectEvent(ev, tt.projection))
+		})
+	}
+	// the event itself is left as is
+	assert.Equal(t, []byte("v"), ev.Kv.Value)
+	assert.NotNil(t, ev.PrevKv)
+}
+
+func TestValidWatchProjection(t *testing.T) {
+	assert.True(t, ValidWatchProjection(pb.WatchCreateRequest_FULL))
+	assert.True(t, ValidWatchProjection(pb.WatchCreateRequest_METADATA_ONLY))
+	assert.False(t, ValidWatchProjection(pb.WatchCreateRequest_Projection(3)))
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// watchResumeTokenVersion is the version of the format of the watch
+	// resume tokens: the version byte, followed by the cluster ID and the
+	// revision of the token, both big endian.
+	watchResumeTokenVersion = 1
+	watchResumeTokenLen     = 1 + 8 + 8
+)
+
+// NewWatchResumeToken returns the opaque token resuming a watch of the given
+// cluster right after the given revision.
+func NewWatchResumeToken(clusterID uint64, rev int64) []byte {
+	token := make([]byte, watchResumeTokenLen)
+	token[0] = watchResumeTokenVersion
+	binary.BigEndian.PutUint64(token[1:], clusterID)
+	binary.BigEndian.PutUint64(token[9:], uint64(rev))
+	return token
+}
+
+// ParseWatchResumeToken returns the cluster ID and the revision of a watch
+// resume token.
+func ParseWatchResumeToken(token []byte) (clusterID uint64, rev int64, err error) {
+	if len(token) != watchResumeTokenLen {
+		return 0, 0, fmt.Errorf("watch resume token has %d bytes, expected %d", len(token), watchResumeTokenLen)
+	}
+	if token[0] != watchResumeTokenVersion {
+		return 0, 0, fmt.Errorf("unsupported watch resume token version %d", token[0])
+	}
+	clusterID = binary.BigEndian.Uint64(token[1:])
+	rev = int64(binary.BigEndian.Uint64(token[9:]))
+	if rev < 0 {
+		return 0, 0, fmt.Errorf("watch resume token has negative revision %d", rev)
+	}
+	return clusterID, rev, nil
+}
+
+// resumeRevision returns the revision to start the watch of the request
+// from, right after the revision of its resume token.
+func (sws *serverWatchStream) resumeRevision(token []byte, startRev int64) (int64, error) {
+	if startRev != 0 {
+		return 0, errors.New("watch start revision set along with a resume token")
+	}
+	clusterID, rev, err := ParseWatchResumeToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if clusterID != uint64(sws.clusterID) {
+		return 0, fmt.Errorf("watch resume token of cluster %x, expected %x", clusterID, uint64(sws.clusterID))
+	}
+	return rev + 1, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchResumeToken(t *testing.T) {
+	token := NewWatchResumeToken(0xabc, 42)
+	clusterID, rev, err := ParseWatchResumeToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0xabc), clusterID)
+	assert.Equal(t, int64(42), rev)
+
+	bad := append([]byte{}, token...)
+	bad[0] = 2
+	for _, token := range [][]byte{nil, token[:10], bad} {
+		_, _, err = ParseWatchResumeToken(token)
+		assert.Error(t, err)
+	}
+}
+
+func TestResumeRevision(t *testing.T) {
+	sws := &serverWatchStream{clusterID: 0xabc}
+	tests := []struct {
+		token    []byte
+		startRev int64
+		want     int64
+		wantErr  bool
+	}{
+		{token: NewWatchResumeToken(0xabc, 42), want: 43},
+		{token: NewWatchResumeToken(0xabc, 42), startRev: 10, wantErr: true},
+		{token: NewWatchResumeToken(0xdef, 42), wantErr: true},
+		{token: []byte("foo"), wantErr: true},
+	}
+	for i, tt := range tests {
+		rev, err := sws.resumeRevision(tt.token, tt.startRev)
+		if tt.wantErr {
+			assert.Error(t, err, "#%d", i)
+			continue
+		}
+		require.NoError(t, err, "#%d", i)
+		assert.Equal(t, tt.want, rev, "#%d", i)
+	}
+}
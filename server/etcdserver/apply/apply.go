@@ -15,6 +15,7 @@
 package apply
 
 import (
+	"bytes"
 	"context"
 
 	"go.uber.org/zap"
@@ -27,6 +28,9 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3revisionpin"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	"go.etcd.io/etcd/server/v3/etcdserver/errors"
 	mvcctxn "go.etcd.io/etcd/server/v3/etcdserver/txn"
@@ -79,11 +83,20 @@ type applierV3 interface {
 
 	LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error)
 	LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseExpire(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseGrantBatch(lc *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error)
+	LeaseRevokeBatch(lc *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error)
 
 	LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error)
 
+	ChangeFeedCheckpoint(ctx context.Context, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error)
+
 	Alarm(*pb.AlarmRequest) (*pb.AlarmResponse, error)
 
+	PrefixQuota(*pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error)
+
+	RevisionPin(*pb.RevisionPinRequest) (*pb.RevisionPinResponse, error)
+
 	Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error)
 
 	AuthEnable() (*pb.AuthEnableResponse, error)
@@ -101,6 +114,11 @@ type applierV3 interface {
 	RoleGet(ua *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse, error)
 	RoleRevokePermission(ua *pb.AuthRoleRevokePermissionRequest) (*pb.AuthRoleRevokePermissionResponse, error)
 	RoleDelete(ua *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
+	RoleSetMaxLeaseTTL(ua *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error)
+	RoleSetQuota(ua *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error)
+	AuthSetBcryptCost(ua *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error)
+	AuthTokenList(ua *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error)
+	AuthTokenRevoke(ua *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error)
 	UserList(ua *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ua *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
 
@@ -119,6 +137,8 @@ type applierV3backend struct {
 	lg              *zap.Logger
 	kv              mvcc.KV
 	alarmStore      *v3alarm.AlarmStore
+	prefixQuotas    *v3prefixquota.PrefixQuotaStore
+	revisionPins    *v3revisionpin.RevisionPinStore
 	authStore       auth.AuthStore
 	lessor          lease.Lessor
 	cluster         *membership.RaftCluster
@@ -133,6 +153,8 @@ func newApplierV3Backend(
 	lg *zap.Logger,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	prefixQuotas *v3prefixquota.PrefixQuotaStore,
+	revisionPins *v3revisionpin.RevisionPinStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
@@ -144,6 +166,8 @@ func newApplierV3Backend(
 		lg:                           lg,
 		kv:                           kv,
 		alarmStore:                   alarmStore,
+		prefixQuotas:                 prefixQuotas,
+		revisionPins:                 revisionPins,
 		authStore:                    authStore,
 		lessor:                       lessor,
 		cluster:                      cluster,
@@ -161,6 +185,15 @@ func (a *applierV3backend) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.Put
 	return mvcctxn.Put(ctx, a.lg, a.lessor, a.kv, txn, p)
 }
 
+// ChangeFeedCheckpoint puts a change feed checkpoint, without the checks of
+// the puts of the clients since it is proposed by the server.
+func (a *applierV3backend) ChangeFeedCheckpoint(ctx context.Context, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
+	if !bytes.HasPrefix(p.Key, []byte(v3changefeed.CheckpointPrefix)) || p.Lease != 0 {
+		return nil, nil, errors.ErrKeyNotReserved
+	}
+	return mvcctxn.Put(ctx, a.lg, a.lessor, a.kv, nil, p)
+}
+
 func (a *applierV3backend) DeleteRange(txn mvcc.TxnWrite, dr *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
 	return mvcctxn.DeleteRange(a.kv, txn, dr)
 }
@@ -181,6 +214,9 @@ func (a *applierV3backend) Compaction(compaction *pb.CompactionRequest) (*pb.Com
 		traceutil.Field{Key: "revision", Value: compaction.Revision},
 	)
 
+	if oldest := a.revisionPins.Oldest(); oldest != 0 && compaction.Revision > oldest {
+		return nil, nil, nil, errors.ErrRevisionPinned
+	}
 	ch, err := a.kv.Compact(trace, compaction.Revision)
 	if err != nil {
 		return nil, ch, nil, err
@@ -192,7 +228,7 @@ func (a *applierV3backend) Compaction(compaction *pb.CompactionRequest) (*pb.Com
 }
 
 func (a *applierV3backend) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
-	l, err := a.lessor.Grant(lease.LeaseID(lc.ID), lc.TTL)
+	l, err := a.lessor.GrantWithLabels(lease.LeaseID(lc.ID), lease.LeaseID(lc.ParentID), lc.TTL, lc.Labels)
 	resp := &pb.LeaseGrantResponse{}
 	if err == nil {
 		resp.ID = int64(l.ID)
@@ -207,6 +243,38 @@ func (a *applierV3backend) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevo
 	return &pb.LeaseRevokeResponse{Header: a.newHeader()}, err
 }
 
+func (a *applierV3backend) LeaseExpire(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
+	err := a.lessor.Expire(lease.LeaseID(lc.ID))
+	return &pb.LeaseRevokeResponse{Header: a.newHeader()}, err
+}
+
+// LeaseGrantBatch grants the leases in order. A lease failing to be granted
+// does not fail the batch but has its error set in its response.
+func (a *applierV3backend) LeaseGrantBatch(lc *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	resps := make([]*pb.LeaseGrantResponse, len(lc.Requests))
+	for i, r := range lc.Requests {
+		l, err := a.lessor.GrantWithLabels(lease.LeaseID(r.ID), lease.LeaseID(r.ParentID), r.TTL, r.Labels)
+		if err != nil {
+			resps[i] = &pb.LeaseGrantResponse{Error: err.Error()}
+			continue
+		}
+		resps[i] = &pb.LeaseGrantResponse{ID: int64(l.ID), TTL: l.TTL()}
+	}
+	return &pb.LeaseGrantBatchResponse{Header: a.newHeader(), Responses: resps}, nil
+}
+
+// LeaseRevokeBatch revokes the leases in order. A lease failing to be revoked
+// does not fail the batch but has its error set in the response.
+func (a *applierV3backend) LeaseRevokeBatch(lc *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	errs := make([]string, len(lc.Requests))
+	for i, r := range lc.Requests {
+		if err := a.lessor.Revoke(lease.LeaseID(r.ID)); err != nil {
+			errs[i] = err.Error()
+		}
+	}
+	return &pb.LeaseRevokeBatchResponse{Header: a.newHeader(), Errors: errs}, nil
+}
+
 func (a *applierV3backend) LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error) {
 	for _, c := range lc.Checkpoints {
 		err := a.lessor.Checkpoint(lease.LeaseID(c.ID), c.Remaining_TTL)
@@ -246,6 +314,69 @@ func (a *applierV3backend) Alarm(ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 	return resp, nil
 }
 
+func (a *applierV3backend) PrefixQuota(r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error) {
+	resp := &pb.PrefixQuotaResponse{}
+
+	switch r.Action {
+	case pb.PrefixQuotaRequest_GET:
+		resp.Quotas = a.prefixQuotas.Get(r.Prefix)
+	case pb.PrefixQuotaRequest_SET:
+		if len(r.Prefix) == 0 || r.MaxBytes < 0 || r.MaxKeys < 0 {
+			return nil, errors.ErrInvalidPrefixQuota
+		}
+		resp.Quotas = append(resp.Quotas, a.prefixQuotas.Set(r.Prefix, r.MaxBytes, r.MaxKeys))
+	case pb.PrefixQuotaRequest_DELETE:
+		if q := a.prefixQuotas.Delete(r.Prefix); q != nil {
+			resp.Quotas = append(resp.Quotas, q)
+		}
+	default:
+		return nil, errors.ErrInvalidPrefixQuota
+	}
+	for _, q := range resp.Quotas {
+		if err := prefixQuotaUsage(context.TODO(), a.kv, q); err != nil {
+			return nil, err
+		}
+	}
+	resp.Header = a.newHeader()
+	return resp, nil
+}
+
+func (a *applierV3backend) RevisionPin(r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error) {
+	resp := &pb.RevisionPinResponse{}
+
+	switch r.Action {
+	case pb.RevisionPinRequest_GET:
+		resp.Pins = a.revisionPins.Get(r.Owner)
+	case pb.RevisionPinRequest_PIN:
+		if r.Owner == "" || r.TTL <= 0 || r.Revision < 0 {
+			return nil, errors.ErrInvalidRevisionPin
+		}
+		rev, cur := r.Revision, a.kv.Rev()
+		if rev == 0 {
+			rev = cur
+		}
+		// the compacted revision itself can still be read
+		if rev < a.kv.FirstRev() {
+			return nil, mvcc.ErrCompacted
+		}
+		if rev > cur {
+			return nil, mvcc.ErrFutureRev
+		}
+		resp.Pins = append(resp.Pins, a.revisionPins.Pin(r.Owner, rev, r.TTL))
+	case pb.RevisionPinRequest_UNPIN:
+		if r.Owner == "" {
+			return nil, errors.ErrInvalidRevisionPin
+		}
+		if p := a.revisionPins.Unpin(r.Owner, r.Version); p != nil {
+			resp.Pins = append(resp.Pins, p)
+		}
+	default:
+		return nil, errors.ErrInvalidRevisionPin
+	}
+	resp.Header = a.newHeader()
+	return resp, nil
+}
+
 type applierV3Capped struct {
 	applierV3
 	q serverstorage.BackendQuota
@@ -270,6 +401,10 @@ func (a *applierV3Capped) LeaseGrant(_ *pb.LeaseGrantRequest) (*pb.LeaseGrantRes
 	return nil, errors.ErrNoSpace
 }
 
+func (a *applierV3Capped) LeaseGrantBatch(_ *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	return nil, errors.ErrNoSpace
+}
+
 func (a *applierV3backend) AuthEnable() (*pb.AuthEnableResponse, error) {
 	err := a.authStore.AuthEnable()
 	if err != nil {
@@ -286,11 +421,16 @@ func (a *applierV3backend) AuthDisable() (*pb.AuthDisableResponse, error) {
 func (a *applierV3backend) AuthStatus() (*pb.AuthStatusResponse, error) {
 	enabled := a.authStore.IsAuthEnabled()
 	authRevision := a.authStore.Revision()
-	return &pb.AuthStatusResponse{Header: a.newHeader(), Enabled: enabled, AuthRevision: authRevision}, nil
+	bcryptCost := int32(a.authStore.BcryptCost())
+	return &pb.AuthStatusResponse{Header: a.newHeader(), Enabled: enabled, AuthRevision: authRevision, BcryptCost: bcryptCost}, nil
 }
 
 func (a *applierV3backend) Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error) {
 	ctx := context.WithValue(context.WithValue(context.Background(), auth.AuthenticateParamIndex{}, a.consistentIndex.ConsistentIndex()), auth.AuthenticateParamSimpleTokenPrefix{}, r.SimpleToken)
+	ctx = context.WithValue(ctx, auth.AuthenticateParamPeerAddress{}, r.PeerAddress)
+	if r.HashedPassword != "" {
+		a.authStore.RehashPassword(r.Name, r.HashedPassword, r.AuthRevision)
+	}
 	resp, err := a.authStore.Authenticate(ctx, r.Name, r.Password)
 	if resp != nil {
 		resp.Header = a.newHeader()
@@ -378,6 +518,48 @@ func (a *applierV3backend) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRe
 	return resp, err
 }
 
+func (a *applierV3backend) RoleSetMaxLeaseTTL(r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	resp, err := a.authStore.RoleSetMaxLeaseTTL(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) RoleSetQuota(r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error) {
+	resp, err := a.authStore.RoleSetQuota(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) AuthSetBcryptCost(r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error) {
+	resp, err := a.authStore.SetBcryptCost(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) AuthTokenList(r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error) {
+	resp, err := a.authStore.TokenList(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) AuthTokenRevoke(r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error) {
+	// the tokens are revoked up to the index of the request on all the members
+	ctx := context.WithValue(context.Background(), auth.AuthenticateParamIndex{}, a.consistentIndex.ConsistentIndex())
+	resp, err := a.authStore.TokenRevoke(ctx, r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
 func (a *applierV3backend) RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error) {
 	resp, err := a.authStore.RoleDelete(r)
 	if resp != nil {
@@ -443,8 +625,8 @@ type quotaApplierV3 struct {
 	q serverstorage.Quota
 }
 
-func newQuotaApplierV3(lg *zap.Logger, quotaBackendBytesCfg int64, be backend.Backend, app applierV3) applierV3 {
-	return &quotaApplierV3{app, serverstorage.NewBackendQuota(lg, quotaBackendBytesCfg, be, "v3-applier")}
+func newQuotaApplierV3(lg *zap.Logger, quotaBackendBytes func() int64, maxQuotaBackendBytesCfg int64, be backend.Backend, app applierV3) applierV3 {
+	return &quotaApplierV3{app, serverstorage.NewRuntimeBackendQuota(lg, quotaBackendBytes, maxQuotaBackendBytesCfg, be, "v3-applier")}
 }
 
 func (a *quotaApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
@@ -474,6 +656,15 @@ func (a *quotaApplierV3) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantRes
 	return resp, err
 }
 
+func (a *quotaApplierV3) LeaseGrantBatch(lc *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	ok := a.q.Available(lc)
+	resp, err := a.applierV3.LeaseGrantBatch(lc)
+	if err == nil && !ok {
+		err = errors.ErrNoSpace
+	}
+	return resp, err
+}
+
 func (a *applierV3backend) newHeader() *pb.ResponseHeader {
 	return &pb.ResponseHeader{
 		ClusterId: uint64(a.cluster.ID()),
@@ -16,12 +16,14 @@ package apply
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3revisionpin"
 	"go.etcd.io/etcd/server/v3/etcdserver/txn"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
@@ -51,17 +53,20 @@ func (aa *authApplierV3) Apply(ctx context.Context, r *pb.InternalRaftRequest, s
 		// does not have header field
 		aa.authInfo.Username = r.Header.Username
 		aa.authInfo.Revision = r.Header.AuthRevision
+		aa.authInfo.Roles = r.Header.Roles
 	}
 	if needAdminPermission(r) {
 		if err := aa.as.IsAdminPermitted(&aa.authInfo); err != nil {
 			aa.authInfo.Username = ""
 			aa.authInfo.Revision = 0
+			aa.authInfo.Roles = nil
 			return &Result{Err: err}
 		}
 	}
 	ret := aa.applierV3.Apply(ctx, r, shouldApplyV3, applyFunc)
 	aa.authInfo.Username = ""
 	aa.authInfo.Revision = 0
+	aa.authInfo.Roles = nil
 	return ret
 }
 
@@ -87,11 +92,11 @@ func (aa *authApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, r *pb.PutRe
 	return aa.applierV3.Put(ctx, txn, r)
 }
 
-func (aa *authApplierV3) Range(ctx context.Context, txn mvcc.TxnRead, r *pb.RangeRequest) (*pb.RangeResponse, error) {
-	if err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, r.RangeEnd); err != nil {
+func (aa *authApplierV3) Range(ctx context.Context, txnRead mvcc.TxnRead, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	if err := txn.CheckRangeAuth(aa.as, &aa.authInfo, r); err != nil {
 		return nil, err
 	}
-	return aa.applierV3.Range(ctx, txn, r)
+	return aa.applierV3.Range(ctx, txnRead, r)
 }
 
 func (aa *authApplierV3) DeleteRange(txn mvcc.TxnWrite, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
@@ -115,6 +120,17 @@ func (aa *authApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnRes
 	return aa.applierV3.Txn(ctx, rt)
 }
 
+func (aa *authApplierV3) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
+	if err := aa.as.IsLeaseGrantPermitted(&aa.authInfo, lc.TTL); err != nil {
+		return nil, err
+	}
+	resp, err := aa.applierV3.LeaseGrant(lc)
+	if err == nil {
+		aa.setGrantor(resp.ID)
+	}
+	return resp, err
+}
+
 func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
 	if err := aa.checkLeasePuts(lease.LeaseID(lc.ID)); err != nil {
 		return nil, err
@@ -122,6 +138,43 @@ func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevoke
 	return aa.applierV3.LeaseRevoke(lc)
 }
 
+// LeaseGrantBatch fails the whole batch if the user may not grant any of its
+// leases.
+func (aa *authApplierV3) LeaseGrantBatch(lc *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	for _, r := range lc.Requests {
+		if err := aa.as.IsLeaseGrantPermitted(&aa.authInfo, r.TTL); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := aa.applierV3.LeaseGrantBatch(lc)
+	if err == nil {
+		for _, r := range resp.Responses {
+			if r.Error == "" {
+				aa.setGrantor(r.ID)
+			}
+		}
+	}
+	return resp, err
+}
+
+// setGrantor records the authenticated user as the grantor of the lease.
+func (aa *authApplierV3) setGrantor(id int64) {
+	if aa.authInfo.Username != "" {
+		aa.lessor.SetGrantor(lease.LeaseID(id), aa.authInfo.Username)
+	}
+}
+
+// LeaseRevokeBatch fails the whole batch if the user may not revoke any of
+// its leases.
+func (aa *authApplierV3) LeaseRevokeBatch(lc *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	for _, r := range lc.Requests {
+		if err := aa.checkLeasePuts(lease.LeaseID(r.ID)); err != nil {
+			return nil, err
+		}
+	}
+	return aa.applierV3.LeaseRevokeBatch(lc)
+}
+
 func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
 	l := aa.lessor.Lookup(leaseID)
 	if l != nil {
@@ -183,10 +236,26 @@ func needAdminPermission(r *pb.InternalRaftRequest) bool {
 		return true
 	case r.AuthRoleDelete != nil:
 		return true
+	case r.AuthRoleSetMaxLeaseTTL != nil:
+		return true
+	case r.AuthRoleSetQuota != nil:
+		return true
+	case r.AuthSetBcryptCost != nil:
+		return true
+	case r.AuthTokenList != nil:
+		return true
+	case r.AuthTokenRevoke != nil:
+		return true
 	case r.AuthUserList != nil:
 		return true
 	case r.AuthRoleList != nil:
 		return true
+	case r.PrefixQuota != nil:
+		return true
+	case r.RevisionPin != nil:
+		// the permission of the users of SnapshotRange to read the range is
+		// checked before pinning its revision.
+		return !strings.HasPrefix(r.RevisionPin.Owner, v3revisionpin.SnapshotRangeOwnerPrefix)
 	default:
 		return false
 	}
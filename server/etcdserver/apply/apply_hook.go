@@ -0,0 +1,53 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/config"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+)
+
+type hookApplierV3 struct {
+	applierV3
+	hooks []config.ApplyHook
+}
+
+// newHookApplierV3 creates an applyV3 that calls the hooks of the embedders
+// around the requests, the cluster requests excluded.
+func newHookApplierV3(hooks []config.ApplyHook, base applierV3) applierV3 {
+	if len(hooks) == 0 {
+		return base
+	}
+	return &hookApplierV3{applierV3: base, hooks: hooks}
+}
+
+func (a *hookApplierV3) Apply(ctx context.Context, r *pb.InternalRaftRequest, shouldApplyV3 membership.ShouldApplyV3, applyFunc applyFunc) *Result {
+	if !shouldApplyV3 || r.ClusterVersionSet != nil || r.ClusterMemberAttrSet != nil || r.DowngradeInfoSet != nil {
+		return a.applierV3.Apply(ctx, r, shouldApplyV3, applyFunc)
+	}
+	for _, h := range a.hooks {
+		if err := h.PreApply(r); err != nil {
+			return &Result{Err: err}
+		}
+	}
+	ar := a.applierV3.Apply(ctx, r, shouldApplyV3, applyFunc)
+	for _, h := range a.hooks {
+		h.PostApply(r, ar.Resp, ar.Err)
+	}
+	return ar
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+type prefixQuotaApplierV3 struct {
+	applierV3
+	kv mvcc.KV
+	qs *v3prefixquota.PrefixQuotaStore
+}
+
+// newPrefixQuotaApplierV3 creates an applyV3 that rejects the Puts and the
+// transactions that would take the keys or the bytes under a prefix beyond
+// its quota.
+func newPrefixQuotaApplierV3(kv mvcc.KV, qs *v3prefixquota.PrefixQuotaStore, base applierV3) applierV3 {
+	return &prefixQuotaApplierV3{applierV3: base, kv: kv, qs: qs}
+}
+
+func (a *prefixQuotaApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
+	if err := a.check(ctx, []*pb.PutRequest{p}); err != nil {
+		return nil, nil, err
+	}
+	return a.applierV3.Put(ctx, txn, p)
+}
+
+func (a *prefixQuotaApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnResponse, *traceutil.Trace, error) {
+	// the branch taken is unknown until the compares are evaluated, so both
+	// of them must fit
+	for _, ops := range [][]*pb.RequestOp{rt.Success, rt.Failure} {
+		if err := a.check(ctx, txnPuts(ops, nil)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return a.applierV3.Txn(ctx, rt)
+}
+
+// check returns ErrPrefixQuotaExceeded if the puts would take a prefix beyond
+// its quota. Deletes are not accounted for, they can only free the quota.
+func (a *prefixQuotaApplierV3) check(ctx context.Context, puts []*pb.PutRequest) error {
+	if a.qs.Len() == 0 {
+		return nil
+	}
+
+	type delta struct {
+		q           *pb.PrefixQuota
+		keys, bytes int64
+	}
+	deltas := make(map[string]*delta)
+	for _, p := range puts {
+		qs := a.qs.Matching(p.Key)
+		if len(qs) == 0 {
+			continue
+		}
+		r, err := a.kv.Range(ctx, p.Key, nil, mvcc.RangeOptions{Estimate: true})
+		if err != nil {
+			return err
+		}
+		keys := int64(1 - r.Count)
+		bytes := int64(len(p.Key)+len(p.Value)) - r.Size
+		if p.IgnoreValue {
+			bytes = 0
+		}
+		for _, q := range qs {
+			d := deltas[string(q.Prefix)]
+			if d == nil {
+				d = &delta{q: q}
+				deltas[string(q.Prefix)] = d
+			}
+			d.keys += keys
+			d.bytes += bytes
+		}
+	}
+
+	for _, d := range deltas {
+		if d.keys <= 0 && d.bytes <= 0 {
+			continue
+		}
+		if err := prefixQuotaUsage(ctx, a.kv, d.q); err != nil {
+			return err
+		}
+		if d.q.MaxKeys > 0 && d.keys > 0 && d.q.UsedKeys+d.keys > d.q.MaxKeys {
+			return errors.ErrPrefixQuotaExceeded
+		}
+		if d.q.MaxBytes > 0 && d.bytes > 0 && d.q.UsedBytes+d.bytes > d.q.MaxBytes {
+			return errors.ErrPrefixQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// txnPuts appends the puts of the ops to puts, including the ones of both
+// branches of the nested transactions. A key put several times is kept once,
+// with its largest value.
+func txnPuts(ops []*pb.RequestOp, puts []*pb.PutRequest) []*pb.PutRequest {
+	for _, op := range ops {
+		switch {
+		case op.GetRequestPut() != nil:
+			p := op.GetRequestPut()
+			found := false
+			for i := range puts {
+				if string(puts[i].Key) == string(p.Key) {
+					if !p.IgnoreValue && (puts[i].IgnoreValue || len(p.Value) > len(puts[i].Value)) {
+						puts[i] = p
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				puts = append(puts, p)
+			}
+		case op.GetRequestTxn() != nil:
+			t := op.GetRequestTxn()
+			puts = txnPuts(t.Success, puts)
+			puts = txnPuts(t.Failure, puts)
+		}
+	}
+	return puts
+}
+
+// prefixQuotaUsage sets the keys and the bytes currently used under the
+// prefix of the quota, from the key index.
+func prefixQuotaUsage(ctx context.Context, kv mvcc.KV, q *pb.PrefixQuota) error {
+	r, err := kv.Range(ctx, q.Prefix, v3prefixquota.PrefixEnd(q.Prefix), mvcc.RangeOptions{Estimate: true})
+	if err != nil {
+		return err
+	}
+	q.UsedKeys = int64(r.Count)
+	q.UsedBytes = r.Size
+	return nil
+}
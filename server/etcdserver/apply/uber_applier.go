@@ -22,8 +22,11 @@ import (
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3revisionpin"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	"go.etcd.io/etcd/server/v3/etcdserver/txn"
 	"go.etcd.io/etcd/server/v3/lease"
@@ -38,8 +41,9 @@ type UberApplier interface {
 type uberApplier struct {
 	lg *zap.Logger
 
-	alarmStore           *v3alarm.AlarmStore
-	warningApplyDuration time.Duration
+	alarmStore *v3alarm.AlarmStore
+	// warningApplyDuration may change at runtime.
+	warningApplyDuration func() time.Duration
 
 	// This is the applier that is taking in consideration current alarms
 	applyV3 applierV3
@@ -53,16 +57,20 @@ func NewUberApplier(
 	be backend.Backend,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	prefixQuotas *v3prefixquota.PrefixQuotaStore,
+	revisionPins *v3revisionpin.RevisionPinStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
 	raftStatus RaftStatusGetter,
 	snapshotServer SnapshotServer,
 	consistentIndex cindex.ConsistentIndexer,
-	warningApplyDuration time.Duration,
+	warningApplyDuration func() time.Duration,
 	txnModeWriteWithSharedBuffer bool,
-	quotaBackendBytesCfg int64) UberApplier {
-	applyV3base_ := newApplierV3(lg, be, kv, alarmStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer, quotaBackendBytesCfg)
+	quotaBackendBytes func() int64,
+	maxQuotaBackendBytesCfg int64,
+	hooks []config.ApplyHook) UberApplier {
+	applyV3base_ := newApplierV3(lg, be, kv, alarmStore, prefixQuotas, revisionPins, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer, quotaBackendBytes, maxQuotaBackendBytesCfg, hooks)
 
 	ua := &uberApplier{
 		lg:                   lg,
@@ -80,6 +88,8 @@ func newApplierV3(
 	be backend.Backend,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	prefixQuotas *v3prefixquota.PrefixQuotaStore,
+	revisionPins *v3revisionpin.RevisionPinStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
@@ -87,12 +97,17 @@ func newApplierV3(
 	snapshotServer SnapshotServer,
 	consistentIndex cindex.ConsistentIndexer,
 	txnModeWriteWithSharedBuffer bool,
-	quotaBackendBytesCfg int64) applierV3 {
-	applierBackend := newApplierV3Backend(lg, kv, alarmStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer)
-	return newAuthApplierV3(
-		authStore,
-		newQuotaApplierV3(lg, quotaBackendBytesCfg, be, applierBackend),
-		lessor,
+	quotaBackendBytes func() int64,
+	maxQuotaBackendBytesCfg int64,
+	hooks []config.ApplyHook) applierV3 {
+	applierBackend := newApplierV3Backend(lg, kv, alarmStore, prefixQuotas, revisionPins, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer)
+	return newHookApplierV3(
+		hooks,
+		newAuthApplierV3(
+			authStore,
+			newPrefixQuotaApplierV3(kv, prefixQuotas, newQuotaApplierV3(lg, quotaBackendBytes, maxQuotaBackendBytesCfg, be, applierBackend)),
+			lessor,
+		),
 	)
 }
 
@@ -110,7 +125,7 @@ func (a *uberApplier) restoreAlarms() {
 
 func (a *uberApplier) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membership.ShouldApplyV3) *Result {
 	// We first execute chain of Apply() calls down the hierarchy:
-	// (i.e. CorruptApplier -> CappedApplier -> Auth -> Quota -> Backend),
+	// (i.e. CorruptApplier -> CappedApplier -> Hook -> Auth -> PrefixQuota -> Quota -> Backend),
 	// then dispatch() unpacks the request to a specific method (like Put),
 	// that gets executed down the hierarchy again:
 	// i.e. CorruptApplier.Put(CappedApplier.Put(...(BackendApplier.Put(...)))).
@@ -125,7 +140,7 @@ func (a *uberApplier) dispatch(ctx context.Context, r *pb.InternalRaftRequest, s
 	defer func(start time.Time) {
 		success := ar.Err == nil || ar.Err == mvcc.ErrCompacted
 		txn.ApplySecObserve(v3Version, op, success, time.Since(start))
-		txn.WarnOfExpensiveRequest(a.lg, a.warningApplyDuration, start, &pb.InternalRaftStringer{Request: r}, ar.Resp, ar.Err)
+		txn.WarnOfExpensiveRequest(a.lg, a.warningApplyDuration(), start, &pb.InternalRaftStringer{Request: r}, ar.Resp, ar.Err)
 		if !success {
 			txn.WarnOfFailedRequest(a.lg, start, &pb.InternalRaftStringer{Request: r}, ar.Resp, ar.Err)
 		}
@@ -172,12 +187,30 @@ func (a *uberApplier) dispatch(ctx context.Context, r *pb.InternalRaftRequest, s
 	case r.LeaseRevoke != nil:
 		op = "LeaseRevoke"
 		ar.Resp, ar.Err = a.applyV3.LeaseRevoke(r.LeaseRevoke)
+	case r.LeaseExpire != nil:
+		op = "LeaseExpire"
+		ar.Resp, ar.Err = a.applyV3.LeaseExpire(r.LeaseExpire)
+	case r.ChangeFeedCheckpoint != nil:
+		op = "ChangeFeedCheckpoint"
+		ar.Resp, ar.Trace, ar.Err = a.applyV3.ChangeFeedCheckpoint(ctx, r.ChangeFeedCheckpoint)
+	case r.LeaseGrantBatch != nil:
+		op = "LeaseGrantBatch"
+		ar.Resp, ar.Err = a.applyV3.LeaseGrantBatch(r.LeaseGrantBatch)
+	case r.LeaseRevokeBatch != nil:
+		op = "LeaseRevokeBatch"
+		ar.Resp, ar.Err = a.applyV3.LeaseRevokeBatch(r.LeaseRevokeBatch)
 	case r.LeaseCheckpoint != nil:
 		op = "LeaseCheckpoint"
 		ar.Resp, ar.Err = a.applyV3.LeaseCheckpoint(r.LeaseCheckpoint)
 	case r.Alarm != nil:
 		op = "Alarm"
 		ar.Resp, ar.Err = a.Alarm(r.Alarm)
+	case r.PrefixQuota != nil:
+		op = "PrefixQuota"
+		ar.Resp, ar.Err = a.applyV3.PrefixQuota(r.PrefixQuota)
+	case r.RevisionPin != nil:
+		op = "RevisionPin"
+		ar.Resp, ar.Err = a.applyV3.RevisionPin(r.RevisionPin)
 	case r.Authenticate != nil:
 		op = "Authenticate"
 		ar.Resp, ar.Err = a.applyV3.Authenticate(r.Authenticate)
@@ -222,6 +255,21 @@ func (a *uberApplier) dispatch(ctx context.Context, r *pb.InternalRaftRequest, s
 	case r.AuthRoleDelete != nil:
 		op = "AuthRoleDelete"
 		ar.Resp, ar.Err = a.applyV3.RoleDelete(r.AuthRoleDelete)
+	case r.AuthRoleSetMaxLeaseTTL != nil:
+		op = "AuthRoleSetMaxLeaseTTL"
+		ar.Resp, ar.Err = a.applyV3.RoleSetMaxLeaseTTL(r.AuthRoleSetMaxLeaseTTL)
+	case r.AuthRoleSetQuota != nil:
+		op = "AuthRoleSetQuota"
+		ar.Resp, ar.Err = a.applyV3.RoleSetQuota(r.AuthRoleSetQuota)
+	case r.AuthSetBcryptCost != nil:
+		op = "AuthSetBcryptCost"
+		ar.Resp, ar.Err = a.applyV3.AuthSetBcryptCost(r.AuthSetBcryptCost)
+	case r.AuthTokenList != nil:
+		op = "AuthTokenList"
+		ar.Resp, ar.Err = a.applyV3.AuthTokenList(r.AuthTokenList)
+	case r.AuthTokenRevoke != nil:
+		op = "AuthTokenRevoke"
+		ar.Resp, ar.Err = a.applyV3.AuthTokenRevoke(r.AuthTokenRevoke)
 	case r.AuthUserList != nil:
 		op = "AuthUserList"
 		ar.Resp, ar.Err = a.applyV3.UserList(r.AuthUserList)
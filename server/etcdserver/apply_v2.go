@@ -132,7 +132,7 @@ func (s *EtcdServer) applyV2Request(r *RequestV2, shouldApplyV3 membership.Shoul
 		}
 		success := resp.Err == nil
 		txn.ApplySecObserve(v2Version, r.Method, success, time.Since(start))
-		txn.WarnOfExpensiveRequest(s.Logger(), s.Cfg.WarningApplyDuration, start, stringer, nil, nil)
+		txn.WarnOfExpensiveRequest(s.Logger(), s.warningApplyDuration(), start, stringer, nil, nil)
 	}(time.Now())
 
 	switch r.Method {
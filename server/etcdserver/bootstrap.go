@@ -42,6 +42,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	servererrors "go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/features"
 	serverstorage "go.etcd.io/etcd/server/v3/storage"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/schema"
@@ -106,6 +107,12 @@ func bootstrap(cfg config.ServerConfig) (b *bootstrappedServer, err error) {
 		backend.Close()
 		return nil, err
 	}
+	if haveWAL && cfg.ServerFeatureGate != nil && cfg.ServerFeatureGate.Enabled(features.VersionRetention) {
+		if err = checkVersionRetention(cfg, cluster, prt); err != nil {
+			backend.Close()
+			return nil, err
+		}
+	}
 	raft := bootstrapRaft(cfg, cluster, s.wal)
 	return &bootstrappedServer{
 		prt:     prt,
@@ -258,6 +265,10 @@ func maybeDefragBackend(cfg config.ServerConfig, be backend.Backend) error {
 		)
 		return nil
 	}
+	if err := be.Check(); err != nil {
+		cfg.Logger.Warn("Skipping defragmentation, backend integrity check failed", zap.Error(err))
+		return nil
+	}
 	return be.Defrag()
 }
 
@@ -293,7 +304,11 @@ func bootstrapExistingClusterNoWAL(cfg config.ServerConfig, prt http.RoundTrippe
 	if err := membership.ValidateClusterAndAssignIDs(cfg.Logger, cl, existingCluster); err != nil {
 		return nil, fmt.Errorf("error validating peerURLs %s: %v", existingCluster, err)
 	}
-	if !isCompatibleWithCluster(cfg.Logger, cl, cl.MemberByName(cfg.Name).ID, prt, cfg.ReqTimeout()) {
+	var featureGates map[string]bool
+	if cfg.ServerFeatureGate != nil {
+		featureGates = cfg.ServerFeatureGate.Values()
+	}
+	if !isCompatibleWithCluster(cfg.Logger, cl, cl.MemberByName(cfg.Name).ID, featureGates, versionRetentionStrings(cfg.VersionRetention), prt, cfg.ReqTimeout()) {
 		return nil, fmt.Errorf("incompatible with current running cluster")
 	}
 	scaleUpLearners := false
@@ -460,6 +475,20 @@ func (c *bootstrapedCluster) Finalize(cfg config.ServerConfig, s *bootstrappedSt
 	return membership.ValidateMaxLearnerConfig(cfg.ExperimentalMaxLearners, c.cl.Members(), scaleUpLearners)
 }
 
+// checkVersionRetention refuses to restart the member if one of the other
+// members keeps other versions of the keys through the compactions, as it
+// would compact the key space differently from them, or from its own past
+// before the version retention changed. Only the members enabling the
+// VersionRetention feature check it, not to wait for the other members to
+// answer when a cluster restarts.
+func checkVersionRetention(cfg config.ServerConfig, c *bootstrapedCluster, prt http.RoundTripper) error {
+	vers := getMembersVersions(cfg.Logger, c.cl, c.nodeID, nil, versionRetentionStrings(cfg.VersionRetention), prt, cfg.PeerDialTimeout())
+	if !isVersionRetentionCompatible(cfg.Logger, vers, versionRetentionStrings(cfg.VersionRetention)) {
+		return fmt.Errorf("version retention incompatible with current running cluster")
+	}
+	return nil
+}
+
 func (c *bootstrapedCluster) databaseFileMissing(s *bootstrappedStorage) bool {
 	v3Cluster := c.cl.Version() != nil && !c.cl.Version().LessThan(semver.Version{Major: 3})
 	return v3Cluster && !s.backend.beExist
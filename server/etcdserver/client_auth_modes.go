@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// ClientAuthModes are the etcd authentication modes allowed to the clients
+// of a listener. The requests carrying the credentials of the other modes are
+// denied.
+type ClientAuthModes struct {
+	// Token allows the passwords and the tokens of the Auth service.
+	Token bool
+	// Cert allows the common names of the verified client certificates.
+	Cert bool
+}
+
+type clientAuthModesKey struct{}
+
+// WithClientAuthModes returns a copy of ctx restricting the authentication
+// of its requests to modes.
+func WithClientAuthModes(ctx context.Context, modes ClientAuthModes) context.Context {
+	return context.WithValue(ctx, clientAuthModesKey{}, modes)
+}
+
+// ClientAuthModesFromContext returns the authentication modes allowed to the
+// request of ctx, all of them by default.
+func ClientAuthModesFromContext(ctx context.Context) ClientAuthModes {
+	if modes, ok := ctx.Value(clientAuthModesKey{}).(ClientAuthModes); ok {
+		return modes
+	}
+	return ClientAuthModes{Token: true, Cert: true}
+}
+
+// hasToken returns true if the request of ctx carries a token.
+func hasToken(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	return len(md[rpctypes.TokenFieldNameGRPC]) > 0 || len(md[rpctypes.TokenFieldNameSwagger]) > 0
+}
@@ -29,6 +29,8 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"github.com/coreos/go-semver/semver"
 	"go.uber.org/zap"
@@ -137,9 +139,11 @@ func getRemotePeerURLs(cl *membership.RaftCluster, local string) []string {
 
 // getMembersVersions returns the versions of the members in the given cluster.
 // The key of the returned map is the member's ID. The value of the returned map
-// is the semver versions string, including server and cluster.
+// is the semver versions string, including server and cluster, the feature
+// gates and the version retentions, those of the local member being featureGates
+// and versionRetention.
 // If it fails to get the version of a member, the key will be nil.
-func getMembersVersions(lg *zap.Logger, cl *membership.RaftCluster, local types.ID, rt http.RoundTripper, timeout time.Duration) map[string]*version.Versions {
+func getMembersVersions(lg *zap.Logger, cl *membership.RaftCluster, local types.ID, featureGates map[string]bool, versionRetention []string, rt http.RoundTripper, timeout time.Duration) map[string]*version.Versions {
 	members := cl.Members()
 	vers := make(map[string]*version.Versions)
 	for _, m := range members {
@@ -148,7 +152,7 @@ func getMembersVersions(lg *zap.Logger, cl *membership.RaftCluster, local types.
 			if cl.Version() != nil {
 				cv = cl.Version().String()
 			}
-			vers[m.ID.String()] = &version.Versions{Server: version.Version, Cluster: cv}
+			vers[m.ID.String()] = &version.Versions{Server: version.Version, Cluster: cv, FeatureGates: featureGates, VersionRetention: versionRetention}
 			continue
 		}
 		ver, err := getVersion(lg, m, rt, timeout)
@@ -184,12 +188,42 @@ func allowedVersionRange(downgradeEnabled bool) (minV *semver.Version, maxV *sem
 // cluster version in the range of [MinV, MaxV] and no known members has a cluster version
 // out of the range.
 // We set this rule since when the local member joins, another member might be offline.
-func isCompatibleWithCluster(lg *zap.Logger, cl *membership.RaftCluster, local types.ID, rt http.RoundTripper, timeout time.Duration) bool {
-	vers := getMembersVersions(lg, cl, local, rt, timeout)
+// The feature gates the other members disagree on are only warned about, while
+// the version retentions must match, for the members to compact alike.
+func isCompatibleWithCluster(lg *zap.Logger, cl *membership.RaftCluster, local types.ID, featureGates map[string]bool, versionRetention []string, rt http.RoundTripper, timeout time.Duration) bool {
+	vers := getMembersVersions(lg, cl, local, featureGates, versionRetention, rt, timeout)
+	for _, mismatch := range serverversion.FeatureGateMismatches(vers, featureGates) {
+		lg.Warn("feature gate mismatch with remote member", zap.String("mismatch", mismatch))
+	}
+	if !isVersionRetentionCompatible(lg, vers, versionRetention) {
+		return false
+	}
 	minV, maxV := allowedVersionRange(getDowngradeEnabledFromRemotePeers(lg, cl, local, rt, timeout))
 	return isCompatibleWithVers(lg, vers, local, minV, maxV)
 }
 
+// isVersionRetentionCompatible returns false if a member in vers keeps other
+// versions of the keys through the compactions than the local versionRetention,
+// as their key spaces, hashes and reads below the compacted revision would
+// diverge. The members that could not be reached are not checked.
+func isVersionRetentionCompatible(lg *zap.Logger, vers map[string]*version.Versions, versionRetention []string) bool {
+	mismatches := serverversion.VersionRetentionMismatches(vers, versionRetention)
+	for _, mismatch := range mismatches {
+		lg.Error("version retention mismatch with remote member", zap.String("mismatch", mismatch))
+	}
+	return len(mismatches) == 0
+}
+
+// versionRetentionStrings returns the version retentions as strings, sorted.
+func versionRetentionStrings(vrs []mvcc.VersionRetention) []string {
+	var ss []string
+	for _, vr := range vrs {
+		ss = append(ss, vr.String())
+	}
+	sort.Strings(ss)
+	return ss
+}
+
 func isCompatibleWithVers(lg *zap.Logger, vers map[string]*version.Versions, local types.ID, minV, maxV *semver.Version) bool {
 	var ok bool
 	for id, v := range vers {
@@ -308,13 +342,16 @@ func promoteMemberHTTP(ctx context.Context, url string, id uint64, peerRt http.R
 		return nil, errors.ErrTimeout
 	}
 	if resp.StatusCode == http.StatusPreconditionFailed {
-		// both ErrMemberNotLearner and ErrLearnerNotReady have same http status code
+		// ErrMemberNotLearner, ErrMemberObserver and ErrLearnerNotReady have same http status code
 		if strings.Contains(string(b), errors.ErrLearnerNotReady.Error()) {
 			return nil, errors.ErrLearnerNotReady
 		}
 		if strings.Contains(string(b), membership.ErrMemberNotLearner.Error()) {
 			return nil, membership.ErrMemberNotLearner
 		}
+		if strings.Contains(string(b), membership.ErrMemberObserver.Error()) {
+			return nil, membership.ErrMemberObserver
+		}
 		return nil, fmt.Errorf("member promote: unknown error(%s)", string(b))
 	}
 	if resp.StatusCode == http.StatusNotFound {
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"sort"
 	"strings"
@@ -37,16 +38,44 @@ import (
 type CorruptionChecker interface {
 	InitialCheck() error
 	PeriodicCheck() error
+	SampledCheck() error
 	CompactHashCheck()
+	LastCheck() CorruptionCheckResult
 }
 
+// CorruptionCheckResult is the outcome of a periodic or sampled corruption
+// check.
+type CorruptionCheckResult struct {
+	Scope pb.CorruptionCheckRequest_Scope
+	// Time is when the check started, zero if no check ran yet.
+	Time time.Time
+	// Revision is the latest revision checked.
+	Revision int64
+	// MembersChecked is the number of peers whose hashes were compared.
+	MembersChecked int
+	// Corrupted lists the members the check raised a corrupt alarm for.
+	Corrupted []types.ID
+	Err       error
+}
+
+// corruptCheckSampleRevisions is the number of revisions of the windows
+// compared by the sampled corruption check.
+const corruptCheckSampleRevisions = 1000
+
 type corruptionChecker struct {
 	lg *zap.Logger
 
 	hasher Hasher
+	// samples is the number of revision windows compared by SampledCheck.
+	samples int
+
+	// checkMu serializes the periodic and sampled checks, which run both on
+	// schedule and on request.
+	checkMu sync.Mutex
 
 	mux                   sync.RWMutex
 	latestRevisionChecked int64
+	lastCheck             CorruptionCheckResult
 }
 
 type Hasher interface {
@@ -54,14 +83,18 @@ type Hasher interface {
 	ReqTimeout() time.Duration
 	MemberId() types.ID
 	PeerHashByRev(int64) []*peerHashKVResp
+	PeerHashRevisions(from, to int64) []*peerHashKVResp
+	// RevisionRange returns the compacted and the current revisions.
+	RevisionRange() (compactRev, rev int64)
 	LinearizableReadNotify(context.Context) error
 	TriggerCorruptAlarm(types.ID)
 }
 
 func newCorruptionChecker(lg *zap.Logger, s *EtcdServer, storage mvcc.HashStorage) *corruptionChecker {
 	return &corruptionChecker{
-		lg:     lg,
-		hasher: hasherAdapter{s, storage},
+		lg:      lg,
+		hasher:  hasherAdapter{s, storage},
+		samples: s.Cfg.CorruptCheckSamples,
 	}
 }
 
@@ -78,6 +111,15 @@ func (h hasherAdapter) PeerHashByRev(rev int64) []*peerHashKVResp {
 	return h.EtcdServer.getPeerHashKVs(rev)
 }
 
+func (h hasherAdapter) PeerHashRevisions(from, to int64) []*peerHashKVResp {
+	return h.EtcdServer.getPeerHashRevisions(from, to)
+}
+
+func (h hasherAdapter) RevisionRange() (int64, int64) {
+	kv := h.EtcdServer.KV()
+	return kv.FirstRev(), kv.Rev()
+}
+
 func (h hasherAdapter) TriggerCorruptAlarm(memberID types.ID) {
 	h.EtcdServer.triggerCorruptAlarm(memberID)
 }
@@ -165,10 +207,20 @@ func (cm *corruptionChecker) InitialCheck() error {
 }
 
 func (cm *corruptionChecker) PeriodicCheck() error {
+	cm.checkMu.Lock()
+	defer cm.checkMu.Unlock()
+	result := CorruptionCheckResult{Scope: pb.CorruptionCheckRequest_FULL, Time: time.Now()}
+	result.Err = cm.periodicCheck(&result)
+	cm.setLastCheck(result)
+	return result.Err
+}
+
+func (cm *corruptionChecker) periodicCheck(result *CorruptionCheckResult) error {
 	h, _, err := cm.hasher.HashByRev(0)
 	if err != nil {
 		return err
 	}
+	result.Revision = h.Revision
 	peers := cm.hasher.PeerHashByRev(h.Revision)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cm.hasher.ReqTimeout())
@@ -190,6 +242,7 @@ func (cm *corruptionChecker) PeriodicCheck() error {
 		}
 		alarmed = true
 		cm.hasher.TriggerCorruptAlarm(id)
+		result.Corrupted = append(result.Corrupted, id)
 	}
 
 	if h2.Hash != h.Hash && h2.Revision == h.Revision && h.CompactRevision == h2.CompactRevision {
@@ -247,10 +300,109 @@ func (cm *corruptionChecker) PeriodicCheck() error {
 			mismatch(p.id)
 		}
 	}
+	result.MembersChecked = checkedCount
 	cm.lg.Info("finished peer corruption check", zap.Int("number-of-peers-checked", checkedCount))
 	return nil
 }
 
+// SampledCheck compares with its peers the hashes of a few windows of
+// revisions picked at random after the compacted revision. It is much
+// cheaper than PeriodicCheck on large key spaces, at the cost of only
+// detecting the inconsistencies within the sampled revisions.
+func (cm *corruptionChecker) SampledCheck() error {
+	cm.checkMu.Lock()
+	defer cm.checkMu.Unlock()
+	result := CorruptionCheckResult{Scope: pb.CorruptionCheckRequest_SAMPLED, Time: time.Now()}
+	result.Err = cm.sampledCheck(&result)
+	cm.setLastCheck(result)
+	return result.Err
+}
+
+func (cm *corruptionChecker) sampledCheck(result *CorruptionCheckResult) error {
+	compactRev, rev := cm.hasher.RevisionRange()
+	windows := sampleRevisionWindows(compactRev, rev, cm.samples, corruptCheckSampleRevisions)
+
+	alarmed := false
+	checked := make(map[types.ID]struct{})
+	for _, w := range windows {
+		h, _, err := cm.hasher.HashRevisions(w.from, w.to)
+		if err == mvcc.ErrCompacted {
+			// compacted since the windows were picked
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		result.Revision = w.to
+
+		for _, p := range cm.hasher.PeerHashRevisions(w.from, w.to) {
+			if p.resp == nil {
+				continue
+			}
+			checked[p.id] = struct{}{}
+			if p.resp.Hash == h.Hash || alarmed {
+				continue
+			}
+			cm.lg.Warn(
+				"found hash mismatch of sampled revisions",
+				zap.Int64("from-revision", w.from),
+				zap.Int64("to-revision", w.to),
+				zap.Uint32("leader-hash", h.Hash),
+				zap.Uint32("follower-hash", p.resp.Hash),
+				zap.String("follower-peer-id", p.id.String()),
+			)
+			alarmed = true
+			cm.hasher.TriggerCorruptAlarm(p.id)
+			result.Corrupted = append(result.Corrupted, p.id)
+		}
+	}
+	result.MembersChecked = len(checked)
+	cm.lg.Info("finished sampled corruption check",
+		zap.Int("number-of-windows-checked", len(windows)),
+		zap.Int("number-of-peers-checked", len(checked)),
+	)
+	return nil
+}
+
+type revisionWindow struct {
+	from, to int64
+}
+
+// sampleRevisionWindows picks n windows of size revisions in the revisions
+// after compactRev up to rev, one at random in each nth of them, or returns
+// all the revisions as a single window if the windows would cover them.
+func sampleRevisionWindows(compactRev, rev int64, n int, size int64) []revisionWindow {
+	if rev <= compactRev {
+		return nil
+	}
+	if n < 1 {
+		n = 1
+	}
+	if rev-compactRev <= int64(n)*size {
+		return []revisionWindow{{from: compactRev + 1, to: rev}}
+	}
+	span := (rev - compactRev) / int64(n)
+	windows := make([]revisionWindow, n)
+	for i := range windows {
+		from := compactRev + 1 + int64(i)*span + rand.Int63n(span-size+1)
+		windows[i] = revisionWindow{from: from, to: from + size - 1}
+	}
+	return windows
+}
+
+// LastCheck returns the result of the last periodic or sampled check.
+func (cm *corruptionChecker) LastCheck() CorruptionCheckResult {
+	cm.mux.RLock()
+	defer cm.mux.RUnlock()
+	return cm.lastCheck
+}
+
+func (cm *corruptionChecker) setLastCheck(result CorruptionCheckResult) {
+	cm.mux.Lock()
+	cm.lastCheck = result
+	cm.mux.Unlock()
+}
+
 // CompactHashCheck is based on the fact that 'compactions' are coordinated
 // between raft members and performed at the same revision. For each compacted
 // revision there is KV store hash computed and saved for some time.
@@ -442,6 +594,19 @@ type peerHashKVResp struct {
 }
 
 func (s *EtcdServer) getPeerHashKVs(rev int64) []*peerHashKVResp {
+	return s.getPeerHashes(func(ctx context.Context, cc *http.Client, url string) (*pb.HashKVResponse, error) {
+		return HashByRev(ctx, cc, url, rev)
+	}, zap.Int64("requested-revision", rev))
+}
+
+func (s *EtcdServer) getPeerHashRevisions(from, to int64) []*peerHashKVResp {
+	return s.getPeerHashes(func(ctx context.Context, cc *http.Client, url string) (*pb.HashKVResponse, error) {
+		return HashRevisions(ctx, cc, url, from, to)
+	}, zap.Int64("requested-from-revision", from), zap.Int64("requested-to-revision", to))
+}
+
+// getPeerHashes fetches a hash from each peer, trying its endpoints in turn.
+func (s *EtcdServer) getPeerHashes(fetch func(ctx context.Context, cc *http.Client, url string) (*pb.HashKVResponse, error), fields ...zap.Field) []*peerHashKVResp {
 	// TODO: handle the case when "s.cluster.Members" have not
 	// been populated (e.g. no snapshot to load from disk)
 	members := s.cluster.Members()
@@ -466,7 +631,7 @@ func (s *EtcdServer) getPeerHashKVs(rev int64) []*peerHashKVResp {
 		var lastErr error
 		for _, ep := range p.eps {
 			ctx, cancel := context.WithTimeout(context.Background(), s.Cfg.ReqTimeout())
-			resp, lastErr := HashByRev(ctx, cc, ep, rev)
+			resp, lastErr := fetch(ctx, cc, ep)
 			cancel()
 			if lastErr == nil {
 				resps = append(resps, &peerHashKVResp{peerInfo: p, resp: resp, err: nil})
@@ -474,10 +639,11 @@ func (s *EtcdServer) getPeerHashKVs(rev int64) []*peerHashKVResp {
 			}
 			lg.Warn(
 				"failed hash kv request",
-				zap.String("local-member-id", s.MemberId().String()),
-				zap.Int64("requested-revision", rev),
-				zap.String("remote-peer-endpoint", ep),
-				zap.Error(lastErr),
+				append([]zap.Field{
+					zap.String("local-member-id", s.MemberId().String()),
+					zap.String("remote-peer-endpoint", ep),
+					zap.Error(lastErr),
+				}, fields...)...,
 			)
 		}
 
@@ -489,7 +655,16 @@ func (s *EtcdServer) getPeerHashKVs(rev int64) []*peerHashKVResp {
 	return resps
 }
 
-const PeerHashKVPath = "/members/hashkv"
+const (
+	PeerHashKVPath        = "/members/hashkv"
+	PeerHashRevisionsPath = "/members/hashrevisions"
+)
+
+// hashRevisionsRequest is the body of the PeerHashRevisionsPath requests.
+type hashRevisionsRequest struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
 
 type hashKVHandler struct {
 	lg     *zap.Logger
@@ -506,7 +681,7 @@ func (h *hashKVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if r.URL.Path != PeerHashKVPath {
+	if r.URL.Path != PeerHashKVPath && r.URL.Path != PeerHashRevisionsPath {
 		http.Error(w, "bad path", http.StatusBadRequest)
 		return
 	}
@@ -518,19 +693,32 @@ func (h *hashKVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req := &pb.HashKVRequest{}
-	if err := json.Unmarshal(b, req); err != nil {
-		h.lg.Warn("failed to unmarshal request", zap.Error(err))
-		http.Error(w, "error unmarshalling request", http.StatusBadRequest)
-		return
+	var (
+		hash   mvcc.KeyValueHash
+		rev    int64
+		fields []zap.Field
+	)
+	if r.URL.Path == PeerHashRevisionsPath {
+		req := &hashRevisionsRequest{}
+		if err := json.Unmarshal(b, req); err != nil {
+			h.lg.Warn("failed to unmarshal request", zap.Error(err))
+			http.Error(w, "error unmarshalling request", http.StatusBadRequest)
+			return
+		}
+		hash, rev, err = h.server.KV().HashStorage().HashRevisions(req.From, req.To)
+		fields = []zap.Field{zap.Int64("requested-from-revision", req.From), zap.Int64("requested-to-revision", req.To)}
+	} else {
+		req := &pb.HashKVRequest{}
+		if err := json.Unmarshal(b, req); err != nil {
+			h.lg.Warn("failed to unmarshal request", zap.Error(err))
+			http.Error(w, "error unmarshalling request", http.StatusBadRequest)
+			return
+		}
+		hash, rev, err = h.server.KV().HashStorage().HashByRev(req.Revision)
+		fields = []zap.Field{zap.Int64("requested-revision", req.Revision)}
 	}
-	hash, rev, err := h.server.KV().HashStorage().HashByRev(req.Revision)
 	if err != nil {
-		h.lg.Warn(
-			"failed to get hashKV",
-			zap.Int64("requested-revision", req.Revision),
-			zap.Error(err),
-		)
+		h.lg.Warn("failed to get hashKV", append(fields, zap.Error(err))...)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -554,12 +742,20 @@ func (h *hashKVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // HashByRev fetch hash of kv store at the given rev via http call to the given url
 func HashByRev(ctx context.Context, cc *http.Client, url string, rev int64) (*pb.HashKVResponse, error) {
-	hashReq := &pb.HashKVRequest{Revision: rev}
+	return fetchPeerHash(ctx, cc, url+PeerHashKVPath, &pb.HashKVRequest{Revision: rev})
+}
+
+// HashRevisions fetches the hash of the kv store revisions from a revision
+// up to another via http call to the given url.
+func HashRevisions(ctx context.Context, cc *http.Client, url string, from, to int64) (*pb.HashKVResponse, error) {
+	return fetchPeerHash(ctx, cc, url+PeerHashRevisionsPath, &hashRevisionsRequest{From: from, To: to})
+}
+
+func fetchPeerHash(ctx context.Context, cc *http.Client, requestUrl string, hashReq interface{}) (*pb.HashKVResponse, error) {
 	hashReqBytes, err := json.Marshal(hashReq)
 	if err != nil {
 		return nil, err
 	}
-	requestUrl := url + PeerHashKVPath
 	req, err := http.NewRequest(http.MethodGet, requestUrl, bytes.NewReader(hashReqBytes))
 	if err != nil {
 		return nil, err
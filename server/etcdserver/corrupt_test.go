@@ -218,10 +218,134 @@ func TestPeriodicCheck(t *testing.T) {
 				t.Errorf("Unexpected corrupt triggered, got: %v, expected?: %v", tc.hasher.alarmTriggered, tc.expectCorrupt)
 			}
 			assert.Equal(t, tc.expectActions, tc.hasher.actions)
+
+			last := monitor.LastCheck()
+			assert.Equal(t, pb.CorruptionCheckRequest_FULL, last.Scope)
+			assert.Equal(t, err, last.Err)
+			assert.Equal(t, tc.expectCorrupt, len(last.Corrupted) == 1)
 		})
 	}
 }
 
+func TestSampledCheck(t *testing.T) {
+	tcs := []struct {
+		name                 string
+		hasher               fakeHasher
+		expectError          bool
+		expectCorrupt        bool
+		expectMembersChecked int
+		expectActions        []string
+	}{
+		{
+			name:          "No revisions",
+			hasher:        fakeHasher{},
+			expectActions: []string{"RevisionRange()"},
+		},
+		{
+			name: "Peer with same hash",
+			hasher: fakeHasher{
+				rev:        10,
+				peerHashes: []*peerHashKVResp{{peerInfo: peerInfo{id: 42}, resp: &pb.HashKVResponse{Hash: 1}}},
+			},
+			expectMembersChecked: 1,
+			expectActions:        []string{"RevisionRange()", "HashRevisions(1, 10)", "PeerHashRevisions(1, 10)"},
+		},
+		{
+			name: "Peer with nil response",
+			hasher: fakeHasher{
+				rev:        10,
+				peerHashes: []*peerHashKVResp{{peerInfo: peerInfo{id: 42}}},
+			},
+			expectActions: []string{"RevisionRange()", "HashRevisions(1, 10)", "PeerHashRevisions(1, 10)"},
+		},
+		{
+			name: "Peer with different hash",
+			hasher: fakeHasher{
+				compactRev: 5,
+				rev:        10,
+				peerHashes: []*peerHashKVResp{{peerInfo: peerInfo{id: 42}, resp: &pb.HashKVResponse{Hash: 2}}},
+			},
+			expectCorrupt:        true,
+			expectMembersChecked: 1,
+			expectActions:        []string{"RevisionRange()", "HashRevisions(6, 10)", "PeerHashRevisions(6, 10)", "TriggerCorruptAlarm(42)"},
+		},
+		{
+			name: "Multiple corrupted peers trigger one alarm",
+			hasher: fakeHasher{
+				rev: 10,
+				peerHashes: []*peerHashKVResp{
+					{peerInfo: peerInfo{id: 88}, resp: &pb.HashKVResponse{Hash: 2}},
+					{peerInfo: peerInfo{id: 89}, resp: &pb.HashKVResponse{Hash: 2}},
+				},
+			},
+			expectCorrupt:        true,
+			expectMembersChecked: 2,
+			expectActions:        []string{"RevisionRange()", "HashRevisions(1, 10)", "PeerHashRevisions(1, 10)", "TriggerCorruptAlarm(88)"},
+		},
+		{
+			name: "Compacted while checking",
+			hasher: fakeHasher{
+				rev:           10,
+				hashRevisions: hashByRev{err: mvcc.ErrCompacted},
+			},
+			expectActions: []string{"RevisionRange()", "HashRevisions(1, 10)"},
+		},
+		{
+			name: "Error getting hash",
+			hasher: fakeHasher{
+				rev:           10,
+				hashRevisions: hashByRev{err: fmt.Errorf("error getting hash")},
+			},
+			expectError:   true,
+			expectActions: []string{"RevisionRange()", "HashRevisions(1, 10)"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.hasher.hashRevisions.err == nil {
+				tc.hasher.hashRevisions.hash = mvcc.KeyValueHash{Hash: 1}
+			}
+			monitor := corruptionChecker{
+				lg:      zaptest.NewLogger(t),
+				hasher:  &tc.hasher,
+				samples: 8,
+			}
+			err := monitor.SampledCheck()
+			if gotError := err != nil; gotError != tc.expectError {
+				t.Errorf("Unexpected error, got: %v, expected?: %v", err, tc.expectError)
+			}
+			if tc.hasher.alarmTriggered != tc.expectCorrupt {
+				t.Errorf("Unexpected corrupt triggered, got: %v, expected?: %v", tc.hasher.alarmTriggered, tc.expectCorrupt)
+			}
+			assert.Equal(t, tc.expectActions, tc.hasher.actions)
+
+			last := monitor.LastCheck()
+			assert.Equal(t, pb.CorruptionCheckRequest_SAMPLED, last.Scope)
+			assert.Equal(t, err, last.Err)
+			assert.Equal(t, tc.expectMembersChecked, last.MembersChecked)
+			assert.Equal(t, tc.expectCorrupt, len(last.Corrupted) == 1)
+		})
+	}
+}
+
+func TestSampleRevisionWindows(t *testing.T) {
+	assert.Empty(t, sampleRevisionWindows(10, 10, 8, 100))
+	assert.Equal(t, []revisionWindow{{from: 11, to: 800}}, sampleRevisionWindows(10, 800, 8, 100))
+	assert.Equal(t, []revisionWindow{{from: 11, to: 810}}, sampleRevisionWindows(10, 810, 8, 100))
+
+	for i := 0; i < 100; i++ {
+		windows := sampleRevisionWindows(10, 100010, 8, 100)
+		assert.Len(t, windows, 8)
+		last := int64(10)
+		for _, w := range windows {
+			assert.Equal(t, int64(99), w.to-w.from)
+			assert.Greater(t, w.from, last, "windows must be sorted and not overlap")
+			last = w.to
+		}
+		assert.LessOrEqual(t, last, int64(100010))
+	}
+}
+
 func TestCompactHashCheck(t *testing.T) {
 	tcs := []struct {
 		name                string
@@ -414,6 +538,8 @@ type fakeHasher struct {
 	hashByRevResponses     []hashByRev
 	linearizableReadNotify error
 	hashes                 []mvcc.KeyValueHash
+	hashRevisions          hashByRev
+	compactRev, rev        int64
 
 	alarmTriggered bool
 	actions        []string
@@ -439,6 +565,11 @@ func (f *fakeHasher) HashByRev(rev int64) (hash mvcc.KeyValueHash, revision int6
 	return hashByRev.hash, hashByRev.revision, hashByRev.err
 }
 
+func (f *fakeHasher) HashRevisions(from, to int64) (hash mvcc.KeyValueHash, revision int64, err error) {
+	f.actions = append(f.actions, fmt.Sprintf("HashRevisions(%d, %d)", from, to))
+	return f.hashRevisions.hash, f.hashRevisions.revision, f.hashRevisions.err
+}
+
 func (f *fakeHasher) Store(hash mvcc.KeyValueHash) {
 	f.actions = append(f.actions, fmt.Sprintf("Store(%v)", hash))
 	f.hashes = append(f.hashes, hash)
@@ -464,6 +595,16 @@ func (f *fakeHasher) PeerHashByRev(rev int64) []*peerHashKVResp {
 	return f.peerHashes
 }
 
+func (f *fakeHasher) PeerHashRevisions(from, to int64) []*peerHashKVResp {
+	f.actions = append(f.actions, fmt.Sprintf("PeerHashRevisions(%d, %d)", from, to))
+	return f.peerHashes
+}
+
+func (f *fakeHasher) RevisionRange() (int64, int64) {
+	f.actions = append(f.actions, "RevisionRange()")
+	return f.compactRev, f.rev
+}
+
 func (f *fakeHasher) LinearizableReadNotify(ctx context.Context) error {
 	f.actions = append(f.actions, "LinearizableReadNotify()")
 	return f.linearizableReadNotify
@@ -0,0 +1,80 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+)
+
+// drainPollInterval is the interval the requests in flight are checked at
+// while draining.
+const drainPollInterval = 10 * time.Millisecond
+
+// BeginRequest tracks a client request in flight, or a stream ending by
+// itself once the server drains, until the returned function is called.
+func (s *EtcdServer) BeginRequest() (end func()) {
+	atomic.AddInt64(&s.inflightRequests, 1)
+	return func() { atomic.AddInt64(&s.inflightRequests, -1) }
+}
+
+// DrainNotify returns a channel closed once the server starts draining, on
+// which the new client streams are rejected and the watch streams are ended
+// once their buffered events are sent.
+func (s *EtcdServer) DrainNotify() <-chan struct{} { return s.drainc }
+
+// Drain drains the client requests of the server: the new streams are
+// rejected, the leadership is transferred if the server is the leader, and
+// the requests in flight are waited for until ctx is done. The server keeps
+// serving the new unary requests until it is stopped, which is left to the
+// caller.
+func (s *EtcdServer) Drain(ctx context.Context) error {
+	lg := s.Logger()
+	s.drainOnce.Do(func() {
+		lg.Info("draining the client requests", zap.String("local-member-id", s.MemberId().String()))
+		close(s.drainc)
+	})
+
+	s.lifecycle.set(lg, LifecycleDraining, "draining: transferring the leadership")
+	if err := s.TransferLeadership(); err != nil {
+		lg.Warn("leadership transfer failed", zap.String("local-member-id", s.MemberId().String()), zap.Error(err))
+	}
+
+	s.lifecycle.set(lg, LifecycleDraining, "draining: waiting for the client requests in flight")
+	start := time.Now()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		n := atomic.LoadInt64(&s.inflightRequests)
+		if n == 0 {
+			lg.Info("drained the client requests", zap.Duration("took", time.Since(start)))
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			lg.Warn("timed out draining the client requests", zap.Int64("in-flight", n), zap.Duration("took", time.Since(start)))
+			return fmt.Errorf("%d client requests still in flight: %w", n, ctx.Err())
+		case <-s.stopping:
+			return errors.ErrStopped
+		}
+	}
+}
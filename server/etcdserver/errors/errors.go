@@ -31,17 +31,24 @@ var (
 	ErrLeaderChanged               = errors.New("etcdserver: leader changed")
 	ErrNotEnoughStartedMembers     = errors.New("etcdserver: re-configuration failed due to not enough started members")
 	ErrLearnerNotReady             = errors.New("etcdserver: can only promote a learner member which is in sync with leader")
+	ErrMemberActive                = errors.New("etcdserver: can only replace a stopped member")
 	ErrNoLeader                    = errors.New("etcdserver: no leader")
 	ErrNotLeader                   = errors.New("etcdserver: not leader")
 	ErrRequestTooLarge             = errors.New("etcdserver: request is too large")
 	ErrNoSpace                     = errors.New("etcdserver: no space")
+	ErrPrefixQuotaExceeded         = errors.New("etcdserver: prefix quota exceeded")
+	ErrInvalidPrefixQuota          = errors.New("etcdserver: invalid prefix quota")
+	ErrRevisionPinned              = errors.New("etcdserver: revision is pinned")
+	ErrInvalidRevisionPin          = errors.New("etcdserver: invalid revision pin")
 	ErrTooManyRequests             = errors.New("etcdserver: too many requests")
 	ErrUnhealthy                   = errors.New("etcdserver: unhealthy cluster")
 	ErrCorrupt                     = errors.New("etcdserver: corrupt cluster")
 	ErrBadLeaderTransferee         = errors.New("etcdserver: bad leader transferee")
+	ErrNotCapable                  = errors.New("etcdserver: not capable")
 	ErrClusterVersionUnavailable   = errors.New("etcdserver: cluster version not found during downgrade")
 	ErrWrongDowngradeVersionFormat = errors.New("etcdserver: wrong downgrade target version format")
 	ErrKeyNotFound                 = errors.New("etcdserver: key not found")
+	ErrKeyNotReserved              = errors.New("etcdserver: key not in the reserved key space")
 )
 
 type DiscoveryError struct {
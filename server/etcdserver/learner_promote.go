@@ -0,0 +1,143 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/raft/v3"
+)
+
+// learnerAutoPromoteInterval is the interval at which the raft progress of the
+// learners is checked for their automatic promotion.
+const learnerAutoPromoteInterval = time.Second
+
+// learnerAutoPromoter tracks since when the learners stay within the lag of the
+// leader, to promote those staying within it long enough.
+type learnerAutoPromoter struct {
+	lag      uint64
+	duration time.Duration
+	// since is the time each learner is within the lag since
+	since map[types.ID]time.Time
+}
+
+func newLearnerAutoPromoter(lag uint64, duration time.Duration) *learnerAutoPromoter {
+	return &learnerAutoPromoter{lag: lag, duration: duration, since: make(map[types.ID]time.Time)}
+}
+
+// reset forgets the tracked learners, once the local member is no longer the
+// leader tracking their raft progress.
+func (p *learnerAutoPromoter) reset() {
+	p.since = make(map[types.ID]time.Time)
+}
+
+// ready returns the learners which stayed within the lag for the duration at
+// now, given the raft status of the leader. The observers are never promoted.
+func (p *learnerAutoPromoter) ready(rs raft.Status, members []*membership.Member, now time.Time) []types.ID {
+	leaderMatch := rs.Progress[rs.ID].Match
+	learners := make(map[types.ID]struct{})
+	var ids []types.ID
+	for _, m := range members {
+		if !m.IsLearner || m.IsObserver {
+			continue
+		}
+		pr, ok := rs.Progress[uint64(m.ID)]
+		if !ok || pr.Match+p.lag < leaderMatch {
+			continue
+		}
+		learners[m.ID] = struct{}{}
+		since, ok := p.since[m.ID]
+		if !ok {
+			p.since[m.ID] = now
+			since = now
+		}
+		if now.Sub(since) >= p.duration {
+			ids = append(ids, m.ID)
+		}
+	}
+	// the learners out of the lag, promoted or removed start over
+	for id := range p.since {
+		if _, ok := learners[id]; !ok {
+			delete(p.since, id)
+		}
+	}
+	return ids
+}
+
+// monitorLearners promotes the learners staying within
+// ExperimentalLearnerAutoPromoteLag entries of the leader for
+// ExperimentalLearnerAutoPromoteDuration. Only the leader, which tracks the raft
+// progress of the members, promotes them, recording the promotions in the audit
+// log.
+func (s *EtcdServer) monitorLearners() {
+	d := s.Cfg.ExperimentalLearnerAutoPromoteDuration
+	if d == 0 {
+		return
+	}
+	lg := s.Logger()
+	p := newLearnerAutoPromoter(s.Cfg.ExperimentalLearnerAutoPromoteLag, d)
+	for {
+		select {
+		case <-time.After(learnerAutoPromoteInterval):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			p.reset()
+			continue
+		}
+		rs := s.raftStatus()
+		if rs.Progress == nil {
+			continue
+		}
+		leaderMatch := rs.Progress[rs.ID].Match
+		for _, id := range p.ready(rs, s.cluster.Members(), time.Now()) {
+			var lag uint64
+			if match := rs.Progress[uint64(id)].Match; match < leaderMatch {
+				lag = leaderMatch - match
+			}
+			ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+			_, err := s.proposePromoteMember(ctx, uint64(id))
+			cancel()
+			if err != nil {
+				learnerPromoteFailed.WithLabelValues(err.Error()).Inc()
+				lg.Warn(
+					"failed to promote learner automatically",
+					zap.String("local-member-id", s.MemberId().String()),
+					zap.String("learner-member-id", id.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			learnerPromoteSucceed.Inc()
+			fields := []zap.Field{
+				zap.String("local-member-id", s.MemberId().String()),
+				zap.String("learner-member-id", id.String()),
+				zap.Uint64("lag", lag),
+				zap.Uint64("max-lag", p.lag),
+				zap.Duration("caught-up-for", time.Since(p.since[id])),
+			}
+			lg.Info("promoted learner automatically", fields...)
+			if al := s.Cfg.AuditLogger; al != nil {
+				al.Info("automatic learner promotion", fields...)
+			}
+		}
+	}
+}
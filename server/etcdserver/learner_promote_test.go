@@ -0,0 +1,67 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/tracker"
+)
+
+func TestLearnerAutoPromoterReady(t *testing.T) {
+	members := []*membership.Member{
+		{ID: 1},
+		{ID: 2, RaftAttributes: membership.RaftAttributes{IsLearner: true}},
+		{ID: 3, RaftAttributes: membership.RaftAttributes{IsLearner: true}},
+		{ID: 4, RaftAttributes: membership.RaftAttributes{IsLearner: true, IsObserver: true}},
+	}
+	status := func(matches map[uint64]uint64) raft.Status {
+		rs := raft.Status{Progress: make(map[uint64]tracker.Progress)}
+		rs.ID = 1
+		for id, match := range matches {
+			rs.Progress[id] = tracker.Progress{Match: match}
+		}
+		return rs
+	}
+
+	p := newLearnerAutoPromoter(10, 3*time.Second)
+	now := time.Now()
+
+	// 2 is within the lag, 3 is not and 4 is an observer
+	rs := status(map[uint64]uint64{1: 100, 2: 95, 3: 50, 4: 100})
+	assert.Empty(t, p.ready(rs, members, now))
+	assert.Empty(t, p.ready(rs, members, now.Add(2*time.Second)))
+	assert.Equal(t, []types.ID{2}, p.ready(rs, members, now.Add(3*time.Second)))
+
+	// 3 falling behind again starts over
+	rs = status(map[uint64]uint64{1: 200, 2: 195, 3: 195, 4: 200})
+	assert.Equal(t, []types.ID{2}, p.ready(rs, members, now.Add(4*time.Second)))
+	rs = status(map[uint64]uint64{1: 300, 2: 295, 3: 250, 4: 300})
+	assert.Equal(t, []types.ID{2}, p.ready(rs, members, now.Add(5*time.Second)))
+	rs = status(map[uint64]uint64{1: 300, 2: 300, 3: 300, 4: 300})
+	assert.Equal(t, []types.ID{2}, p.ready(rs, members, now.Add(7*time.Second)))
+	assert.Equal(t, []types.ID{2}, p.ready(rs, members, now.Add(9*time.Second)))
+	assert.Equal(t, []types.ID{2, 3}, p.ready(rs, members, now.Add(10*time.Second)))
+
+	// the tracking starts over on a leader change
+	p.reset()
+	assert.Empty(t, p.ready(rs, members, now.Add(11*time.Second)))
+}
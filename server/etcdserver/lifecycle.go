@@ -0,0 +1,196 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LifecycleState is a state of the lifecycle of a server. The states are
+// ordered, a server only moving to later states.
+type LifecycleState int
+
+const (
+	// LifecycleStarting is the state of a server bootstrapped from its data
+	// dir whose raft node is not started yet.
+	LifecycleStarting LifecycleState = iota
+	// LifecycleJoinedRaft is the state of a server whose raft node runs,
+	// while it replays the committed entries, waits for a leader and
+	// publishes its member attributes to the cluster.
+	LifecycleJoinedRaft
+	// LifecyclePublished is the state of a server whose member attributes
+	// are published, ready to serve the client requests. ReadyNotify is
+	// closed on this transition.
+	LifecyclePublished
+	// LifecycleServing is the state of a server whose client listeners serve
+	// the client requests.
+	LifecycleServing
+	// LifecycleDraining is the state of a stopping server, draining the
+	// client requests and transferring its leadership.
+	LifecycleDraining
+	// LifecycleStopped is the state of a stopped server. StopNotify is
+	// closed on this transition.
+	LifecycleStopped
+)
+
+var lifecycleStateNames = [...]string{
+	LifecycleStarting:   "starting",
+	LifecycleJoinedRaft: "joined-raft",
+	LifecyclePublished:  "published",
+	LifecycleServing:    "serving",
+	LifecycleDraining:   "draining",
+	LifecycleStopped:    "stopped",
+}
+
+func (st LifecycleState) String() string {
+	if st < 0 || int(st) >= len(lifecycleStateNames) {
+		return fmt.Sprintf("unknown(%d)", int(st))
+	}
+	return lifecycleStateNames[st]
+}
+
+// LifecycleTransition is a change of the lifecycle state of a server, or of
+// the reason of its current state if From equals To, e.g. a server that
+// cannot reach its peers while publishing its member attributes.
+type LifecycleTransition struct {
+	From   LifecycleState
+	To     LifecycleState
+	Reason string
+	Time   time.Time
+}
+
+// lifecycleSubscriberBuffer is the number of transitions buffered for a
+// subscriber, further transitions being dropped until it catches up.
+const lifecycleSubscriberBuffer = 16
+
+type lifecycle struct {
+	mu   sync.Mutex
+	cur  LifecycleTransition
+	subs map[chan LifecycleTransition]struct{}
+	// stopped is closed on the move to LifecycleStopped
+	stopped chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		cur: LifecycleTransition{
+			From:   LifecycleStarting,
+			To:     LifecycleStarting,
+			Reason: "bootstrapped from the data dir",
+			Time:   time.Now(),
+		},
+		subs:    make(map[chan LifecycleTransition]struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// set moves to the given state, or updates the reason of the current one.
+// Moves to earlier states are ignored.
+func (l *lifecycle) set(lg *zap.Logger, st LifecycleState, reason string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if st < l.cur.To || (st == l.cur.To && reason == l.cur.Reason) || l.cur.To == LifecycleStopped {
+		return
+	}
+	l.cur = LifecycleTransition{From: l.cur.To, To: st, Reason: reason, Time: time.Now()}
+	if lg != nil {
+		lg.Info(
+			"server lifecycle changed",
+			zap.Stringer("from", l.cur.From),
+			zap.Stringer("to", l.cur.To),
+			zap.String("reason", reason),
+		)
+	}
+	for ch := range l.subs {
+		select {
+		case ch <- l.cur:
+		default:
+		}
+		if st == LifecycleStopped {
+			close(ch)
+			delete(l.subs, ch)
+		}
+	}
+	if st == LifecycleStopped {
+		close(l.stopped)
+	}
+}
+
+func (l *lifecycle) current() LifecycleTransition {
+	if l == nil {
+		return LifecycleTransition{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cur
+}
+
+func (l *lifecycle) subscribe(ctx context.Context) <-chan LifecycleTransition {
+	ch := make(chan LifecycleTransition, lifecycleSubscriberBuffer)
+	if l == nil {
+		close(ch)
+		return ch
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ch <- l.cur
+	if l.cur.To == LifecycleStopped {
+		close(ch)
+		return ch
+	}
+	l.subs[ch] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-l.stopped:
+			return
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.subs[ch]; ok {
+			close(ch)
+			delete(l.subs, ch)
+		}
+	}()
+	return ch
+}
+
+// Lifecycle returns the last lifecycle transition of the server, whose To is
+// its current state.
+func (s *EtcdServer) Lifecycle() LifecycleTransition { return s.lifecycle.current() }
+
+// SubscribeLifecycle returns a channel receiving the last lifecycle
+// transition of the server, then the following ones. The channel is closed
+// once the server is stopped or ctx is done. The transitions are dropped
+// for a subscriber not keeping up, Lifecycle still returning the current
+// state.
+func (s *EtcdServer) SubscribeLifecycle(ctx context.Context) <-chan LifecycleTransition {
+	return s.lifecycle.subscribe(ctx)
+}
+
+// SetLifecycle moves the server to the given lifecycle state for the given
+// reason. It is for the embedders reporting the states the server does not
+// see by itself, LifecycleServing and LifecycleDraining.
+func (s *EtcdServer) SetLifecycle(st LifecycleState, reason string) {
+	s.lifecycle.set(s.Logger(), st, reason)
+}
@@ -112,12 +112,39 @@ var (
 		Name:      "read_indexes_failed_total",
 		Help:      "The total number of failed read indexes seen.",
 	})
+	boundedStalenessReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "bounded_staleness_reads_total",
+		Help:      "The total number of bounded staleness reads, by whether they were served locally or linearized because the member lagged.",
+	},
+		[]string{"served"},
+	)
 	leaseExpired = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "etcd_debugging",
 		Subsystem: "server",
 		Name:      "lease_expired_total",
 		Help:      "The total number of expired leases.",
 	})
+	keyExpired = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "server",
+		Name:      "key_expired_total",
+		Help:      "The total number of keys deleted once their TTL elapsed.",
+	})
+	leaseMaxAttachedKeys = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "server",
+		Name:      "lease_max_attached_keys",
+		Help:      "The largest number of keys attached to a single lease, checked while a maximum is configured.",
+	})
+
+	autoDefragTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "server",
+		Name:      "auto_defrag_total",
+		Help:      "The total number of backend defragmentations triggered by its fragmentation.",
+	})
 
 	currentVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "etcd",
@@ -167,7 +194,11 @@ func init() {
 	prometheus.MustRegister(proposalsFailed)
 	prometheus.MustRegister(slowReadIndex)
 	prometheus.MustRegister(readIndexFailed)
+	prometheus.MustRegister(boundedStalenessReads)
 	prometheus.MustRegister(leaseExpired)
+	prometheus.MustRegister(keyExpired)
+	prometheus.MustRegister(leaseMaxAttachedKeys)
+	prometheus.MustRegister(autoDefragTotal)
 	prometheus.MustRegister(currentVersion)
 	prometheus.MustRegister(currentGoVersion)
 	prometheus.MustRegister(serverID)
@@ -284,7 +284,7 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 					// We might improve this later on if it causes unnecessary long blocking issues.
 					waitApply := false
 					for _, ent := range rd.CommittedEntries {
-						if ent.Type == raftpb.EntryConfChange {
+						if ent.Type == raftpb.EntryConfChange || ent.Type == raftpb.EntryConfChangeV2 {
 							waitApply = true
 							break
 						}
@@ -305,6 +305,24 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 				} else {
 					// leader already processed 'MsgSnap' and signaled
 					notifyc <- struct{}{}
+
+					// The leader leaves a joint configuration on its own once advanced past the
+					// confChangeV2 entering it, and must apply the change to raft before advancing
+					// not to leave it twice.
+					waitApply := false
+					for _, ent := range rd.CommittedEntries {
+						if ent.Type == raftpb.EntryConfChangeV2 {
+							waitApply = true
+							break
+						}
+					}
+					if waitApply {
+						select {
+						case notifyc <- struct{}{}:
+						case <-r.stopped:
+							return
+						}
+					}
 				}
 
 				r.Advance()
@@ -0,0 +1,140 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+)
+
+// RuntimeConfig is the part of the configuration of the server that can be
+// changed without restarting the member.
+type RuntimeConfig struct {
+	// AutoCompactionRetention is the retention of the auto compaction in its
+	// configured mode, 0 to disable it. A change restarts the auto
+	// compaction, as on a restart of the member.
+	AutoCompactionRetention time.Duration
+	// QuotaBackendBytes is the backend quota, 0 for the default quota. The
+	// quota can neither be disabled nor enabled at runtime.
+	QuotaBackendBytes int64
+	// CorruptCheckTime is the interval of the corruption checks, 0 to disable
+	// them unless they are scheduled.
+	CorruptCheckTime            time.Duration
+	WarningApplyDuration        time.Duration
+	WarningUnaryRequestDuration time.Duration
+}
+
+// RuntimeConfig returns the current runtime config of the server.
+func (s *EtcdServer) RuntimeConfig() RuntimeConfig {
+	if rc, ok := s.runtimeCfg.Load().(RuntimeConfig); ok {
+		return rc
+	}
+	return RuntimeConfig{
+		AutoCompactionRetention:     s.Cfg.AutoCompactionRetention,
+		QuotaBackendBytes:           s.Cfg.QuotaBackendBytes,
+		CorruptCheckTime:            s.Cfg.CorruptCheckTime,
+		WarningApplyDuration:        s.Cfg.WarningApplyDuration,
+		WarningUnaryRequestDuration: s.Cfg.WarningUnaryRequestDuration,
+	}
+}
+
+// Reconfigure replaces the runtime config of the server.
+func (s *EtcdServer) Reconfigure(rc RuntimeConfig) error {
+	if rc.AutoCompactionRetention < 0 || rc.CorruptCheckTime < 0 {
+		return fmt.Errorf("invalid runtime config, the auto compaction retention and the corruption check interval must not be negative")
+	}
+	if rc.WarningApplyDuration <= 0 || rc.WarningUnaryRequestDuration <= 0 {
+		return fmt.Errorf("invalid runtime config, the warning durations must be positive")
+	}
+	if (s.Cfg.QuotaBackendBytes < 0) != (rc.QuotaBackendBytes < 0) {
+		return fmt.Errorf("invalid runtime config, the backend quota cannot be enabled or disabled at runtime")
+	}
+
+	s.reconfigureMu.Lock()
+	defer s.reconfigureMu.Unlock()
+	prev := s.RuntimeConfig()
+	if rc.AutoCompactionRetention != prev.AutoCompactionRetention {
+		if err := s.resetCompactor(rc.AutoCompactionRetention); err != nil {
+			return err
+		}
+	}
+	s.runtimeCfg.Store(rc)
+	if s.runtimeCfgChanged != nil {
+		s.runtimeCfgChanged.Notify()
+	}
+
+	s.Logger().Info(
+		"reconfigured server",
+		zap.String("local-member-id", s.MemberId().String()),
+		zap.Duration("auto-compaction-retention", rc.AutoCompactionRetention),
+		zap.Int64("quota-backend-bytes", rc.QuotaBackendBytes),
+		zap.Duration("corrupt-check-time-interval", rc.CorruptCheckTime),
+		zap.Duration("warning-apply-duration", rc.WarningApplyDuration),
+		zap.Duration("warning-unary-request-duration", rc.WarningUnaryRequestDuration),
+	)
+	return nil
+}
+
+// resetCompactor replaces the auto compactor by one of the given retention.
+func (s *EtcdServer) resetCompactor(retention time.Duration) error {
+	var c v3compactor.Compactor
+	if retention != 0 {
+		var err error
+		c, err = v3compactor.New(s.Logger(), s.Cfg.AutoCompactionMode, retention, s.Cfg.AutoCompactionMaxRevisions, s.Cfg.CompactionRevisionAlignment, s.kv, s)
+		if err != nil {
+			return err
+		}
+		c.Run()
+	}
+
+	s.compactorMu.Lock()
+	old := s.compactor
+	s.compactor = c
+	// the compactors of the followers are paused on leadership changes
+	if c != nil && !s.isLeader() {
+		c.Pause()
+	}
+	s.compactorMu.Unlock()
+	if old != nil {
+		old.Stop()
+	}
+	return nil
+}
+
+// runtimeConfigChanged returns a channel closed on the next change of the
+// runtime config.
+func (s *EtcdServer) runtimeConfigChanged() <-chan struct{} {
+	if s.runtimeCfgChanged == nil {
+		return nil
+	}
+	return s.runtimeCfgChanged.Receive()
+}
+
+// QuotaBackendBytes returns the current backend quota.
+func (s *EtcdServer) QuotaBackendBytes() int64 { return s.RuntimeConfig().QuotaBackendBytes }
+
+// WarningUnaryRequestDuration returns the current duration from which the
+// unary requests are logged as slow.
+func (s *EtcdServer) WarningUnaryRequestDuration() time.Duration {
+	return s.RuntimeConfig().WarningUnaryRequestDuration
+}
+
+func (s *EtcdServer) warningApplyDuration() time.Duration {
+	return s.RuntimeConfig().WarningApplyDuration
+}
@@ -25,6 +25,7 @@ import (
 	"path"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -46,6 +47,7 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/client/pkg/v3/verify"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
 	"go.etcd.io/etcd/pkg/v3/idutil"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/pkg/v3/schedule"
@@ -61,8 +63,11 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3revisionpin"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
+	"go.etcd.io/etcd/server/v3/features"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/lease/leasehttp"
 	serverstorage "go.etcd.io/etcd/server/v3/storage"
@@ -101,6 +106,33 @@ const (
 	// maxPendingRevokes is the maximum number of outstanding expired lease revocations.
 	maxPendingRevokes = 16
 
+	// keyExpiryCheckInterval is the interval at which the leader deletes the
+	// keys whose TTL elapsed.
+	keyExpiryCheckInterval = 500 * time.Millisecond
+	// maxExpiredKeysPerCheck is the maximum number of expired keys deleted per check.
+	maxExpiredKeysPerCheck = 1000
+	// maxPendingKeyExpiries is the maximum number of outstanding expired key deletions.
+	maxPendingKeyExpiries = 16
+
+	// revisionPinExpiryCheckInterval is the interval at which the leader
+	// releases the revision pins whose TTL elapsed.
+	revisionPinExpiryCheckInterval = time.Second
+	// maxExpiredRevisionPinsPerCheck is the maximum number of expired revision
+	// pins released per check.
+	maxExpiredRevisionPinsPerCheck = 100
+
+	// autoDefragCheckInterval is the interval at which the fragmentation of
+	// the backend is checked against the automatic defragmentation threshold.
+	autoDefragCheckInterval = time.Minute
+	// watchBacklogCheckInterval is the interval at which the events held in
+	// memory for the slow watchers are checked against the watch backlog
+	// alarm threshold.
+	watchBacklogCheckInterval = time.Second
+	// leaseKeysCheckInterval is the interval at which the numbers of keys
+	// attached to the leases are checked against the lease keys alarm
+	// threshold.
+	leaseKeysCheckInterval = 5 * time.Second
+
 	recommendedMaxRequestBytes = 10 * 1024 * 1024
 
 	readyPercent = 0.9
@@ -172,7 +204,12 @@ type Server interface {
 	// return ErrIDNotFound if the member ID does not exist.
 	// return ErrLearnerNotReady if the member are not ready.
 	// return ErrMemberNotLearner if the member is not a learner.
+	// return ErrMemberObserver if the member is an observer.
 	PromoteMember(ctx context.Context, id uint64) ([]*membership.Member, error)
+	// ReplaceMember attempts to replace a stopped member by a learner member in a
+	// single configuration change. It will return ErrIDNotFound if the member ID
+	// does not exist, or return ErrMemberActive if the member is running.
+	ReplaceMember(ctx context.Context, id uint64, memb membership.Member) ([]*membership.Member, error)
 
 	// ClusterVersion is the cluster-wide minimum major.minor version.
 	// Cluster version is set to the min version that an etcd member is
@@ -192,6 +229,12 @@ type Server interface {
 	// StorageVersion is the storage schema version. It's supported starting
 	// from 3.6.
 	StorageVersion() *semver.Version
+	// FeatureGates tells whether each feature gate known to the server is
+	// enabled.
+	FeatureGates() map[string]bool
+	// VersionRetention lists the version retentions of the server, as
+	// "<prefix>=<versions>" sorted.
+	VersionRetention() []string
 	Cluster() api.Cluster
 	Alarms() []*pb.AlarmMember
 
@@ -213,12 +256,23 @@ type EtcdServer struct {
 	committedIndex    uint64 // must use atomic operations to access; keep 64-bit aligned.
 	term              uint64 // must use atomic operations to access; keep 64-bit aligned.
 	lead              uint64 // must use atomic operations to access; keep 64-bit aligned.
+	// inflightRequests is the number of client requests in flight, see BeginRequest.
+	inflightRequests int64 // must use atomic operations to access; keep 64-bit aligned.
 
 	consistIndex cindex.ConsistentIndexer // consistIndex is used to get/set/save consistentIndex
 	r            raftNode                 // uses 64-bit atomics; keep 64-bit aligned.
 
 	readych chan struct{}
-	Cfg     config.ServerConfig
+	// lifecycle is the finer grained state of the server than readych and done
+	lifecycle *lifecycle
+	// replayIndex is the commit index the committed entries are replayed up
+	// to on start, 0 once replayed
+	replayIndex uint64
+	// drainc is closed once the server starts draining
+	drainc    chan struct{}
+	drainOnce sync.Once
+
+	Cfg config.ServerConfig
 
 	lgMu *sync.RWMutex
 	lg   *zap.Logger
@@ -264,14 +318,26 @@ type EtcdServer struct {
 	beHooks    *serverstorage.BackendHooks
 	authStore  auth.AuthStore
 	alarmStore *v3alarm.AlarmStore
+	// prefixQuotas holds the storage quotas of key prefixes.
+	prefixQuotas *v3prefixquota.PrefixQuotaStore
+	// revisionPins holds the revisions pinned against compaction.
+	revisionPins *v3revisionpin.RevisionPinStore
 
 	stats  *stats.ServerStats
 	lstats *stats.LeaderStats
 
 	SyncTicker *time.Ticker
+	// compactorMu protects compactor, replaced on reconfiguration.
+	compactorMu sync.Mutex
 	// compactor is used to auto-compact the KV.
 	compactor v3compactor.Compactor
 
+	// runtimeCfg is the RuntimeConfig replacing the one of Cfg once the
+	// server is reconfigured.
+	runtimeCfg        atomic.Value
+	runtimeCfgChanged *notify.Notifier
+	reconfigureMu     sync.Mutex
+
 	// peerRt used to send requests (version, lease) to peers.
 	peerRt   http.RoundTripper
 	reqIDGen *idutil.Generator
@@ -298,11 +364,20 @@ type EtcdServer struct {
 	// Should only be set within apply code path. Used to force snapshot after cluster version downgrade.
 	forceSnapshot     bool
 	corruptionChecker CorruptionChecker
+
+	// membersVersionsMu guards the versions and feature gates last reported
+	// by the members, refreshed at most once per monitorVersionInterval.
+	membersVersionsMu      sync.Mutex
+	membersVersions        map[string]*version.Versions
+	membersVersionsChecked time.Time
 }
 
 // NewServer creates a new EtcdServer from the supplied configuration. The
 // configuration is considered static for the lifetime of the EtcdServer.
 func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
+	if cfg.ServerFeatureGate == nil {
+		cfg.ServerFeatureGate = featureGateFromConfig(cfg)
+	}
 	b, err := bootstrap(cfg)
 	if err != nil {
 		return nil, err
@@ -320,6 +395,8 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	heartbeat := time.Duration(cfg.TickMs) * time.Millisecond
 	srv = &EtcdServer{
 		readych:               make(chan struct{}),
+		lifecycle:             newLifecycle(),
+		drainc:                make(chan struct{}),
 		Cfg:                   cfg,
 		lgMu:                  new(sync.RWMutex),
 		lg:                    cfg.Logger,
@@ -339,6 +416,7 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		consistIndex:          b.storage.backend.ci,
 		firstCommitInTerm:     notify.NewNotifier(),
 		clusterVersionChanged: notify.NewNotifier(),
+		runtimeCfgChanged:     notify.NewNotifier(),
 	}
 	serverID.With(prometheus.Labels{"server_id": b.cluster.nodeID.String()}).Set(1)
 	srv.cluster.SetVersionChangedNotifier(srv.clusterVersionChanged)
@@ -354,6 +432,8 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		MinLeaseTTL:                int64(math.Ceil(minTTL.Seconds())),
 		CheckpointInterval:         cfg.LeaseCheckpointInterval,
 		CheckpointPersist:          cfg.LeaseCheckpointPersist,
+		CheckpointOnRenew:          cfg.LeaseCheckpointOnRenew,
+		MaxAttachedKeys:            cfg.MaxLeaseAttachedKeys,
 		ExpiredLeasesRetryInterval: srv.Cfg.ReqTimeout(),
 	})
 
@@ -368,14 +448,46 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		return nil, err
 	}
 
+	// members of older versions cannot read the compressed nor chunked
+	// records of the snapshots sent to them.
+	recordEncodingAllowed := func() bool {
+		cv := srv.cluster.Version()
+		return cv != nil && !version.LessThan(*cv, version.V3_6)
+	}
+	valueCompression := cfg.ValueCompression
+	if !cfg.ServerFeatureGate.Enabled(features.ValueCompression) {
+		valueCompression = mvcc.ValueCompressionNone
+	}
+	compactionBatchTargetDuration := cfg.CompactionBatchTargetDuration
+	if !cfg.ServerFeatureGate.Enabled(features.CompactionPacing) {
+		compactionBatchTargetDuration = 0
+	}
 	mvccStoreConfig := mvcc.StoreConfig{
-		CompactionBatchLimit:    cfg.CompactionBatchLimit,
-		CompactionSleepInterval: cfg.CompactionSleepInterval,
+		CompactionBatchLimit:          cfg.CompactionBatchLimit,
+		CompactionSleepInterval:       cfg.CompactionSleepInterval,
+		CompactionBatchTargetDuration: compactionBatchTargetDuration,
+		CompactionMinBatchLimit:       cfg.CompactionMinBatchLimit,
+		VersionRetention:              cfg.VersionRetention,
+		SecondaryIndexes:              cfg.SecondaryIndexes,
+		HotKeyTracking:                cfg.HotKeyTracking,
+		PrefixStats:                   cfg.PrefixStats,
+
+		ValueCompression:          valueCompression,
+		ValueCompressionThreshold: cfg.ValueCompressionThreshold,
+		ValueCompressionAllowed:   recordEncodingAllowed,
+	}
+	if cfg.MaxChunkedValueBytes > 0 && cfg.ServerFeatureGate.Enabled(features.ValueChunking) {
+		mvccStoreConfig.ValueChunkSize = int(cfg.MaxRequestBytes)
+		mvccStoreConfig.ValueChunkingAllowed = recordEncodingAllowed
 	}
 	srv.kv = mvcc.New(srv.Logger(), srv.be, srv.lessor, mvccStoreConfig)
 	srv.corruptionChecker = newCorruptionChecker(cfg.Logger, srv, srv.kv.HashStorage())
 
-	srv.authStore = auth.NewAuthStore(srv.Logger(), schema.NewAuthBackend(srv.Logger(), srv.be), tp, int(cfg.BcryptCost))
+	as := auth.NewAuthStore(srv.Logger(), schema.NewAuthBackend(srv.Logger(), srv.be), tp, int(cfg.BcryptCost))
+	if len(cfg.ClientCertSANRoleRules) > 0 {
+		as.SetSANRoleRules(cfg.ClientCertSANRoleRules)
+	}
+	srv.authStore = as
 
 	newSrv := srv // since srv == nil in defer if srv is returned as nil
 	defer func() {
@@ -385,8 +497,11 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 			newSrv.kv.Close()
 		}
 	}()
+	if err = as.CheckReservedRoles(); err != nil {
+		return nil, err
+	}
 	if num := cfg.AutoCompactionRetention; num != 0 {
-		srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, srv.kv, srv)
+		srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, cfg.AutoCompactionMaxRevisions, cfg.CompactionRevisionAlignment, srv.kv, srv)
 		if err != nil {
 			return nil, err
 		}
@@ -396,6 +511,12 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	if err = srv.restoreAlarms(); err != nil {
 		return nil, err
 	}
+	if err = srv.restorePrefixQuotas(); err != nil {
+		return nil, err
+	}
+	if err = srv.restoreRevisionPins(); err != nil {
+		return nil, err
+	}
 	srv.uberApply = srv.NewUberApplier()
 
 	if srv.Cfg.EnableLeaseCheckpoint {
@@ -535,6 +656,12 @@ func (s *EtcdServer) Start() {
 	s.GoAttach(s.monitorKVHash)
 	s.GoAttach(s.monitorCompactHash)
 	s.GoAttach(s.monitorDowngrade)
+	s.GoAttach(s.expireKeys)
+	s.GoAttach(s.expireRevisionPins)
+	s.GoAttach(s.monitorBackendFragmentation)
+	s.GoAttach(s.monitorWatchBacklog)
+	s.GoAttach(s.monitorLeaseKeys)
+	s.GoAttach(s.monitorLearners)
 }
 
 // start prepares and starts server in a new goroutine. It is no longer safe to
@@ -773,9 +900,11 @@ func (s *EtcdServer) run() {
 				if s.lessor != nil {
 					s.lessor.Demote()
 				}
+				s.compactorMu.Lock()
 				if s.compactor != nil {
 					s.compactor.Pause()
 				}
+				s.compactorMu.Unlock()
 				setSyncC(nil)
 			} else {
 				if newLeader {
@@ -785,9 +914,11 @@ func (s *EtcdServer) run() {
 					s.leadTimeMu.Unlock()
 				}
 				setSyncC(s.SyncTicker.C)
+				s.compactorMu.Lock()
 				if s.compactor != nil {
 					s.compactor.Resume()
 				}
+				s.compactorMu.Unlock()
 			}
 			if newLeader {
 				s.leaderChanged.Notify()
@@ -813,7 +944,14 @@ func (s *EtcdServer) run() {
 		appliedt:  sn.Metadata.Term,
 		appliedi:  sn.Metadata.Index,
 	}
+	if hs, _, herr := s.r.raftStorage.InitialState(); herr == nil && hs.Commit > ep.appliedi {
+		s.replayIndex = hs.Commit
+		s.lifecycle.set(lg, LifecycleJoinedRaft, fmt.Sprintf("replaying the committed entries up to index %d", hs.Commit))
+	} else {
+		s.lifecycle.set(lg, LifecycleJoinedRaft, "publishing the member attributes")
+	}
 
+	stopReason := "stopped"
 	defer func() {
 		s.wgMu.Lock() // block concurrent waitgroup adds in GoAttach while stopping
 		close(s.stopping)
@@ -833,6 +971,7 @@ func (s *EtcdServer) run() {
 		s.Cleanup()
 
 		close(s.done)
+		s.lifecycle.set(lg, LifecycleStopped, stopReason)
 	}()
 
 	var expiredLeaseC <-chan []*lease.Lease
@@ -850,6 +989,7 @@ func (s *EtcdServer) run() {
 		case err := <-s.errorc:
 			lg.Warn("server error", zap.Error(err))
 			lg.Warn("data-dir used by this member must be removed")
+			stopReason = err.Error()
 			return
 		case <-getSyncC():
 			if s.v2store.HasTTLKeys() {
@@ -875,8 +1015,14 @@ func (s *EtcdServer) revokeExpiredLeases(leases []*lease.Lease) {
 
 			f := func(lid int64) {
 				s.GoAttach(func() {
-					ctx := s.authStore.WithRoot(s.ctx)
-					_, lerr := s.LeaseRevoke(ctx, &pb.LeaseRevokeRequest{ID: lid})
+					// expired leases are revoked with LeaseExpire rather than
+					// LeaseRevoke, so that their watchers are told they expired.
+					// Members older than 3.6 cannot apply LeaseExpire.
+					req := pb.InternalRaftRequest{LeaseRevoke: &pb.LeaseRevokeRequest{ID: lid}}
+					if s.isClusterVersionV3_6() {
+						req = pb.InternalRaftRequest{LeaseExpire: &pb.LeaseRevokeRequest{ID: lid}}
+					}
+					_, lerr := s.raftRequestOnce(s.ctx, req)
 					if lerr == nil {
 						leaseExpired.Inc()
 					} else {
@@ -896,6 +1042,94 @@ func (s *EtcdServer) revokeExpiredLeases(leases []*lease.Lease) {
 	})
 }
 
+// expireKeys deletes, while the local member is the leader, the keys whose
+// TTL elapsed. A key is only deleted if it was not put again since it was
+// reported as expired.
+func (s *EtcdServer) expireKeys() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-time.After(keyExpiryCheckInterval):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			continue
+		}
+
+		var wg sync.WaitGroup
+		c := make(chan struct{}, maxPendingKeyExpiries)
+		for _, k := range s.KV().ExpiredKeys(maxExpiredKeysPerCheck) {
+			select {
+			case c <- struct{}{}:
+			case <-s.stopping:
+				wg.Wait()
+				return
+			}
+			wg.Add(1)
+			go func(k mvcc.ExpiredKey) {
+				defer func() {
+					<-c
+					wg.Done()
+				}()
+				ctx, cancel := context.WithTimeout(s.authStore.WithRoot(s.ctx), s.Cfg.ReqTimeout())
+				defer cancel()
+				resp, err := s.Txn(ctx, &pb.TxnRequest{
+					Compare: []*pb.Compare{{
+						Key:         k.Key,
+						Target:      pb.Compare_MOD,
+						Result:      pb.Compare_EQUAL,
+						TargetUnion: &pb.Compare_ModRevision{ModRevision: k.ModRevision},
+					}},
+					Success: []*pb.RequestOp{{
+						Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: k.Key}},
+					}},
+				})
+				if err != nil {
+					lg.Warn("failed to delete expired key", zap.ByteString("key", k.Key), zap.Error(err))
+					return
+				}
+				if resp.Succeeded {
+					keyExpired.Inc()
+				}
+			}(k)
+		}
+		wg.Wait()
+	}
+}
+
+// expireRevisionPins releases, while the local member is the leader, the
+// revision pins whose TTL elapsed. A pin is only released if it was not
+// renewed since it was reported as expired.
+func (s *EtcdServer) expireRevisionPins() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-time.After(revisionPinExpiryCheckInterval):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			continue
+		}
+
+		for _, p := range s.revisionPins.Expired(maxExpiredRevisionPinsPerCheck) {
+			ctx, cancel := context.WithTimeout(s.authStore.WithRoot(s.ctx), s.Cfg.ReqTimeout())
+			_, err := s.RevisionPin(ctx, &pb.RevisionPinRequest{
+				Action:  pb.RevisionPinRequest_UNPIN,
+				Owner:   p.Owner,
+				Version: p.Version,
+			})
+			cancel()
+			if err != nil {
+				lg.Warn("failed to release expired revision pin", zap.String("owner", p.Owner), zap.Int64("revision", p.Revision), zap.Error(err))
+				continue
+			}
+			lg.Info("released expired revision pin", zap.String("owner", p.Owner), zap.Int64("revision", p.Revision))
+		}
+	}
+}
+
 // Cleanup removes allocated objects by EtcdServer.NewServer in
 // situation that EtcdServer::Start was not called (that takes care of cleanup).
 func (s *EtcdServer) Cleanup() {
@@ -913,14 +1147,20 @@ func (s *EtcdServer) Cleanup() {
 	if s.be != nil {
 		s.be.Close()
 	}
+	s.compactorMu.Lock()
 	if s.compactor != nil {
 		s.compactor.Stop()
 	}
+	s.compactorMu.Unlock()
 }
 
 func (s *EtcdServer) applyAll(ep *etcdProgress, apply *toApply) {
 	s.applySnapshot(ep, apply)
 	s.applyEntries(ep, apply)
+	if s.replayIndex != 0 && ep.appliedi >= s.replayIndex {
+		s.replayIndex = 0
+		s.lifecycle.set(s.Logger(), LifecycleJoinedRaft, "replayed the committed entries, publishing the member attributes")
+	}
 
 	proposalsApplied.Set(float64(ep.appliedi))
 	s.applyWait.Trigger(ep.appliedi)
@@ -1037,6 +1277,22 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, toApply *toApply) {
 
 	lg.Info("restored alarm store")
 
+	lg.Info("restoring prefix quota store")
+
+	if err := s.restorePrefixQuotas(); err != nil {
+		lg.Panic("failed to restore prefix quota store", zap.Error(err))
+	}
+
+	lg.Info("restored prefix quota store")
+
+	lg.Info("restoring revision pin store")
+
+	if err := s.restoreRevisionPins(); err != nil {
+		lg.Panic("failed to restore revision pin store", zap.Error(err))
+	}
+
+	lg.Info("restored revision pin store")
+
 	if s.authStore != nil {
 		lg.Info("restoring auth store")
 
@@ -1091,8 +1347,8 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, toApply *toApply) {
 }
 
 func (s *EtcdServer) NewUberApplier() apply.UberApplier {
-	return apply.NewUberApplier(s.lg, s.be, s.KV(), s.alarmStore, s.authStore, s.lessor, s.cluster, s, s, s.consistIndex,
-		s.Cfg.WarningApplyDuration, s.Cfg.ExperimentalTxnModeWriteWithSharedBuffer, s.Cfg.QuotaBackendBytes)
+	return apply.NewUberApplier(s.lg, s.be, s.KV(), s.alarmStore, s.prefixQuotas, s.revisionPins, s.authStore, s.lessor, s.cluster, s, s, s.consistIndex,
+		s.warningApplyDuration, s.Cfg.ExperimentalTxnModeWriteWithSharedBuffer, s.QuotaBackendBytes, s.Cfg.MaxQuotaBackendBytes, s.Cfg.ApplyHooks)
 }
 
 func verifySnapshotIndex(snapshot raftpb.Snapshot, cindex uint64) {
@@ -1252,6 +1508,7 @@ func (s *EtcdServer) HardStop() {
 // Do and Process cannot be called after Stop has been invoked.
 func (s *EtcdServer) Stop() {
 	lg := s.Logger()
+	s.lifecycle.set(lg, LifecycleDraining, "transferring the leadership")
 	if err := s.TransferLeadership(); err != nil {
 		lg.Warn("leadership transfer failed", zap.String("local-member-id", s.MemberId().String()), zap.Error(err))
 	}
@@ -1307,6 +1564,12 @@ func (s *EtcdServer) AddMember(ctx context.Context, memb membership.Member) ([]*
 		return nil, err
 	}
 
+	// members older than 3.6 ignore IsObserver and would treat the observer
+	// as a learner they can promote.
+	if memb.IsObserver && !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+
 	// TODO: move Member to protobuf type
 	b, err := json.Marshal(memb)
 	if err != nil {
@@ -1407,7 +1670,7 @@ func (s *EtcdServer) PromoteMember(ctx context.Context, id uint64) ([]*membershi
 				return resp, nil
 			}
 			// If member promotion failed, return early. Otherwise keep retry.
-			if err == errors.ErrLearnerNotReady || err == membership.ErrIDNotFound || err == membership.ErrMemberNotLearner {
+			if err == errors.ErrLearnerNotReady || err == membership.ErrIDNotFound || err == membership.ErrMemberNotLearner || err == membership.ErrMemberObserver {
 				return nil, err
 			}
 		}
@@ -1429,7 +1692,12 @@ func (s *EtcdServer) promoteMember(ctx context.Context, id uint64) ([]*membershi
 	if err := s.checkMembershipOperationPermission(ctx); err != nil {
 		return nil, err
 	}
+	return s.proposePromoteMember(ctx, id)
+}
 
+// proposePromoteMember sends the promote request of a learner node to raft once
+// it is found ready to be promoted. The caller checks the permission.
+func (s *EtcdServer) proposePromoteMember(ctx context.Context, id uint64) ([]*membership.Member, error) {
 	// check if we can promote this learner.
 	if err := s.mayPromoteMember(types.ID(id)); err != nil {
 		return nil, err
@@ -1459,6 +1727,11 @@ func (s *EtcdServer) promoteMember(ctx context.Context, id uint64) ([]*membershi
 
 func (s *EtcdServer) mayPromoteMember(id types.ID) error {
 	lg := s.Logger()
+	// observers are never promoted, whether or not they caught up.
+	if m := s.cluster.Member(id); m != nil && m.IsObserver {
+		return membership.ErrMemberObserver
+	}
+
 	err := s.isLearnerReady(uint64(id))
 	if err != nil {
 		return err
@@ -1566,7 +1839,97 @@ func (s *EtcdServer) mayRemoveMember(id types.ID) error {
 	return nil
 }
 
+// ReplaceMember removes the member of the given id and adds the given member as
+// a learner in a single configuration change. Unlike removing the member and
+// adding a voting one, the cluster never counts the replacement toward its
+// quorum before it starts. The replacement of an observer is an observer.
+func (s *EtcdServer) ReplaceMember(ctx context.Context, id uint64, memb membership.Member) ([]*membership.Member, error) {
+	if err := s.checkMembershipOperationPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	// members older than 3.6 cannot apply the EntryConfChangeV2 entries.
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+
+	// by default StrictReconfigCheck is enabled; reject replacing a running member
+	// or if the remaining members lose the quorum.
+	if err := s.mayReplaceMember(types.ID(id)); err != nil {
+		return nil, err
+	}
+
+	memb.IsLearner = true
+	if m := s.cluster.Member(types.ID(id)); m != nil {
+		memb.IsObserver = m.IsObserver
+	}
+	replaceChangeContext := membership.ReplaceChangeContext{
+		ID:         s.reqIDGen.Next(),
+		ReplacedID: types.ID(id),
+		Member:     memb,
+	}
+	b, err := json.Marshal(replaceChangeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// the two changes enter a joint configuration the leader leaves on its own.
+	cc := raftpb.ConfChangeV2{
+		Transition: raftpb.ConfChangeTransitionAuto,
+		Changes: []raftpb.ConfChangeSingle{
+			{Type: raftpb.ConfChangeRemoveNode, NodeID: id},
+			{Type: raftpb.ConfChangeAddLearnerNode, NodeID: uint64(memb.ID)},
+		},
+		Context: b,
+	}
+	return s.configureV2(ctx, replaceChangeContext.ID, cc)
+}
+
+func (s *EtcdServer) mayReplaceMember(id types.ID) error {
+	if id == s.MemberId() {
+		return errors.ErrMemberActive
+	}
+	if !s.Cfg.StrictReconfigCheck {
+		return nil
+	}
+
+	lg := s.Logger()
+	// only a downed member is replaced, which is not part of the active quorum
+	if t := s.r.transport.ActiveSince(id); !t.IsZero() {
+		lg.Warn(
+			"rejecting member replace request; member is active",
+			zap.String("local-member-id", s.MemberId().String()),
+			zap.String("requested-member-replace", id.String()),
+			zap.Time("active-since", t),
+			zap.Error(errors.ErrMemberActive),
+		)
+		return errors.ErrMemberActive
+	}
+
+	// protect quorum if other members are down, in the joint configuration
+	// with and without the replaced member
+	m := s.cluster.VotingMembers()
+	active := numConnectedSince(s.r.transport, time.Now().Add(-HealthInterval), s.MemberId(), m)
+	if active < 1+(len(m)/2) {
+		lg.Warn(
+			"rejecting member replace request; not enough healthy members",
+			zap.String("local-member-id", s.MemberId().String()),
+			zap.String("requested-member-replace", id.String()),
+			zap.Int("active-peers", active),
+			zap.Error(errors.ErrUnhealthy),
+		)
+		return errors.ErrUnhealthy
+	}
+
+	return nil
+}
+
 func (s *EtcdServer) UpdateMember(ctx context.Context, memb membership.Member) ([]*membership.Member, error) {
+	// an update does not change the raft role of the member
+	if m := s.cluster.Member(memb.ID); m != nil {
+		memb.IsLearner = m.IsLearner
+		memb.IsObserver = m.IsObserver
+	}
 	b, merr := json.Marshal(memb)
 	if merr != nil {
 		return nil, merr
@@ -1681,6 +2044,40 @@ func (s *EtcdServer) configure(ctx context.Context, cc raftpb.ConfChange) ([]*me
 	}
 }
 
+// configureV2 is configure for a confChangeV2, which has no ID field; it waits
+// on the given request ID the change carries in its context instead.
+func (s *EtcdServer) configureV2(ctx context.Context, id uint64, cc raftpb.ConfChangeV2) ([]*membership.Member, error) {
+	lg := s.Logger()
+	ch := s.w.Register(id)
+
+	start := time.Now()
+	if err := s.r.ProposeConfChange(ctx, cc); err != nil {
+		s.w.Trigger(id, nil)
+		return nil, err
+	}
+
+	select {
+	case x := <-ch:
+		if x == nil {
+			lg.Panic("failed to configure")
+		}
+		resp := x.(*confChangeResponse)
+		lg.Info(
+			"applied a configuration change through raft",
+			zap.String("local-member-id", s.MemberId().String()),
+			zap.String("raft-conf-change", raftpb.ConfChangesToString(cc.Changes)),
+		)
+		return resp.membs, resp.err
+
+	case <-ctx.Done():
+		s.w.Trigger(id, nil) // GC wait
+		return nil, s.parseProposeCtxErr(ctx.Err(), start)
+
+	case <-s.stopping:
+		return nil, errors.ErrStopped
+	}
+}
+
 // sync proposes a SYNC request and is non-blocking.
 // This makes no guarantee that the request will be proposed or performed.
 // The request will be canceled after the given timeout.
@@ -1734,6 +2131,7 @@ func (s *EtcdServer) publishV3(timeout time.Duration) {
 		switch err {
 		case nil:
 			close(s.readych)
+			s.lifecycle.set(lg, LifecyclePublished, "published the member attributes")
 			lg.Info(
 				"published local member to cluster through raft",
 				zap.String("local-member-id", s.MemberId().String()),
@@ -1744,6 +2142,11 @@ func (s *EtcdServer) publishV3(timeout time.Duration) {
 			return
 
 		default:
+			reason := fmt.Sprintf("failed to publish the member attributes: %v", err)
+			if s.Leader() == types.ID(raft.None) {
+				reason = "no leader, cannot reach a quorum of the peers; " + reason
+			}
+			s.lifecycle.set(lg, LifecycleJoinedRaft, reason)
 			lg.Warn(
 				"failed to publish local member to cluster through raft",
 				zap.String("local-member-id", s.MemberId().String()),
@@ -1834,10 +2237,27 @@ func (s *EtcdServer) apply(
 			shouldStop = shouldStop || removedSelf
 			s.w.Trigger(cc.ID, &confChangeResponse{s.cluster.Members(), err})
 
+		case raftpb.EntryConfChangeV2:
+			shouldApplyV3 := membership.ApplyV2storeOnly
+			if e.Index > s.consistIndex.ConsistentIndex() {
+				s.consistIndex.SetConsistentApplyingIndex(e.Index, e.Term)
+				shouldApplyV3 = membership.ApplyBoth
+			}
+
+			var cc raftpb.ConfChangeV2
+			pbutil.MustUnmarshal(&cc, e.Data)
+			id, removedSelf, err := s.applyConfChangeV2(cc, confState, shouldApplyV3)
+			s.setAppliedIndex(e.Index)
+			s.setTerm(e.Term)
+			shouldStop = shouldStop || removedSelf
+			if id != 0 {
+				s.w.Trigger(id, &confChangeResponse{s.cluster.Members(), err})
+			}
+
 		default:
 			lg := s.Logger()
 			lg.Panic(
-				"unknown entry type; must be either EntryNormal or EntryConfChange",
+				"unknown entry type; must be either EntryNormal, EntryConfChange or EntryConfChangeV2",
 				zap.String("type", e.Type.String()),
 			)
 		}
@@ -1932,8 +2352,8 @@ func (s *EtcdServer) applyEntryNormal(e *raftpb.Entry) {
 	lg := s.Logger()
 	lg.Warn(
 		"message exceeded backend quota; raising alarm",
-		zap.Int64("quota-size-bytes", s.Cfg.QuotaBackendBytes),
-		zap.String("quota-size", humanize.Bytes(uint64(s.Cfg.QuotaBackendBytes))),
+		zap.Int64("quota-size-bytes", s.QuotaBackendBytes()),
+		zap.String("quota-size", humanize.Bytes(uint64(s.QuotaBackendBytes()))),
 		zap.Error(ar.Err),
 	)
 
@@ -2049,6 +2469,70 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 	return false, nil
 }
 
+// applyConfChangeV2 applies the confChangeV2 replacing a member with a learner
+// member, or leaving the joint configuration such a change entered. It returns
+// the ID of the request waiting for the change, which is 0 for leaving the joint
+// configuration.
+func (s *EtcdServer) applyConfChangeV2(cc raftpb.ConfChangeV2, confState *raftpb.ConfState, shouldApplyV3 membership.ShouldApplyV3) (uint64, bool, error) {
+	// the leader proposes leaving the joint configuration on its own, which
+	// does not change the membership.
+	if cc.LeaveJoint() {
+		*confState = *s.r.ApplyConfChange(cc)
+		s.beHooks.SetConfState(confState)
+		if s.consistIndex != nil && membership.ApplyBoth == shouldApplyV3 {
+			applyingIndex, applyingTerm := s.consistIndex.ConsistentApplyingIndex()
+			s.consistIndex.SetConsistentIndex(applyingIndex, applyingTerm)
+		}
+		return 0, false, nil
+	}
+
+	lg := s.Logger()
+	rcc := new(membership.ReplaceChangeContext)
+	if err := json.Unmarshal(cc.Context, rcc); err != nil {
+		lg.Panic("failed to unmarshal replace change context", zap.Error(err))
+	}
+	if len(cc.Changes) != 2 ||
+		cc.Changes[0].Type != raftpb.ConfChangeRemoveNode || cc.Changes[0].NodeID != uint64(rcc.ReplacedID) ||
+		cc.Changes[1].Type != raftpb.ConfChangeAddLearnerNode || cc.Changes[1].NodeID != uint64(rcc.Member.ID) {
+		lg.Panic(
+			"got different member IDs",
+			zap.String("raft-conf-change", raftpb.ConfChangesToString(cc.Changes)),
+			zap.String("replaced-member-id-from-message", rcc.ReplacedID.String()),
+			zap.String("member-id-from-message", rcc.Member.ID.String()),
+		)
+	}
+
+	if err := s.cluster.ValidateReplaceMember(rcc.ReplacedID, &rcc.Member); err != nil {
+		// a simple change of no node, unlike the rejected change whose two
+		// changes would enter a joint configuration.
+		s.r.ApplyConfChange(raftpb.ConfChange{NodeID: raft.None})
+
+		// The txPostLock callback will not get called in this case,
+		// so we should set the consistent index directly.
+		if s.consistIndex != nil && membership.ApplyBoth == shouldApplyV3 {
+			applyingIndex, applyingTerm := s.consistIndex.ConsistentApplyingIndex()
+			s.consistIndex.SetConsistentIndex(applyingIndex, applyingTerm)
+		}
+		return rcc.ID, false, err
+	}
+
+	*confState = *s.r.ApplyConfChange(cc)
+	s.beHooks.SetConfState(confState)
+	s.cluster.RemoveMember(rcc.ReplacedID, shouldApplyV3)
+	s.cluster.AddMember(&rcc.Member, shouldApplyV3)
+	if rcc.Member.ID == s.MemberId() {
+		isLearner.Set(1)
+	}
+	if rcc.ReplacedID == s.MemberId() {
+		return rcc.ID, true, nil
+	}
+	s.r.transport.RemovePeer(rcc.ReplacedID)
+	if rcc.Member.ID != s.MemberId() {
+		s.r.transport.AddPeer(rcc.Member.ID, rcc.Member.PeerURLs)
+	}
+	return rcc.ID, false, nil
+}
+
 // TODO: non-blocking snapshot
 func (s *EtcdServer) snapshot(snapi uint64, confState raftpb.ConfState) {
 	clone := s.v2store.Clone()
@@ -2153,6 +2637,61 @@ func (s *EtcdServer) ClusterVersion() *semver.Version {
 	return s.cluster.Version()
 }
 
+// featureGateFromConfig returns the feature gate of the features enabled by the
+// fields of cfg, for the servers configured without feature gate.
+func featureGateFromConfig(cfg config.ServerConfig) featuregate.FeatureGate {
+	fg := features.NewDefaultServerFeatureGate()
+	err := fg.SetFromMap(map[string]bool{
+		string(features.CompactHashCheck):             cfg.CompactHashCheckEnabled,
+		string(features.HotKeyTracking):               cfg.HotKeyTracking,
+		string(features.IncrementalDefrag):            cfg.ExperimentalIncrementalDefrag,
+		string(features.InitialCorruptCheck):          cfg.InitialCorruptCheck,
+		string(features.LeaseCheckpoint):              cfg.EnableLeaseCheckpoint,
+		string(features.LeaseCheckpointOnRenew):       cfg.LeaseCheckpointOnRenew,
+		string(features.LeaseCheckpointPersist):       cfg.LeaseCheckpointPersist,
+		string(features.PrefixStats):                  cfg.PrefixStats,
+		string(features.TxnModeWriteWithSharedBuffer): cfg.ExperimentalTxnModeWriteWithSharedBuffer,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return fg
+}
+
+// FeatureEnabled returns true if the feature is enabled on the server.
+func (s *EtcdServer) FeatureEnabled(f featuregate.Feature) bool {
+	return s.Cfg.ServerFeatureGate.Enabled(f)
+}
+
+func (s *EtcdServer) FeatureGates() map[string]bool {
+	return s.Cfg.ServerFeatureGate.Values()
+}
+
+func (s *EtcdServer) VersionRetention() []string {
+	return versionRetentionStrings(s.Cfg.VersionRetention)
+}
+
+// featureEnabledOnMembers returns true if the feature is enabled on all the
+// members, as they last reported their feature gates.
+func (s *EtcdServer) featureEnabledOnMembers(f featuregate.Feature) bool {
+	s.membersVersionsMu.Lock()
+	vers, checked := s.membersVersions, s.membersVersionsChecked
+	s.membersVersionsMu.Unlock()
+	if vers == nil || time.Since(checked) > monitorVersionInterval {
+		vers = getMembersVersions(s.lg, s.cluster, s.MemberId(), s.FeatureGates(), s.VersionRetention(), s.peerRt, s.Cfg.ReqTimeout())
+		s.membersVersionsMu.Lock()
+		s.membersVersions, s.membersVersionsChecked = vers, time.Now()
+		s.membersVersionsMu.Unlock()
+	}
+	for _, m := range s.cluster.Members() {
+		ver := vers[m.ID.String()]
+		if ver == nil || !ver.FeatureGates[string(f)] {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *EtcdServer) StorageVersion() *semver.Version {
 	// `applySnapshot` sets a new backend instance, so we need to acquire the bemu lock.
 	s.bemu.RLock()
@@ -2169,6 +2708,7 @@ func (s *EtcdServer) StorageVersion() *semver.Version {
 // monitorClusterVersions every monitorVersionInterval checks if it's the leader and updates cluster version if needed.
 func (s *EtcdServer) monitorClusterVersions() {
 	monitor := serverversion.NewMonitor(s.Logger(), NewServerVersionAdapter(s))
+	var featureGateMismatches []string
 	for {
 		select {
 		case <-s.firstCommitInTerm.Receive():
@@ -2184,7 +2724,23 @@ func (s *EtcdServer) monitorClusterVersions() {
 		if err != nil {
 			s.lg.Error("Failed to monitor cluster version", zap.Error(err))
 		}
+		s.checkFeatureGates(monitor, &featureGateMismatches)
+	}
+}
+
+// checkFeatureGates warns about the feature gates the members disagree on with
+// the local member, once until they change.
+func (s *EtcdServer) checkFeatureGates(monitor *serverversion.Monitor, last *[]string) {
+	mismatches := monitor.FeatureGateMismatches(s.FeatureGates())
+	if strings.Join(mismatches, "\n") == strings.Join(*last, "\n") {
+		return
+	}
+	*last = mismatches
+	if len(mismatches) == 0 {
+		s.lg.Info("feature gates of the members match")
+		return
 	}
+	s.lg.Warn("feature gates of the members do not match", zap.Strings("mismatches", mismatches))
 }
 
 // monitorStorageVersion every monitorVersionInterval updates storage version if needed.
@@ -2202,34 +2758,277 @@ func (s *EtcdServer) monitorStorageVersion() {
 }
 
 func (s *EtcdServer) monitorKVHash() {
-	t := s.Cfg.CorruptCheckTime
-	if t == 0 {
-		return
+	lg := s.Logger()
+	var cron *schedule.Cron
+	if s.Cfg.CorruptCheckSchedule != "" {
+		var err error
+		if cron, err = schedule.ParseCron(s.Cfg.CorruptCheckSchedule); err != nil {
+			lg.Warn("failed to parse corruption check schedule", zap.Error(err))
+			return
+		}
 	}
-	checkTicker := time.NewTicker(t)
-	defer checkTicker.Stop()
+	scope := s.corruptCheckScope()
 
+	// the interval is read on every check, the server being reconfigurable
+	enabled := false
+	for {
+		changed := s.runtimeConfigChanged()
+		t := s.RuntimeConfig().CorruptCheckTime
+		if t == 0 && cron == nil {
+			if enabled {
+				lg.Info("disabled corruption checking", zap.String("local-member-id", s.MemberId().String()))
+				enabled = false
+			}
+			select {
+			case <-s.stopping:
+				return
+			case <-changed:
+			}
+			continue
+		}
+		if !enabled {
+			lg.Info(
+				"enabled corruption checking",
+				zap.String("local-member-id", s.MemberId().String()),
+				zap.Duration("interval", t),
+				zap.String("schedule", s.Cfg.CorruptCheckSchedule),
+				zap.String("scope", scope.String()),
+			)
+			enabled = true
+		}
+
+		wait := t
+		if cron != nil {
+			next := cron.Next(time.Now())
+			if next.IsZero() {
+				lg.Warn("corruption check schedule has no next run", zap.String("schedule", s.Cfg.CorruptCheckSchedule))
+				return
+			}
+			wait = time.Until(next)
+		}
+		select {
+		case <-s.stopping:
+			return
+		case <-changed:
+			continue
+		case <-time.After(wait):
+		}
+		if !s.isLeader() {
+			continue
+		}
+		if err := s.runCorruptionCheck(scope); err != nil {
+			lg.Warn("failed to check hash KV", zap.Error(err))
+		}
+	}
+}
+
+// monitorBackendFragmentation defragments the backend once the ratio of its
+// bytes not in use to its size exceeds the configured threshold, only during
+// the minutes of the configured schedule if any. Each member decides alone,
+// so the incremental defragmentation is advised to keep serving meanwhile.
+func (s *EtcdServer) monitorBackendFragmentation() {
+	threshold := s.Cfg.ExperimentalAutoDefragThreshold
+	if threshold == 0 {
+		return
+	}
 	lg := s.Logger()
+	var cron *schedule.Cron
+	if s.Cfg.ExperimentalAutoDefragSchedule != "" {
+		var err error
+		if cron, err = schedule.ParseCron(s.Cfg.ExperimentalAutoDefragSchedule); err != nil {
+			lg.Warn("failed to parse automatic defragmentation schedule", zap.Error(err))
+			return
+		}
+	}
+
 	lg.Info(
-		"enabled corruption checking",
+		"enabled automatic defragmentation",
 		zap.String("local-member-id", s.MemberId().String()),
-		zap.Duration("interval", t),
+		zap.Float64("threshold", threshold),
+		zap.String("schedule", s.Cfg.ExperimentalAutoDefragSchedule),
 	)
 	for {
 		select {
+		case <-time.After(autoDefragCheckInterval):
+		case <-s.stopping:
+			return
+		}
+		if cron != nil && !cron.Match(time.Now()) {
+			continue
+		}
+		be := s.Backend()
+		size := be.Size()
+		if size == 0 {
+			continue
+		}
+		ratio := float64(size-be.SizeInUse()) / float64(size)
+		if ratio < threshold {
+			continue
+		}
+		lg.Info(
+			"defragmenting fragmented backend",
+			zap.Float64("fragmentation-ratio", ratio),
+			zap.Float64("threshold", threshold),
+		)
+		if err := be.Defrag(); err != nil {
+			lg.Warn("failed to defragment backend", zap.Error(err))
+			continue
+		}
+		autoDefragTotal.Inc()
+	}
+}
+
+// monitorWatchBacklog raises the WATCHBACKLOG alarm of the member once the
+// events held in memory for its slow watchers reach the configured threshold,
+// and clears it once they are down to half of it. The alarm is a warning, the
+// member keeps accepting writes.
+func (s *EtcdServer) monitorWatchBacklog() {
+	threshold := s.Cfg.WatchBacklogAlarmEvents
+	if threshold == 0 {
+		return
+	}
+	lg := s.Logger()
+	for {
+		select {
+		case <-time.After(watchBacklogCheckInterval):
 		case <-s.stopping:
 			return
-		case <-checkTicker.C:
 		}
+		wl := s.KV().WatchLag(1)
+		pending := int64(wl.PendingEvents)
+		active := s.watchBacklogAlarmed()
+		var action pb.AlarmRequest_AlarmAction
+		switch {
+		case !active && pending >= threshold:
+			action = pb.AlarmRequest_ACTIVATE
+			lg.Warn(
+				"watch backlog exceeded threshold; raising alarm",
+				zap.Int64("pending-events", pending),
+				zap.Int64("threshold", threshold),
+				zap.Int("slow-watchers", wl.SlowWatchers),
+			)
+		case active && pending <= threshold/2:
+			action = pb.AlarmRequest_DEACTIVATE
+			lg.Info(
+				"watch backlog drained; clearing alarm",
+				zap.Int64("pending-events", pending),
+				zap.Int64("threshold", threshold),
+			)
+		default:
+			continue
+		}
+		a := &pb.AlarmRequest{
+			MemberID: uint64(s.MemberId()),
+			Action:   action,
+			Alarm:    pb.AlarmType_WATCHBACKLOG,
+		}
+		if _, err := s.raftRequest(s.ctx, pb.InternalRaftRequest{Alarm: a}); err != nil {
+			lg.Warn("failed to update watch backlog alarm", zap.Error(err))
+		}
+	}
+}
+
+// watchBacklogAlarmed returns true if the WATCHBACKLOG alarm of the member
+// is active.
+func (s *EtcdServer) watchBacklogAlarmed() bool {
+	for _, m := range s.alarmStore.Get(pb.AlarmType_WATCHBACKLOG) {
+		if types.ID(m.MemberID) == s.MemberId() {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorLeaseKeys raises the LEASEKEYS alarm once a lease reaches 90% of
+// the maximum number of attached keys, and clears it once all the leases are
+// down to 80% of it. Only the leader raises and clears the alarm, as all the
+// members share the same leases. The alarm is a warning, the cluster keeps
+// accepting writes.
+func (s *EtcdServer) monitorLeaseKeys() {
+	limit := s.Cfg.MaxLeaseAttachedKeys
+	if limit == 0 {
+		return
+	}
+	lg := s.Logger()
+	for {
+		select {
+		case <-time.After(leaseKeysCheckInterval):
+		case <-s.stopping:
+			return
+		}
+		var (
+			maxID   lease.LeaseID
+			maxKeys int
+		)
+		for _, l := range s.lessor.Leases() {
+			if n := l.AttachedKeys(); n > maxKeys {
+				maxID, maxKeys = l.ID, n
+			}
+		}
+		leaseMaxAttachedKeys.Set(float64(maxKeys))
 		if !s.isLeader() {
 			continue
 		}
-		if err := s.corruptionChecker.PeriodicCheck(); err != nil {
-			lg.Warn("failed to check hash KV", zap.Error(err))
+		alarmed := s.alarmStore.Get(pb.AlarmType_LEASEKEYS)
+		var (
+			action  pb.AlarmRequest_AlarmAction
+			members []uint64
+		)
+		switch {
+		case len(alarmed) == 0 && maxKeys >= limit*9/10:
+			action, members = pb.AlarmRequest_ACTIVATE, []uint64{uint64(s.MemberId())}
+			lg.Warn(
+				"lease approaches maximum number of attached keys; raising alarm",
+				zap.String("lease-id", fmt.Sprintf("%016x", maxID)),
+				zap.Int("attached-keys", maxKeys),
+				zap.Int("max-attached-keys", limit),
+			)
+		case len(alarmed) != 0 && maxKeys < limit*8/10:
+			action = pb.AlarmRequest_DEACTIVATE
+			for _, m := range alarmed {
+				members = append(members, m.MemberID)
+			}
+			lg.Info(
+				"leases below maximum number of attached keys; clearing alarm",
+				zap.Int("attached-keys", maxKeys),
+				zap.Int("max-attached-keys", limit),
+			)
+		default:
+			continue
+		}
+		for _, id := range members {
+			a := &pb.AlarmRequest{MemberID: id, Action: action, Alarm: pb.AlarmType_LEASEKEYS}
+			if _, err := s.raftRequest(s.ctx, pb.InternalRaftRequest{Alarm: a}); err != nil {
+				lg.Warn("failed to update lease keys alarm", zap.Error(err))
+			}
 		}
 	}
 }
 
+func (s *EtcdServer) corruptCheckScope() pb.CorruptionCheckRequest_Scope {
+	return pb.CorruptionCheckRequest_Scope(pb.CorruptionCheckRequest_Scope_value[strings.ToUpper(s.Cfg.CorruptCheckScope)])
+}
+
+func (s *EtcdServer) runCorruptionCheck(scope pb.CorruptionCheckRequest_Scope) error {
+	if scope == pb.CorruptionCheckRequest_SAMPLED {
+		return s.corruptionChecker.SampledCheck()
+	}
+	return s.corruptionChecker.PeriodicCheck()
+}
+
+// CorruptionCheck runs a corruption check of the given scope if run is set,
+// which only the leader can, and returns the result of the last check.
+func (s *EtcdServer) CorruptionCheck(run bool, scope pb.CorruptionCheckRequest_Scope) (CorruptionCheckResult, error) {
+	if run {
+		if !s.isLeader() {
+			return CorruptionCheckResult{}, errors.ErrNotLeader
+		}
+		// the error is part of the result
+		_ = s.runCorruptionCheck(scope)
+	}
+	return s.corruptionChecker.LastCheck(), nil
+}
+
 func (s *EtcdServer) monitorCompactHash() {
 	if !s.Cfg.CompactHashCheckEnabled {
 		return
@@ -2396,6 +3195,29 @@ func (s *EtcdServer) restoreAlarms() error {
 	return nil
 }
 
+func (s *EtcdServer) restorePrefixQuotas() error {
+	qs, err := v3prefixquota.NewPrefixQuotaStore(s.lg, schema.NewPrefixQuotaBackend(s.lg, s.be))
+	if err != nil {
+		return err
+	}
+	s.prefixQuotas = qs
+	return nil
+}
+
+func (s *EtcdServer) restoreRevisionPins() error {
+	be := schema.NewRevisionPinBackend(s.lg, s.be)
+	// the pins are read by the expiry loop, so they are recovered in place
+	if s.revisionPins != nil {
+		return s.revisionPins.Recover(be)
+	}
+	ps, err := v3revisionpin.NewRevisionPinStore(s.lg, be)
+	if err != nil {
+		return err
+	}
+	s.revisionPins = ps
+	return nil
+}
+
 // GoAttach creates a goroutine on a given function and tracks it using
 // the etcdserver waitgroup.
 // The passed function should interrupt on s.StoppingNotify().
@@ -2427,6 +3249,11 @@ func (s *EtcdServer) IsLearner() bool {
 	return s.cluster.IsLocalMemberLearner()
 }
 
+// IsObserver returns if the local member is an observer
+func (s *EtcdServer) IsObserver() bool {
+	return s.cluster.IsLocalMemberObserver()
+}
+
 // IsMemberExist returns if the member with the given id exists in cluster.
 func (s *EtcdServer) IsMemberExist(id types.ID) bool {
 	return s.cluster.IsMemberExist(id)
@@ -1381,6 +1381,103 @@ func TestAddMember(t *testing.T) {
 	}
 }
 
+// TestAddObserverBeforeV3_6 tests AddMember rejects the observers until all the
+// members run 3.6, without proposing any configuration change.
+func TestAddObserverBeforeV3_6(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	n := newNodeConfChangeCommitterRecorder()
+	cl := newTestCluster(t, nil)
+	cl.SetStore(v2store.New())
+	s := &EtcdServer{
+		lgMu:     new(sync.RWMutex),
+		lg:       lg,
+		r:        *newRaftNode(raftNodeConfig{lg: lg, Node: n}),
+		cluster:  cl,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	m := *membership.NewMemberAsObserver("", types.MustNewURLs([]string{"http://127.0.0.1:2380"}), "", nil)
+	if _, err := s.AddMember(context.Background(), m); err != errors.ErrNotCapable {
+		t.Fatalf("AddMember error = %v, want %v", err, errors.ErrNotCapable)
+	}
+	if gaction := n.Action(); len(gaction) != 0 {
+		t.Errorf("action = %v, want none", gaction)
+	}
+}
+
+// TestReplaceMemberBeforeV3_6 tests ReplaceMember is rejected until all the
+// members run 3.6, without proposing any configuration change.
+func TestReplaceMemberBeforeV3_6(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	n := newNodeConfChangeCommitterRecorder()
+	cl := newTestCluster(t, nil)
+	cl.SetStore(v2store.New())
+	cl.AddMember(&membership.Member{ID: 1234}, true)
+	s := &EtcdServer{
+		lgMu:     new(sync.RWMutex),
+		lg:       lg,
+		r:        *newRaftNode(raftNodeConfig{lg: lg, Node: n}),
+		cluster:  cl,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	m := *membership.NewMember("", types.MustNewURLs([]string{"http://127.0.0.1:2380"}), "", nil)
+	if _, err := s.ReplaceMember(context.Background(), 1234, m); err != errors.ErrNotCapable {
+		t.Fatalf("ReplaceMember error = %v, want %v", err, errors.ErrNotCapable)
+	}
+	if gaction := n.Action(); len(gaction) != 0 {
+		t.Errorf("action = %v, want none", gaction)
+	}
+}
+
+// TestPutWithTTLBeforeV3_6 tests Put and Txn reject the keys with a TTL until
+// all the members run 3.6, without proposing any request.
+func TestPutWithTTLBeforeV3_6(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	n := newNodeRecorder()
+	s := &EtcdServer{
+		lgMu:     new(sync.RWMutex),
+		lg:       lg,
+		r:        *newRaftNode(raftNodeConfig{lg: lg, Node: n}),
+		cluster:  newTestCluster(t, nil),
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	put := &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), TtlSeconds: 10}
+	if _, err := s.Put(context.Background(), put); err != errors.ErrNotCapable {
+		t.Errorf("Put error = %v, want %v", err, errors.ErrNotCapable)
+	}
+	txn := &pb.TxnRequest{Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+		Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put}}},
+	}}}}}
+	if _, err := s.Txn(context.Background(), txn); err != errors.ErrNotCapable {
+		t.Errorf("Txn error = %v, want %v", err, errors.ErrNotCapable)
+	}
+	if gaction := n.Action(); len(gaction) != 0 {
+		t.Errorf("action = %v, want none", gaction)
+	}
+}
+
+func TestLeaseGrantWithParentOrLabelsBeforeV3_6(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	n := newNodeRecorder()
+	s := &EtcdServer{
+		lgMu:     new(sync.RWMutex),
+		lg:       lg,
+		r:        *newRaftNode(raftNodeConfig{lg: lg, Node: n}),
+		cluster:  newTestCluster(t, nil),
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	for _, r := range []*pb.LeaseGrantRequest{
+		{TTL: 10, ParentID: 1},
+		{TTL: 10, Labels: []*pb.LeaseLabel{{Key: "app", Value: "web"}}},
+	} {
+		if _, err := s.LeaseGrant(context.Background(), r); err != errors.ErrNotCapable {
+			t.Errorf("LeaseGrant(%v) error = %v, want %v", r, err, errors.ErrNotCapable)
+		}
+	}
+	if gaction := n.Action(); len(gaction) != 0 {
+		t.Errorf("action = %v, want none", gaction)
+	}
+}
+
 // TestRemoveMember tests RemoveMember can propose and perform node removal.
 func TestRemoveMember(t *testing.T) {
 	lg := zaptest.NewLogger(t)
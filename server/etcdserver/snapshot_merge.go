@@ -15,6 +15,7 @@
 package etcdserver
 
 import (
+	"bufio"
 	"io"
 
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
@@ -63,7 +64,13 @@ func (s *EtcdServer) createMergedSnapshotMessage(m raftpb.Message, snapt, snapi
 func newSnapshotReaderCloser(lg *zap.Logger, snapshot backend.Snapshot) io.ReadCloser {
 	pr, pw := io.Pipe()
 	go func() {
-		n, err := snapshot.WriteTo(pw)
+		// the pipe hands the snapshot over to the reader in chunks of
+		// DBChunkSize bytes, without buffering the whole database.
+		w := bufio.NewWriterSize(pw, snap.DBChunkSize)
+		n, err := snapshot.WriteTo(w)
+		if err == nil {
+			err = w.Flush()
+		}
 		if err == nil {
 			lg.Info(
 				"sent database snapshot to writer",
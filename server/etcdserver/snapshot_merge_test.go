@@ -0,0 +1,83 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
+)
+
+// fakeSnapshot streams size zero bytes in small writes, like the bolt
+// snapshot copying the database file.
+type fakeSnapshot struct {
+	size int64
+}
+
+func (s *fakeSnapshot) Size() int64 { return s.size }
+
+func (s *fakeSnapshot) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var n int64
+	for n < s.size {
+		nw, err := w.Write(buf[:min64(int64(len(buf)), s.size-n)])
+		n += int64(nw)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (s *fakeSnapshot) Close() error { return nil }
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// TestSnapshotReaderCloserChunks ensures that a database snapshot written in
+// small writes is handed over to the reader in chunks of DBChunkSize bytes.
+func TestSnapshotReaderCloserChunks(t *testing.T) {
+	const size = 4*snap.DBChunkSize + 1
+
+	rc := newSnapshotReaderCloser(zaptest.NewLogger(t), &fakeSnapshot{size: size})
+	defer rc.Close()
+	// a read from the pipe returns at most the data of a single write.
+	buf := make([]byte, 2*snap.DBChunkSize)
+	var chunks []int
+	for {
+		n, err := rc.Read(buf)
+		if n > 0 {
+			chunks = append(chunks, n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []int{snap.DBChunkSize, snap.DBChunkSize, snap.DBChunkSize, snap.DBChunkSize, 1}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+}
@@ -54,6 +54,16 @@ func Put(ctx context.Context, lg *zap.Logger, lessor lease.Lessor, kv mvcc.KV, t
 		defer txnWrite.End()
 	}
 
+	if p.ValuePart {
+		// the part is staged, the key is put by the final put of the upload
+		resp.ValueUpload, err = txnWrite.PutValuePart(p.Key, p.ValueUpload, p.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp.Header.Revision = txnWrite.Rev()
+		return resp, trace, nil
+	}
+
 	var rr *mvcc.RangeResult
 	if p.IgnoreValue || p.IgnoreLease || p.PrevKv {
 		trace.StepWithFunction(func() {
@@ -82,7 +92,19 @@ func Put(ctx context.Context, lg *zap.Logger, lessor lease.Lessor, kv mvcc.KV, t
 		}
 	}
 
-	resp.Header.Revision = txnWrite.Put(p.Key, val, leaseID)
+	if p.ValueUpload != 0 {
+		var parts []byte
+		if parts, err = txnWrite.TakeValueParts(p.Key, p.ValueUpload); err != nil {
+			return nil, nil, err
+		}
+		val = append(parts, val...)
+	}
+
+	if p.TtlSeconds > 0 {
+		resp.Header.Revision = txnWrite.PutWithTTL(p.Key, val, p.TtlSeconds)
+	} else {
+		resp.Header.Revision = txnWrite.Put(p.Key, val, leaseID)
+	}
 	trace.AddField(traceutil.Field{Key: "response_revision", Value: resp.Header.Revision})
 	return resp, trace, nil
 }
@@ -138,9 +160,10 @@ func Range(ctx context.Context, lg *zap.Logger, kv mvcc.KV, txnRead mvcc.TxnRead
 	}
 
 	ro := mvcc.RangeOptions{
-		Limit: limit,
-		Rev:   r.Revision,
-		Count: r.CountOnly,
+		Limit:    limit,
+		Rev:      r.Revision,
+		Count:    r.CountOnly,
+		Estimate: r.Estimate,
 	}
 
 	rr, err := txnRead.Range(ctx, r.Key, mkGteRange(r.RangeEnd), ro)
@@ -208,6 +231,7 @@ func Range(ctx context.Context, lg *zap.Logger, kv mvcc.KV, txnRead mvcc.TxnRead
 	trace.Step("filter and sort the key-value pairs")
 	resp.Header.Revision = rr.Rev
 	resp.Count = int64(rr.Count)
+	resp.EstimatedSize = rr.Size
 	resp.Kvs = make([]*mvccpb.KeyValue, len(rr.KVs))
 	for i := range rr.KVs {
 		if r.KeysOnly {
@@ -643,6 +667,58 @@ func IsTxnReadonly(r *pb.TxnRequest) bool {
 	return true
 }
 
+// HasPutWithTTL returns true if the txn, or any txn nested in it, puts a key
+// with a TTL.
+func HasPutWithTTL(r *pb.TxnRequest) bool {
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, u := range ops {
+			if p := u.GetRequestPut(); p != nil && p.TtlSeconds > 0 {
+				return true
+			}
+			if t := u.GetRequestTxn(); t != nil && HasPutWithTTL(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckLeaseAttach checks that the puts of the transaction, in either of its
+// branches, do not take their leases past the maximum number of attached keys.
+func CheckLeaseAttach(lessor lease.Lessor, r *pb.TxnRequest) error {
+	items := make(map[lease.LeaseID][]lease.LeaseItem)
+	collectLeaseItems(r, items)
+	for id, its := range items {
+		if err := lessor.CheckAttach(id, its); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collectLeaseItems(r *pb.TxnRequest, items map[lease.LeaseID][]lease.LeaseItem) {
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, u := range ops {
+			if p := u.GetRequestPut(); p != nil && lease.LeaseID(p.Lease) != lease.NoLease {
+				items[lease.LeaseID(p.Lease)] = append(items[lease.LeaseID(p.Lease)], lease.LeaseItem{Key: string(p.Key)})
+			}
+			if t := u.GetRequestTxn(); t != nil {
+				collectLeaseItems(t, items)
+			}
+		}
+	}
+}
+
+// CheckRangeAuth checks the permission of the user to serve the range request.
+// The count-only and keys-only ranges, which do not read the values of the
+// keys, need the count permission only.
+func CheckRangeAuth(as auth.AuthStore, ai *auth.AuthInfo, r *pb.RangeRequest) error {
+	if r.CountOnly || r.KeysOnly {
+		return as.IsCountPermitted(ai, r.Key, r.RangeEnd)
+	}
+	return as.IsRangePermitted(ai, r.Key, r.RangeEnd)
+}
+
 func CheckTxnAuth(as auth.AuthStore, ai *auth.AuthInfo, rt *pb.TxnRequest) error {
 	for _, c := range rt.Compare {
 		if err := as.IsRangePermitted(ai, c.Key, c.RangeEnd); err != nil {
@@ -663,7 +739,7 @@ func checkTxnReqsPermission(as auth.AuthStore, ai *auth.AuthInfo, reqs []*pb.Req
 				continue
 			}
 
-			if err := as.IsRangePermitted(ai, tv.RequestRange.Key, tv.RequestRange.RangeEnd); err != nil {
+			if err := CheckRangeAuth(as, ai, tv.RequestRange); err != nil {
 				return err
 			}
 
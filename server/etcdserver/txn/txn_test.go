@@ -94,3 +94,46 @@ func TestWriteTxnPanic(t *testing.T) {
 
 	assert.Panics(t, func() { Txn(ctx, zaptest.NewLogger(t), txn, false, s, &lease.FakeLessor{}) }, "Expected panic in Txn with writes")
 }
+
+// fullLessor is a lessor whose leases all have the maximum number of
+// attached keys.
+type fullLessor struct {
+	lease.FakeLessor
+	checked map[lease.LeaseID][]lease.LeaseItem
+}
+
+func (fl *fullLessor) Lookup(id lease.LeaseID) *lease.Lease { return &lease.Lease{ID: id} }
+
+func (fl *fullLessor) CheckAttach(id lease.LeaseID, items []lease.LeaseItem) error {
+	fl.checked[id] = append(fl.checked[id], items...)
+	return lease.ErrLeaseTooManyAttachedKeys
+}
+
+// TestLeaseAttachCheckedBeforeApply ensures the puts of both branches of a
+// transaction are checked against the lease attach limit, while the apply of
+// the puts does not depend on it.
+func TestLeaseAttachCheckedBeforeApply(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer s.Close()
+
+	le := &fullLessor{checked: make(map[lease.LeaseID][]lease.LeaseItem)}
+	txn := &pb.TxnRequest{
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("foo"), Lease: 1}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Success: []*pb.RequestOp{
+					{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("bar"), Lease: 1}}},
+				},
+			}}},
+		},
+	}
+	assert.ErrorIs(t, CheckLeaseAttach(le, txn), lease.ErrLeaseTooManyAttachedKeys)
+	assert.Equal(t, []lease.LeaseItem{{Key: "foo"}, {Key: "bar"}}, le.checked[1])
+
+	_, _, err := Put(context.TODO(), zaptest.NewLogger(t), le, s, nil, &pb.PutRequest{Key: []byte("foo"), Lease: 1})
+	assert.NoError(t, err)
+}
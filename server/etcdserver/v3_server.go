@@ -23,21 +23,30 @@ import (
 	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3revisionpin"
 	apply2 "go.etcd.io/etcd/server/v3/etcdserver/apply"
 	"go.etcd.io/etcd/server/v3/etcdserver/errors"
 	"go.etcd.io/etcd/server/v3/etcdserver/txn"
+	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
+	"go.etcd.io/etcd/server/v3/features"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/lease/leasehttp"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 	"go.etcd.io/raft/v3"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/gogo/protobuf/proto"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/peer"
 )
 
 const (
@@ -52,10 +61,18 @@ const (
 	// The timeout for the node to catch up its applied index, and is used in
 	// lease related operations, such as LeaseRenew and LeaseTimeToLive.
 	applyTimeout = time.Second
+
+	// defaultSnapshotRangeLimit is the number of keys returned by a
+	// SnapshotRange call without a limit.
+	defaultSnapshotRangeLimit = 1000
+	// defaultSnapshotRangeTTL is the time to live, in seconds, of the pin of
+	// the revision of a SnapshotRange session without a TTL.
+	defaultSnapshotRangeTTL = 60
 )
 
 type RaftKV interface {
 	Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error)
+	IndexRange(ctx context.Context, r *pb.IndexRangeRequest) (*pb.RangeResponse, error)
 	Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error)
 	DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error)
 	Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error)
@@ -67,6 +84,12 @@ type Lessor interface {
 	LeaseGrant(ctx context.Context, r *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error)
 	// LeaseRevoke sends LeaseRevoke request to raft and toApply it after committed.
 	LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	// LeaseGrantBatch sends a LeaseGrantBatch request to raft, granting all its leases with a
+	// single proposal.
+	LeaseGrantBatch(ctx context.Context, r *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error)
+	// LeaseRevokeBatch sends a LeaseRevokeBatch request to raft, revoking all its leases with a
+	// single proposal.
+	LeaseRevokeBatch(ctx context.Context, r *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error)
 
 	// LeaseRenew renews the lease with given ID. The renewed TTL is returned. Or an error
 	// is returned.
@@ -77,6 +100,13 @@ type Lessor interface {
 
 	// LeaseLeases lists all leases.
 	LeaseLeases(ctx context.Context, r *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error)
+
+	// LeaseQuery lists the leases carrying all the given labels.
+	LeaseQuery(ctx context.Context, r *pb.LeaseQueryRequest) (*pb.LeaseQueryResponse, error)
+
+	// LeaseWatchExpirations returns a chan receiving the leases revoked from now on,
+	// expired leases included, and a func to stop watching.
+	LeaseWatchExpirations() (<-chan lease.RevokedLease, func())
 }
 
 type Authenticator interface {
@@ -94,6 +124,11 @@ type Authenticator interface {
 	RoleGrantPermission(ctx context.Context, r *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error)
 	RoleGet(ctx context.Context, r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse, error)
 	RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRevokePermissionRequest) (*pb.AuthRoleRevokePermissionResponse, error)
+	RoleSetMaxLeaseTTL(ctx context.Context, r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error)
+	RoleSetQuota(ctx context.Context, r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error)
+	AuthSetBcryptCost(ctx context.Context, r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error)
+	AuthTokenList(ctx context.Context, r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error)
+	AuthTokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error)
 	RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 	UserList(ctx context.Context, r *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ctx context.Context, r *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
@@ -110,7 +145,7 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 	var resp *pb.RangeResponse
 	var err error
 	defer func(start time.Time) {
-		txn.WarnOfExpensiveReadOnlyRangeRequest(s.Logger(), s.Cfg.WarningApplyDuration, start, r, resp, err)
+		txn.WarnOfExpensiveReadOnlyRangeRequest(s.Logger(), s.warningApplyDuration(), start, r, resp, err)
 		if resp != nil {
 			trace.AddField(
 				traceutil.Field{Key: "response_count", Value: len(resp.Kvs)},
@@ -120,7 +155,7 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 		trace.LogIfLong(traceThreshold)
 	}(time.Now())
 
-	if !r.Serializable {
+	if !r.Serializable && !(r.BoundedStaleness && s.serveBoundedStalenessLocally(trace)) {
 		err = s.linearizableReadNotify(ctx)
 		trace.Step("agreement among raft nodes before linearized reading")
 		if err != nil {
@@ -128,7 +163,7 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 		}
 	}
 	chk := func(ai *auth.AuthInfo) error {
-		return s.authStore.IsRangePermitted(ai, r.Key, r.RangeEnd)
+		return txn.CheckRangeAuth(s.authStore, ai, r)
 	}
 
 	get := func() { resp, err = txn.Range(ctx, s.Logger(), s.KV(), nil, r) }
@@ -139,7 +174,90 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 	return resp, err
 }
 
+// serveBoundedStalenessLocally returns true if a bounded staleness read can be
+// served from the local state of the member, that is if the member is connected
+// to the leader and its applied index lags the commit index, as learned from the
+// appends and heartbeats of the leader, by at most BoundedStalenessMaxLag entries,
+// and the BoundedStalenessReads feature is enabled. Otherwise the read is
+// linearizable.
+func (s *EtcdServer) serveBoundedStalenessLocally(trace *traceutil.Trace) bool {
+	lead := types.ID(s.getLead())
+	local := s.FeatureEnabled(features.BoundedStalenessReads) &&
+		lead != types.ID(raft.None) &&
+		(lead == s.MemberId() || !s.r.transport.ActiveSince(lead).IsZero()) &&
+		s.getCommittedIndex() <= s.getAppliedIndex()+s.Cfg.BoundedStalenessMaxLag
+	if !local {
+		boundedStalenessReads.WithLabelValues("linearized").Inc()
+		return false
+	}
+	boundedStalenessReads.WithLabelValues("local").Inc()
+	trace.Step("applied index within the staleness bound")
+	return true
+}
+
+// IndexRange looks up the keys whose JSON value has the requested value at an
+// indexed field. Unlike Range, it is served at the current revision only.
+func (s *EtcdServer) IndexRange(ctx context.Context, r *pb.IndexRangeRequest) (*pb.RangeResponse, error) {
+	trace := traceutil.New("index_range",
+		s.Logger(),
+		traceutil.Field{Key: "prefix", Value: string(r.Prefix)},
+		traceutil.Field{Key: "field", Value: r.Field},
+	)
+	defer trace.LogIfLong(traceThreshold)
+
+	if !r.Serializable {
+		err := s.linearizableReadNotify(ctx)
+		trace.Step("agreement among raft nodes before linearized reading")
+		if err != nil {
+			return nil, err
+		}
+	}
+	chk := func(ai *auth.AuthInfo) error {
+		return s.authStore.IsRangePermitted(ai, r.Prefix, v3prefixquota.PrefixEnd(r.Prefix))
+	}
+
+	var resp *pb.RangeResponse
+	var err error
+	get := func() {
+		var rr *mvcc.RangeResult
+		rr, err = s.KV().IndexRange(ctx, r.Prefix, r.Field, r.Value, r.Limit)
+		if err != nil {
+			return
+		}
+		resp = &pb.RangeResponse{
+			Header: &pb.ResponseHeader{Revision: rr.Rev},
+			Kvs:    make([]*mvccpb.KeyValue, len(rr.KVs)),
+			Count:  int64(rr.Count),
+			More:   rr.Count > len(rr.KVs),
+		}
+		for i := range rr.KVs {
+			if r.KeysOnly {
+				rr.KVs[i].Value = nil
+			}
+			resp.Kvs[i] = &rr.KVs[i]
+		}
+		trace.Step("assemble the response")
+	}
+	if serr := s.doSerialize(ctx, chk, get); serr != nil {
+		return nil, serr
+	}
+	return resp, err
+}
+
 func (s *EtcdServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	if r.TtlSeconds > 0 && !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	if r.ValuePart || r.ValueUpload != 0 {
+		if err := s.checkValueUpload(r); err != nil {
+			return nil, err
+		}
+	}
+	if lease.LeaseID(r.Lease) != lease.NoLease {
+		if err := s.lessor.CheckAttach(lease.LeaseID(r.Lease), []lease.LeaseItem{{Key: string(r.Key)}}); err != nil {
+			return nil, err
+		}
+	}
 	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Put: r})
 	if err != nil {
@@ -148,6 +266,32 @@ func (s *EtcdServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse
 	return resp.(*pb.PutResponse), nil
 }
 
+// checkValueUpload admits the puts of the values in parts once all the
+// members enable the ValueChunking feature, as long as the value does not
+// exceed MaxChunkedValueBytes.
+func (s *EtcdServer) checkValueUpload(r *pb.PutRequest) error {
+	if s.Cfg.MaxChunkedValueBytes == 0 || !s.FeatureEnabled(features.ValueChunking) || !s.featureEnabledOnMembers(features.ValueChunking) {
+		return errors.ErrNotCapable
+	}
+	size := int64(len(r.Value))
+	if r.ValueUpload != 0 {
+		tx := s.Backend().ConcurrentReadTx()
+		tx.RLock()
+		u, err := schema.UnsafeReadValueUpload(tx, r.ValueUpload)
+		tx.RUnlock()
+		if err != nil {
+			return err
+		}
+		if u != nil {
+			size += u.Size
+		}
+	}
+	if size > int64(s.Cfg.MaxChunkedValueBytes) {
+		return errors.ErrRequestTooLarge
+	}
+	return nil
+}
+
 func (s *EtcdServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{DeleteRange: r})
 	if err != nil {
@@ -177,7 +321,7 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 		}
 
 		defer func(start time.Time) {
-			txn.WarnOfExpensiveReadOnlyTxnRequest(s.Logger(), s.Cfg.WarningApplyDuration, start, r, resp, err)
+			txn.WarnOfExpensiveReadOnlyTxnRequest(s.Logger(), s.warningApplyDuration(), start, r, resp, err)
 			trace.LogIfLong(traceThreshold)
 		}(time.Now())
 
@@ -190,6 +334,12 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 		return resp, err
 	}
 
+	if txn.HasPutWithTTL(r) && !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	if err := txn.CheckLeaseAttach(s.lessor, r); err != nil {
+		return nil, err
+	}
 	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Txn: r})
 	if err != nil {
@@ -244,6 +394,9 @@ func (s *EtcdServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.
 }
 
 func (s *EtcdServer) LeaseGrant(ctx context.Context, r *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
+	if (r.ParentID != 0 || len(r.Labels) > 0) && !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
 	// no id given? choose one
 	for r.ID == int64(lease.NoLease) {
 		// only use positive int64 id's
@@ -276,6 +429,49 @@ func (s *EtcdServer) LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest)
 	return resp.(*pb.LeaseRevokeResponse), nil
 }
 
+// ChangeFeedCheckpoint proposes the put of a change feed checkpoint in the
+// reserved key space, so that the leaders following the proposing one resume
+// publishing from it.
+func (s *EtcdServer) ChangeFeedCheckpoint(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{ChangeFeedCheckpoint: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PutResponse), nil
+}
+
+func (s *EtcdServer) LeaseGrantBatch(ctx context.Context, r *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	for _, gr := range r.Requests {
+		// no id given? choose one
+		for gr.ID == int64(lease.NoLease) {
+			// only use positive int64 id's
+			gr.ID = int64(s.reqIDGen.Next() & ((1 << 63) - 1))
+		}
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseGrantBatch: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseGrantBatchResponse), nil
+}
+
+func (s *EtcdServer) LeaseRevokeBatch(ctx context.Context, r *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseRevokeBatch: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseRevokeBatchResponse), nil
+}
+
 func (s *EtcdServer) LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, error) {
 	if s.isLeader() {
 		if err := s.waitAppliedIndex(); err != nil {
@@ -328,7 +524,7 @@ func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveR
 			return nil, lease.ErrLeaseNotFound
 		}
 		// TODO: fill out ResponseHeader
-		resp := &pb.LeaseTimeToLiveResponse{Header: &pb.ResponseHeader{}, ID: r.ID, TTL: int64(le.Remaining().Seconds()), GrantedTTL: le.TTL()}
+		resp := &pb.LeaseTimeToLiveResponse{Header: &pb.ResponseHeader{}, ID: r.ID, TTL: int64(le.Remaining().Seconds()), GrantedTTL: le.TTL(), ParentID: int64(le.Parent()), Labels: le.Labels()}
 		if r.Keys {
 			ks := le.Keys()
 			kbs := make([][]byte, len(ks))
@@ -337,6 +533,9 @@ func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveR
 			}
 			resp.Keys = kbs
 		}
+		if r.Stats {
+			resp.Stats = le.Stats()
+		}
 		return resp, nil
 	}
 
@@ -351,7 +550,7 @@ func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveR
 		}
 		for _, url := range leader.PeerURLs {
 			lurl := url + leasehttp.LeaseInternalPrefix
-			resp, err := leasehttp.TimeToLiveHTTP(cctx, lease.LeaseID(r.ID), r.Keys, lurl, s.peerRt)
+			resp, err := leasehttp.TimeToLiveHTTP(cctx, r, lurl, s.peerRt)
 			if err == nil {
 				return resp.LeaseTimeToLiveResponse, nil
 			}
@@ -386,6 +585,20 @@ func (s *EtcdServer) LeaseLeases(_ context.Context, _ *pb.LeaseLeasesRequest) (*
 	return &pb.LeaseLeasesResponse{Header: s.newHeader(), Leases: lss}, nil
 }
 
+func (s *EtcdServer) LeaseQuery(_ context.Context, r *pb.LeaseQueryRequest) (*pb.LeaseQueryResponse, error) {
+	var lss []*pb.LeaseStatus
+	for _, l := range s.lessor.Leases() {
+		if l.HasLabels(r.Labels) {
+			lss = append(lss, &pb.LeaseStatus{ID: int64(l.ID), Labels: l.Labels()})
+		}
+	}
+	return &pb.LeaseQueryResponse{Header: s.newHeader(), Leases: lss}, nil
+}
+
+func (s *EtcdServer) LeaseWatchExpirations() (<-chan lease.RevokedLease, func()) {
+	return s.lessor.WatchRevoked()
+}
+
 func (s *EtcdServer) waitLeader(ctx context.Context) (*membership.Member, error) {
 	leader := s.cluster.Member(s.Leader())
 	for leader == nil {
@@ -414,6 +627,97 @@ func (s *EtcdServer) Alarm(ctx context.Context, r *pb.AlarmRequest) (*pb.AlarmRe
 	return resp.(*pb.AlarmResponse), nil
 }
 
+func (s *EtcdServer) PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{PrefixQuota: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PrefixQuotaResponse), nil
+}
+
+func (s *EtcdServer) RevisionPin(ctx context.Context, r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{RevisionPin: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RevisionPinResponse), nil
+}
+
+// SnapshotRange returns a page of the range at the revision of the session,
+// pinned against compaction by the first call of the session and renewed by
+// the following ones, until the last page releases it. The user must be
+// permitted to read the range.
+func (s *EtcdServer) SnapshotRange(ctx context.Context, r *pb.SnapshotRangeRequest) (*pb.SnapshotRangeResponse, error) {
+	ai, err := s.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ai == nil {
+		ai = &auth.AuthInfo{}
+	}
+	if err = s.AuthStore().IsRangePermitted(ai, r.Key, r.RangeEnd); err != nil {
+		return nil, err
+	}
+
+	limit, ttl := r.Limit, r.TTL
+	if limit <= 0 {
+		limit = defaultSnapshotRangeLimit
+	}
+	if ttl <= 0 {
+		ttl = defaultSnapshotRangeTTL
+	}
+
+	owner := v3revisionpin.SnapshotRangeOwnerPrefix + r.Session
+	rev := r.Revision
+	if pins := s.revisionPins.Get(owner); len(pins) != 0 {
+		rev = pins[0].Revision
+	}
+	// pinning again at the same revision renews the pin
+	presp, err := s.RevisionPin(ctx, &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_PIN, Owner: owner, Revision: rev, TTL: ttl})
+	if err != nil {
+		return nil, err
+	}
+	pin := presp.Pins[0]
+
+	// the pinned revision is read once the local store caught up with the
+	// leader, as a linearizable Range.
+	if err = s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	rresp, err := txn.Range(ctx, s.Logger(), s.KV(), nil, &pb.RangeRequest{
+		Key:      r.Key,
+		RangeEnd: r.RangeEnd,
+		Revision: pin.Revision,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.SnapshotRangeResponse{
+		Header:   rresp.Header,
+		Revision: pin.Revision,
+		Kvs:      rresp.Kvs,
+		More:     rresp.More,
+	}
+	if resp.More {
+		last := resp.Kvs[len(resp.Kvs)-1].Key
+		resp.NextKey = append(append(make([]byte, 0, len(last)+1), last...), 0)
+		return resp, nil
+	}
+	_, err = s.RevisionPin(ctx, &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_UNPIN, Owner: owner, Version: pin.Version})
+	if err != nil {
+		// the pin is released anyway once its TTL elapses
+		s.Logger().Warn("failed to release snapshot range revision pin", zap.String("session", r.Session), zap.Error(err))
+	}
+	return resp, nil
+}
+
 func (s *EtcdServer) AuthEnable(ctx context.Context, r *pb.AuthEnableRequest) (*pb.AuthEnableResponse, error) {
 	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{AuthEnable: r})
 	if err != nil {
@@ -439,6 +743,9 @@ func (s *EtcdServer) AuthStatus(ctx context.Context, r *pb.AuthStatusRequest) (*
 }
 
 func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	if !ClientAuthModesFromContext(ctx).Token {
+		return nil, auth.ErrPermissionDenied
+	}
 	if err := s.linearizableReadNotify(ctx); err != nil {
 		return nil, err
 	}
@@ -470,6 +777,21 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 			Name:        r.Name,
 			SimpleToken: st,
 		}
+		if s.isClusterVersionV3_6() {
+			if p, ok := peer.FromContext(ctx); ok {
+				internalReq.PeerAddress = p.Addr.String()
+			}
+			if s.AuthStore().NeedsRehash(r.Name) {
+				// rehash the password with the raised bcrypt cost, the checked
+				// revision dropping the rehash if the password changed since
+				hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.Password), s.AuthStore().BcryptCost())
+				if err != nil {
+					return nil, err
+				}
+				internalReq.HashedPassword = base64.StdEncoding.EncodeToString(hashedPassword)
+				internalReq.AuthRevision = checkedRevision
+			}
+		}
 
 		resp, err = s.raftRequestOnce(ctx, pb.InternalRaftRequest{Authenticate: internalReq})
 		if err != nil {
@@ -485,8 +807,18 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 	return resp.(*pb.AuthenticateResponse), nil
 }
 
+// isClusterVersionV3_6 returns true if all the members are of version 3.6 or
+// later, so that they all apply the fields of the requests added in 3.6.
+func (s *EtcdServer) isClusterVersionV3_6() bool {
+	cv := s.ClusterVersion()
+	return cv != nil && !version.LessThan(*cv, version.V3_6)
+}
+
 func (s *EtcdServer) UserAdd(ctx context.Context, r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse, error) {
 	if r.Options == nil || !r.Options.NoPassword {
+		if err := s.Cfg.PasswordPolicy.Check(r.Password); err != nil {
+			return nil, err
+		}
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.Password), s.authStore.BcryptCost())
 		if err != nil {
 			return nil, err
@@ -511,6 +843,12 @@ func (s *EtcdServer) UserDelete(ctx context.Context, r *pb.AuthUserDeleteRequest
 }
 
 func (s *EtcdServer) UserChangePassword(ctx context.Context, r *pb.AuthUserChangePasswordRequest) (*pb.AuthUserChangePasswordResponse, error) {
+	if r.HashedPassword == "" {
+		// the passwords hashed by the clients cannot be checked
+		if err := s.Cfg.PasswordPolicy.Check(r.Password); err != nil {
+			return nil, err
+		}
+	}
 	if r.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.Password), s.authStore.BcryptCost())
 		if err != nil {
@@ -599,6 +937,61 @@ func (s *EtcdServer) RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRev
 	return resp.(*pb.AuthRoleRevokePermissionResponse), nil
 }
 
+func (s *EtcdServer) RoleSetMaxLeaseTTL(ctx context.Context, r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleSetMaxLeaseTTL: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthRoleSetMaxLeaseTTLResponse), nil
+}
+
+func (s *EtcdServer) RoleSetQuota(ctx context.Context, r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleSetQuota: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthRoleSetQuotaResponse), nil
+}
+
+func (s *EtcdServer) AuthSetBcryptCost(ctx context.Context, r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthSetBcryptCost: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthSetBcryptCostResponse), nil
+}
+
+func (s *EtcdServer) AuthTokenList(ctx context.Context, r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthTokenList: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthTokenListResponse), nil
+}
+
+func (s *EtcdServer) AuthTokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error) {
+	if !s.isClusterVersionV3_6() {
+		return nil, errors.ErrNotCapable
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthTokenRevoke: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthTokenRevokeResponse), nil
+}
+
 func (s *EtcdServer) RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error) {
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleDelete: r})
 	if err != nil {
@@ -674,8 +1067,14 @@ func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.In
 			return nil, err
 		}
 		if authInfo != nil {
+			// the members before 3.6 would apply the request without the
+			// roles granted by the token
+			if len(authInfo.Roles) > 0 && !s.isClusterVersionV3_6() {
+				return nil, errors.ErrNotCapable
+			}
 			r.Header.Username = authInfo.Username
 			r.Header.AuthRevision = authInfo.Revision
+			r.Header.Roles = authInfo.Roles
 		}
 	}
 
@@ -903,14 +1302,19 @@ func (s *EtcdServer) linearizableReadNotify(ctx context.Context) error {
 }
 
 func (s *EtcdServer) AuthInfoFromCtx(ctx context.Context) (*auth.AuthInfo, error) {
-	authInfo, err := s.AuthStore().AuthInfoFromCtx(ctx)
-	if authInfo != nil || err != nil {
-		return authInfo, err
+	modes := ClientAuthModesFromContext(ctx)
+	if modes.Token {
+		authInfo, err := s.AuthStore().AuthInfoFromCtx(ctx)
+		if authInfo != nil || err != nil {
+			return authInfo, err
+		}
+	} else if hasToken(ctx) && s.AuthStore().IsAuthEnabled() {
+		return nil, auth.ErrPermissionDenied
 	}
-	if !s.Cfg.ClientCertAuthEnabled {
+	if !s.Cfg.ClientCertAuthEnabled || !modes.Cert {
 		return nil, nil
 	}
-	authInfo = s.AuthStore().AuthInfoFromTLS(ctx)
+	authInfo := s.AuthStore().AuthInfoFromTLS(ctx)
 	return authInfo, nil
 }
 
@@ -944,6 +1348,9 @@ func (s *EtcdServer) downgradeValidate(ctx context.Context, v string) (*pb.Downg
 	if err != nil {
 		return nil, err
 	}
+	if err = s.checkDowngradeStorage(targetVersion); err != nil {
+		return nil, err
+	}
 
 	return resp, nil
 }
@@ -955,6 +1362,10 @@ func (s *EtcdServer) downgradeEnable(ctx context.Context, r *pb.DowngradeRequest
 		lg.Warn("reject downgrade request", zap.Error(err))
 		return nil, err
 	}
+	if err = s.checkDowngradeStorage(targetVersion); err != nil {
+		lg.Warn("reject downgrade request", zap.Error(err))
+		return nil, err
+	}
 	err = s.Version().DowngradeEnable(ctx, targetVersion)
 	if err != nil {
 		lg.Warn("reject downgrade request", zap.Error(err))
@@ -964,6 +1375,22 @@ func (s *EtcdServer) downgradeEnable(ctx context.Context, r *pb.DowngradeRequest
 	return &resp, nil
 }
 
+// checkDowngradeStorage rejects the downgrades to v3.5 while the backend
+// holds compressed or chunked key-value records, which v3.5 cannot read.
+func (s *EtcdServer) checkDowngradeStorage(targetVersion *semver.Version) error {
+	if !targetVersion.LessThan(version.V3_6) {
+		return nil
+	}
+	found, err := schema.HasEncodedKeyValues(s.Backend().ConcurrentReadTx())
+	if err != nil {
+		return err
+	}
+	if found {
+		return serverversion.ErrDowngradeEncodedKeyValues
+	}
+	return nil
+}
+
 func (s *EtcdServer) downgradeCancel(ctx context.Context) (*pb.DowngradeResponse, error) {
 	err := s.Version().DowngradeCancel(ctx)
 	if err != nil {
@@ -20,4 +20,5 @@ var (
 	ErrInvalidDowngradeTargetVersion = errors.New("etcdserver: invalid downgrade target version")
 	ErrDowngradeInProcess            = errors.New("etcdserver: cluster has a downgrade job in progress")
 	ErrNoInflightDowngrade           = errors.New("etcdserver: no inflight downgrade job")
+	ErrDowngradeEncodedKeyValues     = errors.New("etcdserver: storage holds compressed or chunked records the downgrade target version cannot read")
 )
@@ -17,6 +17,9 @@ package version
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/coreos/go-semver/semver"
 	"go.uber.org/zap"
@@ -219,3 +222,53 @@ func (m *Monitor) versionsMatchTarget(targetVersion *semver.Version) bool {
 	}
 	return true
 }
+
+// FeatureGateMismatches returns the feature gates of the members disagreeing with
+// the local ones, which may make the members behave differently, e.g. during a
+// mixed-version upgrade. A feature gate unknown to a member is disabled on it, and
+// the members of versions without feature gates are not checked.
+func (m *Monitor) FeatureGateMismatches(local map[string]bool) []string {
+	return FeatureGateMismatches(m.s.GetMembersVersions(), local)
+}
+
+// VersionRetentionMismatches returns the members in vers keeping other versions
+// of the keys through the compactions than the local version retentions, sorted.
+// The members of versions without version retentions keep none.
+func VersionRetentionMismatches(vers map[string]*version.Versions, local []string) []string {
+	var mismatches []string
+	for mid, ver := range vers {
+		if ver == nil || strings.Join(ver.VersionRetention, ",") == strings.Join(local, ",") {
+			continue
+		}
+		mismatches = append(mismatches, fmt.Sprintf("member %s (version %s) has version retention %q, locally %q", mid, ver.Server, ver.VersionRetention, local))
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// FeatureGateMismatches returns the feature gates of the members in vers
+// disagreeing with the local ones, sorted.
+func FeatureGateMismatches(vers map[string]*version.Versions, local map[string]bool) []string {
+	var mismatches []string
+	for mid, ver := range vers {
+		if ver == nil || ver.FeatureGates == nil {
+			continue
+		}
+		for name, lv := range local {
+			rv, ok := ver.FeatureGates[name]
+			switch {
+			case !ok && lv:
+				mismatches = append(mismatches, fmt.Sprintf("member %s (version %s) does not know feature gate %s enabled locally", mid, ver.Server, name))
+			case ok && rv != lv:
+				mismatches = append(mismatches, fmt.Sprintf("member %s (version %s) has feature gate %s=%t, locally %t", mid, ver.Server, name, rv, lv))
+			}
+		}
+		for name, rv := range ver.FeatureGates {
+			if _, ok := local[name]; !ok && rv {
+				mismatches = append(mismatches, fmt.Sprintf("member %s (version %s) has feature gate %s enabled, unknown locally", mid, ver.Server, name))
+			}
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
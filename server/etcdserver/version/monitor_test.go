@@ -167,6 +167,89 @@ func TestVersionMatchTarget(t *testing.T) {
 	}
 }
 
+func TestFeatureGateMismatches(t *testing.T) {
+	local := map[string]bool{"Foo": true, "Bar": false}
+	tests := []struct {
+		name       string
+		versionMap map[string]*version.Versions
+		expected   []string
+	}{
+		{
+			"When feature gates match",
+			map[string]*version.Versions{
+				"mem1": {Server: "3.6.0", FeatureGates: map[string]bool{"Foo": true, "Bar": false}},
+				"mem2": {Server: "3.7.0", FeatureGates: map[string]bool{"Foo": true, "Bar": false, "Baz": false}},
+			},
+			nil,
+		},
+		{
+			"When members do not report feature gates",
+			map[string]*version.Versions{
+				"mem1": {Server: "3.5.0"},
+				"mem2": nil,
+			},
+			nil,
+		},
+		{
+			"When feature gates do not match",
+			map[string]*version.Versions{
+				"mem1": {Server: "3.6.0", FeatureGates: map[string]bool{"Foo": false, "Bar": false}},
+				"mem2": {Server: "3.6.0", FeatureGates: map[string]bool{"Bar": false}},
+				"mem3": {Server: "3.7.0", FeatureGates: map[string]bool{"Foo": true, "Bar": false, "Baz": true}},
+			},
+			[]string{
+				"member mem1 (version 3.6.0) has feature gate Foo=false, locally true",
+				"member mem2 (version 3.6.0) does not know feature gate Foo enabled locally",
+				"member mem3 (version 3.7.0) has feature gate Baz enabled, unknown locally",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMonitor(zaptest.NewLogger(t), &storageMock{
+				memberVersions: tt.versionMap,
+			})
+			assert.Equal(t, tt.expected, monitor.FeatureGateMismatches(local))
+		})
+	}
+}
+
+func TestVersionRetentionMismatches(t *testing.T) {
+	local := []string{"/audit/=3", "=1"}
+	tests := []struct {
+		name       string
+		versionMap map[string]*version.Versions
+		expected   []string
+	}{
+		{
+			"When version retentions match",
+			map[string]*version.Versions{
+				"mem1": {Server: "3.6.0", VersionRetention: []string{"/audit/=3", "=1"}},
+				"mem2": nil,
+			},
+			nil,
+		},
+		{
+			"When version retentions do not match",
+			map[string]*version.Versions{
+				"mem1": {Server: "3.6.0", VersionRetention: []string{"/audit/=2", "=1"}},
+				"mem2": {Server: "3.5.0"},
+			},
+			[]string{
+				`member mem1 (version 3.6.0) has version retention ["/audit/=2" "=1"], locally ["/audit/=3" "=1"]`,
+				`member mem2 (version 3.5.0) has version retention [], locally ["/audit/=3" "=1"]`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, VersionRetentionMismatches(tt.versionMap, local))
+		})
+	}
+}
+
 func TestUpdateClusterVersionIfNeeded(t *testing.T) {
 	tests := []struct {
 		name                 string
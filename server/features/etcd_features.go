@@ -0,0 +1,296 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package features defines the feature gates of the etcd server.
+package features
+
+import (
+	"fmt"
+
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+)
+
+const (
+	// The features are listed in alphabetical order, with the version
+	// they reached their maturity in.
+
+	// AuditLog records the requests of the clients to the audit log of
+	// --experimental-audit-log-path.
+	// alpha: v3.6
+	AuditLog featuregate.Feature = "AuditLog"
+	// Authz consults the external authorizer of
+	// --experimental-authz-webhook-url on the requests of the users
+	// permitted by their roles.
+	// alpha: v3.6
+	Authz featuregate.Feature = "Authz"
+	// AutoDefrag defragments the backend once the ratio of its bytes not in
+	// use exceeds --experimental-auto-defrag-threshold.
+	// alpha: v3.6
+	AutoDefrag featuregate.Feature = "AutoDefrag"
+	// BackendEngine enables the storage engines of the backend other than
+	// bbolt, chosen with --experimental-backend-engine.
+	// alpha: v3.6
+	BackendEngine featuregate.Feature = "BackendEngine"
+	// BackendQuotaGrowth grows the backend quota up to
+	// --experimental-max-quota-backend-bytes.
+	// alpha: v3.6
+	BackendQuotaGrowth featuregate.Feature = "BackendQuotaGrowth"
+	// BoundedStalenessReads serves the bounded staleness reads locally while
+	// the applied index lags the commit index by at most
+	// --experimental-bounded-staleness-max-lag entries. They are
+	// linearizable otherwise.
+	// beta: v3.6
+	BoundedStalenessReads featuregate.Feature = "BoundedStalenessReads"
+	// ChangeFeeds publishes the committed events of key prefixes to sinks
+	// while the member is the leader.
+	// alpha: v3.6
+	ChangeFeeds featuregate.Feature = "ChangeFeeds"
+	// ClientCertSANRoles grants roles to the clients authenticated by the
+	// subject alternative names of their certificates.
+	// alpha: v3.6
+	ClientCertSANRoles featuregate.Feature = "ClientCertSANRoles"
+	// ClientListenerGroups serves the client listener groups of the config
+	// file with their own TLS settings.
+	// alpha: v3.6
+	ClientListenerGroups featuregate.Feature = "ClientListenerGroups"
+	// CompactHashCheck enables the leader to periodically check the
+	// compaction hashes of the followers.
+	// alpha: v3.6
+	CompactHashCheck featuregate.Feature = "CompactHashCheck"
+	// CompactionPacing resizes the compaction batches for the time each
+	// holds the backend to approach
+	// --experimental-compaction-batch-target-duration.
+	// beta: v3.6
+	CompactionPacing featuregate.Feature = "CompactionPacing"
+	// CompactionRevisionAlignment rounds the auto compaction revisions down
+	// to a multiple of --experimental-compaction-revision-alignment.
+	// alpha: v3.6
+	CompactionRevisionAlignment featuregate.Feature = "CompactionRevisionAlignment"
+	// ConnectionLimits limits the number and the accept rate of the client
+	// and peer connections.
+	// alpha: v3.6
+	ConnectionLimits featuregate.Feature = "ConnectionLimits"
+	// CorruptCheckSchedule runs the corruption check passes on the cron
+	// schedule of --experimental-corrupt-check-schedule.
+	// alpha: v3.6
+	CorruptCheckSchedule featuregate.Feature = "CorruptCheckSchedule"
+	// GracefulDrain drains the client requests on SIGTERM or SIGINT for
+	// --experimental-drain-timeout before stopping.
+	// alpha: v3.6
+	GracefulDrain featuregate.Feature = "GracefulDrain"
+	// HotKeyTracking enables the estimation of the traffic per key prefix.
+	// alpha: v3.6
+	HotKeyTracking featuregate.Feature = "HotKeyTracking"
+	// IncrementalDefrag defragments the backend in small batches.
+	// alpha: v3.6
+	IncrementalDefrag featuregate.Feature = "IncrementalDefrag"
+	// InitialCorruptCheck checks the data corruption before serving any
+	// client or peer traffic.
+	// alpha: v3.6
+	InitialCorruptCheck featuregate.Feature = "InitialCorruptCheck"
+	// LearnerAutoPromote promotes the learners caught up with the leader
+	// for --experimental-learner-auto-promote-duration.
+	// alpha: v3.6
+	LearnerAutoPromote featuregate.Feature = "LearnerAutoPromote"
+	// LeaseCheckpoint enables the leader to send regular checkpoints to the
+	// other members to prevent the reset of the remaining TTLs on leader
+	// change.
+	// alpha: v3.6
+	LeaseCheckpoint featuregate.Feature = "LeaseCheckpoint"
+	// LeaseCheckpointOnRenew enables the leader to checkpoint the remaining
+	// TTL of a lease on every renewal, requiring LeaseCheckpoint.
+	// alpha: v3.6
+	LeaseCheckpointOnRenew featuregate.Feature = "LeaseCheckpointOnRenew"
+	// LeaseCheckpointPersist persists the remaining TTLs of the leases,
+	// requiring LeaseCheckpoint.
+	// alpha: v3.6
+	LeaseCheckpointPersist featuregate.Feature = "LeaseCheckpointPersist"
+	// LeaseKeyLimit limits the number of keys attached to a lease to
+	// --experimental-max-lease-attached-keys.
+	// alpha: v3.6
+	LeaseKeyLimit featuregate.Feature = "LeaseKeyLimit"
+	// PasswordPolicy enforces the minimum length and character classes of
+	// the passwords of the users.
+	// alpha: v3.6
+	PasswordPolicy featuregate.Feature = "PasswordPolicy"
+	// PrefixStats keeps the statistics of the keys and watchers per prefix.
+	// alpha: v3.6
+	PrefixStats featuregate.Feature = "PrefixStats"
+	// SampledCorruptCheck enables the corruption check passes comparing the
+	// hashes of samples of the revisions only.
+	// alpha: v3.6
+	SampledCorruptCheck featuregate.Feature = "SampledCorruptCheck"
+	// SecondaryIndexes maintains the secondary indexes on the JSON values of
+	// the keys, looked up with the IndexRange RPC.
+	// alpha: v3.6
+	SecondaryIndexes featuregate.Feature = "SecondaryIndexes"
+	// SnapshotHTTP serves the backend snapshots on the '/snapshot' path of
+	// the client URLs.
+	// alpha: v3.6
+	SnapshotHTTP featuregate.Feature = "SnapshotHTTP"
+	// SocketActivation serves the listening sockets passed by systemd.
+	// alpha: v3.6
+	SocketActivation featuregate.Feature = "SocketActivation"
+	// TxnModeWriteWithSharedBuffer enables the write transactions to use a
+	// shared buffer in their read only checks.
+	// beta: v3.6
+	TxnModeWriteWithSharedBuffer featuregate.Feature = "TxnModeWriteWithSharedBuffer"
+	// ValueChunking enables the puts of the values larger than the request
+	// size limit, up to --experimental-max-chunked-value-bytes, uploaded in
+	// parts and stored split into chunks. It is only served once all the
+	// members enable it, and the storage cannot be downgraded to v3.5 while
+	// it holds chunked records.
+	// alpha: v3.6
+	ValueChunking featuregate.Feature = "ValueChunking"
+	// ValueCompression enables the compression of the stored key-value
+	// records configured by --experimental-value-compression. The storage
+	// cannot be downgraded to v3.5 while it holds compressed records.
+	// alpha: v3.6
+	ValueCompression featuregate.Feature = "ValueCompression"
+	// VersionRetention keeps the last versions of the keys of prefixes
+	// through the compactions.
+	// alpha: v3.6
+	VersionRetention featuregate.Feature = "VersionRetention"
+	// WatchBacklogAlarm raises the WATCHBACKLOG alarm once the events held
+	// for the slow watchers reach --experimental-watch-backlog-alarm-events.
+	// alpha: v3.6
+	WatchBacklogAlarm featuregate.Feature = "WatchBacklogAlarm"
+	// WatchRateLimit limits the rate each watcher is sent events at,
+	// queueing them up to an overflow policy.
+	// alpha: v3.6
+	WatchRateLimit featuregate.Feature = "WatchRateLimit"
+	// WatchStartRevisionLimit rejects the watches starting too far behind
+	// the current revision.
+	// alpha: v3.6
+	WatchStartRevisionLimit featuregate.Feature = "WatchStartRevisionLimit"
+)
+
+var (
+	DefaultEtcdServerFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+		AuditLog:                     {Default: false, PreRelease: featuregate.Alpha},
+		Authz:                        {Default: false, PreRelease: featuregate.Alpha},
+		AutoDefrag:                   {Default: false, PreRelease: featuregate.Alpha},
+		BackendEngine:                {Default: false, PreRelease: featuregate.Alpha},
+		BackendQuotaGrowth:           {Default: false, PreRelease: featuregate.Alpha},
+		BoundedStalenessReads:        {Default: true, PreRelease: featuregate.Beta},
+		ChangeFeeds:                  {Default: false, PreRelease: featuregate.Alpha},
+		ClientCertSANRoles:           {Default: false, PreRelease: featuregate.Alpha},
+		ClientListenerGroups:         {Default: false, PreRelease: featuregate.Alpha},
+		CompactHashCheck:             {Default: false, PreRelease: featuregate.Alpha},
+		CompactionPacing:             {Default: true, PreRelease: featuregate.Beta},
+		CompactionRevisionAlignment:  {Default: false, PreRelease: featuregate.Alpha},
+		ConnectionLimits:             {Default: false, PreRelease: featuregate.Alpha},
+		CorruptCheckSchedule:         {Default: false, PreRelease: featuregate.Alpha},
+		GracefulDrain:                {Default: false, PreRelease: featuregate.Alpha},
+		HotKeyTracking:               {Default: false, PreRelease: featuregate.Alpha},
+		IncrementalDefrag:            {Default: false, PreRelease: featuregate.Alpha},
+		InitialCorruptCheck:          {Default: false, PreRelease: featuregate.Alpha},
+		LearnerAutoPromote:           {Default: false, PreRelease: featuregate.Alpha},
+		LeaseCheckpoint:              {Default: false, PreRelease: featuregate.Alpha},
+		LeaseCheckpointOnRenew:       {Default: false, PreRelease: featuregate.Alpha},
+		LeaseCheckpointPersist:       {Default: false, PreRelease: featuregate.Alpha},
+		LeaseKeyLimit:                {Default: false, PreRelease: featuregate.Alpha},
+		PasswordPolicy:               {Default: false, PreRelease: featuregate.Alpha},
+		PrefixStats:                  {Default: false, PreRelease: featuregate.Alpha},
+		SampledCorruptCheck:          {Default: false, PreRelease: featuregate.Alpha},
+		SecondaryIndexes:             {Default: false, PreRelease: featuregate.Alpha},
+		SnapshotHTTP:                 {Default: false, PreRelease: featuregate.Alpha},
+		SocketActivation:             {Default: false, PreRelease: featuregate.Alpha},
+		TxnModeWriteWithSharedBuffer: {Default: true, PreRelease: featuregate.Beta},
+		ValueChunking:                {Default: false, PreRelease: featuregate.Alpha},
+		ValueCompression:             {Default: false, PreRelease: featuregate.Alpha},
+		VersionRetention:             {Default: false, PreRelease: featuregate.Alpha},
+		WatchBacklogAlarm:            {Default: false, PreRelease: featuregate.Alpha},
+		WatchRateLimit:               {Default: false, PreRelease: featuregate.Alpha},
+		WatchStartRevisionLimit:      {Default: false, PreRelease: featuregate.Alpha},
+	}
+	// ExperimentalFlagToFeatureMap maps the experimental flags turning the
+	// features on and off to the feature gates they are consolidated into.
+	// The flags keep setting their features until they are removed.
+	ExperimentalFlagToFeatureMap = map[string]featuregate.Feature{
+		"experimental-compact-hash-check-enabled":        CompactHashCheck,
+		"experimental-enable-lease-checkpoint":           LeaseCheckpoint,
+		"experimental-enable-lease-checkpoint-persist":   LeaseCheckpointPersist,
+		"experimental-enable-snapshot-http":              SnapshotHTTP,
+		"experimental-hot-key-tracking":                  HotKeyTracking,
+		"experimental-incremental-defrag":                IncrementalDefrag,
+		"experimental-initial-corrupt-check":             InitialCorruptCheck,
+		"experimental-lease-checkpoint-on-renew":         LeaseCheckpointOnRenew,
+		"experimental-prefix-stats":                      PrefixStats,
+		"experimental-socket-activation":                 SocketActivation,
+		"experimental-txn-mode-write-with-shared-buffer": TxnModeWriteWithSharedBuffer,
+	}
+	// ExperimentalParamFlagToFeatureMap maps the experimental flags tuning
+	// the features to their feature gates, which must be enabled for the
+	// flags to be set.
+	ExperimentalParamFlagToFeatureMap = map[string]featuregate.Feature{
+		"experimental-audit-log-exclude-prefixes":       AuditLog,
+		"experimental-audit-log-include-prefixes":       AuditLog,
+		"experimental-audit-log-path":                   AuditLog,
+		"experimental-audit-log-rotation-config-json":   AuditLog,
+		"experimental-authz-cache-ttl":                  Authz,
+		"experimental-authz-fail-open":                  Authz,
+		"experimental-authz-webhook-cert-file":          Authz,
+		"experimental-authz-webhook-key-file":           Authz,
+		"experimental-authz-webhook-timeout":            Authz,
+		"experimental-authz-webhook-trusted-ca-file":    Authz,
+		"experimental-authz-webhook-url":                Authz,
+		"experimental-auto-defrag-schedule":             AutoDefrag,
+		"experimental-auto-defrag-threshold":            AutoDefrag,
+		"experimental-backend-engine":                   BackendEngine,
+		"experimental-bounded-staleness-max-lag":        BoundedStalenessReads,
+		"experimental-change-feed-prefixes":             ChangeFeeds,
+		"experimental-change-feed-webhook-url":          ChangeFeeds,
+		"experimental-client-accept-rate":               ConnectionLimits,
+		"experimental-client-cert-san-role-rules":       ClientCertSANRoles,
+		"experimental-client-max-connections":           ConnectionLimits,
+		"experimental-compaction-batch-target-duration": CompactionPacing,
+		"experimental-compaction-min-batch-limit":       CompactionPacing,
+		"experimental-compaction-revision-alignment":    CompactionRevisionAlignment,
+		"experimental-corrupt-check-samples":            SampledCorruptCheck,
+		"experimental-corrupt-check-schedule":           CorruptCheckSchedule,
+		"experimental-corrupt-check-scope":              SampledCorruptCheck,
+		"experimental-drain-timeout":                    GracefulDrain,
+		"experimental-learner-auto-promote-duration":    LearnerAutoPromote,
+		"experimental-learner-auto-promote-lag":         LearnerAutoPromote,
+		"experimental-lease-checkpoint-interval":        LeaseCheckpoint,
+		"experimental-max-chunked-value-bytes":          ValueChunking,
+		"experimental-max-lease-attached-keys":          LeaseKeyLimit,
+		"experimental-max-quota-backend-bytes":          BackendQuotaGrowth,
+		"experimental-password-min-char-classes":        PasswordPolicy,
+		"experimental-password-min-length":              PasswordPolicy,
+		"experimental-peer-accept-rate":                 ConnectionLimits,
+		"experimental-peer-max-connections":             ConnectionLimits,
+		"experimental-secondary-indexes":                SecondaryIndexes,
+		"experimental-value-compression":                ValueCompression,
+		"experimental-value-compression-threshold":      ValueCompression,
+		"experimental-version-retention":                VersionRetention,
+		"experimental-watch-backlog-alarm-events":       WatchBacklogAlarm,
+		"experimental-watch-max-events-per-second":      WatchRateLimit,
+		"experimental-watch-max-queued-events":          WatchRateLimit,
+		"experimental-watch-max-start-revision-age":     WatchStartRevisionLimit,
+		"experimental-watch-max-start-revision-lag":     WatchStartRevisionLimit,
+		"experimental-watch-overflow-policy":            WatchRateLimit,
+	}
+)
+
+// NewDefaultServerFeatureGate returns the feature gate of the etcd server
+// with the default values of its features.
+func NewDefaultServerFeatureGate() featuregate.MutableFeatureGate {
+	fg := featuregate.New("etcd-server")
+	if err := fg.Add(DefaultEtcdServerFeatureGates); err != nil {
+		panic(fmt.Sprintf("failed to add the etcd server feature gates: %v", err))
+	}
+	return fg
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"testing"
+)
+
+func TestExperimentalFlagToFeatureMap(t *testing.T) {
+	fg := NewDefaultServerFeatureGate()
+	for flag, feature := range ExperimentalFlagToFeatureMap {
+		if _, ok := DefaultEtcdServerFeatureGates[feature]; !ok {
+			t.Errorf("--%s maps to the unknown feature %s", flag, feature)
+			continue
+		}
+		if fg.IsSet(feature) {
+			t.Errorf("feature %s is set on the default feature gate", feature)
+		}
+	}
+}
+
+func TestExperimentalParamFlagToFeatureMap(t *testing.T) {
+	for flag, feature := range ExperimentalParamFlagToFeatureMap {
+		if _, ok := DefaultEtcdServerFeatureGates[feature]; !ok {
+			t.Errorf("--%s maps to the unknown feature %s", flag, feature)
+		}
+		if _, ok := ExperimentalFlagToFeatureMap[flag]; ok {
+			t.Errorf("--%s both turns on and off and tunes the feature %s", flag, feature)
+		}
+	}
+}
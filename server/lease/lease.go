@@ -16,9 +16,11 @@ package lease
 
 import (
 	"math"
+	"sort"
 	"sync"
 	"time"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/server/v3/lease/leasepb"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/schema"
@@ -26,8 +28,10 @@ import (
 
 type Lease struct {
 	ID           LeaseID
-	ttl          int64 // time to live of the lease in seconds
-	remainingTTL int64 // remaining time to live in seconds, if zero valued it is considered unset and the full ttl should be used
+	ttl          int64            // time to live of the lease in seconds
+	parent       LeaseID          // the lease is revoked along with its parent, NoLease if none
+	labels       []*pb.LeaseLabel // sorted by key, immutable
+	remainingTTL int64            // remaining time to live in seconds, if zero valued it is considered unset and the full ttl should be used
 	// expiryMu protects concurrent accesses to expiry
 	expiryMu sync.RWMutex
 	// expiry is time when lease should expire. no expiration when expiry.IsZero() is true
@@ -37,6 +41,18 @@ type Lease struct {
 	mu      sync.RWMutex
 	itemSet map[LeaseItem]struct{}
 	revokec chan struct{}
+
+	// children are the leases revoked along with the lease, guarded by the
+	// mutex of the lessor.
+	children map[LeaseID]struct{}
+
+	// statsMu protects concurrent accesses to the grantor and the renewal
+	// statistics, kept by the primary lessor only
+	statsMu        sync.Mutex
+	grantor        string // the user who granted the lease, empty if unauthenticated
+	renewals       int64
+	lastRenewTime  time.Time
+	statsStartTime time.Time
 }
 
 func (l *Lease) expired() bool {
@@ -44,7 +60,7 @@ func (l *Lease) expired() bool {
 }
 
 func (l *Lease) persistTo(b backend.Backend) {
-	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL}
+	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL, ParentID: int64(l.parent), Labels: l.labels, Grantor: l.Grantor()}
 	tx := b.BatchTx()
 	tx.LockInsideApply()
 	defer tx.Unlock()
@@ -56,6 +72,124 @@ func (l *Lease) TTL() int64 {
 	return l.ttl
 }
 
+// Parent returns the ID of the parent lease, NoLease if none.
+func (l *Lease) Parent() LeaseID {
+	return l.parent
+}
+
+// Labels returns the labels of the lease, sorted by key. They must not be
+// modified.
+func (l *Lease) Labels() []*pb.LeaseLabel {
+	return l.labels
+}
+
+// Grantor returns the name of the user who granted the lease, empty if it was
+// granted without authentication.
+func (l *Lease) Grantor() string {
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	return l.grantor
+}
+
+// Stats returns the statistics of the lease. The renewal statistics are only
+// kept by the primary lessor, since it was promoted or granted the lease.
+func (l *Lease) Stats() *pb.LeaseStats {
+	l.mu.RLock()
+	attached := len(l.itemSet)
+	l.mu.RUnlock()
+
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	st := &pb.LeaseStats{Grantor: l.grantor, AttachedKeys: int64(attached), Renewals: l.renewals}
+	if !l.lastRenewTime.IsZero() {
+		st.LastRenewTime = l.lastRenewTime.UnixNano()
+	}
+	if !l.statsStartTime.IsZero() {
+		st.StatsStartTime = l.statsStartTime.UnixNano()
+		if elapsed := time.Since(l.statsStartTime).Seconds(); elapsed > 0 {
+			st.RenewRate = float64(l.renewals) / elapsed
+		}
+	}
+	return st
+}
+
+// resetStats starts keeping the renewal statistics of the lease anew.
+func (l *Lease) resetStats() {
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	l.renewals = 0
+	l.lastRenewTime = time.Time{}
+	l.statsStartTime = time.Now()
+}
+
+// renewed records a renewal of the lease, returning the time elapsed since
+// the previous renewal, or since the statistics were reset if none.
+func (l *Lease) renewed() time.Duration {
+	now := time.Now()
+	l.statsMu.Lock()
+	defer l.statsMu.Unlock()
+	last := l.lastRenewTime
+	if last.IsZero() {
+		last = l.statsStartTime
+	}
+	l.renewals++
+	l.lastRenewTime = now
+	return now.Sub(last)
+}
+
+// HasLabels returns true if the lease carries all the given labels.
+func (l *Lease) HasLabels(labels []*pb.LeaseLabel) bool {
+	for _, label := range labels {
+		i := sort.Search(len(l.labels), func(i int) bool { return l.labels[i].Key >= label.Key })
+		if i == len(l.labels) || l.labels[i].Key != label.Key || l.labels[i].Value != label.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedLabels validates the labels of a lease and returns a copy of them
+// sorted by key.
+func sortedLabels(labels []*pb.LeaseLabel) ([]*pb.LeaseLabel, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	if len(labels) > MaxLeaseLabels {
+		return nil, ErrInvalidLeaseLabels
+	}
+	sorted := make([]*pb.LeaseLabel, len(labels))
+	for i, label := range labels {
+		if label.Key == "" || len(label.Key)+len(label.Value) > MaxLeaseLabelSize {
+			return nil, ErrInvalidLeaseLabels
+		}
+		sorted[i] = &pb.LeaseLabel{Key: label.Key, Value: label.Value}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Key == sorted[i-1].Key {
+			return nil, ErrInvalidLeaseLabels
+		}
+	}
+	return sorted, nil
+}
+
+func (l *Lease) unsafeAddChild(id LeaseID) {
+	if l.children == nil {
+		l.children = make(map[LeaseID]struct{})
+	}
+	l.children[id] = struct{}{}
+}
+
+// unsafeChildren returns the IDs of the children of the lease, in order.
+func (l *Lease) unsafeChildren() []LeaseID {
+	ids := make([]LeaseID, 0, len(l.children))
+	for id := range l.children {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 // RemainingTTL returns the last checkpointed remaining TTL of the lease.
 func (l *Lease) getRemainingTTL() int64 {
 	if l.remainingTTL > 0 {
@@ -90,6 +224,13 @@ func (l *Lease) Keys() []string {
 	return keys
 }
 
+// AttachedKeys returns the number of keys attached to the lease.
+func (l *Lease) AttachedKeys() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.itemSet)
+}
+
 // Remaining returns the remaining time of the lease.
 func (l *Lease) Remaining() time.Duration {
 	l.expiryMu.RLock()
@@ -115,6 +115,8 @@ func (h *leaseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				ID:         lreq.LeaseTimeToLiveRequest.ID,
 				TTL:        int64(l.Remaining().Seconds()),
 				GrantedTTL: l.TTL(),
+				ParentID:   int64(l.Parent()),
+				Labels:     l.Labels(),
 			},
 		}
 		if lreq.LeaseTimeToLiveRequest.Keys {
@@ -125,6 +127,9 @@ func (h *leaseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 			resp.LeaseTimeToLiveResponse.Keys = kbs
 		}
+		if lreq.LeaseTimeToLiveRequest.Stats {
+			resp.LeaseTimeToLiveResponse.Stats = l.Stats()
+		}
 
 		v, err = resp.Marshal()
 		if err != nil {
@@ -189,15 +194,10 @@ func RenewHTTP(ctx context.Context, id lease.LeaseID, url string, rt http.RoundT
 	return lresp.TTL, nil
 }
 
-// TimeToLiveHTTP retrieves lease information of the given lease ID.
-func TimeToLiveHTTP(ctx context.Context, id lease.LeaseID, keys bool, url string, rt http.RoundTripper) (*leasepb.LeaseInternalResponse, error) {
+// TimeToLiveHTTP retrieves lease information of the given lease.
+func TimeToLiveHTTP(ctx context.Context, r *pb.LeaseTimeToLiveRequest, url string, rt http.RoundTripper) (*leasepb.LeaseInternalResponse, error) {
 	// will post lreq protobuf to leader
-	lreq, err := (&leasepb.LeaseInternalRequest{
-		LeaseTimeToLiveRequest: &pb.LeaseTimeToLiveRequest{
-			ID:   int64(id),
-			Keys: keys,
-		},
-	}).Marshal()
+	lreq, err := (&leasepb.LeaseInternalRequest{LeaseTimeToLiveRequest: r}).Marshal()
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +235,7 @@ func TimeToLiveHTTP(ctx context.Context, id lease.LeaseID, keys bool, url string
 	if err := lresp.Unmarshal(b); err != nil {
 		return nil, fmt.Errorf(`lease: %v. data = "%s"`, err, string(b))
 	}
-	if lresp.LeaseTimeToLiveResponse.ID != int64(id) {
+	if lresp.LeaseTimeToLiveResponse.ID != r.ID {
 		return nil, fmt.Errorf("lease: TTL id mismatch")
 	}
 	return lresp, nil
@@ -23,6 +23,7 @@ import (
 
 	"go.uber.org/zap/zaptest"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/server/v3/lease"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 )
@@ -66,7 +67,11 @@ func TestTimeToLiveHTTP(t *testing.T) {
 	ts := httptest.NewServer(NewHandler(le, waitReady))
 	defer ts.Close()
 
-	resp, err := TimeToLiveHTTP(context.TODO(), l.ID, true, ts.URL+LeaseInternalPrefix, http.DefaultTransport)
+	if _, err = le.Renew(l.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := TimeToLiveHTTP(context.TODO(), &pb.LeaseTimeToLiveRequest{ID: int64(l.ID), Keys: true, Stats: true}, ts.URL+LeaseInternalPrefix, http.DefaultTransport)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -76,6 +81,9 @@ func TestTimeToLiveHTTP(t *testing.T) {
 	if resp.LeaseTimeToLiveResponse.GrantedTTL != 5 {
 		t.Fatalf("granted TTL expected 5, got %d", resp.LeaseTimeToLiveResponse.GrantedTTL)
 	}
+	if st := resp.LeaseTimeToLiveResponse.Stats; st == nil || st.Renewals != 1 || st.LastRenewTime == 0 {
+		t.Fatalf("stats expected 1 renewal, got %+v", st)
+	}
 }
 
 func TestRenewHTTPTimeout(t *testing.T) {
@@ -87,7 +95,7 @@ func TestRenewHTTPTimeout(t *testing.T) {
 
 func TestTimeToLiveHTTPTimeout(t *testing.T) {
 	testApplyTimeout(t, func(l *lease.Lease, serverURL string) error {
-		_, err := TimeToLiveHTTP(context.TODO(), l.ID, true, serverURL+LeaseInternalPrefix, http.DefaultTransport)
+		_, err := TimeToLiveHTTP(context.TODO(), &pb.LeaseTimeToLiveRequest{ID: int64(l.ID), Keys: true}, serverURL+LeaseInternalPrefix, http.DefaultTransport)
 		return err
 	})
 }
@@ -39,6 +39,14 @@ const NoLease = LeaseID(0)
 // MaxLeaseTTL is the maximum lease TTL value
 const MaxLeaseTTL = 9000000000
 
+const (
+	// MaxLeaseLabels is the maximum number of labels of a lease.
+	MaxLeaseLabels = 16
+	// MaxLeaseLabelSize is the maximum size of the key and value of a lease
+	// label, in bytes.
+	MaxLeaseLabelSize = 256
+)
+
 var (
 	forever = time.Time{}
 
@@ -57,10 +65,14 @@ var (
 	// the default interval to check if the expired lease is revoked
 	defaultExpiredleaseRetryInterval = 3 * time.Second
 
-	ErrNotPrimary       = errors.New("not a primary lessor")
-	ErrLeaseNotFound    = errors.New("lease not found")
-	ErrLeaseExists      = errors.New("lease already exists")
-	ErrLeaseTTLTooLarge = errors.New("too large lease TTL")
+	ErrNotPrimary          = errors.New("not a primary lessor")
+	ErrLeaseNotFound       = errors.New("lease not found")
+	ErrLeaseExists         = errors.New("lease already exists")
+	ErrLeaseTTLTooLarge    = errors.New("too large lease TTL")
+	ErrParentLeaseNotFound = errors.New("parent lease not found")
+	ErrInvalidLeaseLabels  = errors.New("invalid lease labels")
+
+	ErrLeaseTooManyAttachedKeys = errors.New("too many keys attached to lease")
 )
 
 // TxnDelete is a TxnWrite that only permits deletes. Defined here
@@ -90,10 +102,24 @@ type Lessor interface {
 
 	// Grant grants a lease that expires at least after TTL seconds.
 	Grant(id LeaseID, ttl int64) (*Lease, error)
-	// Revoke revokes a lease with given ID. The item attached to the
-	// given lease will be removed. If the ID does not exist, an error
-	// will be returned.
+	// GrantWithParent grants a lease like Grant, as a child of the given
+	// parent lease, which must exist. The child is revoked along with its
+	// parent.
+	GrantWithParent(id, parent LeaseID, ttl int64) (*Lease, error)
+	// GrantWithLabels grants a lease like GrantWithParent, carrying the given
+	// labels. There may be at most MaxLeaseLabels labels with distinct,
+	// non-empty keys, each at most MaxLeaseLabelSize bytes long.
+	GrantWithLabels(id, parent LeaseID, ttl int64, labels []*pb.LeaseLabel) (*Lease, error)
+	// Revoke revokes a lease with given ID, along with its children. The
+	// items attached to the revoked leases will be removed. If the ID does
+	// not exist, an error will be returned.
 	Revoke(id LeaseID) error
+	// Expire revokes an expired lease like Revoke, reporting it and its
+	// children as expired to the watchers of the revoked leases.
+	Expire(id LeaseID) error
+	// SetGrantor records the name of the user who granted the lease,
+	// persisting it along with the lease.
+	SetGrantor(id LeaseID, grantor string) error
 
 	// Checkpoint applies the remainingTTL of a lease. The remainingTTL is used in Promote to set
 	// the expiry of leases to less than the full TTL when possible.
@@ -103,6 +129,14 @@ type Lessor interface {
 	// If the lease does not exist, an error will be returned.
 	Attach(id LeaseID, items []LeaseItem) error
 
+	// CheckAttach checks that the given items can be attached to the lease with
+	// given LeaseID. It returns ErrLeaseTooManyAttachedKeys if attaching the items
+	// that are not attached yet takes the lease past the maximum number of attached
+	// keys. The unknown leases are left for the apply of the request to report.
+	// The check runs before the request is proposed, so that the apply does not
+	// depend on the limit of each member.
+	CheckAttach(id LeaseID, items []LeaseItem) error
+
 	// GetLease returns LeaseID for given item.
 	// If no lease found, NoLease value will be returned.
 	GetLease(item LeaseItem) LeaseID
@@ -132,6 +166,11 @@ type Lessor interface {
 	// ExpiredLeasesC returns a chan that is used to receive expired leases.
 	ExpiredLeasesC() <-chan []*Lease
 
+	// WatchRevoked returns a chan receiving the leases revoked from now on,
+	// expired leases included, and a func to stop watching. The chan is
+	// closed if the receiver falls too far behind, or when the lessor stops.
+	WatchRevoked() (<-chan RevokedLease, func())
+
 	// Recover recovers the lessor state from the given backend and RangeDeleter.
 	Recover(b backend.Backend, rd RangeDeleter)
 
@@ -171,6 +210,8 @@ type lessor struct {
 	minLeaseTTL int64
 
 	expiredC chan []*Lease
+	// revokeNotifier broadcasts the revoked leases to their watchers.
+	revokeNotifier *revokeNotifier
 	// stopC is a channel whose closure indicates that the lessor should be stopped.
 	stopC chan struct{}
 	// doneC is a channel whose closure indicates that the lessor is stopped.
@@ -184,6 +225,11 @@ type lessor struct {
 	expiredLeaseRetryInterval time.Duration
 	// whether lessor should always persist remaining TTL (always enabled in v3.6).
 	checkpointPersist bool
+	// whether the primary lessor checkpoints every renewal.
+	checkpointOnRenew bool
+	// maxAttachedKeys is the maximum number of keys attached to a single
+	// lease. 0 means unlimited.
+	maxAttachedKeys int
 	// cluster is used to adapt lessor logic based on cluster version
 	cluster cluster
 }
@@ -198,6 +244,12 @@ type LessorConfig struct {
 	CheckpointInterval         time.Duration
 	ExpiredLeasesRetryInterval time.Duration
 	CheckpointPersist          bool
+	// CheckpointOnRenew checkpoints the remaining TTL of a lease on every
+	// renewal, besides every CheckpointInterval.
+	CheckpointOnRenew bool
+	// MaxAttachedKeys is the maximum number of keys attached to a single
+	// lease, checked before a key is put with the lease. 0 means unlimited.
+	MaxAttachedKeys int
 }
 
 func NewLessor(lg *zap.Logger, b backend.Backend, cluster cluster, cfg LessorConfig) Lessor {
@@ -223,12 +275,15 @@ func newLessor(lg *zap.Logger, b backend.Backend, cluster cluster, cfg LessorCon
 		checkpointInterval:        checkpointInterval,
 		expiredLeaseRetryInterval: expiredLeaseRetryInterval,
 		checkpointPersist:         cfg.CheckpointPersist,
+		checkpointOnRenew:         cfg.CheckpointOnRenew,
+		maxAttachedKeys:           cfg.MaxAttachedKeys,
 		// expiredC is a small buffered chan to avoid unnecessary blocking.
-		expiredC: make(chan []*Lease, 16),
-		stopC:    make(chan struct{}),
-		doneC:    make(chan struct{}),
-		lg:       lg,
-		cluster:  cluster,
+		expiredC:       make(chan []*Lease, 16),
+		revokeNotifier: newRevokeNotifier(),
+		stopC:          make(chan struct{}),
+		doneC:          make(chan struct{}),
+		lg:             lg,
+		cluster:        cluster,
 	}
 	l.initAndRecover()
 
@@ -269,6 +324,14 @@ func (le *lessor) SetCheckpointer(cp Checkpointer) {
 }
 
 func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
+	return le.GrantWithParent(id, NoLease, ttl)
+}
+
+func (le *lessor) GrantWithParent(id, parent LeaseID, ttl int64) (*Lease, error) {
+	return le.GrantWithLabels(id, parent, ttl, nil)
+}
+
+func (le *lessor) GrantWithLabels(id, parent LeaseID, ttl int64, labels []*pb.LeaseLabel) (*Lease, error) {
 	if id == NoLease {
 		return nil, ErrLeaseNotFound
 	}
@@ -277,11 +340,18 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 		return nil, ErrLeaseTTLTooLarge
 	}
 
+	labels, err := sortedLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO: when lessor is under high load, it should give out lease
 	// with longer TTL to reduce renew load.
 	l := &Lease{
 		ID:      id,
 		ttl:     ttl,
+		parent:  parent,
+		labels:  labels,
 		itemSet: make(map[LeaseItem]struct{}),
 		revokec: make(chan struct{}),
 	}
@@ -296,9 +366,17 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 	if _, ok := le.leaseMap[id]; ok {
 		return nil, ErrLeaseExists
 	}
+	if parent != NoLease {
+		p, ok := le.leaseMap[parent]
+		if !ok {
+			return nil, ErrParentLeaseNotFound
+		}
+		p.unsafeAddChild(id)
+	}
 
 	if le.isPrimary() {
 		l.refresh(0)
+		l.resetStats()
 	} else {
 		l.forever()
 	}
@@ -318,7 +396,30 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 	return l, nil
 }
 
+func (le *lessor) SetGrantor(id LeaseID, grantor string) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return ErrLeaseNotFound
+	}
+	l.statsMu.Lock()
+	l.grantor = grantor
+	l.statsMu.Unlock()
+	l.persistTo(le.b)
+	return nil
+}
+
 func (le *lessor) Revoke(id LeaseID) error {
+	return le.revoke(id, false)
+}
+
+func (le *lessor) Expire(id LeaseID) error {
+	return le.revoke(id, true)
+}
+
+func (le *lessor) revoke(id LeaseID, expired bool) error {
 	le.mu.Lock()
 
 	l := le.leaseMap[id]
@@ -327,12 +428,16 @@ func (le *lessor) Revoke(id LeaseID) error {
 		return ErrLeaseNotFound
 	}
 
-	// We shouldn't delete the lease inside the transaction lock, otherwise
+	// We shouldn't delete the leases inside the transaction lock, otherwise
 	// it may lead to deadlock with Grant or Checkpoint operations, which
 	// acquire the le.mu firstly and then the batchTx lock.
-	delete(le.leaseMap, id)
+	ls := le.unsafeRemoveTree(l)
 
-	defer close(l.revokec)
+	defer func() {
+		for _, l := range ls {
+			close(l.revokec)
+		}
+	}()
 	// unlock before doing external work
 	le.mu.Unlock()
 
@@ -342,25 +447,59 @@ func (le *lessor) Revoke(id LeaseID) error {
 
 	txn := le.rd()
 
-	// sort keys so deletes are in same order among all members,
-	// otherwise the backend hashes will be different
-	keys := l.Keys()
-	sort.StringSlice(keys).Sort()
-	for _, key := range keys {
-		txn.DeleteRange([]byte(key), nil)
-	}
+	revoked := make([]RevokedLease, 0, len(ls))
+	for _, l := range ls {
+		// sort keys so deletes are in same order among all members,
+		// otherwise the backend hashes will be different
+		keys := l.Keys()
+		sort.StringSlice(keys).Sort()
+		for _, key := range keys {
+			txn.DeleteRange([]byte(key), nil)
+		}
 
-	// lease deletion needs to be in the same backend transaction with the
-	// kv deletion. Or we might end up with not executing the revoke or not
-	// deleting the keys if etcdserver fails in between.
-	schema.UnsafeDeleteLease(le.b.BatchTx(), &leasepb.Lease{ID: int64(l.ID)})
+		// lease deletion needs to be in the same backend transaction with the
+		// kv deletion. Or we might end up with not executing the revoke or not
+		// deleting the keys if etcdserver fails in between.
+		schema.UnsafeDeleteLease(le.b.BatchTx(), &leasepb.Lease{ID: int64(l.ID)})
+		revoked = append(revoked, RevokedLease{
+			ID:      l.ID,
+			TTL:     l.ttl,
+			Keys:    keys,
+			Expired: expired,
+			Parent:  l.parent,
+			Labels:  l.labels,
+		})
+	}
 
 	txn.End()
 
-	leaseRevoked.Inc()
+	for _, rl := range revoked {
+		le.revokeNotifier.notify(rl)
+		leaseRevoked.Inc()
+		leaseRevokedAttachedKeys.Observe(float64(len(rl.Keys)))
+	}
 	return nil
 }
 
+// unsafeRemoveTree removes the lease and its descendants from the lessor.
+// It returns them parents first, the children of every lease in the order
+// of their IDs, so that all members revoke them in the same order.
+func (le *lessor) unsafeRemoveTree(l *Lease) []*Lease {
+	if p, ok := le.leaseMap[l.parent]; ok {
+		delete(p.children, l.ID)
+	}
+	ls := []*Lease{l}
+	for i := 0; i < len(ls); i++ {
+		delete(le.leaseMap, ls[i].ID)
+		for _, id := range ls[i].unsafeChildren() {
+			if c, ok := le.leaseMap[id]; ok {
+				ls = append(ls, c)
+			}
+		}
+	}
+	return ls
+}
+
 func (le *lessor) Checkpoint(id LeaseID, remainingTTL int64) error {
 	le.mu.Lock()
 	defer le.mu.Unlock()
@@ -405,8 +544,8 @@ func (le *lessor) Renew(id LeaseID) (int64, error) {
 		le.mu.RUnlock()
 		return -1, ErrLeaseNotFound
 	}
-	// Clear remaining TTL when we renew if it is set
-	clearRemainingTTL := le.cp != nil && l.remainingTTL > 0
+	// Clear remaining TTL when we renew if it is set, or checkpoint every renewal
+	checkpoint := le.cp != nil && (le.checkpointOnRenew || l.remainingTTL > 0)
 
 	le.mu.RUnlock()
 	if l.expired() {
@@ -428,8 +567,14 @@ func (le *lessor) Renew(id LeaseID) (int64, error) {
 	// Clear remaining TTL when we renew if it is set
 	// By applying a RAFT entry only when the remainingTTL is already set, we limit the number
 	// of RAFT entries written per lease to a max of 2 per checkpoint interval.
-	if clearRemainingTTL {
-		le.cp(context.Background(), &pb.LeaseCheckpointRequest{Checkpoints: []*pb.LeaseCheckpoint{{ID: int64(l.ID), Remaining_TTL: 0}}})
+	// Checkpointing every renewal instead records and persists the renewed TTL on all members
+	// at the cost of a RAFT entry per renewal.
+	if checkpoint {
+		remainingTTL := int64(0)
+		if le.checkpointOnRenew {
+			remainingTTL = l.ttl
+		}
+		le.cp(context.Background(), &pb.LeaseCheckpointRequest{Checkpoints: []*pb.LeaseCheckpoint{{ID: int64(l.ID), Remaining_TTL: remainingTTL}}})
 	}
 
 	le.mu.Lock()
@@ -439,6 +584,7 @@ func (le *lessor) Renew(id LeaseID) (int64, error) {
 	le.mu.Unlock()
 
 	leaseRenewed.Inc()
+	leaseRenewInterval.Observe(l.renewed().Seconds())
 	return l.ttl, nil
 }
 
@@ -473,6 +619,7 @@ func (le *lessor) Promote(extend time.Duration) {
 	// refresh the expiries of all leases.
 	for _, l := range le.leaseMap {
 		l.refresh(extend)
+		l.resetStats()
 		item := &LeaseWithTime{id: l.ID, time: l.expiry}
 		le.leaseExpiredNotifier.RegisterOrUpdate(item)
 		le.scheduleCheckpointIfNeeded(l)
@@ -557,6 +704,33 @@ func (le *lessor) Attach(id LeaseID, items []LeaseItem) error {
 	return nil
 }
 
+func (le *lessor) CheckAttach(id LeaseID, items []LeaseItem) error {
+	if le.maxAttachedKeys == 0 {
+		return nil
+	}
+	le.mu.RLock()
+	defer le.mu.RUnlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	added := make(map[LeaseItem]struct{})
+	for _, it := range items {
+		if _, ok := l.itemSet[it]; !ok {
+			added[it] = struct{}{}
+		}
+	}
+	if len(added) == 0 || len(l.itemSet)+len(added) <= le.maxAttachedKeys {
+		return nil
+	}
+	leaseAttachRejected.Inc()
+	return ErrLeaseTooManyAttachedKeys
+}
+
 func (le *lessor) GetLease(item LeaseItem) LeaseID {
 	le.mu.RLock()
 	id := le.itemMap[item]
@@ -599,9 +773,14 @@ func (le *lessor) ExpiredLeasesC() <-chan []*Lease {
 	return le.expiredC
 }
 
+func (le *lessor) WatchRevoked() (<-chan RevokedLease, func()) {
+	return le.revokeNotifier.watch()
+}
+
 func (le *lessor) Stop() {
 	close(le.stopC)
 	<-le.doneC
+	le.revokeNotifier.stop()
 }
 
 func (le *lessor) runLoop() {
@@ -803,8 +982,11 @@ func (le *lessor) initAndRecover() {
 			lpb.TTL = le.minLeaseTTL
 		}
 		le.leaseMap[ID] = &Lease{
-			ID:  ID,
-			ttl: lpb.TTL,
+			ID:      ID,
+			ttl:     lpb.TTL,
+			parent:  LeaseID(lpb.ParentID),
+			labels:  lpb.Labels,
+			grantor: lpb.Grantor,
 			// itemSet will be filled in when recover key-value pairs
 			// set expiry to forever, refresh when promoted
 			itemSet:      make(map[LeaseItem]struct{}),
@@ -813,6 +995,12 @@ func (le *lessor) initAndRecover() {
 			remainingTTL: lpb.RemainingTTL,
 		}
 	}
+	// the parents are not necessarily read before their children
+	for _, l := range le.leaseMap {
+		if p, ok := le.leaseMap[l.parent]; ok {
+			p.unsafeAddChild(l.ID)
+		}
+	}
 	le.leaseExpiredNotifier.Init()
 	heap.Init(&le.leaseCheckpointHeap)
 
@@ -829,12 +1017,26 @@ func (fl *FakeLessor) SetCheckpointer(cp Checkpointer) {}
 
 func (fl *FakeLessor) Grant(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
 
+func (fl *FakeLessor) GrantWithParent(id, parent LeaseID, ttl int64) (*Lease, error) {
+	return nil, nil
+}
+
+func (fl *FakeLessor) GrantWithLabels(id, parent LeaseID, ttl int64, labels []*pb.LeaseLabel) (*Lease, error) {
+	return nil, nil
+}
+
 func (fl *FakeLessor) Revoke(id LeaseID) error { return nil }
 
+func (fl *FakeLessor) Expire(id LeaseID) error { return nil }
+
+func (fl *FakeLessor) SetGrantor(id LeaseID, grantor string) error { return nil }
+
 func (fl *FakeLessor) Checkpoint(id LeaseID, remainingTTL int64) error { return nil }
 
 func (fl *FakeLessor) Attach(id LeaseID, items []LeaseItem) error { return nil }
 
+func (fl *FakeLessor) CheckAttach(id LeaseID, items []LeaseItem) error { return nil }
+
 func (fl *FakeLessor) GetLease(item LeaseItem) LeaseID            { return 0 }
 func (fl *FakeLessor) Detach(id LeaseID, items []LeaseItem) error { return nil }
 
@@ -850,6 +1052,8 @@ func (fl *FakeLessor) Leases() []*Lease { return nil }
 
 func (fl *FakeLessor) ExpiredLeasesC() <-chan []*Lease { return nil }
 
+func (fl *FakeLessor) WatchRevoked() (<-chan RevokedLease, func()) { return nil, func() {} }
+
 func (fl *FakeLessor) Recover(b backend.Backend, rd RangeDeleter) {}
 
 func (fl *FakeLessor) Stop() {}
@@ -16,11 +16,13 @@ package lease
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -100,7 +102,7 @@ func TestLessorGrant(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb == nil {
-		t.Errorf("lpb = %d, want not nil", lpb)
+		t.Errorf("lpb = %v, want not nil", lpb)
 	}
 }
 
@@ -204,7 +206,166 @@ func TestLessorRevoke(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb != nil {
-		t.Errorf("lpb = %d, want nil", lpb)
+		t.Errorf("lpb = %v, want nil", lpb)
+	}
+}
+
+// TestLessorRevokeChildren ensures that revoking a lease revokes its children
+// and their children, but not its parent.
+func TestLessorRevokeChildren(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	var fd *fakeDeleter
+	le.SetRangeDeleter(func() TxnDelete {
+		fd = newFakeDeleter(be)
+		return fd
+	})
+
+	if _, err := le.GrantWithParent(2, 1, 100); err != ErrParentLeaseNotFound {
+		t.Fatalf("err = %v, want %v", err, ErrParentLeaseNotFound)
+	}
+	for _, l := range []struct{ id, parent LeaseID }{{1, NoLease}, {2, 1}, {3, 2}, {4, 2}} {
+		if _, err := le.GrantWithParent(l.id, l.parent, 100); err != nil {
+			t.Fatalf("could not grant lease %x (%v)", l.id, err)
+		}
+		if err := le.Attach(l.id, []LeaseItem{{fmt.Sprintf("foo%d", l.id)}}); err != nil {
+			t.Fatalf("failed to attach items to the lease: %v", err)
+		}
+	}
+	if p := le.Lookup(3).Parent(); p != 2 {
+		t.Errorf("parent = %x, want %x", p, 2)
+	}
+
+	if err := le.Revoke(2); err != nil {
+		t.Fatal("failed to revoke lease:", err)
+	}
+	for _, id := range []LeaseID{2, 3, 4} {
+		if le.Lookup(id) != nil {
+			t.Errorf("got revoked lease %x", id)
+		}
+	}
+	if le.Lookup(1) == nil {
+		t.Fatalf("parent lease %x was revoked", 1)
+	}
+	wdeleted := []string{"foo2_", "foo3_", "foo4_"}
+	if !reflect.DeepEqual(fd.deleted, wdeleted) {
+		t.Errorf("deleted= %v, want %v", fd.deleted, wdeleted)
+	}
+
+	// the revoked child is no longer revoked along with its parent
+	if err := le.Revoke(1); err != nil {
+		t.Fatal("failed to revoke lease:", err)
+	}
+	if wdeleted = []string{"foo1_"}; !reflect.DeepEqual(fd.deleted, wdeleted) {
+		t.Errorf("deleted= %v, want %v", fd.deleted, wdeleted)
+	}
+}
+
+// TestLessorWatchRevoked ensures the watchers receive the revoked leases,
+// and that a watcher falling behind is dropped.
+func TestLessorWatchRevoked(t *testing.T) {
+	defer func(size int) { revokedLeaseBufferSize = size }(revokedLeaseBufferSize)
+	revokedLeaseBufferSize = 2
+
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	le.SetRangeDeleter(func() TxnDelete { return newFakeDeleter(be) })
+
+	fastc, stopFast := le.WatchRevoked()
+	defer stopFast()
+	slowc, stopSlow := le.WatchRevoked()
+	defer stopSlow()
+
+	for id := LeaseID(1); id <= 3; id++ {
+		if _, err := le.Grant(id, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := le.Attach(1, []LeaseItem{{"foo"}, {"bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	for id := LeaseID(1); id <= 3; id++ {
+		if err := le.Revoke(id); err != nil {
+			t.Fatal(err)
+		}
+		rl := <-fastc
+		if rl.ID != id || rl.TTL != 100 {
+			t.Errorf("revoked lease = %+v, want ID %d and TTL 100", rl, id)
+		}
+		if id == 1 && !reflect.DeepEqual(rl.Keys, []string{"bar", "foo"}) {
+			t.Errorf("revoked keys = %v, want [bar foo]", rl.Keys)
+		}
+	}
+
+	// the slow watcher got the first leases, then its chan was closed
+	var ids []LeaseID
+	for rl := range slowc {
+		ids = append(ids, rl.ID)
+	}
+	if !reflect.DeepEqual(ids, []LeaseID{1, 2}) {
+		t.Errorf("slow watcher got %v, want [1 2]", ids)
+	}
+
+	le.Stop()
+	if _, ok := <-fastc; ok {
+		t.Error("expected the watch chan to be closed by Stop")
+	}
+}
+
+// TestLessorExpireReportsExpired ensures the leases revoked by Expire,
+// children included, are reported as expired along with their grant
+// metadata, unlike the leases revoked by Revoke.
+func TestLessorExpireReportsExpired(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.SetRangeDeleter(func() TxnDelete { return newFakeDeleter(be) })
+
+	revokedc, stop := le.WatchRevoked()
+	defer stop()
+
+	labels := []*pb.LeaseLabel{{Key: "app", Value: "foo"}}
+	if _, err := le.GrantWithLabels(1, NoLease, 100, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := le.GrantWithLabels(2, 1, 100, labels); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := le.Grant(3, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := le.Expire(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := le.Revoke(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := le.Expire(3); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+
+	want := []RevokedLease{
+		{ID: 1, TTL: 100, Keys: []string{}, Expired: true},
+		{ID: 2, TTL: 100, Keys: []string{}, Expired: true, Parent: 1, Labels: labels},
+		{ID: 3, TTL: 100, Keys: []string{}},
+	}
+	for i := range want {
+		if rl := <-revokedc; !reflect.DeepEqual(rl, want[i]) {
+			t.Errorf("#%d: revoked lease = %+v, want %+v", i, rl, want[i])
+		}
 	}
 }
 
@@ -305,6 +466,41 @@ func TestLessorRenewWithCheckpointer(t *testing.T) {
 
 // TestLessorRenewExtendPileup ensures Lessor extends leases on promotion if too many
 // expire at the same time.
+// TestLessorRenewCheckpointOnRenew ensures that every renewal is checkpointed
+// with the renewed TTL when checkpointing on renewal.
+func TestLessorRenewCheckpointOnRenew(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL, CheckpointOnRenew: true})
+	defer le.Stop()
+	var checkpoints []*pb.LeaseCheckpoint
+	le.SetCheckpointer(func(ctx context.Context, cp *pb.LeaseCheckpointRequest) {
+		for _, cp := range cp.GetCheckpoints() {
+			checkpoints = append(checkpoints, cp)
+			le.Checkpoint(LeaseID(cp.GetID()), cp.GetRemaining_TTL())
+		}
+	})
+	le.Promote(0)
+
+	l, err := le.Grant(1, 10)
+	if err != nil {
+		t.Fatalf("failed to grant lease (%v)", err)
+	}
+	renew(t, le, l.ID)
+	renew(t, le, l.ID)
+
+	wcheckpoints := []*pb.LeaseCheckpoint{{ID: 1, Remaining_TTL: 10}, {ID: 1, Remaining_TTL: 10}}
+	if !reflect.DeepEqual(checkpoints, wcheckpoints) {
+		t.Errorf("checkpoints = %v, want %v", checkpoints, wcheckpoints)
+	}
+	if l.getRemainingTTL() != 10 {
+		t.Errorf("remainingTTL = %d, want %d", l.getRemainingTTL(), 10)
+	}
+}
+
 func TestLessorRenewExtendPileup(t *testing.T) {
 	oldRevokeRate := leaseRevokeRate
 	defer func() { leaseRevokeRate = oldRevokeRate }()
@@ -396,6 +592,46 @@ func TestLessorDetach(t *testing.T) {
 	}
 }
 
+// TestLessorCheckAttach ensures CheckAttach rejects the keys taking a lease
+// past the maximum number of attached keys, but not the attached keys.
+func TestLessorCheckAttach(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL, MaxAttachedKeys: 2})
+	defer le.Stop()
+
+	l, err := le.Grant(1, 100)
+	if err != nil {
+		t.Fatalf("could not grant lease for 100s ttl (%v)", err)
+	}
+	if err = le.CheckAttach(2, []LeaseItem{{"foo"}}); err != nil {
+		t.Fatalf("failed to check attaching to unknown lease: %v", err)
+	}
+	if err = le.CheckAttach(l.ID, []LeaseItem{{"foo"}, {"bar"}, {"baz"}}); !errors.Is(err, ErrLeaseTooManyAttachedKeys) {
+		t.Fatalf("err = %v, want %v", err, ErrLeaseTooManyAttachedKeys)
+	}
+	for _, key := range []string{"foo", "bar"} {
+		if err = le.CheckAttach(l.ID, []LeaseItem{{key}}); err != nil {
+			t.Fatalf("failed to check attaching %q to the lease: %v", key, err)
+		}
+		if err = le.Attach(l.ID, []LeaseItem{{key}}); err != nil {
+			t.Fatalf("failed to attach %q to the lease: %v", key, err)
+		}
+	}
+	if err = le.CheckAttach(l.ID, []LeaseItem{{"baz"}}); !errors.Is(err, ErrLeaseTooManyAttachedKeys) {
+		t.Fatalf("err = %v, want %v", err, ErrLeaseTooManyAttachedKeys)
+	}
+	if err = le.CheckAttach(l.ID, []LeaseItem{{"foo"}, {"bar"}}); err != nil {
+		t.Fatalf("failed to check attaching attached key to the lease: %v", err)
+	}
+	if n := l.AttachedKeys(); n != 2 {
+		t.Fatalf("AttachedKeys() = %d, want 2", n)
+	}
+}
+
 // TestLessorRecover ensures Lessor recovers leases from
 // persist backend.
 func TestLessorRecover(t *testing.T) {
@@ -426,6 +662,145 @@ func TestLessorRecover(t *testing.T) {
 	}
 }
 
+// TestLessorRecoverChildren ensures that the children of the recovered leases
+// are revoked along with them.
+func TestLessorRecoverChildren(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	// the child is granted a lower ID than its parent
+	if _, err := le.Grant(2, 10); err != nil {
+		t.Fatalf("could not grant lease (%v)", err)
+	}
+	if _, err := le.GrantWithParent(1, 2, 10); err != nil {
+		t.Fatalf("could not grant lease (%v)", err)
+	}
+
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	nle.SetRangeDeleter(func() TxnDelete { return newFakeDeleter(be) })
+	if nl := nle.Lookup(1); nl == nil || nl.Parent() != 2 {
+		t.Fatalf("nl = %v, want parent %x", nl, 2)
+	}
+	if err := nle.Revoke(2); err != nil {
+		t.Fatal("failed to revoke lease:", err)
+	}
+	if nle.Lookup(1) != nil {
+		t.Errorf("got revoked lease %x", 1)
+	}
+}
+
+func TestLessorGrantWithLabels(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+
+	labels := []*pb.LeaseLabel{{Key: "zone", Value: "a"}, {Key: "component", Value: "scheduler"}}
+	if _, err := le.GrantWithLabels(1, NoLease, 10, labels); err != nil {
+		t.Fatalf("could not grant lease (%v)", err)
+	}
+
+	tooMany := make([]*pb.LeaseLabel, MaxLeaseLabels+1)
+	for i := range tooMany {
+		tooMany[i] = &pb.LeaseLabel{Key: fmt.Sprintf("key%d", i)}
+	}
+	for i, invalid := range [][]*pb.LeaseLabel{
+		{{Key: "", Value: "a"}},
+		{{Key: "zone", Value: "a"}, {Key: "zone", Value: "b"}},
+		{{Key: "zone", Value: strings.Repeat("a", MaxLeaseLabelSize)}},
+		tooMany,
+	} {
+		if _, err := le.GrantWithLabels(LeaseID(i+2), NoLease, 10, invalid); err != ErrInvalidLeaseLabels {
+			t.Errorf("#%d: err = %v, want %v", i, err, ErrInvalidLeaseLabels)
+		}
+	}
+
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	nl := nle.Lookup(1)
+	if nl == nil {
+		t.Fatalf("failed to recover lease %x", 1)
+	}
+	wlabels := []*pb.LeaseLabel{{Key: "component", Value: "scheduler"}, {Key: "zone", Value: "a"}}
+	if !reflect.DeepEqual(nl.Labels(), wlabels) {
+		t.Errorf("labels = %v, want %v", nl.Labels(), wlabels)
+	}
+	for i, tt := range []struct {
+		labels []*pb.LeaseLabel
+		want   bool
+	}{
+		{nil, true},
+		{[]*pb.LeaseLabel{{Key: "zone", Value: "a"}}, true},
+		{[]*pb.LeaseLabel{{Key: "zone", Value: "a"}, {Key: "component", Value: "scheduler"}}, true},
+		{[]*pb.LeaseLabel{{Key: "zone", Value: "b"}}, false},
+		{[]*pb.LeaseLabel{{Key: "zone", Value: "a"}, {Key: "owner", Value: "a"}}, false},
+	} {
+		if got := nl.HasLabels(tt.labels); got != tt.want {
+			t.Errorf("#%d: HasLabels = %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+// TestLessorStats ensures the primary lessor keeps the renewal statistics of
+// the leases, and that their grantors are persisted.
+func TestLessorStats(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.Promote(0)
+
+	l, err := le.Grant(1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = le.Attach(1, []LeaseItem{{"foo"}, {"bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	if st := l.Stats(); st.Renewals != 0 || st.LastRenewTime != 0 || st.StatsStartTime == 0 {
+		t.Errorf("stats = %+v, want no renewal since the grant", st)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = le.Renew(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st := l.Stats()
+	if st.AttachedKeys != 2 || st.Renewals != 2 || st.RenewRate <= 0 || st.LastRenewTime < st.StatsStartTime {
+		t.Errorf("stats = %+v, want 2 attached keys and 2 renewals", st)
+	}
+
+	// the renewals are counted anew by a new primary
+	le.Demote()
+	le.Promote(0)
+	if st = l.Stats(); st.Renewals != 0 || st.LastRenewTime != 0 {
+		t.Errorf("stats = %+v, want no renewal since the promotion", st)
+	}
+
+	if err = le.SetGrantor(1, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err = le.SetGrantor(2, "alice"); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if g := nle.Lookup(1).Grantor(); g != "alice" {
+		t.Errorf("grantor = %q, want %q", g, "alice")
+	}
+}
+
 func TestLessorExpire(t *testing.T) {
 	lg := zap.NewNop()
 	dir, be := NewTestBackend(t)
@@ -40,6 +40,33 @@ var (
 		Help:      "The number of renewed leases seen by the leader.",
 	})
 
+	leaseRenewInterval = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "lease",
+			Name:      "renew_interval_seconds",
+			Help:      "Bucketed histogram of the intervals between the renewals of a lease seen by the leader.",
+			// 10ms -> ~1.5 hours
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 20),
+		})
+
+	leaseAttachRejected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "lease",
+		Name:      "attach_rejected_total",
+		Help:      "The total number of keys rejected from being attached to a lease with the maximum number of attached keys.",
+	})
+
+	leaseRevokedAttachedKeys = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "lease",
+			Name:      "revoked_attached_keys",
+			Help:      "Bucketed histogram of the number of keys attached to the revoked leases.",
+			// 1 -> ~16 million
+			Buckets: prometheus.ExponentialBuckets(1, 4, 13),
+		})
+
 	leaseTotalTTLs = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "etcd_debugging",
@@ -55,5 +82,8 @@ func init() {
 	prometheus.MustRegister(leaseGranted)
 	prometheus.MustRegister(leaseRevoked)
 	prometheus.MustRegister(leaseRenewed)
+	prometheus.MustRegister(leaseRenewInterval)
+	prometheus.MustRegister(leaseAttachRejected)
+	prometheus.MustRegister(leaseRevokedAttachedKeys)
 	prometheus.MustRegister(leaseTotalTTLs)
 }
@@ -0,0 +1,103 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import (
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// revokedLeaseBufferSize is the number of revoked leases buffered for a
+// watcher before it is considered too slow and dropped.
+var revokedLeaseBufferSize = 1024
+
+// RevokedLease is a lease removed by Revoke or Expire. Expired leases are
+// revoked by the primary lessor through consensus, with Expire rather than
+// Revoke so that they can be told apart.
+type RevokedLease struct {
+	ID LeaseID
+	// TTL is the TTL the lease was granted with.
+	TTL int64
+	// Keys are the keys deleted with the lease, sorted.
+	Keys []string
+	// Expired is true if the lease, or the ancestor it was revoked along
+	// with, expired.
+	Expired bool
+	// Parent is the parent lease the lease was granted with, if any.
+	Parent LeaseID
+	// Labels are the labels the lease was granted with.
+	Labels []*pb.LeaseLabel
+}
+
+// revokeNotifier broadcasts the revoked leases to the watchers.
+type revokeNotifier struct {
+	mu       sync.Mutex
+	watchers map[chan RevokedLease]struct{}
+	stopped  bool
+}
+
+func newRevokeNotifier() *revokeNotifier {
+	return &revokeNotifier{watchers: make(map[chan RevokedLease]struct{})}
+}
+
+// watch returns a chan receiving the leases revoked from now on, and a func
+// to stop watching. The chan is closed when the watch is stopped, when the
+// watcher falls more than revokedLeaseBufferSize leases behind, and when the
+// notifier is stopped.
+func (rn *revokeNotifier) watch() (<-chan RevokedLease, func()) {
+	ch := make(chan RevokedLease, revokedLeaseBufferSize)
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	if rn.stopped {
+		close(ch)
+		return ch, func() {}
+	}
+	rn.watchers[ch] = struct{}{}
+	return ch, func() {
+		rn.mu.Lock()
+		defer rn.mu.Unlock()
+		rn.removeLocked(ch)
+	}
+}
+
+// notify sends the revoked lease to the watchers without blocking.
+func (rn *revokeNotifier) notify(rl RevokedLease) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	for ch := range rn.watchers {
+		select {
+		case ch <- rl:
+		default:
+			rn.removeLocked(ch)
+		}
+	}
+}
+
+func (rn *revokeNotifier) stop() {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	for ch := range rn.watchers {
+		rn.removeLocked(ch)
+	}
+	rn.stopped = true
+}
+
+func (rn *revokeNotifier) removeLocked(ch chan RevokedLease) {
+	if _, ok := rn.watchers[ch]; ok {
+		delete(rn.watchers, ch)
+		close(ch)
+	}
+}
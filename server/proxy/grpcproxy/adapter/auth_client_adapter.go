@@ -64,6 +64,26 @@ func (s *as2ac) RoleRevokePermission(ctx context.Context, in *pb.AuthRoleRevokeP
 	return s.as.RoleRevokePermission(ctx, in)
 }
 
+func (s *as2ac) RoleSetMaxLeaseTTL(ctx context.Context, in *pb.AuthRoleSetMaxLeaseTTLRequest, opts ...grpc.CallOption) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	return s.as.RoleSetMaxLeaseTTL(ctx, in)
+}
+
+func (s *as2ac) RoleSetQuota(ctx context.Context, in *pb.AuthRoleSetQuotaRequest, opts ...grpc.CallOption) (*pb.AuthRoleSetQuotaResponse, error) {
+	return s.as.RoleSetQuota(ctx, in)
+}
+
+func (s *as2ac) AuthSetBcryptCost(ctx context.Context, in *pb.AuthSetBcryptCostRequest, opts ...grpc.CallOption) (*pb.AuthSetBcryptCostResponse, error) {
+	return s.as.AuthSetBcryptCost(ctx, in)
+}
+
+func (s *as2ac) AuthTokenList(ctx context.Context, in *pb.AuthTokenListRequest, opts ...grpc.CallOption) (*pb.AuthTokenListResponse, error) {
+	return s.as.AuthTokenList(ctx, in)
+}
+
+func (s *as2ac) AuthTokenRevoke(ctx context.Context, in *pb.AuthTokenRevokeRequest, opts ...grpc.CallOption) (*pb.AuthTokenRevokeResponse, error) {
+	return s.as.AuthTokenRevoke(ctx, in)
+}
+
 func (s *as2ac) RoleGrantPermission(ctx context.Context, in *pb.AuthRoleGrantPermissionRequest, opts ...grpc.CallOption) (*pb.AuthRoleGrantPermissionResponse, error) {
 	return s.as.RoleGrantPermission(ctx, in)
 }
@@ -44,6 +44,10 @@ func (s *kvs2kvc) Txn(ctx context.Context, in *pb.TxnRequest, opts ...grpc.CallO
 	return s.kvs.Txn(ctx, in)
 }
 
+func (s *kvs2kvc) IndexRange(ctx context.Context, in *pb.IndexRangeRequest, opts ...grpc.CallOption) (*pb.RangeResponse, error) {
+	return s.kvs.IndexRange(ctx, in)
+}
+
 func (s *kvs2kvc) Compact(ctx context.Context, in *pb.CompactionRequest, opts ...grpc.CallOption) (*pb.CompactionResponse, error) {
 	return s.kvs.Compact(ctx, in)
 }
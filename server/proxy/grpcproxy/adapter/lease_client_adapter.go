@@ -53,6 +53,25 @@ func (c *ls2lc) LeaseLeases(ctx context.Context, in *pb.LeaseLeasesRequest, opts
 	return c.leaseServer.LeaseLeases(ctx, in)
 }
 
+func (c *ls2lc) LeaseGrantBatch(ctx context.Context, in *pb.LeaseGrantBatchRequest, opts ...grpc.CallOption) (*pb.LeaseGrantBatchResponse, error) {
+	return c.leaseServer.LeaseGrantBatch(ctx, in)
+}
+
+func (c *ls2lc) LeaseRevokeBatch(ctx context.Context, in *pb.LeaseRevokeBatchRequest, opts ...grpc.CallOption) (*pb.LeaseRevokeBatchResponse, error) {
+	return c.leaseServer.LeaseRevokeBatch(ctx, in)
+}
+
+func (c *ls2lc) LeaseQuery(ctx context.Context, in *pb.LeaseQueryRequest, opts ...grpc.CallOption) (*pb.LeaseQueryResponse, error) {
+	return c.leaseServer.LeaseQuery(ctx, in)
+}
+
+func (c *ls2lc) WatchExpirations(ctx context.Context, in *pb.LeaseWatchExpirationsRequest, opts ...grpc.CallOption) (pb.Lease_WatchExpirationsClient, error) {
+	cs := newPipeStream(ctx, func(ss chanServerStream) error {
+		return c.leaseServer.WatchExpirations(in, &ls2lcWatchExpirationsServerStream{ss})
+	})
+	return &ls2lcWatchExpirationsClientStream{cs}, nil
+}
+
 // ls2lcClientStream implements Lease_LeaseKeepAliveClient
 type ls2lcClientStream struct{ chanClientStream }
 
@@ -80,3 +99,21 @@ func (s *ls2lcServerStream) Recv() (*pb.LeaseKeepAliveRequest, error) {
 	}
 	return v.(*pb.LeaseKeepAliveRequest), nil
 }
+
+// ls2lcWatchExpirationsClientStream implements Lease_WatchExpirationsClient
+type ls2lcWatchExpirationsClientStream struct{ chanClientStream }
+
+// ls2lcWatchExpirationsServerStream implements Lease_WatchExpirationsServer
+type ls2lcWatchExpirationsServerStream struct{ chanServerStream }
+
+func (s *ls2lcWatchExpirationsClientStream) Recv() (*pb.LeaseWatchExpirationsResponse, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseWatchExpirationsResponse), nil
+}
+
+func (s *ls2lcWatchExpirationsServerStream) Send(rr *pb.LeaseWatchExpirationsResponse) error {
+	return s.SendMsg(rr)
+}
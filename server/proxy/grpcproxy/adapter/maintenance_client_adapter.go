@@ -56,6 +56,38 @@ func (s *mts2mtc) Downgrade(ctx context.Context, r *pb.DowngradeRequest, opts ..
 	return s.mts.Downgrade(ctx, r)
 }
 
+func (s *mts2mtc) PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest, opts ...grpc.CallOption) (*pb.PrefixQuotaResponse, error) {
+	return s.mts.PrefixQuota(ctx, r)
+}
+
+func (s *mts2mtc) HotKeys(ctx context.Context, r *pb.HotKeysRequest, opts ...grpc.CallOption) (*pb.HotKeysResponse, error) {
+	return s.mts.HotKeys(ctx, r)
+}
+
+func (s *mts2mtc) CorruptionCheck(ctx context.Context, r *pb.CorruptionCheckRequest, opts ...grpc.CallOption) (*pb.CorruptionCheckResponse, error) {
+	return s.mts.CorruptionCheck(ctx, r)
+}
+
+func (s *mts2mtc) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest, opts ...grpc.CallOption) (*pb.PrefixStatsResponse, error) {
+	return s.mts.PrefixStats(ctx, r)
+}
+
+func (s *mts2mtc) RevisionPin(ctx context.Context, r *pb.RevisionPinRequest, opts ...grpc.CallOption) (*pb.RevisionPinResponse, error) {
+	return s.mts.RevisionPin(ctx, r)
+}
+
+func (s *mts2mtc) SnapshotRange(ctx context.Context, r *pb.SnapshotRangeRequest, opts ...grpc.CallOption) (*pb.SnapshotRangeResponse, error) {
+	return s.mts.SnapshotRange(ctx, r)
+}
+
+func (s *mts2mtc) WatchLag(ctx context.Context, r *pb.WatchLagRequest, opts ...grpc.CallOption) (*pb.WatchLagResponse, error) {
+	return s.mts.WatchLag(ctx, r)
+}
+
+func (s *mts2mtc) Drain(ctx context.Context, r *pb.DrainRequest, opts ...grpc.CallOption) (*pb.DrainResponse, error) {
+	return s.mts.Drain(ctx, r)
+}
+
 func (s *mts2mtc) Snapshot(ctx context.Context, in *pb.SnapshotRequest, opts ...grpc.CallOption) (pb.Maintenance_SnapshotClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return s.mts.Snapshot(in, &ss2scServerStream{ss})
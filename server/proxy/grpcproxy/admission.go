@@ -0,0 +1,212 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// PriorityMetadataKey is the metadata key of the priority of a request to the
+// proxy, "low" or "high", the requests being of normal priority otherwise.
+const PriorityMetadataKey = "etcd-proxy-priority"
+
+type requestPriority int
+
+const (
+	priorityLow requestPriority = iota
+	priorityNormal
+	priorityHigh
+)
+
+func (p requestPriority) String() string {
+	switch p {
+	case priorityLow:
+		return "low"
+	case priorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// latencyObservationTTL is how long an observed backend latency is trusted.
+// Without more recent observations, the low priority requests are admitted
+// again to observe whether the backend recovered.
+const latencyObservationTTL = time.Second
+
+// AdmissionConfig is the admission control of the unary requests of the
+// proxy. The zero config admits all the requests.
+type AdmissionConfig struct {
+	// MaxConcurrentRequests is the maximum number of the low and normal
+	// priority requests served concurrently, 0 for no limit. The high
+	// priority requests are not limited.
+	MaxConcurrentRequests int
+	// QueueTimeout is the maximum time a request waits to be served when
+	// MaxConcurrentRequests is reached, 0 for waiting until the request is
+	// canceled.
+	QueueTimeout time.Duration
+	// ShedLatencyThreshold is the backend latency above which the low
+	// priority requests are rejected, 0 for never rejecting them.
+	ShedLatencyThreshold time.Duration
+	// LowPriorityMethods are the full names of the gRPC methods, like
+	// "/etcdserverpb.KV/Range", whose requests are of low priority unless
+	// their metadata says otherwise.
+	LowPriorityMethods []string
+}
+
+// Admission is the admission control of the unary requests of the proxy,
+// whose config can be updated while serving.
+type Admission struct {
+	mu         sync.Mutex
+	cfg        AdmissionConfig
+	lowMethods map[string]struct{}
+	// slots is nil without a limit of concurrent requests.
+	slots chan struct{}
+	// latency is the moving average of the backend latency.
+	latency  time.Duration
+	observed time.Time
+}
+
+func NewAdmission(cfg AdmissionConfig) *Admission {
+	a := &Admission{}
+	a.Update(cfg)
+	return a
+}
+
+// NewAdmissionUnaryInterceptor returns an interceptor rejecting the unary
+// requests with ErrGRPCRequestTooManyRequests when the proxy is overloaded.
+func NewAdmissionUnaryInterceptor(cfg AdmissionConfig) grpc.UnaryServerInterceptor {
+	return NewAdmission(cfg).UnaryServerInterceptor
+}
+
+// Update replaces the config of the admission control. The requests in flight
+// are not counted against a new limit of concurrent requests.
+func (a *Admission) Update(cfg AdmissionConfig) {
+	lowMethods := make(map[string]struct{}, len(cfg.LowPriorityMethods))
+	for _, m := range cfg.LowPriorityMethods {
+		lowMethods[m] = struct{}{}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.slots == nil || cap(a.slots) != cfg.MaxConcurrentRequests {
+		a.slots = nil
+		if cfg.MaxConcurrentRequests > 0 {
+			a.slots = make(chan struct{}, cfg.MaxConcurrentRequests)
+		}
+	}
+	a.cfg, a.lowMethods = cfg, lowMethods
+}
+
+// UnaryServerInterceptor rejects the unary requests with
+// ErrGRPCRequestTooManyRequests when the proxy is overloaded.
+func (a *Admission) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	a.mu.Lock()
+	cfg, lowMethods, slots := a.cfg, a.lowMethods, a.slots
+	a.mu.Unlock()
+
+	p := priority(ctx, info.FullMethod, lowMethods)
+	if p == priorityLow && a.overloaded(cfg.ShedLatencyThreshold) {
+		admissionRejected.WithLabelValues(p.String(), "overloaded").Inc()
+		return nil, rpctypes.ErrGRPCRequestTooManyRequests
+	}
+	if p != priorityHigh && slots != nil {
+		if err := acquire(ctx, slots, cfg.QueueTimeout); err != nil {
+			admissionRejected.WithLabelValues(p.String(), "queue_timeout").Inc()
+			return nil, err
+		}
+		defer release(slots)
+	}
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	a.observe(time.Since(start))
+	return resp, err
+}
+
+func priority(ctx context.Context, method string, lowMethods map[string]struct{}) requestPriority {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ps := md.Get(PriorityMetadataKey); len(ps) > 0 {
+			switch ps[0] {
+			case "low":
+				return priorityLow
+			case "high":
+				return priorityHigh
+			}
+		}
+	}
+	if _, ok := lowMethods[method]; ok {
+		return priorityLow
+	}
+	return priorityNormal
+}
+
+// acquire waits for a slot of concurrent requests, until the queue timeout
+// or the cancellation of the request.
+func acquire(ctx context.Context, slots chan struct{}, queueTimeout time.Duration) error {
+	select {
+	case slots <- struct{}{}:
+		admissionInflight.Inc()
+		return nil
+	default:
+	}
+
+	if queueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, queueTimeout)
+		defer cancel()
+	}
+	select {
+	case slots <- struct{}{}:
+		admissionInflight.Inc()
+		return nil
+	case <-ctx.Done():
+		return rpctypes.ErrGRPCRequestTooManyRequests
+	}
+}
+
+func release(slots chan struct{}) {
+	<-slots
+	admissionInflight.Dec()
+}
+
+// observe updates the moving average of the backend latency.
+func (a *Admission) observe(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.observed.IsZero() {
+		a.latency = d
+	} else {
+		a.latency += (d - a.latency) / 8
+	}
+	a.observed = time.Now()
+	admissionBackendLatency.Set(a.latency.Seconds())
+}
+
+func (a *Admission) overloaded(threshold time.Duration) bool {
+	if threshold <= 0 {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.latency > threshold && time.Since(a.observed) < latencyObservationTTL
+}
@@ -23,12 +23,20 @@ import (
 
 type AuthProxy struct {
 	authClient pb.AuthClient
+	tokenCache *AuthTokenCache
 }
 
 func NewAuthProxy(c *clientv3.Client) pb.AuthServer {
 	return &AuthProxy{authClient: pb.NewAuthClient(c.ActiveConnection())}
 }
 
+// NewAuthProxyWithTokenCache returns an auth proxy serving Authenticate from
+// the token cache. The client must forward the tokens of the clients through
+// the interceptors of the cache.
+func NewAuthProxyWithTokenCache(c *clientv3.Client, tc *AuthTokenCache) pb.AuthServer {
+	return &AuthProxy{authClient: pb.NewAuthClient(c.ActiveConnection()), tokenCache: tc}
+}
+
 func (ap *AuthProxy) AuthEnable(ctx context.Context, r *pb.AuthEnableRequest) (*pb.AuthEnableResponse, error) {
 	return ap.authClient.AuthEnable(ctx, r)
 }
@@ -42,6 +50,9 @@ func (ap *AuthProxy) AuthStatus(ctx context.Context, r *pb.AuthStatusRequest) (*
 }
 
 func (ap *AuthProxy) Authenticate(ctx context.Context, r *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	if ap.tokenCache != nil {
+		return ap.tokenCache.authenticate(ctx, ap.authClient, r)
+	}
 	return ap.authClient.Authenticate(ctx, r)
 }
 
@@ -65,6 +76,26 @@ func (ap *AuthProxy) RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRev
 	return ap.authClient.RoleRevokePermission(ctx, r)
 }
 
+func (ap *AuthProxy) RoleSetMaxLeaseTTL(ctx context.Context, r *pb.AuthRoleSetMaxLeaseTTLRequest) (*pb.AuthRoleSetMaxLeaseTTLResponse, error) {
+	return ap.authClient.RoleSetMaxLeaseTTL(ctx, r)
+}
+
+func (ap *AuthProxy) RoleSetQuota(ctx context.Context, r *pb.AuthRoleSetQuotaRequest) (*pb.AuthRoleSetQuotaResponse, error) {
+	return ap.authClient.RoleSetQuota(ctx, r)
+}
+
+func (ap *AuthProxy) AuthSetBcryptCost(ctx context.Context, r *pb.AuthSetBcryptCostRequest) (*pb.AuthSetBcryptCostResponse, error) {
+	return ap.authClient.AuthSetBcryptCost(ctx, r)
+}
+
+func (ap *AuthProxy) AuthTokenList(ctx context.Context, r *pb.AuthTokenListRequest) (*pb.AuthTokenListResponse, error) {
+	return ap.authClient.AuthTokenList(ctx, r)
+}
+
+func (ap *AuthProxy) AuthTokenRevoke(ctx context.Context, r *pb.AuthTokenRevokeRequest) (*pb.AuthTokenRevokeResponse, error) {
+	return ap.authClient.AuthTokenRevoke(ctx, r)
+}
+
 func (ap *AuthProxy) RoleGrantPermission(ctx context.Context, r *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error) {
 	return ap.authClient.RoleGrantPermission(ctx, r)
 }
@@ -0,0 +1,236 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+const authenticateMethod = "/etcdserverpb.Auth/Authenticate"
+
+// AuthTokenCache shares the auth tokens of the users between the clients of
+// the proxy authenticating with the same credentials, and refreshes them
+// transparently for the clients. The credentials are kept in memory to
+// refresh the tokens.
+type AuthTokenCache struct {
+	lg *zap.Logger
+	// refreshInterval is the age of the tokens from which they are refreshed
+	// on their next use. The sessions not used for twice the interval are
+	// dropped.
+	refreshInterval time.Duration
+
+	mu sync.Mutex
+	// sessions are the sessions by the hashes of their credentials.
+	sessions map[[sha256.Size]byte]*authSession
+	// tokens are the sessions by the tokens handed out to the clients.
+	tokens map[string]*authSession
+}
+
+// authSession is the current backend token of a user.
+type authSession struct {
+	key      [sha256.Size]byte
+	name     string
+	password string
+
+	// mu serializes the refreshes of the token.
+	mu       sync.Mutex
+	token    string
+	header   *pb.ResponseHeader
+	obtained time.Time
+	// lastUsed and aliases are protected by the mutex of the cache.
+	lastUsed time.Time
+	aliases  []string
+}
+
+// NewAuthTokenCache returns a cache refreshing the tokens once older than the
+// refresh interval, which should be lower than the auth token TTL of the
+// cluster.
+func NewAuthTokenCache(lg *zap.Logger, refreshInterval time.Duration) *AuthTokenCache {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &AuthTokenCache{
+		lg:              lg,
+		refreshInterval: refreshInterval,
+		sessions:        make(map[[sha256.Size]byte]*authSession),
+		tokens:          make(map[string]*authSession),
+	}
+}
+
+func credentialsKey(name, password string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(name + "\x00" + password))
+}
+
+// authenticate returns the token of the session of the credentials, calling
+// Authenticate on the cluster for new or aged sessions.
+func (tc *AuthTokenCache) authenticate(ctx context.Context, ac pb.AuthClient, r *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	key := credentialsKey(r.Name, r.Password)
+	tc.mu.Lock()
+	tc.pruneLocked()
+	s, ok := tc.sessions[key]
+	if !ok {
+		s = &authSession{key: key, name: r.Name, password: r.Password}
+		tc.sessions[key] = s
+	}
+	s.lastUsed = time.Now()
+	tc.mu.Unlock()
+
+	token, header, cached, err := tc.refresh(ctx, ac, s, "")
+	if err != nil {
+		if !ok {
+			tc.drop(s)
+		}
+		return nil, err
+	}
+	if cached {
+		authTokenCacheRequests.WithLabelValues("hit").Inc()
+	} else {
+		authTokenCacheRequests.WithLabelValues("miss").Inc()
+	}
+
+	tc.mu.Lock()
+	if _, ok := tc.tokens[token]; !ok && tc.sessions[key] == s {
+		tc.tokens[token] = s
+		s.aliases = append(s.aliases, token)
+	}
+	tc.mu.Unlock()
+	return &pb.AuthenticateResponse{Header: header, Token: token}, nil
+}
+
+// refresh returns the token of the session, authenticating again if the
+// session has no token, its token is aged, or its token is the given invalid
+// one. Returns whether the token was cached.
+func (tc *AuthTokenCache) refresh(ctx context.Context, ac pb.AuthClient, s *authSession, invalid string) (string, *pb.ResponseHeader, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.token != invalid && time.Since(s.obtained) < tc.refreshInterval {
+		return s.token, s.header, true, nil
+	}
+
+	// the incoming metadata of the request are not forwarded
+	resp, err := ac.Authenticate(metadata.NewIncomingContext(ctx, metadata.MD{}), &pb.AuthenticateRequest{Name: s.name, Password: s.password})
+	if err != nil {
+		return "", nil, false, err
+	}
+	if s.token != "" {
+		authTokenRefreshes.Inc()
+		tc.lg.Debug("refreshed auth token", zap.String("user-name", s.name))
+	}
+	s.token, s.header, s.obtained = resp.Token, resp.Header, time.Now()
+	return s.token, s.header, false, nil
+}
+
+// backendToken returns the current backend token of the token of a client,
+// refreshing it if aged, and whether the token was handed out by the cache.
+// The other tokens are returned as is.
+func (tc *AuthTokenCache) backendToken(ctx context.Context, cc *grpc.ClientConn, token, invalid string) (string, bool, error) {
+	tc.mu.Lock()
+	s, ok := tc.tokens[token]
+	if ok {
+		s.lastUsed = time.Now()
+	}
+	tc.mu.Unlock()
+	if !ok {
+		return token, false, nil
+	}
+	t, _, _, err := tc.refresh(ctx, pb.NewAuthClient(cc), s, invalid)
+	return t, true, err
+}
+
+// user returns the user of a token handed out by the cache.
+func (tc *AuthTokenCache) user(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	s, ok := tc.tokens[token]
+	if !ok {
+		return "", false
+	}
+	return s.name, true
+}
+
+func (tc *AuthTokenCache) drop(s *authSession) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.dropLocked(s)
+}
+
+func (tc *AuthTokenCache) dropLocked(s *authSession) {
+	if tc.sessions[s.key] == s {
+		delete(tc.sessions, s.key)
+	}
+	for _, t := range s.aliases {
+		delete(tc.tokens, t)
+	}
+}
+
+// pruneLocked drops the sessions not used for twice the refresh interval,
+// their clients authenticating again on the expiry of their tokens.
+func (tc *AuthTokenCache) pruneLocked() {
+	for _, s := range tc.sessions {
+		if time.Since(s.lastUsed) > 2*tc.refreshInterval {
+			tc.dropLocked(s)
+		}
+	}
+}
+
+// UnaryClientInterceptor forwards the auth tokens of the clients like
+// AuthUnaryClientInterceptor, replacing the tokens handed out by the cache by
+// their current backend tokens, and retrying once the requests failing on an
+// invalid auth token with a new token.
+func (tc *AuthTokenCache) UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	token := getAuthTokenFromClient(ctx)
+	if token == "" || method == authenticateMethod {
+		return AuthUnaryClientInterceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+	bt, cached, err := tc.backendToken(ctx, cc, token, "")
+	if err != nil {
+		return err
+	}
+	err = invoker(ctx, method, req, reply, cc, append(opts, grpc.PerRPCCredentials(&proxyTokenCredential{bt}))...)
+	if !cached || rpctypes.Error(err) != rpctypes.ErrInvalidAuthToken {
+		return err
+	}
+	if bt, _, err = tc.backendToken(ctx, cc, token, bt); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, append(opts, grpc.PerRPCCredentials(&proxyTokenCredential{bt}))...)
+}
+
+// StreamClientInterceptor forwards the auth tokens of the clients like
+// AuthStreamClientInterceptor, replacing the tokens handed out by the cache
+// by their current backend tokens.
+func (tc *AuthTokenCache) StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if token, ok := ctx.Value(rpctypes.TokenFieldNameGRPC).(string); ok && token != "" {
+		bt, _, err := tc.backendToken(ctx, cc, token, "")
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, rpctypes.TokenFieldNameGRPC, bt)
+	}
+	return AuthStreamClientInterceptor(ctx, desc, cc, method, streamer, opts...)
+}
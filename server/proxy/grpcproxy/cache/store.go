@@ -19,6 +19,7 @@ package cache
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/golang/groupcache/lru"
 
@@ -52,10 +53,18 @@ func keyFunc(req *pb.RangeRequest) string {
 }
 
 func NewCache(maxCacheEntries int) Cache {
+	return NewTTLCache(maxCacheEntries, 0)
+}
+
+// NewTTLCache returns a cache whose responses are not served once older than
+// the ttl, bounding the staleness of the responses to the writes not
+// invalidating them. The responses do not expire with a zero ttl.
+func NewTTLCache(maxCacheEntries int, ttl time.Duration) Cache {
 	return &cache{
 		lru:          lru.New(maxCacheEntries),
 		cachedRanges: adt.NewIntervalTree(),
 		compactedRev: -1,
+		ttl:          ttl,
 	}
 }
 
@@ -70,6 +79,14 @@ type cache struct {
 	cachedRanges adt.IntervalTree
 
 	compactedRev int64
+
+	ttl time.Duration
+}
+
+// entry is a cached response, added at the given time.
+type entry struct {
+	resp  *pb.RangeResponse
+	added time.Time
 }
 
 // Add adds the response of a request to the cache if its revision is larger than the compacted revision of the cache.
@@ -80,7 +97,7 @@ func (c *cache) Add(req *pb.RangeRequest, resp *pb.RangeResponse) {
 	defer c.mu.Unlock()
 
 	if req.Revision > c.compactedRev {
-		c.lru.Add(key, resp)
+		c.lru.Add(key, &entry{resp: resp, added: time.Now()})
 	}
 	// we do not need to invalidate a request with a revision specified.
 	// so we do not need to add it into the reverse index.
@@ -123,8 +140,13 @@ func (c *cache) Get(req *pb.RangeRequest) (*pb.RangeResponse, error) {
 		return nil, ErrCompacted
 	}
 
-	if resp, ok := c.lru.Get(key); ok {
-		return resp.(*pb.RangeResponse), nil
+	if v, ok := c.lru.Get(key); ok {
+		e := v.(*entry)
+		if c.ttl > 0 && time.Since(e.added) > c.ttl {
+			c.lru.Remove(key)
+			return nil, errors.New("not exist")
+		}
+		return e.resp, nil
 	}
 	return nil, errors.New("not exist")
 }
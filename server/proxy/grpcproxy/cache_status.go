@@ -0,0 +1,154 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// PathProxyCache is the path of the cache status of the proxy.
+const PathProxyCache = "/proxy/cache"
+
+// CacheStatus is the status of the deduplication of the requests by the
+// proxy: the watch broadcasts shared by the watchers of the clients, and the
+// range responses cached for the serializable range requests.
+type CacheStatus struct {
+	// WatchGroups are the active broadcasts, each serving its subscribers
+	// from one etcd server watcher.
+	WatchGroups []WatchGroupStatus `json:"watch_groups"`
+	// Watchers is the total number of the subscribers of the watch groups.
+	Watchers int `json:"watchers"`
+
+	// CachedRanges is the number of the range responses cached.
+	CachedRanges int `json:"cached_ranges"`
+	// CacheHits and CacheMisses count the serializable range requests served
+	// from the cache and from the etcd cluster.
+	CacheHits   int64 `json:"cache_hits"`
+	CacheMisses int64 `json:"cache_misses"`
+	// CacheHitRatio is the ratio of the cache hits, 0 before any request.
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+}
+
+// WatchGroupStatus is the status of a watch broadcast.
+type WatchGroupStatus struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end,omitempty"`
+	// Subscribers is the number of the client watchers served.
+	Subscribers int `json:"subscribers"`
+	// NextRevision is the minimum expected next revision of the broadcast.
+	NextRevision int64 `json:"next_revision"`
+}
+
+// CacheStatusHandler serves the CacheStatus of the KV and watch proxies
+// registered with it.
+type CacheStatusHandler struct {
+	mu sync.RWMutex
+	kp *kvProxy
+	wp *watchProxy
+}
+
+func NewCacheStatusHandler() *CacheStatusHandler {
+	return &CacheStatusHandler{}
+}
+
+// Register sets the proxies whose status is served. The servers not created
+// by NewKvProxy and NewWatchProxy are ignored.
+func (h *CacheStatusHandler) Register(kv pb.KVServer, w pb.WatchServer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.kp, _ = kv.(*kvProxy)
+	h.wp, _ = w.(*watchProxy)
+}
+
+// Status returns the status of the registered proxies.
+func (h *CacheStatusHandler) Status() CacheStatus {
+	h.mu.RLock()
+	kp, wp := h.kp, h.wp
+	h.mu.RUnlock()
+
+	cs := CacheStatus{WatchGroups: []WatchGroupStatus{}}
+	if wp != nil {
+		cs.WatchGroups = wp.ranges.status()
+		for _, g := range cs.WatchGroups {
+			cs.Watchers += g.Subscribers
+		}
+	}
+	if kp != nil {
+		cs.CachedRanges = kp.cache.Size()
+		cs.CacheHits = atomic.LoadInt64(&kp.hits)
+		cs.CacheMisses = atomic.LoadInt64(&kp.misses)
+		if total := cs.CacheHits + cs.CacheMisses; total > 0 {
+			cs.CacheHitRatio = float64(cs.CacheHits) / float64(total)
+		}
+	}
+	return cs
+}
+
+func (h *CacheStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	d, err := json.Marshal(h.Status())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(d)
+}
+
+// HandleProxyCache registers cache status handler on '/proxy/cache'.
+func HandleProxyCache(mux *http.ServeMux, h *CacheStatusHandler) {
+	mux.Handle(PathProxyCache, h)
+}
+
+// status returns the status of the watch broadcasts, ordered by their ranges.
+func (wrs *watchRanges) status() []WatchGroupStatus {
+	wrs.mu.Lock()
+	defer wrs.mu.Unlock()
+	groups := []WatchGroupStatus{}
+	for wr, wbs := range wrs.bcasts {
+		wbs.mu.Lock()
+		for wb := range wbs.bcasts {
+			wb.mu.RLock()
+			groups = append(groups, WatchGroupStatus{
+				Key:          wr.key,
+				RangeEnd:     wr.end,
+				Subscribers:  len(wb.receivers),
+				NextRevision: wb.nextrev,
+			})
+			wb.mu.RUnlock()
+		}
+		wbs.mu.Unlock()
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Key != groups[j].Key {
+			return groups[i].Key < groups[j].Key
+		}
+		if groups[i].RangeEnd != groups[j].RangeEnd {
+			return groups[i].RangeEnd < groups[j].RangeEnd
+		}
+		return groups[i].NextRevision < groups[j].NextRevision
+	})
+	return groups
+}
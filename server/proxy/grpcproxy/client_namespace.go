@@ -0,0 +1,296 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// ClientNamespaceConfig maps the identities of the clients of the proxy to
+// the prefixes of the keys of their KV, Watch and Lease requests. The
+// clients without a namespace access the keys as is.
+type ClientNamespaceConfig struct {
+	// Users are the namespaces of the auth users, resolved from the tokens
+	// handed out by TokenCache. The other tokens are rejected as invalid for
+	// the clients to authenticate again through the proxy.
+	Users      map[string]string
+	TokenCache *AuthTokenCache
+	// CommonNames are the namespaces of the common names of the verified TLS
+	// client certificates, for the clients without a namespace by user.
+	CommonNames map[string]string
+}
+
+// ClientNamespaces rewrites the keys of the requests of the clients with a
+// namespace, like the clientv3 namespace package. The compactions, affecting
+// all the namespaces, are denied to the clients with a namespace.
+type ClientNamespaces struct {
+	mu  sync.RWMutex
+	cfg ClientNamespaceConfig
+}
+
+func NewClientNamespaces(cfg ClientNamespaceConfig) *ClientNamespaces {
+	return &ClientNamespaces{cfg: cfg}
+}
+
+// Update replaces the namespaces of the clients. The established watch
+// streams keep their namespaces.
+func (cn *ClientNamespaces) Update(cfg ClientNamespaceConfig) {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	cn.cfg = cfg
+}
+
+// namespace returns the namespace of the client of the request.
+func (cn *ClientNamespaces) namespace(ctx context.Context) (string, error) {
+	cn.mu.RLock()
+	cfg := cn.cfg
+	cn.mu.RUnlock()
+
+	if cfg.TokenCache != nil && len(cfg.Users) > 0 {
+		if token := getAuthTokenFromClient(ctx); token != "" {
+			name, ok := cfg.TokenCache.user(token)
+			if !ok {
+				return "", rpctypes.ErrGRPCInvalidAuthToken
+			}
+			if pfx, ok := cfg.Users[name]; ok {
+				return pfx, nil
+			}
+		}
+	}
+	if len(cfg.CommonNames) == 0 {
+		return "", nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return "", nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", nil
+	}
+	for _, chains := range tlsInfo.State.VerifiedChains {
+		if len(chains) > 0 {
+			return cfg.CommonNames[chains[0].Subject.CommonName], nil
+		}
+	}
+	return "", nil
+}
+
+func (cn *ClientNamespaces) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if info.FullMethod == authenticateMethod {
+		return handler(ctx, req)
+	}
+	pfx, err := cn.namespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pfx == "" {
+		return handler(ctx, req)
+	}
+	if _, ok := req.(*pb.CompactionRequest); ok {
+		return nil, rpctypes.ErrGRPCPermissionDenied
+	}
+	prefixRequest(pfx, req)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return unprefixResponse(pfx, resp), nil
+}
+
+func (cn *ClientNamespaces) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	pfx, err := cn.namespace(ss.Context())
+	if err != nil {
+		return err
+	}
+	if pfx != "" {
+		ss = &namespacedServerStream{ServerStream: ss, pfx: pfx}
+	}
+	return handler(srv, ss)
+}
+
+// namespacedServerStream rewrites the keys of the watch streams.
+type namespacedServerStream struct {
+	grpc.ServerStream
+	pfx string
+}
+
+func (ss *namespacedServerStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if wr, ok := m.(*pb.WatchRequest); ok {
+		if cr := wr.GetCreateRequest(); cr != nil {
+			cr.Key, cr.RangeEnd = prefixInterval(ss.pfx, cr.Key, cr.RangeEnd)
+		}
+	}
+	return nil
+}
+
+func (ss *namespacedServerStream) SendMsg(m interface{}) error {
+	if wr, ok := m.(*pb.WatchResponse); ok && len(wr.Events) > 0 {
+		// the events are shared with the other watchers of the proxy
+		resp := *wr
+		resp.Events = make([]*mvccpb.Event, len(wr.Events))
+		for i, ev := range wr.Events {
+			e := *ev
+			e.Kv = unprefixKV(ss.pfx, ev.Kv)
+			e.PrevKv = unprefixKV(ss.pfx, ev.PrevKv)
+			resp.Events[i] = &e
+		}
+		m = &resp
+	}
+	return ss.ServerStream.SendMsg(m)
+}
+
+func prefixRequest(pfx string, req interface{}) {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		r.Key, r.RangeEnd = prefixInterval(pfx, r.Key, r.RangeEnd)
+	case *pb.PutRequest:
+		r.Key, _ = prefixInterval(pfx, r.Key, nil)
+	case *pb.DeleteRangeRequest:
+		r.Key, r.RangeEnd = prefixInterval(pfx, r.Key, r.RangeEnd)
+	case *pb.TxnRequest:
+		for _, c := range r.Compare {
+			c.Key, c.RangeEnd = prefixInterval(pfx, c.Key, c.RangeEnd)
+		}
+		for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+			for _, op := range ops {
+				switch {
+				case op.GetRequestRange() != nil:
+					prefixRequest(pfx, op.GetRequestRange())
+				case op.GetRequestPut() != nil:
+					prefixRequest(pfx, op.GetRequestPut())
+				case op.GetRequestDeleteRange() != nil:
+					prefixRequest(pfx, op.GetRequestDeleteRange())
+				case op.GetRequestTxn() != nil:
+					prefixRequest(pfx, op.GetRequestTxn())
+				}
+			}
+		}
+	}
+}
+
+// unprefixResponse returns a copy of the response without the prefix of the
+// keys, the responses being shared by the range cache.
+func unprefixResponse(pfx string, resp interface{}) interface{} {
+	switch r := resp.(type) {
+	case *pb.RangeResponse:
+		cp := *r
+		cp.Kvs = unprefixKVs(pfx, r.Kvs)
+		return &cp
+	case *pb.PutResponse:
+		cp := *r
+		cp.PrevKv = unprefixKV(pfx, r.PrevKv)
+		return &cp
+	case *pb.DeleteRangeResponse:
+		cp := *r
+		cp.PrevKvs = unprefixKVs(pfx, r.PrevKvs)
+		return &cp
+	case *pb.TxnResponse:
+		cp := *r
+		cp.Responses = make([]*pb.ResponseOp, len(r.Responses))
+		for i, op := range r.Responses {
+			switch {
+			case op.GetResponseRange() != nil:
+				cp.Responses[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseRange{
+					ResponseRange: unprefixResponse(pfx, op.GetResponseRange()).(*pb.RangeResponse)}}
+			case op.GetResponsePut() != nil:
+				cp.Responses[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponsePut{
+					ResponsePut: unprefixResponse(pfx, op.GetResponsePut()).(*pb.PutResponse)}}
+			case op.GetResponseDeleteRange() != nil:
+				cp.Responses[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseDeleteRange{
+					ResponseDeleteRange: unprefixResponse(pfx, op.GetResponseDeleteRange()).(*pb.DeleteRangeResponse)}}
+			case op.GetResponseTxn() != nil:
+				cp.Responses[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseTxn{
+					ResponseTxn: unprefixResponse(pfx, op.GetResponseTxn()).(*pb.TxnResponse)}}
+			default:
+				cp.Responses[i] = op
+			}
+		}
+		return &cp
+	case *pb.LeaseTimeToLiveResponse:
+		cp := *r
+		cp.Keys = make([][]byte, len(r.Keys))
+		for i, k := range r.Keys {
+			cp.Keys[i] = unprefixKey(pfx, k)
+		}
+		return &cp
+	}
+	return resp
+}
+
+func unprefixKVs(pfx string, kvs []*mvccpb.KeyValue) []*mvccpb.KeyValue {
+	if kvs == nil {
+		return nil
+	}
+	ukvs := make([]*mvccpb.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		ukvs[i] = unprefixKV(pfx, kv)
+	}
+	return ukvs
+}
+
+func unprefixKV(pfx string, kv *mvccpb.KeyValue) *mvccpb.KeyValue {
+	if kv == nil {
+		return nil
+	}
+	cp := *kv
+	cp.Key = unprefixKey(pfx, kv.Key)
+	return &cp
+}
+
+func unprefixKey(pfx string, key []byte) []byte {
+	if len(key) < len(pfx) {
+		return key
+	}
+	return key[len(pfx):]
+}
+
+// prefixInterval prefixes the interval of keys like the clientv3 namespace
+// package, the keys from a key being bounded by the end of the namespace.
+func prefixInterval(pfx string, key, end []byte) (pfxKey []byte, pfxEnd []byte) {
+	pfxKey = make([]byte, len(pfx)+len(key))
+	copy(pfxKey[copy(pfxKey, pfx):], key)
+
+	if isFromKey(end) {
+		pfxEnd = []byte(pfx)
+		ok := false
+		for i := len(pfxEnd) - 1; i >= 0; i-- {
+			if pfxEnd[i]++; pfxEnd[i] != 0 {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			// 0xff..ff => 0x00
+			pfxEnd = []byte{0}
+		}
+	} else if len(end) >= 1 {
+		pfxEnd = make([]byte, len(pfx)+len(end))
+		copy(pfxEnd[copy(pfxEnd, pfx):], end)
+	}
+	return pfxKey, pfxEnd
+}
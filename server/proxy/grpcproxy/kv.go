@@ -16,6 +16,9 @@ package grpcproxy
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -25,31 +28,104 @@ import (
 type kvProxy struct {
 	kv    clientv3.KV
 	cache cache.Cache
+
+	// hits and misses count the serializable range requests served from
+	// the cache and from the cluster, for the cache status.
+	hits   int64
+	misses int64
 }
 
 func NewKvProxy(c *clientv3.Client) (pb.KVServer, <-chan struct{}) {
+	return NewKvProxyWithCache(c, KvCacheConfig{})
+}
+
+// KvCacheConfig configures the cache of the serializable range responses of
+// the KV proxy.
+type KvCacheConfig struct {
+	// MaxEntries is the maximum number of the cached responses,
+	// cache.DefaultMaxEntries if 0.
+	MaxEntries int
+	// MaxStaleness is the maximum age of the cached responses, 0 for
+	// serving them until invalidated by the writes through the proxy.
+	MaxStaleness time.Duration
+	// WatchPrefixes are the prefixes of the keys watched to invalidate the
+	// cached responses on the writes not going through the proxy.
+	WatchPrefixes []string
+}
+
+// NewKvProxyWithCache returns a KV proxy with the given cache. The returned
+// channel is closed once the watches of the cache stop with the client.
+func NewKvProxyWithCache(c *clientv3.Client, cfg KvCacheConfig) (pb.KVServer, <-chan struct{}) {
+	maxEntries := cfg.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = cache.DefaultMaxEntries
+	}
 	kv := &kvProxy{
 		kv:    c.KV,
-		cache: cache.NewCache(cache.DefaultMaxEntries),
+		cache: cache.NewTTLCache(maxEntries, cfg.MaxStaleness),
 	}
 	donec := make(chan struct{})
-	close(donec)
+	var wg sync.WaitGroup
+	for _, prefix := range cfg.WatchPrefixes {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			kv.invalidateOnWatch(c.Ctx(), c.Watcher, prefix)
+		}(prefix)
+	}
+	go func() {
+		wg.Wait()
+		close(donec)
+	}()
 	return kv, donec
 }
 
+// invalidateOnWatch invalidates the cached responses of the keys under the
+// prefix changed, until the context is done. The responses of all the keys
+// under the prefix are invalidated whenever the watch is (re)created, since
+// it may have missed changes.
+func (p *kvProxy) invalidateOnWatch(ctx context.Context, w clientv3.Watcher, prefix string) {
+	end := clientv3.GetPrefixRangeEnd(prefix)
+	for {
+		wctx, cancel := context.WithCancel(ctx)
+		wch := w.Watch(clientv3.WithRequireLeader(wctx), prefix, clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+		for wr := range wch {
+			if wr.Created || wr.Err() != nil {
+				p.cache.Invalidate([]byte(prefix), []byte(end))
+			}
+			for _, ev := range wr.Events {
+				p.cache.Invalidate(ev.Kv.Key, nil)
+			}
+			cacheKeys.Set(float64(p.cache.Size()))
+		}
+		cancel()
+		// the changes are not watched until the watch is recreated
+		p.cache.Invalidate([]byte(prefix), []byte(end))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
 func (p *kvProxy) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
 	if r.Serializable {
 		resp, err := p.cache.Get(r)
 		switch err {
 		case nil:
 			cacheHits.Inc()
+			atomic.AddInt64(&p.hits, 1)
 			return resp, nil
 		case cache.ErrCompacted:
 			cacheHits.Inc()
+			atomic.AddInt64(&p.hits, 1)
 			return nil, err
 		}
 
 		cachedMisses.Inc()
+		atomic.AddInt64(&p.misses, 1)
 	}
 
 	resp, err := p.kv.Do(ctx, RangeRequestToOp(r))
@@ -67,6 +143,22 @@ func (p *kvProxy) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRespo
 	return gresp, nil
 }
 
+func (p *kvProxy) IndexRange(ctx context.Context, r *pb.IndexRangeRequest) (*pb.RangeResponse, error) {
+	var opts []clientv3.OpOption
+	if r.Limit > 0 {
+		opts = append(opts, clientv3.WithLimit(r.Limit))
+	}
+	if r.Serializable {
+		opts = append(opts, clientv3.WithSerializable())
+	}
+	if r.KeysOnly {
+		opts = append(opts, clientv3.WithKeysOnly())
+	}
+
+	resp, err := p.kv.IndexGet(ctx, string(r.Prefix), r.Field, r.Value, opts...)
+	return (*pb.RangeResponse)(resp), err
+}
+
 func (p *kvProxy) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
 	p.cache.Invalidate(r.Key, nil)
 	cacheKeys.Set(float64(p.cache.Size()))
@@ -179,12 +271,18 @@ func RangeRequestToOp(r *pb.RangeRequest) clientv3.Op {
 	if r.CountOnly {
 		opts = append(opts, clientv3.WithCountOnly())
 	}
+	if r.Estimate {
+		opts = append(opts, clientv3.WithEstimate())
+	}
 	if r.KeysOnly {
 		opts = append(opts, clientv3.WithKeysOnly())
 	}
 	if r.Serializable {
 		opts = append(opts, clientv3.WithSerializable())
 	}
+	if r.BoundedStaleness {
+		opts = append(opts, clientv3.WithBoundedStaleness())
+	}
 
 	return clientv3.OpGet(string(r.Key), opts...)
 }
@@ -201,6 +299,9 @@ func PutRequestToOp(r *pb.PutRequest) clientv3.Op {
 	if r.PrevKv {
 		opts = append(opts, clientv3.WithPrevKV())
 	}
+	if r.TtlSeconds != 0 {
+		opts = append(opts, clientv3.WithTTL(r.TtlSeconds))
+	}
 	return clientv3.OpPut(string(r.Key), string(r.Value), opts...)
 }
 
@@ -17,6 +17,7 @@ package grpcproxy
 import (
 	"context"
 	"io"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -91,16 +92,33 @@ func (lp *leaseProxy) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeRequest
 	return (*pb.LeaseRevokeResponse)(r), nil
 }
 
+func (lp *leaseProxy) LeaseGrantBatch(ctx context.Context, cr *pb.LeaseGrantBatchRequest) (*pb.LeaseGrantBatchResponse, error) {
+	rp, err := lp.leaseClient.LeaseGrantBatch(ctx, cr, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
+func (lp *leaseProxy) LeaseRevokeBatch(ctx context.Context, rr *pb.LeaseRevokeBatchRequest) (*pb.LeaseRevokeBatchResponse, error) {
+	rp, err := lp.leaseClient.LeaseRevokeBatch(ctx, rr)
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
 func (lp *leaseProxy) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
-	var (
-		r   *clientv3.LeaseTimeToLiveResponse
-		err error
-	)
+	var opts []clientv3.LeaseOption
 	if rr.Keys {
-		r, err = lp.lessor.TimeToLive(ctx, clientv3.LeaseID(rr.ID), clientv3.WithAttachedKeys())
-	} else {
-		r, err = lp.lessor.TimeToLive(ctx, clientv3.LeaseID(rr.ID))
+		opts = append(opts, clientv3.WithAttachedKeys())
+	}
+	if rr.Stats {
+		opts = append(opts, clientv3.WithLeaseStats())
 	}
+	r, err := lp.lessor.TimeToLive(ctx, clientv3.LeaseID(rr.ID), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +128,25 @@ func (lp *leaseProxy) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiv
 		TTL:        r.TTL,
 		GrantedTTL: r.GrantedTTL,
 		Keys:       r.Keys,
+		ParentID:   int64(r.ParentID),
+	}
+	for k, v := range r.Labels {
+		rp.Labels = append(rp.Labels, &pb.LeaseLabel{Key: k, Value: v})
+	}
+	sort.Slice(rp.Labels, func(i, j int) bool { return rp.Labels[i].Key < rp.Labels[j].Key })
+	if st := r.Stats; st != nil {
+		rp.Stats = &pb.LeaseStats{
+			Grantor:      st.Grantor,
+			AttachedKeys: st.AttachedKeys,
+			Renewals:     st.Renewals,
+			RenewRate:    st.RenewRate,
+		}
+		if !st.LastRenewTime.IsZero() {
+			rp.Stats.LastRenewTime = st.LastRenewTime.UnixNano()
+		}
+		if !st.StatsStartTime.IsZero() {
+			rp.Stats.StatsStartTime = st.StatsStartTime.UnixNano()
+		}
 	}
 	return rp, err
 }
@@ -130,6 +167,34 @@ func (lp *leaseProxy) LeaseLeases(ctx context.Context, rr *pb.LeaseLeasesRequest
 	return rp, err
 }
 
+func (lp *leaseProxy) LeaseQuery(ctx context.Context, rr *pb.LeaseQueryRequest) (*pb.LeaseQueryResponse, error) {
+	return lp.leaseClient.LeaseQuery(ctx, rr)
+}
+
+func (lp *leaseProxy) WatchExpirations(rr *pb.LeaseWatchExpirationsRequest, stream pb.Lease_WatchExpirationsServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	ctx = withClientAuthToken(ctx, stream.Context())
+
+	wc, err := lp.leaseClient.WatchExpirations(ctx, rr)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := wc.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
 func (lp *leaseProxy) LeaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) error {
 	lp.mu.Lock()
 	select {
@@ -85,3 +85,35 @@ func (mp *maintenanceProxy) MoveLeader(ctx context.Context, r *pb.MoveLeaderRequ
 func (mp *maintenanceProxy) Downgrade(ctx context.Context, r *pb.DowngradeRequest) (*pb.DowngradeResponse, error) {
 	return mp.maintenanceClient.Downgrade(ctx, r)
 }
+
+func (mp *maintenanceProxy) PrefixQuota(ctx context.Context, r *pb.PrefixQuotaRequest) (*pb.PrefixQuotaResponse, error) {
+	return mp.maintenanceClient.PrefixQuota(ctx, r)
+}
+
+func (mp *maintenanceProxy) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	return mp.maintenanceClient.HotKeys(ctx, r)
+}
+
+func (mp *maintenanceProxy) CorruptionCheck(ctx context.Context, r *pb.CorruptionCheckRequest) (*pb.CorruptionCheckResponse, error) {
+	return mp.maintenanceClient.CorruptionCheck(ctx, r)
+}
+
+func (mp *maintenanceProxy) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	return mp.maintenanceClient.PrefixStats(ctx, r)
+}
+
+func (mp *maintenanceProxy) RevisionPin(ctx context.Context, r *pb.RevisionPinRequest) (*pb.RevisionPinResponse, error) {
+	return mp.maintenanceClient.RevisionPin(ctx, r)
+}
+
+func (mp *maintenanceProxy) SnapshotRange(ctx context.Context, r *pb.SnapshotRangeRequest) (*pb.SnapshotRangeResponse, error) {
+	return mp.maintenanceClient.SnapshotRange(ctx, r)
+}
+
+func (mp *maintenanceProxy) WatchLag(ctx context.Context, r *pb.WatchLagRequest) (*pb.WatchLagResponse, error) {
+	return mp.maintenanceClient.WatchLag(ctx, r)
+}
+
+func (mp *maintenanceProxy) Drain(ctx context.Context, r *pb.DrainRequest) (*pb.DrainResponse, error) {
+	return mp.maintenanceClient.Drain(ctx, r)
+}
@@ -35,6 +35,18 @@ var (
 		Name:      "watchers_coalescing_total",
 		Help:      "Total number of current watchers coalescing",
 	})
+	watchGroups = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "watch_groups_total",
+		Help:      "Total number of current watch broadcast groups",
+	})
+	watchGroupWatchers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "watch_group_watchers_total",
+		Help:      "Total number of current watchers served by the watch broadcast groups",
+	})
 	eventsCoalescing = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "etcd",
 		Subsystem: "grpc_proxy",
@@ -59,14 +71,55 @@ var (
 		Name:      "cache_misses_total",
 		Help:      "Total number of cache misses",
 	})
+	admissionInflight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "admission_inflight_requests",
+		Help:      "Number of the requests holding a slot of the concurrent requests",
+	})
+	admissionRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "admission_rejected_total",
+		Help:      "Total number of the requests rejected by the admission control",
+	},
+		[]string{"priority", "reason"},
+	)
+	admissionBackendLatency = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "admission_backend_latency_seconds",
+		Help:      "Moving average of the latency of the requests admitted",
+	})
+	authTokenCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "auth_token_cache_requests_total",
+		Help:      "Total number of the authenticate requests served by the auth token cache",
+	},
+		[]string{"result"},
+	)
+	authTokenRefreshes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "auth_token_refreshes_total",
+		Help:      "Total number of the auth tokens refreshed by the auth token cache",
+	})
 )
 
 func init() {
 	prometheus.MustRegister(watchersCoalescing)
+	prometheus.MustRegister(watchGroups)
+	prometheus.MustRegister(watchGroupWatchers)
 	prometheus.MustRegister(eventsCoalescing)
 	prometheus.MustRegister(cacheKeys)
 	prometheus.MustRegister(cacheHits)
 	prometheus.MustRegister(cachedMisses)
+	prometheus.MustRegister(admissionInflight)
+	prometheus.MustRegister(admissionRejected)
+	prometheus.MustRegister(admissionBackendLatency)
+	prometheus.MustRegister(authTokenCacheRequests)
+	prometheus.MustRegister(authTokenRefreshes)
 }
 
 // HandleMetrics performs a GET request against etcd endpoint and returns '/metrics'.
@@ -0,0 +1,383 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// ErrCrossShard is returned for the requests on the keys of several shards.
+var ErrCrossShard = status.Error(codes.InvalidArgument, "grpcproxy: request spans multiple shards")
+
+// Shard is a backend etcd cluster serving the keys under Prefix. The keys
+// under none of the prefixes of the shards are served by the default cluster
+// of the proxy.
+type Shard struct {
+	Prefix string
+	Client *clientv3.Client
+}
+
+// shardRanges routes the keys to the shards of the longest prefix of the keys.
+type shardRanges struct {
+	// prefixes are the prefixes of the shards, the index len(prefixes) being
+	// the default shard of the empty prefix.
+	prefixes []string
+}
+
+// sortShards returns the shards ordered by decreasing length of prefix, for
+// the longest prefix to match first.
+func sortShards(shards []Shard) []Shard {
+	sorted := append([]Shard(nil), shards...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Prefix) > len(sorted[j].Prefix) })
+	return sorted
+}
+
+func newShardRanges(shards []Shard) shardRanges {
+	sr := shardRanges{prefixes: make([]string, len(shards))}
+	for i, s := range shards {
+		sr.prefixes[i] = s.Prefix
+	}
+	return sr
+}
+
+func (sr shardRanges) prefix(i int) string {
+	if i == len(sr.prefixes) {
+		return ""
+	}
+	return sr.prefixes[i]
+}
+
+// shardOf returns the shard of the key.
+func (sr shardRanges) shardOf(key []byte) int {
+	for i, p := range sr.prefixes {
+		if bytes.HasPrefix(key, []byte(p)) {
+			return i
+		}
+	}
+	return len(sr.prefixes)
+}
+
+// shardOfRange returns the shard of all the keys of the range [key, end), or
+// ErrCrossShard.
+func (sr shardRanges) shardOfRange(key, end []byte) (int, error) {
+	i := sr.shardOf(key)
+	if len(end) == 0 {
+		return i, nil
+	}
+	p := sr.prefix(i)
+	if p != "" && !rangeWithin(end, []byte(clientv3.GetPrefixRangeEnd(p))) {
+		return 0, ErrCrossShard
+	}
+	// the more specific shards must not serve keys of the range
+	for j, q := range sr.prefixes {
+		if j == i || len(q) <= len(p) || !strings.HasPrefix(q, p) {
+			continue
+		}
+		if rangesIntersect(key, end, []byte(q), []byte(clientv3.GetPrefixRangeEnd(q))) {
+			return 0, ErrCrossShard
+		}
+	}
+	return i, nil
+}
+
+// shardOfOp returns the shard of all the keys of the op, the default shard
+// for the ops without keys.
+func (sr shardRanges) shardOfOp(op clientv3.Op) (int, error) {
+	shard := -1
+	add := func(key, end []byte) error {
+		i, err := sr.shardOfRange(key, end)
+		if err != nil {
+			return err
+		}
+		if shard != -1 && shard != i {
+			return ErrCrossShard
+		}
+		shard = i
+		return nil
+	}
+
+	var walk func(op clientv3.Op) error
+	walk = func(op clientv3.Op) error {
+		if !op.IsTxn() {
+			return add(op.KeyBytes(), op.RangeBytes())
+		}
+		cmps, thenOps, elseOps := op.Txn()
+		for _, cmp := range cmps {
+			if err := add(cmp.Key, cmp.RangeEnd); err != nil {
+				return err
+			}
+		}
+		for _, ops := range [][]clientv3.Op{thenOps, elseOps} {
+			for _, op := range ops {
+				if err := walk(op); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(op); err != nil {
+		return 0, err
+	}
+	if shard == -1 {
+		return len(sr.prefixes), nil
+	}
+	return shard, nil
+}
+
+// rangeWithin returns whether the range ending at end is within the range
+// ending at limit, "\x00" ending the ranges of all the keys from their start.
+func rangeWithin(end, limit []byte) bool {
+	if isFromKey(limit) {
+		return true
+	}
+	return !isFromKey(end) && bytes.Compare(end, limit) <= 0
+}
+
+func rangesIntersect(key1, end1, key2, end2 []byte) bool {
+	return (isFromKey(end2) || bytes.Compare(key1, end2) < 0) && (isFromKey(end1) || bytes.Compare(key2, end1) < 0)
+}
+
+func isFromKey(end []byte) bool { return len(end) == 1 && end[0] == 0 }
+
+type shardedKV struct {
+	shardRanges
+	// kvs are the KVs of the shards, the last one of the default shard.
+	kvs []clientv3.KV
+}
+
+// NewShardedKV wraps the KV of the default cluster so that the requests on
+// the keys of the shards are served by their clusters. The requests on the
+// keys of several shards, including the compactions, fail with ErrCrossShard.
+func NewShardedKV(kv clientv3.KV, shards []Shard) clientv3.KV {
+	shards = sortShards(shards)
+	skv := &shardedKV{shardRanges: newShardRanges(shards)}
+	for _, s := range shards {
+		skv.kvs = append(skv.kvs, s.Client.KV)
+	}
+	skv.kvs = append(skv.kvs, kv)
+	return skv
+}
+
+func (kv *shardedKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpPut(key, val, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Put(), nil
+}
+
+func (kv *shardedKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpGet(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(), nil
+}
+
+func (kv *shardedKV) IndexGet(ctx context.Context, prefix, field, value string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	i, err := kv.shardOfRange([]byte(prefix), []byte(clientv3.GetPrefixRangeEnd(prefix)))
+	if err != nil {
+		return nil, err
+	}
+	return kv.kvs[i].IndexGet(ctx, prefix, field, value, opts...)
+}
+
+func (kv *shardedKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	r, err := kv.Do(ctx, clientv3.OpDelete(key, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return r.Del(), nil
+}
+
+// Compact fails, the revisions of the shards being unrelated.
+func (kv *shardedKV) Compact(ctx context.Context, rev int64, opts ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	return nil, ErrCrossShard
+}
+
+func (kv *shardedKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	i, err := kv.shardOfOp(op)
+	if err != nil {
+		return clientv3.OpResponse{}, err
+	}
+	return kv.kvs[i].Do(ctx, op)
+}
+
+func (kv *shardedKV) Txn(ctx context.Context) clientv3.Txn {
+	return &shardedTxn{ctx: ctx, kv: kv}
+}
+
+// shardedTxn collects a transaction to commit it on the shard of its keys.
+type shardedTxn struct {
+	ctx context.Context
+	kv  *shardedKV
+
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (txn *shardedTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	txn.cmps = append(txn.cmps, cs...)
+	return txn
+}
+
+func (txn *shardedTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.thenOps = append(txn.thenOps, ops...)
+	return txn
+}
+
+func (txn *shardedTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	txn.elseOps = append(txn.elseOps, ops...)
+	return txn
+}
+
+func (txn *shardedTxn) Commit() (*clientv3.TxnResponse, error) {
+	r, err := txn.kv.Do(txn.ctx, clientv3.OpTxn(txn.cmps, txn.thenOps, txn.elseOps))
+	if err != nil {
+		return nil, err
+	}
+	return r.Txn(), nil
+}
+
+type shardedWatcher struct {
+	shardRanges
+	// ws are the watchers of the shards, the last one of the default shard.
+	ws []clientv3.Watcher
+}
+
+// NewShardedWatcher wraps the watcher of the default cluster so that the
+// watches on the keys of the shards are served by their clusters. The watches
+// on the keys of several shards are closed.
+func NewShardedWatcher(w clientv3.Watcher, shards []Shard) clientv3.Watcher {
+	shards = sortShards(shards)
+	sw := &shardedWatcher{shardRanges: newShardRanges(shards)}
+	for _, s := range shards {
+		sw.ws = append(sw.ws, s.Client.Watcher)
+	}
+	sw.ws = append(sw.ws, w)
+	return sw
+}
+
+func (w *shardedWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	// since OpOption is opaque, determine the ranges through an OpGet
+	op := clientv3.OpGet(key, opts...)
+	i, err := w.shardOfRange(op.KeyBytes(), op.RangeBytes())
+	for _, r := range op.Ranges() {
+		if err != nil {
+			break
+		}
+		var j int
+		if j, err = w.shardOfRange([]byte(r.Key), []byte(r.End)); err == nil && j != i {
+			err = ErrCrossShard
+		}
+	}
+	if err != nil {
+		// the watch proxy rejects these watches on checking their permission
+		wch := make(chan clientv3.WatchResponse)
+		close(wch)
+		return wch
+	}
+	return w.ws[i].Watch(ctx, key, opts...)
+}
+
+func (w *shardedWatcher) RequestProgress(ctx context.Context) error {
+	for _, sw := range w.ws {
+		if err := sw.RequestProgress(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *shardedWatcher) Close() error {
+	var firstErr error
+	for _, sw := range w.ws {
+		if err := sw.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type shardedClusterProxy struct {
+	pb.ClusterServer
+	shards []Shard
+}
+
+// NewShardedClusterProxy wraps the cluster proxy of the default cluster so
+// that MemberList lists the members of all the shards. The other requests are
+// served by the default cluster.
+func NewShardedClusterProxy(cp pb.ClusterServer, shards []Shard) pb.ClusterServer {
+	return &shardedClusterProxy{ClusterServer: cp, shards: shards}
+}
+
+func (cp *shardedClusterProxy) MemberList(ctx context.Context, r *pb.MemberListRequest) (*pb.MemberListResponse, error) {
+	resp, err := cp.ClusterServer.MemberList(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range cp.shards {
+		sresp, err := pb.NewClusterClient(s.Client.ActiveConnection()).MemberList(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		resp.Members = append(resp.Members, sresp.Members...)
+	}
+	return resp, nil
+}
+
+type shardedMaintenanceProxy struct {
+	pb.MaintenanceServer
+	shards []Shard
+}
+
+// NewShardedMaintenanceProxy wraps the maintenance proxy of the default cluster
+// so that Status sums up the database sizes of all the shards, and reports the
+// errors of the shards. The other requests are served by the default cluster.
+func NewShardedMaintenanceProxy(mp pb.MaintenanceServer, shards []Shard) pb.MaintenanceServer {
+	return &shardedMaintenanceProxy{MaintenanceServer: mp, shards: shards}
+}
+
+func (mp *shardedMaintenanceProxy) Status(ctx context.Context, r *pb.StatusRequest) (*pb.StatusResponse, error) {
+	resp, err := mp.MaintenanceServer.Status(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range mp.shards {
+		sresp, err := pb.NewMaintenanceClient(s.Client.ActiveConnection()).Status(ctx, r)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("shard %q: %v", s.Prefix, err))
+			continue
+		}
+		resp.DbSize += sresp.DbSize
+		resp.DbSizeInUse += sresp.DbSizeInUse
+		for _, e := range sresp.Errors {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("shard %q: %s", s.Prefix, e))
+		}
+	}
+	return resp, nil
+}
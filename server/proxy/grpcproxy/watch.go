@@ -27,6 +27,7 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 )
 
 type watchProxy struct {
@@ -208,21 +209,36 @@ func (wps *watchProxyStream) close() {
 }
 
 func (wps *watchProxyStream) checkPermissionForWatch(key, rangeEnd []byte) error {
+	_, err := wps.kv.Do(wps.ctx, RangeRequestToOp(permissionRangeRequest(key, rangeEnd)))
+	return err
+}
+
+// checkPermissionForWatchRanges checks the permission for a watch on several
+// key ranges in a single transaction, so that the ranges are checked, and
+// then watched, on the same backend of a sharded proxy.
+func (wps *watchProxyStream) checkPermissionForWatchRanges(cr *pb.WatchCreateRequest) error {
+	ops := []clientv3.Op{RangeRequestToOp(permissionRangeRequest(cr.Key, cr.RangeEnd))}
+	for _, r := range cr.Ranges {
+		ops = append(ops, RangeRequestToOp(permissionRangeRequest(r.Key, r.RangeEnd)))
+	}
+	_, err := wps.kv.Do(wps.ctx, clientv3.OpTxn(nil, ops, nil))
+	return err
+}
+
+func permissionRangeRequest(key, rangeEnd []byte) *pb.RangeRequest {
 	if len(key) == 0 {
 		// If the length of the key is 0, we need to obtain full range.
 		// look at clientv3.WithPrefix()
 		key = []byte{0}
 		rangeEnd = []byte{0}
 	}
-	req := &pb.RangeRequest{
+	return &pb.RangeRequest{
 		Serializable: true,
 		Key:          key,
 		RangeEnd:     rangeEnd,
 		CountOnly:    true,
 		Limit:        1,
 	}
-	_, err := wps.kv.Do(wps.ctx, RangeRequestToOp(req))
-	return err
 }
 
 func (wps *watchProxyStream) recvLoop() error {
@@ -235,7 +251,67 @@ func (wps *watchProxyStream) recvLoop() error {
 		case *pb.WatchRequest_CreateRequest:
 			cr := uv.CreateRequest
 
-			if err := wps.checkPermissionForWatch(cr.Key, cr.RangeEnd); err != nil {
+			filters, ferr := v3rpc.FiltersFromRequest(cr)
+			if ferr != nil {
+				wps.watchCh <- &pb.WatchResponse{
+					Header:       &pb.ResponseHeader{},
+					WatchId:      clientv3.InvalidWatchID,
+					Created:      true,
+					Canceled:     true,
+					CancelReason: rpctypes.ErrorDesc(rpctypes.ErrGRPCInvalidWatchValueFilter),
+				}
+				continue
+			}
+
+			if len(cr.Ranges) > 0 {
+				if rerr := mvcc.ValidateWatchRanges(v3rpc.KeyRangesFromRequest(cr)); rerr == mvcc.ErrWatcherOverlappingRanges {
+					wps.watchCh <- &pb.WatchResponse{
+						Header:       &pb.ResponseHeader{},
+						WatchId:      clientv3.InvalidWatchID,
+						Created:      true,
+						Canceled:     true,
+						CancelReason: rerr.Error(),
+					}
+					continue
+				}
+			}
+
+			if !v3rpc.ValidWatchProjection(cr.Projection) {
+				wps.watchCh <- &pb.WatchResponse{
+					Header:       &pb.ResponseHeader{},
+					WatchId:      clientv3.InvalidWatchID,
+					Created:      true,
+					Canceled:     true,
+					CancelReason: rpctypes.ErrorDesc(rpctypes.ErrGRPCInvalidWatchProjection),
+				}
+				continue
+			}
+
+			nextrev := cr.StartRevision
+			if len(cr.ResumeToken) > 0 {
+				// the proxy does not check the cluster of the token, it only
+				// learns the cluster ID from the responses of the cluster
+				_, rev, terr := v3rpc.ParseWatchResumeToken(cr.ResumeToken)
+				if terr != nil || cr.StartRevision != 0 {
+					wps.watchCh <- &pb.WatchResponse{
+						Header:       &pb.ResponseHeader{},
+						WatchId:      clientv3.InvalidWatchID,
+						Created:      true,
+						Canceled:     true,
+						CancelReason: rpctypes.ErrorDesc(rpctypes.ErrGRPCInvalidWatchResumeToken),
+					}
+					continue
+				}
+				nextrev = rev + 1
+			}
+
+			var err error
+			if len(cr.Ranges) > 0 {
+				err = wps.checkPermissionForWatchRanges(cr)
+			} else {
+				err = wps.checkPermissionForWatch(cr.Key, cr.RangeEnd)
+			}
+			if err != nil {
 				wps.watchCh <- &pb.WatchResponse{
 					Header:       &pb.ResponseHeader{},
 					WatchId:      clientv3.InvalidWatchID,
@@ -248,14 +324,15 @@ func (wps *watchProxyStream) recvLoop() error {
 
 			wps.mu.Lock()
 			w := &watcher{
-				wr:  watchRange{string(cr.Key), string(cr.RangeEnd)},
+				wr:  newWatchRange(cr),
 				id:  wps.nextWatcherID,
 				wps: wps,
 
-				nextrev:  cr.StartRevision,
-				progress: cr.ProgressNotify,
-				prevKV:   cr.PrevKv,
-				filters:  v3rpc.FiltersFromRequest(cr),
+				nextrev:    nextrev,
+				progress:   cr.ProgressNotify,
+				prevKV:     cr.PrevKv,
+				projection: cr.Projection,
+				filters:    filters,
 			}
 			if !w.wr.valid() {
 				w.post(&pb.WatchResponse{WatchId: clientv3.InvalidWatchID, Created: true, Canceled: true})
@@ -263,7 +340,7 @@ func (wps *watchProxyStream) recvLoop() error {
 				continue
 			}
 			wps.nextWatcherID++
-			w.nextrev = cr.StartRevision
+			w.nextrev = nextrev
 			wps.watchers[w.id] = w
 			wps.ranges.add(w)
 			wps.mu.Unlock()
@@ -62,6 +62,9 @@ func newWatchBroadcast(lg *zap.Logger, wp *watchProxy, w *watcher, update func(*
 			clientv3.WithPrevKV(),
 			clientv3.WithCreatedNotify(),
 		}
+		if ranges := w.wr.keyRanges(); len(ranges) > 0 {
+			opts = append(opts, clientv3.WithRanges(ranges...))
+		}
 
 		cctx = withClientAuthToken(cctx, w.wps.stream.Context())
 
@@ -105,6 +108,7 @@ func (wb *watchBroadcast) add(w *watcher) bool {
 	if wb.responses == 0 {
 		// Newly created; create event will be sent by etcd.
 		wb.receivers[w] = struct{}{}
+		watchGroupWatchers.Inc()
 		return true
 	}
 	// already sent by etcd; emulate create event
@@ -123,6 +127,7 @@ func (wb *watchBroadcast) add(w *watcher) bool {
 	}
 	wb.receivers[w] = struct{}{}
 	watchersCoalescing.Inc()
+	watchGroupWatchers.Inc()
 
 	return true
 }
@@ -133,6 +138,7 @@ func (wb *watchBroadcast) delete(w *watcher) {
 		panic("deleting missing watcher from broadcast")
 	}
 	delete(wb.receivers, w)
+	watchGroupWatchers.Dec()
 	if len(wb.receivers) > 0 {
 		// do not dec the only left watcher for coalescing.
 		watchersCoalescing.Dec()
@@ -151,6 +157,7 @@ func (wb *watchBroadcast) stop() {
 	if !wb.empty() {
 		// do not dec the only left watcher for coalescing.
 		watchersCoalescing.Sub(float64(wb.size() - 1))
+		watchGroupWatchers.Sub(float64(wb.size()))
 	}
 
 	wb.cancel()
@@ -75,6 +75,7 @@ func (wbs *watchBroadcasts) coalesce(wb *watchBroadcast) {
 		wb.mu.Unlock()
 		if wb.empty() {
 			delete(wbs.bcasts, wb)
+			watchGroups.Dec()
 			wb.stop()
 			break
 		}
@@ -96,6 +97,7 @@ func (wbs *watchBroadcasts) add(w *watcher) {
 	wb := newWatchBroadcast(wbs.wp.lg, wbs.wp, w, wbs.update)
 	wbs.watchers[w] = wb
 	wbs.bcasts[wb] = struct{}{}
+	watchGroups.Inc()
 }
 
 // delete removes a watcher and returns the number of remaining watchers.
@@ -111,6 +113,7 @@ func (wbs *watchBroadcasts) delete(w *watcher) int {
 	wb.delete(w)
 	if wb.empty() {
 		delete(wbs.bcasts, wb)
+		watchGroups.Dec()
 		wb.stop()
 	}
 	return len(wbs.bcasts)
@@ -121,6 +124,7 @@ func (wbs *watchBroadcasts) stop() {
 	for wb := range wbs.bcasts {
 		wb.stop()
 	}
+	watchGroups.Sub(float64(len(wbs.bcasts)))
 	wbs.bcasts = nil
 	close(wbs.updatec)
 	wbs.mu.Unlock()
@@ -20,15 +20,53 @@ import (
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 )
 
 type watchRange struct {
 	key, end string
+	// ranges are the other key ranges watched, encoded so that the
+	// watchers of the same key ranges share their broadcast.
+	ranges string
+}
+
+func newWatchRange(cr *pb.WatchCreateRequest) watchRange {
+	wr := watchRange{key: string(cr.Key), end: string(cr.RangeEnd)}
+	if len(cr.Ranges) > 0 {
+		b, _ := (&pb.WatchCreateRequest{Ranges: cr.Ranges}).Marshal()
+		wr.ranges = string(b)
+	}
+	return wr
+}
+
+// keyRanges returns the other key ranges watched.
+func (wr *watchRange) keyRanges() []clientv3.KeyRange {
+	if wr.ranges == "" {
+		return nil
+	}
+	var cr pb.WatchCreateRequest
+	if err := cr.Unmarshal([]byte(wr.ranges)); err != nil {
+		panic(err)
+	}
+	ranges := make([]clientv3.KeyRange, len(cr.Ranges))
+	for i, r := range cr.Ranges {
+		ranges[i] = clientv3.KeyRange{Key: string(r.Key), End: string(r.RangeEnd)}
+	}
+	return ranges
 }
 
 func (wr *watchRange) valid() bool {
-	return len(wr.end) == 0 || wr.end > wr.key || (wr.end[0] == 0 && len(wr.end) == 1)
+	for _, r := range wr.keyRanges() {
+		if !validRange(r.Key, r.End) {
+			return false
+		}
+	}
+	return validRange(wr.key, wr.end)
+}
+
+func validRange(key, end string) bool {
+	return len(end) == 0 || end > key || (end[0] == 0 && len(end) == 1)
 }
 
 type watcher struct {
@@ -38,6 +76,8 @@ type watcher struct {
 	filters  []mvcc.FilterFunc
 	progress bool
 	prevKV   bool
+	// projection strips the events down to the parts it keeps.
+	projection pb.WatchCreateRequest_Projection
 
 	// id is the id returned to the client on its watch stream.
 	id int64
@@ -89,7 +129,9 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 			continue
 		}
 
-		if !w.prevKV {
+		if w.projection != pb.WatchCreateRequest_FULL {
+			ev = v3rpc.ProjectEvent(ev, w.projection)
+		} else if !w.prevKV {
 			evCopy := *ev
 			evCopy.PrevKv = nil
 			ev = &evCopy
@@ -102,18 +144,27 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 	}
 
 	// all events are filtered out?
-	if !wr.IsProgressNotify() && !wr.Created && len(events) == 0 && wr.CompactRevision == 0 {
+	if !wr.IsProgressNotify() && !wr.Created && len(events) == 0 && wr.CompactRevision == 0 && wr.DroppedEvents == 0 {
 		return
 	}
 
+	var resumeToken []byte
+	if wr.IsProgressNotify() && w.nextrev <= wr.Header.Revision+1 {
+		// the watcher received all the events up to the notification
+		resumeToken = wr.ResumeToken
+	}
+
 	w.lastHeader = wr.Header
 	w.post(&pb.WatchResponse{
 		Header:          &wr.Header,
 		Created:         wr.Created,
 		CompactRevision: wr.CompactRevision,
+		LostRevisions:   wr.LostRevisions,
 		Canceled:        wr.Canceled,
 		WatchId:         w.id,
 		Events:          events,
+		ResumeToken:     resumeToken,
+		DroppedEvents:   wr.DroppedEvents,
 	})
 }
 
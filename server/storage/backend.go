@@ -50,7 +50,13 @@ func newBackend(cfg config.ServerConfig, hooks backend.Hooks) backend.Backend {
 		// permit 10% excess over quota for disarm
 		bcfg.MmapSize = uint64(cfg.QuotaBackendBytes + cfg.QuotaBackendBytes/10)
 	}
+	if cfg.QuotaBackendBytes >= 0 && cfg.MaxQuotaBackendBytes > cfg.QuotaBackendBytes {
+		// the quota may grow up to the hard cap
+		bcfg.MmapSize = uint64(cfg.MaxQuotaBackendBytes + cfg.MaxQuotaBackendBytes/10)
+	}
 	bcfg.Mlock = cfg.ExperimentalMemoryMlock
+	bcfg.IncrementalDefrag = cfg.ExperimentalIncrementalDefrag
+	bcfg.Engine = cfg.ExperimentalBackendEngine
 	bcfg.Hooks = hooks
 	return backend.New(bcfg)
 }
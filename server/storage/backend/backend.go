@@ -15,11 +15,8 @@
 package backend
 
 import (
-	"fmt"
 	"hash/crc32"
 	"io"
-	"os"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -66,6 +63,9 @@ type Backend interface {
 	// OpenReadTxN returns the number of currently open read transactions in the backend.
 	OpenReadTxN() int64
 	Defrag() error
+	// Check runs a consistency check of the data of the backend
+	// engine, returning an error wrapping ErrCorrupted if it fails.
+	Check() error
 	ForceCommit()
 	Close() error
 
@@ -102,10 +102,12 @@ type backend struct {
 	openReadTxN int64
 	// mlock prevents backend database file to be swapped
 	mlock bool
+	// incrementalDefrag copies the database in batches while serving
+	// requests, instead of blocking them for the whole defragmentation.
+	incrementalDefrag bool
 
-	mu    sync.RWMutex
-	bopts *bolt.Options
-	db    *bolt.DB
+	mu     sync.RWMutex
+	engine Engine
 
 	batchInterval time.Duration
 	batchLimit    int
@@ -123,6 +125,12 @@ type backend struct {
 
 	hooks Hooks
 
+	// defragMu serializes defragmentations.
+	defragMu sync.Mutex
+	// defragJournal records the changes made during an incremental
+	// defragmentation. It is protected by the batchTx lock.
+	defragJournal *defragJournal
+
 	// txPostLockInsideApplyHook is called each time right after locking the tx.
 	txPostLockInsideApplyHook func()
 
@@ -136,6 +144,10 @@ type BackendConfig struct {
 	BatchInterval time.Duration
 	// BatchLimit is the maximum puts before flushing the BatchTx.
 	BatchLimit int
+	// Engine is the storage engine of the backend. If empty, it is the
+	// engine of the existing file, EngineBolt for a new one. The file of
+	// another engine is converted.
+	Engine string
 	// BackendFreelistType is the backend boltdb's freelist type.
 	BackendFreelistType bolt.FreelistType
 	// MmapSize is the number of bytes to mmap for the backend.
@@ -146,6 +158,9 @@ type BackendConfig struct {
 	UnsafeNoFsync bool `json:"unsafe-no-fsync"`
 	// Mlock prevents backend database file to be swapped
 	Mlock bool
+	// IncrementalDefrag defragments the backend in batches, serving reads
+	// and writes between them, with a short final cutover.
+	IncrementalDefrag bool
 
 	// Hooks are getting executed during lifecycle of Backend's transactions.
 	Hooks Hooks
@@ -171,17 +186,7 @@ func NewDefaultBackend(lg *zap.Logger, path string) Backend {
 }
 
 func newBackend(bcfg BackendConfig) *backend {
-	bopts := &bolt.Options{}
-	if boltOpenOptions != nil {
-		*bopts = *boltOpenOptions
-	}
-	bopts.InitialMmapSize = bcfg.mmapSize()
-	bopts.FreelistType = bcfg.BackendFreelistType
-	bopts.NoSync = bcfg.UnsafeNoFsync
-	bopts.NoGrowSync = bcfg.UnsafeNoFsync
-	bopts.Mlock = bcfg.Mlock
-
-	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	engine, err := openEngine(bcfg)
 	if err != nil {
 		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.Error(err))
 	}
@@ -189,20 +194,21 @@ func newBackend(bcfg BackendConfig) *backend {
 	// In future, may want to make buffering optional for low-concurrency systems
 	// or dynamically swap between buffered/non-buffered depending on workload.
 	b := &backend{
-		bopts: bopts,
-		db:    db,
+		engine: engine,
 
 		batchInterval: bcfg.BatchInterval,
 		batchLimit:    bcfg.BatchLimit,
 		mlock:         bcfg.Mlock,
 
+		incrementalDefrag: bcfg.IncrementalDefrag,
+
 		readTx: &readTx{
 			baseReadTx: baseReadTx{
 				buf: txReadBuffer{
 					txBuffer:   txBuffer{make(map[BucketID]*bucketBuffer)},
 					bufVersion: 0,
 				},
-				buckets: make(map[BucketID]*bolt.Bucket),
+				buckets: make(map[BucketID]EngineBucket),
 				txWg:    new(sync.WaitGroup),
 				txMu:    new(sync.RWMutex),
 			},
@@ -332,13 +338,17 @@ func (b *backend) Snapshot() Snapshot {
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	tx, err := b.db.Begin(false)
+	tx, err := b.engine.Begin(false)
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
 	}
+	snap, err := tx.Snapshot()
+	if err != nil {
+		b.lg.Fatal("failed to snapshot", zap.Error(err))
+	}
 
 	stopc, donec := make(chan struct{}), make(chan struct{})
-	dbBytes := tx.Size()
+	dbBytes := snap.Size()
 	go func() {
 		defer close(donec)
 		// sendRateBytes is based on transferring snapshot data over a 1 gigabit/s connection
@@ -368,7 +378,7 @@ func (b *backend) Snapshot() Snapshot {
 		}
 	}()
 
-	return &snapshot{tx, stopc, donec}
+	return &snapshot{snap, stopc, donec}
 }
 
 func (b *backend) Hash(ignores func(bucketName, keyName []byte) bool) (uint32, error) {
@@ -376,22 +386,20 @@ func (b *backend) Hash(ignores func(bucketName, keyName []byte) bool) (uint32, e
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	err := b.db.View(func(tx *bolt.Tx) error {
-		c := tx.Cursor()
-		for next, _ := c.First(); next != nil; next, _ = c.Next() {
-			b := tx.Bucket(next)
-			if b == nil {
-				return fmt.Errorf("cannot get hash of bucket %s", string(next))
+	tx, err := b.engine.Begin(false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	err = tx.ForEachBucket(func(next []byte, b EngineBucket) error {
+		h.Write(next)
+		b.ForEach(func(k, v []byte) error {
+			if ignores != nil && !ignores(next, k) {
+				h.Write(k)
+				h.Write(v)
 			}
-			h.Write(next)
-			b.ForEach(func(k, v []byte) error {
-				if ignores != nil && !ignores(next, k) {
-					h.Write(k)
-					h.Write(v)
-				}
-				return nil
-			})
-		}
+			return nil
+		})
 		return nil
 	})
 
@@ -433,7 +441,7 @@ func (b *backend) Close() error {
 	<-b.donec
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	return b.db.Close()
+	return b.engine.Close()
 }
 
 // Commits returns total number of commits since start
@@ -442,103 +450,36 @@ func (b *backend) Commits() int64 {
 }
 
 func (b *backend) Defrag() error {
-	return b.defrag()
-}
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
 
-func (b *backend) defrag() error {
 	now := time.Now()
 	isDefragActive.Set(1)
 	defer isDefragActive.Set(0)
 
-	// TODO: make this non-blocking?
-	// lock batchTx to ensure nobody is using previous tx, and then
-	// close previous ongoing tx.
-	b.batchTx.LockOutsideApply()
-	defer b.batchTx.Unlock()
-
-	// lock database after lock tx to avoid deadlock.
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	// block concurrent read requests while resetting tx
-	b.readTx.Lock()
-	defer b.readTx.Unlock()
-
-	b.batchTx.unsafeCommit(true)
-
-	b.batchTx.tx = nil
-
-	// Create a temporary file to ensure we start with a clean slate.
-	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
-	dir := filepath.Dir(b.db.Path())
-	temp, err := os.CreateTemp(dir, "db.tmp.*")
-	if err != nil {
-		return err
-	}
-	options := bolt.Options{}
-	if boltOpenOptions != nil {
-		options = *boltOpenOptions
-	}
-	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
-		return temp, nil
-	}
-	// Don't load tmp db into memory regardless of opening options
-	options.Mlock = false
-	tdbp := temp.Name()
-	tmpdb, err := bolt.Open(tdbp, 0600, &options)
-	if err != nil {
-		return err
-	}
-
-	dbp := b.db.Path()
+	dbp := b.engine.Path()
 	size1, sizeInUse1 := b.Size(), b.SizeInUse()
 	if b.lg != nil {
 		b.lg.Info(
 			"defragmenting",
 			zap.String("path", dbp),
+			zap.Bool("incremental", b.incrementalDefrag),
 			zap.Int64("current-db-size-bytes", size1),
 			zap.String("current-db-size", humanize.Bytes(uint64(size1))),
 			zap.Int64("current-db-size-in-use-bytes", sizeInUse1),
 			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse1))),
 		)
 	}
-	// gofail: var defragBeforeCopy struct{}
-	err = defragdb(b.db, tmpdb, defragLimit)
-	if err != nil {
-		tmpdb.Close()
-		if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
-			b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
-		}
-		return err
-	}
 
-	err = b.db.Close()
-	if err != nil {
-		b.lg.Fatal("failed to close database", zap.Error(err))
-	}
-	err = tmpdb.Close()
-	if err != nil {
-		b.lg.Fatal("failed to close tmp database", zap.Error(err))
+	var err error
+	if b.incrementalDefrag {
+		err = b.defragIncremental()
+	} else {
+		err = b.defrag()
 	}
-	// gofail: var defragBeforeRename struct{}
-	err = os.Rename(tdbp, dbp)
 	if err != nil {
-		b.lg.Fatal("failed to rename tmp database", zap.Error(err))
-	}
-
-	b.db, err = bolt.Open(dbp, 0600, b.bopts)
-	if err != nil {
-		b.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
+		return err
 	}
-	b.batchTx.tx = b.unsafeBegin(true)
-
-	b.readTx.reset()
-	b.readTx.tx = b.unsafeBegin(false)
-
-	size := b.readTx.tx.Size()
-	db := b.readTx.tx.DB()
-	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(db.Stats().FreePageN)*int64(db.Info().PageSize)))
 
 	took := time.Since(now)
 	defragSec.Observe(took.Seconds())
@@ -560,83 +501,70 @@ func (b *backend) defrag() error {
 	return nil
 }
 
-func defragdb(odb, tmpdb *bolt.DB, limit int) error {
-	// open a tx on tmpdb for writes
-	tmptx, err := tmpdb.Begin(true)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tmptx.Rollback()
-		}
-	}()
+func (b *backend) defrag() error {
+	// lock batchTx to ensure nobody is using previous tx, and then
+	// close previous ongoing tx.
+	b.batchTx.LockOutsideApply()
+	defer b.batchTx.Unlock()
 
-	// open a tx on old db for read
-	tx, err := odb.Begin(false)
-	if err != nil {
+	// lock database after lock tx to avoid deadlock.
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// block concurrent read requests while resetting tx
+	b.readTx.Lock()
+	defer b.readTx.Unlock()
+
+	b.batchTx.unsafeCommit(true)
+
+	b.batchTx.tx = nil
+
+	if err := b.engine.Defrag(); err != nil {
 		return err
 	}
-	defer tx.Rollback()
 
-	c := tx.Cursor()
+	b.unsafeBeginTxs()
+	return nil
+}
 
-	count := 0
-	for next, _ := c.First(); next != nil; next, _ = c.Next() {
-		b := tx.Bucket(next)
-		if b == nil {
-			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
-		}
+// unsafeBeginTxs begins the batch and read transactions after the database
+// is replaced. It must be called holding the batchTx, mu and readTx locks,
+// after the batchTx is committed and stopped.
+func (b *backend) unsafeBeginTxs() {
+	b.batchTx.tx = b.unsafeBegin(true)
 
-		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
-		if berr != nil {
-			return berr
-		}
-		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-		if err = b.ForEach(func(k, v []byte) error {
-			count++
-			if count > limit {
-				err = tmptx.Commit()
-				if err != nil {
-					return err
-				}
-				tmptx, err = tmpdb.Begin(true)
-				if err != nil {
-					return err
-				}
-				tmpb = tmptx.Bucket(next)
-				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-				count = 0
-			}
-			return tmpb.Put(k, v)
-		}); err != nil {
-			return err
-		}
-	}
+	b.readTx.reset()
+	b.readTx.tx = b.unsafeBegin(false)
 
-	return tmptx.Commit()
+	b.updateSize(b.readTx.tx.Size(), b.engine.Stats())
 }
 
-func (b *backend) begin(write bool) *bolt.Tx {
+func (b *backend) begin(write bool) EngineTx {
 	b.mu.RLock()
 	tx := b.unsafeBegin(write)
+	stats := b.engine.Stats()
 	b.mu.RUnlock()
 
-	size := tx.Size()
-	db := tx.DB()
-	stats := db.Stats()
-	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(stats.FreePageN)*int64(db.Info().PageSize)))
-	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenTxN))
+	b.updateSize(tx.Size(), stats)
+	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenReadTxN))
 
 	return tx
 }
 
-func (b *backend) unsafeBegin(write bool) *bolt.Tx {
+// updateSize records the size of the database and the space not in use.
+func (b *backend) updateSize(size int64, stats EngineStats) {
+	atomic.StoreInt64(&b.size, size)
+	atomic.StoreInt64(&b.sizeInUse, size-stats.FreeBytes)
+	freeBytes.Set(float64(stats.FreeBytes))
+	freelistBytes.Set(float64(stats.FreelistBytes))
+	if size > 0 {
+		fragmentationRatio.Set(float64(stats.FreeBytes) / float64(size))
+	}
+}
+
+func (b *backend) unsafeBegin(write bool) EngineTx {
 	// gofail: var beforeStartDBTxn struct{}
-	tx, err := b.db.Begin(write)
+	tx, err := b.engine.Begin(write)
 	// gofail: var afterStartDBTxn struct{}
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
@@ -649,7 +577,7 @@ func (b *backend) OpenReadTxN() int64 {
 }
 
 type snapshot struct {
-	*bolt.Tx
+	Snapshot
 	stopc chan struct{}
 	donec chan struct{}
 }
@@ -657,5 +585,5 @@ type snapshot struct {
 func (s *snapshot) Close() error {
 	close(s.stopc)
 	<-s.donec
-	return s.Tx.Rollback()
+	return s.Snapshot.Close()
 }
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	bolt "go.etcd.io/bbolt"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
@@ -193,6 +194,117 @@ func TestBackendDefrag(t *testing.T) {
 	b.ForceCommit()
 }
 
+// TestBackendFreelistStats ensures the space freed by deletions is reported.
+func TestBackendFreelistStats(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < backend.DefragLimitForTest()+100; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("foo_%d", i)), []byte("bar"))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	tx = b.BatchTx()
+	tx.Lock()
+	for i := 0; i < backend.DefragLimitForTest(); i++ {
+		tx.UnsafeDelete(schema.Test, []byte(fmt.Sprintf("foo_%d", i)))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+	// the pages of the deletions are only released once the read txs opened
+	// before them are rolled back, asynchronously to the next commits
+	require.Eventually(t, func() bool {
+		tx.Lock()
+		tx.UnsafePut(schema.Test, []byte("more"), []byte("bar"))
+		tx.Unlock()
+		b.ForceCommit()
+		return backend.EngineStatsForTest(b).FreeBytes > 0
+	}, time.Second, 10*time.Millisecond)
+
+	stats := backend.EngineStatsForTest(b)
+	assert.Greater(t, stats.FreelistBytes, int64(0))
+	assert.Less(t, b.SizeInUse(), b.Size())
+}
+
+// TestBackendIncrementalDefrag ensures the changes made while the backend is
+// being defragmented incrementally are kept.
+func TestBackendIncrementalDefrag(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.IncrementalDefrag = true
+	b, _ := betesting.NewTmpBackendFromCfg(t, bcfg)
+	defer betesting.Close(t, b)
+	defer backend.SetDefragBatchLimitForTest(100)()
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < 300; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("foo_%03d", i)), []byte("bar"))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	batches := 0
+	defer backend.SetAfterDefragBatchForTest(func() {
+		batches++
+		if batches != 1 {
+			return
+		}
+		// the first 100 keys are copied, the others are not yet
+		tx := b.BatchTx()
+		tx.Lock()
+		tx.UnsafePut(schema.Test, []byte("foo_010"), []byte("baz"))
+		tx.UnsafeDelete(schema.Test, []byte("foo_020"))
+		tx.UnsafeDelete(schema.Test, []byte("foo_200"))
+		tx.UnsafePut(schema.Test, []byte("zzz"), []byte("bar"))
+		tx.UnsafeCreateBucket(schema.Alarm)
+		tx.UnsafePut(schema.Alarm, []byte("alarm"), []byte("bar"))
+		tx.Unlock()
+	})()
+
+	if err := b.Defrag(); err != nil {
+		t.Fatal(err)
+	}
+	if batches < 3 {
+		t.Errorf("batches = %d, want at least 3", batches)
+	}
+
+	tests := []struct {
+		bucket backend.Bucket
+		key    string
+		wval   []byte
+	}{
+		{schema.Test, "foo_000", []byte("bar")},
+		{schema.Test, "foo_010", []byte("baz")},
+		{schema.Test, "foo_020", nil},
+		{schema.Test, "foo_200", nil},
+		{schema.Test, "foo_299", []byte("bar")},
+		{schema.Test, "zzz", []byte("bar")},
+		{schema.Alarm, "alarm", []byte("bar")},
+	}
+	tx = b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	for _, tt := range tests {
+		_, vals := tx.UnsafeRange(tt.bucket, []byte(tt.key), nil, 0)
+		var val []byte
+		if len(vals) > 0 {
+			val = vals[0]
+		}
+		if !reflect.DeepEqual(val, tt.wval) {
+			t.Errorf("%s/%s = %q, want %q", tt.bucket, tt.key, val, tt.wval)
+		}
+	}
+	keys, _ := tx.UnsafeRange(schema.Test, []byte("foo_"), []byte("foo`"), 0)
+	if len(keys) != 298 {
+		t.Errorf("len(keys) = %d, want 298", len(keys))
+	}
+}
+
 // TestBackendWriteback ensures writes are stored to the read txn on write txn unlock.
 func TestBackendWriteback(t *testing.T) {
 	b, _ := betesting.NewDefaultTmpBackend(t)
@@ -0,0 +1,104 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrCorrupted is returned, wrapped, when the integrity check of a backend fails.
+var ErrCorrupted = errors.New("backend integrity check failed")
+
+// maxCheckErrors is the number of inconsistencies reported by a check; a
+// corrupted page tree can otherwise produce an error for every page.
+const maxCheckErrors = 10
+
+// Check runs a consistency check of the backend engine. For bbolt, it runs
+// the bolt consistency check, which walks the page tree of every bucket and
+// verifies that every page is either reachable once or free and that the
+// keys are ordered.
+func (b *backend) Check() error {
+	// hold the batch tx lock so that no commit changes the pages while they
+	// are checked.
+	b.batchTx.LockOutsideApply()
+	defer b.batchTx.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.engine.Check()
+}
+
+// CheckFile runs the same consistency check as Backend.Check on the
+// database file at the given path, for example a snapshot file, which must
+// not be in use by another process.
+func CheckFile(path string) error {
+	isLog, err := isLogFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if isLog {
+		return checkLogFile(path)
+	}
+	db, err := bolt.Open(path, 0400, &bolt.Options{ReadOnly: true, PreLoadFreelist: true})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	defer db.Close()
+	return checkDB(db)
+}
+
+// checkLogFile verifies the records of the log at path. A partially written
+// last record is not a corruption, it is truncated when the log is opened.
+func checkLogFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if err = checkLog(f, fi.Size()); err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return nil
+}
+
+func checkDB(db *bolt.DB) error {
+	var msgs []string
+	err := db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			// drain the channel so that the check goroutine completes.
+			if len(msgs) < maxCheckErrors {
+				msgs = append(msgs, err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		msgs = append(msgs, err.Error())
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrCorrupted, strings.Join(msgs, "; "))
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestBackendCheck(t *testing.T) {
+	b, path := betesting.NewDefaultTmpBackend(t)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < 1000; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("key%04d", i)), make([]byte, 100))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	assert.NoError(t, b.Check())
+	betesting.Close(t, b)
+	assert.NoError(t, backend.CheckFile(path))
+}
+
+func TestCheckFileCorrupted(t *testing.T) {
+	// bbolt panics when opening a database with a corrupted page tree and no
+	// freelist written to disk, so the freelist is synced here.
+	path := filepath.Join(t.TempDir(), "db")
+	db, err := bolt.Open(path, 0600, &bolt.Options{NoFreelistSync: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("test"))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 1000; i++ {
+			if err = bucket.Put([]byte(fmt.Sprintf("key%04d", i)), make([]byte, 100)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.Close())
+	assert.NoError(t, backend.CheckFile(path))
+
+	// change a key of a leaf page so that the keys of the page are no longer
+	// sorted. bolt still opens the file.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(data, []byte("key0500"))
+	if i < 0 {
+		t.Fatal("key0500 not found in the database file")
+	}
+	copy(data[i:], "key9500")
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err = backend.CheckFile(path)
+	if !errors.Is(err, backend.ErrCorrupted) {
+		t.Fatalf("expected %v, got %v", backend.ErrCorrupted, err)
+	}
+	assert.Contains(t, err.Error(), "key[")
+}
@@ -0,0 +1,295 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"go.uber.org/zap"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// defragBatchLimit is the number of keys copied by an incremental
+	// defragmentation between two releases of the backend.
+	defragBatchLimit = 1000
+
+	// defragCatchUpRounds is the maximum number of times the changes made
+	// during an incremental defragmentation are copied without blocking,
+	// before the final cutover copies the rest.
+	defragCatchUpRounds = 3
+
+	// afterDefragBatch is called after each batch of an incremental
+	// defragmentation, for testing.
+	afterDefragBatch = func() {}
+)
+
+// defragIncremental copies the database in batches of defragBatchLimit keys,
+// each from its own read transaction, leaving the backend available between
+// them. The keys changed since the copy started are journaled and copied
+// again, first without blocking, then at the cutover which blocks the
+// backend only for the last changes and the database swap.
+//
+// Only the bbolt engine is defragmented incrementally, the others are
+// defragmented at once.
+func (b *backend) defragIncremental() (err error) {
+	e, ok := b.engine.(*boltEngine)
+	if !ok {
+		return b.defrag()
+	}
+	tmpdb, err := e.openDefragTmpDB()
+	if err != nil {
+		return err
+	}
+
+	// Commit before journaling, so that the batches read every change not
+	// journaled.
+	b.batchTx.LockOutsideApply()
+	b.batchTx.commit(false)
+	b.defragJournal = newDefragJournal()
+	b.batchTx.Unlock()
+
+	cutover := false
+	defer func() {
+		if err == nil {
+			return
+		}
+		if !cutover {
+			b.batchTx.LockOutsideApply()
+			b.defragJournal = nil
+			b.batchTx.Unlock()
+		}
+		e.removeDefragTmpDB(tmpdb)
+	}()
+
+	// gofail: var defragBeforeCopy struct{}
+	buckets, err := defragBuckets(e.db)
+	if err != nil {
+		return err
+	}
+	for _, name := range buckets {
+		for next := []byte{}; next != nil; {
+			if next, err = defragBucketBatch(e.db, tmpdb, name, next, defragBatchLimit); err != nil {
+				return err
+			}
+			afterDefragBatch()
+		}
+	}
+
+	for i := 0; i < defragCatchUpRounds; i++ {
+		b.batchTx.LockOutsideApply()
+		b.batchTx.commit(false)
+		j := b.defragJournal
+		if j.size() <= defragBatchLimit {
+			b.batchTx.Unlock()
+			break
+		}
+		b.defragJournal = newDefragJournal()
+		b.batchTx.Unlock()
+
+		if err = j.copy(e.db, tmpdb); err != nil {
+			return err
+		}
+		afterDefragBatch()
+	}
+
+	cutover = true
+	b.batchTx.LockOutsideApply()
+	defer b.batchTx.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readTx.Lock()
+	defer b.readTx.Unlock()
+
+	b.batchTx.unsafeCommit(true)
+	j := b.defragJournal
+	b.defragJournal = nil
+	if b.lg != nil {
+		b.lg.Info("copying the last changes before finishing the defragmentation", zap.Int("changes", j.size()))
+	}
+	if err = j.copy(e.db, tmpdb); err != nil {
+		// keep serving from the current database
+		b.batchTx.tx = b.unsafeBegin(true)
+		b.readTx.tx = b.unsafeBegin(false)
+		return err
+	}
+	b.batchTx.tx = nil
+
+	e.replaceDB(tmpdb)
+	b.unsafeBeginTxs()
+	return nil
+}
+
+// defragBuckets returns the names of the buckets of the database.
+func defragBuckets(db *bolt.DB) ([][]byte, error) {
+	tx, err := db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var names [][]byte
+	err = tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		names = append(names, append([]byte(nil), name...))
+		return nil
+	})
+	return names, err
+}
+
+// defragBucketBatch copies up to limit keys of the bucket, starting from the
+// key from, to tmpdb. It returns the key to start the next batch from, or nil
+// once the bucket is copied.
+func defragBucketBatch(odb, tmpdb *bolt.DB, name, from []byte, limit int) ([]byte, error) {
+	tx, err := odb.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	b := tx.Bucket(name)
+	if b == nil {
+		// deleted since the defragmentation started, it is journaled
+		return nil, nil
+	}
+
+	tmptx, err := tmpdb.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	tmpb, err := tmptx.CreateBucketIfNotExists(name)
+	if err != nil {
+		tmptx.Rollback()
+		return nil, err
+	}
+	tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+
+	c := b.Cursor()
+	k, v := c.Seek(from)
+	for n := 0; k != nil && n < limit; n++ {
+		if err = tmpb.Put(k, v); err != nil {
+			tmptx.Rollback()
+			return nil, err
+		}
+		k, v = c.Next()
+	}
+
+	var next []byte
+	if k != nil {
+		next = append([]byte(nil), k...)
+	}
+	return next, tmptx.Commit()
+}
+
+// defragJournal records the keys and buckets changed during an incremental
+// defragmentation, to copy them again from the current database.
+type defragJournal struct {
+	// buckets are the buckets created or deleted, copied again as a whole.
+	buckets map[string]struct{}
+	keys    map[string]map[string]struct{}
+	n       int
+}
+
+func newDefragJournal() *defragJournal {
+	return &defragJournal{
+		buckets: make(map[string]struct{}),
+		keys:    make(map[string]map[string]struct{}),
+	}
+}
+
+func (j *defragJournal) bucket(name []byte) {
+	if _, ok := j.buckets[string(name)]; !ok {
+		j.buckets[string(name)] = struct{}{}
+		j.n++
+	}
+}
+
+func (j *defragJournal) key(bucket, key []byte) {
+	keys, ok := j.keys[string(bucket)]
+	if !ok {
+		keys = make(map[string]struct{})
+		j.keys[string(bucket)] = keys
+	}
+	if _, ok := keys[string(key)]; !ok {
+		keys[string(key)] = struct{}{}
+		j.n++
+	}
+}
+
+// size returns the number of journaled keys and buckets.
+func (j *defragJournal) size() int { return j.n }
+
+// copy copies the journaled keys and buckets from odb to tmpdb, deleting the
+// ones that no longer exist.
+func (j *defragJournal) copy(odb, tmpdb *bolt.DB) error {
+	tx, err := odb.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tmptx, err := tmpdb.Begin(true)
+	if err != nil {
+		return err
+	}
+	if err = j.unsafeCopy(tx, tmptx); err != nil {
+		tmptx.Rollback()
+		return err
+	}
+	return tmptx.Commit()
+}
+
+func (j *defragJournal) unsafeCopy(tx, tmptx *bolt.Tx) error {
+	for name := range j.buckets {
+		if err := tmptx.DeleteBucket([]byte(name)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		b := tx.Bucket([]byte(name))
+		if b == nil {
+			continue
+		}
+		tmpb, err := tmptx.CreateBucket([]byte(name))
+		if err != nil {
+			return err
+		}
+		tmpb.FillPercent = 0.9
+		if err = b.ForEach(tmpb.Put); err != nil {
+			return err
+		}
+	}
+
+	for name, keys := range j.keys {
+		if _, ok := j.buckets[name]; ok {
+			continue
+		}
+		b := tx.Bucket([]byte(name))
+		if b == nil {
+			continue
+		}
+		tmpb, err := tmptx.CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+		for k := range keys {
+			if v := b.Get([]byte(k)); v != nil {
+				err = tmpb.Put([]byte(k), v)
+			} else {
+				err = tmpb.Delete([]byte(k))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
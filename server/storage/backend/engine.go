@@ -0,0 +1,143 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+)
+
+const (
+	// EngineBolt stores the backend in a bbolt B+tree file. It is the
+	// default engine.
+	EngineBolt = "bbolt"
+	// EngineLog appends the changes of the backend to a log file, avoiding
+	// the page rewrites of bbolt on write-heavy workloads. The keys and the
+	// small values are indexed in memory, the other values are read from
+	// the log. The log is compacted by defragmentation.
+	EngineLog = "log"
+)
+
+// Engine is the storage engine persisting the buckets of a backend. The
+// backend serializes the write transactions, and buffers their changes for
+// the read transactions until they are committed.
+type Engine interface {
+	// Path returns the path of the file holding the data.
+	Path() string
+	// Begin starts a transaction. There is at most one write transaction
+	// at a time, concurrent with any number of read transactions seeing
+	// the data as of the last commit before they began.
+	Begin(write bool) (EngineTx, error)
+	// Stats returns the space and transaction statistics of the engine.
+	Stats() EngineStats
+	// Check runs a consistency check of the data on disk, returning
+	// an error wrapping ErrCorrupted if it fails.
+	Check() error
+	// Defrag rewrites the data to release the space not in use. It must be
+	// called with no open transaction.
+	Defrag() error
+	Close() error
+}
+
+type EngineStats struct {
+	// FreeBytes is the number of bytes allocated but not in use.
+	FreeBytes int64
+	// FreelistBytes is the number of bytes taken by the list of the free
+	// pages, 0 if the engine keeps none.
+	FreelistBytes int64
+	// OpenReadTxN is the number of open read transactions.
+	OpenReadTxN int
+}
+
+type EngineTx interface {
+	// Bucket returns the bucket with the name, or nil if it does not exist.
+	Bucket(name []byte) EngineBucket
+	// ForEachBucket calls fn for each bucket, ordered by name.
+	ForEachBucket(fn func(name []byte, b EngineBucket) error) error
+	// CreateBucket creates the bucket if it does not exist.
+	CreateBucket(name []byte) error
+	// DeleteBucket deletes the bucket if it exists.
+	DeleteBucket(name []byte) error
+	// Size returns the size of the data, in bytes, as seen by the tx.
+	Size() int64
+	// Snapshot returns a snapshot of the data seen by the read tx, in the
+	// file format of the engine, which the other engines convert when they
+	// open it. Its size is a multiple of 512 bytes, for the clients to tell
+	// apart the checksum appended to it. The tx must not be used
+	// afterwards, it is released by closing the snapshot.
+	Snapshot() (Snapshot, error)
+	Commit() error
+	Rollback() error
+}
+
+type EngineBucket interface {
+	Put(key, value []byte) error
+	// SeqPut puts a key in a bucket mostly written in increasing key
+	// order, for the engines optimizing append-only workloads.
+	SeqPut(key, value []byte) error
+	Delete(key []byte) error
+	Cursor() EngineCursor
+	// ForEach calls fn for each key of the bucket, in order.
+	ForEach(fn func(k, v []byte) error) error
+}
+
+type EngineCursor interface {
+	// Seek moves the cursor to the first key greater than or equal to the
+	// given key, returning a nil key if there is none.
+	Seek(key []byte) (k, v []byte)
+	// Next moves the cursor to the next key, returning a nil key at the
+	// end of the bucket.
+	Next() (k, v []byte)
+}
+
+// ValidateEngine returns an error if the engine is not supported.
+func ValidateEngine(engine string) error {
+	switch engine {
+	case "", EngineBolt, EngineLog:
+		return nil
+	default:
+		return fmt.Errorf("unknown backend engine %q (supported: %q, %q)", engine, EngineBolt, EngineLog)
+	}
+}
+
+// DetectEngine returns the engine of the database file at path, EngineBolt
+// if it does not exist.
+func DetectEngine(path string) (string, error) {
+	isLog, err := isLogFile(path)
+	if err != nil {
+		return "", err
+	}
+	if isLog {
+		return EngineLog, nil
+	}
+	return EngineBolt, nil
+}
+
+func openEngine(bcfg BackendConfig) (Engine, error) {
+	if bcfg.Engine == "" {
+		engine, err := DetectEngine(bcfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		bcfg.Engine = engine
+	}
+	switch bcfg.Engine {
+	case EngineBolt:
+		return openBoltEngine(bcfg)
+	case EngineLog:
+		return openLogEngine(bcfg)
+	default:
+		return nil, ValidateEngine(bcfg.Engine)
+	}
+}
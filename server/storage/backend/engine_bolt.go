@@ -0,0 +1,349 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+type boltEngine struct {
+	lg    *zap.Logger
+	bopts *bolt.Options
+	db    *bolt.DB
+}
+
+func openBoltEngine(bcfg BackendConfig) (Engine, error) {
+	bopts := &bolt.Options{}
+	if boltOpenOptions != nil {
+		*bopts = *boltOpenOptions
+	}
+	bopts.InitialMmapSize = bcfg.mmapSize()
+	bopts.FreelistType = bcfg.BackendFreelistType
+	bopts.NoSync = bcfg.UnsafeNoFsync
+	bopts.NoGrowSync = bcfg.UnsafeNoFsync
+	bopts.Mlock = bcfg.Mlock
+
+	isLog, err := isLogFile(bcfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	if isLog {
+		// a snapshot received from a member running the log engine, or a
+		// log left by the log engine.
+		if bcfg.Logger != nil {
+			bcfg.Logger.Info("importing log into the bbolt engine", zap.String("path", bcfg.Path))
+		}
+		if err = importLogFile(bcfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to import log: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	if err != nil {
+		return nil, err
+	}
+	return &boltEngine{lg: bcfg.Logger, bopts: bopts, db: db}, nil
+}
+
+func (e *boltEngine) Path() string { return e.db.Path() }
+
+func (e *boltEngine) Begin(write bool) (EngineTx, error) {
+	tx, err := e.db.Begin(write)
+	if err != nil {
+		return nil, err
+	}
+	return boltTx{tx}, nil
+}
+
+func (e *boltEngine) Stats() EngineStats {
+	stats := e.db.Stats()
+	return EngineStats{
+		FreeBytes:     int64(stats.FreePageN) * int64(e.db.Info().PageSize),
+		FreelistBytes: int64(stats.FreelistInuse),
+		OpenReadTxN:   stats.OpenTxN,
+	}
+}
+
+func (e *boltEngine) Check() error { return checkDB(e.db) }
+
+func (e *boltEngine) Defrag() error {
+	tmpdb, err := e.openDefragTmpDB()
+	if err != nil {
+		return err
+	}
+
+	// gofail: var defragBeforeCopy struct{}
+	err = defragdb(e.db, tmpdb, defragLimit)
+	if err != nil {
+		e.removeDefragTmpDB(tmpdb)
+		return err
+	}
+
+	e.replaceDB(tmpdb)
+	return nil
+}
+
+func (e *boltEngine) Close() error { return e.db.Close() }
+
+// openDefragTmpDB creates the database the backend is defragmented into.
+func (e *boltEngine) openDefragTmpDB() (*bolt.DB, error) {
+	// Create a temporary file to ensure we start with a clean slate.
+	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
+	dir := filepath.Dir(e.db.Path())
+	temp, err := os.CreateTemp(dir, "db.tmp.*")
+	if err != nil {
+		return nil, err
+	}
+	options := bolt.Options{}
+	if boltOpenOptions != nil {
+		options = *boltOpenOptions
+	}
+	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
+		return temp, nil
+	}
+	// Don't load tmp db into memory regardless of opening options
+	options.Mlock = false
+	return bolt.Open(temp.Name(), 0600, &options)
+}
+
+func (e *boltEngine) removeDefragTmpDB(tmpdb *bolt.DB) {
+	tmpdb.Close()
+	if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
+		e.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
+	}
+}
+
+// replaceDB replaces the database with the defragmented one. It must be
+// called with no open transaction.
+func (e *boltEngine) replaceDB(tmpdb *bolt.DB) {
+	dbp, tdbp := e.db.Path(), tmpdb.Path()
+	err := e.db.Close()
+	if err != nil {
+		e.lg.Fatal("failed to close database", zap.Error(err))
+	}
+	err = tmpdb.Close()
+	if err != nil {
+		e.lg.Fatal("failed to close tmp database", zap.Error(err))
+	}
+	// gofail: var defragBeforeRename struct{}
+	err = os.Rename(tdbp, dbp)
+	if err != nil {
+		e.lg.Fatal("failed to rename tmp database", zap.Error(err))
+	}
+
+	e.db, err = bolt.Open(dbp, 0600, e.bopts)
+	if err != nil {
+		e.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
+	}
+}
+
+func defragdb(odb, tmpdb *bolt.DB, limit int) error {
+	// open a tx on tmpdb for writes
+	tmptx, err := tmpdb.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmptx.Rollback()
+		}
+	}()
+
+	// open a tx on old db for read
+	tx, err := odb.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	c := tx.Cursor()
+
+	count := 0
+	for next, _ := c.First(); next != nil; next, _ = c.Next() {
+		b := tx.Bucket(next)
+		if b == nil {
+			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
+		}
+
+		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
+		if berr != nil {
+			return berr
+		}
+		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+
+		if err = b.ForEach(func(k, v []byte) error {
+			count++
+			if count > limit {
+				err = tmptx.Commit()
+				if err != nil {
+					return err
+				}
+				tmptx, err = tmpdb.Begin(true)
+				if err != nil {
+					return err
+				}
+				tmpb = tmptx.Bucket(next)
+				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+
+				count = 0
+			}
+			return tmpb.Put(k, v)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tmptx.Commit()
+}
+
+type boltTx struct {
+	*bolt.Tx
+}
+
+func (tx boltTx) Bucket(name []byte) EngineBucket {
+	b := tx.Tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (tx boltTx) ForEachBucket(fn func(name []byte, b EngineBucket) error) error {
+	return tx.Tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, boltBucket{b})
+	})
+}
+
+func (tx boltTx) CreateBucket(name []byte) error {
+	_, err := tx.Tx.CreateBucketIfNotExists(name)
+	return err
+}
+
+func (tx boltTx) DeleteBucket(name []byte) error {
+	if err := tx.Tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return nil
+}
+
+func (tx boltTx) Snapshot() (Snapshot, error) { return boltSnapshot{tx.Tx}, nil }
+
+func (tx boltTx) Commit() error {
+	err := tx.Tx.Commit()
+	rebalanceSec.Observe(tx.Stats().RebalanceTime.Seconds())
+	spillSec.Observe(tx.Stats().SpillTime.Seconds())
+	writeSec.Observe(tx.Stats().WriteTime.Seconds())
+	return err
+}
+
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b boltBucket) SeqPut(key, value []byte) error {
+	// it is useful to increase fill percent when the workloads are mostly append-only.
+	// this can delay the page split and reduce space usage.
+	b.FillPercent = 0.9
+	return b.Put(key, value)
+}
+
+func (b boltBucket) Cursor() EngineCursor { return b.Bucket.Cursor() }
+
+// boltSnapshot is the bbolt file as seen by a read tx.
+type boltSnapshot struct {
+	*bolt.Tx
+}
+
+func (s boltSnapshot) Close() error { return s.Tx.Rollback() }
+
+// importLogFile replaces the log of the log engine at path by a bbolt
+// database of its data.
+func importLogFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "db.tmp.*")
+	if err != nil {
+		return err
+	}
+	err = tmp.Close()
+	if err == nil {
+		err = ConvertLogToBolt(path, tmp.Name())
+	}
+	if err == nil {
+		err = fsyncFile(tmp.Name())
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func fsyncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fileutil.Fsync(f)
+}
+
+// OpenBoltReadOnly opens the database file at path read-only as a bbolt
+// database, whatever the engine that wrote it. A log of the log engine is
+// converted to a temporary bbolt file first, removed by the returned close
+// function.
+func OpenBoltReadOnly(path string) (db *bolt.DB, closeDB func() error, err error) {
+	isLog, err := isLogFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isLog {
+		if db, err = bolt.Open(path, 0400, &bolt.Options{ReadOnly: true}); err != nil {
+			return nil, nil, err
+		}
+		return db, db.Close, nil
+	}
+
+	tmp, err := os.CreateTemp("", "etcd-db.*")
+	if err != nil {
+		return nil, nil, err
+	}
+	err = tmp.Close()
+	if err == nil {
+		err = ConvertLogToBolt(path, tmp.Name())
+	}
+	if err == nil {
+		db, err = bolt.Open(tmp.Name(), 0400, &bolt.Options{ReadOnly: true})
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, nil, err
+	}
+	return db, func() error {
+		err := db.Close()
+		if rmErr := os.Remove(tmp.Name()); err == nil {
+			err = rmErr
+		}
+		return err
+	}, nil
+}
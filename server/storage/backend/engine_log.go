@@ -0,0 +1,1148 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/google/btree"
+	"go.uber.org/zap"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+// The log engine persists each commit as a record appended to the log file.
+// Unlike bbolt, a commit only writes the changed keys, whatever the size of
+// the database. The keys are indexed in memory, in copy-on-write B-trees,
+// along with the small values, the other values being read from the log
+// file. The log is compacted by defragmentation, which rewrites the data
+// currently in use.
+//
+// The log starts with logMagic, followed by the records. A record is the
+// length and the CRC-32C of its payload, in little endian, followed by the
+// payload holding the operations of a commit. An operation is its type
+// followed by its bucket name, key and value, as length prefixed byte
+// strings, when the type has them.
+const (
+	logMagic = "etcdlog1"
+
+	logRecordHeaderSize = 8
+	// logRecordTargetSize is the size over which the records are split
+	// when the log is rewritten.
+	logRecordTargetSize = 1024 * 1024
+	// logInlineValueSize is the size up to which the values are kept in
+	// memory rather than read from the log file.
+	logInlineValueSize = 64
+	// logSnapshotAlignment is the size the snapshots are padded to a
+	// multiple of, like the bbolt pages, for the clients to tell apart the
+	// checksum appended to a snapshot.
+	logSnapshotAlignment = 512
+
+	logBTreeDegree = 32
+	// logCursorBatch is the number of items a cursor reads at once.
+	logCursorBatch = 64
+)
+
+const (
+	logOpCreateBucket byte = iota + 1
+	logOpDeleteBucket
+	logOpPut
+	logOpDelete
+	// logOpPad pads a record, the rest of its payload is ignored.
+	logOpPad
+)
+
+var (
+	errLogTxClosed       = errors.New("log engine: tx closed")
+	errLogTxNotWritable  = errors.New("log engine: tx not writable")
+	errLogTxStale        = errors.New("log engine: tx began before a defragmentation")
+	errLogBucketNotFound = errors.New("log engine: bucket not found")
+	errLogKeyRequired    = errors.New("log engine: key required")
+
+	logCRCTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type logEngine struct {
+	lg     *zap.Logger
+	path   string
+	noSync bool
+
+	// wmu serializes the writes to the log file.
+	wmu sync.Mutex
+	f   *fileutil.LockedFile
+
+	// mu protects the fields below.
+	mu sync.Mutex
+	// state is the data as of the last commit.
+	state *logState
+	// file is the log file the values of state are read from.
+	file *logFile
+	// size is the size of the log file.
+	size    int64
+	readTxN int
+}
+
+// logFile is a version of the log file the values are read from. Defrag
+// replaces the log file, the previous version is closed once the txs
+// reading it are done.
+type logFile struct {
+	f *os.File
+	// refs counts the engine and the txs reading the file, protected by
+	// logEngine.mu.
+	refs int
+}
+
+// read returns the value of the item, read from the file if it is not kept
+// in memory.
+func (f *logFile) read(it logItem) ([]byte, error) {
+	if it.off == 0 {
+		return it.value, nil
+	}
+	v := make([]byte, it.n)
+	if _, err := f.f.ReadAt(v, it.off); err != nil {
+		return nil, fmt.Errorf("failed to read log value at offset %d: %w", it.off, err)
+	}
+	return v, nil
+}
+
+func openLogEngine(bcfg BackendConfig) (Engine, error) {
+	lg := bcfg.Logger
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	isBolt, err := isBoltFile(bcfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	if isBolt {
+		// a snapshot received from a member running the bbolt engine, or a
+		// database left by the bbolt engine, which converts it back.
+		lg.Info("importing bbolt database into the log engine", zap.String("path", bcfg.Path))
+		if err = importBoltFile(bcfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to import bbolt database: %w", err)
+		}
+	}
+
+	f, err := fileutil.LockFile(bcfg.Path, os.O_RDWR|os.O_CREATE, fileutil.PrivateFileMode)
+	if err != nil {
+		return nil, err
+	}
+	e := &logEngine{lg: lg, path: bcfg.Path, noSync: bcfg.UnsafeNoFsync, f: f}
+	if err = e.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// load replays the log, truncating its last record if it was only partially
+// written.
+func (e *logEngine) load() error {
+	fi, err := e.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if size < int64(len(logMagic)) {
+		if err = e.f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err = e.f.WriteAt([]byte(logMagic), 0); err != nil {
+			return err
+		}
+		size = int64(len(logMagic))
+		if err = e.sync(); err != nil {
+			return err
+		}
+	}
+
+	rf, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	file := &logFile{f: rf, refs: 1}
+	state, valid, err := readLog(file, size)
+	if err == io.ErrUnexpectedEOF {
+		e.lg.Warn(
+			"truncating partially written record of the backend log",
+			zap.String("path", e.path),
+			zap.Int64("offset", valid),
+			zap.Int64("size", size),
+		)
+		if err = e.f.Truncate(valid); err != nil {
+			rf.Close()
+			return err
+		}
+		size = valid
+		err = e.sync()
+	}
+	if err != nil {
+		rf.Close()
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	e.state, e.file, e.size = state, file, size
+	return nil
+}
+
+func (e *logEngine) sync() error {
+	if e.noSync {
+		return nil
+	}
+	return fileutil.Fdatasync(e.f.File)
+}
+
+// append writes the record at the end of the log. A failed write or sync
+// may leave a partially written record, which is truncated for the next
+// records to follow the last complete one; the engine stops if it cannot
+// be.
+func (e *logEngine) append(rec []byte) error {
+	_, err := e.f.WriteAt(rec, e.size)
+	if err == nil {
+		err = e.sync()
+	}
+	if err == nil {
+		return nil
+	}
+	terr := e.f.Truncate(e.size)
+	if terr == nil {
+		terr = e.sync()
+	}
+	if terr != nil {
+		e.lg.Fatal(
+			"failed to truncate partially written record of the backend log",
+			zap.String("path", e.path),
+			zap.Int64("offset", e.size),
+			zap.NamedError("write-error", err),
+			zap.Error(terr),
+		)
+	}
+	return err
+}
+
+// release drops a reference to the log file, closing it once unused.
+func (e *logEngine) release(f *logFile) {
+	e.mu.Lock()
+	f.refs--
+	unused := f.refs == 0
+	e.mu.Unlock()
+	if !unused {
+		return
+	}
+	if err := f.f.Close(); err != nil {
+		e.lg.Warn("failed to close backend log", zap.String("path", e.path), zap.Error(err))
+	}
+}
+
+func (e *logEngine) Path() string { return e.path }
+
+func (e *logEngine) Begin(write bool) (EngineTx, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tx := &logTx{lg: e.lg, e: e, state: e.state, file: e.file, size: e.size, writable: write}
+	e.file.refs++
+	if write {
+		tx.state = e.state.clone()
+		tx.owned = make(map[string]bool)
+	} else {
+		e.readTxN++
+	}
+	return tx, nil
+}
+
+func (e *logEngine) Stats() EngineStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	free := e.size - e.state.size()
+	if free < 0 {
+		free = 0
+	}
+	return EngineStats{FreeBytes: free, OpenReadTxN: e.readTxN}
+}
+
+func (e *logEngine) Check() error {
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+	e.mu.Lock()
+	size := e.size
+	e.mu.Unlock()
+
+	f, err := os.Open(e.path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	defer f.Close()
+	if err = checkLog(f, size); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return nil
+}
+
+func (e *logEngine) Defrag() error {
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+	etx, _ := e.Begin(false)
+	tx := etx.(*logTx)
+	defer tx.Rollback()
+
+	// Snapshotter.cleanupSnapdir cleans up the temporary files left by a
+	// failure.
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), "db.tmp.*")
+	if err != nil {
+		return err
+	}
+	// the keys and the small values are shared with the current state,
+	// the other values are read from the new log.
+	state := newLogState()
+	for name, b := range tx.state.buckets {
+		state.buckets[name] = &logBucket{items: btree.NewG(logBTreeDegree, logItemLess), size: b.size}
+	}
+	size, err := writeLog(tmp, tx, func(bucket, key, value []byte, off int64) {
+		it := logItem{key: key, value: value}
+		if len(value) > logInlineValueSize {
+			it = logItem{key: key, off: off, n: len(value)}
+		}
+		state.buckets[string(bucket)].items.ReplaceOrInsert(it)
+	})
+	if err == nil {
+		err = fileutil.Fsync(tmp)
+	}
+	if err != nil {
+		tmp.Close()
+		if rmErr := os.Remove(tmp.Name()); rmErr != nil {
+			e.lg.Error("failed to remove db.tmp after defragmentation failed", zap.Error(rmErr))
+		}
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), e.path); err != nil {
+		e.lg.Fatal("failed to rename tmp database", zap.Error(err))
+	}
+	if err = e.f.Close(); err != nil {
+		e.lg.Fatal("failed to close database", zap.Error(err))
+	}
+	if e.f, err = fileutil.LockFile(e.path, os.O_RDWR, fileutil.PrivateFileMode); err != nil {
+		e.lg.Fatal("failed to open database", zap.String("path", e.path), zap.Error(err))
+	}
+	if err = syncDir(filepath.Dir(e.path)); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	old := e.file
+	e.state, e.file, e.size = state, &logFile{f: tmp, refs: 1}, size
+	e.mu.Unlock()
+	e.release(old)
+	return nil
+}
+
+func (e *logEngine) Close() error {
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+	e.release(e.file)
+	return e.f.Close()
+}
+
+// logState is a version of the data. The committed states are never
+// modified, a write tx modifies a copy of the last one.
+type logState struct {
+	buckets map[string]*logBucket
+}
+
+type logBucket struct {
+	items *btree.BTreeG[logItem]
+	// size is the size of the operations writing the bucket in a
+	// compacted log.
+	size int64
+}
+
+type logItem struct {
+	key []byte
+	// value is the value kept in memory, when off is 0.
+	value []byte
+	// off and n are the offset and the size of the value in the log file.
+	off int64
+	n   int
+}
+
+func logItemLess(a, b logItem) bool { return bytes.Compare(a.key, b.key) < 0 }
+
+// valueSize returns the size of the value of the item.
+func (it logItem) valueSize() int {
+	if it.off == 0 {
+		return len(it.value)
+	}
+	return it.n
+}
+
+func newLogState() *logState {
+	return &logState{buckets: make(map[string]*logBucket)}
+}
+
+// clone returns a copy of the state sharing its buckets.
+func (s *logState) clone() *logState {
+	c := &logState{buckets: make(map[string]*logBucket, len(s.buckets))}
+	for name, b := range s.buckets {
+		c.buckets[name] = b
+	}
+	return c
+}
+
+// size returns the size of the state in a compacted log.
+func (s *logState) size() int64 {
+	size := int64(len(logMagic))
+	for _, b := range s.buckets {
+		size += b.size
+	}
+	return size
+}
+
+// bucketNames returns the names of the buckets, sorted.
+func (s *logState) bucketNames() []string {
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readLog replays the log of the given size read from the file. It returns
+// the state of the log, the end offset of its last valid record, and
+// io.ErrUnexpectedEOF if the last record is partially written.
+func readLog(file *logFile, size int64) (*logState, int64, error) {
+	tx := &logTx{state: newLogState(), file: file, writable: true, owned: make(map[string]bool)}
+	valid, err := scanLog(io.NewSectionReader(file.f, 0, size), size, func(off int64, payload []byte) error {
+		return decodeLogOps(payload, func(op byte, bucket, key, value []byte, valueOff int) error {
+			return tx.replay(op, bucket, key, value, off+int64(valueOff))
+		})
+	})
+	return tx.state, valid, err
+}
+
+type logTx struct {
+	lg    *zap.Logger
+	e     *logEngine
+	state *logState
+	// file is the log file the values of the state are read from.
+	file *logFile
+	size int64
+
+	writable bool
+	done     bool
+	// owned are the buckets of the state copied by the write tx.
+	owned map[string]bool
+	// ops are the operations of the write tx, encoded.
+	ops []byte
+	// puts are the values of the write tx read from the log once
+	// committed.
+	puts []logPut
+}
+
+// logPut is a value put by a write tx, at pos in its operations.
+type logPut struct {
+	bucket     string
+	key, value []byte
+	pos        int
+}
+
+func (tx *logTx) Bucket(name []byte) EngineBucket {
+	if _, ok := tx.state.buckets[string(name)]; !ok {
+		return nil
+	}
+	return &logTxBucket{tx: tx, name: name}
+}
+
+func (tx *logTx) ForEachBucket(fn func(name []byte, b EngineBucket) error) error {
+	for _, name := range tx.state.bucketNames() {
+		if err := fn([]byte(name), &logTxBucket{tx: tx, name: []byte(name)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *logTx) CreateBucket(name []byte) error {
+	if _, ok := tx.state.buckets[string(name)]; ok {
+		return nil
+	}
+	return tx.record(logOpCreateBucket, name, nil, nil)
+}
+
+func (tx *logTx) DeleteBucket(name []byte) error {
+	if _, ok := tx.state.buckets[string(name)]; !ok {
+		return nil
+	}
+	return tx.record(logOpDeleteBucket, name, nil, nil)
+}
+
+func (tx *logTx) Size() int64 { return tx.size }
+
+// Snapshot streams the data seen by the tx as a compacted log, padded to a
+// multiple of logSnapshotAlignment.
+func (tx *logTx) Snapshot() (Snapshot, error) {
+	if tx.done {
+		return nil, errLogTxClosed
+	}
+	size := tx.compactedSize()
+	return &logSnapshot{tx: tx, size: size + int64(len(logPadRecord(size)))}, nil
+}
+
+// compactedSize returns the size of the log writeLog writes for the data
+// seen by the tx.
+func (tx *logTx) compactedSize() int64 {
+	size := int64(len(logMagic))
+	var payload int64
+	for _, name := range tx.state.bucketNames() {
+		payload += logOpSize(logOpCreateBucket, []byte(name), nil, 0)
+		tx.state.buckets[name].items.Ascend(func(it logItem) bool {
+			payload += logOpSize(logOpPut, []byte(name), it.key, it.valueSize())
+			if payload >= logRecordTargetSize {
+				size += logRecordHeaderSize + payload
+				payload = 0
+			}
+			return true
+		})
+	}
+	if payload > 0 {
+		size += logRecordHeaderSize + payload
+	}
+	return size
+}
+
+func (tx *logTx) Commit() error {
+	if tx.done {
+		return errLogTxClosed
+	}
+	if !tx.writable {
+		return errLogTxNotWritable
+	}
+	tx.done = true
+
+	e := tx.e
+	defer e.release(tx.file)
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
+	e.mu.Lock()
+	stale := tx.file != e.file
+	e.mu.Unlock()
+	if stale {
+		return errLogTxStale
+	}
+	var n int
+	if len(tx.ops) > 0 {
+		rec := appendLogRecord(nil, tx.ops)
+		if err := e.append(rec); err != nil {
+			return err
+		}
+		n = len(rec)
+		tx.unloadValues(e.size)
+	}
+	e.mu.Lock()
+	e.state = tx.state
+	e.size += int64(n)
+	e.mu.Unlock()
+	return nil
+}
+
+func (tx *logTx) Rollback() error {
+	if tx.done {
+		return errLogTxClosed
+	}
+	tx.done = true
+	if tx.e == nil {
+		return nil
+	}
+	if !tx.writable {
+		tx.e.mu.Lock()
+		tx.e.readTxN--
+		tx.e.mu.Unlock()
+	}
+	tx.e.release(tx.file)
+	return nil
+}
+
+// record applies the operation to the state of the write tx and records it
+// to be appended to the log on commit.
+func (tx *logTx) record(op byte, bucket, key, value []byte) error {
+	if tx.done {
+		return errLogTxClosed
+	}
+	if !tx.writable {
+		return errLogTxNotWritable
+	}
+	if err := tx.apply(op, bucket, logItem{key: key, value: value}); err != nil {
+		return err
+	}
+	tx.ops = appendLogOp(tx.ops, op, bucket, key, value)
+	if op == logOpPut && len(value) > logInlineValueSize {
+		tx.puts = append(tx.puts, logPut{bucket: string(bucket), key: key, value: value, pos: len(tx.ops) - len(value)})
+	}
+	return nil
+}
+
+// unloadValues replaces the large values put by the committed write tx by
+// their offsets in its record, appended to the log at off.
+func (tx *logTx) unloadValues(off int64) {
+	for _, p := range tx.puts {
+		b, ok := tx.state.buckets[p.bucket]
+		if !ok {
+			continue
+		}
+		// the key may have been deleted or put again since.
+		it, ok := b.items.Get(logItem{key: p.key})
+		if !ok || it.off != 0 || len(it.value) != len(p.value) || &it.value[0] != &p.value[0] {
+			continue
+		}
+		b.items.ReplaceOrInsert(logItem{key: it.key, off: off + logRecordHeaderSize + int64(p.pos), n: len(p.value)})
+	}
+}
+
+// replay applies an operation read from the log, whose value is at off in
+// the log file. The key and the value kept in memory are copied, the
+// record is not retained.
+func (tx *logTx) replay(op byte, bucket, key, value []byte, off int64) error {
+	if op != logOpPut {
+		return tx.apply(op, bucket, logItem{key: key})
+	}
+	it := logItem{key: append([]byte(nil), key...), off: off, n: len(value)}
+	if len(value) <= logInlineValueSize {
+		it = logItem{key: it.key, value: append([]byte(nil), value...)}
+	}
+	return tx.apply(op, bucket, it)
+}
+
+// apply applies the operation to the state of the write tx. The key and
+// value of the item are kept, they must not be modified afterwards.
+func (tx *logTx) apply(op byte, bucket []byte, it logItem) error {
+	name := string(bucket)
+	switch op {
+	case logOpCreateBucket:
+		if _, ok := tx.state.buckets[name]; !ok {
+			tx.state.buckets[name] = &logBucket{
+				items: btree.NewG(logBTreeDegree, logItemLess),
+				size:  logOpSize(logOpCreateBucket, bucket, nil, 0),
+			}
+			tx.owned[name] = true
+		}
+	case logOpDeleteBucket:
+		delete(tx.state.buckets, name)
+		delete(tx.owned, name)
+	case logOpPut:
+		b := tx.ownBucket(name)
+		if b == nil {
+			return errLogBucketNotFound
+		}
+		if old, ok := b.items.ReplaceOrInsert(it); ok {
+			b.size -= logOpSize(logOpPut, bucket, old.key, old.valueSize())
+		}
+		b.size += logOpSize(logOpPut, bucket, it.key, it.valueSize())
+	case logOpDelete:
+		b := tx.ownBucket(name)
+		if b == nil {
+			return errLogBucketNotFound
+		}
+		if old, ok := b.items.Delete(it); ok {
+			b.size -= logOpSize(logOpPut, bucket, old.key, old.valueSize())
+		}
+	default:
+		return fmt.Errorf("unknown log operation %d", op)
+	}
+	return nil
+}
+
+// ownBucket returns the bucket with the name, copying it first if it is
+// shared with the committed state.
+func (tx *logTx) ownBucket(name string) *logBucket {
+	b, ok := tx.state.buckets[name]
+	if !ok {
+		return nil
+	}
+	if !tx.owned[name] {
+		b = &logBucket{items: b.items.Clone(), size: b.size}
+		tx.state.buckets[name] = b
+		tx.owned[name] = true
+	}
+	return b
+}
+
+// value returns the value of the item. The engine stops if it cannot be
+// read from the log file, the cursors having no way to return the error.
+func (tx *logTx) value(it logItem) []byte {
+	v, err := tx.file.read(it)
+	if err != nil {
+		tx.lg.Fatal("failed to read backend log", zap.String("path", tx.file.f.Name()), zap.Error(err))
+	}
+	return v
+}
+
+type logTxBucket struct {
+	tx   *logTx
+	name []byte
+}
+
+func (b *logTxBucket) items() *btree.BTreeG[logItem] {
+	if lb, ok := b.tx.state.buckets[string(b.name)]; ok {
+		return lb.items
+	}
+	return nil
+}
+
+func (b *logTxBucket) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return errLogKeyRequired
+	}
+	k, v := make([]byte, len(key)), make([]byte, len(value))
+	copy(k, key)
+	copy(v, value)
+	return b.tx.record(logOpPut, b.name, k, v)
+}
+
+// SeqPut is Put, the log is written in commit order whatever the keys.
+func (b *logTxBucket) SeqPut(key, value []byte) error { return b.Put(key, value) }
+
+func (b *logTxBucket) Delete(key []byte) error {
+	return b.tx.record(logOpDelete, b.name, key, nil)
+}
+
+func (b *logTxBucket) Cursor() EngineCursor { return &logCursor{tx: b.tx, items: b.items()} }
+
+func (b *logTxBucket) ForEach(fn func(k, v []byte) error) error {
+	items := b.items()
+	if items == nil {
+		return nil
+	}
+	var err error
+	items.Ascend(func(it logItem) bool {
+		var v []byte
+		if v, err = b.tx.file.read(it); err == nil {
+			err = fn(it.key, v)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// logCursor reads the items of a bucket in batches of logCursorBatch.
+type logCursor struct {
+	tx    *logTx
+	items *btree.BTreeG[logItem]
+	buf   []logItem
+	pos   int
+}
+
+func (c *logCursor) Seek(key []byte) ([]byte, []byte) {
+	c.load(key, true)
+	return c.pop()
+}
+
+func (c *logCursor) Next() ([]byte, []byte) {
+	if c.pos == len(c.buf) && len(c.buf) == logCursorBatch {
+		c.load(c.buf[len(c.buf)-1].key, false)
+	}
+	return c.pop()
+}
+
+func (c *logCursor) load(from []byte, inclusive bool) {
+	c.buf, c.pos = c.buf[:0], 0
+	if c.items == nil {
+		return
+	}
+	c.items.AscendGreaterOrEqual(logItem{key: from}, func(it logItem) bool {
+		if !inclusive && bytes.Equal(it.key, from) {
+			return true
+		}
+		c.buf = append(c.buf, it)
+		return len(c.buf) < logCursorBatch
+	})
+}
+
+func (c *logCursor) pop() ([]byte, []byte) {
+	if c.pos == len(c.buf) {
+		return nil, nil
+	}
+	it := c.buf[c.pos]
+	c.pos++
+	return it.key, c.tx.value(it)
+}
+
+// logSnapshot streams the data seen by its read tx as a compacted log,
+// released on close.
+type logSnapshot struct {
+	tx   *logTx
+	size int64
+}
+
+func (s *logSnapshot) Size() int64 { return s.size }
+
+func (s *logSnapshot) WriteTo(w io.Writer) (int64, error) {
+	n, err := writeLog(w, s.tx, nil)
+	if err != nil {
+		return n, err
+	}
+	m, err := w.Write(logPadRecord(n))
+	return n + int64(m), err
+}
+
+func (s *logSnapshot) Close() error { return s.tx.Rollback() }
+
+// logPadRecord returns the record padding a log of the given size to a
+// multiple of logSnapshotAlignment, nil if it is one already.
+func logPadRecord(size int64) []byte {
+	n := (logSnapshotAlignment - size%logSnapshotAlignment) % logSnapshotAlignment
+	if n == 0 {
+		return nil
+	}
+	if n <= logRecordHeaderSize {
+		n += logSnapshotAlignment
+	}
+	payload := make([]byte, n-logRecordHeaderSize)
+	payload[0] = logOpPad
+	return appendLogRecord(nil, payload)
+}
+
+func appendLogOp(buf []byte, op byte, bucket, key, value []byte) []byte {
+	buf = append(buf, op)
+	buf = appendLogBytes(buf, bucket)
+	switch op {
+	case logOpPut:
+		buf = appendLogBytes(buf, key)
+		buf = appendLogBytes(buf, value)
+	case logOpDelete:
+		buf = appendLogBytes(buf, key)
+	}
+	return buf
+}
+
+func appendLogBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// logOpSize returns the size of the encoded operation, with a value of the
+// given size.
+func logOpSize(op byte, bucket, key []byte, valueSize int) int64 {
+	size := 1 + logBytesSize(len(bucket))
+	switch op {
+	case logOpPut:
+		size += logBytesSize(len(key)) + logBytesSize(valueSize)
+	case logOpDelete:
+		size += logBytesSize(len(key))
+	}
+	return size
+}
+
+// logBytesSize returns the size of a length prefixed byte string of n
+// bytes.
+func logBytesSize(n int) int64 {
+	size := int64(1)
+	for l := uint64(n); l >= 0x80; l >>= 7 {
+		size++
+	}
+	return size + int64(n)
+}
+
+// decodeLogOps calls fn with each operation of the record payload, and the
+// offset of its value in the payload.
+func decodeLogOps(payload []byte, fn func(op byte, bucket, key, value []byte, valueOff int) error) error {
+	rest := payload
+	for len(rest) > 0 {
+		op := rest[0]
+		if op == logOpPad {
+			return nil
+		}
+		rest = rest[1:]
+		var bucket, key, value []byte
+		var ok bool
+		if bucket, rest, ok = readLogBytes(rest); !ok {
+			return fmt.Errorf("truncated log operation %d", op)
+		}
+		switch op {
+		case logOpCreateBucket, logOpDeleteBucket:
+		case logOpPut:
+			if key, rest, ok = readLogBytes(rest); ok {
+				value, rest, ok = readLogBytes(rest)
+			}
+		case logOpDelete:
+			key, rest, ok = readLogBytes(rest)
+		default:
+			return fmt.Errorf("unknown log operation %d", op)
+		}
+		if !ok {
+			return fmt.Errorf("truncated log operation %d", op)
+		}
+		if err := fn(op, bucket, key, value, len(payload)-len(rest)-len(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLogBytes(buf []byte) (b []byte, rest []byte, ok bool) {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < l {
+		return nil, nil, false
+	}
+	end := n + int(l)
+	return buf[n:end:end], buf[end:], true
+}
+
+func appendLogRecord(buf, payload []byte) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.Checksum(payload, logCRCTable))
+	return append(buf, payload...)
+}
+
+// scanLog calls fn with the payload of each record of the log of the given
+// size, and the offset of the payload in the log. It returns the end offset
+// of the last valid record, and io.ErrUnexpectedEOF if the last record is
+// partially written.
+func scanLog(r io.Reader, size int64, fn func(off int64, payload []byte) error) (int64, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(logMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != logMagic {
+		return 0, fmt.Errorf("invalid log magic %q", magic)
+	}
+	off := int64(len(logMagic))
+	var hdr [logRecordHeaderSize]byte
+	for off < size {
+		if size-off < logRecordHeaderSize {
+			return off, io.ErrUnexpectedEOF
+		}
+		if _, err := io.ReadFull(br, hdr[:]); err != nil {
+			return off, err
+		}
+		n := int64(binary.LittleEndian.Uint32(hdr[0:]))
+		end := off + logRecordHeaderSize + n
+		if end > size {
+			return off, io.ErrUnexpectedEOF
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return off, err
+		}
+		if crc32.Checksum(payload, logCRCTable) != binary.LittleEndian.Uint32(hdr[4:]) {
+			if end == size {
+				return off, io.ErrUnexpectedEOF
+			}
+			return off, fmt.Errorf("log record at offset %d: checksum mismatch", off)
+		}
+		if err := fn(off+logRecordHeaderSize, payload); err != nil {
+			return off, fmt.Errorf("log record at offset %d: %v", off, err)
+		}
+		off = end
+	}
+	return off, nil
+}
+
+// checkLog verifies the records of the log of the given size.
+func checkLog(r io.Reader, size int64) error {
+	_, err := scanLog(r, size, func(_ int64, payload []byte) error {
+		return decodeLogOps(payload, func(byte, []byte, []byte, []byte, int) error { return nil })
+	})
+	return err
+}
+
+// writeLog writes the data seen by the tx as a compacted log, returning its
+// size. If onPut is not nil, it is called with each key written and the
+// offset of its value in the log.
+func writeLog(w io.Writer, tx EngineTx, onPut func(bucket, key, value []byte, off int64)) (int64, error) {
+	bw := bufio.NewWriter(w)
+	n, _ := bw.WriteString(logMagic)
+	size := int64(n)
+	var payload []byte
+	var puts []logPut
+	flush := func() error {
+		if len(payload) == 0 {
+			return nil
+		}
+		for _, p := range puts {
+			onPut([]byte(p.bucket), p.key, p.value, size+logRecordHeaderSize+int64(p.pos))
+		}
+		n, err := bw.Write(appendLogRecord(nil, payload))
+		size += int64(n)
+		payload, puts = payload[:0], puts[:0]
+		return err
+	}
+	err := tx.ForEachBucket(func(name []byte, b EngineBucket) error {
+		payload = appendLogOp(payload, logOpCreateBucket, name, nil, nil)
+		return b.ForEach(func(k, v []byte) error {
+			payload = appendLogOp(payload, logOpPut, name, k, v)
+			if onPut != nil {
+				puts = append(puts, logPut{bucket: string(name), key: k, value: v, pos: len(payload) - len(v)})
+			}
+			if len(payload) >= logRecordTargetSize {
+				return flush()
+			}
+			return nil
+		})
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	return size, err
+}
+
+// writeBoltFile writes the data seen by the tx as a bbolt database at path.
+func writeBoltFile(path string, tx EngineTx) error {
+	// the file is temporary, it is not synced.
+	db, err := bolt.Open(path, 0600, &bolt.Options{NoSync: true, NoGrowSync: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	btx, err := db.Begin(true)
+	if err != nil {
+		return err
+	}
+	count := 0
+	err = tx.ForEachBucket(func(name []byte, b EngineBucket) error {
+		bb, err := btx.CreateBucketIfNotExists(name)
+		if err != nil {
+			return err
+		}
+		bb.FillPercent = 0.9
+		return b.ForEach(func(k, v []byte) error {
+			count++
+			if count > defragLimit {
+				if err := btx.Commit(); err != nil {
+					return err
+				}
+				if btx, err = db.Begin(true); err != nil {
+					return err
+				}
+				bb = btx.Bucket(name)
+				bb.FillPercent = 0.9
+				count = 0
+			}
+			return bb.Put(k, v)
+		})
+	})
+	if err != nil {
+		btx.Rollback()
+		return err
+	}
+	return btx.Commit()
+}
+
+// importBoltFile replaces the bbolt database at path by a log of its data.
+func importBoltFile(path string) error {
+	db, err := bolt.Open(path, 0400, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "db.tmp.*")
+	if err != nil {
+		db.Close()
+		return err
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		_, err := writeLog(tmp, boltTx{tx}, nil)
+		return err
+	})
+	db.Close()
+	if err == nil {
+		err = fileutil.Fsync(tmp)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// ConvertLogToBolt writes the data of the log of the log engine at path as
+// a bbolt database at dst. The log is not modified, a partially written
+// last record is ignored.
+func ConvertLogToBolt(path, dst string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	file := &logFile{f: f, refs: 1}
+	state, _, err := readLog(file, fi.Size())
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	return writeBoltFile(dst, &logTx{lg: zap.NewNop(), state: state, file: file})
+}
+
+// isBoltFile returns true if the file at path is a database left by the
+// bbolt engine or received in a snapshot, that is a file that does not start
+// with logMagic. A file shorter than logMagic is a log whose creation was
+// interrupted.
+func isBoltFile(path string) (bool, error) {
+	magic, err := readMagic(path)
+	return len(magic) == len(logMagic) && magic != logMagic, err
+}
+
+// isLogFile returns true if the file at path is a log of the log engine.
+func isLogFile(path string) (bool, error) {
+	magic, err := readMagic(path)
+	return magic == logMagic, err
+}
+
+// readMagic returns the first len(logMagic) bytes of the file at path, less
+// if it is shorter, none if it does not exist.
+func readMagic(path string) (string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, len(logMagic))
+	n, err := io.ReadFull(f, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return string(buf[:n]), err
+}
+
+func syncDir(dir string) error {
+	d, err := fileutil.OpenDir(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return fileutil.Fsync(d)
+}
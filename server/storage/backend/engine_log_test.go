@@ -0,0 +1,265 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func newLogBackend(t *testing.T, path string) backend.Backend {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Path, bcfg.Engine, bcfg.BatchInterval = path, backend.EngineLog, time.Hour
+	return backend.New(bcfg)
+}
+
+// writeKeys puts n keys, then deletes every other one. Every tenth value
+// is large enough to be read from the log by the log engine.
+func writeKeys(b backend.Backend, n int) {
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Key)
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < n; i++ {
+		v := fmt.Sprintf("value%d", i)
+		if i%10 == 0 {
+			v = strings.Repeat(v, 20)
+		}
+		tx.UnsafeSeqPut(schema.Key, []byte(fmt.Sprintf("key%04d", i)), []byte(v))
+	}
+	tx.UnsafePut(schema.Test, []byte("foo"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+
+	tx.Lock()
+	for i := 0; i < n; i += 2 {
+		tx.UnsafeDelete(schema.Key, []byte(fmt.Sprintf("key%04d", i)))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+}
+
+func rangeKeys(b backend.Backend) []string {
+	tx := b.ConcurrentReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	ks, _ := tx.UnsafeRange(schema.Key, []byte("key"), []byte("kez"), 0)
+	keys := make([]string, len(ks))
+	for i, k := range ks {
+		keys[i] = string(k)
+	}
+	return keys
+}
+
+func TestLogEngineReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	b := newLogBackend(t, path)
+	writeKeys(b, 100)
+	keys := rangeKeys(b)
+	assert.Len(t, keys, 50)
+	assert.Equal(t, "key0001", keys[0])
+	assert.NoError(t, b.Check())
+	h, err := b.Hash(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, b.Close())
+
+	// a partially written record is truncated on open
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write([]byte{100, 0, 0, 0, 1, 2, 3})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	b = newLogBackend(t, path)
+	defer betesting.Close(t, b)
+	assert.Equal(t, keys, rangeKeys(b))
+	h2, err := b.Hash(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, h, h2)
+	assert.NoError(t, b.Check())
+}
+
+func TestLogEngineHash(t *testing.T) {
+	bb, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, bb)
+	lb := newLogBackend(t, filepath.Join(t.TempDir(), "db"))
+	defer betesting.Close(t, lb)
+	writeKeys(bb, 100)
+	writeKeys(lb, 100)
+
+	assert.Equal(t, rangeKeys(bb), rangeKeys(lb))
+	bh, err := bb.Hash(nil)
+	assert.NoError(t, err)
+	lh, err := lb.Hash(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, bh, lh, "the hash must not depend on the engine")
+}
+
+func TestLogEngineDefrag(t *testing.T) {
+	b := newLogBackend(t, filepath.Join(t.TempDir(), "db"))
+	defer betesting.Close(t, b)
+	writeKeys(b, 1000)
+	keys := rangeKeys(b)
+
+	h, err := b.Hash(nil)
+	assert.NoError(t, err)
+
+	size := b.Size()
+	assert.Less(t, b.SizeInUse(), size)
+	assert.NoError(t, b.Defrag())
+	assert.Less(t, b.Size(), size)
+	assert.Equal(t, keys, rangeKeys(b))
+	dh, err := b.Hash(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, h, dh)
+
+	// the log is appended after the defragmentation
+	writeKeys(b, 1000)
+	assert.Equal(t, keys, rangeKeys(b))
+	assert.NoError(t, b.Check())
+}
+
+func TestLogEngineSnapshot(t *testing.T) {
+	b := newLogBackend(t, filepath.Join(t.TempDir(), "db"))
+	defer betesting.Close(t, b)
+	writeKeys(b, 100)
+	h, err := b.Hash(nil)
+	assert.NoError(t, err)
+
+	f, err := os.CreateTemp(t.TempDir(), "etcd_backend_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := b.Snapshot()
+	n, err := snap.WriteTo(f)
+	assert.NoError(t, err)
+	assert.Equal(t, snap.Size(), n)
+	assert.Zero(t, n%512, "the snapshot must be padded for its checksum to be told apart")
+	assert.NoError(t, snap.Close())
+	assert.NoError(t, f.Close())
+
+	// the snapshot is a log, converted by the bbolt engine
+	assert.NoError(t, backend.CheckFile(f.Name()))
+	engine, err := backend.DetectEngine(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, backend.EngineLog, engine)
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Path, bcfg.Engine = f.Name(), backend.EngineBolt
+	nb := backend.New(bcfg)
+	bh, err := nb.Hash(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, h, bh)
+	assert.NoError(t, nb.Close())
+	engine, err = backend.DetectEngine(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, backend.EngineBolt, engine)
+	assert.NoError(t, backend.CheckFile(f.Name()))
+
+	// and back by the log engine
+	nb = newLogBackend(t, f.Name())
+	defer betesting.Close(t, nb)
+	lh, err := nb.Hash(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, h, lh)
+	assert.Equal(t, rangeKeys(b), rangeKeys(nb))
+}
+
+func TestLogEngineDetect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	b := newLogBackend(t, path)
+	writeKeys(b, 100)
+	keys := rangeKeys(b)
+	assert.NoError(t, b.Close())
+
+	// without an engine, the backend opens the log as is
+	b = backend.NewDefaultBackend(zaptest.NewLogger(t), path)
+	defer betesting.Close(t, b)
+	assert.Equal(t, keys, rangeKeys(b))
+	engine, err := backend.DetectEngine(path)
+	assert.NoError(t, err)
+	assert.Equal(t, backend.EngineLog, engine)
+
+	db, closeDB, err := backend.OpenBoltReadOnly(path)
+	require.NoError(t, err)
+	assert.NoError(t, db.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 50, tx.Bucket(schema.Key.Name()).Stats().KeyN)
+		return nil
+	}))
+	assert.NoError(t, closeDB())
+}
+
+func TestLogEngineCommitFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Path, bcfg.Engine = path, backend.EngineLog
+	e, err := backend.OpenEngineForTest(bcfg)
+	require.NoError(t, err)
+	put := func(v string) error {
+		tx, err := e.Begin(true)
+		require.NoError(t, err)
+		require.NoError(t, tx.CreateBucket(schema.Test.Name()))
+		require.NoError(t, tx.Bucket(schema.Test.Name()).Put([]byte("foo"), []byte(v)))
+		return tx.Commit()
+	}
+	get := func() string {
+		tx, err := e.Begin(false)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		_, v := tx.Bucket(schema.Test.Name()).Cursor().Seek([]byte("foo"))
+		return string(v)
+	}
+	require.NoError(t, put("bar"))
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// a partially written record, then a failed write: writing at an offset
+	// fails on a file opened for appending, which can be truncated.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0, 0, 0, 1, 2, 3})
+	require.NoError(t, err)
+	restore := backend.SetLogFileForTest(e, f)
+	assert.Error(t, put("baz"))
+	restore()
+	assert.NoError(t, f.Close())
+	assert.Equal(t, "bar", get())
+	nfi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, fi.Size(), nfi.Size(), "the partially written record must be truncated")
+
+	// the next record follows the last complete one
+	require.NoError(t, put(strings.Repeat("qux", 100)))
+	assert.NoError(t, e.Check())
+	assert.NoError(t, e.Close())
+	e, err = backend.OpenEngineForTest(bcfg)
+	require.NoError(t, err)
+	defer e.Close()
+	assert.Equal(t, strings.Repeat("qux", 100), get())
+}
@@ -14,10 +14,19 @@
 
 package backend
 
-import bolt "go.etcd.io/bbolt"
+import (
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
 
 func DbFromBackendForTest(b Backend) *bolt.DB {
-	return b.(*backend).db
+	return b.(*backend).engine.(*boltEngine).db
+}
+
+func EngineStatsForTest(b Backend) EngineStats {
+	return b.(*backend).engine.Stats()
 }
 
 func DefragLimitForTest() int {
@@ -27,3 +36,27 @@ func DefragLimitForTest() int {
 func CommitsForTest(b Backend) int64 {
 	return b.(*backend).Commits()
 }
+
+func SetDefragBatchLimitForTest(limit int) (restore func()) {
+	old := defragBatchLimit
+	defragBatchLimit = limit
+	return func() { defragBatchLimit = old }
+}
+
+func SetAfterDefragBatchForTest(f func()) (restore func()) {
+	old := afterDefragBatch
+	afterDefragBatch = f
+	return func() { afterDefragBatch = old }
+}
+
+func OpenEngineForTest(bcfg BackendConfig) (Engine, error) {
+	return openEngine(bcfg)
+}
+
+// SetLogFileForTest makes the log engine write the log to f.
+func SetLogFileForTest(e Engine, f *os.File) (restore func()) {
+	le := e.(*logEngine)
+	old := le.f
+	le.f = &fileutil.LockedFile{File: f}
+	return func() { le.f = old }
+}
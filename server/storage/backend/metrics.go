@@ -90,6 +90,27 @@ var (
 		Name:      "defrag_inflight",
 		Help:      "Whether or not defrag is active on the member. 1 means active, 0 means not.",
 	})
+
+	freeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_free_bytes",
+		Help:      "The number of bytes allocated by the backend but not in use.",
+	})
+
+	freelistBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_freelist_bytes",
+		Help:      "The number of bytes taken by the list of the free pages of the backend.",
+	})
+
+	fragmentationRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_fragmentation_ratio",
+		Help:      "The ratio of the bytes allocated by the backend but not in use to its size.",
+	})
 )
 
 func init() {
@@ -100,4 +121,7 @@ func init() {
 	prometheus.MustRegister(defragSec)
 	prometheus.MustRegister(snapshotTransferSec)
 	prometheus.MustRegister(isDefragActive)
+	prometheus.MustRegister(freeBytes)
+	prometheus.MustRegister(freelistBytes)
+	prometheus.MustRegister(fragmentationRatio)
 }
@@ -21,6 +21,8 @@ const (
 	snapDirSegment     = "snap"
 	walDirSegment      = "wal"
 	backendFileSegment = "db"
+
+	httpSnapshotDirSegment = "http-snapshot"
 )
 
 func ToBackendFileName(dataDir string) string {
@@ -38,3 +40,9 @@ func ToWalDir(dataDir string) string {
 func ToMemberDir(dataDir string) string {
 	return filepath.Join(dataDir, memberDirSegment)
 }
+
+// ToHTTPSnapshotDir returns the directory holding the snapshots served over
+// HTTP, which are removed when the member restarts.
+func ToHTTPSnapshotDir(dataDir string) string {
+	return filepath.Join(ToMemberDir(dataDir), httpSnapshotDirSegment)
+}
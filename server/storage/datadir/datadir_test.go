@@ -45,3 +45,8 @@ func TestToWalDirSlash(t *testing.T) {
 	result := datadir.ToWalDir("/dir/data-dir/")
 	assert.Equal(t, "/dir/data-dir/member/wal", result)
 }
+
+func TestToHTTPSnapshotDir(t *testing.T) {
+	result := datadir.ToHTTPSnapshotDir("/dir/data-dir")
+	assert.Equal(t, "/dir/data-dir/member/http-snapshot", result)
+}
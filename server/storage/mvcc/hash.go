@@ -28,17 +28,45 @@ import (
 
 const (
 	hashStorageMaxSize = 10
+	// hashRangeKeys is the number of records hashed per range of the key
+	// bucket.
+	hashRangeKeys = 10000
 )
 
-func unsafeHashByRev(tx backend.ReadTx, compactRevision, revision int64, keep map[revision]struct{}) (KeyValueHash, error) {
-	h := newKVHasher(compactRevision, revision, keep)
-	err := tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
-		h.WriteKeyValue(k, v)
-		return nil
-	})
+func unsafeHashByRev(tx backend.ReadTx, compactRevision, rev int64, keep map[revision]struct{}) (KeyValueHash, error) {
+	h := newKVHasher(compactRevision, rev, keep)
+	err := unsafeHashRevisions(tx, &h, 0, rev)
 	return h.Hash(), err
 }
 
+// unsafeHashRevisions writes the records of the main revisions from up to to
+// into the hasher.
+func unsafeHashRevisions(tx backend.ReadTx, h *kvHasher, from, to int64) error {
+	// range over the key bucket in batches rather than iterating it, as the
+	// chunks of the records cannot be read during an iteration.
+	min, max := newRevBytes(), newRevBytes()
+	revToBytes(revision{main: from}, min)
+	revToBytes(revision{main: to + 1}, max)
+	for {
+		keys, vals := tx.UnsafeRange(schema.Key, min, max, hashRangeKeys)
+		for i := range keys {
+			// hash the plain records, so that the hash does not depend on
+			// the value compression nor chunking of the member.
+			v, err := unsafeReadKeyValue(tx, keys[i], vals[i])
+			if err != nil {
+				return err
+			}
+			h.WriteKeyValue(keys[i], v)
+		}
+		if len(keys) < hashRangeKeys {
+			return nil
+		}
+		next := bytesToRev(keys[len(keys)-1])
+		next.sub++
+		revToBytes(next, min)
+	}
+}
+
 type kvHasher struct {
 	hash            hash.Hash32
 	compactRevision int64
@@ -92,6 +120,11 @@ type HashStorage interface {
 	// HashByRev computes the hash of all MVCC revisions up to a given revision.
 	HashByRev(rev int64) (hash KeyValueHash, currentRev int64, err error)
 
+	// HashRevisions computes the hash of the MVCC revisions from a given
+	// revision up to another, which must all be after the compacted revision.
+	// The compact revision of the returned hash is the one before from.
+	HashRevisions(from, to int64) (hash KeyValueHash, currentRev int64, err error)
+
 	// Store adds hash value in local cache, allowing it can be returned by HashByRev.
 	Store(valueHash KeyValueHash)
 
@@ -134,6 +167,10 @@ func (s *hashStorage) HashByRev(rev int64) (KeyValueHash, int64, error) {
 	return s.store.hashByRev(rev)
 }
 
+func (s *hashStorage) HashRevisions(from, to int64) (KeyValueHash, int64, error) {
+	return s.store.hashRevisions(from, to)
+}
+
 func (s *hashStorage) Store(hash KeyValueHash) {
 	s.lg.Info("storing new hash",
 		zap.Uint32("hash", hash.Hash),
@@ -222,3 +222,41 @@ func TestHasherStoreFull(t *testing.T) {
 		t.Errorf("Didn't expect error for new revision, err: %v", err)
 	}
 }
+
+func TestHashRevisions(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	ob, _ := betesting.NewDefaultTmpBackend(t)
+	other := NewStore(lg, ob, &lease.FakeLessor{}, StoreConfig{})
+
+	for _, st := range []*store{s, other} {
+		st.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+		st.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+		st.DeleteRange([]byte("foo"), nil)
+	}
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	other.Put([]byte("foo"), []byte("corrupted"), lease.NoLease)
+
+	hash, currentRev, err := s.HashStorage().HashRevisions(3, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), currentRev)
+	assert.Equal(t, KeyValueHash{Hash: hash.Hash, CompactRevision: 2, Revision: 4}, hash)
+	ohash, _, err := other.HashStorage().HashRevisions(3, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, ohash, "hashes of the same revisions must match")
+
+	hash, _, err = s.HashStorage().HashRevisions(3, 5)
+	assert.NoError(t, err)
+	ohash, _, err = other.HashStorage().HashRevisions(3, 5)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, ohash, "hashes of different revisions must not match")
+
+	_, _, err = s.HashStorage().HashRevisions(3, 6)
+	assert.ErrorIs(t, err, ErrFutureRev)
+	done, err := s.Compact(traceutil.TODO(), 3)
+	assert.NoError(t, err)
+	<-done
+	_, _, err = s.HashStorage().HashRevisions(3, 5)
+	assert.ErrorIs(t, err, ErrCompacted)
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"hash/maphash"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hotKeySketchDepth and hotKeySketchWidth are the dimensions of the
+	// count-min sketch estimating the traffic per key prefix.
+	hotKeySketchDepth = 4
+	hotKeySketchWidth = 2048
+	// hotKeyTopK is the number of hottest prefixes tracked for each kind of
+	// traffic.
+	hotKeyTopK = 32
+	// hotKeyDecayInterval is the interval at which the counts are halved, so
+	// that they reflect the recent traffic.
+	hotKeyDecayInterval = time.Minute
+)
+
+// HotKey is a key prefix with the estimated number of operations on its keys.
+type HotKey struct {
+	Prefix []byte
+	Count  int64
+}
+
+// HotKeys are the key prefixes with the most traffic, for each kind of
+// traffic, in descending order of count.
+type HotKeys struct {
+	Reads       []HotKey
+	Writes      []HotKey
+	WatchEvents []HotKey
+}
+
+// hotKeyTracker estimates the read, write and watch traffic per key prefix.
+// A nil tracker records nothing.
+type hotKeyTracker struct {
+	reads       *hotKeySketch
+	writes      *hotKeySketch
+	watchEvents *hotKeySketch
+}
+
+func newHotKeyTracker() *hotKeyTracker {
+	return &hotKeyTracker{
+		reads:       newHotKeySketch("read"),
+		writes:      newHotKeySketch("write"),
+		watchEvents: newHotKeySketch("watch_event"),
+	}
+}
+
+func (t *hotKeyTracker) recordRead(key []byte) {
+	if t != nil {
+		t.reads.record(key, time.Now())
+	}
+}
+
+func (t *hotKeyTracker) recordWrite(key []byte) {
+	if t != nil {
+		t.writes.record(key, time.Now())
+	}
+}
+
+func (t *hotKeyTracker) recordWatchEvent(key []byte) {
+	if t != nil {
+		t.watchEvents.record(key, time.Now())
+	}
+}
+
+// hotKeys returns up to limit of the hottest prefixes of each kind of
+// traffic, all of them if limit is not positive.
+func (t *hotKeyTracker) hotKeys(limit int) HotKeys {
+	if t == nil {
+		return HotKeys{}
+	}
+	now := time.Now()
+	return HotKeys{
+		Reads:       t.reads.top(limit, now),
+		Writes:      t.writes.top(limit, now),
+		WatchEvents: t.watchEvents.top(limit, now),
+	}
+}
+
+// hotKeyPrefix returns the prefix the traffic on the key is accounted to: the
+// key up to and including its last '/', or the key itself if it has none.
+func hotKeyPrefix(key []byte) []byte {
+	if i := bytes.LastIndexByte(key, '/'); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
+
+// hotKeySketch is a count-min sketch of the operations per key prefix,
+// along with the prefixes of the highest estimates. Estimates may exceed
+// the actual counts on hash collisions, but never fall below them.
+type hotKeySketch struct {
+	traffic string
+	seed    maphash.Seed
+
+	mu        sync.Mutex
+	counts    [hotKeySketchDepth][hotKeySketchWidth]int64
+	hottest   map[string]int64
+	lastDecay time.Time
+	// reported are the prefixes whose count is reported by the metric.
+	reported []string
+}
+
+func newHotKeySketch(traffic string) *hotKeySketch {
+	return &hotKeySketch{
+		traffic:   traffic,
+		seed:      maphash.MakeSeed(),
+		hottest:   make(map[string]int64, hotKeyTopK),
+		lastDecay: time.Now(),
+	}
+}
+
+func (s *hotKeySketch) record(key []byte, now time.Time) {
+	prefix := hotKeyPrefix(key)
+	h := maphash.Bytes(s.seed, prefix)
+	// derive the indexes of the rows by double hashing
+	h1, h2 := uint32(h), uint32(h>>32)|1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decay(now)
+	est := int64(math.MaxInt64)
+	for i := range s.counts {
+		idx := (h1 + uint32(i)*h2) % hotKeySketchWidth
+		s.counts[i][idx]++
+		if c := s.counts[i][idx]; c < est {
+			est = c
+		}
+	}
+
+	if _, ok := s.hottest[string(prefix)]; ok || len(s.hottest) < hotKeyTopK {
+		s.hottest[string(prefix)] = est
+		return
+	}
+	coldest, coldestCount := "", int64(math.MaxInt64)
+	for p, c := range s.hottest {
+		if c < coldestCount {
+			coldest, coldestCount = p, c
+		}
+	}
+	if est > coldestCount {
+		delete(s.hottest, coldest)
+		s.hottest[string(prefix)] = est
+	}
+}
+
+// decay halves the counts once per decay interval, after reporting the
+// hottest prefixes to the metric.
+func (s *hotKeySketch) decay(now time.Time) {
+	if now.Sub(s.lastDecay) < hotKeyDecayInterval {
+		return
+	}
+	s.lastDecay = now
+
+	for _, p := range s.reported {
+		hotKeyPrefixGauge.DeleteLabelValues(s.traffic, p)
+	}
+	s.reported = s.reported[:0]
+	for p, c := range s.hottest {
+		hotKeyPrefixGauge.WithLabelValues(s.traffic, p).Set(float64(c))
+		s.reported = append(s.reported, p)
+	}
+
+	for i := range s.counts {
+		for j := range s.counts[i] {
+			s.counts[i][j] /= 2
+		}
+	}
+	for p, c := range s.hottest {
+		if c /= 2; c == 0 {
+			delete(s.hottest, p)
+		} else {
+			s.hottest[p] = c
+		}
+	}
+}
+
+func (s *hotKeySketch) top(limit int, now time.Time) []HotKey {
+	s.mu.Lock()
+	s.decay(now)
+	keys := make([]HotKey, 0, len(s.hottest))
+	for p, c := range s.hottest {
+		keys = append(keys, HotKey{Prefix: []byte(p), Count: c})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Count != keys[j].Count {
+			return keys[i].Count > keys[j].Count
+		}
+		return bytes.Compare(keys[i].Prefix, keys[j].Prefix) < 0
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestHotKeyPrefix(t *testing.T) {
+	tcs := []struct {
+		key  string
+		want string
+	}{
+		{key: "foo", want: "foo"},
+		{key: "/foo", want: "/"},
+		{key: "/registry/pods/default/nginx", want: "/registry/pods/default/"},
+		{key: "/registry/pods/", want: "/registry/pods/"},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.want, string(hotKeyPrefix([]byte(tc.key))))
+	}
+}
+
+func TestHotKeySketchTop(t *testing.T) {
+	s := newHotKeySketch("test")
+	now := s.lastDecay
+	for i := 0; i < 100; i++ {
+		s.record([]byte(fmt.Sprintf("/hot/%d", i)), now)
+	}
+	for i := 0; i < 10; i++ {
+		s.record([]byte(fmt.Sprintf("/warm/%d", i)), now)
+	}
+	// more cold prefixes than tracked ones must not evict the hot ones
+	for i := 0; i < 2*hotKeyTopK; i++ {
+		s.record([]byte(fmt.Sprintf("/cold%d/key", i)), now)
+	}
+
+	top := s.top(2, now)
+	if assert.Len(t, top, 2) {
+		assert.Equal(t, "/hot/", string(top[0].Prefix))
+		assert.GreaterOrEqual(t, top[0].Count, int64(100))
+		assert.Equal(t, "/warm/", string(top[1].Prefix))
+		assert.GreaterOrEqual(t, top[1].Count, int64(10))
+	}
+	assert.Len(t, s.top(0, now), hotKeyTopK)
+}
+
+func TestHotKeySketchDecay(t *testing.T) {
+	s := newHotKeySketch("test")
+	now := s.lastDecay
+	for i := 0; i < 8; i++ {
+		s.record([]byte("/a/key"), now)
+	}
+	s.record([]byte("/b/key"), now)
+
+	top := s.top(0, now.Add(hotKeyDecayInterval))
+	if assert.Len(t, top, 1) {
+		assert.Equal(t, "/a/", string(top[0].Prefix))
+		assert.GreaterOrEqual(t, top[0].Count, int64(4))
+	}
+}
+
+func TestHotKeyTrackerDisabled(t *testing.T) {
+	var tracker *hotKeyTracker
+	tracker.recordRead([]byte("foo"))
+	tracker.recordWrite([]byte("foo"))
+	tracker.recordWatchEvent([]byte("foo"))
+	assert.Equal(t, HotKeys{}, tracker.hotKeys(0))
+}
+
+func TestStoreHotKeys(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{HotKeyTracking: true})
+	defer cleanup(s, b, tmpPath)
+
+	s.Put([]byte("/foo/a"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("/foo/b"), []byte("bar"), lease.NoLease)
+	s.DeleteRange([]byte("/foo/a"), nil)
+	s.Range(context.TODO(), []byte("/foo/b"), nil, RangeOptions{})
+
+	hk := s.HotKeys(0)
+	if assert.Len(t, hk.Writes, 1) {
+		assert.Equal(t, HotKey{Prefix: []byte("/foo/"), Count: 3}, hk.Writes[0])
+	}
+	if assert.Len(t, hk.Reads, 1) {
+		assert.Equal(t, HotKey{Prefix: []byte("/foo/"), Count: 1}, hk.Reads[0])
+	}
+}
@@ -26,10 +26,12 @@ type index interface {
 	Range(key, end []byte, atRev int64) ([][]byte, []revision)
 	Revisions(key, end []byte, atRev int64, limit int) ([]revision, int)
 	CountRevisions(key, end []byte, atRev int64) int
-	Put(key []byte, rev revision)
+	Estimate(key, end []byte) (count int, size int64)
+	Put(key []byte, rev revision, size int)
 	Tombstone(key []byte, rev revision) error
 	Compact(rev int64) map[revision]struct{}
 	Keep(rev int64) map[revision]struct{}
+	Retained(key, end []byte, atRev, compactRev int64) bool
 	Equal(b index) bool
 
 	Insert(ki *keyIndex)
@@ -40,18 +42,28 @@ type treeIndex struct {
 	sync.RWMutex
 	tree *btree.BTreeG[*keyIndex]
 	lg   *zap.Logger
+	// retention keeps the last versions of the retained keys through the
+	// compactions.
+	retention versionRetention
 }
 
 func newTreeIndex(lg *zap.Logger) index {
+	return newRetainingTreeIndex(lg, nil)
+}
+
+func newRetainingTreeIndex(lg *zap.Logger, retention versionRetention) index {
 	return &treeIndex{
 		tree: btree.NewG(32, func(aki *keyIndex, bki *keyIndex) bool {
 			return aki.Less(bki)
 		}),
-		lg: lg,
+		lg:        lg,
+		retention: retention,
 	}
 }
 
-func (ti *treeIndex) Put(key []byte, rev revision) {
+// Put records the revision of a put of the key with a key and value of
+// the given size.
+func (ti *treeIndex) Put(key []byte, rev revision, size int) {
 	keyi := &keyIndex{key: key}
 
 	ti.Lock()
@@ -59,10 +71,12 @@ func (ti *treeIndex) Put(key []byte, rev revision) {
 	okeyi, ok := ti.tree.Get(keyi)
 	if !ok {
 		keyi.put(ti.lg, rev.main, rev.sub)
+		keyi.size = int64(size)
 		ti.tree.ReplaceOrInsert(keyi)
 		return
 	}
 	okeyi.put(ti.lg, rev.main, rev.sub)
+	okeyi.size = int64(size)
 }
 
 func (ti *treeIndex) Get(key []byte, atRev int64) (modified, created revision, ver int64, err error) {
@@ -155,6 +169,29 @@ func (ti *treeIndex) CountRevisions(key, end []byte, atRev int64) int {
 	return total
 }
 
+// Estimate returns the number of keys from key(included) to end(excluded)
+// at the latest revision, and the total size of their keys and values. It
+// only visits the in-memory index.
+func (ti *treeIndex) Estimate(key, end []byte) (count int, size int64) {
+	ti.RLock()
+	defer ti.RUnlock()
+
+	if end == nil {
+		if ki := ti.keyIndex(&keyIndex{key: key}); ki != nil && ki.isLive() {
+			return 1, ki.size
+		}
+		return 0, 0
+	}
+	ti.unsafeVisit(key, end, func(ki *keyIndex) bool {
+		if ki.isLive() {
+			count++
+			size += ki.size
+		}
+		return true
+	})
+	return count, size
+}
+
 func (ti *treeIndex) Range(key, end []byte, atRev int64) (keys [][]byte, revs []revision) {
 	ti.RLock()
 	defer ti.RUnlock()
@@ -200,7 +237,12 @@ func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
 		// Lock is needed here to prevent modification to the keyIndex while
 		// compaction is going on or revision added to empty before deletion
 		ti.Lock()
-		keyi.compact(ti.lg, rev, available)
+		atRev := ti.compactRev(keyi, rev, available)
+		if atRev == 0 {
+			ti.Unlock()
+			return true
+		}
+		keyi.compact(ti.lg, atRev, available)
 		if keyi.isEmpty() {
 			_, ok := ti.tree.Delete(keyi)
 			if !ok {
@@ -219,12 +261,57 @@ func (ti *treeIndex) Keep(rev int64) map[revision]struct{} {
 	ti.RLock()
 	defer ti.RUnlock()
 	ti.tree.Ascend(func(keyi *keyIndex) bool {
-		keyi.keep(rev, available)
+		if atRev := ti.compactRev(keyi, rev, available); atRev != 0 {
+			keyi.keep(atRev, available)
+		}
 		return true
 	})
 	return available
 }
 
+// compactRev returns the revision to compact the key at for a compaction at
+// the given rev, 0 if it is not to be compacted. The revisions of the key
+// retained past it are added to the available map.
+func (ti *treeIndex) compactRev(keyi *keyIndex, rev int64, available map[revision]struct{}) int64 {
+	n := ti.retention.versions(keyi.key)
+	if n == 0 {
+		return rev
+	}
+	atRev := keyi.retainedRev(rev, n)
+	if atRev < rev {
+		keyi.retain(atRev, rev, available)
+	}
+	return atRev
+}
+
+// Retained reports whether the compactions up to compactRev kept the revisions
+// of all the keys from key(included) to end(excluded) at the given rev, so
+// that they are read as they were at it.
+func (ti *treeIndex) Retained(key, end []byte, atRev, compactRev int64) bool {
+	if atRev >= compactRev {
+		return true
+	}
+	ti.RLock()
+	defer ti.RUnlock()
+
+	retained := true
+	check := func(ki *keyIndex) bool {
+		n := ti.retention.versions(ki.key)
+		if n == 0 || ki.retainedRev(compactRev, n) > atRev {
+			retained = false
+		}
+		return retained
+	}
+	if end == nil {
+		if keyi := ti.keyIndex(&keyIndex{key: key}); keyi != nil {
+			check(keyi)
+		}
+		return retained
+	}
+	ti.unsafeVisit(key, end, check)
+	return retained
+}
+
 func (ti *treeIndex) Equal(bi index) bool {
 	b := bi.(*treeIndex)
 
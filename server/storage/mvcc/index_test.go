@@ -24,8 +24,8 @@ import (
 
 func TestIndexGet(t *testing.T) {
 	ti := newTreeIndex(zaptest.NewLogger(t))
-	ti.Put([]byte("foo"), revision{main: 2})
-	ti.Put([]byte("foo"), revision{main: 4})
+	ti.Put([]byte("foo"), revision{main: 2}, 0)
+	ti.Put([]byte("foo"), revision{main: 4}, 0)
 	ti.Tombstone([]byte("foo"), revision{main: 6})
 
 	tests := []struct {
@@ -67,7 +67,7 @@ func TestIndexRange(t *testing.T) {
 
 	ti := newTreeIndex(zaptest.NewLogger(t))
 	for i := range allKeys {
-		ti.Put(allKeys[i], allRevs[i])
+		ti.Put(allKeys[i], allRevs[i], 0)
 	}
 
 	atRev := int64(3)
@@ -122,7 +122,7 @@ func TestIndexRange(t *testing.T) {
 
 func TestIndexTombstone(t *testing.T) {
 	ti := newTreeIndex(zaptest.NewLogger(t))
-	ti.Put([]byte("foo"), revision{main: 1})
+	ti.Put([]byte("foo"), revision{main: 1}, 0)
 
 	err := ti.Tombstone([]byte("foo"), revision{main: 2})
 	if err != nil {
@@ -139,13 +139,42 @@ func TestIndexTombstone(t *testing.T) {
 	}
 }
 
+func TestIndexEstimate(t *testing.T) {
+	ti := newTreeIndex(zaptest.NewLogger(t))
+	ti.Put([]byte("foo"), revision{main: 1}, 10)
+	ti.Put([]byte("foo1"), revision{main: 2}, 20)
+	ti.Put([]byte("foo2"), revision{main: 3}, 30)
+	ti.Put([]byte("foo1"), revision{main: 4}, 40)
+	if err := ti.Tombstone([]byte("foo2"), revision{main: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key, end []byte
+		wcount   int
+		wsize    int64
+	}{
+		{[]byte("foo"), nil, 1, 10},
+		{[]byte("foo2"), nil, 0, 0},
+		{[]byte("bar"), nil, 0, 0},
+		{[]byte("foo"), []byte("foo3"), 2, 50},
+		{[]byte("foo1"), []byte{}, 1, 40},
+	}
+	for i, tt := range tests {
+		count, size := ti.Estimate(tt.key, tt.end)
+		if count != tt.wcount || size != tt.wsize {
+			t.Errorf("#%d: estimate = (%d, %d), want (%d, %d)", i, count, size, tt.wcount, tt.wsize)
+		}
+	}
+}
+
 func TestIndexRevision(t *testing.T) {
 	allKeys := [][]byte{[]byte("foo"), []byte("foo1"), []byte("foo2"), []byte("foo2"), []byte("foo1"), []byte("foo")}
 	allRevs := []revision{{main: 1}, {main: 2}, {main: 3}, {main: 4}, {main: 5}, {main: 6}}
 
 	ti := newTreeIndex(zaptest.NewLogger(t))
 	for i := range allKeys {
-		ti.Put(allKeys[i], allRevs[i])
+		ti.Put(allKeys[i], allRevs[i], 0)
 	}
 
 	tests := []struct {
@@ -261,7 +290,7 @@ func TestIndexCompactAndKeep(t *testing.T) {
 		if tt.remove {
 			ti.Tombstone(tt.key, tt.rev)
 		} else {
-			ti.Put(tt.key, tt.rev)
+			ti.Put(tt.key, tt.rev, 0)
 		}
 	}
 	for i := int64(1); i < maxRev; i++ {
@@ -294,7 +323,7 @@ func TestIndexCompactAndKeep(t *testing.T) {
 			if tt.remove {
 				ti.Tombstone(tt.key, tt.rev)
 			} else {
-				ti.Put(tt.key, tt.rev)
+				ti.Put(tt.key, tt.rev, 0)
 			}
 		}
 		am := ti.Compact(i)
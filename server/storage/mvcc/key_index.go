@@ -75,6 +75,7 @@ type keyIndex struct {
 	key         []byte
 	modified    revision // the main rev of the last modification
 	generations []generation
+	size        int64 // the size of the key and value of the last put, for estimates
 }
 
 // put puts a revision to the keyIndex.
@@ -248,6 +249,43 @@ func (ki *keyIndex) keep(atRev int64, available map[revision]struct{}) {
 	}
 }
 
+// retainedRev returns the revision to compact the key at to keep its last
+// versions puts up to atRev, or 0 if the key has no more puts than that up
+// to atRev and is not to be compacted. The revisions of the key from it on
+// are kept, so that the key is read as it was at any revision from it on.
+func (ki *keyIndex) retainedRev(atRev int64, versions int) int64 {
+	for i := len(ki.generations) - 1; i >= 0; i-- {
+		revs := ki.generations[i].revs
+		if i != len(ki.generations)-1 && len(revs) > 0 {
+			// skip the tombstone
+			revs = revs[:len(revs)-1]
+		}
+		// skip the puts after atRev
+		n := len(revs)
+		for n > 0 && revs[n-1].main > atRev {
+			n--
+		}
+		if n < versions {
+			versions -= n
+			continue
+		}
+		return revs[n-versions].main
+	}
+	return 0
+}
+
+// retain adds the revisions of the key after fromRev up to toRev to the
+// available map.
+func (ki *keyIndex) retain(fromRev, toRev int64, available map[revision]struct{}) {
+	for _, g := range ki.generations {
+		for _, rev := range g.revs {
+			if rev.main > fromRev && rev.main <= toRev {
+				available[rev] = struct{}{}
+			}
+		}
+	}
+}
+
 func (ki *keyIndex) doCompact(atRev int64, available map[revision]struct{}) (genIdx int, revIndex int) {
 	// walk until reaching the first revision smaller or equal to "atRev",
 	// and add the revision to the available map
@@ -278,6 +316,11 @@ func (ki *keyIndex) isEmpty() bool {
 	return len(ki.generations) == 1 && ki.generations[0].isEmpty()
 }
 
+// isLive returns true if the key was not deleted since its last put.
+func (ki *keyIndex) isLive() bool {
+	return len(ki.generations) > 0 && !ki.generations[len(ki.generations)-1].isEmpty()
+}
+
 // findGeneration finds out the generation of the keyIndex that the
 // given rev belongs to. If the given rev is at the gap of two generations,
 // which means that the key does not exist at the given rev, it returns nil.
@@ -0,0 +1,127 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// expiredKeyRetryInterval is the delay after which an expired key is
+// reported again if it was not deleted.
+var expiredKeyRetryInterval = 3 * time.Second
+
+// ExpiredKey is a key whose TTL elapsed.
+type ExpiredKey struct {
+	Key []byte
+	// ModRevision is the revision the key was last put at. Deleting the
+	// key only if it was not modified since this revision avoids deleting
+	// a key put again after it was reported.
+	ModRevision int64
+}
+
+type keyExpiryItem struct {
+	key    string
+	rev    int64
+	expiry time.Time
+	index  int
+}
+
+type keyExpiryQueue []*keyExpiryItem
+
+func (q keyExpiryQueue) Len() int           { return len(q) }
+func (q keyExpiryQueue) Less(i, j int) bool { return q[i].expiry.Before(q[j].expiry) }
+
+func (q keyExpiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *keyExpiryQueue) Push(x interface{}) {
+	item := x.(*keyExpiryItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *keyExpiryQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	item.index = -1 // for safety
+	*q = old[0 : n-1]
+	return item
+}
+
+// keyExpiry tracks the expiration time of the keys put with a TTL. Only the
+// TTLs are persisted: as for leases, expiration times are computed from the
+// local time a put is applied at, and a restart gives the keys a full TTL.
+type keyExpiry struct {
+	mu    sync.Mutex
+	items map[string]*keyExpiryItem
+	queue keyExpiryQueue
+}
+
+func newKeyExpiry() *keyExpiry {
+	return &keyExpiry{items: make(map[string]*keyExpiryItem)}
+}
+
+// set schedules the expiration of the key put at rev, ttl seconds from now.
+func (e *keyExpiry) set(key string, rev, ttl int64, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	expiry := now.Add(time.Duration(ttl) * time.Second)
+	if item, ok := e.items[key]; ok {
+		item.rev, item.expiry = rev, expiry
+		heap.Fix(&e.queue, item.index)
+		return
+	}
+	item := &keyExpiryItem{key: key, rev: rev, expiry: expiry}
+	heap.Push(&e.queue, item)
+	e.items[key] = item
+}
+
+// remove cancels the expiration of the key. It returns false if the key had
+// no TTL.
+func (e *keyExpiry) remove(key string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	item, ok := e.items[key]
+	if !ok {
+		return false
+	}
+	heap.Remove(&e.queue, item.index)
+	delete(e.items, key)
+	return true
+}
+
+// expired returns up to limit keys whose expiration time passed. They are
+// reported again after expiredKeyRetryInterval unless removed or set again.
+func (e *keyExpiry) expired(now time.Time, limit int) []ExpiredKey {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var keys []ExpiredKey
+	for len(e.queue) > 0 && len(keys) < limit {
+		item := e.queue[0]
+		if item.expiry.After(now) {
+			break
+		}
+		keys = append(keys, ExpiredKey{Key: []byte(item.key), ModRevision: item.rev})
+		item.expiry = now.Add(expiredKeyRetryInterval)
+		heap.Fix(&e.queue, 0)
+	}
+	return keys
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestKeyExpiry(t *testing.T) {
+	e := newKeyExpiry()
+	now := time.Now()
+	e.set("a", 2, 10, now)
+	e.set("b", 3, 5, now)
+	e.set("c", 4, 20, now)
+
+	assert.Empty(t, e.expired(now.Add(4*time.Second), 10))
+	assert.Equal(t, []ExpiredKey{{Key: []byte("b"), ModRevision: 3}}, e.expired(now.Add(5*time.Second), 10))
+
+	// a put resets the TTL
+	e.set("a", 5, 10, now.Add(5*time.Second))
+	assert.Empty(t, e.expired(now.Add(7*time.Second), 10))
+
+	// expired keys are reported again after the retry interval
+	later := now.Add(5*time.Second + expiredKeyRetryInterval)
+	assert.Equal(t, []ExpiredKey{{Key: []byte("b"), ModRevision: 3}}, e.expired(later, 10))
+
+	assert.True(t, e.remove("b"))
+	assert.False(t, e.remove("b"))
+	assert.Equal(t, []ExpiredKey{{Key: []byte("a"), ModRevision: 5}}, e.expired(now.Add(time.Minute), 1))
+	assert.Equal(t, []ExpiredKey{{Key: []byte("c"), ModRevision: 4}}, e.expired(now.Add(time.Minute), 1))
+}
+
+func TestStorePutWithTTL(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	s.PutWithTTL([]byte("a"), []byte("v"), 10) // rev 2
+	s.PutWithTTL([]byte("b"), []byte("v"), 10) // rev 3
+	s.PutWithTTL([]byte("c"), []byte("v"), 10) // rev 4
+	s.Put([]byte("b"), []byte("v"), lease.NoLease)
+	s.DeleteRange([]byte("c"), nil)
+	assert.Empty(t, s.ExpiredKeys(10))
+	assert.Equal(t, []ExpiredKey{{Key: []byte("a"), ModRevision: 2}}, s.expiry.expired(time.Now().Add(10*time.Second), 10))
+	s.Close()
+
+	// the TTLs of the keys are restored with a full TTL
+	s = NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+	assert.Empty(t, s.expiry.expired(time.Now().Add(5*time.Second), 10))
+	assert.Equal(t, []ExpiredKey{{Key: []byte("a"), ModRevision: 2}}, s.expiry.expired(time.Now().Add(10*time.Second), 10))
+
+	var keys []string
+	tx := b.ReadTx()
+	tx.RLock()
+	schema.UnsafeForEachKeyTTL(tx, func(key []byte, rev, ttl int64) error {
+		keys = append(keys, string(key))
+		return nil
+	})
+	tx.RUnlock()
+	assert.Equal(t, []string{"a"}, keys)
+}
@@ -27,12 +27,17 @@ type RangeOptions struct {
 	Limit int64
 	Rev   int64
 	Count bool
+	// Estimate returns the count and the total size of the keys and values
+	// in the range at the latest revision, from the in-memory index only.
+	Estimate bool
 }
 
 type RangeResult struct {
 	KVs   []mvccpb.KeyValue
 	Rev   int64
 	Count int
+	// Size is the total size of the keys and values, set for estimates.
+	Size int64
 }
 
 type ReadView interface {
@@ -79,6 +84,11 @@ type WriteView interface {
 	// A put also increases the rev of the store, and generates one event in the event history.
 	// The returned rev is the current revision of the KV when the operation is executed.
 	Put(key, value []byte, lease lease.LeaseID) (rev int64)
+
+	// PutWithTTL puts the given key, value into the store like Put, without lease. The key
+	// expires once ttl seconds elapse without it being put or deleted: it is then reported
+	// by ExpiredKeys. A put without TTL removes the TTL of the key.
+	PutWithTTL(key, value []byte, ttl int64) (rev int64)
 }
 
 // TxnWrite represents a transaction that can modify the store.
@@ -87,6 +97,14 @@ type TxnWrite interface {
 	WriteView
 	// Changes gets the changes made since opening the write txn.
 	Changes() []mvccpb.KeyValue
+
+	// PutValuePart stages part as the next part of the value of key put in
+	// several parts as the upload, starting a new upload if upload is 0,
+	// without changing the revision. It returns the upload.
+	PutValuePart(key []byte, upload int64, part []byte) (int64, error)
+	// TakeValueParts removes the parts of the value of key staged as the
+	// upload, and returns them concatenated.
+	TakeValueParts(key []byte, upload int64) ([]byte, error)
 }
 
 // txnReadWrite coerces a read txn to a write, panicking on any write operation.
@@ -96,7 +114,16 @@ func (trw *txnReadWrite) DeleteRange(key, end []byte) (n, rev int64) { panic("un
 func (trw *txnReadWrite) Put(key, value []byte, lease lease.LeaseID) (rev int64) {
 	panic("unexpected Put")
 }
+func (trw *txnReadWrite) PutWithTTL(key, value []byte, ttl int64) (rev int64) {
+	panic("unexpected PutWithTTL")
+}
 func (trw *txnReadWrite) Changes() []mvccpb.KeyValue { return nil }
+func (trw *txnReadWrite) PutValuePart(key []byte, upload int64, part []byte) (int64, error) {
+	panic("unexpected PutValuePart")
+}
+func (trw *txnReadWrite) TakeValueParts(key []byte, upload int64) ([]byte, error) {
+	panic("unexpected TakeValueParts")
+}
 
 func NewReadOnlyTxnWrite(txn TxnRead) TxnWrite { return &txnReadWrite{txn} }
 
@@ -122,6 +149,26 @@ type KV interface {
 	// HashStorage returns HashStorage interface for KV storage.
 	HashStorage() HashStorage
 
+	// ExpiredKeys returns up to limit keys whose TTL elapsed. A returned key is
+	// reported again after a retry interval unless it is put or deleted meanwhile.
+	ExpiredKeys(limit int) []ExpiredKey
+
+	// IndexRange returns up to limit keys under the prefix whose JSON value has the
+	// value at the field path, looked up in the secondary index registered on them.
+	// The count of the result is the total number of matching keys. It returns
+	// ErrIndexNotFound if no such index is registered.
+	IndexRange(ctx context.Context, prefix []byte, field, value string, limit int64) (*RangeResult, error)
+
+	// HotKeys returns up to limit of the key prefixes with the most read, write
+	// and watch traffic, all of the tracked ones if limit is not positive. They
+	// are empty unless hot key tracking is enabled.
+	HotKeys(limit int) HotKeys
+
+	// PrefixStats returns the statistics of the keys under the prefix,
+	// grouped by their sub-prefixes depth '/' separated levels below it. They
+	// are empty unless prefix statistics are enabled.
+	PrefixStats(prefix []byte, depth int) []PrefixStat
+
 	// Compact frees all superseded keys with revisions less than rev.
 	Compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, error)
 
@@ -144,4 +191,9 @@ type Watchable interface {
 	// NewWatchStream returns a WatchStream that can be used to
 	// watch events happened or happening on the KV.
 	NewWatchStream() WatchStream
+
+	// WatchLag returns the lag of the watchers behind the current revision,
+	// with up to limit of the slowest of them, all of them if limit is not
+	// positive.
+	WatchLag(limit int) WatchLag
 }
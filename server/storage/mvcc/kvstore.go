@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -48,11 +49,51 @@ const (
 
 var restoreChunkKeys = 10000 // non-const for testing
 var defaultCompactBatchLimit = 1000
+var defaultCompactMinBatchLimit = 100
 var minimumBatchInterval = 10 * time.Millisecond
 
 type StoreConfig struct {
 	CompactionBatchLimit    int
 	CompactionSleepInterval time.Duration
+	// CompactionBatchTargetDuration paces the compaction: the batches are
+	// resized, between CompactionMinBatchLimit and CompactionBatchLimit
+	// revisions, for the time each holds the backend, its commit included,
+	// to approach it, and are spaced out by at least as long as they took.
+	// Zero disables the pacing.
+	CompactionBatchTargetDuration time.Duration
+	CompactionMinBatchLimit       int
+	// SecondaryIndexes are the fields of the JSON values indexed to be
+	// looked up by IndexRange.
+	SecondaryIndexes []SecondaryIndex
+	// HotKeyTracking enables the estimation of the traffic per key prefix
+	// reported by HotKeys.
+	HotKeyTracking bool
+	// PrefixStats enables the statistics of the keys per prefix reported by
+	// PrefixStats, kept up to date as the keys are written.
+	PrefixStats bool
+	// ValueCompression compresses the key-value records whose value is at
+	// least ValueCompressionThreshold bytes. The records are read whatever
+	// compression they were written with.
+	ValueCompression          ValueCompression
+	ValueCompressionThreshold int
+	// ValueCompressionAllowed reports whether the records may be compressed,
+	// that is whether every member that may read them, including from a
+	// snapshot, supports it. Nil allows it.
+	ValueCompressionAllowed func() bool
+	// ValueChunkSize splits the key-value records larger than it into chunks
+	// of at most its size, stored in the key chunk bucket. 0 stores every
+	// record whole.
+	ValueChunkSize int
+	// ValueChunkingAllowed reports whether the records may be split, as
+	// ValueCompressionAllowed does for their compression. Nil allows it.
+	ValueChunkingAllowed func() bool
+	// VersionRetention keeps the last versions of the keys with the given
+	// prefixes through the compactions, whatever the compacted revision.
+	// The retained revisions are read by the ranges within the prefixes
+	// below the compacted revision. It must be the same on every member
+	// for their hashes to match, which the server checks as the members
+	// join or restart.
+	VersionRetention []VersionRetention
 }
 
 type store struct {
@@ -66,6 +107,25 @@ type store struct {
 
 	b       backend.Backend
 	kvindex index
+	// retention keeps the last versions of the retained keys through the
+	// compactions.
+	retention versionRetention
+	// expiry tracks the expiration of the keys put with a TTL.
+	expiry *keyExpiry
+	// secondary indexes the keys by the fields of their values.
+	secondary *secondaryIndex
+	// hotKeys estimates the traffic per key prefix, nil if disabled.
+	hotKeys *hotKeyTracker
+	// prefixStats keeps the statistics of the keys per prefix, nil if
+	// disabled.
+	prefixStats *prefixStatTracker
+	// encodedKeyValues tells if the backend records that the key bucket may
+	// hold compressed or chunked records, guarded by the batch tx lock.
+	encodedKeyValues bool
+	// keyChunkBucket and valuePartBucket tell if the buckets created on first
+	// use exist, guarded by the batch tx lock.
+	keyChunkBucket  bool
+	valuePartBucket bool
 
 	le lease.Lessor
 
@@ -79,6 +139,9 @@ type store struct {
 	compactMainRev int64
 
 	fifoSched schedule.Scheduler
+	// compactionPacer sizes the compaction batches, only used by the
+	// compactions run one at a time by fifoSched.
+	compactionPacer *compactionPacer
 
 	stopc chan struct{}
 
@@ -98,10 +161,22 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 	if cfg.CompactionSleepInterval == 0 {
 		cfg.CompactionSleepInterval = minimumBatchInterval
 	}
+	if cfg.CompactionMinBatchLimit == 0 {
+		cfg.CompactionMinBatchLimit = defaultCompactMinBatchLimit
+	}
+	if cfg.CompactionMinBatchLimit > cfg.CompactionBatchLimit {
+		cfg.CompactionMinBatchLimit = cfg.CompactionBatchLimit
+	}
+	retention := newVersionRetention(cfg.VersionRetention)
 	s := &store{
 		cfg:     cfg,
 		b:       b,
-		kvindex: newTreeIndex(lg),
+		kvindex: newRetainingTreeIndex(lg, retention),
+		expiry:  newKeyExpiry(),
+
+		retention: retention,
+
+		secondary: newSecondaryIndex(cfg.SecondaryIndexes),
 
 		le: le,
 
@@ -110,10 +185,18 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 
 		fifoSched: schedule.NewFIFOScheduler(lg),
 
+		compactionPacer: newCompactionPacer(cfg),
+
 		stopc: make(chan struct{}),
 
 		lg: lg,
 	}
+	if cfg.HotKeyTracking {
+		s.hotKeys = newHotKeyTracker()
+	}
+	if cfg.PrefixStats {
+		s.prefixStats = newPrefixStatTracker()
+	}
 	s.hashes = newHashStorage(lg, s)
 	s.ReadView = &readView{s}
 	s.WriteView = &writeView{s}
@@ -125,6 +208,7 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 	tx.LockOutsideApply()
 	tx.UnsafeCreateBucket(schema.Key)
 	schema.UnsafeCreateMetaBucket(tx)
+	schema.UnsafeCreateKeyTTLBucket(tx)
 	tx.Unlock()
 	s.b.ForceCommit()
 
@@ -198,6 +282,32 @@ func (s *store) hashByRev(rev int64) (hash KeyValueHash, currentRev int64, err e
 	return hash, currentRev, err
 }
 
+func (s *store) hashRevisions(from, to int64) (hash KeyValueHash, currentRev int64, err error) {
+	start := time.Now()
+
+	s.mu.RLock()
+	s.revMu.RLock()
+	compactRev, currentRev := s.compactMainRev, s.currentRev
+	s.revMu.RUnlock()
+
+	if from <= compactRev {
+		s.mu.RUnlock()
+		return KeyValueHash{}, currentRev, ErrCompacted
+	} else if to > currentRev {
+		s.mu.RUnlock()
+		return KeyValueHash{}, currentRev, ErrFutureRev
+	}
+
+	tx := s.b.ReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	s.mu.RUnlock()
+	h := newKVHasher(from-1, to, nil)
+	err = unsafeHashRevisions(tx, &h, from, to)
+	hashRevSec.Observe(time.Since(start).Seconds())
+	return h.Hash(), currentRev, err
+}
+
 func (s *store) updateCompactRev(rev int64) (<-chan struct{}, int64, error) {
 	s.revMu.Lock()
 	if rev <= s.compactMainRev {
@@ -214,7 +324,11 @@ func (s *store) updateCompactRev(rev int64) (<-chan struct{}, int64, error) {
 	compactMainRev := s.compactMainRev
 	s.compactMainRev = rev
 
-	SetScheduledCompact(s.b.BatchTx(), rev)
+	tx := s.b.BatchTx()
+	tx.LockInsideApply()
+	UnsafeSetScheduledCompact(tx, rev)
+	s.unsafeDeleteValueUploads(tx, rev)
+	tx.Unlock()
 	// ensure that desired compaction is persisted
 	// gofail: var compactBeforeCommitScheduledCompact struct{}
 	s.b.ForceCommit()
@@ -284,7 +398,15 @@ func (s *store) Restore(b backend.Backend) error {
 	s.fifoSched.Stop()
 
 	s.b = b
-	s.kvindex = newTreeIndex(s.lg)
+	s.kvindex = newRetainingTreeIndex(s.lg, s.retention)
+	s.expiry = newKeyExpiry()
+	s.secondary = newSecondaryIndex(s.cfg.SecondaryIndexes)
+
+	// the snapshot may come from a member not supporting key TTLs
+	tx := s.b.BatchTx()
+	tx.LockOutsideApply()
+	schema.UnsafeCreateKeyTTLBucket(tx)
+	tx.Unlock()
 
 	{
 		// During restore the metrics might report 'special' values
@@ -313,6 +435,8 @@ func (s *store) restore() error {
 	tx := s.b.ReadTx()
 	tx.Lock()
 
+	s.encodedKeyValues = schema.UnsafeReadEncodedKeyValues(tx)
+	s.keyChunkBucket, s.valuePartBucket = false, false
 	finishedCompact, found := UnsafeReadFinishedCompact(tx)
 	if found {
 		s.revMu.Lock()
@@ -336,7 +460,7 @@ func (s *store) restore() error {
 		}
 		// rkvc blocks if the total pending keys exceeds the restore
 		// chunk size to keep keys from consuming too much memory.
-		restoreChunk(s.lg, rkvc, keys, vals, keyToLease)
+		restoreChunk(s.lg, tx, rkvc, keys, vals, keyToLease)
 		if len(keys) < restoreChunkKeys {
 			// partial set implies final set
 			break
@@ -380,6 +504,23 @@ func (s *store) restore() error {
 		}
 	}
 
+	now := time.Now()
+	err := schema.UnsafeForEachKeyTTL(tx, func(key []byte, rev, ttl int64) error {
+		// ignore the TTLs of keys modified without updating their TTL,
+		// for example by a filtered snapshot restore.
+		if modRev, _, _, err := s.kvindex.Get(key, s.currentRev); err == nil && modRev.main == rev {
+			s.expiry.set(string(key), rev, ttl, now)
+		}
+		return nil
+	})
+	if err != nil {
+		tx.Unlock()
+		return err
+	}
+
+	s.restoreSecondaryIndex(tx)
+	s.restorePrefixStats(tx)
+
 	tx.Unlock()
 
 	s.lg.Info("kvstore restored", zap.Int64("current-rev", s.currentRev))
@@ -441,8 +582,10 @@ func restoreIntoIndex(lg *zap.Logger, idx index) (chan<- revKeyValue, <-chan int
 					continue
 				}
 				ki.put(lg, rev.main, rev.sub)
+				ki.size = int64(len(rkv.kv.Key) + len(rkv.kv.Value))
 			} else if !isTombstone(rkv.key) {
 				ki.restore(lg, revision{rkv.kv.CreateRevision, 0}, rev, rkv.kv.Version)
+				ki.size = int64(len(rkv.kv.Key) + len(rkv.kv.Value))
 				idx.Insert(ki)
 				kiCache[rkv.kstr] = ki
 			}
@@ -451,10 +594,10 @@ func restoreIntoIndex(lg *zap.Logger, idx index) (chan<- revKeyValue, <-chan int
 	return rkvc, revc
 }
 
-func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID) {
+func restoreChunk(lg *zap.Logger, tx backend.ReadTx, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID) {
 	for i, key := range keys {
 		rkv := revKeyValue{key: key}
-		if err := rkv.kv.Unmarshal(vals[i]); err != nil {
+		if err := unsafeUnmarshalKeyValue(tx, &rkv.kv, key, vals[i]); err != nil {
 			lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
 		}
 		rkv.kstr = string(rkv.kv.Key)
@@ -522,3 +665,113 @@ func isTombstone(b []byte) bool {
 func (s *store) HashStorage() HashStorage {
 	return s.hashes
 }
+
+func (s *store) ExpiredKeys(limit int) []ExpiredKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.expiry.expired(time.Now(), limit)
+}
+
+func (s *store) HotKeys(limit int) HotKeys {
+	return s.hotKeys.hotKeys(limit)
+}
+
+func (s *store) PrefixStats(prefix []byte, depth int) []PrefixStat {
+	return s.prefixStats.prefixStats(prefix, depth)
+}
+
+// restorePrefixStats recomputes the statistics of the keys per prefix from
+// the key bucket, every revision of which is historical but the latest ones
+// of the live keys.
+func (s *store) restorePrefixStats(tx backend.ReadTx) {
+	if s.prefixStats == nil {
+		return
+	}
+	s.prefixStats.resetKeys()
+	min, max := newRevBytes(), newRevBytes()
+	revToBytes(revision{main: 1}, min)
+	revToBytes(revision{main: math.MaxInt64, sub: math.MaxInt64}, max)
+	for {
+		keys, vals := tx.UnsafeRange(schema.Key, min, max, int64(restoreChunkKeys))
+		for i, key := range keys {
+			var kv mvccpb.KeyValue
+			if err := unsafeUnmarshalKeyValue(tx, &kv, key, vals[i]); err != nil {
+				s.lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
+			}
+			live := false
+			if !isTombstone(key) {
+				modRev, _, _, err := s.kvindex.Get(kv.Key, s.currentRev)
+				live = err == nil && modRev == bytesToRev(key)
+			}
+			s.prefixStats.restore(kv.Key, int64(len(kv.Key)+len(kv.Value)), live)
+		}
+		if len(keys) < restoreChunkKeys {
+			break
+		}
+		next := bytesToRev(keys[len(keys)-1][:revBytesLen])
+		next.sub++
+		revToBytes(next, min)
+	}
+	s.lg.Info("restored prefix statistics")
+}
+
+// restoreSecondaryIndex indexes the keys under the prefixes of the secondary
+// indexes at the current revision.
+func (s *store) restoreSecondaryIndex(tx backend.ReadTx) {
+	if len(s.secondary.indexes) == 0 {
+		return
+	}
+	ibytes := newRevBytes()
+	for _, fi := range s.secondary.indexes {
+		keys, revs := s.kvindex.Range(fi.Prefix, prefixEnd(fi.Prefix), s.currentRev)
+		for i := range keys {
+			revToBytes(revs[i], ibytes)
+			_, vs := tx.UnsafeRange(schema.Key, ibytes, nil, 0)
+			if len(vs) != 1 {
+				s.lg.Fatal(
+					"range failed to find revision pair",
+					zap.Int64("revision-main", revs[i].main),
+					zap.Int64("revision-sub", revs[i].sub),
+				)
+			}
+			var kv mvccpb.KeyValue
+			if err := unsafeUnmarshalKeyValue(tx, &kv, ibytes, vs[0]); err != nil {
+				s.lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
+			}
+			s.secondary.put(kv.Key, kv.Value)
+		}
+	}
+	s.lg.Info("restored secondary indexes", zap.Int("indexes", len(s.secondary.indexes)))
+}
+
+// IndexRange returns the keys under the prefix whose field has the value,
+// looked up in the secondary index of the prefix and field.
+func (s *store) IndexRange(ctx context.Context, prefix []byte, field, value string, limit int64) (*RangeResult, error) {
+	keys, err := s.secondary.lookup(prefix, field, value)
+	if err != nil {
+		return nil, err
+	}
+	tr := s.Read(ConcurrentReadTxMode, traceutil.TODO())
+	defer tr.End()
+
+	res := &RangeResult{Rev: tr.Rev()}
+	for _, key := range keys {
+		r, err := tr.Range(ctx, key, nil, RangeOptions{})
+		if err != nil {
+			return nil, err
+		}
+		// the index is updated as writes are applied, so it may be ahead
+		// of the read transaction.
+		if len(r.KVs) == 0 {
+			continue
+		}
+		if v, ok := jsonField(r.KVs[0].Value, strings.Split(field, ".")); !ok || v != value {
+			continue
+		}
+		res.Count++
+		if limit <= 0 || int64(len(res.KVs)) < limit {
+			res.KVs = append(res.KVs, r.KVs[0])
+		}
+	}
+	return res, nil
+}
@@ -21,6 +21,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 )
 
@@ -38,26 +39,46 @@ func (s *store) scheduleCompaction(compactMainRev, prevCompactRev int64) (KeyVal
 	end := make([]byte, 8)
 	binary.BigEndian.PutUint64(end, uint64(compactMainRev+1))
 
-	batchNum := s.cfg.CompactionBatchLimit
-	batchTicker := time.NewTicker(s.cfg.CompactionSleepInterval)
-	defer batchTicker.Stop()
+	batchTimer := time.NewTimer(0)
+	if !batchTimer.Stop() {
+		<-batchTimer.C
+	}
+	defer batchTimer.Stop()
 	h := newKVHasher(prevCompactRev, compactMainRev, keep)
 	last := make([]byte, 8+1+8)
 	for {
 		var rev revision
 
 		start := time.Now()
+		batchNum := s.compactionPacer.limit
 
 		tx := s.b.BatchTx()
 		tx.LockOutsideApply()
 		keys, values := tx.UnsafeRange(schema.Key, last, end, int64(batchNum))
 		for i := range keys {
 			rev = bytesToRev(keys[i])
+			v, err := unsafeReadKeyValue(tx, keys[i], values[i])
+			if err != nil {
+				tx.Unlock()
+				return KeyValueHash{}, err
+			}
+			h.WriteKeyValue(keys[i], v)
 			if _, ok := keep[rev]; !ok {
+				if err := unsafeDeleteKeyValueChunks(tx, keys[i], values[i]); err != nil {
+					tx.Unlock()
+					return KeyValueHash{}, err
+				}
+				if s.prefixStats != nil {
+					var kv mvccpb.KeyValue
+					if err := kv.Unmarshal(v); err != nil {
+						tx.Unlock()
+						return KeyValueHash{}, err
+					}
+					s.prefixStats.compact(kv.Key, int64(len(kv.Key)+len(kv.Value)))
+				}
 				tx.UnsafeDelete(schema.Key, keys[i])
 				keyCompactions++
 			}
-			h.WriteKeyValue(keys[i], values[i])
 		}
 
 		if len(keys) < batchNum {
@@ -82,12 +103,70 @@ func (s *store) scheduleCompaction(compactMainRev, prevCompactRev int64) (KeyVal
 		// gofail: var compactBeforeCommitBatch struct{}
 		s.b.ForceCommit()
 		// gofail: var compactAfterCommitBatch struct{}
-		dbCompactionPauseMs.Observe(float64(time.Since(start) / time.Millisecond))
+		took := time.Since(start)
+		dbCompactionPauseMs.Observe(float64(took / time.Millisecond))
 
+		batchTimer.Reset(s.compactionPacer.next(took))
 		select {
-		case <-batchTicker.C:
+		case <-batchTimer.C:
 		case <-s.stopc:
 			return KeyValueHash{}, fmt.Errorf("interrupted due to stop signal")
 		}
 	}
 }
+
+// compactionPacer resizes the compaction batches for the time each holds the
+// backend, its commit included, to approach the target, and spaces them out
+// by at least as long as they took, so that the compaction of many revisions
+// leaves the backend to the requests at least half of the time. Without
+// target, the batches keep the maximum size and interval.
+type compactionPacer struct {
+	target   time.Duration
+	interval time.Duration
+	min, max int
+	// limit is the size of the next batch.
+	limit int
+}
+
+func newCompactionPacer(cfg StoreConfig) *compactionPacer {
+	return &compactionPacer{
+		target:   cfg.CompactionBatchTargetDuration,
+		interval: cfg.CompactionSleepInterval,
+		min:      cfg.CompactionMinBatchLimit,
+		max:      cfg.CompactionBatchLimit,
+		limit:    cfg.CompactionBatchLimit,
+	}
+}
+
+// next resizes the next batch after the last one took the given time, and
+// returns how long to wait before running it.
+func (p *compactionPacer) next(took time.Duration) time.Duration {
+	if p.target <= 0 {
+		// the interval runs from the start of the batch
+		if took >= p.interval {
+			return 0
+		}
+		return p.interval - took
+	}
+
+	if took <= 0 {
+		took = 1
+	}
+	limit := int(int64(p.limit) * int64(p.target) / int64(took))
+	// grow at most twofold, shrink as much as needed
+	if limit > 2*p.limit {
+		limit = 2 * p.limit
+	}
+	if limit < p.min {
+		limit = p.min
+	}
+	if limit > p.max {
+		limit = p.max
+	}
+	p.limit = limit
+
+	if took > p.interval {
+		return took
+	}
+	return p.interval
+}
@@ -144,3 +144,50 @@ func TestCompactAllAndRestore(t *testing.T) {
 		t.Errorf("unexpect range error %v", err)
 	}
 }
+
+func TestCompactionPacer(t *testing.T) {
+	p := newCompactionPacer(StoreConfig{
+		CompactionBatchLimit:          1000,
+		CompactionMinBatchLimit:       100,
+		CompactionSleepInterval:       10 * time.Millisecond,
+		CompactionBatchTargetDuration: 20 * time.Millisecond,
+	})
+	tests := []struct {
+		took time.Duration
+
+		wlimit int
+		wwait  time.Duration
+	}{
+		// twice as slow as the target halves the batches
+		{40 * time.Millisecond, 500, 40 * time.Millisecond},
+		// far slower than the target stops at the minimum batch size
+		{time.Second, 100, time.Second},
+		// faster than the target at most doubles the batches
+		{time.Millisecond, 200, 10 * time.Millisecond},
+		{10 * time.Millisecond, 400, 10 * time.Millisecond},
+		{20 * time.Millisecond, 400, 20 * time.Millisecond},
+		{time.Millisecond, 800, 10 * time.Millisecond},
+		// up to the maximum batch size
+		{time.Millisecond, 1000, 10 * time.Millisecond},
+	}
+	for i, tt := range tests {
+		wait := p.next(tt.took)
+		if p.limit != tt.wlimit {
+			t.Errorf("#%d: limit = %d, want %d", i, p.limit, tt.wlimit)
+		}
+		if wait != tt.wwait {
+			t.Errorf("#%d: wait = %v, want %v", i, wait, tt.wwait)
+		}
+	}
+
+	p = newCompactionPacer(StoreConfig{
+		CompactionBatchLimit:    1000,
+		CompactionSleepInterval: 10 * time.Millisecond,
+	})
+	if wait := p.next(4 * time.Millisecond); p.limit != 1000 || wait != 6*time.Millisecond {
+		t.Errorf("unpaced limit, wait = %d, %v, want 1000, 6ms", p.limit, wait)
+	}
+	if wait := p.next(time.Second); p.limit != 1000 || wait != 0 {
+		t.Errorf("unpaced limit, wait = %d, %v, want 1000, 0", p.limit, wait)
+	}
+}
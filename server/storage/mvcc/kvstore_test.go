@@ -391,6 +391,7 @@ func TestStoreRestore(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	b.tx.rangeRespc <- rangeResp{nil, nil}
 	b.tx.rangeRespc <- rangeResp{[][]byte{schema.FinishedCompactKeyName}, [][]byte{newTestRevBytes(revision{3, 0})}}
 	b.tx.rangeRespc <- rangeResp{[][]byte{schema.ScheduledCompactKeyName}, [][]byte{newTestRevBytes(revision{3, 0})}}
 
@@ -406,6 +407,7 @@ func TestStoreRestore(t *testing.T) {
 		t.Errorf("current rev = %v, want 5", s.currentRev)
 	}
 	wact := []testutil.Action{
+		{Name: "range", Params: []interface{}{schema.Meta, schema.MetaEncodedKeyValuesName, []byte(nil), int64(1)}},
 		{Name: "range", Params: []interface{}{schema.Meta, schema.FinishedCompactKeyName, []byte(nil), int64(0)}},
 		{Name: "range", Params: []interface{}{schema.Meta, schema.ScheduledCompactKeyName, []byte(nil), int64(0)}},
 		{Name: "range", Params: []interface{}{schema.Key, newTestRevBytes(revision{1, 0}), newTestRevBytes(revision{math.MaxInt64, math.MaxInt64}), int64(restoreChunkKeys)}},
@@ -418,7 +420,7 @@ func TestStoreRestore(t *testing.T) {
 		{created: revision{4, 0}, ver: 2, revs: []revision{{3, 0}, {5, 0}}},
 		{created: revision{0, 0}, ver: 0, revs: nil},
 	}
-	ki := &keyIndex{key: []byte("foo"), modified: revision{5, 0}, generations: gens}
+	ki := &keyIndex{key: []byte("foo"), modified: revision{5, 0}, generations: gens, size: 6}
 	wact = []testutil.Action{
 		{Name: "keyIndex", Params: []interface{}{ki}},
 		{Name: "insert", Params: []interface{}{ki}},
@@ -893,19 +895,23 @@ func newFakeStore(lg *zap.Logger) *store {
 	b := &fakeBackend{&fakeBatchTx{
 		Recorder:   &testutil.RecorderBuffered{},
 		rangeRespc: make(chan rangeResp, 5)}}
+	cfg := StoreConfig{
+		CompactionBatchLimit:    10000,
+		CompactionSleepInterval: minimumBatchInterval,
+	}
 	s := &store{
-		cfg: StoreConfig{
-			CompactionBatchLimit:    10000,
-			CompactionSleepInterval: minimumBatchInterval,
-		},
-		b:              b,
-		le:             &lease.FakeLessor{},
-		kvindex:        newFakeIndex(),
-		currentRev:     0,
-		compactMainRev: -1,
-		fifoSched:      schedule.NewFIFOScheduler(lg),
-		stopc:          make(chan struct{}),
-		lg:             lg,
+		cfg:             cfg,
+		b:               b,
+		le:              &lease.FakeLessor{},
+		kvindex:         newFakeIndex(),
+		expiry:          newKeyExpiry(),
+		secondary:       newSecondaryIndex(nil),
+		currentRev:      0,
+		compactMainRev:  -1,
+		fifoSched:       schedule.NewFIFOScheduler(lg),
+		compactionPacer: newCompactionPacer(cfg),
+		stopc:           make(chan struct{}),
+		lg:              lg,
 	}
 	s.ReadView, s.WriteView = &readView{s}, &writeView{s}
 	s.hashes = newHashStorage(lg, s)
@@ -974,6 +980,7 @@ func (b *fakeBackend) OpenReadTxN() int64
 func (b *fakeBackend) Snapshot() backend.Snapshot                                 { return nil }
 func (b *fakeBackend) ForceCommit()                                               {}
 func (b *fakeBackend) Defrag() error                                              { return nil }
+func (b *fakeBackend) Check() error                                               { return nil }
 func (b *fakeBackend) Close() error                                               { return nil }
 func (b *fakeBackend) SetTxPostLockInsideApplyHook(func())                        {}
 
@@ -1024,7 +1031,11 @@ func (i *fakeIndex) Range(key, end []byte, atRev int64) ([][]byte, []revision) {
 	r := <-i.indexRangeRespc
 	return r.keys, r.revs
 }
-func (i *fakeIndex) Put(key []byte, rev revision) {
+func (i *fakeIndex) Estimate(key, end []byte) (int, int64) {
+	i.Recorder.Record(testutil.Action{Name: "estimate", Params: []interface{}{key, end}})
+	return 0, 0
+}
+func (i *fakeIndex) Put(key []byte, rev revision, size int) {
 	i.Recorder.Record(testutil.Action{Name: "put", Params: []interface{}{key, rev}})
 }
 func (i *fakeIndex) Tombstone(key []byte, rev revision) error {
@@ -1044,7 +1055,8 @@ func (i *fakeIndex) Keep(rev int64) map[revision]struct{} {
 	i.Recorder.Record(testutil.Action{Name: "keep", Params: []interface{}{rev}})
 	return <-i.indexCompactRespc
 }
-func (i *fakeIndex) Equal(b index) bool { return false }
+func (i *fakeIndex) Retained(key, end []byte, atRev, compactRev int64) bool { return false }
+func (i *fakeIndex) Equal(b index) bool                                     { return false }
 
 func (i *fakeIndex) Insert(ki *keyIndex) {
 	i.Recorder.Record(testutil.Action{Name: "insert", Params: []interface{}{ki}})
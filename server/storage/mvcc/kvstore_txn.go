@@ -17,6 +17,7 @@ package mvcc
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -65,6 +66,7 @@ func (tr *storeTxnRead) Range(ctx context.Context, key, end []byte, ro RangeOpti
 }
 
 func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev int64, ro RangeOptions) (*RangeResult, error) {
+	tr.s.hotKeys.recordRead(key)
 	rev := ro.Rev
 	if rev > curRev {
 		return &RangeResult{KVs: nil, Count: -1, Rev: curRev}, ErrFutureRev
@@ -72,9 +74,16 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 	if rev <= 0 {
 		rev = curRev
 	}
-	if rev < tr.s.compactMainRev {
+	// the retained versions of the keys are kept below the compacted
+	// revision, the ranges with a key not retained at rev are compacted.
+	if rev < tr.s.compactMainRev && (!tr.s.retention.covers(key, end) || !tr.s.kvindex.Retained(key, end, rev, tr.s.compactMainRev)) {
 		return &RangeResult{KVs: nil, Count: -1, Rev: 0}, ErrCompacted
 	}
+	if ro.Estimate {
+		count, size := tr.s.kvindex.Estimate(key, end)
+		tr.trace.Step("estimate keys from in-memory index tree")
+		return &RangeResult{KVs: nil, Count: count, Size: size, Rev: curRev}, nil
+	}
 	if ro.Count {
 		total := tr.s.kvindex.CountRevisions(key, end, rev)
 		tr.trace.Step("count revisions from in-memory index tree")
@@ -115,7 +124,7 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 				zap.Int("len-values", len(vs)),
 			)
 		}
-		if err := kvs[i].Unmarshal(vs[0]); err != nil {
+		if err := unsafeUnmarshalKeyValue(tr.tx, &kvs[i], revBytes, vs[0]); err != nil {
 			tr.s.lg.Fatal(
 				"failed to unmarshal mvccpb.KeyValue",
 				zap.Error(err),
@@ -170,7 +179,12 @@ func (tw *storeTxnWrite) DeleteRange(key, end []byte) (int64, int64) {
 }
 
 func (tw *storeTxnWrite) Put(key, value []byte, lease lease.LeaseID) int64 {
-	tw.put(key, value, lease)
+	tw.put(key, value, lease, 0)
+	return tw.beginRev + 1
+}
+
+func (tw *storeTxnWrite) PutWithTTL(key, value []byte, ttl int64) int64 {
+	tw.put(key, value, lease.NoLease, ttl)
 	return tw.beginRev + 1
 }
 
@@ -188,7 +202,8 @@ func (tw *storeTxnWrite) End() {
 	tw.s.mu.RUnlock()
 }
 
-func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
+func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID, ttl int64) {
+	tw.s.hotKeys.recordWrite(key)
 	rev := tw.beginRev + 1
 	c := rev
 	oldLease := lease.NoLease
@@ -223,12 +238,27 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 		)
 	}
 
+	d = tw.s.compressKeyValue(d, len(value))
+
+	if tw.s.prefixStats != nil {
+		n, prevSize := tw.s.kvindex.Estimate(key, nil)
+		tw.s.prefixStats.put(key, int64(len(key)+len(value)), prevSize, n == 1)
+	}
+
 	tw.trace.Step("marshal mvccpb.KeyValue")
-	tw.tx.UnsafeSeqPut(schema.Key, ibytes, d)
-	tw.s.kvindex.Put(key, idxRev)
+	tw.s.unsafePutKeyValue(tw.tx, ibytes, d)
+	tw.s.kvindex.Put(key, idxRev, len(key)+len(value))
+	tw.s.secondary.put(key, value)
 	tw.changes = append(tw.changes, kv)
 	tw.trace.Step("store kv pair into bolt db")
 
+	if ttl > 0 {
+		schema.UnsafePutKeyTTL(tw.tx, key, rev, ttl)
+		tw.s.expiry.set(string(key), rev, ttl, time.Now())
+	} else if tw.s.expiry.remove(string(key)) {
+		schema.UnsafeDeleteKeyTTL(tw.tx, key)
+	}
+
 	if oldLease == leaseID {
 		tw.trace.Step("attach lease to kv pair")
 		return
@@ -274,6 +304,7 @@ func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
 }
 
 func (tw *storeTxnWrite) delete(key []byte) {
+	tw.s.hotKeys.recordWrite(key)
 	ibytes := newRevBytes()
 	idxRev := revision{main: tw.beginRev + 1, sub: int64(len(tw.changes))}
 	revToBytes(idxRev, ibytes)
@@ -290,6 +321,10 @@ func (tw *storeTxnWrite) delete(key []byte) {
 		)
 	}
 
+	if tw.s.prefixStats != nil {
+		_, prevSize := tw.s.kvindex.Estimate(key, nil)
+		tw.s.prefixStats.delete(key, prevSize)
+	}
 	tw.tx.UnsafeSeqPut(schema.Key, ibytes, d)
 	err = tw.s.kvindex.Tombstone(key, idxRev)
 	if err != nil {
@@ -299,8 +334,13 @@ func (tw *storeTxnWrite) delete(key []byte) {
 			zap.Error(err),
 		)
 	}
+	tw.s.secondary.delete(key)
 	tw.changes = append(tw.changes, kv)
 
+	if tw.s.expiry.remove(string(key)) {
+		schema.UnsafeDeleteKeyTTL(tw.tx, key)
+	}
+
 	item := lease.LeaseItem{Key: string(key)}
 	leaseID := tw.s.le.GetLease(item)
 
@@ -85,6 +85,22 @@ var (
 			Help:      "Total number of unsynced slow watchers.",
 		})
 
+	watchMaxLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "mvcc",
+			Name:      "watch_max_lag_revisions",
+			Help:      "Number of revisions the slowest watcher is behind the current revision.",
+		})
+
+	watchPendingEventsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "mvcc",
+			Name:      "watch_backlog_events",
+			Help:      "Number of events held in memory for the slow watchers blocked on their full watch channel.",
+		})
+
 	totalEventsCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "etcd_debugging",
@@ -265,6 +281,16 @@ var (
 			Name:      "total_put_size_in_bytes",
 			Help:      "The total size of put kv pairs seen by this member.",
 		})
+
+	hotKeyPrefixGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "mvcc",
+			Name:      "hot_key_prefix_operations",
+			Help:      "The estimated number of operations on the hottest key prefixes, halved every minute, by kind of traffic.",
+		},
+		[]string{"traffic", "prefix"},
+	)
 )
 
 func init() {
@@ -276,6 +302,8 @@ func init() {
 	prometheus.MustRegister(watchStreamGauge)
 	prometheus.MustRegister(watcherGauge)
 	prometheus.MustRegister(slowWatcherGauge)
+	prometheus.MustRegister(watchMaxLagGauge)
+	prometheus.MustRegister(watchPendingEventsGauge)
 	prometheus.MustRegister(totalEventsCounter)
 	prometheus.MustRegister(pendingEventsGauge)
 	prometheus.MustRegister(indexCompactionPauseMs)
@@ -291,6 +319,7 @@ func init() {
 	prometheus.MustRegister(currentRev)
 	prometheus.MustRegister(compactRev)
 	prometheus.MustRegister(totalPutSizeGauge)
+	prometheus.MustRegister(hotKeyPrefixGauge)
 }
 
 // ReportEventReceived reports that an event is received.
@@ -0,0 +1,197 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PrefixStat is the statistics of the keys under a key prefix.
+type PrefixStat struct {
+	Prefix []byte
+	// Keys is the number of keys.
+	Keys int64
+	// LiveBytes is the size of the keys and values of their latest revisions.
+	LiveBytes int64
+	// HistoricalBytes is the size of the keys and values of their previous
+	// revisions, tombstones included, not yet compacted.
+	HistoricalBytes int64
+	// Watchers is the number of watchers whose key is under the prefix.
+	Watchers int64
+}
+
+func (st *PrefixStat) add(o *PrefixStat) {
+	st.Keys += o.Keys
+	st.LiveBytes += o.LiveBytes
+	st.HistoricalBytes += o.HistoricalBytes
+	st.Watchers += o.Watchers
+}
+
+func (st *PrefixStat) isZero() bool {
+	return st.Keys == 0 && st.LiveBytes == 0 && st.HistoricalBytes == 0 && st.Watchers == 0
+}
+
+// prefixStatTracker keeps the statistics of the keys per parent, the key up
+// to and including its last '/', up to date as the keys are written,
+// compacted and watched, so that the statistics of a prefix only sum those
+// of the parents under it. A nil tracker records nothing.
+type prefixStatTracker struct {
+	mu    sync.Mutex
+	stats map[string]*PrefixStat
+}
+
+func newPrefixStatTracker() *prefixStatTracker {
+	return &prefixStatTracker{stats: make(map[string]*PrefixStat)}
+}
+
+// keyParent returns the key up to and including its last '/', empty if it
+// has none.
+func keyParent(key []byte) []byte {
+	return key[:bytes.LastIndexByte(key, '/')+1]
+}
+
+// update applies f to the statistics of the parent of the key, dropping them
+// once they are all zero.
+func (t *prefixStatTracker) update(key []byte, f func(st *PrefixStat)) {
+	if t == nil {
+		return
+	}
+	parent := keyParent(key)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st, ok := t.stats[string(parent)]
+	if !ok {
+		st = &PrefixStat{}
+		t.stats[string(parent)] = st
+	}
+	f(st)
+	if st.isZero() {
+		delete(t.stats, string(parent))
+	}
+}
+
+// put accounts a put of the key and a value of size bytes in total, over
+// the live revision of the key of prevSize bytes if it had one.
+func (t *prefixStatTracker) put(key []byte, size, prevSize int64, existed bool) {
+	t.update(key, func(st *PrefixStat) {
+		if existed {
+			st.LiveBytes -= prevSize
+			st.HistoricalBytes += prevSize
+		} else {
+			st.Keys++
+		}
+		st.LiveBytes += size
+	})
+}
+
+// delete accounts a deletion of the key whose live revision is of prevSize
+// bytes. The tombstone counts as a historical revision of the key alone.
+func (t *prefixStatTracker) delete(key []byte, prevSize int64) {
+	t.update(key, func(st *PrefixStat) {
+		st.Keys--
+		st.LiveBytes -= prevSize
+		st.HistoricalBytes += prevSize + int64(len(key))
+	})
+}
+
+// compact accounts the removal of a historical revision of the key of size
+// bytes.
+func (t *prefixStatTracker) compact(key []byte, size int64) {
+	t.update(key, func(st *PrefixStat) {
+		st.HistoricalBytes -= size
+	})
+}
+
+// watch accounts delta watchers of the key.
+func (t *prefixStatTracker) watch(key []byte, delta int64) {
+	t.update(key, func(st *PrefixStat) {
+		st.Watchers += delta
+	})
+}
+
+// resetKeys clears the statistics of the keys, keeping the watchers, for
+// them to be restored.
+func (t *prefixStatTracker) resetKeys() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for p, st := range t.stats {
+		if st.Watchers == 0 {
+			delete(t.stats, p)
+		} else {
+			*st = PrefixStat{Watchers: st.Watchers}
+		}
+	}
+}
+
+// restore accounts a revision of the key of size bytes, either the live
+// revision of the key or a historical one.
+func (t *prefixStatTracker) restore(key []byte, size int64, live bool) {
+	t.update(key, func(st *PrefixStat) {
+		if live {
+			st.Keys++
+			st.LiveBytes += size
+		} else {
+			st.HistoricalBytes += size
+		}
+	})
+}
+
+// prefixStats returns the statistics of the keys under the prefix grouped
+// by their sub-prefixes depth levels below it, sorted by prefix.
+func (t *prefixStatTracker) prefixStats(prefix []byte, depth int) []PrefixStat {
+	if t == nil {
+		return nil
+	}
+	grouped := make(map[string]*PrefixStat)
+	t.mu.Lock()
+	for parent, st := range t.stats {
+		if !strings.HasPrefix(parent, string(prefix)) {
+			continue
+		}
+		sub := subPrefix(parent, len(prefix), depth)
+		g, ok := grouped[sub]
+		if !ok {
+			g = &PrefixStat{Prefix: []byte(sub)}
+			grouped[sub] = g
+		}
+		g.add(st)
+	}
+	t.mu.Unlock()
+
+	stats := make([]PrefixStat, 0, len(grouped))
+	for _, g := range grouped {
+		stats = append(stats, *g)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return bytes.Compare(stats[i].Prefix, stats[j].Prefix) < 0
+	})
+	return stats
+}
+
+// subPrefix cuts the parent after the depth-th '/' following its first n
+// bytes, or returns it whole if it has fewer levels.
+func subPrefix(parent string, n, depth int) string {
+	for ; depth > 0; depth-- {
+		i := strings.IndexByte(parent[n:], '/')
+		if i < 0 {
+			return parent
+		}
+		n += i + 1
+	}
+	return parent[:n]
+}
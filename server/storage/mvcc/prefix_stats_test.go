@@ -0,0 +1,99 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestSubPrefix(t *testing.T) {
+	tcs := []struct {
+		parent string
+		prefix string
+		depth  int
+		want   string
+	}{
+		{parent: "/registry/pods/default/", prefix: "/registry/", depth: 0, want: "/registry/"},
+		{parent: "/registry/pods/default/", prefix: "/registry/", depth: 1, want: "/registry/pods/"},
+		{parent: "/registry/pods/default/", prefix: "/registry/", depth: 2, want: "/registry/pods/default/"},
+		{parent: "/registry/pods/default/", prefix: "/registry/", depth: 3, want: "/registry/pods/default/"},
+		{parent: "/registry/pods/default/", prefix: "/registry/po", depth: 1, want: "/registry/pods/"},
+		{parent: "", prefix: "", depth: 1, want: ""},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.want, subPrefix(tc.parent, len(tc.prefix), tc.depth), "parent %q prefix %q depth %d", tc.parent, tc.prefix, tc.depth)
+	}
+}
+
+func TestPrefixStatTrackerDisabled(t *testing.T) {
+	var tracker *prefixStatTracker
+	tracker.put([]byte("foo"), 1, 0, false)
+	tracker.delete([]byte("foo"), 1)
+	tracker.watch([]byte("foo"), 1)
+	assert.Nil(t, tracker.prefixStats(nil, 0))
+}
+
+func TestStorePrefixStats(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{PrefixStats: true})
+	defer cleanup(s, b, tmpPath)
+
+	s.Put([]byte("/foo/a"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("/foo/a"), []byte("bazz"), lease.NoLease)
+	s.Put([]byte("/foo/b/c"), []byte("x"), lease.NoLease)
+	s.Put([]byte("top"), []byte("v"), lease.NoLease)
+	s.DeleteRange([]byte("/foo/a"), nil)
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	wid, err := w.Watch(0, []byte("/foo/b/"), []byte("/foo/b0"), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte("/foo/"), Keys: 1, LiveBytes: 9, HistoricalBytes: 9 + 10 + 6, Watchers: 1},
+	}, s.PrefixStats([]byte("/foo/"), 0))
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte("/foo/"), HistoricalBytes: 9 + 10 + 6},
+		{Prefix: []byte("/foo/b/"), Keys: 1, LiveBytes: 9, Watchers: 1},
+	}, s.PrefixStats([]byte("/foo/"), 1))
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte(""), Keys: 1, LiveBytes: 4},
+		{Prefix: []byte("/"), Keys: 1, LiveBytes: 9, HistoricalBytes: 9 + 10 + 6, Watchers: 1},
+	}, s.PrefixStats(nil, 1))
+
+	done, err := s.Compact(traceutil.TODO(), 3)
+	require.NoError(t, err)
+	<-done
+	compacted := s.PrefixStats(nil, 2)
+	assert.Less(t, compacted[1].HistoricalBytes, int64(9+10+6), "compaction must remove historical revisions")
+
+	// commit the deletes of the compaction, not reflected by the read buffer
+	s.Commit()
+	require.NoError(t, s.Restore(b))
+	assert.Equal(t, compacted, s.PrefixStats(nil, 2), "restored statistics must match the ones kept up to date")
+
+	require.NoError(t, w.Cancel(wid))
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte("/foo/b/"), Keys: 1, LiveBytes: 9},
+	}, s.PrefixStats([]byte("/foo/b/"), 0))
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrIndexNotFound = errors.New("mvcc: secondary index not found")
+
+// SecondaryIndex registers an index on a field of the JSON values of the
+// keys with a prefix.
+type SecondaryIndex struct {
+	// Prefix is the key prefix of the indexed keys.
+	Prefix []byte
+	// Field is the dot separated path of the indexed field, for example
+	// "spec.nodeName".
+	Field string
+}
+
+// ParseSecondaryIndex parses a secondary index given as "<prefix>=<field>".
+func ParseSecondaryIndex(s string) (SecondaryIndex, error) {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return SecondaryIndex{}, fmt.Errorf("invalid secondary index %q, expected <prefix>=<field>", s)
+	}
+	field := s[i+1:]
+	for _, p := range strings.Split(field, ".") {
+		if p == "" {
+			return SecondaryIndex{}, fmt.Errorf("invalid secondary index %q, empty field path element", s)
+		}
+	}
+	return SecondaryIndex{Prefix: []byte(s[:i]), Field: field}, nil
+}
+
+type fieldIndex struct {
+	SecondaryIndex
+	path []string
+
+	// values maps the indexed keys to their field value.
+	values map[string]string
+	// keys maps the field values to the set of keys having them.
+	keys map[string]map[string]struct{}
+}
+
+// secondaryIndex maps the values of the indexed fields to the keys holding
+// them at the current revision. As the key index, it is kept in memory only
+// and rebuilt from the backend on restore.
+type secondaryIndex struct {
+	mu      sync.RWMutex
+	indexes []*fieldIndex
+}
+
+func newSecondaryIndex(cfgs []SecondaryIndex) *secondaryIndex {
+	si := &secondaryIndex{}
+	for _, cfg := range cfgs {
+		si.indexes = append(si.indexes, &fieldIndex{
+			SecondaryIndex: cfg,
+			path:           strings.Split(cfg.Field, "."),
+			values:         make(map[string]string),
+			keys:           make(map[string]map[string]struct{}),
+		})
+	}
+	return si
+}
+
+// put indexes the new value of the key.
+func (si *secondaryIndex) put(key, value []byte) {
+	if len(si.indexes) == 0 {
+		return
+	}
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	for _, fi := range si.indexes {
+		if !bytes.HasPrefix(key, fi.Prefix) {
+			continue
+		}
+		fi.remove(string(key))
+		if v, ok := jsonField(value, fi.path); ok {
+			fi.add(string(key), v)
+		}
+	}
+}
+
+// delete removes the key from the indexes.
+func (si *secondaryIndex) delete(key []byte) {
+	if len(si.indexes) == 0 {
+		return
+	}
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	for _, fi := range si.indexes {
+		if bytes.HasPrefix(key, fi.Prefix) {
+			fi.remove(string(key))
+		}
+	}
+}
+
+// lookup returns the sorted keys whose field has the value in the index of
+// the prefix and field.
+func (si *secondaryIndex) lookup(prefix []byte, field, value string) ([][]byte, error) {
+	si.mu.RLock()
+	defer si.mu.RUnlock()
+	for _, fi := range si.indexes {
+		if !bytes.Equal(fi.Prefix, prefix) || fi.Field != field {
+			continue
+		}
+		keys := make([][]byte, 0, len(fi.keys[value]))
+		for k := range fi.keys[value] {
+			keys = append(keys, []byte(k))
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+		return keys, nil
+	}
+	return nil, ErrIndexNotFound
+}
+
+func (fi *fieldIndex) add(key, value string) {
+	fi.values[key] = value
+	ks, ok := fi.keys[value]
+	if !ok {
+		ks = make(map[string]struct{})
+		fi.keys[value] = ks
+	}
+	ks[key] = struct{}{}
+}
+
+func (fi *fieldIndex) remove(key string) {
+	value, ok := fi.values[key]
+	if !ok {
+		return
+	}
+	delete(fi.values, key)
+	delete(fi.keys[value], key)
+	if len(fi.keys[value]) == 0 {
+		delete(fi.keys, value)
+	}
+}
+
+// jsonField returns the value at the path in the JSON object. Strings are
+// returned as is, numbers and booleans by their JSON encoding. Values that
+// are not JSON objects, or other field types, are not indexed.
+func jsonField(value []byte, path []string) (string, bool) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(value))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return "", false
+	}
+	for _, p := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if v, ok = obj[p]; !ok {
+			return "", false
+		}
+	}
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return strconv.FormatBool(x), true
+	default:
+		return "", false
+	}
+}
+
+// prefixEnd returns the end of the range of the keys with the prefix. The
+// range has no end if the prefix only holds 0xff bytes.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	return []byte{}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestParseSecondaryIndex(t *testing.T) {
+	tests := []struct {
+		s    string
+		want SecondaryIndex
+		werr bool
+	}{
+		{s: "/pods/=spec.nodeName", want: SecondaryIndex{Prefix: []byte("/pods/"), Field: "spec.nodeName"}},
+		{s: "/a=b/=status", want: SecondaryIndex{Prefix: []byte("/a=b/"), Field: "status"}},
+		{s: "/pods/", werr: true},
+		{s: "=spec", werr: true},
+		{s: "/pods/=", werr: true},
+		{s: "/pods/=spec..nodeName", werr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			si, err := ParseSecondaryIndex(tt.s)
+			if tt.werr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, si)
+		})
+	}
+}
+
+func TestJSONField(t *testing.T) {
+	path := []string{"spec", "nodeName"}
+	tests := []struct {
+		value string
+		want  string
+		wok   bool
+	}{
+		{value: `{"spec":{"nodeName":"n1"}}`, want: "n1", wok: true},
+		{value: `{"spec":{"nodeName":3}}`, want: "3", wok: true},
+		{value: `{"spec":{"nodeName":1.50}}`, want: "1.50", wok: true},
+		{value: `{"spec":{"nodeName":true}}`, want: "true", wok: true},
+		{value: `{"spec":{"nodeName":null}}`},
+		{value: `{"spec":{"nodeName":{"a":1}}}`},
+		{value: `{"spec":{}}`},
+		{value: `{"spec":"n1"}`},
+		{value: `not json`},
+	}
+	for _, tt := range tests {
+		v, ok := jsonField([]byte(tt.value), path)
+		assert.Equal(t, tt.wok, ok, tt.value)
+		assert.Equal(t, tt.want, v, tt.value)
+	}
+}
+
+func TestStoreIndexRange(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	cfg := StoreConfig{SecondaryIndexes: []SecondaryIndex{{Prefix: []byte("/pods/"), Field: "spec.nodeName"}}}
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+
+	s.Put([]byte("/pods/a"), []byte(`{"spec":{"nodeName":"n1"}}`), lease.NoLease)
+	s.Put([]byte("/pods/b"), []byte(`{"spec":{"nodeName":"n2"}}`), lease.NoLease)
+	s.Put([]byte("/pods/c"), []byte(`{"spec":{"nodeName":"n1"}}`), lease.NoLease)
+	s.Put([]byte("/pods/d"), []byte(`{"spec":{"nodeName":"n1"}}`), lease.NoLease)
+	s.Put([]byte("/nodes/n1"), []byte(`{"spec":{"nodeName":"n1"}}`), lease.NoLease)
+	// /pods/d moves to n2 and /pods/b is deleted
+	s.Put([]byte("/pods/d"), []byte(`{"spec":{"nodeName":"n2"}}`), lease.NoLease)
+	s.DeleteRange([]byte("/pods/b"), nil)
+
+	assertKeys := func(s *store, value string, limit int64, wkeys []string, wcount int) {
+		t.Helper()
+		r, err := s.IndexRange(context.TODO(), []byte("/pods/"), "spec.nodeName", value, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, kv := range r.KVs {
+			keys = append(keys, string(kv.Key))
+		}
+		assert.Equal(t, wkeys, keys)
+		assert.Equal(t, wcount, r.Count)
+		assert.Equal(t, s.Rev(), r.Rev)
+	}
+	assertKeys(s, "n1", 0, []string{"/pods/a", "/pods/c"}, 2)
+	assertKeys(s, "n1", 1, []string{"/pods/a"}, 2)
+	assertKeys(s, "n2", 0, []string{"/pods/d"}, 1)
+	assertKeys(s, "n3", 0, nil, 0)
+
+	_, err := s.IndexRange(context.TODO(), []byte("/pods/"), "status.phase", "Running", 0)
+	assert.Equal(t, ErrIndexNotFound, err)
+	s.Close()
+
+	// the index is rebuilt on restore
+	s = NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+	defer cleanup(s, b, tmpPath)
+	assertKeys(s, "n1", 0, []string{"/pods/a", "/pods/c"}, 2)
+	assertKeys(s, "n2", 0, []string{"/pods/d"}, 1)
+}
@@ -15,6 +15,10 @@
 package mvcc
 
 import (
+	"bytes"
+	"math"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 )
@@ -58,3 +62,94 @@ func UnsafeSetFinishedCompact(tx backend.BatchTx, value int64) {
 	revToBytes(revision{main: value}, rbytes)
 	tx.UnsafePut(schema.Meta, schema.FinishedCompactKeyName, rbytes)
 }
+
+// UnsafePurgeKeyRange removes from the key bucket every revision of the keys
+// in the range [key, end), except for tombstones, and returns the number of
+// revisions removed. Tombstones are kept so that the store revision does not
+// move backwards. An empty end only matches key, while end "\x00" matches
+// every key greater than or equal to key.
+func UnsafePurgeKeyRange(tx backend.BatchTx, key, end []byte) (int, error) {
+	return unsafeDeleteRevisionsIf(tx, func(rev []byte, kv *mvccpb.KeyValue) bool {
+		return !isTombstone(rev) && inKeyRange(kv.Key, key, end)
+	})
+}
+
+// UnsafeDeleteKeysIf removes from the key bucket every revision, including
+// tombstones, of the keys for which drop returns true, and returns the
+// number of revisions removed.
+func UnsafeDeleteKeysIf(tx backend.BatchTx, drop func(key []byte) bool) (int, error) {
+	return unsafeDeleteRevisionsIf(tx, func(_ []byte, kv *mvccpb.KeyValue) bool {
+		return drop(kv.Key)
+	})
+}
+
+// UnsafeDeleteRevisionsAfter removes from the key bucket every revision
+// greater than rev, and returns the number of revisions removed.
+func UnsafeDeleteRevisionsAfter(tx backend.BatchTx, rev int64) int {
+	min, max := newRevBytes(), newRevBytes()
+	revToBytes(revision{main: rev + 1}, min)
+	revToBytes(revision{main: math.MaxInt64, sub: math.MaxInt64}, max)
+	keys, vals := tx.UnsafeRange(schema.Key, min, max, 0)
+	revs := make([][]byte, len(keys))
+	for i, k := range keys {
+		revs[i] = append([]byte(nil), k...)
+		// a corrupted chunked record leaves its chunks behind, harmlessly
+		_ = unsafeDeleteKeyValueChunks(tx, revs[i], vals[i])
+	}
+	for _, rev := range revs {
+		tx.UnsafeDelete(schema.Key, rev)
+	}
+	return len(revs)
+}
+
+// UnsafePutKeyValue writes kv to the key bucket at the revision made of its
+// mod revision and the given sub revision, preserving its create revision,
+// version and lease. The key index is rebuilt from the key bucket when the
+// store is opened.
+func UnsafePutKeyValue(tx backend.BatchTx, kv *mvccpb.KeyValue, sub int64) error {
+	v, err := kv.Marshal()
+	if err != nil {
+		return err
+	}
+	rbytes := newRevBytes()
+	revToBytes(revision{main: kv.ModRevision, sub: sub}, rbytes)
+	tx.UnsafePut(schema.Key, rbytes, v)
+	return nil
+}
+
+func unsafeDeleteRevisionsIf(tx backend.BatchTx, drop func(rev []byte, kv *mvccpb.KeyValue) bool) (int, error) {
+	var revs [][]byte
+	err := tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
+		var kv mvccpb.KeyValue
+		if err := unsafeUnmarshalKeyValue(tx, &kv, k, v); err != nil {
+			return err
+		}
+		if drop(k, &kv) {
+			revs = append(revs, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, rev := range revs {
+		_, vs := tx.UnsafeRange(schema.Key, rev, nil, 0)
+		if len(vs) == 1 {
+			if err := unsafeDeleteKeyValueChunks(tx, rev, vs[0]); err != nil {
+				return 0, err
+			}
+		}
+		tx.UnsafeDelete(schema.Key, rev)
+	}
+	return len(revs), nil
+}
+
+func inKeyRange(k, key, end []byte) bool {
+	if len(end) == 0 {
+		return bytes.Equal(k, key)
+	}
+	if bytes.Compare(k, key) < 0 {
+		return false
+	}
+	return bytes.Equal(end, []byte{0}) || bytes.Compare(k, end) < 0
+}
@@ -15,6 +15,8 @@
 package mvcc
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"testing"
@@ -23,6 +25,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 	"go.etcd.io/etcd/server/v3/storage/schema"
@@ -111,3 +115,135 @@ func TestFinishedCompact(t *testing.T) {
 		})
 	}
 }
+
+// TestPurgeKeyRange ensures that UnsafePurgeKeyRange removes every revision of
+// the keys in range but their tombstones, and leaves other keys untouched.
+func TestPurgeKeyRange(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	s := NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	s.Put([]byte("/junk/a"), []byte("1"), lease.NoLease)
+	s.Put([]byte("/junk/a"), []byte("2"), lease.NoLease)
+	s.Put([]byte("/junk/b"), []byte("3"), lease.NoLease)
+	s.Put([]byte("/keep"), []byte("4"), lease.NoLease)
+	s.DeleteRange([]byte("/junk/"), []byte("/junk0"))
+	rev := s.Rev()
+	s.Close()
+
+	tx := b.BatchTx()
+	tx.Lock()
+	n, err := UnsafePurgeKeyRange(tx, []byte("/junk/"), []byte("/junk0"))
+	tx.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	b.ForceCommit()
+
+	s = NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	assert.Equal(t, rev, s.Rev())
+	r, err := s.Range(context.TODO(), []byte("/"), []byte("0"), RangeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.KVs))
+	assert.Equal(t, "/keep", string(r.KVs[0].Key))
+	r, err = s.Range(context.TODO(), []byte("/junk/a"), nil, RangeOptions{Rev: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(r.KVs))
+}
+
+// TestDeleteRevisionsAfter ensures that UnsafeDeleteRevisionsAfter restores
+// the key space as of the given revision.
+func TestDeleteRevisionsAfter(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	s := NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	s.Put([]byte("foo"), []byte("1"), lease.NoLease)
+	s.Put([]byte("bar"), []byte("2"), lease.NoLease)
+	s.Put([]byte("foo"), []byte("3"), lease.NoLease)
+	s.DeleteRange([]byte("bar"), nil)
+	s.Close()
+
+	tx := b.BatchTx()
+	tx.Lock()
+	n := UnsafeDeleteRevisionsAfter(tx, 3)
+	tx.Unlock()
+	assert.Equal(t, 2, n)
+	b.ForceCommit()
+
+	s = NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	assert.Equal(t, int64(3), s.Rev())
+	r, err := s.Range(context.TODO(), []byte("a"), []byte("z"), RangeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(r.KVs))
+	assert.Equal(t, "bar", string(r.KVs[0].Key))
+	assert.Equal(t, "foo", string(r.KVs[1].Key))
+	assert.Equal(t, "1", string(r.KVs[1].Value))
+}
+
+// TestDeleteKeysIf ensures that UnsafeDeleteKeysIf removes every revision,
+// including tombstones, of the matching keys.
+func TestDeleteKeysIf(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	s := NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	s.Put([]byte("/a/1"), []byte("1"), lease.NoLease)
+	s.Put([]byte("/b/1"), []byte("2"), lease.NoLease)
+	s.Put([]byte("/a/1"), []byte("3"), lease.NoLease)
+	s.DeleteRange([]byte("/a/1"), nil)
+	s.Close()
+
+	tx := b.BatchTx()
+	tx.Lock()
+	n, err := UnsafeDeleteKeysIf(tx, func(key []byte) bool { return bytes.HasPrefix(key, []byte("/a/")) })
+	tx.Unlock()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	b.ForceCommit()
+
+	s = NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	assert.Equal(t, int64(3), s.Rev())
+	r, err := s.Range(context.TODO(), []byte("/"), []byte("0"), RangeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.KVs))
+	assert.Equal(t, "/b/1", string(r.KVs[0].Key))
+}
+
+func TestPutKeyValue(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	s := NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	s.Close()
+
+	kvs := []*mvccpb.KeyValue{
+		{Key: []byte("a"), Value: []byte("1"), CreateRevision: 2, ModRevision: 7, Version: 3},
+		{Key: []byte("b"), Value: []byte("2"), CreateRevision: 5, ModRevision: 7, Version: 1},
+		{Key: []byte("c"), Value: []byte("3"), CreateRevision: 4, ModRevision: 4, Version: 1},
+	}
+	tx := b.BatchTx()
+	tx.Lock()
+	for i, kv := range kvs[:2] {
+		assert.NoError(t, UnsafePutKeyValue(tx, kv, int64(i)))
+	}
+	assert.NoError(t, UnsafePutKeyValue(tx, kvs[2], 0))
+	tx.Unlock()
+	b.ForceCommit()
+
+	s = NewStore(lg, b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+	assert.Equal(t, int64(7), s.Rev())
+	r, err := s.Range(context.TODO(), []byte("a"), []byte("z"), RangeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(kvs), len(r.KVs))
+	for i := range kvs {
+		assert.Equal(t, *kvs[i], r.KVs[i])
+	}
+}
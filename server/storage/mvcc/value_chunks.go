@@ -0,0 +1,134 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// chunkedKeyValueEncoding follows the encodedKeyValueMarker in the head of
+// the key-value records split into chunks. It is followed by the number of
+// chunks as an uvarint and by the first chunk, the others being stored in
+// the key chunk bucket.
+const chunkedKeyValueEncoding byte = 0xff
+
+// chunkKeyValue splits the record into chunks of at most size bytes. It
+// returns the head record, stored in the key bucket in place of the record,
+// and the chunks following the first one.
+func chunkKeyValue(record []byte, size int) (head []byte, chunks [][]byte) {
+	n := (len(record) + size - 1) / size
+	first, rest := record[:size], record[size:]
+	head = make([]byte, 2, 2+binary.MaxVarintLen32+len(first))
+	head[0], head[1] = encodedKeyValueMarker, chunkedKeyValueEncoding
+	head = binary.AppendUvarint(head, uint64(n))
+	head = append(head, first...)
+	for len(rest) > 0 {
+		l := size
+		if l > len(rest) {
+			l = len(rest)
+		}
+		chunks = append(chunks, rest[:l])
+		rest = rest[l:]
+	}
+	return head, chunks
+}
+
+// keyValueChunks returns the number of chunks of the record, and its first
+// chunk, or false if the record is whole.
+func keyValueChunks(record []byte) (n uint32, first []byte, ok bool, err error) {
+	if len(record) < 2 || record[0] != encodedKeyValueMarker || record[1] != chunkedKeyValueEncoding {
+		return 0, nil, false, nil
+	}
+	v, l := binary.Uvarint(record[2:])
+	if l <= 0 || v < 2 || v > math.MaxUint32 {
+		return 0, nil, false, fmt.Errorf("invalid chunked key-value record header")
+	}
+	return uint32(v), record[2+l:], true, nil
+}
+
+// unsafeReadKeyValue returns the plain record stored under the key bucket
+// key rev, reassembled from its chunks and decompressed as needed.
+func unsafeReadKeyValue(tx backend.ReadTx, rev, record []byte) ([]byte, error) {
+	n, first, ok, err := keyValueChunks(record)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		chunks := schema.UnsafeRangeKeyChunks(tx, rev[:revBytesLen])
+		if len(chunks) != int(n)-1 {
+			return nil, fmt.Errorf("found %d chunks of the key-value record %x, expected %d", len(chunks), rev, n-1)
+		}
+		size := len(first)
+		for _, c := range chunks {
+			size += len(c)
+		}
+		record = make([]byte, 0, size)
+		record = append(record, first...)
+		for _, c := range chunks {
+			record = append(record, c...)
+		}
+	}
+	return decompressKeyValue(record)
+}
+
+// unsafeUnmarshalKeyValue unmarshals the record stored under the key bucket
+// key rev into kv, whether it is chunked, compressed or not.
+func unsafeUnmarshalKeyValue(tx backend.ReadTx, kv *mvccpb.KeyValue, rev, record []byte) error {
+	record, err := unsafeReadKeyValue(tx, rev, record)
+	if err != nil {
+		return err
+	}
+	return kv.Unmarshal(record)
+}
+
+// unsafeDeleteKeyValueChunks removes the chunks of the record stored under
+// the key bucket key rev, if any.
+func unsafeDeleteKeyValueChunks(tx backend.BatchTx, rev, record []byte) error {
+	n, _, ok, err := keyValueChunks(record)
+	if err != nil || !ok {
+		return err
+	}
+	schema.UnsafeDeleteKeyChunks(tx, rev[:revBytesLen], n)
+	return nil
+}
+
+// unsafePutKeyValue stores the record under the key bucket key rev, split
+// into chunks if the store is configured to and the record is too large.
+func (s *store) unsafePutKeyValue(tx backend.BatchTx, rev, record []byte) {
+	size := s.cfg.ValueChunkSize
+	if size > 0 && len(record) > size && (s.cfg.ValueChunkingAllowed == nil || s.cfg.ValueChunkingAllowed()) {
+		var chunks [][]byte
+		record, chunks = chunkKeyValue(record, size)
+		if !s.keyChunkBucket {
+			schema.UnsafeCreateKeyChunkBucket(tx)
+			s.keyChunkBucket = true
+		}
+		for i, c := range chunks {
+			schema.UnsafePutKeyChunk(tx, rev, uint32(i+1), c)
+		}
+	}
+	if !s.encodedKeyValues && record[0] == encodedKeyValueMarker {
+		// keeps the storage from being downgraded to v3.5
+		schema.UnsafeSetEncodedKeyValues(tx)
+		s.encodedKeyValues = true
+	}
+	tx.UnsafeSeqPut(schema.Key, rev, record)
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestChunkKeyValue(t *testing.T) {
+	record := bytes.Repeat([]byte("0123456789"), 25)
+	head, chunks := chunkKeyValue(record, 100)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, record[100:200], chunks[0])
+	assert.Equal(t, record[200:], chunks[1])
+
+	n, first, ok, err := keyValueChunks(head)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3), n)
+	assert.Equal(t, record[:100], first)
+
+	var kv mvccpb.KeyValue
+	assert.ErrorIs(t, UnmarshalKeyValue(&kv, head), ErrChunkedKeyValue)
+
+	_, _, ok, err = keyValueChunks(record)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, _, err = keyValueChunks([]byte{encodedKeyValueMarker, chunkedKeyValueEncoding})
+	assert.Error(t, err)
+}
+
+func TestStoreValueChunks(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	tcs := []struct {
+		name        string
+		allowed     bool
+		wantChunked bool
+	}{
+		{name: "allowed", allowed: true, wantChunked: true},
+		{name: "not allowed by the cluster version", allowed: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b, tmpPath := betesting.NewDefaultTmpBackend(t)
+			cfg := StoreConfig{
+				ValueChunkSize:       1024,
+				ValueChunkingAllowed: func() bool { return tc.allowed },
+			}
+			s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+			defer cleanup(s, b, tmpPath)
+
+			ub, utmpPath := betesting.NewDefaultTmpBackend(t)
+			us := NewStore(zaptest.NewLogger(t), ub, &lease.FakeLessor{}, StoreConfig{})
+			defer cleanup(us, ub, utmpPath)
+
+			for _, st := range []*store{s, us} {
+				st.Put([]byte("large"), large, lease.NoLease)
+				st.Put([]byte("large"), append(large, '!'), lease.NoLease)
+				st.Commit()
+			}
+
+			countChunks := func() int {
+				tx := b.ReadTx()
+				tx.RLock()
+				defer tx.RUnlock()
+				n := 0
+				tx.UnsafeForEach(schema.KeyChunk, func(k, v []byte) error {
+					n++
+					return nil
+				})
+				return n
+			}
+			if tc.wantChunked {
+				assert.Equal(t, 2*(len(large)/1024), countChunks())
+			} else {
+				assert.Zero(t, countChunks())
+			}
+
+			r, err := s.Range(context.TODO(), []byte("large"), nil, RangeOptions{Rev: 2})
+			require.NoError(t, err)
+			require.Len(t, r.KVs, 1)
+			assert.Equal(t, large, r.KVs[0].Value)
+
+			hash, _, err := s.HashStorage().HashByRev(0)
+			require.NoError(t, err)
+			uhash, _, err := us.HashStorage().HashByRev(0)
+			require.NoError(t, err)
+			assert.Equal(t, uhash, hash, "hash must not depend on the value chunking")
+
+			for _, st := range []*store{s, us} {
+				done, err := st.Compact(traceutil.TODO(), 3)
+				require.NoError(t, err)
+				<-done
+				st.Commit()
+			}
+			assert.Equal(t, us.HashStorage().Hashes(), s.HashStorage().Hashes(), "compaction hash must not depend on the value chunking")
+			if tc.wantChunked {
+				assert.Equal(t, len(large)/1024, countChunks(), "compaction must remove the chunks of the compacted revisions")
+			}
+
+			require.NoError(t, s.Restore(b))
+			r, err = s.Range(context.TODO(), []byte("large"), nil, RangeOptions{})
+			require.NoError(t, err)
+			require.Len(t, r.KVs, 1)
+			assert.Equal(t, append(large, '!'), r.KVs[0].Value)
+		})
+	}
+}
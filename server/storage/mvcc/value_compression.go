@@ -0,0 +1,183 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// encodedKeyValueMarker starts the key-value records stored other than as a
+// marshaled mvccpb.KeyValue, followed by their encoding: the
+// ValueCompression they were compressed with, or chunkedKeyValueEncoding. A
+// marshaled mvccpb.KeyValue never starts with it, as 0 is not a valid
+// protobuf field number, so that plain records are read as they always were.
+const encodedKeyValueMarker = schema.EncodedKeyValueMarker
+
+// ValueCompression is the algorithm compressing the key-value records.
+type ValueCompression byte
+
+const (
+	// ValueCompressionNone stores the key-value records uncompressed.
+	ValueCompressionNone ValueCompression = iota
+	// ValueCompressionDeflate compresses the key-value records with DEFLATE,
+	// favoring speed over the compression ratio.
+	ValueCompressionDeflate
+	// ValueCompressionSnappy compresses the key-value records with Snappy,
+	// the fastest of the algorithms with the lowest compression ratio.
+	ValueCompressionSnappy
+	// ValueCompressionZstd compresses the key-value records with Zstandard,
+	// with the highest compression ratio.
+	ValueCompressionZstd
+)
+
+var valueCompressionNames = map[ValueCompression]string{
+	ValueCompressionNone:    "none",
+	ValueCompressionDeflate: "deflate",
+	ValueCompressionSnappy:  "snappy",
+	ValueCompressionZstd:    "zstd",
+}
+
+func (c ValueCompression) String() string {
+	if name, ok := valueCompressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", byte(c))
+}
+
+// ParseValueCompression returns the ValueCompression of the given name. An
+// empty name is ValueCompressionNone.
+func ParseValueCompression(name string) (ValueCompression, error) {
+	if name == "" {
+		return ValueCompressionNone, nil
+	}
+	for c, n := range valueCompressionNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return ValueCompressionNone, fmt.Errorf("unknown value compression %q", name)
+}
+
+var deflateWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
+
+// zstdEncoder and zstdDecoder are safe for concurrent use of EncodeAll and
+// DecodeAll, and are shared by all the stores.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// compressKeyValue returns the record compressed with the compression c,
+// or the record itself if it does not get smaller.
+func compressKeyValue(c ValueCompression, record []byte) []byte {
+	header := []byte{encodedKeyValueMarker, byte(c)}
+	var compressed []byte
+	switch c {
+	case ValueCompressionDeflate:
+		var buf bytes.Buffer
+		buf.Grow(len(record))
+		buf.Write(header)
+		w := deflateWriters.Get().(*flate.Writer)
+		defer deflateWriters.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(record); err != nil {
+			return record
+		}
+		if err := w.Close(); err != nil {
+			return record
+		}
+		compressed = buf.Bytes()
+	case ValueCompressionSnappy:
+		compressed = make([]byte, len(header)+snappy.MaxEncodedLen(len(record)))
+		copy(compressed, header)
+		compressed = compressed[:len(header)+len(snappy.Encode(compressed[len(header):], record))]
+	case ValueCompressionZstd:
+		compressed = zstdEncoder.EncodeAll(record, append(make([]byte, 0, len(record)), header...))
+	default:
+		return record
+	}
+	if len(compressed) >= len(record) {
+		return record
+	}
+	return compressed
+}
+
+// decompressKeyValue returns the uncompressed record, the record itself if
+// it is not compressed.
+func decompressKeyValue(record []byte) ([]byte, error) {
+	if len(record) == 0 || record[0] != encodedKeyValueMarker {
+		return record, nil
+	}
+	if len(record) < 2 {
+		return nil, fmt.Errorf("truncated compressed key-value record")
+	}
+	switch c := ValueCompression(record[1]); c {
+	case ValueCompressionDeflate:
+		r := flate.NewReader(bytes.NewReader(record[2:]))
+		defer r.Close()
+		return io.ReadAll(r)
+	case ValueCompressionSnappy:
+		return snappy.Decode(nil, record[2:])
+	case ValueCompressionZstd:
+		return zstdDecoder.DecodeAll(record[2:], nil)
+	default:
+		return nil, fmt.Errorf("unsupported key-value record compression %v", c)
+	}
+}
+
+// ErrChunkedKeyValue is returned by UnmarshalKeyValue for the records split
+// into chunks, which cannot be unmarshaled without their other chunks.
+var ErrChunkedKeyValue = errors.New("mvcc: key-value record is split into chunks")
+
+// UnmarshalKeyValue unmarshals a record of the key bucket into kv, whether
+// it is compressed or not.
+func UnmarshalKeyValue(kv *mvccpb.KeyValue, record []byte) error {
+	if _, _, ok, _ := keyValueChunks(record); ok {
+		return ErrChunkedKeyValue
+	}
+	record, err := decompressKeyValue(record)
+	if err != nil {
+		return err
+	}
+	return kv.Unmarshal(record)
+}
+
+// compressKeyValue returns the record of a put of a value of the given size,
+// compressed if the store is configured to and the value is large enough.
+func (s *store) compressKeyValue(record []byte, valueSize int) []byte {
+	if s.cfg.ValueCompression == ValueCompressionNone || valueSize < s.cfg.ValueCompressionThreshold {
+		return record
+	}
+	if s.cfg.ValueCompressionAllowed != nil && !s.cfg.ValueCompressionAllowed() {
+		return record
+	}
+	return compressKeyValue(s.cfg.ValueCompression, record)
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestParseValueCompression(t *testing.T) {
+	tcs := []struct {
+		name    string
+		want    ValueCompression
+		wantErr bool
+	}{
+		{name: "", want: ValueCompressionNone},
+		{name: "none", want: ValueCompressionNone},
+		{name: "deflate", want: ValueCompressionDeflate},
+		{name: "snappy", want: ValueCompressionSnappy},
+		{name: "zstd", want: ValueCompressionZstd},
+		{name: "zip", wantErr: true},
+	}
+	for _, tc := range tcs {
+		c, err := ParseValueCompression(tc.name)
+		if tc.wantErr {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.want, c, tc.name)
+	}
+}
+
+func TestCompressKeyValue(t *testing.T) {
+	kv := mvccpb.KeyValue{Key: []byte("foo"), Value: bytes.Repeat([]byte("bar"), 1000), CreateRevision: 2, ModRevision: 3, Version: 2}
+	record, err := kv.Marshal()
+	require.NoError(t, err)
+
+	small, err := (&mvccpb.KeyValue{Key: []byte("a"), Value: []byte("b")}).Marshal()
+	require.NoError(t, err)
+
+	for _, c := range []ValueCompression{ValueCompressionDeflate, ValueCompressionSnappy, ValueCompressionZstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			compressed := compressKeyValue(c, record)
+			assert.Less(t, len(compressed), len(record))
+			assert.Equal(t, []byte{encodedKeyValueMarker, byte(c)}, compressed[:2])
+
+			for _, r := range [][]byte{record, compressed} {
+				var got mvccpb.KeyValue
+				require.NoError(t, UnmarshalKeyValue(&got, r))
+				assert.Equal(t, kv, got)
+			}
+
+			// incompressible records are stored as is
+			assert.Equal(t, small, compressKeyValue(c, small))
+		})
+	}
+
+	_, err = decompressKeyValue([]byte{encodedKeyValueMarker, 42})
+	assert.Error(t, err)
+}
+
+func TestStoreValueCompression(t *testing.T) {
+	large := bytes.Repeat([]byte("value"), 1000)
+	tcs := []struct {
+		name           string
+		allowed        bool
+		wantCompressed bool
+	}{
+		{name: "allowed", allowed: true, wantCompressed: true},
+		{name: "not allowed by the cluster version", allowed: false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b, tmpPath := betesting.NewDefaultTmpBackend(t)
+			cfg := StoreConfig{
+				ValueCompression:          ValueCompressionDeflate,
+				ValueCompressionThreshold: 1024,
+				ValueCompressionAllowed:   func() bool { return tc.allowed },
+			}
+			s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+			defer cleanup(s, b, tmpPath)
+
+			ub, utmpPath := betesting.NewDefaultTmpBackend(t)
+			us := NewStore(zaptest.NewLogger(t), ub, &lease.FakeLessor{}, StoreConfig{})
+			defer cleanup(us, ub, utmpPath)
+
+			for _, st := range []*store{s, us} {
+				st.Put([]byte("large"), large, lease.NoLease)
+				st.Put([]byte("small"), []byte("value"), lease.NoLease)
+				st.DeleteRange([]byte("small"), nil)
+				st.Commit()
+			}
+
+			tx := b.ReadTx()
+			tx.RLock()
+			_, vs := tx.UnsafeRange(schema.Key, newTestRevBytes(revision{main: 2}), nil, 0)
+			encoded := schema.UnsafeReadEncodedKeyValues(tx)
+			tx.RUnlock()
+			require.Len(t, vs, 1)
+			assert.Equal(t, tc.wantCompressed, vs[0][0] == encodedKeyValueMarker)
+			assert.Equal(t, tc.wantCompressed, encoded, "the backend must record that it holds compressed records")
+
+			r, err := s.Range(context.TODO(), []byte("large"), nil, RangeOptions{})
+			require.NoError(t, err)
+			require.Len(t, r.KVs, 1)
+			assert.Equal(t, large, r.KVs[0].Value)
+
+			hash, _, err := s.HashStorage().HashByRev(0)
+			require.NoError(t, err)
+			uhash, _, err := us.HashStorage().HashByRev(0)
+			require.NoError(t, err)
+			assert.Equal(t, uhash, hash, "hash must not depend on the value compression")
+
+			for _, st := range []*store{s, us} {
+				done, err := st.Compact(traceutil.TODO(), 3)
+				require.NoError(t, err)
+				<-done
+			}
+			assert.Equal(t, us.HashStorage().Hashes(), s.HashStorage().Hashes(), "compaction hash must not depend on the value compression")
+
+			require.NoError(t, s.Restore(b))
+			r, err = s.Range(context.TODO(), []byte("large"), nil, RangeOptions{})
+			require.NoError(t, err)
+			require.Len(t, r.KVs, 1)
+			assert.Equal(t, large, r.KVs[0].Value)
+		})
+	}
+}
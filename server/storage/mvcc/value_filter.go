@@ -0,0 +1,80 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// ValueFilter matches the values of the put events sent to a watcher.
+type ValueFilter struct {
+	// Prefix matches the values starting with it.
+	Prefix []byte
+	// Regex matches the values containing a match of the regular
+	// expression.
+	Regex string
+	// JSONField is the dot separated path of a field of the JSON object
+	// values, matching the values where it is JSONValue.
+	JSONField string
+	JSONValue string
+}
+
+// NewValueFilterFunc returns the FilterFunc filtering out the put events
+// whose value does not match all the conditions set in the filter. The
+// delete events are not filtered out.
+func NewValueFilterFunc(vf ValueFilter) (FilterFunc, error) {
+	var re *regexp.Regexp
+	if vf.Regex != "" {
+		var err error
+		if re, err = regexp.Compile(vf.Regex); err != nil {
+			return nil, fmt.Errorf("invalid value filter regex %q: %w", vf.Regex, err)
+		}
+	}
+	var path []string
+	if vf.JSONField != "" {
+		path = strings.Split(vf.JSONField, ".")
+		for _, p := range path {
+			if p == "" {
+				return nil, fmt.Errorf("invalid value filter JSON field %q, empty field path element", vf.JSONField)
+			}
+		}
+	} else if vf.JSONValue != "" {
+		return nil, fmt.Errorf("invalid value filter, JSON value %q without a JSON field", vf.JSONValue)
+	}
+
+	return func(e mvccpb.Event) bool {
+		if e.Type != mvccpb.PUT {
+			return false
+		}
+		v := e.Kv.Value
+		if !bytes.HasPrefix(v, vf.Prefix) {
+			return true
+		}
+		if re != nil && !re.Match(v) {
+			return true
+		}
+		if path != nil {
+			if fv, ok := jsonField(v, path); !ok || fv != vf.JSONValue {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
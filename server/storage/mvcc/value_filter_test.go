@@ -0,0 +1,67 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestValueFilterFunc(t *testing.T) {
+	put := func(v string) mvccpb.Event {
+		return mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("k"), Value: []byte(v)}}
+	}
+	tests := []struct {
+		name     string
+		vf       ValueFilter
+		event    mvccpb.Event
+		filtered bool
+	}{
+		{name: "empty filter", event: put("v")},
+		{name: "prefix match", vf: ValueFilter{Prefix: []byte("ab")}, event: put("abc")},
+		{name: "prefix mismatch", vf: ValueFilter{Prefix: []byte("ab")}, event: put("bc"), filtered: true},
+		{name: "regex match", vf: ValueFilter{Regex: "b+c$"}, event: put("abbc")},
+		{name: "regex mismatch", vf: ValueFilter{Regex: "^b"}, event: put("abc"), filtered: true},
+		{name: "json match", vf: ValueFilter{JSONField: "spec.node", JSONValue: "n1"}, event: put(`{"spec":{"node":"n1"}}`)},
+		{name: "json number match", vf: ValueFilter{JSONField: "n", JSONValue: "1.5"}, event: put(`{"n":1.5}`)},
+		{name: "json mismatch", vf: ValueFilter{JSONField: "spec.node", JSONValue: "n1"}, event: put(`{"spec":{"node":"n2"}}`), filtered: true},
+		{name: "json missing field", vf: ValueFilter{JSONField: "spec.node", JSONValue: "n1"}, event: put(`{"spec":{}}`), filtered: true},
+		{name: "not json", vf: ValueFilter{JSONField: "spec", JSONValue: "n1"}, event: put("n1"), filtered: true},
+		{name: "all conditions", vf: ValueFilter{Prefix: []byte("{"), Regex: "node", JSONField: "node", JSONValue: "n1"}, event: put(`{"node":"n1"}`)},
+		{name: "delete", vf: ValueFilter{Prefix: []byte("ab")}, event: mvccpb.Event{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("k")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewValueFilterFunc(tt.vf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.filtered, f(tt.event))
+		})
+	}
+}
+
+func TestValueFilterFuncInvalid(t *testing.T) {
+	for _, vf := range []ValueFilter{
+		{Regex: "a("},
+		{JSONField: "spec..node", JSONValue: "n1"},
+		{JSONValue: "n1"},
+	} {
+		_, err := NewValueFilterFunc(vf)
+		assert.Error(t, err, "%+v", vf)
+	}
+}
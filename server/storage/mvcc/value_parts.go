@@ -0,0 +1,98 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// ErrValueUploadNotFound is returned for the parts and the final put of an
+// upload that does not exist, either never started for the key or discarded
+// by a compaction.
+var ErrValueUploadNotFound = errors.New("mvcc: value upload not found")
+
+func (tw *storeTxnWrite) PutValuePart(key []byte, upload int64, part []byte) (int64, error) {
+	tw.s.unsafeCreateValuePartBucket(tw.tx)
+	var u *schema.ValueUpload
+	if upload == 0 {
+		upload = schema.UnsafeNextValueUpload(tw.tx)
+		u = &schema.ValueUpload{Key: key, Revision: tw.beginRev}
+	} else {
+		var err error
+		if u, err = tw.s.unsafeReadValueUpload(tw.tx, key, upload); err != nil {
+			return 0, err
+		}
+	}
+	schema.UnsafePutValuePart(tw.tx, upload, u, part)
+	return upload, nil
+}
+
+func (tw *storeTxnWrite) TakeValueParts(key []byte, upload int64) ([]byte, error) {
+	tw.s.unsafeCreateValuePartBucket(tw.tx)
+	u, err := tw.s.unsafeReadValueUpload(tw.tx, key, upload)
+	if err != nil {
+		return nil, err
+	}
+	parts := schema.UnsafeRangeValueParts(tw.tx, upload)
+	if len(parts) != int(u.Parts) {
+		tw.s.lg.Fatal("missing staged value parts", zap.Int64("upload", upload), zap.Int("found", len(parts)), zap.Uint32("expected", u.Parts))
+	}
+	value := make([]byte, 0, u.Size)
+	for _, p := range parts {
+		value = append(value, p...)
+	}
+	schema.UnsafeDeleteValueUpload(tw.tx, upload, u)
+	return value, nil
+}
+
+// unsafeReadValueUpload returns the upload of the value of key.
+func (s *store) unsafeReadValueUpload(tx backend.ReadTx, key []byte, upload int64) (*schema.ValueUpload, error) {
+	u, err := schema.UnsafeReadValueUpload(tx, upload)
+	if err != nil {
+		s.lg.Fatal("failed to read value upload", zap.Int64("upload", upload), zap.Error(err))
+	}
+	if u == nil || !bytes.Equal(u.Key, key) {
+		return nil, ErrValueUploadNotFound
+	}
+	return u, nil
+}
+
+// unsafeCreateValuePartBucket creates the value part bucket the first time
+// a value is put in parts, the stores not putting values in parts going
+// without it.
+func (s *store) unsafeCreateValuePartBucket(tx backend.BatchTx) {
+	if !s.valuePartBucket {
+		schema.UnsafeCreateValuePartBucket(tx)
+		s.valuePartBucket = true
+	}
+}
+
+// unsafeDeleteValueUploads discards the uploads started before the
+// compacted revision, so that the abandoned ones do not stay forever.
+func (s *store) unsafeDeleteValueUploads(tx backend.BatchTx, compactRev int64) {
+	n, err := schema.UnsafeDeleteValueUploadsBefore(tx, compactRev)
+	if err != nil {
+		s.lg.Fatal("failed to discard value uploads", zap.Error(err))
+	}
+	if n > 0 {
+		s.lg.Info("discarded value uploads started before the compaction", zap.Int("uploads", n), zap.Int64("compact-revision", compactRev))
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func putValuePart(t *testing.T, s *store, key []byte, upload int64, part string) (int64, error) {
+	t.Helper()
+	txn := s.Write(traceutil.TODO())
+	defer txn.End()
+	return txn.PutValuePart(key, upload, []byte(part))
+}
+
+func TestStoreValueParts(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	key := []byte("foo")
+	upload, err := putValuePart(t, s, key, 0, "a")
+	require.NoError(t, err)
+	require.NotZero(t, upload)
+	_, err = putValuePart(t, s, key, upload, "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), s.Rev(), "staging parts must not change the revision")
+
+	// the uploads are bound to their key
+	_, err = putValuePart(t, s, []byte("bar"), upload, "c")
+	assert.ErrorIs(t, err, ErrValueUploadNotFound)
+	other, err := putValuePart(t, s, key, 0, "x")
+	require.NoError(t, err)
+	assert.NotEqual(t, upload, other)
+
+	txn := s.Write(traceutil.TODO())
+	value, err := txn.TakeValueParts(key, upload)
+	require.NoError(t, err)
+	txn.Put(key, append(value, 'c'), lease.NoLease)
+	txn.End()
+	r, err := s.Range(context.TODO(), key, nil, RangeOptions{})
+	require.NoError(t, err)
+	require.Len(t, r.KVs, 1)
+	assert.Equal(t, "abc", string(r.KVs[0].Value))
+
+	// the parts are taken once
+	txn = s.Write(traceutil.TODO())
+	_, err = txn.TakeValueParts(key, upload)
+	txn.End()
+	assert.ErrorIs(t, err, ErrValueUploadNotFound)
+
+	// the compaction discards the uploads started before its revision
+	s.Put([]byte("bar"), []byte("v"), lease.NoLease)
+	done, err := s.Compact(traceutil.TODO(), s.Rev())
+	require.NoError(t, err)
+	<-done
+	_, err = putValuePart(t, s, key, other, "y")
+	assert.ErrorIs(t, err, ErrValueUploadNotFound)
+
+	// the uploads survive the restore
+	upload, err = putValuePart(t, s, key, 0, "d")
+	require.NoError(t, err)
+	s.Commit()
+	require.NoError(t, s.Restore(b))
+	txn = s.Write(traceutil.TODO())
+	value, err = txn.TakeValueParts(key, upload)
+	txn.End()
+	require.NoError(t, err)
+	assert.Equal(t, "d", string(value))
+}
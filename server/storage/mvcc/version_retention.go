@@ -0,0 +1,100 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VersionRetention keeps the last versions of the keys with a prefix through
+// the compactions, whatever the compacted revision.
+type VersionRetention struct {
+	// Prefix is the key prefix of the retained keys, the whole key space if
+	// empty.
+	Prefix []byte
+	// Versions is the number of versions, that is of puts, kept per key.
+	Versions int
+}
+
+// ParseVersionRetention parses a version retention given as
+// "<prefix>=<versions>", or as "<versions>" for the whole key space.
+func ParseVersionRetention(s string) (VersionRetention, error) {
+	var prefix string
+	versions := s
+	if i := strings.LastIndex(s, "="); i >= 0 {
+		prefix, versions = s[:i], s[i+1:]
+	}
+	n, err := strconv.Atoi(versions)
+	if err != nil || n <= 0 {
+		return VersionRetention{}, fmt.Errorf("invalid version retention %q, expected [<prefix>=]<versions> with a positive number of versions", s)
+	}
+	return VersionRetention{Prefix: []byte(prefix), Versions: n}, nil
+}
+
+// String returns the version retention as "<prefix>=<versions>", as parsed by
+// ParseVersionRetention.
+func (r VersionRetention) String() string {
+	return fmt.Sprintf("%s=%d", r.Prefix, r.Versions)
+}
+
+// versionRetention holds the version retentions by decreasing prefix length,
+// so that the first matching one is the most specific.
+type versionRetention []VersionRetention
+
+func newVersionRetention(vrs []VersionRetention) versionRetention {
+	if len(vrs) == 0 {
+		return nil
+	}
+	vr := make(versionRetention, len(vrs))
+	copy(vr, vrs)
+	sort.SliceStable(vr, func(i, j int) bool { return len(vr[i].Prefix) > len(vr[j].Prefix) })
+	return vr
+}
+
+// versions returns the number of versions of the key kept through the
+// compactions, 0 if they are not retained.
+func (vr versionRetention) versions(key []byte) int {
+	for _, r := range vr {
+		if bytes.HasPrefix(key, r.Prefix) {
+			return r.Versions
+		}
+	}
+	return 0
+}
+
+// covers reports whether all the keys of the range [key, end) are retained,
+// in which case the range may be read below the compacted revision.
+func (vr versionRetention) covers(key, end []byte) bool {
+	for _, r := range vr {
+		if !bytes.HasPrefix(key, r.Prefix) {
+			continue
+		}
+		if len(end) == 0 || len(r.Prefix) == 0 {
+			return true
+		}
+		// "\x00" ends the range with the whole key space after the key
+		if bytes.Equal(end, []byte{0}) {
+			continue
+		}
+		if pend := prefixEnd(r.Prefix); len(pend) == 0 || bytes.Compare(end, pend) <= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestParseVersionRetention(t *testing.T) {
+	tests := []struct {
+		s    string
+		want VersionRetention
+		werr bool
+	}{
+		{s: "/audit/=10", want: VersionRetention{Prefix: []byte("/audit/"), Versions: 10}},
+		{s: "/a=b/=3", want: VersionRetention{Prefix: []byte("/a=b/"), Versions: 3}},
+		{s: "5", want: VersionRetention{Prefix: []byte(""), Versions: 5}},
+		{s: "=5", want: VersionRetention{Prefix: []byte(""), Versions: 5}},
+		{s: "/audit/=0", werr: true},
+		{s: "/audit/=", werr: true},
+		{s: "/audit/", werr: true},
+	}
+	for _, tt := range tests {
+		vr, err := ParseVersionRetention(tt.s)
+		if tt.werr {
+			assert.Error(t, err, tt.s)
+			continue
+		}
+		require.NoError(t, err, tt.s)
+		assert.Equal(t, tt.want, vr, tt.s)
+	}
+}
+
+func TestVersionRetention(t *testing.T) {
+	vr := newVersionRetention([]VersionRetention{
+		{Prefix: []byte("/audit/"), Versions: 2},
+		{Prefix: []byte("/audit/users/"), Versions: 5},
+	})
+	assert.Equal(t, 5, vr.versions([]byte("/audit/users/a")))
+	assert.Equal(t, 2, vr.versions([]byte("/audit/a")))
+	assert.Equal(t, 0, vr.versions([]byte("/other")))
+
+	assert.True(t, vr.covers([]byte("/audit/a"), nil))
+	assert.True(t, vr.covers([]byte("/audit/"), []byte("/audit0")))
+	assert.True(t, vr.covers([]byte("/audit/users/"), []byte("/audit/users0")))
+	assert.False(t, vr.covers([]byte("/audit/"), []byte("/b")))
+	assert.False(t, vr.covers([]byte("/audit/"), []byte{0}))
+	assert.False(t, vr.covers([]byte("/a"), nil))
+
+	global := newVersionRetention([]VersionRetention{{Versions: 1}})
+	assert.True(t, global.covers([]byte("a"), []byte{0}))
+}
+
+func TestStoreVersionRetention(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{
+		VersionRetention: []VersionRetention{{Prefix: []byte("/audit/"), Versions: 2}},
+	})
+	defer cleanup(s, b, tmpPath)
+
+	s.Put([]byte("/audit/a"), []byte("v1"), lease.NoLease) // rev 2
+	s.Put([]byte("/audit/a"), []byte("v2"), lease.NoLease) // rev 3
+	s.Put([]byte("/other"), []byte("x1"), lease.NoLease)   // rev 4
+	s.Put([]byte("/audit/a"), []byte("v3"), lease.NoLease) // rev 5
+	s.Put([]byte("/other"), []byte("x2"), lease.NoLease)   // rev 6
+	s.Put([]byte("/audit/b"), []byte("b1"), lease.NoLease) // rev 7
+
+	done, err := s.Compact(traceutil.TODO(), 7)
+	require.NoError(t, err)
+	<-done
+	// the puts after the compacted revision keep the retained versions readable
+	s.Put([]byte("/audit/a"), []byte("v4"), lease.NoLease) // rev 8
+	s.Put([]byte("/audit/a"), []byte("v5"), lease.NoLease) // rev 9
+
+	check := func() {
+		r, err := s.Range(context.TODO(), []byte("/audit/a"), nil, RangeOptions{Rev: 3})
+		require.NoError(t, err)
+		require.Len(t, r.KVs, 1)
+		assert.Equal(t, "v2", string(r.KVs[0].Value))
+
+		// the first version is past the retained ones
+		_, err = s.Range(context.TODO(), []byte("/audit/a"), nil, RangeOptions{Rev: 2})
+		assert.ErrorIs(t, err, ErrCompacted)
+		_, err = s.Range(context.TODO(), []byte("/audit/"), []byte("/audit0"), RangeOptions{Rev: 2})
+		assert.ErrorIs(t, err, ErrCompacted)
+		_, err = s.Range(context.TODO(), []byte("/audit/"), []byte("/audit0"), RangeOptions{Rev: 2, Count: true})
+		assert.ErrorIs(t, err, ErrCompacted)
+
+		r, err = s.Range(context.TODO(), []byte("/audit/"), []byte("/audit0"), RangeOptions{Rev: 5})
+		require.NoError(t, err)
+		require.Len(t, r.KVs, 1)
+		assert.Equal(t, "v3", string(r.KVs[0].Value))
+
+		_, err = s.Range(context.TODO(), []byte("/other"), nil, RangeOptions{Rev: 4})
+		assert.ErrorIs(t, err, ErrCompacted)
+	}
+	check()
+
+	// commit the deletes of the compaction, not reflected by the read buffer
+	s.Commit()
+	require.NoError(t, s.Restore(b))
+	check()
+}
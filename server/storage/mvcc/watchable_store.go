@@ -36,10 +36,13 @@ var (
 
 	// maxWatchersPerSync is the number of watchers to sync in a single batch
 	maxWatchersPerSync = 512
+
+	// watcherShardCount is the number of shards of the watchers of a store
+	watcherShardCount = 16
 )
 
 type watchable interface {
-	watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc)
+	watch(ranges []KeyRange, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc)
 	progress(w *watcher)
 	rev() int64
 }
@@ -47,23 +50,49 @@ type watchable interface {
 type watchableStore struct {
 	*store
 
-	// mu protects watcher groups and batches. It should never be locked
-	// before locking store.mu to avoid deadlock.
+	// mu protects victims. It should never be locked before locking
+	// store.mu to avoid deadlock.
 	mu sync.RWMutex
 
+	// notifyMu orders the notifications of the write txns by their
+	// revisions.
+	notifyMu sync.Mutex
+
 	// victims are watcher batches that were blocked on the watch channel
 	victims []watcherBatch
 	victimc chan struct{}
 
+	// shards hold the watchers by the hash of their keys.
+	shards []*watcherShard
+
+	stopc chan struct{}
+	wg    sync.WaitGroup
+}
+
+// watcherShard holds the unsynced and synced watchers whose keys hash to it,
+// so that the watchers of different shards are registered, synced and
+// notified concurrently.
+type watcherShard struct {
+	// mu protects the watcher groups of the shard and their watchers. It
+	// should never be locked after locking watchableStore.mu.
+	mu sync.RWMutex
+
 	// contains all unsynced watchers that needs to sync with events that have happened
 	unsynced watcherGroup
 
 	// contains all synced watchers that are in sync with the progress of the store.
-	// The key of the map is the key that the watcher watches on.
 	synced watcherGroup
+}
 
-	stopc chan struct{}
-	wg    sync.WaitGroup
+func newWatcherShards(n int) []*watcherShard {
+	shards := make([]*watcherShard, n)
+	for i := range shards {
+		shards[i] = &watcherShard{
+			unsynced: newWatcherGroup(),
+			synced:   newWatcherGroup(),
+		}
+	}
+	return shards
 }
 
 // cancelFunc updates unsynced and synced maps when running
@@ -79,11 +108,10 @@ func newWatchableStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg S
 		lg = zap.NewNop()
 	}
 	s := &watchableStore{
-		store:    NewStore(lg, b, le, cfg),
-		victimc:  make(chan struct{}, 1),
-		unsynced: newWatcherGroup(),
-		synced:   newWatcherGroup(),
-		stopc:    make(chan struct{}),
+		store:   NewStore(lg, b, le, cfg),
+		victimc: make(chan struct{}, 1),
+		shards:  newWatcherShards(watcherShardCount),
+		stopc:   make(chan struct{}),
 	}
 	s.store.ReadView = &readView{s}
 	s.store.WriteView = &writeView{s}
@@ -113,17 +141,19 @@ func (s *watchableStore) NewWatchStream() WatchStream {
 	}
 }
 
-func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc) {
+func (s *watchableStore) watch(ranges []KeyRange, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc) {
 	wa := &watcher{
-		key:    key,
-		end:    end,
+		key:    ranges[0].Key,
+		end:    ranges[0].End,
+		ranges: ranges[1:],
 		minRev: startRev,
 		id:     id,
 		ch:     ch,
 		fcs:    fcs,
 	}
 
-	s.mu.Lock()
+	sh := s.shard(wa.key)
+	sh.mu.Lock()
 	s.revMu.RLock()
 	synced := startRev > s.store.currentRev || startRev == 0
 	if synced {
@@ -131,28 +161,30 @@ func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch c
 		if startRev > wa.minRev {
 			wa.minRev = startRev
 		}
-		s.synced.add(wa)
+		sh.synced.add(wa)
 	} else {
 		slowWatcherGauge.Inc()
-		s.unsynced.add(wa)
+		sh.unsynced.add(wa)
 	}
 	s.revMu.RUnlock()
-	s.mu.Unlock()
+	sh.mu.Unlock()
 
 	watcherGauge.Inc()
+	s.store.prefixStats.watch(wa.key, 1)
 
 	return wa, func() { s.cancelWatcher(wa) }
 }
 
 // cancelWatcher removes references of the watcher from the watchableStore
 func (s *watchableStore) cancelWatcher(wa *watcher) {
+	sh := s.shard(wa.key)
 	for {
-		s.mu.Lock()
-		if s.unsynced.delete(wa) {
+		sh.mu.Lock()
+		if sh.unsynced.delete(wa) {
 			slowWatcherGauge.Dec()
 			watcherGauge.Dec()
 			break
-		} else if s.synced.delete(wa) {
+		} else if sh.synced.delete(wa) {
 			watcherGauge.Dec()
 			break
 		} else if wa.compacted {
@@ -164,46 +196,84 @@ func (s *watchableStore) cancelWatcher(wa *watcher) {
 		}
 
 		if !wa.victim {
-			s.mu.Unlock()
+			sh.mu.Unlock()
 			panic("watcher not victim but not in watch groups")
 		}
 
-		var victimBatch watcherBatch
-		for _, wb := range s.victims {
-			if wb[wa] != nil {
-				victimBatch = wb
-				break
-			}
-		}
-		if victimBatch != nil {
+		if s.deleteVictim(wa) {
 			slowWatcherGauge.Dec()
 			watcherGauge.Dec()
-			delete(victimBatch, wa)
 			break
 		}
 
 		// victim being processed so not accessible; retry
-		s.mu.Unlock()
+		sh.mu.Unlock()
 		time.Sleep(time.Millisecond)
 	}
 
+	if wa.ch != nil {
+		s.store.prefixStats.watch(wa.key, -1)
+	}
 	wa.ch = nil
-	s.mu.Unlock()
+	sh.mu.Unlock()
 }
 
-func (s *watchableStore) Restore(b backend.Backend) error {
+// deleteVictim removes the watcher from the victim batches, returning false
+// if it is in none of them.
+func (s *watchableStore) deleteVictim(wa *watcher) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	for _, wb := range s.victims {
+		if wb[wa] != nil {
+			delete(wb, wa)
+			return true
+		}
+	}
+	return false
+}
+
+// shard returns the shard of the watchers of the key.
+func (s *watchableStore) shard(key []byte) *watcherShard {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	// FNV-1a
+	h := uint32(2166136261)
+	for _, c := range key {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	return s.shards[h%uint32(len(s.shards))]
+}
+
+// unsyncedSize returns the number of the unsynced watchers of all the shards.
+func (s *watchableStore) unsyncedSize() int {
+	n := 0
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		n += sh.unsynced.size()
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+func (s *watchableStore) Restore(b backend.Backend) error {
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		defer sh.mu.Unlock()
+	}
 	err := s.store.Restore(b)
 	if err != nil {
 		return err
 	}
 
-	for wa := range s.synced.watchers {
-		wa.restore = true
-		s.unsynced.add(wa)
+	for _, sh := range s.shards {
+		for wa := range sh.synced.watchers {
+			wa.restore = true
+			sh.unsynced.add(wa)
+		}
+		sh.synced = newWatcherGroup()
 	}
-	s.synced = newWatcherGroup()
 	return nil
 }
 
@@ -216,16 +286,15 @@ func (s *watchableStore) syncWatchersLoop() {
 	defer delayTicker.Stop()
 
 	for {
-		s.mu.RLock()
 		st := time.Now()
-		lastUnsyncedWatchers := s.unsynced.size()
-		s.mu.RUnlock()
+		lastUnsyncedWatchers := s.unsyncedSize()
 
 		unsyncedWatchers := 0
 		if lastUnsyncedWatchers > 0 {
 			unsyncedWatchers = s.syncWatchers()
 		}
 		syncDuration := time.Since(st)
+		s.reportWatchLag()
 
 		delayTicker.Reset(waitDuration)
 		// more work pending?
@@ -295,27 +364,34 @@ func (s *watchableStore) moveVictims() (moved int) {
 		}
 
 		// assign completed victim watchers to unsync/sync
-		s.mu.Lock()
-		s.store.revMu.RLock()
-		curRev := s.store.currentRev
-		for w, eb := range wb {
+		byShard := make(map[*watcherShard][]*watcher)
+		for w := range wb {
 			if newVictim != nil && newVictim[w] != nil {
 				// couldn't send watch response; stays victim
 				continue
 			}
-			w.victim = false
-			if eb.moreRev != 0 {
-				w.minRev = eb.moreRev
-			}
-			if w.minRev <= curRev {
-				s.unsynced.add(w)
-			} else {
-				slowWatcherGauge.Dec()
-				s.synced.add(w)
+			sh := s.shard(w.key)
+			byShard[sh] = append(byShard[sh], w)
+		}
+		for sh, ws := range byShard {
+			sh.mu.Lock()
+			s.store.revMu.RLock()
+			curRev := s.store.currentRev
+			for _, w := range ws {
+				w.victim = false
+				if eb := wb[w]; eb.moreRev != 0 {
+					w.minRev = eb.moreRev
+				}
+				if w.minRev <= curRev {
+					sh.unsynced.add(w)
+				} else {
+					slowWatcherGauge.Dec()
+					sh.synced.add(w)
+				}
 			}
+			s.store.revMu.RUnlock()
+			sh.mu.Unlock()
 		}
-		s.store.revMu.RUnlock()
-		s.mu.Unlock()
 	}
 
 	if len(newVictim) > 0 {
@@ -327,16 +403,35 @@ func (s *watchableStore) moveVictims() (moved int) {
 	return moved
 }
 
-// syncWatchers syncs unsynced watchers by:
+// syncWatchers syncs the unsynced watchers of every shard, returning the
+// number of them left unsynced.
+func (s *watchableStore) syncWatchers() int {
+	unsynced := 0
+	for _, sh := range s.shards {
+		unsynced += s.syncShard(sh)
+	}
+
+	s.mu.RLock()
+	vsz := 0
+	for _, v := range s.victims {
+		vsz += len(v)
+	}
+	s.mu.RUnlock()
+	slowWatcherGauge.Set(float64(unsynced + vsz))
+
+	return unsynced
+}
+
+// syncShard syncs unsynced watchers of the shard by:
 //  1. choose a set of watchers from the unsynced watcher group
 //  2. iterate over the set to get the minimum revision and remove compacted watchers
 //  3. use minimum revision to get all key-value pairs and send those events to watchers
 //  4. remove synced watchers in set from unsynced group and move to synced group
-func (s *watchableStore) syncWatchers() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *watchableStore) syncShard(sh *watcherShard) int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
 
-	if s.unsynced.size() == 0 {
+	if sh.unsynced.size() == 0 {
 		return 0
 	}
 
@@ -349,7 +444,7 @@ func (s *watchableStore) syncWatchers() int {
 	curRev := s.store.currentRev
 	compactionRev := s.store.compactMainRev
 
-	wg, minRev := s.unsynced.choose(maxWatchersPerSync, curRev, compactionRev)
+	wg, minRev := sh.unsynced.choose(maxWatchersPerSync, curRev, compactionRev)
 	minBytes, maxBytes := newRevBytes(), newRevBytes()
 	revToBytes(revision{main: minRev}, minBytes)
 	revToBytes(revision{main: curRev + 1}, maxBytes)
@@ -359,7 +454,7 @@ func (s *watchableStore) syncWatchers() int {
 	tx := s.store.b.ReadTx()
 	tx.RLock()
 	revs, vs := tx.UnsafeRange(schema.Key, minBytes, maxBytes, 0)
-	evs := kvsToEvents(s.store.lg, wg, revs, vs)
+	evs := kvsToEvents(s.store.lg, tx, wg, revs, vs)
 	// Must unlock after kvsToEvents, because vs (come from boltdb memory) is not deep copy.
 	// We can only unlock after Unmarshal, which will do deep copy.
 	// Otherwise we will trigger SIGSEGV during boltdb re-mmap.
@@ -373,8 +468,8 @@ func (s *watchableStore) syncWatchers() int {
 		eb, ok := wb[w]
 		if !ok {
 			// bring un-notified watcher to synced
-			s.synced.add(w)
-			s.unsynced.delete(w)
+			sh.synced.add(w)
+			sh.unsynced.delete(w)
 			continue
 		}
 
@@ -395,26 +490,20 @@ func (s *watchableStore) syncWatchers() int {
 				// stay unsynced; more to read
 				continue
 			}
-			s.synced.add(w)
+			sh.synced.add(w)
 		}
-		s.unsynced.delete(w)
+		sh.unsynced.delete(w)
 	}
 	s.addVictim(victims)
 
-	vsz := 0
-	for _, v := range s.victims {
-		vsz += len(v)
-	}
-	slowWatcherGauge.Set(float64(s.unsynced.size() + vsz))
-
-	return s.unsynced.size()
+	return sh.unsynced.size()
 }
 
 // kvsToEvents gets all events for the watchers from all key-value pairs
-func kvsToEvents(lg *zap.Logger, wg *watcherGroup, revs, vals [][]byte) (evs []mvccpb.Event) {
+func kvsToEvents(lg *zap.Logger, tx backend.ReadTx, wg *watcherGroup, revs, vals [][]byte) (evs []mvccpb.Event) {
 	for i, v := range vals {
 		var kv mvccpb.KeyValue
-		if err := kv.Unmarshal(v); err != nil {
+		if err := unsafeUnmarshalKeyValue(tx, &kv, revs[i], v); err != nil {
 			lg.Panic("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
 		}
 
@@ -434,16 +523,54 @@ func kvsToEvents(lg *zap.Logger, wg *watcherGroup, revs, vals [][]byte) (evs []m
 }
 
 // notify notifies the fact that given event at the given rev just happened to
-// watchers that watch on the key of the event.
+// watchers that watch on the key of the event. The shards with synced
+// watchers are notified concurrently.
 func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
+	var busy []int
+	for i, sh := range s.shards {
+		sh.mu.RLock()
+		if sh.synced.size() != 0 {
+			busy = append(busy, i)
+		}
+		sh.mu.RUnlock()
+	}
+	if len(busy) == 1 {
+		s.addVictim(s.notifyShard(s.shards[busy[0]], rev, evs))
+		return
+	}
+
+	victims := make([]watcherBatch, len(busy))
+	var wg sync.WaitGroup
+	wg.Add(len(busy))
+	for j, i := range busy {
+		go func(j int, sh *watcherShard) {
+			defer wg.Done()
+			victims[j] = s.notifyShard(sh, rev, evs)
+		}(j, s.shards[i])
+	}
+	wg.Wait()
+	for _, victim := range victims {
+		s.addVictim(victim)
+	}
+}
+
+// notifyShard notifies the synced watchers of the shard of the events,
+// returning the slow ones moved out of the synced group.
+func (s *watchableStore) notifyShard(sh *watcherShard, rev int64, evs []mvccpb.Event) watcherBatch {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
 	victim := make(watcherBatch)
-	for w, eb := range newWatcherBatch(&s.synced, evs) {
+	for w, eb := range newWatcherBatch(&sh.synced, evs) {
 		if eb.revs != 1 {
 			s.store.lg.Panic(
 				"unexpected multiple revisions in watch notification",
 				zap.Int("number-of-revisions", eb.revs),
 			)
 		}
+		for _, ev := range eb.evs {
+			s.store.hotKeys.recordWatchEvent(ev.Kv.Key)
+		}
 		if w.send(WatchResponse{WatchID: w.id, Events: eb.evs, Revision: rev}) {
 			pendingEventsGauge.Add(float64(len(eb.evs)))
 		} else {
@@ -451,18 +578,20 @@ func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
 			w.minRev = rev + 1
 			w.victim = true
 			victim[w] = eb
-			s.synced.delete(w)
+			sh.synced.delete(w)
 			slowWatcherGauge.Inc()
 		}
 	}
-	s.addVictim(victim)
+	return victim
 }
 
 func (s *watchableStore) addVictim(victim watcherBatch) {
 	if len(victim) == 0 {
 		return
 	}
+	s.mu.Lock()
 	s.victims = append(s.victims, victim)
+	s.mu.Unlock()
 	select {
 	case s.victimc <- struct{}{}:
 	default:
@@ -472,10 +601,15 @@ func (s *watchableStore) addVictim(victim watcherBatch) {
 func (s *watchableStore) rev() int64 { return s.store.Rev() }
 
 func (s *watchableStore) progress(w *watcher) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if _, ok := s.synced.watchers[w]; ok {
+	// the revision of the store must not run ahead of the notifications of
+	// the synced watchers
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	sh := s.shard(w.key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if _, ok := sh.synced.watchers[w]; ok {
 		w.send(WatchResponse{WatchID: w.id, Revision: s.rev()})
 		// If the ch is full, this watcher is receiving events.
 		// We do not need to send progress at all.
@@ -488,6 +622,9 @@ type watcher struct {
 	// end indicates the end of the range to watch.
 	// If end is set, the watcher is on a range.
 	end []byte
+	// ranges are the other ranges the watcher watches, disjoint from
+	// the range of key and from each other.
+	ranges []KeyRange
 
 	// victim is set when ch is blocked and undergoing victim processing
 	victim bool
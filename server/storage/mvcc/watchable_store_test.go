@@ -48,7 +48,7 @@ func TestWatch(t *testing.T) {
 	w := s.NewWatchStream()
 	w.Watch(0, testKey, nil, 0)
 
-	if !s.synced.contains(string(testKey)) {
+	if !s.shard(testKey).synced.contains(string(testKey)) {
 		// the key must have had an entry in synced
 		t.Errorf("existence = false, want true")
 	}
@@ -73,7 +73,7 @@ func TestNewWatcherCancel(t *testing.T) {
 		t.Error(err)
 	}
 
-	if s.synced.contains(string(testKey)) {
+	if s.shard(testKey).synced.contains(string(testKey)) {
 		// the key shoud have been deleted
 		t.Errorf("existence = true, want false")
 	}
@@ -88,12 +88,8 @@ func TestCancelUnsynced(t *testing.T) {
 	// method to sync watchers in unsynced map. We want to keep watchers
 	// in unsynced to test if syncWatchers works as expected.
 	s := &watchableStore{
-		store:    NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
-		unsynced: newWatcherGroup(),
-
-		// to make the test not crash from assigning to nil map.
-		// 'synced' doesn't get populated in this test.
-		synced: newWatcherGroup(),
+		store:  NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
+		shards: newWatcherShards(watcherShardCount),
 	}
 
 	defer func() {
@@ -131,7 +127,7 @@ func TestCancelUnsynced(t *testing.T) {
 	//
 	// unsynced should be empty
 	// because cancel removes watcher from unsynced
-	if size := s.unsynced.size(); size != 0 {
+	if size := s.unsyncedSize(); size != 0 {
 		t.Errorf("unsynced size = %d, want 0", size)
 	}
 }
@@ -143,9 +139,8 @@ func TestSyncWatchers(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
 
 	s := &watchableStore{
-		store:    NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
-		unsynced: newWatcherGroup(),
-		synced:   newWatcherGroup(),
+		store:  NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
+		shards: newWatcherShards(watcherShardCount),
 	}
 
 	defer func() {
@@ -169,8 +164,8 @@ func TestSyncWatchers(t *testing.T) {
 
 	// Before running s.syncWatchers() synced should be empty because we manually
 	// populate unsynced only
-	sws := s.synced.watcherSetByKey(string(testKey))
-	uws := s.unsynced.watcherSetByKey(string(testKey))
+	sws := s.shard(testKey).synced.watcherSetByKey(string(testKey))
+	uws := s.shard(testKey).unsynced.watcherSetByKey(string(testKey))
 
 	if len(sws) != 0 {
 		t.Fatalf("synced[string(testKey)] size = %d, want 0", len(sws))
@@ -183,8 +178,8 @@ func TestSyncWatchers(t *testing.T) {
 	// this should move all unsynced watchers to synced ones
 	s.syncWatchers()
 
-	sws = s.synced.watcherSetByKey(string(testKey))
-	uws = s.unsynced.watcherSetByKey(string(testKey))
+	sws = s.shard(testKey).synced.watcherSetByKey(string(testKey))
+	uws = s.shard(testKey).unsynced.watcherSetByKey(string(testKey))
 
 	// After running s.syncWatchers(), synced should not be empty because syncwatchers
 	// populates synced in this test case
@@ -256,6 +251,12 @@ func TestWatchCompacted(t *testing.T) {
 		if resp.CompactRevision == 0 {
 			t.Errorf("resp.Compacted = %v, want %v", resp.CompactRevision, compactRev)
 		}
+		if resp.LostRevisions != 1 {
+			t.Errorf("resp.LostRevisions = %v, want 1", resp.LostRevisions)
+		}
+		if resp.Revision != int64(maxRev)+1 {
+			t.Errorf("resp.Revision = %v, want %v", resp.Revision, maxRev+1)
+		}
 	case <-time.After(1 * time.Second):
 		t.Fatalf("failed to receive response (timeout)")
 	}
@@ -428,7 +429,7 @@ func TestWatchBatchUnsynced(t *testing.T) {
 
 	s.store.revMu.Lock()
 	defer s.store.revMu.Unlock()
-	if size := s.synced.size(); size != 1 {
+	if size := s.shard(v).synced.size(); size != 1 {
 		t.Errorf("synced size = %d, want 1", size)
 	}
 }
@@ -611,6 +612,75 @@ func TestWatchVictims(t *testing.T) {
 	}
 }
 
+// TestWatchShards tests that the watchers of keys of different shards,
+// registered concurrently, are notified of the events on their keys in
+// order, along with a range watcher over all of them.
+func TestWatchShards(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	defer func() {
+		b.Close()
+		s.Close()
+		os.Remove(tmpPath)
+	}()
+
+	numKeys := watcherShardCount * 8
+	keys := make([][]byte, numKeys)
+	used := make(map[*watcherShard]struct{})
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("foo%03d", i))
+		used[s.shard(keys[i])] = struct{}{}
+	}
+	if len(used) < 2 {
+		t.Fatalf("keys hashed to %d shard(s), want more", len(used))
+	}
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	var wg sync.WaitGroup
+	ids := make([]WatchID, numKeys)
+	wg.Add(numKeys)
+	for i := range keys {
+		go func(i int) {
+			defer wg.Done()
+			ids[i], _ = w.Watch(0, keys[i], nil, 0)
+		}(i)
+	}
+	wg.Wait()
+	rangeID, _ := w.Watch(0, []byte("foo"), []byte("fop"), 0)
+
+	for i := range keys {
+		s.Put(keys[i], []byte("bar"), lease.NoLease)
+	}
+
+	got := make(map[WatchID][]string)
+	for n := 0; n < 2*numKeys; {
+		select {
+		case wr := <-w.Chan():
+			for _, ev := range wr.Events {
+				got[wr.WatchID] = append(got[wr.WatchID], string(ev.Kv.Key))
+				n++
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the events")
+		}
+	}
+	for i, id := range ids {
+		if want := []string{string(keys[i])}; !reflect.DeepEqual(got[id], want) {
+			t.Errorf("watcher of %q got %v, want %v", keys[i], got[id], want)
+		}
+	}
+	if len(got[rangeID]) != numKeys {
+		t.Fatalf("range watcher got %d events, want %d", len(got[rangeID]), numKeys)
+	}
+	for i := range keys {
+		if got[rangeID][i] != string(keys[i]) {
+			t.Errorf("range watcher event #%d = %q, want %q", i, got[rangeID][i], keys[i])
+		}
+	}
+}
+
 // TestStressWatchCancelClose tests closing a watch stream while
 // canceling its watches.
 func TestStressWatchCancelClose(t *testing.T) {
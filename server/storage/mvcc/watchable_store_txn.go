@@ -38,12 +38,13 @@ func (tw *watchableStoreTxnWrite) End() {
 		}
 	}
 
-	// end write txn under watchable store lock so the updates are visible
-	// when asynchronous event posting checks the current store revision
-	tw.s.mu.Lock()
-	tw.s.notify(rev, evs)
+	// notify once the write txn ends, in the order of the revisions, so that
+	// the watchers entering the synced groups from now on find the updates
+	// in the backend instead
+	tw.s.notifyMu.Lock()
 	tw.TxnWrite.End()
-	tw.s.mu.Unlock()
+	tw.s.notify(rev, evs)
+	tw.s.notifyMu.Unlock()
 }
 
 type watchableStoreTxnWrite struct {
@@ -17,6 +17,7 @@ package mvcc
 import (
 	"bytes"
 	"errors"
+	"sort"
 	"sync"
 
 	"go.etcd.io/etcd/api/v3/mvccpb"
@@ -27,10 +28,19 @@ var (
 	ErrWatcherNotExist    = errors.New("mvcc: watcher does not exist")
 	ErrEmptyWatcherRange  = errors.New("mvcc: watcher range is empty")
 	ErrWatcherDuplicateID = errors.New("mvcc: duplicate watch ID provided on the WatchStream")
+	// ErrWatcherOverlappingRanges is returned when the ranges of a watcher overlap.
+	ErrWatcherOverlappingRanges = errors.New("mvcc: watcher ranges overlap")
 )
 
 type WatchID int64
 
+// KeyRange is the range of keys [Key, End). A nil End is the single key Key,
+// and an empty End all the keys greater than or equal to Key.
+type KeyRange struct {
+	Key []byte
+	End []byte
+}
+
 // FilterFunc returns true if the given event should be filtered out.
 type FilterFunc func(e mvccpb.Event) bool
 
@@ -47,6 +57,10 @@ type WatchStream interface {
 	// an auto-generated watch ID is returned.
 	Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error)
 
+	// WatchRanges creates a watcher like Watch, watching the events
+	// happening or happened on any of the given disjoint key ranges.
+	WatchRanges(id WatchID, ranges []KeyRange, startRev int64, fcs ...FilterFunc) (WatchID, error)
+
 	// Chan returns a chan. All watch response will be sent to the returned chan.
 	Chan() <-chan WatchResponse
 
@@ -85,6 +99,10 @@ type WatchResponse struct {
 
 	// CompactRevision is set when the watcher is cancelled due to compaction.
 	CompactRevision int64
+
+	// LostRevisions is the number of revisions from the revision the watcher
+	// was at up to CompactRevision whose events were compacted.
+	LostRevisions int64
 }
 
 // watchStream contains a collection of watchers that share
@@ -103,10 +121,13 @@ type watchStream struct {
 
 // Watch creates a new watcher in the stream and returns its WatchID.
 func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error) {
-	// prevent wrong range where key >= end lexicographically
-	// watch request with 'WithFromKey' has empty-byte range end
-	if len(end) != 0 && bytes.Compare(key, end) != -1 {
-		return -1, ErrEmptyWatcherRange
+	return ws.WatchRanges(id, []KeyRange{{Key: key, End: end}}, startRev, fcs...)
+}
+
+// WatchRanges creates a new watcher of the ranges in the stream and returns its WatchID.
+func (ws *watchStream) WatchRanges(id WatchID, ranges []KeyRange, startRev int64, fcs ...FilterFunc) (WatchID, error) {
+	if err := ValidateWatchRanges(ranges); err != nil {
+		return -1, err
 	}
 
 	ws.mu.Lock()
@@ -125,13 +146,46 @@ func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ..
 		return -1, ErrWatcherDuplicateID
 	}
 
-	w, c := ws.watchable.watch(key, end, startRev, id, ws.ch, fcs...)
+	w, c := ws.watchable.watch(ranges, startRev, id, ws.ch, fcs...)
 
 	ws.cancels[id] = c
 	ws.watchers[id] = w
 	return id, nil
 }
 
+// ValidateWatchRanges returns an error if a range of a watcher is empty or if
+// its ranges overlap.
+func ValidateWatchRanges(ranges []KeyRange) error {
+	if len(ranges) == 0 {
+		return ErrEmptyWatcherRange
+	}
+	for _, r := range ranges {
+		// prevent wrong range where key >= end lexicographically
+		// watch request with 'WithFromKey' has empty-byte range end
+		if len(r.End) != 0 && bytes.Compare(r.Key, r.End) != -1 {
+			return ErrEmptyWatcherRange
+		}
+	}
+	if len(ranges) == 1 {
+		return nil
+	}
+	sorted := make([]KeyRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i].Key, sorted[j].Key) < 0 })
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		switch {
+		case prev.End == nil:
+			if bytes.Equal(prev.Key, cur.Key) {
+				return ErrWatcherOverlappingRanges
+			}
+		case len(prev.End) == 0 || bytes.Compare(cur.Key, prev.End) < 0:
+			return ErrWatcherOverlappingRanges
+		}
+	}
+	return nil
+}
+
 func (ws *watchStream) Chan() <-chan WatchResponse {
 	return ws.ch
 }
@@ -119,17 +119,17 @@ func (w watcherSet) delete(wa *watcher) {
 
 type watcherSetByKey map[string]watcherSet
 
-func (w watcherSetByKey) add(wa *watcher) {
-	set := w[string(wa.key)]
+func (w watcherSetByKey) add(key []byte, wa *watcher) {
+	set := w[string(key)]
 	if set == nil {
 		set = make(watcherSet)
-		w[string(wa.key)] = set
+		w[string(key)] = set
 	}
 	set.add(wa)
 }
 
-func (w watcherSetByKey) delete(wa *watcher) bool {
-	k := string(wa.key)
+func (w watcherSetByKey) delete(key []byte, wa *watcher) bool {
+	k := string(key)
 	if v, ok := w[k]; ok {
 		if _, ok := v[wa]; ok {
 			delete(v, wa)
@@ -164,13 +164,20 @@ func newWatcherGroup() watcherGroup {
 // add puts a watcher in the group.
 func (wg *watcherGroup) add(wa *watcher) {
 	wg.watchers.add(wa)
-	if wa.end == nil {
-		wg.keyWatchers.add(wa)
+	wg.addRange(wa.key, wa.end, wa)
+	for _, r := range wa.ranges {
+		wg.addRange(r.Key, r.End, wa)
+	}
+}
+
+func (wg *watcherGroup) addRange(key, end []byte, wa *watcher) {
+	if end == nil {
+		wg.keyWatchers.add(key, wa)
 		return
 	}
 
 	// interval already registered?
-	ivl := adt.NewStringAffineInterval(string(wa.key), string(wa.end))
+	ivl := adt.NewStringAffineInterval(string(key), string(end))
 	if iv := wg.ranges.Find(ivl); iv != nil {
 		iv.Val.(watcherSet).add(wa)
 		return
@@ -197,12 +204,20 @@ func (wg *watcherGroup) delete(wa *watcher) bool {
 		return false
 	}
 	wg.watchers.delete(wa)
-	if wa.end == nil {
-		wg.keyWatchers.delete(wa)
+	ok := wg.deleteRange(wa.key, wa.end, wa)
+	for _, r := range wa.ranges {
+		ok = wg.deleteRange(r.Key, r.End, wa) && ok
+	}
+	return ok
+}
+
+func (wg *watcherGroup) deleteRange(key, end []byte, wa *watcher) bool {
+	if end == nil {
+		wg.keyWatchers.delete(key, wa)
 		return true
 	}
 
-	ivl := adt.NewStringAffineInterval(string(wa.key), string(wa.end))
+	ivl := adt.NewStringAffineInterval(string(key), string(end))
 	iv := wg.ranges.Find(ivl)
 	if iv == nil {
 		return false
@@ -252,7 +267,7 @@ func (wg *watcherGroup) chooseAll(curRev, compactRev int64) int64 {
 		}
 		if w.minRev < compactRev {
 			select {
-			case w.ch <- WatchResponse{WatchID: w.id, CompactRevision: compactRev}:
+			case w.ch <- WatchResponse{WatchID: w.id, Revision: curRev, CompactRevision: compactRev, LostRevisions: compactRev - w.minRev}:
 				w.compacted = true
 				wg.delete(w)
 			default:
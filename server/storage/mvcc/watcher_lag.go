@@ -0,0 +1,125 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import "sort"
+
+// WatcherLag is the lag of a watcher behind the current revision.
+type WatcherLag struct {
+	ID  WatchID
+	Key []byte
+	End []byte
+	// PendingEvents is the number of events held in memory for the watcher
+	// while its watch channel is full. The events of the watchers still to
+	// be synced are read from the backend, they are not held in memory.
+	PendingEvents int
+	// OldestRevision is the revision of the oldest event not sent to the
+	// watcher yet.
+	OldestRevision int64
+	// Lag is the number of revisions the watcher is behind the current
+	// revision.
+	Lag int64
+}
+
+// WatchLag is the lag of the watchers behind the current revision.
+type WatchLag struct {
+	// Watchers are the lagging watchers, slowest first.
+	Watchers []WatcherLag
+	// SlowWatchers is the number of lagging watchers.
+	SlowWatchers int
+	// PendingEvents is the number of events held in memory for all the
+	// lagging watchers.
+	PendingEvents int
+}
+
+// WatchLag returns the lag of the unsynced watchers and of the watchers
+// blocked on their full watch channel, with up to limit of the slowest of
+// them, all of them if limit is not positive.
+func (s *watchableStore) WatchLag(limit int) WatchLag {
+	var wl WatchLag
+	s.laggingWatchers(func(l WatcherLag) {
+		wl.Watchers = append(wl.Watchers, l)
+		wl.PendingEvents += l.PendingEvents
+	})
+	wl.SlowWatchers = len(wl.Watchers)
+	sort.Slice(wl.Watchers, func(i, j int) bool {
+		if wl.Watchers[i].Lag != wl.Watchers[j].Lag {
+			return wl.Watchers[i].Lag > wl.Watchers[j].Lag
+		}
+		return wl.Watchers[i].PendingEvents > wl.Watchers[j].PendingEvents
+	})
+	if limit > 0 && len(wl.Watchers) > limit {
+		wl.Watchers = wl.Watchers[:limit]
+	}
+	return wl
+}
+
+// laggingWatchers calls f with the lag of every unsynced watcher and every
+// victim. The victims being retried at the time are missed.
+func (s *watchableStore) laggingWatchers(f func(WatcherLag)) {
+	s.store.revMu.RLock()
+	curRev := s.store.currentRev
+	s.store.revMu.RUnlock()
+
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		for w := range sh.unsynced.watchers {
+			if w.minRev > curRev {
+				// restored from a snapshot with a future revision
+				continue
+			}
+			f(newWatcherLag(w, w.minRev, 0, curRev))
+		}
+		sh.mu.RUnlock()
+	}
+
+	s.mu.RLock()
+	for _, wb := range s.victims {
+		for w, eb := range wb {
+			oldest := w.minRev
+			if len(eb.evs) > 0 {
+				oldest = eb.evs[0].Kv.ModRevision
+			}
+			f(newWatcherLag(w, oldest, len(eb.evs), curRev))
+		}
+	}
+	s.mu.RUnlock()
+}
+
+func newWatcherLag(w *watcher, oldest int64, pending int, curRev int64) WatcherLag {
+	return WatcherLag{
+		ID:             w.id,
+		Key:            w.key,
+		End:            w.end,
+		PendingEvents:  pending,
+		OldestRevision: oldest,
+		Lag:            curRev - oldest + 1,
+	}
+}
+
+// reportWatchLag reports the lag of the slowest watcher and the events held
+// in memory for the lagging watchers.
+func (s *watchableStore) reportWatchLag() {
+	var maxLag int64
+	pending := 0
+	s.laggingWatchers(func(l WatcherLag) {
+		if l.Lag > maxLag {
+			maxLag = l.Lag
+		}
+		pending += l.PendingEvents
+	})
+	watchMaxLagGauge.Set(float64(maxLag))
+	watchPendingEventsGauge.Set(float64(pending))
+}
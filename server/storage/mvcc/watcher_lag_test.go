@@ -0,0 +1,70 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestWatchLag(t *testing.T) {
+	oldChanBufLen := chanBufLen
+	defer func() { chanBufLen = oldChanBufLen }()
+	chanBufLen = 1
+
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	// no sync loops, the watchers stay unsynced or victims
+	s := &watchableStore{
+		store:   NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
+		victimc: make(chan struct{}, 1),
+		shards:  newWatcherShards(watcherShardCount),
+	}
+	s.store.ReadView = &readView{s}
+	s.store.WriteView = &writeView{s}
+	defer s.store.Close()
+
+	for i := 0; i < 3; i++ {
+		s.Put([]byte("foo"), []byte("v"), lease.NoLease)
+	}
+	unsynced, err := s.NewWatchStream().Watch(0, []byte("foo"), nil, 2)
+	require.NoError(t, err)
+	victim, err := s.NewWatchStream().Watch(0, []byte("bar"), []byte("baz"), 0)
+	require.NoError(t, err)
+	// the first event fills the watch channel, the second one is held
+	s.Put([]byte("bar"), []byte("v"), lease.NoLease)
+	s.Put([]byte("bar"), []byte("v"), lease.NoLease)
+
+	wl := s.WatchLag(0)
+	assert.Equal(t, WatchLag{
+		Watchers: []WatcherLag{
+			{ID: unsynced, Key: []byte("foo"), OldestRevision: 2, Lag: 5},
+			{ID: victim, Key: []byte("bar"), End: []byte("baz"), PendingEvents: 1, OldestRevision: 6, Lag: 1},
+		},
+		SlowWatchers:  2,
+		PendingEvents: 1,
+	}, wl)
+
+	wl = s.WatchLag(1)
+	assert.Len(t, wl.Watchers, 1)
+	assert.Equal(t, unsynced, wl.Watchers[0].ID)
+	assert.Equal(t, 2, wl.SlowWatchers)
+	assert.Equal(t, 1, wl.PendingEvents)
+}
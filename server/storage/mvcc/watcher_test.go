@@ -212,6 +212,84 @@ func TestWatcherWatchWrongRange(t *testing.T) {
 	}
 }
 
+// TestWatcherWatchRanges tests a watcher receives the events of all its
+// ranges, synced or not, and only them.
+func TestWatcherWatchRanges(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	w := s.NewWatchStream()
+	defer w.Close()
+
+	overlapping := [][]KeyRange{
+		{{Key: []byte("a")}, {Key: []byte("a")}},
+		{{Key: []byte("a"), End: []byte("c")}, {Key: []byte("b")}},
+		{{Key: []byte("b"), End: []byte("d")}, {Key: []byte("a"), End: []byte("c")}},
+		{{Key: []byte("a"), End: []byte{}}, {Key: []byte("z")}},
+	}
+	for i, ranges := range overlapping {
+		if _, err := w.WatchRanges(0, ranges, 0); err != ErrWatcherOverlappingRanges {
+			t.Errorf("#%d: expected ErrWatcherOverlappingRanges, got %v", i, err)
+		}
+	}
+	if _, err := w.WatchRanges(0, nil, 0); err != ErrEmptyWatcherRange {
+		t.Errorf("expected ErrEmptyWatcherRange, got %v", err)
+	}
+
+	ranges := []KeyRange{
+		{Key: []byte("foo"), End: []byte("fop")},
+		{Key: []byte("bar")},
+		{Key: []byte("zoo"), End: []byte{}},
+	}
+	for _, k := range []string{"foo1", "bar", "baz", "zoo1"} {
+		s.Put([]byte(k), []byte("v"), lease.NoLease)
+	}
+
+	// unsynced watcher
+	id, err := w.WatchRanges(0, ranges, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for len(keys) < 3 {
+		resp := <-w.Chan()
+		for _, ev := range resp.Events {
+			keys = append(keys, string(ev.Kv.Key))
+		}
+	}
+	if want := []string{"foo1", "bar", "zoo1"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("unsynced events = %v, want %v", keys, want)
+	}
+
+	// synced watcher
+	for _, k := range []string{"baz", "foo2", "bar", "fop", "zoo2"} {
+		s.Put([]byte(k), []byte("v"), lease.NoLease)
+	}
+	keys = nil
+	for len(keys) < 3 {
+		resp := <-w.Chan()
+		for _, ev := range resp.Events {
+			keys = append(keys, string(ev.Kv.Key))
+		}
+	}
+	if want := []string{"foo2", "bar", "zoo2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("synced events = %v, want %v", keys, want)
+	}
+
+	if err := w.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"foo", "bar", "zoo"} {
+		sh := s.shard([]byte(k))
+		sh.mu.RLock()
+		if sh.synced.contains(k) || sh.unsynced.contains(k) {
+			t.Errorf("canceled watcher still watches %q", k)
+		}
+		sh.mu.RUnlock()
+	}
+}
+
 func TestWatchDeleteRange(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
 	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
@@ -297,9 +375,8 @@ func TestWatcherRequestProgress(t *testing.T) {
 	// method to sync watchers in unsynced map. We want to keep watchers
 	// in unsynced to test if syncWatchers works as expected.
 	s := &watchableStore{
-		store:    NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
-		unsynced: newWatcherGroup(),
-		synced:   newWatcherGroup(),
+		store:  NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
+		shards: newWatcherShards(watcherShardCount),
 	}
 
 	defer func() {
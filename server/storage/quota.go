@@ -52,8 +52,13 @@ func (*passthroughQuota) Cost(interface{}) int       { return 0 }
 func (*passthroughQuota) Remaining() int64           { return 1 }
 
 type BackendQuota struct {
-	be              backend.Backend
-	maxBackendBytes int64
+	be backend.Backend
+	// quotaBytes returns the configured quota, 0 for the default one. It may
+	// change at runtime.
+	quotaBytes func() int64
+	// capBackendBytes is the hard cap up to which the quota grows automatically,
+	// the quota does not grow if it is not greater than maxBackendBytes.
+	capBackendBytes int64
 }
 
 const (
@@ -61,6 +66,13 @@ const (
 	leaseOverhead = 64
 	// kvOverhead is an estimate for the cost of storing a key's Metadata
 	kvOverhead = 256
+
+	// quotaGrowThreshold is the ratio of the quota the backend size in use must
+	// reach for the quota to grow.
+	quotaGrowThreshold = 0.9
+	// quotaGrowSteps is the number of steps the configured quota is divided in
+	// when growing.
+	quotaGrowSteps = 4
 )
 
 var (
@@ -71,8 +83,19 @@ var (
 	maxQuotaSize     = humanize.Bytes(uint64(MaxQuotaBytes))
 )
 
-// NewBackendQuota creates a quota layer with the given storage limit.
-func NewBackendQuota(lg *zap.Logger, quotaBackendBytesCfg int64, be backend.Backend, name string) Quota {
+// NewBackendQuota creates a quota layer with the given storage limit. If
+// maxQuotaBackendBytesCfg is greater than the limit, the limit grows up to it
+// when the backend size in use, which neither compaction nor defragmentation
+// can reclaim, approaches the limit.
+func NewBackendQuota(lg *zap.Logger, quotaBackendBytesCfg, maxQuotaBackendBytesCfg int64, be backend.Backend, name string) Quota {
+	return NewRuntimeBackendQuota(lg, func() int64 { return quotaBackendBytesCfg }, maxQuotaBackendBytesCfg, be, name)
+}
+
+// NewRuntimeBackendQuota is like NewBackendQuota, the storage limit being read
+// from quotaBackendBytes on each request for it to be changed at runtime. A
+// quota disabled by a negative limit on creation stays disabled.
+func NewRuntimeBackendQuota(lg *zap.Logger, quotaBackendBytesFn func() int64, maxQuotaBackendBytesCfg int64, be backend.Backend, name string) Quota {
+	quotaBackendBytesCfg := quotaBackendBytesFn()
 	quotaBackendBytes.Set(float64(quotaBackendBytesCfg))
 	if quotaBackendBytesCfg < 0 {
 		// disable quotas if negative
@@ -99,7 +122,7 @@ func NewBackendQuota(lg *zap.Logger, quotaBackendBytesCfg int64, be backend.Back
 			}
 		})
 		quotaBackendBytes.Set(float64(DefaultQuotaBytes))
-		return &BackendQuota{be, DefaultQuotaBytes}
+		return &BackendQuota{be, quotaBackendBytesFn, maxQuotaBackendBytesCfg}
 	}
 
 	quotaLogOnce.Do(func() {
@@ -120,7 +143,7 @@ func NewBackendQuota(lg *zap.Logger, quotaBackendBytesCfg int64, be backend.Back
 			zap.String("quota-size", humanize.Bytes(uint64(quotaBackendBytesCfg))),
 		)
 	})
-	return &BackendQuota{be, quotaBackendBytesCfg}
+	return &BackendQuota{be, quotaBackendBytesFn, maxQuotaBackendBytesCfg}
 }
 
 func (b *BackendQuota) Available(v interface{}) bool {
@@ -130,7 +153,44 @@ func (b *BackendQuota) Available(v interface{}) bool {
 		return true
 	}
 	// TODO: maybe optimize Backend.Size()
-	return b.be.Size()+int64(cost) < b.maxBackendBytes
+	return b.be.Size()+int64(cost) < b.limit()
+}
+
+// limit returns the quota. When growth is enabled, it grows from the configured
+// quota by steps of a quarter of it, up to the hard cap, while the backend size
+// in use is above 90% of it. Since it only depends on the state of the backend,
+// the quotas of the API and of the applier agree, and a restarted member keeps
+// its grown quota.
+func (b *BackendQuota) limit() int64 {
+	q := b.maxBackendBytes()
+	if b.capBackendBytes <= q {
+		return q
+	}
+	inUse := float64(b.be.SizeInUse())
+	step := (q + quotaGrowSteps - 1) / quotaGrowSteps
+	for q < b.capBackendBytes && inUse >= float64(q)*quotaGrowThreshold {
+		q += step
+	}
+	if q > b.capBackendBytes {
+		q = b.capBackendBytes
+	}
+	return q
+}
+
+// Grown returns the current quota, and whether it grew beyond the configured
+// quota.
+func (b *BackendQuota) Grown() (int64, bool) {
+	q := b.limit()
+	quotaBackendBytes.Set(float64(q))
+	return q, q > b.maxBackendBytes()
+}
+
+// maxBackendBytes returns the configured quota.
+func (b *BackendQuota) maxBackendBytes() int64 {
+	if q := b.quotaBytes(); q > 0 {
+		return q
+	}
+	return DefaultQuotaBytes
 }
 
 func (b *BackendQuota) Cost(v interface{}) int {
@@ -141,6 +201,8 @@ func (b *BackendQuota) Cost(v interface{}) int {
 		return costTxn(r)
 	case *pb.LeaseGrantRequest:
 		return leaseOverhead
+	case *pb.LeaseGrantBatchRequest:
+		return leaseOverhead * len(r.Requests)
 	default:
 		panic("unexpected cost")
 	}
@@ -172,5 +234,5 @@ func costTxn(r *pb.TxnRequest) int {
 }
 
 func (b *BackendQuota) Remaining() int64 {
-	return b.maxBackendBytes - b.be.Size()
+	return b.limit() - b.be.Size()
 }
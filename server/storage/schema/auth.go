@@ -95,6 +95,12 @@ func (atx *authBatchTx) UnsafeSaveAuthRevision(rev uint64) {
 	atx.tx.UnsafePut(Auth, AuthRevisionKeyName, revBytes)
 }
 
+func (atx *authBatchTx) UnsafeSaveAuthBcryptCost(cost int) {
+	costBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(costBytes, uint64(cost))
+	atx.tx.UnsafePut(Auth, AuthBcryptCostKeyName, costBytes)
+}
+
 func (atx *authBatchTx) UnsafeReadAuthEnabled() bool {
 	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
 	return arx.UnsafeReadAuthEnabled()
@@ -105,6 +111,11 @@ func (atx *authBatchTx) UnsafeReadAuthRevision() uint64 {
 	return arx.UnsafeReadAuthRevision()
 }
 
+func (atx *authBatchTx) UnsafeReadAuthBcryptCost() int {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeReadAuthBcryptCost()
+}
+
 func (atx *authBatchTx) Lock() {
 	atx.tx.LockInsideApply()
 }
@@ -135,6 +146,15 @@ func (atx *authReadTx) UnsafeReadAuthRevision() uint64 {
 	return binary.BigEndian.Uint64(vs[0])
 }
 
+func (atx *authReadTx) UnsafeReadAuthBcryptCost() int {
+	_, vs := atx.tx.UnsafeRange(Auth, AuthBcryptCostKeyName, nil, 0)
+	if len(vs) != 1 {
+		// the bcrypt cost of the cluster has not been set
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(vs[0]))
+}
+
 func (atx *authReadTx) Lock() {
 	atx.tx.RLock()
 }
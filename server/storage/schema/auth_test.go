@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 )
@@ -129,3 +130,80 @@ func TestAuthRevision(t *testing.T) {
 		})
 	}
 }
+
+// TestAuthBcryptCost ensures that UnsafeSaveAuthBcryptCost&UnsafeReadAuthBcryptCost work well together.
+func TestAuthBcryptCost(t *testing.T) {
+	tcs := []struct {
+		name     string
+		setCost  int
+		wantCost int
+	}{
+		{
+			name:     "Returns 0 by default",
+			wantCost: 0,
+		},
+		{
+			name:     "Returns 12 after setting 12",
+			setCost:  12,
+			wantCost: 12,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zaptest.NewLogger(t)
+			be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+			abe := NewAuthBackend(lg, be)
+			abe.CreateAuthBuckets()
+
+			if tc.setCost != 0 {
+				tx := abe.BatchTx()
+				tx.Lock()
+				tx.UnsafeSaveAuthBcryptCost(tc.setCost)
+				tx.Unlock()
+			}
+			abe.ForceCommit()
+			be.Close()
+
+			be2 := backend.NewDefaultBackend(lg, tmpPath)
+			defer be2.Close()
+			abe2 := NewAuthBackend(lg, be2)
+			tx := abe2.ReadTx()
+			tx.Lock()
+			defer tx.Unlock()
+			v := tx.UnsafeReadAuthBcryptCost()
+
+			assert.Equal(t, tc.wantCost, v)
+		})
+	}
+}
+
+// TestAuthTokenRevocations ensures that the token revocations are persisted
+// and read back, and that they can be deleted.
+func TestAuthTokenRevocations(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+	abe := NewAuthBackend(lg, be)
+	abe.CreateAuthBuckets()
+
+	expires := time.Unix(0, time.Now().Add(time.Minute).UnixNano())
+	byID := auth.TokenRevocation{ID: "12", Expires: expires}
+	byUser := auth.TokenRevocation{User: "foo", Index: 34, Expires: expires}
+	tx := abe.BatchTx()
+	tx.Lock()
+	tx.UnsafeSaveAuthRevision(1)
+	tx.UnsafePutTokenRevocation(byID)
+	tx.UnsafePutTokenRevocation(byUser)
+	tx.Unlock()
+	abe.ForceCommit()
+	be.Close()
+
+	be2 := backend.NewDefaultBackend(lg, tmpPath)
+	defer be2.Close()
+	abe2 := NewAuthBackend(lg, be2)
+	tx2 := abe2.BatchTx()
+	tx2.Lock()
+	assert.ElementsMatch(t, []auth.TokenRevocation{byID, byUser}, tx2.UnsafeGetAllTokenRevocations())
+	tx2.UnsafeDeleteTokenRevocation(byID)
+	assert.Equal(t, []auth.TokenRevocation{byUser}, tx2.UnsafeGetAllTokenRevocations())
+	tx2.Unlock()
+}
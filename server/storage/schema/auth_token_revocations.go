@@ -0,0 +1,81 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/server/v3/auth"
+)
+
+var (
+	// the token revocations are kept in the auth bucket, keyed by the ID of
+	// the revoked token or by the user whose tokens are revoked
+	authTokenRevocationIDPrefix   = []byte("tokenRevocation/id/")
+	authTokenRevocationUserPrefix = []byte("tokenRevocation/user/")
+)
+
+func tokenRevocationKey(r auth.TokenRevocation) []byte {
+	if r.ID != "" {
+		return append(append([]byte{}, authTokenRevocationIDPrefix...), r.ID...)
+	}
+	return append(append([]byte{}, authTokenRevocationUserPrefix...), r.User...)
+}
+
+func (atx *authBatchTx) UnsafePutTokenRevocation(r auth.TokenRevocation) {
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint64(v, r.Index)
+	binary.BigEndian.PutUint64(v[8:], uint64(r.Expires.UnixNano()))
+	atx.tx.UnsafePut(Auth, tokenRevocationKey(r), v)
+}
+
+func (atx *authBatchTx) UnsafeDeleteTokenRevocation(r auth.TokenRevocation) {
+	atx.tx.UnsafeDelete(Auth, tokenRevocationKey(r))
+}
+
+func (atx *authBatchTx) UnsafeGetAllTokenRevocations() []auth.TokenRevocation {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeGetAllTokenRevocations()
+}
+
+func (atx *authReadTx) UnsafeGetAllTokenRevocations() []auth.TokenRevocation {
+	var rs []auth.TokenRevocation
+	err := atx.tx.UnsafeForEach(Auth, func(k []byte, v []byte) error {
+		var r auth.TokenRevocation
+		switch {
+		case bytes.HasPrefix(k, authTokenRevocationIDPrefix):
+			r.ID = string(k[len(authTokenRevocationIDPrefix):])
+		case bytes.HasPrefix(k, authTokenRevocationUserPrefix):
+			r.User = string(k[len(authTokenRevocationUserPrefix):])
+		default:
+			return nil
+		}
+		if len(v) != 16 {
+			atx.lg.Panic("invalid token revocation", zap.ByteString("key", k))
+		}
+		r.Index = binary.BigEndian.Uint64(v)
+		r.Expires = time.Unix(0, int64(binary.BigEndian.Uint64(v[8:])))
+		rs = append(rs, r)
+		return nil
+	})
+	if err != nil {
+		atx.lg.Panic("failed to get token revocations", zap.Error(err))
+	}
+	return rs
+}
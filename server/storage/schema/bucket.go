@@ -22,10 +22,17 @@ import (
 )
 
 var (
-	keyBucketName   = []byte("key")
-	metaBucketName  = []byte("meta")
-	leaseBucketName = []byte("lease")
-	alarmBucketName = []byte("alarm")
+	keyBucketName    = []byte("key")
+	metaBucketName   = []byte("meta")
+	leaseBucketName  = []byte("lease")
+	alarmBucketName  = []byte("alarm")
+	keyTTLBucketName = []byte("key_ttl")
+
+	keyChunkBucketName  = []byte("key_chunk")
+	valuePartBucketName = []byte("value_part")
+
+	prefixQuotaBucketName = []byte("prefix_quota")
+	revisionPinBucketName = []byte("revision_pin")
 
 	clusterBucketName = []byte("cluster")
 
@@ -45,9 +52,15 @@ var (
 	Lease   = backend.Bucket(bucket{id: 3, name: leaseBucketName, safeRangeBucket: false})
 	Alarm   = backend.Bucket(bucket{id: 4, name: alarmBucketName, safeRangeBucket: false})
 	Cluster = backend.Bucket(bucket{id: 5, name: clusterBucketName, safeRangeBucket: false})
+	KeyTTL  = backend.Bucket(bucket{id: 6, name: keyTTLBucketName, safeRangeBucket: false})
+
+	PrefixQuota = backend.Bucket(bucket{id: 7, name: prefixQuotaBucketName, safeRangeBucket: false})
+	KeyChunk    = backend.Bucket(bucket{id: 8, name: keyChunkBucketName, safeRangeBucket: true})
+	RevisionPin = backend.Bucket(bucket{id: 9, name: revisionPinBucketName, safeRangeBucket: false})
 
 	Members        = backend.Bucket(bucket{id: 10, name: membersBucketName, safeRangeBucket: false})
 	MembersRemoved = backend.Bucket(bucket{id: 11, name: membersRemovedBucketName, safeRangeBucket: false})
+	ValuePart      = backend.Bucket(bucket{id: 12, name: valuePartBucketName, safeRangeBucket: false})
 
 	Auth      = backend.Bucket(bucket{id: 20, name: authBucketName, safeRangeBucket: false})
 	AuthUsers = backend.Bucket(bucket{id: 21, name: authUsersBucketName, safeRangeBucket: false})
@@ -81,6 +94,10 @@ var (
 	ClusterDowngradeKeyName      = []byte("downgrade")
 	// Since v3.6
 	MetaStorageVersionName = []byte("storageVersion")
+	AuthBcryptCostKeyName  = []byte("bcryptCost")
+	// MetaEncodedKeyValuesName records that the key bucket may hold
+	// compressed or chunked records.
+	MetaEncodedKeyValuesName = []byte("encodedKeyValues")
 	// Before adding new meta key please update server/etcdserver/version
 )
 
@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"math"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// UnsafeCreateKeyChunkBucket creates the bucket storing the chunks of the
+// key-value records too large to be stored in a single record.
+func UnsafeCreateKeyChunkBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(KeyChunk)
+}
+
+// keyChunkKey returns the key of the chunk of the given index of the record
+// stored under the given key bucket key.
+func keyChunkKey(rev []byte, index uint32) []byte {
+	k := make([]byte, len(rev)+4)
+	copy(k, rev)
+	binary.BigEndian.PutUint32(k[len(rev):], index)
+	return k
+}
+
+// UnsafePutKeyChunk stores the chunk of the given index of the record stored
+// under the given key bucket key.
+func UnsafePutKeyChunk(tx backend.BatchTx, rev []byte, index uint32, chunk []byte) {
+	tx.UnsafePut(KeyChunk, keyChunkKey(rev, index), chunk)
+}
+
+// UnsafeRangeKeyChunks returns the chunks of the record stored under the
+// given key bucket key, in order of index.
+func UnsafeRangeKeyChunks(tx backend.ReadTx, rev []byte) [][]byte {
+	_, vs := tx.UnsafeRange(KeyChunk, keyChunkKey(rev, 0), keyChunkKey(rev, math.MaxUint32), 0)
+	return vs
+}
+
+// UnsafeDeleteKeyChunks removes the chunks of the given indexes, from 1 up
+// to but not including n, of the record stored under the given key bucket
+// key.
+func UnsafeDeleteKeyChunks(tx backend.BatchTx, rev []byte, n uint32) {
+	for i := uint32(1); i < n; i++ {
+		tx.UnsafeDelete(KeyChunk, keyChunkKey(rev, i))
+	}
+}
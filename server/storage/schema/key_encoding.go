@@ -0,0 +1,113 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// EncodedKeyValueMarker starts the records of the key bucket stored other
+// than as a marshaled mvccpb.KeyValue, compressed or split into chunks,
+// which etcd v3.5 cannot read.
+const EncodedKeyValueMarker byte = 0
+
+// ErrEncodedKeyValues is returned when downgrading a backend holding
+// compressed or chunked key-value records to v3.5.
+var ErrEncodedKeyValues = errors.New("cannot downgrade storage, the key bucket holds compressed or chunked records")
+
+var errFoundEncodedKeyValue = errors.New("found an encoded key-value record")
+
+// UnsafeSetEncodedKeyValues records that the key bucket may hold compressed
+// or chunked records.
+func UnsafeSetEncodedKeyValues(tx backend.BatchTx) {
+	tx.UnsafePut(Meta, MetaEncodedKeyValuesName, []byte{1})
+}
+
+// UnsafeReadEncodedKeyValues returns true if the key bucket may hold
+// compressed or chunked records.
+func UnsafeReadEncodedKeyValues(tx backend.ReadTx) bool {
+	_, vs := tx.UnsafeRange(Meta, MetaEncodedKeyValuesName, nil, 1)
+	return len(vs) != 0
+}
+
+// UnsafeHasEncodedKeyValues returns true if the key bucket holds compressed
+// or chunked records, looking for them only if it may hold some.
+func UnsafeHasEncodedKeyValues(tx backend.ReadTx) (bool, error) {
+	if !UnsafeReadEncodedKeyValues(tx) {
+		return false, nil
+	}
+	err := tx.UnsafeForEach(Key, func(k, v []byte) error {
+		if len(v) != 0 && v[0] == EncodedKeyValueMarker {
+			return errFoundEncodedKeyValue
+		}
+		return nil
+	})
+	if errors.Is(err, errFoundEncodedKeyValue) {
+		return true, nil
+	}
+	return false, err
+}
+
+// HasEncodedKeyValues returns true if the key bucket holds compressed or
+// chunked records.
+func HasEncodedKeyValues(tx backend.ReadTx) (bool, error) {
+	tx.RLock()
+	defer tx.RUnlock()
+	return UnsafeHasEncodedKeyValues(tx)
+}
+
+// encodedKeyValuesChange keeps the compressed and chunked records from
+// being downgraded to v3.5, dropping the field recording them otherwise.
+type encodedKeyValuesChange struct{}
+
+func (encodedKeyValuesChange) upgradeAction() action {
+	return noopAction{}
+}
+
+func (encodedKeyValuesChange) downgradeAction() action {
+	return dropEncodedKeyValuesAction{}
+}
+
+// dropEncodedKeyValuesAction fails if the key bucket holds compressed or
+// chunked records, and deletes the field recording they may exist
+// otherwise.
+type dropEncodedKeyValuesAction struct{}
+
+func (a dropEncodedKeyValuesAction) unsafeCheck(tx backend.ReadTx) error {
+	found, err := UnsafeHasEncodedKeyValues(tx)
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrEncodedKeyValues
+	}
+	return nil
+}
+
+func (a dropEncodedKeyValuesAction) unsafeDo(tx backend.BatchTx) (action, error) {
+	if err := a.unsafeCheck(tx); err != nil {
+		return nil, err
+	}
+	return deleteKeyAction{Bucket: Meta, FieldName: MetaEncodedKeyValuesName}.unsafeDo(tx)
+}
+
+// noopAction changes nothing.
+type noopAction struct{}
+
+func (noopAction) unsafeDo(tx backend.BatchTx) (action, error) {
+	return noopAction{}, nil
+}
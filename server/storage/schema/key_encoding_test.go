@@ -0,0 +1,105 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	waltesting "go.etcd.io/etcd/server/v3/storage/wal/testing"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+func TestMigrateEncodedKeyValues(t *testing.T) {
+	tcs := []struct {
+		name    string
+		records [][]byte
+		// setFlag records that the key bucket may hold encoded records
+		setFlag bool
+
+		expectErr     error
+		expectChanges []Change
+	}{
+		{
+			name:    "Downgrading v3.6 to v3.5 works without encoded records",
+			records: [][]byte{{0x0a, 0x01, 'a'}},
+			expectChanges: []Change{
+				{StorageVersion: version.V3_5, Type: ChangeDrop, Bucket: "meta", Field: "storageVersion", OldValue: []byte("3.6.0")},
+			},
+		},
+		{
+			name:    "Downgrading v3.6 to v3.5 drops the field once the encoded records are gone",
+			records: [][]byte{{0x0a, 0x01, 'a'}},
+			setFlag: true,
+			expectChanges: []Change{
+				{StorageVersion: version.V3_5, Type: ChangeDrop, Bucket: "meta", Field: "encodedKeyValues", OldValue: []byte{1}},
+				{StorageVersion: version.V3_5, Type: ChangeDrop, Bucket: "meta", Field: "storageVersion", OldValue: []byte("3.6.0")},
+			},
+		},
+		{
+			name:      "Downgrading v3.6 to v3.5 fails with encoded records",
+			records:   [][]byte{{0x0a, 0x01, 'a'}, {EncodedKeyValueMarker, 1, 'b'}},
+			setFlag:   true,
+			expectErr: ErrEncodedKeyValues,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zap.NewNop()
+			dataPath := setupBackendData(t, version.V3_6, func(tx backend.BatchTx) {
+				MustUnsafeSaveConfStateToBackend(lg, tx, &raftpb.ConfState{})
+				UnsafeUpdateConsistentIndex(tx, 1, 1)
+				UnsafeSetStorageVersion(tx, &version.V3_6)
+				tx.UnsafeCreateBucket(Key)
+				for i, r := range tc.records {
+					tx.UnsafePut(Key, []byte{byte(i)}, r)
+				}
+				if tc.setFlag {
+					UnsafeSetEncodedKeyValues(tx)
+				}
+			})
+			w, _ := waltesting.NewTmpWAL(t, nil)
+			defer w.Close()
+			walVersion, err := wal.ReadWALVersion(w)
+			require.NoError(t, err)
+
+			b := backend.NewDefaultBackend(lg, dataPath)
+			defer b.Close()
+
+			changes, err := PlanMigration(lg, b.ReadTx(), walVersion, version.V3_5)
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectChanges, changes)
+
+			err = Migrate(lg, b.BatchTx(), walVersion, version.V3_5)
+			assert.ErrorIs(t, err, tc.expectErr)
+			found, err := HasEncodedKeyValues(b.ReadTx())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectErr != nil, found)
+			if tc.expectErr != nil {
+				assert.Equal(t, &version.V3_6, UnsafeReadStorageVersion(b.BatchTx()))
+				assert.True(t, UnsafeReadEncodedKeyValues(b.BatchTx()))
+			} else {
+				assert.Nil(t, UnsafeReadStorageVersion(b.BatchTx()))
+				assert.False(t, UnsafeReadEncodedKeyValues(b.BatchTx()))
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+const keyTTLValueLen = 16
+
+// UnsafeCreateKeyTTLBucket creates the bucket storing the TTL of the keys
+// put with a TTL.
+func UnsafeCreateKeyTTLBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(KeyTTL)
+}
+
+// UnsafePutKeyTTL records that the key was put at the given revision with
+// a TTL of ttl seconds.
+func UnsafePutKeyTTL(tx backend.BatchTx, key []byte, rev, ttl int64) {
+	v := make([]byte, keyTTLValueLen)
+	binary.BigEndian.PutUint64(v, uint64(rev))
+	binary.BigEndian.PutUint64(v[8:], uint64(ttl))
+	tx.UnsafePut(KeyTTL, key, v)
+}
+
+// UnsafeDeleteKeyTTL removes the TTL of the key.
+func UnsafeDeleteKeyTTL(tx backend.BatchTx, key []byte) {
+	tx.UnsafeDelete(KeyTTL, key)
+}
+
+// UnsafeForEachKeyTTL calls visitor with the revision and the TTL of every
+// key put with a TTL.
+func UnsafeForEachKeyTTL(tx backend.ReadTx, visitor func(key []byte, rev, ttl int64) error) error {
+	return tx.UnsafeForEach(KeyTTL, func(k, v []byte) error {
+		if len(v) != keyTTLValueLen {
+			return fmt.Errorf("invalid TTL of key %q: %x", k, v)
+		}
+		return visitor(k, int64(binary.BigEndian.Uint64(v)), int64(binary.BigEndian.Uint64(v[8:])))
+	})
+}
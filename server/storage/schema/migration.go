@@ -15,6 +15,7 @@
 package schema
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/coreos/go-semver/semver"
@@ -67,6 +68,113 @@ func (p migrationPlan) unsafeExecute(lg *zap.Logger, tx backend.BatchTx) (err er
 	return nil
 }
 
+// unsafeDescribe returns the changes the plan makes when executed on the
+// content of tx, without modifying it.
+func (p migrationPlan) unsafeDescribe(tx backend.ReadTx) ([]Change, error) {
+	d := &changeDescriber{tx: tx, pending: map[string]*[]byte{}}
+	for _, s := range p {
+		for _, a := range s.actions {
+			switch a := a.(type) {
+			case setKeyAction:
+				d.set(s.target, a.Bucket, a.FieldName, a.FieldValue)
+			case deleteKeyAction:
+				d.drop(s.target, a.Bucket, a.FieldName)
+			case dropEncodedKeyValuesAction:
+				if err := a.unsafeCheck(tx); err != nil {
+					return nil, err
+				}
+				d.drop(s.target, Meta, MetaEncodedKeyValuesName)
+			case noopAction:
+			default:
+				return nil, fmt.Errorf("cannot describe action %T", a)
+			}
+		}
+		if !s.target.LessThan(version.V3_6) {
+			d.set(s.target, Meta, MetaStorageVersionName, []byte(s.target.String()))
+		}
+	}
+	return d.changes, nil
+}
+
+// ChangeType is the type of change made to a backend field by a migration.
+type ChangeType string
+
+const (
+	ChangeAdd     ChangeType = "add"
+	ChangeRewrite ChangeType = "rewrite"
+	ChangeDrop    ChangeType = "drop"
+)
+
+// Change describes a change made to a single backend field by a migration.
+type Change struct {
+	// StorageVersion is the storage version reached by the migration step making the change.
+	StorageVersion semver.Version
+	Type           ChangeType
+	Bucket         string
+	Field          string
+	OldValue       []byte
+	NewValue       []byte
+}
+
+func (c Change) String() string {
+	target := fmt.Sprintf("%d.%d", c.StorageVersion.Major, c.StorageVersion.Minor)
+	switch c.Type {
+	case ChangeAdd:
+		return fmt.Sprintf("[%s] add %s/%s = %q", target, c.Bucket, c.Field, c.NewValue)
+	case ChangeRewrite:
+		return fmt.Sprintf("[%s] rewrite %s/%s: %q -> %q", target, c.Bucket, c.Field, c.OldValue, c.NewValue)
+	default:
+		return fmt.Sprintf("[%s] drop %s/%s (was %q)", target, c.Bucket, c.Field, c.OldValue)
+	}
+}
+
+// changeDescriber records the changes made by a sequence of actions, keeping
+// track of the values they would write to describe the following ones.
+type changeDescriber struct {
+	tx      backend.ReadTx
+	pending map[string]*[]byte
+	changes []Change
+}
+
+func (d *changeDescriber) get(bucket backend.Bucket, field []byte) ([]byte, bool) {
+	if v, ok := d.pending[bucket.String()+"/"+string(field)]; ok {
+		return *v, *v != nil
+	}
+	_, vs := d.tx.UnsafeRange(bucket, field, nil, 1)
+	if len(vs) == 1 {
+		return vs[0], true
+	}
+	return nil, false
+}
+
+func (d *changeDescriber) set(target semver.Version, bucket backend.Bucket, field, value []byte) {
+	old, found := d.get(bucket, field)
+	c := Change{StorageVersion: target, Bucket: bucket.String(), Field: string(field), OldValue: old, NewValue: value}
+	switch {
+	case !found:
+		c.Type = ChangeAdd
+	case !bytes.Equal(old, value):
+		c.Type = ChangeRewrite
+	default:
+		return
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	d.pending[bucket.String()+"/"+string(field)] = &value
+	d.changes = append(d.changes, c)
+}
+
+func (d *changeDescriber) drop(target semver.Version, bucket backend.Bucket, field []byte) {
+	old, found := d.get(bucket, field)
+	if !found {
+		return
+	}
+	var deleted []byte
+	d.pending[bucket.String()+"/"+string(field)] = &deleted
+	d.changes = append(d.changes, Change{StorageVersion: target, Type: ChangeDrop, Bucket: bucket.String(), Field: string(field), OldValue: old})
+}
+
 // migrationStep represents a single migrationStep of migrating etcd storage between two minor versions.
 type migrationStep struct {
 	target  semver.Version
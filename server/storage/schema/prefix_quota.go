@@ -0,0 +1,81 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+type prefixQuotaBackend struct {
+	lg *zap.Logger
+	be backend.Backend
+}
+
+func NewPrefixQuotaBackend(lg *zap.Logger, be backend.Backend) *prefixQuotaBackend {
+	return &prefixQuotaBackend{
+		lg: lg,
+		be: be,
+	}
+}
+
+func (s *prefixQuotaBackend) CreatePrefixQuotaBucket() {
+	tx := s.be.BatchTx()
+	tx.LockOutsideApply()
+	defer tx.Unlock()
+	tx.UnsafeCreateBucket(PrefixQuota)
+}
+
+// MustPutPrefixQuota persists the limits of the quota, keyed by its prefix.
+func (s *prefixQuotaBackend) MustPutPrefixQuota(q *etcdserverpb.PrefixQuota) {
+	v, err := (&etcdserverpb.PrefixQuota{Prefix: q.Prefix, MaxBytes: q.MaxBytes, MaxKeys: q.MaxKeys}).Marshal()
+	if err != nil {
+		s.lg.Panic("failed to marshal prefix quota", zap.Error(err))
+	}
+
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	tx.UnsafePut(PrefixQuota, q.Prefix, v)
+}
+
+func (s *prefixQuotaBackend) MustDeletePrefixQuota(prefix []byte) {
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	tx.UnsafeDelete(PrefixQuota, prefix)
+}
+
+func (s *prefixQuotaBackend) GetAllPrefixQuotas() ([]*etcdserverpb.PrefixQuota, error) {
+	tx := s.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	var qs []*etcdserverpb.PrefixQuota
+	err := tx.UnsafeForEach(PrefixQuota, func(k, v []byte) error {
+		var q etcdserverpb.PrefixQuota
+		if err := q.Unmarshal(v); err != nil {
+			return err
+		}
+		qs = append(qs, &q)
+		return nil
+	})
+	return qs, err
+}
+
+func (s prefixQuotaBackend) ForceCommit() {
+	s.be.ForceCommit()
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+type revisionPinBackend struct {
+	lg *zap.Logger
+	be backend.Backend
+}
+
+func NewRevisionPinBackend(lg *zap.Logger, be backend.Backend) *revisionPinBackend {
+	return &revisionPinBackend{
+		lg: lg,
+		be: be,
+	}
+}
+
+func (s *revisionPinBackend) CreateRevisionPinBucket() {
+	tx := s.be.BatchTx()
+	tx.LockOutsideApply()
+	defer tx.Unlock()
+	tx.UnsafeCreateBucket(RevisionPin)
+}
+
+// MustPutRevisionPin persists the pin, keyed by its owner.
+func (s *revisionPinBackend) MustPutRevisionPin(p *etcdserverpb.RevisionPin) {
+	v, err := p.Marshal()
+	if err != nil {
+		s.lg.Panic("failed to marshal revision pin", zap.Error(err))
+	}
+
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	tx.UnsafePut(RevisionPin, []byte(p.Owner), v)
+}
+
+func (s *revisionPinBackend) MustDeleteRevisionPin(owner string) {
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	tx.UnsafeDelete(RevisionPin, []byte(owner))
+}
+
+func (s *revisionPinBackend) GetAllRevisionPins() ([]*etcdserverpb.RevisionPin, error) {
+	tx := s.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	var ps []*etcdserverpb.RevisionPin
+	err := tx.UnsafeForEach(RevisionPin, func(k, v []byte) error {
+		var p etcdserverpb.RevisionPin
+		if err := p.Unmarshal(v); err != nil {
+			return err
+		}
+		ps = append(ps, &p)
+		return nil
+	})
+	return ps, err
+}
+
+func (s revisionPinBackend) ForceCommit() {
+	s.be.ForceCommit()
+}
@@ -63,21 +63,41 @@ func Migrate(lg *zap.Logger, tx backend.BatchTx, w WALVersion, target semver.Ver
 
 // UnsafeMigrate is non thread-safe version of Migrate.
 func UnsafeMigrate(lg *zap.Logger, tx backend.BatchTx, w WALVersion, target semver.Version) error {
+	plan, err := unsafeMigrationPlan(lg, tx, w, target)
+	if err != nil {
+		return err
+	}
+	return plan.unsafeExecute(lg, tx)
+}
+
+// PlanMigration returns the changes that Migrate would make to the storage
+// schema to reach the target version, without applying them.
+func PlanMigration(lg *zap.Logger, tx backend.ReadTx, w WALVersion, target semver.Version) ([]Change, error) {
+	tx.RLock()
+	defer tx.RUnlock()
+	plan, err := unsafeMigrationPlan(lg, tx, w, target)
+	if err != nil {
+		return nil, err
+	}
+	return plan.unsafeDescribe(tx)
+}
+
+func unsafeMigrationPlan(lg *zap.Logger, tx backend.ReadTx, w WALVersion, target semver.Version) (migrationPlan, error) {
 	current, err := UnsafeDetectSchemaVersion(lg, tx)
 	if err != nil {
-		return fmt.Errorf("cannot detect storage schema version: %v", err)
+		return nil, fmt.Errorf("cannot detect storage schema version: %v", err)
 	}
 	plan, err := newPlan(lg, current, target)
 	if err != nil {
-		return fmt.Errorf("cannot create migration plan: %v", err)
+		return nil, fmt.Errorf("cannot create migration plan: %v", err)
 	}
 	if target.LessThan(current) {
 		minVersion := w.MinimalEtcdVersion()
 		if minVersion != nil && target.LessThan(*minVersion) {
-			return fmt.Errorf("cannot downgrade storage, WAL contains newer entries")
+			return nil, fmt.Errorf("cannot downgrade storage, WAL contains newer entries")
 		}
 	}
-	return plan.unsafeExecute(lg, tx)
+	return plan, nil
 }
 
 // DetectSchemaVersion returns version of storage schema. Returned value depends on etcd version that created the backend. For
@@ -130,6 +150,7 @@ var (
 	schemaChanges = map[semver.Version][]schemaChange{
 		version.V3_6: {
 			addNewField(Meta, MetaStorageVersionName, emptyStorageVersion),
+			encodedKeyValuesChange{},
 		},
 	}
 	// emptyStorageVersion is used for v3.6 Step for the first time, in all other version StoragetVersion should be set by migrator.
@@ -227,6 +227,77 @@ func TestMigrate(t *testing.T) {
 	}
 }
 
+func TestPlanMigration(t *testing.T) {
+	tcs := []struct {
+		name          string
+		version       semver.Version
+		targetVersion semver.Version
+		walEntries    []etcdserverpb.InternalRaftRequest
+
+		expectChanges  []Change
+		expectErrorMsg string
+	}{
+		{
+			name:          "Upgrading v3.5 to v3.6 adds and sets the storage version",
+			version:       version.V3_5,
+			targetVersion: version.V3_6,
+			expectChanges: []Change{
+				{StorageVersion: version.V3_6, Type: ChangeAdd, Bucket: "meta", Field: "storageVersion", NewValue: []byte("")},
+				{StorageVersion: version.V3_6, Type: ChangeRewrite, Bucket: "meta", Field: "storageVersion", OldValue: []byte(""), NewValue: []byte("3.6.0")},
+			},
+		},
+		{
+			name:          "Downgrading v3.6 to v3.5 drops the storage version",
+			version:       version.V3_6,
+			targetVersion: version.V3_5,
+			expectChanges: []Change{
+				{StorageVersion: version.V3_5, Type: ChangeDrop, Bucket: "meta", Field: "storageVersion", OldValue: []byte("3.6.0")},
+			},
+		},
+		{
+			name:          "Migrating v3.6 to v3.6 makes no change",
+			version:       version.V3_6,
+			targetVersion: version.V3_6,
+		},
+		{
+			name:          "Downgrading v3.6 to v3.5 fails if there are newer WAL entries",
+			version:       version.V3_6,
+			targetVersion: version.V3_5,
+			walEntries: []etcdserverpb.InternalRaftRequest{
+				{ClusterVersionSet: &membershippb.ClusterVersionSetRequest{Ver: "3.6.0"}},
+			},
+			expectErrorMsg: "cannot downgrade storage, WAL contains newer entries",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zap.NewNop()
+			dataPath := setupBackendData(t, tc.version, nil)
+			w, _ := waltesting.NewTmpWAL(t, tc.walEntries)
+			defer w.Close()
+			walVersion, err := wal.ReadWALVersion(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := backend.NewDefaultBackend(lg, dataPath)
+			defer b.Close()
+
+			changes, err := PlanMigration(lg, b.ReadTx(), walVersion, tc.targetVersion)
+			if tc.expectErrorMsg != "" {
+				assert.EqualError(t, err, tc.expectErrorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectChanges, changes)
+
+			v, err := DetectSchemaVersion(lg, b.ReadTx())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.version, v)
+		})
+	}
+}
+
 func TestMigrateIsReversible(t *testing.T) {
 	tcs := []struct {
 		initialVersion semver.Version
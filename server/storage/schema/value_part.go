@@ -0,0 +1,145 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+const valueUploadHeaderLen = 20
+
+// ValueUpload is the upload of a value put in several parts, staged until
+// the final put of its key.
+type ValueUpload struct {
+	// Key is the key the value is put to.
+	Key []byte
+	// Revision is the revision current when the upload started.
+	Revision int64
+	// Parts is the number of staged parts.
+	Parts uint32
+	// Size is the total size of the staged parts.
+	Size int64
+}
+
+// UnsafeCreateValuePartBucket creates the bucket staging the parts of the
+// values put in several requests.
+func UnsafeCreateValuePartBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(ValuePart)
+}
+
+func valueUploadKey(upload int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(upload))
+	return k
+}
+
+func valuePartKey(upload int64, index uint32) []byte {
+	k := make([]byte, 12)
+	binary.BigEndian.PutUint64(k, uint64(upload))
+	binary.BigEndian.PutUint32(k[8:], index)
+	return k
+}
+
+// UnsafeReadValueUpload returns the upload, nil if it does not exist.
+func UnsafeReadValueUpload(tx backend.ReadTx, upload int64) (*ValueUpload, error) {
+	_, vs := tx.UnsafeRange(ValuePart, valueUploadKey(upload), nil, 1)
+	if len(vs) == 0 {
+		return nil, nil
+	}
+	v := vs[0]
+	if len(v) < valueUploadHeaderLen {
+		return nil, fmt.Errorf("invalid value upload %x: %x", upload, v)
+	}
+	return &ValueUpload{
+		Revision: int64(binary.BigEndian.Uint64(v)),
+		Parts:    binary.BigEndian.Uint32(v[8:]),
+		Size:     int64(binary.BigEndian.Uint64(v[12:])),
+		Key:      append([]byte(nil), v[valueUploadHeaderLen:]...),
+	}, nil
+}
+
+// UnsafePutValuePart stages the part as the next one of the upload.
+func UnsafePutValuePart(tx backend.BatchTx, upload int64, u *ValueUpload, part []byte) {
+	tx.UnsafePut(ValuePart, valuePartKey(upload, u.Parts), part)
+	u.Parts++
+	u.Size += int64(len(part))
+	v := make([]byte, valueUploadHeaderLen, valueUploadHeaderLen+len(u.Key))
+	binary.BigEndian.PutUint64(v, uint64(u.Revision))
+	binary.BigEndian.PutUint32(v[8:], u.Parts)
+	binary.BigEndian.PutUint64(v[12:], uint64(u.Size))
+	tx.UnsafePut(ValuePart, valueUploadKey(upload), append(v, u.Key...))
+}
+
+// UnsafeRangeValueParts returns the staged parts of the upload, in order.
+func UnsafeRangeValueParts(tx backend.ReadTx, upload int64) [][]byte {
+	_, vs := tx.UnsafeRange(ValuePart, valuePartKey(upload, 0), valuePartKey(upload, math.MaxUint32), 0)
+	return vs
+}
+
+// UnsafeDeleteValueUpload removes the upload and its staged parts.
+func UnsafeDeleteValueUpload(tx backend.BatchTx, upload int64, u *ValueUpload) {
+	for i := uint32(0); i < u.Parts; i++ {
+		tx.UnsafeDelete(ValuePart, valuePartKey(upload, i))
+	}
+	tx.UnsafeDelete(ValuePart, valueUploadKey(upload))
+}
+
+// UnsafeDeleteValueUploadsBefore removes the uploads started before the
+// revision, with their staged parts, and returns their number.
+func UnsafeDeleteValueUploadsBefore(tx backend.BatchTx, rev int64) (int, error) {
+	stale := map[int64]*ValueUpload{}
+	err := tx.UnsafeForEach(ValuePart, func(k, v []byte) error {
+		if len(k) != 8 {
+			return nil
+		}
+		if len(v) < valueUploadHeaderLen {
+			return fmt.Errorf("invalid value upload %x: %x", k, v)
+		}
+		if r := int64(binary.BigEndian.Uint64(v)); r < rev {
+			stale[int64(binary.BigEndian.Uint64(k))] = &ValueUpload{Parts: binary.BigEndian.Uint32(v[8:])}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for upload, u := range stale {
+		UnsafeDeleteValueUpload(tx, upload, u)
+	}
+	return len(stale), nil
+}
+
+// valueUploadCounterKey holds the last upload started. Its length tells it
+// apart from the uploads and their parts.
+var valueUploadCounterKey = []byte("upload")
+
+// UnsafeNextValueUpload returns a new upload, greater than all the uploads
+// started before.
+func UnsafeNextValueUpload(tx backend.BatchTx) int64 {
+	var upload int64
+	_, vs := tx.UnsafeRange(ValuePart, valueUploadCounterKey, nil, 1)
+	if len(vs) == 1 && len(vs[0]) == 8 {
+		upload = int64(binary.BigEndian.Uint64(vs[0]))
+	}
+	upload++
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, uint64(upload))
+	tx.UnsafePut(ValuePart, valueUploadCounterKey, v)
+	return upload
+}
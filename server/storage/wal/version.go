@@ -137,8 +137,9 @@ func visitEntryData(entryType raftpb.EntryType, data []byte, visitor Visitor) er
 		if err != nil {
 			return nil
 		}
+		// the members propose EntryConfChangeV2 since 3.6 to replace a member.
 		msg = proto.MessageReflect(&confChange)
-		return visitor(msg.Descriptor().FullName(), &version.V3_4)
+		return visitor(msg.Descriptor().FullName(), &version.V3_6)
 	default:
 		panic("unhandled")
 	}
@@ -79,14 +79,14 @@ func TestEtcdVersionFromEntry(t *testing.T) {
 			expect: &version.V3_0,
 		},
 		{
-			name: "Using ConfigChangeV2 implies v3.4",
+			name: "Using ConfigChangeV2 implies v3.6",
 			input: raftpb.Entry{
 				Term:  1,
 				Index: 2,
 				Type:  raftpb.EntryConfChangeV2,
 				Data:  confChangeV2Data,
 			},
-			expect: &version.V3_4,
+			expect: &version.V3_6,
 		},
 	}
 	for _, tc := range tcs {
@@ -138,6 +138,16 @@ func TestEtcdVersionFromMessage(t *testing.T) {
 			input:  &etcdserverpb.InternalRaftRequest{DowngradeInfoSet: &membershippb.DowngradeInfoSetRequest{}},
 			expect: &version.V3_5,
 		},
+		{
+			name:   "Setting a PrefixQuotaRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{PrefixQuota: &etcdserverpb.PrefixQuotaRequest{Prefix: []byte("tenant/")}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting a RevisionPinRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{RevisionPin: &etcdserverpb.RevisionPinRequest{Owner: "backup"}},
+			expect: &version.V3_6,
+		},
 		{
 			name:   "Enum CompareResult set to EQUAL implies v3.0",
 			input:  &etcdserverpb.Compare{Result: etcdserverpb.Compare_EQUAL},
@@ -28,7 +28,6 @@ import (
 	"go.uber.org/zap"
 
 	"go.etcd.io/etcd/api/v3/version"
-	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/tests/v3/framework/e2e"
 	"go.etcd.io/etcd/tests/v3/framework/testutils"
@@ -45,9 +44,7 @@ func TestDowngradeUpgradeClusterOf3(t *testing.T) {
 func testDowngradeUpgrade(t *testing.T, clusterSize int) {
 	currentEtcdBinary := e2e.BinPath.Etcd
 	lastReleaseBinary := e2e.BinPath.EtcdLastRelease
-	if !fileutil.Exist(lastReleaseBinary) {
-		t.Skipf("%q does not exist", lastReleaseBinary)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	currentVersion, err := getVersionFromBinary(currentEtcdBinary)
 	require.NoError(t, err)
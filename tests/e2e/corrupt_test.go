@@ -17,6 +17,7 @@ package e2e
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,9 +57,8 @@ func corruptTest(cx ctlCtx) {
 			}
 		}
 	}
-	// enough time for all nodes sync on the same data
-	cx.t.Log("sleeping 3sec to let nodes sync...")
-	time.Sleep(3 * time.Second)
+	cx.t.Log("waiting for all nodes to sync...")
+	cx.epc.WaitFor(cx.t, context.TODO(), e2e.StatusPerMember.SameRevision, 10*time.Second)
 
 	cx.t.Log("connecting clientv3...")
 	eps := cx.epc.EndpointsV3()
@@ -139,7 +139,7 @@ func TestPeriodicCheckDetectsCorruption(t *testing.T) {
 
 	err = epc.Procs[0].Restart(context.TODO())
 	assert.NoError(t, err)
-	time.Sleep(checkTime * 11 / 10)
+	epc.WaitFor(t, ctx, corruptAlarmRaised, 10*checkTime)
 	alarmResponse, err := cc.AlarmList(ctx)
 	assert.NoError(t, err, "error on alarm list")
 	assert.Equal(t, []*etcdserverpb.AlarmMember{{Alarm: etcdserverpb.AlarmType_CORRUPT, MemberID: memberID}}, alarmResponse.Alarms)
@@ -188,8 +188,20 @@ func TestCompactHashCheckDetectCorruption(t *testing.T) {
 	assert.NoError(t, err)
 	_, err = cc.Compact(ctx, 5, config.CompactOption{})
 	assert.NoError(t, err)
-	time.Sleep(checkTime * 11 / 10)
+	epc.WaitFor(t, ctx, corruptAlarmRaised, 10*checkTime)
 	alarmResponse, err := cc.AlarmList(ctx)
 	assert.NoError(t, err, "error on alarm list")
 	assert.Equal(t, []*etcdserverpb.AlarmMember{{Alarm: etcdserverpb.AlarmType_CORRUPT, MemberID: memberID}}, alarmResponse.Alarms)
 }
+
+// corruptAlarmRaised returns true when a member reports the CORRUPT alarm.
+func corruptAlarmRaised(s e2e.StatusPerMember) bool {
+	return s.Any(func(st *clientv3.StatusResponse) bool {
+		for _, e := range st.Errors {
+			if strings.Contains(e, etcdserverpb.AlarmType_CORRUPT.String()) {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3EndpointHashKVCluster(t *testing.T) {
+	testCtl(t, endpointHashKVClusterTest, withQuorum())
+}
+
+func endpointHashKVClusterTest(cx ctlCtx) {
+	maintenanceInitKeys(cx)
+
+	// the command exits with an error unless the hashes of all the members
+	// match at their lowest common revision.
+	cmdArgs := append(cx.PrefixArgs(), "endpoint", "hashkv", "--cluster")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "all 3 endpoints match at revision"); err != nil {
+		cx.t.Fatalf("endpointHashKVClusterTest error (%v)", err)
+	}
+}
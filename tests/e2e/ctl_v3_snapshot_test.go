@@ -129,8 +129,11 @@ func snapshotStatusBeforeRestoreTest(cx ctlCtx) {
 }
 
 func ctlV3SnapshotSave(cx ctlCtx, fpath string) error {
+	endPhase := e2e.PhaseReportFor(cx.t).StartPhase("snapshot save", "")
 	cmdArgs := append(cx.PrefixArgs(), "snapshot", "save", fpath)
-	return e2e.SpawnWithExpectWithEnv(cmdArgs, cx.envMap, fmt.Sprintf("Snapshot saved at %s", fpath))
+	err := e2e.SpawnWithExpectWithEnv(cmdArgs, cx.envMap, fmt.Sprintf("Snapshot saved at %s", fpath))
+	endPhase(err)
+	return err
 }
 
 func getSnapshotStatus(cx ctlCtx, fpath string) (snapshot.Status, error) {
@@ -22,7 +22,6 @@ import (
 	"testing"
 	"time"
 
-	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/testutil"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/v2"
@@ -38,9 +37,7 @@ func TestTLSClusterOf3UsingDiscovery(t *testing.T) { testClusterUsingDiscovery(t
 func testClusterUsingDiscovery(t *testing.T, size int, peerTLS bool) {
 	e2e.BeforeTest(t)
 
-	if !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-		t.Skipf("%q does not exist", e2e.BinPath.EtcdLastRelease)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	dc, err := e2e.NewEtcdProcessCluster(context.TODO(), t,
 		e2e.WithBasePort(2000),
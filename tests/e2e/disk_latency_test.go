@@ -0,0 +1,53 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/tests/v3/framework/config"
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+// TestDiskLatencyWithinThresholds ensures that the p99 latencies of the WAL
+// fsyncs and of the backend commits of a write workload stay below the
+// thresholds given by the -wal-fsync-p99-threshold and
+// -backend-commit-p99-threshold flags.
+func TestDiskLatencyWithinThresholds(t *testing.T) {
+	e2e.BeforeTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	epc, err := e2e.NewEtcdProcessCluster(ctx, t, e2e.WithClusterSize(3))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if errC := epc.Close(); errC != nil {
+			t.Fatalf("error closing etcd processes (%v)", errC)
+		}
+	})
+
+	check, err := e2e.NewDiskLatencyCheck(ctx, epc)
+	require.NoError(t, err)
+
+	cc := epc.Client()
+	for i := 0; i < 200; i++ {
+		require.NoError(t, cc.Put(ctx, fmt.Sprintf("key-%d", i), fmt.Sprint(i), config.PutOptions{}))
+	}
+	require.NoError(t, check.Verify(ctx, e2e.DefaultDiskLatencyThresholds))
+}
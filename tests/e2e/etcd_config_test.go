@@ -18,7 +18,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -49,6 +51,93 @@ func TestEtcdExampleConfig(t *testing.T) {
 	}
 }
 
+func TestEtcdConfigFileReconfigure(t *testing.T) {
+	e2e.SkipInShortMode(t)
+
+	configFile := filepath.Join(t.TempDir(), "etcd.yaml")
+	configTemplate := `name: e0
+data-dir: %s
+listen-client-urls: http://127.0.0.1:%d
+advertise-client-urls: http://127.0.0.1:%d
+listen-peer-urls: http://127.0.0.1:%d
+initial-advertise-peer-urls: http://127.0.0.1:%d
+initial-cluster: e0=http://127.0.0.1:%d
+log-level: %s
+quota-backend-bytes: %d
+`
+	writeConfig := func(level string, quota int64) {
+		port := e2e.EtcdProcessBasePort
+		cfg := fmt.Sprintf(configTemplate, t.TempDir(), port, port, port+1, port+1, port+1, level, quota)
+		require.NoError(t, os.WriteFile(configFile, []byte(cfg), 0600))
+	}
+	writeConfig("info", 0)
+
+	proc, err := e2e.SpawnCmd([]string{e2e.BinPath.Etcd, "--config-file", configFile}, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, proc.Stop())
+		proc.Close()
+	}()
+	require.NoError(t, e2e.WaitReadyExpectProc(context.TODO(), proc, e2e.EtcdServerReadyLines))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	writeConfig("debug", 64*1024*1024)
+	require.NoError(t, proc.Signal(syscall.SIGHUP))
+	_, err = proc.ExpectWithContext(ctx, "reconfigured server from config file")
+	require.NoError(t, err)
+
+	// an invalid config is rejected, the server keeping its config
+	writeConfig("bogus", 64*1024*1024)
+	require.NoError(t, proc.Signal(syscall.SIGHUP))
+	_, err = proc.ExpectWithContext(ctx, "failed to reconfigure server")
+	require.NoError(t, err)
+}
+
+func TestEtcdValidateConfig(t *testing.T) {
+	e2e.SkipInShortMode(t)
+
+	configFile := filepath.Join(t.TempDir(), "etcd.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`name: e0
+listen-client-urls: https://127.0.0.1:2379
+advertise-client-urls: https://127.0.0.1:2379
+initial-cluster: e1=http://127.0.0.1:2380
+`), 0600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expect   []string
+		exitCode int
+	}{
+		{
+			name:   "valid",
+			args:   []string{"--name", "e0", "--initial-cluster", "e0=http://localhost:2380"},
+			expect: []string{`"valid": true`},
+		},
+		{
+			name:     "invalid",
+			args:     []string{"--config-file", configFile},
+			expect:   []string{`"valid": false`, `"listen-client-urls"`, `has no member named --name \"e0\"`},
+			exitCode: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			proc, err := e2e.SpawnCmd(append([]string{e2e.BinPath.Etcd, "--validate-config"}, tc.args...), nil)
+			require.NoError(t, err)
+			for _, s := range tc.expect {
+				_, err = proc.Expect(s)
+				assert.NoError(t, err)
+			}
+			proc.Close()
+			exitCode, err := proc.ExitCode()
+			require.NoError(t, err)
+			assert.Equal(t, tc.exitCode, exitCode)
+		})
+	}
+}
+
 func TestEtcdMultiPeer(t *testing.T) {
 	e2e.SkipInShortMode(t)
 
@@ -16,7 +16,11 @@ package e2e
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -24,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/expect"
 	"go.etcd.io/etcd/tests/v3/framework/config"
@@ -90,6 +95,128 @@ func TestGrpcProxyAutoSync(t *testing.T) {
 	assert.Equal(t, []testutils.KV{{Key: "k1", Val: "v1"}}, kvs)
 }
 
+func TestGrpcProxyBackendUser(t *testing.T) {
+	e2e.SkipInShortMode(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	epc, err := e2e.NewEtcdProcessCluster(ctx, t, e2e.WithClusterSize(1))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, epc.Close())
+	}()
+
+	epcClient := epc.Client()
+	createUsers(ctx, t, epcClient)
+	require.NoError(t, epcClient.AuthEnable(ctx))
+
+	var (
+		node1ClientURL = epc.Procs[0].Config().ClientURL
+		proxyClientURL = "127.0.0.1:32379"
+	)
+
+	// Run a grpc-proxy terminating the TLS of its clients, and authenticating
+	// with etcd as the test user.
+	proxyProc, err := e2e.SpawnCmd([]string{e2e.BinPath.Etcd, "grpc-proxy", "start",
+		"--advertise-client-url", proxyClientURL, "--listen-addr", proxyClientURL,
+		"--endpoints", node1ClientURL,
+		"--cert-file", e2e.CertPath, "--key-file", e2e.PrivateKeyPath, "--trusted-ca-file", e2e.CaPath,
+		"--user", "test:testPassword",
+	}, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, proxyProc.Stop())
+	}()
+
+	proxyCtl, err := e2e.NewEtcdctl(e2e.ClientConfig{ConnectionType: e2e.ClientTLS}, []string{proxyClientURL})
+	require.NoError(t, err)
+
+	var resp *clientv3.GetResponse
+	for i := 0; i < 10; i++ {
+		if err = proxyCtl.Put(ctx, "/test/k1", "v1", config.PutOptions{}); err == nil {
+			break
+		}
+		// the proxy may not be serving yet
+		time.Sleep(500 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	resp, err = proxyCtl.Get(ctx, "/test/k1", config.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []testutils.KV{{Key: "/test/k1", Val: "v1"}}, testutils.KeyValuesFromGetResponse(resp))
+
+	// the requests are limited to the permissions of the proxy user
+	err = proxyCtl.Put(ctx, "k2", "v2", config.PutOptions{})
+	require.ErrorContains(t, err, rpctypes.ErrPermissionDenied.Error())
+}
+
+func TestGrpcProxyReload(t *testing.T) {
+	e2e.SkipInShortMode(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	epc, err := e2e.NewEtcdProcessCluster(ctx, t, e2e.WithClusterSize(1))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, epc.Close())
+	}()
+
+	var (
+		node1ClientURL = epc.Procs[0].Config().ClientURL
+		proxyClientURL = "127.0.0.1:32379"
+		configFile     = filepath.Join(t.TempDir(), "grpc-proxy.yaml")
+	)
+	require.NoError(t, os.WriteFile(configFile, []byte("experimental-client-namespace: []\n"), 0600))
+
+	proxyProc, err := e2e.SpawnCmd([]string{e2e.BinPath.Etcd, "grpc-proxy", "start",
+		"--advertise-client-url", proxyClientURL, "--listen-addr", proxyClientURL,
+		"--endpoints", node1ClientURL,
+		"--cert-file", e2e.CertPath, "--key-file", e2e.PrivateKeyPath, "--trusted-ca-file", e2e.CaPath,
+		"--experimental-config-file", configFile,
+	}, nil)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, proxyProc.Stop())
+	}()
+
+	proxyCtl, err := e2e.NewEtcdctl(e2e.ClientConfig{ConnectionType: e2e.ClientTLS}, []string{proxyClientURL})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		if err = proxyCtl.Put(ctx, "k1", "v1", config.PutOptions{}); err == nil {
+			break
+		}
+		// the proxy may not be serving yet
+		time.Sleep(500 * time.Millisecond)
+	}
+	require.NoError(t, err)
+
+	// the clients with the certificate of the fixtures are namespaced after
+	// the reload on SIGHUP
+	require.NoError(t, os.WriteFile(configFile, []byte("experimental-client-namespace: [\"cn:example.com=/ns/\"]\n"), 0600))
+	require.NoError(t, proxyProc.Signal(syscall.SIGHUP))
+	_, err = proxyProc.ExpectWithContext(ctx, "reloaded gRPC proxy config file")
+	require.NoError(t, err)
+	require.NoError(t, proxyCtl.Put(ctx, "k2", "v2", config.PutOptions{}))
+
+	resp, err := epc.Client().Get(ctx, "", config.GetOptions{Prefix: true})
+	require.NoError(t, err)
+	assert.Equal(t, []testutils.KV{{Key: "/ns/k2", Val: "v2"}, {Key: "k1", Val: "v1"}}, testutils.KeyValuesFromGetResponse(resp))
+
+	// an invalid config file is rejected, the proxy keeping its config
+	require.NoError(t, os.WriteFile(configFile, []byte("experimental-max-concurrent-requests: -1\n"), 0600))
+	tlsInfo := transport.TLSInfo{CertFile: e2e.CertPath, KeyFile: e2e.PrivateKeyPath, TrustedCAFile: e2e.CaPath}
+	tlsConfig, err := tlsInfo.ClientConfig()
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	hresp, err := httpClient.Post("https://"+proxyClientURL+"/proxy/reload", "", nil)
+	require.NoError(t, err)
+	hresp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, hresp.StatusCode)
+
+	presp, err := proxyCtl.Get(ctx, "k2", config.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []testutils.KV{{Key: "k2", Val: "v2"}}, testutils.KeyValuesFromGetResponse(presp))
+}
+
 func waitForEndpointInLog(ctx context.Context, proxyProc *expect.ExpectProcess, endpoint string) error {
 	endpoint = strings.Replace(endpoint, "http://", "", 1)
 
@@ -23,7 +23,6 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/tests/v3/framework/config"
 	"go.etcd.io/etcd/tests/v3/framework/e2e"
 )
@@ -62,9 +61,7 @@ func TestMixVersionsSnapshotByAddingMember(t *testing.T) {
 func mixVersionsSnapshotTestByAddingMember(t *testing.T, clusterVersion, newInstanceVersion e2e.ClusterVersion) {
 	e2e.BeforeTest(t)
 
-	if !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-		t.Skipf("%q does not exist", e2e.BinPath.EtcdLastRelease)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	// Create an etcd cluster with 1 member
 	epc, err := e2e.NewEtcdProcessCluster(context.TODO(), t,
@@ -98,26 +95,11 @@ func mixVersionsSnapshotTestByAddingMember(t *testing.T, clusterVersion, newInst
 
 	// verify all nodes have exact same revision and hash
 	t.Log("Verify all nodes have exact same revision and hash")
-	assert.Eventually(t, func() bool {
-		hashKvs, err := epc.Client().HashKV(context.TODO(), 0)
-		if err != nil {
-			t.Logf("failed to get HashKV: %v", err)
-			return false
-		}
-		if len(hashKvs) != 2 {
-			t.Logf("expected 2 hashkv responses, but got: %d", len(hashKvs))
-			return false
-		}
-
-		if hashKvs[0].Header.Revision != hashKvs[1].Header.Revision {
-			t.Logf("Got different revisions, [%d, %d]", hashKvs[0].Header.Revision, hashKvs[1].Header.Revision)
-			return false
-		}
-
-		assert.Equal(t, hashKvs[0].Hash, hashKvs[1].Hash)
-
-		return true
-	}, 10*time.Second, 500*time.Millisecond)
+	epc.WaitFor(t, context.TODO(), e2e.StatusPerMember.SameRevision, 10*time.Second)
+	hashKvs, err := epc.Client().HashKV(context.TODO(), 0)
+	require.NoError(t, err)
+	require.Len(t, hashKvs, 2)
+	assert.Equal(t, hashKvs[0].Hash, hashKvs[1].Hash)
 }
 
 func TestMixVersionsSnapshotByMockingPartition(t *testing.T) {
@@ -143,9 +125,7 @@ func TestMixVersionsSnapshotByMockingPartition(t *testing.T) {
 func mixVersionsSnapshotTestByMockPartition(t *testing.T, clusterVersion e2e.ClusterVersion, mockPartitionNodeIndex int) {
 	e2e.BeforeTest(t)
 
-	if !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-		t.Skipf("%q does not exist", e2e.BinPath.EtcdLastRelease)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	// Create an etcd cluster with 3 member of MinorityLastVersion
 	epc, err := e2e.NewEtcdProcessCluster(context.TODO(), t,
@@ -184,31 +164,12 @@ func mixVersionsSnapshotTestByMockPartition(t *testing.T, clusterVersion e2e.Clu
 
 	// verify all nodes have exact same revision and hash
 	t.Log("Verify all nodes have exact same revision and hash")
-	assert.Eventually(t, func() bool {
-		hashKvs, err := epc.Client().HashKV(context.TODO(), 0)
-		if err != nil {
-			t.Logf("failed to get HashKV: %v", err)
-			return false
-		}
-		if len(hashKvs) != 3 {
-			t.Logf("expected 3 hashkv responses, but got: %d", len(hashKvs))
-			return false
-		}
-
-		if hashKvs[0].Header.Revision != hashKvs[1].Header.Revision {
-			t.Logf("Got different revisions, [%d, %d]", hashKvs[0].Header.Revision, hashKvs[1].Header.Revision)
-			return false
-		}
-		if hashKvs[1].Header.Revision != hashKvs[2].Header.Revision {
-			t.Logf("Got different revisions, [%d, %d]", hashKvs[1].Header.Revision, hashKvs[2].Header.Revision)
-			return false
-		}
-
-		assert.Equal(t, hashKvs[0].Hash, hashKvs[1].Hash)
-		assert.Equal(t, hashKvs[1].Hash, hashKvs[2].Hash)
-
-		return true
-	}, 10*time.Second, 500*time.Millisecond)
+	epc.WaitFor(t, context.TODO(), e2e.StatusPerMember.SameRevision, 10*time.Second)
+	hashKvs, err := epc.Client().HashKV(context.TODO(), 0)
+	require.NoError(t, err)
+	require.Len(t, hashKvs, 3)
+	assert.Equal(t, hashKvs[0].Hash, hashKvs[1].Hash)
+	assert.Equal(t, hashKvs[1].Hash, hashKvs[2].Hash)
 
 	// assert process logs to check snapshot be sent
 	t.Log("Verify logs to check snapshot be sent from leader to follower")
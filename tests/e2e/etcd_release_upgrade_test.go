@@ -21,17 +21,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coreos/go-semver/semver"
+
 	"go.etcd.io/etcd/api/v3/version"
-	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/tests/v3/framework/e2e"
 )
 
 // TestReleaseUpgrade ensures that changes to master branch does not affect
 // upgrade from latest etcd releases.
 func TestReleaseUpgrade(t *testing.T) {
-	if !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-		t.Skipf("%q does not exist", e2e.BinPath.EtcdLastRelease)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	e2e.BeforeTest(t)
 
@@ -68,12 +68,23 @@ func TestReleaseUpgrade(t *testing.T) {
 
 	t.Log("Cluster of etcd in old version running")
 
+	wc, err := e2e.NewWatchContinuityCheck(context.TODO(), epc, "watch/")
+	if err != nil {
+		t.Fatalf("could not open watches (%v)", err)
+	}
+	defer wc.Close()
+
 	for i := range epc.Procs {
 		t.Logf("Stopping node: %v", i)
 		if err := epc.Procs[i].Stop(); err != nil {
 			t.Fatalf("#%d: error closing etcd process (%v)", i, err)
 		}
 		t.Logf("Stopped node: %v", i)
+		for j := 0; j < 3; j++ {
+			if err := wc.Put(context.TODO(), fmt.Sprintf("watch/%d-%d", i, j), "bar"); err != nil {
+				t.Fatalf("#%d-%d: put error (%v)", i, j, err)
+			}
+		}
 		epc.Procs[i].Config().ExecPath = e2e.BinPath.Etcd
 		epc.Procs[i].Config().KeepDataDir = true
 
@@ -91,29 +102,31 @@ func TestReleaseUpgrade(t *testing.T) {
 		t.Logf("Tested reads after node restarts: %v", i)
 	}
 
+	t.Log("Validating watch continuity...")
+	if err := wc.Verify(context.TODO(), 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
 	t.Log("Waiting for full upgrade...")
 	// TODO: update after release candidate
 	// expect upgraded cluster version
 	// new cluster version needs more time to upgrade
 	ver := version.Cluster(version.Version)
-	for i := 0; i < 7; i++ {
-		if err = e2e.CURLGet(epc, e2e.CURLReq{Endpoint: "/version", Expected: `"etcdcluster":"` + ver}); err != nil {
-			t.Logf("#%d: %v is not ready yet (%v)", i, ver, err)
-			time.Sleep(time.Second)
-			continue
-		}
-		break
-	}
-	if err != nil {
+	sv := semver.Must(semver.NewVersion(version.Version))
+	storageVer := semver.Version{Major: sv.Major, Minor: sv.Minor}.String()
+	epc.WaitFor(t, context.TODO(), func(s e2e.StatusPerMember) bool {
+		return s.All(func(st *clientv3.StatusResponse) bool {
+			return st.StorageVersion == storageVer
+		})
+	}, 10*time.Second)
+	if err = e2e.CURLGet(epc, e2e.CURLReq{Endpoint: "/version", Expected: `"etcdcluster":"` + ver}); err != nil {
 		t.Fatalf("cluster version is not upgraded (%v)", err)
 	}
 	t.Log("TestReleaseUpgrade businessLogic DONE")
 }
 
 func TestReleaseUpgradeWithRestart(t *testing.T) {
-	if !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-		t.Skipf("%q does not exist", e2e.BinPath.EtcdLastRelease)
-	}
+	e2e.EnsureLastReleaseBinary(t)
 
 	e2e.BeforeTest(t)
 
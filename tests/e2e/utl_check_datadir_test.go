@@ -0,0 +1,67 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/tests/v3/framework/config"
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestEtcdutlCheckDataDir(t *testing.T) {
+	e2e.BeforeTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	epc, err := e2e.NewEtcdProcessCluster(ctx, t,
+		e2e.WithClusterSize(1),
+		e2e.WithKeepDataDir(true),
+		e2e.WithSnapshotCount(5),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if errC := epc.Close(); errC != nil {
+			t.Fatalf("error closing etcd processes (%v)", errC)
+		}
+	})
+
+	cc := epc.Client()
+	for i := 0; i < 20; i++ {
+		require.NoError(t, cc.Put(ctx, fmt.Sprintf("key-%d", i), fmt.Sprint(i), config.PutOptions{}))
+	}
+	require.NoError(t, epc.Procs[0].Stop())
+	dataDir := epc.Procs[0].Config().DataDirPath
+
+	err = e2e.SpawnWithExpect([]string{e2e.BinPath.Etcdutl, "check", "datadir", dataDir}, "would start cleanly: true")
+	assert.NoError(t, err)
+
+	// corrupt the checksummed pgid field of the first meta page of the db file
+	f, err := os.OpenFile(datadir.ToBackendFileName(dataDir), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff, 0xff}, 16+40)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	err = e2e.SpawnWithExpect([]string{e2e.BinPath.Etcdutl, "check", "datadir", dataDir}, "would start cleanly: false")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/tests/v3/framework/config"
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestEtcdutlHashKVMatchesServer(t *testing.T) {
+	e2e.BeforeTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	epc, err := e2e.NewEtcdProcessCluster(ctx, t,
+		e2e.WithClusterSize(1),
+		e2e.WithKeepDataDir(true),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if errC := epc.Close(); errC != nil {
+			t.Fatalf("error closing etcd processes (%v)", errC)
+		}
+	})
+
+	cc := epc.Client()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cc.Put(ctx, fmt.Sprintf("key-%d", i%3), fmt.Sprint(i), config.PutOptions{}))
+	}
+	_, err = cc.Compact(ctx, 5, config.CompactOption{Physical: true})
+	require.NoError(t, err)
+
+	const hashRev = 8
+	serverHashes, err := cc.HashKV(ctx, hashRev)
+	require.NoError(t, err)
+	require.Len(t, serverHashes, 1)
+
+	require.NoError(t, epc.Procs[0].Stop())
+	dbPath := datadir.ToBackendFileName(epc.Procs[0].Config().DataDirPath)
+	err = e2e.SpawnWithExpect([]string{e2e.BinPath.Etcdutl, "hashkv", dbPath, "--rev", fmt.Sprint(hashRev)},
+		fmt.Sprintf("%d, %d, %d", serverHashes[0].Hash, hashRev, serverHashes[0].CompactRevision))
+	assert.NoError(t, err)
+}
@@ -28,15 +28,12 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	"go.etcd.io/etcd/api/v3/version"
-	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 	"go.etcd.io/etcd/tests/v3/framework/e2e"
 )
 
 func TestEtctlutlMigrate(t *testing.T) {
-	lastReleaseBinary := e2e.BinPath.EtcdLastRelease
-
 	tcs := []struct {
 		name           string
 		targetVersion  string
@@ -112,8 +109,8 @@ func TestEtctlutlMigrate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			e2e.BeforeTest(t)
 			lg := zaptest.NewLogger(t)
-			if tc.clusterVersion != e2e.CurrentVersion && !fileutil.Exist(e2e.BinPath.EtcdLastRelease) {
-				t.Skipf("%q does not exist", lastReleaseBinary)
+			if tc.clusterVersion != e2e.CurrentVersion {
+				e2e.EnsureLastReleaseBinary(t)
 			}
 			dataDirPath := t.TempDir()
 
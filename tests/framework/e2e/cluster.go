@@ -127,6 +127,7 @@ type EtcdProcessCluster struct {
 	Cfg     *EtcdProcessClusterConfig
 	Procs   []EtcdProcess
 	nextSeq int // sequence number of the next etcd process (if it will be required)
+	phases  *PhaseReport
 }
 
 type EtcdProcessClusterConfig struct {
@@ -378,6 +379,7 @@ func InitEtcdProcessCluster(t testing.TB, cfg *EtcdProcessClusterConfig) (*EtcdP
 		lg:      zaptest.NewLogger(t),
 		Procs:   make([]EtcdProcess, cfg.ClusterSize),
 		nextSeq: cfg.ClusterSize,
+		phases:  PhaseReportFor(t),
 	}
 
 	// launch etcd processes
@@ -634,6 +636,7 @@ func (cfg *EtcdProcessClusterConfig) EtcdServerProcessConfig(tb testing.TB, i in
 		InitialToken: cfg.InitialToken,
 		GoFailPort:   gofailPort,
 		Proxy:        proxyCfg,
+		phases:       PhaseReportFor(tb),
 	}
 }
 
@@ -790,15 +793,21 @@ func (epc *EtcdProcessCluster) StartNewProc(ctx context.Context, cfg *EtcdProces
 	return proc.Start(ctx)
 }
 
-func (epc *EtcdProcessCluster) Start(ctx context.Context) error {
+func (epc *EtcdProcessCluster) Start(ctx context.Context) (err error) {
+	endPhase := epc.phases.StartPhase("cluster start", "")
+	defer func() { endPhase(err) }()
 	return epc.start(func(ep EtcdProcess) error { return ep.Start(ctx) })
 }
 
-func (epc *EtcdProcessCluster) RollingStart(ctx context.Context) error {
+func (epc *EtcdProcessCluster) RollingStart(ctx context.Context) (err error) {
+	endPhase := epc.phases.StartPhase("cluster rolling start", "")
+	defer func() { endPhase(err) }()
 	return epc.rollingStart(func(ep EtcdProcess) error { return ep.Start(ctx) })
 }
 
-func (epc *EtcdProcessCluster) Restart(ctx context.Context) error {
+func (epc *EtcdProcessCluster) Restart(ctx context.Context) (err error) {
+	endPhase := epc.phases.StartPhase("cluster restart", "")
+	defer func() { endPhase(err) }()
 	return epc.start(func(ep EtcdProcess) error { return ep.Restart(ctx) })
 }
 
@@ -833,6 +842,8 @@ func (epc *EtcdProcessCluster) rollingStart(f func(ep EtcdProcess) error) error
 }
 
 func (epc *EtcdProcessCluster) Stop() (err error) {
+	endPhase := epc.phases.StartPhase("cluster stop", "")
+	defer func() { endPhase(err) }()
 	errCh := make(chan error, len(epc.Procs))
 	for i := range epc.Procs {
 		if epc.Procs[i] == nil {
@@ -901,6 +912,7 @@ func (epc *EtcdProcessCluster) WaitLeader(t testing.TB) int {
 // WaitMembersForLeader waits until given members agree on the same leader,
 // and returns its 'index' in the 'membs' list
 func (epc *EtcdProcessCluster) WaitMembersForLeader(ctx context.Context, t testing.TB, membs []EtcdProcess) int {
+	defer PhaseReportFor(t).StartPhase("wait leader", "")(nil)
 	cc := epc.Client()
 
 	// ensure leader is up via linearizable get
@@ -95,6 +95,9 @@ type EtcdServerProcessConfig struct {
 	GoFailPort     int
 
 	Proxy *proxy.ServerConfig
+
+	// phases records the start, restart and stop timings of the process.
+	phases *PhaseReport
 }
 
 func NewEtcdServerProcess(cfg *EtcdServerProcessConfig) (*EtcdServerProcess, error) {
@@ -125,7 +128,9 @@ func (epc *EtcdServerProcess) Client(opts ...config.ClientOption) *EtcdctlV3 {
 	return etcdctl
 }
 
-func (ep *EtcdServerProcess) Start(ctx context.Context) error {
+func (ep *EtcdServerProcess) Start(ctx context.Context) (err error) {
+	endPhase := ep.cfg.phases.StartPhase("member start", ep.cfg.Name)
+	defer func() { endPhase(err) }()
 	ep.donec = make(chan struct{})
 	if ep.proc != nil {
 		panic("already started")
@@ -152,12 +157,14 @@ func (ep *EtcdServerProcess) Start(ctx context.Context) error {
 	return err
 }
 
-func (ep *EtcdServerProcess) Restart(ctx context.Context) error {
+func (ep *EtcdServerProcess) Restart(ctx context.Context) (err error) {
+	endPhase := ep.cfg.phases.StartPhase("member restart", ep.cfg.Name)
+	defer func() { endPhase(err) }()
 	ep.cfg.lg.Info("restarting server...", zap.String("name", ep.cfg.Name))
-	if err := ep.Stop(); err != nil {
+	if err = ep.Stop(); err != nil {
 		return err
 	}
-	err := ep.Start(ctx)
+	err = ep.Start(ctx)
 	if err == nil {
 		ep.cfg.lg.Info("restarted server", zap.String("name", ep.cfg.Name))
 	}
@@ -169,8 +176,10 @@ func (ep *EtcdServerProcess) Stop() (err error) {
 	if ep == nil || ep.proc == nil {
 		return nil
 	}
+	endPhase := ep.cfg.phases.StartPhase("member stop", ep.cfg.Name)
 	defer func() {
 		ep.proc = nil
+		endPhase(err)
 	}()
 
 	err = ep.proc.Stop()
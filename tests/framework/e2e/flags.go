@@ -58,6 +58,9 @@ func InitFlags() {
 
 	binDir := flag.String("bin-dir", binDirDef, "The directory for store etcd and etcdctl binaries.")
 	flag.StringVar(&CertDir, "cert-dir", certDirDef, "The directory for store certificate files.")
+	flag.DurationVar(&DefaultDiskLatencyThresholds.WALFsyncP99, "wal-fsync-p99-threshold", DefaultDiskLatencyThresholds.WALFsyncP99, "The p99 WAL fsync duration above which disk latency checks fail (0 to disable).")
+	flag.DurationVar(&DefaultDiskLatencyThresholds.BackendCommitP99, "backend-commit-p99-threshold", DefaultDiskLatencyThresholds.BackendCommitP99, "The p99 backend commit duration above which disk latency checks fail (0 to disable).")
+	flag.StringVar(&PhaseReportDir, "phase-report-dir", "", "The directory to write the per-test reports of the framework phase timings to (empty to disable).")
 	flag.Parse()
 
 	BinPath = initBinPath(*binDir)
@@ -0,0 +1,178 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+// LastReleaseDownloadEnv is the environment variable opting in to download
+// the last release binary when BinPath.EtcdLastRelease is missing. Its value
+// is the release to download, for example "v3.5.9".
+const LastReleaseDownloadEnv = "ETCD_E2E_DOWNLOAD_LAST_RELEASE"
+
+var (
+	lastReleaseDownloadOnce sync.Once
+	lastReleaseDownloadErr  error
+)
+
+// EnsureLastReleaseBinary makes sure that BinPath.EtcdLastRelease exists.
+// If it is missing, the binary of the release set in LastReleaseDownloadEnv
+// is downloaded to BinPath.EtcdLastRelease, failing the test if that does
+// not work. Without LastReleaseDownloadEnv the test is skipped.
+func EnsureLastReleaseBinary(t testing.TB) {
+	t.Helper()
+	if fileutil.Exist(BinPath.EtcdLastRelease) {
+		return
+	}
+	version := os.Getenv(LastReleaseDownloadEnv)
+	if version == "" {
+		t.Skipf("%q does not exist, set %s to the last release version (e.g. v3.5.9) to download it", BinPath.EtcdLastRelease, LastReleaseDownloadEnv)
+	}
+	lastReleaseDownloadOnce.Do(func() {
+		lastReleaseDownloadErr = downloadReleaseBinary(version, BinPath.EtcdLastRelease)
+	})
+	if lastReleaseDownloadErr != nil {
+		t.Fatalf("failed to download etcd %s to %q: %v", version, BinPath.EtcdLastRelease, lastReleaseDownloadErr)
+	}
+}
+
+// releaseDownloadURL is the URL the release assets are downloaded from.
+var releaseDownloadURL = "https://github.com/etcd-io/etcd/releases/download"
+
+// downloadReleaseBinary downloads the etcd binary of the given release from
+// GitHub and writes it to dst. The release tarball is verified against the
+// SHA256SUMS published with the release before the binary is extracted.
+func downloadReleaseBinary(version, dst string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("downloading release binaries is not supported on %s", runtime.GOOS)
+	}
+	name := fmt.Sprintf("etcd-%s-linux-%s.tar.gz", version, runtime.GOARCH)
+	baseURL := fmt.Sprintf("%s/%s", releaseDownloadURL, version)
+
+	var sums bytes.Buffer
+	if err := fetch(baseURL+"/SHA256SUMS", &sums); err != nil {
+		return err
+	}
+	want, err := releaseChecksum(sums.Bytes(), name)
+	if err != nil {
+		return err
+	}
+
+	tarball, err := os.CreateTemp("", name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tarball.Name())
+	defer tarball.Close()
+	h := sha256.New()
+	url := baseURL + "/" + name
+	if err = fetch(url, io.MultiWriter(tarball, h)); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", url, got, want)
+	}
+	if _, err = tarball.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	gzr, err := gzip.NewReader(tarball)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("etcd binary not found in %s", url)
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == "etcd" {
+			return writeExecutable(tr, dst)
+		}
+	}
+}
+
+// fetch writes the content at url to w.
+func fetch(url string, w io.Writer) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %q fetching %s", resp.Status, url)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// releaseChecksum returns the hex encoded SHA-256 checksum of the file name
+// listed in the content of a SHA256SUMS file.
+func releaseChecksum(sums []byte, name string) (string, error) {
+	for _, line := range strings.Split(string(sums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			if _, err := hex.DecodeString(fields[0]); err != nil || len(fields[0]) != sha256.Size*2 {
+				return "", fmt.Errorf("invalid checksum %q of %s", fields[0], name)
+			}
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum of %s in SHA256SUMS", name)
+}
+
+// writeExecutable atomically writes the content of r to the executable file dst.
+func writeExecutable(r io.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Chmod(0755)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func releaseTarball(t *testing.T, name string, binary []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	gzw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gzw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name + "/etcd", Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(binary)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return b.Bytes()
+}
+
+func TestDownloadReleaseBinary(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("downloading release binaries is not supported on %s", runtime.GOOS)
+	}
+	const version = "v3.5.9"
+	name := fmt.Sprintf("etcd-%s-linux-%s", version, runtime.GOARCH)
+	tarball := releaseTarball(t, name, []byte("etcd binary"))
+	sum := sha256.Sum256(tarball)
+
+	tcs := []struct {
+		name      string
+		sums      string
+		expectErr string
+	}{
+		{
+			name: "matching checksum",
+			sums: fmt.Sprintf("%x  etcd-%s-darwin-amd64.zip\n%s  %s.tar.gz\n", sha256.Sum256(nil), version, hex.EncodeToString(sum[:]), name),
+		},
+		{
+			name:      "checksum mismatch",
+			sums:      fmt.Sprintf("%x  %s.tar.gz\n", sha256.Sum256([]byte("tampered")), name),
+			expectErr: "checksum mismatch",
+		},
+		{
+			name:      "missing checksum",
+			sums:      fmt.Sprintf("%x  etcd-%s-darwin-amd64.zip\n", sha256.Sum256(nil), version),
+			expectErr: "no checksum",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/" + version + "/SHA256SUMS":
+					w.Write([]byte(tc.sums))
+				case "/" + version + "/" + name + ".tar.gz":
+					w.Write(tarball)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			defer func(old string) { releaseDownloadURL = old }(releaseDownloadURL)
+			releaseDownloadURL = srv.URL
+
+			dst := filepath.Join(t.TempDir(), "etcd-last-release")
+			err := downloadReleaseBinary(version, dst)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				assert.NoFileExists(t, dst)
+				return
+			}
+			require.NoError(t, err)
+			b, err := os.ReadFile(dst)
+			require.NoError(t, err)
+			assert.Equal(t, "etcd binary", string(b))
+		})
+	}
+}
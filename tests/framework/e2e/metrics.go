@@ -0,0 +1,230 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+)
+
+const (
+	WALFsyncDurationMetric      = "etcd_disk_wal_fsync_duration_seconds"
+	BackendCommitDurationMetric = "etcd_disk_backend_commit_duration_seconds"
+)
+
+// DiskLatencyThresholds bound the p99 latencies of the disk operations of
+// the members. A zero threshold is not checked.
+type DiskLatencyThresholds struct {
+	WALFsyncP99      time.Duration
+	BackendCommitP99 time.Duration
+}
+
+// DefaultDiskLatencyThresholds are the thresholds given by the
+// -wal-fsync-p99-threshold and -backend-commit-p99-threshold flags.
+var DefaultDiskLatencyThresholds = DiskLatencyThresholds{
+	WALFsyncP99:      time.Second,
+	BackendCommitP99: time.Second,
+}
+
+// FetchMetrics scrapes the metrics of the member, from its metrics URL if
+// it has one, from its client URL otherwise.
+func FetchMetrics(ctx context.Context, member EtcdProcess) (map[string]*dto.MetricFamily, error) {
+	cfg := member.Config()
+	url := cfg.MetricsURL
+	if url == "" {
+		url = cfg.ClientURL
+	}
+	url += "/metrics"
+
+	client := &http.Client{}
+	if strings.HasPrefix(url, "https://") {
+		tlsInfo := transport.TLSInfo{CertFile: CertPath, KeyFile: PrivateKeyPath, TrustedCAFile: CaPath}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = cfg.Client.AutoTLS
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q fetching %s", resp.Status, url)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// DiskLatencyCheck checks that the p99 latencies of the WAL fsyncs and of
+// the backend commits of the members of a cluster, observed since the check
+// was created, are within thresholds.
+type DiskLatencyCheck struct {
+	clus      *EtcdProcessCluster
+	baselines []map[string]histogram
+}
+
+// NewDiskLatencyCheck records the current disk latency histograms of the
+// members of the cluster, so that Verify only considers the operations of
+// the scenario that follows.
+func NewDiskLatencyCheck(ctx context.Context, clus *EtcdProcessCluster) (*DiskLatencyCheck, error) {
+	c := &DiskLatencyCheck{clus: clus}
+	for _, proc := range clus.Procs {
+		hs, err := fetchDiskHistograms(ctx, proc)
+		if err != nil {
+			return nil, err
+		}
+		c.baselines = append(c.baselines, hs)
+	}
+	return c, nil
+}
+
+// Verify returns an error if the p99 latency of the WAL fsyncs or of the
+// backend commits of a member exceeds the given thresholds.
+func (c *DiskLatencyCheck) Verify(ctx context.Context, thresholds DiskLatencyThresholds) error {
+	var errs []string
+	for i, proc := range c.clus.Procs {
+		hs, err := fetchDiskHistograms(ctx, proc)
+		if err != nil {
+			return err
+		}
+		for _, m := range []struct {
+			name      string
+			threshold time.Duration
+		}{
+			{WALFsyncDurationMetric, thresholds.WALFsyncP99},
+			{BackendCommitDurationMetric, thresholds.BackendCommitP99},
+		} {
+			if m.threshold == 0 {
+				continue
+			}
+			h := hs[m.name]
+			if i < len(c.baselines) {
+				h = h.since(c.baselines[i][m.name])
+			}
+			p99 := time.Duration(h.quantile(0.99) * float64(time.Second))
+			if p99 > m.threshold {
+				errs = append(errs, fmt.Sprintf("member %q: p99 of %s is %v over %d samples, above threshold %v", proc.Config().Name, m.name, p99, h.count, m.threshold))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("disk latency above thresholds: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func fetchDiskHistograms(ctx context.Context, member EtcdProcess) (map[string]histogram, error) {
+	mfs, err := FetchMetrics(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	hs := make(map[string]histogram)
+	for _, name := range []string{WALFsyncDurationMetric, BackendCommitDurationMetric} {
+		mf, ok := mfs[name]
+		if !ok {
+			return nil, fmt.Errorf("member %q has no %s metric", member.Config().Name, name)
+		}
+		hs[name] = newHistogram(mf)
+	}
+	return hs, nil
+}
+
+// histogram is a cumulative histogram, the sum of the histograms of all the
+// metrics of a family.
+type histogram struct {
+	count uint64
+	// bounds are the upper bounds of the buckets, excluding +Inf, and counts
+	// the cumulative count of each bucket.
+	bounds []float64
+	counts []uint64
+}
+
+func newHistogram(mf *dto.MetricFamily) histogram {
+	var h histogram
+	for _, m := range mf.GetMetric() {
+		mh := m.GetHistogram()
+		if mh == nil {
+			continue
+		}
+		h.count += mh.GetSampleCount()
+		for i, b := range mh.GetBucket() {
+			// the +Inf bucket only repeats the sample count
+			if math.IsInf(b.GetUpperBound(), 1) {
+				break
+			}
+			if i == len(h.bounds) {
+				h.bounds = append(h.bounds, b.GetUpperBound())
+				h.counts = append(h.counts, 0)
+			}
+			h.counts[i] += b.GetCumulativeCount()
+		}
+	}
+	return h
+}
+
+// since returns the histogram of the observations made after base. If the
+// member restarted since base, its counters were reset and h is returned.
+func (h histogram) since(base histogram) histogram {
+	if base.count > h.count || len(base.counts) != len(h.counts) {
+		return h
+	}
+	d := histogram{count: h.count - base.count, bounds: h.bounds, counts: make([]uint64, len(h.counts))}
+	for i := range h.counts {
+		d.counts[i] = h.counts[i] - base.counts[i]
+	}
+	return d
+}
+
+// quantile estimates the q-quantile of the histogram, interpolating linearly
+// within buckets as done by the histogram_quantile function of Prometheus.
+// It returns 0 if the histogram is empty, and the upper bound of the last
+// bucket if the quantile falls in the +Inf bucket.
+func (h histogram) quantile(q float64) float64 {
+	if h.count == 0 || len(h.bounds) == 0 {
+		return 0
+	}
+	rank := q * float64(h.count)
+	for i, c := range h.counts {
+		if float64(c) < rank {
+			continue
+		}
+		var start float64
+		var prev uint64
+		if i > 0 {
+			start, prev = h.bounds[i-1], h.counts[i-1]
+		}
+		if c == prev {
+			return h.bounds[i]
+		}
+		return start + (h.bounds[i]-start)*(rank-float64(prev))/float64(c-prev)
+	}
+	return h.bounds[len(h.bounds)-1]
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	metrics := `# TYPE etcd_disk_wal_fsync_duration_seconds histogram
+etcd_disk_wal_fsync_duration_seconds_bucket{le="0.001"} 0
+etcd_disk_wal_fsync_duration_seconds_bucket{le="0.002"} 50
+etcd_disk_wal_fsync_duration_seconds_bucket{le="0.004"} 100
+etcd_disk_wal_fsync_duration_seconds_bucket{le="+Inf"} 100
+etcd_disk_wal_fsync_duration_seconds_sum 0.2
+etcd_disk_wal_fsync_duration_seconds_count 100
+`
+	var parser expfmt.TextParser
+	mfs, err := parser.TextToMetricFamilies(strings.NewReader(metrics))
+	require.NoError(t, err)
+	h := newHistogram(mfs[WALFsyncDurationMetric])
+
+	assert.Equal(t, uint64(100), h.count)
+	assert.InDelta(t, 0.0015, h.quantile(0.25), 1e-9)
+	assert.InDelta(t, 0.00396, h.quantile(0.99), 1e-9)
+	assert.Equal(t, 0.0, histogram{}.quantile(0.99))
+
+	base := histogram{count: 50, bounds: h.bounds, counts: []uint64{0, 50, 50}}
+	since := h.since(base)
+	assert.Equal(t, uint64(50), since.count)
+	assert.InDelta(t, 0.00398, since.quantile(0.99), 1e-9)
+
+	// counters reset by a restart
+	assert.Equal(t, h, h.since(histogram{count: 200, bounds: h.bounds, counts: []uint64{0, 200, 200}}))
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// PhaseReportDir is the directory the phase reports of the tests are written
+// to, as <test name>.phases.json. Reports are not written when it is empty.
+var PhaseReportDir string
+
+// Phase is a timed framework operation, e.g. a cluster start or a member
+// restart.
+type Phase struct {
+	Name     string        `json:"name"`
+	Member   string        `json:"member,omitempty"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// PhaseReport records the phases of a test. It is logged and written to
+// PhaseReportDir when the test finishes.
+type PhaseReport struct {
+	tb testing.TB
+
+	mu     sync.Mutex
+	phases []Phase
+}
+
+var (
+	phaseReportsMu sync.Mutex
+	phaseReports   = make(map[testing.TB]*PhaseReport)
+)
+
+// PhaseReportFor returns the phase report of the test, creating it on first
+// use. It returns nil, which records nothing, if tb is nil.
+func PhaseReportFor(tb testing.TB) *PhaseReport {
+	if tb == nil {
+		return nil
+	}
+	phaseReportsMu.Lock()
+	defer phaseReportsMu.Unlock()
+	if r, ok := phaseReports[tb]; ok {
+		return r
+	}
+	r := &PhaseReport{tb: tb}
+	phaseReports[tb] = r
+	tb.Cleanup(func() {
+		phaseReportsMu.Lock()
+		delete(phaseReports, tb)
+		phaseReportsMu.Unlock()
+		r.finish()
+	})
+	return r
+}
+
+// StartPhase starts timing a phase of the member, or of the whole test if
+// member is empty. The phase is recorded when the returned func is called
+// with the error the phase ended with, if any.
+func (r *PhaseReport) StartPhase(name, member string) func(err error) {
+	if r == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		p := Phase{Name: name, Member: member, Start: start, Duration: time.Since(start)}
+		if err != nil {
+			p.Error = err.Error()
+		}
+		r.mu.Lock()
+		r.phases = append(r.phases, p)
+		r.mu.Unlock()
+	}
+}
+
+// Phases returns the recorded phases, sorted by start time.
+func (r *PhaseReport) Phases() []Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	phases := append([]Phase(nil), r.phases...)
+	sort.SliceStable(phases, func(i, j int) bool { return phases[i].Start.Before(phases[j].Start) })
+	return phases
+}
+
+// String formats the phases as one line per phase, sorted by start time.
+func (r *PhaseReport) String() string {
+	var sb strings.Builder
+	for _, p := range r.Phases() {
+		name := p.Name
+		if p.Member != "" {
+			name = fmt.Sprintf("%s (%s)", p.Name, p.Member)
+		}
+		fmt.Fprintf(&sb, "%-40s %10v", name, p.Duration.Round(time.Millisecond))
+		if p.Error != "" {
+			fmt.Fprintf(&sb, " error: %s", p.Error)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (r *PhaseReport) finish() {
+	phases := r.Phases()
+	if len(phases) == 0 {
+		return
+	}
+	r.tb.Logf("phase report:\n%s", r)
+	if PhaseReportDir == "" {
+		return
+	}
+	if err := r.writeTo(PhaseReportDir, phases); err != nil {
+		r.tb.Errorf("failed to write the phase report (%v)", err)
+	}
+}
+
+func (r *PhaseReport) writeTo(dir string, phases []Phase) error {
+	b, err := json.MarshalIndent(struct {
+		Test   string  `json:"test"`
+		Phases []Phase `json:"phases"`
+	}{r.tb.Name(), phases}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(phaseReportPath(dir, r.tb.Name()), b, 0644)
+}
+
+func phaseReportPath(dir, testName string) string {
+	return filepath.Join(dir, strings.ReplaceAll(testName, "/", "_")+".phases.json")
+}
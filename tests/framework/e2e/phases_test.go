@@ -0,0 +1,59 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseReport(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { PhaseReportDir = old }(PhaseReportDir)
+	PhaseReportDir = dir
+
+	var name string
+	t.Run("sub", func(t *testing.T) {
+		name = t.Name()
+		r := PhaseReportFor(t)
+		require.Same(t, r, PhaseReportFor(t))
+
+		PhaseReportFor(t).StartPhase("cluster start", "")(nil)
+		r.StartPhase("member restart", "test-0")(errors.New("timed out"))
+	})
+
+	b, err := os.ReadFile(phaseReportPath(dir, name))
+	require.NoError(t, err)
+	var report struct {
+		Test   string
+		Phases []Phase
+	}
+	require.NoError(t, json.Unmarshal(b, &report))
+	assert.Equal(t, "TestPhaseReport/sub", report.Test)
+	require.Len(t, report.Phases, 2)
+	assert.Equal(t, "cluster start", report.Phases[0].Name)
+	assert.Empty(t, report.Phases[0].Member)
+	assert.Equal(t, "member restart", report.Phases[1].Name)
+	assert.Equal(t, "test-0", report.Phases[1].Member)
+	assert.Equal(t, "timed out", report.Phases[1].Error)
+
+	// a nil report records nothing
+	PhaseReportFor(nil).StartPhase("cluster start", "")(nil)
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// WaitForPollInterval is the interval between two rounds of Status RPCs in WaitFor.
+const WaitForPollInterval = 500 * time.Millisecond
+
+// MemberStatus is the result of a Status RPC sent to a single member.
+type MemberStatus struct {
+	Status *clientv3.StatusResponse
+	Err    error
+}
+
+// StatusPerMember maps member names to the result of their latest Status RPC.
+type StatusPerMember map[string]MemberStatus
+
+// AllHealthy returns true if every member responded to the Status RPC without error.
+func (s StatusPerMember) AllHealthy() bool {
+	for _, ms := range s {
+		if ms.Err != nil || ms.Status == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns true if every member responded to the Status RPC and
+// its response satisfies f.
+func (s StatusPerMember) All(f func(*clientv3.StatusResponse) bool) bool {
+	if !s.AllHealthy() {
+		return false
+	}
+	for _, ms := range s {
+		if !f(ms.Status) {
+			return false
+		}
+	}
+	return true
+}
+
+// Any returns true if a member responded to the Status RPC with a response
+// satisfying f.
+func (s StatusPerMember) Any(f func(*clientv3.StatusResponse) bool) bool {
+	for _, ms := range s {
+		if ms.Err == nil && ms.Status != nil && f(ms.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// SameRevision returns true if every member responded to the Status RPC at
+// the same revision of the key space.
+func (s StatusPerMember) SameRevision() bool {
+	var rev int64
+	return s.All(func(st *clientv3.StatusResponse) bool {
+		if rev == 0 {
+			rev = st.Header.Revision
+		}
+		return st.Header.Revision == rev
+	})
+}
+
+// String formats the statuses as one line per member, sorted by member name.
+func (s StatusPerMember) String() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		ms := s[name]
+		switch {
+		case ms.Err != nil:
+			fmt.Fprintf(&sb, "%s: error: %v\n", name, ms.Err)
+		case ms.Status == nil:
+			fmt.Fprintf(&sb, "%s: no status\n", name)
+		default:
+			st := ms.Status
+			fmt.Fprintf(&sb, "%s: version=%s storage-version=%s leader=%x raft-term=%d raft-index=%d raft-applied-index=%d is-learner=%v errors=%v\n",
+				name, st.Version, st.StorageVersion, st.Leader, st.RaftTerm, st.RaftIndex, st.RaftAppliedIndex, st.IsLearner, st.Errors)
+		}
+	}
+	return sb.String()
+}
+
+// MembersStatus sends a Status RPC to every member of the cluster in parallel.
+func (epc *EtcdProcessCluster) MembersStatus(ctx context.Context) StatusPerMember {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	result := make(StatusPerMember, len(epc.Procs))
+	for _, proc := range epc.Procs {
+		wg.Add(1)
+		go func(proc EtcdProcess) {
+			defer wg.Done()
+			ms := MemberStatus{}
+			resp, err := proc.Client().Status(ctx)
+			switch {
+			case err != nil:
+				ms.Err = err
+			case len(resp) != 1:
+				ms.Err = fmt.Errorf("expected exactly one status response, got %d", len(resp))
+			default:
+				ms.Status = resp[0]
+			}
+			mu.Lock()
+			result[proc.Config().Name] = ms
+			mu.Unlock()
+		}(proc)
+	}
+	wg.Wait()
+	return result
+}
+
+// WaitFor polls the Status RPC of every member in the cluster until cond
+// returns true, failing the test with the last observed statuses if it
+// does not within timeout.
+func (epc *EtcdProcessCluster) WaitFor(t testing.TB, ctx context.Context, cond func(StatusPerMember) bool, timeout time.Duration) {
+	t.Helper()
+	defer PhaseReportFor(t).StartPhase("wait for condition", "")(nil)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(WaitForPollInterval)
+	defer ticker.Stop()
+	for {
+		status := epc.MembersStatus(ctx)
+		if cond(status) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("condition not met within %v (%v), last observed member status:\n%s", timeout, ctx.Err(), status)
+		case <-ticker.C:
+		}
+	}
+}
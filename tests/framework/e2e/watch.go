@@ -0,0 +1,252 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// WatchedWrite identifies a write by its key and revision.
+type WatchedWrite struct {
+	Key      string
+	Revision int64
+}
+
+// WatchContinuityCheck opens a long-lived watch on every member of a cluster
+// before a lifecycle operation, e.g. an upgrade or a restart of the members,
+// and verifies afterwards that each watch received every write made through
+// the check exactly once and in revision order.
+type WatchContinuityCheck struct {
+	prefix string
+	writer *clientv3.Client
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	writes   []WatchedWrite
+	watchers []*memberWatcher
+}
+
+type memberWatcher struct {
+	name   string
+	client *clientv3.Client
+
+	mu      sync.Mutex
+	events  []WatchedWrite
+	lastRev int64
+	errs    []string
+}
+
+// NewWatchContinuityCheck opens a watch on the prefix on every member of the
+// cluster, starting after the current revision. The watches reconnect to
+// their member when it is restarted. Keys under the prefix must only be
+// written through Put while the check is running.
+func NewWatchContinuityCheck(ctx context.Context, clus *EtcdProcessCluster, prefix string) (*WatchContinuityCheck, error) {
+	writer, err := newWatchCheckClient(clus.Cfg.Client, clus.EndpointsV3())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := writer.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	wctx, cancel := context.WithCancel(context.Background())
+	c := &WatchContinuityCheck{prefix: prefix, writer: writer, cancel: cancel}
+	for _, proc := range clus.Procs {
+		client, err := newWatchCheckClient(clus.Cfg.Client, proc.EndpointsV3())
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		w := &memberWatcher{name: proc.Config().Name, client: client, lastRev: resp.Header.Revision}
+		c.watchers = append(c.watchers, w)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			w.watch(wctx, prefix)
+		}()
+	}
+	return c, nil
+}
+
+func newWatchCheckClient(cfg ClientConfig, endpoints []string) (*clientv3.Client, error) {
+	ccfg := clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+		Logger:      zap.NewNop(),
+	}
+	if cfg.ConnectionType != ClientNonTLS {
+		tlsInfo := transport.TLSInfo{CertFile: CertPath, KeyFile: PrivateKeyPath, TrustedCAFile: CaPath}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = cfg.AutoTLS
+		ccfg.TLS = tlsConfig
+	}
+	return clientv3.New(ccfg)
+}
+
+// watch receives the events of the member until ctx is canceled. The client
+// resumes the watch after the last received revision when the member comes
+// back, a watch that is closed is reopened the same way.
+func (w *memberWatcher) watch(ctx context.Context, prefix string) {
+	for ctx.Err() == nil {
+		w.mu.Lock()
+		rev := w.lastRev + 1
+		w.mu.Unlock()
+		for resp := range w.client.Watch(ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(rev)) {
+			w.mu.Lock()
+			if resp.CompactRevision != 0 {
+				w.errs = append(w.errs, fmt.Sprintf("watch compacted at revision %d", resp.CompactRevision))
+				w.lastRev = resp.CompactRevision - 1
+			}
+			for _, ev := range resp.Events {
+				w.events = append(w.events, WatchedWrite{Key: string(ev.Kv.Key), Revision: ev.Kv.ModRevision})
+				w.lastRev = ev.Kv.ModRevision
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Put writes the value to a key under the prefix of the check and records
+// the revision of the write.
+func (c *WatchContinuityCheck) Put(ctx context.Context, key, value string) error {
+	if !strings.HasPrefix(key, c.prefix) {
+		return fmt.Errorf("key %q is not under the watched prefix %q", key, c.prefix)
+	}
+	resp, err := c.writer.Put(ctx, key, value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.writes = append(c.writes, WatchedWrite{Key: key, Revision: resp.Header.Revision})
+	c.mu.Unlock()
+	return nil
+}
+
+// Verify waits up to timeout for the watches to catch up with the last write,
+// then returns an error if a watch missed a write, received one more than
+// once or out of order, or received an event that was not written through Put.
+func (c *WatchContinuityCheck) Verify(ctx context.Context, timeout time.Duration) error {
+	c.mu.Lock()
+	writes := append([]WatchedWrite(nil), c.writes...)
+	c.mu.Unlock()
+	sort.Slice(writes, func(i, j int) bool { return writes[i].Revision < writes[j].Revision })
+	var lastRev int64
+	if len(writes) > 0 {
+		lastRev = writes[len(writes)-1].Revision
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var errs []string
+	for _, w := range c.watchers {
+		events, werrs := w.waitFor(ctx, lastRev)
+		errs = append(errs, werrs...)
+		if err := verifyWatchContinuity(writes, events); err != nil {
+			errs = append(errs, fmt.Sprintf("member %s: %v", w.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("watch continuity violated:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// waitFor returns the events of the member once it received the revision,
+// or when ctx is done.
+func (w *memberWatcher) waitFor(ctx context.Context, rev int64) ([]WatchedWrite, []string) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		w.mu.Lock()
+		done := w.lastRev >= rev
+		events := append([]WatchedWrite(nil), w.events...)
+		var errs []string
+		for _, err := range w.errs {
+			errs = append(errs, fmt.Sprintf("member %s: %s", w.name, err))
+		}
+		w.mu.Unlock()
+		if done {
+			return events, errs
+		}
+		select {
+		case <-ctx.Done():
+			return events, append(errs, fmt.Sprintf("member %s: watch did not reach revision %d (%v)", w.name, rev, ctx.Err()))
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close cancels the watches and closes the clients of the check.
+func (c *WatchContinuityCheck) Close() {
+	c.cancel()
+	c.wg.Wait()
+	for _, w := range c.watchers {
+		w.client.Close()
+	}
+	c.writer.Close()
+}
+
+// verifyWatchContinuity compares the events received by a watch with the
+// writes, both identified by key and revision, writes being sorted by
+// revision.
+func verifyWatchContinuity(writes, events []WatchedWrite) error {
+	var errs []string
+	seen := make(map[WatchedWrite]int, len(events))
+	var lastRev int64
+	for _, ev := range events {
+		if ev.Revision < lastRev {
+			errs = append(errs, fmt.Sprintf("event %q at revision %d received after revision %d", ev.Key, ev.Revision, lastRev))
+		}
+		lastRev = ev.Revision
+		seen[ev]++
+	}
+
+	written := make(map[WatchedWrite]bool, len(writes))
+	for _, wr := range writes {
+		written[wr] = true
+		switch n := seen[wr]; {
+		case n == 0:
+			errs = append(errs, fmt.Sprintf("missing event %q at revision %d", wr.Key, wr.Revision))
+		case n > 1:
+			errs = append(errs, fmt.Sprintf("event %q at revision %d received %d times", wr.Key, wr.Revision, n))
+		}
+	}
+	for _, ev := range events {
+		if !written[ev] {
+			errs = append(errs, fmt.Sprintf("unexpected event %q at revision %d", ev.Key, ev.Revision))
+			written[ev] = true
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
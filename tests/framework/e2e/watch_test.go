@@ -0,0 +1,69 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyWatchContinuity(t *testing.T) {
+	writes := []WatchedWrite{{"a", 2}, {"b", 3}, {"a", 4}}
+	tcs := []struct {
+		name   string
+		events []WatchedWrite
+		errs   []string
+	}{
+		{
+			name:   "all events",
+			events: []WatchedWrite{{"a", 2}, {"b", 3}, {"a", 4}},
+		},
+		{
+			name:   "missing event",
+			events: []WatchedWrite{{"a", 2}, {"a", 4}},
+			errs:   []string{`missing event "b" at revision 3`},
+		},
+		{
+			name:   "duplicated event",
+			events: []WatchedWrite{{"a", 2}, {"b", 3}, {"b", 3}, {"a", 4}},
+			errs:   []string{`event "b" at revision 3 received 2 times`},
+		},
+		{
+			name:   "out of order",
+			events: []WatchedWrite{{"b", 3}, {"a", 2}, {"a", 4}},
+			errs:   []string{`event "a" at revision 2 received after revision 3`},
+		},
+		{
+			name:   "unexpected event",
+			events: []WatchedWrite{{"a", 2}, {"b", 3}, {"c", 3}, {"a", 4}},
+			errs:   []string{`unexpected event "c" at revision 3`},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyWatchContinuity(writes, tc.events)
+			if len(tc.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				for _, e := range tc.errs {
+					assert.Contains(t, err.Error(), e)
+				}
+			}
+		})
+	}
+}
@@ -168,9 +168,19 @@ type ClusterConfig struct {
 	EnableLeaseCheckpoint   bool
 	LeaseCheckpointInterval time.Duration
 	LeaseCheckpointPersist  bool
+	LeaseCheckpointOnRenew  bool
+	MaxLeaseAttachedKeys    int
 
 	WatchProgressNotifyInterval time.Duration
+	WatchMaxStartRevisionLag    int64
+	WatchMaxStartRevisionAge    time.Duration
+	WatchMaxEventsPerSecond     int64
+	WatchMaxQueuedEvents        int64
+	WatchOverflowPolicy         string
+	WatchBacklogAlarmEvents     int64
 	ExperimentalMaxLearners     int
+	LearnerAutoPromoteLag       uint64
+	LearnerAutoPromoteDuration  time.Duration
 	DisableStrictReconfigCheck  bool
 	CorruptCheckTime            time.Duration
 }
@@ -284,8 +294,18 @@ func (c *Cluster) mustNewMember(t testutil.TB) *Member {
 			EnableLeaseCheckpoint:       c.Cfg.EnableLeaseCheckpoint,
 			LeaseCheckpointInterval:     c.Cfg.LeaseCheckpointInterval,
 			LeaseCheckpointPersist:      c.Cfg.LeaseCheckpointPersist,
+			LeaseCheckpointOnRenew:      c.Cfg.LeaseCheckpointOnRenew,
+			MaxLeaseAttachedKeys:        c.Cfg.MaxLeaseAttachedKeys,
 			WatchProgressNotifyInterval: c.Cfg.WatchProgressNotifyInterval,
+			WatchMaxStartRevisionLag:    c.Cfg.WatchMaxStartRevisionLag,
+			WatchMaxStartRevisionAge:    c.Cfg.WatchMaxStartRevisionAge,
+			WatchMaxEventsPerSecond:     c.Cfg.WatchMaxEventsPerSecond,
+			WatchMaxQueuedEvents:        c.Cfg.WatchMaxQueuedEvents,
+			WatchOverflowPolicy:         c.Cfg.WatchOverflowPolicy,
+			WatchBacklogAlarmEvents:     c.Cfg.WatchBacklogAlarmEvents,
 			ExperimentalMaxLearners:     c.Cfg.ExperimentalMaxLearners,
+			LearnerAutoPromoteLag:       c.Cfg.LearnerAutoPromoteLag,
+			LearnerAutoPromoteDuration:  c.Cfg.LearnerAutoPromoteDuration,
 			DisableStrictReconfigCheck:  c.Cfg.DisableStrictReconfigCheck,
 			CorruptCheckTime:            c.Cfg.CorruptCheckTime,
 		})
@@ -576,8 +596,9 @@ type Member struct {
 	UseBridge                bool
 	UseTCP                   bool
 
-	IsLearner bool
-	Closed    bool
+	IsLearner  bool
+	IsObserver bool
+	Closed     bool
 
 	GrpcServerRecorder *grpc_testing.GrpcRecorder
 }
@@ -608,8 +629,18 @@ type MemberConfig struct {
 	EnableLeaseCheckpoint       bool
 	LeaseCheckpointInterval     time.Duration
 	LeaseCheckpointPersist      bool
+	LeaseCheckpointOnRenew      bool
+	MaxLeaseAttachedKeys        int
 	WatchProgressNotifyInterval time.Duration
+	WatchMaxStartRevisionLag    int64
+	WatchMaxStartRevisionAge    time.Duration
+	WatchMaxEventsPerSecond     int64
+	WatchMaxQueuedEvents        int64
+	WatchOverflowPolicy         string
+	WatchBacklogAlarmEvents     int64
 	ExperimentalMaxLearners     int
+	LearnerAutoPromoteLag       uint64
+	LearnerAutoPromoteDuration  time.Duration
 	DisableStrictReconfigCheck  bool
 	CorruptCheckTime            time.Duration
 }
@@ -713,8 +744,16 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	m.EnableLeaseCheckpoint = mcfg.EnableLeaseCheckpoint
 	m.LeaseCheckpointInterval = mcfg.LeaseCheckpointInterval
 	m.LeaseCheckpointPersist = mcfg.LeaseCheckpointPersist
+	m.LeaseCheckpointOnRenew = mcfg.LeaseCheckpointOnRenew
+	m.MaxLeaseAttachedKeys = mcfg.MaxLeaseAttachedKeys
 
 	m.WatchProgressNotifyInterval = mcfg.WatchProgressNotifyInterval
+	m.WatchMaxStartRevisionLag = mcfg.WatchMaxStartRevisionLag
+	m.WatchMaxStartRevisionAge = mcfg.WatchMaxStartRevisionAge
+	m.WatchMaxEventsPerSecond = mcfg.WatchMaxEventsPerSecond
+	m.WatchMaxQueuedEvents = mcfg.WatchMaxQueuedEvents
+	m.WatchOverflowPolicy = mcfg.WatchOverflowPolicy
+	m.WatchBacklogAlarmEvents = mcfg.WatchBacklogAlarmEvents
 
 	m.InitialCorruptCheck = true
 	if mcfg.CorruptCheckTime > time.Duration(0) {
@@ -726,6 +765,11 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	if mcfg.ExperimentalMaxLearners != 0 {
 		m.ExperimentalMaxLearners = mcfg.ExperimentalMaxLearners
 	}
+	m.ExperimentalLearnerAutoPromoteLag = embed.DefaultLearnerAutoPromoteLag
+	if mcfg.LearnerAutoPromoteLag != 0 {
+		m.ExperimentalLearnerAutoPromoteLag = mcfg.LearnerAutoPromoteLag
+	}
+	m.ExperimentalLearnerAutoPromoteDuration = mcfg.LearnerAutoPromoteDuration
 	m.V2Deprecation = config.V2_DEPR_DEFAULT
 	m.GrpcServerRecorder = &grpc_testing.GrpcRecorder{}
 	m.Logger = memberLogger(t, mcfg.Name)
@@ -1561,14 +1605,30 @@ func (c *Cluster) GetLearnerMembers() ([]*pb.Member, error) {
 // AddAndLaunchLearnerMember creates a learner member, adds it to Cluster
 // via v3 MemberAdd API, and then launches the new member.
 func (c *Cluster) AddAndLaunchLearnerMember(t testutil.TB) {
+	c.addAndLaunchLearnerMember(t, false)
+}
+
+// AddAndLaunchObserverMember creates an observer member, adds it to Cluster
+// via v3 MemberAdd API, and then launches the new member.
+func (c *Cluster) AddAndLaunchObserverMember(t testutil.TB) {
+	c.addAndLaunchLearnerMember(t, true)
+}
+
+func (c *Cluster) addAndLaunchLearnerMember(t testutil.TB, isObserver bool) {
 	m := c.mustNewMember(t)
 	m.IsLearner = true
+	m.IsObserver = isObserver
 
 	scheme := SchemeFromTLSInfo(c.Cfg.PeerTLS)
 	peerURLs := []string{scheme + "://" + m.PeerListeners[0].Addr().String()}
 
 	cli := c.Client(0)
-	_, err := cli.MemberAddAsLearner(context.Background(), peerURLs)
+	var err error
+	if isObserver {
+		_, err = cli.MemberAddAsObserver(context.Background(), peerURLs)
+	} else {
+		_, err = cli.MemberAddAsLearner(context.Background(), peerURLs)
+	}
 	if err != nil {
 		t.Fatalf("failed to add learner member %v", err)
 	}
@@ -1598,6 +1658,7 @@ func (c *Cluster) getMembers() []*pb.Member {
 			PeerURLs:   m.PeerURLs.StringSlice(),
 			ClientURLs: m.ClientURLs.StringSlice(),
 			IsLearner:  m.IsLearner,
+			IsObserver: m.IsObserver,
 		}
 		mems = append(mems, mem)
 	}
@@ -1664,6 +1725,7 @@ func (p SortableProtoMemberSliceByPeerURLs) Swap(i, j int) { p[i], p[j] = p[j],
 func (c *Cluster) MustNewMember(t testutil.TB, resp *clientv3.MemberAddResponse) *Member {
 	m := c.mustNewMember(t)
 	m.IsLearner = resp.Member.IsLearner
+	m.IsObserver = resp.Member.IsObserver
 	m.NewCluster = false
 
 	m.InitialPeerURLsMap = types.URLsMap{}
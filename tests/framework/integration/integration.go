@@ -259,6 +259,10 @@ func (c integrationClient) Defragment(ctx context.Context, o config.DefragOption
 	return nil
 }
 
+func (c integrationClient) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	return c.Client.Grant(ctx, ttl)
+}
+
 func (c integrationClient) TimeToLive(ctx context.Context, id clientv3.LeaseID, o config.LeaseOption) (*clientv3.LeaseTimeToLiveResponse, error) {
 	var leaseOpts []clientv3.LeaseOption
 	if o.WithAttachedKeys {
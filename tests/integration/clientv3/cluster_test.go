@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
 )
 
@@ -292,6 +293,49 @@ func TestMemberPromote(t *testing.T) {
 	}
 }
 
+// TestMemberAutoPromote ensures that the leader promotes a learner staying
+// caught up with it automatically.
+func TestMemberAutoPromote(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{
+		Size:                       3,
+		DisableStrictReconfigCheck: true,
+		LearnerAutoPromoteDuration: time.Second,
+	})
+	defer clus.Terminate(t)
+
+	capi := clus.RandClient()
+	memberAddResp, err := capi.MemberAddAsLearner(context.Background(), []string{"http://127.0.0.1:1234"})
+	if err != nil {
+		t.Fatalf("failed to add member %v", err)
+	}
+	learnerID := memberAddResp.Member.ID
+
+	learnerMember := clus.MustNewMember(t, memberAddResp)
+	if err = learnerMember.Launch(); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		resp, err := capi.MemberList(context.Background())
+		if err != nil {
+			t.Fatalf("failed to list member %v", err)
+		}
+		for _, m := range resp.Members {
+			if m.ID == learnerID && !m.IsLearner {
+				return
+			}
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			t.Fatal("timed out waiting for the learner to be promoted automatically")
+		}
+	}
+}
+
 // TestMemberPromoteMemberNotLearner ensures that promoting a voting member fails.
 func TestMemberPromoteMemberNotLearner(t *testing.T) {
 	integration2.BeforeTest(t)
@@ -328,6 +372,38 @@ func TestMemberPromoteMemberNotLearner(t *testing.T) {
 	}
 }
 
+// TestMemberPromoteObserver ensures that promoting an observer fails, whether or not it caught up.
+func TestMemberPromoteObserver(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, DisableStrictReconfigCheck: true})
+	defer clus.Terminate(t)
+
+	// the request is sent to a follower to include the server-side forwarding to leader.
+	leaderIdx := clus.WaitLeader(t)
+	followerIdx := (leaderIdx + 1) % 3
+	capi := clus.Client(followerIdx)
+
+	urls := []string{"http://127.0.0.1:1234"}
+	memberAddResp, err := capi.MemberAddAsObserver(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("failed to add member %v", err)
+	}
+	if !memberAddResp.Member.IsLearner || !memberAddResp.Member.IsObserver {
+		t.Fatalf("Added a member as observer, got resp.Member.IsLearner = %v, resp.Member.IsObserver = %v",
+			memberAddResp.Member.IsLearner, memberAddResp.Member.IsObserver)
+	}
+
+	_, err = capi.MemberPromote(context.Background(), memberAddResp.Member.ID)
+	if err == nil {
+		t.Fatalf("expecting promote observer to fail, got no error")
+	}
+	expectedErrKeywords := "cannot promote an observer member"
+	if !strings.Contains(err.Error(), expectedErrKeywords) {
+		t.Fatalf("expecting error to contain %s, got %s", expectedErrKeywords, err.Error())
+	}
+}
+
 // TestMemberPromoteMemberNotExist ensures that promoting a member that does not exist in cluster fails.
 func TestMemberPromoteMemberNotExist(t *testing.T) {
 	integration2.BeforeTest(t)
@@ -426,3 +502,91 @@ func TestMaxLearnerInCluster(t *testing.T) {
 		t.Errorf("failed to add member %v", err)
 	}
 }
+
+// TestMemberReplace ensures that a stopped member is replaced by a learner in
+// a single step, which catches up and is promoted, while replacing a running
+// member fails.
+func TestMemberReplace(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	leaderIdx := clus.WaitLeader(t)
+	stoppedIdx := (leaderIdx + 1) % 3
+	capi := clus.Client(leaderIdx)
+	urls := []string{"http://127.0.0.1:1234"}
+
+	// replacing a running member would lose a voting member in the meantime
+	expectedErrKeywords := "can only replace a stopped member"
+	runningID := uint64(clus.Members[(leaderIdx+2)%3].Server.MemberId())
+	_, err := capi.MemberReplace(context.Background(), runningID, urls)
+	if err == nil {
+		t.Fatalf("expect replacing a running member to fail, got no error")
+	}
+	if !strings.Contains(err.Error(), expectedErrKeywords) {
+		t.Fatalf("expect error to contain %s, got %s", expectedErrKeywords, err.Error())
+	}
+
+	stopped := clus.Members[stoppedIdx]
+	stoppedID := uint64(stopped.Server.MemberId())
+	stopped.Stop(t)
+
+	// retry until the leader finds the member stopped and the others connected
+	// long enough to be counted in the quorum, or timeout
+	var resp *clientv3.MemberReplaceResponse
+	timeout := time.After(10 * time.Second)
+	for {
+		resp, err = capi.MemberReplace(context.Background(), stoppedID, urls)
+		if err == nil {
+			break
+		}
+		if !strings.Contains(err.Error(), expectedErrKeywords) && !strings.Contains(err.Error(), "unhealthy cluster") {
+			t.Fatalf("unexpected error when replacing member: %v", err)
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("failed all attempts to replace member, last error: %v", err)
+		}
+	}
+
+	if !resp.Member.IsLearner {
+		t.Fatalf("replacement member IsLearner = %v, want true", resp.Member.IsLearner)
+	}
+	if len(resp.Members) != 3 {
+		t.Fatalf("number of members = %d, want %d", len(resp.Members), 3)
+	}
+	for _, m := range resp.Members {
+		if m.ID == stoppedID {
+			t.Fatalf("replaced member %x is still in the cluster", stoppedID)
+		}
+	}
+
+	// the replaced member is gone for good; launch the replacement in its place
+	stopped.Client.Close()
+	stopped.Terminate(t)
+	clus.Members = append(clus.Members[:stoppedIdx], clus.Members[stoppedIdx+1:]...)
+	replacement := clus.MustNewMember(t, &clientv3.MemberAddResponse{Member: resp.Member, Members: resp.Members})
+	if err = replacement.Launch(); err != nil {
+		t.Fatal(err)
+	}
+
+	// retry until promote succeed or timeout
+	expectedErrKeywords = "can only promote a learner member which is in sync with leader"
+	timeout = time.After(5 * time.Second)
+	for {
+		_, err = capi.MemberPromote(context.Background(), resp.Member.ID)
+		if err == nil {
+			break
+		}
+		if !strings.Contains(err.Error(), expectedErrKeywords) {
+			t.Fatalf("unexpected error when promoting learner member: %v", err)
+		}
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("failed all attempts to promote learner member, last error: %v", err)
+		}
+	}
+}
@@ -288,6 +288,103 @@ func TestKVRange(t *testing.T) {
 	}
 }
 
+func TestKVGetEstimate(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := context.TODO()
+
+	for _, k := range []string{"a", "b", "c", "c", "d"} {
+		if _, err := kv.Put(ctx, k, "value"); err != nil {
+			t.Fatalf("couldn't put %q (%v)", k, err)
+		}
+	}
+	if _, err := kv.Delete(ctx, "d"); err != nil {
+		t.Fatalf("couldn't delete (%v)", err)
+	}
+
+	tests := []struct {
+		key   string
+		opts  []clientv3.OpOption
+		count int64
+		size  int64
+	}{
+		{"a", nil, 1, 6},
+		{"d", nil, 0, 0},
+		{"a", []clientv3.OpOption{clientv3.WithRange("c")}, 2, 12},
+		{"a", []clientv3.OpOption{clientv3.WithFromKey()}, 3, 18},
+		{"", []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithSerializable()}, 3, 18},
+	}
+	for i, tt := range tests {
+		resp, err := kv.Get(ctx, tt.key, append(tt.opts, clientv3.WithEstimate())...)
+		if err != nil {
+			t.Fatalf("#%d: couldn't range (%v)", i, err)
+		}
+		if len(resp.Kvs) != 0 {
+			t.Errorf("#%d: expected no kvs, got %+v", i, resp.Kvs)
+		}
+		if resp.Count != tt.count || resp.EstimatedSize != tt.size {
+			t.Errorf("#%d: estimate = (%d, %d), want (%d, %d)", i, resp.Count, resp.EstimatedSize, tt.count, tt.size)
+		}
+	}
+
+	if _, err := kv.Get(ctx, "a", clientv3.WithEstimate(), clientv3.WithRev(2)); err != rpctypes.ErrEstimateRevision {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrEstimateRevision, err)
+	}
+}
+
+func TestKVGetBoundedStaleness(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kv := clus.Client(0)
+	ctx := context.TODO()
+	if _, err := kv.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatalf("couldn't put (%v)", err)
+	}
+	// the member applied the put before responding, so it reads its own write
+	resp, err := kv.Get(ctx, "foo", clientv3.WithBoundedStaleness())
+	if err != nil {
+		t.Fatalf("couldn't get (%v)", err)
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != "bar" {
+		t.Fatalf("expected foo=bar, got %+v", resp.Kvs)
+	}
+
+	clus.Members[1].Stop(t)
+	clus.Members[2].Stop(t)
+
+	// wait for election timeout, then member[0] will not have a leader.
+	var (
+		electionTicks = 10
+		tickDuration  = 10 * time.Millisecond
+	)
+	time.Sleep(time.Duration(3*electionTicks) * tickDuration)
+
+	// without a leader the staleness cannot be bounded, the read is linearizable
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	_, err = kv.Get(tctx, "foo", clientv3.WithBoundedStaleness())
+	cancel()
+	if err == nil {
+		t.Fatal("expected the bounded staleness read to fail without a leader")
+	}
+	if _, err = kv.Get(ctx, "foo", clientv3.WithSerializable()); err != nil {
+		t.Fatalf("couldn't get serializable (%v)", err)
+	}
+
+	// clients may give timeout errors since the members are stopped; take
+	// the clients so that terminating the cluster won't complain
+	clus.Client(1).Close()
+	clus.Client(2).Close()
+	clus.TakeClient(1)
+	clus.TakeClient(2)
+}
+
 func TestKVGetErrConnClosed(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -850,6 +947,86 @@ func TestKVForLearner(t *testing.T) {
 	}
 }
 
+// TestKVForObserver ensures that an observer serves the reads and the watches,
+// and rejects the writes.
+func TestKVForObserver(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, DisableStrictReconfigCheck: true})
+	defer clus.Terminate(t)
+
+	clus.AddAndLaunchObserverMember(t)
+	if len(clus.Members) != 4 {
+		t.Fatalf("expecting 4 members in cluster after adding the observer member, got %d", len(clus.Members))
+	}
+
+	cfg := clientv3.Config{
+		Endpoints:   []string{clus.Members[3].GRPCURL()},
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	}
+	// this client only has endpoint of the observer member
+	cli, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatalf("failed to create clientv3: %v", err)
+	}
+	defer cli.Close()
+
+	<-clus.Members[3].ReadyNotify()
+
+	wch := cli.Watch(context.TODO(), "foo")
+	if _, err = clus.Client(0).Put(context.TODO(), "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case wresp := <-wch:
+		if err = wresp.Err(); err != nil {
+			t.Fatalf("unexpected watch error: %v", err)
+		}
+		if len(wresp.Events) != 1 || string(wresp.Events[0].Kv.Value) != "bar" {
+			t.Fatalf("unexpected watch events: %v", wresp.Events)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watch event on the observer")
+	}
+
+	tests := []struct {
+		op   clientv3.Op
+		wErr bool
+	}{
+		{
+			op:   clientv3.OpGet("foo", clientv3.WithSerializable()),
+			wErr: false,
+		},
+		{
+			op:   clientv3.OpGet("foo"),
+			wErr: false,
+		},
+		{
+			op:   clientv3.OpPut("foo", "bar"),
+			wErr: true,
+		},
+		{
+			op:   clientv3.OpDelete("foo"),
+			wErr: true,
+		},
+		{
+			op:   clientv3.OpTxn([]clientv3.Cmp{clientv3.Compare(clientv3.CreateRevision("foo"), "=", 0)}, nil, nil),
+			wErr: true,
+		},
+	}
+
+	for idx, test := range tests {
+		_, err := cli.Do(context.TODO(), test.op)
+		if err != nil && !test.wErr {
+			t.Errorf("%d: expect no error, got %v", idx, err)
+		}
+		if err == nil && test.wErr {
+			t.Errorf("%d: expect error, got nil", idx)
+		}
+	}
+}
+
 // TestBalancerSupportLearner verifies that balancer's retry and failover mechanism supports cluster with learner member
 func TestBalancerSupportLearner(t *testing.T) {
 	integration2.BeforeTest(t)
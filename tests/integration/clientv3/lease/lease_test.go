@@ -26,6 +26,7 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.etcd.io/etcd/server/v3/lease"
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
 )
 
@@ -95,6 +96,162 @@ func TestLeaseRevoke(t *testing.T) {
 	}
 }
 
+// TestLeaseRevokeParent ensures that revoking a lease revokes its child
+// leases and deletes their keys.
+func TestLeaseRevokeParent(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+
+	_, err := cli.Grant(context.Background(), 10, clientv3.WithParentLease(12345))
+	if err != rpctypes.ErrParentLeaseNotFound {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrParentLeaseNotFound)
+	}
+
+	parent, err := cli.Grant(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("failed to create lease %v", err)
+	}
+	child, err := cli.Grant(context.Background(), 10, clientv3.WithParentLease(parent.ID))
+	if err != nil {
+		t.Fatalf("failed to create lease %v", err)
+	}
+	if _, err = cli.Put(context.TODO(), "foo", "bar", clientv3.WithLease(child.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	ttl, err := cli.TimeToLive(context.Background(), child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl.ParentID != parent.ID {
+		t.Errorf("parent ID = %x, want %x", ttl.ParentID, parent.ID)
+	}
+
+	if _, err = cli.Revoke(context.Background(), parent.ID); err != nil {
+		t.Fatalf("failed to revoke lease %v", err)
+	}
+	ttl, err = cli.TimeToLive(context.Background(), child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl.TTL != -1 {
+		t.Errorf("child lease TTL = %d, want -1", ttl.TTL)
+	}
+	gresp, err := cli.Get(context.TODO(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 0 {
+		t.Errorf("got %d keys of the revoked child lease, want 0", len(gresp.Kvs))
+	}
+}
+
+func TestLeaseQuery(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+
+	_, err := cli.Grant(context.Background(), 10, clientv3.WithLeaseLabels(map[string]string{"": "a"}))
+	if err != rpctypes.ErrInvalidLeaseLabels {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrInvalidLeaseLabels)
+	}
+
+	labels := map[string]string{"component": "scheduler", "zone": "a"}
+	l1, err := cli.Grant(context.Background(), 10, clientv3.WithLeaseLabels(labels))
+	if err != nil {
+		t.Fatalf("failed to create lease %v", err)
+	}
+	if _, err = cli.Grant(context.Background(), 10, clientv3.WithLeaseLabels(map[string]string{"component": "scheduler", "zone": "b"})); err != nil {
+		t.Fatalf("failed to create lease %v", err)
+	}
+	if _, err = cli.Grant(context.Background(), 10); err != nil {
+		t.Fatalf("failed to create lease %v", err)
+	}
+
+	ttl, err := cli.TimeToLive(context.Background(), l1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ttl.Labels, labels) {
+		t.Errorf("labels = %v, want %v", ttl.Labels, labels)
+	}
+
+	resp, err := cli.Query(context.Background(), map[string]string{"component": "scheduler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Leases) != 2 {
+		t.Errorf("got %d leases, want 2", len(resp.Leases))
+	}
+	resp, err = cli.Query(context.Background(), map[string]string{"component": "scheduler", "zone": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wleases := []clientv3.LeaseStatus{{ID: l1.ID, Labels: labels}}
+	if !reflect.DeepEqual(resp.Leases, wleases) {
+		t.Errorf("leases = %+v, want %+v", resp.Leases, wleases)
+	}
+}
+
+func TestLeaseGrantRevokeBatch(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+
+	gresp, err := cli.GrantBatch(context.Background(), []int64{10, lease.MaxLeaseTTL + 1, 20}, clientv3.WithLeaseLabels(map[string]string{"component": "sessions"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Leases) != 3 {
+		t.Fatalf("got %d leases, want 3", len(gresp.Leases))
+	}
+	if gresp.Leases[1].Error != lease.ErrLeaseTTLTooLarge.Error() {
+		t.Errorf("error = %q, want %q", gresp.Leases[1].Error, lease.ErrLeaseTTLTooLarge.Error())
+	}
+	granted := []clientv3.LeaseID{gresp.Leases[0].ID, gresp.Leases[2].ID}
+	for i, l := range []clientv3.LeaseGrantResponse{gresp.Leases[0], gresp.Leases[2]} {
+		if l.Error != "" || l.ID == clientv3.NoLease {
+			t.Fatalf("#%d: failed to grant lease: %+v", i, l)
+		}
+	}
+	if gresp.Leases[2].TTL != 20 {
+		t.Errorf("TTL = %d, want 20", gresp.Leases[2].TTL)
+	}
+	qresp, err := cli.Query(context.Background(), map[string]string{"component": "sessions"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qresp.Leases) != 2 {
+		t.Errorf("got %d labeled leases, want 2", len(qresp.Leases))
+	}
+
+	rresp, err := cli.RevokeBatch(context.Background(), append(granted, 12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	werrs := []string{"", "", lease.ErrLeaseNotFound.Error()}
+	if !reflect.DeepEqual(rresp.Errors, werrs) {
+		t.Errorf("errors = %q, want %q", rresp.Errors, werrs)
+	}
+	lresp, err := cli.Leases(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Leases) != 0 {
+		t.Errorf("got %d leases, want 0", len(lresp.Leases))
+	}
+}
+
 func TestLeaseKeepAliveOnce(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -584,6 +741,23 @@ func TestLeaseTimeToLive(t *testing.T) {
 	if len(lresp.Keys) != 0 {
 		t.Fatalf("unexpected keys %+v", lresp.Keys)
 	}
+	if lresp.Stats != nil {
+		t.Fatalf("unexpected stats %+v", lresp.Stats)
+	}
+
+	if _, err = lapi.KeepAliveOnce(context.Background(), resp.ID); err != nil {
+		t.Fatal(err)
+	}
+	// the stats are kept by the leader, whichever member is asked
+	for i := range clus.Members {
+		lresp, lerr = clus.Client(i).TimeToLive(context.Background(), resp.ID, clientv3.WithLeaseStats())
+		if lerr != nil {
+			t.Fatal(lerr)
+		}
+		if st := lresp.Stats; st == nil || st.Renewals != 1 || st.AttachedKeys != int64(len(keys)) || st.LastRenewTime.IsZero() {
+			t.Fatalf("#%d: expected 1 renewal and %d attached keys, got %+v", i, len(keys), st)
+		}
+	}
 }
 
 func TestLeaseTimeToLiveLeaseNotFound(t *testing.T) {
@@ -652,6 +826,78 @@ func TestLeaseLeases(t *testing.T) {
 	}
 }
 
+// TestLeaseWatchExpirations ensures the leases revoked or expired through
+// any member are streamed by WatchExpirations, telling the expired leases
+// apart.
+func TestLeaseWatchExpirations(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wch, err := clus.Client(0).WatchExpirations(ctx, clientv3.WithAttachedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredch, err := clus.Client(1).WatchExpirations(ctx, clientv3.WithExpiredOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := clus.Client(1).Grant(context.TODO(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{"app": "foo"}
+	expiring, err := clus.Client(2).Grant(context.TODO(), 1, clientv3.WithLeaseLabels(labels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clus.Client(2).Put(context.TODO(), "foo", "bar", clientv3.WithLease(expiring.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = clus.Client(1).Revoke(context.TODO(), revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	recv := func(wch <-chan clientv3.LeaseWatchExpirationsResponse, n int) []clientv3.LeaseExpiration {
+		var got []clientv3.LeaseExpiration
+		for len(got) < n {
+			select {
+			case wr, ok := <-wch:
+				if !ok {
+					t.Fatal("expiration chan closed unexpectedly")
+				}
+				if wr.Err() != nil {
+					t.Fatal(wr.Err())
+				}
+				got = append(got, wr.Expirations...)
+			case <-time.After(10 * time.Second):
+				t.Fatalf("timed out waiting for expirations, got %+v", got)
+			}
+		}
+		return got
+	}
+	want := []clientv3.LeaseExpiration{
+		{ID: revoked.ID, GrantedTTL: revoked.TTL},
+		{ID: expiring.ID, GrantedTTL: expiring.TTL, Keys: [][]byte{[]byte("foo")}, Expired: true, Labels: labels},
+	}
+	if got := recv(wch, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("expirations = %+v, want %+v", got, want)
+	}
+	want = []clientv3.LeaseExpiration{{ID: expiring.ID, GrantedTTL: expiring.TTL, Expired: true, Labels: labels}}
+	if got := recv(expiredch, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expired-only expirations = %+v, want %+v", got, want)
+	}
+
+	cancel()
+	if _, ok := <-wch; ok {
+		t.Error("expected the expiration chan to be closed")
+	}
+}
+
 // TestLeaseRenewLostQuorum ensures keepalives work after losing quorum
 // for a while.
 func TestLeaseRenewLostQuorum(t *testing.T) {
@@ -29,6 +29,7 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/api/v3/version"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/features"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
@@ -435,3 +436,23 @@ func TestMaintenanceStatus(t *testing.T) {
 		t.Fatal("no leader found")
 	}
 }
+
+// TestMaintenanceStatusFeatureGates ensures that the status of a member
+// reports its enabled feature gates, among which the initial corruption
+// check the integration members enable.
+func TestMaintenanceStatusFeatureGates(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	resp, err := cli.Status(context.TODO(), clus.Members[0].GRPCURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Contains(t, resp.FeatureGates, string(features.InitialCorruptCheck))
+	if !clus.Members[0].Server.FeatureEnabled(features.InitialCorruptCheck) {
+		t.Fatal("expected the initial corruption check to be enabled")
+	}
+}
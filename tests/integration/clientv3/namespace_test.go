@@ -83,3 +83,36 @@ func TestNamespaceWatch(t *testing.T) {
 	// let client close teardown namespace watch
 	c.Watcher = nsWatcher
 }
+
+func TestNamespaceWatchRanges(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	c := clus.Client(0)
+	nsWatcher := namespace.NewWatcher(c.Watcher, "foo/")
+	defer nsWatcher.Close()
+
+	for _, k := range []string{"foo/abc", "abc", "foo/def1", "def1", "foo/xyz"} {
+		if _, err := c.Put(context.TODO(), k, "bar"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nsWch := nsWatcher.Watch(context.TODO(), "abc", clientv3.WithRev(1),
+		clientv3.WithRanges(clientv3.KeyRange{Key: "def", End: clientv3.GetPrefixRangeEnd("def")}, clientv3.KeyRange{Key: "xyz"}))
+	var keys []string
+	for len(keys) < 3 {
+		wr := <-nsWch
+		if err := wr.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range wr.Events {
+			keys = append(keys, string(ev.Kv.Key))
+		}
+	}
+	if want := []string{"abc", "def1", "xyz"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected namespaced keys %v, got %v", want, keys)
+	}
+}
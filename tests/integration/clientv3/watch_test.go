@@ -516,6 +516,12 @@ func TestWatchCompactRevision(t *testing.T) {
 	if !wresp.Canceled {
 		t.Fatalf("wresp.Canceled expected true, got %+v", wresp)
 	}
+	// resuming at the compact revision misses the events of revisions 2 and 3,
+	// catching up on the revisions up to the current one
+	if wresp.CompactRevision != 4 || wresp.LostRevisions != 2 || wresp.Header.Revision != 6 {
+		t.Fatalf("expected compact revision 4, 2 lost revisions and revision 6, got %d, %d and %d",
+			wresp.CompactRevision, wresp.LostRevisions, wresp.Header.Revision)
+	}
 
 	// ensure the channel is closed
 	if wresp, ok = <-wch; ok {
@@ -0,0 +1,121 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+type auditEntry struct {
+	Msg       string `json:"msg"`
+	User      string `json:"user"`
+	Method    string `json:"method"`
+	Code      string `json:"code"`
+	KeyRanges []struct {
+		Key      string `json:"key"`
+		RangeEnd string `json:"range-end"`
+	} `json:"key-ranges"`
+}
+
+func TestEmbedEtcdAuditLog(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(false, 2)
+	cfg := embed.NewConfig()
+	require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("AuditLog=true"))
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.ExperimentalAuditLogPath = filepath.Join(t.TempDir(), "audit.log")
+	cfg.ExperimentalAuditLogExcludePrefixes = []string{"/events/"}
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	<-e.Server.ReadyNotify()
+
+	ctx := context.TODO()
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	_, err = cli.RoleAdd(ctx, "root")
+	require.NoError(t, err)
+	_, err = cli.UserAdd(ctx, "root", "123")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = cli.AuthEnable(ctx)
+	require.NoError(t, err)
+	cli.Close()
+
+	rootCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "root", Password: "123"})
+	require.NoError(t, err)
+	_, err = rootCli.Put(ctx, "/pods/a", "1")
+	require.NoError(t, err)
+	_, err = rootCli.Put(ctx, "/events/a", "1")
+	require.NoError(t, err)
+	_, err = rootCli.Get(ctx, "/pods/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	rootCli.Close()
+
+	anonCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	_, err = anonCli.Get(ctx, "/pods/a")
+	require.Error(t, err)
+	anonCli.Close()
+	e.Close()
+
+	f, err := os.Open(cfg.ExperimentalAuditLogPath)
+	require.NoError(t, err)
+	defer f.Close()
+	var entries []auditEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var entry auditEntry
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &entry))
+		if entry.Msg == "request" && (entry.Method == "/etcdserverpb.KV/Put" || entry.Method == "/etcdserverpb.KV/Range") {
+			entries = append(entries, entry)
+		}
+	}
+	require.NoError(t, sc.Err())
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, "/etcdserverpb.KV/Put", entries[0].Method)
+	assert.Equal(t, "root", entries[0].User)
+	assert.Equal(t, "OK", entries[0].Code)
+	require.Len(t, entries[0].KeyRanges, 1)
+	assert.Equal(t, "/pods/a", entries[0].KeyRanges[0].Key)
+
+	assert.Equal(t, "/etcdserverpb.KV/Range", entries[1].Method, "expected the put of an excluded key to be left out")
+	require.Len(t, entries[1].KeyRanges, 1)
+	assert.Equal(t, "/pods/", entries[1].KeyRanges[0].Key)
+	assert.Equal(t, "/pods0", entries[1].KeyRanges[0].RangeEnd)
+
+	assert.Equal(t, "", entries[2].User)
+	assert.Equal(t, "InvalidArgument", entries[2].Code, "expected the request without user to be rejected")
+}
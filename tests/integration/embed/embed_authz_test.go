@@ -0,0 +1,184 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3authz"
+)
+
+func TestEmbedEtcdAuthzWebhook(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	// the webhook, served over TLS, denies the writes and the watches of the
+	// keys under /frozen/ and the snapshots, and fails while failing is set
+	var (
+		mu       sync.Mutex
+		requests []v3authz.Request
+		failing  atomic.Bool
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req v3authz.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+		allowed := req.Method != "/etcdserverpb.Maintenance/Snapshot"
+		for _, kr := range req.Keys {
+			if (req.Method == "/etcdserverpb.KV/Put" || req.Method == "/etcdserverpb.Watch/Watch") && bytes.HasPrefix(kr.Key, []byte("/frozen/")) {
+				allowed = false
+			}
+		}
+		json.NewEncoder(w).Encode(v3authz.Decision{Allowed: allowed, Reason: "change freeze"})
+	}))
+	defer srv.Close()
+
+	urls := newEmbedURLs(false, 2)
+	cfg := embed.NewConfig()
+	require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("Authz=true"))
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.ExperimentalAuthzWebhookURL = srv.URL
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+	cfg.ExperimentalAuthzWebhookTrustedCAFile = caFile
+	cfg.ExperimentalAuthzCacheTTL = 0
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	ctx := context.TODO()
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	// the requests are not authorized without auth
+	_, err = cli.Put(ctx, "/frozen/a", "1")
+	require.NoError(t, err)
+	_, err = cli.RoleAdd(ctx, "root")
+	require.NoError(t, err)
+	_, err = cli.UserAdd(ctx, "root", "123")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = cli.UserAdd(ctx, "alice", "123")
+	require.NoError(t, err)
+	_, err = cli.RoleAdd(ctx, "pods")
+	require.NoError(t, err)
+	_, err = cli.RoleGrantPermission(ctx, "pods", "/pods/", clientv3.GetPrefixRangeEnd("/pods/"), clientv3.PermissionType(clientv3.PermReadWrite))
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "alice", "pods")
+	require.NoError(t, err)
+	_, err = cli.AuthEnable(ctx)
+	require.NoError(t, err)
+	cli.Close()
+
+	rootCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "root", Password: "123"})
+	require.NoError(t, err)
+	defer rootCli.Close()
+	_, err = rootCli.Put(ctx, "/pods/a", "1")
+	require.NoError(t, err)
+	_, err = rootCli.Put(ctx, "/frozen/a", "2")
+	require.ErrorIs(t, err, rpctypes.ErrAuthorizerDenied)
+	_, err = rootCli.Get(ctx, "/frozen/a")
+	require.NoError(t, err)
+	wresp := <-rootCli.Watch(ctx, "/frozen/", clientv3.WithPrefix())
+	require.True(t, wresp.Canceled)
+	require.ErrorIs(t, wresp.Err(), rpctypes.ErrAuthorizerDenied)
+	rc, err := rootCli.Snapshot(ctx)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.ErrorIs(t, err, rpctypes.ErrAuthorizerDenied)
+	rc.Close()
+
+	aliceCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "alice", Password: "123"})
+	require.NoError(t, err)
+	defer aliceCli.Close()
+	mu.Lock()
+	n := len(requests)
+	mu.Unlock()
+	// the requests denied by the roles are not sent to the authorizer
+	_, err = aliceCli.Get(ctx, "/frozen/a")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+	_, err = aliceCli.Get(ctx, "/pods/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	mu.Lock()
+	require.Len(t, requests, n+1)
+	last := requests[n]
+	mu.Unlock()
+	assert.Equal(t, "alice", last.User)
+	assert.Equal(t, "/etcdserverpb.KV/Range", last.Method)
+	require.Len(t, last.Keys, 1)
+	assert.Equal(t, "/pods/", string(last.Keys[0].Key))
+	assert.Equal(t, "/pods0", string(last.Keys[0].RangeEnd))
+
+	wctx, wcancel := context.WithCancel(ctx)
+	defer wcancel()
+	wch := aliceCli.Watch(wctx, "/pods/", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	wresp = <-wch
+	require.NoError(t, wresp.Err())
+	require.True(t, wresp.Created)
+	mu.Lock()
+	last = requests[len(requests)-1]
+	mu.Unlock()
+	assert.Equal(t, "/etcdserverpb.Watch/Watch", last.Method)
+	require.Len(t, last.Keys, 1)
+	assert.Equal(t, "/pods/", string(last.Keys[0].Key))
+
+	lresp, err := aliceCli.Grant(ctx, 60)
+	require.NoError(t, err)
+	_, err = aliceCli.KeepAliveOnce(ctx, lresp.ID)
+	require.NoError(t, err)
+	mu.Lock()
+	last = requests[len(requests)-1]
+	mu.Unlock()
+	assert.Equal(t, "/etcdserverpb.Lease/LeaseKeepAlive", last.Method)
+	assert.Empty(t, last.Keys)
+
+	// the requests are denied while the authorizer fails, by default
+	failing.Store(true)
+	_, err = aliceCli.Get(ctx, "/pods/a", clientv3.WithSerializable())
+	require.ErrorIs(t, err, rpctypes.ErrAuthorizerFailed)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+)
+
+// keySink records the keys of the published events.
+type keySink struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *keySink) Publish(ctx context.Context, b v3changefeed.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ev := range b.Events {
+		s.keys = append(s.keys, string(ev.Kv.Key))
+	}
+	return nil
+}
+
+func (s *keySink) Close() error { return nil }
+
+func (s *keySink) published() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.keys...)
+}
+
+func TestEmbedEtcdChangeFeedCheckpoint(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(false, 2)
+	dir := filepath.Join(t.TempDir(), "embed-etcd")
+	start := func(sink *keySink) *embed.Etcd {
+		cfg := embed.NewConfig()
+		require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("ChangeFeeds=true"))
+		setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+		cfg.Dir = dir
+		cfg.ExperimentalChangeFeeds = []v3changefeed.Config{{Name: "test", Prefixes: []string{"/a/"}, Sink: sink}}
+		e, err := embed.StartEtcd(cfg)
+		require.NoError(t, err)
+		<-e.Server.ReadyNotify()
+		return e
+	}
+
+	sink := &keySink{}
+	e := start(sink)
+	ctx := context.TODO()
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	// the checkpoints are proposed by the server, regardless of the users
+	_, err = cli.UserAdd(ctx, "root", "123")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = cli.AuthEnable(ctx)
+	require.NoError(t, err)
+	cli.Close()
+
+	cli, err = clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "root", Password: "123"})
+	require.NoError(t, err)
+	defer cli.Close()
+	presp, err := cli.Put(ctx, "/a/1", "v")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(sink.published()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	// the checkpoint is in the reserved key space of the replicated key space
+	checkpoint := v3changefeed.CheckpointPrefix + "test\x00/a/"
+	require.Eventually(t, func() bool {
+		resp, err := cli.Get(ctx, checkpoint)
+		return err == nil && len(resp.Kvs) == 1 && string(resp.Kvs[0].Value) == strconv.FormatInt(presp.Header.Revision, 10)
+	}, 5*time.Second, 50*time.Millisecond)
+	e.Close()
+
+	// publishing resumes from the checkpoint
+	sink = &keySink{}
+	e = start(sink)
+	defer e.Close()
+	_, err = cli.Put(ctx, "/a/2", "v")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(sink.published()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"/a/2"}, sink.published())
+}
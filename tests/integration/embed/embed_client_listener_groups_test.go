@@ -0,0 +1,98 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func TestEmbedEtcdClientListenerGroups(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(false, 2)
+	mtlsURL := newEmbedURLs(true, 3)[2]
+	cfg := embed.NewConfig()
+	require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set("ClientListenerGroups=true"))
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.ExperimentalClientListenerGroups = []embed.ClientListenerGroup{{
+		Name:             "control-plane",
+		ListenClientUrls: []url.URL{mtlsURL},
+		TLSInfo:          testTLSInfo,
+		AuthModes:        []string{embed.ClientAuthModeCert},
+	}}
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	defer cli.Close()
+	for _, name := range []string{"root", "example.com"} {
+		_, err = cli.UserAdd(ctx, name, "123")
+		require.NoError(t, err)
+	}
+	_, err = cli.RoleAdd(ctx, "root")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "example.com", "root")
+	require.NoError(t, err)
+	_, err = cli.AuthEnable(ctx)
+	require.NoError(t, err)
+
+	// the clients of the group are authenticated by their certificates
+	tlsConfig, err := testTLSInfo.ClientConfig()
+	require.NoError(t, err)
+	certCli, err := clientv3.New(clientv3.Config{Endpoints: []string{mtlsURL.String()}, TLS: tlsConfig})
+	require.NoError(t, err)
+	defer certCli.Close()
+	_, err = certCli.Put(ctx, "foo", "bar")
+	require.NoError(t, err)
+
+	// the passwords are denied on the listeners of the group
+	_, err = clientv3.New(clientv3.Config{
+		Endpoints: []string{mtlsURL.String()},
+		TLS:       tlsConfig,
+		Username:  "root",
+		Password:  "123",
+	})
+	require.ErrorContains(t, err, "permission denied")
+
+	// and allowed on the other listeners
+	rootCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "root", Password: "123"})
+	require.NoError(t, err)
+	defer rootCli.Close()
+	resp, err := rootCli.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+}
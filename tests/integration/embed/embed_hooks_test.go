@@ -0,0 +1,122 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/config"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+var errForbiddenKey = errors.New("forbidden key")
+
+// putHook rejects the puts of the key "forbidden" and counts the puts applied.
+type putHook struct {
+	mu      sync.Mutex
+	applied int
+}
+
+func (h *putHook) PreApply(r *pb.InternalRaftRequest) error {
+	if r.Put != nil && string(r.Put.Key) == "forbidden" {
+		return errForbiddenKey
+	}
+	return nil
+}
+
+func (h *putHook) PostApply(r *pb.InternalRaftRequest, resp proto.Message, err error) {
+	if r.Put != nil && err == nil {
+		h.mu.Lock()
+		h.applied++
+		h.mu.Unlock()
+	}
+}
+
+func TestEmbedEtcdHooks(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	var (
+		mu      sync.Mutex
+		unary   []string
+		streams []string
+	)
+	hook := &putHook{}
+	urls := newEmbedURLs(false, 2)
+	cfg := embed.NewConfig()
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.ExperimentalUnaryInterceptors = []grpc.UnaryServerInterceptor{
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			mu.Lock()
+			unary = append(unary, info.FullMethod)
+			mu.Unlock()
+			return handler(ctx, req)
+		},
+	}
+	cfg.ExperimentalStreamInterceptors = []grpc.StreamServerInterceptor{
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			mu.Lock()
+			streams = append(streams, info.FullMethod)
+			mu.Unlock()
+			return handler(srv, ss)
+		},
+	}
+	cfg.ExperimentalApplyHooks = []config.ApplyHook{hook}
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	defer cli.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wch := cli.Watch(ctx, "foo")
+	_, err = cli.Put(ctx, "foo", "bar")
+	require.NoError(t, err)
+	<-wch
+	_, err = cli.Put(ctx, "forbidden", "bar")
+	require.ErrorContains(t, err, errForbiddenKey.Error())
+
+	mu.Lock()
+	assert.Contains(t, unary, "/etcdserverpb.KV/Put")
+	assert.Contains(t, streams, "/etcdserverpb.Watch/Watch")
+	mu.Unlock()
+	hook.mu.Lock()
+	assert.Equal(t, 1, hook.applied)
+	hook.mu.Unlock()
+
+	resp, err := cli.Get(ctx, "forbidden")
+	require.NoError(t, err)
+	assert.Empty(t, resp.Kvs)
+}
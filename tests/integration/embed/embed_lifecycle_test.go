@@ -0,0 +1,95 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+)
+
+func TestEmbedEtcdLifecycle(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(false, 2)
+	cfg := embed.NewConfig()
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	closed := false
+	defer func() {
+		if !closed {
+			e.Close()
+		}
+	}()
+
+	ch := e.Server.SubscribeLifecycle(context.Background())
+	var states []etcdserver.LifecycleState
+	waitFor := func(st etcdserver.LifecycleState) {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case tr, ok := <-ch:
+				require.True(t, ok, "lifecycle channel closed before %s, got %v", st, states)
+				assert.NotEmpty(t, tr.Reason)
+				states = append(states, tr.To)
+				if tr.To == st {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s, got %v", st, states)
+			}
+		}
+	}
+	waitFor(etcdserver.LifecycleServing)
+
+	// the client URLs of the embed tests are unix sockets
+	hc := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", urls[0].Host)
+		},
+	}}
+	resp, err := hc.Get("http://localhost/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	hc.CloseIdleConnections()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	e.Close()
+	closed = true
+	waitFor(etcdserver.LifecycleStopped)
+	_, ok := <-ch
+	assert.False(t, ok, "expected the lifecycle channel to be closed once stopped")
+	assert.Contains(t, states, etcdserver.LifecycleDraining)
+	for i := 1; i < len(states); i++ {
+		assert.LessOrEqual(t, states[i-1], states[i], "lifecycle moved backwards: %v", states)
+	}
+	assert.Equal(t, etcdserver.LifecycleStopped, e.Server.Lifecycle().To)
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+const whoAmIMethod = "/embedtest.WhoAmI/Get"
+
+// whoAmIServiceDesc describes a user service returning the name of the
+// authenticated etcd user as the single role of an AuthUserGetResponse.
+var whoAmIServiceDesc = grpc.ServiceDesc{
+	ServiceName: "embedtest.WhoAmI",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Get",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &pb.AuthUserGetRequest{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				ai, err := embed.AuthInfoFromContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				resp := &pb.AuthUserGetResponse{}
+				if ai != nil {
+					resp.Roles = []string{ai.Username}
+				}
+				return resp, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: whoAmIMethod}, handler)
+		},
+	}},
+}
+
+func TestEmbedEtcdUserServiceAuthInfo(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(false, 2)
+	cfg := embed.NewConfig()
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.ServiceRegister = func(s *grpc.Server) {
+		s.RegisterService(&whoAmIServiceDesc, struct{}{})
+	}
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	whoAmI := func(cli *clientv3.Client) []string {
+		resp := &pb.AuthUserGetResponse{}
+		require.NoError(t, cli.ActiveConnection().Invoke(context.TODO(), whoAmIMethod, &pb.AuthUserGetRequest{}, resp))
+		return resp.Roles
+	}
+
+	ctx := context.TODO()
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	defer cli.Close()
+	assert.Empty(t, whoAmI(cli), "expected no user with authentication disabled")
+
+	_, err = cli.RoleAdd(ctx, "root")
+	require.NoError(t, err)
+	_, err = cli.UserAdd(ctx, "root", "123")
+	require.NoError(t, err)
+	_, err = cli.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = cli.AuthEnable(ctx)
+	require.NoError(t, err)
+
+	rootCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}, Username: "root", Password: "123"})
+	require.NoError(t, err)
+	defer rootCli.Close()
+	assert.Equal(t, []string{"root"}, whoAmI(rootCli))
+
+	anonCli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+	require.NoError(t, err)
+	defer anonCli.Close()
+	assert.Empty(t, whoAmI(anonCli), "expected no user without credentials")
+
+	_, err = embed.AuthInfoFromContext(context.TODO())
+	assert.ErrorIs(t, err, embed.ErrNotUserService)
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cluster_proxy
+
+package embed_test
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/testutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/featuregate"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func TestEmbedEtcdValueParts(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	tcs := []struct {
+		name         string
+		featureGates string
+		expectErr    error
+	}{
+		{name: "ValueChunking enabled", featureGates: "ValueChunking=true"},
+		{name: "ValueChunking disabled", expectErr: rpctypes.ErrNotCapable},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			urls := newEmbedURLs(false, 2)
+			cfg := embed.NewConfig()
+			setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+			cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+			cfg.MaxRequestBytes = 64 * 1024
+			if tc.featureGates != "" {
+				require.NoError(t, cfg.ServerFeatureGate.(featuregate.MutableFeatureGate).Set(tc.featureGates))
+				cfg.ExperimentalMaxChunkedValueBytes = 1024 * 1024
+			}
+			e, err := embed.StartEtcd(cfg)
+			require.NoError(t, err)
+			defer e.Close()
+			<-e.Server.ReadyNotify()
+
+			cli, err := clientv3.New(clientv3.Config{Endpoints: []string{urls[0].String()}})
+			require.NoError(t, err)
+			defer cli.Close()
+			ctx := context.TODO()
+
+			value := string(bytes.Repeat([]byte("v"), 300*1024))
+			_, err = cli.Put(ctx, "foo", value)
+			assert.ErrorIs(t, err, rpctypes.ErrRequestTooLarge)
+
+			_, err = cli.Put(ctx, "foo", value, clientv3.WithValueParts(32*1024))
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			resp, err := cli.Get(ctx, "foo")
+			require.NoError(t, err)
+			require.Len(t, resp.Kvs, 1)
+			assert.Equal(t, value, string(resp.Kvs[0].Value))
+
+			// the value cannot exceed the maximum size of the values put in parts
+			_, err = cli.Put(ctx, "bar", value+value+value+value, clientv3.WithValueParts(32*1024))
+			assert.ErrorIs(t, err, rpctypes.ErrRequestTooLarge)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+)
+
+const (
+	rangeMethod = "/etcdserverpb.KV/Range"
+	putMethod   = "/etcdserverpb.KV/Put"
+)
+
+func withPriority(priority string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcproxy.PriorityMetadataKey, priority))
+}
+
+func invoke(ctx context.Context, interceptor grpc.UnaryServerInterceptor, method string, handler grpc.UnaryHandler) error {
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return err
+}
+
+func noopHandler(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+func TestProxyAdmissionConcurrencyLimit(t *testing.T) {
+	interceptor := grpcproxy.NewAdmissionUnaryInterceptor(grpcproxy.AdmissionConfig{
+		MaxConcurrentRequests: 1,
+		QueueTimeout:          50 * time.Millisecond,
+	})
+
+	startedc, releasec, donec := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		donec <- invoke(context.Background(), interceptor, putMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(startedc)
+			<-releasec
+			return nil, nil
+		})
+	}()
+	<-startedc
+
+	if err := invoke(context.Background(), interceptor, putMethod, noopHandler); err != rpctypes.ErrGRPCRequestTooManyRequests {
+		t.Fatalf("queued request err = %v, want %v", err, rpctypes.ErrGRPCRequestTooManyRequests)
+	}
+	if err := invoke(withPriority("high"), interceptor, putMethod, noopHandler); err != nil {
+		t.Fatalf("high priority request err = %v, want nil", err)
+	}
+
+	// a queued request is served once the slot is released
+	queuedc := make(chan error)
+	go func() {
+		queuedc <- invoke(context.Background(), interceptor, putMethod, noopHandler)
+	}()
+	close(releasec)
+	if err := <-donec; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-queuedc; err != nil {
+		t.Fatalf("queued request err = %v, want nil", err)
+	}
+}
+
+func TestProxyAdmissionShedLowPriority(t *testing.T) {
+	interceptor := grpcproxy.NewAdmissionUnaryInterceptor(grpcproxy.AdmissionConfig{
+		ShedLatencyThreshold: 10 * time.Millisecond,
+		LowPriorityMethods:   []string{rangeMethod},
+	})
+
+	// a slow backend
+	err := invoke(context.Background(), interceptor, putMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = invoke(context.Background(), interceptor, rangeMethod, noopHandler); err != rpctypes.ErrGRPCRequestTooManyRequests {
+		t.Fatalf("low priority method err = %v, want %v", err, rpctypes.ErrGRPCRequestTooManyRequests)
+	}
+	if err = invoke(withPriority("low"), interceptor, putMethod, noopHandler); err != rpctypes.ErrGRPCRequestTooManyRequests {
+		t.Fatalf("low priority metadata err = %v, want %v", err, rpctypes.ErrGRPCRequestTooManyRequests)
+	}
+	if err = invoke(withPriority("high"), interceptor, rangeMethod, noopHandler); err != nil {
+		t.Fatalf("high priority metadata err = %v, want nil", err)
+	}
+
+	// the low priority requests are admitted again to observe the recovery
+	// of the backend
+	time.Sleep(1100 * time.Millisecond)
+	if err = invoke(context.Background(), interceptor, rangeMethod, noopHandler); err != nil {
+		t.Fatalf("low priority request err = %v, want nil", err)
+	}
+}
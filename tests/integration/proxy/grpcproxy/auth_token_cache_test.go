@@ -0,0 +1,136 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestProxyAuthTokenCache(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	// the simple tokens expire after a second unused
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, AuthTokenTTL: 1})
+	defer clus.Terminate(t)
+	enableRootAuth(t, clus.Client(0))
+
+	auth, kv := newAuthTokenCacheProxy(t, clus.Members[0].GRPCURL(), time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// the clients authenticating as the same user share a token
+	resp, err := auth.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"})
+	require.NoError(t, err)
+	resp2, err := auth.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"})
+	require.NoError(t, err)
+	require.Equal(t, resp.Token, resp2.Token)
+	_, err = auth.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "wrong"})
+	require.ErrorIs(t, err, rpctypes.ErrGRPCAuthFailed)
+
+	_, err = kv.Range(withToken(ctx, resp.Token), &pb.RangeRequest{Key: []byte("foo")})
+	require.NoError(t, err)
+
+	// the requests failing on the expired backend token are retried with a
+	// new one
+	time.Sleep(3 * time.Second)
+	_, err = kv.Range(withToken(ctx, resp.Token), &pb.RangeRequest{Key: []byte("foo")})
+	require.NoError(t, err)
+}
+
+func TestProxyAuthTokenCacheRefresh(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	enableRootAuth(t, clus.Client(0))
+
+	auth, kv := newAuthTokenCacheProxy(t, clus.Members[0].GRPCURL(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := auth.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"})
+	require.NoError(t, err)
+
+	// the aged token is refreshed, the former token of the session being
+	// still served
+	time.Sleep(600 * time.Millisecond)
+	resp2, err := auth.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"})
+	require.NoError(t, err)
+	require.NotEqual(t, resp.Token, resp2.Token)
+	_, err = kv.Range(withToken(ctx, resp.Token), &pb.RangeRequest{Key: []byte("foo")})
+	require.NoError(t, err)
+	_, err = kv.Range(withToken(ctx, resp2.Token), &pb.RangeRequest{Key: []byte("foo")})
+	require.NoError(t, err)
+}
+
+func enableRootAuth(t *testing.T, c *clientv3.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := c.UserAdd(ctx, "root", "123")
+	require.NoError(t, err)
+	_, err = c.UserGrantRole(ctx, "root", "root")
+	require.NoError(t, err)
+	_, err = c.AuthEnable(ctx)
+	require.NoError(t, err)
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, rpctypes.TokenFieldNameGRPC, token)
+}
+
+// newAuthTokenCacheProxy serves the auth and the kv proxies of a client
+// forwarding the auth tokens through a token cache.
+func newAuthTokenCacheProxy(t *testing.T, endpoint string, refreshInterval time.Duration) (pb.AuthClient, pb.KVClient) {
+	tc := grpcproxy.NewAuthTokenCache(zaptest.NewLogger(t), refreshInterval)
+	backend, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{
+			grpc.WithUnaryInterceptor(tc.UnaryClientInterceptor),
+			grpc.WithStreamInterceptor(tc.StreamClientInterceptor),
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { backend.Close() })
+
+	kvp, _ := grpcproxy.NewKvProxy(backend)
+	server := grpc.NewServer()
+	pb.RegisterAuthServer(server, grpcproxy.NewAuthProxyWithTokenCache(backend, tc))
+	pb.RegisterKVServer(server, kvp)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewAuthClient(conn), pb.NewKVClient(conn)
+}
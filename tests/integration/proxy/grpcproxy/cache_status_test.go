@@ -0,0 +1,145 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestProxyCacheStatus(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	backend, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{clus.Members[0].GRPCURL()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	kvp, _ := grpcproxy.NewKvProxy(backend)
+	watchp, _ := grpcproxy.NewWatchProxy(backend.Ctx(), zaptest.NewLogger(t), backend)
+	cacheStatus := grpcproxy.NewCacheStatusHandler()
+	cacheStatus.Register(kvp, watchp)
+
+	server := grpc.NewServer()
+	pb.RegisterKVServer(server, kvp)
+	pb.RegisterWatchServer(server, watchp)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	defer server.Stop()
+
+	mux := http.NewServeMux()
+	grpcproxy.HandleProxyCache(mux, cacheStatus)
+	hs := httptest.NewServer(mux)
+	defer hs.Close()
+
+	client, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{l.Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a miss caching the range, then a hit
+	for i := 0; i < 2; i++ {
+		if _, err = client.Get(ctx, "foo", clientv3.WithSerializable()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// two watchers on the same key share a broadcast
+	for i := 0; i < 2; i++ {
+		wch := client.Watch(ctx, "foo", clientv3.WithCreatedNotify())
+		if wr := <-wch; !wr.Created {
+			t.Fatalf("expected created watch response, got %+v", wr)
+		}
+	}
+
+	var cs grpcproxy.CacheStatus
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cs = getCacheStatus(t, hs.URL)
+		if len(cs.WatchGroups) == 1 || time.Now().After(deadline) {
+			break
+		}
+		// the watchers are coalesced once the broadcast responded
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if len(cs.WatchGroups) != 1 {
+		t.Fatalf("watch groups = %+v, want 1 group", cs.WatchGroups)
+	}
+	if g := cs.WatchGroups[0]; g.Key != "foo" || g.Subscribers != 2 {
+		t.Errorf("watch group = %+v, want key foo with 2 subscribers", g)
+	}
+	if cs.Watchers != 2 {
+		t.Errorf("watchers = %d, want 2", cs.Watchers)
+	}
+	if cs.CachedRanges != 1 || cs.CacheHits != 1 || cs.CacheMisses != 1 || cs.CacheHitRatio != 0.5 {
+		t.Errorf("cache status = %+v, want 1 cached range, 1 hit and 1 miss", cs)
+	}
+
+	resp, err := http.Post(hs.URL+grpcproxy.PathProxyCache, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST status code = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func getCacheStatus(t *testing.T, url string) grpcproxy.CacheStatus {
+	resp, err := http.Get(url + grpcproxy.PathProxyCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var cs grpcproxy.CacheStatus
+	if err = json.NewDecoder(resp.Body).Decode(&cs); err != nil {
+		t.Fatal(err)
+	}
+	return cs
+}
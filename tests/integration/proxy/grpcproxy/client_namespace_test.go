@@ -0,0 +1,131 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestProxyClientNamespace(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, name := range []string{"alice", "bob"} {
+		_, err := clus.Client(0).UserAdd(ctx, name, "123")
+		require.NoError(t, err)
+		_, err = clus.Client(0).UserGrantRole(ctx, name, "root")
+		require.NoError(t, err)
+	}
+	enableRootAuth(t, clus.Client(0))
+
+	lg := zaptest.NewLogger(t)
+	tc := grpcproxy.NewAuthTokenCache(lg, time.Minute)
+	backend, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{clus.Members[0].GRPCURL()},
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{
+			grpc.WithUnaryInterceptor(tc.UnaryClientInterceptor),
+			grpc.WithStreamInterceptor(tc.StreamClientInterceptor),
+		},
+	})
+	require.NoError(t, err)
+	defer backend.Close()
+
+	cn := grpcproxy.NewClientNamespaces(grpcproxy.ClientNamespaceConfig{
+		Users:      map[string]string{"alice": "/alice/", "bob": "/bob/"},
+		TokenCache: tc,
+	})
+	kvp, _ := grpcproxy.NewKvProxy(backend)
+	watchp, _ := grpcproxy.NewWatchProxy(backend.Ctx(), lg, backend)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(cn.UnaryServerInterceptor),
+		grpc.StreamInterceptor(cn.StreamServerInterceptor),
+	)
+	pb.RegisterAuthServer(server, grpcproxy.NewAuthProxyWithTokenCache(backend, tc))
+	pb.RegisterKVServer(server, kvp)
+	pb.RegisterWatchServer(server, watchp)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(l)
+	defer server.Stop()
+
+	alice := newNamespaceClient(t, l.Addr().String(), "alice")
+	defer alice.Close()
+	bob := newNamespaceClient(t, l.Addr().String(), "bob")
+	defer bob.Close()
+	root := newNamespaceClient(t, l.Addr().String(), "root")
+	defer root.Close()
+
+	wch := alice.Watch(ctx, "k", clientv3.WithPrefix())
+	_, err = alice.Put(ctx, "k1", "a")
+	require.NoError(t, err)
+	_, err = bob.Put(ctx, "k1", "b")
+	require.NoError(t, err)
+
+	// the clients see the keys of their namespaces only
+	resp, err := alice.Get(ctx, "", clientv3.WithFromKey())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.Equal(t, "k1", string(resp.Kvs[0].Key))
+	require.Equal(t, "a", string(resp.Kvs[0].Value))
+	tresp, err := bob.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value("k1"), "=", "b")).
+		Then(clientv3.OpGet("k1")).
+		Commit()
+	require.NoError(t, err)
+	require.True(t, tresp.Succeeded)
+	require.Equal(t, "k1", string(tresp.Responses[0].GetResponseRange().Kvs[0].Key))
+
+	wresp := <-wch
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, "k1", string(wresp.Events[0].Kv.Key))
+
+	// the clients without a namespace see all the keys
+	resp, err = root.Get(ctx, "/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 2)
+	require.Equal(t, "/alice/k1", string(resp.Kvs[0].Key))
+	require.Equal(t, "/bob/k1", string(resp.Kvs[1].Key))
+
+	_, err = alice.Compact(ctx, resp.Header.Revision)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func newNamespaceClient(t *testing.T, endpoint, user string) *clientv3.Client {
+	c, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 5 * time.Second,
+		Username:    user,
+		Password:    "123",
+	})
+	require.NoError(t, err)
+	return c
+}
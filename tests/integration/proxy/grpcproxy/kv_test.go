@@ -53,6 +53,71 @@ func TestKVProxyRange(t *testing.T) {
 	client.Close()
 }
 
+func TestKVProxyCacheInvalidation(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvts := newKVProxyServerWithCache([]string{clus.Members[0].GRPCURL()}, t, grpcproxy.KvCacheConfig{
+		MaxStaleness:  time.Second,
+		WatchPrefixes: []string{"/watched/"},
+	})
+	defer kvts.close()
+
+	client, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{kvts.l.Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	direct := clus.Client(0)
+	for _, key := range []string{"/watched/k", "/other/k"} {
+		if _, err = direct.Put(ctx, key, "v1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(key string) string {
+		resp, gerr := client.Get(ctx, key, clientv3.WithSerializable())
+		if gerr != nil {
+			t.Fatal(gerr)
+		}
+		return string(resp.Kvs[0].Value)
+	}
+
+	// cache the responses, then write behind the proxy
+	start := time.Now()
+	for _, key := range []string{"/watched/k", "/other/k"} {
+		if v := get(key); v != "v1" {
+			t.Fatalf("value of %q = %q, want v1", key, v)
+		}
+		if _, err = direct.Put(ctx, key, "v2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the watched keys are invalidated by their changes
+	for v := get("/watched/k"); v != "v2"; v = get("/watched/k") {
+		if time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("value of /watched/k = %q, want v2", v)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v := get("/other/k"); v != "v1" && time.Since(start) < time.Second {
+		t.Fatalf("value of /other/k = %q, want the cached v1", v)
+	}
+
+	// the other keys are served until their maximum staleness
+	time.Sleep(time.Until(start.Add(time.Second + 100*time.Millisecond)))
+	if v := get("/other/k"); v != "v2" {
+		t.Fatalf("value of /other/k = %q, want v2", v)
+	}
+}
+
 type kvproxyTestServer struct {
 	kp     pb.KVServer
 	c      *clientv3.Client
@@ -67,6 +132,10 @@ func (kts *kvproxyTestServer) close() {
 }
 
 func newKVProxyServer(endpoints []string, t *testing.T) *kvproxyTestServer {
+	return newKVProxyServerWithCache(endpoints, t, grpcproxy.KvCacheConfig{})
+}
+
+func newKVProxyServerWithCache(endpoints []string, t *testing.T, cacheCfg grpcproxy.KvCacheConfig) *kvproxyTestServer {
 	cfg := clientv3.Config{
 		Endpoints:   endpoints,
 		DialTimeout: 5 * time.Second,
@@ -76,7 +145,7 @@ func newKVProxyServer(endpoints []string, t *testing.T) *kvproxyTestServer {
 		t.Fatal(err)
 	}
 
-	kvp, _ := grpcproxy.NewKvProxy(client)
+	kvp, _ := grpcproxy.NewKvProxyWithCache(client, cacheCfg)
 
 	kvts := &kvproxyTestServer{
 		kp: kvp,
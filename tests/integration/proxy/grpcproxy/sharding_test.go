@@ -0,0 +1,139 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+func TestProxySharding(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clusDefault := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clusDefault.Terminate(t)
+	// the members of the clusters listen on tcp not to share their unix socket names
+	clusShard := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusShard.Terminate(t)
+
+	backend := newShardingClient(t, clusDefault.Members[0].GRPCURL())
+	defer backend.Close()
+	shardBackend := newShardingClient(t, clusShard.Members[0].GRPCURL())
+	defer shardBackend.Close()
+
+	lg := zaptest.NewLogger(t)
+	shards := []grpcproxy.Shard{{Prefix: "/b/", Client: shardBackend}}
+	backend.KV = grpcproxy.NewShardedKV(backend.KV, shards)
+	backend.Watcher = grpcproxy.NewShardedWatcher(backend.Watcher, shards)
+	kvp, _ := grpcproxy.NewKvProxy(backend)
+	watchp, _ := grpcproxy.NewWatchProxy(backend.Ctx(), lg, backend)
+	clusterp, _ := grpcproxy.NewClusterProxy(lg, backend, "", "")
+	mainp := grpcproxy.NewMaintenanceProxy(backend)
+
+	server := grpc.NewServer()
+	pb.RegisterKVServer(server, kvp)
+	pb.RegisterWatchServer(server, watchp)
+	pb.RegisterClusterServer(server, grpcproxy.NewShardedClusterProxy(clusterp, shards))
+	pb.RegisterMaintenanceServer(server, grpcproxy.NewShardedMaintenanceProxy(mainp, shards))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(l)
+	defer server.Stop()
+
+	client := newShardingClient(t, l.Addr().String())
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wch := client.Watch(ctx, "/b/", clientv3.WithPrefix())
+
+	_, err = client.Put(ctx, "/a/1", "a")
+	require.NoError(t, err)
+	_, err = client.Put(ctx, "/b/1", "b")
+	require.NoError(t, err)
+
+	// the keys are stored by the clusters of their shards
+	direct := clusShard.Client(0)
+	resp, err := direct.Get(ctx, "/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.Equal(t, "/b/1", string(resp.Kvs[0].Key))
+	resp, err = clusDefault.Client(0).Get(ctx, "/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.Equal(t, "/a/1", string(resp.Kvs[0].Key))
+
+	wresp := <-wch
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, "/b/1", string(wresp.Events[0].Kv.Key))
+
+	resp, err = client.Get(ctx, "/b/", clientv3.WithPrefix())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+
+	// a txn within a shard
+	tresp, err := client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value("/b/1"), "=", "b")).
+		Then(clientv3.OpPut("/b/2", "b")).
+		Commit()
+	require.NoError(t, err)
+	require.True(t, tresp.Succeeded)
+
+	// requests across the shards
+	_, err = client.Get(ctx, "/", clientv3.WithPrefix())
+	require.ErrorContains(t, err, "request spans multiple shards")
+	_, err = client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value("/b/1"), "=", "b")).
+		Then(clientv3.OpPut("/a/2", "a")).
+		Commit()
+	require.ErrorContains(t, err, "request spans multiple shards")
+	_, err = client.Compact(ctx, 1)
+	require.ErrorContains(t, err, "request spans multiple shards")
+	cwch := client.Watch(ctx, "/", clientv3.WithPrefix())
+	wresp = <-cwch
+	require.True(t, wresp.Canceled)
+
+	// the responses of the clusters are merged
+	mresp, err := client.MemberList(ctx)
+	require.NoError(t, err)
+	require.Len(t, mresp.Members, 2)
+	sresp, err := client.Status(ctx, l.Addr().String())
+	require.NoError(t, err)
+	require.Empty(t, sresp.Errors)
+	dresp, err := direct.Status(ctx, clusShard.Members[0].GRPCURL())
+	require.NoError(t, err)
+	require.Greater(t, sresp.DbSize, dresp.DbSize)
+}
+
+func newShardingClient(t *testing.T, endpoint string) *clientv3.Client {
+	c, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{endpoint},
+		DialTimeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	return c
+}
@@ -15,7 +15,9 @@
 package snapshot_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -137,6 +139,300 @@ func TestSnapshotV3RestoreMulti(t *testing.T) {
 	}
 }
 
+// TestSnapshotV3Slim ensures that a slimmed snapshot only retains
+// the latest revision of each key and can be restored.
+func TestSnapshotV3Slim(t *testing.T) {
+	integration2.BeforeTest(t)
+	kvs := []kv{{"foo1", "bar1"}, {"foo1", "bar2"}, {"foo2", "bar3"}}
+	dbPath := createSnapshotFile(t, kvs)
+
+	slimPath := filepath.Join(t.TempDir(), "slim.db")
+	sp := snapshot.NewV3(zaptest.NewLogger(t))
+	if err := sp.Slim(snapshot.SlimConfig{SnapshotPath: dbPath, OutputPath: slimPath}); err != nil {
+		t.Fatal(err)
+	}
+
+	cURLs, _, srvs := restoreCluster(t, 1, slimPath)
+	defer srvs[0].Close()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{cURLs[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	gresp, err := cli.Get(context.Background(), "foo", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 2 || string(gresp.Kvs[0].Value) != "bar2" || string(gresp.Kvs[1].Value) != "bar3" {
+		t.Fatalf("unexpected kvs after slim: %v", gresp.Kvs)
+	}
+	if _, err = cli.Get(context.Background(), "foo1", clientv3.WithRev(2)); err == nil || !strings.Contains(err.Error(), "compacted") {
+		t.Fatalf("expected historical revision to be compacted, got %v", err)
+	}
+}
+
+// TestSnapshotV3RestoreFiltered ensures that restoring a snapshot with
+// prefix and revision filters only restores the selected key space.
+func TestSnapshotV3RestoreFiltered(t *testing.T) {
+	integration2.BeforeTest(t)
+	// revisions 2, 3, 4 and 5
+	kvs := []kv{{"/a/1", "v1"}, {"/a/1", "v2"}, {"/b/1", "v3"}, {"/a/x/1", "v4"}}
+	dbPath := createSnapshotFile(t, kvs)
+
+	cURLs, _, srvs := restoreClusterWithConfig(t, 1, dbPath, func(cfg *snapshot.RestoreConfig) {
+		cfg.IncludePrefixes = []string{"/a/"}
+		cfg.ExcludePrefixes = []string{"/a/x/"}
+		cfg.Revision = 4
+	})
+	defer srvs[0].Close()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{cURLs[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	gresp, err := cli.Get(context.Background(), "/", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 1 || string(gresp.Kvs[0].Key) != "/a/1" || string(gresp.Kvs[0].Value) != "v2" {
+		t.Fatalf("unexpected kvs after filtered restore: %v", gresp.Kvs)
+	}
+	if gresp.Header.Revision != 3 {
+		t.Fatalf("expected restored revision 3, got %d", gresp.Header.Revision)
+	}
+}
+
+// TestSnapshotV3EncryptDecrypt ensures that an encrypted snapshot can only be
+// read once decrypted with the right key and that tampering is detected.
+func TestSnapshotV3EncryptDecrypt(t *testing.T) {
+	integration2.BeforeTest(t)
+	kvs := []kv{{"foo1", "bar1"}, {"foo2", "bar2"}, {"foo3", "bar3"}}
+	dbPath := createSnapshotFile(t, kvs)
+
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, snapshot.EncryptionKeySize)
+	encPath := filepath.Join(dir, "snapshot.db.enc")
+	sp := snapshot.NewV3(zaptest.NewLogger(t))
+	if err := sp.Encrypt(snapshot.EncryptConfig{SnapshotPath: dbPath, OutputPath: encPath, Key: key}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sp.Status(encPath); !errors.Is(err, snapshot.ErrEncrypted) {
+		t.Fatalf("expected %v, got %v", snapshot.ErrEncrypted, err)
+	}
+
+	wrongKey := bytes.Repeat([]byte{2}, snapshot.EncryptionKeySize)
+	err := sp.Decrypt(snapshot.EncryptConfig{SnapshotPath: encPath, OutputPath: filepath.Join(dir, "wrong.db"), Key: wrongKey})
+	if !errors.Is(err, snapshot.ErrDecrypt) {
+		t.Fatalf("expected %v with a wrong key, got %v", snapshot.ErrDecrypt, err)
+	}
+
+	decPath := filepath.Join(dir, "snapshot.db")
+	if err = sp.Decrypt(snapshot.EncryptConfig{SnapshotPath: encPath, OutputPath: decPath, Key: key}); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(decPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatal("decrypted snapshot differs from the original one")
+	}
+
+	enc, err := os.ReadFile(encPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamperedPath := filepath.Join(dir, "tampered.db.enc")
+	if err = os.WriteFile(tamperedPath, enc[:len(enc)-1], 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = sp.Decrypt(snapshot.EncryptConfig{SnapshotPath: tamperedPath, OutputPath: filepath.Join(dir, "tampered.db"), Key: key})
+	if !errors.Is(err, snapshot.ErrDecrypt) {
+		t.Fatalf("expected %v with a truncated file, got %v", snapshot.ErrDecrypt, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "tampered.db")); !os.IsNotExist(err) {
+		t.Fatalf("expected no output file after a failed decryption, got %v", err)
+	}
+}
+
+// TestSnapshotV3ExportImport ensures that a snapshot exported to JSON and
+// imported back restores the latest state of the key space.
+func TestSnapshotV3ExportImport(t *testing.T) {
+	integration2.BeforeTest(t)
+	// revisions 2, 3 and 4
+	kvs := []kv{{"foo1", "bar1"}, {"foo1", "bar2"}, {"foo2", "bar3"}}
+	dbPath := createSnapshotFile(t, kvs)
+
+	dir := t.TempDir()
+	sp := snapshot.NewV3(zaptest.NewLogger(t))
+	var exported bytes.Buffer
+	if err := sp.Export(snapshot.ExportConfig{SnapshotPath: dbPath, Output: &exported}); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "dump.json")
+	if err := os.WriteFile(jsonPath, exported.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	importPath := filepath.Join(dir, "imported.db")
+	if err := sp.Import(snapshot.ImportConfig{InputPath: jsonPath, OutputPath: importPath}); err != nil {
+		t.Fatal(err)
+	}
+
+	var reexported bytes.Buffer
+	if err := sp.Export(snapshot.ExportConfig{SnapshotPath: importPath, Output: &reexported}); err != nil {
+		t.Fatal(err)
+	}
+	if exported.String() != reexported.String() {
+		t.Fatalf("expected the imported snapshot to export the same JSON, got\n%s\nwant\n%s", reexported.String(), exported.String())
+	}
+
+	cURLs, _, srvs := restoreCluster(t, 1, importPath)
+	defer srvs[0].Close()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{cURLs[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	gresp, err := cli.Get(context.Background(), "foo", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 2 || string(gresp.Kvs[0].Value) != "bar2" || gresp.Kvs[0].Version != 2 || string(gresp.Kvs[1].Value) != "bar3" {
+		t.Fatalf("unexpected kvs after import: %v", gresp.Kvs)
+	}
+	if gresp.Header.Revision != 4 {
+		t.Fatalf("expected imported revision 4, got %d", gresp.Header.Revision)
+	}
+}
+
+// TestSnapshotV3RestoreIncremental ensures that a snapshot restored with the
+// incremental backups taken after it contains the changes made since the
+// snapshot.
+func TestSnapshotV3RestoreIncremental(t *testing.T) {
+	integration2.BeforeTest(t)
+	testutil.SkipTestIfShortMode(t,
+		"Snapshot creation tests are depending on embedded etcd server so are integration-level tests.")
+
+	urls := newEmbedURLs(t, 2)
+	cfg := integration2.NewEmbedConfig(t, "default")
+	cfg.ClusterState = "new"
+	cfg.LCUrls, cfg.ACUrls = urls[:1], urls[:1]
+	cfg.LPUrls, cfg.APUrls = urls[1:], urls[1:]
+	cfg.InitialCluster = fmt.Sprintf("%s=%s", cfg.Name, urls[1].String())
+	srv, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	select {
+	case <-srv.Server.ReadyNotify():
+	case <-time.After(3 * time.Second):
+		t.Fatalf("failed to start embed.Etcd for creating snapshots")
+	}
+
+	ccfg := clientv3.Config{Endpoints: []string{cfg.ACUrls[0].String()}}
+	cli, err := integration2.NewClient(t, ccfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	mustPut := func(k, v string) {
+		if _, err := cli.Put(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustPut("foo1", "bar1")
+	mustPut("foo2", "bar2")
+	dir := t.TempDir()
+	sp := snapshot.NewV3(zaptest.NewLogger(t))
+	fullPath := filepath.Join(dir, "full.db")
+	if _, err = sp.Save(ctx, ccfg, fullPath); err != nil {
+		t.Fatal(err)
+	}
+	sinceIndex, err := snapshot.ConsistentIndex(fullPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustPut("foo1", "bar10")
+	if _, err = cli.Delete(ctx, "foo2"); err != nil {
+		t.Fatal(err)
+	}
+	// the commit index of the last entry is persisted to the WAL along with
+	// the next entry, after the response to the client
+	mustPut("sync", "")
+	inc1 := filepath.Join(dir, "inc1.bak")
+	info, err := snapshot.CreateIncrementalBackup(zaptest.NewLogger(t), snapshot.IncrementalBackupConfig{
+		WALDir: filepath.Join(cfg.Dir, "member", "wal"), SinceIndex: sinceIndex, OutputPath: inc1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lresp, err := cli.Grant(ctx, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(ctx, "foo3", "bar3", clientv3.WithLease(lresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	mustPut("sync", "")
+	inc2 := filepath.Join(dir, "inc2.bak")
+	if _, err = snapshot.CreateIncrementalBackup(zaptest.NewLogger(t), snapshot.IncrementalBackupConfig{
+		WALDir: filepath.Join(cfg.Dir, "member", "wal"), SinceIndex: info.LastIndex, OutputPath: inc2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// incremental backups must be applied in order
+	outOfOrderDir := filepath.Join(dir, "out-of-order.etcd")
+	err = sp.Restore(snapshot.RestoreConfig{
+		SnapshotPath:           fullPath,
+		Name:                   "m0",
+		OutputDataDir:          outOfOrderDir,
+		PeerURLs:               []string{"http://127.0.0.1:2380"},
+		InitialCluster:         "m0=http://127.0.0.1:2380",
+		InitialClusterToken:    testClusterTkn,
+		IncrementalBackupPaths: []string{inc2},
+	})
+	if err == nil || !strings.Contains(err.Error(), "continuous chain") {
+		t.Fatalf("expected a gap error, got %v", err)
+	}
+
+	cURLs, _, srvs := restoreClusterWithConfig(t, 1, fullPath, func(cfg *snapshot.RestoreConfig) {
+		cfg.IncrementalBackupPaths = []string{inc1, inc2}
+	})
+	defer srvs[0].Close()
+
+	rcli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{cURLs[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rcli.Close()
+	gresp, err := rcli.Get(ctx, "foo", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 2 || string(gresp.Kvs[0].Value) != "bar10" || string(gresp.Kvs[1].Key) != "foo3" {
+		t.Fatalf("unexpected kvs after incremental restore: %v", gresp.Kvs)
+	}
+	if gresp.Kvs[1].Lease != int64(lresp.ID) {
+		t.Fatalf("expected foo3 to be attached to lease %x, got %x", lresp.ID, gresp.Kvs[1].Lease)
+	}
+}
+
 // TestCorruptedBackupFileCheck tests if we can correctly identify a corrupted backup file.
 func TestCorruptedBackupFileCheck(t *testing.T) {
 	dbPath := testutils.MustAbsPath("testdata/corrupted_backup.db")
@@ -220,6 +516,15 @@ func createSnapshotFile(t *testing.T, kvs []kv) string {
 const testClusterTkn = "tkn"
 
 func restoreCluster(t *testing.T, clusterN int, dbPath string) (
+	cURLs []url.URL,
+	pURLs []url.URL,
+	srvs []*embed.Etcd) {
+	return restoreClusterWithConfig(t, clusterN, dbPath, func(*snapshot.RestoreConfig) {})
+}
+
+// restoreClusterWithConfig is like restoreCluster, but calls update on the
+// restore configuration of each member before restoring it.
+func restoreClusterWithConfig(t *testing.T, clusterN int, dbPath string, update func(*snapshot.RestoreConfig)) (
 	cURLs []url.URL,
 	pURLs []url.URL,
 	srvs []*embed.Etcd) {
@@ -244,14 +549,16 @@ func restoreCluster(t *testing.T, clusterN int, dbPath string) (
 		sp := snapshot.NewV3(
 			zaptest.NewLogger(t, zaptest.Level(zapcore.InfoLevel)).Named(cfg.Name).Named("sm"))
 
-		if err := sp.Restore(snapshot.RestoreConfig{
+		rcfg := snapshot.RestoreConfig{
 			SnapshotPath:        dbPath,
 			Name:                cfg.Name,
 			OutputDataDir:       cfg.Dir,
 			PeerURLs:            []string{pURLs[i].String()},
 			InitialCluster:      ics,
 			InitialClusterToken: cfg.InitialClusterToken,
-		}); err != nil {
+		}
+		update(&rcfg)
+		if err := sp.Restore(rcfg); err != nil {
 			t.Fatal(err)
 		}
 
@@ -16,6 +16,7 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -138,6 +139,77 @@ func TestV3StorageQuotaApply(t *testing.T) {
 	}
 }
 
+// TestV3StorageQuotaGrow tests the V3 server grows the quota up to the hard
+// cap with a warning alarm instead of refusing writes.
+func TestV3StorageQuotaGrow(t *testing.T) {
+	integration.BeforeTest(t)
+	quotasize := int64(16 * os.Getpagesize())
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	clus.Members[0].QuotaBackendBytes = quotasize
+	clus.Members[0].MaxQuotaBackendBytes = 16 * quotasize
+	clus.Members[0].Stop(t)
+	clus.Members[0].Restart(t)
+	clus.WaitMembersForLeader(t, clus.Members)
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+	waitForRestart(t, kvc)
+
+	// write twice the configured quota
+	buf := make([]byte, 1024)
+	for i := 0; int64(i*len(buf)) < 2*quotasize; i++ {
+		key := []byte(fmt.Sprintf("foo%d", i))
+		if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: key, Value: buf}); err != nil {
+			t.Fatalf("#%d: couldn't put key (%v)", i, err)
+		}
+		clus.Members[0].Server.Backend().ForceCommit()
+	}
+
+	resp, err := clus.Members[0].Server.Alarm(context.TODO(), &pb.AlarmRequest{Action: pb.AlarmRequest_GET})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Alarms) != 1 || resp.Alarms[0].Alarm != pb.AlarmType_QUOTAGROWN {
+		t.Fatalf("expected a single QUOTAGROWN alarm, got %v", resp.Alarms)
+	}
+}
+
+// TestV3StorageQuotaReconfigure ensures the backend quota is enforced as soon as
+// it is changed at runtime.
+func TestV3StorageQuotaReconfigure(t *testing.T) {
+	integration.BeforeTest(t)
+	quotasize := int64(16 * os.Getpagesize())
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+	srv := clus.Members[0].Server
+
+	bigbuf := make([]byte, quotasize)
+	if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: bigbuf}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := srv.RuntimeConfig()
+	rc.QuotaBackendBytes = -1
+	if err := srv.Reconfigure(rc); err == nil {
+		t.Fatal("expected an error disabling the quota at runtime")
+	}
+	rc.QuotaBackendBytes = quotasize
+	if err := srv.Reconfigure(rc); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.QuotaBackendBytes(); got != quotasize {
+		t.Fatalf("quota got %d, expected %d", got, quotasize)
+	}
+
+	_, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("bar"), Value: bigbuf})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCNoSpace) {
+		t.Fatalf("put got %v, expected %v", err, rpctypes.ErrGRPCNoSpace)
+	}
+}
+
 // TestV3AlarmDeactivate ensures that space alarms can be deactivated so puts go through.
 func TestV3AlarmDeactivate(t *testing.T) {
 	integration.BeforeTest(t)
@@ -354,3 +426,52 @@ func TestV3CorruptAlarmWithLeaseCorrupted(t *testing.T) {
 		}
 	}
 }
+
+// TestV3PrefixQuota ensures puts exceeding the quota of a prefix are rejected
+// and that quotas survive member restarts.
+func TestV3PrefixQuota(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+	mt := integration.ToGRPC(clus.Client(0)).Maintenance
+
+	qreq := &pb.PrefixQuotaRequest{Action: pb.PrefixQuotaRequest_SET, Prefix: []byte("foo/"), MaxKeys: 2}
+	if _, err := mt.PrefixQuota(context.TODO(), qreq); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"foo/a", "foo/b", "foo/a", "bar"} {
+		if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte(k), Value: []byte("v")}); err != nil {
+			t.Fatalf("put %q failed: %v", k, err)
+		}
+	}
+	_, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo/c"), Value: []byte("v")})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCPrefixQuotaExceeded) {
+		t.Fatalf("put got %v, expected %v", err, rpctypes.ErrGRPCPrefixQuotaExceeded)
+	}
+
+	clus.Members[0].Stop(t)
+	clus.Members[0].Restart(t)
+	clus.WaitMembersForLeader(t, clus.Members)
+	kvc = integration.ToGRPC(clus.Client(0)).KV
+	mt = integration.ToGRPC(clus.Client(0)).Maintenance
+	waitForRestart(t, kvc)
+
+	resp, err := mt.PrefixQuota(context.TODO(), &pb.PrefixQuotaRequest{Action: pb.PrefixQuotaRequest_GET})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Quotas) != 1 || string(resp.Quotas[0].Prefix) != "foo/" || resp.Quotas[0].UsedKeys != 2 {
+		t.Fatalf("unexpected quotas after restart %+v", resp.Quotas)
+	}
+
+	qreq = &pb.PrefixQuotaRequest{Action: pb.PrefixQuotaRequest_DELETE, Prefix: []byte("foo/")}
+	if _, err = mt.PrefixQuota(context.TODO(), qreq); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo/c"), Value: []byte("v")}); err != nil {
+		t.Fatal(err)
+	}
+}
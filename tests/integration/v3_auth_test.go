@@ -16,11 +16,23 @@ package integration
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/metadata"
+
 	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
@@ -320,6 +332,371 @@ func TestV3AuthWithLeaseAttach(t *testing.T) {
 	}
 }
 
+func TestV3AuthWithLeaseGrantMaxTTL(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	if _, err := integration.ToGRPC(clus.Client(0)).Auth.RoleSetMaxLeaseTTL(context.TODO(), &pb.AuthRoleSetMaxLeaseTTLRequest{Role: "role1", MaxLeaseTTL: 60}); err != nil {
+		t.Fatal(err)
+	}
+
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	if _, err := userc.Grant(context.TODO(), 60); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userc.Grant(context.TODO(), 90); !errors.Is(err, rpctypes.ErrRoleLeaseTTLTooLarge) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrRoleLeaseTTLTooLarge, err)
+	}
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	if _, err := rootc.Grant(context.TODO(), 90); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestV3AuthRoleQuota ensures the requests of the users exceeding the quotas
+// of their roles are rejected.
+func TestV3AuthRoleQuota(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	quota := &pb.AuthRoleSetQuotaRequest{Role: "role1", MaxRequestsPerSecond: 5, MaxWatchStreams: 1, MaxTxnOps: 2}
+	if _, err := integration.ToGRPC(clus.Client(0)).Auth.RoleSetQuota(context.TODO(), quota); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	ops := []clientv3.Op{clientv3.OpPut("k1a", "v"), clientv3.OpPut("k1b", "v"), clientv3.OpPut("k1c", "v")}
+	_, err := userc.Txn(ctx).Then(ops...).Commit()
+	require.ErrorIs(t, err, rpctypes.ErrRoleTxnOps)
+	_, err = userc.Txn(ctx).Then(ops[:2]...).Commit()
+	require.NoError(t, err)
+
+	wc := pb.NewWatchClient(userc.ActiveConnection())
+	watch := func(ctx context.Context) error {
+		ws, werr := wc.Watch(ctx)
+		if werr != nil {
+			return werr
+		}
+		werr = ws.Send(&pb.WatchRequest{RequestUnion: &pb.WatchRequest_CreateRequest{CreateRequest: &pb.WatchCreateRequest{Key: []byte("k1")}}})
+		if werr != nil {
+			return werr
+		}
+		_, werr = ws.Recv()
+		return rpctypes.Error(werr)
+	}
+	wctx, wcancel := context.WithCancel(ctx)
+	require.NoError(t, watch(wctx))
+	require.ErrorIs(t, watch(ctx), rpctypes.ErrRoleWatchStreams)
+	// closing the watch stream releases it
+	wcancel()
+	require.Eventually(t, func() bool {
+		wctx, wcancel = context.WithCancel(ctx)
+		defer wcancel()
+		return watch(wctx) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	for i := 0; ; i++ {
+		if _, err = userc.Get(ctx, "k1"); err != nil {
+			require.ErrorIs(t, err, rpctypes.ErrRoleRequestRate)
+			break
+		}
+		require.Less(t, i, 10, "expected the request rate quota to be exceeded")
+	}
+
+	// the root user has no quota
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	for i := 0; i < 10; i++ {
+		_, err = rootc.Get(ctx, "k1")
+		require.NoError(t, err)
+	}
+}
+
+// TestV3AuthSetBcryptCost ensures the passwords are rehashed with the bcrypt
+// cost raised on the cluster when their users authenticate.
+func TestV3AuthSetBcryptCost(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	_, err := rootc.AuthSetBcryptCost(context.TODO(), bcrypt.MaxCost+1)
+	require.ErrorIs(t, err, rpctypes.ErrInvalidAuthMgmt)
+	_, err = rootc.AuthSetBcryptCost(context.TODO(), bcrypt.MinCost+1)
+	require.NoError(t, err)
+	resp, err := rootc.AuthStatus(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, int32(bcrypt.MinCost+1), resp.BcryptCost)
+
+	as := clus.Members[0].Server.AuthStore()
+	require.True(t, as.NeedsRehash("user1"))
+	authRevision := as.Revision()
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+	_, err = userc.Put(context.TODO(), "k1", "v")
+	require.NoError(t, err)
+	require.False(t, as.NeedsRehash("user1"))
+	// the tokens of the other users are not invalidated by the rehash
+	require.Equal(t, authRevision, as.Revision())
+
+	_, err = rootc.Put(context.TODO(), "k1", "v")
+	require.NoError(t, err)
+}
+
+// TestV3AuthTokenListRevoke ensures the tokens of the users are listed and
+// are invalid once revoked.
+func TestV3AuthTokenListRevoke(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authc := integration.ToGRPC(clus.Client(0)).Auth
+	authSetupUsers(t, authc, users)
+	authSetupRoot(t, authc)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+
+	authenticate := func() string {
+		resp, err := authc.Authenticate(context.TODO(), &pb.AuthenticateRequest{Name: "user1", Password: "user1-123"})
+		require.NoError(t, err)
+		return resp.Token
+	}
+	get := func(token string) error {
+		ctx := metadata.NewOutgoingContext(context.TODO(), metadata.Pairs(rpctypes.TokenFieldNameGRPC, token))
+		_, err := integration.ToGRPC(clus.Client(0)).KV.Range(ctx, &pb.RangeRequest{Key: []byte("k1")})
+		return err
+	}
+
+	token := authenticate()
+	require.NoError(t, get(token))
+	lresp, err := rootc.AuthTokenList(context.TODO(), "user1")
+	require.NoError(t, err)
+	require.Len(t, lresp.Tokens, 1)
+	require.Equal(t, "user1", lresp.Tokens[0].User)
+	require.NotEmpty(t, lresp.Tokens[0].PeerAddress)
+
+	rresp, err := rootc.AuthTokenRevoke(context.TODO(), lresp.Tokens[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rresp.Revoked)
+	require.ErrorIs(t, get(token), rpctypes.ErrGRPCInvalidAuthToken)
+
+	tokens := []string{authenticate(), authenticate()}
+	rresp, err = rootc.AuthTokenRevokeUser(context.TODO(), "user1")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rresp.Revoked)
+	for _, token := range tokens {
+		require.ErrorIs(t, get(token), rpctypes.ErrGRPCInvalidAuthToken)
+	}
+
+	// the tokens are administered by the root only
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+	_, err = userc.AuthTokenList(context.TODO(), "")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+}
+
+// TestV3AuthMaintenanceReadOnlyRole ensures the users with the predefined
+// maintenance-readonly role call the read-only maintenance RPCs only.
+func TestV3AuthMaintenanceReadOnlyRole(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	authc := integration.ToGRPC(clus.Client(0)).Auth
+	_, err := authc.UserAdd(context.TODO(), &pb.AuthUserAddRequest{Name: "monitor", Password: "monitor-123", Options: &authpb.UserAddOptions{NoPassword: false}})
+	require.NoError(t, err)
+	_, err = authc.UserGrantRole(context.TODO(), &pb.AuthUserGrantRoleRequest{User: "monitor", Role: "maintenance-readonly"})
+	require.NoError(t, err)
+	authSetupRoot(t, authc)
+
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "monitor", Password: "monitor-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	ep := clus.Client(0).Endpoints()[0]
+	_, err = c.Status(context.TODO(), ep)
+	require.NoError(t, err)
+	_, err = c.HashKV(context.TODO(), ep, 0)
+	require.NoError(t, err)
+	_, err = c.MemberList(context.TODO())
+	require.NoError(t, err)
+	_, err = c.AlarmList(context.TODO())
+	require.NoError(t, err)
+
+	_, err = c.Get(context.TODO(), "foo")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+	_, err = c.Put(context.TODO(), "foo", "bar")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+	_, err = c.Defragment(context.TODO(), ep)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+// TestV3AuthSnapshotRange ensures the users allowed to read a range get it
+// with SnapshotRange, without being administrators.
+func TestV3AuthSnapshotRange(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k3",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	for _, k := range []string{"k1", "k2"} {
+		_, err := userc.Put(context.TODO(), k, "v")
+		require.NoError(t, err)
+	}
+	resp, err := userc.SnapshotRange(context.TODO(), "scan", "k1", "k3", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.True(t, resp.More)
+	resp, err = userc.SnapshotRange(context.TODO(), "scan", string(resp.NextKey), "k3", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.False(t, resp.More)
+
+	_, err = userc.SnapshotRange(context.TODO(), "other", "k1", "k4", 0, 1)
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+}
+
+// TestV3AuthLeaseGrantor ensures the user who granted a lease is reported in
+// its statistics.
+func TestV3AuthLeaseGrantor(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	userc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer userc.Close()
+
+	lresp, err := userc.Grant(context.TODO(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = userc.Put(context.TODO(), "k1", "v", clientv3.WithLease(lresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	tresp, err := userc.TimeToLive(context.TODO(), lresp.ID, clientv3.WithLeaseStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.Stats == nil || tresp.Stats.Grantor != "user1" || tresp.Stats.AttachedKeys != 1 {
+		t.Fatalf("expected the lease granted by user1 with 1 attached key, got %+v", tresp.Stats)
+	}
+}
+
 func authSetupUsers(t *testing.T, auth pb.AuthClient, users []user) {
 	for _, user := range users {
 		if _, err := auth.UserAdd(context.TODO(), &pb.AuthUserAddRequest{Name: user.name, Password: user.password, Options: &authpb.UserAddOptions{NoPassword: false}}); err != nil {
@@ -347,6 +724,132 @@ func authSetupUsers(t *testing.T, auth pb.AuthClient, users []user) {
 	}
 }
 
+// TestV3AuthOIDC ensures the clients presenting the OIDC ID tokens of the
+// configured issuer are authenticated as the etcd users of their subject,
+// holding the roles mapped from their groups.
+func TestV3AuthOIDC(t *testing.T) {
+	integration.BeforeTest(t)
+
+	pem, err := os.ReadFile(integration.TestTLSInfo.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer":%q,"jwks_uri":%q}`, idp.URL, idp.URL+"/keys")
+		case "/keys":
+			fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"k1","n":%q,"e":%q}]}`,
+				base64.RawURLEncoding.EncodeToString(priv.N.Bytes()),
+				base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.E)).Bytes()))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer idp.Close()
+	var tokens atomic.Int32
+	idToken := func(aud, sub string, groups ...string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			tk := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+				"sub":    sub,
+				"aud":    aud,
+				"iss":    idp.URL,
+				"exp":    time.Now().Add(time.Hour).Unix(),
+				"nonce":  tokens.Add(1),
+				"groups": groups,
+			})
+			tk.Header["kid"] = "k1"
+			return tk.SignedString(priv)
+		}
+	}
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{
+		Size:      1,
+		AuthToken: fmt.Sprintf("oidc,issuer=%s,client-id=etcd,username-prefix=oidc:,group-roles=devs:devs;admins:root", idp.URL),
+	})
+	defer clus.Terminate(t)
+
+	auth := integration.ToGRPC(clus.Client(0)).Auth
+	if _, err = auth.RoleAdd(context.TODO(), &pb.AuthRoleAddRequest{Name: "devs"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = auth.RoleGrantPermission(context.TODO(), &pb.AuthRoleGrantPermissionRequest{
+		Name: "devs",
+		Perm: &authpb.Permission{PermType: authpb.READWRITE, Key: []byte("k2"), RangeEnd: []byte("k3")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	users := []user{
+		{
+			name:     "oidc:alice",
+			password: "unused",
+			role:     "role1",
+			key:      "k1",
+			end:      "k2",
+		},
+	}
+	authSetupUsers(t, auth, users)
+	authSetupRoot(t, auth)
+
+	alice, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), TokenSource: idToken("etcd", "alice")})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer alice.Close()
+	if _, err = alice.Put(context.TODO(), "k1", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = alice.Put(context.TODO(), "k3", "v"); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// the user of the groups granted roles needs not exist
+	bob, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), TokenSource: idToken("etcd", "bob", "devs")})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer bob.Close()
+	if _, err = bob.Put(context.TODO(), "k2", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bob.Put(context.TODO(), "k1", "v"); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// the updates of the auth store invalidate the ID tokens, the clients
+	// getting new ones
+	root, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), TokenSource: idToken("etcd", "carol", "admins")})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer root.Close()
+	if _, err = root.RoleGrantPermission(context.TODO(), "devs", "k4", "", clientv3.PermissionType(clientv3.PermReadWrite)); err != nil {
+		t.Fatal(err)
+	}
+	n := tokens.Load()
+	if _, err = bob.Put(context.TODO(), "k4", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if tokens.Load() == n {
+		t.Fatal("expected the client to get a new ID token")
+	}
+
+	other, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), TokenSource: idToken("other", "alice")})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer other.Close()
+	if _, err = other.Put(context.TODO(), "k1", "v"); !errors.Is(err, rpctypes.ErrInvalidAuthToken) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidAuthToken, err)
+	}
+}
+
 func authSetupRoot(t *testing.T, auth pb.AuthClient) {
 	root := []user{
 		{
@@ -585,3 +1088,68 @@ func TestV3AuthWatchErrorAndWatchId0(t *testing.T) {
 
 	<-watchEndCh
 }
+
+// TestV3AuthWatchAndCountPermissions ensures the users granted the watch or
+// count permission of keys only can watch or count them without reading them.
+func TestV3AuthWatchAndCountPermissions(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	users := []user{
+		{
+			name:     "user1",
+			password: "user1-123",
+			role:     "role1",
+		},
+	}
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, users)
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	_, err := rootc.RoleGrantPermission(ctx, "role1", "w", "x", clientv3.PermissionType(clientv3.PermWatch))
+	require.NoError(t, err)
+	_, err = rootc.RoleGrantPermission(ctx, "role1", "c", "d", clientv3.PermissionType(clientv3.PermCount))
+	require.NoError(t, err)
+	_, err = rootc.Put(ctx, "c1", "v")
+	require.NoError(t, err)
+
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user1", Password: "user1-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	resp, err := c.Get(ctx, "c", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.Count)
+	resp, err = c.Get(ctx, "c", clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.Empty(t, resp.Kvs[0].Value)
+	_, err = c.Get(ctx, "c1")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+	_, err = c.Get(ctx, "w1")
+	require.ErrorIs(t, err, rpctypes.ErrPermissionDenied)
+
+	wChan := c.Watch(ctx, "c1")
+	wresp := <-wChan
+	require.ErrorContains(t, wresp.Err(), rpctypes.ErrPermissionDenied.Error())
+
+	wChan = c.Watch(ctx, "w", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	wresp = <-wChan
+	require.NoError(t, wresp.Err())
+	_, err = rootc.Put(ctx, "w1", "v")
+	require.NoError(t, err)
+	wresp = <-wChan
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, "w1", string(wresp.Events[0].Kv.Key))
+}
@@ -153,6 +153,122 @@ func TestV3CompactCurrentRev(t *testing.T) {
 	}
 }
 
+// TestV3RevisionPin ensures compactions past a pinned revision are rejected
+// until the pin is released or expires.
+func TestV3RevisionPin(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+	mt := integration.ToGRPC(clus.RandClient()).Maintenance
+	preq := &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}
+	for i := 0; i < 3; i++ {
+		if _, err := kvc.Put(context.Background(), preq); err != nil {
+			t.Fatalf("couldn't put key (%v)", err)
+		}
+	}
+
+	pin := &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_PIN, Owner: "backup", Revision: 2, TTL: 60}
+	if _, err := mt.RevisionPin(context.Background(), pin); err != nil {
+		t.Fatalf("couldn't pin revision (%v)", err)
+	}
+	_, err := kvc.Compact(context.Background(), &pb.CompactionRequest{Revision: 4})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCRevisionPinned) {
+		t.Fatalf("compact got %v, expected %v", err, rpctypes.ErrGRPCRevisionPinned)
+	}
+	if _, err = kvc.Compact(context.Background(), &pb.CompactionRequest{Revision: 2}); err != nil {
+		t.Fatalf("couldn't compact up to the pinned revision (%v)", err)
+	}
+	pin.Revision = 1
+	if _, err = mt.RevisionPin(context.Background(), pin); !eqErrGRPC(err, rpctypes.ErrGRPCCompacted) {
+		t.Fatalf("pin got %v, expected %v", err, rpctypes.ErrGRPCCompacted)
+	}
+
+	unpin := &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_UNPIN, Owner: "backup"}
+	if _, err = mt.RevisionPin(context.Background(), unpin); err != nil {
+		t.Fatalf("couldn't unpin revision (%v)", err)
+	}
+	if _, err = kvc.Compact(context.Background(), &pb.CompactionRequest{Revision: 3}); err != nil {
+		t.Fatalf("couldn't compact after unpinning (%v)", err)
+	}
+
+	// the current revision is pinned if none is given
+	pin = &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_PIN, Owner: "scan", TTL: 1}
+	resp, err := mt.RevisionPin(context.Background(), pin)
+	if err != nil {
+		t.Fatalf("couldn't pin revision (%v)", err)
+	}
+	if len(resp.Pins) != 1 || resp.Pins[0].Revision != 4 || resp.Pins[0].Version != 1 {
+		t.Fatalf("unexpected pins %+v", resp.Pins)
+	}
+	// the pin is released once its TTL elapses
+	for i := 0; ; i++ {
+		resp, err = mt.RevisionPin(context.Background(), &pb.RevisionPinRequest{Action: pb.RevisionPinRequest_GET})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Pins) == 0 {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("expired pins were not released %+v", resp.Pins)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// TestV3SnapshotRange ensures that the pages of a SnapshotRange session are
+// read at the revision of its first call, pinned until the last page.
+func TestV3SnapshotRange(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+	mt := integration.ToGRPC(clus.RandClient()).Maintenance
+	for _, k := range []string{"a", "b", "c"} {
+		if _, err := kvc.Put(context.Background(), &pb.PutRequest{Key: []byte(k), Value: []byte("v1")}); err != nil {
+			t.Fatalf("couldn't put key (%v)", err)
+		}
+	}
+
+	req := &pb.SnapshotRangeRequest{Session: "scan", Key: []byte("a"), RangeEnd: []byte("z"), Limit: 2}
+	resp, err := mt.SnapshotRange(context.Background(), req)
+	if err != nil {
+		t.Fatalf("couldn't get snapshot range (%v)", err)
+	}
+	if resp.Revision != 4 || len(resp.Kvs) != 2 || !resp.More || string(resp.NextKey) != "b\x00" {
+		t.Fatalf("unexpected first page %+v", resp)
+	}
+
+	// the view ignores the writes after its first call and can't be compacted
+	if _, err = kvc.Put(context.Background(), &pb.PutRequest{Key: []byte("c"), Value: []byte("v2")}); err != nil {
+		t.Fatalf("couldn't put key (%v)", err)
+	}
+	_, err = kvc.Compact(context.Background(), &pb.CompactionRequest{Revision: 5})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCRevisionPinned) {
+		t.Fatalf("compact got %v, expected %v", err, rpctypes.ErrGRPCRevisionPinned)
+	}
+
+	req.Key = resp.NextKey
+	resp, err = mt.SnapshotRange(context.Background(), req)
+	if err != nil {
+		t.Fatalf("couldn't get snapshot range (%v)", err)
+	}
+	if resp.Revision != 4 || len(resp.Kvs) != 1 || resp.More || string(resp.Kvs[0].Value) != "v1" {
+		t.Fatalf("unexpected last page %+v", resp)
+	}
+
+	// the last page releases the pin of the session
+	if _, err = kvc.Compact(context.Background(), &pb.CompactionRequest{Revision: 5}); err != nil {
+		t.Fatalf("couldn't compact after the last page (%v)", err)
+	}
+	if _, err = mt.SnapshotRange(context.Background(), &pb.SnapshotRangeRequest{Key: []byte("a")}); !eqErrGRPC(err, rpctypes.ErrGRPCEmptyField) {
+		t.Fatalf("snapshot range without session got %v, expected %v", err, rpctypes.ErrGRPCEmptyField)
+	}
+}
+
 // TestV3HashKV ensures that multiple calls of HashKV on same node return same hash and compact rev.
 func TestV3HashKV(t *testing.T) {
 	integration.BeforeTest(t)
@@ -1010,6 +1126,63 @@ func TestV3PutMissingLease(t *testing.T) {
 	}
 }
 
+// TestV3PutWithTTL ensures that a key put with a TTL is deleted once the TTL
+// elapsed, unless it is put again without a TTL.
+func TestV3PutWithTTL(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+	if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), TtlSeconds: 1}); err != nil {
+		t.Fatalf("couldn't put key (%v)", err)
+	}
+	if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("baz"), Value: []byte("bar"), TtlSeconds: 1}); err != nil {
+		t.Fatalf("couldn't put key (%v)", err)
+	}
+	// overwriting the key without a TTL keeps it
+	if _, err := kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte("baz"), Value: []byte("qux")}); err != nil {
+		t.Fatalf("couldn't put key (%v)", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		rresp, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("foo")})
+		if err != nil {
+			t.Fatalf("couldn't get key (%v)", err)
+		}
+		if len(rresp.Kvs) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected key %q to expire, got %v", "foo", rresp.Kvs)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	rresp, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("baz")})
+	if err != nil {
+		t.Fatalf("couldn't get key (%v)", err)
+	}
+	if len(rresp.Kvs) != 1 || string(rresp.Kvs[0].Value) != "qux" {
+		t.Fatalf("expected key %q to be kept, got %v", "baz", rresp.Kvs)
+	}
+
+	tests := []struct {
+		req  *pb.PutRequest
+		werr error
+	}{
+		{&pb.PutRequest{Key: []byte("foo"), TtlSeconds: -1}, rpctypes.ErrGRPCNegativeKeyTTL},
+		{&pb.PutRequest{Key: []byte("foo"), TtlSeconds: 1, Lease: 123456}, rpctypes.ErrGRPCLeaseProvided},
+		{&pb.PutRequest{Key: []byte("foo"), TtlSeconds: 1, IgnoreLease: true}, rpctypes.ErrGRPCLeaseProvided},
+	}
+	for i, tt := range tests {
+		if _, err := kvc.Put(context.TODO(), tt.req); !eqErrGRPC(err, tt.werr) {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+	}
+}
+
 // TestV3DeleteRange tests various edge cases in the DeleteRange API.
 func TestV3DeleteRange(t *testing.T) {
 	integration.BeforeTest(t)
@@ -184,6 +184,83 @@ func TestTransferLeadershipWithLearner(t *testing.T) {
 	}
 }
 
+// TestDrainLeader ensures a drained leader transfers its leadership, rejects
+// the new streams, sends the buffered watch events before ending its watch
+// streams, and stops.
+func TestDrainLeader(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	leadIdx := clus.WaitLeader(t)
+	lead := clus.Members[leadIdx]
+	leadID := uint64(lead.Server.MemberId())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wc := integration.ToGRPC(clus.Client(leadIdx)).Watch
+	ws, err := wc.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creq := &pb.WatchRequest_CreateRequest{CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo")}}
+	if err = ws.Send(&pb.WatchRequest{RequestUnion: creq}); err != nil {
+		t.Fatal(err)
+	}
+	if resp, rerr := ws.Recv(); rerr != nil || !resp.Created {
+		t.Fatalf("expected the watch to be created, got %v, %v", resp, rerr)
+	}
+	kvc := integration.ToGRPC(clus.Client(leadIdx)).KV
+	for i := 0; i < 3; i++ {
+		if _, err = kvc.Put(ctx, &pb.PutRequest{Key: []byte("foo"), Value: []byte(fmt.Sprint(i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mc := integration.ToGRPC(clus.Client(leadIdx)).Maintenance
+	if _, err = mc.Drain(ctx, &pb.DrainRequest{TimeoutSeconds: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := 0
+	for {
+		resp, rerr := ws.Recv()
+		if rerr != nil {
+			if rpctypes.ErrorDesc(rerr) != rpctypes.ErrDraining.Error() {
+				t.Fatalf("expected %v, got %v", rpctypes.ErrDraining, rerr)
+			}
+			break
+		}
+		events += len(resp.Events)
+	}
+	if events != 3 {
+		t.Fatalf("expected the 3 buffered events before the watch stream ends, got %d", events)
+	}
+
+	ws, err = wc.Watch(ctx)
+	if err == nil {
+		_, err = ws.Recv()
+	}
+	if rpctypes.ErrorDesc(err) != rpctypes.ErrDraining.Error() {
+		t.Fatalf("expected the new stream to be rejected with %v, got %v", rpctypes.ErrDraining, err)
+	}
+
+	select {
+	case <-lead.Server.StopNotify():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the drained member to stop")
+	}
+	for i, m := range clus.Members {
+		if i == leadIdx {
+			continue
+		}
+		if newLeadID := integration.CheckLeaderTransition(m, leadID); newLeadID == leadID {
+			t.Fatalf("expected the leadership to move from %x", leadID)
+		}
+	}
+}
+
 func TestFirstCommitNotification(t *testing.T) {
 	integration.BeforeTest(t)
 	ctx := context.Background()
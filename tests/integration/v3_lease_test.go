@@ -29,6 +29,7 @@ import (
 	framecfg "go.etcd.io/etcd/tests/v3/framework/config"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
 
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -610,6 +611,59 @@ func TestV3PutOnNonExistLease(t *testing.T) {
 	}
 }
 
+// TestV3PutOnFullLease ensures that putting a key with a lease that has the
+// maximum number of attached keys fails, and that the LEASEKEYS alarm is
+// raised as the lease approaches it and cleared once it is revoked.
+func TestV3PutOnFullLease(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3, MaxLeaseAttachedKeys: 10})
+	defer clus.Terminate(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli := integration.ToGRPC(clus.RandClient())
+	lresp, err := cli.Lease.LeaseGrant(ctx, &pb.LeaseGrantRequest{TTL: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		putr := &pb.PutRequest{Key: []byte(fmt.Sprintf("foo%d", i)), Value: []byte("bar"), Lease: lresp.ID}
+		if _, err = cli.KV.Put(ctx, putr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	putr := &pb.PutRequest{Key: []byte("foo10"), Value: []byte("bar"), Lease: lresp.ID}
+	if _, err = cli.KV.Put(ctx, putr); !eqErrGRPC(err, rpctypes.ErrGRPCLeaseTooManyKeys) {
+		t.Errorf("err = %v, want %v", err, rpctypes.ErrGRPCLeaseTooManyKeys)
+	}
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: putr}}}}
+	if _, err = cli.KV.Txn(ctx, txn); !eqErrGRPC(err, rpctypes.ErrGRPCLeaseTooManyKeys) {
+		t.Errorf("err = %v, want %v", err, rpctypes.ErrGRPCLeaseTooManyKeys)
+	}
+	// the attached keys can still be updated
+	putr = &pb.PutRequest{Key: []byte("foo0"), Value: []byte("baz"), Lease: lresp.ID}
+	if _, err = cli.KV.Put(ctx, putr); err != nil {
+		t.Fatal(err)
+	}
+
+	hasLeaseKeysAlarm := func() bool {
+		aresp, aerr := cli.Maintenance.Alarm(ctx, &pb.AlarmRequest{Action: pb.AlarmRequest_GET})
+		require.NoError(t, aerr)
+		for _, a := range aresp.Alarms {
+			if a.Alarm == pb.AlarmType_LEASEKEYS {
+				return true
+			}
+		}
+		return false
+	}
+	require.Eventually(t, hasLeaseKeysAlarm, 10*time.Second, 100*time.Millisecond)
+
+	_, err = cli.Lease.LeaseRevoke(ctx, &pb.LeaseRevokeRequest{ID: lresp.ID})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return !hasLeaseKeysAlarm() }, 10*time.Second, 100*time.Millisecond)
+}
+
 // TestV3GetNonExistLease ensures client retrieving nonexistent lease on a follower doesn't result node panic
 // related issue https://github.com/etcd-io/etcd/issues/6537
 func TestV3GetNonExistLease(t *testing.T) {
@@ -28,8 +28,10 @@ import (
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
 )
 
@@ -354,6 +356,443 @@ func TestV3WatchFutureRevision(t *testing.T) {
 	}
 }
 
+// TestV3WatchMaxStartRevisionLag tests that watchers starting too far behind
+// the current revision are rejected with a typed error.
+func TestV3WatchMaxStartRevisionLag(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support rejecting the lagging watches yet")
+	}
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1, WatchMaxStartRevisionLag: 5})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var rev int64
+	for i := 0; i < 10; i++ {
+		resp, err := cli.Put(ctx, "foo", fmt.Sprintf("bar%d", i))
+		require.NoError(t, err)
+		rev = resp.Header.Revision
+	}
+
+	wresp := <-cli.Watch(ctx, "foo", clientv3.WithRev(rev-6))
+	require.ErrorIs(t, wresp.Err(), rpctypes.ErrWatchStartRevisionTooOld)
+	require.True(t, wresp.Canceled)
+	require.Equal(t, rev, wresp.Header.Revision)
+
+	wresp = <-cli.Watch(ctx, "foo", clientv3.WithRev(rev-5))
+	require.NoError(t, wresp.Err())
+	require.NotEmpty(t, wresp.Events)
+	require.Equal(t, rev-5, wresp.Events[0].Kv.ModRevision)
+}
+
+// TestV3WatchMaxStartRevisionAge tests that watchers replaying events written
+// too long ago are rejected with a typed error.
+func TestV3WatchMaxStartRevisionAge(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support rejecting the lagging watches yet")
+	}
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1, WatchMaxStartRevisionAge: time.Second})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	presp, err := cli.Put(ctx, "foo", "bar0")
+	require.NoError(t, err)
+	oldRev := presp.Header.Revision
+	time.Sleep(1500 * time.Millisecond)
+	presp, err = cli.Put(ctx, "foo", "bar1")
+	require.NoError(t, err)
+	rev := presp.Header.Revision
+
+	wresp := <-cli.Watch(ctx, "foo", clientv3.WithRev(oldRev))
+	require.ErrorIs(t, wresp.Err(), rpctypes.ErrWatchStartRevisionTooOld)
+	require.True(t, wresp.Canceled)
+	require.Equal(t, rev, wresp.Header.Revision)
+
+	wresp = <-cli.Watch(ctx, "foo", clientv3.WithRev(rev))
+	require.NoError(t, wresp.Err())
+	require.NotEmpty(t, wresp.Events)
+	require.Equal(t, rev, wresp.Events[0].Kv.ModRevision)
+}
+
+// TestV3WatchValueFilter tests that the put events whose value does not match
+// the value filter of the watcher are not sent to it.
+func TestV3WatchValueFilter(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	wch := cli.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithFilterValueJSONField("spec.node", "n1"))
+	for _, kv := range [][2]string{
+		{"pods/a", `{"spec":{"node":"n2"}}`},
+		{"pods/b", `{"spec":{"node":"n1"}}`},
+		{"pods/c", "not json"},
+	} {
+		_, err := cli.Put(ctx, kv[0], kv[1])
+		require.NoError(t, err)
+	}
+	_, err := cli.Delete(ctx, "pods/a")
+	require.NoError(t, err)
+
+	var events []*clientv3.Event
+	for len(events) < 2 {
+		wresp := <-wch
+		require.NoError(t, wresp.Err())
+		events = append(events, wresp.Events...)
+	}
+	require.Len(t, events, 2)
+	require.Equal(t, "pods/b", string(events[0].Kv.Key))
+	require.Equal(t, clientv3.EventTypeDelete, events[1].Type)
+	require.Equal(t, "pods/a", string(events[1].Kv.Key))
+
+	wresp := <-cli.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithFilterValueRegex("a("))
+	require.ErrorIs(t, wresp.Err(), rpctypes.ErrInvalidWatchValueFilter)
+	require.True(t, wresp.Canceled)
+}
+
+// TestV3WatchProjection tests the watchers with a projection receive their
+// events stripped down, without affecting the other watchers.
+func TestV3WatchProjection(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := cli.Put(ctx, "foo", "v0")
+	require.NoError(t, err)
+
+	keysOnly := cli.Watch(ctx, "foo", clientv3.WithPrevKV(), clientv3.WithEventKeysOnly())
+	metadataOnly := cli.Watch(ctx, "foo", clientv3.WithPrevKV(), clientv3.WithEventMetadataOnly())
+	full := cli.Watch(ctx, "foo", clientv3.WithPrevKV())
+
+	presp, err := cli.Put(ctx, "foo", "v1")
+	require.NoError(t, err)
+	rev := presp.Header.Revision
+
+	wresp := <-keysOnly
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, &mvccpb.KeyValue{Key: []byte("foo"), ModRevision: rev}, wresp.Events[0].Kv)
+	require.Nil(t, wresp.Events[0].PrevKv)
+
+	wresp = <-metadataOnly
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, &mvccpb.KeyValue{Key: []byte("foo"), CreateRevision: rev - 1, ModRevision: rev, Version: 2}, wresp.Events[0].Kv)
+	require.Nil(t, wresp.Events[0].PrevKv)
+
+	wresp = <-full
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 1)
+	require.Equal(t, "v1", string(wresp.Events[0].Kv.Value))
+	require.Equal(t, "v0", string(wresp.Events[0].PrevKv.Value))
+}
+
+// TestV3WatchCoalesce tests that the events of each key within the coalescing
+// window of the watcher are sent as its latest event.
+func TestV3WatchCoalesce(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support coalescing the watch events yet")
+	}
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := cli.Put(ctx, "foo", "v0")
+	require.NoError(t, err)
+
+	wch := cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithPrevKV(), clientv3.WithCoalesceWindow(time.Second))
+	for _, kv := range [][2]string{{"foo", "v1"}, {"foo", "v2"}, {"foo", "v3"}, {"foo2", "v1"}} {
+		_, err = cli.Put(ctx, kv[0], kv[1])
+		require.NoError(t, err)
+	}
+
+	wresp := <-wch
+	require.NoError(t, wresp.Err())
+	require.Len(t, wresp.Events, 2)
+	require.Equal(t, "foo", string(wresp.Events[0].Kv.Key))
+	require.Equal(t, "v3", string(wresp.Events[0].Kv.Value))
+	require.True(t, wresp.Events[0].Coalesced)
+	require.Equal(t, "v0", string(wresp.Events[0].PrevKv.Value))
+	require.Equal(t, "foo2", string(wresp.Events[1].Kv.Key))
+	require.False(t, wresp.Events[1].Coalesced)
+	require.Equal(t, wresp.Events[1].Kv.ModRevision, wresp.Header.Revision)
+}
+
+// TestV3WatchResumeToken tests a watch resumed with the resume token of a
+// progress notification receives exactly the events following it.
+func TestV3WatchResumeToken(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support WatchProgress yet")
+	}
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	wctx, wcancel := context.WithCancel(ctx)
+	wch := cli.Watch(wctx, "foo", clientv3.WithCreatedNotify())
+	wresp := <-wch
+	require.True(t, wresp.Created)
+
+	_, err := cli.Put(ctx, "foo", "v1")
+	require.NoError(t, err)
+	wresp = <-wch
+	require.Len(t, wresp.Events, 1)
+
+	require.NoError(t, cli.RequestProgress(wctx))
+	wresp = <-wch
+	require.True(t, wresp.IsProgressNotify())
+	require.NotEmpty(t, wresp.ResumeToken)
+	token := wresp.ResumeToken
+	wcancel()
+
+	for _, v := range []string{"v2", "v3"} {
+		_, err = cli.Put(ctx, "foo", v)
+		require.NoError(t, err)
+	}
+	var values []string
+	wch = cli.Watch(ctx, "foo", clientv3.WithResumeToken(token))
+	for len(values) < 2 {
+		wresp = <-wch
+		require.NoError(t, wresp.Err())
+		for _, ev := range wresp.Events {
+			values = append(values, string(ev.Kv.Value))
+		}
+	}
+	require.Equal(t, []string{"v2", "v3"}, values)
+
+	// the events following the token are compacted
+	presp, err := cli.Put(ctx, "foo", "v4")
+	require.NoError(t, err)
+	_, err = cli.Compact(ctx, presp.Header.Revision)
+	require.NoError(t, err)
+	wresp = <-cli.Watch(ctx, "foo", clientv3.WithResumeToken(token))
+	require.True(t, wresp.Canceled)
+	require.Equal(t, presp.Header.Revision, wresp.CompactRevision)
+
+	for _, opts := range [][]clientv3.OpOption{
+		{clientv3.WithResumeToken([]byte("foo"))},
+		{clientv3.WithResumeToken(token), clientv3.WithRev(1)},
+	} {
+		wresp = <-cli.Watch(ctx, "foo", opts...)
+		require.ErrorIs(t, wresp.Err(), rpctypes.ErrInvalidWatchResumeToken)
+	}
+}
+
+// TestV3WatchRanges tests a watcher of several key ranges receives the
+// events of all of them, and only them.
+func TestV3WatchRanges(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	wch := cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithCreatedNotify(), clientv3.WithRanges(
+		clientv3.KeyRange{Key: "bar"},
+		clientv3.KeyRange{Key: "zoo", End: clientv3.GetPrefixRangeEnd("zoo")},
+	))
+	wresp := <-wch
+	require.True(t, wresp.Created)
+
+	for _, k := range []string{"foo1", "baz", "bar", "zoo1", "bar1", "foo2"} {
+		_, err := cli.Put(ctx, k, "v")
+		require.NoError(t, err)
+	}
+	var keys []string
+	for len(keys) < 4 {
+		wresp = <-wch
+		require.NoError(t, wresp.Err())
+		for _, ev := range wresp.Events {
+			keys = append(keys, string(ev.Kv.Key))
+		}
+	}
+	require.Equal(t, []string{"foo1", "bar", "zoo1", "foo2"}, keys)
+
+	// the ranges must not overlap
+	wresp = <-cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithRanges(clientv3.KeyRange{Key: "foo1"}))
+	require.True(t, wresp.Canceled)
+	require.ErrorContains(t, wresp.Err(), mvcc.ErrWatcherOverlappingRanges.Error())
+}
+
+// TestV3WatchOverflow tests the watchers exceeding their delivery rate
+// limit are applied the overflow policy.
+func TestV3WatchOverflow(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support the watch overflow policies yet")
+	}
+	integration.BeforeTest(t)
+
+	for _, policy := range []string{v3rpc.WatchOverflowDropOldest, v3rpc.WatchOverflowCancel} {
+		t.Run(policy, func(t *testing.T) {
+			clus := integration.NewCluster(t, &integration.ClusterConfig{
+				Size:                    1,
+				WatchMaxEventsPerSecond: 1,
+				WatchMaxQueuedEvents:    2,
+				WatchOverflowPolicy:     policy,
+			})
+			defer clus.Terminate(t)
+
+			cli := clus.RandClient()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			wch := cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+			wresp := <-wch
+			require.True(t, wresp.Created)
+
+			const total = 10
+			for i := 0; i < total; i++ {
+				_, err := cli.Put(ctx, fmt.Sprintf("foo%d", i), "v")
+				require.NoError(t, err)
+			}
+
+			var received, dropped int64
+			for received+dropped < total {
+				var ok bool
+				wresp, ok = <-wch
+				require.True(t, ok)
+				if policy == v3rpc.WatchOverflowCancel && wresp.Canceled {
+					require.ErrorIs(t, wresp.Err(), rpctypes.ErrWatchOverflow)
+					return
+				}
+				require.NoError(t, wresp.Err())
+				received += int64(len(wresp.Events))
+				dropped += wresp.DroppedEvents
+			}
+			require.Equal(t, v3rpc.WatchOverflowDropOldest, policy)
+			require.Equal(t, int64(total), received+dropped)
+			require.NotZero(t, dropped)
+			require.Equal(t, "foo9", string(wresp.Events[len(wresp.Events)-1].Kv.Key))
+		})
+	}
+}
+
+// TestV3WatchBacklogAlarm tests the slow watchers are reported with their lag
+// and raise the watch backlog alarm, cleared once they are gone.
+func TestV3WatchBacklogAlarm(t *testing.T) {
+	if integration.ThroughProxy {
+		t.Skipf("grpc-proxy does not support the watch lag yet")
+	}
+	integration.BeforeTest(t)
+
+	// the watch stream blocks on the delivery rate limit, leaving the events
+	// of the watcher in memory once its watch channel is full
+	clus := integration.NewCluster(t, &integration.ClusterConfig{
+		Size:                    1,
+		WatchMaxEventsPerSecond: 1,
+		WatchMaxQueuedEvents:    1,
+		WatchBacklogAlarmEvents: 1,
+	})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	wctx, wcancel := context.WithCancel(ctx)
+	defer wcancel()
+	wch := cli.Watch(wctx, "foo", clientv3.WithCreatedNotify())
+	wresp := <-wch
+	require.True(t, wresp.Created)
+
+	for i := 0; i < 200; i++ {
+		_, err := cli.Put(ctx, "foo", "v")
+		require.NoError(t, err)
+	}
+
+	lresp, err := cli.WatchLag(ctx, cli.Endpoints()[0], 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), lresp.SlowWatchers)
+	require.Len(t, lresp.Watchers, 1)
+	require.Greater(t, lresp.Watchers[0].OldestRevision, wresp.Header.Revision)
+	require.Equal(t, "foo", string(lresp.Watchers[0].Key))
+	require.NotZero(t, lresp.Watchers[0].Lag)
+	require.NotZero(t, lresp.PendingEvents)
+
+	hasBacklogAlarm := func() bool {
+		aresp, err := cli.AlarmList(ctx)
+		require.NoError(t, err)
+		for _, a := range aresp.Alarms {
+			if a.Alarm == pb.AlarmType_WATCHBACKLOG {
+				return true
+			}
+		}
+		return false
+	}
+	require.Eventually(t, hasBacklogAlarm, 10*time.Second, 100*time.Millisecond)
+
+	// the watcher is canceled along with its watch stream
+	wcancel()
+	require.Eventually(t, func() bool { return !hasBacklogAlarm() }, 10*time.Second, 100*time.Millisecond)
+}
+
+// TestV3WatchSharedEvents tests the watchers of the same events receive
+// them with the fields of their own spec.
+func TestV3WatchSharedEvents(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := cli.Put(ctx, "foo", "v0")
+	require.NoError(t, err)
+
+	wchs := []clientv3.WatchChan{
+		cli.Watch(ctx, "foo", clientv3.WithPrevKV()),
+		cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithPrevKV()),
+		cli.Watch(ctx, "foo"),
+	}
+	_, err = cli.Put(ctx, "foo", "v1")
+	require.NoError(t, err)
+
+	for i, wch := range wchs {
+		wresp := <-wch
+		require.NoError(t, wresp.Err())
+		require.Len(t, wresp.Events, 1)
+		require.Equal(t, "v1", string(wresp.Events[0].Kv.Value))
+		if i < 2 {
+			require.Equal(t, "v0", string(wresp.Events[0].PrevKv.Value))
+		} else {
+			require.Nil(t, wresp.Events[0].PrevKv)
+		}
+	}
+}
+
 // TestV3WatchWrongRange tests wrong range does not create watchers.
 func TestV3WatchWrongRange(t *testing.T) {
 	integration.BeforeTest(t)
@@ -0,0 +1,184 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+)
+
+const (
+	minRetryInterval = 100 * time.Millisecond
+	maxRetryInterval = 30 * time.Second
+)
+
+// bridge publishes the events of its prefixes to its sink. The revision of
+// the last published batch of every prefix is checkpointed, so that
+// publishing resumes from it after failures and restarts.
+type bridge struct {
+	lg       *zap.Logger
+	cli      *clientv3.Client
+	name     string
+	prefixes []string
+	sink     v3changefeed.Sink
+	cp       *checkpoints
+}
+
+// run publishes the events of all prefixes until the context is canceled.
+func (b *bridge) run(ctx context.Context) {
+	donec := make(chan struct{}, len(b.prefixes))
+	for _, prefix := range b.prefixes {
+		go func(prefix string) {
+			b.tailLoop(ctx, prefix)
+			donec <- struct{}{}
+		}(prefix)
+	}
+	for range b.prefixes {
+		<-donec
+	}
+}
+
+// tailLoop publishes the events of the prefix until the context is
+// canceled, watching it again with backoff after failures.
+func (b *bridge) tailLoop(ctx context.Context, prefix string) {
+	interval := minRetryInterval
+	for {
+		last, _ := b.cp.get(prefix)
+		err := b.tail(ctx, prefix)
+		if ctx.Err() != nil {
+			return
+		}
+		if rev, _ := b.cp.get(prefix); rev != last {
+			interval = minRetryInterval
+		}
+		b.lg.Warn("failed to tail prefix, retrying",
+			zap.String("prefix", prefix),
+			zap.Duration("retry-interval", interval),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		interval = nextRetryInterval(interval)
+	}
+}
+
+// tail publishes the events of the prefix from its checkpoint until the
+// watch fails.
+func (b *bridge) tail(ctx context.Context, prefix string) error {
+	rev, err := b.startRevision(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for {
+		if rev, err = b.watch(ctx, prefix, rev); err != nil {
+			return err
+		}
+	}
+}
+
+// startRevision returns the revision to start watching the prefix from,
+// following its checkpoint, or following the current revision if the
+// prefix has no checkpoint yet, checkpointing it.
+func (b *bridge) startRevision(ctx context.Context, prefix string) (int64, error) {
+	if rev, ok := b.cp.get(prefix); ok {
+		return rev + 1, nil
+	}
+	resp, err := b.cli.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	if err := b.cp.save(prefix, resp.Header.Revision); err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision + 1, nil
+}
+
+// watch publishes the events of the prefix from the revision until the
+// watch fails. It returns the revision to watch from again once the
+// revision is compacted.
+func (b *bridge) watch(ctx context.Context, prefix string, rev int64) (int64, error) {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	for wresp := range b.cli.Watch(wctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(rev)) {
+		if wresp.CompactRevision != 0 {
+			b.lg.Warn(
+				"checkpoint was compacted, events between the checkpoint and the compaction revision are lost",
+				zap.String("prefix", prefix),
+				zap.Int64("compact-revision", wresp.CompactRevision),
+				zap.Int64("lost-revisions", wresp.LostRevisions),
+			)
+			return wresp.CompactRevision, nil
+		}
+		if err := wresp.Err(); err != nil {
+			return 0, err
+		}
+		if len(wresp.Events) == 0 {
+			continue
+		}
+		evs := make([]mvccpb.Event, len(wresp.Events))
+		for i, ev := range wresp.Events {
+			evs[i] = mvccpb.Event(*ev)
+		}
+		if err := b.publish(ctx, v3changefeed.Batch{Feed: b.name, Prefix: prefix, Events: evs}); err != nil {
+			return 0, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("watch closed")
+}
+
+// publish delivers the batch, retrying with backoff until it succeeds or
+// the context is canceled, and checkpoints its last revision.
+func (b *bridge) publish(ctx context.Context, batch v3changefeed.Batch) error {
+	interval := minRetryInterval
+	for {
+		err := b.sink.Publish(ctx, batch)
+		if err == nil {
+			break
+		}
+		b.lg.Warn("failed to publish events, retrying",
+			zap.String("prefix", batch.Prefix),
+			zap.Int64("revision", batch.Events[0].Kv.ModRevision),
+			zap.Duration("retry-interval", interval),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		interval = nextRetryInterval(interval)
+	}
+	return b.cp.save(batch.Prefix, batch.Events[len(batch.Events)-1].Kv.ModRevision)
+}
+
+func nextRetryInterval(interval time.Duration) time.Duration {
+	if interval *= 2; interval > maxRetryInterval {
+		return maxRetryInterval
+	}
+	return interval
+}
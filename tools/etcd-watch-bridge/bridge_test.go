@@ -0,0 +1,149 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// recordingSink records the mod revisions of the published events, failing
+// the first publish if failOnce is set.
+type recordingSink struct {
+	mu       sync.Mutex
+	failOnce bool
+	revs     []int64
+}
+
+func (s *recordingSink) Publish(_ context.Context, b v3changefeed.Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failOnce {
+		s.failOnce = false
+		return errors.New("unavailable")
+	}
+	for _, ev := range b.Events {
+		s.revs = append(s.revs, ev.Kv.ModRevision)
+	}
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) published() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.revs...)
+}
+
+func TestBridge(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	cli := clus.RandClient()
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	start := func(sink *recordingSink) (stop func()) {
+		cp, err := loadCheckpoints(path, "test")
+		require.NoError(t, err)
+		b := &bridge{lg: zaptest.NewLogger(t), cli: cli, name: "test", prefixes: []string{"foo"}, sink: sink, cp: cp}
+		ctx, cancel := context.WithCancel(context.Background())
+		donec := make(chan struct{})
+		go func() {
+			b.run(ctx)
+			close(donec)
+		}()
+		// the bridge starts from the current revision
+		require.Eventually(t, func() bool {
+			_, ok := cp.get("foo")
+			return ok
+		}, 5*time.Second, 10*time.Millisecond)
+		return func() {
+			cancel()
+			<-donec
+		}
+	}
+	put := func(key string) int64 {
+		resp, err := cli.Put(context.Background(), key, "v")
+		require.NoError(t, err)
+		return resp.Header.Revision
+	}
+
+	// the failed batch is published again
+	sink := &recordingSink{failOnce: true}
+	stop := start(sink)
+	rev1 := put("foo1")
+	put("bar")
+	rev2 := put("foo2")
+	require.Eventually(t, func() bool { return len(sink.published()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{rev1, rev2}, sink.published())
+	stop()
+
+	// publishing resumes from the checkpoint
+	rev3 := put("foo3")
+	sink = &recordingSink{}
+	stop = start(sink)
+	defer stop()
+	require.Eventually(t, func() bool { return len(sink.published()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{rev3}, sink.published())
+}
+
+func TestCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cp, err := loadCheckpoints(path, "a")
+	require.NoError(t, err)
+	_, ok := cp.get("foo")
+	require.False(t, ok)
+	require.NoError(t, cp.save("foo", 5))
+	require.NoError(t, cp.save("", 7))
+
+	cp, err = loadCheckpoints(path, "a")
+	require.NoError(t, err)
+	rev, ok := cp.get("foo")
+	require.True(t, ok)
+	require.Equal(t, int64(5), rev)
+	rev, ok = cp.get("")
+	require.True(t, ok)
+	require.Equal(t, int64(7), rev)
+
+	_, err = loadCheckpoints(path, "b")
+	require.ErrorContains(t, err, `belongs to bridge "a"`)
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	s := newWriterSink(&buf)
+	b := v3changefeed.Batch{Feed: "a", Prefix: "foo", Events: []mvccpb.Event{
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("foo1"), Value: []byte("v"), CreateRevision: 2, ModRevision: 2, Version: 1}},
+	}}
+	require.NoError(t, s.Publish(context.Background(), b))
+	require.NoError(t, s.Publish(context.Background(), b))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, `{"feed":"a","prefix":"foo","events":[{"type":"PUT","key":"Zm9vMQ==","value":"dg==","create_revision":2,"mod_revision":2,"version":1}]}`, lines[0])
+}
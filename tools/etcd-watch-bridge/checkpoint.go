@@ -0,0 +1,99 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// checkpointFile is the JSON encoding of the checkpoints.
+type checkpointFile struct {
+	Name      string           `json:"name"`
+	Revisions map[string]int64 `json:"revisions"`
+}
+
+// checkpoints hold the revision of the last published batch of every
+// prefix of a bridge. They are kept in a local file rather than in etcd, so
+// that checkpointing does not produce events for the watched prefixes.
+type checkpoints struct {
+	path string
+	name string
+
+	mu   sync.Mutex
+	revs map[string]int64
+}
+
+// loadCheckpoints reads the checkpoints of the named bridge from the file,
+// which may not exist yet.
+func loadCheckpoints(path, name string) (*checkpoints, error) {
+	c := &checkpoints{path: path, name: name, revs: make(map[string]int64)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f checkpointFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint file %q: %w", path, err)
+	}
+	if f.Name != name {
+		return nil, fmt.Errorf("checkpoint file %q belongs to bridge %q, not %q", path, f.Name, name)
+	}
+	for prefix, rev := range f.Revisions {
+		c.revs[prefix] = rev
+	}
+	return c, nil
+}
+
+// get returns the checkpoint of the prefix, if any.
+func (c *checkpoints) get(prefix string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rev, ok := c.revs[prefix]
+	return rev, ok
+}
+
+// save checkpoints the revision of the prefix, replacing the file
+// atomically.
+func (c *checkpoints) save(prefix string, rev int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revs[prefix] = rev
+	b, err := json.Marshal(checkpointFile{Name: c.name, Revisions: c.revs})
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
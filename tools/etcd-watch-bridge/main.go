@@ -0,0 +1,114 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/logutil"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+)
+
+// prefixesFlag collects the values of a repeated flag.
+type prefixesFlag []string
+
+func (p *prefixesFlag) String() string { return strings.Join(*p, ",") }
+
+func (p *prefixesFlag) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+func main() {
+	var prefixes prefixesFlag
+	endpoints := flag.String("endpoints", "127.0.0.1:2379", "comma-separated list of the etcd endpoints")
+	dialTimeout := flag.Duration("dial-timeout", 5*time.Second, "dial timeout for the etcd client")
+	cacert := flag.String("cacert", "", "CA bundle verifying the certificates of the etcd endpoints")
+	cert := flag.String("cert", "", "client certificate for the etcd endpoints")
+	key := flag.String("key", "", "client key for the etcd endpoints")
+	name := flag.String("name", "etcd-watch-bridge", "name of the bridge, given to the sink with every batch and guarding its checkpoint file")
+	flag.Var(&prefixes, "prefix", "key prefix whose events are published, repeated for more prefixes (empty means the whole key space)")
+	sink := flag.String("sink", "stdout", "sink of the events: 'webhook' posts them to --webhook-url, 'stdout' writes them as lines of JSON")
+	webhookURL := flag.String("webhook-url", "", "URL the 'webhook' sink posts the events to")
+	checkpointFile := flag.String("checkpoint-file", "etcd-watch-bridge.checkpoint", "file holding the revision of the last published events of every prefix")
+	flag.Parse()
+
+	lg, err := logutil.CreateDefaultZapLogger(zap.InfoLevel)
+	if err != nil {
+		panic(err)
+	}
+	if len(prefixes) == 0 {
+		exitWithError(lg, fmt.Errorf("at least one --prefix is required"))
+	}
+
+	var s v3changefeed.Sink
+	switch *sink {
+	case "webhook":
+		if *webhookURL == "" {
+			exitWithError(lg, fmt.Errorf("--webhook-url is required by the 'webhook' sink"))
+		}
+		s = v3changefeed.NewWebhookSink(*webhookURL, nil)
+	case "stdout":
+		s = newWriterSink(os.Stdout)
+	default:
+		exitWithError(lg, fmt.Errorf("unknown sink %q", *sink))
+	}
+	defer s.Close()
+
+	cp, err := loadCheckpoints(*checkpointFile, *name)
+	if err != nil {
+		exitWithError(lg, err)
+	}
+
+	cfg := clientv3.Config{
+		Endpoints:   strings.Split(*endpoints, ","),
+		DialTimeout: *dialTimeout,
+		Logger:      lg,
+	}
+	if *cacert != "" || *cert != "" || *key != "" {
+		tlsInfo := transport.TLSInfo{TrustedCAFile: *cacert, CertFile: *cert, KeyFile: *key}
+		if cfg.TLS, err = tlsInfo.ClientConfig(); err != nil {
+			exitWithError(lg, err)
+		}
+	}
+	cli, err := clientv3.New(cfg)
+	if err != nil {
+		exitWithError(lg, err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	lg.Info("started publishing events", zap.String("name", *name), zap.Strings("prefixes", prefixes), zap.String("sink", *sink))
+	b := &bridge{lg: lg, cli: cli, name: *name, prefixes: prefixes, sink: s, cp: cp}
+	b.run(ctx)
+	lg.Info("stopped publishing events")
+}
+
+func exitWithError(lg *zap.Logger, err error) {
+	lg.Error("etcd-watch-bridge failed", zap.Error(err))
+	os.Exit(1)
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3changefeed"
+)
+
+// writerSink writes every batch as a line of JSON, encoded as the webhook
+// sink posts it, e.g. to the standard output to be piped into the producer
+// of a message queue.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newWriterSink(w io.Writer) v3changefeed.Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Publish(_ context.Context, b v3changefeed.Batch) error {
+	line, err := json.Marshal(v3changefeed.NewWebhookBatch(b))
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintf(s.w, "%s\n", line)
+	return err
+}
+
+func (s *writerSink) Close() error { return nil }